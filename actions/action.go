@@ -3,8 +3,10 @@ package actions
 import (
 	"context"
 	"fmt"
-	"net/http"
+	"net/url"
 	"time"
+
+	"github.com/bozz33/sublimeadmin/form"
 )
 
 // ActionType defines the action style.
@@ -33,6 +35,17 @@ type Action struct {
 	ConfirmLabel         string
 	CancelLabel          string
 
+	// Destructive confirmation
+	ConfirmPhrase         string                // phrase the user must type to confirm
+	ConfirmPhraseResolver func(item any) string // per-item phrase, e.g. the record name
+	SummaryResolver       func(item any) string // URL of an HTML summary shown in the modal
+	Async                 bool                  // submit in the background with a loading state
+
+	// Modal form (see WithForm)
+	Form        *form.Form
+	FormHandler func(ctx context.Context, item any, data url.Values) error
+	SubmitLabel string
+
 	// Lifecycle hooks
 	BeforeFunc    func(ctx context.Context, item any) error
 	AfterFunc     func(ctx context.Context, item any) error
@@ -46,10 +59,17 @@ type Action struct {
 	RateLimitMax    int           // max calls per window (0 = disabled)
 	RateLimitWindow time.Duration // window duration
 
+	// Idempotency: a submission is processed once (see Idempotent)
+	IdempotencyRequired bool
+
 	// Notification
 	SuccessMessage string
 	FailureMessage string
 
+	// Standalone endpoint (see ForResource)
+	ResourceSlug string
+	BasePath     string // path of the panel mounting the endpoint, e.g. "/admin"
+
 	// Redirect
 	RedirectURL      string         // static redirect after action; empty = back to list
 	RedirectResolver func(item any) string // dynamic redirect
@@ -108,6 +128,64 @@ func (a *Action) WithConfirmLabels(confirm, cancel string) *Action {
 	return a
 }
 
+// RequiresPhrase asks the user to type phrase before the action can be
+// confirmed. It enables the confirmation modal.
+func (a *Action) RequiresPhrase(phrase string) *Action {
+	a.ConfirmPhrase = phrase
+	a.enableConfirmation()
+	return a
+}
+
+// RequiresPhraseFrom asks the user to type a phrase resolved from the item,
+// typically its name: "type the resource name to confirm".
+func (a *Action) RequiresPhraseFrom(fn func(item any) string) *Action {
+	a.ConfirmPhraseResolver = fn
+	a.enableConfirmation()
+	return a
+}
+
+// ConfirmationPhrase returns the phrase to type for the given item, or "".
+func (a *Action) ConfirmationPhrase(item any) string {
+	if a.ConfirmPhraseResolver != nil {
+		return a.ConfirmPhraseResolver(item)
+	}
+	return a.ConfirmPhrase
+}
+
+// WithSummary sets the URL of an HTML fragment loaded into the confirmation
+// modal when it opens, e.g. what a deletion will cascade to. The CRUD
+// handler serves one for deletions at {baseURL}/{id}/delete-summary.
+func (a *Action) WithSummary(resolver func(item any) string) *Action {
+	a.SummaryResolver = resolver
+	return a
+}
+
+// SummaryURL returns the summary URL for a given item, or "".
+func (a *Action) SummaryURL(item any) string {
+	if a.SummaryResolver != nil {
+		return a.SummaryResolver(item)
+	}
+	return ""
+}
+
+// SubmitAsync submits the confirmed action in the background: the modal
+// stays open with a loading state, shows the error if the action fails and
+// follows the redirect once it succeeds.
+func (a *Action) SubmitAsync() *Action {
+	a.Async = true
+	return a
+}
+
+func (a *Action) enableConfirmation() {
+	if a.RequiresConfirmation {
+		return
+	}
+	a.RequiresConfirmation = true
+	a.ConfirmLabel = "Confirm"
+	a.CancelLabel = "Cancel"
+	a.Type = Button
+}
+
 // Before registers a hook called before the action executes.
 // Return a non-nil error to abort execution.
 func (a *Action) Before(fn func(ctx context.Context, item any) error) *Action {
@@ -147,13 +225,23 @@ func (a *Action) IsAuthorized(ctx context.Context, item any) bool {
 	return a.AuthorizeFunc(ctx, item)
 }
 
-// RateLimit sets a rate limit (max calls per window).
+// RateLimit sets a rate limit (max calls per window) for each user, e.g.
+// RateLimit(5, time.Hour). It is enforced by the Limiter of the panel.
 func (a *Action) RateLimit(max int, window time.Duration) *Action {
 	a.RateLimitMax = max
 	a.RateLimitWindow = window
 	return a
 }
 
+// Idempotent makes the form of the action carry an idempotency key, so a
+// submission sent twice (a double click on "Send invoice") runs once. The
+// second one fails with ErrDuplicateSubmission. A submission without key
+// fails with ErrMissingIdempotencyKey.
+func (a *Action) Idempotent() *Action {
+	a.IdempotencyRequired = true
+	return a
+}
+
 // WithSuccessMessage sets the flash message shown on success.
 func (a *Action) WithSuccessMessage(msg string) *Action {
 	a.SuccessMessage = msg
@@ -226,17 +314,6 @@ func (a *Action) URL(item any) string {
 	return ""
 }
 
-// ServeHTTP is intentionally not implemented on Action.
-// Only ModalAction implements http.Handler and can be registered as a route handler.
-// If you need an HTTP-handled action, use actions.ModalAction instead.
-//
-// Calling this method will panic to surface the misconfiguration at startup
-// rather than silently returning 501 at runtime.
-func (a *Action) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	panic("sublimeadmin: Action.ServeHTTP called on action \"" + a.Name + "\" — " +
-		"Action does not implement http.Handler. Use actions.ModalAction for HTTP-handled actions.")
-}
-
 // EditAction creates a standard Edit button.
 func EditAction(baseURL string) *Action {
 	return New("edit").
@@ -257,7 +334,11 @@ func DeleteAction(baseURL string) *Action {
 		RequiresDialog("Delete this item?", "This action cannot be undone.").
 		SetUrl(func(item any) string {
 			return fmt.Sprintf("%s/%v", baseURL, getItemID(item))
-		})
+		}).
+		WithSummary(func(item any) string {
+			return fmt.Sprintf("%s/%v/delete-summary", baseURL, getItemID(item))
+		}).
+		SubmitAsync()
 	a.Method = "DELETE"
 	return a
 }
@@ -333,7 +414,11 @@ func ForceDeleteAction(baseURL string) *Action {
 		RequiresDialog("Permanently delete?", "This action CANNOT be undone. The record will be deleted forever.").
 		SetUrl(func(item any) string {
 			return fmt.Sprintf("%s/%v/force-delete", baseURL, getItemID(item))
-		})
+		}).
+		WithSummary(func(item any) string {
+			return fmt.Sprintf("%s/%v/delete-summary", baseURL, getItemID(item))
+		}).
+		SubmitAsync()
 	a.Method = "DELETE"
 	return a
 }
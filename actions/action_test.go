@@ -141,3 +141,48 @@ func TestSetUrl(t *testing.T) {
 		t.Errorf("Expected '/users/123/edit', got '%s'", url)
 	}
 }
+
+func TestRequiresPhrase(t *testing.T) {
+	entity := &MockEntity{ID: 7, Name: "acme"}
+
+	action := New("delete").RequiresPhrase("DELETE")
+	if !action.RequiresConfirmation || action.Type != Button {
+		t.Error("Expected RequiresPhrase to enable the confirmation modal")
+	}
+	if action.ConfirmLabel != "Confirm" {
+		t.Errorf("Expected default confirm label, got '%s'", action.ConfirmLabel)
+	}
+	if got := action.ConfirmationPhrase(entity); got != "DELETE" {
+		t.Errorf("Expected phrase 'DELETE', got '%s'", got)
+	}
+
+	action = New("delete").
+		RequiresDialog("Delete?", "Gone forever").
+		WithConfirmLabels("Delete", "Keep").
+		RequiresPhraseFrom(func(item any) string { return item.(*MockEntity).Name })
+	if got := action.ConfirmationPhrase(entity); got != "acme" {
+		t.Errorf("Expected phrase 'acme', got '%s'", got)
+	}
+	if action.ConfirmLabel != "Delete" || action.CancelLabel != "Keep" {
+		t.Error("Expected RequiresPhraseFrom to keep the custom labels")
+	}
+
+	if got := New("edit").ConfirmationPhrase(entity); got != "" {
+		t.Errorf("Expected no phrase, got '%s'", got)
+	}
+}
+
+func TestDeleteAction_SummaryAndAsync(t *testing.T) {
+	action := DeleteAction("/users")
+	entity := &MockEntity{ID: 5}
+
+	if got := action.SummaryURL(entity); got != "/users/5/delete-summary" {
+		t.Errorf("Expected '/users/5/delete-summary', got '%s'", got)
+	}
+	if !action.Async {
+		t.Error("Expected DeleteAction to submit asynchronously")
+	}
+	if got := New("edit").SummaryURL(entity); got != "" {
+		t.Errorf("Expected no summary URL, got '%s'", got)
+	}
+}
@@ -0,0 +1,227 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// BulkAction is an action run on the records selected in a table, e.g.
+// "Publish" or "Archive". Register it on a resource; the CRUDHandler routes
+//
+//	POST /{slug}/bulk/{name}   (form field ids[])
+//
+// to it, after a confirmation modal when RequiresDialog is set.
+type BulkAction struct {
+	Name  string
+	Label string
+	Icon  string
+	Color string
+
+	// Confirmation modal
+	RequiresConfirmation bool
+	ModalTitle           string
+	ModalDescription     string
+	ConfirmLabel         string
+
+	// Handler runs the action on all the selected ids at once; ItemHandler on
+	// each of them in turn, reporting the records that failed one by one.
+	Handler     func(ctx context.Context, ids []string) error
+	ItemHandler func(ctx context.Context, id string) error
+
+	// QueueAbove runs selections of more records in the background on the
+	// jobs queue of the panel (0 = always run in the request).
+	QueueAbove int
+
+	// Authorization
+	AuthorizeFunc func(ctx context.Context) bool
+
+	// Notification, e.g. "Published %d records." (%d is the number of
+	// records processed successfully)
+	SuccessMessage string
+}
+
+// Bulk creates a new bulk action.
+func Bulk(name string) *BulkAction {
+	return &BulkAction{
+		Name:  name,
+		Label: name,
+		Color: ColorGray,
+	}
+}
+
+// Handle sets the handler run on all the selected ids at once. Return a
+// *BulkError to report the records that failed.
+func (b *BulkAction) Handle(fn func(ctx context.Context, ids []string) error) *BulkAction {
+	b.Handler = fn
+	return b
+}
+
+// HandleEach sets a handler run on each selected id in turn: the records
+// that fail are reported and the others are still processed.
+func (b *BulkAction) HandleEach(fn func(ctx context.Context, id string) error) *BulkAction {
+	b.ItemHandler = fn
+	return b
+}
+
+// SetLabel sets the label.
+func (b *BulkAction) SetLabel(label string) *BulkAction {
+	b.Label = label
+	return b
+}
+
+// SetIcon sets the icon.
+func (b *BulkAction) SetIcon(icon string) *BulkAction {
+	b.Icon = icon
+	return b
+}
+
+// SetColor sets the color. Use the Color* constants (e.g. actions.ColorDanger).
+func (b *BulkAction) SetColor(color string) *BulkAction {
+	b.Color = color
+	return b
+}
+
+// RequiresDialog enables the confirmation modal.
+func (b *BulkAction) RequiresDialog(title, desc string) *BulkAction {
+	b.RequiresConfirmation = true
+	b.ModalTitle = title
+	b.ModalDescription = desc
+	if b.ConfirmLabel == "" {
+		b.ConfirmLabel = "Confirm"
+	}
+	return b
+}
+
+// InBackground runs selections of more than threshold records on the jobs
+// queue of the panel instead of in the request.
+func (b *BulkAction) InBackground(threshold int) *BulkAction {
+	b.QueueAbove = threshold
+	return b
+}
+
+// Authorize sets the authorization callback.
+func (b *BulkAction) Authorize(fn func(ctx context.Context) bool) *BulkAction {
+	b.AuthorizeFunc = fn
+	return b
+}
+
+// IsAuthorized checks whether the action is authorized.
+func (b *BulkAction) IsAuthorized(ctx context.Context) bool {
+	if b.AuthorizeFunc == nil {
+		return true
+	}
+	return b.AuthorizeFunc(ctx)
+}
+
+// WithSuccessMessage sets the flash message shown on success; %d is
+// replaced by the number of records processed.
+func (b *BulkAction) WithSuccessMessage(msg string) *BulkAction {
+	b.SuccessMessage = msg
+	return b
+}
+
+// Queued reports whether a selection of n records runs in the background.
+func (b *BulkAction) Queued(n int) bool {
+	return b.QueueAbove > 0 && n > b.QueueAbove
+}
+
+// BulkFailure is a record a bulk action failed on.
+type BulkFailure struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// BulkError is returned by a bulk handler to report the records that
+// failed; the others are considered processed.
+type BulkError struct {
+	Failures []BulkFailure
+}
+
+// Error implements error.
+func (e *BulkError) Error() string {
+	return fmt.Sprintf("%d records failed", len(e.Failures))
+}
+
+// Fail records that the action failed on id.
+func (e *BulkError) Fail(id string, err error) {
+	e.Failures = append(e.Failures, BulkFailure{ID: id, Error: err.Error()})
+}
+
+// BulkResult is the outcome of a bulk action.
+type BulkResult struct {
+	Total    int           `json:"total"`
+	Failures []BulkFailure `json:"failures,omitempty"`
+}
+
+// Succeeded returns the number of records processed successfully.
+func (r BulkResult) Succeeded() int {
+	return r.Total - len(r.Failures)
+}
+
+// Message describes the result for a flash message: the success message
+// of the action, or how many records failed and why.
+func (r BulkResult) Message(success string) string {
+	if len(r.Failures) == 0 {
+		if success == "" {
+			success = "%d records processed."
+		}
+		if strings.Contains(success, "%d") {
+			return fmt.Sprintf(success, r.Succeeded())
+		}
+		return success
+	}
+	const maxListed = 5
+	reasons := make([]string, 0, maxListed)
+	for i, f := range r.Failures {
+		if i == maxListed {
+			reasons = append(reasons, fmt.Sprintf("and %d more", len(r.Failures)-maxListed))
+			break
+		}
+		reasons = append(reasons, fmt.Sprintf("#%s: %s", f.ID, f.Error))
+	}
+	return fmt.Sprintf("%d of %d records failed (%s).", len(r.Failures), r.Total, strings.Join(reasons, "; "))
+}
+
+// Run executes the action on ids. progress, when not nil, is called with
+// the number of records handled so far.
+func (b *BulkAction) Run(ctx context.Context, ids []string, progress func(done int)) BulkResult {
+	result := BulkResult{Total: len(ids)}
+	switch {
+	case b.ItemHandler != nil:
+		for i, id := range ids {
+			if err := ctx.Err(); err != nil {
+				for _, rest := range ids[i:] {
+					result.Failures = append(result.Failures, BulkFailure{ID: rest, Error: err.Error()})
+				}
+				return result
+			}
+			if err := b.ItemHandler(ctx, id); err != nil {
+				result.Failures = append(result.Failures, BulkFailure{ID: id, Error: err.Error()})
+			}
+			if progress != nil {
+				progress(i + 1)
+			}
+		}
+	case b.Handler != nil:
+		err := b.Handler(ctx, ids)
+		var bulkErr *BulkError
+		switch {
+		case errors.As(err, &bulkErr):
+			result.Failures = bulkErr.Failures
+		case err != nil:
+			for _, id := range ids {
+				result.Failures = append(result.Failures, BulkFailure{ID: id, Error: err.Error()})
+			}
+		}
+		if progress != nil {
+			progress(len(ids))
+		}
+	default:
+		for _, id := range ids {
+			result.Failures = append(result.Failures, BulkFailure{ID: id, Error: "no handler"})
+		}
+	}
+	return result
+}
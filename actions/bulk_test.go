@@ -0,0 +1,83 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBulk(t *testing.T) {
+	b := Bulk("publish").SetLabel("Publish").SetIcon("send").SetColor(ColorPrimary).
+		RequiresDialog("Publish posts?", "They will be visible to everyone.").
+		InBackground(100)
+
+	if b.Name != "publish" || b.Label != "Publish" || b.Icon != "send" {
+		t.Errorf("unexpected bulk action %+v", b)
+	}
+	if !b.RequiresConfirmation || b.ConfirmLabel != "Confirm" {
+		t.Error("expected RequiresDialog to enable the confirmation modal")
+	}
+	if b.Queued(100) || !b.Queued(101) {
+		t.Error("expected selections above 100 records to be queued")
+	}
+	if Bulk("x").Queued(10000) {
+		t.Error("expected bulk actions to run in the request by default")
+	}
+}
+
+func TestBulkAction_Run_each(t *testing.T) {
+	var done []int
+	b := Bulk("publish").HandleEach(func(_ context.Context, id string) error {
+		if id == "2" {
+			return errors.New("already published")
+		}
+		return nil
+	})
+
+	result := b.Run(context.Background(), []string{"1", "2", "3"}, func(n int) { done = append(done, n) })
+	if result.Total != 3 || result.Succeeded() != 2 {
+		t.Errorf("expected 2 of 3 records processed, got %+v", result)
+	}
+	if len(result.Failures) != 1 || result.Failures[0] != (BulkFailure{ID: "2", Error: "already published"}) {
+		t.Errorf("expected the failure of record 2, got %+v", result.Failures)
+	}
+	if len(done) != 3 || done[2] != 3 {
+		t.Errorf("expected progress after each record, got %v", done)
+	}
+}
+
+func TestBulkAction_Run_all(t *testing.T) {
+	b := Bulk("archive").Handle(func(_ context.Context, ids []string) error {
+		e := &BulkError{}
+		e.Fail(ids[0], errors.New("locked"))
+		return e
+	})
+	if result := b.Run(context.Background(), []string{"7", "8"}, nil); len(result.Failures) != 1 || result.Failures[0].ID != "7" {
+		t.Errorf("expected the failures of the BulkError, got %+v", result)
+	}
+
+	b.Handle(func(context.Context, []string) error { return errors.New("database down") })
+	if result := b.Run(context.Background(), []string{"7", "8"}, nil); result.Succeeded() != 0 {
+		t.Errorf("expected every record to fail on a plain error, got %+v", result)
+	}
+}
+
+func TestBulkResult_Message(t *testing.T) {
+	ok := BulkResult{Total: 4}
+	if got := ok.Message("Published %d posts."); got != "Published 4 posts." {
+		t.Errorf("unexpected message %q", got)
+	}
+	if got := ok.Message(""); got != "4 records processed." {
+		t.Errorf("unexpected default message %q", got)
+	}
+
+	failed := BulkResult{Total: 10}
+	for _, id := range []string{"1", "2", "3", "4", "5", "6"} {
+		failed.Failures = append(failed.Failures, BulkFailure{ID: id, Error: "locked"})
+	}
+	got := failed.Message("Published %d posts.")
+	if !strings.HasPrefix(got, "6 of 10 records failed (#1: locked;") || !strings.Contains(got, "and 1 more") {
+		t.Errorf("unexpected failure message %q", got)
+	}
+}
@@ -18,4 +18,60 @@
 //		SetUrl(func(item any) string {
 //			return fmt.Sprintf("/users/%s/archive", actions.GetItemID(item))
 //		})
+//
+// Destructive confirmations can ask the user to type a phrase, show what the
+// action will affect and submit in the background with a loading state:
+//
+//	purge := actions.New("purge").
+//		RequiresDialog("Purge this project?", "Every build is deleted.").
+//		RequiresPhraseFrom(func(item any) string { return item.(*Project).Name }).
+//		WithSummary(func(item any) string {
+//			return fmt.Sprintf("/projects/%s/delete-summary", actions.GetItemID(item))
+//		}).
+//		SubmitAsync()
+//
+// Bulk actions run on the rows selected in the list. Register them on the
+// resource; failures are reported per record and large selections can run
+// on the jobs queue of the panel:
+//
+//	r.SetBulkActions(actions.Bulk("publish").
+//		SetLabel("Publish").
+//		RequiresDialog("Publish the selected posts?", "").
+//		InBackground(500).
+//		HandleEach(func(ctx context.Context, id string) error {
+//			return posts.Publish(ctx, id)
+//		}))
+//
+// Actions with a form open a modal collecting parameters, validated against
+// the rules of the fields before they reach the handler:
+//
+//	r.SetRowActions(actions.New("change-status").
+//		SetLabel("Change status").
+//		WithForm(form.Schema(form.Select("status").Options(statuses).Required())).
+//		Handle(func(ctx context.Context, item any, data url.Values) error {
+//			return posts.SetStatus(ctx, item, data.Get("status"))
+//		}))
+//
+// Groups gather actions in a dropdown, e.g. the less frequent actions of a
+// row; a group shows only the actions authorized for the item:
+//
+//	r.SetRowActionGroups(actions.Group("More", duplicate, transfer, archive))
+//
+// Rate limits apply to each user, and idempotent actions run once per
+// submitted form, whatever the number of clicks. They are enforced by the
+// Limiter the panel installs in the context (see WithLimiter):
+//
+//	send := actions.New("send-invoice").
+//		WithForm(invoiceForm).
+//		RateLimit(5, time.Hour).
+//		Idempotent().
+//		Handle(sendInvoice)
+//
+// Standalone actions are mounted by the panel under a resource and run the
+// whole lifecycle without a handler of their own (see ServeHTTP):
+//
+//	panel.AddActions(actions.New("send-invoice").
+//		ForResource("orders").
+//		WithSuccessMessage("Invoice sent.").
+//		Handle(sendInvoice))
 package actions
@@ -0,0 +1,126 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/bozz33/sublimeadmin/flash"
+	"github.com/bozz33/sublimeadmin/form"
+	"github.com/bozz33/sublimeadmin/logger"
+)
+
+// ForResource makes the action a standalone endpoint of the resource with
+// the given slug, mounted by the panel (see engine.Panel.AddActions) at
+//
+//	POST /{slug}/{id}/actions/{name}   runs the action on a record
+//	POST /{slug}/actions/{name}        runs it without a record
+//
+// The action becomes a button posting there, unless it already has a URL,
+// so it can also be listed in the row actions of the resource. Its URLs
+// start with BasePath, the path of the panel, set when it is mounted.
+func (a *Action) ForResource(slug string) *Action {
+	a.ResourceSlug = slug
+	a.Type = Button
+	a.Method = "POST"
+	if a.UrlResolver == nil {
+		a.UrlResolver = func(item any) string {
+			if item == nil {
+				return fmt.Sprintf("%s/%s/actions/%s", a.BasePath, slug, a.Name)
+			}
+			return fmt.Sprintf("%s/%s/%s/actions/%s", a.BasePath, slug, getItemID(item), a.Name)
+		}
+	}
+	return a
+}
+
+// DefaultFailureMessage is the flash message of a failed action without a
+// FailureMessage.
+const DefaultFailureMessage = "The action could not be completed."
+
+// FailureText returns the message shown to the user when the action failed
+// with err: its FailureMessage, else DefaultFailureMessage. The error, which
+// may carry internal details, is logged instead.
+func (a *Action) FailureText(ctx context.Context, err error) string {
+	logger.FromContext(ctx).Error("action failed", slog.String("action", a.Name), logger.Err(err))
+	if a.FailureMessage != "" {
+		return a.FailureMessage
+	}
+	return DefaultFailureMessage
+}
+
+// ServeHTTP runs the action for a POST request: authorize → before →
+// handler → after, then a flash message and a redirect. The record the
+// action runs on is taken from the context (see WithItem); the panel puts
+// it there for the routes of ForResource.
+//
+// The posted values reach the handler set with Handle, validated first when
+// the action has a form. Invalid values answer 422, and submissions refused
+// by the Limiter of the context 429 or 409. A failing action redirects
+// with an error flash message (see FailureText).
+func (a *Action) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+	item := ItemFromContext(ctx)
+	if !a.IsAuthorized(ctx, item) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	data := make(url.Values, len(r.PostForm))
+	for key, values := range r.PostForm {
+		if key != "_token" && key != "_method" {
+			data[key] = values
+		}
+	}
+
+	var fieldErrs form.FormErrors
+	var rateErr *RateLimitError
+	switch err := a.Submit(ctx, item, data); {
+	case errors.As(err, &rateErr) || errors.Is(err, ErrDuplicateSubmission) || errors.Is(err, ErrMissingIdempotencyKey):
+		writeLimitError(w, err)
+		return
+	case errors.As(err, &fieldErrs):
+		http.Error(w, fieldErrs.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		flash.Error(r, a.FailureText(ctx, err))
+	case a.SuccessMessage != "":
+		flash.Success(r, a.SuccessMessage)
+	}
+
+	redirect := a.ResolveRedirect(item)
+	if redirect == "" {
+		redirect = r.Header.Get("Referer")
+	}
+	if redirect == "" && a.ResourceSlug != "" {
+		redirect = a.BasePath + "/" + a.ResourceSlug
+	}
+	if redirect == "" {
+		redirect = a.BasePath + "/"
+	}
+	http.Redirect(w, r, redirect, http.StatusSeeOther)
+}
+
+type itemKey struct{}
+
+// WithItem returns a context carrying the record an action runs on.
+func WithItem(ctx context.Context, item any) context.Context {
+	return context.WithValue(ctx, itemKey{}, item)
+}
+
+// ItemFromContext returns the record of the context (see WithItem), or nil.
+func ItemFromContext(ctx context.Context) any {
+	return ctx.Value(itemKey{})
+}
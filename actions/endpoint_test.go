@@ -0,0 +1,96 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestAction_ForResource(t *testing.T) {
+	a := New("send-invoice").ForResource("orders")
+
+	if a.ResourceSlug != "orders" || a.Type != Button || a.Method != "POST" {
+		t.Errorf("unexpected standalone action %+v", a)
+	}
+	if got := a.URL(&MockEntity{ID: 7}); got != "/orders/7/actions/send-invoice" {
+		t.Errorf("unexpected row URL %q", got)
+	}
+	if got := a.URL(nil); got != "/orders/actions/send-invoice" {
+		t.Errorf("unexpected list URL %q", got)
+	}
+
+	a.BasePath = "/admin"
+	if got := a.URL(&MockEntity{ID: 7}); got != "/admin/orders/7/actions/send-invoice" {
+		t.Errorf("unexpected row URL in a panel %q", got)
+	}
+
+	custom := New("send-invoice").SetUrl(func(any) string { return "/custom" }).ForResource("orders")
+	if custom.URL(nil) != "/custom" {
+		t.Error("expected ForResource to keep an existing URL")
+	}
+}
+
+func TestAction_ServeHTTP(t *testing.T) {
+	var steps []string
+	a := New("send-invoice").ForResource("orders").
+		Before(func(context.Context, any) error { steps = append(steps, "before"); return nil }).
+		WithSuccessMessage("Invoice sent.").
+		Handle(func(_ context.Context, item any, data url.Values) error {
+			steps = append(steps, "handle "+GetItemID(item)+" "+data.Encode())
+			return nil
+		})
+
+	req := httptest.NewRequest(http.MethodPost, "/orders/7/actions/send-invoice", strings.NewReader("_token=x&copy=1"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, req.WithContext(WithItem(req.Context(), &MockEntity{ID: 7})))
+
+	if rw.Code != http.StatusSeeOther || rw.Header().Get("Location") != "/orders" {
+		t.Errorf("expected a redirect to the list, got %d %q", rw.Code, rw.Header().Get("Location"))
+	}
+	if strings.Join(steps, ", ") != "before, handle 7 copy=1" {
+		t.Errorf("unexpected lifecycle %v", steps)
+	}
+}
+
+func TestAction_ServeHTTP_refused(t *testing.T) {
+	a := New("send-invoice").
+		Authorize(func(_ context.Context, item any) bool { return item != nil }).
+		Handle(func(context.Context, any, url.Values) error { return errors.New("mail server down") })
+
+	rw := httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rw.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET, got %d", rw.Code)
+	}
+
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", nil))
+	if rw.Code != http.StatusForbidden {
+		t.Errorf("expected 403 without a record, got %d", rw.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Referer", "/orders/7")
+	rw = httptest.NewRecorder()
+	a.ServeHTTP(rw, req.WithContext(WithItem(req.Context(), &MockEntity{ID: 7})))
+	if rw.Code != http.StatusSeeOther || rw.Header().Get("Location") != "/orders/7" {
+		t.Errorf("expected a failed action to redirect back, got %d %q", rw.Code, rw.Header().Get("Location"))
+	}
+}
+
+func TestAction_FailureText(t *testing.T) {
+	err := errors.New("action send-invoice: pq: relation \"invoices\" does not exist")
+	a := New("send-invoice")
+	if got := a.FailureText(context.Background(), err); got != DefaultFailureMessage {
+		t.Errorf("expected the default failure message, got %q", got)
+	}
+	a.WithFailureMessage("The invoice could not be sent.")
+	if got := a.FailureText(context.Background(), err); got != "The invoice could not be sent." {
+		t.Errorf("expected the failure message of the action, got %q", got)
+	}
+}
@@ -0,0 +1,109 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+
+	"github.com/bozz33/sublimeadmin/form"
+)
+
+// WithForm makes the action open a modal form collecting parameters before
+// it runs, e.g. "Change status" with a select and a note:
+//
+//	actions.New("change-status").
+//		SetLabel("Change status").
+//		WithForm(form.Schema(
+//			form.Select("status").Options(...).Required(),
+//			form.Textarea("note"),
+//		)).
+//		Handle(func(ctx context.Context, item any, data url.Values) error {
+//			return setStatus(ctx, item, data.Get("status"), data.Get("note"))
+//		})
+//
+// The values are validated against the rules of the fields (see Submit)
+// before they reach the handler.
+func (a *Action) WithForm(f *form.Form) *Action {
+	a.Form = f
+	a.Type = Button
+	a.Method = "POST"
+	if a.SubmitLabel == "" {
+		a.SubmitLabel = "Submit"
+	}
+	return a
+}
+
+// Handle sets the handler receiving the values of the modal form. item is
+// nil for actions that are not tied to a record.
+func (a *Action) Handle(fn func(ctx context.Context, item any, data url.Values) error) *Action {
+	a.FormHandler = fn
+	return a
+}
+
+// WithSubmitLabel sets the label of the submit button of the modal form.
+func (a *Action) WithSubmitLabel(label string) *Action {
+	a.SubmitLabel = label
+	return a
+}
+
+// HasForm reports whether the action opens a modal form.
+func (a *Action) HasForm() bool {
+	return a.Form != nil
+}
+
+// Submit validates data against the modal form and runs the handler through
+// the action lifecycle (see Execute). Invalid values are reported as a
+// form.FormErrors, with the first error of each field. Valid submissions
+// then go through the Limiter of the context, which may refuse them with
+// a *RateLimitError, ErrMissingIdempotencyKey or ErrDuplicateSubmission.
+// The idempotency key of a submission that fails is released.
+func (a *Action) Submit(ctx context.Context, item any, data url.Values) error {
+	idempotencyKey := data.Get(IdempotencyKeyField)
+	if data.Has(IdempotencyKeyField) {
+		data = maps.Clone(data)
+		data.Del(IdempotencyKeyField)
+	}
+
+	if a.Form != nil {
+		// Validate on a copy: the form is shared by concurrent requests
+		f := form.Schema(a.Form.Schema...)
+		if !f.Validate(formValues(data)) {
+			errs := make(form.FormErrors, len(f.Errors))
+			for field, messages := range f.Errors {
+				errs[field] = messages[0]
+			}
+			return errs
+		}
+	}
+	if a.FormHandler == nil {
+		return fmt.Errorf("action %s: no handler", a.Name)
+	}
+	limiter := LimiterFromContext(ctx)
+	if err := limiter.Check(ctx, a, idempotencyKey); err != nil {
+		return err
+	}
+	err := a.Execute(ctx, item, func() error {
+		return a.FormHandler(ctx, item, data)
+	})
+	if err != nil {
+		_ = limiter.Release(ctx, a, idempotencyKey)
+	}
+	return err
+}
+
+// formValues converts posted values for form.Validate: single values as
+// strings, repeated ones (multi-selects, checkbox lists) as slices.
+func formValues(data url.Values) map[string]any {
+	values := make(map[string]any, len(data))
+	for key, v := range data {
+		switch len(v) {
+		case 0:
+		case 1:
+			values[key] = v[0]
+		default:
+			values[key] = v
+		}
+	}
+	return values
+}
@@ -0,0 +1,63 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/bozz33/sublimeadmin/form"
+)
+
+func TestAction_WithForm(t *testing.T) {
+	a := New("change-status").WithForm(form.Schema(form.Text("note")))
+
+	if !a.HasForm() || a.Type != Button || a.Method != "POST" || a.SubmitLabel != "Submit" {
+		t.Errorf("unexpected form action %+v", a)
+	}
+	if New("edit").HasForm() {
+		t.Error("expected actions without a form")
+	}
+}
+
+func TestAction_Submit(t *testing.T) {
+	var got url.Values
+	a := New("change-status").
+		WithForm(form.Schema(
+			form.Select("status").Required(),
+			form.Text("note"),
+		)).
+		Handle(func(_ context.Context, _ any, data url.Values) error {
+			got = data
+			if data.Get("status") == "locked" {
+				return errors.New("locked")
+			}
+			return nil
+		})
+
+	err := a.Submit(context.Background(), nil, url.Values{"note": {"Ready"}})
+	var fieldErrs form.FormErrors
+	if !errors.As(err, &fieldErrs) || fieldErrs["status"] == "" {
+		t.Fatalf("expected a required error on status, got %v", err)
+	}
+	if got != nil {
+		t.Error("expected the handler not to run with invalid values")
+	}
+
+	if err := a.Submit(context.Background(), nil, url.Values{"status": {"draft"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Get("status") != "draft" {
+		t.Errorf("expected the values to reach the handler, got %v", got)
+	}
+
+	var failed error
+	a.OnFailure(func(_ context.Context, _ any, err error) { failed = err })
+	if err := a.Submit(context.Background(), nil, url.Values{"status": {"locked"}}); err == nil || failed == nil {
+		t.Errorf("expected the error to go through the lifecycle, got %v", err)
+	}
+
+	if err := New("x").WithForm(form.Schema()).Submit(context.Background(), nil, nil); err == nil {
+		t.Error("expected an error without a handler")
+	}
+}
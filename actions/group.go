@@ -23,6 +23,15 @@ func NewGroup(label string) *ActionGroup {
 	}
 }
 
+// Group creates an ActionGroup holding acts, e.g. to gather the less
+// frequent actions of a row in a dropdown and keep the table narrow:
+//
+//	actions.Group("More", actions.RestoreAction(url), actions.ForceDeleteAction(url)).
+//		SetIcon("more_horiz")
+func Group(label string, acts ...*Action) *ActionGroup {
+	return NewGroup(label).Add(acts...)
+}
+
 // Add appends one or more actions to the group.
 func (g *ActionGroup) Add(acts ...*Action) *ActionGroup {
 	g.items = append(g.items, acts...)
@@ -61,8 +70,23 @@ func (g *ActionGroup) Items() []*Action {
 	return g.items
 }
 
+// VisibleItems returns the actions of the group authorized for the given
+// context and item; none when the group itself is not.
+func (g *ActionGroup) VisibleItems(ctx context.Context, item any) []*Action {
+	if !g.IsAuthorized(ctx, item) {
+		return nil
+	}
+	visible := make([]*Action, 0, len(g.items))
+	for _, a := range g.items {
+		if a.IsAuthorized(ctx, item) {
+			visible = append(visible, a)
+		}
+	}
+	return visible
+}
+
 // MoreActionsGroup creates a standard "More" group with a vertical ellipsis icon.
 // Usage: actions.MoreActionsGroup(actions.RestoreAction(url), actions.ForceDeleteAction(url))
 func MoreActionsGroup(acts ...*Action) *ActionGroup {
-	return NewGroup("More").SetIcon("more_vert").Add(acts...)
+	return Group("More", acts...)
 }
@@ -231,3 +231,29 @@ func TestMoreActionsGroup_is_authorized_no_func(t *testing.T) {
 		t.Error("expected IsAuthorized()=true by default for MoreActionsGroup")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Group / VisibleItems tests
+// ---------------------------------------------------------------------------
+
+func TestGroup_constructor(t *testing.T) {
+	g := Group("More", New("restore"), New("purge"))
+	if g.Label != "More" || g.Icon != "more_vert" || len(g.Items()) != 2 {
+		t.Errorf("unexpected group %+v", g)
+	}
+}
+
+func TestGroup_VisibleItems(t *testing.T) {
+	hidden := New("purge").Authorize(func(_ context.Context, _ any) bool { return false })
+	g := Group("More", New("restore"), hidden)
+
+	visible := g.VisibleItems(context.Background(), nil)
+	if len(visible) != 1 || visible[0].Name != "restore" {
+		t.Errorf("expected only the authorized action, got %d items", len(visible))
+	}
+
+	g.Authorize(func(_ context.Context, _ any) bool { return false })
+	if len(g.VisibleItems(context.Background(), nil)) != 0 {
+		t.Error("expected no items when the group is not authorized")
+	}
+}
@@ -0,0 +1,181 @@
+package actions
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/auth"
+)
+
+// IdempotencyKeyField is the form field holding the idempotency key of an
+// idempotent action (see Action.Idempotent).
+const IdempotencyKeyField = "_idempotency_key"
+
+// ErrDuplicateSubmission is returned when an idempotent action is
+// submitted again with the same key, e.g. after a double click.
+var ErrDuplicateSubmission = errors.New("actions: duplicate submission")
+
+// ErrMissingIdempotencyKey is returned when an idempotent action is
+// submitted without its idempotency key.
+var ErrMissingIdempotencyKey = errors.New("actions: missing idempotency key")
+
+// RateLimitError is returned when an action exceeds its rate limit (see
+// Action.RateLimit) for the current user.
+type RateLimitError struct {
+	Action     string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("action %s: too many attempts, retry in %s", e.Action, e.RetryAfter.Round(time.Second))
+}
+
+// LimitStore keeps the state of a Limiter. The panel backs it with the
+// rate limit and idempotency stores of the middleware package.
+type LimitStore interface {
+	// Allow reports whether key may run again under a limit of limit calls
+	// per window and, when it may not, how long to wait.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error)
+	// Claim records key for ttl. It reports false when key is already
+	// recorded.
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Release forgets a claimed key, so it can be claimed again.
+	Release(ctx context.Context, key string) error
+}
+
+// LimiterConfig configures a Limiter.
+type LimiterConfig struct {
+	// Store keeps the calls and the idempotency keys. Required.
+	Store LimitStore
+
+	// KeyFunc identifies the caller the limits apply to. Default: the ID
+	// of the authenticated user, or "guest".
+	KeyFunc func(ctx context.Context) string
+
+	// IdempotencyTTL is how long an idempotency key is remembered.
+	// Default: 24 hours.
+	IdempotencyTTL time.Duration
+}
+
+// DefaultLimiterConfig returns the default configuration, without a store.
+func DefaultLimiterConfig() *LimiterConfig {
+	return &LimiterConfig{
+		KeyFunc:        userKey,
+		IdempotencyTTL: 24 * time.Hour,
+	}
+}
+
+// Limiter enforces the rate limits (see Action.RateLimit) and the
+// idempotency keys (see Action.Idempotent) of actions. The panel installs
+// one in the context of its requests (see WithLimiter); without it,
+// actions are not limited.
+type Limiter struct {
+	config *LimiterConfig
+}
+
+// NewLimiter creates a Limiter with the default configuration.
+func NewLimiter(store LimitStore) *Limiter {
+	config := DefaultLimiterConfig()
+	config.Store = store
+	return NewLimiterWithConfig(config)
+}
+
+// NewLimiterWithConfig creates a Limiter with a custom configuration.
+func NewLimiterWithConfig(config *LimiterConfig) *Limiter {
+	defaults := DefaultLimiterConfig()
+	if config.KeyFunc == nil {
+		config.KeyFunc = defaults.KeyFunc
+	}
+	if config.IdempotencyTTL == 0 {
+		config.IdempotencyTTL = defaults.IdempotencyTTL
+	}
+	return &Limiter{config: config}
+}
+
+// Check is called before action runs. It returns a *RateLimitError when
+// the current user exceeded the rate limit of the action, then, for an
+// idempotent action, ErrMissingIdempotencyKey without idempotencyKey and
+// ErrDuplicateSubmission when it was already submitted. The key is claimed
+// once allowed: call Release when the action then fails, so the user can
+// retry. A nil Limiter allows everything.
+func (l *Limiter) Check(ctx context.Context, action *Action, idempotencyKey string) error {
+	if l == nil || l.config.Store == nil {
+		return nil
+	}
+	caller := l.config.KeyFunc(ctx)
+
+	if action.RateLimitMax > 0 && action.RateLimitWindow > 0 {
+		allowed, retryAfter, err := l.config.Store.Allow(ctx, "action:"+action.Name+":"+caller, action.RateLimitMax, action.RateLimitWindow)
+		if err != nil {
+			return fmt.Errorf("action %s: %w", action.Name, err)
+		}
+		if !allowed {
+			return &RateLimitError{Action: action.Name, RetryAfter: retryAfter}
+		}
+	}
+
+	if action.IdempotencyRequired {
+		if idempotencyKey == "" {
+			return ErrMissingIdempotencyKey
+		}
+		claimed, err := l.config.Store.Claim(ctx, l.idempotencyKey(ctx, action, idempotencyKey), l.config.IdempotencyTTL)
+		if err != nil {
+			return fmt.Errorf("action %s: %w", action.Name, err)
+		}
+		if !claimed {
+			return ErrDuplicateSubmission
+		}
+	}
+	return nil
+}
+
+// Release forgets the idempotency key claimed by Check for a submission of
+// action that failed, so it can be submitted again.
+func (l *Limiter) Release(ctx context.Context, action *Action, idempotencyKey string) error {
+	if l == nil || l.config.Store == nil || !action.IdempotencyRequired || idempotencyKey == "" {
+		return nil
+	}
+	if err := l.config.Store.Release(ctx, l.idempotencyKey(ctx, action, idempotencyKey)); err != nil {
+		return fmt.Errorf("action %s: %w", action.Name, err)
+	}
+	return nil
+}
+
+// idempotencyKey returns the key of the store for a submission of action
+// by the current user.
+func (l *Limiter) idempotencyKey(ctx context.Context, action *Action, key string) string {
+	return "action:" + action.Name + ":" + l.config.KeyFunc(ctx) + ":" + key
+}
+
+type limiterKey struct{}
+
+// WithLimiter returns a context enforcing the limits of the actions with l.
+func WithLimiter(ctx context.Context, l *Limiter) context.Context {
+	return context.WithValue(ctx, limiterKey{}, l)
+}
+
+// LimiterFromContext returns the Limiter of the context, or nil.
+func LimiterFromContext(ctx context.Context) *Limiter {
+	l, _ := ctx.Value(limiterKey{}).(*Limiter)
+	return l
+}
+
+// NewIdempotencyKey returns a random key for the form of an idempotent
+// action.
+func NewIdempotencyKey() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// userKey identifies the authenticated user.
+func userKey(ctx context.Context) string {
+	if user := auth.UserFromContext(ctx); !user.IsGuest() {
+		return fmt.Sprintf("user:%d", user.ID)
+	}
+	return "guest"
+}
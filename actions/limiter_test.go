@@ -0,0 +1,225 @@
+package actions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/form"
+)
+
+// countingStore allows limit calls per key and records the claimed keys.
+type countingStore struct {
+	calls   map[string]int
+	claimed map[string]bool
+}
+
+func newCountingStore() *countingStore {
+	return &countingStore{calls: map[string]int{}, claimed: map[string]bool{}}
+}
+
+func (s *countingStore) Allow(_ context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	s.calls[key]++
+	if s.calls[key] > limit {
+		return false, window / time.Duration(limit), nil
+	}
+	return true, 0, nil
+}
+
+func (s *countingStore) Claim(_ context.Context, key string, _ time.Duration) (bool, error) {
+	if s.claimed[key] {
+		return false, nil
+	}
+	s.claimed[key] = true
+	return true, nil
+}
+
+func (s *countingStore) Release(_ context.Context, key string) error {
+	delete(s.claimed, key)
+	return nil
+}
+
+func TestLimiter_Check_rate_limit(t *testing.T) {
+	ctx := context.Background()
+	store := newCountingStore()
+	limiter := NewLimiterWithConfig(&LimiterConfig{
+		Store:   store,
+		KeyFunc: func(context.Context) string { return "user:7" },
+	})
+	send := New("send-invoice").RateLimit(2, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.Check(ctx, send, ""); err != nil {
+			t.Fatalf("call %d: unexpected error %v", i+1, err)
+		}
+	}
+	err := limiter.Check(ctx, send, "")
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) || rateErr.RetryAfter != 30*time.Minute {
+		t.Fatalf("expected a RateLimitError, got %v", err)
+	}
+	if store.calls["action:send-invoice:user:7"] != 3 {
+		t.Errorf("expected the calls to be counted per action and user, got %v", store.calls)
+	}
+
+	if err := limiter.Check(ctx, New("archive"), ""); err != nil {
+		t.Errorf("expected actions without a rate limit to run, got %v", err)
+	}
+	if len(store.calls) != 1 {
+		t.Errorf("expected no call to be counted without a rate limit, got %v", store.calls)
+	}
+
+	var nilLimiter *Limiter
+	if err := nilLimiter.Check(ctx, send, ""); err != nil {
+		t.Errorf("expected a nil Limiter to allow everything, got %v", err)
+	}
+}
+
+func TestLimiter_Check_idempotency(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewLimiter(newCountingStore())
+	send := New("send-invoice").Idempotent()
+
+	if err := limiter.Check(ctx, send, "abc"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := limiter.Check(ctx, send, "abc"); !errors.Is(err, ErrDuplicateSubmission) {
+		t.Errorf("expected ErrDuplicateSubmission, got %v", err)
+	}
+	if err := limiter.Check(ctx, send, "def"); err != nil {
+		t.Errorf("expected another key to run, got %v", err)
+	}
+	if err := limiter.Check(ctx, New("archive"), "abc"); err != nil {
+		t.Errorf("expected keys to be ignored for actions that are not idempotent, got %v", err)
+	}
+	if err := limiter.Check(ctx, send, ""); !errors.Is(err, ErrMissingIdempotencyKey) {
+		t.Errorf("expected ErrMissingIdempotencyKey, got %v", err)
+	}
+
+	if err := limiter.Release(ctx, send, "abc"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := limiter.Check(ctx, send, "abc"); err != nil {
+		t.Errorf("expected a released key to run again, got %v", err)
+	}
+}
+
+func TestLimiter_Check_rate_limit_keeps_key(t *testing.T) {
+	ctx := context.Background()
+	store := newCountingStore()
+	limiter := NewLimiter(store)
+	send := New("send-invoice").RateLimit(1, time.Hour).Idempotent()
+
+	if err := limiter.Check(ctx, send, "abc"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	var rateErr *RateLimitError
+	if err := limiter.Check(ctx, send, "def"); !errors.As(err, &rateErr) {
+		t.Fatalf("expected a RateLimitError, got %v", err)
+	}
+	if len(store.claimed) != 1 {
+		t.Errorf("expected a limited submission not to claim its key, got %v", store.claimed)
+	}
+}
+
+func TestAction_Submit_limited(t *testing.T) {
+	ctx := WithLimiter(context.Background(), NewLimiter(newCountingStore()))
+	var sent []url.Values
+	send := New("send-invoice").
+		WithForm(form.Schema(form.Email("to").Required())).
+		Idempotent().
+		Handle(func(_ context.Context, _ any, data url.Values) error {
+			sent = append(sent, data)
+			return nil
+		})
+
+	data := url.Values{"to": {"jane@example.com"}, IdempotencyKeyField: {"abc"}}
+	if err := send.Submit(ctx, nil, data); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := send.Submit(ctx, nil, data); !errors.Is(err, ErrDuplicateSubmission) {
+		t.Errorf("expected the second submission to be refused, got %v", err)
+	}
+	if len(sent) != 1 {
+		t.Fatalf("expected the handler to run once, got %d", len(sent))
+	}
+	if sent[0].Has(IdempotencyKeyField) || !data.Has(IdempotencyKeyField) {
+		t.Error("expected the key to be removed from a copy of the values")
+	}
+
+	// Invalid values do not use the key up
+	if err := send.Submit(ctx, nil, url.Values{IdempotencyKeyField: {"def"}}); err == nil {
+		t.Fatal("expected a validation error")
+	}
+	if err := send.Submit(ctx, nil, url.Values{"to": {"jane@example.com"}, IdempotencyKeyField: {"def"}}); err != nil {
+		t.Errorf("expected the key to be usable after a validation error, got %v", err)
+	}
+
+	if err := send.Submit(ctx, nil, url.Values{"to": {"jane@example.com"}}); !errors.Is(err, ErrMissingIdempotencyKey) {
+		t.Errorf("expected a submission without key to be refused, got %v", err)
+	}
+}
+
+func TestAction_Submit_releases_key_on_failure(t *testing.T) {
+	ctx := WithLimiter(context.Background(), NewLimiter(newCountingStore()))
+	fail := true
+	send := New("send-invoice").
+		Idempotent().
+		Handle(func(context.Context, any, url.Values) error {
+			if fail {
+				return errors.New("smtp down")
+			}
+			return nil
+		})
+
+	data := url.Values{IdempotencyKeyField: {"abc"}}
+	if err := send.Submit(ctx, nil, data); err == nil {
+		t.Fatal("expected the handler error")
+	}
+	fail = false
+	if err := send.Submit(ctx, nil, data); err != nil {
+		t.Errorf("expected the key to be usable after a failure, got %v", err)
+	}
+}
+
+func TestModalAction_ServeHTTP_POST_limited(t *testing.T) {
+	m := NewModal("archive").
+		WithForm("/items/1/archive", ModalField{Name: "reason", Type: "text"}).
+		RateLimit(3, 3*time.Minute).
+		Idempotent()
+	ctx := WithLimiter(context.Background(), NewLimiter(newCountingStore()))
+
+	post := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/items/1/archive", strings.NewReader(url.Values{IdempotencyKeyField: {key}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rw := httptest.NewRecorder()
+		m.ServeHTTP(rw, req.WithContext(ctx))
+		return rw
+	}
+
+	if rw := post("abc"); rw.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d", rw.Code)
+	}
+	if rw := post("abc"); rw.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a duplicate submission, got %d", rw.Code)
+	}
+	if rw := post(""); rw.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a missing key, got %d", rw.Code)
+	}
+	rw := post("def")
+	if rw.Code != http.StatusTooManyRequests || rw.Header().Get("Retry-After") != "60" {
+		t.Errorf("expected 429 with Retry-After, got %d %q", rw.Code, rw.Header().Get("Retry-After"))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/items/1/archive", nil)
+	get := httptest.NewRecorder()
+	m.ServeHTTP(get, req)
+	if !strings.Contains(get.Body.String(), `name="`+IdempotencyKeyField+`"`) {
+		t.Error("expected the form to carry an idempotency key")
+	}
+}
@@ -2,9 +2,15 @@ package actions
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/flash"
 )
 
 // ModalSize defines the size of a modal dialog.
@@ -168,6 +174,18 @@ func (m *ModalAction) WithSuccessMessage(msg string) *ModalAction {
 	return m
 }
 
+// RateLimit sets a rate limit per user (fluent, returns *ModalAction).
+func (m *ModalAction) RateLimit(max int, window time.Duration) *ModalAction {
+	m.Action.RateLimit(max, window)
+	return m
+}
+
+// Idempotent makes the form of the modal carry an idempotency key (fluent, returns *ModalAction).
+func (m *ModalAction) Idempotent() *ModalAction {
+	m.Action.Idempotent()
+	return m
+}
+
 // ServeHTTP handles modal rendering (GET) and form submission (POST).
 //
 //   - GET  → returns the modal HTML fragment (form or confirmation dialog)
@@ -185,13 +203,26 @@ func (m *ModalAction) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "bad request", http.StatusBadRequest)
 			return
 		}
+		if phrase := m.Action.ConfirmPhrase; phrase != "" && strings.TrimSpace(r.FormValue(ConfirmPhraseField)) != phrase {
+			http.Error(w, fmt.Sprintf("type %q to confirm", phrase), http.StatusBadRequest)
+			return
+		}
+		limiter, key := LimiterFromContext(r.Context()), r.FormValue(IdempotencyKeyField)
+		if err := limiter.Check(r.Context(), m.Action, key); err != nil {
+			writeLimitError(w, err)
+			return
+		}
 		// Execute BeforeFunc if configured
 		if m.Action.BeforeFunc != nil {
 			if err := m.Action.BeforeFunc(r.Context(), nil); err != nil {
+				_ = limiter.Release(r.Context(), m.Action, key)
 				http.Error(w, m.Action.FailureMessage, http.StatusInternalServerError)
 				return
 			}
 		}
+		if m.Action.SuccessMessage != "" {
+			flash.Success(r, m.Action.SuccessMessage)
+		}
 		// Redirect to FormAction or Referer
 		redirectTo := m.FormAction
 		if redirectTo == "" {
@@ -230,7 +261,7 @@ func (m *ModalAction) renderModalFragment(w http.ResponseWriter, r *http.Request
 		panelClass = "h-full " + sizeClass + " bg-white dark:bg-gray-800 shadow-xl overflow-y-auto"
 	}
 
-	fmt.Fprintf(w, `<div id="modal-fragment" class="%s">`, wrapperClass)
+	fmt.Fprintf(w, `<div id="modal-fragment" class="%s" data-confirm-dialog data-confirm-phrase="%s">`, wrapperClass, htmlEscape(m.Action.ConfirmPhrase))
 	fmt.Fprintf(w, `<div class="%s">`, panelClass)
 
 	// Header
@@ -240,7 +271,7 @@ func (m *ModalAction) renderModalFragment(w http.ResponseWriter, r *http.Request
 	}
 	fmt.Fprintf(w, `<div class="flex items-center justify-between px-6 py-4 border-b border-gray-200 dark:border-gray-700">`)
 	fmt.Fprintf(w, `<h2 class="text-lg font-semibold text-gray-900 dark:text-white">%s</h2>`, htmlEscape(title))
-	fmt.Fprintf(w, `<button type="button" onclick="document.getElementById('modal-fragment').remove()" class="p-2 rounded-lg hover:bg-gray-100 dark:hover:bg-gray-700 text-gray-400 hover:text-gray-600 transition-colors"><span class="material-icons-outlined">close</span></button>`)
+	fmt.Fprintf(w, `<button type="button" x-data x-on:click="document.getElementById('modal-fragment').remove()" class="p-2 rounded-lg hover:bg-gray-100 dark:hover:bg-gray-700 text-gray-400 hover:text-gray-600 transition-colors"><span class="material-icons-outlined">close</span></button>`)
 	fmt.Fprintf(w, `</div>`)
 
 	// Body
@@ -251,13 +282,15 @@ func (m *ModalAction) renderModalFragment(w http.ResponseWriter, r *http.Request
 
 	// Form with fields
 	if len(m.FormFields) > 0 {
-		fmt.Fprintf(w, `<form method="POST" action="%s" class="space-y-4">`, htmlEscape(action))
+		fmt.Fprintf(w, `<form method="POST" action="%s" class="space-y-4" data-confirm-form>`, htmlEscape(action))
 		if method != "POST" {
 			fmt.Fprintf(w, `<input type="hidden" name="_method" value="%s"/>`, htmlEscape(method))
 		}
+		renderIdempotencyKeyHTML(w, m.Action)
 		for _, field := range m.FormFields {
 			renderModalFieldHTML(w, field)
 		}
+		renderConfirmPhraseHTML(w, m.Action.ConfirmPhrase)
 		fmt.Fprintf(w, `<div class="flex items-center justify-end gap-3 pt-4 border-t border-gray-200 dark:border-gray-700">`)
 		cancelLabel := m.Action.CancelLabel
 		if cancelLabel == "" {
@@ -267,8 +300,8 @@ func (m *ModalAction) renderModalFragment(w http.ResponseWriter, r *http.Request
 		if confirmLabel == "" {
 			confirmLabel = "Submit"
 		}
-		fmt.Fprintf(w, `<button type="button" onclick="document.getElementById('modal-fragment').remove()" class="px-4 py-2 text-sm font-medium text-gray-700 dark:text-gray-300 border border-gray-300 dark:border-gray-600 rounded-xl hover:bg-gray-50 dark:hover:bg-gray-700 transition-colors">%s</button>`, htmlEscape(cancelLabel))
-		fmt.Fprintf(w, `<button type="submit" class="px-4 py-2 text-sm font-semibold text-white bg-primary-600 hover:bg-primary-700 rounded-xl transition-colors">%s</button>`, htmlEscape(confirmLabel))
+		fmt.Fprintf(w, `<button type="button" x-data x-on:click="document.getElementById('modal-fragment').remove()" class="px-4 py-2 text-sm font-medium text-gray-700 dark:text-gray-300 border border-gray-300 dark:border-gray-600 rounded-xl hover:bg-gray-50 dark:hover:bg-gray-700 transition-colors">%s</button>`, htmlEscape(cancelLabel))
+		fmt.Fprintf(w, `<button type="submit" class="px-4 py-2 text-sm font-semibold text-white bg-primary-600 hover:bg-primary-700 rounded-xl transition-colors" data-confirm-submit%s>%s</button>`, disabledIf(m.Action.ConfirmPhrase != ""), htmlEscape(confirmLabel))
 		fmt.Fprintf(w, `</div></form>`)
 	} else if m.Action.RequiresConfirmation {
 		// Pure confirmation dialog (no form fields)
@@ -276,6 +309,11 @@ func (m *ModalAction) renderModalFragment(w http.ResponseWriter, r *http.Request
 		fmt.Fprintf(w, `<div class="w-12 h-12 bg-red-100 dark:bg-red-900/30 rounded-full flex items-center justify-center flex-shrink-0"><span class="material-icons-outlined text-red-500 text-2xl">warning</span></div>`)
 		fmt.Fprintf(w, `<div class="flex-1"><p class="text-sm text-gray-600 dark:text-gray-400">%s</p></div>`, htmlEscape(m.Action.ModalDescription))
 		fmt.Fprintf(w, `</div>`)
+		if m.Action.ConfirmPhrase != "" {
+			fmt.Fprintf(w, `<div class="mt-4">`)
+			renderConfirmPhraseHTML(w, m.Action.ConfirmPhrase, "modal-fragment-form")
+			fmt.Fprintf(w, `</div>`)
+		}
 		fmt.Fprintf(w, `<div class="flex items-center justify-end gap-3 mt-6">`)
 		cancelLabel := m.Action.CancelLabel
 		if cancelLabel == "" {
@@ -289,12 +327,13 @@ func (m *ModalAction) renderModalFragment(w http.ResponseWriter, r *http.Request
 		if m.Action.Color != "danger" {
 			colorClass = "bg-primary-600 hover:bg-primary-700"
 		}
-		fmt.Fprintf(w, `<button type="button" onclick="document.getElementById('modal-fragment').remove()" class="px-4 py-2 text-sm font-medium text-gray-700 dark:text-gray-300 border border-gray-300 dark:border-gray-600 rounded-xl hover:bg-gray-50 dark:hover:bg-gray-700 transition-colors">%s</button>`, htmlEscape(cancelLabel))
-		fmt.Fprintf(w, `<form method="POST" action="%s" style="display:inline">`, htmlEscape(action))
+		fmt.Fprintf(w, `<button type="button" x-data x-on:click="document.getElementById('modal-fragment').remove()" class="px-4 py-2 text-sm font-medium text-gray-700 dark:text-gray-300 border border-gray-300 dark:border-gray-600 rounded-xl hover:bg-gray-50 dark:hover:bg-gray-700 transition-colors">%s</button>`, htmlEscape(cancelLabel))
+		fmt.Fprintf(w, `<form id="modal-fragment-form" method="POST" action="%s" style="display:inline" data-confirm-form>`, htmlEscape(action))
 		if method != "POST" {
 			fmt.Fprintf(w, `<input type="hidden" name="_method" value="%s"/>`, htmlEscape(method))
 		}
-		fmt.Fprintf(w, `<button type="submit" class="px-4 py-2 text-sm font-semibold text-white %s rounded-xl transition-colors">%s</button>`, colorClass, htmlEscape(confirmLabel))
+		renderIdempotencyKeyHTML(w, m.Action)
+		fmt.Fprintf(w, `<button type="submit" class="px-4 py-2 text-sm font-semibold text-white %s rounded-xl transition-colors" data-confirm-submit%s>%s</button>`, colorClass, disabledIf(m.Action.ConfirmPhrase != ""), htmlEscape(confirmLabel))
 		fmt.Fprintf(w, `</form></div>`)
 	}
 
@@ -341,7 +380,7 @@ func (m *ModalAction) renderWizardFragment(w http.ResponseWriter, r *http.Reques
 	// Header
 	fmt.Fprintf(w, `<div class="flex items-center justify-between px-6 py-4 border-b border-gray-200 dark:border-gray-700">`)
 	fmt.Fprintf(w, `<h2 class="text-lg font-semibold text-gray-900 dark:text-white">%s</h2>`, htmlEscape(title))
-	fmt.Fprintf(w, `<button type="button" onclick="document.getElementById('modal-fragment').remove()" class="p-2 rounded-lg hover:bg-gray-100 dark:hover:bg-gray-700 text-gray-400 hover:text-gray-600 transition-colors"><span class="material-icons-outlined">close</span></button>`)
+	fmt.Fprintf(w, `<button type="button" x-data x-on:click="document.getElementById('modal-fragment').remove()" class="p-2 rounded-lg hover:bg-gray-100 dark:hover:bg-gray-700 text-gray-400 hover:text-gray-600 transition-colors"><span class="material-icons-outlined">close</span></button>`)
 	fmt.Fprintf(w, `</div>`)
 
 	// Alpine.js step controller
@@ -393,6 +432,7 @@ func (m *ModalAction) renderWizardFragment(w http.ResponseWriter, r *http.Reques
 	if method != "POST" {
 		fmt.Fprintf(w, `<input type="hidden" name="_method" value="%s"/>`, htmlEscape(method))
 	}
+	renderIdempotencyKeyHTML(w, m.Action)
 
 	// Step field panels
 	for i, step := range m.Steps {
@@ -410,7 +450,7 @@ func (m *ModalAction) renderWizardFragment(w http.ResponseWriter, r *http.Reques
 	fmt.Fprintf(w, `<div x-show="step === 0"></div>`) // spacer
 	// Right side: Cancel + Next/Submit
 	fmt.Fprintf(w, `<div class="flex items-center gap-3">`)
-	fmt.Fprintf(w, `<button type="button" onclick="document.getElementById('modal-fragment').remove()" class="px-4 py-2 text-sm font-medium text-gray-700 dark:text-gray-300 border border-gray-300 dark:border-gray-600 rounded-xl hover:bg-gray-50 dark:hover:bg-gray-700 transition-colors">%s</button>`, htmlEscape(cancelLabel))
+	fmt.Fprintf(w, `<button type="button" x-data x-on:click="document.getElementById('modal-fragment').remove()" class="px-4 py-2 text-sm font-medium text-gray-700 dark:text-gray-300 border border-gray-300 dark:border-gray-600 rounded-xl hover:bg-gray-50 dark:hover:bg-gray-700 transition-colors">%s</button>`, htmlEscape(cancelLabel))
 	fmt.Fprintf(w, `<button type="button" x-show="step < %d" @click="step++" class="px-4 py-2 text-sm font-semibold text-white bg-primary-600 hover:bg-primary-700 rounded-xl transition-colors">Next</button>`, N-1)
 	fmt.Fprintf(w, `<button type="submit" x-show="step === %d" class="px-4 py-2 text-sm font-semibold text-white bg-primary-600 hover:bg-primary-700 rounded-xl transition-colors">%s</button>`, N-1, htmlEscape(confirmLabel))
 	fmt.Fprintf(w, `</div>`)
@@ -471,6 +511,60 @@ func renderModalFieldHTML(w http.ResponseWriter, f ModalField) {
 	fmt.Fprintf(w, `</div>`)
 }
 
+// renderIdempotencyKeyHTML writes the idempotency key of an idempotent
+// action (see Action.Idempotent).
+func renderIdempotencyKeyHTML(w http.ResponseWriter, a *Action) {
+	if a.IdempotencyRequired {
+		fmt.Fprintf(w, `<input type="hidden" name="%s" value="%s"/>`, IdempotencyKeyField, NewIdempotencyKey())
+	}
+}
+
+// writeLimitError answers a submission refused by the Limiter.
+func writeLimitError(w http.ResponseWriter, err error) {
+	var rateErr *RateLimitError
+	switch {
+	case errors.As(err, &rateErr):
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateErr.RetryAfter.Seconds()))))
+		http.Error(w, "too many attempts, please try again later", http.StatusTooManyRequests)
+	case errors.Is(err, ErrDuplicateSubmission):
+		http.Error(w, "this form was already submitted", http.StatusConflict)
+	case errors.Is(err, ErrMissingIdempotencyKey):
+		http.Error(w, "missing idempotency key", http.StatusBadRequest)
+	default:
+		http.Error(w, "the action could not be checked", http.StatusInternalServerError)
+	}
+}
+
+// ConfirmPhraseField is the form field holding the phrase typed to confirm
+// an action (see Action.RequiresPhrase).
+const ConfirmPhraseField = "confirm_phrase"
+
+// renderConfirmPhraseHTML writes the input in which the user types the
+// confirmation phrase. The submit button stays disabled until it matches
+// (Confirm in app.js); the server checks it again. form associates the
+// input with a form it is not nested in.
+func renderConfirmPhraseHTML(w http.ResponseWriter, phrase string, form ...string) {
+	if phrase == "" {
+		return
+	}
+	formAttr := ""
+	if len(form) > 0 {
+		formAttr = fmt.Sprintf(` form="%s"`, htmlEscape(form[0]))
+	}
+	fmt.Fprintf(w, `<div class="space-y-1">`)
+	fmt.Fprintf(w, `<label for="modal-confirm-phrase" class="block text-sm text-gray-700 dark:text-gray-300">Type <strong class="font-semibold select-all">%s</strong> to confirm.</label>`, htmlEscape(phrase))
+	fmt.Fprintf(w, `<input type="text" id="modal-confirm-phrase" name="%s"%s autocomplete="off" spellcheck="false" data-confirm-phrase-input class="block w-full rounded-xl border border-gray-300 dark:border-gray-600 bg-white dark:bg-gray-700 px-3 py-2 text-sm text-gray-900 dark:text-white focus:outline-none focus:ring-2 focus:ring-red-500 focus:border-red-500"/>`, ConfirmPhraseField, formAttr)
+	fmt.Fprintf(w, `</div>`)
+}
+
+// disabledIf returns the disabled attribute when cond is true.
+func disabledIf(cond bool) string {
+	if cond {
+		return " disabled"
+	}
+	return ""
+}
+
 // modalSizeClass returns the Tailwind width class for a modal size.
 func modalSizeClass(size ModalSize) string {
 	switch size {
@@ -493,6 +587,12 @@ func htmlEscape(s string) string {
 	return r.Replace(s)
 }
 
+// RequiresPhrase asks the user to type phrase before confirming (fluent, returns *ModalAction).
+func (m *ModalAction) RequiresPhrase(phrase string) *ModalAction {
+	m.Action.RequiresPhrase(phrase)
+	return m
+}
+
 // ConfirmAction creates a pre-configured delete confirmation modal action.
 func ConfirmAction(name, title, description string) *ModalAction {
 	m := NewModal(name)
@@ -213,6 +213,33 @@ func TestModalAction_ServeHTTP_POST_fallback_root(t *testing.T) {
 	}
 }
 
+func TestModalAction_ServeHTTP_confirm_phrase(t *testing.T) {
+	m := ConfirmAction("purge", "Purge the cache?", "Every cached page is dropped.").RequiresPhrase("purge")
+	m.FormAction = "/cache"
+
+	req := httptest.NewRequest(http.MethodGet, "/modal", nil)
+	rw := httptest.NewRecorder()
+	m.ServeHTTP(rw, req)
+	body := rw.Body.String()
+	if !strings.Contains(body, `name="confirm_phrase" form="modal-fragment-form"`) {
+		t.Error("expected the confirmation phrase input")
+	}
+	if !strings.Contains(body, `data-confirm-submit disabled`) {
+		t.Error("expected the confirm button disabled until the phrase is typed")
+	}
+
+	for phrase, want := range map[string]int{"": http.StatusBadRequest, "purg": http.StatusBadRequest, " purge ": http.StatusSeeOther} {
+		form := url.Values{ConfirmPhraseField: {phrase}}
+		req = httptest.NewRequest(http.MethodPost, "/modal", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rw = httptest.NewRecorder()
+		m.ServeHTTP(rw, req)
+		if rw.Code != want {
+			t.Errorf("phrase %q: expected %d, got %d", phrase, want, rw.Code)
+		}
+	}
+}
+
 // ---------------------------------------------------------------------------
 // ServeHTTP — method not allowed
 // ---------------------------------------------------------------------------
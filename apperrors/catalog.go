@@ -0,0 +1,138 @@
+package apperrors
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Stable machine-readable error codes. They are part of the API contract:
+// clients may switch on them, so existing codes must never change.
+const (
+	CodeBadRequest         = "BAD_REQUEST"
+	CodeUnauthorized       = "UNAUTHORIZED"
+	CodeForbidden          = "FORBIDDEN"
+	CodeNotFound           = "NOT_FOUND"
+	CodeMethodNotAllowed   = "METHOD_NOT_ALLOWED"
+	CodeConflict           = "CONFLICT"
+	CodePayloadTooLarge    = "PAYLOAD_TOO_LARGE"
+	CodeCSRFMismatch       = "CSRF_TOKEN_MISMATCH"
+	CodeValidation         = "VALIDATION_ERROR"
+	CodeTooManyRequests    = "TOO_MANY_REQUESTS"
+	CodeInternal           = "INTERNAL_ERROR"
+	CodeNotImplemented     = "NOT_IMPLEMENTED"
+	CodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+)
+
+// StatusPageExpired is the non-standard 419 status used for expired
+// sessions and CSRF token mismatches.
+const StatusPageExpired = 419
+
+// CodeInfo describes an error code of the catalog.
+type CodeInfo struct {
+	Code   string `json:"code"`
+	Status int    `json:"status"`
+	Title  string `json:"title"`
+}
+
+var (
+	catalogMu sync.RWMutex
+	catalog   = map[string]CodeInfo{}
+)
+
+func init() {
+	for _, info := range []CodeInfo{
+		{CodeBadRequest, http.StatusBadRequest, "Bad Request"},
+		{CodeUnauthorized, http.StatusUnauthorized, "Unauthorized"},
+		{CodeForbidden, http.StatusForbidden, "Forbidden"},
+		{CodeNotFound, http.StatusNotFound, "Not Found"},
+		{CodeMethodNotAllowed, http.StatusMethodNotAllowed, "Method Not Allowed"},
+		{CodeConflict, http.StatusConflict, "Conflict"},
+		{CodePayloadTooLarge, http.StatusRequestEntityTooLarge, "Payload Too Large"},
+		{CodeCSRFMismatch, StatusPageExpired, "Page Expired"},
+		{CodeValidation, http.StatusUnprocessableEntity, "Validation Failed"},
+		{CodeTooManyRequests, http.StatusTooManyRequests, "Too Many Requests"},
+		{CodeInternal, http.StatusInternalServerError, "Internal Server Error"},
+		{CodeNotImplemented, http.StatusNotImplemented, "Not Implemented"},
+		{CodeServiceUnavailable, http.StatusServiceUnavailable, "Service Unavailable"},
+	} {
+		RegisterCode(info)
+	}
+}
+
+// RegisterCode adds an application-specific code to the catalog, or
+// replaces an existing one.
+//
+//	apperrors.RegisterCode(apperrors.CodeInfo{Code: "ORDER_LOCKED", Status: 423, Title: "Order Locked"})
+func RegisterCode(info CodeInfo) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	catalog[info.Code] = info
+}
+
+// LookupCode returns the catalog entry of a code.
+func LookupCode(code string) (CodeInfo, bool) {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+	info, ok := catalog[code]
+	return info, ok
+}
+
+// Catalog returns all registered codes, sorted by status then code.
+func Catalog() []CodeInfo {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	infos := make([]CodeInfo, 0, len(catalog))
+	for _, info := range catalog {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Status != infos[j].Status {
+			return infos[i].Status < infos[j].Status
+		}
+		return infos[i].Code < infos[j].Code
+	})
+	return infos
+}
+
+// FromCode creates an error from a catalog code, using its status. Unknown
+// codes are 500 errors. An empty message uses the code title.
+func FromCode(code, message string) *AppError {
+	info, ok := LookupCode(code)
+	if !ok {
+		info = CodeInfo{Code: code, Status: http.StatusInternalServerError, Title: http.StatusText(http.StatusInternalServerError)}
+	}
+	if message == "" {
+		message = info.Title
+	}
+	return New(code, message, info.Status)
+}
+
+// titleFor returns the catalog title of code, or the status text.
+func titleFor(code string, status int) string {
+	if info, ok := LookupCode(code); ok && info.Title != "" {
+		return info.Title
+	}
+	if text := http.StatusText(status); text != "" {
+		return text
+	}
+	if status == StatusPageExpired {
+		return "Page Expired"
+	}
+	return "Error"
+}
+
+// typeFor returns the problem type URI of code: TypeBaseURI followed by the
+// code in kebab case, or "about:blank" when TypeBaseURI is empty.
+func typeFor(code string) string {
+	if TypeBaseURI == "" || code == "" {
+		return "about:blank"
+	}
+	return strings.TrimRight(TypeBaseURI, "/") + "/" + strings.ToLower(strings.ReplaceAll(code, "_", "-"))
+}
+
+// TypeBaseURI prefixes problem type URIs, e.g. "https://docs.example.com/errors"
+// gives "https://docs.example.com/errors/not-found". Empty uses "about:blank".
+var TypeBaseURI = ""
@@ -10,7 +10,11 @@
 //   - HTTP error factories (NotFound, BadRequest, etc.)
 //   - Error wrapping and unwrapping
 //   - Panic recovery middleware
-//   - Custom error pages
+//   - Custom error pages, per request with ContextMiddleware
+//   - RFC 7807 application/problem+json for API and HTMX/Datastar requests
+//   - Catalog of stable machine-readable codes (RegisterCode, FromCode)
+//   - Error reporting (Reporter, Sentry-compatible DSNs, sampling)
+//   - Retry with exponential backoff for transient errors (Retry, Temporary)
 //
 // Basic usage:
 //
@@ -26,4 +30,12 @@
 //		appErr := errors.ToAppError(err)
 //		// Handle based on status code
 //	}
+//
+//	// Respond with a page, problem+json or text depending on the request
+//	apperrors.Handle(w, r, apperrors.Forbidden(""))
+//
+//	// Report panics and every 5xx error to Sentry
+//	sentry, _ := apperrors.NewSentryReporter(os.Getenv("SENTRY_DSN"))
+//	apperrors.SetReporter(apperrors.Sample(sentry, 0.5))
+//	apperrors.ReportOnCreate(apperrors.SeverityError)
 package apperrors
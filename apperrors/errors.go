@@ -16,6 +16,8 @@ type AppError struct {
 	Err        error
 	Stack      string
 	Fields     map[string]any
+
+	temporary bool
 }
 
 // Error implements the error interface.
@@ -59,17 +61,19 @@ func (e *AppError) WithStack() *AppError {
 
 // New creates a new AppError.
 func New(code, message string, statusCode int) *AppError {
-	return &AppError{
+	e := &AppError{
 		Code:       code,
 		Message:    message,
 		StatusCode: statusCode,
 		Fields:     make(map[string]any),
 	}
+	reportCreated(e)
+	return e
 }
 
 // Wrap wraps an existing error.
 func Wrap(err error, code, message string, statusCode int) *AppError {
-	return &AppError{
+	e := &AppError{
 		Code:       code,
 		Message:    message,
 		StatusCode: statusCode,
@@ -77,6 +81,8 @@ func Wrap(err error, code, message string, statusCode int) *AppError {
 		Stack:      string(debug.Stack()),
 		Fields:     make(map[string]any),
 	}
+	reportCreated(e)
+	return e
 }
 
 // NotFound creates a 404 error.
@@ -84,7 +90,7 @@ func NotFound(message string) *AppError {
 	if message == "" {
 		message = "Resource not found"
 	}
-	return New("NOT_FOUND", message, http.StatusNotFound)
+	return New(CodeNotFound, message, http.StatusNotFound)
 }
 
 // NotFoundf creates a formatted 404 error.
@@ -97,7 +103,7 @@ func BadRequest(message string) *AppError {
 	if message == "" {
 		message = "Invalid request"
 	}
-	return New("BAD_REQUEST", message, http.StatusBadRequest)
+	return New(CodeBadRequest, message, http.StatusBadRequest)
 }
 
 // BadRequestf creates a formatted 400 error.
@@ -110,7 +116,7 @@ func Unauthorized(message string) *AppError {
 	if message == "" {
 		message = "Authentication required"
 	}
-	return New("UNAUTHORIZED", message, http.StatusUnauthorized)
+	return New(CodeUnauthorized, message, http.StatusUnauthorized)
 }
 
 // Forbidden creates a 403 error.
@@ -118,7 +124,7 @@ func Forbidden(message string) *AppError {
 	if message == "" {
 		message = "Access denied"
 	}
-	return New("FORBIDDEN", message, http.StatusForbidden)
+	return New(CodeForbidden, message, http.StatusForbidden)
 }
 
 // Conflict creates a 409 error.
@@ -126,12 +132,37 @@ func Conflict(message string) *AppError {
 	if message == "" {
 		message = "A conflict occurred"
 	}
-	return New("CONFLICT", message, http.StatusConflict)
+	return New(CodeConflict, message, http.StatusConflict)
+}
+
+// MethodNotAllowed creates a 405 error.
+func MethodNotAllowed(message string) *AppError {
+	if message == "" {
+		message = "HTTP method not allowed"
+	}
+	return New(CodeMethodNotAllowed, message, http.StatusMethodNotAllowed)
+}
+
+// PageExpired creates a 419 error, used for CSRF token mismatches and
+// expired sessions.
+func PageExpired(message string) *AppError {
+	if message == "" {
+		message = "The page has expired, please refresh and try again"
+	}
+	return New(CodeCSRFMismatch, message, StatusPageExpired)
+}
+
+// TooManyRequests creates a 429 error.
+func TooManyRequests(message string) *AppError {
+	if message == "" {
+		message = "Too many requests"
+	}
+	return New(CodeTooManyRequests, message, http.StatusTooManyRequests)
 }
 
 // ValidationError creates a validation error.
 func ValidationError(fields map[string]string) *AppError {
-	err := New("VALIDATION_ERROR", "Validation failed", http.StatusUnprocessableEntity)
+	err := New(CodeValidation, "Validation failed", http.StatusUnprocessableEntity)
 	err.Fields = lo.MapEntries(fields, func(k string, v string) (string, any) {
 		return k, v
 	})
@@ -144,7 +175,7 @@ func Internal(err error, message string) *AppError {
 		message = "An internal error occurred"
 	}
 
-	appErr := Wrap(err, "INTERNAL_ERROR", message, http.StatusInternalServerError)
+	appErr := Wrap(err, CodeInternal, message, http.StatusInternalServerError)
 	appErr.Stack = string(debug.Stack())
 	return appErr
 }
@@ -154,12 +185,20 @@ func Internalf(err error, format string, args ...any) *AppError {
 	return Internal(err, fmt.Sprintf(format, args...))
 }
 
+// NotImplemented creates a 501 error.
+func NotImplemented(message string) *AppError {
+	if message == "" {
+		message = "Not implemented"
+	}
+	return New(CodeNotImplemented, message, http.StatusNotImplemented)
+}
+
 // ServiceUnavailable creates a 503 error.
 func ServiceUnavailable(message string) *AppError {
 	if message == "" {
 		message = "Service temporarily unavailable"
 	}
-	return New("SERVICE_UNAVAILABLE", message, http.StatusServiceUnavailable)
+	return New(CodeServiceUnavailable, message, http.StatusServiceUnavailable)
 }
 
 // ToAppError converts a standard error to AppError.
@@ -197,7 +236,7 @@ func IsNotFound(err error) bool {
 
 // IsValidation checks if it's a validation error.
 func IsValidation(err error) bool {
-	return HasCode(err, "VALIDATION_ERROR")
+	return HasCode(err, CodeValidation)
 }
 
 // GetValidationErrors extracts validation errors from an AppError.
@@ -1,10 +1,12 @@
 package apperrors
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 
 	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/logger"
 )
 
 // Handler manages HTTP errors.
@@ -70,7 +72,9 @@ func NewHandler(opts ...HandlerOption) *Handler {
 	return h
 }
 
-// Handle handles an error and returns the appropriate page.
+// Handle handles an error and writes a response matching the client:
+// problem+json for API and HTMX/Datastar requests (see WantsProblem), the
+// error page of the status for browsers, plain text otherwise.
 func (h *Handler) Handle(w http.ResponseWriter, r *http.Request, err error) {
 	if err == nil {
 		return
@@ -80,7 +84,10 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request, err error) {
 
 	h.logError(r, appErr)
 
-	w.WriteHeader(appErr.StatusCode)
+	if WantsProblem(r) {
+		WriteProblem(w, r, appErr)
+		return
+	}
 
 	errorPage := h.getErrorPage(appErr.StatusCode)
 	if errorPage == nil {
@@ -88,9 +95,9 @@ func (h *Handler) Handle(w http.ResponseWriter, r *http.Request, err error) {
 		return
 	}
 
-	if err := errorPage.Render(r.Context(), w); err != nil {
-		http.Error(w, appErr.Message, appErr.StatusCode)
-	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(appErr.StatusCode)
+	_ = errorPage.Render(context.WithValue(r.Context(), errorKey{}, appErr), w)
 }
 
 // HandleFunc returns a middleware that captures panics.
@@ -98,9 +105,7 @@ func (h *Handler) HandleFunc(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if rec := recover(); rec != nil {
-				err := Internal(nil, "An error occurred")
-				_ = err.WithField("panic", rec)
-				h.Handle(w, r, err)
+				h.Handle(w, r, Recovered(rec, r))
 			}
 		}()
 
@@ -108,10 +113,12 @@ func (h *Handler) HandleFunc(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-// logError records the error in logs.
+// logError records the error in logs, with the request logger when the
+// handler has none.
 func (h *Handler) logError(r *http.Request, appErr *AppError) {
-	if h.logger == nil {
-		return
+	var log Logger = h.logger
+	if log == nil {
+		log = logger.FromContext(r.Context())
 	}
 
 	attrs := []any{
@@ -122,6 +129,10 @@ func (h *Handler) logError(r *http.Request, appErr *AppError) {
 		slog.String("remote_addr", r.RemoteAddr),
 	}
 
+	if requestID := logger.RequestIDFromContext(r.Context()); requestID != "" {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+
 	for k, v := range appErr.Fields {
 		attrs = append(attrs, slog.Any(k, v))
 	}
@@ -136,11 +147,11 @@ func (h *Handler) logError(r *http.Request, appErr *AppError) {
 
 	switch {
 	case appErr.StatusCode >= 500:
-		h.logger.Error("server error", attrs...)
+		log.Error("server error", attrs...)
 	case appErr.StatusCode >= 400:
-		h.logger.Warn("client error", attrs...)
+		log.Warn("client error", attrs...)
 	default:
-		h.logger.Info("request completed with error", attrs...)
+		log.Info("request completed with error", attrs...)
 	}
 }
 
@@ -159,9 +170,7 @@ func (h *Handler) Middleware() func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if rec := recover(); rec != nil {
-					err := Internal(nil, "An error occurred")
-					_ = err.WithField("panic", rec)
-					h.Handle(w, r, err)
+					h.Handle(w, r, Recovered(rec, r))
 				}
 			}()
 
@@ -180,11 +189,43 @@ func (h *Handler) NotFound() http.HandlerFunc {
 // MethodNotAllowed returns a 405 handler.
 func (h *Handler) MethodNotAllowed() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		err := New("METHOD_NOT_ALLOWED", "HTTP method not allowed", http.StatusMethodNotAllowed)
-		h.Handle(w, r, err)
+		h.Handle(w, r, MethodNotAllowed(""))
+	}
+}
+
+// ContextMiddleware returns a middleware that stores the handler in the
+// request context, so that Handle renders its error pages for every error
+// raised further down the chain (CRUD handlers, auth, CSRF, rate limiting).
+func (h *Handler) ContextMiddleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(ContextWithHandler(r.Context(), h)))
+		})
 	}
 }
 
+type handlerKey struct{}
+
+type errorKey struct{}
+
+// ContextWithHandler returns a copy of ctx carrying h.
+func ContextWithHandler(ctx context.Context, h *Handler) context.Context {
+	return context.WithValue(ctx, handlerKey{}, h)
+}
+
+// HandlerFromContext returns the handler stored with ContextWithHandler, or nil.
+func HandlerFromContext(ctx context.Context) *Handler {
+	h, _ := ctx.Value(handlerKey{}).(*Handler)
+	return h
+}
+
+// ErrorFromContext returns the error being rendered. Error page components
+// use it to show the message and status of the error.
+func ErrorFromContext(ctx context.Context) *AppError {
+	e, _ := ctx.Value(errorKey{}).(*AppError)
+	return e
+}
+
 // Global instance
 var defaultHandler = NewHandler()
 
@@ -193,7 +234,12 @@ func SetDefaultHandler(h *Handler) {
 	defaultHandler = h
 }
 
-// Handle uses the global handler.
+// Handle uses the handler of the request context (see ContextMiddleware),
+// or the global handler.
 func Handle(w http.ResponseWriter, r *http.Request, err error) {
+	if h := HandlerFromContext(r.Context()); h != nil {
+		h.Handle(w, r, err)
+		return
+	}
 	defaultHandler.Handle(w, r, err)
 }
@@ -0,0 +1,85 @@
+package apperrors
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/bozz33/sublimeadmin/logger"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details document. Code, RequestID and
+// Errors are extension members.
+type Problem struct {
+	Type      string         `json:"type"`
+	Title     string         `json:"title"`
+	Status    int            `json:"status"`
+	Detail    string         `json:"detail,omitempty"`
+	Instance  string         `json:"instance,omitempty"`
+	Code      string         `json:"code,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+	Errors    map[string]any `json:"errors,omitempty"`
+}
+
+// Problem converts the error to problem details. Internal errors only
+// expose their message, never the wrapped error. r may be nil.
+func (e *AppError) Problem(r *http.Request) Problem {
+	p := Problem{
+		Type:   typeFor(e.Code),
+		Title:  titleFor(e.Code, e.StatusCode),
+		Status: e.StatusCode,
+		Detail: e.Message,
+		Code:   e.Code,
+	}
+	if p.Detail == p.Title {
+		p.Detail = ""
+	}
+	if e.Code == CodeValidation && len(e.Fields) > 0 {
+		p.Errors = e.Fields
+	}
+	if r != nil {
+		p.Instance = r.URL.Path
+		p.RequestID = logger.RequestIDFromContext(r.Context())
+	}
+	return p
+}
+
+// WriteProblem writes err as application/problem+json.
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	appErr := ToAppError(err)
+	if appErr == nil {
+		return
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(appErr.StatusCode)
+	_ = json.NewEncoder(w).Encode(appErr.Problem(r))
+}
+
+// WantsProblem reports whether the client expects a machine-readable error:
+// API clients (Accept: application/json or application/problem+json) and
+// HTMX/Datastar requests, which swap fragments and cannot use a full page.
+func WantsProblem(r *http.Request) bool {
+	if r.Header.Get("HX-Request") == "true" || r.Header.Get("Datastar-Request") == "true" {
+		return true
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return false
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		switch mediaType {
+		case "application/json", ProblemContentType:
+			return true
+		case "text/html":
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package apperrors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblem(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/admin/users/42", nil)
+	r = r.WithContext(logger.ContextWithRequestID(r.Context(), "req-1"))
+
+	p := NotFound("User 42 not found").Problem(r)
+
+	assert.Equal(t, "about:blank", p.Type)
+	assert.Equal(t, "Not Found", p.Title)
+	assert.Equal(t, http.StatusNotFound, p.Status)
+	assert.Equal(t, "User 42 not found", p.Detail)
+	assert.Equal(t, "/admin/users/42", p.Instance)
+	assert.Equal(t, CodeNotFound, p.Code)
+	assert.Equal(t, "req-1", p.RequestID)
+}
+
+func TestProblemHidesInternalCause(t *testing.T) {
+	p := Internal(errors.New("pq: password authentication failed"), "Delete error").Problem(nil)
+
+	assert.Equal(t, "Delete error", p.Detail)
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "pq:")
+}
+
+func TestProblemValidationErrors(t *testing.T) {
+	p := ValidationError(map[string]string{"email": "is required"}).Problem(nil)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, p.Status)
+	assert.Equal(t, map[string]any{"email": "is required"}, p.Errors)
+}
+
+func TestProblemTypeBaseURI(t *testing.T) {
+	TypeBaseURI = "https://docs.example.com/errors/"
+	defer func() { TypeBaseURI = "" }()
+
+	assert.Equal(t, "https://docs.example.com/errors/not-found", NotFound("").Problem(nil).Type)
+}
+
+func TestWantsProblem(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   bool
+	}{
+		{"browser", "Accept", "text/html,application/xhtml+xml,*/*;q=0.8", false},
+		{"json", "Accept", "application/json", true},
+		{"problem", "Accept", "application/problem+json", true},
+		{"htmx", "HX-Request", "true", true},
+		{"datastar", "Datastar-Request", "true", true},
+		{"none", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.value)
+			}
+			assert.Equal(t, tt.want, WantsProblem(r))
+		})
+	}
+}
+
+func TestHandlerNegotiatesContentType(t *testing.T) {
+	h := NewHandler()
+
+	// API client: problem+json
+	r := httptest.NewRequest(http.MethodDelete, "/admin/posts/1", nil)
+	r.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	h.Handle(w, r, Forbidden(""))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+	var p Problem
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Equal(t, CodeForbidden, p.Code)
+	assert.Equal(t, "Access denied", p.Detail)
+
+	// Browser without error page: plain text
+	r = httptest.NewRequest(http.MethodGet, "/admin/posts/1", nil)
+	w = httptest.NewRecorder()
+	h.Handle(w, r, Forbidden(""))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, w.Body.String(), "Access denied")
+}
+
+func TestCatalog(t *testing.T) {
+	info, ok := LookupCode(CodeCSRFMismatch)
+	require.True(t, ok)
+	assert.Equal(t, StatusPageExpired, info.Status)
+
+	RegisterCode(CodeInfo{Code: "ORDER_LOCKED", Status: http.StatusLocked, Title: "Order Locked"})
+	err := FromCode("ORDER_LOCKED", "")
+	assert.Equal(t, http.StatusLocked, err.StatusCode)
+	assert.Equal(t, "Order Locked", err.Message)
+
+	codes := Catalog()
+	require.NotEmpty(t, codes)
+	for i := 1; i < len(codes); i++ {
+		assert.LessOrEqual(t, codes[i-1].Status, codes[i].Status)
+	}
+
+	assert.Equal(t, http.StatusInternalServerError, FromCode("UNKNOWN", "boom").StatusCode)
+}
+
+func TestHandleUsesContextHandler(t *testing.T) {
+	page := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, "themed: "+ErrorFromContext(ctx).Message)
+		return err
+	})
+	h := NewHandler(WithErrorPage(http.StatusNotFound, page))
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Handle(w, r, NotFound("No such post"))
+	})
+	w := httptest.NewRecorder()
+	h.ContextMiddleware()(next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/posts/9", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Equal(t, "themed: No such post", w.Body.String())
+
+	// Without the middleware, the global handler answers in plain text.
+	w = httptest.NewRecorder()
+	next.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/posts/9", nil))
+	assert.Contains(t, w.Body.String(), "No such post")
+	assert.NotContains(t, w.Body.String(), "themed")
+}
@@ -0,0 +1,364 @@
+package apperrors
+
+import (
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/clientip"
+	"github.com/bozz33/sublimeadmin/logger"
+)
+
+// Severity ranks reported events. Values follow the Sentry levels.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota + 1
+	SeverityWarning
+	SeverityError
+	SeverityFatal
+)
+
+// String returns the Sentry level name of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityFatal:
+		return "fatal"
+	default:
+		return "debug"
+	}
+}
+
+// Severity returns the severity of the error: error for 5xx statuses,
+// warning for 4xx, info otherwise.
+func (e *AppError) Severity() Severity {
+	switch {
+	case e.StatusCode >= 500:
+		return SeverityError
+	case e.StatusCode >= 400:
+		return SeverityWarning
+	default:
+		return SeverityInfo
+	}
+}
+
+// Frame is a stack frame of an event, innermost last as Sentry expects.
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"filename"`
+	Line     int    `json:"lineno"`
+}
+
+// EventRequest is the HTTP request an event happened in. Sensitive headers
+// and query values are redacted.
+type EventRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Query   string            `json:"query_string,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// EventUser is the user an event happened for.
+type EventUser struct {
+	ID        string `json:"id,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Username  string `json:"username,omitempty"`
+	IPAddress string `json:"ip_address,omitempty"`
+}
+
+// Event is an error or panic sent to a Reporter.
+type Event struct {
+	ID         string
+	Timestamp  time.Time
+	Severity   Severity
+	Type       string // "panic" or the Go type of the error
+	Message    string
+	Error      error
+	Code       string
+	StatusCode int
+	Frames     []Frame
+	Request    *EventRequest
+	User       *EventUser
+	RequestID  string
+	Tags       map[string]string
+	Extra      map[string]any
+}
+
+// Reporter sends events to an error tracking service.
+// Implement this interface in your project, or use NewSentryReporter.
+type Reporter interface {
+	Report(ctx context.Context, event *Event) error
+}
+
+// ReporterFunc adapts a function to the Reporter interface.
+type ReporterFunc func(ctx context.Context, event *Event) error
+
+// Report implements Reporter.
+func (f ReporterFunc) Report(ctx context.Context, event *Event) error {
+	return f(ctx, event)
+}
+
+// Sample returns a reporter forwarding a random fraction of events to r:
+// rate 0.25 keeps one event in four. Fatal events (panics) are always kept.
+func Sample(r Reporter, rate float64) Reporter {
+	return ReporterFunc(func(ctx context.Context, event *Event) error {
+		if event.Severity < SeverityFatal && rate < 1 && rand.Float64() >= rate {
+			return nil
+		}
+		return r.Report(ctx, event)
+	})
+}
+
+var (
+	reporterMu      sync.RWMutex
+	reporter        Reporter
+	reportOnCreate  Severity
+	reportUserFuncs []func(ctx context.Context) *EventUser
+)
+
+// SetReporter configures the reporter used by Report and the recovery
+// middleware. nil disables reporting.
+//
+//	sentry, err := apperrors.NewSentryReporter(os.Getenv("SENTRY_DSN"))
+//	apperrors.SetReporter(apperrors.Sample(sentry, 0.5))
+func SetReporter(r Reporter) {
+	reporterMu.Lock()
+	defer reporterMu.Unlock()
+	reporter = r
+}
+
+// ReportOnCreate reports every AppError created by New or Wrap (and the
+// factories built on them) whose severity is at least min. 0 disables it.
+// Events are sent at creation: fields added afterwards are not included.
+//
+//	apperrors.ReportOnCreate(apperrors.SeverityError) // every 5xx error
+func ReportOnCreate(min Severity) {
+	reporterMu.Lock()
+	defer reporterMu.Unlock()
+	reportOnCreate = min
+}
+
+// RegisterUserFunc adds a function resolving the user of a request context
+// for reported events. The auth package registers one for its users.
+func RegisterUserFunc(fn func(ctx context.Context) *EventUser) {
+	reporterMu.Lock()
+	defer reporterMu.Unlock()
+	reportUserFuncs = append(reportUserFuncs, fn)
+}
+
+// NewEvent builds an event for err with the stack of the caller. r may be
+// nil; otherwise the event carries the request, request ID and user.
+func NewEvent(ctx context.Context, err error, r *http.Request) *Event {
+	event := &Event{
+		ID:        newEventID(),
+		Timestamp: time.Now().UTC(),
+		Severity:  SeverityError,
+		Type:      fmt.Sprintf("%T", err),
+		Frames:    callerFrames(3),
+		Tags:      map[string]string{},
+		Extra:     map[string]any{},
+	}
+	if err != nil {
+		event.Message = err.Error()
+		event.Error = err
+	}
+	if appErr, ok := err.(*AppError); ok {
+		event.Severity = appErr.Severity()
+		event.Code = appErr.Code
+		event.StatusCode = appErr.StatusCode
+		event.Message = appErr.Message
+		if appErr.Err != nil {
+			event.Message += ": " + logger.RedactString(appErr.Err.Error())
+		}
+		for k, v := range appErr.Fields {
+			event.Extra[k] = logger.Redact(v)
+		}
+	}
+	if event.Code != "" {
+		event.Tags["code"] = event.Code
+	}
+
+	if ctx == nil && r != nil {
+		ctx = r.Context()
+	}
+	if ctx != nil {
+		event.RequestID = logger.RequestIDFromContext(ctx)
+		event.User = eventUser(ctx)
+	}
+	if r != nil {
+		event.Request = newEventRequest(r)
+		if event.RequestID == "" {
+			event.RequestID = logger.RequestIDFromContext(r.Context())
+		}
+		if event.User == nil {
+			event.User = eventUser(r.Context())
+		}
+		if event.User != nil && event.User.IPAddress == "" {
+			event.User.IPAddress = clientip.FromRequest(r)
+		}
+	}
+	if event.RequestID != "" {
+		event.Tags["request_id"] = event.RequestID
+	}
+	if ctx != nil {
+		if route := logger.Route(ctx); route != "" {
+			event.Tags["route"] = route
+		}
+	}
+	return event
+}
+
+// Report sends event to the configured reporter. It is a no-op when no
+// reporter is set; failures are logged, never returned.
+func Report(ctx context.Context, event *Event) {
+	reporterMu.RLock()
+	r := reporter
+	reporterMu.RUnlock()
+
+	if r == nil || event == nil {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := r.Report(ctx, event); err != nil {
+		logger.FromContext(ctx).Warn("error report failed", logger.Err(err), "event_id", event.ID)
+	}
+}
+
+// CaptureException reports err with the stack of the caller and returns
+// the event ID, or "" when no reporter is set.
+func CaptureException(ctx context.Context, err error, r *http.Request) string {
+	if err == nil || !reporterEnabled() {
+		return ""
+	}
+	event := NewEvent(ctx, err, r)
+	Report(ctx, event)
+	return event.ID
+}
+
+// Recovered converts a recovered panic into a 500 error and reports it as
+// a fatal event, with the stack of the panic. r may be nil.
+func Recovered(rec any, r *http.Request) *AppError {
+	appErr := &AppError{
+		Code:       CodeInternal,
+		Message:    "An error occurred",
+		StatusCode: http.StatusInternalServerError,
+		Fields:     map[string]any{"panic": fmt.Sprint(rec)},
+	}
+	if err, ok := rec.(error); ok {
+		appErr.Err = err
+	}
+
+	if reporterEnabled() {
+		var ctx context.Context
+		if r != nil {
+			ctx = r.Context()
+		}
+		event := NewEvent(ctx, appErr, r)
+		event.Type = "panic"
+		event.Severity = SeverityFatal
+		event.Message = fmt.Sprint(rec)
+		Report(ctx, event)
+	}
+	return appErr
+}
+
+// reportCreated is called by New and Wrap.
+func reportCreated(e *AppError) {
+	reporterMu.RLock()
+	enabled := reporter != nil && reportOnCreate > 0 && e.Severity() >= reportOnCreate
+	reporterMu.RUnlock()
+
+	if enabled {
+		Report(context.Background(), NewEvent(context.Background(), e, nil))
+	}
+}
+
+func reporterEnabled() bool {
+	reporterMu.RLock()
+	defer reporterMu.RUnlock()
+	return reporter != nil
+}
+
+func eventUser(ctx context.Context) *EventUser {
+	reporterMu.RLock()
+	funcs := reportUserFuncs
+	reporterMu.RUnlock()
+
+	for _, fn := range funcs {
+		if u := fn(ctx); u != nil {
+			return u
+		}
+	}
+	return nil
+}
+
+func newEventRequest(r *http.Request) *EventRequest {
+	req := &EventRequest{
+		Method:  r.Method,
+		URL:     r.URL.Path,
+		Query:   logger.RedactString(r.URL.RawQuery),
+		Headers: make(map[string]string, len(r.Header)),
+	}
+	if r.Host != "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		req.URL = scheme + "://" + r.Host + r.URL.Path
+	}
+	for name, values := range r.Header {
+		if logger.IsSensitiveKey(name) {
+			req.Headers[name] = logger.RedactedValue
+			continue
+		}
+		req.Headers[name] = logger.RedactString(strings.Join(values, ", "))
+	}
+	return req
+}
+
+// callerFrames returns the stack above the caller, innermost last, without
+// the frames of this package (factories, Recovered) and of the runtime.
+func callerFrames(skip int) []Frame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var out []Frame
+	for {
+		f, more := frames.Next()
+		internal := strings.HasPrefix(f.Function, "runtime.") ||
+			(strings.HasPrefix(f.Function, "github.com/bozz33/sublimeadmin/apperrors.") && !strings.HasSuffix(f.File, "_test.go"))
+		if !internal {
+			out = append(out, Frame{Function: f.Function, File: f.File, Line: f.Line})
+		}
+		if !more {
+			break
+		}
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	_, _ = crand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,203 @@
+package apperrors
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordReporter collects reported events.
+type recordReporter struct {
+	mu     sync.Mutex
+	events []*Event
+}
+
+func (r *recordReporter) Report(_ context.Context, event *Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *recordReporter) Events() []*Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Event(nil), r.events...)
+}
+
+func useReporter(t *testing.T, r Reporter) {
+	t.Helper()
+	SetReporter(r)
+	t.Cleanup(func() {
+		SetReporter(nil)
+		ReportOnCreate(0)
+	})
+}
+
+func TestSeverity(t *testing.T) {
+	assert.Equal(t, SeverityError, Internal(nil, "").Severity())
+	assert.Equal(t, SeverityWarning, NotFound("").Severity())
+	assert.Equal(t, "fatal", SeverityFatal.String())
+}
+
+func TestReportOnCreate(t *testing.T) {
+	rec := &recordReporter{}
+	useReporter(t, rec)
+
+	_ = NotFound("")
+	assert.Empty(t, rec.Events(), "reporting on create is disabled by default")
+
+	ReportOnCreate(SeverityError)
+	_ = NotFound("")
+	_ = Internal(errors.New("db down"), "Save failed")
+
+	events := rec.Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, CodeInternal, events[0].Code)
+	assert.Equal(t, "Save failed: db down", events[0].Message)
+	require.NotEmpty(t, events[0].Frames)
+	assert.Contains(t, events[0].Frames[len(events[0].Frames)-1].Function, "TestReportOnCreate")
+}
+
+func TestRecovered(t *testing.T) {
+	rec := &recordReporter{}
+	useReporter(t, rec)
+
+	r := httptest.NewRequest(http.MethodPost, "/admin/users?token=abc&page=2", nil)
+	r.Header.Set("Authorization", "Bearer secret")
+	r.Header.Set("User-Agent", "test")
+	r = r.WithContext(logger.ContextWithRequestID(r.Context(), "req-7"))
+
+	var appErr *AppError
+	func() {
+		defer func() { appErr = Recovered(recover(), r) }()
+		panic("boom")
+	}()
+
+	assert.Equal(t, http.StatusInternalServerError, appErr.StatusCode)
+	assert.Equal(t, "boom", appErr.Fields["panic"])
+
+	events := rec.Events()
+	require.Len(t, events, 1)
+	ev := events[0]
+	assert.Equal(t, "panic", ev.Type)
+	assert.Equal(t, SeverityFatal, ev.Severity)
+	assert.Equal(t, "boom", ev.Message)
+	assert.Equal(t, "req-7", ev.Tags["request_id"])
+	require.NotNil(t, ev.Request)
+	assert.Equal(t, logger.RedactedValue, ev.Request.Headers["Authorization"])
+	assert.Equal(t, "test", ev.Request.Headers["User-Agent"])
+	assert.NotContains(t, ev.Request.Query, "abc")
+	assert.Contains(t, ev.Request.Query, "page=2")
+}
+
+func TestRecoveredUser(t *testing.T) {
+	rec := &recordReporter{}
+	useReporter(t, rec)
+
+	type userKey struct{}
+	RegisterUserFunc(func(ctx context.Context) *EventUser {
+		if id, ok := ctx.Value(userKey{}).(string); ok {
+			return &EventUser{ID: id}
+		}
+		return nil
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	r.RemoteAddr = "10.0.0.1:5555"
+	r = r.WithContext(context.WithValue(r.Context(), userKey{}, "42"))
+	_ = Recovered("boom", r)
+
+	events := rec.Events()
+	require.Len(t, events, 1)
+	require.NotNil(t, events[0].User)
+	assert.Equal(t, "42", events[0].User.ID)
+	assert.Equal(t, "10.0.0.1", events[0].User.IPAddress)
+}
+
+func TestSample(t *testing.T) {
+	rec := &recordReporter{}
+
+	never := Sample(rec, 0)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, never.Report(context.Background(), &Event{Severity: SeverityError}))
+	}
+	assert.Empty(t, rec.Events())
+
+	require.NoError(t, never.Report(context.Background(), &Event{Severity: SeverityFatal}))
+	assert.Len(t, rec.Events(), 1, "panics are never sampled out")
+
+	always := Sample(rec, 1)
+	require.NoError(t, always.Report(context.Background(), &Event{Severity: SeverityError}))
+	assert.Len(t, rec.Events(), 2)
+}
+
+func TestParseSentryDSN(t *testing.T) {
+	endpoint, key, err := parseSentryDSN("https://pub123@o1.ingest.sentry.io/4501")
+	require.NoError(t, err)
+	assert.Equal(t, "https://o1.ingest.sentry.io/api/4501/envelope/", endpoint)
+	assert.Equal(t, "pub123", key)
+
+	endpoint, _, err = parseSentryDSN("http://key@glitchtip.local/sentry/7")
+	require.NoError(t, err)
+	assert.Equal(t, "http://glitchtip.local/sentry/api/7/envelope/", endpoint)
+
+	for _, dsn := range []string{"", "https://sentry.io/1", "https://key@sentry.io/"} {
+		_, _, err := parseSentryDSN(dsn)
+		assert.Error(t, err, dsn)
+	}
+}
+
+func TestSentryReporter(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		auth     string
+		envelope []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		auth = r.Header.Get("X-Sentry-Auth")
+		body, _ := io.ReadAll(r.Body)
+		scanner := bufio.NewScanner(strings.NewReader(string(body)))
+		for scanner.Scan() {
+			envelope = append(envelope, scanner.Text())
+		}
+	}))
+	defer srv.Close()
+
+	cfg := DefaultSentryConfig(strings.Replace(srv.URL, "http://", "http://pubkey@", 1) + "/3")
+	cfg.Environment = "test"
+	s, err := NewSentryReporterWithConfig(cfg)
+	require.NoError(t, err)
+
+	event := NewEvent(context.Background(), Internal(nil, "Save failed"), nil)
+	require.NoError(t, s.Report(context.Background(), event))
+	require.True(t, s.Close(5*time.Second))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, auth, "sentry_key=pubkey")
+	require.Len(t, envelope, 3)
+	assert.Contains(t, envelope[0], event.ID)
+
+	var payload map[string]any
+	require.NoError(t, json.Unmarshal([]byte(envelope[2]), &payload))
+	assert.Equal(t, "error", payload["level"])
+	assert.Equal(t, "test", payload["environment"])
+	assert.Equal(t, map[string]any{"code": CodeInternal}, payload["tags"])
+
+	assert.Error(t, s.Report(context.Background(), event), "closed reporter")
+}
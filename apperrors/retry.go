@@ -0,0 +1,146 @@
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures Retry.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of calls, including the first one.
+	MaxAttempts int
+	// InitialDelay is the wait before the second attempt; each following
+	// wait is multiplied by Multiplier, up to MaxDelay.
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	Multiplier   float64
+	// Jitter randomizes each wait by up to this fraction (0.2 = ±20%), so
+	// clients failing together do not retry together.
+	Jitter float64
+	// Retryable decides whether an error is worth retrying. Defaults to
+	// IsRetryable.
+	Retryable func(err error) bool
+}
+
+// DefaultRetryPolicy returns a policy of 3 attempts with exponential
+// backoff starting at 200ms.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:  3,
+		InitialDelay: 200 * time.Millisecond,
+		MaxDelay:     5 * time.Second,
+		Multiplier:   2,
+		Jitter:       0.2,
+	}
+}
+
+// Delay returns the wait before the given attempt (2 for the first retry),
+// without jitter.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	if attempt < 2 || p.InitialDelay <= 0 {
+		return 0
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialDelay)
+	for i := 2; i < attempt; i++ {
+		delay *= multiplier
+		if p.MaxDelay > 0 && delay >= float64(p.MaxDelay) {
+			return p.MaxDelay
+		}
+	}
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		return p.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+// Retry calls fn until it succeeds, returns an error that is not retryable,
+// or policy.MaxAttempts is reached, waiting with exponential backoff and
+// jitter between attempts. It returns the last error of fn, joined with the
+// context error when ctx is done while waiting.
+//
+//	err := apperrors.Retry(ctx, apperrors.DefaultRetryPolicy(), func(ctx context.Context) error {
+//		return client.Send(ctx, payload)
+//	})
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = IsRetryable
+	}
+	attempts := max(policy.MaxAttempts, 1)
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return errors.Join(err, ctxErr)
+		}
+		if err = fn(ctx); err == nil || !retryable(err) || attempt == attempts {
+			return err
+		}
+
+		timer := time.NewTimer(jitter(policy.Delay(attempt+1), policy.Jitter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+	return err
+}
+
+// IsRetryable reports whether err is transient: errors with a Temporary()
+// method returning true (AppError, network errors) and timeouts. Context
+// cancellation is never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) && temporary.Temporary() {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// Temporary reports whether the error is transient and the operation may
+// succeed if retried: errors marked with MarkTemporary, and 429, 502, 503
+// and 504 errors.
+func (e *AppError) Temporary() bool {
+	if e.temporary {
+		return true
+	}
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// MarkTemporary marks the error as transient, so Retry retries it.
+func (e *AppError) MarkTemporary() *AppError {
+	e.temporary = true
+	return e
+}
+
+func jitter(d time.Duration, fraction float64) time.Duration {
+	if d <= 0 || fraction <= 0 {
+		return d
+	}
+	fraction = min(fraction, 1)
+	return time.Duration(float64(d) * (1 + fraction*(2*rand.Float64()-1)))
+}
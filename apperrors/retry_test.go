@@ -0,0 +1,102 @@
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fastPolicy(attempts int) RetryPolicy {
+	return RetryPolicy{MaxAttempts: attempts, InitialDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond, Multiplier: 2}
+}
+
+func TestRetry_SucceedsAfterTransientErrors(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), fastPolicy(5), func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return ServiceUnavailable("")
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetry_StopsOnPermanentError(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), fastPolicy(5), func(context.Context) error {
+		calls++
+		return BadRequest("invalid")
+	})
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, CodeBadRequest, ToAppError(err).Code)
+}
+
+func TestRetry_MaxAttempts(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), fastPolicy(3), func(context.Context) error {
+		calls++
+		return Internal(nil, "").MarkTemporary()
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetry_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := fastPolicy(10)
+	policy.InitialDelay = time.Hour
+
+	calls := 0
+	err := Retry(ctx, policy, func(context.Context) error {
+		calls++
+		cancel()
+		return TooManyRequests("")
+	})
+
+	assert.Equal(t, 1, calls)
+	assert.ErrorIs(t, err, context.Canceled)
+	var appErr *AppError
+	assert.True(t, errors.As(err, &appErr))
+	assert.Equal(t, CodeTooManyRequests, appErr.Code)
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	p := RetryPolicy{InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2}
+
+	assert.Equal(t, time.Duration(0), p.Delay(1))
+	assert.Equal(t, 100*time.Millisecond, p.Delay(2))
+	assert.Equal(t, 200*time.Millisecond, p.Delay(3))
+	assert.Equal(t, 400*time.Millisecond, p.Delay(4))
+	assert.Equal(t, time.Second, p.Delay(10))
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		d := jitter(time.Second, 0.2)
+		assert.GreaterOrEqual(t, d, 800*time.Millisecond)
+		assert.LessOrEqual(t, d, 1200*time.Millisecond)
+	}
+	assert.Equal(t, time.Second, jitter(time.Second, 0))
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.True(t, IsRetryable(ServiceUnavailable("")))
+	assert.True(t, IsRetryable(fmt.Errorf("send: %w", TooManyRequests(""))))
+	assert.True(t, IsRetryable(Internal(nil, "").MarkTemporary()))
+	assert.True(t, IsRetryable(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.False(t, IsRetryable(Internal(nil, "")))
+	assert.False(t, IsRetryable(NotFound("")))
+	assert.False(t, IsRetryable(context.Canceled))
+	assert.False(t, IsRetryable(errors.New("boom")))
+	assert.False(t, IsRetryable(nil))
+}
@@ -0,0 +1,246 @@
+package apperrors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SentryConfig configures the Sentry reporter.
+type SentryConfig struct {
+	// DSN of the project: https://<key>@<host>/<project_id>. Any service
+	// implementing the Sentry envelope API works (Sentry, GlitchTip, Bugsink).
+	DSN         string
+	Environment string
+	Release     string
+	ServerName  string
+
+	// QueueSize bounds the events waiting to be sent; new events are dropped
+	// when it is full, so reporting never blocks a request.
+	QueueSize  int
+	Timeout    time.Duration
+	HTTPClient *http.Client
+
+	// Retry retries sends failing with a network error, 429 or 5xx.
+	Retry RetryPolicy
+}
+
+// DefaultSentryConfig returns a default configuration.
+func DefaultSentryConfig(dsn string) *SentryConfig {
+	return &SentryConfig{
+		DSN:       dsn,
+		QueueSize: 100,
+		Timeout:   5 * time.Second,
+		Retry:     DefaultRetryPolicy(),
+	}
+}
+
+// SentryReporter sends events to a Sentry-compatible service from a
+// background goroutine. Call Close on shutdown to flush pending events.
+type SentryReporter struct {
+	config   *SentryConfig
+	endpoint string
+	key      string
+	client   *http.Client
+
+	queue chan *Event
+	wg    sync.WaitGroup
+	once  sync.Once
+}
+
+// NewSentryReporter creates a Sentry reporter for dsn.
+func NewSentryReporter(dsn string) (*SentryReporter, error) {
+	return NewSentryReporterWithConfig(DefaultSentryConfig(dsn))
+}
+
+// NewSentryReporterWithConfig creates a Sentry reporter with custom config.
+func NewSentryReporterWithConfig(config *SentryConfig) (*SentryReporter, error) {
+	if config == nil {
+		return nil, fmt.Errorf("apperrors: sentry config is required")
+	}
+	endpoint, key, err := parseSentryDSN(config.DSN)
+	if err != nil {
+		return nil, err
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 100
+	}
+	client := config.HTTPClient
+	if client == nil {
+		timeout := config.Timeout
+		if timeout <= 0 {
+			timeout = 5 * time.Second
+		}
+		client = &http.Client{Timeout: timeout}
+	}
+
+	s := &SentryReporter{
+		config:   config,
+		endpoint: endpoint,
+		key:      key,
+		client:   client,
+		queue:    make(chan *Event, config.QueueSize),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+// Report implements Reporter. It queues the event and returns an error when
+// the queue is full or the reporter is closed.
+func (s *SentryReporter) Report(_ context.Context, event *Event) (err error) {
+	defer func() {
+		if recover() != nil {
+			err = fmt.Errorf("apperrors: sentry reporter is closed")
+		}
+	}()
+
+	select {
+	case s.queue <- event:
+		return nil
+	default:
+		return fmt.Errorf("apperrors: sentry queue full, event %s dropped", event.ID)
+	}
+}
+
+// Close sends the queued events and stops the reporter, waiting at most
+// timeout. It reports whether every event was sent.
+func (s *SentryReporter) Close(timeout time.Duration) bool {
+	s.once.Do(func() { close(s.queue) })
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+func (s *SentryReporter) run() {
+	defer s.wg.Done()
+	for event := range s.queue {
+		_ = Retry(context.Background(), s.config.Retry, func(context.Context) error {
+			return s.send(event)
+		})
+	}
+}
+
+func (s *SentryReporter) send(event *Event) error {
+	payload, err := json.Marshal(s.payload(event))
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	header, _ := json.Marshal(map[string]string{
+		"event_id": event.ID,
+		"sent_at":  time.Now().UTC().Format(time.RFC3339),
+	})
+	body.Write(header)
+	body.WriteString("\n")
+	itemHeader, _ := json.Marshal(map[string]any{"type": "event", "length": len(payload)})
+	body.Write(itemHeader)
+	body.WriteString("\n")
+	body.Write(payload)
+	body.WriteString("\n")
+
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", "Sentry sentry_version=7, sentry_client=sublimeadmin/1.0, sentry_key="+s.key)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		// Built as a literal: errors of the reporter itself are never reported.
+		err := &AppError{Code: CodeServiceUnavailable, Message: "sentry returned " + resp.Status, StatusCode: resp.StatusCode}
+		if resp.StatusCode >= 500 {
+			err.temporary = true
+		}
+		return err
+	}
+	return nil
+}
+
+// payload converts an event to the Sentry event format.
+func (s *SentryReporter) payload(event *Event) map[string]any {
+	exception := map[string]any{
+		"type":  event.Type,
+		"value": event.Message,
+	}
+	if len(event.Frames) > 0 {
+		exception["stacktrace"] = map[string]any{"frames": event.Frames}
+	}
+	if event.Type == "panic" {
+		exception["mechanism"] = map[string]any{"type": "panic", "handled": false}
+	}
+
+	p := map[string]any{
+		"event_id":  event.ID,
+		"timestamp": event.Timestamp.Format(time.RFC3339Nano),
+		"level":     event.Severity.String(),
+		"platform":  "go",
+		"logger":    "sublimeadmin",
+		"exception": map[string]any{"values": []any{exception}},
+	}
+	if event.Request != nil {
+		p["request"] = event.Request
+	}
+	if event.User != nil {
+		p["user"] = event.User
+	}
+	if len(event.Tags) > 0 {
+		p["tags"] = event.Tags
+	}
+	if len(event.Extra) > 0 {
+		p["extra"] = event.Extra
+	}
+	if s.config.Environment != "" {
+		p["environment"] = s.config.Environment
+	}
+	if s.config.Release != "" {
+		p["release"] = s.config.Release
+	}
+	if s.config.ServerName != "" {
+		p["server_name"] = s.config.ServerName
+	}
+	return p
+}
+
+// parseSentryDSN returns the envelope endpoint and public key of a DSN.
+func parseSentryDSN(dsn string) (endpoint, key string, err error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", fmt.Errorf("apperrors: invalid sentry DSN: %w", err)
+	}
+	if u.User == nil || u.User.Username() == "" || u.Host == "" {
+		return "", "", fmt.Errorf("apperrors: invalid sentry DSN: missing key or host")
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	if i < 0 || path[i+1:] == "" {
+		return "", "", fmt.Errorf("apperrors: invalid sentry DSN: missing project ID")
+	}
+	prefix, project := path[:i], path[i+1:]
+
+	return fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, prefix, project), u.User.Username(), nil
+}
@@ -3,9 +3,13 @@ package auth
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"strconv"
 
 	"github.com/alexedwards/scs/v2"
+	"github.com/bozz33/sublimeadmin/apperrors"
+	"github.com/bozz33/sublimeadmin/logger"
 )
 
 const (
@@ -217,6 +221,24 @@ func UserFromContext(ctx context.Context) *User {
 	return Guest()
 }
 
+func init() {
+	// Every context-aware log line carries the authenticated user.
+	logger.RegisterEnricher("user_id", func(ctx context.Context) []slog.Attr {
+		if user, ok := ctx.Value(userKey).(*User); ok && user.IsAuthenticated() {
+			return []slog.Attr{slog.Int("user_id", user.ID)}
+		}
+		return nil
+	})
+
+	// Reported errors and panics carry the authenticated user.
+	apperrors.RegisterUserFunc(func(ctx context.Context) *apperrors.EventUser {
+		if user, ok := ctx.Value(userKey).(*User); ok && user.IsAuthenticated() {
+			return &apperrors.EventUser{ID: strconv.Itoa(user.ID), Email: user.Email, Username: user.Name}
+		}
+		return nil
+	})
+}
+
 // WithManager adds the auth manager to the context.
 func WithManager(ctx context.Context, manager *Manager) context.Context {
 	return context.WithValue(ctx, managerKey, manager)
@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// PasswordPolicy configures the passwords accepted on registration, reset
+// and profile change.
+type PasswordPolicy struct {
+	// MinLength is the minimum number of characters (default 8).
+	MinLength int
+	// RequireUpper, RequireLower, RequireDigit and RequireSymbol require at
+	// least one character of each class.
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// DenyCommon rejects the most common passwords ("password", "123456"...).
+	DenyCommon bool
+	// Denylist rejects more passwords (case-insensitive), e.g. the name of
+	// the application.
+	Denylist []string
+	// History is the number of previous passwords a user cannot reuse
+	// (0 to allow any). It needs a UserRepository keeping their hashes.
+	History int
+	// MaxAge is how long a password stays valid before the user must change
+	// it (0 for never). It needs a UserRepository knowing when it changed.
+	MaxAge time.Duration
+}
+
+// DefaultPasswordPolicy returns the policy used when none is configured:
+// 8 characters at least, the common passwords denied.
+func DefaultPasswordPolicy() *PasswordPolicy {
+	return &PasswordPolicy{MinLength: 8, DenyCommon: true}
+}
+
+// PasswordRule is a rule of a policy checkable in the browser, listed under
+// the password fields and ticked by the strength meter as the user types.
+type PasswordRule struct {
+	ID    string // "length", "upper", "lower", "digit" or "symbol"
+	Label string // e.g. "At least 8 characters"
+}
+
+// Rules returns the rules of the policy checkable in the browser.
+func (p *PasswordPolicy) Rules() []PasswordRule {
+	rules := []PasswordRule{{ID: "length", Label: fmt.Sprintf("At least %d characters", p.MinimumLength())}}
+	if p.RequireUpper {
+		rules = append(rules, PasswordRule{ID: "upper", Label: "An uppercase letter"})
+	}
+	if p.RequireLower {
+		rules = append(rules, PasswordRule{ID: "lower", Label: "A lowercase letter"})
+	}
+	if p.RequireDigit {
+		rules = append(rules, PasswordRule{ID: "digit", Label: "A number"})
+	}
+	if p.RequireSymbol {
+		rules = append(rules, PasswordRule{ID: "symbol", Label: "A symbol"})
+	}
+	return rules
+}
+
+// MinimumLength returns MinLength, 8 when unset.
+func (p *PasswordPolicy) MinimumLength() int {
+	if p.MinLength <= 0 {
+		return 8
+	}
+	return p.MinLength
+}
+
+// PasswordError lists the rules of a policy a password breaks.
+type PasswordError struct {
+	Violations []string
+}
+
+func (e *PasswordError) Error() string {
+	return strings.Join(e.Violations, " ")
+}
+
+// Validate checks password against the policy, all but History and MaxAge
+// which need the user. It returns a *PasswordError, or nil.
+func (p *PasswordPolicy) Validate(password string) error {
+	var violations []string
+	if len([]rune(password)) < p.MinimumLength() {
+		violations = append(violations, fmt.Sprintf("Password must be at least %d characters.", p.MinimumLength()))
+	}
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		violations = append(violations, "Password must contain an uppercase letter.")
+	}
+	if p.RequireLower && !lower {
+		violations = append(violations, "Password must contain a lowercase letter.")
+	}
+	if p.RequireDigit && !digit {
+		violations = append(violations, "Password must contain a number.")
+	}
+	if p.RequireSymbol && !symbol {
+		violations = append(violations, "Password must contain a symbol.")
+	}
+	if p.denied(password) {
+		violations = append(violations, "This password is too common, choose another one.")
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return &PasswordError{Violations: violations}
+}
+
+// denied reports whether password is common or in the denylist.
+func (p *PasswordPolicy) denied(password string) bool {
+	password = strings.ToLower(password)
+	if p.DenyCommon {
+		if _, ok := commonPasswords[password]; ok {
+			return true
+		}
+	}
+	for _, d := range p.Denylist {
+		if strings.ToLower(d) == password {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired reports whether a password changed at changedAt must be changed.
+// A zero changedAt never expires: the date is unknown.
+func (p *PasswordPolicy) Expired(changedAt time.Time) bool {
+	return p.MaxAge > 0 && !changedAt.IsZero() && time.Since(changedAt) > p.MaxAge
+}
+
+// commonPasswords are the most frequent passwords of public breach lists,
+// lowercased.
+var commonPasswords = map[string]struct{}{}
+
+func init() {
+	for _, p := range strings.Fields(`
+		123456 123456789 12345678 12345 1234567 1234567890 111111 000000 123123
+		654321 666666 121212 112233 123321 1q2w3e4r 1q2w3e4r5t qwerty qwerty123
+		qwertyuiop azerty azertyuiop asdfghjkl zxcvbnm password password1
+		password123 passw0rd p@ssw0rd admin admin123 administrator root toor
+		letmein welcome welcome1 login abc123 iloveyou monkey dragon master
+		sunshine princess football baseball superman batman trustno1 shadow
+		michael charlie jennifer hunter secret changeme default guest test
+		test123 qazwsx starwars whatever freedom computer internet soleil
+		motdepasse bonjour`) {
+		commonPasswords[p] = struct{}{}
+	}
+}
+
+const passwordPolicyKey contextKey = "auth_password_policy"
+
+// WithPasswordPolicy adds the password policy to the context.
+func WithPasswordPolicy(ctx context.Context, policy *PasswordPolicy) context.Context {
+	return context.WithValue(ctx, passwordPolicyKey, policy)
+}
+
+// PasswordPolicyFromContext retrieves the password policy from the context,
+// DefaultPasswordPolicy when there is none.
+func PasswordPolicyFromContext(ctx context.Context) *PasswordPolicy {
+	if policy, ok := ctx.Value(passwordPolicyKey).(*PasswordPolicy); ok && policy != nil {
+		return policy
+	}
+	return DefaultPasswordPolicy()
+}
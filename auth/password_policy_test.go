@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	p := &PasswordPolicy{MinLength: 10, RequireUpper: true, RequireDigit: true, RequireSymbol: true, DenyCommon: true, Denylist: []string{"Sublime2024!"}}
+
+	assert.NoError(t, p.Validate("Corr3ct-Horse"))
+
+	err := p.Validate("horse")
+	var perr *PasswordError
+	require.True(t, errors.As(err, &perr))
+	assert.Equal(t, []string{
+		"Password must be at least 10 characters.",
+		"Password must contain an uppercase letter.",
+		"Password must contain a number.",
+		"Password must contain a symbol.",
+	}, perr.Violations)
+
+	assert.EqualError(t, p.Validate("sublime2024!"), "Password must contain an uppercase letter. This password is too common, choose another one.")
+	assert.Error(t, DefaultPasswordPolicy().Validate("Password123"))
+	assert.NoError(t, (&PasswordPolicy{}).Validate("password"), "the common passwords are only denied with DenyCommon")
+}
+
+func TestPasswordPolicy_Rules(t *testing.T) {
+	rules := (&PasswordPolicy{RequireLower: true}).Rules()
+	assert.Equal(t, []PasswordRule{
+		{ID: "length", Label: "At least 8 characters"},
+		{ID: "lower", Label: "A lowercase letter"},
+	}, rules)
+}
+
+func TestPasswordPolicy_Expired(t *testing.T) {
+	p := &PasswordPolicy{MaxAge: 90 * 24 * time.Hour}
+	assert.True(t, p.Expired(time.Now().Add(-100*24*time.Hour)))
+	assert.False(t, p.Expired(time.Now().Add(-time.Hour)))
+	assert.False(t, p.Expired(time.Time{}))
+	assert.False(t, (&PasswordPolicy{}).Expired(time.Now().Add(-1000*24*time.Hour)))
+}
+
+func TestPasswordPolicyFromContext(t *testing.T) {
+	assert.Equal(t, DefaultPasswordPolicy(), PasswordPolicyFromContext(context.Background()))
+	p := &PasswordPolicy{MinLength: 12}
+	assert.Same(t, p, PasswordPolicyFromContext(WithPasswordPolicy(context.Background(), p)))
+}
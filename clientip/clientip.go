@@ -0,0 +1,143 @@
+// Package clientip resolves the IP address of the client of a request.
+//
+// The address of the peer is used unless it is a trusted proxy: only then
+// are the X-Forwarded-For and X-Real-IP headers read, so a client cannot
+// claim another address with one header. Behind a load balancer:
+//
+//	if err := clientip.SetTrustedProxies("10.0.0.0/8", "127.0.0.1"); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// The IP filter, the maintenance bypass, the rate limits and the request
+// logs of the middleware package, the error reports and the remembered
+// devices all use FromRequest. IsHTTPS reads X-Forwarded-Proto the same
+// way, for the Secure flag of the device cookies.
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// Resolver resolves the client IP trusting the forwarding headers set by
+// its proxies only.
+type Resolver struct {
+	proxies []*net.IPNet
+}
+
+// NewResolver creates a resolver trusting the proxies, IPs ("10.0.0.5") or
+// CIDR ranges ("10.0.0.0/8"). Without proxies, it returns the address of
+// the peer.
+func NewResolver(proxies ...string) (*Resolver, error) {
+	r := &Resolver{}
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return nil, fmt.Errorf("clientip: invalid proxy %q", p)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			r.proxies = append(r.proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, fmt.Errorf("clientip: invalid proxy %q: %w", p, err)
+		}
+		r.proxies = append(r.proxies, ipNet)
+	}
+	return r, nil
+}
+
+// IP returns the IP of the client of r: the address of the peer, or when
+// the peer is a trusted proxy, the last address of X-Forwarded-For not
+// set by a trusted proxy, else X-Real-IP.
+func (res *Resolver) IP(r *http.Request) string {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	if !res.trusted(peer) {
+		return peer
+	}
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			if !res.trusted(hop) {
+				return hop
+			}
+		}
+	}
+	if xri := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(xri) != nil {
+		return xri
+	}
+	return peer
+}
+
+// IsHTTPS reports whether the client of r uses HTTPS: r came over TLS, or
+// from a trusted proxy setting X-Forwarded-Proto to https.
+func (res *Resolver) IsHTTPS(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	return res.trusted(peer) && strings.EqualFold(strings.TrimSpace(r.Header.Get("X-Forwarded-Proto")), "https")
+}
+
+// trusted reports whether ip is one of the proxies.
+func (res *Resolver) trusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, p := range res.proxies {
+		if p.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultResolver is the resolver of FromRequest.
+var defaultResolver atomic.Pointer[Resolver]
+
+func init() {
+	defaultResolver.Store(&Resolver{})
+}
+
+// SetTrustedProxies sets the proxies trusted by FromRequest, none by
+// default (see NewResolver).
+func SetTrustedProxies(proxies ...string) error {
+	r, err := NewResolver(proxies...)
+	if err != nil {
+		return err
+	}
+	defaultResolver.Store(r)
+	return nil
+}
+
+// FromRequest returns the IP of the client of r, trusting the proxies of
+// SetTrustedProxies.
+func FromRequest(r *http.Request) string {
+	return defaultResolver.Load().IP(r)
+}
+
+// IsHTTPS reports whether the client of r uses HTTPS, trusting the proxies
+// of SetTrustedProxies.
+func IsHTTPS(r *http.Request) bool {
+	return defaultResolver.Load().IsHTTPS(r)
+}
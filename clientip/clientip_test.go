@@ -0,0 +1,85 @@
+package clientip
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolver_IP(t *testing.T) {
+	res, err := NewResolver("10.0.0.0/8", "192.168.1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		remote string
+		xff    string
+		xri    string
+		want   string
+	}{
+		{"direct client", "203.0.113.9:1234", "", "", "203.0.113.9"},
+		{"spoofed header from an untrusted peer", "203.0.113.9:1234", "10.1.1.1", "10.1.1.2", "203.0.113.9"},
+		{"behind a trusted proxy", "10.0.0.2:80", "203.0.113.1", "", "203.0.113.1"},
+		{"client prepending a fake hop", "10.0.0.2:80", "1.2.3.4, 203.0.113.1", "", "203.0.113.1"},
+		{"chain of trusted proxies", "192.168.1.1:80", "203.0.113.1, 10.0.0.7", "", "203.0.113.1"},
+		{"X-Real-IP", "10.0.0.2:80", "", "203.0.113.5", "203.0.113.5"},
+		{"garbage header", "10.0.0.2:80", "not-an-ip", "", "10.0.0.2"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tt.remote
+		if tt.xff != "" {
+			r.Header.Set("X-Forwarded-For", tt.xff)
+		}
+		if tt.xri != "" {
+			r.Header.Set("X-Real-IP", tt.xri)
+		}
+		if got := res.IP(r); got != tt.want {
+			t.Errorf("%s: IP = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSetTrustedProxies(t *testing.T) {
+	defer func() { _ = SetTrustedProxies() }()
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "127.0.0.1:1234"
+	r.Header.Set("X-Forwarded-For", "203.0.113.1")
+	if got := FromRequest(r); got != "127.0.0.1" {
+		t.Errorf("expected the headers ignored by default, got %q", got)
+	}
+
+	if err := SetTrustedProxies("127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if got := FromRequest(r); got != "203.0.113.1" {
+		t.Errorf("expected the header of the trusted proxy, got %q", got)
+	}
+
+	if err := SetTrustedProxies("not-a-proxy"); err == nil {
+		t.Error("expected an error for an invalid proxy")
+	}
+}
+
+func TestIsHTTPS(t *testing.T) {
+	defer func() { _ = SetTrustedProxies() }()
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "127.0.0.1:1234"
+	r.Header.Set("X-Forwarded-Proto", "https")
+	if IsHTTPS(r) {
+		t.Error("expected the header ignored by default")
+	}
+	if err := SetTrustedProxies("127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if !IsHTTPS(r) {
+		t.Error("expected the header of the trusted proxy")
+	}
+	r.Header.Set("X-Forwarded-Proto", "http")
+	if IsHTTPS(r) {
+		t.Error("expected plain HTTP")
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/bozz33/sublimeadmin/config"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// openDB opens a database connection. driver is a config database driver
+// (sqlite, sqlite3, postgres or mysql); when empty it is guessed from the
+// DSN. It returns the connection and the resolved driver.
+func openDB(driver, dsn string) (*sql.DB, string, error) {
+	if driver == "" {
+		driver = guessDriver(dsn)
+	}
+
+	var name string
+	switch driver {
+	case "sqlite", "sqlite3":
+		name = "sqlite"
+		dsn = strings.TrimPrefix(dsn, "sqlite://")
+	case "postgres", "postgresql", "pgx":
+		driver, name = "postgres", "pgx"
+	case "mysql":
+		name = "mysql"
+		dsn = strings.TrimPrefix(dsn, "mysql://")
+	default:
+		return nil, "", fmt.Errorf("unsupported database driver %q", driver)
+	}
+
+	db, err := sql.Open(name, dsn)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, "", fmt.Errorf("failed to connect to %s: %w", driver, err)
+	}
+	return db, driver, nil
+}
+
+// guessDriver guesses the database driver of a DSN.
+func guessDriver(dsn string) string {
+	switch {
+	case strings.HasPrefix(dsn, "postgres://"), strings.HasPrefix(dsn, "postgresql://"):
+		return "postgres"
+	case strings.HasPrefix(dsn, "mysql://"), strings.Contains(dsn, "@tcp("):
+		return "mysql"
+	default:
+		return "sqlite"
+	}
+}
+
+// openProjectDB opens the database of --dsn, or of the project
+// configuration (config.yaml and SUBLIME_DATABASE_* variables) when no DSN
+// is given.
+func openProjectDB(driver, dsn string) (*sql.DB, string, error) {
+	if dsn == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, "", err
+		}
+		driver, dsn = cfg.Database.Driver, cfg.Database.URL
+	}
+	return openDB(driver, dsn)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/bozz33/sublimeadmin/config"
+	"github.com/bozz33/sublimeadmin/devserver"
+)
+
+func dev(args []string) {
+	// Arguments after "--" are passed to the application.
+	var appArgs []string
+	for i, arg := range args {
+		if arg == "--" {
+			args, appArgs = args[:i], args[i+1:]
+			break
+		}
+	}
+
+	defaults := devserver.DefaultConfig()
+	fs := flag.NewFlagSet("dev", flag.ExitOnError)
+	root := fs.String("root", ".", "Module root directory")
+	proxy := fs.String("proxy", defaults.ProxyAddr, "Address of the reloading proxy")
+	addr := fs.String("addr", "", "Address the application listens on (default: server host and port of the project config)")
+	build := fs.String("build", strings.Join(defaults.Build, " "), "Build command")
+	bin := fs.String("bin", defaults.Bin, "Binary produced by the build command")
+	noTempl := fs.Bool("no-templ", false, "Do not run templ generate on .templ changes")
+	noScan := fs.Bool("no-scan", false, "Do not regenerate internal/registry/provider_gen.go")
+	_ = fs.Parse(args)
+
+	cfg := devserver.DefaultConfig()
+	cfg.Root = *root
+	cfg.ProxyAddr = *proxy
+	cfg.AppAddr = firstNonEmpty(*addr, projectAddr(), defaults.AppAddr)
+	cfg.Build = strings.Fields(*build)
+	cfg.Bin = *bin
+	cfg.Args = appArgs
+	cfg.Templ = !*noTempl
+	cfg.Scan = !*noScan
+
+	s, err := devserver.NewWithConfig(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Dev server error: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Open http://%s (Ctrl+C to stop)\n", cfg.ProxyAddr)
+	if err := s.Run(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "Dev server error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// projectAddr returns the address of the project configuration, or "".
+func projectAddr() string {
+	cfg, err := config.Load()
+	if err != nil || cfg.Server.Port == 0 {
+		return ""
+	}
+	host := cfg.Server.Host
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, strconv.Itoa(cfg.Server.Port))
+}
@@ -1,11 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 
 	"github.com/bozz33/sublimeadmin/generator"
+	"github.com/bozz33/sublimeadmin/scanner"
 )
 
 const version = "1.0.0"
@@ -19,6 +26,10 @@ func main() {
 	command := os.Args[1]
 
 	switch command {
+	case "new":
+		newProject(os.Args[2:])
+	case "dev":
+		dev(os.Args[2:])
 	case "make:resource":
 		makeResource(os.Args[2:])
 	case "make:page":
@@ -29,6 +40,28 @@ func main() {
 		makeEnum(os.Args[2:])
 	case "make:action":
 		makeAction(os.Args[2:])
+	case "make:migration":
+		makeMigration(os.Args[2:])
+	case "make:seeder":
+		makeSeeder(os.Args[2:])
+	case "migrate":
+		runMigrate(os.Args[2:], "up")
+	case "migrate:rollback":
+		runMigrate(os.Args[2:], "down")
+	case "migrate:status":
+		runMigrate(os.Args[2:], "status")
+	case "db:seed":
+		dbSeed(os.Args[2:])
+	case "user:create":
+		userCreate(os.Args[2:])
+	case "user:reset-password":
+		userResetPassword(os.Args[2:])
+	case "user:promote":
+		userPromote(os.Args[2:])
+	case "scan":
+		scan(os.Args[2:])
+	case "templates:publish":
+		publishTemplates(os.Args[2:])
 	case "version", "--version", "-v":
 		fmt.Printf("SublimeAdmin CLI v%s\n", version)
 	case "help", "--help", "-h":
@@ -46,27 +79,81 @@ func makeResource(args []string) {
 	force := fs.Bool("force", false, "Overwrite existing files")
 	dryRun := fs.Bool("dry-run", false, "Show what would be generated without writing")
 	verbose := fs.Bool("verbose", false, "Verbose output")
-	_ = fs.Parse(args)
+	templatePack := fs.String("template-pack", "", "Template pack directory or name in .sublimego/packs")
+	relationFlags := map[string]*stringList{
+		generator.RelationBelongsTo:  {},
+		generator.RelationHasOne:     {},
+		generator.RelationHasMany:    {},
+		generator.RelationManyToMany: {},
+	}
+	fs.Var(relationFlags[generator.RelationBelongsTo], "belongs-to", "Belongs-to relation name[:slug] (repeatable)")
+	fs.Var(relationFlags[generator.RelationHasOne], "has-one", "Has-one relation name[:slug] (repeatable)")
+	fs.Var(relationFlags[generator.RelationHasMany], "has-many", "Has-many relation name[:slug] (repeatable)")
+	fs.Var(relationFlags[generator.RelationManyToMany], "many-to-many", "Many-to-many relation name[:slug] (repeatable)")
+	fromSchema := fs.Bool("from-schema", false, "Read the fields from the existing Ent schema")
+	fromTable := fs.String("from-table", "", "Read the fields from a database table")
+	dsn := fs.String("dsn", "", "Database DSN for --from-table")
+	driver := fs.String("driver", "", "Database driver for --from-table (sqlite, postgres, mysql; default: guessed from --dsn)")
+	withTests := fs.Bool("with-tests", false, "Also generate table-driven tests (resource_test.go)")
+	_ = parseInterspersed(fs, args)
 
 	name := fs.Arg(0)
 	if name == "" {
 		fmt.Fprintln(os.Stderr, "Usage: sublimego make:resource <Name> [flags]")
-		fmt.Fprintln(os.Stderr, "Example: sublimego make:resource User --output=./")
+		fmt.Fprintln(os.Stderr, "Example: sublimego make:resource Post --belongs-to author:users --has-many comments")
 		os.Exit(1)
 	}
 
+	var relations []generator.RelationData
+	for _, kind := range []string{
+		generator.RelationBelongsTo,
+		generator.RelationHasOne,
+		generator.RelationHasMany,
+		generator.RelationManyToMany,
+	} {
+		parsed, err := generator.ParseRelations(kind, *relationFlags[kind]...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid relation: %v\n", err)
+			os.Exit(1)
+		}
+		relations = append(relations, parsed...)
+	}
+
 	gen, err := generator.New(&generator.Options{
-		Force:     *force,
-		DryRun:    *dryRun,
-		Verbose:   *verbose,
-		OutputDir: *output,
+		Force:        *force,
+		DryRun:       *dryRun,
+		Verbose:      *verbose,
+		OutputDir:    *output,
+		WithTests:    *withTests,
+		TemplatePack: *templatePack,
 	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Generator error: %v\n", err)
 		os.Exit(1)
 	}
 
-	if err := generator.GenerateResource(gen, name, *output); err != nil {
+	switch {
+	case *fromSchema && *fromTable != "":
+		fmt.Fprintln(os.Stderr, "--from-schema and --from-table cannot be combined")
+		os.Exit(1)
+	case *fromSchema:
+		err = generator.GenerateResourceFromSchema(gen, name, *output, relations...)
+	case *fromTable != "":
+		if *dsn == "" {
+			fmt.Fprintln(os.Stderr, "--from-table requires --dsn")
+			os.Exit(1)
+		}
+		db, dialect, openErr := openDB(*driver, *dsn)
+		if openErr != nil {
+			fmt.Fprintf(os.Stderr, "Database error: %v\n", openErr)
+			os.Exit(1)
+		}
+		err = generator.GenerateResourceFromTable(context.Background(), gen, name, *output, db, dialect, *fromTable, relations...)
+		db.Close()
+	default:
+		err = generator.GenerateResourceWithRelations(gen, name, *output, relations...)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error generating resource: %v\n", err)
 		os.Exit(1)
 	}
@@ -76,6 +163,40 @@ func makeResource(args []string) {
 	fmt.Printf("   Slug: %s\n", data.Slug)
 	fmt.Printf("   Package: %s\n", data.PackageName)
 	fmt.Printf("   Type: %s\n", data.TypeName)
+	for _, rel := range relations {
+		fmt.Printf("   Relation: %s %s -> %s\n", rel.Kind, rel.Name, rel.RelatedSlug)
+	}
+	if *withTests {
+		fmt.Println("   Tests: resource_test.go (run 'go test ./internal/resources/" + data.PackageName + "/')")
+	}
+}
+
+// stringList is a repeatable flag; each value may also hold a
+// comma-separated list.
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// parseInterspersed parses flags placed before or after the positional
+// arguments, so "make:resource Post --has-many comments" works.
+func parseInterspersed(fs *flag.FlagSet, args []string) error {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	return fs.Parse(positional)
 }
 
 func makePage(args []string) {
@@ -85,6 +206,7 @@ func makePage(args []string) {
 	output := fs.String("output", ".", "Output directory")
 	force := fs.Bool("force", false, "Overwrite existing files")
 	verbose := fs.Bool("verbose", false, "Verbose output")
+	templatePack := fs.String("template-pack", "", "Template pack directory or name in .sublimego/packs")
 	_ = fs.Parse(args)
 
 	name := fs.Arg(0)
@@ -95,9 +217,10 @@ func makePage(args []string) {
 	}
 
 	gen, err := generator.New(&generator.Options{
-		Force:     *force,
-		Verbose:   *verbose,
-		OutputDir: *output,
+		Force:        *force,
+		Verbose:      *verbose,
+		OutputDir:    *output,
+		TemplatePack: *templatePack,
 	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Generator error: %v\n", err)
@@ -117,6 +240,7 @@ func makeWidget(args []string) {
 	output := fs.String("output", ".", "Output directory")
 	force := fs.Bool("force", false, "Overwrite existing files")
 	verbose := fs.Bool("verbose", false, "Verbose output")
+	templatePack := fs.String("template-pack", "", "Template pack directory or name in .sublimego/packs")
 	_ = fs.Parse(args)
 
 	name := fs.Arg(0)
@@ -127,9 +251,10 @@ func makeWidget(args []string) {
 	}
 
 	gen, err := generator.New(&generator.Options{
-		Force:     *force,
-		Verbose:   *verbose,
-		OutputDir: *output,
+		Force:        *force,
+		Verbose:      *verbose,
+		OutputDir:    *output,
+		TemplatePack: *templatePack,
 	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Generator error: %v\n", err)
@@ -149,6 +274,7 @@ func makeEnum(args []string) {
 	output := fs.String("output", ".", "Output directory")
 	force := fs.Bool("force", false, "Overwrite existing files")
 	verbose := fs.Bool("verbose", false, "Verbose output")
+	templatePack := fs.String("template-pack", "", "Template pack directory or name in .sublimego/packs")
 	_ = fs.Parse(args)
 
 	name := fs.Arg(0)
@@ -159,9 +285,10 @@ func makeEnum(args []string) {
 	}
 
 	gen, err := generator.New(&generator.Options{
-		Force:     *force,
-		Verbose:   *verbose,
-		OutputDir: *output,
+		Force:        *force,
+		Verbose:      *verbose,
+		OutputDir:    *output,
+		TemplatePack: *templatePack,
 	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Generator error: %v\n", err)
@@ -179,6 +306,7 @@ func makeAction(args []string) {
 	output := fs.String("output", ".", "Output directory")
 	force := fs.Bool("force", false, "Overwrite existing files")
 	verbose := fs.Bool("verbose", false, "Verbose output")
+	templatePack := fs.String("template-pack", "", "Template pack directory or name in .sublimego/packs")
 	_ = fs.Parse(args)
 
 	name := fs.Arg(0)
@@ -189,9 +317,10 @@ func makeAction(args []string) {
 	}
 
 	gen, err := generator.New(&generator.Options{
-		Force:     *force,
-		Verbose:   *verbose,
-		OutputDir: *output,
+		Force:        *force,
+		Verbose:      *verbose,
+		OutputDir:    *output,
+		TemplatePack: *templatePack,
 	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Generator error: %v\n", err)
@@ -204,6 +333,76 @@ func makeAction(args []string) {
 	}
 }
 
+func publishTemplates(args []string) {
+	fs := flag.NewFlagSet("templates:publish", flag.ExitOnError)
+	output := fs.String("output", ".", "Project root directory")
+	pack := fs.String("pack", "", "Publish into .sublimego/packs/<pack> instead of .sublimego/templates")
+	force := fs.Bool("force", false, "Overwrite existing templates")
+	_ = parseInterspersed(fs, args)
+
+	dir := filepath.Join(*output, generator.ProjectTemplateDir)
+	if *pack != "" {
+		dir = filepath.Join(*output, generator.ProjectPackDir, *pack)
+	}
+
+	written, err := generator.PublishTemplates(dir, *force, fs.Args()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error publishing templates: %v\n", err)
+		os.Exit(1)
+	}
+	for _, path := range written {
+		fmt.Printf("Published: %s\n", path)
+	}
+	fmt.Printf("\n%d template(s) published to %s\n", len(written), dir)
+}
+
+func scan(args []string) {
+	fs := flag.NewFlagSet("scan", flag.ExitOnError)
+	root := fs.String("root", ".", "Module root directory")
+	output := fs.String("output", filepath.Join("internal", "registry", "provider_gen.go"), "Generated provider file")
+	pkg := fs.String("package", "", "Package name of the generated file (default: output directory name)")
+	watch := fs.Bool("watch", false, "Watch for changes and rescan changed packages")
+	verbose := fs.Bool("verbose", false, "Verbose output")
+	_ = fs.Parse(args)
+
+	s, err := scanner.New(&scanner.Options{
+		Root:    *root,
+		Output:  *output,
+		Package: *pkg,
+		Verbose: *verbose,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Scanner error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if _, err := s.Scan(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error scanning: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Registered %d resource(s) in %s\n", len(s.Registrations()), s.Output())
+	if !*watch {
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Println("Watching for changes (Ctrl+C to stop)...")
+	err = s.Watch(ctx, func(diff *scanner.Diff, err error) {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error scanning: %v\n", err)
+			return
+		}
+		fmt.Print(diff)
+		fmt.Printf("Registered %d resource(s) in %s\n", len(s.Registrations()), s.Output())
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintf(os.Stderr, "Watch error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
 func printHelp() {
 	fmt.Printf(`SublimeAdmin CLI v%s
 A code generator for the SublimeAdmin Go framework.
@@ -212,25 +411,70 @@ Usage:
   sublimego <command> [arguments] [flags]
 
 Commands:
+  new <app>              Create a runnable project (main.go, config.yaml, auth,
+                         example resource, migrations, Makefile);
+                         prompts for the module path, database and auth
+                         (--module, --driver, --dsn, --no-auth, --yes)
+  dev                    Watch the project: templ generate, scan, rebuild and
+                         restart on change, with a browser-reloading proxy
+                         (--proxy, --addr, --build, --no-templ, --no-scan;
+                         arguments after -- go to the application)
   make:resource <Name>   Generate a new resource (table + form + CRUD)
+                         (--belongs-to, --has-one, --has-many, --many-to-many
+                         name[:slug] add relations, selects and Ent edges;
+                         --from-schema or --from-table <t> --dsn <dsn> read
+                         the fields from the Ent schema or a live table;
+                         --with-tests adds table-driven CRUD, form binding
+                         and validation tests)
   make:page <Name>       Generate a custom page
   make:widget <Name>     Generate a dashboard widget
   make:enum <Name>       Generate a typed enum (HasLabel, HasColor, HasIcon)
   make:action <Name>     Generate a custom action handler
+  make:migration <name>  Generate a SQL migration with Up and Down sections
+  make:seeder <Name>     Generate a SQL seeder (--go for a Go seeder)
+  migrate                Apply pending migrations (--steps, --dry-run)
+  migrate:rollback       Roll back the last migrations (--steps, default 1)
+  migrate:status         List applied and pending migrations
+  db:seed [names...]     Run the seeders of seeders/ in file name order
+                         (--dsn/--driver default to the project config)
+  user:create            Create a user in the project database (--email,
+                         --role admin, --name; the password is prompted)
+  user:reset-password    Set a new password (sublimego user:reset-password <email>)
+  user:promote <email>   Set the role of a user (--role, default admin)
+  scan                   Register resources in internal/registry/provider_gen.go
+                         (--watch rescans changed packages incrementally)
+  templates:publish      Copy the embedded templates to .sublimego/templates
+                         (or --pack <name>) to customize the generated code
 
 Global Flags:
   --output <dir>         Output directory (default: current dir)
   --force                Overwrite existing files
   --dry-run              Show what would be generated (no writes)
   --verbose              Verbose output
+  --template-pack <pack> Template pack directory, or name in .sublimego/packs
 
 Examples:
+  sublimego new shop --module github.com/acme/shop
+  sublimego new shop --driver postgres --yes
+  sublimego dev --proxy localhost:3000
   sublimego make:resource User --output=./
   sublimego make:resource Product --output=./
+  sublimego make:resource Post --belongs-to author:users --has-many comments
+  sublimego make:resource Product --from-schema
+  sublimego make:resource Product --from-table products --dsn "file:app.db"
+  sublimego make:resource Product --from-schema --with-tests
   sublimego make:page Settings --output=./
   sublimego make:widget RevenueChart --output=./
   sublimego make:enum OrderStatus --output=./
   sublimego make:action ArchivePost --output=./
+  sublimego make:migration create_products
+  sublimego migrate --dsn "postgres://localhost/shop"
+  sublimego migrate:rollback --steps 2
+  sublimego user:create --email admin@acme.test --role admin
+  sublimego user:promote jane@acme.test --role super_admin
+  sublimego scan --watch
+  sublimego templates:publish resource form
+  sublimego make:resource Product --template-pack strict
 
 `, version)
 }
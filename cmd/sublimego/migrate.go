@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bozz33/sublimeadmin/generator"
+	"github.com/bozz33/sublimeadmin/migrate"
+)
+
+func makeMigration(args []string) {
+	fs := flag.NewFlagSet("make:migration", flag.ExitOnError)
+	output := fs.String("output", ".", "Output directory")
+	_ = parseInterspersed(fs, args)
+
+	name := fs.Arg(0)
+	if name == "" {
+		fmt.Fprintln(os.Stderr, "Usage: sublimego make:migration <name> [flags]")
+		fmt.Fprintln(os.Stderr, "Example: sublimego make:migration create_products")
+		os.Exit(1)
+	}
+
+	path, err := generator.GenerateMigrationFile(name, *output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating migration: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Migration generated: %s\n", path)
+	fmt.Println("\nNext steps:")
+	fmt.Println("  1. Fill in the Up and Down sections")
+	fmt.Println("  2. Run 'sublimego migrate'")
+}
+
+func makeSeeder(args []string) {
+	fs := flag.NewFlagSet("make:seeder", flag.ExitOnError)
+	output := fs.String("output", ".", "Output directory")
+	goSeeder := fs.Bool("go", false, "Generate a Go seeder registered with migrate.RegisterSeeder")
+	_ = parseInterspersed(fs, args)
+
+	name := fs.Arg(0)
+	if name == "" {
+		fmt.Fprintln(os.Stderr, "Usage: sublimego make:seeder <Name> [flags]")
+		fmt.Fprintln(os.Stderr, "Example: sublimego make:seeder Products")
+		os.Exit(1)
+	}
+
+	if *goSeeder {
+		if err := generator.GenerateSeeder(name, *output); err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating seeder: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Seeder generated: %s\n", filepath.Join(*output, "seeders", generator.ToSnakeCase(name)+"_seeder.go"))
+		fmt.Println("\nGo seeders run from your application with migrate.New(db).Seed(ctx).")
+		return
+	}
+
+	path, err := generator.GenerateSQLSeeder(name, *output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating seeder: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Seeder generated: %s\n", path)
+	fmt.Println("\nRun it with 'sublimego db:seed'.")
+}
+
+// migrationFlags are the flags shared by migrate, migrate:* and db:seed.
+type migrationFlags struct {
+	dsn    *string
+	driver *string
+	dir    *string
+	dryRun *bool
+}
+
+func addMigrationFlags(fs *flag.FlagSet, dirDefault, dirUsage string) migrationFlags {
+	return migrationFlags{
+		dsn:    fs.String("dsn", "", "Database DSN (default: database.url of the project config)"),
+		driver: fs.String("driver", "", "Database driver (sqlite, postgres, mysql; default: guessed from --dsn)"),
+		dir:    fs.String("dir", dirDefault, dirUsage),
+		dryRun: fs.Bool("dry-run", false, "Print the statements without running them"),
+	}
+}
+
+func (f migrationFlags) open() (*migrate.Migrator, func()) {
+	db, dialect, err := openProjectDB(*f.driver, *f.dsn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Database error: %v\n", err)
+		os.Exit(1)
+	}
+	m := migrate.NewWithConfig(db, &migrate.Config{
+		Dir:     *f.dir,
+		SeedDir: *f.dir,
+		Dialect: dialect,
+		DryRun:  *f.dryRun,
+		Out:     os.Stdout,
+	})
+	return m, func() { db.Close() }
+}
+
+func runMigrate(args []string, action string) {
+	name := "migrate"
+	if action != "up" {
+		name += ":" + map[string]string{"down": "rollback", "status": "status"}[action]
+	}
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	flags := addMigrationFlags(fs, "migrations", "Migrations directory")
+	steps := fs.Int("steps", 0, "Number of migrations to apply (default: all) or roll back (default: 1)")
+	_ = fs.Parse(args)
+
+	m, closeDB := flags.open()
+	defer closeDB()
+	ctx := context.Background()
+
+	switch action {
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			switch {
+			case s.Missing:
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05") + " (file missing)"
+			case s.Applied:
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%-12s %-40s %s\n", s.Version, s.Name, state)
+		}
+		if len(statuses) == 0 {
+			fmt.Println("No migrations found in", *flags.dir)
+		}
+
+	case "down":
+		reverted, err := m.Down(ctx, *steps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(reverted) > 0 {
+			fmt.Printf("\n%s %d migration(s)\n", dryRunVerb(*flags.dryRun, "Rolled back"), len(reverted))
+		}
+
+	default:
+		applied, err := m.Up(ctx, *steps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(applied) > 0 {
+			fmt.Printf("\n%s %d migration(s)\n", dryRunVerb(*flags.dryRun, "Applied"), len(applied))
+		}
+	}
+}
+
+func dbSeed(args []string) {
+	fs := flag.NewFlagSet("db:seed", flag.ExitOnError)
+	flags := addMigrationFlags(fs, "seeders", "Seeders directory")
+	_ = parseInterspersed(fs, args)
+
+	m, closeDB := flags.open()
+	defer closeDB()
+
+	ran, err := m.Seed(context.Background(), fs.Args()...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("\n%s %d seeder(s)\n", dryRunVerb(*flags.dryRun, "Ran"), len(ran))
+}
+
+func dryRunVerb(dryRun bool, verb string) string {
+	if dryRun {
+		return "Dry run: " + strings.ToLower(verb[:1]) + verb[1:]
+	}
+	return verb
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bozz33/sublimeadmin/generator"
+	"golang.org/x/term"
+)
+
+func newProject(args []string) {
+	fs := flag.NewFlagSet("new", flag.ExitOnError)
+	module := fs.String("module", "", "Go module path (default: example.com/<app>)")
+	driver := fs.String("driver", "", "Database driver: sqlite, postgres or mysql (default: sqlite)")
+	dsn := fs.String("dsn", "", "Database DSN (default: a local development database)")
+	noAuth := fs.Bool("no-auth", false, "Leave the panel without a login page")
+	adminEmail := fs.String("admin-email", "", "Email of the seeded admin user")
+	yes := fs.Bool("yes", false, "Accept the defaults without prompting")
+	force := fs.Bool("force", false, "Overwrite existing files")
+	noTidy := fs.Bool("no-tidy", false, "Skip go mod tidy")
+	_ = parseInterspersed(fs, args)
+
+	name := fs.Arg(0)
+	if name == "" {
+		fmt.Fprintln(os.Stderr, "Usage: sublimego new <app> [flags]")
+		fmt.Fprintln(os.Stderr, "Example: sublimego new shop --module github.com/acme/shop")
+		os.Exit(1)
+	}
+	dir := name
+	name = filepath.Base(name)
+
+	data := generator.NewProjectData(name)
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	p := newPrompter(os.Stdin, os.Stdout)
+	interactive := !*yes && p.isTerminal()
+	if interactive {
+		fmt.Printf("Creating %s in ./%s\n\n", name, dir)
+	}
+
+	data.Module = p.ask(interactive && !set["module"], "Module path", firstNonEmpty(*module, data.Module))
+	data.Driver = p.choose(interactive && !set["driver"], "Database driver", generator.ProjectDrivers, firstNonEmpty(*driver, data.Driver))
+	data.DSN = p.ask(interactive && !set["dsn"], "Database DSN", firstNonEmpty(*dsn, generator.DefaultDSN(data.Driver, name)))
+	data.Auth = p.confirm(interactive && !set["no-auth"], "Protect the panel with a login page", !*noAuth)
+	if data.Auth {
+		data.Registration = p.confirm(interactive, "Allow registration", false)
+		data.PasswordReset = p.confirm(interactive, "Enable password reset (emails are logged until a mailer is set)", false)
+
+		email := p.ask(interactive && !set["admin-email"], "Admin email (empty to skip)", firstNonEmpty(*adminEmail, "admin@example.com"))
+		if email != "" && (interactive || set["admin-email"]) {
+			for {
+				password := p.password("Admin password (8+ characters)")
+				err := data.SetAdmin(email, password)
+				if err == nil {
+					break
+				}
+				if !interactive {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+				fmt.Printf("  %v\n", err)
+			}
+		}
+	}
+
+	gen, err := generator.New(&generator.Options{Force: *force, OutputDir: dir})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Generator error: %v\n", err)
+		os.Exit(1)
+	}
+	files, err := generator.GenerateProject(gen, data, dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating project: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\nCreated %s (%s)\n", name, data.Module)
+	for _, f := range files {
+		fmt.Printf("   %s\n", filepath.Join(dir, f))
+	}
+
+	if !*noTidy {
+		fmt.Println("\nResolving dependencies (go mod tidy)...")
+		cmd := exec.Command("go", "mod", "tidy")
+		cmd.Dir = dir
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "go mod tidy failed: %v (run it again in %s)\n", err, dir)
+		}
+	}
+
+	fmt.Println("\nNext steps:")
+	fmt.Printf("  cd %s\n", dir)
+	fmt.Println("  make setup   # migrate and seed the database")
+	fmt.Println("  make run     # http://localhost:8080")
+	if data.AdminEmail != "" {
+		fmt.Printf("\nSign in as %s.\n", data.AdminEmail)
+	} else if data.Auth && !data.Registration {
+		fmt.Println("\nNo admin user was seeded: create one with 'sublimego user:create --email <email>' after make setup.")
+	}
+}
+
+// prompter asks the sublimego new questions.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	fd  int
+}
+
+func newPrompter(in *os.File, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out, fd: int(in.Fd())}
+}
+
+func (p *prompter) isTerminal() bool {
+	return term.IsTerminal(p.fd)
+}
+
+// readLine reads an answer; EOF gives an empty answer.
+func (p *prompter) readLine() string {
+	line, _ := p.in.ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
+// ask returns the answer to a question, or def when not asking or the
+// answer is empty.
+func (p *prompter) ask(ask bool, question, def string) string {
+	if !ask {
+		return def
+	}
+	if def != "" {
+		fmt.Fprintf(p.out, "? %s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "? %s: ", question)
+	}
+	if answer := p.readLine(); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// choose returns one of options.
+func (p *prompter) choose(ask bool, question string, options []string, def string) string {
+	for {
+		answer := p.ask(ask, question+" ("+strings.Join(options, ", ")+")", def)
+		if slices.Contains(options, answer) || !ask {
+			return answer
+		}
+		fmt.Fprintf(p.out, "  choose one of %s\n", strings.Join(options, ", "))
+	}
+}
+
+// confirm asks a yes/no question.
+func (p *prompter) confirm(ask bool, question string, def bool) bool {
+	if !ask {
+		return def
+	}
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		fmt.Fprintf(p.out, "? %s [%s]: ", question, hint)
+		switch strings.ToLower(p.readLine()) {
+		case "":
+			return def
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+	}
+}
+
+// password reads a password without echo on a terminal.
+func (p *prompter) password(question string) string {
+	fmt.Fprintf(p.out, "? %s: ", question)
+	if !p.isTerminal() {
+		return p.readLine()
+	}
+	b, err := term.ReadPassword(p.fd)
+	fmt.Fprintln(p.out)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"net/mail"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// userFlags are the flags shared by the user:* commands.
+type userFlags struct {
+	email  *string
+	dsn    *string
+	driver *string
+	table  *string
+}
+
+func addUserFlags(fs *flag.FlagSet) userFlags {
+	return userFlags{
+		email:  fs.String("email", "", "Email of the user"),
+		dsn:    fs.String("dsn", "", "Database DSN (default: database.url of the project config)"),
+		driver: fs.String("driver", "", "Database driver (sqlite, postgres, mysql; default: guessed from --dsn)"),
+		table:  fs.String("table", "users", "Users table"),
+	}
+}
+
+// open connects to the database; the email is --email or the first
+// argument.
+func (f userFlags) open(fs *flag.FlagSet, usage string) (*userStore, string) {
+	email := firstNonEmpty(*f.email, fs.Arg(0))
+	if email == "" {
+		fmt.Fprintf(os.Stderr, "Usage: sublimego %s\n", usage)
+		os.Exit(1)
+	}
+	if _, err := mail.ParseAddress(email); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid email %q\n", email)
+		os.Exit(1)
+	}
+
+	if !tableNamePattern.MatchString(*f.table) {
+		fmt.Fprintf(os.Stderr, "Error: invalid table name %q\n", *f.table)
+		os.Exit(1)
+	}
+
+	db, dialect, err := openProjectDB(*f.driver, *f.dsn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Database error: %v\n", err)
+		os.Exit(1)
+	}
+	return &userStore{db: db, dialect: dialect, table: *f.table}, email
+}
+
+func userCreate(args []string) {
+	fs := flag.NewFlagSet("user:create", flag.ExitOnError)
+	flags := addUserFlags(fs)
+	name := fs.String("name", "", "Name of the user (default: the email local part)")
+	role := fs.String("role", "admin", "Role of the user (stored in the role column)")
+	password := fs.String("password", "", "Password (default: prompted; read from stdin when not a terminal)")
+	_ = parseInterspersed(fs, args)
+
+	store, email := flags.open(fs, "user:create --email <email> [--role admin] [flags]")
+	defer store.db.Close()
+	ctx := context.Background()
+
+	if *name == "" {
+		*name = strings.SplitN(email, "@", 2)[0]
+	}
+	hash := hashPassword(*password)
+
+	id, roleStored, err := store.create(ctx, *name, email, hash, *role)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Created user #%d %s", id, email)
+	if roleStored {
+		fmt.Printf(" (%s)", *role)
+	}
+	fmt.Println()
+	if !roleStored {
+		fmt.Printf("The %s table has no role column: the role was not stored.\n", store.table)
+	}
+}
+
+func userResetPassword(args []string) {
+	fs := flag.NewFlagSet("user:reset-password", flag.ExitOnError)
+	flags := addUserFlags(fs)
+	password := fs.String("password", "", "New password (default: prompted; read from stdin when not a terminal)")
+	_ = parseInterspersed(fs, args)
+
+	store, email := flags.open(fs, "user:reset-password <email> [flags]")
+	defer store.db.Close()
+
+	if err := store.update(context.Background(), email, "password", hashPassword(*password)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Password of %s reset\n", email)
+}
+
+func userPromote(args []string) {
+	fs := flag.NewFlagSet("user:promote", flag.ExitOnError)
+	flags := addUserFlags(fs)
+	role := fs.String("role", "admin", "New role of the user")
+	_ = parseInterspersed(fs, args)
+
+	store, email := flags.open(fs, "user:promote <email> [--role admin] [flags]")
+	defer store.db.Close()
+	ctx := context.Background()
+
+	if ok, err := store.hasRoleColumn(ctx); err != nil || !ok {
+		fmt.Fprintf(os.Stderr, "Error: the %s table has no role column; add one with a migration:\n", store.table)
+		fmt.Fprintf(os.Stderr, "  ALTER TABLE %s ADD COLUMN role VARCHAR(50) NOT NULL DEFAULT 'user';\n", store.table)
+		os.Exit(1)
+	}
+	if err := store.update(ctx, email, "role", *role); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s is now %s\n", email, *role)
+}
+
+// hashPassword hashes password, prompting for it when empty.
+func hashPassword(password string) string {
+	if password == "" {
+		p := newPrompter(os.Stdin, os.Stdout)
+		for {
+			password = p.password("Password (8+ characters)")
+			if p.isTerminal() && p.password("Confirm password") != password {
+				fmt.Println("  the passwords do not match")
+				continue
+			}
+			if len(password) >= 8 || !p.isTerminal() {
+				break
+			}
+			fmt.Println("  the password must have at least 8 characters")
+		}
+	}
+	if len(password) < 8 {
+		fmt.Fprintln(os.Stderr, "Error: the password must have at least 8 characters")
+		os.Exit(1)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return string(hash)
+}
+
+// errUserNotFound is returned when no user has the email.
+var errUserNotFound = errors.New("user not found")
+
+// userStore manages the users table of a project (see the users
+// repository generated by sublimego new).
+type userStore struct {
+	db      *sql.DB
+	dialect string
+	table   string
+}
+
+// bind returns the n-th query placeholder of the dialect.
+func (s *userStore) bind(n int) string {
+	if s.dialect == "postgres" {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// hasRoleColumn reports whether the users table has a role column.
+func (s *userStore) hasRoleColumn(ctx context.Context) (bool, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT * FROM "+s.table+" WHERE 1 = 0")
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return false, err
+	}
+	for _, c := range columns {
+		if strings.EqualFold(c, "role") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// create inserts a user and reports whether the role was stored.
+func (s *userStore) create(ctx context.Context, name, email, hash, role string) (int64, bool, error) {
+	exists, err := s.exists(ctx, email)
+	if err != nil {
+		return 0, false, err
+	}
+	if exists {
+		return 0, false, fmt.Errorf("a user with email %s already exists (use user:reset-password or user:promote)", email)
+	}
+
+	withRole, err := s.hasRoleColumn(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	columns := []string{"name", "email", "password"}
+	args := []any{name, email, hash}
+	if withRole {
+		columns = append(columns, "role")
+		args = append(args, role)
+	}
+	binds := make([]string, len(args))
+	for i := range binds {
+		binds[i] = s.bind(i + 1)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", s.table, strings.Join(columns, ", "), strings.Join(binds, ", "))
+
+	if s.dialect == "postgres" {
+		var id int64
+		err := s.db.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&id)
+		return id, withRole, err
+	}
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, false, err
+	}
+	id, err := res.LastInsertId()
+	return id, withRole, err
+}
+
+// exists reports whether a user has the email.
+func (s *userStore) exists(ctx context.Context, email string) (bool, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM "+s.table+" WHERE email = "+s.bind(1), email).Scan(&n)
+	return n > 0, err
+}
+
+// update sets a column of the user with the email.
+func (s *userStore) update(ctx context.Context, email, column string, value any) error {
+	exists, err := s.exists(ctx, email)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s", errUserNotFound, email)
+	}
+	_, err = s.db.ExecContext(ctx,
+		fmt.Sprintf("UPDATE %s SET %s = %s WHERE email = %s", s.table, column, s.bind(1), s.bind(2)), value, email)
+	return err
+}
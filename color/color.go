@@ -62,6 +62,22 @@ func (Color) FromRGB(r, g, b int) *Palette {
 	return generatePaletteFromRGB(r, g, b)
 }
 
+// Parse returns the built-in palette with the given name ("blue"), or a
+// palette generated from a hex ("#3b82f6") or RGB ("rgb(59, 130, 246)")
+// color. It returns nil for unknown names.
+func Parse(value string) *Palette {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == "":
+		return nil
+	case strings.HasPrefix(value, "#"):
+		return Color{}.Hex(value)
+	case strings.HasPrefix(value, "rgb"):
+		return Color{}.RGB(value)
+	}
+	return BuiltIn[strings.ToLower(value)]
+}
+
 // generatePaletteFromRGB creates a full Tailwind-style palette (50-950) from a base RGB color.
 // Uses HSL color space to generate lighter and darker shades.
 func generatePaletteFromRGB(r, g, b int) *Palette {
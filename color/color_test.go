@@ -146,3 +146,18 @@ func TestColorInvalidHex(t *testing.T) {
 		t.Errorf("expected 11 shades in fallback, got %d", len(palette.Shades))
 	}
 }
+
+func TestParse(t *testing.T) {
+	if got := color.Parse("Slate"); got != color.Slate {
+		t.Errorf("expected the slate palette, got %v", got)
+	}
+	if got := color.Parse("#3b82f6"); got == nil || len(got.Shades) != 11 {
+		t.Errorf("expected a generated palette, got %v", got)
+	}
+	if got := color.Parse("rgb(59, 130, 246)"); got == nil {
+		t.Error("expected a generated palette for rgb()")
+	}
+	if got := color.Parse("unknown"); got != nil {
+		t.Errorf("expected nil for an unknown name, got %v", got)
+	}
+}
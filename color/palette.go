@@ -140,16 +140,102 @@ var Cyan = &Palette{
 	},
 }
 
+// Pink palette.
+var Pink = &Palette{
+	Name: "pink",
+	Shades: []Shade{
+		{50, "#fdf2f8"}, {100, "#fce7f3"}, {200, "#fbcfe8"},
+		{300, "#f9a8d4"}, {400, "#f472b6"}, {500, "#ec4899"},
+		{600, "#db2777"}, {700, "#be185d"}, {800, "#9d174d"},
+		{900, "#831843"}, {950, "#500724"},
+	},
+}
+
+// Emerald palette.
+var Emerald = &Palette{
+	Name: "emerald",
+	Shades: []Shade{
+		{50, "#ecfdf5"}, {100, "#d1fae5"}, {200, "#a7f3d0"},
+		{300, "#6ee7b7"}, {400, "#34d399"}, {500, "#10b981"},
+		{600, "#059669"}, {700, "#047857"}, {800, "#065f46"},
+		{900, "#064e3b"}, {950, "#022c22"},
+	},
+}
+
+// Yellow palette.
+var Yellow = &Palette{
+	Name: "yellow",
+	Shades: []Shade{
+		{50, "#fefce8"}, {100, "#fef9c3"}, {200, "#fef08a"},
+		{300, "#fde047"}, {400, "#facc15"}, {500, "#eab308"},
+		{600, "#ca8a04"}, {700, "#a16207"}, {800, "#854d0e"},
+		{900, "#713f12"}, {950, "#422006"},
+	},
+}
+
+// Gray is the default neutral palette (Tailwind gray).
+var Gray = &Palette{
+	Name: "gray",
+	Shades: []Shade{
+		{50, "#f9fafb"}, {100, "#f3f4f6"}, {200, "#e5e7eb"},
+		{300, "#d1d5db"}, {400, "#9ca3af"}, {500, "#6b7280"},
+		{600, "#4b5563"}, {700, "#374151"}, {800, "#1f2937"},
+		{900, "#111827"}, {950, "#030712"},
+	},
+}
+
+// Slate neutral palette (blue-tinted).
+var Slate = &Palette{
+	Name: "slate",
+	Shades: []Shade{
+		{50, "#f8fafc"}, {100, "#f1f5f9"}, {200, "#e2e8f0"},
+		{300, "#cbd5e1"}, {400, "#94a3b8"}, {500, "#64748b"},
+		{600, "#475569"}, {700, "#334155"}, {800, "#1e293b"},
+		{900, "#0f172a"}, {950, "#020617"},
+	},
+}
+
+// Zinc neutral palette.
+var Zinc = &Palette{
+	Name: "zinc",
+	Shades: []Shade{
+		{50, "#fafafa"}, {100, "#f4f4f5"}, {200, "#e4e4e7"},
+		{300, "#d4d4d8"}, {400, "#a1a1aa"}, {500, "#71717a"},
+		{600, "#52525b"}, {700, "#3f3f46"}, {800, "#27272a"},
+		{900, "#18181b"}, {950, "#09090b"},
+	},
+}
+
+// Stone neutral palette (warm).
+var Stone = &Palette{
+	Name: "stone",
+	Shades: []Shade{
+		{50, "#fafaf9"}, {100, "#f5f5f4"}, {200, "#e7e5e4"},
+		{300, "#d6d3d1"}, {400, "#a8a29e"}, {500, "#78716c"},
+		{600, "#57534e"}, {700, "#44403c"}, {800, "#292524"},
+		{900, "#1c1917"}, {950, "#0c0a09"},
+	},
+}
+
 // BuiltIn is the registry of all built-in palettes by name.
 var BuiltIn = map[string]*Palette{
-	"green":  Green,
-	"blue":   Blue,
-	"purple": Purple,
-	"red":    Red,
-	"orange": Orange,
-	"indigo": Indigo,
-	"teal":   Teal,
-	"rose":   Rose,
-	"amber":  Amber,
-	"cyan":   Cyan,
+	"green":   Green,
+	"blue":    Blue,
+	"purple":  Purple,
+	"red":     Red,
+	"orange":  Orange,
+	"indigo":  Indigo,
+	"teal":    Teal,
+	"rose":    Rose,
+	"amber":   Amber,
+	"cyan":    Cyan,
+	"pink":    Pink,
+	"emerald": Emerald,
+	"yellow":  Yellow,
+
+	// Neutral palettes, for the gray color of the themes.
+	"gray":  Gray,
+	"slate": Slate,
+	"zinc":  Zinc,
+	"stone": Stone,
 }
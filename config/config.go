@@ -2,7 +2,10 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/bozz33/sublimeadmin/logger"
 )
 
 // Config is the main configuration structure.
@@ -16,6 +19,16 @@ type Config struct {
 	Logging     LoggingConfig  `mapstructure:"logging" validate:"required"`
 	Security    SecurityConfig `mapstructure:"security" validate:"required"`
 	Features    FeaturesConfig `mapstructure:"features"`
+	// Flags are the feature flags of the flags section; see FlagEnabled.
+	Flags map[string]bool `mapstructure:"flags"`
+	// Plugins enables, disables and configures plugins by name; see
+	// plugin.Configure.
+	Plugins map[string]PluginConfig `mapstructure:"plugins"`
+
+	// settings are the raw settings, for Lookup and Get.
+	settings map[string]any
+	// scopes caches the panel and tenant configs built by Scoped.
+	scopes *scopeCache
 }
 
 // AppConfig holds application metadata.
@@ -82,6 +95,42 @@ type LoggingConfig struct {
 	MaxAge           int    `mapstructure:"max_age" validate:"min=0"`
 	EnableCaller     bool   `mapstructure:"enable_caller"`
 	EnableStacktrace bool   `mapstructure:"enable_stacktrace"`
+
+	// Sinks fans logs out to several destinations, each with its own level.
+	// When set, Output and FilePath are ignored.
+	Sinks []logger.SinkConfig `mapstructure:"sinks" validate:"dive"`
+}
+
+// LoggerConfig converts the logging section to a logger.Config.
+//
+//	log, err := logger.Open(cfg.LoggerConfig())
+func (c *Config) LoggerConfig() *logger.Config {
+	l := c.Logging
+	sinks := make([]logger.SinkConfig, 0, len(l.Sinks))
+	for _, s := range l.Sinks {
+		if s.Format == "" {
+			s.Format = l.Format
+		}
+		sinks = append(sinks, s)
+	}
+
+	if len(sinks) == 0 {
+		sink := logger.SinkConfig{Type: l.Output, Format: l.Format}
+		if l.Output == logger.SinkFile {
+			sink.Path = l.FilePath
+			sink.MaxSizeMB = l.MaxSize
+			sink.MaxBackups = l.MaxBackups
+			sink.MaxAgeDays = l.MaxAge
+		}
+		sinks = append(sinks, sink)
+	}
+
+	return &logger.Config{
+		Environment: c.Environment,
+		Level:       logger.ParseLevel(l.Level),
+		AddSource:   l.EnableCaller,
+		Sinks:       sinks,
+	}
 }
 
 // SecurityConfig holds security settings.
@@ -106,6 +155,31 @@ type FeaturesConfig struct {
 	EnableSwagger   bool `mapstructure:"enable_swagger"`
 }
 
+// PluginConfig configures a plugin:
+//
+//	plugins:
+//	  blog:
+//	    enabled: false
+//	  seo:
+//	    settings:
+//	      sitemap: true
+type PluginConfig struct {
+	// Enabled disables the plugin when false; plugins are enabled by default.
+	Enabled *bool `mapstructure:"enabled"`
+	// Settings are passed to the plugin (see plugin.Panel.Settings).
+	Settings map[string]any `mapstructure:"settings"`
+}
+
+// PluginEnabled reports whether the plugin name is enabled: plugins are
+// enabled unless plugins.<name>.enabled is false.
+func (c *Config) PluginEnabled(name string) bool {
+	if c == nil {
+		return true
+	}
+	pc, ok := c.Plugins[strings.ToLower(name)]
+	return !ok || pc.Enabled == nil || *pc.Enabled
+}
+
 // IsDevelopment returns true if running in development mode.
 func (c *Config) IsDevelopment() bool {
 	return c.Environment == "development"
@@ -6,9 +6,14 @@
 //
 // Features:
 //   - YAML configuration files
-//   - Environment-specific overrides
-//   - Hot-reload with file watching
-//   - Type-safe configuration structs
+//   - Environment variable overlay (SUBLIME_SERVER_PORT overrides server.port)
+//   - ${VAR}, ${VAR:-default} and ${file:/run/secrets/x} references
+//   - Remote providers: HTTP/S3, Consul KV and etcd, merged over the file
+//   - Validation with a report of every invalid key
+//   - Hot-reload with file watching, and remote provider watching
+//   - Type-safe configuration structs, and config.Get[T] for other keys
+//   - Feature flags (flags section) checked with config.FlagEnabled
+//   - Per-panel and per-tenant sections resolved with config.ForTenant
 //   - Default values
 //
 // Basic usage:
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// referencePattern matches ${VAR}, ${VAR:-default} and ${file:/path}.
+var referencePattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// EnvVar returns the environment variable overriding a dotted key:
+// "database.url" gives SUBLIME_DATABASE_URL with the default prefix.
+func (l *Loader) EnvVar(key string) string {
+	name := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	if l.options.EnvPrefix == "" {
+		return name
+	}
+	return strings.ToUpper(l.options.EnvPrefix) + "_" + name
+}
+
+// bindEnvironmentVariables overlays environment variables over every key of
+// Config and of the config file, e.g. SUBLIME_SERVER_PORT over server.port.
+func (l *Loader) bindEnvironmentVariables() {
+	keys := configKeys(reflect.TypeOf(Config{}), "")
+	keys = append(keys, l.v.AllKeys()...)
+
+	for _, key := range keys {
+		_ = l.v.BindEnv(key, l.EnvVar(key))
+	}
+}
+
+// configKeys returns the dotted mapstructure keys of the leaf fields of t.
+func configKeys(t reflect.Type, prefix string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.SplitN(f.Tag.Get("mapstructure"), ",", 2)[0]
+		if name == "" || name == "-" || !f.IsExported() {
+			continue
+		}
+		key := prefix + name
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && ft.PkgPath() != "time" {
+			keys = append(keys, configKeys(ft, key+".")...)
+			continue
+		}
+		// Map entries (flags.*) are bound from the keys of the config file.
+		if ft.Kind() == reflect.Map {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// ExpandReferences replaces references in s:
+//
+//	${VAR}              value of the environment variable VAR
+//	${VAR:-default}     VAR, or default when VAR is unset or empty
+//	${file:/run/secrets/db_password}  content of the file (Docker/Kubernetes secrets)
+//
+// File contents are trimmed of surrounding whitespace. It returns an error
+// naming every unset variable and unreadable file.
+func ExpandReferences(s string) (string, error) {
+	var missing []string
+	out := referencePattern.ReplaceAllStringFunc(s, func(ref string) string {
+		expr := ref[2 : len(ref)-1]
+
+		if path, ok := strings.CutPrefix(expr, "file:"); ok {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				missing = append(missing, fmt.Sprintf("secret file %s: %v", path, err))
+				return ""
+			}
+			return strings.TrimSpace(string(data))
+		}
+
+		name, def, hasDefault := strings.Cut(expr, ":-")
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+		if hasDefault {
+			return def
+		}
+		missing = append(missing, "environment variable "+name+" is not set")
+		return ""
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%s", strings.Join(missing, "; "))
+	}
+	return out, nil
+}
+
+// expandSettings expands references in every string of settings, returning
+// one line per key that could not be expanded.
+func expandSettings(settings map[string]any, prefix string) []string {
+	var problems []string
+	for key, value := range settings {
+		path := prefix + key
+		switch v := value.(type) {
+		case string:
+			expanded, err := ExpandReferences(v)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", path, err))
+				continue
+			}
+			settings[key] = expanded
+		case map[string]any:
+			problems = append(problems, expandSettings(v, path+".")...)
+		case []any:
+			for i, item := range v {
+				if str, ok := item.(string); ok {
+					expanded, err := ExpandReferences(str)
+					if err != nil {
+						problems = append(problems, fmt.Sprintf("%s[%d]: %v", path, i, err))
+						continue
+					}
+					v[i] = expanded
+				}
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// decode expands references in the settings of v and unmarshals them.
+func (l *Loader) decode(v *viper.Viper) (*Config, error) {
+	settings := v.AllSettings()
+	if problems := expandSettings(settings, ""); len(problems) > 0 {
+		return nil, fmt.Errorf("unresolved configuration references:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+
+	return decodeSettings(settings)
+}
+
+// decodeSettings unmarshals expanded settings into a Config.
+func decodeSettings(settings map[string]any) (*Config, error) {
+	expanded := viper.New()
+	if err := expanded.MergeConfigMap(settings); err != nil {
+		return nil, fmt.Errorf("failed to merge config: %w", err)
+	}
+
+	cfg := &Config{}
+	if err := expanded.Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	cfg.settings = expanded.AllSettings()
+	cfg.scopes = &scopeCache{configs: map[string]*Config{}}
+	return cfg, nil
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"context"
+	"strings"
+)
+
+const flagsKey contextKey = "flags"
+
+// Flag reports whether the feature flag name is enabled in the flags
+// section:
+//
+//	flags:
+//	  new_table_ui: true
+//
+// Unknown flags are disabled. Flags can be toggled per deployment with
+// SUBLIME_FLAGS_NEW_TABLE_UI=true.
+func (c *Config) Flag(name string) bool {
+	if c == nil {
+		return false
+	}
+	return c.Flags[strings.ToLower(name)]
+}
+
+// FlagEnabled reports whether the feature flag name is enabled for the
+// request: overrides set with ContextWithFlag first, then the configuration
+// of the request, with its panel and tenant sections (see ForTenant). Panels, resources and templates use it
+// to gate features:
+//
+//	if config.FlagEnabled(ctx, "new_table_ui") { ... }
+func FlagEnabled(ctx context.Context, name string) bool {
+	name = strings.ToLower(name)
+	if ctx != nil {
+		if overrides, ok := ctx.Value(flagsKey).(map[string]bool); ok {
+			if enabled, ok := overrides[name]; ok {
+				return enabled
+			}
+		}
+	}
+	return ForTenant(ctx).Flag(name)
+}
+
+// ContextWithFlag overrides a feature flag for the context, e.g. to enable
+// a feature for beta users or in tests.
+func ContextWithFlag(ctx context.Context, name string, enabled bool) context.Context {
+	overrides := map[string]bool{}
+	if parent, ok := ctx.Value(flagsKey).(map[string]bool); ok {
+		for k, v := range parent {
+			overrides[k] = v
+		}
+	}
+	overrides[strings.ToLower(name)] = enabled
+	return context.WithValue(ctx, flagsKey, overrides)
+}
@@ -8,14 +8,14 @@ import (
 	"strings"
 	"time"
 
-	"github.com/go-playground/validator/v10"
+	"github.com/bozz33/sublimeadmin/validation"
 	"github.com/spf13/viper"
 )
 
 // Loader manages configuration loading and validation.
 type Loader struct {
 	v         *viper.Viper
-	validator *validator.Validate
+	validator *validation.Validator
 	options   *LoadOptions
 }
 
@@ -26,6 +26,7 @@ type LoadOptions struct {
 	ConfigType        string
 	EnvPrefix         string
 	RequireConfigFile bool
+	Providers         []Provider
 }
 
 // NewLoader creates a new Loader with default options.
@@ -39,7 +40,7 @@ func NewLoader(opts ...Option) *Loader {
 		},
 		ConfigName:        "config",
 		ConfigType:        "yaml",
-		EnvPrefix:         "SublimeAdmin",
+		EnvPrefix:         "SUBLIME",
 		RequireConfigFile: false,
 	}
 
@@ -49,12 +50,14 @@ func NewLoader(opts ...Option) *Loader {
 
 	return &Loader{
 		v:         viper.New(),
-		validator: validator.New(),
+		validator: validation.New().WithFieldNameTag("mapstructure"),
 		options:   options,
 	}
 }
 
-// Load loads and validates the complete configuration.
+// Load loads and validates the complete configuration: defaults, then the
+// config file, then remote providers, then SUBLIME_* environment variables. ${VAR} and
+// ${file:/path} references are expanded before validation.
 func (l *Loader) Load() (*Config, error) {
 	if err := l.configure(); err != nil {
 		return nil, fmt.Errorf("failed to configure loader: %w", err)
@@ -66,11 +69,15 @@ func (l *Loader) Load() (*Config, error) {
 		return nil, err
 	}
 
+	if err := l.readProviders(); err != nil {
+		return nil, err
+	}
+
 	l.bindEnvironmentVariables()
 
-	cfg := &Config{}
-	if err := l.v.Unmarshal(cfg); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	cfg, err := l.decode(l.v)
+	if err != nil {
+		return nil, err
 	}
 
 	if err := l.validateTags(cfg); err != nil {
@@ -81,6 +88,7 @@ func (l *Loader) Load() (*Config, error) {
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
 
+	SetCurrent(cfg)
 	return cfg, nil
 }
 
@@ -170,69 +178,19 @@ func (l *Loader) readConfigFile() error {
 	return nil
 }
 
-// bindEnvironmentVariables automatically binds environment variables.
-func (l *Loader) bindEnvironmentVariables() {
-	l.v.AutomaticEnv()
-	l.v.SetEnvPrefix(l.options.EnvPrefix)
-	l.v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-
-	criticalVars := []string{
-		"environment",
-		"server.port",
-		"server.host",
-		"database.driver",
-		"database.url",
-		"security.secret_key",
-		"logging.level",
-	}
-
-	for _, key := range criticalVars {
-		envKey := l.options.EnvPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
-		_ = l.v.BindEnv(key, envKey)
-	}
-}
-
-// validateTags validates validation tags.
+// validateTags validates the struct tags of cfg with the validation
+// package and reports every invalid key with its environment variable.
 func (l *Loader) validateTags(cfg *Config) error {
-	if err := l.validator.Struct(cfg); err != nil {
-		return l.formatValidationError(err)
-	}
-	return nil
-}
-
-// formatValidationError formats validation errors in a readable way.
-func (l *Loader) formatValidationError(err error) error {
-	validationErrors, ok := err.(validator.ValidationErrors)
-	if !ok {
-		return err
+	errs := l.validator.PathErrors(cfg)
+	if errs.IsEmpty() {
+		return nil
 	}
 
-	var errors []string
-	for _, e := range validationErrors {
-		field := e.Field()
-		tag := e.Tag()
-		param := e.Param()
-
-		var msg string
-		switch tag {
-		case "required":
-			msg = fmt.Sprintf("'%s' is required", field)
-		case "oneof":
-			msg = fmt.Sprintf("'%s' must be one of: %s", field, param)
-		case "min":
-			msg = fmt.Sprintf("'%s' must be at least %s", field, param)
-		case "max":
-			msg = fmt.Sprintf("'%s' must be at most %s", field, param)
-		case "required_if":
-			msg = fmt.Sprintf("'%s' is required when %s", field, param)
-		default:
-			msg = fmt.Sprintf("'%s' failed validation '%s'", field, tag)
-		}
-
-		errors = append(errors, msg)
+	var lines []string
+	for _, key := range errs.Fields() {
+		lines = append(lines, fmt.Sprintf("%s: %s (env %s)", key, errs.Get(key), l.EnvVar(key)))
 	}
-
-	return fmt.Errorf("validation failed:\n  - %s", strings.Join(errors, "\n  - "))
+	return fmt.Errorf("invalid configuration:\n  - %s", strings.Join(lines, "\n  - "))
 }
 
 // generateSecureSecret generates a cryptographically secure secret.
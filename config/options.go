@@ -47,7 +47,7 @@ func WithConfigFile(file string) Option {
 	}
 }
 
-// WithEnvPrefix sets the environment variable prefix.
+// WithEnvPrefix sets the environment variable prefix ("SUBLIME" by default).
 func WithEnvPrefix(prefix string) Option {
 	return func(opts *LoadOptions) {
 		opts.EnvPrefix = prefix
@@ -109,6 +109,6 @@ func WithDockerDefaults() Option {
 	return func(opts *LoadOptions) {
 		opts.ConfigPaths = []string{}
 		opts.RequireConfigFile = false
-		opts.EnvPrefix = "SublimeAdmin"
+		opts.EnvPrefix = "SUBLIME"
 	}
 }
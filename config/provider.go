@@ -0,0 +1,194 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Provider supplies a configuration document from outside the local file
+// system (key-value store, object storage...). Documents are merged over
+// the config file, in the order providers are given, and environment
+// variables still take precedence.
+// Implement this interface in your project for other sources.
+type Provider interface {
+	// Name identifies the provider in errors and logs.
+	Name() string
+	// Load returns the document and its format ("yaml" or "json").
+	Load(ctx context.Context) (data []byte, format string, err error)
+}
+
+// WatchableProvider is a Provider able to notify changes, for hot reload.
+type WatchableProvider interface {
+	Provider
+	// Watch blocks until ctx is done, calling onChange each time the
+	// document changes. Transient errors are retried internally.
+	Watch(ctx context.Context, onChange func()) error
+}
+
+// WithProvider adds remote configuration providers.
+//
+//	cfg, err := config.Load(config.WithProvider(&config.ConsulProvider{
+//		Address: "http://consul:8500",
+//		Key:     "sublimeadmin/config.yaml",
+//	}))
+func WithProvider(providers ...Provider) Option {
+	return func(opts *LoadOptions) {
+		opts.Providers = append(opts.Providers, providers...)
+	}
+}
+
+// providerTimeout bounds the initial load of each provider.
+const providerTimeout = 30 * time.Second
+
+// readProviders merges the documents of every provider into l.v.
+func (l *Loader) readProviders() error {
+	for _, p := range l.options.Providers {
+		ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+		data, format, err := p.Load(ctx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("config provider %s: %w", p.Name(), err)
+		}
+
+		doc := viper.New()
+		doc.SetConfigType(format)
+		if err := doc.ReadConfig(bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("config provider %s: invalid %s document: %w", p.Name(), format, err)
+		}
+		if err := l.v.MergeConfigMap(doc.AllSettings()); err != nil {
+			return fmt.Errorf("config provider %s: %w", p.Name(), err)
+		}
+	}
+	return nil
+}
+
+// formatOf returns the format of a document from its file name.
+func formatOf(name, fallback string) string {
+	if fallback != "" {
+		return fallback
+	}
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		return "json"
+	case ".toml":
+		return "toml"
+	default:
+		return "yaml"
+	}
+}
+
+// waitOrDone waits d and reports whether ctx is still active.
+func waitOrDone(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// ---------------------------------------------------------------------------
+// HTTP / S3
+// ---------------------------------------------------------------------------
+
+// HTTPProvider fetches the configuration document from a URL: a config
+// service, or an S3/GCS object through its public or pre-signed URL.
+// Changes are detected by polling, with ETag revalidation when the server
+// supports it.
+type HTTPProvider struct {
+	URL     string
+	Headers map[string]string // e.g. Authorization
+	// Format defaults to the extension of the URL path, then yaml.
+	Format       string
+	PollInterval time.Duration // default 30s
+	Client       *http.Client
+
+	mu   sync.Mutex
+	etag string
+	sum  [sha256.Size]byte
+}
+
+// Name implements Provider.
+func (p *HTTPProvider) Name() string {
+	return "http " + p.URL
+}
+
+// Load implements Provider.
+func (p *HTTPProvider) Load(ctx context.Context) ([]byte, string, error) {
+	data, _, err := p.fetch(ctx, false)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, formatOf(strings.SplitN(p.URL, "?", 2)[0], p.Format), nil
+}
+
+// Watch implements WatchableProvider.
+func (p *HTTPProvider) Watch(ctx context.Context, onChange func()) error {
+	interval := p.PollInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	for waitOrDone(ctx, interval) {
+		if _, changed, err := p.fetch(ctx, true); err == nil && changed {
+			onChange()
+		}
+	}
+	return ctx.Err()
+}
+
+// fetch downloads the document and reports whether it changed since the
+// previous fetch.
+func (p *HTTPProvider) fetch(ctx context.Context, revalidate bool) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	for k, v := range p.Headers {
+		req.Header.Set(k, v)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if revalidate && p.etag != "" {
+		req.Header.Set("If-None-Match", p.etag)
+	}
+
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: providerTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+
+	sum := sha256.Sum256(data)
+	changed := sum != p.sum
+	p.sum = sum
+	p.etag = resp.Header.Get("ETag")
+	return data, changed, nil
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ConsulProvider reads the configuration document from a Consul KV key and
+// watches it with blocking queries, through the Consul HTTP API.
+type ConsulProvider struct {
+	Address string // default http://127.0.0.1:8500
+	Key     string // e.g. "sublimeadmin/config.yaml"
+	Token   string // ACL token
+	// Format defaults to the extension of Key, then yaml.
+	Format string
+	// WaitTime bounds each blocking query (default 5m).
+	WaitTime time.Duration
+	// RetryInterval is the wait after a failed query (default 5s).
+	RetryInterval time.Duration
+	Client        *http.Client
+
+	// index of the last loaded document, so Watch reports changes made
+	// after Load.
+	index atomic.Uint64
+}
+
+// Name implements Provider.
+func (p *ConsulProvider) Name() string {
+	return "consul " + p.Key
+}
+
+// Load implements Provider.
+func (p *ConsulProvider) Load(ctx context.Context) ([]byte, string, error) {
+	data, index, err := p.get(ctx, 0)
+	if err != nil {
+		return nil, "", err
+	}
+	p.index.Store(index)
+	return data, formatOf(p.Key, p.Format), nil
+}
+
+// Watch implements WatchableProvider.
+func (p *ConsulProvider) Watch(ctx context.Context, onChange func()) error {
+	retry := p.RetryInterval
+	if retry <= 0 {
+		retry = 5 * time.Second
+	}
+
+	index := p.index.Load()
+	for ctx.Err() == nil {
+		_, next, err := p.get(ctx, index)
+		if err != nil {
+			if !waitOrDone(ctx, retry) {
+				break
+			}
+			continue
+		}
+		if index != 0 && next > index {
+			onChange()
+		}
+		// Consul may reset the index (snapshot restore): start over with a
+		// non-blocking query.
+		if next < index {
+			next = 0
+		}
+		index = next
+	}
+	return ctx.Err()
+}
+
+// get reads the raw value of the key. With index > 0 it blocks until the
+// key changes or WaitTime elapses, and returns the new index.
+func (p *ConsulProvider) get(ctx context.Context, index uint64) ([]byte, uint64, error) {
+	address := strings.TrimRight(p.Address, "/")
+	if address == "" {
+		address = "http://127.0.0.1:8500"
+	}
+	query := url.Values{"raw": {""}}
+	if index > 0 {
+		wait := p.WaitTime
+		if wait <= 0 {
+			wait = 5 * time.Minute
+		}
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", wait.String())
+	}
+	u := fmt.Sprintf("%s/v1/kv/%s?%s", address, strings.TrimLeft(p.Key, "/"), query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if p.Token != "" {
+		req.Header.Set("X-Consul-Token", p.Token)
+	}
+
+	client := p.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, 0, fmt.Errorf("key %q not found", p.Key)
+	default:
+		return nil, 0, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	next, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	return data, next, nil
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EtcdProvider reads the configuration document from an etcd v3 key and
+// watches it, through the etcd gRPC gateway (JSON over HTTP).
+type EtcdProvider struct {
+	Endpoints []string // default http://127.0.0.1:2379
+	Key       string   // e.g. "/sublimeadmin/config.yaml"
+	Username  string
+	Password  string
+	// Format defaults to the extension of Key, then yaml.
+	Format string
+	// RetryInterval is the wait after a broken watch stream (default 5s).
+	RetryInterval time.Duration
+	Client        *http.Client
+}
+
+// Name implements Provider.
+func (p *EtcdProvider) Name() string {
+	return "etcd " + p.Key
+}
+
+// Load implements Provider.
+func (p *EtcdProvider) Load(ctx context.Context) ([]byte, string, error) {
+	var resp struct {
+		Kvs []struct {
+			Value string `json:"value"`
+		} `json:"kvs"`
+	}
+	err := p.call(ctx, "/v3/kv/range", map[string]string{"key": encodeKey(p.Key)}, func(body *json.Decoder) error {
+		return body.Decode(&resp)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, "", fmt.Errorf("key %q not found", p.Key)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(resp.Kvs[0].Value)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid value: %w", err)
+	}
+	return data, formatOf(p.Key, p.Format), nil
+}
+
+// Watch implements WatchableProvider.
+func (p *EtcdProvider) Watch(ctx context.Context, onChange func()) error {
+	retry := p.RetryInterval
+	if retry <= 0 {
+		retry = 5 * time.Second
+	}
+
+	request := map[string]any{
+		"create_request": map[string]string{"key": encodeKey(p.Key)},
+	}
+	for ctx.Err() == nil {
+		_ = p.call(ctx, "/v3/watch", request, func(body *json.Decoder) error {
+			for {
+				var msg struct {
+					Result struct {
+						Events []json.RawMessage `json:"events"`
+					} `json:"result"`
+				}
+				if err := body.Decode(&msg); err != nil {
+					return err
+				}
+				if len(msg.Result.Events) > 0 {
+					onChange()
+				}
+			}
+		})
+		if !waitOrDone(ctx, retry) {
+			break
+		}
+	}
+	return ctx.Err()
+}
+
+// call posts payload to path on the first endpoint answering, and passes
+// the response body to read.
+func (p *EtcdProvider) call(ctx context.Context, path string, payload any, read func(*json.Decoder) error) error {
+	endpoints := p.Endpoints
+	if len(endpoints) == 0 {
+		endpoints = []string{"http://127.0.0.1:2379"}
+	}
+	client := p.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, endpoint := range endpoints {
+		endpoint = strings.TrimRight(endpoint, "/")
+
+		token := ""
+		if p.Username != "" {
+			if token, err = p.authenticate(ctx, client, endpoint); err != nil {
+				lastErr = err
+				continue
+			}
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+path, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", token)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			lastErr = fmt.Errorf("%s: unexpected status %s", endpoint, resp.Status)
+			continue
+		}
+		err = read(json.NewDecoder(bufio.NewReader(resp.Body)))
+		resp.Body.Close()
+		return err
+	}
+	return lastErr
+}
+
+// authenticate returns a token for the configured user.
+func (p *EtcdProvider) authenticate(ctx context.Context, client *http.Client, endpoint string) (string, error) {
+	body, _ := json.Marshal(map[string]string{"name": p.Username, "password": p.Password})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/v3/auth/authenticate", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s: authentication failed: %s", endpoint, resp.Status)
+	}
+
+	var out struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	return out.Token, nil
+}
+
+// encodeKey encodes an etcd key for the JSON gateway.
+func encodeKey(key string) string {
+	return base64.StdEncoding.EncodeToString([]byte(key))
+}
@@ -0,0 +1,154 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// document is a remote document whose content can change during a test.
+type document struct {
+	mu    sync.Mutex
+	body  string
+	index int
+}
+
+func (d *document) Get() (string, int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.body, d.index
+}
+
+func (d *document) Set(body string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.body = body
+	d.index++
+}
+
+func TestLoad_HTTPProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"engine": {"brand_name": "Remote"}, "server": {"port": 7070}}`))
+	}))
+	defer srv.Close()
+
+	dir := writeConfig(t, `
+server:
+  port: 8080
+app:
+  name: From file
+`)
+	t.Setenv("SUBLIME_SERVER_PORT", "9090")
+
+	cfg, err := Load(WithConfigPaths([]string{dir}), WithProvider(&HTTPProvider{
+		URL:     srv.URL + "/config.json",
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, "Remote", cfg.Engine.BrandName)
+	assert.Equal(t, "From file", cfg.App.Name)
+	assert.Equal(t, 9090, cfg.Server.Port, "environment variables take precedence")
+}
+
+func TestLoad_ProviderError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	_, err := Load(WithConfigPaths([]string{t.TempDir()}), WithProvider(&HTTPProvider{URL: srv.URL}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "config provider http "+srv.URL)
+}
+
+func TestConsulProvider(t *testing.T) {
+	doc := &document{}
+	doc.Set("engine:\n  brand_name: Consul\n")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/kv/sublimeadmin/config.yaml", r.URL.Path)
+		assert.Equal(t, "acl", r.Header.Get("X-Consul-Token"))
+
+		// Simulate a blocking query: wait until the index moves.
+		if r.URL.Query().Get("index") != "" {
+			deadline := time.Now().Add(2 * time.Second)
+			for time.Now().Before(deadline) {
+				if _, index := doc.Get(); r.URL.Query().Get("index") != itoa(index) {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		}
+		body, index := doc.Get()
+		w.Header().Set("X-Consul-Index", itoa(index))
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	provider := &ConsulProvider{Address: srv.URL, Key: "sublimeadmin/config.yaml", Token: "acl"}
+	cfg, err := Load(WithConfigPaths([]string{t.TempDir()}), WithProvider(provider))
+	require.NoError(t, err)
+	assert.Equal(t, "Consul", cfg.Engine.BrandName)
+
+	w := Watch(cfg, WithConfigPaths([]string{t.TempDir()}), WithProvider(provider))
+	require.NotNil(t, w)
+	defer w.Stop()
+
+	changed := make(chan *Config, 1)
+	w.OnChange(func(_, new *Config) error {
+		changed <- new
+		return nil
+	})
+
+	doc.Set("engine:\n  brand_name: Updated\n")
+	select {
+	case newCfg := <-changed:
+		assert.Equal(t, "Updated", newCfg.Engine.BrandName)
+		assert.Equal(t, "Updated", w.GetConfig().Engine.BrandName)
+		assert.Equal(t, cfg.Security.SecretKey, newCfg.Security.SecretKey)
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnChange was not called")
+	}
+}
+
+func TestEtcdProvider(t *testing.T) {
+	key := "/sublimeadmin/config.yaml"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/auth/authenticate":
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "tok"})
+		case "/v3/kv/range":
+			assert.Equal(t, "tok", r.Header.Get("Authorization"))
+			var req map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(key)), req["key"])
+
+			value := base64.StdEncoding.EncodeToString([]byte("app:\n  name: Etcd\n"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"kvs": []map[string]string{{"value": value}}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	cfg, err := Load(WithConfigPaths([]string{t.TempDir()}), WithProvider(&EtcdProvider{
+		Endpoints: []string{"http://127.0.0.1:1", srv.URL},
+		Key:       key,
+		Username:  "root",
+		Password:  "pass",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "Etcd", cfg.App.Name)
+}
+
+func itoa(i int) string {
+	b, _ := json.Marshal(i)
+	return string(b)
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+)
+
+// scopeCache holds the scoped configs of a Config, built once per scope.
+type scopeCache struct {
+	mu      sync.Mutex
+	configs map[string]*Config
+}
+
+// ScopeFunc returns the scope ID (panel or tenant) of a request context,
+// or "" when there is none.
+type ScopeFunc func(ctx context.Context) string
+
+var (
+	scopeMu    sync.RWMutex
+	panelFunc  ScopeFunc
+	tenantFunc ScopeFunc
+)
+
+// RegisterPanelFunc sets how ForTenant finds the current panel. The engine
+// package registers the panel serving the request.
+func RegisterPanelFunc(fn ScopeFunc) {
+	scopeMu.Lock()
+	defer scopeMu.Unlock()
+	panelFunc = fn
+}
+
+// RegisterTenantFunc sets how ForTenant finds the current tenant. The
+// engine package registers the tenant resolved by TenantMiddleware.
+func RegisterTenantFunc(fn ScopeFunc) {
+	scopeMu.Lock()
+	defer scopeMu.Unlock()
+	tenantFunc = fn
+}
+
+// Scoped returns the configuration of the section at path ("panels.admin",
+// "tenants.acme") merged over c: keys of the section override the base
+// config, and every other key is inherited.
+//
+//	tenants:
+//	  acme:
+//	    engine:
+//	      brand_name: Acme Admin
+//	    flags:
+//	      new_table_ui: true
+//
+// It returns c when the section does not exist or cannot be decoded.
+func (c *Config) Scoped(path string) *Config {
+	if c == nil || c.scopes == nil {
+		return c
+	}
+	path = strings.ToLower(path)
+
+	c.scopes.mu.Lock()
+	defer c.scopes.mu.Unlock()
+	if scoped, ok := c.scopes.configs[path]; ok {
+		return scoped
+	}
+
+	scoped := c
+	if section, ok := c.Lookup(path); ok {
+		if overrides, ok := section.(map[string]any); ok {
+			cfg, err := decodeSettings(mergeSettings(c.settings, overrides))
+			if err != nil {
+				log.Printf("[Config] Invalid %s section, using the base config: %v", path, err)
+			} else {
+				scoped = cfg
+			}
+		}
+	}
+	c.scopes.configs[path] = scoped
+	return scoped
+}
+
+// ForPanel returns the configuration of the panels.<id> section.
+func (c *Config) ForPanel(id string) *Config {
+	return c.Scoped("panels." + id)
+}
+
+// ForTenantID returns the configuration of the tenants.<id> section.
+func (c *Config) ForTenantID(id string) *Config {
+	return c.Scoped("tenants." + id)
+}
+
+// ForTenant returns the configuration of the request: the config of the
+// context (see FromContext), then the section of the current panel, then
+// the section of the current tenant. Multi-tenant setups use it to vary
+// branding, limits or mailer settings per tenant:
+//
+//	cfg := config.ForTenant(r.Context())
+//	from := config.Value(cfg, "mailer.from", "noreply@example.com")
+func ForTenant(ctx context.Context) *Config {
+	cfg := FromContext(ctx)
+	if cfg == nil || ctx == nil {
+		return cfg
+	}
+
+	scopeMu.RLock()
+	panel, tenant := panelFunc, tenantFunc
+	scopeMu.RUnlock()
+
+	if panel != nil {
+		if id := panel(ctx); id != "" {
+			cfg = cfg.ForPanel(id)
+		}
+	}
+	if tenant != nil {
+		if id := tenant(ctx); id != "" {
+			cfg = cfg.ForTenantID(id)
+		}
+	}
+	return cfg
+}
+
+// mergeSettings returns a deep copy of base with overrides merged over it.
+func mergeSettings(base, overrides map[string]any) map[string]any {
+	out := make(map[string]any, len(base))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range overrides {
+		if sub, ok := v.(map[string]any); ok {
+			if baseSub, ok := out[k].(map[string]any); ok {
+				out[k] = mergeSettings(baseSub, sub)
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type scopeKey string
+
+func TestScoped(t *testing.T) {
+	dir := writeConfig(t, `
+engine:
+  brand_name: Admin
+  max_page_size: 100
+flags:
+  new_table_ui: false
+  exports: true
+panels:
+  admin:
+    engine:
+      default_page_size: 50
+tenants:
+  acme:
+    engine:
+      brand_name: Acme Admin
+    flags:
+      new_table_ui: true
+    mailer:
+      from: admin@acme.test
+`)
+	cfg, err := Load(WithConfigPaths([]string{dir}))
+	require.NoError(t, err)
+
+	acme := cfg.ForTenantID("acme")
+	assert.Equal(t, "Acme Admin", acme.Engine.BrandName)
+	assert.Equal(t, 100, acme.Engine.MaxPageSize, "inherited from the base config")
+	assert.True(t, acme.Flag("new_table_ui"))
+	assert.True(t, acme.Flag("exports"))
+	assert.Equal(t, "admin@acme.test", Value(acme, "mailer.from", ""))
+	assert.Same(t, acme, cfg.ForTenantID("acme"), "scoped configs are cached")
+
+	assert.Same(t, cfg, cfg.ForTenantID("unknown"))
+	assert.Equal(t, "Admin", cfg.Engine.BrandName, "the base config is unchanged")
+
+	both := cfg.ForPanel("admin").ForTenantID("acme")
+	assert.Equal(t, 50, both.Engine.DefaultPageSize)
+	assert.Equal(t, "Acme Admin", both.Engine.BrandName)
+}
+
+func TestForTenant(t *testing.T) {
+	dir := writeConfig(t, `
+tenants:
+  acme:
+    app:
+      name: Acme
+    flags:
+      beta: true
+`)
+	_, err := Load(WithConfigPaths([]string{dir}))
+	require.NoError(t, err)
+
+	RegisterTenantFunc(func(ctx context.Context) string {
+		id, _ := ctx.Value(scopeKey("tenant")).(string)
+		return id
+	})
+	t.Cleanup(func() { RegisterTenantFunc(nil) })
+
+	ctx := context.Background()
+	assert.Equal(t, "SublimeAdmin", ForTenant(ctx).App.Name)
+	assert.False(t, FlagEnabled(ctx, "beta"))
+
+	ctx = context.WithValue(ctx, scopeKey("tenant"), "acme")
+	assert.Equal(t, "Acme", ForTenant(ctx).App.Name)
+	assert.True(t, FlagEnabled(ctx, "beta"))
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// current is the configuration returned by Current and read by Get.
+var current atomic.Pointer[Config]
+
+// SetCurrent replaces the process-wide configuration. Load and the watcher
+// call it, so it only needs to be called for configs built by hand.
+func SetCurrent(cfg *Config) {
+	current.Store(cfg)
+}
+
+// Current returns the last loaded configuration, or nil before Load.
+func Current() *Config {
+	return current.Load()
+}
+
+type contextKey string
+
+const configKey contextKey = "config"
+
+// ContextWithConfig stores cfg in the context, overriding Current for
+// FromContext and FlagEnabled.
+func ContextWithConfig(ctx context.Context, cfg *Config) context.Context {
+	return context.WithValue(ctx, configKey, cfg)
+}
+
+// FromContext returns the configuration stored in the context, or Current.
+func FromContext(ctx context.Context) *Config {
+	if ctx != nil {
+		if cfg, ok := ctx.Value(configKey).(*Config); ok && cfg != nil {
+			return cfg
+		}
+	}
+	return Current()
+}
+
+// Lookup returns the value of a dotted key ("engine.brand_name",
+// "integrations.stripe.key"), including keys that have no field in Config.
+// Keys are case-insensitive.
+func (c *Config) Lookup(key string) (any, bool) {
+	if c == nil || c.settings == nil {
+		return nil, false
+	}
+
+	var value any = c.settings
+	for _, part := range strings.Split(strings.ToLower(key), ".") {
+		section, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = section[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// Value returns the dotted key of cfg converted to T, or def when the key is
+// missing or cannot be converted.
+//
+//	limit := config.Value(cfg, "exports.max_rows", 10000)
+func Value[T any](cfg *Config, key string, def T) T {
+	raw, ok := cfg.Lookup(key)
+	if !ok || raw == nil {
+		return def
+	}
+	if v, ok := convert[T](raw); ok {
+		return v
+	}
+	return def
+}
+
+// Get returns the dotted key of the current configuration converted to T,
+// or def when the key is missing or cannot be converted.
+//
+//	timeout := config.Get("integrations.stripe.timeout", 10*time.Second)
+//	name := config.Get("app.name", "Admin")
+func Get[T any](key string, def T) T {
+	return Value(Current(), key, def)
+}
+
+// convert converts a decoded setting (string, float64, bool, []any...) to T.
+func convert[T any](raw any) (T, bool) {
+	var zero T
+	if v, ok := raw.(T); ok {
+		return v, true
+	}
+
+	var (
+		out any
+		err error
+	)
+	switch any(zero).(type) {
+	case string:
+		out, err = cast.ToStringE(raw)
+	case bool:
+		out, err = cast.ToBoolE(raw)
+	case int:
+		out, err = cast.ToIntE(raw)
+	case int64:
+		out, err = cast.ToInt64E(raw)
+	case uint:
+		out, err = cast.ToUintE(raw)
+	case float64:
+		out, err = cast.ToFloat64E(raw)
+	case time.Duration:
+		out, err = cast.ToDurationE(raw)
+	case []string:
+		out, err = cast.ToStringSliceE(raw)
+	case []int:
+		out, err = cast.ToIntSliceE(raw)
+	case map[string]any:
+		out, err = cast.ToStringMapE(raw)
+	case map[string]string:
+		out, err = cast.ToStringMapStringE(raw)
+	default:
+		return zero, false
+	}
+	if err != nil {
+		return zero, false
+	}
+	return out.(T), true
+}
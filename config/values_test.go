@@ -0,0 +1,79 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	dir := writeConfig(t, `
+app:
+  name: Backoffice
+integrations:
+  stripe:
+    timeout: 3s
+    retries: "4"
+    currencies: [eur, usd]
+`)
+	cfg, err := Load(WithConfigPaths([]string{dir}))
+	require.NoError(t, err)
+	require.Same(t, cfg, Current())
+
+	assert.Equal(t, "Backoffice", Get("app.name", "Admin"))
+	assert.Equal(t, 15, Get("engine.default_page_size", 0))
+	assert.Equal(t, 3*time.Second, Get("integrations.stripe.timeout", time.Second))
+	assert.Equal(t, 4, Get("integrations.stripe.retries", 1))
+	assert.Equal(t, []string{"eur", "usd"}, Get[[]string]("integrations.stripe.currencies", nil))
+	assert.Equal(t, "fallback", Get("integrations.paypal.key", "fallback"))
+	assert.Equal(t, 7, Get("app.name", 7), "unconvertible values give the default")
+
+	assert.Equal(t, "Backoffice", Value(cfg, "APP.Name", ""))
+	assert.Equal(t, "def", Value[string](nil, "app.name", "def"))
+}
+
+func TestFlagEnabled(t *testing.T) {
+	dir := writeConfig(t, `
+flags:
+  new_table_ui: true
+  bulk_export: false
+`)
+	t.Setenv("SUBLIME_FLAGS_BULK_EXPORT", "true")
+
+	cfg, err := Load(WithConfigPaths([]string{dir}))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	assert.True(t, FlagEnabled(ctx, "new_table_ui"))
+	assert.True(t, FlagEnabled(ctx, "bulk_export"), "environment variables override flags")
+	assert.False(t, FlagEnabled(ctx, "unknown"))
+
+	ctx = ContextWithFlag(ctx, "new_table_ui", false)
+	assert.False(t, FlagEnabled(ctx, "new_table_ui"))
+	assert.True(t, FlagEnabled(ctx, "bulk_export"))
+
+	other := &Config{Flags: map[string]bool{"beta": true}}
+	assert.True(t, FlagEnabled(ContextWithConfig(context.Background(), other), "beta"))
+	assert.False(t, cfg.Flag("beta"))
+}
+
+func TestPluginEnabled(t *testing.T) {
+	dir := writeConfig(t, `
+plugins:
+  blog:
+    enabled: false
+  seo:
+    settings:
+      sitemap: true
+`)
+	cfg, err := Load(WithConfigPaths([]string{dir}))
+	require.NoError(t, err)
+
+	assert.False(t, cfg.PluginEnabled("blog"))
+	assert.True(t, cfg.PluginEnabled("seo"))
+	assert.True(t, cfg.PluginEnabled("unknown"))
+	assert.Equal(t, true, cfg.Plugins["seo"].Settings["sitemap"])
+}
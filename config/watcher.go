@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"sync"
@@ -17,6 +18,11 @@ type Watcher struct {
 	handlers []ChangeHandler
 	active   bool
 	stopCh   chan struct{}
+
+	// loader re-reads remote providers on reload; reloadMu serializes
+	// reloads, as viper is not safe for concurrent use.
+	loader   *Loader
+	reloadMu sync.Mutex
 }
 
 // ChangeHandler is a function called when the config changes.
@@ -75,17 +81,79 @@ func (w *Watcher) Stop() {
 func (w *Watcher) handleConfigChange(e fsnotify.Event) {
 	log.Printf("[Config] Configuration file modified: %s", e.Name)
 
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	// Viper replaced the file layer: merge the remote documents again.
+	if w.loader != nil {
+		if err := w.loader.readProviders(); err != nil {
+			log.Printf("[Config] Failed to read providers: %v", err)
+			return
+		}
+	}
+	w.reload()
+}
+
+// handleProviderChange reloads the configuration when the document of a
+// remote provider changed.
+func (w *Watcher) handleProviderChange(p Provider) {
+	log.Printf("[Config] Configuration modified: %s", p.Name())
+
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	// Re-read the file first so keys removed from a provider fall back to it.
+	if err := w.loader.readConfigFile(); err != nil {
+		log.Printf("[Config] Failed to read config file: %v", err)
+		return
+	}
+	if err := w.loader.readProviders(); err != nil {
+		log.Printf("[Config] Failed to read providers: %v", err)
+		log.Println("[Config] Keeping previous configuration")
+		return
+	}
+	w.reload()
+}
+
+// startProviders watches every WatchableProvider of the loader until Stop.
+func (w *Watcher) startProviders() int {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-w.stopCh
+		cancel()
+	}()
+
+	watched := 0
+	for _, p := range w.loader.options.Providers {
+		wp, ok := p.(WatchableProvider)
+		if !ok {
+			continue
+		}
+		watched++
+		go func() {
+			_ = wp.Watch(ctx, func() { w.handleProviderChange(wp) })
+		}()
+	}
+	return watched
+}
+
+// reload decodes and validates w.v, then swaps the config and notifies the
+// handlers. The previous config is kept when the new one is invalid.
+func (w *Watcher) reload() {
 	w.mu.RLock()
 	oldCfg := w.copyConfig(w.cfg)
 	w.mu.RUnlock()
 
-	newCfg := &Config{}
-	if err := w.v.Unmarshal(newCfg); err != nil {
-		log.Printf("[Config] Failed to unmarshal new config: %v", err)
+	loader := w.loader
+	if loader == nil {
+		loader = NewLoader()
+	}
+	newCfg, err := loader.decode(w.v)
+	if err != nil {
+		log.Printf("[Config] Failed to decode new config: %v", err)
 		return
 	}
 
-	loader := NewLoader()
 	if err := loader.validateTags(newCfg); err != nil {
 		log.Printf("[Config] Invalid configuration after reload: %v", err)
 		log.Println("[Config] Keeping previous configuration")
@@ -101,6 +169,7 @@ func (w *Watcher) handleConfigChange(e fsnotify.Event) {
 	w.mu.Lock()
 	w.cfg = newCfg
 	w.mu.Unlock()
+	SetCurrent(newCfg)
 
 	log.Println("[Config] Configuration reloaded successfully")
 
@@ -146,6 +215,13 @@ func (w *Watcher) copyConfig(cfg *Config) *Config {
 		copy(copied.Security.AllowedMethods, cfg.Security.AllowedMethods)
 	}
 
+	if cfg.Flags != nil {
+		copied.Flags = make(map[string]bool, len(cfg.Flags))
+		for name, enabled := range cfg.Flags {
+			copied.Flags[name] = enabled
+		}
+	}
+
 	return &copied
 }
 
@@ -156,28 +232,59 @@ func (w *Watcher) IsActive() bool {
 	return w.active
 }
 
-// Watch is a helper to enable hot reload in development only.
-func Watch(cfg *Config) *Watcher {
-	if cfg.Environment != "development" {
-		log.Println("[Config] Hot reload disabled (not in development mode)")
-		return nil
-	}
-
-	if !cfg.Features.EnableHotReload {
-		log.Println("[Config] Hot reload disabled (feature flag disabled)")
+// Watch is a helper to enable hot reload. The config file is watched in
+// development only; remote providers given with WithProvider are watched
+// in every environment, and their changes call the same OnChange handlers.
+//
+//	w := config.Watch(cfg, config.WithProvider(consul))
+//	w.OnChange(config.LogChangeHandler)
+func Watch(cfg *Config, opts ...Option) *Watcher {
+	loader := NewLoader(opts...)
+	watchFile := cfg.Environment == "development" && cfg.Features.EnableHotReload
+
+	if !watchFile && len(loader.options.Providers) == 0 {
+		if cfg.Environment != "development" {
+			log.Println("[Config] Hot reload disabled (not in development mode)")
+		} else {
+			log.Println("[Config] Hot reload disabled (feature flag disabled)")
+		}
 		return nil
 	}
 
-	loader := NewLoader()
 	if err := loader.configure(); err != nil {
 		log.Printf("[Config] Failed to configure loader for hot reload: %v", err)
 		return nil
 	}
 
 	watcher := NewWatcher(cfg, loader.GetViper())
-	if err := watcher.Start(); err != nil {
-		log.Printf("[Config] Failed to start hot reload: %v", err)
-		return nil
+	if len(loader.options.Providers) > 0 {
+		loader.setDefaults()
+		// Keep the running secret when none is configured.
+		loader.v.SetDefault("security.secret_key", cfg.Security.SecretKey)
+		if err := loader.readConfigFile(); err != nil {
+			log.Printf("[Config] Failed to start hot reload: %v", err)
+			return nil
+		}
+		if err := loader.readProviders(); err != nil {
+			log.Printf("[Config] Failed to start hot reload: %v", err)
+			return nil
+		}
+		loader.bindEnvironmentVariables()
+		watcher.loader = loader
+	}
+
+	if watchFile {
+		if err := watcher.Start(); err != nil {
+			log.Printf("[Config] Failed to start hot reload: %v", err)
+			return nil
+		}
+	}
+
+	if watcher.loader != nil {
+		watcher.mu.Lock()
+		watcher.active = true
+		watcher.mu.Unlock()
+		log.Printf("[Config] Watching %d remote provider(s)", watcher.startProviders())
 	}
 
 	return watcher
@@ -0,0 +1,371 @@
+package devserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/scanner"
+)
+
+// Config configures the development server.
+type Config struct {
+	// Root is the module directory (default ".").
+	Root string
+	// Build is the build command, run in Root.
+	Build []string
+	// Bin is the binary produced by Build, relative to Root.
+	Bin string
+	// Args are passed to the application.
+	Args []string
+	// AppAddr is the address the application listens on.
+	AppAddr string
+	// ProxyAddr is the address of the reloading proxy.
+	ProxyAddr string
+	// Templ runs TemplCommand when .templ files change.
+	Templ        bool
+	TemplCommand []string
+	// Scan regenerates the resource provider file (sublimego scan) when Go
+	// files change.
+	Scan bool
+	// Extensions are the watched file extensions.
+	Extensions []string
+	// ExcludeDirs are directory names never watched.
+	ExcludeDirs []string
+	// Debounce groups the events of an editor save or a git checkout.
+	Debounce time.Duration
+	// StartTimeout is how long to wait for the application to listen.
+	StartTimeout time.Duration
+	// StopTimeout is how long the application has to exit on interrupt
+	// before it is killed.
+	StopTimeout time.Duration
+	// Out receives the pipeline and application output.
+	Out io.Writer
+}
+
+// DefaultConfig returns the default development server configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		Root:         ".",
+		Build:        []string{"go", "build", "-o", filepath.Join("tmp", "app"), "."},
+		Bin:          filepath.Join("tmp", "app"),
+		AppAddr:      "localhost:8080",
+		ProxyAddr:    "localhost:3000",
+		Templ:        true,
+		TemplCommand: []string{"templ", "generate"},
+		Scan:         true,
+		Extensions:   []string{".go", ".templ", ".yaml", ".yml", ".sql"},
+		ExcludeDirs:  []string{"tmp", "bin", "vendor", "node_modules", "testdata"},
+		Debounce:     200 * time.Millisecond,
+		StartTimeout: 10 * time.Second,
+		StopTimeout:  5 * time.Second,
+		Out:          os.Stdout,
+	}
+}
+
+// Server rebuilds and restarts an application on change and serves it
+// through a proxy that reloads the browser.
+type Server struct {
+	cfg     *Config
+	root    string
+	scanner *scanner.Scanner
+	reload  *broadcaster
+
+	mu       sync.Mutex
+	proc     *exec.Cmd
+	exited   chan struct{}
+	buildErr string // output of the last failed step, shown by the proxy
+}
+
+// New creates a development server with the default configuration.
+func New() (*Server, error) {
+	return NewWithConfig(DefaultConfig())
+}
+
+// NewWithConfig creates a development server with a custom configuration.
+func NewWithConfig(cfg *Config) (*Server, error) {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	defaults := DefaultConfig()
+	if cfg.Root == "" {
+		cfg.Root = defaults.Root
+	}
+	if len(cfg.Build) == 0 {
+		cfg.Build = defaults.Build
+	}
+	if cfg.Bin == "" {
+		cfg.Bin = defaults.Bin
+	}
+	if cfg.AppAddr == "" {
+		cfg.AppAddr = defaults.AppAddr
+	}
+	if cfg.ProxyAddr == "" {
+		cfg.ProxyAddr = defaults.ProxyAddr
+	}
+	if len(cfg.TemplCommand) == 0 {
+		cfg.TemplCommand = defaults.TemplCommand
+	}
+	if len(cfg.Extensions) == 0 {
+		cfg.Extensions = defaults.Extensions
+	}
+	if cfg.Debounce <= 0 {
+		cfg.Debounce = defaults.Debounce
+	}
+	if cfg.StartTimeout <= 0 {
+		cfg.StartTimeout = defaults.StartTimeout
+	}
+	if cfg.StopTimeout <= 0 {
+		cfg.StopTimeout = defaults.StopTimeout
+	}
+	if cfg.Out == nil {
+		cfg.Out = io.Discard
+	}
+
+	root, err := filepath.Abs(cfg.Root)
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{cfg: cfg, root: root, reload: newBroadcaster()}
+	if cfg.Scan {
+		if s.scanner, err = scanner.New(&scanner.Options{Root: root}); err != nil {
+			return nil, fmt.Errorf("devserver: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// Run builds and starts the application, serves the proxy and rebuilds on
+// every change until ctx is done. The application is stopped on return.
+func (s *Server) Run(ctx context.Context) error {
+	var ignored []string
+	if s.scanner != nil {
+		ignored = append(ignored, s.scanner.Output())
+	}
+	w, err := newWatcher(s.root, s.cfg, ignored...)
+	if err != nil {
+		return err
+	}
+	defer w.close()
+
+	ln, err := net.Listen("tcp", s.cfg.ProxyAddr)
+	if err != nil {
+		return fmt.Errorf("devserver: proxy: %w", err)
+	}
+	srv := &http.Server{Handler: s.Handler()}
+	go srv.Serve(ln) //nolint:errcheck
+	defer srv.Close()
+	defer s.stop()
+
+	s.logf("Proxy on http://%s -> http://%s\n", ln.Addr(), s.cfg.AppAddr)
+	s.Rebuild(ctx, nil)
+
+	return w.run(ctx, s.cfg.Debounce, func(c Changes) {
+		s.logf("Changed: %s\n", c)
+		s.Rebuild(ctx, &c)
+	})
+}
+
+// Rebuild runs the pipeline: templ generate, scan, build and restart,
+// then reloads the browsers. A nil Changes runs every step. It reports
+// whether the application was restarted.
+func (s *Server) Rebuild(ctx context.Context, c *Changes) bool {
+	start := time.Now()
+
+	if s.cfg.Templ && (c == nil || len(c.Templ) > 0) {
+		if err := s.templ(ctx, c); err != nil {
+			return s.fail("templ generate", err)
+		}
+	}
+
+	if s.scanner != nil && (c == nil || len(c.GoDirs) > 0) {
+		var (
+			diff *scanner.Diff
+			err  error
+		)
+		if c == nil {
+			diff, err = s.scanner.Scan()
+		} else {
+			dirs := make([]string, len(c.GoDirs))
+			for i, dir := range c.GoDirs {
+				dirs[i] = filepath.Join(s.root, dir)
+			}
+			diff, err = s.scanner.Rescan(dirs...)
+		}
+		if err != nil {
+			return s.fail("scan", err)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(diff.String()), "\n") {
+			if line != "" {
+				s.logf("%s\n", line)
+			}
+		}
+	}
+
+	if c != nil && !c.NeedsBuild() {
+		return false
+	}
+
+	if out, err := s.command(ctx, s.cfg.Build...); err != nil {
+		return s.fail("build", fmt.Errorf("%w\n%s", err, out))
+	}
+
+	s.stop()
+	if err := s.start(); err != nil {
+		return s.fail("start", err)
+	}
+	if err := s.waitForApp(ctx); err != nil {
+		return s.fail("start", err)
+	}
+
+	s.setBuildErr("")
+	s.logf("Ready in %s\n", time.Since(start).Round(time.Millisecond))
+	s.reload.send()
+	return true
+}
+
+// templ compiles the changed .templ files, or all of them.
+func (s *Server) templ(ctx context.Context, c *Changes) error {
+	if _, err := exec.LookPath(s.cfg.TemplCommand[0]); err != nil {
+		s.logf("%s not found, skipping .templ compilation\n", s.cfg.TemplCommand[0])
+		return nil
+	}
+	if c == nil {
+		_, err := s.commandOutput(ctx, s.cfg.TemplCommand...)
+		return err
+	}
+	for _, f := range c.Templ {
+		if _, err := os.Stat(filepath.Join(s.root, f)); err != nil {
+			continue // removed
+		}
+		args := append(append([]string{}, s.cfg.TemplCommand...), "-f", f)
+		if _, err := s.commandOutput(ctx, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) commandOutput(ctx context.Context, args ...string) ([]byte, error) {
+	out, err := s.command(ctx, args...)
+	if err != nil {
+		return out, fmt.Errorf("%w\n%s", err, out)
+	}
+	return out, nil
+}
+
+// command runs a command in Root and returns its combined output.
+func (s *Server) command(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = s.root
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	err := cmd.Run()
+	return out.Bytes(), err
+}
+
+// start runs the built binary.
+func (s *Server) start() error {
+	bin := s.cfg.Bin
+	if !filepath.IsAbs(bin) {
+		bin = filepath.Join(s.root, bin)
+	}
+	cmd := exec.Command(bin, s.cfg.Args...)
+	cmd.Dir = s.root
+	cmd.Stdout, cmd.Stderr = s.cfg.Out, s.cfg.Out
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+
+	s.mu.Lock()
+	s.proc, s.exited = cmd, exited
+	s.mu.Unlock()
+	return nil
+}
+
+// stop interrupts the application, killing it after StopTimeout.
+func (s *Server) stop() {
+	s.mu.Lock()
+	proc, exited := s.proc, s.exited
+	s.proc, s.exited = nil, nil
+	s.mu.Unlock()
+	if proc == nil {
+		return
+	}
+
+	if err := proc.Process.Signal(os.Interrupt); err != nil {
+		_ = proc.Process.Kill()
+	}
+	select {
+	case <-exited:
+	case <-time.After(s.cfg.StopTimeout):
+		_ = proc.Process.Kill()
+		<-exited
+	}
+}
+
+// waitForApp waits until the application accepts connections.
+func (s *Server) waitForApp(ctx context.Context) error {
+	s.mu.Lock()
+	exited := s.exited
+	s.mu.Unlock()
+
+	deadline := time.Now().Add(s.cfg.StartTimeout)
+	for time.Now().Before(deadline) {
+		conn, err := net.DialTimeout("tcp", s.cfg.AppAddr, 200*time.Millisecond)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-exited:
+			return errors.New("the application exited")
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return fmt.Errorf("the application is not listening on %s after %s", s.cfg.AppAddr, s.cfg.StartTimeout)
+}
+
+// fail records a failed step, shown by the proxy until the next success.
+func (s *Server) fail(step string, err error) bool {
+	msg := fmt.Sprintf("%s failed: %v", step, err)
+	s.logf("%s\n", strings.TrimSpace(msg))
+	s.setBuildErr(msg)
+	s.reload.send()
+	return false
+}
+
+func (s *Server) setBuildErr(msg string) {
+	s.mu.Lock()
+	s.buildErr = msg
+	s.mu.Unlock()
+}
+
+// BuildError returns the error of the last rebuild, or "".
+func (s *Server) BuildError() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buildErr
+}
+
+func (s *Server) logf(format string, args ...any) {
+	fmt.Fprintf(s.cfg.Out, "[dev] "+format, args...)
+}
@@ -0,0 +1,194 @@
+package devserver
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T, appAddr string) *Server {
+	t.Helper()
+	s, err := NewWithConfig(&Config{Root: t.TempDir(), AppAddr: appAddr})
+	require.NoError(t, err)
+	return s
+}
+
+func TestDefaultConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Equal(t, "localhost:8080", cfg.AppAddr)
+	assert.Equal(t, "localhost:3000", cfg.ProxyAddr)
+	assert.True(t, cfg.Templ)
+	assert.True(t, cfg.Scan)
+	assert.Contains(t, cfg.Extensions, ".templ")
+}
+
+func TestProxyInjectsReloadScript(t *testing.T) {
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api" {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"ok":true}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, "<html><body><h1>Posts</h1></body></html>")
+	}))
+	defer app.Close()
+
+	u, _ := url.Parse(app.URL)
+	s := newTestServer(t, u.Host)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, body, "<h1>Posts</h1><script>")
+	assert.True(t, strings.HasSuffix(body, "</script></body></html>"))
+	assert.Equal(t, len(body), int(rec.Result().ContentLength))
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api", nil))
+	assert.Equal(t, `{"ok":true}`, rec.Body.String())
+}
+
+func TestProxyShowsBuildError(t *testing.T) {
+	s := newTestServer(t, "localhost:1")
+	s.fail("build", assert.AnError)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "build failed")
+	assert.Contains(t, rec.Body.String(), ReloadPath)
+
+	s.setBuildErr("")
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Waiting for the application")
+}
+
+func TestReloadEvents(t *testing.T) {
+	s := newTestServer(t, "localhost:1")
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + ReloadPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	lines := bufio.NewReader(resp.Body)
+	line, err := lines.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, ": connected\n", line)
+
+	// Wait for the subscription before sending.
+	require.Eventually(t, func() bool {
+		s.reload.mu.Lock()
+		defer s.reload.mu.Unlock()
+		return len(s.reload.clients) == 1
+	}, time.Second, 10*time.Millisecond)
+	s.reload.send()
+
+	_, _ = lines.ReadString('\n') // blank line
+	line, err = lines.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: reload\n", line)
+}
+
+func TestClassify(t *testing.T) {
+	root := t.TempDir()
+	w := &watcher{root: root, cfg: DefaultConfig()}
+
+	c := w.classify(map[string]bool{
+		filepath.Join(root, "main.go"):                       true,
+		filepath.Join(root, "internal", "posts", "posts.go"): true,
+		filepath.Join(root, "internal", "posts", "form.go"):  true,
+		filepath.Join(root, "views", "list.templ"):           true,
+		filepath.Join(root, "config.yaml"):                   true,
+		filepath.Join(root, "internal", "removed"):           true,
+	})
+
+	assert.Equal(t, []string{filepath.Join("views", "list.templ")}, c.Templ)
+	assert.Equal(t, []string{".", filepath.Join("internal", "posts"), filepath.Join("internal", "removed")}, c.GoDirs)
+	assert.Equal(t, []string{"config.yaml"}, c.Other)
+	assert.Len(t, c.Files, 6)
+	assert.True(t, c.NeedsBuild())
+	assert.False(t, Changes{}.NeedsBuild())
+	assert.Equal(t, "main.go", Changes{Files: []string{"main.go"}}.String())
+	assert.Equal(t, "6 files (config.yaml, internal/posts/form.go, internal/posts/posts.go, ...)", c.String())
+}
+
+func TestWatched(t *testing.T) {
+	root := t.TempDir()
+	output := filepath.Join(root, "internal", "registry", "provider_gen.go")
+	w := &watcher{root: root, cfg: DefaultConfig(), ignored: map[string]bool{output: true}}
+
+	assert.True(t, w.watched(filepath.Join(root, "main.go")))
+	assert.True(t, w.watched(filepath.Join(root, "views", "list.templ")))
+	assert.True(t, w.watched(filepath.Join(root, "config.yaml")))
+	assert.False(t, w.watched(filepath.Join(root, "views", "list_templ.go")))
+	assert.False(t, w.watched(filepath.Join(root, "main_test.go")))
+	assert.False(t, w.watched(filepath.Join(root, "README.md")))
+	assert.False(t, w.watched(output))
+
+	assert.True(t, w.skipDir("tmp"))
+	assert.True(t, w.skipDir(".git"))
+	assert.False(t, w.skipDir("internal"))
+}
+
+func TestWatcherRun(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "tmp"), 0o755))
+	w, err := newWatcher(root, DefaultConfig())
+	require.NoError(t, err)
+	defer w.close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	changes := make(chan Changes, 1)
+	go w.run(ctx, 20*time.Millisecond, func(c Changes) { //nolint:errcheck
+		changes <- c
+	})
+
+	require.NoError(t, os.WriteFile(filepath.Join(root, "tmp", "app"), []byte("bin"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte("package main"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0o644))
+
+	select {
+	case c := <-changes:
+		assert.Equal(t, []string{"main.go"}, c.Files)
+		assert.Equal(t, []string{"."}, c.GoDirs)
+	case <-ctx.Done():
+		t.Fatal("no changes reported")
+	}
+}
+
+func TestRebuildFailure(t *testing.T) {
+	s, err := NewWithConfig(&Config{
+		Root:  t.TempDir(),
+		Build: []string{"go", "build", "-o", "tmp/app", "."},
+	})
+	require.NoError(t, err)
+
+	ok := s.Rebuild(context.Background(), &Changes{GoDirs: []string{"."}, Files: []string{"main.go"}})
+	assert.False(t, ok)
+	assert.Contains(t, s.BuildError(), "build failed")
+}
@@ -0,0 +1,32 @@
+// Package devserver runs a SublimeGo application in development: it
+// watches the module, compiles the changed .templ files, regenerates the
+// resource provider file, rebuilds and restarts the application, and
+// reloads the browser through a proxy.
+//
+// Features:
+//   - Recursive watch of .go, .templ, .yaml, .yml and .sql files, debounced
+//   - templ generate on the changed .templ files only
+//   - Incremental resource scan (see package scanner)
+//   - Graceful restart: interrupt, then kill after StopTimeout
+//   - Reverse proxy injecting a reload script in HTML pages
+//   - Build errors shown in the browser until the next successful build
+//
+// Basic usage:
+//
+//	s, err := devserver.NewWithConfig(&devserver.Config{
+//		AppAddr:   "localhost:8080",
+//		ProxyAddr: "localhost:3000",
+//		Templ:     true,
+//		Scan:      true,
+//		Out:       os.Stdout,
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	err = s.Run(ctx) // open http://localhost:3000
+//
+// From the CLI:
+//
+//	sublimego dev
+//	sublimego dev --proxy localhost:3000 -- --config config.dev.yaml
+package devserver
@@ -0,0 +1,165 @@
+package devserver
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ReloadPath is the Server-Sent Events endpoint the injected script listens
+// to; a "reload" event is sent after every rebuild.
+const ReloadPath = "/__sublimego/reload"
+
+// reloadScript reloads the page on a "reload" event. When the dev server
+// itself restarts, the EventSource reconnects and the page reloads.
+const reloadScript = `<script>(function(){var es=new EventSource("` + ReloadPath + `"),lost=false;` +
+	`es.addEventListener("reload",function(){location.reload()});` +
+	`es.onerror=function(){lost=true};es.onopen=function(){if(lost)location.reload()};})();</script>`
+
+// broadcaster fans the reload events out to the connected browsers.
+type broadcaster struct {
+	mu      sync.Mutex
+	clients map[chan struct{}]struct{}
+}
+
+func newBroadcaster() *broadcaster {
+	return &broadcaster{clients: make(map[chan struct{}]struct{})}
+}
+
+func (b *broadcaster) subscribe() chan struct{} {
+	ch := make(chan struct{}, 1)
+	b.mu.Lock()
+	b.clients[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *broadcaster) unsubscribe(ch chan struct{}) {
+	b.mu.Lock()
+	delete(b.clients, ch)
+	b.mu.Unlock()
+}
+
+// send notifies every browser; a pending notification is not duplicated.
+func (b *broadcaster) send() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.clients {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Handler returns the proxy handler: it forwards requests to the
+// application, injects the reload script in HTML pages, serves the reload
+// events and shows the last build error instead of a stale application.
+func (s *Server) Handler() http.Handler {
+	target := &url.URL{Scheme: "http", Host: s.cfg.AppAddr}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.FlushInterval = -1
+
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		// Let the transport negotiate compression and decompress, so that
+		// HTML responses can be rewritten.
+		r.Header.Del("Accept-Encoding")
+	}
+	proxy.ModifyResponse = injectReloadScript
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		if wantsHTML(r) {
+			writePage(w, http.StatusBadGateway, "Starting", "<p>Waiting for the application on "+html.EscapeString(s.cfg.AppAddr)+".</p>")
+			return
+		}
+		http.Error(w, "devserver: "+err.Error(), http.StatusBadGateway)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(ReloadPath, s.serveReload)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if msg := s.BuildError(); msg != "" {
+			if wantsHTML(r) {
+				writePage(w, http.StatusInternalServerError, "Build failed", "<pre>"+html.EscapeString(msg)+"</pre>")
+				return
+			}
+			http.Error(w, msg, http.StatusServiceUnavailable)
+			return
+		}
+		proxy.ServeHTTP(w, r)
+	})
+	return mux
+}
+
+// serveReload streams the reload events.
+func (s *Server) serveReload(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	ch := s.reload.subscribe()
+	defer s.reload.unsubscribe(ch)
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ch:
+			fmt.Fprint(w, "event: reload\ndata: {}\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// injectReloadScript adds the reload script before </body> in HTML
+// responses, or at the end when there is none.
+func injectReloadScript(resp *http.Response) error {
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") || resp.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	if i := bytes.LastIndex(body, []byte("</body>")); i >= 0 {
+		body = append(body[:i:i], append([]byte(reloadScript), body[i:]...)...)
+	} else {
+		body = append(body, reloadScript...)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return nil
+}
+
+// wantsHTML reports whether a request comes from a page load.
+func wantsHTML(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+// writePage writes a minimal page that reloads on the next rebuild.
+func writePage(w http.ResponseWriter, status int, title, body string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>%s</title>
+<style>body{font-family:system-ui,sans-serif;margin:2rem;color:#1f2937}pre{background:#111827;color:#f9fafb;padding:1rem;border-radius:.5rem;overflow:auto;white-space:pre-wrap}</style>
+</head><body><h1>%s</h1>%s%s</body></html>`, title, title, body, reloadScript)
+}
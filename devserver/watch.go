@@ -0,0 +1,199 @@
+package devserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Changes are the files changed since the last rebuild, grouped by the
+// pipeline steps they trigger.
+type Changes struct {
+	// Templ are the changed .templ files, compiled by templ generate.
+	Templ []string
+	// GoDirs are the package directories of the changed Go files, rescanned
+	// for resources.
+	GoDirs []string
+	// Other are the other watched files (config, SQL, ...).
+	Other []string
+	// Files are all the changed paths.
+	Files []string
+}
+
+// NeedsBuild reports whether the changes require a rebuild.
+func (c Changes) NeedsBuild() bool {
+	return len(c.Files) > 0
+}
+
+// String summarizes the changes: "3 files (main.go, views/list.templ, ...)".
+func (c Changes) String() string {
+	const shown = 3
+	if len(c.Files) == 1 {
+		return c.Files[0]
+	}
+	names := c.Files
+	if len(names) > shown {
+		names = names[:shown]
+	}
+	summary := strings.Join(names, ", ")
+	if len(c.Files) > shown {
+		summary += ", ..."
+	}
+	return fmt.Sprintf("%d files (%s)", len(c.Files), summary)
+}
+
+// watcher watches the module tree and groups the events into Changes.
+type watcher struct {
+	fs      *fsnotify.Watcher
+	root    string
+	cfg     *Config
+	ignored map[string]bool
+}
+
+// newWatcher watches root and its directories, except ExcludeDirs and
+// hidden directories. Events on the ignored files are dropped.
+func newWatcher(root string, cfg *Config, ignored ...string) (*watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("devserver: %w", err)
+	}
+	w := &watcher{fs: fsw, root: root, cfg: cfg, ignored: map[string]bool{}}
+	for _, f := range ignored {
+		w.ignored[f] = true
+	}
+	if err := w.addTree(root); err != nil {
+		fsw.Close()
+		return nil, fmt.Errorf("devserver: %w", err)
+	}
+	return w, nil
+}
+
+func (w *watcher) close() error {
+	return w.fs.Close()
+}
+
+// addTree watches dir and its subdirectories.
+func (w *watcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != w.root && w.skipDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		return w.fs.Add(path)
+	})
+}
+
+// skipDir reports whether a directory is never watched.
+func (w *watcher) skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") ||
+		slices.Contains(w.cfg.ExcludeDirs, name)
+}
+
+// watched reports whether a file change is relevant. Generated files
+// (templ output, the scanner provider) and tests never trigger a rebuild.
+func (w *watcher) watched(path string) bool {
+	name := filepath.Base(path)
+	if w.ignored[path] || strings.HasSuffix(name, "_templ.go") || strings.HasSuffix(name, "_test.go") {
+		return false
+	}
+	return slices.Contains(w.cfg.Extensions, filepath.Ext(name))
+}
+
+// run calls fn with the changes of every burst of events, Debounce after
+// the last one. It blocks until ctx is done.
+func (w *watcher) run(ctx context.Context, debounce time.Duration, fn func(Changes)) error {
+	pending := map[string]bool{}
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if !w.skipDir(info.Name()) {
+						_ = w.addTree(event.Name)
+					}
+					continue
+				}
+			}
+			if w.watched(event.Name) || w.removedDir(event) {
+				pending[event.Name] = true
+				timer.Reset(debounce)
+			}
+
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("devserver: watch: %w", err)
+
+		case <-timer.C:
+			c := w.classify(pending)
+			pending = map[string]bool{}
+			if c.NeedsBuild() {
+				fn(c)
+			}
+		}
+	}
+}
+
+// removedDir reports whether an event removes or renames a watched
+// directory, whose packages must be rescanned.
+func (w *watcher) removedDir(event fsnotify.Event) bool {
+	return event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 &&
+		filepath.Ext(event.Name) == "" && !w.skipDir(filepath.Base(event.Name))
+}
+
+// classify groups the changed paths by pipeline step. Paths are relative
+// to the root and sorted.
+func (w *watcher) classify(pending map[string]bool) Changes {
+	var c Changes
+	dirs := map[string]bool{}
+	for path := range pending {
+		rel, err := filepath.Rel(w.root, path)
+		if err != nil {
+			rel = path
+		}
+		c.Files = append(c.Files, rel)
+		switch filepath.Ext(path) {
+		case ".templ":
+			c.Templ = append(c.Templ, rel)
+		case ".go":
+			dirs[filepath.Dir(rel)] = true
+		case "":
+			dirs[rel] = true
+		default:
+			c.Other = append(c.Other, rel)
+		}
+	}
+	for dir := range dirs {
+		c.GoDirs = append(c.GoDirs, dir)
+	}
+	sort.Strings(c.Templ)
+	sort.Strings(c.GoDirs)
+	sort.Strings(c.Other)
+	sort.Strings(c.Files)
+	return c
+}
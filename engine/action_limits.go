@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/actions"
+	"github.com/bozz33/sublimeadmin/middleware"
+)
+
+// actionLimitStore backs an actions.Limiter with the stores of the
+// middleware package.
+type actionLimitStore struct {
+	rates middleware.RateLimitStore
+	keys  middleware.IdempotencyStore
+}
+
+// NewActionLimitStore returns the store of an actions.Limiter keeping the
+// calls of the actions in rates and their idempotency keys in keys. Use the
+// Redis stores to share the limits across replicas:
+//
+//	store := engine.NewActionLimitStore(
+//		middleware.NewRedisRateLimitStore(rdb, "actions:"),
+//		middleware.NewRedisIdempotencyStore(rdb, ""),
+//	)
+//	panel.WithActionLimiter(actions.NewLimiter(store))
+func NewActionLimitStore(rates middleware.RateLimitStore, keys middleware.IdempotencyStore) actions.LimitStore {
+	return &actionLimitStore{rates: rates, keys: keys}
+}
+
+// Allow implements actions.LimitStore.
+func (s *actionLimitStore) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	result, err := middleware.AllowPerWindow(ctx, s.rates, key, limit, window)
+	if err != nil {
+		return false, 0, err
+	}
+	return result.Allowed, result.RetryAfter, nil
+}
+
+// Claim implements actions.LimitStore.
+func (s *actionLimitStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return s.keys.Claim(ctx, key, ttl)
+}
+
+// Release implements actions.LimitStore.
+func (s *actionLimitStore) Release(ctx context.Context, key string) error {
+	return s.keys.Release(ctx, key)
+}
+
+// WithActionLimiter sets the limiter enforcing the rate limits and the
+// idempotency keys of the actions (see actions.Action.RateLimit and
+// Idempotent). By default they are kept in memory, per process.
+func (p *Panel) WithActionLimiter(limiter *actions.Limiter) *Panel {
+	p.actionLimiter = limiter
+	return p
+}
+
+// defaultActionLimiter keeps the limits of the actions in memory.
+func defaultActionLimiter() *actions.Limiter {
+	return actions.NewLimiter(NewActionLimitStore(
+		middleware.NewMemoryRateLimitStore(0),
+		middleware.NewMemoryIdempotencyStore(),
+	))
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/apperrors"
 	authpkg "github.com/bozz33/sublimeadmin/auth"
 	"github.com/bozz33/sublimeadmin/ui/layouts"
 	authtemplates "github.com/bozz33/sublimeadmin/views/auth"
@@ -33,6 +34,20 @@ type FrameworkUser interface {
 	GetPassword() string
 }
 
+// UserWithRoles is implemented by users whose roles are stored with them
+// (see sublimego user:promote); the roles are copied to the session on
+// login.
+type UserWithRoles interface {
+	GetRoles() []string
+}
+
+// UserCounter is implemented by the UserRepositories that count their
+// users; registration then enforces the LimitUsers limit of the plan of
+// the tenant (see CheckLimit).
+type UserCounter interface {
+	CountUsers(ctx context.Context) (int, error)
+}
+
 // AuthHandler handles authentication routes.
 type AuthHandler struct {
 	authManager *authpkg.Manager
@@ -57,7 +72,7 @@ func (h *AuthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		case http.MethodPost:
 			h.handleLogin(w, r)
 		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			apperrors.Handle(w, r, apperrors.MethodNotAllowed(""))
 		}
 	case "/register":
 		switch r.Method {
@@ -66,7 +81,7 @@ func (h *AuthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		case http.MethodPost:
 			h.handleRegister(w, r)
 		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			apperrors.Handle(w, r, apperrors.MethodNotAllowed(""))
 		}
 	case "/logout":
 		h.handleLogout(w, r)
@@ -78,7 +93,7 @@ func (h *AuthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // showLogin displays the login page.
 func (h *AuthHandler) showLogin(w http.ResponseWriter, r *http.Request) {
 	if h.authManager.IsAuthenticatedFromRequest(r) {
-		http.Redirect(w, r, h.dashboardPath(), http.StatusFound)
+		http.Redirect(w, r, h.dashboardPath(r), http.StatusFound)
 		return
 	}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -88,7 +103,7 @@ func (h *AuthHandler) showLogin(w http.ResponseWriter, r *http.Request) {
 // handleLogin handles login form submission.
 func (h *AuthHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Invalid form", http.StatusBadRequest)
+		apperrors.Handle(w, r, apperrors.BadRequest("Invalid form"))
 		return
 	}
 
@@ -105,20 +120,33 @@ func (h *AuthHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
 		h.showLoginWithError(w, r, "Invalid email or password")
 		return
 	}
+	if !GetPanelFromContext(r.Context()).canSignIn(r.Context(), dbUser) {
+		h.showLoginWithError(w, r, "This account is disabled")
+		return
+	}
 
 	authUser := &authpkg.User{
 		ID:    dbUser.GetID(),
 		Name:  dbUser.GetName(),
 		Email: dbUser.GetEmail(),
 	}
+	if u, ok := dbUser.(UserWithRoles); ok {
+		authUser.Roles = u.GetRoles()
+	}
 
 	if err := h.authManager.LoginWithRequest(r, authUser); err != nil {
-		http.Error(w, "Login failed", http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, "Login failed"))
 		return
 	}
+	if passwordExpired(r.Context(), h.users, authUser.ID) {
+		h.authManager.Session().Put(r.Context(), passwordExpiredKey, true)
+	}
 
-	// Remember Me: extend session lifetime via a long-lived cookie
-	if r.FormValue("remember_me") == "1" || r.FormValue("remember_me") == "on" {
+	remember := r.FormValue("remember_me") == "1" || r.FormValue("remember_me") == "on"
+	if p := GetPanelFromContext(r.Context()); p.devicesEnabled() {
+		p.rememberDevice(w, r, authUser, remember)
+	} else if remember {
+		// Remember Me: extend session lifetime via a long-lived cookie
 		http.SetCookie(w, &http.Cookie{
 			Name:     "_remember",
 			Value:    "1",
@@ -131,7 +159,7 @@ func (h *AuthHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
 
 	intendedURL := h.getIntendedURL(r)
 	if intendedURL == "" {
-		intendedURL = h.dashboardPath()
+		intendedURL = h.dashboardPath(r)
 	}
 	http.Redirect(w, r, intendedURL, http.StatusFound)
 }
@@ -139,7 +167,7 @@ func (h *AuthHandler) handleLogin(w http.ResponseWriter, r *http.Request) {
 // showRegister displays the registration page.
 func (h *AuthHandler) showRegister(w http.ResponseWriter, r *http.Request) {
 	if h.authManager.IsAuthenticatedFromRequest(r) {
-		http.Redirect(w, r, h.dashboardPath(), http.StatusFound)
+		http.Redirect(w, r, h.dashboardPath(r), http.StatusFound)
 		return
 	}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -149,7 +177,7 @@ func (h *AuthHandler) showRegister(w http.ResponseWriter, r *http.Request) {
 // handleRegister handles registration form submission.
 func (h *AuthHandler) handleRegister(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Invalid form", http.StatusBadRequest)
+		apperrors.Handle(w, r, apperrors.BadRequest("Invalid form"))
 		return
 	}
 
@@ -163,14 +191,14 @@ func (h *AuthHandler) handleRegister(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(password) < 6 {
-		h.showRegisterWithError(w, r, "Password must be at least 6 characters")
+	if msg := validateNewPassword(r.Context(), h.users, 0, password); msg != "" {
+		h.showRegisterWithError(w, r, msg)
 		return
 	}
 
 	exists, err := h.users.ExistsByEmail(r.Context(), email)
 	if err != nil {
-		http.Error(w, "Database error", http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, "Database error"))
 		return
 	}
 	if exists {
@@ -178,10 +206,22 @@ func (h *AuthHandler) handleRegister(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if counter, ok := h.users.(UserCounter); ok {
+		count, err := counter.CountUsers(r.Context())
+		if err != nil {
+			apperrors.Handle(w, r, apperrors.Internal(err, "Database error"))
+			return
+		}
+		if err := CheckLimit(r.Context(), LimitUsers, count); err != nil {
+			h.showRegisterWithError(w, r, "Registrations are closed: "+err.Error()+".")
+			return
+		}
+	}
+
 	hashedPassword := h.hashPassword(password)
 	newUser, err := h.users.Create(r.Context(), name, email, hashedPassword)
 	if err != nil {
-		http.Error(w, "Failed to create user", http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, "Failed to create user"))
 		return
 	}
 	authUser := &authpkg.User{
@@ -189,23 +229,29 @@ func (h *AuthHandler) handleRegister(w http.ResponseWriter, r *http.Request) {
 		Name:  newUser.GetName(),
 		Email: newUser.GetEmail(),
 	}
+	if u, ok := newUser.(UserWithRoles); ok {
+		authUser.Roles = u.GetRoles()
+	}
 
 	if err := h.authManager.LoginWithRequest(r, authUser); err != nil {
-		http.Error(w, "Login failed", http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, "Login failed"))
 		return
 	}
 
-	http.Redirect(w, r, h.dashboardPath(), http.StatusFound)
+	http.Redirect(w, r, h.dashboardPath(r), http.StatusFound)
 }
 
 // handleLogout logs out the user.
 func (h *AuthHandler) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if p := GetPanelFromContext(r.Context()); p.devicesEnabled() {
+		p.forgetDevice(w, r)
+	}
 	if err := h.authManager.LogoutWithRequest(r); err != nil {
-		http.Error(w, "Logout failed", http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, "Logout failed"))
 		return
 	}
 
-	http.Redirect(w, r, h.loginPath(), http.StatusFound)
+	http.Redirect(w, r, h.loginPath(r), http.StatusFound)
 }
 
 // Helpers
@@ -225,9 +271,9 @@ func (h *AuthHandler) getIntendedURL(r *http.Request) string {
 }
 
 // dashboardPath returns the panel's root URL (dashboard).
-// Uses the global PanelConfig so it stays consistent with template-generated links.
-func (h *AuthHandler) dashboardPath() string {
-	cfg := layouts.GetPanelConfig()
+// Uses the request's PanelConfig so it stays consistent with template-generated links.
+func (h *AuthHandler) dashboardPath(r *http.Request) string {
+	cfg := layouts.GetPanelConfigFromContext(r.Context())
 	if cfg.Path == "" || cfg.Path == "/" {
 		return "/"
 	}
@@ -235,8 +281,8 @@ func (h *AuthHandler) dashboardPath() string {
 }
 
 // loginPath returns the panel's login URL.
-func (h *AuthHandler) loginPath() string {
-	cfg := layouts.GetPanelConfig()
+func (h *AuthHandler) loginPath(r *http.Request) string {
+	cfg := layouts.GetPanelConfigFromContext(r.Context())
 	if cfg.Path == "" || cfg.Path == "/" {
 		return "/login"
 	}
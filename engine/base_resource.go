@@ -6,8 +6,12 @@ import (
 	"io"
 	"net/http"
 	"reflect"
+	"strconv"
+	"sync"
 
 	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/actions"
+	"github.com/bozz33/sublimeadmin/infolist"
 	"github.com/bozz33/sublimeadmin/table"
 )
 
@@ -24,12 +28,25 @@ type BaseResource struct {
 	// Table configuration
 	tableColumns       []table.Column
 	tableFilters       []FilterDef
+	filterPanel        []table.Filter
 	tableBulkActions   []BulkActionDef
+	bulkActions        []*actions.BulkAction
+	rowActions         []*actions.Action
+	rowActionGroups    []*actions.ActionGroup
+	listActions        []*actions.Action
 	tableHeaderActions []HeaderAction
 	tableExportURL     string
 	tableImportURL     string
 	recordUrlFn        func(item any) string // optional: custom row URL override
 	columnManager      bool
+	tableStackBelow    table.Breakpoint
+	tablePrimaryColumn string
+	deleteConfirmFn    func(item any) string // optional: phrase typed to confirm a deletion
+	drawerForms        bool
+	pageSizes          PageSizes
+
+	// Detail page
+	infolist *infolist.Infolist
 }
 
 // NewBaseResource creates a BaseResource with required values.
@@ -90,6 +107,30 @@ func (b *BaseResource) SetTableColumns(cols ...table.Column) *BaseResource {
 	return b
 }
 
+// TableColumns returns the columns set with SetTableColumns.
+func (b *BaseResource) TableColumns(_ context.Context) []table.Column {
+	return b.tableColumns
+}
+
+// SetFilters sets the typed filters of the filter panel of the table (see
+// ResourceFilterPanel):
+//
+//	b.SetFilters(
+//		table.Select("status").WithLabel("Status").WithOptions(statuses),
+//		table.DateRange("created_at").WithLabel("Created"),
+//		table.NumberRange("total").WithLabel("Total"),
+//		table.RelationSelect("author_id").WithLabel("Author").WithOptionsFunc(authors),
+//	)
+func (b *BaseResource) SetFilters(filters ...table.Filter) *BaseResource {
+	b.filterPanel = filters
+	return b
+}
+
+// FilterPanel returns the filters set with SetFilters.
+func (b *BaseResource) FilterPanel(_ context.Context) []table.Filter {
+	return b.filterPanel
+}
+
 // SetTableFilters sets the filters for BuildTableState.
 func (b *BaseResource) SetTableFilters(filters ...FilterDef) *BaseResource {
 	b.tableFilters = filters
@@ -102,6 +143,67 @@ func (b *BaseResource) SetTableBulkActions(actions ...BulkActionDef) *BaseResour
 	return b
 }
 
+// SetBulkActions registers bulk actions run on the selected rows of the
+// list (see ResourceBulkActionable).
+//
+//	r.SetBulkActions(actions.Bulk("publish").SetLabel("Publish").
+//		HandleEach(func(ctx context.Context, id string) error { return r.repo.Publish(ctx, id) }))
+func (b *BaseResource) SetBulkActions(bulkActions ...*actions.BulkAction) *BaseResource {
+	b.bulkActions = bulkActions
+	return b
+}
+
+// BulkActions returns the bulk actions registered with SetBulkActions.
+func (b *BaseResource) BulkActions() []*actions.BulkAction {
+	return b.bulkActions
+}
+
+// SetRowActions registers actions shown on each row of the list, after
+// the view, edit and delete buttons. Those with a form (see
+// actions.Action.WithForm) open it in a modal and are routed by the
+// CRUDHandler (see ResourceFormActionable).
+//
+//	r.SetRowActions(actions.New("change-status").SetLabel("Change status").
+//		WithForm(form.Schema(form.Select("status").Options(statuses).Required())).
+//		Handle(func(ctx context.Context, item any, data url.Values) error {
+//			return r.repo.SetStatus(ctx, item.(*Post).ID, data.Get("status"))
+//		}))
+func (b *BaseResource) SetRowActions(rowActions ...*actions.Action) *BaseResource {
+	b.rowActions = rowActions
+	return b
+}
+
+// RowActions returns the actions registered with SetRowActions.
+func (b *BaseResource) RowActions() []*actions.Action {
+	return b.rowActions
+}
+
+// SetRowActionGroups registers dropdowns of row actions, shown after the
+// actions of SetRowActions:
+//
+//	r.SetRowActionGroups(actions.Group("More", archive, duplicate, transfer))
+func (b *BaseResource) SetRowActionGroups(groups ...*actions.ActionGroup) *BaseResource {
+	b.rowActionGroups = groups
+	return b
+}
+
+// RowActionGroups returns the dropdowns registered with SetRowActionGroups.
+func (b *BaseResource) RowActionGroups() []*actions.ActionGroup {
+	return b.rowActionGroups
+}
+
+// SetListActions registers actions shown in the header of the list, not
+// tied to a record: the handler of those with a form receives a nil item.
+func (b *BaseResource) SetListActions(listActions ...*actions.Action) *BaseResource {
+	b.listActions = listActions
+	return b
+}
+
+// ListActions returns the actions registered with SetListActions.
+func (b *BaseResource) ListActions() []*actions.Action {
+	return b.listActions
+}
+
 // SetExportURL enables the export button with the given URL.
 func (b *BaseResource) SetExportURL(url string) *BaseResource {
 	b.tableExportURL = url
@@ -134,34 +236,154 @@ func (b *BaseResource) EnableColumnManager() *BaseResource {
 	return b
 }
 
+// SetTableStackBelow sets the screen width below which the table rows
+// collapse into stacked cards (default: table.BreakpointMD).
+func (b *BaseResource) SetTableStackBelow(bp table.Breakpoint) *BaseResource {
+	b.tableStackBelow = bp
+	return b
+}
+
+// SetTablePrimaryColumn sets the column titling the stacked cards on small
+// screens (default: the first column).
+func (b *BaseResource) SetTablePrimaryColumn(key string) *BaseResource {
+	b.tablePrimaryColumn = key
+	return b
+}
+
+// SetPageSizes sets the page sizes of the list: the default and maximum
+// per page, and the choices of the table footer (default:
+// DefaultPageSizes). Zero keeps the size of the handler.
+func (b *BaseResource) SetPageSizes(defaultSize, maxSize int, options ...int) *BaseResource {
+	b.pageSizes = PageSizes{Default: defaultSize, Max: maxSize, Options: options}
+	return b
+}
+
+// PageSizes implements ResourcePageSizes.
+func (b *BaseResource) PageSizes() PageSizes {
+	return b.pageSizes
+}
+
+// SetDeleteConfirmation makes deleting a record require typing the phrase
+// returned by fn, usually the record's name. Return "" for records that can
+// be deleted with a plain confirmation.
+func (b *BaseResource) SetDeleteConfirmation(fn func(item any) string) *BaseResource {
+	b.deleteConfirmFn = fn
+	return b
+}
+
+// DeleteConfirmation implements ResourceDeleteConfirmable.
+func (b *BaseResource) DeleteConfirmation() func(item any) string {
+	return b.deleteConfirmFn
+}
+
+// EnableDrawerForms opens the New and Edit forms in a slide-over over the
+// list instead of navigating to their pages; the list refreshes on save.
+func (b *BaseResource) EnableDrawerForms() *BaseResource {
+	b.drawerForms = true
+	return b
+}
+
+// SetInfolist describes the detail page of the records, served at
+// GET /{slug}/{id} (see ResourceInfolistable). Its record entries are
+// filled with each record:
+//
+//	r.SetInfolist(infolist.New().AddSection(infolist.NewSection("Customer").Add(
+//		infolist.Text("Email"),
+//		infolist.Badge("Status").Colors(map[string]string{"active": "green"}),
+//		infolist.Relation("Orders").Display("Number"),
+//	)))
+func (b *BaseResource) SetInfolist(il *infolist.Infolist) *BaseResource {
+	b.infolist = il
+	return b
+}
+
+// Infolist returns the infolist set with SetInfolist, or nil.
+func (b *BaseResource) Infolist(_ context.Context) *infolist.Infolist {
+	return b.infolist
+}
+
 // BuildTableState constructs a TableState from the resource's list data.
-// Resolution order: ResourceQueryable > ResourceSearchable > ResourceFilterable > List.
+// Resolution order: ResourceQueryable > PaginatedResource > ResourceSearchable > ResourceFilterable > List.
 func (b *BaseResource) BuildTableState(ctx context.Context, canCreate, canDelete bool) (TableState, error) {
 	lq := GetListQuery(ctx)
 	activeFilters := GetActiveFilters(ctx)
 
-	items, total, err := b.fetchItems(ctx, lq, activeFilters)
+	tree, isTree := treeMode(b.listedResource(ctx), lq, activeFilters)
+	var page *PageResult
+	var err error
+	if isTree {
+		page, err = b.fetchTree(ctx, lq)
+	} else {
+		page, err = b.fetchItems(ctx, lq, activeFilters)
+	}
 	if err != nil {
 		return TableState{}, err
 	}
+	// The page of PaginatedCRUDHandler, maybe cached, is loaded by it.
+	if ctxPage, _ := PageFromContext(ctx); ctxPage != page {
+		if err := EagerLoad(ctx, b.listedResource(ctx), page.Items); err != nil {
+			return TableState{}, err
+		}
+	}
 
-	rows := b.buildRows(items)
-	pagination := buildPagination(lq, total)
+	var nodes []treeNode
+	if isTree {
+		nodes = arrangeTree(tree, page.Items)
+		items := make([]any, len(nodes))
+		for i, n := range nodes {
+			items[i] = n.item
+		}
+		page.Items = items
+	}
+	rows := b.buildRows(page.Items)
+	for i, n := range nodes {
+		rows[i].Depth, rows[i].ParentID, rows[i].HasChildren = n.depth, n.parentID, n.hasChildren
+	}
+	if len(b.rowActions) > 0 || len(b.rowActionGroups) > 0 {
+		for i := range rows {
+			rows[i].Actions = actionDefs(ctx, b.slug, rows[i].ID, rows[i].Record, b.rowActions)
+			rows[i].ActionGroups = actionGroupDefs(ctx, b.slug, rows[i].ID, rows[i].Record, b.rowActionGroups)
+		}
+	}
+	favURL := favoritesURL(ctx)
+	if favURL != "" {
+		for i := range rows {
+			rows[i].Pinned = isFavorite(ctx, b.slug, rows[i].ID)
+		}
+	}
+	var pagination *Pagination
+	if !isTree {
+		pagination = buildPagination(lq, page)
+	}
 	search, sortKey, sortDir := extractSortSearch(lq)
+	var pageSizes []int
+	if pagination != nil {
+		pageSizes = pageSizeOptions(b.listedResource(ctx), pagination.PerPage)
+	}
+	treeMoveURL := ""
+	if p, ok := b.listedResource(ctx).(ResourcePermissions); isTree && ok && p.CanUpdate(ctx) {
+		treeMoveURL = PanelURL(ctx, "/"+b.slug+"/tree/move")
+	}
+
+	bulkActions := b.tableBulkActions
+	if defs := bulkActionDefs(ctx, b.slug, b.bulkActions); len(defs) > 0 {
+		bulkActions = append(append([]BulkActionDef{}, bulkActions...), defs...)
+	}
 
-	return TableState{
+	state := TableState{
 		Title:         b.pluralLabel,
 		Slug:          b.slug,
 		Columns:       b.tableColumns,
 		Rows:          rows,
 		CanCreate:     canCreate,
 		CanDelete:     canDelete,
-		NewURL:        "/" + b.slug + "/create",
-		BaseURL:       "/" + b.slug,
+		NewURL:        PanelURL(ctx, "/"+b.slug+"/create"),
+		BaseURL:       PanelURL(ctx, "/"+b.slug),
 		Filters:       b.tableFilters,
 		ActiveFilters: activeFilters,
-		BulkActions:   b.tableBulkActions,
+		BulkActions:   bulkActions,
 		HeaderActions: b.tableHeaderActions,
+		ListActions:   actionDefs(ctx, b.slug, "", nil, b.listActions),
 		ExportURL:     b.tableExportURL,
 		ImportURL:     b.tableImportURL,
 		Pagination:    pagination,
@@ -169,33 +391,90 @@ func (b *BaseResource) BuildTableState(ctx context.Context, canCreate, canDelete
 		SortKey:       sortKey,
 		SortDir:       sortDir,
 		ColumnManager: b.columnManager,
-	}, nil
+		StackBelow:    b.tableStackBelow,
+		PrimaryColumn: b.tablePrimaryColumn,
+		DrawerForms:   b.drawerForms,
+		PageSizes:     pageSizes,
+		Tree:          isTree,
+		TreeMoveURL:   treeMoveURL,
+		FavoritesURL:  favURL,
+		Pinned:        favURL != "" && isFavorite(ctx, b.slug, ""),
+	}
+	if res, ok := b.listedResource(ctx).(ResourceFilterPanel); ok {
+		state.TypedFilters = res.FilterPanel(ctx)
+	}
+	if fp := filterPanelFromContext(ctx); fp != nil {
+		state.FilterValues = fp.values
+		state.FilterChips = fp.chips
+		state.FilterClearURL = fp.clearURL
+	}
+	state.ColumnSearch = columnSearchFromContext(ctx)
+	return state, nil
 }
 
-// fetchItems resolves which data-fetching strategy to use based on available interfaces.
-func (b *BaseResource) fetchItems(ctx context.Context, lq *ListQuery, activeFilters map[string]string) ([]any, int, error) {
-	self := interface{}(b)
+// fetchTree lists all the records of a tree, within the scope of the list.
+func (b *BaseResource) fetchTree(ctx context.Context, lq *ListQuery) (*PageResult, error) {
+	lister, ok := b.listedResource(ctx).(interface {
+		List(ctx context.Context) ([]any, error)
+	})
+	if !ok {
+		lister = b
+	}
+	items, err := lister.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if lq != nil {
+		items = scopeItems(items, lq.Scope)
+	}
+	return listedPage(items), nil
+}
+
+// fetchItems resolves which data-fetching strategy to use based on available interfaces,
+// those of the resource listed by CRUDHandler when it embeds b.
+// Without ListQuery, it is the page of PaginatedCRUDHandler, if any.
+// Apart from ListQuery and ListPaginated, the items are restricted to the scope of the list (see ResourceScoped)
+// and its Where filters.
+func (b *BaseResource) fetchItems(ctx context.Context, lq *ListQuery, activeFilters map[string]string) (*PageResult, error) {
+	self := b.listedResource(ctx)
 	if lq == nil {
+		if page, ok := PageFromContext(ctx); ok && page != nil {
+			return page, nil
+		}
 		items, err := b.List(ctx)
-		return items, len(items), err
+		return listedPage(items), err
 	}
-	if q, ok := self.(ResourceQueryable); ok {
-		return q.ListQuery(ctx, *lq)
+	if page, ok, err := queryItems(ctx, self, *lq); ok {
+		return page, err
 	}
 	if lq.Search != "" {
 		if s, ok := self.(ResourceSearchable); ok {
 			items, err := s.Search(ctx, lq.Search)
-			return items, len(items), err
+			return listedPage(scopeItems(items, lq.conditions())), err
 		}
 	}
 	if len(activeFilters) > 0 {
 		if f, ok := self.(ResourceFilterable); ok {
 			items, err := f.ListFiltered(ctx, activeFilters)
-			return items, len(items), err
+			return listedPage(scopeItems(items, lq.conditions())), err
 		}
 	}
 	items, err := b.List(ctx)
-	return items, len(items), err
+	return listedPage(scopeItems(items, lq.conditions())), err
+}
+
+// listedResource returns the resource listed by CRUDHandler when it embeds
+// b, else b.
+func (b *BaseResource) listedResource(ctx context.Context) any {
+	if res := GetResourceFromContext(ctx); res != nil && res.Slug() == b.slug {
+		return res
+	}
+	return b
+}
+
+// listedPage wraps all the items of a list.
+func listedPage(items []any) *PageResult {
+	return &PageResult{Items: items, Total: int64(len(items)), Visible: len(items)}
 }
 
 // buildRows converts items to table rows using each column's Value() method.
@@ -207,29 +486,54 @@ func (b *BaseResource) buildRows(items []any) []Row {
 		if b.recordUrlFn != nil {
 			row.RecordURL = b.recordUrlFn(item)
 		}
-		for _, col := range b.tableColumns {
-			row.Cells = append(row.Cells, col.Value(item))
+		if b.deleteConfirmFn != nil {
+			row.DeletePhrase = b.deleteConfirmFn(item)
+		}
+		row.Cells = make([]string, len(b.tableColumns))
+		for i, col := range b.tableColumns {
+			row.Cells[i] = col.Value(item)
 		}
 		rows = append(rows, row)
 	}
 	return rows
 }
 
-// buildPagination constructs a Pagination struct from ListQuery + total count.
-func buildPagination(lq *ListQuery, total int) *Pagination {
-	if lq == nil || lq.PerPage <= 0 {
+// buildPagination constructs a Pagination struct from ListQuery (or the
+// page itself without one) + the page listed.
+func buildPagination(lq *ListQuery, page *PageResult) *Pagination {
+	current, perPage := page.Page, page.Size
+	if lq != nil {
+		current, perPage = lq.Page, lq.PerPage
+	}
+	if perPage <= 0 {
 		return nil
 	}
-	lastPage := (total + lq.PerPage - 1) / lq.PerPage
+	if page.NextCursor != "" || page.PrevCursor != "" {
+		return &Pagination{
+			CurrentPage: current,
+			PerPage:     perPage,
+			NextCursor:  page.NextCursor,
+			PrevCursor:  page.PrevCursor,
+		}
+	}
+	total := int(page.Total)
+	lastPage := (total + perPage - 1) / perPage
 	if lastPage < 1 {
 		lastPage = 1
 	}
-	return &Pagination{
-		CurrentPage: lq.Page,
-		PerPage:     lq.PerPage,
+	p := &Pagination{
+		CurrentPage: current,
+		PerPage:     perPage,
 		Total:       total,
 		LastPage:    lastPage,
 	}
+	switch page.Count {
+	case CountNone:
+		p.Count, p.LastPage, p.HasMore = CountNone, 0, !page.Last
+	case CountEstimated, CountCapped:
+		p.Count, p.HasMore = page.Count, !page.Last
+	}
+	return p
 }
 
 // extractSortSearch pulls search/sort values from ListQuery for template use.
@@ -304,6 +608,10 @@ func emptyComponent() templ.Component {
 	})
 }
 
+// idFields caches the index of the ID field of each struct type (nil when
+// there is none), so that getItemID looks it up once per type.
+var idFields sync.Map // reflect.Type → []int
+
 // getItemID extracts the ID from an item using reflection.
 // Looks for fields named "ID", "Id", or "id" (int or string).
 func getItemID(item any) string {
@@ -317,13 +625,31 @@ func getItemID(item any) string {
 	if v.Kind() != reflect.Struct {
 		return fmt.Sprintf("%v", item)
 	}
-	for _, name := range []string{"ID", "Id", "id"} {
-		f := v.FieldByName(name)
-		if f.IsValid() {
-			return fmt.Sprintf("%v", f.Interface())
+	index, ok := idFields.Load(v.Type())
+	if !ok {
+		var idx []int
+		for _, name := range []string{"ID", "Id", "id"} {
+			if f, found := v.Type().FieldByName(name); found {
+				idx = f.Index
+				break
+			}
 		}
+		index, _ = idFields.LoadOrStore(v.Type(), idx)
 	}
-	return ""
+	idx := index.([]int)
+	if idx == nil {
+		return ""
+	}
+	f := v.FieldByIndex(idx)
+	if f.Type().PkgPath() == "" {
+		switch f.Kind() {
+		case reflect.String:
+			return f.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return strconv.FormatInt(f.Int(), 10)
+		}
+	}
+	return fmt.Sprintf("%v", f.Interface())
 }
 
 // SimpleResource is a minimal resource that only requires metadata.
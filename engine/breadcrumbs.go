@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bozz33/sublimeadmin/ui/layouts"
+)
+
+// Pages of a resource, passed to ResourceBreadcrumbs.
+const (
+	PageList   = "list"
+	PageCreate = "create"
+	PageView   = "view"
+	PageEdit   = "edit"
+)
+
+// ResourceBreadcrumbs is an optional interface for resources that customize
+// the topbar breadcrumbs. It receives the breadcrumbs built from the route
+// (resource → record → action) and returns the ones to display; item is nil
+// on the list and create pages.
+//
+//	func (r *PostResource) Breadcrumbs(ctx context.Context, page string, item any, crumbs []layouts.Breadcrumb) []layouts.Breadcrumb {
+//	    return append([]layouts.Breadcrumb{{Label: "Blog"}}, crumbs...)
+//	}
+type ResourceBreadcrumbs interface {
+	Breadcrumbs(ctx context.Context, page string, item any, crumbs []layouts.Breadcrumb) []layouts.Breadcrumb
+}
+
+// ResourceRecordTitle is an optional interface for resources that name
+// their records in the breadcrumbs ("Jane Doe" instead of "#42").
+type ResourceRecordTitle interface {
+	RecordTitle(item any) string
+}
+
+// resourceBreadcrumbs returns the breadcrumbs of a page of the resource.
+func resourceBreadcrumbs(ctx context.Context, res Resource, page, id string, item any) []layouts.Breadcrumb {
+	slug := res.Slug()
+	crumbs := []layouts.Breadcrumb{{Label: res.PluralLabel(), URL: slug}}
+
+	record := recordTitle(res, id, item)
+
+	// The ancestors of a record of a tree lead to it.
+	if tree, ok := res.(ResourceTree); ok && item != nil && (page == PageView || page == PageEdit) {
+		for _, ancestor := range treeAncestors(ctx, res, tree, item) {
+			ancestorID := getItemID(ancestor)
+			crumbs = append(crumbs, layouts.Breadcrumb{Label: recordTitle(res, ancestorID, ancestor), URL: recordPath(ctx, res, ancestorID)})
+		}
+	}
+
+	switch page {
+	case PageCreate:
+		crumbs = append(crumbs, layouts.Breadcrumb{Label: "Create"})
+	case PageView:
+		crumbs = append(crumbs, layouts.Breadcrumb{Label: record})
+	case PageEdit:
+		recordURL := ""
+		if hasRecordView(ctx, res) {
+			recordURL = slug + "/" + id
+		}
+		crumbs = append(crumbs,
+			layouts.Breadcrumb{Label: record, URL: recordURL},
+			layouts.Breadcrumb{Label: "Edit"},
+		)
+	}
+
+	if rb, ok := res.(ResourceBreadcrumbs); ok {
+		crumbs = rb.Breadcrumbs(ctx, page, item, crumbs)
+	}
+	return crumbs
+}
+
+// recordTitle names a record in the breadcrumbs: its RecordTitle, or "#id".
+func recordTitle(res Resource, id string, item any) string {
+	if rt, ok := res.(ResourceRecordTitle); ok && item != nil {
+		if title := rt.RecordTitle(item); title != "" {
+			return title
+		}
+	}
+	return "#" + id
+}
+
+// recordPath returns the breadcrumb URL of a record: its detail page, or
+// its edit form without one.
+func recordPath(ctx context.Context, res Resource, id string) string {
+	if hasRecordView(ctx, res) {
+		return res.Slug() + "/" + id
+	}
+	return res.Slug() + "/" + id + "/edit"
+}
+
+// withBreadcrumbs returns the request carrying the breadcrumbs of a page of
+// the resource.
+func withBreadcrumbs(r *http.Request, res Resource, page, id string, item any) *http.Request {
+	crumbs := resourceBreadcrumbs(r.Context(), res, page, id, item)
+	return r.WithContext(layouts.WithBreadcrumbs(r.Context(), crumbs))
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/ui/layouts"
+)
+
+type breadcrumbResource struct {
+	*mockResource
+}
+
+func (b *breadcrumbResource) View(ctx context.Context, item any) templ.Component {
+	return emptyComponent()
+}
+
+func (b *breadcrumbResource) Get(ctx context.Context, id string) (any, error) {
+	return "Jane Doe", nil
+}
+
+func (b *breadcrumbResource) RecordTitle(item any) string {
+	return item.(string)
+}
+
+func (b *breadcrumbResource) Breadcrumbs(ctx context.Context, page string, item any, crumbs []layouts.Breadcrumb) []layouts.Breadcrumb {
+	return append([]layouts.Breadcrumb{{Label: "Team"}}, crumbs...)
+}
+
+func breadcrumbLabels(crumbs []layouts.Breadcrumb) string {
+	labels := make([]string, len(crumbs))
+	for i, c := range crumbs {
+		labels[i] = c.Label + "=" + c.URL
+	}
+	return strings.Join(labels, " > ")
+}
+
+func TestResourceBreadcrumbs(t *testing.T) {
+	ctx := context.Background()
+	res := newMockResource("user")
+
+	tests := []struct {
+		page string
+		id   string
+		want string
+	}{
+		{PageList, "", "users=user"},
+		{PageCreate, "", "users=user > Create="},
+		{PageView, "42", "users=user > #42="},
+		{PageEdit, "42", "users=user > #42= > Edit="},
+	}
+	for _, tt := range tests {
+		got := breadcrumbLabels(resourceBreadcrumbs(ctx, res, tt.page, tt.id, nil))
+		if got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.page, tt.want, got)
+		}
+	}
+
+	// Record title, link to the view page and customization
+	custom := &breadcrumbResource{newMockResource("user")}
+	got := breadcrumbLabels(resourceBreadcrumbs(ctx, custom, PageEdit, "42", "Jane Doe"))
+	if want := "Team= > users=user > Jane Doe=user/42 > Edit="; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestCRUDHandler_Breadcrumbs(t *testing.T) {
+	h := newHandler(&breadcrumbResource{newMockResource("user")})
+	rw := serveWith(h, http.MethodGet, "/user/42", nil)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rw.Code)
+	}
+	body := rw.Body.String()
+	if !strings.Contains(body, `aria-label="Breadcrumb"`) || !strings.Contains(body, ">Jane Doe</span>") {
+		t.Error("expected the breadcrumbs of the record in the topbar")
+	}
+	if !strings.Contains(body, `/user" class="truncate`) {
+		t.Error("expected a link to the list in the breadcrumbs")
+	}
+}
@@ -0,0 +1,181 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bozz33/sublimeadmin/actions"
+	"github.com/bozz33/sublimeadmin/apperrors"
+	"github.com/bozz33/sublimeadmin/flash"
+	"github.com/bozz33/sublimeadmin/jobs"
+)
+
+// ResourceBulkActionable is an optional interface for resources with bulk
+// actions (see actions.Bulk). BaseResource implements it (see
+// SetBulkActions). The CRUDHandler routes
+//
+//	POST /{slug}/bulk/{name}           runs the action on the ids[] posted
+//	GET  /{slug}/bulk/{name}/{jobID}   progress of a selection run in the background
+//
+// and the list shows the actions when rows are selected.
+type ResourceBulkActionable interface {
+	BulkActions() []*actions.BulkAction
+}
+
+// BulkJobStatus is the progress of a bulk action run in the background,
+// served as JSON.
+type BulkJobStatus struct {
+	ID       string                `json:"id"`
+	Status   jobs.Status           `json:"status"`
+	Progress int                   `json:"progress"`
+	Total    int                   `json:"total"`
+	Failures []actions.BulkFailure `json:"failures,omitempty"`
+	Error    string                `json:"error,omitempty"`
+}
+
+// RunBulkAction runs a bulk action on the selected ids (form field ids[]),
+// then redirects to the list with a flash message reporting the records
+// that failed. Selections above the threshold of the action run on the
+// jobs queue of the handler when there is one.
+func (h *CRUDHandler) RunBulkAction(w http.ResponseWriter, r *http.Request, name string) {
+	ctx := r.Context()
+
+	action := h.bulkAction(name)
+	if action == nil {
+		apperrors.Handle(w, r, apperrors.NotFound(""))
+		return
+	}
+	if !action.IsAuthorized(ctx) {
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
+		return
+	}
+
+	ids := r.Form["ids[]"]
+	if len(ids) == 0 {
+		apperrors.Handle(w, r, apperrors.BadRequest("No items selected"))
+		return
+	}
+
+	list := PanelURL(ctx, "/"+h.Resource.Slug())
+	if action.Queued(len(ids)) && h.Jobs != nil {
+		jobID := dispatchJob(ctx, h.Jobs, "bulk "+h.Resource.Slug()+" "+action.Name, func(jobCtx context.Context, job *jobs.Job) error {
+			result := action.Run(requestValues{jobCtx, ctx}, ids, func(done int) {
+				job.UpdateProgress(done * 100 / len(ids))
+			})
+			job.SetResult(result)
+			if len(result.Failures) > 0 {
+				return errors.New(result.Message(""))
+			}
+			return nil
+		})
+		flash.Add(r, flash.NewMessage(flash.TypeInfo, fmt.Sprintf("%d records are being processed in the background.", len(ids))).
+			WithTitle(action.Label).
+			WithLink("View progress", PanelURL(ctx, bulkActionURL(h.Resource.Slug(), action.Name)+"/"+jobID)))
+		http.Redirect(w, r, list, http.StatusSeeOther)
+		return
+	}
+
+	result := action.Run(ctx, ids, nil)
+	msgType := flash.TypeSuccess
+	switch {
+	case result.Succeeded() == 0:
+		msgType = flash.TypeError
+	case len(result.Failures) > 0:
+		msgType = flash.TypeWarning
+	}
+	flash.Add(r, flash.NewMessage(msgType, result.Message(action.SuccessMessage)).WithTitle(action.Label))
+	http.Redirect(w, r, list, http.StatusSeeOther)
+}
+
+// BulkActionStatus serves the progress of a bulk action run in the
+// background as JSON (see BulkJobStatus).
+func (h *CRUDHandler) BulkActionStatus(w http.ResponseWriter, r *http.Request, name, jobID string) {
+	action := h.bulkAction(name)
+	if action == nil || h.Jobs == nil {
+		apperrors.Handle(w, r, apperrors.NotFound(""))
+		return
+	}
+	if !action.IsAuthorized(r.Context()) {
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
+		return
+	}
+	job, ok := h.Jobs.Get(jobID)
+	if !ok || job.Name != "bulk "+h.Resource.Slug()+" "+action.Name {
+		apperrors.Handle(w, r, apperrors.NotFound(""))
+		return
+	}
+
+	status := BulkJobStatus{ID: job.ID, Status: job.Status, Progress: job.Progress}
+	if result, ok := job.Result.(actions.BulkResult); ok {
+		status.Total = result.Total
+		status.Failures = result.Failures
+	}
+	if job.Error != nil {
+		status.Error = job.Error.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+// bulkAction returns the bulk action of the resource called name.
+func (h *CRUDHandler) bulkAction(name string) *actions.BulkAction {
+	ba, ok := h.Resource.(ResourceBulkActionable)
+	if !ok {
+		return nil
+	}
+	for _, action := range ba.BulkActions() {
+		if action.Name == name {
+			return action
+		}
+	}
+	return nil
+}
+
+// bulkActionURL returns the URL a bulk action is posted to.
+func bulkActionURL(slug, name string) string {
+	return "/" + slug + "/bulk/" + name
+}
+
+// bulkActionDefs lists the bulk actions of a resource authorized for the
+// current user, for the table.
+func bulkActionDefs(ctx context.Context, slug string, bulkActions []*actions.BulkAction) []BulkActionDef {
+	defs := make([]BulkActionDef, 0, len(bulkActions))
+	for _, action := range bulkActions {
+		if !action.IsAuthorized(ctx) {
+			continue
+		}
+		def := BulkActionDef{
+			Key:   action.Name,
+			Label: action.Label,
+			Icon:  action.Icon,
+			Color: action.Color,
+			URL:   PanelURL(ctx, bulkActionURL(slug, action.Name)),
+		}
+		if action.RequiresConfirmation {
+			def.Confirm = &BulkConfirm{
+				Title:        action.ModalTitle,
+				Description:  action.ModalDescription,
+				ConfirmLabel: action.ConfirmLabel,
+			}
+		}
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+// requestValues carries the values of a request (user, tenant...) into a
+// job, which is cancelled with the queue rather than with the request.
+type requestValues struct {
+	context.Context
+	request context.Context
+}
+
+func (c requestValues) Value(key any) any {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.request.Value(key)
+}
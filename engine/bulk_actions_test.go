@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/bozz33/sublimeadmin/actions"
+	"github.com/bozz33/sublimeadmin/flash"
+	"github.com/bozz33/sublimeadmin/jobs"
+)
+
+// newBulkResource registers a "publish" bulk action failing on record 2.
+func newBulkResource(published *[]string) *mockResource {
+	res := newMockResource("posts")
+	res.SetBulkActions(
+		actions.Bulk("publish").SetLabel("Publish").
+			WithSuccessMessage("Published %d posts.").
+			HandleEach(func(_ context.Context, id string) error {
+				if id == "2" {
+					return errors.New("already published")
+				}
+				*published = append(*published, id)
+				return nil
+			}),
+		actions.Bulk("purge").Authorize(func(context.Context) bool { return false }),
+	)
+	return res
+}
+
+// postBulk posts ids to a bulk action with a flash manager in the context
+// and returns the response and the flash messages added.
+func postBulk(h http.Handler, path string, ids ...string) (*httptest.ResponseRecorder, []*flash.Message) {
+	session := scs.New()
+	ctx, _ := session.Load(context.Background(), "")
+	manager := flash.NewManager(session)
+
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(url.Values{"ids[]": ids}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(flash.WithManager(ctx, manager))
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	return rw, manager.Get(ctx)
+}
+
+func TestCRUDHandler_RunBulkAction(t *testing.T) {
+	var published []string
+	h := newHandler(newBulkResource(&published))
+
+	rw, messages := postBulk(h, "/posts/bulk/publish", "1", "2", "3")
+	if rw.Code != http.StatusSeeOther || rw.Header().Get("Location") != "/posts" {
+		t.Fatalf("expected a redirect to the list, got %d %q", rw.Code, rw.Header().Get("Location"))
+	}
+	if strings.Join(published, ",") != "1,3" {
+		t.Errorf("expected records 1 and 3 to be published, got %v", published)
+	}
+	if len(messages) != 1 || messages[0].Type != flash.TypeWarning || !strings.Contains(messages[0].Text, "#2: already published") {
+		t.Errorf("expected a warning reporting record 2, got %+v", messages)
+	}
+
+	_, messages = postBulk(h, "/posts/bulk/publish", "4")
+	if len(messages) != 1 || messages[0].Type != flash.TypeSuccess || messages[0].Text != "Published 1 posts." {
+		t.Errorf("expected the success message, got %+v", messages)
+	}
+
+	for path, want := range map[string]int{
+		"/posts/bulk/unknown": http.StatusNotFound,
+		"/posts/bulk/purge":   http.StatusForbidden,
+	} {
+		if rw, _ := postBulk(h, path, "1"); rw.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, rw.Code)
+		}
+	}
+	if rw, _ := postBulk(h, "/posts/bulk/publish"); rw.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without a selection, got %d", rw.Code)
+	}
+}
+
+func TestCRUDHandler_RunBulkAction_background(t *testing.T) {
+	var published []string
+	res := newBulkResource(&published)
+	res.BulkActions()[0].InBackground(2)
+
+	queue := jobs.NewQueue(1)
+	queue.Start()
+	defer queue.Stop()
+	h := &CRUDHandler{Resource: res, Jobs: queue}
+
+	rw, messages := postBulk(h, "/posts/bulk/publish", "1", "2", "3")
+	if rw.Code != http.StatusSeeOther {
+		t.Fatalf("expected a redirect to the list, got %d", rw.Code)
+	}
+	all := queue.GetAll()
+	if len(all) != 1 {
+		t.Fatalf("expected a job, got %d", len(all))
+	}
+	if len(messages) != 1 || len(messages[0].Actions) != 1 || !strings.HasSuffix(messages[0].Actions[0].URL, "/posts/bulk/publish/"+all[0].ID) {
+		t.Errorf("expected a message linking to the progress, got %+v", messages)
+	}
+	if _, err := queue.Wait(all[0].ID, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	rw = serveWith(h, http.MethodGet, "/posts/bulk/publish/"+all[0].ID, nil)
+	var status BulkJobStatus
+	if err := json.Unmarshal(rw.Body.Bytes(), &status); err != nil {
+		t.Fatalf("expected JSON, got %d %s", rw.Code, rw.Body.String())
+	}
+	if status.Status != jobs.StatusFailed || status.Total != 3 || len(status.Failures) != 1 || status.Failures[0].ID != "2" {
+		t.Errorf("expected the per-record failures, got %+v", status)
+	}
+
+	if rw := serveWith(h, http.MethodGet, "/posts/bulk/publish/unknown", nil); rw.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown job, got %d", rw.Code)
+	}
+
+	// Small selections still run in the request
+	if _, messages := postBulk(h, "/posts/bulk/publish", "5"); len(messages) != 1 || messages[0].Type != flash.TypeSuccess {
+		t.Errorf("expected the selection to run in the request, got %+v", messages)
+	}
+}
+
+func TestBaseResource_BulkActions_in_table(t *testing.T) {
+	var published []string
+	res := newBulkResource(&published)
+	res.BulkActions()[0].RequiresDialog("Publish?", "")
+
+	state, err := res.BuildTableState(context.Background(), true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.BulkActions) != 1 {
+		t.Fatalf("expected the authorized bulk action only, got %+v", state.BulkActions)
+	}
+	ba := state.BulkActions[0]
+	if ba.URL != "/posts/bulk/publish" || ba.Confirm == nil || ba.Confirm.Title != "Publish?" {
+		t.Errorf("unexpected bulk action %+v", ba)
+	}
+}
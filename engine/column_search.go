@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/bozz33/sublimeadmin/table"
+)
+
+// ColumnSearchPrefix prefixes the params of the inline column searches:
+// col_<key> holds the text searched in the column of key.
+const ColumnSearchPrefix = "col_"
+
+// ResourceTableColumns is implemented by the resources listing the columns
+// of their table (see BaseResource.SetTableColumns and ResourceFor).
+// CRUDHandler.List translates the col_<key> params of their columns
+// searchable inline (see table.InlineSearchable) into FilterLike
+// expressions added to ListQuery.Where, so ResourceQueryable and
+// PaginatedResource get them like any other filter.
+type ResourceTableColumns interface {
+	TableColumns(ctx context.Context) []table.Column
+}
+
+type columnSearchKey struct{}
+
+// columnSearchFromContext returns the values of the inline column searches
+// of the list request of ctx, by column key, or nil.
+func columnSearchFromContext(ctx context.Context) map[string]string {
+	values, _ := ctx.Value(columnSearchKey{}).(map[string]string)
+	return values
+}
+
+// applyColumnSearch adds the inline column searches of r to lq.Where and
+// returns their values by column key, nil when there are none.
+func applyColumnSearch(r *http.Request, res Resource, lq *ListQuery) map[string]string {
+	withColumns, ok := res.(ResourceTableColumns)
+	if !ok {
+		return nil
+	}
+	query := r.URL.Query()
+	var values map[string]string
+	for _, col := range withColumns.TableColumns(r.Context()) {
+		if !table.SearchesInline(col) {
+			continue
+		}
+		v := strings.TrimSpace(query.Get(ColumnSearchPrefix + col.Key()))
+		if v == "" {
+			continue
+		}
+		if values == nil {
+			values = make(map[string]string)
+		}
+		values[col.Key()] = v
+		lq.Where = append(lq.Where, &FilterExpr{Field: col.Key(), Operator: FilterLike, Value: v})
+	}
+	return values
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bozz33/sublimeadmin/table"
+)
+
+type recipe struct {
+	ID     int
+	Title  string `json:"title" admin:"searchinline"`
+	Status string `json:"status" admin:"badge,searchinline"`
+	Notes  string `json:"notes"`
+}
+
+func TestApplyColumnSearch(t *testing.T) {
+	res := NewBaseResource("recipes", "Recipe", "Recipes")
+	res.SetTableColumns(
+		table.Text("title").SearchableInline(),
+		table.Badge("status").SearchableInline(),
+		table.Text("notes"),
+	)
+
+	r := httptest.NewRequest(http.MethodGet, "/recipes?col_title=+pie+&col_status=&col_notes=x", nil)
+	lq := ParseListQuery(r)
+	values := applyColumnSearch(r, res, &lq)
+
+	if len(values) != 1 || values["title"] != "pie" {
+		t.Errorf("values = %v, want only title=pie", values)
+	}
+	if len(lq.Where) != 1 {
+		t.Fatalf("expected 1 filter, got %d", len(lq.Where))
+	}
+	if f := lq.Where[0]; f.Field != "title" || f.Operator != FilterLike || f.Value != "pie" {
+		t.Errorf("unexpected filter %+v", f)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/recipes", nil)
+	lq = ParseListQuery(r)
+	if values := applyColumnSearch(r, res, &lq); values != nil || len(lq.Where) != 0 {
+		t.Errorf("a list without column search should not be filtered, got %v", values)
+	}
+}
+
+func TestCRUDHandler_ColumnSearch(t *testing.T) {
+	recipes := []*recipe{
+		{ID: 1, Title: "Apple pie", Status: "published"},
+		{ID: 2, Title: "Apple crumble", Status: "draft"},
+		{ID: 3, Title: "Cherry tart", Status: "published", Notes: "apple"},
+	}
+	res := ResourceFor[recipe]().WithList(func(_ context.Context) ([]*recipe, error) { return recipes, nil })
+
+	rw := httptest.NewRecorder()
+	NewCRUDHandler(res).ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/recipes?col_Title=apple&col_Status=pub&col_Notes=apple", nil))
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rw.Code)
+	}
+	body := rw.Body.String()
+	if !strings.Contains(body, "Apple pie") || strings.Contains(body, "Apple crumble") || strings.Contains(body, "Cherry tart") {
+		t.Error("the list should only show the recipes matching every column search")
+	}
+	if !strings.Contains(body, `name="col_Title"`) || !strings.Contains(body, `value="apple"`) {
+		t.Error("the column search inputs should be rendered with their values")
+	}
+	if strings.Contains(body, `name="col_Notes"`) {
+		t.Error("a column not searchable inline should not get an input")
+	}
+}
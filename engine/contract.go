@@ -3,8 +3,10 @@ package engine
 import (
 	"context"
 	"net/http"
+	"net/url"
 
 	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/form"
 	"github.com/bozz33/sublimeadmin/table"
 )
 
@@ -76,6 +78,16 @@ type ResourceValidator interface {
 	ValidateField(ctx context.Context, field, value string) error
 }
 
+// ResourceFormSchema is an optional interface for resources exposing the
+// fields of their form. Before Create and Update run, the files uploaded
+// for its FileUpload fields are checked against their rules (see
+// form.Form.ValidateFiles): invalid uploads show the form again with
+// their errors. An edit may leave a required upload empty to keep the
+// current file.
+type ResourceFormSchema interface {
+	FormSchema(ctx context.Context) *form.Form
+}
+
 // Column defines a table column.
 //
 // Deprecated: Use the typed column constructors from the table package instead:
@@ -162,6 +174,16 @@ type Row struct {
 	Cells     []string
 	Record    any    // original record — passed to col.Render() for rich column rendering
 	RecordURL string // optional: custom URL when clicking the row/first cell
+
+	DeletePhrase string           // optional: phrase typed to confirm the deletion (see SetDeleteConfirmation)
+	Actions      []ActionDef      // actions of the row (see SetRowActions)
+	ActionGroups []ActionGroupDef // dropdowns of row actions (see SetRowActionGroups)
+	Pinned       bool             // starred by the user (see Panel.WithFavorites)
+
+	// Tree rows (see ResourceTree)
+	Depth       int    // 0 for a root
+	ParentID    string // "" for a root
+	HasChildren bool
 }
 
 // EmptyState configures the empty table placeholder.
@@ -196,6 +218,10 @@ type TableState struct {
 	Filters        []FilterDef       // available filter definitions (legacy)
 	TypedFilters   []table.Filter    // typed filters: SelectFilter, DateFilter, TextFilter, CustomFilter
 	ActiveFilters  map[string]string // currently active filter values (key -> value)
+	FilterValues   url.Values        // values of the filter panel, by param without the filter_ prefix
+	FilterChips    []FilterChip      // active filters of the filter panel
+	FilterClearURL string            // the list without the filters of the filter panel
+	ColumnSearch   map[string]string // values of the inline column searches, by column key
 	BulkActions    []BulkActionDef   // available bulk actions
 	ExportURL      string            // non-empty = show export button
 	ImportURL      string            // non-empty = show import button
@@ -203,12 +229,21 @@ type TableState struct {
 	SortKey        string            // current ?sort= column key
 	SortDir        string            // current ?dir= (asc|desc)
 	HeaderActions  []HeaderAction    // always-visible action buttons in header
+	ListActions    []ActionDef       // header actions of the resource (see SetListActions)
 	PollInterval   int               // HTMX polling interval in seconds (0 = disabled)
 	EmptyState     *EmptyState       // custom empty state (nil = default)
 	HiddenColumns  []string          // column keys hidden by default (user can toggle)
 	ColumnManager  bool              // show column visibility toggle button
 	ColumnOrder    []string          // ordered list of column keys (empty = default order)
 	ReorderColumns bool              // allow drag & drop column reordering
+	StackBelow     table.Breakpoint  // rows collapse into stacked cards below this width (default: md)
+	PrimaryColumn  string            // column key titling the stacked cards (default: first column)
+	DrawerForms    bool              // open New and Edit in a slide-over (?display=drawer)
+	PageSizes      []int             // per page choices of the table footer (see ResourcePageSizes)
+	Tree           bool              // rows form a tree (see ResourceTree)
+	TreeMoveURL    string            // POST target of the rows dragged in the tree (empty = not movable)
+	FavoritesURL   string            // POST target of the star toggles (empty = favorites disabled)
+	Pinned         bool              // the resource is starred by the user
 }
 
 // FilterDef describes a filter available on the table.
@@ -232,6 +267,15 @@ type BulkActionDef struct {
 	Icon  string
 	Color string // "danger", "warning", "primary"
 	URL   string // POST target URL
+
+	Confirm *BulkConfirm // confirmation modal shown first (nil = none)
+}
+
+// BulkConfirm is the confirmation modal of a bulk action.
+type BulkConfirm struct {
+	Title        string
+	Description  string
+	ConfirmLabel string
 }
 
 // HeaderAction describes a standalone action button shown in the table header.
@@ -250,6 +294,17 @@ type Pagination struct {
 	PerPage     int
 	Total       int
 	LastPage    int
+
+	// In cursor mode (see NewCursorPage), the cursors of the pages around,
+	// without Total nor LastPage.
+	NextCursor string
+	PrevCursor string
+
+	// How Total was counted (see PageResult.Count). HasMore tells whether
+	// pages follow when the total is not exact; LastPage is 0 for
+	// CountNone.
+	Count   CountStrategy
+	HasMore bool
 }
 
 // contextKey is the type for context keys.
@@ -293,19 +348,28 @@ type ResourceFilterable interface {
 	ListFiltered(ctx context.Context, filters map[string]string) ([]any, error)
 }
 
-// ListQuery holds all query parameters for list operations.
+// ListQuery holds all query parameters for list operations (see
+// ParseListQuery). It converts to and from PaginationParams, so a resource
+// implements either ResourceQueryable or PaginatedResource for both
+// CRUDHandler and PaginatedCRUDHandler.
 type ListQuery struct {
 	Filters map[string]string // filter_* query params
+	Where   []*FilterExpr     // ?filters=JSON expressions (see ParseFiltersJSON)
 	Search  string            // ?search=
-	SortKey string            // ?sort=field
+	SortKey string            // ?sort=field, the first of Sorts
 	SortDir string            // ?dir=asc|desc
+	Sorts   []SortField       // ?sort=-name,id
 	Page    int               // ?page=N (1-indexed)
 	PerPage int               // ?per_page=N
+	Cursor  *Cursor           // ?cursor=, the position of the page in cursor mode (see NewCursorPage)
+	Tab     string            // ?tab=name, the selected ListTab (see ResourceTabs)
+	Scope   []*FilterExpr     // default scope of the resource (see ResourceScoped)
 }
 
 // ResourceQueryable is an optional interface for resources that handle
 // all list query parameters in a single call (filters + search + sort + pagination).
 // Prefer this over ResourceFilterable when you need full control.
+// PaginatedCRUDHandler uses it too when the resource is no PaginatedResource.
 type ResourceQueryable interface {
 	ListQuery(ctx context.Context, q ListQuery) (items []any, total int, err error)
 }
@@ -316,6 +380,13 @@ type ResourceSearchable interface {
 	Search(ctx context.Context, query string) ([]any, error)
 }
 
+// ResourceBatchLister is an optional interface for resources listing the
+// records whose field is one of values in one query (WHERE field IN (...)).
+// BatchRelationLoader uses it to load a relation for a page of records.
+type ResourceBatchLister interface {
+	ListWhereIn(ctx context.Context, field string, values []string) ([]any, error)
+}
+
 // ResourceHookable is an optional interface for resources that need
 // lifecycle hooks around CRUD operations.
 type ResourceHookable interface {
@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+)
+
+// CountStrategy tells how a list counts its records. An exact COUNT(*)
+// scans every matching row, which dominates the query time of large
+// tables.
+type CountStrategy string
+
+const (
+	// CountExact counts every record (default).
+	CountExact CountStrategy = "exact"
+
+	// CountEstimated uses the estimate of the query planner for the
+	// unfiltered list, e.g. EstimatePostgresCount, and CountCapped
+	// otherwise.
+	CountEstimated CountStrategy = "estimated"
+
+	// CountCapped counts up to the cap, shown as "10000+" beyond.
+	CountCapped CountStrategy = "capped"
+
+	// CountNone skips the count: the list only links the previous and next
+	// pages.
+	CountNone CountStrategy = "none"
+)
+
+// PageCounter counts the records of a list for NewCountedPage.
+type PageCounter struct {
+	// Count counts the records of the query, up to limit records when
+	// limit > 0, e.g. SELECT count(*) FROM (SELECT 1 FROM ... LIMIT n).
+	Count func(ctx context.Context, limit int) (int64, error)
+
+	// Estimate estimates the records of the unfiltered list, or returns a
+	// negative number when it cannot. Optional.
+	Estimate func(ctx context.Context) (int64, error)
+}
+
+// NewCountedPage creates the PageResult of a page of up to PerPage+1 items,
+// counting the records with the CountStrategy of params; the extra item
+// tells whether there are more.
+//
+//	func (r *EventResource) ListPaginated(ctx context.Context, p engine.PaginationParams) (*engine.PageResult, error) {
+//	    query := r.db.Event.Query().Where(...)
+//	    events, err := query.Clone().Offset(p.Offset()).Limit(p.PerPage + 1).All(ctx)
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    items := make([]any, len(events))
+//	    for i, e := range events {
+//	        items[i] = e
+//	    }
+//	    return engine.NewCountedPage(ctx, items, p, engine.PageCounter{
+//	        Count: func(ctx context.Context, limit int) (int64, error) {
+//	            if limit > 0 {
+//	                query = query.Limit(limit)
+//	            }
+//	            n, err := query.Count(ctx)
+//	            return int64(n), err
+//	        },
+//	        Estimate: func(ctx context.Context) (int64, error) {
+//	            return engine.EstimatePostgresCount(ctx, r.sqlDB, "events")
+//	        },
+//	    })
+//	}
+func NewCountedPage(ctx context.Context, items []any, params PaginationParams, counter PageCounter) (*PageResult, error) {
+	size := params.PerPage
+	if size <= 0 {
+		size = 15
+	}
+	more := len(items) > size
+	if more {
+		items = items[:size]
+	}
+	// Records up to the end of the page: the total on the last page.
+	seen := int64(params.Offset() + len(items))
+	if !more && (len(items) > 0 || params.Offset() == 0) {
+		page := NewPage(items, seen, params.Page, size)
+		page.Count = CountExact
+		return page, nil
+	}
+
+	strategy := params.CountStrategy
+	if strategy == CountEstimated && (counter.Estimate == nil || len(params.Filters) > 0 || params.Search != "") {
+		strategy = CountCapped
+	}
+
+	page := NewPage(items, 0, params.Page, size)
+	switch strategy {
+	case CountNone:
+		page.Count = CountNone
+		page.Total = seen
+		page.TotalPages = int64(params.Page)
+		page.MaxPage = page.TotalPages - 1
+
+	case CountEstimated:
+		estimate, err := counter.Estimate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if estimate >= 0 {
+			// A stale estimate still covers the pages listed.
+			page = NewPage(items, max(estimate, seen+1), params.Page, size)
+			page.Count = CountEstimated
+			break
+		}
+		fallthrough
+
+	case CountCapped:
+		limit := 0
+		if params.CountCap > 0 {
+			limit = params.CountCap + 1
+		}
+		total, err := counter.Count(ctx, limit)
+		if err != nil {
+			return nil, err
+		}
+		page = NewPage(items, total, params.Page, size)
+		page.Count = CountExact
+		if limit > 0 && total > int64(params.CountCap) {
+			page = NewPage(items, int64(params.CountCap), params.Page, size)
+			page.Count = CountCapped
+		}
+
+	default:
+		total, err := counter.Count(ctx, 0)
+		if err != nil {
+			return nil, err
+		}
+		page = NewPage(items, total, params.Page, size)
+		page.Count = CountExact
+	}
+	page.Last = !more
+	return page, nil
+}
+
+// EstimatePostgresCount returns the planner estimate of the rows of table
+// (pg_class.reltuples), or -1 when the table was never analyzed. It is
+// refreshed by ANALYZE and autovacuum.
+func EstimatePostgresCount(ctx context.Context, db *sql.DB, table string) (int64, error) {
+	var estimate sql.NullFloat64
+	err := db.QueryRowContext(ctx, "SELECT reltuples FROM pg_class WHERE oid = to_regclass($1)", table).Scan(&estimate)
+	if err == sql.ErrNoRows {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !estimate.Valid {
+		return -1, nil
+	}
+	return int64(estimate.Float64), nil
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewCountedPage(t *testing.T) {
+	items := func(n int) []any {
+		out := make([]any, n)
+		for i := range out {
+			out[i] = i
+		}
+		return out
+	}
+	var limits []int
+	counter := PageCounter{
+		Count: func(ctx context.Context, limit int) (int64, error) {
+			limits = append(limits, limit)
+			if limit > 0 {
+				return min(int64(limit), 25000), nil
+			}
+			return 25000, nil
+		},
+		Estimate: func(ctx context.Context) (int64, error) { return 24000, nil },
+	}
+	params := func(strategy CountStrategy, page int, filtered bool) PaginationParams {
+		p := PaginationParams{Page: page, PerPage: 20, CountStrategy: strategy, CountCap: 10000}
+		if filtered {
+			p.Filters = []*FilterExpr{{Field: "status", Operator: FilterEq, Value: "open"}}
+		}
+		return p
+	}
+
+	tests := []struct {
+		name     string
+		params   PaginationParams
+		items    int
+		count    CountStrategy
+		total    int64
+		last     bool
+		limits   []int
+		estimate int64
+	}{
+		{"exact", params(CountExact, 2, false), 21, CountExact, 25000, false, []int{0}, 0},
+		{"exact last page", params(CountExact, 3, false), 5, CountExact, 45, true, nil, 0},
+		{"capped", params(CountCapped, 2, false), 21, CountCapped, 10000, false, []int{10001}, 0},
+		{"estimated", params(CountEstimated, 2, false), 21, CountEstimated, 24000, false, nil, 0},
+		{"estimated filtered", params(CountEstimated, 2, true), 21, CountCapped, 10000, false, []int{10001}, 0},
+		{"never analyzed", params(CountEstimated, 2, false), 21, CountCapped, 10000, false, []int{10001}, -1},
+		{"none", params(CountNone, 2, false), 21, CountNone, 40, false, nil, 0},
+		{"none beyond the end", params(CountNone, 9, false), 0, CountNone, 160, true, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits = nil
+			c := counter
+			if tt.estimate != 0 {
+				c.Estimate = func(ctx context.Context) (int64, error) { return tt.estimate, nil }
+			}
+			page, err := NewCountedPage(context.Background(), items(tt.items), tt.params, c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if page.Count != tt.count || page.Total != tt.total || page.Last != tt.last || len(page.Items) != min(tt.items, 20) {
+				t.Errorf("expected %s total %d (last %v), got %s total %d (last %v) of %d items",
+					tt.count, tt.total, tt.last, page.Count, page.Total, page.Last, len(page.Items))
+			}
+			if len(limits) != len(tt.limits) || (len(limits) > 0 && limits[0] != tt.limits[0]) {
+				t.Errorf("expected counts up to %v, got %v", tt.limits, limits)
+			}
+		})
+	}
+}
+
+func TestBuildPagination_countStrategy(t *testing.T) {
+	p := buildPagination(nil, &PageResult{Page: 3, Size: 20, Total: 60, Count: CountNone})
+	if p.LastPage != 0 || !p.HasMore || p.Count != CountNone {
+		t.Errorf("expected an uncounted pagination with more pages, got %+v", p)
+	}
+	p = buildPagination(nil, &PageResult{Page: 3, Size: 20, Total: 10000, Count: CountCapped, Last: true})
+	if p.LastPage != 500 || p.HasMore || p.Count != CountCapped {
+		t.Errorf("unexpected capped pagination %+v", p)
+	}
+	if p = buildPagination(nil, NewPage(nil, 45, 1, 20)); p.HasMore || p.Count != "" || p.LastPage != 3 {
+		t.Errorf("unexpected exact pagination %+v", p)
+	}
+}
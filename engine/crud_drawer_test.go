@@ -0,0 +1,94 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// storeSpyResource records whether Create or Update was called.
+type storeSpyResource struct {
+	*mockResource
+	created bool
+	updated string
+}
+
+func (s *storeSpyResource) Create(_ context.Context, _ *http.Request) error {
+	s.created = true
+	return nil
+}
+
+func (s *storeSpyResource) Update(_ context.Context, id string, _ *http.Request) error {
+	s.updated = id
+	return nil
+}
+
+func TestCRUDHandler_Create_drawer(t *testing.T) {
+	h := newHandler(newMockResource("items"))
+
+	rw := serveWith(h, http.MethodGet, "/items/create?display=drawer", nil)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rw.Code)
+	}
+	body := rw.Body.String()
+	if !strings.Contains(body, "data-slide-over-content") || !strings.Contains(body, `data-title="Create items"`) {
+		t.Errorf("expected a slide-over fragment, got %s", body)
+	}
+	if strings.Contains(body, "<html") {
+		t.Error("expected the drawer form without the layout")
+	}
+
+	rw = serveWith(h, http.MethodGet, "/items/7/edit?display=drawer", nil)
+	if rw.Code != http.StatusOK || !strings.Contains(rw.Body.String(), `data-title="Edit items"`) {
+		t.Errorf("expected the edit form as a fragment, got %d %s", rw.Code, rw.Body.String())
+	}
+}
+
+func TestCRUDHandler_Store_drawer(t *testing.T) {
+	res := &storeSpyResource{mockResource: newMockResource("items")}
+	h := newHandler(res)
+
+	rw := serveWith(h, http.MethodPost, "/items/create", url.Values{"name": {"Test"}})
+	if rw.Code != http.StatusSeeOther || rw.Header().Get("Location") != "/items" {
+		t.Errorf("expected a redirect to the list, got %d %q", rw.Code, rw.Header().Get("Location"))
+	}
+	if !res.created || res.updated != "" {
+		t.Errorf("expected POST /items/create to create, got created=%v updated=%q", res.created, res.updated)
+	}
+
+	rw = serveWith(h, http.MethodPost, "/items/create?display=drawer", url.Values{"name": {"Test"}})
+	if rw.Code != http.StatusNoContent {
+		t.Errorf("expected 204 for a drawer form, got %d", rw.Code)
+	}
+
+	rw = serveWith(h, http.MethodPost, "/items/7/edit?display=drawer", url.Values{"name": {"Test"}})
+	if rw.Code != http.StatusNoContent || res.updated != "7" {
+		t.Errorf("expected the drawer edit form to update 7, got %d %q", rw.Code, res.updated)
+	}
+}
+
+func TestCRUDHandler_Store_drawer_error(t *testing.T) {
+	h := newHandler(newStoreErrorResource("items", errors.New("duplicate entry")))
+
+	rw := serveWith(h, http.MethodPost, "/items/create?display=drawer", url.Values{"name": {"Test"}})
+	if rw.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rw.Code)
+	}
+	if body := rw.Body.String(); !strings.Contains(body, "data-slide-over-content") || strings.Contains(body, "<html") {
+		t.Errorf("expected the form re-rendered as a fragment, got %s", body)
+	}
+}
+
+func TestBaseResource_EnableDrawerForms(t *testing.T) {
+	b := NewBaseResource("items", "Item", "Items")
+	if state, _ := b.BuildTableState(context.Background(), true, true); state.DrawerForms {
+		t.Error("expected drawer forms to be off by default")
+	}
+	b.EnableDrawerForms()
+	if state, _ := b.BuildTableState(context.Background(), true, true); !state.DrawerForms {
+		t.Error("expected EnableDrawerForms to set TableState.DrawerForms")
+	}
+}
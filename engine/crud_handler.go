@@ -5,13 +5,20 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/actions"
+	"github.com/bozz33/sublimeadmin/apperrors"
 	datastarPkg "github.com/bozz33/sublimeadmin/datastar"
 	formPkg "github.com/bozz33/sublimeadmin/form"
+	"github.com/bozz33/sublimeadmin/jobs"
+	"github.com/bozz33/sublimeadmin/logger"
+	"github.com/bozz33/sublimeadmin/middleware"
+	"github.com/bozz33/sublimeadmin/tracing"
+	"github.com/bozz33/sublimeadmin/ui/components"
 	"github.com/bozz33/sublimeadmin/ui/layouts"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const contextKeyListQuery contextKey = "list_query"
@@ -27,6 +34,18 @@ func GetListQuery(ctx context.Context) *ListQuery {
 // CRUDHandler automatically handles CRUD operations for a resource.
 type CRUDHandler struct {
 	Resource Resource
+
+	// Jobs runs large bulk action selections in the background (see
+	// actions.BulkAction.InBackground). Without it they run in the request.
+	Jobs *jobs.Queue
+
+	// Actions are the standalone actions of the resource (see
+	// Panel.AddActions), routed like its form actions.
+	Actions []*actions.Action
+
+	// UnitOfWork runs each mutation of the resource in a transaction (see
+	// Panel.WithUnitOfWork). Without it they run as is.
+	UnitOfWork UnitOfWork
 }
 
 // NewCRUDHandler creates a CRUD handler for a given resource.
@@ -35,43 +54,54 @@ func NewCRUDHandler(r Resource) *CRUDHandler {
 }
 
 // List displays the list of items.
-// Extracts filter_*, search, sort, dir, page, per_page from query params
-// and injects them into context as both ActiveFilters and ListQuery.
+// Parses the ListQuery of the request (see ParseListQuery) and injects it
+// into context as both ActiveFilters and ListQuery.
+// The filters of the selected tab (see ResourceTabs) are added to them,
+// and those of the filter panel and of the inline column searches to Where
+// (see ResourceFilterPanel and ResourceTableColumns).
+// Requests for the table alone get it without the page (see ListTableID).
 func (h *CRUDHandler) List(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	q := r.URL.Query()
 
-	// Build ListQuery from all relevant params
-	lq := &ListQuery{
-		Filters: make(map[string]string),
-		Search:  q.Get("search"),
-		SortKey: q.Get("sort"),
-		SortDir: q.Get("dir"),
-		Page:    1,
-		PerPage: 20,
-	}
-	if lq.SortDir != "desc" {
-		lq.SortDir = "asc"
+	scope, err := resourceScope(ctx, h.Resource)
+	if err != nil {
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
+		return
 	}
-	if p, err := strconv.Atoi(q.Get("page")); err == nil && p > 0 {
-		lq.Page = p
+	lq := parseListQuery(r, listPageSizes(w, r, h.Resource, listQueryConfig()))
+	lq.Scope = scope
+	tabs, tab := listTabs(ctx, h.Resource, q.Get("tab"))
+	if tab != nil {
+		lq.Tab = tab.Name
+		for key, val := range tab.Filters {
+			lq.Filters[key] = val
+		}
 	}
-	if pp, err := strconv.Atoi(q.Get("per_page")); err == nil && pp > 0 && pp <= 200 {
-		lq.PerPage = pp
+
+	if fp := applyFilterPanel(r, h.Resource, &lq); fp != nil {
+		ctx = context.WithValue(ctx, filterPanelKey{}, fp)
 	}
-	for key, vals := range q {
-		if strings.HasPrefix(key, "filter_") && len(vals) > 0 && vals[0] != "" {
-			lq.Filters[strings.TrimPrefix(key, "filter_")] = vals[0]
-		}
+	if cs := applyColumnSearch(r, h.Resource, &lq); cs != nil {
+		ctx = context.WithValue(ctx, columnSearchKey{}, cs)
 	}
 
 	// Inject into context
-	ctx = context.WithValue(ctx, contextKeyListQuery, lq)
+	ctx = context.WithValue(ctx, contextKeyListQuery, &lq)
+	ctx = context.WithValue(ctx, ContextKeyResource, h.Resource)
 	if len(lq.Filters) > 0 {
 		ctx = context.WithValue(ctx, ContextKeyActiveFilters, lq.Filters)
 	}
 
-	component := h.Resource.Table(ctx)
+	// The table alone, without the page around it, is cached apart.
+	w.Header().Add("Vary", "HX-Request, HX-Target")
+	if isTableFragmentRequest(r) {
+		renderTableFragment(w, r.WithContext(ctx), h.Resource.Table(ctx))
+		return
+	}
+
+	r = withBreadcrumbs(r.WithContext(ctx), h.Resource, PageList, "", nil)
+	component := resourcePage(r, h.Resource, PageList, nil, tabLinks(r, h.Resource, tabs, tab), h.Resource.Table(ctx))
 	render(w, r, h.Resource.PluralLabel(), component)
 }
 
@@ -80,28 +110,30 @@ func (h *CRUDHandler) Create(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	if !h.Resource.CanCreate(ctx) {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
 		return
 	}
 
-	component := h.Resource.Form(ctx, nil)
-	render(w, r, "Create "+h.Resource.Label(), component)
+	component := resourcePage(r, h.Resource, PageCreate, nil, nil, h.Resource.Form(ctx, nil))
+	renderForm(w, withBreadcrumbs(r, h.Resource, PageCreate, "", nil), "Create "+h.Resource.Label(), component)
 }
 
-// View displays the read-only detail view (Infolist) for a resource.
-// Only available if the resource implements ResourceViewable.
+// View displays the read-only detail page of a record, its eager relations
+// loaded (see EagerLoad): the View of the resource (see ResourceViewable)
+// or its infolist (see ResourceInfolistable), then a tab per relation
+// manager (see RelationManagerAware). Resources without one redirect to the
+// edit form.
 func (h *CRUDHandler) View(w http.ResponseWriter, r *http.Request, id string) {
 	ctx := r.Context()
 
 	if !h.Resource.CanRead(ctx) {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
 		return
 	}
 
-	viewable, ok := h.Resource.(ResourceViewable)
-	if !ok {
+	if !hasRecordView(ctx, h.Resource) {
 		// Resource has no View — redirect to edit
-		http.Redirect(w, r, fmt.Sprintf("/%s/%s/edit", h.Resource.Slug(), id), http.StatusSeeOther)
+		http.Redirect(w, r, PanelURL(r.Context(), fmt.Sprintf("/%s/%s/edit", h.Resource.Slug(), id)), http.StatusSeeOther)
 		return
 	}
 
@@ -110,12 +142,24 @@ func (h *CRUDHandler) View(w http.ResponseWriter, r *http.Request, id string) {
 		http.NotFound(w, r)
 		return
 	}
+	if err := EagerLoad(ctx, h.Resource, []any{item}); err != nil {
+		apperrors.Handle(w, r, apperrors.Internal(err, "Relation error"))
+		return
+	}
 
-	component := viewable.View(ctx, item)
-	render(w, r, h.Resource.Label(), component)
+	props := components.RecordViewProps{
+		Content:   recordContent(ctx, h.Resource, item),
+		Relations: relationTabs(ctx, h.Resource, id),
+	}
+	if h.Resource.CanUpdate(ctx) {
+		props.EditURL = PanelURL(r.Context(), fmt.Sprintf("/%s/%s/edit", h.Resource.Slug(), id))
+	}
+	component := resourcePage(r, h.Resource, PageView, item, nil, components.RecordView(props))
+	render(w, withBreadcrumbs(r, h.Resource, PageView, id, item), h.Resource.Label(), component)
 }
 
-// Edit displays the edit form.
+// Edit displays the edit form, then a tab per relation manager (see
+// RelationManagerAware) out of the drawer.
 func (h *CRUDHandler) Edit(w http.ResponseWriter, r *http.Request, id string) {
 	ctx := r.Context()
 
@@ -130,83 +174,113 @@ func (h *CRUDHandler) Edit(w http.ResponseWriter, r *http.Request, id string) {
 		ctx = context.WithValue(ctx, contextKeyRelationManagers, rwr.GetRelationManagers())
 	}
 
-	component := h.Resource.Form(ctx, item)
-	render(w, r, "Edit "+h.Resource.Label(), component)
+	content := h.Resource.Form(ctx, item)
+	if tabs := relationTabs(ctx, h.Resource, id); len(tabs) > 0 && !isDrawer(r) {
+		content = templ.Join(content, components.RelationTabs(tabs))
+	}
+	component := resourcePage(r, h.Resource, PageEdit, item, nil, content)
+	renderForm(w, withBreadcrumbs(r, h.Resource, PageEdit, id, item), "Edit "+h.Resource.Label(), component)
 }
 
 // Store handles creation.
 // If the resource returns a ValidationErrors error, the form is re-rendered
-// with inline field errors instead of returning HTTP 500.
+// with inline field errors instead of returning HTTP 500. So is it when
+// the tenant reached the limit of the resource of its plan (see CheckLimit)
+// or an uploaded file breaks the rules of its field (see
+// ResourceFormSchema).
 func (h *CRUDHandler) Store(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	if !h.Resource.CanCreate(ctx) {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
 		return
 	}
 
-	if err := h.Resource.Create(ctx, r); err != nil {
+	err := checkResourceLimit(ctx, h.Resource)
+	if err == nil {
+		err = validateUploads(ctx, h.Resource, r, true)
+	}
+	if err == nil {
+		err = createWithHooks(ctx, h.Resource, r)
+	}
+	if err != nil {
 		ctx2 := injectFormErrors(ctx, err)
 		w.WriteHeader(http.StatusUnprocessableEntity)
-		component := h.Resource.Form(ctx2, nil)
-		render(w, r.WithContext(ctx2), "Create "+h.Resource.Label(), component)
+		component := resourcePage(r, h.Resource, PageCreate, nil, nil, h.Resource.Form(ctx2, nil))
+		renderForm(w, withBreadcrumbs(r.WithContext(ctx2), h.Resource, PageCreate, "", nil), "Create "+h.Resource.Label(), component)
 		return
 	}
 
-	http.Redirect(w, r, "/"+h.Resource.Slug(), http.StatusSeeOther)
+	h.saved(w, r)
 }
 
 // Update handles updates.
 // If the resource returns a ValidationErrors error, the form is re-rendered
-// with inline field errors instead of returning HTTP 500.
+// with inline field errors instead of returning HTTP 500, as for invalid
+// uploads (see ResourceFormSchema).
 func (h *CRUDHandler) Update(w http.ResponseWriter, r *http.Request, id string) {
 	ctx := r.Context()
 
 	if !h.Resource.CanUpdate(ctx) {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
 		return
 	}
 
-	if err := h.Resource.Update(ctx, id, r); err != nil {
+	err := validateUploads(ctx, h.Resource, r, false)
+	if err == nil {
+		err = updateWithHooks(ctx, h.Resource, id, r)
+	}
+	if err != nil {
 		// Re-fetch item to pre-populate the form with submitted values.
 		item, _ := h.Resource.Get(ctx, id)
 		ctx2 := injectFormErrors(ctx, err)
 		w.WriteHeader(http.StatusUnprocessableEntity)
-		component := h.Resource.Form(ctx2, item)
-		render(w, r.WithContext(ctx2), "Edit "+h.Resource.Label(), component)
+		component := resourcePage(r, h.Resource, PageEdit, item, nil, h.Resource.Form(ctx2, item))
+		renderForm(w, withBreadcrumbs(r.WithContext(ctx2), h.Resource, PageEdit, id, item), "Edit "+h.Resource.Label(), component)
 		return
 	}
 
-	http.Redirect(w, r, "/"+h.Resource.Slug(), http.StatusSeeOther)
+	h.saved(w, r)
 }
 
 // Delete handles deletion.
 // If the resource implements SoftDeletable, this performs a soft delete.
-// Otherwise it permanently deletes the item.
+// Otherwise it permanently deletes the item. Resources requiring a typed
+// confirmation (ResourceDeleteConfirmable) are checked first.
 func (h *CRUDHandler) Delete(w http.ResponseWriter, r *http.Request, id string) {
 	ctx := r.Context()
 
 	if !h.Resource.CanDelete(ctx) {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
+		return
+	}
+
+	if err := h.checkDeleteConfirmation(r, id); err != nil {
+		apperrors.Handle(w, r, err)
 		return
 	}
 
 	// Use soft delete when resource supports it.
 	if sd, ok := h.Resource.(SoftDeletable); ok {
-		if err := sd.SoftDelete(ctx, id); err != nil {
-			http.Error(w, "Soft delete error: "+err.Error(), http.StatusInternalServerError)
+		if err := deleteWithHooks(ctx, h.Resource, id, func() error { return sd.SoftDelete(ctx, id) }); err != nil {
+			apperrors.Handle(w, r, apperrors.Internal(err, "Soft delete error"))
 			return
 		}
-		http.Redirect(w, r, "/"+h.Resource.Slug(), http.StatusSeeOther)
+		http.Redirect(w, r, PanelURL(r.Context(), "/"+h.Resource.Slug()), http.StatusSeeOther)
+		return
+	}
+
+	if err := h.deleteDependents(r, id); err != nil {
+		apperrors.Handle(w, r, err)
 		return
 	}
 
-	if err := h.Resource.Delete(ctx, id); err != nil {
-		http.Error(w, "Delete error: "+err.Error(), http.StatusInternalServerError)
+	if err := deleteWithHooks(ctx, h.Resource, id, func() error { return h.Resource.Delete(ctx, id) }); err != nil {
+		apperrors.Handle(w, r, apperrors.Internal(err, "Delete error"))
 		return
 	}
 
-	http.Redirect(w, r, "/"+h.Resource.Slug(), http.StatusSeeOther)
+	http.Redirect(w, r, PanelURL(r.Context(), "/"+h.Resource.Slug()), http.StatusSeeOther)
 }
 
 // Restore handles restoring a soft-deleted item.
@@ -215,22 +289,22 @@ func (h *CRUDHandler) Restore(w http.ResponseWriter, r *http.Request, id string)
 	ctx := r.Context()
 
 	if !h.Resource.CanDelete(ctx) {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
 		return
 	}
 
 	sd, ok := h.Resource.(SoftDeletable)
 	if !ok {
-		http.Error(w, "Resource does not support soft delete", http.StatusBadRequest)
+		apperrors.Handle(w, r, apperrors.BadRequest("Resource does not support soft delete"))
 		return
 	}
 
 	if err := sd.Restore(ctx, id); err != nil {
-		http.Error(w, "Restore error: "+err.Error(), http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, "Restore error"))
 		return
 	}
 
-	http.Redirect(w, r, "/"+h.Resource.Slug(), http.StatusSeeOther)
+	http.Redirect(w, r, PanelURL(r.Context(), "/"+h.Resource.Slug()), http.StatusSeeOther)
 }
 
 // ForceDelete permanently deletes a (possibly soft-deleted) item.
@@ -239,22 +313,27 @@ func (h *CRUDHandler) ForceDelete(w http.ResponseWriter, r *http.Request, id str
 	ctx := r.Context()
 
 	if !h.Resource.CanDelete(ctx) {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
 		return
 	}
 
 	sd, ok := h.Resource.(SoftDeletable)
 	if !ok {
-		http.Error(w, "Resource does not support force delete", http.StatusBadRequest)
+		apperrors.Handle(w, r, apperrors.BadRequest("Resource does not support force delete"))
+		return
+	}
+
+	if err := h.deleteDependents(r, id); err != nil {
+		apperrors.Handle(w, r, err)
 		return
 	}
 
 	if err := sd.ForceDelete(ctx, id); err != nil {
-		http.Error(w, "Force delete error: "+err.Error(), http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, "Force delete error"))
 		return
 	}
 
-	http.Redirect(w, r, "/"+h.Resource.Slug(), http.StatusSeeOther)
+	http.Redirect(w, r, PanelURL(r.Context(), "/"+h.Resource.Slug()), http.StatusSeeOther)
 }
 
 // BulkDelete handles bulk deletion.
@@ -262,34 +341,76 @@ func (h *CRUDHandler) BulkDelete(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	if !h.Resource.CanDelete(ctx) {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Form parsing error", http.StatusBadRequest)
+		apperrors.Handle(w, r, apperrors.BadRequest("Form parsing error"))
 		return
 	}
 
 	ids := r.Form["ids[]"]
 	if len(ids) == 0 {
-		http.Error(w, "No items selected", http.StatusBadRequest)
+		apperrors.Handle(w, r, apperrors.BadRequest("No items selected"))
 		return
 	}
 
+	if _, soft := h.Resource.(SoftDeletable); !soft {
+		if err := h.deleteDependents(r, ids...); err != nil {
+			apperrors.Handle(w, r, err)
+			return
+		}
+	}
+
 	if err := h.Resource.BulkDelete(ctx, ids); err != nil {
-		http.Error(w, "Bulk delete error: "+err.Error(), http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, "Bulk delete error"))
 		return
 	}
 
-	http.Redirect(w, r, "/"+h.Resource.Slug(), http.StatusSeeOther)
+	http.Redirect(w, r, PanelURL(r.Context(), "/"+h.Resource.Slug()), http.StatusSeeOther)
 }
 
 // ServeHTTP implements http.Handler with automatic routing.
+// The request context carries a "crud {slug}" span (a no-op unless
+// middleware.Tracing is installed) so resource and DB calls are traced.
 func (h *CRUDHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, span := tracing.Start(r.Context(), "crud "+h.Resource.Slug(),
+		attribute.String("resource.slug", h.Resource.Slug()),
+		attribute.String("http.request.method", r.Method),
+	)
+	defer span.End()
+	r = r.WithContext(ctx)
+	logger.SetResource(ctx, h.Resource.Slug())
+	logger.SetRoute(ctx, r.Pattern)
+
+	// Successful writes drop cached pages tagged with the resource slug
+	// (see middleware.Cache).
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		rw := middleware.NewResponseWriter(w)
+		defer func() {
+			if rw.Status() < http.StatusBadRequest {
+				middleware.InvalidateCache(ctx, h.Resource.Slug())
+			}
+		}()
+		w = rw
+	}
+
+	serveInUnitOfWork(h.UnitOfWork, w, r, h.route)
+}
+
+// route routes a request of the resource to its handler.
+func (h *CRUDHandler) route(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/"+h.Resource.Slug())
 	path = strings.TrimPrefix(path, "/")
 	parts := strings.Split(path, "/")
+	if !guardTenant(w, r, h.Resource, parts) {
+		return
+	}
+	if len(parts) >= 3 && parts[1] == "relations" {
+		h.serveRelations(w, r, path)
+		return
+	}
 
 	switch r.Method {
 	case http.MethodGet:
@@ -309,6 +430,19 @@ func (h *CRUDHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// serveRelations serves the requests of the relation managers of the
+// resource (see RelationManagerHandler) at /{slug}/{id}/relations/....
+func (h *CRUDHandler) serveRelations(w http.ResponseWriter, r *http.Request, path string) {
+	rm := NewRelationManagerHandler(h.Resource)
+	if !rm.HasManagers() {
+		http.NotFound(w, r)
+		return
+	}
+	r2 := r.Clone(r.Context())
+	r2.URL.Path = "/" + path
+	rm.ServeHTTP(w, r2)
+}
+
 // Patch handles partial updates from inline-edit table columns.
 // Route: PATCH /{slug}/{id}
 //
@@ -320,12 +454,12 @@ func (h *CRUDHandler) Patch(w http.ResponseWriter, r *http.Request, id string) {
 	ctx := r.Context()
 
 	if !h.Resource.CanUpdate(ctx) {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		apperrors.Handle(w, r, apperrors.BadRequest("Bad request"))
 		return
 	}
 
@@ -368,7 +502,7 @@ func (h *CRUDHandler) ValidateField(w http.ResponseWriter, r *http.Request) {
 	field := r.URL.Query().Get("field")
 	value := r.URL.Query().Get("value")
 	if field == "" {
-		http.Error(w, "missing field parameter", http.StatusBadRequest)
+		apperrors.Handle(w, r, apperrors.BadRequest("missing field parameter"))
 		return
 	}
 
@@ -414,8 +548,16 @@ func (h *CRUDHandler) routeGET(w http.ResponseWriter, r *http.Request, path stri
 		h.Create(w, r)
 	case path == "validate-field":
 		h.ValidateField(w, r)
+	case len(parts) == 2 && parts[0] == "actions":
+		h.ActionForm(w, r, "", parts[1])
+	case len(parts) == 3 && parts[1] == "actions":
+		h.ActionForm(w, r, parts[0], parts[2])
 	case len(parts) == 2 && parts[1] == "edit":
 		h.Edit(w, r, parts[0])
+	case len(parts) == 2 && parts[1] == "delete-summary":
+		h.DeleteSummary(w, r, parts[0])
+	case len(parts) == 3 && parts[0] == "bulk":
+		h.BulkActionStatus(w, r, parts[1], parts[2])
 	case len(parts) == 1 && parts[0] != "":
 		h.View(w, r, parts[0])
 	default:
@@ -426,7 +568,7 @@ func (h *CRUDHandler) routeGET(w http.ResponseWriter, r *http.Request, path stri
 // routePOST dispatches POST requests (including _method override).
 func (h *CRUDHandler) routePOST(w http.ResponseWriter, r *http.Request, path string, parts []string) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		apperrors.Handle(w, r, apperrors.BadRequest("Bad request"))
 		return
 	}
 	if r.FormValue("_method") == "DELETE" && len(parts) >= 1 {
@@ -436,13 +578,42 @@ func (h *CRUDHandler) routePOST(w http.ResponseWriter, r *http.Request, path str
 	switch {
 	case path == "bulk-delete":
 		h.BulkDelete(w, r)
-	case path == "" || path == "/":
+	case path == "tree/move":
+		h.MoveTreeNode(w, r)
+	case len(parts) == 2 && parts[0] == "bulk":
+		h.RunBulkAction(w, r, parts[1])
+	case len(parts) == 2 && parts[0] == "actions":
+		h.RunFormAction(w, r, "", parts[1])
+	case len(parts) == 3 && parts[1] == "actions":
+		h.RunFormAction(w, r, parts[0], parts[2])
+	case path == "" || path == "/" || path == "create":
 		h.Store(w, r)
 	case len(parts) >= 1:
 		h.Update(w, r, parts[0])
 	}
 }
 
+// validateUploads checks the files uploaded in r against the FileUpload
+// fields of the form of res, when it exposes them (see ResourceFormSchema).
+// Without creating, the required fields left empty keep their files.
+func validateUploads(ctx context.Context, res Resource, r *http.Request, creating bool) error {
+	fs, ok := res.(ResourceFormSchema)
+	if !ok {
+		return nil
+	}
+	schema := fs.FormSchema(ctx)
+	if schema == nil {
+		return nil
+	}
+	if err := parseRequestForm(r); err != nil {
+		return formPkg.FormErrors{"_error": "The uploaded files could not be read."}
+	}
+	if errs := schema.ValidateFiles(r, creating); errs != nil {
+		return errs
+	}
+	return nil
+}
+
 // injectFormErrors converts an error into FormErrors and injects it into context.
 // If the error implements ValidationErrors (with FieldErrors()), per-field errors
 // are used. Otherwise, the error message is stored under the "_error" key.
@@ -459,6 +630,37 @@ func injectFormErrors(ctx context.Context, err error) context.Context {
 	}
 }
 
+// DisplayDrawer is the value of the display query parameter asking for the
+// create and edit forms as a fragment shown in a slide-over over the list
+// (GET /{slug}/create?display=drawer). Saving such a form answers 204 No
+// Content instead of redirecting; the slide-over then refreshes the list.
+const DisplayDrawer = "drawer"
+
+// isDrawer reports whether the request comes from a slide-over form.
+func isDrawer(r *http.Request) bool {
+	return r.URL.Query().Get("display") == DisplayDrawer
+}
+
+// saved ends a successful Store or Update.
+func (h *CRUDHandler) saved(w http.ResponseWriter, r *http.Request) {
+	if isDrawer(r) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	http.Redirect(w, r, PanelURL(r.Context(), "/"+h.Resource.Slug()), http.StatusSeeOther)
+}
+
+// renderForm displays a create/edit form in the layout, or alone for a
+// slide-over.
+func renderForm(w http.ResponseWriter, r *http.Request, title string, content templ.Component) {
+	if isDrawer(r) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = components.SlideOverContent(title, content).Render(r.Context(), w)
+		return
+	}
+	render(w, r, title, content)
+}
+
 // render is a helper to display a component in the layout.
 func render(w http.ResponseWriter, r *http.Request, title string, content templ.Component) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -1,8 +1,10 @@
 package engine
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -10,6 +12,9 @@ import (
 	"testing"
 
 	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/apperrors"
+	formPkg "github.com/bozz33/sublimeadmin/form"
+	"github.com/bozz33/sublimeadmin/middleware"
 )
 
 // ---------------------------------------------------------------------------
@@ -155,6 +160,26 @@ func TestCRUDHandler_GET_create_forbidden(t *testing.T) {
 	}
 }
 
+func TestCRUDHandler_forbidden_problemJSON(t *testing.T) {
+	res := newMockResource("items")
+	h := &CRUDHandler{Resource: &noCreateResource{BaseResource: res.BaseResource}}
+
+	req := httptest.NewRequest(http.MethodGet, "/items/create", nil)
+	req.Header.Set("Accept", "application/json")
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+
+	if rw.Code != http.StatusForbidden {
+		t.Errorf("expected status 403, got %d", rw.Code)
+	}
+	if ct := rw.Header().Get("Content-Type"); ct != apperrors.ProblemContentType {
+		t.Errorf("expected Content-Type %q, got %q", apperrors.ProblemContentType, ct)
+	}
+	if !strings.Contains(rw.Body.String(), `"code":"FORBIDDEN"`) {
+		t.Errorf("expected FORBIDDEN code in body, got %q", rw.Body.String())
+	}
+}
+
 // noCreateResource denies CanCreate.
 type noCreateResource struct {
 	*BaseResource
@@ -280,6 +305,27 @@ func TestCRUDHandler_POST_method_override_DELETE_forbidden(t *testing.T) {
 	}
 }
 
+func TestCRUDHandler_write_invalidates_cache(t *testing.T) {
+	var invalidated []string
+	middleware.OnCacheInvalidate(func(ctx context.Context, tags []string) {
+		invalidated = append(invalidated, tags...)
+	})
+
+	form := url.Values{}
+	form.Set("_method", "DELETE")
+
+	serveWith(&CRUDHandler{Resource: &noDeleteResource{BaseResource: newMockResource("cached").BaseResource}},
+		http.MethodPost, "/cached/1", form)
+	if len(invalidated) != 0 {
+		t.Errorf("expected no invalidation after a forbidden write, got %v", invalidated)
+	}
+
+	serveWith(newHandler(newMockResource("cached")), http.MethodPost, "/cached/1", form)
+	if len(invalidated) != 1 || invalidated[0] != "cached" {
+		t.Errorf("expected cache tag 'cached' invalidated, got %v", invalidated)
+	}
+}
+
 // noDeleteResource denies CanDelete.
 type noDeleteResource struct {
 	*BaseResource
@@ -588,3 +634,72 @@ func TestCRUDHandler_POST_update_store_error_rerenders_form(t *testing.T) {
 		t.Errorf("expected 422 re-render on Update error, got %d", rw.Code)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// File uploads — checked against the FileUpload fields of the form
+// ---------------------------------------------------------------------------
+
+// uploadResource exposes a form with a required CSV upload.
+type uploadResource struct {
+	*mockResource
+	saved int
+}
+
+func (u *uploadResource) FormSchema(context.Context) *formPkg.Form {
+	return formPkg.Schema(formPkg.NewSection("Import").SetSchema(
+		formPkg.FileUpload("users").Accept(".csv").Required(),
+	))
+}
+
+func (u *uploadResource) Create(context.Context, *http.Request) error {
+	u.saved++
+	return nil
+}
+
+func (u *uploadResource) Update(context.Context, string, *http.Request) error {
+	u.saved++
+	return nil
+}
+
+// postUpload posts a multipart form with the file, none when name is "".
+func postUpload(h http.Handler, path, name string, content []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	_ = mw.WriteField("title", "Users")
+	if name != "" {
+		part, _ := mw.CreateFormFile("users", name)
+		_, _ = part.Write(content)
+	}
+	_ = mw.Close()
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	return rw
+}
+
+func TestCRUDHandler_POST_validates_uploads(t *testing.T) {
+	res := &uploadResource{mockResource: newMockResource("imports")}
+	h := newHandler(res)
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	if rw := postUpload(h, "/imports/create", "users.csv", png); rw.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for an image named .csv, got %d", rw.Code)
+	}
+	if rw := postUpload(h, "/imports/create", "", nil); rw.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for a missing required upload, got %d", rw.Code)
+	}
+	if res.saved != 0 {
+		t.Fatalf("expected invalid uploads not to be saved, got %d saves", res.saved)
+	}
+
+	if rw := postUpload(h, "/imports/create", "users.csv", []byte("name,email\njane,jane@example.com\n")); rw.Code != http.StatusSeeOther {
+		t.Errorf("expected a redirect for a CSV file, got %d", rw.Code)
+	}
+	if rw := postUpload(h, "/imports/7", "", nil); rw.Code != http.StatusSeeOther {
+		t.Errorf("expected an update to keep the current file, got %d", rw.Code)
+	}
+	if res.saved != 2 {
+		t.Errorf("expected 2 saves, got %d", res.saved)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/bozz33/sublimeadmin/apperrors"
 )
 
 // CSRFConfig contains the CSRF middleware configuration.
@@ -108,12 +110,12 @@ func (m *CSRFManager) Middleware(next http.Handler) http.Handler {
 
 		cookie, err := r.Cookie(m.config.CookieName)
 		if err != nil || cookie.Value == "" {
-			http.Error(w, "CSRF token missing", http.StatusForbidden)
+			apperrors.Handle(w, r, apperrors.PageExpired("CSRF token missing"))
 			return
 		}
 
 		if token != cookie.Value {
-			http.Error(w, "CSRF token mismatch", http.StatusForbidden)
+			apperrors.Handle(w, r, apperrors.PageExpired("CSRF token mismatch"))
 			return
 		}
 
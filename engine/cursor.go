@@ -0,0 +1,195 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+)
+
+// PaginationMode selects how the lists are paginated.
+type PaginationMode string
+
+const (
+	// PaginationOffset pages the lists by number (?page=N), counting their
+	// records.
+	PaginationOffset PaginationMode = "offset"
+
+	// PaginationCursor pages the lists by keyset (?cursor=...), without
+	// OFFSET nor total count, for large tables.
+	PaginationCursor PaginationMode = "cursor"
+)
+
+// ErrInvalidCursor is returned by DecodeCursor for a malformed cursor.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the position of a page in a list paginated by cursor: the
+// values of the sort fields of the record before the page, or after it when
+// Before is set. It travels in the URL encoded by EncodeCursor.
+type Cursor struct {
+	Sort   string // sort of the list, e.g. "-created_at,id" (see ParseSortFields)
+	Values []any  // values of the sort fields of the record
+	Before bool   // the page ends before the record instead of starting after it
+}
+
+// cursorJSON is the encoded form of a Cursor.
+type cursorJSON struct {
+	Sort   string `json:"s,omitempty"`
+	Values []any  `json:"v"`
+	Before bool   `json:"b,omitempty"`
+}
+
+// EncodeCursor encodes c as an opaque URL-safe string.
+func EncodeCursor(c Cursor) string {
+	b, _ := json.Marshal(cursorJSON{Sort: c.Sort, Values: c.Values, Before: c.Before})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor decodes a cursor of EncodeCursor. Integers come back as
+// int64, other numbers as float64.
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursorJSON
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&c); err != nil || len(c.Values) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	for i, v := range c.Values {
+		n, ok := v.(json.Number)
+		if !ok {
+			continue
+		}
+		if iv, err := n.Int64(); err == nil {
+			c.Values[i] = iv
+		} else if fv, err := n.Float64(); err == nil {
+			c.Values[i] = fv
+		}
+	}
+	return &Cursor{Sort: c.Sort, Values: c.Values, Before: c.Before}, nil
+}
+
+// formatSortFields is the inverse of ParseSortFields.
+func formatSortFields(sorts []SortField) string {
+	parts := make([]string, len(sorts))
+	for i, s := range sorts {
+		parts[i] = s.Field
+		if s.Desc {
+			parts[i] = "-" + s.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// keysetSorts returns the sort of a list paginated by cursor: that of the
+// list then id, which sets apart the records of equal sort values.
+func (p PaginationParams) keysetSorts() []SortField {
+	for _, s := range p.Sorts {
+		if s.Field == "id" {
+			return p.Sorts
+		}
+	}
+	return append(slices.Clone(p.Sorts), SortField{Field: "id"})
+}
+
+// CursorSorts returns the sort to query a page paginated by cursor with:
+// that of the list then id, reversed for a page before the cursor.
+func (p PaginationParams) CursorSorts() []SortField {
+	sorts := slices.Clone(p.keysetSorts())
+	if p.Cursor != nil && p.Cursor.Before {
+		for i := range sorts {
+			sorts[i].Desc = !sorts[i].Desc
+		}
+	}
+	return sorts
+}
+
+// CursorFilters returns the keyset condition of the cursor, to add to the
+// filters of the query, or nil on the first page. For the sort "-date"
+// after the record (d, 7) it is date < d OR (date = d AND id > 7). The
+// sort fields must not be null.
+func (p PaginationParams) CursorFilters() []*FilterExpr {
+	sorts := p.keysetSorts()
+	if p.Cursor == nil || len(p.Cursor.Values) != len(sorts) {
+		return nil
+	}
+	or := make([]*FilterExpr, 0, len(sorts))
+	for i, s := range sorts {
+		op := FilterGt
+		if s.Desc != p.Cursor.Before {
+			op = FilterLt
+		}
+		cmp := &FilterExpr{Field: s.Field, Operator: op, Value: p.Cursor.Values[i]}
+		if i == 0 {
+			or = append(or, cmp)
+			continue
+		}
+		and := make([]*FilterExpr, 0, i+1)
+		for j := range i {
+			and = append(and, &FilterExpr{Field: sorts[j].Field, Operator: FilterEq, Value: p.Cursor.Values[j]})
+		}
+		or = append(or, &FilterExpr{And: append(and, cmp)})
+	}
+	if len(or) == 1 {
+		return or
+	}
+	return []*FilterExpr{{Or: or}}
+}
+
+// NewCursorPage creates the PageResult of a list paginated by cursor from
+// up to PerPage+1 items, queried with CursorFilters and CursorSorts; the
+// extra item tells whether there are more. keys returns the values of the
+// fields of CursorSorts for an item.
+//
+//	func (r *EventResource) ListPaginated(ctx context.Context, p engine.PaginationParams) (*engine.PageResult, error) {
+//	    events, err := r.store.Events(ctx, append(p.Filters, p.CursorFilters()...), p.CursorSorts(), p.PerPage+1)
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    return engine.NewCursorPage(events, p, func(item any) []any {
+//	        e := item.(*Event)
+//	        return []any{e.CreatedAt, e.ID}
+//	    }), nil
+//	}
+//
+// The page has no total: NextCursor and PrevCursor lead to its neighbours.
+func NewCursorPage(items []any, params PaginationParams, keys func(item any) []any) *PageResult {
+	size := params.PerPage
+	if size <= 0 {
+		size = 15
+	}
+	before := params.Cursor != nil && params.Cursor.Before
+	more := len(items) > size
+	if more {
+		items = items[:size]
+	}
+	if before {
+		// Queried in reverse, see CursorSorts.
+		items = slices.Clone(items)
+		slices.Reverse(items)
+	}
+
+	page := &PageResult{
+		Items:   items,
+		Page:    params.Page,
+		Size:    size,
+		Visible: len(items),
+	}
+	if len(items) > 0 {
+		sort := formatSortFields(params.keysetSorts())
+		if more || before {
+			page.NextCursor = EncodeCursor(Cursor{Sort: sort, Values: keys(items[len(items)-1])})
+		}
+		if params.Cursor != nil && (more || !before) {
+			page.PrevCursor = EncodeCursor(Cursor{Sort: sort, Values: keys(items[0]), Before: true})
+		}
+	}
+	page.First = page.PrevCursor == ""
+	page.Last = page.NextCursor == ""
+	return page
+}
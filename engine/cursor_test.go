@@ -0,0 +1,187 @@
+package engine
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func TestEncodeDecodeCursor(t *testing.T) {
+	c := Cursor{Sort: "-score,id", Values: []any{4.5, 12, "2024-01-02T00:00:00Z"}, Before: true}
+	got, err := DecodeCursor(EncodeCursor(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Sort != c.Sort || !got.Before || got.Values[0] != 4.5 || got.Values[1] != int64(12) || got.Values[2] != "2024-01-02T00:00:00Z" {
+		t.Errorf("unexpected cursor %+v", got)
+	}
+
+	for _, raw := range []string{"", "not base64!", EncodeCursor(Cursor{Sort: "id"})} {
+		if _, err := DecodeCursor(raw); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("DecodeCursor(%q): expected ErrInvalidCursor, got %v", raw, err)
+		}
+	}
+}
+
+func TestParsePaginationParams_cursor(t *testing.T) {
+	cursor := EncodeCursor(Cursor{Sort: "-score,id", Values: []any{3, 7}})
+
+	p := ParsePaginationParams(httptest.NewRequest(http.MethodGet, "/scores?sort=-score&page=4&cursor="+cursor, nil))
+	if p.Mode != PaginationCursor || p.Cursor == nil || p.Page != 1 {
+		t.Errorf("expected the cursor of the page, got %+v", p)
+	}
+
+	p = ParsePaginationParams(httptest.NewRequest(http.MethodGet, "/scores?sort=score&cursor="+cursor, nil))
+	if p.Mode != PaginationOffset || p.Cursor != nil {
+		t.Errorf("a cursor of another sort should be dropped, got %+v", p)
+	}
+
+	cfg := NewPaginator().WithMode(PaginationCursor).cfg
+	if p = ParsePaginationParamsWithConfig(httptest.NewRequest(http.MethodGet, "/scores", nil), cfg); p.Mode != PaginationCursor || p.Cursor != nil {
+		t.Errorf("expected the first page in cursor mode, got %+v", p)
+	}
+}
+
+type scoreRecord struct {
+	ID    int
+	Score int
+}
+
+// listScores pages records like a keyset query would.
+func listScores(records []*scoreRecord, p PaginationParams) *PageResult {
+	sorts := p.CursorSorts()
+	var items []any
+	for _, r := range records {
+		if MatchFilters(r, p.CursorFilters()) {
+			items = append(items, r)
+		}
+	}
+	slices.SortFunc(items, func(a, b any) int {
+		for _, s := range sorts {
+			ra, rb := a.(*scoreRecord), b.(*scoreRecord)
+			c := cmp.Compare(ra.ID, rb.ID)
+			if s.Field == "score" {
+				c = cmp.Compare(ra.Score, rb.Score)
+			}
+			if s.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	if len(items) > p.PerPage+1 {
+		items = items[:p.PerPage+1]
+	}
+	return NewCursorPage(items, p, func(item any) []any {
+		r := item.(*scoreRecord)
+		keys := make([]any, len(sorts))
+		for i, s := range sorts {
+			keys[i] = r.ID
+			if s.Field == "score" {
+				keys[i] = r.Score
+			}
+		}
+		return keys
+	})
+}
+
+func TestNewCursorPage(t *testing.T) {
+	var records []*scoreRecord
+	for i := 1; i <= 23; i++ {
+		records = append(records, &scoreRecord{ID: i, Score: i % 4})
+	}
+	params := func(cursor string) PaginationParams {
+		url := "/scores?sort=-score&per_page=10"
+		if cursor != "" {
+			url += "&cursor=" + cursor
+		}
+		return ParsePaginationParams(httptest.NewRequest(http.MethodGet, url, nil))
+	}
+	ids := func(page *PageResult) []int {
+		var out []int
+		for _, item := range page.Items {
+			out = append(out, item.(*scoreRecord).ID)
+		}
+		return out
+	}
+
+	var pages [][]int
+	var next []string
+	page := listScores(records, params(""))
+	if !page.First || page.PrevCursor != "" {
+		t.Errorf("the first page should have no previous page, got %+v", page)
+	}
+	for {
+		pages = append(pages, ids(page))
+		next = append(next, page.NextCursor)
+		if page.Last {
+			break
+		}
+		page = listScores(records, params(page.NextCursor))
+	}
+
+	var seen []int
+	for _, p := range pages {
+		seen = append(seen, p...)
+	}
+	if len(pages) != 3 || len(seen) != 23 || seen[0] != 3 || seen[1] != 7 {
+		t.Fatalf("expected 3 pages of all the records sorted by score desc then id, got %v", pages)
+	}
+	slices.Sort(seen)
+	if len(slices.Compact(seen)) != 23 {
+		t.Errorf("expected each record once, got %v", pages)
+	}
+
+	// Back from the last page
+	prev := listScores(records, params(page.PrevCursor))
+	if !slices.Equal(ids(prev), pages[1]) || prev.NextCursor == "" || prev.PrevCursor == "" {
+		t.Errorf("expected the second page, got %v", ids(prev))
+	}
+	first := listScores(records, params(prev.PrevCursor))
+	if !slices.Equal(ids(first), pages[0]) || first.PrevCursor != "" || first.NextCursor != next[0] {
+		t.Errorf("expected the first page, got %v (prev %q)", ids(first), first.PrevCursor)
+	}
+}
+
+// cursorResource lists its records by cursor.
+type cursorResource struct {
+	*mockResource
+	records []*scoreRecord
+	state   TableState
+}
+
+func (c *cursorResource) ListPaginated(ctx context.Context, p PaginationParams) (*PageResult, error) {
+	return listScores(c.records, p), nil
+}
+
+func (c *cursorResource) Table(ctx context.Context) templ.Component {
+	c.state, _ = c.BuildTableState(ctx, false, false)
+	return emptyComponent()
+}
+
+func TestBuildTableState_cursorPagination(t *testing.T) {
+	res := &cursorResource{mockResource: newMockResource("scores")}
+	for i := 1; i <= 30; i++ {
+		res.records = append(res.records, &scoreRecord{ID: i})
+	}
+	h := NewCRUDHandler(res)
+
+	serveWith(h, http.MethodGet, "/scores", nil)
+	if p := res.state.Pagination; p == nil || p.NextCursor == "" || p.PrevCursor != "" || len(res.state.Rows) != 20 {
+		t.Fatalf("expected the first page with a next cursor, got %+v", p)
+	}
+
+	serveWith(h, http.MethodGet, "/scores?cursor="+res.state.Pagination.NextCursor, nil)
+	if p := res.state.Pagination; p.NextCursor != "" || p.PrevCursor == "" || len(res.state.Rows) != 10 || res.state.Rows[0].ID != "21" {
+		t.Errorf("expected the last page, got %+v", p)
+	}
+}
@@ -0,0 +1,224 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bozz33/sublimeadmin/actions"
+	"github.com/bozz33/sublimeadmin/apperrors"
+	"github.com/bozz33/sublimeadmin/ui/components"
+)
+
+// ResourceDeleteConfirmable is an optional interface for resources whose
+// records are deleted only after the user types a phrase, usually the name
+// of the record. BaseResource implements it (see SetDeleteConfirmation).
+//
+// DeleteConfirmation returns nil when no phrase is required. Otherwise the
+// delete modal asks for the phrase and the CRUDHandler rejects deletions
+// whose confirm_phrase field does not match it.
+type ResourceDeleteConfirmable interface {
+	DeleteConfirmation() func(item any) string
+}
+
+// DeleteChildrenField is the form field of the delete modal by which the
+// user chooses to delete the related records of an OnDeleteCascade relation
+// with the record ("delete with children").
+const DeleteChildrenField = "delete_children"
+
+// DeleteImpact is a line of the summary shown in the delete modal, e.g.
+// "12 Comments will be deleted".
+type DeleteImpact struct {
+	Label    string // what is affected, e.g. "Comments"
+	Count    int
+	Icon     string
+	Detach   bool // the records are detached rather than deleted
+	Restrict bool // the records prevent the deletion (OnDeleteRestrict)
+	Cascade  bool // the records are deleted only if the user chooses so (OnDeleteCascade)
+}
+
+// ResourceDeleteSummarizer is an optional interface for resources that
+// describe what deleting a record will affect. The CRUDHandler serves it as
+// an HTML fragment loaded into the delete modal:
+//
+//	GET /{slug}/{id}/delete-summary
+//
+// Without it, the summary counts the related records of the relation
+// managers of the resource (see RelationManagerAware), according to the
+// OnDelete policy of their relation.
+type ResourceDeleteSummarizer interface {
+	DeleteSummary(ctx context.Context, id string) ([]DeleteImpact, error)
+}
+
+// DeleteSummary renders what deleting a record will affect, or responds
+// 204 No Content when nothing else is affected.
+func (h *CRUDHandler) DeleteSummary(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	if !h.Resource.CanDelete(ctx) {
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
+		return
+	}
+
+	impacts, err := h.deleteImpacts(ctx, id)
+	if err != nil {
+		apperrors.Handle(w, r, apperrors.Internal(err, "Delete summary error"))
+		return
+	}
+
+	lines := make([]components.SummaryLine, 0, len(impacts))
+	blocked, children := false, 0
+	for _, impact := range impacts {
+		if impact.Count <= 0 {
+			continue
+		}
+		line := components.SummaryLine{Label: impact.Label, Count: impact.Count, Icon: impact.Icon, Note: "will be deleted"}
+		switch {
+		case impact.Restrict:
+			line.Note, line.Blocking = "prevent the deletion", true
+			blocked = true
+		case impact.Cascade:
+			children += impact.Count
+		case impact.Detach:
+			line.Note = "will be detached"
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = components.ConfirmSummary(lines).Render(ctx, w)
+	if children > 0 && !blocked {
+		_ = components.DeleteChildrenOption(DeleteChildrenField, children).Render(ctx, w)
+	}
+}
+
+// deleteImpacts returns the summary of the resource, or counts the records
+// of its has-one, has-many and many-to-many relation managers.
+func (h *CRUDHandler) deleteImpacts(ctx context.Context, id string) ([]DeleteImpact, error) {
+	if s, ok := h.Resource.(ResourceDeleteSummarizer); ok {
+		return s.DeleteSummary(ctx, id)
+	}
+	rma, ok := h.Resource.(RelationManagerAware)
+	if !ok {
+		return nil, nil
+	}
+	_, soft := h.Resource.(SoftDeletable)
+	var impacts []DeleteImpact
+	for _, rm := range rma.GetRelationManagers() {
+		switch rm.RelationType() {
+		case RelationHasOne, RelationHasMany, RelationMorphMany, RelationManyToMany:
+		default:
+			continue
+		}
+		related, err := rm.ListRelated(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("relation %s: %w", rm.Name(), err)
+		}
+		impact := DeleteImpact{
+			Label:  rm.Label(),
+			Count:  len(related),
+			Icon:   rm.Icon(),
+			Detach: rm.RelationType() == RelationManyToMany,
+		}
+		switch policy := onDelete(h.Resource, rm); {
+		case soft:
+			// Soft-deleted records keep their related records
+		case policy == OnDeleteRestrict:
+			impact.Restrict = true
+		case policy == OnDeleteCascade:
+			impact.Cascade = true
+		case policy == OnDeleteSetNull:
+			impact.Detach = true
+		}
+		impacts = append(impacts, impact)
+	}
+	return impacts, nil
+}
+
+// onDelete returns the OnDelete policy of the relation of rm, empty when
+// the resource does not declare one. Many-to-many links are always removed:
+// only OnDeleteRestrict is kept for them.
+func onDelete(res Resource, rm RelationManager) OnDeleteAction {
+	rel := managerRelation(res, rm)
+	if rel == nil {
+		return ""
+	}
+	if rm.RelationType() == RelationManyToMany && rel.OnDelete != OnDeleteRestrict {
+		return ""
+	}
+	return rel.OnDelete
+}
+
+// deleteDependents enforces the OnDelete policies of the relations of the
+// records about to be deleted for good (soft deletes keep the related
+// records). It refuses the deletion with 409 Conflict while an
+// OnDeleteRestrict relation has records, or an OnDeleteCascade one has
+// records the user did not choose to delete (DeleteChildrenField).
+// Otherwise it deletes those records through their relation manager.
+func (h *CRUDHandler) deleteDependents(r *http.Request, ids ...string) error {
+	ctx := r.Context()
+	rma, ok := h.Resource.(RelationManagerAware)
+	if !ok {
+		return nil
+	}
+	withChildren := r.FormValue(DeleteChildrenField) != ""
+	type dependent struct {
+		rm       RelationManager
+		parentID string
+		items    []any
+	}
+	var children []dependent
+	for _, rm := range rma.GetRelationManagers() {
+		policy := onDelete(h.Resource, rm)
+		if policy != OnDeleteRestrict && policy != OnDeleteCascade {
+			continue
+		}
+		for _, id := range ids {
+			related, err := rm.ListRelated(ctx, id)
+			if err != nil {
+				return apperrors.Internal(fmt.Errorf("relation %s: %w", rm.Name(), err), "Delete error")
+			}
+			if len(related) == 0 {
+				continue
+			}
+			if policy == OnDeleteRestrict {
+				return apperrors.Conflict(fmt.Sprintf("This record cannot be deleted: %d %s depend on it.", len(related), rm.Label()))
+			}
+			if !withChildren {
+				return apperrors.Conflict(fmt.Sprintf("%d %s depend on this record. Choose to delete them with it to continue.", len(related), rm.Label()))
+			}
+			children = append(children, dependent{rm: rm, parentID: id, items: related})
+		}
+	}
+	for _, c := range children {
+		for _, item := range c.items {
+			if err := c.rm.DeleteRelated(ctx, c.parentID, getItemID(item)); err != nil {
+				return apperrors.Internal(fmt.Errorf("relation %s: %w", c.rm.Name(), err), "Delete error")
+			}
+		}
+	}
+	return nil
+}
+
+// checkDeleteConfirmation verifies the phrase typed in the delete modal
+// when the resource requires one.
+func (h *CRUDHandler) checkDeleteConfirmation(r *http.Request, id string) error {
+	c, ok := h.Resource.(ResourceDeleteConfirmable)
+	if !ok || c.DeleteConfirmation() == nil {
+		return nil
+	}
+	item, err := h.Resource.Get(r.Context(), id)
+	if err != nil {
+		return apperrors.NotFound("")
+	}
+	phrase := c.DeleteConfirmation()(item)
+	if phrase == "" || strings.TrimSpace(r.FormValue(actions.ConfirmPhraseField)) == phrase {
+		return nil
+	}
+	return apperrors.BadRequestf("Type %q to confirm the deletion.", phrase)
+}
@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// confirmResource requires typing the name of the record to delete it and
+// exposes relation managers for the delete summary.
+type confirmResource struct {
+	*mockResource
+	managers []RelationManager
+}
+
+func newConfirmResource(managers ...RelationManager) *confirmResource {
+	r := &confirmResource{mockResource: newMockResource("projects"), managers: managers}
+	r.SetDeleteConfirmation(func(item any) string { return item.(*testItem).Name })
+	return r
+}
+
+func (r *confirmResource) Get(_ context.Context, id string) (any, error) {
+	return &testItem{Name: "acme-" + id}, nil
+}
+
+func (r *confirmResource) GetRelationManagers() []RelationManager { return r.managers }
+
+func TestCRUDHandler_Delete_confirm_phrase(t *testing.T) {
+	res := newConfirmResource()
+	h := newHandler(res)
+
+	for phrase, want := range map[string]int{"": http.StatusBadRequest, "acme": http.StatusBadRequest, "acme-3": http.StatusSeeOther} {
+		res.deleteCalledWith = ""
+		rw := serveWith(h, http.MethodPost, "/projects/3", url.Values{"_method": {"DELETE"}, "confirm_phrase": {phrase}})
+		if rw.Code != want {
+			t.Errorf("phrase %q: expected %d, got %d", phrase, want, rw.Code)
+		}
+		if deleted := res.deleteCalledWith == "3"; deleted != (want == http.StatusSeeOther) {
+			t.Errorf("phrase %q: unexpected deletion state %v", phrase, deleted)
+		}
+	}
+
+	// Without SetDeleteConfirmation a plain confirmation is enough
+	res.SetDeleteConfirmation(nil)
+	if rw := serveWith(h, http.MethodPost, "/projects/3", url.Values{"_method": {"DELETE"}}); rw.Code != http.StatusSeeOther {
+		t.Errorf("expected 303 without a confirmation phrase, got %d", rw.Code)
+	}
+}
+
+func TestBaseResource_DeletePhrase_in_rows(t *testing.T) {
+	b := NewBaseResource("projects", "Project", "Projects").
+		SetDeleteConfirmation(func(item any) string { return item.(*testItem).Name })
+	rows := b.buildRows([]any{&testItem{Name: "acme"}})
+	if rows[0].DeletePhrase != "acme" {
+		t.Errorf("expected the delete phrase on the row, got %q", rows[0].DeletePhrase)
+	}
+}
+
+func TestCRUDHandler_DeleteSummary(t *testing.T) {
+	comments := newMockRM("comments")
+	comments.listItems = []any{1, 2, 3}
+	tags := newMockRM("tags")
+	tags.relationType = RelationManyToMany
+	tags.listItems = []any{1}
+	empty := newMockRM("files")
+
+	rw := serveWith(newHandler(newConfirmResource(comments, tags, empty)), http.MethodGet, "/projects/3/delete-summary", nil)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rw.Code)
+	}
+	body := rw.Body.String()
+	for _, want := range []string{">3</strong> comments Label", "will be deleted", ">1</strong> tags Label", "will be detached"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in the summary, got %s", want, body)
+		}
+	}
+	if strings.Contains(body, "files Label") {
+		t.Error("expected relations without records to be left out")
+	}
+
+	// Nothing else affected
+	rw = serveWith(newHandler(newConfirmResource(empty)), http.MethodGet, "/projects/3/delete-summary", nil)
+	if rw.Code != http.StatusNoContent {
+		t.Errorf("expected 204 without related records, got %d", rw.Code)
+	}
+
+	rw = serveWith(&CRUDHandler{Resource: &noDeleteResource{BaseResource: newMockResource("projects").BaseResource}}, http.MethodGet, "/projects/3/delete-summary", nil)
+	if rw.Code != http.StatusForbidden {
+		t.Errorf("expected 403 when CanDelete=false, got %d", rw.Code)
+	}
+}
+
+type summarizedResource struct{ *mockResource }
+
+func (s *summarizedResource) DeleteSummary(_ context.Context, id string) ([]DeleteImpact, error) {
+	return []DeleteImpact{{Label: "invoices of " + id, Count: 2, Icon: "receipt"}}, nil
+}
+
+func TestCRUDHandler_DeleteSummary_custom(t *testing.T) {
+	rw := serveWith(newHandler(&summarizedResource{newMockResource("clients")}), http.MethodGet, "/clients/9/delete-summary", nil)
+	if rw.Code != http.StatusOK || !strings.Contains(rw.Body.String(), ">2</strong> invoices of 9") {
+		t.Errorf("expected the summary of the resource, got %d %s", rw.Code, rw.Body.String())
+	}
+}
+
+// policyResource declares the OnDelete policies of its relations.
+type policyResource struct {
+	*confirmResource
+	relations []*Relation
+}
+
+func (r *policyResource) GetRelations() []*Relation { return r.relations }
+
+func TestCRUDHandler_Delete_relation_policies(t *testing.T) {
+	comments := newMockRM("comments")
+	comments.listItems = []any{&testItem{Name: "a"}, &testItem{Name: "b"}}
+	invoices := newMockRM("invoices")
+	files := newMockRM("files")
+	files.listItems = []any{1}
+	res := &policyResource{newConfirmResource(comments, invoices, files), []*Relation{
+		HasMany("comments", "comments").OnDelete(OnDeleteCascade).Build(),
+		HasMany("invoices", "invoices").OnDelete(OnDeleteRestrict).Build(),
+		HasMany("files", "files").OnDelete(OnDeleteSetNull).Build(),
+	}}
+	res.SetDeleteConfirmation(nil)
+	h := newHandler(res)
+
+	body := serveWith(h, http.MethodGet, "/projects/3/delete-summary", nil).Body.String()
+	for _, want := range []string{">2</strong> comments Label", `name="delete_children"`, ">1</strong> files Label", "will be detached"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in the summary, got %s", want, body)
+		}
+	}
+	if strings.Contains(body, "data-confirm-blocked") {
+		t.Error("expected the deletion not blocked without invoices")
+	}
+
+	rw := serveWith(h, http.MethodPost, "/projects/3", url.Values{"_method": {"DELETE"}})
+	if rw.Code != http.StatusConflict || res.deleteCalledWith != "" || comments.deleteCalled {
+		t.Errorf("expected 409 without choosing to delete the comments, got %d", rw.Code)
+	}
+	rw = serveWith(h, http.MethodPost, "/projects/3", url.Values{"_method": {"DELETE"}, DeleteChildrenField: {"1"}})
+	if rw.Code != http.StatusSeeOther || res.deleteCalledWith != "3" || !comments.deleteCalled {
+		t.Errorf("expected the record deleted with its comments, got %d", rw.Code)
+	}
+
+	// Invoices prevent the deletion
+	res.deleteCalledWith, comments.deleteCalled = "", false
+	invoices.listItems = []any{1}
+	body = serveWith(h, http.MethodGet, "/projects/3/delete-summary", nil).Body.String()
+	if !strings.Contains(body, "data-confirm-blocked") || !strings.Contains(body, "prevent the deletion") || strings.Contains(body, "delete_children") {
+		t.Errorf("expected a blocked summary, got %s", body)
+	}
+	rw = serveWith(h, http.MethodPost, "/projects/3", url.Values{"_method": {"DELETE"}, DeleteChildrenField: {"1"}})
+	if rw.Code != http.StatusConflict || res.deleteCalledWith != "" || comments.deleteCalled {
+		t.Errorf("expected 409 with invoices, got %d", rw.Code)
+	}
+	rw = serveWith(h, http.MethodPost, "/projects/bulk-delete", url.Values{"ids[]": {"3", "4"}})
+	if rw.Code != http.StatusConflict {
+		t.Errorf("expected the bulk deletion refused, got %d", rw.Code)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/bozz33/sublimeadmin/apperrors"
 	"github.com/bozz33/sublimeadmin/export"
 	"github.com/bozz33/sublimeadmin/importer"
 )
@@ -23,11 +24,17 @@ func NewExportHandler(r Resource, format export.Format) *ExportHandler {
 
 // ServeHTTP streams the export file to the client.
 func (h *ExportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	scope, err := resourceScope(r.Context(), h.resource)
+	if err != nil {
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
+		return
+	}
 	items, err := h.resource.List(r.Context())
 	if err != nil {
-		http.Error(w, "Failed to list items: "+err.Error(), http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, "Failed to list items"))
 		return
 	}
+	items = scopeItems(items, scope)
 
 	format := h.format
 	if q := r.URL.Query().Get("format"); q == "xlsx" {
@@ -42,7 +49,7 @@ func (h *ExportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	exp := export.New(format).FromStructs(items)
 	if err := exp.Write(w); err != nil {
-		http.Error(w, "Export failed: "+err.Error(), http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, "Export failed"))
 	}
 }
 
@@ -72,7 +79,7 @@ func (h *ImportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case http.MethodPost:
 		h.handleUpload(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		apperrors.Handle(w, r, apperrors.MethodNotAllowed(""))
 	}
 }
 
@@ -90,13 +97,13 @@ func (h *ImportHandler) showForm(w http.ResponseWriter, r *http.Request) {
 
 func (h *ImportHandler) handleUpload(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseMultipartForm(32 << 20); err != nil {
-		http.Error(w, "Failed to parse form: "+err.Error(), http.StatusBadRequest)
+		apperrors.Handle(w, r, apperrors.Wrap(err, apperrors.CodeBadRequest, "Failed to parse form", http.StatusBadRequest))
 		return
 	}
 
 	file, header, err := r.FormFile("file")
 	if err != nil {
-		http.Error(w, "No file uploaded", http.StatusBadRequest)
+		apperrors.Handle(w, r, apperrors.BadRequest("No file uploaded"))
 		return
 	}
 	defer func() { _ = file.Close() }()
@@ -104,21 +111,21 @@ func (h *ImportHandler) handleUpload(w http.ResponseWriter, r *http.Request) {
 	// Resource must implement ResourceImportable to handle rows
 	importable, ok := h.resource.(ResourceImportable)
 	if !ok {
-		http.Error(w, "This resource does not support import", http.StatusNotImplemented)
+		apperrors.Handle(w, r, apperrors.NotImplemented("This resource does not support import"))
 		return
 	}
 
 	imp := importer.New(importer.DefaultConfig())
 	result, err := imp.ImportFromFile(r.Context(), file, header, importable.ImportRow)
 	if err != nil {
-		http.Error(w, "Import failed: "+err.Error(), http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, "Import failed"))
 		return
 	}
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	_, _ = fmt.Fprintf(w, `<p>Import complete: %d success, %d errors, %d skipped.</p>
-<a href="/%s">Back to list</a>`,
-		result.SuccessCount, result.ErrorCount, result.SkippedCount, h.resource.Slug())
+<a href="%s">Back to list</a>`,
+		result.SuccessCount, result.ErrorCount, result.SkippedCount, PanelURL(r.Context(), "/"+h.resource.Slug()))
 }
 
 // ResourceImportable is an optional interface for resources that support import.
@@ -0,0 +1,294 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/apperrors"
+	"github.com/bozz33/sublimeadmin/search"
+	"github.com/bozz33/sublimeadmin/ui/layouts"
+)
+
+// Favorite is a record, or a whole resource, pinned by a user: it is listed
+// in the "Pinned" group of the sidebar and first in the command palette.
+type Favorite struct {
+	Resource string    // slug of the resource
+	RecordID string    // "" when the resource itself is pinned
+	Label    string    // title of the record (see ResourceRecordTitle) or plural label of the resource
+	Tenant   string    // ID of the tenant of the record, "" without tenants
+	PinnedAt time.Time // when it was pinned
+}
+
+// FavoriteStore persists the favorites of each user.
+// Implementations must be safe for concurrent use; Favorites is called on
+// every page of an authenticated user, so keep it cheap.
+//
+// MemoryFavoriteStore (default) keeps the favorites per process. Implement
+// FavoriteStore on top of a table to keep them across restarts.
+type FavoriteStore interface {
+	// Favorites returns the favorites of the user, in the order pinned.
+	Favorites(ctx context.Context, userID int) ([]Favorite, error)
+	// AddFavorite pins fav, replacing the favorite of the same record.
+	AddFavorite(ctx context.Context, userID int, fav Favorite) error
+	// RemoveFavorite unpins the record of the resource ("" for the
+	// resource itself) in the tenant.
+	RemoveFavorite(ctx context.Context, userID int, tenant, resource, recordID string) error
+}
+
+// MemoryFavoriteStore is an in-process FavoriteStore.
+type MemoryFavoriteStore struct {
+	mu        sync.RWMutex
+	favorites map[int][]Favorite
+}
+
+// NewMemoryFavoriteStore creates an empty in-memory store.
+func NewMemoryFavoriteStore() *MemoryFavoriteStore {
+	return &MemoryFavoriteStore{favorites: make(map[int][]Favorite)}
+}
+
+// Favorites implements FavoriteStore.
+func (s *MemoryFavoriteStore) Favorites(_ context.Context, userID int) ([]Favorite, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.favorites[userID]), nil
+}
+
+// AddFavorite implements FavoriteStore.
+func (s *MemoryFavoriteStore) AddFavorite(_ context.Context, userID int, fav Favorite) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	favs := s.favorites[userID]
+	if i := slices.IndexFunc(favs, func(f Favorite) bool { return sameFavorite(f, fav.Tenant, fav.Resource, fav.RecordID) }); i >= 0 {
+		favs[i] = fav
+		return nil
+	}
+	s.favorites[userID] = append(favs, fav)
+	return nil
+}
+
+// RemoveFavorite implements FavoriteStore.
+func (s *MemoryFavoriteStore) RemoveFavorite(_ context.Context, userID int, tenant, resource, recordID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.favorites[userID] = slices.DeleteFunc(s.favorites[userID], func(f Favorite) bool {
+		return sameFavorite(f, tenant, resource, recordID)
+	})
+	return nil
+}
+
+// sameFavorite reports whether f pins the record of the resource in the
+// tenant.
+func sameFavorite(f Favorite, tenant, resource, recordID string) bool {
+	return f.Tenant == tenant && f.Resource == resource && f.RecordID == recordID
+}
+
+// WithFavorites lets the users star records and resources: a star toggles
+// them in the list of each resource, and the pinned ones are listed in the
+// sidebar and the command palette. Defaults to a MemoryFavoriteStore when
+// store is nil. Favorites need an AuthManager.
+func (p *Panel) WithFavorites(store FavoriteStore) *Panel {
+	if store == nil {
+		store = NewMemoryFavoriteStore()
+	}
+	p.favoriteStore = store
+	return p
+}
+
+// favoritesEnabled reports whether the users of the panel can pin records.
+func (p *Panel) favoritesEnabled() bool {
+	return p != nil && p.favoriteStore != nil && p.AuthManager != nil
+}
+
+type favoritesKey struct{}
+
+// requestFavorites loads the favorites of the user of a request once, for
+// the sidebar, the list and the palette rendering it.
+type requestFavorites struct {
+	once  sync.Once
+	load  func() []Favorite
+	items []Favorite
+}
+
+// withFavorites returns ctx loading the favorites of the user of r, in the
+// tenant of the request, on first use.
+func (p *Panel) withFavorites(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, favoritesKey{}, &requestFavorites{load: func() []Favorite {
+		id := p.AuthManager.UserIDFromRequest(r)
+		if id <= 0 {
+			return nil
+		}
+		favs, err := p.favoriteStore.Favorites(ctx, id)
+		if err != nil {
+			return nil
+		}
+		tenant := tenantID(ctx)
+		return slices.DeleteFunc(favs, func(f Favorite) bool { return f.Tenant != tenant })
+	}})
+}
+
+// userFavorites returns the favorites of the user of the request.
+func userFavorites(ctx context.Context) []Favorite {
+	rf, ok := ctx.Value(favoritesKey{}).(*requestFavorites)
+	if !ok {
+		return nil
+	}
+	rf.once.Do(func() { rf.items = rf.load() })
+	return rf.items
+}
+
+// isFavorite reports whether the user of the request pinned the record of
+// the resource ("" for the resource itself).
+func isFavorite(ctx context.Context, resource, recordID string) bool {
+	return slices.ContainsFunc(userFavorites(ctx), func(f Favorite) bool {
+		return f.Resource == resource && f.RecordID == recordID
+	})
+}
+
+// favoritesURL returns the target of the star toggles of the panel of ctx,
+// "" when favorites are disabled.
+func favoritesURL(ctx context.Context) string {
+	if _, ok := ctx.Value(favoritesKey{}).(*requestFavorites); !ok {
+		return ""
+	}
+	return PanelURL(ctx, "/api/favorites")
+}
+
+// tenantID returns the ID of the tenant of ctx, "" without one.
+func tenantID(ctx context.Context) string {
+	if t := TenantFromContext(ctx); t != nil {
+		return t.ID
+	}
+	return ""
+}
+
+// pinnedFavorite is a favorite of the user with its resource, readable by
+// the user.
+type pinnedFavorite struct {
+	Favorite
+	res Resource
+}
+
+// Path returns the path of the favorite relative to the panel: the list of
+// the resource or the page of the record.
+func (f pinnedFavorite) Path(ctx context.Context) string {
+	if f.RecordID == "" {
+		return f.res.Slug()
+	}
+	return recordPath(ctx, f.res, f.RecordID)
+}
+
+// pinnedFavorites returns the favorites of the user of ctx whose resource
+// is in the panel and readable.
+func (p *Panel) pinnedFavorites(ctx context.Context) []pinnedFavorite {
+	favs := userFavorites(ctx)
+	pinned := make([]pinnedFavorite, 0, len(favs))
+	for _, f := range favs {
+		if res := p.resource(f.Resource); res != nil && res.CanRead(ctx) {
+			pinned = append(pinned, pinnedFavorite{Favorite: f, res: res})
+		}
+	}
+	return pinned
+}
+
+// resource returns the resource of the panel with slug, or nil.
+func (p *Panel) resource(slug string) Resource {
+	for _, res := range p.Resources {
+		if res.Slug() == slug {
+			return res
+		}
+	}
+	return nil
+}
+
+// pinnedNavGroup returns the "Pinned" group of the sidebar, empty (hence
+// hidden) until the user pins something.
+func (p *Panel) pinnedNavGroup() layouts.NavGroup {
+	return layouts.NavGroup{
+		Label:       "Pinned",
+		Icon:        "star",
+		Collapsible: p.collapsibleNavGroups,
+		ItemsFunc: func(ctx context.Context) []layouts.NavItem {
+			pinned := p.pinnedFavorites(ctx)
+			items := make([]layouts.NavItem, 0, len(pinned))
+			for _, f := range pinned {
+				items = append(items, layouts.NavItem{Slug: f.Path(ctx), Label: f.Label, Icon: f.res.Icon()})
+			}
+			return items
+		},
+	}
+}
+
+// handleFavorites lists and toggles the favorites of the user:
+//
+//	GET  /api/favorites                      the favorites, as search results
+//	POST /api/favorites  resource, id ("" for the resource itself)
+//
+// A POST pins the record when it is not pinned, else unpins it, and answers
+// {"pinned": bool}.
+func (p *Panel) handleFavorites(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		pinned := p.pinnedFavorites(ctx)
+		results := make([]search.Result, 0, len(pinned))
+		for _, f := range pinned {
+			results = append(results, search.Result{
+				ID:           f.Resource + "/" + f.RecordID,
+				Title:        f.Label,
+				URL:          PanelURL(ctx, "/"+f.Path(ctx)),
+				Icon:         f.res.Icon(),
+				ResourceType: f.res.PluralLabel(),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(results)
+	case http.MethodPost:
+		p.toggleFavorite(w, r)
+	default:
+		apperrors.Handle(w, r, apperrors.MethodNotAllowed(""))
+	}
+}
+
+// toggleFavorite pins or unpins the record of the form of r.
+func (p *Panel) toggleFavorite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID := p.AuthManager.UserIDFromRequest(r)
+	if userID <= 0 {
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
+		return
+	}
+	res := p.resource(r.FormValue("resource"))
+	if res == nil || !res.CanRead(ctx) {
+		apperrors.Handle(w, r, apperrors.NotFoundf("Resource %s not found", r.FormValue("resource")))
+		return
+	}
+	id, tenant := r.FormValue("id"), tenantID(ctx)
+
+	pinned := !isFavorite(ctx, res.Slug(), id)
+	var err error
+	if pinned {
+		fav := Favorite{Resource: res.Slug(), RecordID: id, Label: res.PluralLabel(), Tenant: tenant, PinnedAt: time.Now()}
+		if id != "" {
+			item, getErr := res.Get(ctx, id)
+			if getErr != nil || item == nil {
+				apperrors.Handle(w, r, apperrors.NotFoundf("Record %s not found", id))
+				return
+			}
+			fav.Label = recordTitle(res, id, item)
+		}
+		err = p.favoriteStore.AddFavorite(ctx, userID, fav)
+	} else {
+		err = p.favoriteStore.RemoveFavorite(ctx, userID, tenant, res.Slug(), id)
+	}
+	if err != nil {
+		apperrors.Handle(w, r, apperrors.Internal(err, "Favorites error"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]bool{"pinned": pinned})
+}
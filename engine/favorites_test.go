@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/bozz33/sublimeadmin/auth"
+	"github.com/bozz33/sublimeadmin/search"
+	"github.com/bozz33/sublimeadmin/ui/layouts"
+)
+
+// favoritesPanel returns a panel with favorites and a handler serving h as
+// the user 1.
+func favoritesPanel(posts *[]*blogPost) (*Panel, func(h http.HandlerFunc) http.Handler) {
+	sm := scs.New()
+	p := NewPanel("favorites-test").WithFavorites(nil)
+	p.AuthManager = auth.NewManager(sm)
+	p.AddResources(newPostResource(posts))
+	p.registerNavItems()
+	serve := func(h http.HandlerFunc) http.Handler {
+		return sm.LoadAndSave(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = p.AuthManager.Login(r.Context(), auth.NewUser(1, "ann@example.com", "Ann"))
+			p.injectConfig(h).ServeHTTP(w, r)
+		}))
+	}
+	return p, serve
+}
+
+func TestPanel_Favorites(t *testing.T) {
+	posts := []*blogPost{{ID: 1, Title: "Hello"}}
+	p, serve := favoritesPanel(&posts)
+	h := serve(p.handleFavorites)
+
+	toggle := func(resource, id string) (int, bool) {
+		form := url.Values{"resource": {resource}, "id": {id}}
+		req := httptest.NewRequest(http.MethodPost, "/api/favorites", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		var body struct{ Pinned bool }
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec.Code, body.Pinned
+	}
+	list := func() []search.Result {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/favorites", nil))
+		var results []search.Result
+		if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+			t.Fatalf("GET /api/favorites: %v", err)
+		}
+		return results
+	}
+
+	if code, pinned := toggle("blog-posts", "1"); code != http.StatusOK || !pinned {
+		t.Fatalf("pin the record: status %d, pinned %v", code, pinned)
+	}
+	if code, pinned := toggle("blog-posts", ""); code != http.StatusOK || !pinned {
+		t.Fatalf("pin the resource: status %d, pinned %v", code, pinned)
+	}
+	results := list()
+	if len(results) != 2 || !strings.HasSuffix(results[0].URL, "/blog-posts/1/edit") || results[1].Title != "Blog Posts" {
+		t.Errorf("favorites = %+v", results)
+	}
+
+	if _, pinned := toggle("blog-posts", "1"); pinned {
+		t.Error("a second toggle should unpin the record")
+	}
+	if results := list(); len(results) != 1 {
+		t.Errorf("favorites after unpinning = %+v", results)
+	}
+
+	if code, _ := toggle("blog-posts", "42"); code != http.StatusNotFound {
+		t.Errorf("pin a missing record: status %d, want 404", code)
+	}
+	if code, _ := toggle("nope", ""); code != http.StatusNotFound {
+		t.Errorf("pin an unknown resource: status %d, want 404", code)
+	}
+}
+
+func TestPanel_Favorites_nav(t *testing.T) {
+	posts := []*blogPost{{ID: 1, Title: "Hello"}}
+	p, serve := favoritesPanel(&posts)
+	_ = p.favoriteStore.AddFavorite(context.Background(), 1, Favorite{Resource: "blog-posts", RecordID: "1", Label: "Hello"})
+	_ = p.favoriteStore.AddFavorite(context.Background(), 1, Favorite{Resource: "gone", Label: "Gone"})
+
+	var groups []layouts.NavGroup
+	var state TableState
+	serve(func(w http.ResponseWriter, r *http.Request) {
+		groups = layouts.ResolveNavGroups(r.Context(), layouts.GetNavGroups(r.Context()))
+		ctx := context.WithValue(r.Context(), paginationContextKey, listedPage([]any{posts[0]}))
+		state, _ = newMockResource("blog-posts").BuildTableState(ctx, true, true)
+	}).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var pinned *layouts.NavGroup
+	for i := range groups {
+		if groups[i].Label == "Pinned" {
+			pinned = &groups[i]
+		}
+	}
+	if pinned == nil || len(pinned.Items) != 1 || pinned.Items[0].Slug != "blog-posts/1/edit" {
+		t.Fatalf("pinned group = %+v", pinned)
+	}
+	if state.FavoritesURL == "" || state.Pinned || len(state.Rows) != 1 || !state.Rows[0].Pinned {
+		t.Errorf("table: favorites URL %q, resource pinned %v, rows %+v", state.FavoritesURL, state.Pinned, state.Rows)
+	}
+}
+
+func TestMemoryFavoriteStore(t *testing.T) {
+	s := NewMemoryFavoriteStore()
+	ctx := context.Background()
+	_ = s.AddFavorite(ctx, 1, Favorite{Resource: "posts", RecordID: "1", Label: "Old"})
+	_ = s.AddFavorite(ctx, 1, Favorite{Resource: "posts", RecordID: "1", Label: "New"})
+	_ = s.AddFavorite(ctx, 1, Favorite{Resource: "posts", RecordID: "1", Tenant: "acme"})
+	favs, _ := s.Favorites(ctx, 1)
+	if len(favs) != 2 || favs[0].Label != "New" {
+		t.Errorf("expected one favorite per record and tenant, got %+v", favs)
+	}
+	if favs, _ := s.Favorites(ctx, 2); len(favs) != 0 {
+		t.Errorf("expected the favorites to be per user, got %+v", favs)
+	}
+	_ = s.RemoveFavorite(ctx, 1, "", "posts", "1")
+	if favs, _ := s.Favorites(ctx, 1); len(favs) != 1 || favs[0].Tenant != "acme" {
+		t.Errorf("expected the favorite of the tenant to stay, got %+v", favs)
+	}
+}
@@ -0,0 +1,204 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+)
+
+// ErrInvalidFilter is wrapped by the errors of a FilterCompiler: a field
+// outside its whitelist, an unknown operator or a malformed value. Answer
+// it with 400 Bad Request.
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// filterComparisons are the predicates of the comparison operators.
+var filterComparisons = map[FilterOperator]func(col string, value any) *sql.Predicate{
+	FilterGt:  sql.GT,
+	FilterGte: sql.GTE,
+	FilterLt:  sql.LT,
+	FilterLte: sql.LTE,
+}
+
+// FilterCompiler translates the FilterExpr trees of ParseFiltersJSON into
+// Ent predicates and SQL WHERE clauses. Only the fields of its whitelist can
+// be filtered on, and values always become placeholders:
+//
+//	filters := engine.NewFilterCompiler("name", "age").
+//		Field("created", "created_at")
+//
+//	// Ent
+//	p, err := filters.Selector(params.Filters)
+//	users, err := client.User.Query().Where(predicate.User(p)).All(ctx)
+//
+//	// SQL
+//	where, args, err := filters.WithDialect(dialect.Postgres).SQL(params.Filters)
+//	rows, err := db.QueryContext(ctx, "SELECT * FROM users WHERE "+where, args...)
+//
+// Filters of a list are ANDed, and the groups of ["OR"] are ORed. The
+// operators are those of FilterOperator; like matches a substring.
+type FilterCompiler struct {
+	fields  map[string]string // filter field → column
+	dialect string
+}
+
+// NewFilterCompiler creates a compiler allowing the given fields, each
+// stored in the column of the same name.
+func NewFilterCompiler(fields ...string) *FilterCompiler {
+	c := &FilterCompiler{fields: make(map[string]string, len(fields)), dialect: dialect.MySQL}
+	for _, f := range fields {
+		c.fields[f] = f
+	}
+	return c
+}
+
+// Field allows the filter field name, stored in column.
+func (c *FilterCompiler) Field(name, column string) *FilterCompiler {
+	c.fields[name] = column
+	return c
+}
+
+// WithTenant allows the tenant field of s, so that the filters scoped by
+// tenant compile (see TenantScoper).
+func (c *FilterCompiler) WithTenant(s *TenantScoper) *FilterCompiler {
+	return c.Field(s.Field(), s.Field())
+}
+
+// WithDialect sets the SQL dialect (dialect.MySQL, dialect.SQLite or
+// dialect.Postgres) of the clauses of SQL: its identifier quotes and
+// placeholders. The default is MySQL: `name` = ?.
+func (c *FilterCompiler) WithDialect(name string) *FilterCompiler {
+	c.dialect = name
+	return c
+}
+
+// Predicate compiles the filters into an Ent SQL predicate on unqualified
+// columns, or nil when there are none.
+func (c *FilterCompiler) Predicate(filters []*FilterExpr) (*sql.Predicate, error) {
+	return c.compileAll(filters, sql.And, func(col string) string { return col })
+}
+
+// Selector compiles the filters into a predicate of an Ent query, with the
+// columns qualified by the table of the query. Convert it to the predicate
+// type of the entity, e.g. predicate.User(p). Without filters, it selects
+// every row.
+func (c *FilterCompiler) Selector(filters []*FilterExpr) (func(*sql.Selector), error) {
+	// Validate once, so that the query itself cannot fail.
+	if _, err := c.Predicate(filters); err != nil {
+		return nil, err
+	}
+	return func(s *sql.Selector) {
+		if p, _ := c.compileAll(filters, sql.And, s.C); p != nil {
+			s.Where(p)
+		}
+	}, nil
+}
+
+// SQL compiles the filters into a WHERE clause (without the WHERE keyword)
+// and its arguments. The clause is empty when there are no filters.
+func (c *FilterCompiler) SQL(filters []*FilterExpr) (string, []any, error) {
+	p, err := c.Predicate(filters)
+	if err != nil || p == nil {
+		return "", nil, err
+	}
+	p.SetDialect(c.dialect)
+	where, args := p.Query()
+	return where, args, nil
+}
+
+// compileAll compiles a list of filters joined by join.
+func (c *FilterCompiler) compileAll(filters []*FilterExpr, join func(...*sql.Predicate) *sql.Predicate, col func(string) string) (*sql.Predicate, error) {
+	preds := make([]*sql.Predicate, 0, len(filters))
+	for _, f := range filters {
+		if f == nil {
+			continue
+		}
+		p, err := c.compile(f, col)
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, p)
+	}
+	switch len(preds) {
+	case 0:
+		return nil, nil
+	case 1:
+		return preds[0], nil
+	}
+	return join(preds...), nil
+}
+
+// compile compiles a single filter or group.
+func (c *FilterCompiler) compile(f *FilterExpr, col func(string) string) (*sql.Predicate, error) {
+	if len(f.And) > 0 || len(f.Or) > 0 {
+		and, err := c.compileAll(f.And, sql.And, col)
+		if err != nil {
+			return nil, err
+		}
+		or, err := c.compileAll(f.Or, sql.Or, col)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case and == nil:
+			return or, nil
+		case or == nil:
+			return and, nil
+		}
+		return sql.And(and, or), nil
+	}
+
+	column, ok := c.fields[f.Field]
+	if !ok {
+		return nil, fmt.Errorf("%w: field %q is not allowed", ErrInvalidFilter, f.Field)
+	}
+	column = col(column)
+
+	switch f.Operator {
+	case FilterEq, "":
+		if f.Value == nil {
+			return sql.IsNull(column), nil
+		}
+		return sql.EQ(column, f.Value), nil
+	case FilterNeq:
+		if f.Value == nil {
+			return sql.NotNull(column), nil
+		}
+		return sql.NEQ(column, f.Value), nil
+	case FilterGt, FilterGte, FilterLt, FilterLte:
+		if f.Value == nil {
+			return nil, fmt.Errorf("%w: %s %s needs a value", ErrInvalidFilter, f.Field, f.Operator)
+		}
+		return filterComparisons[f.Operator](column, f.Value), nil
+	case FilterLike, FilterNotLike:
+		if f.Value == nil {
+			return nil, fmt.Errorf("%w: %s %s needs a value", ErrInvalidFilter, f.Field, f.Operator)
+		}
+		p := sql.Contains(column, fmt.Sprint(f.Value))
+		if f.Operator == FilterNotLike {
+			p = sql.Not(p)
+		}
+		return p, nil
+	case FilterBetween:
+		bounds, ok := f.Value.([]any)
+		if !ok || len(bounds) != 2 {
+			return nil, fmt.Errorf("%w: %s between needs two values", ErrInvalidFilter, f.Field)
+		}
+		return sql.And(sql.GTE(column, bounds[0]), sql.LTE(column, bounds[1])), nil
+	case FilterIn, FilterNotIn:
+		values, ok := f.Value.([]any)
+		if !ok {
+			values = []any{f.Value}
+		}
+		if f.Operator == FilterNotIn {
+			return sql.NotIn(column, values...), nil
+		}
+		return sql.In(column, values...), nil
+	case FilterIsNull:
+		return sql.IsNull(column), nil
+	case FilterIsNotNull:
+		return sql.NotNull(column), nil
+	}
+	return nil, fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, f.Operator)
+}
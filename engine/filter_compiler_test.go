@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+)
+
+func compileFiltersSQL(t *testing.T, c *FilterCompiler, raw string) (string, []any, error) {
+	t.Helper()
+	filters, err := ParseFiltersJSON(raw)
+	if err != nil {
+		t.Fatalf("ParseFiltersJSON(%s): %v", raw, err)
+	}
+	return c.SQL(filters)
+}
+
+func TestFilterCompiler_SQL(t *testing.T) {
+	c := NewFilterCompiler("name", "age", "role", "deleted_at").Field("created", "created_at")
+
+	tests := []struct {
+		raw   string
+		where string
+		args  []any
+	}{
+		{`["name","john"]`, "`name` = ?", []any{"john"}},
+		{`["name","like","jo%"]`, "`name` LIKE ?", []any{`%jo\%%`}},
+		{`["name","not like","jo"]`, "NOT (`name` LIKE ?)", []any{"%jo%"}},
+		{`["age","between",[20,25]]`, "`age` >= ? AND `age` <= ?", []any{20.0, 25.0}},
+		{`["role","in",["admin","editor"]]`, "`role` IN (?, ?)", []any{"admin", "editor"}},
+		{`["role","not in",["guest"]]`, "`role` NOT IN (?)", []any{"guest"}},
+		{`["deleted_at","is null"]`, "`deleted_at` IS NULL", nil},
+		{`["deleted_at","is not null"]`, "`deleted_at` IS NOT NULL", nil},
+		{`["created","gte","2024-01-01"]`, "`created_at` >= ?", []any{"2024-01-01"}},
+		{
+			`[["name","john"],["age","gt",18]]`,
+			"`name` = ? AND `age` > ?", []any{"john", 18.0},
+		},
+		{
+			`[["name","like","jo"],["OR"],["age","lt",30],["role","admin"]]`,
+			"(`name` LIKE ? OR `age` < ?) AND `role` = ?", []any{"%jo%", 30.0, "admin"},
+		},
+		{``, "", nil},
+	}
+	for _, tt := range tests {
+		where, args, err := compileFiltersSQL(t, c, tt.raw)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.raw, err)
+			continue
+		}
+		if where != tt.where || !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("%s:\n got %s %v\nwant %s %v", tt.raw, where, args, tt.where, tt.args)
+		}
+	}
+}
+
+func TestFilterCompiler_SQL_postgres(t *testing.T) {
+	c := NewFilterCompiler("name", "age").WithDialect(dialect.Postgres)
+	where, args, err := compileFiltersSQL(t, c, `[["name","john"],["age","between",[1,2]]]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"name" = $1 AND ("age" >= $2 AND "age" <= $3)`; where != want {
+		t.Errorf("got %s, want %s", where, want)
+	}
+	if len(args) != 3 {
+		t.Errorf("expected 3 args, got %v", args)
+	}
+}
+
+func TestFilterCompiler_invalid(t *testing.T) {
+	c := NewFilterCompiler("name", "age")
+	for _, raw := range []string{
+		`["password","secret"]`,
+		`["name","regexp","^a"]`,
+		`["age","between",[1]]`,
+		`["age","gt",null]`,
+		`[["name","john"],["OR"],["email","x"]]`,
+	} {
+		if _, _, err := compileFiltersSQL(t, c, raw); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("%s: expected ErrInvalidFilter, got %v", raw, err)
+		}
+	}
+}
+
+func TestFilterCompiler_Selector(t *testing.T) {
+	c := NewFilterCompiler("name")
+	filters, _ := ParseFiltersJSON(`["name","like","jo"]`)
+	p, err := c.Selector(filters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := sql.Select("*").From(sql.Table("users"))
+	p(s)
+	query, args := s.Query()
+	if want := "SELECT * FROM `users` WHERE `users`.`name` LIKE ?"; query != want {
+		t.Errorf("got %s, want %s", query, want)
+	}
+	if !reflect.DeepEqual(args, []any{"%jo%"}) {
+		t.Errorf("unexpected args %v", args)
+	}
+
+	if _, err := c.Selector([]*FilterExpr{{Field: "email", Operator: FilterEq, Value: "x"}}); !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("expected ErrInvalidFilter, got %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/bozz33/sublimeadmin/table"
+)
+
+// ResourceFilterPanel is implemented by the resources listing typed filters
+// in the filter panel of their table (see BaseResource.SetFilters).
+// CRUDHandler.List translates the values of the filters into FilterExpr
+// added to ListQuery.Where, so ResourceQueryable and PaginatedResource get
+// them like any other filter, and remembers them for the user: paging,
+// sorting or coming back to the list keeps them.
+//
+// The select, multiselect, boolean, date, number, relation and text filters
+// of the table package are supported.
+type ResourceFilterPanel interface {
+	FilterPanel(ctx context.Context) []table.Filter
+}
+
+// FilterChip is an active filter of the filter panel, shown above the table.
+type FilterChip struct {
+	Label     string // e.g. "Status: Published"
+	RemoveURL string // the list without this filter
+}
+
+// filterPanelParam marks the lists requested from the filter panel: their
+// filters replace those remembered for the list, even when there are none.
+const filterPanelParam = "filtered"
+
+// filterPanelSessionKey is the session key of the filters remembered for
+// the list of a resource.
+func filterPanelSessionKey(slug string) string {
+	return "list_filters:" + slug
+}
+
+// filterPanelState is the filter panel of a list request.
+type filterPanelState struct {
+	values   url.Values // by param name, without the filter_ prefix
+	chips    []FilterChip
+	clearURL string
+}
+
+type filterPanelKey struct{}
+
+// filterPanelFromContext returns the filter panel of the list request of
+// ctx, or nil.
+func filterPanelFromContext(ctx context.Context) *filterPanelState {
+	fp, _ := ctx.Value(filterPanelKey{}).(*filterPanelState)
+	return fp
+}
+
+// applyFilterPanel moves the values of the panel filters of res from
+// lq.Filters to lq.Where, as FilterExpr, and returns the panel of the list,
+// nil when res has none.
+func applyFilterPanel(r *http.Request, res Resource, lq *ListQuery) *filterPanelState {
+	ctx := r.Context()
+	withPanel, ok := res.(ResourceFilterPanel)
+	if !ok {
+		return nil
+	}
+	filters := slices.DeleteFunc(slices.Clone(withPanel.FilterPanel(ctx)), func(f table.Filter) bool {
+		return filterParams(f) == nil
+	})
+	if len(filters) == 0 {
+		return nil
+	}
+
+	query := r.URL.Query()
+	values := url.Values{}
+	for _, f := range filters {
+		for _, param := range filterParams(f) {
+			if vs := slices.DeleteFunc(slices.Clone(query["filter_"+param]), func(v string) bool { return v == "" }); len(vs) > 0 {
+				values[param] = vs
+			}
+		}
+	}
+	if session := listSession(ctx); session != nil {
+		key := filterPanelSessionKey(res.Slug())
+		switch {
+		case query.Has(filterPanelParam) && len(values) == 0:
+			session.Remove(ctx, key)
+		case query.Has(filterPanelParam):
+			session.Put(ctx, key, values.Encode())
+		case len(values) == 0:
+			values, _ = url.ParseQuery(session.GetString(ctx, key))
+		}
+	}
+
+	fp := &filterPanelState{values: values, clearURL: filterPanelURL(query, nil)}
+	for _, f := range filters {
+		params := filterParams(f)
+		for _, param := range params {
+			delete(lq.Filters, param)
+		}
+		exprs, text := filterPanelExprs(ctx, f, values)
+		if len(exprs) == 0 {
+			continue
+		}
+		lq.Where = append(lq.Where, exprs...)
+		rest := url.Values{}
+		for param, vs := range values {
+			if !slices.Contains(params, param) {
+				rest[param] = vs
+			}
+		}
+		fp.chips = append(fp.chips, FilterChip{Label: f.Label() + ": " + text, RemoveURL: filterPanelURL(query, rest)})
+	}
+	return fp
+}
+
+// filterParams returns the params of the values of f, without the filter_
+// prefix, nil when the filter panel does not support f.
+func filterParams(f table.Filter) []string {
+	switch f.Type() {
+	case "select", "multiselect", "boolean", "relation", "text":
+		return []string{f.Key()}
+	case "date":
+		return []string{f.Key() + "_from", f.Key() + "_until"}
+	case "number":
+		return []string{f.Key() + "_min", f.Key() + "_max"}
+	}
+	return nil
+}
+
+// filterPanelExprs translates the values of f into filter expressions,
+// with the text of its chip. Invalid values are ignored.
+func filterPanelExprs(ctx context.Context, f table.Filter, values url.Values) ([]*FilterExpr, string) {
+	key := f.Key()
+	switch f.Type() {
+	case "select", "text":
+		v := values.Get(key)
+		if v == "" {
+			return nil, ""
+		}
+		if f.Type() == "text" {
+			return []*FilterExpr{{Field: key, Operator: FilterLike, Value: v}}, v
+		}
+		return []*FilterExpr{{Field: key, Operator: FilterEq, Value: v}}, optionLabel(FilterOptions(ctx, f), v)
+	case "boolean":
+		b, err := strconv.ParseBool(values.Get(key))
+		if err != nil {
+			return nil, ""
+		}
+		return []*FilterExpr{{Field: key, Operator: FilterEq, Value: b}}, optionLabel(f.FilterOptions(), strconv.FormatBool(b))
+	case "multiselect", "relation":
+		vs := values[key]
+		if len(vs) == 0 {
+			return nil, ""
+		}
+		options := FilterOptions(ctx, f)
+		labels := make([]string, len(vs))
+		in := make([]any, len(vs))
+		for i, v := range vs {
+			labels[i], in[i] = optionLabel(options, v), v
+		}
+		if len(vs) == 1 {
+			return []*FilterExpr{{Field: key, Operator: FilterEq, Value: vs[0]}}, labels[0]
+		}
+		return []*FilterExpr{{Field: key, Operator: FilterIn, Value: in}}, strings.Join(labels, ", ")
+	case "date":
+		var exprs []*FilterExpr
+		var text []string
+		if from, err := time.Parse(time.DateOnly, values.Get(key+"_from")); err == nil {
+			exprs = append(exprs, &FilterExpr{Field: key, Operator: FilterGte, Value: from})
+			text = append(text, "from "+from.Format(time.DateOnly))
+		}
+		if until, err := time.Parse(time.DateOnly, values.Get(key+"_until")); err == nil {
+			// The whole last day
+			exprs = append(exprs, &FilterExpr{Field: key, Operator: FilterLt, Value: until.AddDate(0, 0, 1)})
+			text = append(text, "until "+until.Format(time.DateOnly))
+		}
+		return exprs, strings.Join(text, " ")
+	case "number":
+		var exprs []*FilterExpr
+		var text []string
+		if v, err := strconv.ParseFloat(values.Get(key+"_min"), 64); err == nil {
+			exprs = append(exprs, &FilterExpr{Field: key, Operator: FilterGte, Value: v})
+			text = append(text, "≥ "+strconv.FormatFloat(v, 'f', -1, 64))
+		}
+		if v, err := strconv.ParseFloat(values.Get(key+"_max"), 64); err == nil {
+			exprs = append(exprs, &FilterExpr{Field: key, Operator: FilterLte, Value: v})
+			text = append(text, "≤ "+strconv.FormatFloat(v, 'f', -1, 64))
+		}
+		return exprs, strings.Join(text, " ")
+	}
+	return nil, ""
+}
+
+// FilterOptions returns the options of f for the request of ctx: the
+// related records of a relation filter are loaded on each request.
+func FilterOptions(ctx context.Context, f table.Filter) []table.FilterOption {
+	if rf, ok := f.(interface {
+		OptionsFor(ctx context.Context) []table.FilterOption
+	}); ok {
+		return rf.OptionsFor(ctx)
+	}
+	return f.FilterOptions()
+}
+
+// optionLabel returns the label of the option of value v, else v.
+func optionLabel(options []table.FilterOption, v string) string {
+	for _, o := range options {
+		if o.Value == v {
+			return o.Label
+		}
+	}
+	return v
+}
+
+// filterPanelURL returns the list of query with the panel filters values,
+// from the first page.
+func filterPanelURL(query url.Values, values url.Values) string {
+	next := url.Values{}
+	for key, vs := range query {
+		if !strings.HasPrefix(key, "filter_") && key != "page" && key != "cursor" {
+			next[key] = vs
+		}
+	}
+	for param, vs := range values {
+		next["filter_"+param] = vs
+	}
+	next.Set(filterPanelParam, "1")
+	return "?" + next.Encode()
+}
+
+// listSession returns the session of the panel of ctx, nil without
+// authentication.
+func listSession(ctx context.Context) *scs.SessionManager {
+	p := GetPanelFromContext(ctx)
+	if p == nil || p.AuthManager == nil {
+		return nil
+	}
+	return p.AuthManager.Session()
+}
+
+// FilterPanelFilters returns the filters of the filter panel of the table:
+// those of the panel that the panel supports, the others are rendered in the
+// toolbar (see ResourceFilterPanel).
+func (s TableState) FilterPanelFilters() []table.Filter {
+	return slices.DeleteFunc(slices.Clone(s.TypedFilters), func(f table.Filter) bool { return filterParams(f) == nil })
+}
+
+// ToolbarFilters returns the typed filters of the table the filter panel
+// does not support, e.g. the custom filters.
+func (s TableState) ToolbarFilters() []table.Filter {
+	return slices.DeleteFunc(slices.Clone(s.TypedFilters), func(f table.Filter) bool { return filterParams(f) != nil })
+}
@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/alexedwards/scs/v2"
+	"github.com/bozz33/sublimeadmin/auth"
+	"github.com/bozz33/sublimeadmin/table"
+)
+
+func TestFilterPanelExprs(t *testing.T) {
+	ctx := context.Background()
+	values := url.Values{
+		"status":             {"draft", "published"},
+		"featured":           {"true"},
+		"total_min":          {"10"},
+		"total_max":          {"oops"},
+		"published_at_until": {"2024-01-31"},
+	}
+	statuses := []table.FilterOption{{Value: "draft", Label: "Draft"}, {Value: "published", Label: "Published"}}
+
+	exprs, text := filterPanelExprs(ctx, table.MultiSelect("status").WithOptions(statuses), values)
+	if len(exprs) != 1 || exprs[0].Operator != FilterIn || text != "Draft, Published" {
+		t.Errorf("multiselect = %+v, %q", exprs, text)
+	}
+	exprs, text = filterPanelExprs(ctx, table.Boolean("featured"), values)
+	if len(exprs) != 1 || exprs[0].Value != true || text != "Yes" {
+		t.Errorf("boolean = %+v, %q", exprs, text)
+	}
+	exprs, _ = filterPanelExprs(ctx, table.NumberRange("total"), values)
+	if len(exprs) != 1 || exprs[0].Operator != FilterGte || exprs[0].Value != 10.0 {
+		t.Errorf("number range with an invalid max = %+v", exprs)
+	}
+	exprs, _ = filterPanelExprs(ctx, table.DateRange("published_at"), values)
+	if len(exprs) != 1 || exprs[0].Operator != FilterLt || !exprs[0].Value.(time.Time).Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("date range = %+v", exprs)
+	}
+	authors := func(context.Context) []table.FilterOption { return []table.FilterOption{{Value: "7", Label: "Ann"}} }
+	exprs, text = filterPanelExprs(ctx, table.RelationSelect("author_id").WithOptionsFunc(authors), url.Values{"author_id": {"7"}})
+	if len(exprs) != 1 || exprs[0].Operator != FilterEq || text != "Ann" {
+		t.Errorf("relation = %+v, %q", exprs, text)
+	}
+}
+
+// filteredPosts is a queryable list of blog posts, its table writing the
+// title of each row then the chips of the filter panel.
+type filteredPosts struct {
+	*BaseResource
+	posts []*blogPost
+}
+
+func (r *filteredPosts) ListQuery(ctx context.Context, q ListQuery) ([]any, int, error) {
+	var items []any
+	for _, p := range r.posts {
+		if MatchFilters(p, q.conditions()) {
+			items = append(items, p)
+		}
+	}
+	return items, len(items), nil
+}
+
+func (r *filteredPosts) Table(ctx context.Context) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		state, err := r.BuildTableState(ctx, false, false)
+		if err != nil {
+			return err
+		}
+		for _, row := range state.Rows {
+			fmt.Fprintln(w, row.Record.(*blogPost).Title)
+		}
+		for _, chip := range state.FilterChips {
+			fmt.Fprintln(w, chip.Label)
+		}
+		return nil
+	})
+}
+
+func TestCRUDHandler_FilterPanel(t *testing.T) {
+	res := &filteredPosts{BaseResource: NewBaseResource("blog-posts", "Blog Post", "Blog Posts"), posts: []*blogPost{
+		{ID: 1, Title: "Draft post", Status: "draft"},
+		{ID: 2, Title: "Published post", Status: "published"},
+		{ID: 3, Title: "Another published", Status: "published"},
+	}}
+	res.SetFilters(
+		table.Select("status").WithLabel("Status").WithOptions([]table.FilterOption{{Value: "draft", Label: "Draft"}, {Value: "published", Label: "Published"}}),
+		table.NumberRange("id").WithLabel("ID"),
+	)
+	sm := scs.New()
+	p := NewPanel("filter-panel-test")
+	p.AuthManager = auth.NewManager(sm)
+	h := sm.LoadAndSave(p.injectConfig(NewCRUDHandler(res)))
+
+	var cookies []*http.Cookie
+	list := func(query string) string {
+		req := httptest.NewRequest(http.MethodGet, "/blog-posts"+query, nil)
+		req.Header.Set("HX-Request", "true")
+		req.Header.Set("HX-Target", ListTableID)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d", query, rec.Code)
+		}
+		if c := rec.Result().Cookies(); len(c) > 0 {
+			cookies = c
+		}
+		return rec.Body.String()
+	}
+	shows := func(body string) string {
+		var got []string
+		for _, title := range []string{"Draft post", "Published post", "Another published"} {
+			if strings.Contains(body, title) {
+				got = append(got, title)
+			}
+		}
+		return strings.Join(got, ",")
+	}
+
+	body := list("?filter_status=published&filter_id_min=3&filtered=1")
+	if got := shows(body); got != "Another published" {
+		t.Errorf("filtered list shows %q", got)
+	}
+	if !strings.Contains(body, "Status: Published") || !strings.Contains(body, "ID: ≥ 3") {
+		t.Errorf("the active filters should be listed as chips:\n%s", body)
+	}
+	if got := shows(list("?page=1")); got != "Another published" {
+		t.Errorf("the filters should be remembered, the list shows %q", got)
+	}
+	if got := shows(list("?filtered=1")); got != "Draft post,Published post,Another published" {
+		t.Errorf("clearing the filters should show every post, got %q", got)
+	}
+	if got := shows(list("")); got != "Draft post,Published post,Another published" {
+		t.Errorf("cleared filters should stay cleared, got %q", got)
+	}
+}
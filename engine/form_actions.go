@@ -0,0 +1,326 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/bozz33/sublimeadmin/actions"
+	"github.com/bozz33/sublimeadmin/apperrors"
+	"github.com/bozz33/sublimeadmin/flash"
+	formPkg "github.com/bozz33/sublimeadmin/form"
+	"github.com/bozz33/sublimeadmin/middleware"
+	"github.com/bozz33/sublimeadmin/ui/components"
+)
+
+// ResourceFormActionable is an optional interface for resources with
+// actions of their own on the rows or in the header of the list.
+// BaseResource implements it (see SetRowActions and SetListActions). The
+// actions with a form (see actions.Action.WithForm) are routed by the
+// CRUDHandler:
+//
+//	GET  /{slug}/{id}/actions/{name}   the form of a row action
+//	POST /{slug}/{id}/actions/{name}   validates it and runs the action
+//	GET  /{slug}/actions/{name}        the same for a list action (no record)
+//	POST /{slug}/actions/{name}
+//
+// and the list opens their forms in a modal. The standalone actions of the
+// panel (see Panel.AddActions) are routed the same way.
+type ResourceFormActionable interface {
+	RowActions() []*actions.Action
+	ListActions() []*actions.Action
+}
+
+// ResourceActionGroupable is an optional interface for resources gathering
+// row actions in dropdowns (see actions.Group), to keep the table narrow
+// when there are many. BaseResource implements it (see
+// SetRowActionGroups). The actions with a form are routed like row actions.
+type ResourceActionGroupable interface {
+	RowActionGroups() []*actions.ActionGroup
+}
+
+// ActionDef describes an action of a row or of the header of the list,
+// for the table.
+type ActionDef struct {
+	Name  string
+	Label string
+	Icon  string
+	Color string
+	URL   string // the link, or the form of the action when Form is set
+
+	Form    bool         // URL opens a modal form
+	Method  string       // method the action is submitted with after Confirm ("" = a link)
+	Confirm *BulkConfirm // confirmation modal shown first (nil = none)
+}
+
+// ActionGroupDef is a dropdown of row actions, for the table.
+type ActionGroupDef struct {
+	Label   string
+	Icon    string
+	Color   string
+	Actions []ActionDef
+}
+
+// ActionForm displays the form of an action: alone for the modal
+// (?display=drawer), in the layout otherwise. id is empty for list actions.
+func (h *CRUDHandler) ActionForm(w http.ResponseWriter, r *http.Request, id, name string) {
+	action, item, ok := h.formAction(w, r, id, name)
+	if !ok {
+		return
+	}
+	if !action.HasForm() {
+		apperrors.Handle(w, r, apperrors.NotFound(""))
+		return
+	}
+	h.renderActionForm(w, r, action, id, item, "")
+}
+
+// RunFormAction validates the values and files posted to the form of an
+// action and runs it. Invalid values, or an error of the action, show the form again
+// with a 422 status; on success the modal is closed (204) or the user is
+// redirected, with the success message of the action as a flash message.
+// Submissions over the rate limit of the action get a 429, and those of
+// an idempotent action posted twice a 409. Standalone actions without a
+// form (see Panel.AddActions) are run by actions.Action.ServeHTTP.
+func (h *CRUDHandler) RunFormAction(w http.ResponseWriter, r *http.Request, id, name string) {
+	action, item, ok := h.formAction(w, r, id, name)
+	if !ok {
+		return
+	}
+	if !action.HasForm() {
+		action.ServeHTTP(w, r.WithContext(actions.WithItem(r.Context(), item)))
+		return
+	}
+
+	if err := parseRequestForm(r); err != nil {
+		apperrors.Handle(w, r, apperrors.BadRequest("Bad request"))
+		return
+	}
+	data := make(url.Values, len(r.PostForm))
+	for key, values := range r.PostForm {
+		if key != "_token" && key != "_method" {
+			data[key] = values
+		}
+	}
+
+	var err error
+	if fileErrs := action.Form.ValidateFiles(r, true); fileErrs != nil {
+		err = fileErrs
+	} else {
+		err = action.Submit(r.Context(), item, data)
+	}
+	if err != nil {
+		var rateErr *actions.RateLimitError
+		switch {
+		case errors.As(err, &rateErr):
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(rateErr.RetryAfter.Seconds()))))
+			apperrors.Handle(w, r, apperrors.TooManyRequests("Too many attempts, please try again later."))
+			return
+		case errors.Is(err, actions.ErrDuplicateSubmission):
+			apperrors.Handle(w, r, apperrors.Conflict("This form was already submitted."))
+			return
+		case errors.Is(err, actions.ErrMissingIdempotencyKey):
+			apperrors.Handle(w, r, apperrors.BadRequest("This form is missing its idempotency key."))
+			return
+		}
+		var fieldErrs formPkg.FormErrors
+		if errors.As(err, &fieldErrs) {
+			r = r.WithContext(formPkg.WithFormErrors(r.Context(), fieldErrs))
+			err = nil
+		}
+		message := ""
+		if err != nil {
+			message = action.FailureMessage
+			if message == "" {
+				// Without the "action {name}:" prefix of Execute
+				if cause := errors.Unwrap(err); cause != nil {
+					err = cause
+				}
+				message = err.Error()
+			}
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		h.renderActionForm(w, r, action, id, item, message)
+		return
+	}
+
+	if action.SuccessMessage != "" {
+		flash.Success(r, action.SuccessMessage)
+	}
+	if isDrawer(r) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	redirect := action.ResolveRedirect(item)
+	if redirect == "" {
+		redirect = PanelURL(r.Context(), "/"+h.Resource.Slug())
+	}
+	http.Redirect(w, r, redirect, http.StatusSeeOther)
+}
+
+// formAction returns the form action called name, with its record for a
+// row action, after checking it is authorized. It answers the request
+// itself when it is not found or not allowed.
+func (h *CRUDHandler) formAction(w http.ResponseWriter, r *http.Request, id, name string) (*actions.Action, any, bool) {
+	ctx := r.Context()
+
+	var action *actions.Action
+	if fa, ok := h.Resource.(ResourceFormActionable); ok {
+		list := fa.ListActions()
+		if id != "" {
+			list = fa.RowActions()
+		}
+		action = findFormAction(list, name)
+	}
+	var group *actions.ActionGroup
+	if ag, ok := h.Resource.(ResourceActionGroupable); ok && action == nil && id != "" {
+		for _, g := range ag.RowActionGroups() {
+			if action = findFormAction(g.Items(), name); action != nil {
+				group = g
+				break
+			}
+		}
+	}
+	if action == nil {
+		action = findStandaloneAction(h.Actions, name)
+	}
+	if action == nil {
+		apperrors.Handle(w, r, apperrors.NotFound(""))
+		return nil, nil, false
+	}
+
+	var item any
+	if id != "" {
+		var err error
+		if item, err = h.Resource.Get(ctx, id); err != nil || item == nil {
+			apperrors.Handle(w, r, apperrors.NotFound(""))
+			return nil, nil, false
+		}
+	}
+	if !action.IsAuthorized(ctx, item) || group != nil && !group.IsAuthorized(ctx, item) {
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
+		return nil, nil, false
+	}
+	return action, item, true
+}
+
+// renderActionForm displays the form of an action with the errors of the
+// context and message, the error of the action.
+func (h *CRUDHandler) renderActionForm(w http.ResponseWriter, r *http.Request, action *actions.Action, id string, item any, message string) {
+	title := action.ModalTitle
+	if title == "" {
+		title = action.Label
+	}
+	idempotencyKey := ""
+	if action.IdempotencyRequired {
+		idempotencyKey = actions.NewIdempotencyKey()
+	}
+	content := components.ActionForm(components.ActionFormProps{
+		URL:         PanelURL(r.Context(), formActionURL(h.Resource.Slug(), id, action.Name)),
+		Description: action.ModalDescription,
+		Fields:      action.Form.Schema,
+		SubmitLabel: action.SubmitLabel,
+		Color:       action.Color,
+		Error:       message,
+		CancelURL:   PanelURL(r.Context(), "/"+h.Resource.Slug()),
+		CSRFToken:   middleware.GetCSRFToken(r),
+
+		IdempotencyKey: idempotencyKey,
+	})
+	page := PageList
+	if id != "" {
+		page = PageView
+	}
+	renderForm(w, withBreadcrumbs(r, h.Resource, page, id, item), title, content)
+}
+
+// formActionURL returns the URL of the form of an action; id is empty for
+// list actions.
+func formActionURL(slug, id, name string) string {
+	if id == "" {
+		return "/" + slug + "/actions/" + name
+	}
+	return "/" + slug + "/" + id + "/actions/" + name
+}
+
+// findStandaloneAction returns the standalone action called name, with or
+// without a form, or nil.
+func findStandaloneAction(list []*actions.Action, name string) *actions.Action {
+	for _, a := range list {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// findFormAction returns the action with a form called name, or nil.
+func findFormAction(list []*actions.Action, name string) *actions.Action {
+	for _, a := range list {
+		if a.Name == name && a.HasForm() {
+			return a
+		}
+	}
+	return nil
+}
+
+// actionDefs lists the actions authorized for the current user and item
+// (nil for list actions), for the table.
+func actionDefs(ctx context.Context, slug, id string, item any, list []*actions.Action) []ActionDef {
+	var defs []ActionDef
+	for _, action := range list {
+		if !action.IsAuthorized(ctx, item) {
+			continue
+		}
+		def := ActionDef{
+			Name:  action.Name,
+			Label: action.Label,
+			Icon:  action.Icon,
+			Color: action.Color,
+		}
+		switch {
+		case action.HasForm():
+			def.URL = PanelURL(ctx, formActionURL(slug, id, action.Name))
+			def.Form = true
+		case action.Type == actions.Button || action.RequiresConfirmation:
+			def.URL = action.URL(item)
+			def.Method = action.Method
+			if def.Method == "" || def.Method == "GET" {
+				def.Method = "POST"
+			}
+			if action.RequiresConfirmation {
+				def.Confirm = &BulkConfirm{
+					Title:        action.ModalTitle,
+					Description:  action.ModalDescription,
+					ConfirmLabel: action.ConfirmLabel,
+				}
+			}
+		default:
+			def.URL = action.URL(item)
+		}
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+// actionGroupDefs lists the dropdowns of row actions with at least one
+// action authorized for the current user and item, for the table.
+func actionGroupDefs(ctx context.Context, slug, id string, item any, groups []*actions.ActionGroup) []ActionGroupDef {
+	var defs []ActionGroupDef
+	for _, g := range groups {
+		items := actionDefs(ctx, slug, id, item, g.VisibleItems(ctx, item))
+		if len(items) == 0 {
+			continue
+		}
+		defs = append(defs, ActionGroupDef{
+			Label:   g.Label,
+			Icon:    g.Icon,
+			Color:   g.Color,
+			Actions: items,
+		})
+	}
+	return defs
+}
@@ -0,0 +1,298 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/actions"
+	"github.com/bozz33/sublimeadmin/form"
+)
+
+// statusResource has a "change-status" row action asking for a status and
+// a note, and an "invite" list action asking for an email address.
+type statusResource struct {
+	*mockResource
+	changed map[string]url.Values
+	invited []string
+}
+
+func newStatusResource() *statusResource {
+	r := &statusResource{mockResource: newMockResource("posts"), changed: map[string]url.Values{}}
+	r.SetRowActions(
+		actions.New("change-status").SetLabel("Change status").
+			WithForm(form.Schema(
+				form.Select("status").Options(map[string]string{"draft": "Draft", "published": "Published"}).Required(),
+				form.Textarea("note"),
+			)).
+			WithSuccessMessage("Status changed.").
+			Handle(func(_ context.Context, item any, data url.Values) error {
+				if data.Get("status") == "published" && item.(*testItem).Name == "post-9" {
+					return errors.New("post 9 is locked")
+				}
+				r.changed[item.(*testItem).Name] = data
+				return nil
+			}),
+		actions.New("archive").Authorize(func(context.Context, any) bool { return false }).
+			WithForm(form.Schema(form.Text("reason"))),
+		actions.EditAction("/posts"),
+	)
+	r.SetListActions(
+		actions.New("invite").SetLabel("Invite").
+			WithForm(form.Schema(form.Email("email").Required())).
+			Handle(func(_ context.Context, item any, data url.Values) error {
+				if item != nil {
+					return errors.New("list actions have no record")
+				}
+				r.invited = append(r.invited, data.Get("email"))
+				return nil
+			}),
+	)
+	return r
+}
+
+func (r *statusResource) Get(_ context.Context, id string) (any, error) {
+	return &testItem{Name: "post-" + id}, nil
+}
+
+func TestCRUDHandler_ActionForm(t *testing.T) {
+	h := newHandler(newStatusResource())
+
+	rw := serveWith(h, http.MethodGet, "/posts/3/actions/change-status?display=drawer", nil)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rw.Code)
+	}
+	body := rw.Body.String()
+	for _, want := range []string{`data-title="Change status"`, `action="/posts/3/actions/change-status"`, `name="status"`, `name="note"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected the form to contain %s", want)
+		}
+	}
+
+	for path, want := range map[string]int{
+		"/posts/3/actions/unknown": http.StatusNotFound,
+		"/posts/3/actions/edit":    http.StatusNotFound, // no form
+		"/posts/3/actions/archive": http.StatusForbidden,
+		"/posts/actions/invite":    http.StatusOK,
+		"/posts/actions/archive":   http.StatusNotFound, // a row action
+	} {
+		if rw := serveWith(h, http.MethodGet, path, nil); rw.Code != want {
+			t.Errorf("%s: expected %d, got %d", path, want, rw.Code)
+		}
+	}
+}
+
+func TestCRUDHandler_RunFormAction(t *testing.T) {
+	res := newStatusResource()
+	h := newHandler(res)
+
+	rw := serveWith(h, http.MethodPost, "/posts/3/actions/change-status", url.Values{"status": {"published"}, "note": {"Ready"}, "_token": {"x"}})
+	if rw.Code != http.StatusSeeOther || rw.Header().Get("Location") != "/posts" {
+		t.Fatalf("expected a redirect to the list, got %d %q", rw.Code, rw.Header().Get("Location"))
+	}
+	data := res.changed["post-3"]
+	if data.Get("status") != "published" || data.Get("note") != "Ready" {
+		t.Errorf("expected the values to reach the handler, got %v", data)
+	}
+	if data.Has("_token") {
+		t.Error("expected the CSRF token not to reach the handler")
+	}
+
+	// Invalid values show the form again with the errors
+	rw = serveWith(h, http.MethodPost, "/posts/4/actions/change-status?display=drawer", url.Values{"note": {"Ready"}})
+	if rw.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rw.Code)
+	}
+	if _, ok := res.changed["post-4"]; ok {
+		t.Error("expected the handler not to run with invalid values")
+	}
+	if !strings.Contains(rw.Body.String(), `name="status"`) {
+		t.Error("expected the form in the response")
+	}
+
+	// Errors of the action are shown above the form
+	rw = serveWith(h, http.MethodPost, "/posts/9/actions/change-status?display=drawer", url.Values{"status": {"published"}})
+	if rw.Code != http.StatusUnprocessableEntity || !strings.Contains(rw.Body.String(), "post 9 is locked") {
+		t.Errorf("expected the error of the action, got %d", rw.Code)
+	}
+
+	// A modal is closed without a redirect
+	rw = serveWith(h, http.MethodPost, "/posts/5/actions/change-status?display=drawer", url.Values{"status": {"draft"}})
+	if rw.Code != http.StatusNoContent {
+		t.Errorf("expected 204 for the modal, got %d", rw.Code)
+	}
+
+	// List actions have no record
+	rw = serveWith(h, http.MethodPost, "/posts/actions/invite", url.Values{"email": {"jane@example.com"}})
+	if rw.Code != http.StatusSeeOther || len(res.invited) != 1 || res.invited[0] != "jane@example.com" {
+		t.Errorf("expected the list action to run, got %d %v", rw.Code, res.invited)
+	}
+}
+
+func TestBaseResource_FormActions_in_table(t *testing.T) {
+	res := newStatusResource()
+	state, err := res.BuildTableState(context.Background(), true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.ListActions) != 1 || state.ListActions[0].URL != "/posts/actions/invite" {
+		t.Errorf("expected the invite list action, got %+v", state.ListActions)
+	}
+
+	// archive is not authorized; edit is a plain link
+	defs := actionDefs(context.Background(), "posts", "3", &testItem{Name: "post-3"}, res.RowActions())
+	if len(defs) != 2 {
+		t.Fatalf("expected 2 row actions, got %+v", defs)
+	}
+	if !defs[0].Form || defs[0].Label != "Change status" || defs[0].URL != "/posts/3/actions/change-status" {
+		t.Errorf("expected the change-status form action, got %+v", defs[0])
+	}
+	if defs[1].Form || defs[1].Method != "" || defs[1].Name != "edit" {
+		t.Errorf("expected the edit link, got %+v", defs[1])
+	}
+}
+
+func TestCRUDHandler_RunFormAction_in_group(t *testing.T) {
+	res := newStatusResource()
+	var transferred []string
+	locked := true
+	res.SetRowActionGroups(actions.Group("More",
+		actions.New("transfer").
+			WithForm(form.Schema(form.Text("owner").Required())).
+			Handle(func(_ context.Context, item any, data url.Values) error {
+				transferred = append(transferred, data.Get("owner"))
+				return nil
+			}),
+	).Authorize(func(context.Context, any) bool { return !locked }))
+	h := newHandler(res)
+
+	if rw := serveWith(h, http.MethodGet, "/posts/3/actions/transfer", nil); rw.Code != http.StatusForbidden {
+		t.Errorf("expected 403 while the group is not authorized, got %d", rw.Code)
+	}
+	locked = false
+	rw := serveWith(h, http.MethodPost, "/posts/3/actions/transfer", url.Values{"owner": {"jane"}})
+	if rw.Code != http.StatusSeeOther || len(transferred) != 1 || transferred[0] != "jane" {
+		t.Errorf("expected the action of the group to run, got %d %v", rw.Code, transferred)
+	}
+}
+
+func TestActionGroupDefs(t *testing.T) {
+	ctx := context.Background()
+	deny := func(context.Context, any) bool { return false }
+	groups := []*actions.ActionGroup{
+		actions.Group("More",
+			actions.RestoreAction("/posts"),
+			actions.New("purge").Authorize(deny),
+		).SetIcon("more_horiz"),
+		actions.Group("Hidden", actions.New("a")).Authorize(deny),
+		actions.Group("Empty", actions.New("b").Authorize(deny)),
+	}
+
+	defs := actionGroupDefs(ctx, "posts", "3", 3, groups)
+	if len(defs) != 1 || defs[0].Label != "More" || defs[0].Icon != "more_horiz" {
+		t.Fatalf("expected only the More group, got %+v", defs)
+	}
+	restore := defs[0].Actions
+	if len(restore) != 1 || restore[0].URL != "/posts/3/restore" || restore[0].Method != "POST" || restore[0].Confirm == nil {
+		t.Errorf("expected the restore action with its confirmation, got %+v", restore)
+	}
+}
+
+func TestCRUDHandler_RunFormAction_limited(t *testing.T) {
+	res := newStatusResource()
+	var sent []string
+	res.SetRowActions(actions.New("send-invoice").
+		WithForm(form.Schema(form.Email("to").Required())).
+		RateLimit(3, time.Hour).
+		Idempotent().
+		Handle(func(_ context.Context, item any, data url.Values) error {
+			sent = append(sent, data.Get("to"))
+			return nil
+		}))
+	crud := newHandler(res)
+	limiter := defaultActionLimiter()
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		crud.ServeHTTP(w, r.WithContext(actions.WithLimiter(r.Context(), limiter)))
+	})
+
+	rw := serveWith(h, http.MethodGet, "/posts/3/actions/send-invoice", nil)
+	if !strings.Contains(rw.Body.String(), `name="`+actions.IdempotencyKeyField+`"`) {
+		t.Error("expected the form to carry an idempotency key")
+	}
+
+	values := url.Values{"to": {"jane@example.com"}, actions.IdempotencyKeyField: {"abc"}}
+	if rw := serveWith(h, http.MethodPost, "/posts/3/actions/send-invoice", values); rw.Code != http.StatusSeeOther {
+		t.Fatalf("expected a redirect, got %d", rw.Code)
+	}
+	if rw := serveWith(h, http.MethodPost, "/posts/3/actions/send-invoice", values); rw.Code != http.StatusConflict {
+		t.Errorf("expected 409 for the same key, got %d", rw.Code)
+	}
+	values.Del(actions.IdempotencyKeyField)
+	if rw := serveWith(h, http.MethodPost, "/posts/3/actions/send-invoice", values); rw.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without key, got %d", rw.Code)
+	}
+	values.Set(actions.IdempotencyKeyField, "def")
+	rw = serveWith(h, http.MethodPost, "/posts/3/actions/send-invoice", values)
+	if rw.Code != http.StatusTooManyRequests || rw.Header().Get("Retry-After") == "" {
+		t.Errorf("expected 429 with Retry-After over the rate limit, got %d", rw.Code)
+	}
+	if len(sent) != 1 {
+		t.Errorf("expected the action to run once, got %v", sent)
+	}
+}
+
+func TestPanel_AddActions(t *testing.T) {
+	var sent []string
+	res := newStatusResource()
+	h := NewPanel("standalone-actions").
+		AddResources(res).
+		AddActions(actions.New("send-invoice").
+			ForResource("posts").
+			Authorize(func(_ context.Context, item any) bool { return item == nil || item.(*testItem).Name != "post-2" }).
+			Handle(func(_ context.Context, item any, _ url.Values) error {
+				sent = append(sent, item.(*testItem).Name)
+				return nil
+			})).
+		Router()
+
+	rw := serveWith(h, http.MethodPost, "/posts/3/actions/send-invoice", url.Values{})
+	if rw.Code != http.StatusSeeOther || rw.Header().Get("Location") != "/posts" {
+		t.Fatalf("expected a redirect to the list, got %d %q", rw.Code, rw.Header().Get("Location"))
+	}
+	if len(sent) != 1 || sent[0] != "post-3" {
+		t.Errorf("expected the action to run on post-3, got %v", sent)
+	}
+	if rw := serveWith(h, http.MethodPost, "/posts/2/actions/send-invoice", url.Values{}); rw.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for an unauthorized record, got %d", rw.Code)
+	}
+	if rw := serveWith(h, http.MethodGet, "/posts/3/actions/send-invoice", nil); rw.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for the form of an action without one, got %d", rw.Code)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Router to panic for an action of an unknown resource")
+		}
+	}()
+	NewPanel("unknown-resource").AddActions(actions.New("send-invoice").ForResource("orders")).Router()
+}
+
+func TestPanel_AddActions_panelPath(t *testing.T) {
+	a := actions.New("send-invoice").ForResource("posts").
+		Handle(func(context.Context, any, url.Values) error { return nil })
+	p := NewPanel("standalone-actions-path").AddResources(newStatusResource()).AddActions(a)
+	p.Path = "/admin"
+	h := p.Router()
+
+	if got := a.URL(nil); got != "/admin/posts/actions/send-invoice" {
+		t.Errorf("expected the URL in the panel, got %q", got)
+	}
+	rw := serveWith(h, http.MethodPost, "/admin/posts/3/actions/send-invoice", url.Values{})
+	if rw.Code != http.StatusSeeOther || rw.Header().Get("Location") != "/admin/posts" {
+		t.Errorf("expected a redirect to the list in the panel, got %d %q", rw.Code, rw.Header().Get("Location"))
+	}
+}
@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/apperrors"
+	"github.com/bozz33/sublimeadmin/form"
+	"github.com/bozz33/sublimeadmin/middleware"
+)
+
+// formThrottleStore backs a form.Throttle with a rate limit store of the
+// middleware package.
+type formThrottleStore struct {
+	rates middleware.RateLimitStore
+}
+
+// NewFormThrottleStore returns the store of a form.Throttle counting the
+// submissions in rates. Use the Redis store to share the limits across
+// replicas:
+//
+//	store := engine.NewFormThrottleStore(middleware.NewRedisRateLimitStore(rdb, "forms:"))
+func NewFormThrottleStore(rates middleware.RateLimitStore) form.ThrottleStore {
+	return &formThrottleStore{rates: rates}
+}
+
+// Allow implements form.ThrottleStore.
+func (s *formThrottleStore) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	result, err := middleware.AllowPerWindow(ctx, s.rates, key, limit, window)
+	if err != nil {
+		return false, 0, err
+	}
+	return result.Allowed, result.RetryAfter, nil
+}
+
+// WithAntiSpam protects the public forms of the panel, registration and
+// password reset, with the honeypot, minimum fill time and throttle of f:
+//
+//	panel.WithAntiSpam(form.New().
+//		WithHoneypot("").
+//		WithMinFillTime(3 * time.Second).
+//		WithThrottle(form.Throttle{
+//			Store:  engine.NewFormThrottleStore(middleware.NewMemoryRateLimitStore(0)),
+//			Limit:  5,
+//			Window: time.Hour,
+//			Key:    middleware.KeyByIP,
+//		}))
+//
+// Without a Name, the throttle counts the submissions of each form apart.
+func (p *Panel) WithAntiSpam(f *form.Form) *Panel {
+	p.antiSpam = f
+	return p
+}
+
+// guardPublicForm renders the anti-spam fields of the panel in the pages of
+// next and checks the submissions posted to it (see WithAntiSpam).
+func (p *Panel) guardPublicForm(next http.Handler) http.Handler {
+	if p.antiSpam == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(form.WithAntiSpam(r.Context(), p.antiSpam))
+		if r.Method == http.MethodPost {
+			if err := p.antiSpam.CheckSubmission(r); err != nil {
+				var throttled *form.ThrottleError
+				switch {
+				case errors.As(err, &throttled):
+					w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+					apperrors.Handle(w, r, apperrors.TooManyRequests("Too many attempts, please try again later."))
+				case errors.Is(err, form.ErrSpam):
+					apperrors.Handle(w, r, apperrors.BadRequest("The form could not be submitted. Please try again."))
+				default:
+					apperrors.Handle(w, r, apperrors.Internal(err, "Form check failed"))
+				}
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
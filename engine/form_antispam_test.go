@@ -0,0 +1,51 @@
+package engine
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/form"
+	"github.com/bozz33/sublimeadmin/middleware"
+)
+
+func TestPanel_WithAntiSpam(t *testing.T) {
+	rates := middleware.NewMemoryRateLimitStore(0)
+	defer rates.Stop()
+	p := &Panel{}
+	p.WithAntiSpam(form.New().WithHoneypot("").WithThrottle(form.Throttle{
+		Store:  NewFormThrottleStore(rates),
+		Limit:  2,
+		Window: time.Hour,
+	}))
+	h := p.guardPublicForm(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = form.ContextAntiSpamFields().Render(r.Context(), w)
+	}))
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/register", nil))
+	if !strings.Contains(rec.Body.String(), `name="website"`) {
+		t.Errorf("the page should render the honeypot, got %s", rec.Body)
+	}
+
+	if rec := post("email=a%40b.c&website="); rec.Code != http.StatusOK {
+		t.Errorf("a human submission = %d, want 200", rec.Code)
+	}
+	if rec := post("email=a%40b.c&website=spam"); rec.Code != http.StatusBadRequest {
+		t.Errorf("a filled honeypot = %d, want 400", rec.Code)
+	}
+	rec = post("email=a%40b.c")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("a third submission = %d (Retry-After %q), want 429", rec.Code, rec.Header().Get("Retry-After"))
+	}
+}
@@ -0,0 +1,216 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/actions"
+	"github.com/bozz33/sublimeadmin/form"
+	"github.com/bozz33/sublimeadmin/jobs"
+	"github.com/bozz33/sublimeadmin/table"
+	"github.com/bozz33/sublimeadmin/ui/components"
+)
+
+// JobsResource is the built-in monitor of the jobs of a queue (see
+// NewJobsResource).
+type JobsResource struct {
+	*BaseResource
+	queue  *jobs.Queue
+	cancel *actions.Action
+}
+
+// NewJobsResource creates the read-only resource listing the jobs of
+// queue, filtered by tenant and status (?filter_tenant=acme), with an
+// action cancelling the pending ones:
+//
+//	central.AddResources(engine.NewJobsResource(queue))
+//
+// Served by a tenant panel, it lists only the jobs of the current tenant.
+func NewJobsResource(queue *jobs.Queue) *JobsResource {
+	r := &JobsResource{
+		BaseResource: NewBaseResource("jobs", "Job", "Jobs"),
+		queue:        queue,
+	}
+	r.SetIcon("pending_actions")
+	r.cancel = actions.New("cancel").
+		SetLabel("Cancel").
+		SetIcon("cancel").
+		SetColor(actions.ColorDanger).
+		WithForm(form.Schema()).
+		WithSubmitLabel("Cancel job").
+		WithSuccessMessage("Job cancelled.").
+		Authorize(func(ctx context.Context, item any) bool {
+			job, ok := item.(*jobs.Job)
+			return ok && job.Status == jobs.StatusPending
+		}).
+		Handle(func(ctx context.Context, item any, _ url.Values) error {
+			return queue.Cancel(item.(*jobs.Job).ID)
+		})
+	r.cancel.ModalTitle = "Cancel this job?"
+	r.SetRowActions(r.cancel)
+	return r
+}
+
+func (r *JobsResource) CanCreate(ctx context.Context) bool { return false }
+func (r *JobsResource) CanUpdate(ctx context.Context) bool { return false }
+func (r *JobsResource) CanDelete(ctx context.Context) bool { return false }
+
+// List returns the jobs visible from ctx, most recent first.
+func (r *JobsResource) List(ctx context.Context) ([]any, error) {
+	list, err := r.jobs(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]any, len(list))
+	for i, job := range list {
+		items[i] = job
+	}
+	return items, nil
+}
+
+func (r *JobsResource) Get(ctx context.Context, id string) (any, error) {
+	job, ok := r.queue.Get(id)
+	if !ok || !r.visible(ctx, job) {
+		return nil, nil
+	}
+	return job, nil
+}
+
+// ListFiltered returns the jobs matching the "tenant" and "status" filters.
+func (r *JobsResource) ListFiltered(ctx context.Context, filters map[string]string) ([]any, error) {
+	list, err := r.jobs(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]any, len(list))
+	for i, job := range list {
+		items[i] = job
+	}
+	return items, nil
+}
+
+// jobs returns the jobs visible from ctx matching filters, most recent
+// first, including the stored jobs of previous runs (see jobs.Queue.List).
+func (r *JobsResource) jobs(ctx context.Context, filters map[string]string) ([]*jobs.Job, error) {
+	filter := jobs.Filter{Status: jobs.Status(filters["status"]), TenantID: filters["tenant"]}
+	if t := TenantFromContext(ctx); t != nil && filter.TenantID == "" {
+		filter.TenantID = t.ID
+	}
+	list, err := r.queue.List(filter)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(list, func(job *jobs.Job) bool { return !r.visible(ctx, job) }), nil
+}
+
+// visible reports whether job can be seen from ctx: by its tenant only in
+// a tenant panel.
+func (r *JobsResource) visible(ctx context.Context, job *jobs.Job) bool {
+	t := TenantFromContext(ctx)
+	return t == nil || job.TenantID == t.ID
+}
+
+// Table lists the jobs, filtered and searched by name with the ListQuery
+// of the context.
+func (r *JobsResource) Table(ctx context.Context) templ.Component {
+	var filters map[string]string
+	lq := GetListQuery(ctx)
+	if lq != nil {
+		filters = lq.Filters
+	}
+	list, err := r.jobs(ctx, filters)
+	if err != nil {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, werr := io.WriteString(w, "<p class=\"text-red-500\">Error loading table: "+templ.EscapeString(err.Error())+"</p>")
+			return werr
+		})
+	}
+	if lq != nil && lq.Search != "" {
+		query := strings.ToLower(strings.TrimSpace(lq.Search))
+		list = slices.DeleteFunc(list, func(job *jobs.Job) bool {
+			return !strings.Contains(strings.ToLower(job.Name), query)
+		})
+	}
+
+	data := make([]any, len(list))
+	for i, job := range list {
+		data[i] = job
+	}
+	base := PanelURL(ctx, "/"+r.Slug())
+	t := table.New(data).WithColumns(r.columns(ctx)...).WithFilters(r.filters(ctx)...)
+	t.BaseURL = base
+	t.Pagination = false
+	cancel := r.cancel
+	t.Actions = []*actions.Action{actions.New(cancel.Name).
+		SetLabel(cancel.Label).
+		SetIcon(cancel.Icon).
+		SetColor(cancel.Color).
+		SetUrl(func(item any) string {
+			if !cancel.IsAuthorized(ctx, item) {
+				return "#"
+			}
+			return base + "/" + item.(*jobs.Job).ID + "/actions/" + cancel.Name
+		})}
+	return components.Table(ctx, t, data)
+}
+
+// filters returns the filters of the table: the tenants having jobs, out
+// of a tenant panel, and the statuses.
+func (r *JobsResource) filters(ctx context.Context) []table.Filter {
+	var list []table.Filter
+	if TenantFromContext(ctx) == nil {
+		var tenants []string
+		for _, job := range r.queue.GetAll() {
+			if job.TenantID != "" && !slices.Contains(tenants, job.TenantID) {
+				tenants = append(tenants, job.TenantID)
+			}
+		}
+		if len(tenants) > 0 {
+			slices.Sort(tenants)
+			options := make([]table.FilterOption, len(tenants))
+			for i, id := range tenants {
+				options[i] = table.FilterOption{Value: id, Label: id}
+			}
+			list = append(list, table.Select("tenant").WithLabel("Tenant").WithOptions(options))
+		}
+	}
+	statuses := []jobs.Status{jobs.StatusPending, jobs.StatusRunning, jobs.StatusCompleted, jobs.StatusFailed, jobs.StatusCancelled}
+	options := make([]table.FilterOption, len(statuses))
+	for i, s := range statuses {
+		options[i] = table.FilterOption{Value: string(s), Label: humanize(string(s))}
+	}
+	return append(list, table.Select("status").WithLabel("Status").WithOptions(options))
+}
+
+// columns returns the columns of the table, the tenant out of a tenant
+// panel.
+func (r *JobsResource) columns(ctx context.Context) []table.Column {
+	cols := []table.Column{table.Text("Name").WithLabel("Name")}
+	if TenantFromContext(ctx) == nil {
+		cols = append(cols, table.Text("TenantID").WithLabel("Tenant"))
+	}
+	return append(cols,
+		table.Text("Queue").WithLabel("Queue"),
+		table.Badge("Status").WithLabel("Status").
+			Using(func(item any) string { return string(item.(*jobs.Job).Status) }).
+			Colors(map[string]string{
+				string(jobs.StatusRunning):   "info",
+				string(jobs.StatusCompleted): "success",
+				string(jobs.StatusFailed):    "danger",
+			}),
+		table.Text("Progress").WithLabel("Progress").
+			Using(func(item any) string { return strconv.Itoa(item.(*jobs.Job).Progress) + "%" }),
+		table.Text("Error").WithLabel("Error").Using(func(item any) string {
+			if err := item.(*jobs.Job).Error; err != nil {
+				return err.Error()
+			}
+			return ""
+		}),
+		table.DateCol("CreatedAt").WithLabel("Created"),
+	)
+}
@@ -0,0 +1,177 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// listQueryConfig is the pagination config of CRUDHandler lists.
+func listQueryConfig() PaginationConfig {
+	cfg := defaultPaginationConfig()
+	cfg.DefaultSize = 20
+	cfg.MaxSize = 200
+	cfg.OrderParams = []string{"dir", "order"}
+	return cfg
+}
+
+// ParseListQuery extracts the ListQuery of a list request: the params read
+// by ParsePaginationParams, ?dir= ordering the first sort (overriding its
+// "-" prefix), plus the filter_* params.
+func ParseListQuery(r *http.Request) ListQuery {
+	return parseListQuery(r, listQueryConfig())
+}
+
+// withSortDir returns r with the direction of ?dir= applied to the first
+// field of its sort param, so ?sort=-title&dir=asc sorts by title
+// ascending, and a cursor is kept only for that sort.
+func withSortDir(r *http.Request, cfg PaginationConfig) *http.Request {
+	query := r.URL.Query()
+	dir := query.Get("dir")
+	if dir == "" {
+		return r
+	}
+	for _, name := range cfg.SortParams {
+		sorts := ParseSortFields(query.Get(name))
+		if len(sorts) == 0 {
+			continue
+		}
+		sorts[0].Desc = strings.EqualFold(dir, "desc")
+		query.Set(name, formatSortFields(sorts))
+		r = r.Clone(r.Context())
+		r.URL.RawQuery = query.Encode()
+		return r
+	}
+	return r
+}
+
+// parseListQuery is ParseListQuery with the pagination config cfg.
+func parseListQuery(r *http.Request, cfg PaginationConfig) ListQuery {
+	r = withSortDir(r, cfg)
+	q := ParsePaginationParamsWithConfig(r, cfg).ListQuery()
+	for key, vals := range r.URL.Query() {
+		if strings.HasPrefix(key, "filter_") && len(vals) > 0 && vals[0] != "" {
+			q.Filters[strings.TrimPrefix(key, "filter_")] = vals[0]
+		}
+	}
+	return q
+}
+
+// conditions returns the Scope then the Where filters of the query, for the
+// items listed in memory.
+func (q ListQuery) conditions() []*FilterExpr {
+	return append(slices.Clone(q.Scope), q.Where...)
+}
+
+// Offset returns the SQL OFFSET value for the current page.
+func (q ListQuery) Offset() int {
+	return q.PaginationParams().Offset()
+}
+
+// PaginationParams converts the query for PaginatedResource. Its Filters
+// are the Scope, then Where, then the filter_* params as equality
+// expressions sorted by field.
+func (q ListQuery) PaginationParams() PaginationParams {
+	p := PaginationParams{
+		Mode:    PaginationOffset,
+		Page:    q.Page,
+		PerPage: q.PerPage,
+		Cursor:  q.Cursor,
+		Search:  q.Search,
+		Sorts:   slices.Clone(q.Sorts),
+		Order:   q.SortDir,
+	}
+	if q.Cursor != nil {
+		p.Mode = PaginationCursor
+	}
+	if len(p.Sorts) == 0 && q.SortKey != "" {
+		p.Sorts = []SortField{{Field: q.SortKey, Desc: q.SortDir == "desc"}}
+	}
+	if p.Order != "desc" {
+		p.Order = "asc"
+	}
+	p.Sort = formatSortFields(p.Sorts)
+
+	p.Filters = append(slices.Clone(q.Scope), q.Where...)
+	keys := make([]string, 0, len(q.Filters))
+	for key := range q.Filters {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		p.Filters = append(p.Filters, &FilterExpr{Field: key, Operator: FilterEq, Value: q.Filters[key]})
+	}
+	return p
+}
+
+// ListQuery converts the params for ResourceQueryable: Filters become
+// Where and the first sort SortKey and SortDir.
+func (p PaginationParams) ListQuery() ListQuery {
+	q := ListQuery{
+		Filters: make(map[string]string),
+		Where:   p.Filters,
+		Search:  p.Search,
+		Sorts:   slices.Clone(p.Sorts),
+		Page:    p.Page,
+		PerPage: p.PerPage,
+		Cursor:  p.Cursor,
+		SortDir: "asc",
+	}
+	if len(q.Sorts) > 0 {
+		q.SortKey = q.Sorts[0].Field
+		// The legacy order applies to a single unprefixed sort.
+		if len(q.Sorts) == 1 && !strings.HasPrefix(p.Sort, "-") && strings.EqualFold(p.Order, "desc") {
+			q.Sorts[0].Desc = true
+		}
+		if q.Sorts[0].Desc {
+			q.SortDir = "desc"
+		}
+	}
+	return q
+}
+
+// paginatedLister is the list method of PaginatedResource.
+type paginatedLister interface {
+	ListPaginated(ctx context.Context, params PaginationParams) (*PageResult, error)
+}
+
+// queryItems lists a page of res with q through ResourceQueryable or,
+// adapted, PaginatedResource. ok is false when res implements neither.
+func queryItems(ctx context.Context, res any, q ListQuery) (page *PageResult, ok bool, err error) {
+	if rq, isQueryable := res.(ResourceQueryable); isQueryable {
+		items, total, err := rq.ListQuery(ctx, q)
+		return NewPage(items, int64(total), q.Page, q.PerPage), true, err
+	}
+	if pl, isPaginated := res.(paginatedLister); isPaginated {
+		page, err = pl.ListPaginated(ctx, q.PaginationParams())
+		if page == nil {
+			page = NewPage(nil, 0, q.Page, q.PerPage)
+		}
+		return page, true, err
+	}
+	return nil, false, nil
+}
+
+// queryPage lists a page of res with params through PaginatedResource or,
+// adapted, ResourceQueryable, the scope first. ok is false when res
+// implements neither.
+func queryPage(ctx context.Context, res any, params PaginationParams, scope []*FilterExpr) (page *PageResult, ok bool, err error) {
+	if pl, isPaginated := res.(paginatedLister); isPaginated {
+		if len(scope) > 0 {
+			params.Filters = append(slices.Clone(scope), params.Filters...)
+		}
+		page, err = pl.ListPaginated(ctx, params)
+		return page, true, err
+	}
+	if rq, isQueryable := res.(ResourceQueryable); isQueryable {
+		q := params.ListQuery()
+		q.Scope = scope
+		items, total, err := rq.ListQuery(ctx, q)
+		if err != nil {
+			return nil, true, err
+		}
+		return NewPage(items, int64(total), params.Page, params.PerPage), true, nil
+	}
+	return nil, false, nil
+}
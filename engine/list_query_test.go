@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func TestParseListQuery(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, `/posts?sort=-title,id&per_page=500&q=go&filter_status=draft&filter_empty=&filters=["views","gt",10]`, nil)
+	q := ParseListQuery(req)
+
+	if q.SortKey != "title" || q.SortDir != "desc" || len(q.Sorts) != 2 || q.Sorts[1].Field != "id" {
+		t.Errorf("unexpected sort %q %q %+v", q.SortKey, q.SortDir, q.Sorts)
+	}
+	if q.Page != 1 || q.PerPage != 200 {
+		t.Errorf("expected page 1 of 200 (the maximum), got %d of %d", q.Page, q.PerPage)
+	}
+	if q.Search != "go" {
+		t.Errorf("Search = %q", q.Search)
+	}
+	if len(q.Filters) != 1 || q.Filters["status"] != "draft" {
+		t.Errorf("Filters = %v", q.Filters)
+	}
+	if len(q.Where) != 1 || q.Where[0].Field != "views" || q.Where[0].Operator != FilterGt {
+		t.Errorf("Where = %+v", q.Where)
+	}
+
+	q = ParseListQuery(httptest.NewRequest(http.MethodGet, "/posts?sort=-title&dir=asc", nil))
+	if q.SortDir != "asc" || q.Sorts[0].Desc {
+		t.Errorf("dir should override the sort prefix, got %q %+v", q.SortDir, q.Sorts)
+	}
+	q = ParseListQuery(httptest.NewRequest(http.MethodGet, "/posts?sort=title&dir=desc", nil))
+	if q.SortDir != "desc" || !q.Sorts[0].Desc {
+		t.Errorf("dir should order the sort, got %q %+v", q.SortDir, q.Sorts)
+	}
+	if q = ParseListQuery(httptest.NewRequest(http.MethodGet, "/posts", nil)); q.PerPage != 20 || q.SortDir != "asc" || q.Filters == nil {
+		t.Errorf("unexpected defaults %+v", q)
+	}
+}
+
+func TestListQuery_PaginationParams(t *testing.T) {
+	q := ListQuery{
+		Filters: map[string]string{"status": "draft", "author": "jane"},
+		Where:   []*FilterExpr{{Field: "views", Operator: FilterGt, Value: 10}},
+		Scope:   []*FilterExpr{{Field: "team_id", Operator: FilterEq, Value: 7}},
+		Search:  "go",
+		SortKey: "title",
+		SortDir: "desc",
+		Page:    3,
+		PerPage: 10,
+	}
+	p := q.PaginationParams()
+
+	if p.Page != 3 || p.PerPage != 10 || p.Offset() != 20 || q.Offset() != 20 || p.Search != "go" {
+		t.Errorf("unexpected params %+v", p)
+	}
+	if p.Sort != "-title" || p.Order != "desc" || len(p.Sorts) != 1 || !p.Sorts[0].Desc {
+		t.Errorf("unexpected sort %q %q %+v", p.Sort, p.Order, p.Sorts)
+	}
+	var fields []string
+	for _, f := range p.Filters {
+		fields = append(fields, f.Field)
+	}
+	if got := strings.Join(fields, ","); got != "team_id,views,author,status" {
+		t.Errorf("expected the scope, the expressions then the filters, got %s", got)
+	}
+
+	back := p.ListQuery()
+	if back.SortKey != "title" || back.SortDir != "desc" || back.Page != 3 || back.Search != "go" || len(back.Where) != 4 {
+		t.Errorf("unexpected round trip %+v", back)
+	}
+}
+
+func TestPaginationParams_ListQuery_legacyOrder(t *testing.T) {
+	q := PaginationParams{Sort: "name", Sorts: ParseSortFields("name"), Order: "desc"}.ListQuery()
+	if q.SortKey != "name" || q.SortDir != "desc" {
+		t.Errorf("expected the legacy order to apply, got %q %q", q.SortKey, q.SortDir)
+	}
+}
+
+// pagedResource lists its records with ListPaginated only.
+type pagedResource struct {
+	*mockResource
+	params PaginationParams
+}
+
+func (p *pagedResource) ListPaginated(ctx context.Context, params PaginationParams) (*PageResult, error) {
+	p.params = params
+	return NewPage([]any{map[string]any{"id": 1}, map[string]any{"id": 2}}, 42, params.Page, params.PerPage), nil
+}
+
+func (p *pagedResource) Table(ctx context.Context) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		state, err := p.BuildTableState(ctx, false, false)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "rows=%d total=%d", len(state.Rows), state.Pagination.Total)
+		return err
+	})
+}
+
+func TestCRUDHandler_List_PaginatedResource(t *testing.T) {
+	res := &pagedResource{mockResource: newMockResource("posts")}
+	h := NewCRUDHandler(res)
+
+	body := serveWith(h, http.MethodGet, "/posts?page=2&per_page=10&sort=title&dir=desc&filter_status=draft", nil).Body.String()
+	if !strings.Contains(body, "rows=2 total=42") {
+		t.Errorf("expected the page of ListPaginated, got %s", body)
+	}
+	p := res.params
+	if p.Page != 2 || p.PerPage != 10 || p.Sort != "-title" || len(p.Filters) != 1 || p.Filters[0].Field != "status" {
+		t.Errorf("unexpected params %+v", p)
+	}
+}
+
+// queryableResource lists its records with ListQuery only.
+type queryableResource struct {
+	*mockResource
+	query ListQuery
+	page  *PageResult
+}
+
+func (q *queryableResource) ListQuery(ctx context.Context, lq ListQuery) ([]any, int, error) {
+	q.query = lq
+	return []any{map[string]any{"id": 1}}, 30, nil
+}
+
+func (q *queryableResource) Table(ctx context.Context) templ.Component {
+	q.page, _ = PageFromContext(ctx)
+	return emptyComponent()
+}
+
+func TestPaginatedCRUDHandler_List_ResourceQueryable(t *testing.T) {
+	res := &queryableResource{mockResource: newMockResource("posts")}
+	h := NewPaginatedCRUDHandler(res)
+
+	serveWith(h, http.MethodGet, `/posts?page=3&size=10&sort=-title&filters=["views","gt",10]`, nil)
+	page := res.page
+	if page == nil || page.Total != 30 || page.Page != 3 || page.TotalPages != 3 || len(page.Items) != 1 {
+		t.Fatalf("expected the page built from ListQuery, got %+v", page)
+	}
+	lq := res.query
+	if lq.SortKey != "title" || lq.SortDir != "desc" || len(lq.Where) != 1 || lq.Where[0].Field != "views" {
+		t.Errorf("unexpected query %+v", lq)
+	}
+}
@@ -3,6 +3,7 @@ package engine
 import (
 	"net/http"
 
+	"github.com/bozz33/sublimeadmin/apperrors"
 	"github.com/bozz33/sublimeadmin/ui/layouts"
 )
 
@@ -22,12 +23,13 @@ func (h *PageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Check access permission
 	if !h.page.CanAccess(ctx) {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
 		return
 	}
 
 	// Render the page content
 	content := h.page.Render(ctx, r)
+	ctx = layouts.WithBreadcrumbs(ctx, []layouts.Breadcrumb{{Label: h.page.Label()}})
 
 	// Wrap in the base layout
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
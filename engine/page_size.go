@@ -0,0 +1,179 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultPageSizes are the page sizes offered in the table footer.
+var DefaultPageSizes = []int{10, 25, 50, 100}
+
+// PageSizeCookiePrefix prefixes the cookie remembering the page size of a
+// list (followed by the resource slug), used for guests and when the user
+// has no saved preference.
+const PageSizeCookiePrefix = "sublime_per_page_"
+
+// PageSizes are the page sizes of the list of a resource. Zero values keep
+// those of the handler (see PaginationConfig).
+type PageSizes struct {
+	Default int   // per page without a choice of the user
+	Max     int   // largest per page accepted
+	Options []int // choices of the table footer (default: DefaultPageSizes)
+}
+
+// ResourcePageSizes is an optional interface for resources setting the
+// page sizes of their list instead of the PaginationConfig of the handler.
+// BaseResource implements it, see SetPageSizes.
+type ResourcePageSizes interface {
+	PageSizes() PageSizes
+}
+
+// PageSizeStore persists the page size chosen by each user for each list.
+// Implementations must be safe for concurrent use; UserPageSize is called
+// on every list request of an authenticated user, so keep it cheap.
+//
+// MemoryPageSizeStore (default) keeps the preferences per process.
+type PageSizeStore interface {
+	// UserPageSize returns the page size of the list of the resource for
+	// the user, or 0 if none is saved.
+	UserPageSize(ctx context.Context, userID int, resource string) (int, error)
+	SetUserPageSize(ctx context.Context, userID int, resource string, size int) error
+}
+
+// MemoryPageSizeStore is an in-process PageSizeStore.
+type MemoryPageSizeStore struct {
+	mu    sync.RWMutex
+	sizes map[int]map[string]int
+}
+
+// NewMemoryPageSizeStore creates an empty in-memory store.
+func NewMemoryPageSizeStore() *MemoryPageSizeStore {
+	return &MemoryPageSizeStore{sizes: make(map[int]map[string]int)}
+}
+
+// UserPageSize implements PageSizeStore.
+func (s *MemoryPageSizeStore) UserPageSize(_ context.Context, userID int, resource string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sizes[userID][resource], nil
+}
+
+// SetUserPageSize implements PageSizeStore.
+func (s *MemoryPageSizeStore) SetUserPageSize(_ context.Context, userID int, resource string, size int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sizes[userID] == nil {
+		s.sizes[userID] = make(map[string]int)
+	}
+	s.sizes[userID][resource] = size
+	return nil
+}
+
+// WithPageSizeStore sets the store of the page size preferences of the
+// users. Defaults to a MemoryPageSizeStore.
+func (p *Panel) WithPageSizeStore(store PageSizeStore) *Panel {
+	p.pageSizeStore = store
+	return p
+}
+
+// pageSizeUser returns the store of the page sizes and the authenticated
+// user of the request, if any.
+func pageSizeUser(r *http.Request) (PageSizeStore, int) {
+	p := GetPanelFromContext(r.Context())
+	if p == nil || p.pageSizeStore == nil || p.AuthManager == nil {
+		return nil, 0
+	}
+	return p.pageSizeStore, p.AuthManager.UserIDFromRequest(r)
+}
+
+// userPageSize returns the saved page size of the list of slug: the
+// preference of the authenticated user, else the page size cookie.
+func userPageSize(r *http.Request, slug string) int {
+	if store, id := pageSizeUser(r); id > 0 {
+		if size, err := store.UserPageSize(r.Context(), id, slug); err == nil && size > 0 {
+			return size
+		}
+	}
+	if c, err := r.Cookie(PageSizeCookiePrefix + slug); err == nil {
+		size, _ := strconv.Atoi(c.Value)
+		return size
+	}
+	return 0
+}
+
+// listPageSizes returns cfg with the page sizes of res and, without a size
+// in the request, the one saved by the user as default. A size in the
+// request is saved as the preference of the user.
+func listPageSizes(w http.ResponseWriter, r *http.Request, res Resource, cfg PaginationConfig) PaginationConfig {
+	if rs, ok := res.(ResourcePageSizes); ok {
+		sizes := rs.PageSizes()
+		if sizes.Max > 0 {
+			cfg.MaxSize = sizes.Max
+		}
+		if sizes.Default > 0 {
+			cfg.DefaultSize = min(sizes.Default, cfg.MaxSize)
+		}
+	}
+
+	saved := userPageSize(r, res.Slug())
+	var size int
+	for _, name := range cfg.SizeParams {
+		if v := r.URL.Query().Get(name); v != "" {
+			size, _ = strconv.Atoi(v)
+			break
+		}
+	}
+	switch {
+	case size <= 0 || size > cfg.MaxSize:
+		if saved > 0 {
+			cfg.DefaultSize = min(saved, cfg.MaxSize)
+		}
+	case size != saved:
+		savePageSize(w, r, res.Slug(), size)
+	}
+	return cfg
+}
+
+// savePageSize saves the page size of the list of slug chosen by the user.
+// Failures only lose the preference.
+func savePageSize(w http.ResponseWriter, r *http.Request, slug string, size int) {
+	if store, id := pageSizeUser(r); id > 0 {
+		_ = store.SetUserPageSize(r.Context(), id, slug, size)
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     PageSizeCookiePrefix + slug,
+		Value:    strconv.Itoa(size),
+		Path:     "/",
+		MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// pageSizeOptions returns the page sizes offered for the list of res,
+// perPage included.
+func pageSizeOptions(res any, perPage int) []int {
+	options, maxSize := DefaultPageSizes, 0
+	if rs, ok := res.(ResourcePageSizes); ok {
+		sizes := rs.PageSizes()
+		if len(sizes.Options) > 0 {
+			options = sizes.Options
+		}
+		maxSize = sizes.Max
+	}
+	out := make([]int, 0, len(options)+1)
+	for _, size := range options {
+		if size > 0 && (maxSize == 0 || size <= maxSize) {
+			out = append(out, size)
+		}
+	}
+	if perPage > 0 && !slices.Contains(out, perPage) {
+		out = append(out, perPage)
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
@@ -0,0 +1,67 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestCRUDHandler_List_pageSizes(t *testing.T) {
+	var perPage int
+	res := &spyResource{mockResource: newMockResource("posts"), captureFunc: func(ctx context.Context) {
+		perPage = GetListQuery(ctx).PerPage
+	}}
+	res.SetPageSizes(10, 50)
+	h := NewCRUDHandler(res)
+
+	serveWith(h, http.MethodGet, "/posts", nil)
+	if perPage != 10 {
+		t.Errorf("expected the default size of the resource, got %d", perPage)
+	}
+
+	rec := serveWith(h, http.MethodGet, "/posts?per_page=500", nil)
+	if perPage != 50 || len(rec.Result().Cookies()) != 0 {
+		t.Errorf("expected the maximum of the resource and nothing saved, got %d", perPage)
+	}
+
+	// The chosen size is remembered
+	rec = serveWith(h, http.MethodGet, "/posts?per_page=25", nil)
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != PageSizeCookiePrefix+"posts" || cookies[0].Value != "25" {
+		t.Fatalf("expected the page size cookie, got %v", cookies)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/posts", nil)
+	req.AddCookie(cookies[0])
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	if perPage != 25 {
+		t.Errorf("expected the saved size, got %d", perPage)
+	}
+}
+
+func TestPageSizeOptions(t *testing.T) {
+	if got := pageSizeOptions(newMockResource("posts"), 20); !slices.Equal(got, []int{10, 20, 25, 50, 100}) {
+		t.Errorf("expected the default sizes and the current one, got %v", got)
+	}
+	res := newMockResource("posts")
+	res.SetPageSizes(0, 50, 5, 50, 500)
+	if got := pageSizeOptions(res, 50); !slices.Equal(got, []int{5, 50}) {
+		t.Errorf("expected the sizes of the resource up to its maximum, got %v", got)
+	}
+}
+
+func TestMemoryPageSizeStore(t *testing.T) {
+	s := NewMemoryPageSizeStore()
+	ctx := context.Background()
+	_ = s.SetUserPageSize(ctx, 1, "posts", 50)
+	if size, _ := s.UserPageSize(ctx, 1, "posts"); size != 50 {
+		t.Errorf("expected 50, got %d", size)
+	}
+	if size, _ := s.UserPageSize(ctx, 1, "users"); size != 0 {
+		t.Errorf("expected the preference to be per resource, got %d", size)
+	}
+	if size, _ := s.UserPageSize(ctx, 2, "posts"); size != 0 {
+		t.Errorf("expected the preference to be per user, got %d", size)
+	}
+}
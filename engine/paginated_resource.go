@@ -6,12 +6,16 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/apperrors"
+	"github.com/bozz33/sublimeadmin/metrics"
+	"github.com/bozz33/sublimeadmin/middleware"
 	"github.com/bozz33/sublimeadmin/ui/layouts"
 )
 
@@ -30,11 +34,22 @@ type PaginationConfig struct {
 	SearchParams []string // query param names for search (default: ["search","q"])
 	FilterParams []string // query param names for filters (default: ["filters"])
 	OrderParams  []string // query param names for order (default: ["order"])
+	CursorParams []string // query param names for the cursor (default: ["cursor"])
 	ErrorEnabled bool     // include error info in Page response
+
+	// Mode is PaginationOffset (default) or PaginationCursor.
+	Mode PaginationMode
+
+	// CountStrategy tells how the lists count their records (default
+	// CountExact, see NewCountedPage), and CountCap the cap of CountCapped
+	// (default 10000).
+	CountStrategy CountStrategy
+	CountCap      int
 }
 
 func defaultPaginationConfig() PaginationConfig {
 	return PaginationConfig{
+		Mode:         PaginationOffset,
 		DefaultSize:  15,
 		MaxSize:      100,
 		PageStart:    1,
@@ -44,6 +59,10 @@ func defaultPaginationConfig() PaginationConfig {
 		SearchParams: []string{"search", "q"},
 		FilterParams: []string{"filters"},
 		OrderParams:  []string{"order"},
+		CursorParams: []string{"cursor"},
+
+		CountStrategy: CountExact,
+		CountCap:      10000,
 	}
 }
 
@@ -115,6 +134,7 @@ type FilterExpr struct {
 //	["name","john"]                        → name = 'john'
 //	["name","like","john"]                 → name LIKE '%john%'
 //	["age","between",[20,25]]              → age BETWEEN 20 AND 25
+//	["deleted_at","is null"]               → deleted_at IS NULL
 //	[["name","like","john"],["OR"],["age","gt",18]]
 func ParseFiltersJSON(raw string) ([]*FilterExpr, error) {
 	if raw == "" {
@@ -189,8 +209,13 @@ func parseSingleFilter(arr []any) (*FilterExpr, error) {
 		return nil, fmt.Errorf("filter field must be a string")
 	}
 
-	// ["field","value"] → eq
+	// ["field","value"] → eq, ["field","is null"] → is null
 	if len(arr) == 2 {
+		if s, ok := arr[1].(string); ok {
+			if op := FilterOperator(strings.ToLower(s)); op == FilterIsNull || op == FilterIsNotNull {
+				return &FilterExpr{Field: field, Operator: op}, nil
+			}
+		}
 		return &FilterExpr{Field: field, Operator: FilterEq, Value: arr[1]}, nil
 	}
 
@@ -214,14 +239,20 @@ func isLogicalOp(s string) bool {
 
 // PaginationParams represents all pagination, sort, search and filter params.
 type PaginationParams struct {
+	Mode    PaginationMode // PaginationCursor when configured or a cursor is given
 	Page    int
 	PerPage int
+	Cursor  *Cursor // position of the page in cursor mode, nil on the first page
 	Search  string
 	Sorts   []SortField   // multi-column sort, parsed from sort param
 	Filters []*FilterExpr // structured filters, parsed from filters param
 	// Legacy single-sort fields (backward compat)
 	Sort  string
 	Order string
+
+	// How to count the records, see NewCountedPage.
+	CountStrategy CountStrategy
+	CountCap      int
 }
 
 // ParsePaginationParams extracts pagination from an HTTP request.
@@ -233,9 +264,12 @@ func ParsePaginationParams(r *http.Request) PaginationParams {
 // ParsePaginationParamsWithConfig parses with a custom config.
 func ParsePaginationParamsWithConfig(r *http.Request, cfg PaginationConfig) PaginationParams {
 	params := PaginationParams{
-		Page:    cfg.PageStart,
-		PerPage: cfg.DefaultSize,
-		Order:   "asc",
+		Mode:          cfg.Mode,
+		Page:          cfg.PageStart,
+		PerPage:       cfg.DefaultSize,
+		Order:         "asc",
+		CountStrategy: cfg.CountStrategy,
+		CountCap:      cfg.CountCap,
 	}
 
 	q := r.URL.Query()
@@ -251,6 +285,7 @@ func ParsePaginationParamsWithConfig(r *http.Request, cfg PaginationConfig) Pagi
 				Sort    string `json:"sort"`
 				Order   string `json:"order"`
 				Search  string `json:"search"`
+				Cursor  string `json:"cursor"`
 				Filters any    `json:"filters"`
 			}
 			if json.Unmarshal(body, &req) == nil {
@@ -264,8 +299,12 @@ func ParsePaginationParamsWithConfig(r *http.Request, cfg PaginationConfig) Pagi
 					params.PerPage = *req.PerPage
 				}
 				params.Sort = req.Sort
+				params.Sorts = ParseSortFields(req.Sort)
 				params.Order = req.Order
 				params.Search = req.Search
+				if req.Cursor != "" {
+					params.Cursor, _ = DecodeCursor(req.Cursor)
+				}
 				if req.Filters != nil {
 					b, _ := json.Marshal(req.Filters)
 					params.Filters, _ = ParseFiltersJSON(string(b))
@@ -323,6 +362,9 @@ func ParsePaginationParamsWithConfig(r *http.Request, cfg PaginationConfig) Pagi
 			break
 		}
 	}
+	if len(params.Sorts) == 1 && strings.EqualFold(params.Order, "desc") && !strings.HasPrefix(params.Sort, "-") {
+		params.Sorts[0].Desc = true
+	}
 
 	// Filters JSON
 	for _, pname := range cfg.FilterParams {
@@ -332,6 +374,25 @@ func ParsePaginationParamsWithConfig(r *http.Request, cfg PaginationConfig) Pagi
 		}
 	}
 
+	// Cursor, kept for the sort it was made for only
+	for _, pname := range cfg.CursorParams {
+		if v := q.Get(pname); v != "" {
+			params.Cursor, _ = DecodeCursor(v)
+			break
+		}
+	}
+	if params.Cursor != nil {
+		if params.Cursor.Sort != formatSortFields(params.keysetSorts()) {
+			params.Cursor = nil
+		} else {
+			params.Mode = PaginationCursor
+			params.Page = cfg.PageStart
+		}
+	}
+	if params.Mode == "" {
+		params.Mode = PaginationOffset
+	}
+
 	return params
 }
 
@@ -360,8 +421,15 @@ type PageResult struct {
 	First        bool   `json:"first"`
 	Last         bool   `json:"last"`
 	Visible      int    `json:"visible"`
+	NextCursor   string `json:"next_cursor,omitempty"` // cursor mode, see NewCursorPage
+	PrevCursor   string `json:"prev_cursor,omitempty"`
 	Error        bool   `json:"error,omitempty"`
 	ErrorMessage string `json:"error_message,omitempty"`
+
+	// Count tells how Total was counted (see NewCountedPage): empty or
+	// CountExact for an exact total, CountEstimated for an estimate,
+	// CountCapped for more than Total records, CountNone for no total.
+	Count CountStrategy `json:"count,omitempty"`
 }
 
 // NewPage creates a PageResult from items, total count, page and size.
@@ -421,6 +489,13 @@ type PaginationCacheAdapter interface {
 	Flush()
 }
 
+// PaginationCachePrefixDeleter is implemented by the caches able to drop
+// the pages of one resource (see Paginator.InvalidateResource). Caches
+// without it are flushed instead.
+type PaginationCachePrefixDeleter interface {
+	DeletePrefix(prefix string)
+}
+
 // MemoryPaginationCache is a simple in-memory cache for paginated results.
 type MemoryPaginationCache struct {
 	mu      sync.RWMutex
@@ -464,6 +539,17 @@ func (c *MemoryPaginationCache) Flush() {
 	c.entries = make(map[string]*pageCacheEntry)
 }
 
+// DeletePrefix implements PaginationCachePrefixDeleter.
+func (c *MemoryPaginationCache) DeletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, key)
+		}
+	}
+}
+
 // ---------------------------
 // PaginatedListFunc — function type
 // ---------------------------
@@ -491,6 +577,9 @@ type Paginator struct {
 	cache    PaginationCacheAdapter
 	cacheTTL time.Duration
 	listFn   PaginatedListFunc
+
+	resource string    // namespace of the cache keys, see WithResource
+	hookOnce sync.Once // registers the invalidation hook of the cache
 }
 
 // NewPaginator creates a Paginator with default config.
@@ -507,13 +596,73 @@ func (p *Paginator) WithConfig(cfg PaginationConfig) *Paginator {
 	return p
 }
 
-// WithCache enables caching of paginated results.
+// WithMode sets the pagination mode: PaginationOffset or PaginationCursor.
+func (p *Paginator) WithMode(mode PaginationMode) *Paginator {
+	p.cfg.Mode = mode
+	return p
+}
+
+// WithCountStrategy sets how the lists count their records.
+func (p *Paginator) WithCountStrategy(strategy CountStrategy) *Paginator {
+	p.cfg.CountStrategy = strategy
+	return p
+}
+
+// WithCache enables caching of paginated results. The cached pages of a
+// resource are dropped after each successful write through the CRUD
+// handlers (see middleware.InvalidateCache).
 func (p *Paginator) WithCache(adapter PaginationCacheAdapter, ttl time.Duration) *Paginator {
 	p.cache = adapter
 	p.cacheTTL = ttl
+	p.hookOnce.Do(func() {
+		middleware.OnCacheInvalidate(func(_ context.Context, tags []string) {
+			for _, tag := range tags {
+				p.InvalidateResource(tag)
+			}
+		})
+	})
 	return p
 }
 
+// WithResource sets the resource slug the cache keys of Response are
+// namespaced with. PaginatedCRUDHandler uses the slug of its resource.
+func (p *Paginator) WithResource(slug string) *Paginator {
+	p.resource = slug
+	return p
+}
+
+// InvalidateResource drops the cached pages of the resource slug, or the
+// whole cache when it is not a PaginationCachePrefixDeleter.
+func (p *Paginator) InvalidateResource(slug string) {
+	switch c := p.cache.(type) {
+	case nil:
+	case PaginationCachePrefixDeleter:
+		c.DeletePrefix(pageCacheKeyPrefix(slug))
+	default:
+		c.Flush()
+	}
+}
+
+// cachedPage returns the cached page of params of the resource slug for
+// the tenant of ctx or fetches it, caching the pages fetched without error.
+func (p *Paginator) cachedPage(ctx context.Context, slug string, params PaginationParams, fetch func() (*PageResult, error)) (*PageResult, error) {
+	if p.cache == nil {
+		return fetch()
+	}
+	key := pageCacheKey(pageCacheSlug(ctx, slug), params)
+	if cached, ok := p.cache.Get(key); ok {
+		metrics.CacheHits.WithLabelValues("pagination").Inc()
+		return cached, nil
+	}
+	metrics.CacheMisses.WithLabelValues("pagination").Inc()
+
+	page, err := fetch()
+	if err == nil && page != nil {
+		p.cache.Set(key, page, p.cacheTTL)
+	}
+	return page, err
+}
+
 // With sets the list function.
 func (p *Paginator) With(fn PaginatedListFunc) *PaginatorRequest {
 	return &PaginatorRequest{paginator: p, fn: fn}
@@ -541,28 +690,15 @@ type PaginatorResponse struct {
 
 // Response executes the paginated query.
 func (pr *PaginatorResponse) Response(ctx context.Context) *PageResult {
-	// Build cache key
-	cacheKey := fmt.Sprintf("page:%d:size:%d:search:%s:sort:%s:order:%s",
-		pr.params.Page, pr.params.PerPage, pr.params.Search, pr.params.Sort, pr.params.Order)
-
-	if pr.paginator.cache != nil {
-		if cached, ok := pr.paginator.cache.Get(cacheKey); ok {
-			return cached
-		}
-	}
-
-	pageResult, err := pr.fn(ctx, pr.params)
+	pageResult, err := pr.paginator.cachedPage(ctx, pr.paginator.resource, pr.params, func() (*PageResult, error) {
+		return pr.fn(ctx, pr.params)
+	})
 	if err != nil {
 		if pr.paginator.cfg.ErrorEnabled {
 			return NewPageError(err)
 		}
 		return NewPage(nil, 0, pr.params.Page, pr.params.PerPage)
 	}
-
-	if pr.paginator.cache != nil && pageResult != nil {
-		pr.paginator.cache.Set(cacheKey, pageResult, pr.paginator.cacheTTL)
-	}
-
 	return pageResult
 }
 
@@ -588,37 +724,47 @@ func (h *PaginatedCRUDHandler) WithPaginator(p *Paginator) *PaginatedCRUDHandler
 	return h
 }
 
-// List displays the paginated list of items.
+// List displays the paginated list of items, listed by the PaginatedResource
+// or, adapted, the ResourceQueryable (see ListQuery).
 func (h *PaginatedCRUDHandler) List(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	params := ParsePaginationParamsWithConfig(r, h.Paginator.cfg)
-
-	var component templ.Component
-	title := h.Resource.PluralLabel()
+	scope, err := resourceScope(ctx, h.Resource)
+	if err != nil {
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
+		return
+	}
+	params := ParsePaginationParamsWithConfig(r, listPageSizes(w, r, h.Resource, h.Paginator.cfg))
 
-	if paginated, ok := h.Resource.(PaginatedResource); ok {
-		pageResult, err := paginated.ListPaginated(ctx, params)
-		if err != nil {
-			http.Error(w, "List error: "+err.Error(), http.StatusInternalServerError)
-			return
+	// The scope is part of the cache key: it may differ between users.
+	keyParams := params
+	keyParams.Filters = append(slices.Clone(scope), params.Filters...)
+	pageResult, err := h.Paginator.cachedPage(ctx, h.Resource.Slug(), keyParams, func() (*PageResult, error) {
+		// nil when the resource lists without pagination
+		page, _, err := queryPage(ctx, h.Resource, params, scope)
+		if err == nil && page != nil {
+			err = EagerLoad(ctx, h.Resource, page.Items)
 		}
+		return page, err
+	})
+	if err != nil {
+		apperrors.Handle(w, r, apperrors.Internal(err, "List error"))
+		return
+	}
+	if pageResult != nil {
 		ctx = context.WithValue(ctx, paginationContextKey, pageResult)
-		component = h.Resource.Table(ctx)
-	} else {
-		component = h.Resource.Table(ctx)
 	}
 
-	renderPage(w, r, title, component)
+	renderPage(w, withBreadcrumbs(r, h.Resource, PageList, "", nil), h.Resource.PluralLabel(), h.Resource.Table(ctx))
 }
 
 // Create displays the creation form.
 func (h *PaginatedCRUDHandler) Create(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	if !h.Resource.CanCreate(ctx) {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
 		return
 	}
-	renderPage(w, r, "Create "+h.Resource.Label(), h.Resource.Form(ctx, nil))
+	renderPage(w, withBreadcrumbs(r, h.Resource, PageCreate, "", nil), "Create "+h.Resource.Label(), h.Resource.Form(ctx, nil))
 }
 
 // Edit displays the edit form.
@@ -629,77 +775,92 @@ func (h *PaginatedCRUDHandler) Edit(w http.ResponseWriter, r *http.Request, id s
 		http.NotFound(w, r)
 		return
 	}
-	renderPage(w, r, "Edit "+h.Resource.Label(), h.Resource.Form(ctx, item))
+	renderPage(w, withBreadcrumbs(r, h.Resource, PageEdit, id, item), "Edit "+h.Resource.Label(), h.Resource.Form(ctx, item))
 }
 
 // Store handles creation.
 func (h *PaginatedCRUDHandler) Store(w http.ResponseWriter, r *http.Request) {
 	if !h.Resource.CanCreate(r.Context()) {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
 		return
 	}
 	if err := h.Resource.Create(r.Context(), r); err != nil {
-		http.Error(w, "Creation error: "+err.Error(), http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, "Creation error"))
 		return
 	}
-	http.Redirect(w, r, "/"+h.Resource.Slug()+preservePaginationQuery(r), http.StatusSeeOther)
+	http.Redirect(w, r, PanelURL(r.Context(), "/"+h.Resource.Slug()+preservePaginationQuery(r)), http.StatusSeeOther)
 }
 
 // Update handles updates.
 func (h *PaginatedCRUDHandler) Update(w http.ResponseWriter, r *http.Request, id string) {
 	if !h.Resource.CanUpdate(r.Context()) {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
 		return
 	}
 	if err := h.Resource.Update(r.Context(), id, r); err != nil {
-		http.Error(w, "Update error: "+err.Error(), http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, "Update error"))
 		return
 	}
-	http.Redirect(w, r, "/"+h.Resource.Slug()+preservePaginationQuery(r), http.StatusSeeOther)
+	http.Redirect(w, r, PanelURL(r.Context(), "/"+h.Resource.Slug()+preservePaginationQuery(r)), http.StatusSeeOther)
 }
 
 // Delete handles deletion.
 func (h *PaginatedCRUDHandler) Delete(w http.ResponseWriter, r *http.Request, id string) {
 	ctx := r.Context()
 	if !h.Resource.CanDelete(ctx) {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
 		return
 	}
 	if err := h.Resource.Delete(ctx, id); err != nil {
-		http.Error(w, "Delete error: "+err.Error(), http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, "Delete error"))
 		return
 	}
-	http.Redirect(w, r, "/"+h.Resource.Slug()+preservePaginationQuery(r), http.StatusSeeOther)
+	http.Redirect(w, r, PanelURL(r.Context(), "/"+h.Resource.Slug()+preservePaginationQuery(r)), http.StatusSeeOther)
 }
 
 // BulkDelete handles bulk deletion.
 func (h *PaginatedCRUDHandler) BulkDelete(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	if !h.Resource.CanDelete(ctx) {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
 		return
 	}
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Form parsing error", http.StatusBadRequest)
+		apperrors.Handle(w, r, apperrors.BadRequest("Form parsing error"))
 		return
 	}
 	ids := r.Form["ids[]"]
 	if len(ids) == 0 {
-		http.Error(w, "No items selected", http.StatusBadRequest)
+		apperrors.Handle(w, r, apperrors.BadRequest("No items selected"))
 		return
 	}
 	if err := h.Resource.BulkDelete(ctx, ids); err != nil {
-		http.Error(w, "Bulk delete error: "+err.Error(), http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, "Bulk delete error"))
 		return
 	}
-	http.Redirect(w, r, "/"+h.Resource.Slug()+preservePaginationQuery(r), http.StatusSeeOther)
+	http.Redirect(w, r, PanelURL(r.Context(), "/"+h.Resource.Slug()+preservePaginationQuery(r)), http.StatusSeeOther)
 }
 
 // ServeHTTP implements http.Handler with automatic routing.
 func (h *PaginatedCRUDHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Successful writes drop the cached pages of the resource (see
+	// Paginator.WithCache and middleware.Cache).
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		rw := middleware.NewResponseWriter(w)
+		defer func() {
+			if rw.Status() < http.StatusBadRequest {
+				middleware.InvalidateCache(r.Context(), h.Resource.Slug())
+			}
+		}()
+		w = rw
+	}
+
 	path := strings.TrimPrefix(r.URL.Path, "/"+h.Resource.Slug())
 	path = strings.TrimPrefix(path, "/")
 	parts := strings.Split(path, "/")
+	if !guardTenant(w, r, h.Resource, parts) {
+		return
+	}
 
 	switch r.Method {
 	case http.MethodGet:
@@ -711,7 +872,7 @@ func (h *PaginatedCRUDHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		case len(parts) == 2 && parts[1] == "edit":
 			h.Edit(w, r, parts[0])
 		default:
-			http.Redirect(w, r, fmt.Sprintf("/%s/%s/edit", h.Resource.Slug(), parts[0]), http.StatusSeeOther)
+			http.Redirect(w, r, PanelURL(r.Context(), fmt.Sprintf("/%s/%s/edit", h.Resource.Slug(), parts[0])), http.StatusSeeOther)
 		}
 	case http.MethodPost:
 		r.ParseForm()
@@ -0,0 +1,176 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/middleware"
+)
+
+// pageCacheKeyPrefix is the prefix of the cache keys of the pages of the
+// resource slug.
+func pageCacheKeyPrefix(slug string) string {
+	return slug + ":"
+}
+
+// pageCacheSlug namespaces the cache keys of the resource slug with the
+// tenant of ctx, so that the pages of a tenant never serve another. They
+// keep the prefix of the slug, dropped by InvalidateResource.
+func pageCacheSlug(ctx context.Context, slug string) string {
+	if t := TenantFromContext(ctx); t != nil {
+		return slug + ":tenant:" + t.ID
+	}
+	return slug
+}
+
+// pageCacheKey is the cache key of the page of params of the resource slug.
+// It covers every param changing the page, the filters (scope included)
+// as a hash.
+func pageCacheKey(slug string, p PaginationParams) string {
+	filters, _ := json.Marshal(p.Filters)
+	key := fmt.Sprintf("%spage:%d:size:%d:search:%s:sort:%s:order:%s:filters:%x",
+		pageCacheKeyPrefix(slug), p.Page, p.PerPage, p.Search, p.Sort, p.Order, sha256.Sum256(filters))
+	if p.Cursor != nil {
+		key += ":cursor:" + EncodeCursor(*p.Cursor)
+	}
+	return key
+}
+
+// --- Redis cache ---
+
+const (
+	redisGetScript = `return redis.call("GET", KEYS[1])`
+	redisSetScript = `return redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])`
+	redisDelScript = `return redis.call("DEL", KEYS[1])`
+
+	// redisDelPrefixScript deletes the keys matching the pattern ARGV[1].
+	redisDelPrefixScript = `
+local cursor = "0"
+repeat
+  local res = redis.call("SCAN", cursor, "MATCH", ARGV[1], "COUNT", 500)
+  cursor = res[1]
+  if #res[2] > 0 then
+    redis.call("DEL", unpack(res[2]))
+  end
+until cursor == "0"
+return 0
+`
+)
+
+// RedisPaginationCache is a PaginationCacheAdapter shared by every replica
+// connected to the same Redis, so a write on one replica invalidates the
+// pages of all. Pages are stored as JSON: their items come back as
+// map[string]any unless decoded by WithItems.
+//
+//	cache := engine.NewRedisPaginationCache(goRedisEvaler{client}, "").
+//	    WithItems(engine.ItemsAs[*ent.Post]())
+//	p := engine.NewPaginator().WithCache(cache, time.Minute)
+//
+// See middleware.RedisEvaler to adapt a client. The cache fails open: a
+// Redis error is a miss.
+type RedisPaginationCache struct {
+	client middleware.RedisEvaler
+	prefix string
+	items  func(json.RawMessage) ([]any, error)
+}
+
+// NewRedisPaginationCache creates a Redis-backed cache. Keys are
+// namespaced with prefix (default "pagination:").
+func NewRedisPaginationCache(client middleware.RedisEvaler, prefix string) *RedisPaginationCache {
+	if prefix == "" {
+		prefix = "pagination:"
+	}
+	return &RedisPaginationCache{client: client, prefix: prefix, items: ItemsAs[map[string]any]()}
+}
+
+// WithItems sets the decoder of the items of the cached pages.
+func (c *RedisPaginationCache) WithItems(decode func(json.RawMessage) ([]any, error)) *RedisPaginationCache {
+	c.items = decode
+	return c
+}
+
+// ItemsAs returns a decoder of items of type T, for WithItems.
+func ItemsAs[T any]() func(json.RawMessage) ([]any, error) {
+	return func(raw json.RawMessage) ([]any, error) {
+		var typed []T
+		if err := json.Unmarshal(raw, &typed); err != nil {
+			return nil, err
+		}
+		items := make([]any, len(typed))
+		for i, item := range typed {
+			items[i] = item
+		}
+		return items, nil
+	}
+}
+
+// cachedPageJSON decodes a PageResult keeping its items raw.
+type cachedPageJSON struct {
+	PageResult
+	Items json.RawMessage `json:"items"`
+}
+
+// Get implements PaginationCacheAdapter.
+func (c *RedisPaginationCache) Get(key string) (*PageResult, bool) {
+	raw, err := c.client.Eval(context.Background(), redisGetScript, []string{c.prefix + key})
+	if err != nil {
+		return nil, false
+	}
+	var b []byte
+	switch v := raw.(type) {
+	case string:
+		b = []byte(v)
+	case []byte:
+		b = v
+	default:
+		return nil, false
+	}
+
+	var cached cachedPageJSON
+	if err := json.Unmarshal(b, &cached); err != nil {
+		return nil, false
+	}
+	page := cached.PageResult
+	if len(cached.Items) > 0 && string(cached.Items) != "null" {
+		if page.Items, err = c.items(cached.Items); err != nil {
+			return nil, false
+		}
+	}
+	return &page, true
+}
+
+// Set implements PaginationCacheAdapter.
+func (c *RedisPaginationCache) Set(key string, page *PageResult, ttl time.Duration) {
+	if ttl < time.Millisecond {
+		return
+	}
+	b, err := json.Marshal(page)
+	if err != nil {
+		return
+	}
+	c.client.Eval(context.Background(), redisSetScript, []string{c.prefix + key}, string(b), ttl.Milliseconds())
+}
+
+// Delete implements PaginationCacheAdapter.
+func (c *RedisPaginationCache) Delete(key string) {
+	c.client.Eval(context.Background(), redisDelScript, []string{c.prefix + key})
+}
+
+// Flush implements PaginationCacheAdapter. It deletes the keys of the
+// prefix only.
+func (c *RedisPaginationCache) Flush() {
+	c.DeletePrefix("")
+}
+
+// DeletePrefix implements PaginationCachePrefixDeleter. The keys are
+// found by SCAN, which a Redis Cluster only runs on one node.
+func (c *RedisPaginationCache) DeletePrefix(prefix string) {
+	c.client.Eval(context.Background(), redisDelPrefixScript, nil, redisGlobEscaper.Replace(c.prefix+prefix)+"*")
+}
+
+// redisGlobEscaper escapes the special characters of a SCAN MATCH pattern.
+var redisGlobEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
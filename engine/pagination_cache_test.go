@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+func TestPaginator_cacheKeyIncludesFilters(t *testing.T) {
+	calls := 0
+	list := func(ctx context.Context, p PaginationParams) (*PageResult, error) {
+		calls++
+		return NewPage([]any{p.Filters[0].Value}, 1, p.Page, p.PerPage), nil
+	}
+	p := NewPaginator().WithResource("posts").WithCache(NewMemoryPaginationCache(), time.Minute)
+
+	get := func(status string) *PageResult {
+		r := httptest.NewRequest(http.MethodGet, `/posts?filters=["status","eq","`+status+`"]`, nil)
+		return p.With(list).Request(r).Response(context.Background())
+	}
+	draft, published := get("draft"), get("published")
+	if calls != 2 || draft.Items[0] != "draft" || published.Items[0] != "published" {
+		t.Errorf("expected a page per filter, got %v and %v after %d calls", draft.Items, published.Items, calls)
+	}
+	if get("draft"); calls != 2 {
+		t.Errorf("expected the page of the same filter cached, got %d calls", calls)
+	}
+}
+
+func TestPaginator_InvalidateResource(t *testing.T) {
+	cache := NewMemoryPaginationCache()
+	p := NewPaginator().WithCache(cache, time.Minute)
+	cache.Set(pageCacheKey("posts", PaginationParams{Page: 1}), &PageResult{}, time.Minute)
+	cache.Set(pageCacheKey("users", PaginationParams{Page: 1}), &PageResult{}, time.Minute)
+
+	p.InvalidateResource("posts")
+	if _, ok := cache.Get(pageCacheKey("posts", PaginationParams{Page: 1})); ok {
+		t.Error("expected the pages of posts dropped")
+	}
+	if _, ok := cache.Get(pageCacheKey("users", PaginationParams{Page: 1})); !ok {
+		t.Error("expected the pages of users kept")
+	}
+}
+
+func TestPaginator_cacheKeyIncludesTenant(t *testing.T) {
+	list := func(ctx context.Context, p PaginationParams) (*PageResult, error) {
+		return NewPage([]any{TenantFromContext(ctx).ID}, 1, p.Page, p.PerPage), nil
+	}
+	p := NewPaginator().WithResource("posts").WithCache(NewMemoryPaginationCache(), time.Minute)
+
+	get := func(tenant string) *PageResult {
+		ctx := WithTenant(context.Background(), &Tenant{ID: tenant})
+		return p.With(list).Request(httptest.NewRequest(http.MethodGet, "/posts", nil)).Response(ctx)
+	}
+	if acme, globex := get("acme"), get("globex"); acme.Items[0] != "acme" || globex.Items[0] != "globex" {
+		t.Errorf("expected a page per tenant, got %v and %v", acme.Items, globex.Items)
+	}
+}
+
+// countingResource counts its ListPaginated calls.
+type countingResource struct {
+	pagedResource
+	calls int
+}
+
+func (c *countingResource) ListPaginated(ctx context.Context, params PaginationParams) (*PageResult, error) {
+	c.calls++
+	return c.pagedResource.ListPaginated(ctx, params)
+}
+
+func (c *countingResource) Table(ctx context.Context) templ.Component {
+	return emptyComponent()
+}
+
+func TestPaginatedCRUDHandler_cacheInvalidatedByWrites(t *testing.T) {
+	res := &countingResource{pagedResource: pagedResource{mockResource: newMockResource("cached-posts")}}
+	h := NewPaginatedCRUDHandler(res).
+		WithPaginator(NewPaginator().WithCache(NewMemoryPaginationCache(), time.Minute))
+
+	serveWith(h, http.MethodGet, "/cached-posts?page=2", nil)
+	serveWith(h, http.MethodGet, "/cached-posts?page=2", nil)
+	if res.calls != 1 {
+		t.Fatalf("expected the second list served from the cache, got %d calls", res.calls)
+	}
+
+	serveWith(h, http.MethodPost, "/cached-posts", url.Values{"title": {"Hello"}})
+	serveWith(h, http.MethodGet, "/cached-posts?page=2", nil)
+	if res.calls != 2 {
+		t.Errorf("expected the write to invalidate the cached pages, got %d calls", res.calls)
+	}
+}
+
+// fakePageRedis runs the scripts of RedisPaginationCache on a map.
+type fakePageRedis struct {
+	data     map[string]string
+	patterns []string
+}
+
+func (f *fakePageRedis) Eval(_ context.Context, script string, keys []string, args ...any) (any, error) {
+	switch script {
+	case redisGetScript:
+		v, ok := f.data[keys[0]]
+		if !ok {
+			return nil, nil
+		}
+		return v, nil
+	case redisSetScript:
+		f.data[keys[0]] = args[0].(string)
+	case redisDelScript:
+		delete(f.data, keys[0])
+	case redisDelPrefixScript:
+		pattern := args[0].(string)
+		f.patterns = append(f.patterns, pattern)
+		for key := range f.data {
+			if strings.HasPrefix(key, strings.TrimSuffix(pattern, "*")) {
+				delete(f.data, key)
+			}
+		}
+	}
+	return "OK", nil
+}
+
+func TestRedisPaginationCache(t *testing.T) {
+	redis := &fakePageRedis{data: make(map[string]string)}
+	cache := NewRedisPaginationCache(redis, "").WithItems(ItemsAs[blogPost]())
+
+	cache.Set("posts:page:1", NewPage([]any{blogPost{ID: 1, Title: "Hello"}}, 11, 1, 10), time.Minute)
+	page, ok := cache.Get("posts:page:1")
+	if !ok || page.Total != 11 || page.TotalPages != 2 || len(page.Items) != 1 {
+		t.Fatalf("unexpected cached page %+v", page)
+	}
+	if post, ok := page.Items[0].(blogPost); !ok || post.Title != "Hello" {
+		t.Errorf("expected the items decoded as blogPost, got %#v", page.Items[0])
+	}
+	if _, ok := cache.Get("posts:page:2"); ok {
+		t.Error("expected a miss")
+	}
+
+	NewPaginator().WithCache(cache, time.Minute).InvalidateResource("posts")
+	if _, ok := cache.Get("posts:page:1"); ok {
+		t.Error("expected the pages of posts dropped")
+	}
+	if got := redis.patterns[0]; got != "pagination:posts:*" {
+		t.Errorf("unexpected SCAN pattern %q", got)
+	}
+}
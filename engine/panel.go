@@ -7,21 +7,33 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/a-h/templ"
 	"github.com/alexedwards/scs/v2"
+	"github.com/bozz33/sublimeadmin/actions"
+	"github.com/bozz33/sublimeadmin/apperrors"
 	"github.com/bozz33/sublimeadmin/auth"
 	"github.com/bozz33/sublimeadmin/export"
+	"github.com/bozz33/sublimeadmin/flash"
+	"github.com/bozz33/sublimeadmin/form"
+	"github.com/bozz33/sublimeadmin/health"
+	"github.com/bozz33/sublimeadmin/jobs"
+	"github.com/bozz33/sublimeadmin/logger"
 	"github.com/bozz33/sublimeadmin/mailer"
 	"github.com/bozz33/sublimeadmin/middleware"
 	"github.com/bozz33/sublimeadmin/notifications"
-	"github.com/bozz33/sublimeadmin/plugin"
+	"github.com/bozz33/sublimeadmin/scim"
 	"github.com/bozz33/sublimeadmin/search"
 	"github.com/bozz33/sublimeadmin/ui/assets"
+	"github.com/bozz33/sublimeadmin/ui/icons"
 	"github.com/bozz33/sublimeadmin/ui/layouts"
 	"github.com/bozz33/sublimeadmin/views/dashboard"
+	errorViews "github.com/bozz33/sublimeadmin/views/errors"
 	"github.com/bozz33/sublimeadmin/widget"
 	// Auto-register widget renderers (Stats, Chart, Grid, Timeline, Progress).
 	// This import ensures widget.Render() works without manual blank import in user projects.
@@ -42,23 +54,36 @@ import (
 // NavigationItem represents a manual sidebar link (not tied to a Resource).
 type NavigationItem struct {
 	Label       string
-	URL         string
-	Icon        string
+	URL         string // slug relative to the panel path ("reports"), path ("/docs") or URL ("https://...")
+	Icon        string // icon name, see ui/icons ("people", "heroicons:users")
 	Badge       string // optional badge text
 	BadgeColor  string // optional badge color ("green", "red", etc.)
 	Group       string // optional group name to attach to a NavigationGroup
 	Sort        int
 	ActiveRegex string // regex to match the current URL for active state
+	External    bool   // open in a new tab (default for absolute URLs)
+
+	// BadgeFunc computes the badge and its color on each request, e.g. a
+	// count of pending orders. It overrides Badge and BadgeColor.
+	BadgeFunc func(ctx context.Context) (string, string)
+	// VisibleWhen hides the item when it returns false, e.g. for users
+	// without a permission.
+	VisibleWhen func(ctx context.Context) bool
+	// Children are the sub-items of a submenu.
+	Children []NavigationItem
 }
 
 // NavigationGroup represents a collapsible sidebar group of NavigationItems.
 type NavigationGroup struct {
 	Label       string
-	Icon        string
-	Collapsible bool
-	DefaultOpen bool
+	Icon        string // icon name, see ui/icons ("people", "heroicons:users")
+	Collapsible bool   // the users can fold the group; the state is saved in the browser
+	DefaultOpen bool   // a collapsible group is folded until opened, unless DefaultOpen
 	Items       []NavigationItem
 	Sort        int
+
+	// VisibleWhen hides the group when it returns false.
+	VisibleWhen func(ctx context.Context) bool
 }
 
 type Panel struct {
@@ -96,16 +121,124 @@ type Panel struct {
 	// and injected into request context so form templates can include them.
 	csrf *CSRFManager
 
+	// Security headers (CSP, HSTS...). Set via WithSecurityHeaders().
+	securityHeaders *middleware.SecurityHeadersConfig
+
+	// Flash message manager. Set via WithFlash(); defaults to one storing the
+	// messages in Session.
+	flashManager *flash.Manager
+
+	// Background jobs queue (large bulk action selections). Set via WithJobs().
+	jobs *jobs.Queue
+
+	// Transaction of the resource mutations. Set via WithUnitOfWork().
+	unitOfWork UnitOfWork
+
+	// Checks of the /healthz and /readyz probes. Set via WithHealthChecks().
+	healthChecks *health.Registry
+
+	// SCIM provisioning endpoint at /scim/v2 and its store. Set via
+	// WithSCIM().
+	scim      *scim.Server
+	scimStore scim.Store
+
+	// notifHandler serves the notifications when Notifications is set; its
+	// SSE streams end when Run shuts the server down.
+	notifHandler *notifications.Handler
+
+	// Rate limits and idempotency keys of the actions. Set via
+	// WithActionLimiter(); defaults to one keeping them in memory.
+	actionLimiter *actions.Limiter
+
+	// Honeypot, minimum fill time and throttling of the registration and
+	// password reset forms. Set via WithAntiSpam().
+	antiSpam *form.Form
+
+	// Standalone actions routed under their resource. Set via AddActions().
+	actions []*actions.Action
+
+	// Error page overrides by status code. Set via WithErrorPage().
+	errorPages map[int]templ.Component
+
 	// Lifecycle hooks
 	beforeBootHooks []BootHook
 	afterBootHooks  []BootHook
 
+	// Graceful shutdown of Run. Set via WithShutdownTimeout() and
+	// OnShutdown().
+	shutdownTimeout time.Duration
+	shutdownHooks   []func(ctx context.Context) error
+
 	// Color scheme for semantic colors (primary, danger, success, warning, info, secondary)
 	colorScheme *ColorScheme
 
 	// Manual navigation items and groups (supplement auto-generated Resource nav)
-	NavItems  []NavigationItem
-	NavGroups []NavigationGroup
+	NavItems             []NavigationItem
+	NavGroups            []NavigationGroup
+	collapsibleNavGroups bool
+
+	// Custom "Go to" targets of the global search. Set via AddCommands().
+	commands []search.Command
+
+	// Synonyms, stop words and typo tolerance of the global search. Set via
+	// WithSearchAnalyzer().
+	searchAnalyzer *search.Analyzer
+
+	// Plugin assets and route groups, extra stylesheets and scripts
+	pluginAssets []pluginMount
+	pluginRoutes []pluginMount
+	stylesheets  []string
+	scripts      []string
+	renderHooks  map[string][]templ.Component
+
+	// Themes, default theme and theme preferences of the users
+	themes     []layouts.Theme
+	theme      string
+	themeStore ThemeStore
+
+	// Color mode preferences of the users (light, dark, system)
+	colorModeStore ColorModeStore
+
+	// Page sizes of the lists chosen by the users
+	pageSizeStore PageSizeStore
+
+	// Records and resources pinned by the users
+	favoriteStore FavoriteStore
+
+	// Devices the users signed in from, with their remember tokens. Set via
+	// WithRememberMe().
+	deviceStore     DeviceStore
+	rememberFor     time.Duration
+	newDeviceAlerts bool
+
+	// Passwords accepted on registration, reset and profile change. Set via
+	// WithPasswordPolicy(); nil means auth.DefaultPasswordPolicy.
+	passwordPolicy *auth.PasswordPolicy
+
+	// Row isolation of the TenantAware resources in a shared database
+	tenantScoper *TenantScoper
+
+	// Search namespace of the resources of a panel serving one tenant, set
+	// by MultiPanelRouter.RegisterPanel
+	searchNamespace string
+
+	// Tenant of the requests, tenants of the topbar switcher and access
+	// check of the panel
+	tenantResolver TenantResolver
+	tenantSwitcher func(ctx context.Context) ([]*Tenant, error)
+	accessCheck    func(ctx context.Context) bool
+
+	// Icon browser page (/debug/icons)
+	iconBrowser bool
+
+	// Traffic of the panel on the dashboard and at /system/metrics
+	serverMetrics bool
+
+	// Configuration and navigation of the layouts, built by Router and
+	// injected into each request, so panels served side by side (see
+	// Serve) each render their own.
+	config    *layouts.PanelConfig
+	navGroups []layouts.NavGroup
 }
 
 // NewPanel initializes a Panel with sensible defaults.
@@ -139,6 +272,13 @@ func (p *Panel) WithNavGroups(groups ...NavigationGroup) *Panel {
 	return p
 }
 
+// WithCollapsibleNavGroups lets the users fold the navigation groups built
+// from the Group() of the resources and pages.
+func (p *Panel) WithCollapsibleNavGroups(enabled bool) *Panel {
+	p.collapsibleNavGroups = enabled
+	return p
+}
+
 // Builder methods — Filament-style fluent API.
 
 func (p *Panel) WithPath(path string) *Panel {
@@ -234,6 +374,44 @@ func (p *Panel) WithSession(session *scs.SessionManager) *Panel {
 	return p
 }
 
+// WithFlash sets the manager of the flash messages. Panels with a session
+// store them in it by default; stateless panels can keep them in a signed
+// cookie instead:
+//
+//	panel.WithFlash(flash.NewCookieManager([]byte(os.Getenv("FLASH_SECRET"))))
+func (p *Panel) WithFlash(manager *flash.Manager) *Panel {
+	p.flashManager = manager
+	return p
+}
+
+// WithJobs sets the queue running large bulk action selections in the
+// background (see actions.BulkAction.InBackground). The queue must be
+// started by the application.
+func (p *Panel) WithJobs(queue *jobs.Queue) *Panel {
+	p.jobs = queue
+	return p
+}
+
+// WithUnitOfWork runs each create, update, delete, relation and action
+// request of the resources in a transaction of uow, rolled back when the
+// request fails (see UnitOfWork):
+//
+//	panel.WithUnitOfWork(engine.SQLUnitOfWork(db))
+func (p *Panel) WithUnitOfWork(uow UnitOfWork) *Panel {
+	p.unitOfWork = uow
+	return p
+}
+
+// WithHealthChecks serves the liveness and readiness probes of checks at
+// /healthz and /readyz under the panel path, without authentication:
+//
+//	checks := health.NewRegistry().Register("db", health.Ping(db))
+//	panel.WithHealthChecks(checks)
+func (p *Panel) WithHealthChecks(checks *health.Registry) *Panel {
+	p.healthChecks = checks
+	return p
+}
+
 // WithMailer sets the mailer used for password reset emails.
 // Use mailer.NewSMTPMailer(cfg) for production, mailer.LogMailer{} for dev.
 func (p *Panel) WithMailer(m mailer.Mailer) *Panel {
@@ -248,22 +426,40 @@ func (p *Panel) WithBaseURL(url string) *Panel {
 	return p
 }
 
-// syncConfig pushes Panel fields into the global layouts.PanelConfig.
-// Called once at Router() time so all templates see the correct values.
+// syncConfig builds the layouts.PanelConfig of the panel from its fields,
+// also set as the global one for code rendering outside of a request.
+// Called once at Router() time.
 func (p *Panel) syncConfig() {
-	layouts.SetPanelConfig(&layouts.PanelConfig{
+	themes, theme := p.panelThemes()
+	if len(themes) > 1 && p.themeStore == nil {
+		p.themeStore = NewMemoryThemeStore()
+	}
+	if p.colorModeStore == nil {
+		p.colorModeStore = NewMemoryColorModeStore()
+	}
+	if p.pageSizeStore == nil {
+		p.pageSizeStore = NewMemoryPageSizeStore()
+	}
+	p.config = &layouts.PanelConfig{
 		Name:              p.BrandName,
 		Path:              p.Path,
 		Logo:              p.Logo,
 		Favicon:           p.Favicon,
 		PrimaryColor:      p.PrimaryColor,
 		DarkMode:          p.DarkMode,
+		Theme:             theme,
+		Themes:            themes,
 		Registration:      p.Registration,
 		EmailVerification: p.EmailVerification,
 		PasswordReset:     p.PasswordReset,
 		Profile:           p.Profile,
 		Notifications:     p.Notifications,
-	})
+		Favorites:         p.favoritesEnabled(),
+		Stylesheets:       p.stylesheets,
+		Scripts:           p.scripts,
+		Switcher:          p.switcherGroups,
+	}
+	layouts.SetPanelConfig(p.config)
 }
 
 // AddResources adds a block of resources.
@@ -281,78 +477,129 @@ func (p *Panel) AddPages(pages ...Page) *Panel {
 	return p
 }
 
+// AddActions mounts standalone actions, each under the resource named by
+// actions.Action.ForResource, running the whole action lifecycle without a
+// handler of its own:
+//
+//	panel.AddActions(actions.New("send-invoice").
+//		ForResource("orders").
+//		WithSuccessMessage("Invoice sent.").
+//		Handle(sendInvoice))
+//
+// serves POST /orders/{id}/actions/send-invoice. Router() panics when an
+// action names no resource of the panel.
+func (p *Panel) AddActions(as ...*actions.Action) *Panel {
+	p.actions = append(p.actions, as...)
+	return p
+}
+
 // navItem is a unified type for navigation items (resources and pages)
 type navItem struct {
-	slug       string
-	label      string
-	icon       string
-	group      string
-	sort       int
-	badge      string // optional badge text (e.g. unread count)
-	badgeColor string // optional badge color ("green", "red", etc.)
+	layouts.NavItem
+	group string
+	sort  int
 }
 
 // registerNavItems injects navigation items into the sidebar.
 // Merges auto-generated groups (from Resources+Pages+NavItems) with manual NavGroups.
 func (p *Panel) registerNavItems() {
 	allItems := p.collectNavItems()
-	sort.Slice(allItems, func(i, j int) bool {
+	sort.SliceStable(allItems, func(i, j int) bool {
 		return allItems[i].sort < allItems[j].sort
 	})
-	autoGroups := groupNavItems(allItems)
+	autoGroups := groupNavItems(allItems, p.collapsibleNavGroups)
 	manualGroups := p.buildManualNavGroups()
-	layouts.SetNavGroups(append(autoGroups, manualGroups...))
+	if p.favoritesEnabled() {
+		// Pinned items come after the ungrouped items (the dashboard), first otherwise.
+		at := 0
+		if len(autoGroups) > 0 && autoGroups[0].Label == "" {
+			at = 1
+		}
+		autoGroups = slices.Insert(autoGroups, at, p.pinnedNavGroup())
+	}
+	p.navGroups = append(append([]layouts.NavGroup{}, autoGroups...), manualGroups...)
+	layouts.SetNavGroups(p.navGroups)
 }
 
 // collectNavItems builds the flat list of nav items from resources, pages, and manual NavItems.
 func (p *Panel) collectNavItems() []navItem {
 	items := make([]navItem, 0, len(p.Resources)+len(p.Pages)+len(p.NavItems))
 	for _, r := range p.Resources {
-		// Use background context for badge — nav is rendered server-side without request context
-		badge := r.Badge(context.Background())
-		badgeColor := r.BadgeColor(context.Background())
+		// Badges are computed on each request, with the request context.
 		items = append(items, navItem{
-			slug: r.Slug(), label: r.PluralLabel(),
-			icon: r.Icon(), group: r.Group(), sort: r.Sort(),
-			badge: badge, badgeColor: badgeColor,
+			NavItem: layouts.NavItem{
+				Slug: r.Slug(), Label: r.PluralLabel(), Icon: r.Icon(),
+				BadgeFunc: resourceBadge(r),
+			},
+			group: r.Group(), sort: r.Sort(),
 		})
 	}
 	for _, pg := range p.Pages {
 		items = append(items, navItem{
-			slug: pg.Slug(), label: pg.Label(),
-			icon: pg.Icon(), group: pg.Group(), sort: pg.Sort(),
+			NavItem: layouts.NavItem{Slug: pg.Slug(), Label: pg.Label(), Icon: pg.Icon()},
+			group:   pg.Group(), sort: pg.Sort(),
 		})
 	}
 	for _, ni := range p.NavItems {
-		items = append(items, navItem{
-			slug: ni.URL, label: ni.Label,
-			icon: ni.Icon, group: ni.Group, sort: ni.Sort,
-			badge: ni.Badge,
-		})
+		items = append(items, navItem{NavItem: toNavItem(ni), group: ni.Group, sort: ni.Sort})
 	}
 	return items
 }
 
-// registerNavItems injects navigation items + manual NavGroups into the sidebar.
+// resourceBadge returns the badge callback of a resource.
+func resourceBadge(r Resource) func(ctx context.Context) (string, string) {
+	return func(ctx context.Context) (string, string) {
+		badge := r.Badge(ctx)
+		if badge == "" {
+			return "", ""
+		}
+		return badge, r.BadgeColor(ctx)
+	}
+}
+
+// toNavItem converts a manual NavigationItem, with its sub-items.
+func toNavItem(ni NavigationItem) layouts.NavItem {
+	item := layouts.NavItem{
+		Slug:          ni.URL,
+		Label:         ni.Label,
+		Icon:          ni.Icon,
+		Badge:         ni.Badge,
+		BadgeColor:    ni.BadgeColor,
+		External:      ni.External,
+		ActivePattern: ni.ActiveRegex,
+		BadgeFunc:     ni.BadgeFunc,
+		VisibleWhen:   ni.VisibleWhen,
+	}
+	for _, child := range ni.Children {
+		item.Children = append(item.Children, toNavItem(child))
+	}
+	return item
+}
+
+// buildManualNavGroups converts the manual NavGroups, sorted by Sort.
 func (p *Panel) buildManualNavGroups() []layouts.NavGroup {
-	result := make([]layouts.NavGroup, 0, len(p.NavGroups))
-	for _, g := range p.NavGroups {
+	groups := append([]NavigationGroup(nil), p.NavGroups...)
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Sort < groups[j].Sort })
+	result := make([]layouts.NavGroup, 0, len(groups))
+	for _, g := range groups {
 		children := make([]layouts.NavItem, 0, len(g.Items))
 		for _, ni := range g.Items {
-			children = append(children, layouts.NavItem{
-				Slug:  ni.URL,
-				Label: ni.Label,
-				Icon:  ni.Icon,
-				Badge: ni.Badge,
-			})
+			children = append(children, toNavItem(ni))
 		}
-		result = append(result, layouts.NavGroup{Label: g.Label, Items: children})
+		result = append(result, layouts.NavGroup{
+			Label:       g.Label,
+			Icon:        g.Icon,
+			Items:       children,
+			Collapsible: g.Collapsible,
+			Collapsed:   g.Collapsible && !g.DefaultOpen,
+			VisibleWhen: g.VisibleWhen,
+		})
 	}
 	return result
 }
 
 // groupNavItems groups sorted nav items into NavGroups (root first, then named groups).
-func groupNavItems(allItems []navItem) []layouts.NavGroup {
+func groupNavItems(allItems []navItem, collapsible bool) []layouts.NavGroup {
 	grouped := make(map[string][]navItem)
 	for _, item := range allItems {
 		key := item.group
@@ -375,7 +622,7 @@ func groupNavItems(allItems []navItem) []layouts.NavGroup {
 	}
 	sort.Strings(groupNames)
 	for _, name := range groupNames {
-		navGroups = append(navGroups, layouts.NavGroup{Label: name, Items: toNavItems(grouped[name])})
+		navGroups = append(navGroups, layouts.NavGroup{Label: name, Items: toNavItems(grouped[name]), Collapsible: collapsible})
 	}
 	return navGroups
 }
@@ -384,48 +631,113 @@ func groupNavItems(allItems []navItem) []layouts.NavGroup {
 func toNavItems(items []navItem) []layouts.NavItem {
 	result := make([]layouts.NavItem, len(items))
 	for i, item := range items {
-		result[i] = layouts.NavItem{
-			Slug:       item.slug,
-			Label:      item.label,
-			Icon:       item.icon,
-			Badge:      item.badge,
-			BadgeColor: item.badgeColor,
-		}
+		result[i] = item.NavItem
 	}
 	return result
 }
 
 // Router generates the standard HTTP Handler with automatic CRUD.
-// It also calls syncConfig(), registerNavItems() and plugin.BootAll() exactly once.
+// It also boots the plugins (see RegisterExtension), then calls syncConfig()
+// and registerNavItems() exactly once.
 func (p *Panel) Router() http.Handler {
 	if err := p.runBeforeBoot(); err != nil {
 		panic("sublimeadmin: before_boot hook failed: " + err.Error())
 	}
-	p.syncConfig()
-	p.registerNavItems() // called once here after all resources/pages are added
-	if err := plugin.Boot(); err != nil {
+	if err := p.runExtensions(); err != nil {
 		panic("sublimeadmin: plugin boot failed: " + err.Error())
 	}
+	p.syncConfig()
+	p.registerNavItems() // called once here after all resources/pages are added
 	mux := http.NewServeMux()
 	p.registerStaticRoutes(mux)
 	p.registerAuthRoutes(mux)
 	p.registerCoreRoutes(mux)
+	p.checkActions()
 	p.registerResourceRoutes(mux)
 	p.registerPageRoutes(mux)
-	var handler http.Handler = p.injectConfig(mux)
+	p.registerPluginRoutes(mux)
+	if p.actionLimiter == nil {
+		p.actionLimiter = defaultActionLimiter()
+	}
+	var handler http.Handler = mux
+	if p.serverMetrics {
+		// Innermost, to label the requests with the pattern of the mux.
+		handler = middleware.Metrics()(handler)
+	}
+	if p.devicesEnabled() {
+		handler = p.rememberLogin(handler)
+	}
+	handler = p.injectConfig(handler)
+	if p.tenantResolver != nil {
+		handler = TenantMiddleware(p.tenantResolver, false)(handler)
+	}
+	flashManager := p.flashManager
+	if flashManager == nil && p.Session != nil {
+		flashManager = flash.NewManager(p.Session)
+	}
+	if flashManager != nil {
+		handler = middleware.Flash(flashManager)(handler)
+	}
 	if p.Session != nil {
 		handler = p.Session.LoadAndSave(handler)
 	}
-	handler = SecurityHeadersMiddleware(handler)
+	handler = middleware.SecurityHeadersWithConfig(p.securityHeaders)(handler)
 	if p.csrf != nil {
 		handler = p.csrf.Middleware(csrfTokenInjector(p.csrf, handler))
 	}
+	handler = p.errorHandler().ContextMiddleware()(handler)
+	if p.scim != nil {
+		// Outside the session and CSRF: the identity provider sends a token.
+		handler = p.scimRouter(handler)
+	}
+	handler = p.stripPath(handler)
 	if err := p.runAfterBoot(); err != nil {
 		panic("sublimeadmin: after_boot hook failed: " + err.Error())
 	}
 	return handler
 }
 
+// WithSecurityHeaders configures the security headers sent on every panel
+// response. Defaults to middleware.DefaultSecurityHeadersConfig().
+//
+//	cfg := middleware.DefaultSecurityHeadersConfig()
+//	cfg.CSP = middleware.DefaultCSP().ReportURI("/csp-report")
+//	cfg.HSTS = &middleware.HSTSConfig{MaxAge: 365 * 24 * time.Hour}
+//	panel.WithSecurityHeaders(cfg)
+func (p *Panel) WithSecurityHeaders(config *middleware.SecurityHeadersConfig) *Panel {
+	p.securityHeaders = config
+	return p
+}
+
+// WithErrorPage overrides the page rendered for an error status. Panels
+// render themed 403, 404, 419, 429 and 500 pages by default; other statuses
+// fall back to plain text. The component can read the error being rendered
+// with apperrors.ErrorFromContext(ctx).
+//
+//	panel.WithErrorPage(http.StatusNotFound, views.NotFound())
+func (p *Panel) WithErrorPage(statusCode int, page templ.Component) *Panel {
+	if p.errorPages == nil {
+		p.errorPages = make(map[int]templ.Component)
+	}
+	p.errorPages[statusCode] = page
+	return p
+}
+
+// errorHandler builds the error handler of the panel: the themed pages
+// with the WithErrorPage overrides applied. Every handler and middleware of
+// the panel renders its errors through it (see apperrors.Handle).
+func (p *Panel) errorHandler() *apperrors.Handler {
+	pages := errorViews.PanelPages()
+	for status, page := range p.errorPages {
+		pages[status] = page
+	}
+	opts := make([]apperrors.HandlerOption, 0, len(pages))
+	for status, page := range pages {
+		opts = append(opts, apperrors.WithErrorPage(status, page))
+	}
+	return apperrors.NewHandler(opts...)
+}
+
 // EnableCSRF enables CSRF protection for all mutating requests (POST/PUT/DELETE/PATCH).
 // Tokens are injected into context and accessible via engine.CSRFTokenFromContext(ctx).
 // Include them in forms with: <input type="hidden" name="_token" value={ engine.CSRFTokenFromContext(ctx) }/>
@@ -460,18 +772,19 @@ func csrfTokenInjector(m *CSRFManager, next http.Handler) http.Handler {
 }
 
 func (p *Panel) registerStaticRoutes(mux *http.ServeMux) {
-	fs := http.FileServer(http.FS(assets.FS))
-	// Always register at /assets/ — required for StripPrefix-mounted setups.
-	mux.Handle("/assets/", gzipMiddleware(cacheControlMiddleware(http.StripPrefix("/assets", fs))))
-
-	// When the panel has a non-root path AND is served directly (without an external
-	// http.StripPrefix), templates generate URLs like /admin/assets/css/output.css.
-	// Those requests would fall through to the "/" catch-all and return HTML instead of
-	// the actual CSS/JS file, causing catastrophic rendering.
-	// Registering a second handler at {Panel.Path}/assets/ fixes this.
-	if p.Path != "" && p.Path != "/" {
-		prefix := strings.TrimRight(p.Path, "/") + "/assets"
-		mux.Handle(prefix+"/", gzipMiddleware(cacheControlMiddleware(http.StripPrefix(prefix, fs))))
+	// Templates link to {Panel.Path}/assets/...; stripPath serves those here.
+	// Hashed URLs (see assets.URL) are cached forever, the others revalidated.
+	mux.Handle("/assets/", gzipMiddleware(http.StripPrefix("/assets", assets.Handler())))
+
+	// Icon sprite sheet, built now rather than on the first page
+	icons.Sprite()
+	mux.Handle(icons.SpritePath, gzipMiddleware(icons.SpriteHandler()))
+	p.registerPluginAssets(mux)
+
+	// Probes answer without a session: they are not behind protect.
+	if p.healthChecks != nil {
+		mux.Handle("/healthz", p.healthChecks.LivenessHandler())
+		mux.Handle("/readyz", p.healthChecks.ReadinessHandler())
 	}
 }
 
@@ -489,14 +802,14 @@ func (p *Panel) registerAuthRoutes(mux *http.ServeMux) {
 	mux.Handle("/login", middleware.RequireGuest(p.AuthManager, "/")(loginLimiter.Middleware()(authHandler)))
 	mux.Handle("/logout", authHandler)
 	if p.Registration {
-		mux.Handle("/register", middleware.RequireGuest(p.AuthManager, "/")(authHandler))
+		mux.Handle("/register", middleware.RequireGuest(p.AuthManager, "/")(p.guardPublicForm(authHandler)))
 	}
 	if p.Profile {
 		mux.Handle("/profile", gzipMiddleware(p.protect(NewProfileHandler(p.AuthManager, p.Users))))
 	}
 	if p.PasswordReset {
 		rh := NewPasswordResetHandler(p.AuthManager, p.Users, p.Mailer, p.BaseURL)
-		mux.Handle("/forgot-password", rh)
+		mux.Handle("/forgot-password", p.guardPublicForm(rh))
 		mux.Handle("/reset-password", rh)
 	}
 }
@@ -504,19 +817,53 @@ func (p *Panel) registerAuthRoutes(mux *http.ServeMux) {
 func (p *Panel) registerCoreRoutes(mux *http.ServeMux) {
 	// Dashboard
 	mux.Handle("/", gzipMiddleware(p.protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			apperrors.Handle(w, r, apperrors.NotFound(""))
+			return
+		}
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		cfg := layouts.GetPanelConfigFromContext(r.Context())
 		dashCfg := dashboard.DashboardConfig{
 			Title:       "Dashboard",
 			Description: "Bienvenue dans votre panneau d'administration — " + cfg.Name,
 		}
-		_ = dashboard.Index(dashCfg, widget.GetAllWidgets(r.Context())).Render(r.Context(), w)
+		widgets := widget.GetAllWidgets(r.Context())
+		if p.serverMetrics {
+			widgets = append(widgets, p.serverMetricsWidget(r.Context()))
+		}
+		_ = dashboard.Index(dashCfg, widgets).Render(r.Context(), w)
 	}))))
 	// Global search
 	mux.Handle("/api/search", p.protect(http.HandlerFunc(p.handleSearch)))
+	// Theme switcher
+	if len(p.config.Themes) > 1 {
+		mux.Handle("/api/theme", p.protect(http.HandlerFunc(p.handleTheme)))
+	}
+	// Server metrics
+	if p.serverMetrics {
+		mux.Handle("/system/metrics", gzipMiddleware(p.protect(http.HandlerFunc(p.handleServerMetrics))))
+	}
+	// Icon browser
+	if p.iconBrowser {
+		mux.Handle("/debug/icons", p.protect(http.HandlerFunc(p.handleIconBrowser)))
+	}
+	// Color mode toggle
+	mux.Handle("/api/color-mode", p.protect(http.HandlerFunc(p.handleColorMode)))
+	// Favorites
+	if p.favoritesEnabled() {
+		mux.Handle("/api/favorites", p.protect(http.HandlerFunc(p.handleFavorites)))
+	}
+	// Tenant switcher
+	if p.tenantSwitcher != nil {
+		mux.Handle("/api/tenant", p.protect(http.HandlerFunc(p.handleTenantSwitch)))
+	}
+	// Runtime log level (admins only, never mounted without authentication)
+	if p.AuthManager != nil {
+		mux.Handle("/api/log-level", p.protect(middleware.RequireAdmin(p.AuthManager)(logger.LevelHandler(nil))))
+	}
 	// Notifications
 	if p.Notifications {
-		notifHandler := notifications.NewHandler(nil, func(r *http.Request) string {
+		p.notifHandler = notifications.NewHandler(nil, func(r *http.Request) string {
 			if p.AuthManager != nil {
 				if id := p.AuthManager.UserIDFromRequest(r); id > 0 {
 					return fmt.Sprintf("%d", id)
@@ -524,7 +871,7 @@ func (p *Panel) registerCoreRoutes(mux *http.ServeMux) {
 			}
 			return ""
 		})
-		notifHandler.Register(mux, "/api/notifications")
+		p.notifHandler.Register(mux, "/api/notifications")
 	}
 }
 
@@ -535,12 +882,19 @@ func (p *Panel) handleSearch(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode([]search.Result{})
 		return
 	}
-	results, err := search.QuickSearch(r.Context(), query)
+	ctx := r.Context()
+	if t := TenantFromContext(ctx); t != nil {
+		ctx = search.WithNamespace(ctx, t.ID)
+	}
+	if p.searchAnalyzer != nil {
+		ctx = search.WithAnalyzer(ctx, p.searchAnalyzer)
+	}
+	results, err := search.QuickSearch(ctx, query)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, ""))
 		return
 	}
-	_ = json.NewEncoder(w).Encode(results)
+	_ = json.NewEncoder(w).Encode(append(p.navigationResults(ctx, query), results...))
 }
 
 func (p *Panel) registerResourceRoutes(mux *http.ServeMux) {
@@ -551,19 +905,40 @@ func (p *Panel) registerResourceRoutes(mux *http.ServeMux) {
 
 func (p *Panel) mountResource(mux *http.ServeMux, res Resource) {
 	slug := res.Slug()
-	h := gzipMiddleware(p.protect(NewCRUDHandler(res)))
+	crud := NewCRUDHandler(res)
+	crud.Jobs = p.jobs
+	crud.UnitOfWork = p.unitOfWork
+	for _, a := range p.actions {
+		if a.ResourceSlug == slug {
+			a.BasePath = strings.TrimRight(p.Path, "/")
+			crud.Actions = append(crud.Actions, a)
+		}
+	}
+	h := gzipMiddleware(p.protect(crud))
 	mux.Handle("/"+slug+"/", h)
 	mux.Handle("/"+slug, h)
 	mux.Handle("/"+slug+"/export", p.protect(NewExportHandler(res, export.FormatCSV)))
 	if _, ok := res.(ResourceImportable); ok {
 		mux.Handle("/"+slug+"/import", p.protect(NewImportHandler(res)))
 	}
-	if rm := NewRelationManagerHandler(res); rm.HasManagers() {
-		mux.Handle("/"+slug+"/relations/", p.protect(rm))
-	}
-	// Auto-register resource in global search if it implements search.Searchable.
+	// Auto-register resource in global search if it implements search.Searchable,
+	// in the namespace of the tenant of the panel if any.
 	if s, ok := res.(search.Searchable); ok {
-		search.Register(s)
+		_, scoped := res.(ResourceScoped)
+		if _, tenant := res.(TenantAware); scoped || (tenant && p.tenantScoper != nil) {
+			s = scopedSearchable{s, res}
+		}
+		search.RegisterIn(p.searchNamespace, s)
+	}
+}
+
+// checkActions panics when a standalone action names no resource of the
+// panel (see AddActions).
+func (p *Panel) checkActions() {
+	for _, a := range p.actions {
+		if !slices.ContainsFunc(p.Resources, func(res Resource) bool { return res.Slug() == a.ResourceSlug }) {
+			panic(fmt.Sprintf("sublimeadmin: action %q is not mounted: no resource %q (see actions.Action.ForResource)", a.Name, a.ResourceSlug))
+		}
 	}
 }
 
@@ -579,8 +954,17 @@ func (p *Panel) EnableDebug(mux *http.ServeMux) {
 	EnablePprof(mux)
 }
 
-// protect wraps a handler with auth + any custom middlewares.
+// protect wraps a handler with panic recovery, auth, the access check and
+// any custom middlewares. Recovery runs after auth so reported panics carry
+// the user.
 func (p *Panel) protect(h http.Handler) http.Handler {
+	h = middleware.Recovery(nil)(h)
+	if p.accessCheck != nil {
+		h = p.requireAccess(h)
+	}
+	if p.passwordPolicy != nil && p.passwordPolicy.MaxAge > 0 && p.Profile {
+		h = p.requireFreshPassword(h)
+	}
 	if p.AuthManager != nil {
 		h = middleware.RequireAuth(p.AuthManager)(h)
 	}
@@ -590,14 +974,63 @@ func (p *Panel) protect(h http.Handler) http.Handler {
 	return h
 }
 
+// stripPath serves the requests under the Path of the panel as if the panel
+// were at the root, so the router works whether it is mounted with
+// http.StripPrefix or not (see Serve).
+func (p *Panel) stripPath(next http.Handler) http.Handler {
+	prefix := strings.TrimRight(p.Path, "/")
+	if prefix == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != prefix && !strings.HasPrefix(r.URL.Path, prefix+"/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = strings.TrimPrefix(r.URL.Path, prefix)
+		if r2.URL.Path == "" {
+			r2.URL.Path = "/"
+		}
+		r2.URL.RawPath = ""
+		next.ServeHTTP(w, r2)
+	})
+}
+
 // injectConfig injects the Panel's PanelConfig and NavGroups into every request context.
 // This enables multi-panel setups where each panel has its own config and navigation.
 func (p *Panel) injectConfig(next http.Handler) http.Handler {
-	cfg := layouts.GetPanelConfig()
+	// Before Router, the panel has no configuration nor navigation of its own.
+	cfg := p.config
+	if cfg == nil {
+		cfg = layouts.GetPanelConfig()
+	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ctx := layouts.WithPanelConfig(r.Context(), cfg)
-		ctx = layouts.WithNavGroups(ctx, layouts.GetNavGroups(ctx))
-		next.ServeHTTP(w, r.WithContext(ctx))
+		ctx := context.WithValue(r.Context(), ContextKeyPanel, p)
+		ctx = layouts.WithPanelConfig(ctx, cfg)
+		navGroups := p.navGroups
+		if navGroups == nil {
+			navGroups = layouts.GetNavGroups(ctx)
+		}
+		ctx = layouts.WithNavGroups(ctx, navGroups)
+		ctx = layouts.WithCurrentPath(ctx, r.URL.Path)
+		ctx = icons.WithSprite(ctx, icons.SpriteURL(cfg.Path))
+		if p.actionLimiter != nil {
+			ctx = actions.WithLimiter(ctx, p.actionLimiter)
+		}
+		if len(p.renderHooks) > 0 {
+			ctx = layouts.WithHooks(ctx, p.renderHooks)
+		}
+		if len(cfg.Themes) > 1 {
+			ctx = layouts.WithTheme(ctx, p.userTheme(r.WithContext(ctx)))
+		}
+		if p.favoritesEnabled() {
+			ctx = p.withFavorites(ctx, r)
+		}
+		if p.passwordPolicy != nil {
+			ctx = auth.WithPasswordPolicy(ctx, p.passwordPolicy)
+		}
+		next.ServeHTTP(w, p.withColorMode(w, r.WithContext(ctx)))
 	})
 }
 
@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/apperrors"
+	"github.com/bozz33/sublimeadmin/ui/layouts"
+)
+
+// ColorModeCookieName is the cookie remembering the color mode of the
+// browser, used for guests and when the user has no saved preference.
+const ColorModeCookieName = "sublime_color_mode"
+
+// ColorModeStore persists the color mode (light, dark or system) chosen by
+// each user. Implementations must be safe for concurrent use; UserColorMode
+// is called on every request of an authenticated user, so keep it cheap.
+//
+// MemoryColorModeStore (default) keeps the preferences per process.
+type ColorModeStore interface {
+	// UserColorMode returns the color mode of the user, or "" if none is saved.
+	UserColorMode(ctx context.Context, userID int) (string, error)
+	SetUserColorMode(ctx context.Context, userID int, mode string) error
+}
+
+// MemoryColorModeStore is an in-process ColorModeStore.
+type MemoryColorModeStore struct {
+	mu    sync.RWMutex
+	modes map[int]string
+}
+
+// NewMemoryColorModeStore creates an empty in-memory store.
+func NewMemoryColorModeStore() *MemoryColorModeStore {
+	return &MemoryColorModeStore{modes: make(map[int]string)}
+}
+
+// UserColorMode implements ColorModeStore.
+func (s *MemoryColorModeStore) UserColorMode(_ context.Context, userID int) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.modes[userID], nil
+}
+
+// SetUserColorMode implements ColorModeStore.
+func (s *MemoryColorModeStore) SetUserColorMode(_ context.Context, userID int, mode string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.modes[userID] = mode
+	return nil
+}
+
+// WithColorModeStore sets the store of the color mode preferences of the
+// users. Defaults to a MemoryColorModeStore.
+func (p *Panel) WithColorModeStore(store ColorModeStore) *Panel {
+	p.colorModeStore = store
+	return p
+}
+
+// userColorMode returns the saved color mode of the request: the preference
+// of the authenticated user, else the color mode cookie.
+func (p *Panel) userColorMode(r *http.Request) (layouts.ColorMode, bool) {
+	if p.colorModeStore != nil && p.AuthManager != nil {
+		if id := p.AuthManager.UserIDFromRequest(r); id > 0 {
+			if name, err := p.colorModeStore.UserColorMode(r.Context(), id); err == nil && name != "" {
+				return layouts.ParseColorMode(name)
+			}
+		}
+	}
+	if c, err := r.Cookie(ColorModeCookieName); err == nil {
+		return layouts.ParseColorMode(c.Value)
+	}
+	return "", false
+}
+
+// withColorMode returns the request carrying the color mode of the user and
+// the color scheme of the browser, so the page is rendered with the right
+// class. Browsers send the scheme (Sec-CH-Prefers-Color-Scheme) once asked
+// with Accept-CH.
+func (p *Panel) withColorMode(w http.ResponseWriter, r *http.Request) *http.Request {
+	w.Header().Add("Accept-CH", "Sec-CH-Prefers-Color-Scheme")
+	w.Header().Add("Vary", "Sec-CH-Prefers-Color-Scheme")
+	ctx := layouts.WithSystemDark(r.Context(), r.Header.Get("Sec-CH-Prefers-Color-Scheme") == "dark")
+	if mode, ok := p.userColorMode(r); ok {
+		ctx = layouts.WithColorMode(ctx, mode)
+	}
+	return r.WithContext(ctx)
+}
+
+// handleColorMode saves the color mode chosen in the topbar toggle:
+// POST /api/color-mode with mode={light|dark|system}.
+func (p *Panel) handleColorMode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apperrors.Handle(w, r, apperrors.MethodNotAllowed(""))
+		return
+	}
+	mode, ok := layouts.ParseColorMode(r.FormValue("mode"))
+	if !ok {
+		apperrors.Handle(w, r, apperrors.BadRequest("unknown color mode"))
+		return
+	}
+	if p.colorModeStore != nil && p.AuthManager != nil {
+		if id := p.AuthManager.UserIDFromRequest(r); id > 0 {
+			if err := p.colorModeStore.SetUserColorMode(r.Context(), id, string(mode)); err != nil {
+				apperrors.Handle(w, r, apperrors.Internal(err, ""))
+				return
+			}
+		}
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     ColorModeCookieName,
+		Value:    string(mode),
+		Path:     "/",
+		MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestPanel_ColorMode(t *testing.T) {
+	h := NewPanel("color-mode-test").Router()
+	renders := func(body, class, mode string) bool {
+		return strings.Contains(body, `<html lang="en" class="`+class+`"`) &&
+			strings.Contains(body, `data-color-mode="`+mode+`"`)
+	}
+
+	// No preference: system mode, dark only when the browser says so
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := rec.Body.String()
+	if !renders(body, "h-full", "system") {
+		t.Error("expected the system mode without the dark class")
+	}
+	if !strings.Contains(body, `data-color-mode-option="light"`) {
+		t.Error("expected the color mode toggle in the topbar")
+	}
+	if rec.Header().Get("Accept-CH") != "Sec-CH-Prefers-Color-Scheme" {
+		t.Error("expected the color scheme client hint to be requested")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Sec-CH-Prefers-Color-Scheme", "dark")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if !renders(rec.Body.String(), "h-full dark", "system") {
+		t.Error("expected the dark class from the client hint")
+	}
+
+	// Switch
+	form := url.Values{"mode": {"dark"}}
+	req = httptest.NewRequest(http.MethodPost, "/api/color-mode", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != ColorModeCookieName || cookies[0].Value != "dark" {
+		t.Fatalf("expected the color mode cookie, got %v", cookies)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if !renders(rec.Body.String(), "h-full dark", "dark") {
+		t.Error("expected the saved mode rendered on the server")
+	}
+
+	// Light wins over the client hint
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: ColorModeCookieName, Value: "light"})
+	req.Header.Set("Sec-CH-Prefers-Color-Scheme", "dark")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if !renders(rec.Body.String(), "h-full", "light") {
+		t.Error("expected the light mode without the dark class")
+	}
+
+	// Unknown mode
+	form = url.Values{"mode": {"sepia"}}
+	req = httptest.NewRequest(http.MethodPost, "/api/color-mode", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown color mode, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,23 @@
+package engine
+
+import (
+	"net/http"
+
+	"github.com/bozz33/sublimeadmin/ui/icons"
+	"github.com/bozz33/sublimeadmin/ui/layouts"
+)
+
+// EnableIconBrowser mounts a page listing the SVG icons of every set
+// (icons.RegisterSet, icons.Register) at /debug/icons, behind the auth of
+// the panel. Handy in development to find the name of an icon.
+func (p *Panel) EnableIconBrowser(enabled bool) *Panel {
+	p.iconBrowser = enabled
+	return p
+}
+
+// handleIconBrowser renders the icon browser page.
+func (p *Panel) handleIconBrowser(w http.ResponseWriter, r *http.Request) {
+	ctx := layouts.WithBreadcrumbs(r.Context(), []layouts.Breadcrumb{{Label: "Icons"}})
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = layouts.Page("Icons", icons.DebugPage()).Render(ctx, w)
+}
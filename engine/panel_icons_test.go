@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/bozz33/sublimeadmin/ui/icons"
+)
+
+func TestPanel_Icons(t *testing.T) {
+	icons.RegisterSet("icontest", fstest.MapFS{
+		"rocket.svg":       {Data: []byte(`<?xml version="1.0"?><!-- rocket --><svg viewBox="0 0 24 24" data-icon="rocket"></svg>`)},
+		"arrow-upward.svg": {Data: []byte(`<svg viewBox="0 0 24 24" data-icon="arrow"></svg>`)},
+	})
+	icons.Register("icontest-logo", `<svg viewBox="0 0 10 10" data-icon="logo"></svg>`)
+
+	if svg, ok := icons.Lookup("icontest:rocket"); !ok || svg != `<svg viewBox="0 0 24 24" data-icon="rocket"></svg>` {
+		t.Errorf("expected the rocket of the set without the prolog, got %q", svg)
+	}
+	if _, ok := icons.Lookup("icontest:arrow_upward"); !ok {
+		t.Error("expected underscores to fall back to dashes")
+	}
+	if _, ok := icons.Lookup("material:rocket"); ok {
+		t.Error("expected the material prefix to force the font")
+	}
+	if _, ok := icons.Lookup("sublime:users"); !ok {
+		t.Error("expected the built-in SVG set")
+	}
+
+	h := NewPanel("icons-test").
+		WithNavItems(
+			NavigationItem{Label: "Launches", URL: "launches", Icon: "rocket", Sort: 1},
+			NavigationItem{Label: "Brand", URL: "brand", Icon: "icontest-logo", Sort: 2},
+			NavigationItem{Label: "People", URL: "people", Icon: "people", Sort: 3},
+		).
+		EnableIconBrowser(true).
+		Router()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`#i-icontest-rocket"></use>`,                                  // fallback chain: registered sets
+		`#i-custom-icontest-logo"></use>`,                             // custom SVGs
+		`<span class="material-icons-outlined text-xl">people</span>`, // font
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %s in the sidebar", want)
+		}
+	}
+	if strings.Contains(body, `data-icon="rocket"`) {
+		t.Error("expected the sidebar to reference the sprite instead of inlining the SVG")
+	}
+
+	// Sprite sheet
+	m := regexp.MustCompile(`<use href="([^"#]+)#`).FindStringSubmatch(body)
+	if m == nil {
+		t.Fatal("expected a sprite URL")
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, m[1], nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Header().Get("Cache-Control"), "immutable") {
+		t.Fatalf("expected the sprite with immutable caching, got %d %q", rec.Code, rec.Header().Get("Cache-Control"))
+	}
+	if !strings.Contains(rec.Body.String(), `<symbol id="i-icontest-rocket" viewBox="0 0 24 24"></symbol>`) {
+		t.Errorf("expected the rocket symbol in the sprite, got %s", rec.Body.String())
+	}
+	req := httptest.NewRequest(http.MethodGet, m[1], nil)
+	req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for a cached sprite, got %d", rec.Code)
+	}
+
+	_, before := icons.Sprite()
+	icons.Register("icontest-new", `<svg viewBox="0 0 24 24"></svg>`)
+	if _, after := icons.Sprite(); after == before {
+		t.Error("expected the sprite to be rebuilt when an icon is registered")
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/icons", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	body = rec.Body.String()
+	for _, want := range []string{"icontest:rocket", "icontest-logo", "sublime:users"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %s in the icon browser", want)
+		}
+	}
+}
@@ -1,10 +1,29 @@
 package engine
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+)
 
 // BootHook is a function called during panel boot lifecycle.
 type BootHook func(p *Panel) error
 
+// extensions are the boot hooks run by every panel; see RegisterExtension.
+var (
+	extensionsMu sync.RWMutex
+	extensions   []BootHook
+)
+
+// RegisterExtension registers a hook run by every panel when it boots,
+// after its BeforeBoot hooks and before the navigation and the routes are
+// built, so the hook can still add resources, pages, navigation items and
+// middleware. Package plugin registers the plugins this way.
+func RegisterExtension(fn BootHook) {
+	extensionsMu.Lock()
+	defer extensionsMu.Unlock()
+	extensions = append(extensions, fn)
+}
+
 // WithBeforeBoot registers a hook called before Router() boots the panel.
 // Multiple hooks are called in registration order.
 // Return a non-nil error to abort boot.
@@ -30,6 +49,20 @@ func (p *Panel) runBeforeBoot() error {
 	return nil
 }
 
+// runExtensions executes the hooks registered with RegisterExtension.
+func (p *Panel) runExtensions() error {
+	extensionsMu.RLock()
+	hooks := append([]BootHook(nil), extensions...)
+	extensionsMu.RUnlock()
+
+	for _, fn := range hooks {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // runAfterBoot executes all registered AfterBoot hooks.
 func (p *Panel) runAfterBoot() error {
 	for i, fn := range p.afterBootHooks {
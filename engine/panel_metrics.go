@@ -0,0 +1,32 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bozz33/sublimeadmin/ui/layouts"
+	widgetViews "github.com/bozz33/sublimeadmin/views/widgets"
+	"github.com/bozz33/sublimeadmin/widget"
+)
+
+// EnableServerMetrics records the traffic of the panel with
+// middleware.Metrics and shows it on the dashboard: throughput, p95 latency
+// and error rate of the last minutes, with a drill-down page per route at
+// /system/metrics, behind the auth of the panel. Do not wrap the panel in
+// middleware.Metrics as well: its requests would be counted twice.
+func (p *Panel) EnableServerMetrics(enabled bool) *Panel {
+	p.serverMetrics = enabled
+	return p
+}
+
+// serverMetricsWidget returns the server metrics widget of the dashboard.
+func (p *Panel) serverMetricsWidget(ctx context.Context) *widget.ServerMetricsWidget {
+	return widget.NewServerMetrics(nil).WithDetailsURL(PanelURL(ctx, "/system/metrics"))
+}
+
+// handleServerMetrics renders the drill-down page of the server metrics.
+func (p *Panel) handleServerMetrics(w http.ResponseWriter, r *http.Request) {
+	ctx := layouts.WithBreadcrumbs(r.Context(), []layouts.Breadcrumb{{Label: "Server metrics"}})
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = layouts.Page("Server metrics", widgetViews.ServerMetricsDetails(p.serverMetricsWidget(ctx))).Render(ctx, w)
+}
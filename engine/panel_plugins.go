@@ -0,0 +1,102 @@
+package engine
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+const (
+	// PluginAssetsPrefix is the URL prefix of the plugin assets:
+	// /assets/plugins/{name}/...
+	PluginAssetsPrefix = "/assets/plugins/"
+	// PluginRoutesPrefix is the URL prefix of the plugin routes:
+	// /p/{name}/...
+	PluginRoutesPrefix = "/p/"
+)
+
+var pluginNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// pluginMount is a file system or a handler mounted for a plugin.
+type pluginMount struct {
+	name    string
+	assets  fs.FS
+	handler http.Handler
+}
+
+// WithPluginAssets serves fsys under /assets/plugins/{name}/. Plugins embed
+// their CSS, JS and images with //go:embed and mount them here.
+func (p *Panel) WithPluginAssets(name string, fsys fs.FS) *Panel {
+	mustPluginName(name)
+	p.pluginAssets = append(p.pluginAssets, pluginMount{name: name, assets: fsys})
+	return p
+}
+
+// WithPluginRoutes mounts h under /p/{name}/, behind the panel
+// authentication and middleware. The prefix is stripped: h sees /p/blog/posts
+// as /posts.
+func (p *Panel) WithPluginRoutes(name string, h http.Handler) *Panel {
+	mustPluginName(name)
+	p.pluginRoutes = append(p.pluginRoutes, pluginMount{name: name, handler: h})
+	return p
+}
+
+// WithStylesheets adds stylesheets to the head of every panel page. Local
+// URLs ("/assets/...") are prefixed with the panel path.
+func (p *Panel) WithStylesheets(urls ...string) *Panel {
+	p.stylesheets = append(p.stylesheets, urls...)
+	return p
+}
+
+// WithScripts adds deferred scripts to every panel page. Local URLs
+// ("/assets/...") are prefixed with the panel path.
+func (p *Panel) WithScripts(urls ...string) *Panel {
+	p.scripts = append(p.scripts, urls...)
+	return p
+}
+
+// WithRenderHook adds a component to a render hook of the panel layout
+// (see layouts.RegisterHook), after the global ones:
+//
+//	panel.WithRenderHook(layouts.HookTopbarEnd, envBadge)
+func (p *Panel) WithRenderHook(name string, component templ.Component) *Panel {
+	if p.renderHooks == nil {
+		p.renderHooks = make(map[string][]templ.Component)
+	}
+	p.renderHooks[name] = append(p.renderHooks[name], component)
+	return p
+}
+
+func mustPluginName(name string) {
+	if !pluginNamePattern.MatchString(name) {
+		panic(fmt.Sprintf("sublimeadmin: invalid plugin name %q: use lowercase letters, digits, - and _", name))
+	}
+}
+
+// registerPluginAssets serves the plugin assets, at the root and under the
+// panel path like the core assets.
+func (p *Panel) registerPluginAssets(mux *http.ServeMux) {
+	prefixes := []string{""}
+	if p.Path != "" && p.Path != "/" {
+		prefixes = append(prefixes, strings.TrimRight(p.Path, "/"))
+	}
+	for _, m := range p.pluginAssets {
+		fileServer := http.FileServer(http.FS(m.assets))
+		for _, prefix := range prefixes {
+			path := prefix + PluginAssetsPrefix + m.name
+			mux.Handle(path+"/", gzipMiddleware(cacheControlMiddleware(http.StripPrefix(path, fileServer))))
+		}
+	}
+}
+
+// registerPluginRoutes mounts the plugin route groups.
+func (p *Panel) registerPluginRoutes(mux *http.ServeMux) {
+	for _, m := range p.pluginRoutes {
+		path := PluginRoutesPrefix + m.name
+		mux.Handle(path+"/", p.protect(http.StripPrefix(path, m.handler)))
+	}
+}
@@ -1,8 +1,11 @@
 package engine
 
 import (
+	"cmp"
+	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 )
 
@@ -73,9 +76,7 @@ func (r *Registry) MountAll(mux *http.ServeMux) {
 		if p.Path == "" {
 			panic(fmt.Sprintf("engine: panel %q has no Path set — call WithPath() before MountAll()", p.ID))
 		}
-		handler := p.Router()
-		mux.Handle(p.Path+"/", http.StripPrefix(p.Path, handler))
-		mux.Handle(p.Path, http.StripPrefix(p.Path, handler))
+		mountPanel(mux, p)
 	}
 }
 
@@ -83,3 +84,53 @@ func (r *Registry) MountAll(mux *http.ServeMux) {
 func MountAll(mux *http.ServeMux) {
 	globalRegistry.MountAll(mux)
 }
+
+// Serve returns a handler serving several panels in one process, each
+// under its own Path, with its own configuration and navigation:
+//
+//	admin := engine.NewPanel("admin").WithPath("/admin").AddResources(users)
+//	ops := engine.NewPanel("ops").WithPath("/ops").AddResources(jobs)
+//	http.ListenAndServe(":8080", engine.Serve(admin, ops))
+//
+// A panel without a Path (or at "/") receives the requests of no other
+// panel. Serve panics when two panels share a Path.
+func Serve(panels ...*Panel) http.Handler {
+	mux := http.NewServeMux()
+	seen := make(map[string]string, len(panels))
+	for _, p := range panels {
+		path := strings.TrimRight(p.Path, "/")
+		if other, ok := seen[path]; ok {
+			panic(fmt.Sprintf("engine: panels %q and %q are both served at %q", other, p.ID, cmp.Or(p.Path, "/")))
+		}
+		seen[path] = p.ID
+		mountPanel(mux, p)
+	}
+	return mux
+}
+
+// mountPanel mounts the router of the panel at its Path.
+func mountPanel(mux *http.ServeMux, p *Panel) {
+	handler := p.Router()
+	path := strings.TrimRight(p.Path, "/")
+	if path == "" {
+		mux.Handle("/", handler)
+		return
+	}
+	mux.Handle(path+"/", handler)
+	mux.Handle(path, handler)
+}
+
+// PanelURL returns the URL of a path of the panel serving the request of
+// ctx, e.g. "/users" → "/admin/users" for a panel at "/admin". Outside of a
+// panel, the path is returned unchanged.
+func PanelURL(ctx context.Context, path string) string {
+	p := GetPanelFromContext(ctx)
+	if p == nil {
+		return path
+	}
+	base := strings.TrimRight(p.Path, "/")
+	if base == "" {
+		return path
+	}
+	return base + path
+}
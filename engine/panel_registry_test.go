@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestServe_MultiplePanels(t *testing.T) {
+	users := newMockResource("users")
+	jobs := newMockResource("jobs")
+	h := Serve(
+		NewPanel("serve-admin").WithPath("/admin").WithBrandName("Admin Panel").AddResources(users),
+		NewPanel("serve-ops").WithPath("/ops").WithBrandName("Ops Panel").AddResources(jobs),
+	)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/users", nil))
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if !strings.Contains(body, "Admin Panel") || strings.Contains(body, "Ops Panel") {
+		t.Error("expected the configuration of the admin panel")
+	}
+	if !strings.Contains(body, `href="/admin/users"`) || strings.Contains(body, `href="/ops/jobs"`) {
+		t.Error("expected the navigation of the admin panel only")
+	}
+	if !strings.Contains(body, "/admin/assets/") {
+		t.Error("expected the assets under the panel path")
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ops/jobs", nil))
+	body = rec.Body.String()
+	if !strings.Contains(body, "Ops Panel") || !strings.Contains(body, `href="/ops/jobs"`) || strings.Contains(body, `href="/admin/users"`) {
+		t.Error("expected the configuration and navigation of the ops panel")
+	}
+
+	rec = serveWith(h, http.MethodPost, "/admin/users/7", url.Values{"_method": {"DELETE"}})
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/admin/users" {
+		t.Errorf("expected a redirect to /admin/users, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if users.deleteCalledWith != "7" {
+		t.Errorf("expected user 7 deleted, got %q", users.deleteCalledWith)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ops/assets/styles.css", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected the assets served under /ops, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/billing/users", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 outside of the panels, got %d", rec.Code)
+	}
+}
+
+func TestServe_DuplicatePath(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for two panels at the same path")
+		}
+	}()
+	Serve(NewPanel("serve-dup-a").WithPath("/dup"), NewPanel("serve-dup-b").WithPath("/dup/"))
+}
+
+func TestPanelURL(t *testing.T) {
+	if got := PanelURL(context.Background(), "/users"); got != "/users" {
+		t.Errorf("outside of a panel, got %q", got)
+	}
+	ctx := context.WithValue(context.Background(), ContextKeyPanel, NewPanel("url-test").WithPath("/admin/"))
+	if got := PanelURL(ctx, "/users/1/edit"); got != "/admin/users/1/edit" {
+		t.Errorf("got %q", got)
+	}
+	ctx = context.WithValue(context.Background(), ContextKeyPanel, NewPanel("url-root").WithPath("/"))
+	if got := PanelURL(ctx, "/users"); got != "/users" {
+		t.Errorf("at the root, got %q", got)
+	}
+}
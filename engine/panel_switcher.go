@@ -0,0 +1,149 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/bozz33/sublimeadmin/apperrors"
+	"github.com/bozz33/sublimeadmin/ui/layouts"
+)
+
+// WithTenantResolver resolves the tenant of each request of the panel (see
+// TenantMiddleware); requests without a tenant are served without one.
+// Resources read it with TenantFromContext.
+func (p *Panel) WithTenantResolver(resolver TenantResolver) *Panel {
+	p.tenantResolver = resolver
+	return p
+}
+
+// WithTenantSwitcher lists in the topbar switcher the tenants the current
+// user can access. Choosing one sends the user to its URL, built by the
+// tenant resolver when it implements TenantURLBuilder, else to the panel
+// with ?tenant={id}.
+//
+//	panel.WithTenantResolver(engine.NewSubdomainResolver("example.com")).
+//	    WithTenantSwitcher(func(ctx context.Context) ([]*engine.Tenant, error) {
+//	        return memberships.TenantsOf(ctx, authManager.UserID(ctx))
+//	    })
+func (p *Panel) WithTenantSwitcher(fn func(ctx context.Context) ([]*Tenant, error)) *Panel {
+	p.tenantSwitcher = fn
+	return p
+}
+
+// WithAccessCheck restricts the panel to the users for which fn returns
+// true; the others get a 403 and the panel is hidden from their panel
+// switcher. fn runs after authentication.
+func (p *Panel) WithAccessCheck(fn func(ctx context.Context) bool) *Panel {
+	p.accessCheck = fn
+	return p
+}
+
+// CanAccess reports whether the user of ctx can access the panel.
+func (p *Panel) CanAccess(ctx context.Context) bool {
+	return p.accessCheck == nil || p.accessCheck(ctx)
+}
+
+// requireAccess rejects the users failing the access check of the panel.
+func (p *Panel) requireAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !p.CanAccess(r.Context()) {
+			apperrors.Handle(w, r, apperrors.Forbidden(""))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// switcherGroups returns the sections of the topbar switcher: the panels of
+// the registry (when the panel is registered) and the tenants the user can
+// access, each shown only when there is more than one to choose from.
+func (p *Panel) switcherGroups(ctx context.Context) []layouts.SwitcherGroup {
+	var groups []layouts.SwitcherGroup
+	if items := p.panelSwitcherItems(ctx); len(items) > 1 {
+		groups = append(groups, layouts.SwitcherGroup{Label: "Panels", Items: items})
+	}
+	if items := p.tenantSwitcherItems(ctx); len(items) > 1 {
+		groups = append(groups, layouts.SwitcherGroup{Label: "Tenants", Items: items})
+	}
+	return groups
+}
+
+func (p *Panel) panelSwitcherItems(ctx context.Context) []layouts.SwitcherItem {
+	if Get(p.ID) != p {
+		return nil
+	}
+	panels := All()
+	sort.Slice(panels, func(i, j int) bool { return panels[i].Path < panels[j].Path })
+	var items []layouts.SwitcherItem
+	for _, other := range panels {
+		if other != p && !other.CanAccess(ctx) {
+			continue
+		}
+		items = append(items, layouts.SwitcherItem{
+			Label:  other.BrandName,
+			URL:    strings.TrimRight(other.Path, "/") + "/",
+			Icon:   "dashboard",
+			Active: other == p,
+		})
+	}
+	return items
+}
+
+func (p *Panel) tenantSwitcherItems(ctx context.Context) []layouts.SwitcherItem {
+	if p.tenantSwitcher == nil {
+		return nil
+	}
+	tenants, err := p.tenantSwitcher(ctx)
+	if err != nil {
+		return nil
+	}
+	current := TenantFromContext(ctx)
+	items := make([]layouts.SwitcherItem, 0, len(tenants))
+	for _, t := range tenants {
+		label := t.Name
+		if label == "" {
+			label = t.ID
+		}
+		items = append(items, layouts.SwitcherItem{
+			Label:  label,
+			URL:    strings.TrimRight(p.Path, "/") + "/api/tenant?" + url.Values{"id": {t.ID}}.Encode(),
+			Icon:   "apartment",
+			Active: current != nil && current.ID == t.ID,
+		})
+	}
+	return items
+}
+
+// handleTenantSwitch sends the user to the tenant chosen in the topbar
+// switcher: GET /api/tenant?id={id}. Only the tenants listed by the tenant
+// switcher of the user are allowed.
+func (p *Panel) handleTenantSwitch(w http.ResponseWriter, r *http.Request) {
+	tenants, err := p.tenantSwitcher(r.Context())
+	if err != nil {
+		apperrors.Handle(w, r, apperrors.Internal(err, ""))
+		return
+	}
+	id := r.URL.Query().Get("id")
+	var tenant *Tenant
+	for _, t := range tenants {
+		if t.ID == id {
+			tenant = t
+		}
+	}
+	if tenant == nil {
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
+		return
+	}
+	path := strings.TrimRight(p.Path, "/") + "/"
+	target := ""
+	if b, ok := p.tenantResolver.(TenantURLBuilder); ok {
+		target = b.TenantURL(r, tenant, path)
+	}
+	if target == "" {
+		target = path + "?" + url.Values{"tenant": {tenant.ID}}.Encode()
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
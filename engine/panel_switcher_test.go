@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTenantURLBuilders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://acme.example.com:8080/admin/", nil)
+	acme := &Tenant{ID: "acme", Subdomain: "acme"}
+	globex := &Tenant{ID: "globex", Subdomain: "globex", Domain: "globex.io"}
+
+	tests := []struct {
+		name    string
+		builder TenantURLBuilder
+		tenant  *Tenant
+		want    string
+	}{
+		{"subdomain", NewSubdomainResolver("example.com"), acme, "http://acme.example.com:8080/admin/"},
+		{"domain", NewSubdomainResolver("example.com"), globex, "http://globex.io:8080/admin/"},
+		{"path", NewPathResolver(), acme, "/acme/admin/"},
+		{"chained query", NewChainedTenantResolver(nil, 0, NewHeaderContrib("X-Tenant"), NewQueryContrib("t")), acme, "/admin/?t=acme"},
+		{"chained none", NewChainedTenantResolver(nil, 0, NewHeaderContrib("X-Tenant")), acme, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.builder.TenantURL(req, tt.tenant, "/admin/"); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+
+	req.TLS = &tls.ConnectionState{}
+	if got := NewSubdomainResolver("example.com").TenantURL(req, acme, "/"); got != "https://acme.example.com:8080/" {
+		t.Errorf("expected the https scheme, got %q", got)
+	}
+}
+
+// queryTenantResolver resolves the tenant of the ?tenant= parameter.
+type queryTenantResolver map[string]*Tenant
+
+func (q queryTenantResolver) Resolve(r *http.Request) (*Tenant, bool) {
+	t, ok := q[r.URL.Query().Get("tenant")]
+	return t, ok
+}
+
+func TestPanel_TenantSwitcher(t *testing.T) {
+	tenants := queryTenantResolver{
+		"acme":   {ID: "acme", Name: "Acme"},
+		"globex": {ID: "globex", Name: "Globex"},
+	}
+	h := NewPanel("tenant-switcher-test").
+		WithTenantResolver(tenants).
+		WithTenantSwitcher(func(ctx context.Context) ([]*Tenant, error) {
+			return []*Tenant{tenants["acme"], tenants["globex"]}, nil
+		}).
+		Router()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?tenant=globex", nil))
+	body := rec.Body.String()
+	if !strings.Contains(body, "/api/tenant?id=acme") {
+		t.Error("expected the tenants in the topbar switcher")
+	}
+	if !strings.Contains(body, `<span class="max-w-[10rem] truncate">Globex</span>`) {
+		t.Error("expected the current tenant on the switcher button")
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tenant?id=acme", nil))
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/?tenant=acme" {
+		t.Errorf("expected a redirect to the tenant, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tenant?id=initech", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 for a tenant not listed, got %d", rec.Code)
+	}
+}
+
+func TestPanel_AccessCheck(t *testing.T) {
+	allowed := false
+	p := NewPanel("access-check-test").WithAccessCheck(func(ctx context.Context) bool { return allowed })
+	h := p.Router()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", rec.Code)
+	}
+
+	allowed = true
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", rec.Code)
+	}
+}
+
+func TestPanel_PanelSwitcher(t *testing.T) {
+	admin := NewPanel("switcher-admin").WithPath("/switcher-admin").WithBrandName("Admin")
+	vendor := NewPanel("switcher-vendor").WithPath("/switcher-vendor").WithBrandName("Vendor")
+	hidden := NewPanel("switcher-hidden").WithPath("/switcher-hidden").
+		WithAccessCheck(func(ctx context.Context) bool { return false })
+	Register(admin)
+	Register(vendor)
+	Register(hidden)
+
+	var labels []string
+	for _, g := range admin.switcherGroups(context.Background()) {
+		for _, item := range g.Items {
+			if strings.HasPrefix(item.URL, "/switcher-") {
+				labels = append(labels, item.Label+"="+item.URL)
+				if item.Active != (item.Label == "Admin") {
+					t.Errorf("expected only the current panel active, got %+v", item)
+				}
+			}
+		}
+	}
+	if got := strings.Join(labels, " "); got != "Admin=/switcher-admin/ Vendor=/switcher-vendor/" {
+		t.Errorf("expected the accessible panels, got %q", got)
+	}
+
+	if groups := NewPanel("switcher-unregistered").switcherGroups(context.Background()); len(groups) != 0 {
+		t.Errorf("expected no switcher for an unregistered panel, got %v", groups)
+	}
+}
@@ -4,8 +4,14 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/a-h/templ"
+
+	"github.com/bozz33/sublimeadmin/health"
+	"github.com/bozz33/sublimeadmin/metrics"
+	"github.com/bozz33/sublimeadmin/scim"
 	"github.com/bozz33/sublimeadmin/ui/layouts"
 )
 
@@ -211,3 +217,206 @@ func TestPanel_WithNavGroups_Context(t *testing.T) {
 		t.Errorf("expected G1, got %s", got[0].Label)
 	}
 }
+
+func TestPanel_ErrorPages(t *testing.T) {
+	h := NewPanel("errors").
+		WithErrorPage(http.StatusNotFound, templ.Raw("<h1>custom not found</h1>")).
+		Router()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "custom not found") {
+		t.Errorf("expected the overridden 404 page, got %q", w.Body.String())
+	}
+
+	// API clients still get problem details.
+	r := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	r.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("expected problem+json, got %q", ct)
+	}
+}
+
+func TestPanel_DefaultErrorPages(t *testing.T) {
+	h := NewPanel("default-errors").Router()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "Page Not Found") || !strings.Contains(body, "id=\"main-content\"") {
+		t.Errorf("expected the themed 404 page inside the panel layout")
+	}
+}
+
+func TestPanel_Navigation_Resolve(t *testing.T) {
+	type roleKey struct{}
+	isAdmin := func(ctx context.Context) bool { return ctx.Value(roleKey{}) == "admin" }
+
+	p := NewPanel("nav-test").WithPath("/admin").
+		WithNavItems(
+			NavigationItem{Label: "Orders", URL: "orders", Sort: 1, BadgeFunc: func(ctx context.Context) (string, string) {
+				return "3", "danger"
+			}},
+			NavigationItem{Label: "Logs", URL: "logs", Sort: 2, VisibleWhen: isAdmin},
+			NavigationItem{Label: "Docs", URL: "https://example.com/docs", Sort: 3},
+		).
+		WithNavGroups(NavigationGroup{
+			Label: "Settings", Collapsible: true,
+			Items: []NavigationItem{{Label: "Billing", Children: []NavigationItem{
+				{Label: "Plans", URL: "plans"},
+				{Label: "Invoices", URL: "invoices", VisibleWhen: isAdmin},
+			}}},
+		}, NavigationGroup{Label: "Admin", VisibleWhen: isAdmin, Items: []NavigationItem{{Label: "Users", URL: "users"}}})
+	p.syncConfig()
+	p.registerNavItems()
+
+	ctx := layouts.WithPanelConfig(context.Background(), layouts.GetPanelConfig())
+	ctx = layouts.WithCurrentPath(ctx, "/admin/plans/42")
+	groups := layouts.ResolveNavGroups(ctx, layouts.GetNavGroups(ctx))
+
+	if len(groups) != 2 {
+		t.Fatalf("expected the root and Settings groups for a guest, got %d", len(groups))
+	}
+	root := groups[0].Items
+	if len(root) != 2 || root[0].Label != "Orders" || root[1].Label != "Docs" {
+		t.Fatalf("expected Orders and Docs, got %+v", root)
+	}
+	if root[0].Badge != "3" || root[0].BadgeColor != "danger" {
+		t.Errorf("expected the computed badge, got %q %q", root[0].Badge, root[0].BadgeColor)
+	}
+	settings := groups[1]
+	if !settings.Collapsible || !settings.Collapsed {
+		t.Error("expected a collapsible group folded by default")
+	}
+	billing := settings.Items[0]
+	if len(billing.Children) != 1 || !billing.Children[0].Active || !billing.Active {
+		t.Errorf("expected the active Plans sub-item to activate Billing, got %+v", billing)
+	}
+
+	ctx = context.WithValue(ctx, roleKey{}, "admin")
+	groups = layouts.ResolveNavGroups(ctx, layouts.GetNavGroups(ctx))
+	if len(groups) != 3 || len(groups[0].Items) != 3 || len(groups[1].Items[0].Children) != 2 {
+		t.Errorf("expected the admin items to be visible, got %+v", groups)
+	}
+}
+
+func TestPanel_Navigation_Sidebar(t *testing.T) {
+	h := NewPanel("sidebar-test").
+		WithNavItems(NavigationItem{Label: "Docs", URL: "https://example.com/docs"}).
+		WithNavGroups(NavigationGroup{Label: "Reports", Icon: "bar_chart", Collapsible: true, Items: []NavigationItem{
+			{Label: "Sales", URL: "reports/sales", Badge: "new", BadgeColor: "green"},
+		}}).
+		Router()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`href="https://example.com/docs" target="_blank" rel="noopener noreferrer"`,
+		`localStorage.getItem(&#39;sidebar.navgroup_reports&#39;)`,
+		`href="/reports/sales"`,
+		"bg-green-100",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %s in the sidebar", want)
+		}
+	}
+}
+
+func TestPanel_RenderHooks(t *testing.T) {
+	layouts.RegisterHook(layouts.HookSidebarFooter, templ.Raw(`<p id="global-hook">v1.2.0</p>`))
+	defer layouts.ResetHooks(layouts.HookSidebarFooter)
+
+	h := NewPanel("hooks-test").
+		WithRenderHook(layouts.HookTopbarEnd, templ.Raw(`<span id="panel-hook">staging</span>`)).
+		WithRenderHook(layouts.HookBodyEnd, templ.Raw(`<div id="body-hook"></div>`)).
+		Router()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := rec.Body.String()
+	for _, want := range []string{`id="global-hook"`, `id="panel-hook"`, `id="body-hook"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %s in the page", want)
+		}
+	}
+	if n := strings.Count(body, `id="global-hook"`); n != 2 {
+		t.Errorf("expected the sidebar footer in the desktop and mobile sidebars, got %d", n)
+	}
+
+	// Panel hooks stay scoped to their panel.
+	rec = httptest.NewRecorder()
+	NewPanel("hooks-other").Router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if strings.Contains(rec.Body.String(), `id="panel-hook"`) {
+		t.Error("expected the hooks of a panel not to leak into another one")
+	}
+}
+
+func TestPanel_HealthChecks(t *testing.T) {
+	down := func(context.Context) error { return context.DeadlineExceeded }
+	checks := health.NewRegistry().
+		Register("process", func(context.Context) error { return nil }, health.Liveness()).
+		Register("db", down)
+	h := NewPanel("health-test").WithHealthChecks(checks).Router()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"process"`) {
+		t.Errorf("/healthz = %d %s, want the liveness report", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), `"db"`) {
+		t.Errorf("/readyz = %d %s, want the failed readiness report", rec.Code, rec.Body)
+	}
+}
+
+func TestPanel_ServerMetrics(t *testing.T) {
+	metrics.HTTPWindow.Reset()
+	h := NewPanel("metrics-test").EnableServerMetrics(true).Router()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !strings.Contains(rec.Body.String(), "p95 latency") || !strings.Contains(rec.Body.String(), `href="/system/metrics"`) {
+		t.Error("expected the server metrics widget on the dashboard")
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/system/metrics", nil))
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "No requests recorded yet") {
+		t.Errorf("/system/metrics = %d, want the traffic of the dashboard route", rec.Code)
+	}
+}
+
+func TestPanel_SCIM(t *testing.T) {
+	store := scim.NewMemoryStore()
+	p := NewPanel("scim-test").WithSCIM(store, "s3cret").EnableCSRF()
+	p.Path = "/admin"
+	h := p.Router()
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/scim/v2/Users", strings.NewReader(`{"userName": "ann@example.com"}`))
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /scim/v2/Users = %d %s, want 201 without a CSRF token", rec.Code, rec.Body)
+	}
+	if loc := rec.Header().Get("Location"); loc != "http://example.com/admin/scim/v2/Users/1" {
+		t.Errorf("Location = %q", loc)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/scim/v2/Users", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /scim/v2/Users without a token = %d, want 401", rec.Code)
+	}
+}
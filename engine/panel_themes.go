@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/apperrors"
+	"github.com/bozz33/sublimeadmin/ui/layouts"
+)
+
+// ThemeCookieName is the cookie remembering the theme of the browser, used
+// for guests and when the user has no saved preference.
+const ThemeCookieName = "sublime_theme"
+
+// ThemeStore persists the theme chosen by each user.
+// Implementations must be safe for concurrent use; UserTheme is called on
+// every request of an authenticated user, so keep it cheap.
+//
+// MemoryThemeStore (default) keeps the preferences per process. Implement
+// ThemeStore on top of your users table to keep them across restarts.
+type ThemeStore interface {
+	// UserTheme returns the theme of the user, or "" if none is saved.
+	UserTheme(ctx context.Context, userID int) (string, error)
+	SetUserTheme(ctx context.Context, userID int, theme string) error
+}
+
+// MemoryThemeStore is an in-process ThemeStore.
+type MemoryThemeStore struct {
+	mu     sync.RWMutex
+	themes map[int]string
+}
+
+// NewMemoryThemeStore creates an empty in-memory store.
+func NewMemoryThemeStore() *MemoryThemeStore {
+	return &MemoryThemeStore{themes: make(map[int]string)}
+}
+
+// UserTheme implements ThemeStore.
+func (s *MemoryThemeStore) UserTheme(_ context.Context, userID int) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.themes[userID], nil
+}
+
+// SetUserTheme implements ThemeStore.
+func (s *MemoryThemeStore) SetUserTheme(_ context.Context, userID int, theme string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.themes[userID] = theme
+	return nil
+}
+
+// WithThemes sets the themes the users can switch between from the topbar;
+// the first one is the default unless WithTheme is called.
+//
+//	panel.WithThemes(layouts.BuiltInThemes()...)
+//	panel.WithThemes(layouts.DefaultTheme, layouts.Theme{
+//	    Name: "brand", Label: "Brand", Font: "'Nunito', sans-serif",
+//	    FontURL: "https://fonts.googleapis.com/css2?family=Nunito:wght@400;600;700",
+//	    Radius: "0.375rem",
+//	    Light: layouts.ThemeColors{Primary: "#0f766e", Gray: "stone"},
+//	})
+func (p *Panel) WithThemes(themes ...layouts.Theme) *Panel {
+	p.themes = append(p.themes, themes...)
+	return p
+}
+
+// WithTheme sets the default theme. The name of a built-in theme is enough:
+// panel.WithTheme("ocean") adds layouts.OceanTheme to the themes.
+func (p *Panel) WithTheme(name string) *Panel {
+	p.theme = name
+	return p
+}
+
+// WithThemeStore sets the store of the theme preferences of the users.
+// Defaults to a MemoryThemeStore.
+func (p *Panel) WithThemeStore(store ThemeStore) *Panel {
+	p.themeStore = store
+	return p
+}
+
+// panelThemes returns the themes of the panel and the default theme.
+func (p *Panel) panelThemes() ([]layouts.Theme, string) {
+	themes := append([]layouts.Theme(nil), p.themes...)
+	has := func(name string) bool {
+		for _, t := range themes {
+			if t.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	if p.theme != "" && !has(p.theme) {
+		for _, t := range layouts.BuiltInThemes() {
+			if t.Name == p.theme {
+				themes = append(themes, t)
+			}
+		}
+	}
+	def := p.theme
+	if def == "" && len(themes) > 0 {
+		def = themes[0].Name
+	}
+	return themes, def
+}
+
+// userTheme returns the saved theme of the request: the preference of the
+// authenticated user, else the theme cookie.
+func (p *Panel) userTheme(r *http.Request) string {
+	if p.themeStore != nil && p.AuthManager != nil {
+		if id := p.AuthManager.UserIDFromRequest(r); id > 0 {
+			if name, err := p.themeStore.UserTheme(r.Context(), id); err == nil && name != "" {
+				return name
+			}
+		}
+	}
+	if c, err := r.Cookie(ThemeCookieName); err == nil {
+		return c.Value
+	}
+	return ""
+}
+
+// handleTheme saves the theme chosen in the topbar switcher:
+// POST /api/theme with theme={name}.
+func (p *Panel) handleTheme(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apperrors.Handle(w, r, apperrors.MethodNotAllowed(""))
+		return
+	}
+	name := r.FormValue("theme")
+	if _, ok := layouts.GetPanelConfigFromContext(r.Context()).FindTheme(name); !ok {
+		apperrors.Handle(w, r, apperrors.BadRequest("unknown theme"))
+		return
+	}
+	if p.themeStore != nil && p.AuthManager != nil {
+		if id := p.AuthManager.UserIDFromRequest(r); id > 0 {
+			if err := p.themeStore.SetUserTheme(r.Context(), id, name); err != nil {
+				apperrors.Handle(w, r, apperrors.Internal(err, ""))
+				return
+			}
+		}
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     ThemeCookieName,
+		Value:    name,
+		Path:     "/",
+		MaxAge:   int((365 * 24 * time.Hour).Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/bozz33/sublimeadmin/ui/layouts"
+)
+
+func TestPanel_Themes_SyncConfig(t *testing.T) {
+	p := NewPanel("themes-test").WithThemes(layouts.DefaultTheme, layouts.OceanTheme)
+	p.syncConfig()
+	cfg := layouts.GetPanelConfig()
+	if cfg.Theme != "default" {
+		t.Errorf("expected the first theme as default, got %q", cfg.Theme)
+	}
+	if len(cfg.Themes) != 2 {
+		t.Errorf("expected 2 themes, got %d", len(cfg.Themes))
+	}
+	if p.themeStore == nil {
+		t.Error("expected a memory theme store with several themes")
+	}
+
+	// A built-in theme name is enough.
+	p = NewPanel("themes-test").WithTheme("grape")
+	p.syncConfig()
+	cfg = layouts.GetPanelConfig()
+	if cfg.Theme != "grape" || len(cfg.Themes) != 1 || cfg.Themes[0].Name != "grape" {
+		t.Errorf("expected the grape theme, got %q %v", cfg.Theme, cfg.Themes)
+	}
+}
+
+func TestTheme_CSS(t *testing.T) {
+	if css := layouts.DefaultTheme.CSS(); css != "" {
+		t.Errorf("expected no override for the default theme, got %q", css)
+	}
+
+	css := layouts.OceanTheme.CSS()
+	for _, want := range []string{
+		`:root[data-theme="ocean"]{`,
+		"--color-primary-500:#3b82f6",
+		"--primary-500:#3b82f6",
+		"--color-gray-900:#0f172a",
+		"--color-info-500:#06b6d4",
+		"--color-blue-500:#06b6d4",
+		"--radius-lg:0.75rem",
+	} {
+		if !strings.Contains(css, want) {
+			t.Errorf("expected %q in %s", want, css)
+		}
+	}
+
+	css = layouts.CompactTheme.CSS()
+	if !strings.Contains(css, "--spacing:0.225rem") || !strings.Contains(css, "--font-sans:ui-sans-serif") {
+		t.Errorf("expected density and font variables, got %s", css)
+	}
+
+	css = layouts.GrapeTheme.CSS()
+	if !strings.Contains(css, `:root.dark[data-theme="grape"]{--primary-50:`) {
+		t.Errorf("expected dark mode overrides, got %s", css)
+	}
+}
+
+func TestPanel_ThemeSwitch(t *testing.T) {
+	h := NewPanel("switch-test").WithThemes(layouts.BuiltInThemes()...).WithTheme("ocean").Router()
+
+	// Default theme
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	body, _ := io.ReadAll(rec.Body)
+	if !strings.Contains(string(body), `data-theme="ocean"`) {
+		t.Error("expected the default theme on the html element")
+	}
+	if !strings.Contains(string(body), `:root[data-theme="forest"]`) {
+		t.Error("expected the CSS of every theme for runtime switching")
+	}
+	if !strings.Contains(string(body), `data-theme-option="compact"`) {
+		t.Error("expected the theme switcher in the topbar")
+	}
+
+	// Switch
+	form := url.Values{"theme": {"forest"}}
+	req := httptest.NewRequest(http.MethodPost, "/api/theme", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != ThemeCookieName || cookies[0].Value != "forest" {
+		t.Fatalf("expected the theme cookie, got %v", cookies)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookies[0])
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if !strings.Contains(rec.Body.String(), `data-theme="forest"`) {
+		t.Error("expected the saved theme on the html element")
+	}
+
+	// Unknown theme
+	form = url.Values{"theme": {"nope"}}
+	req = httptest.NewRequest(http.MethodPost, "/api/theme", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown theme, got %d", rec.Code)
+	}
+}
+
+func TestMemoryThemeStore(t *testing.T) {
+	s := NewMemoryThemeStore()
+	ctx := context.Background()
+	if name, _ := s.UserTheme(ctx, 1); name != "" {
+		t.Errorf("expected no theme, got %q", name)
+	}
+	_ = s.SetUserTheme(ctx, 1, "ocean")
+	if name, _ := s.UserTheme(ctx, 1); name != "ocean" {
+		t.Errorf("expected ocean, got %q", name)
+	}
+	if name, _ := s.UserTheme(ctx, 2); name != "" {
+		t.Errorf("expected the preference to be per user, got %q", name)
+	}
+}
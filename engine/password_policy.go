@@ -0,0 +1,108 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	authpkg "github.com/bozz33/sublimeadmin/auth"
+)
+
+// PasswordHistoryRepository is implemented by the UserRepositories keeping
+// the hashes of the previous passwords of their users (appending the old
+// hash in UpdatePassword); a PasswordPolicy with a History then rejects
+// their reuse. Without it, only the current password is rejected.
+type PasswordHistoryRepository interface {
+	// PasswordHistory returns the hashes of the last n passwords of the
+	// user, the current one first.
+	PasswordHistory(ctx context.Context, userID, n int) ([]string, error)
+}
+
+// PasswordAgeRepository is implemented by the UserRepositories recording
+// when the password of a user last changed; a PasswordPolicy with a MaxAge
+// then sends the users with an expired password to their profile.
+type PasswordAgeRepository interface {
+	// PasswordChangedAt returns when the password of the user last
+	// changed, the zero time when unknown.
+	PasswordChangedAt(ctx context.Context, userID int) (time.Time, error)
+}
+
+// passwordExpiredKey flags the session of a user who signed in with an
+// expired password, until it is changed.
+const passwordExpiredKey = "password_expired"
+
+// WithPasswordPolicy sets the passwords accepted on registration, reset
+// and profile change; the rules are listed under the password fields and
+// checked as the user types. A policy with a MaxAge needs the Profile page:
+// the users are sent there until they change an expired password.
+// Defaults to auth.DefaultPasswordPolicy when policy is nil.
+func (p *Panel) WithPasswordPolicy(policy *authpkg.PasswordPolicy) *Panel {
+	if policy == nil {
+		policy = authpkg.DefaultPasswordPolicy()
+	}
+	p.passwordPolicy = policy
+	return p
+}
+
+// validateNewPassword checks password against the policy of ctx and, for
+// an existing user (userID > 0), against their previous passwords. It
+// returns the message to show, "" when the password is accepted.
+func validateNewPassword(ctx context.Context, users UserRepository, userID int, password string) string {
+	policy := authpkg.PasswordPolicyFromContext(ctx)
+	if err := policy.Validate(password); err != nil {
+		return err.Error()
+	}
+	if policy.History <= 0 || userID <= 0 {
+		return ""
+	}
+	for _, hash := range previousPasswords(ctx, users, userID, policy.History) {
+		if (&AuthHandler{}).verifyPassword(password, hash) {
+			if policy.History == 1 {
+				return "Password must differ from your current password."
+			}
+			return fmt.Sprintf("Password must differ from your last %d passwords.", policy.History)
+		}
+	}
+	return ""
+}
+
+// previousPasswords returns the hashes of the last n passwords of the
+// user: from a PasswordHistoryRepository, else the current one.
+func previousPasswords(ctx context.Context, users UserRepository, userID, n int) []string {
+	if history, ok := users.(PasswordHistoryRepository); ok {
+		hashes, err := history.PasswordHistory(ctx, userID, n)
+		if err == nil {
+			return hashes
+		}
+	}
+	u, err := users.GetByID(ctx, userID)
+	if err != nil || u == nil {
+		return nil
+	}
+	return []string{u.GetPassword()}
+}
+
+// passwordExpired reports whether the password of the user has outlived
+// the MaxAge of the policy of ctx.
+func passwordExpired(ctx context.Context, users UserRepository, userID int) bool {
+	policy := authpkg.PasswordPolicyFromContext(ctx)
+	ages, ok := users.(PasswordAgeRepository)
+	if !ok || policy.MaxAge <= 0 {
+		return false
+	}
+	changedAt, err := ages.PasswordChangedAt(ctx, userID)
+	return err == nil && policy.Expired(changedAt)
+}
+
+// requireFreshPassword sends the users who signed in with an expired
+// password to their profile until they change it.
+func (p *Panel) requireFreshPassword(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/profile" && p.AuthManager.Session().GetBool(r.Context(), passwordExpiredKey) {
+			http.Redirect(w, r, PanelURL(r.Context(), "/profile"), http.StatusFound)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
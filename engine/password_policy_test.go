@@ -0,0 +1,113 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/bozz33/sublimeadmin/auth"
+)
+
+type policyUser struct {
+	id                    int
+	name, email, password string
+}
+
+func (u *policyUser) GetID() int          { return u.id }
+func (u *policyUser) GetName() string     { return u.name }
+func (u *policyUser) GetEmail() string    { return u.email }
+func (u *policyUser) GetPassword() string { return u.password }
+
+// policyUsers is a UserRepository of one user keeping their previous
+// passwords and when the password changed.
+type policyUsers struct {
+	UserRepository
+	user      *policyUser
+	history   []string
+	changedAt time.Time
+}
+
+func (r *policyUsers) FindByEmail(context.Context, string) (FrameworkUser, error) { return r.user, nil }
+func (r *policyUsers) GetByID(context.Context, int) (FrameworkUser, error)        { return r.user, nil }
+
+func (r *policyUsers) PasswordHistory(_ context.Context, _, n int) ([]string, error) {
+	return append([]string{r.user.password}, r.history...)[:min(n, len(r.history)+1)], nil
+}
+
+func (r *policyUsers) PasswordChangedAt(context.Context, int) (time.Time, error) {
+	return r.changedAt, nil
+}
+
+func TestValidateNewPassword(t *testing.T) {
+	ah := &AuthHandler{}
+	users := &policyUsers{
+		user:    &policyUser{id: 1, password: ah.hashPassword("current-pass")},
+		history: []string{ah.hashPassword("previous-pass"), ah.hashPassword("ancient-pass")},
+	}
+	ctx := auth.WithPasswordPolicy(context.Background(), &auth.PasswordPolicy{MinLength: 8, History: 2})
+
+	tests := []struct {
+		password string
+		userID   int
+		want     string
+	}{
+		{"short", 1, "Password must be at least 8 characters."},
+		{"current-pass", 1, "Password must differ from your last 2 passwords."},
+		{"previous-pass", 1, "Password must differ from your last 2 passwords."},
+		{"ancient-pass", 1, ""},
+		{"current-pass", 0, ""}, // registration: no history
+	}
+	for _, tt := range tests {
+		if got := validateNewPassword(ctx, users, tt.userID, tt.password); got != tt.want {
+			t.Errorf("validateNewPassword(%q, user %d) = %q, want %q", tt.password, tt.userID, got, tt.want)
+		}
+	}
+}
+
+func TestPanel_PasswordExpiry(t *testing.T) {
+	sm := scs.New()
+	users := &policyUsers{
+		user:      &policyUser{id: 1, email: "ann@example.com", password: (&AuthHandler{}).hashPassword("secret-pass")},
+		changedAt: time.Now().Add(-100 * 24 * time.Hour),
+	}
+	p := NewPanel("password-test").WithPasswordPolicy(&auth.PasswordPolicy{MaxAge: 90 * 24 * time.Hour})
+	p.AuthManager = auth.NewManager(sm)
+	p.Users = users
+
+	mux := http.NewServeMux()
+	mux.Handle("/login", NewAuthHandler(p.AuthManager, users))
+	mux.Handle("/profile", p.protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	mux.Handle("/", p.protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	h := sm.LoadAndSave(p.injectConfig(mux))
+
+	form := url.Values{"email": {"ann@example.com"}, "password": {"secret-pass"}}
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	cookies := rec.Result().Cookies()
+	if rec.Code != http.StatusFound || len(cookies) == 0 {
+		t.Fatalf("login: status %d, cookies %v", rec.Code, cookies)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := get("/"); rec.Code != http.StatusFound || rec.Header().Get("Location") != "/profile" {
+		t.Errorf("dashboard with an expired password: status %d, location %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if rec := get("/profile"); rec.Code != http.StatusOK {
+		t.Errorf("profile with an expired password: status %d, want 200", rec.Code)
+	}
+}
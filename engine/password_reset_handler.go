@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/apperrors"
 	authpkg "github.com/bozz33/sublimeadmin/auth"
 	"github.com/bozz33/sublimeadmin/mailer"
 	authtemplates "github.com/bozz33/sublimeadmin/views/auth"
@@ -52,7 +53,7 @@ func (h *PasswordResetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		case http.MethodPost:
 			h.handleForgotPassword(w, r)
 		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			apperrors.Handle(w, r, apperrors.MethodNotAllowed(""))
 		}
 	case "/reset-password":
 		switch r.Method {
@@ -63,7 +64,7 @@ func (h *PasswordResetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 		case http.MethodPost:
 			h.handleResetPassword(w, r)
 		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			apperrors.Handle(w, r, apperrors.MethodNotAllowed(""))
 		}
 	default:
 		http.NotFound(w, r)
@@ -72,7 +73,7 @@ func (h *PasswordResetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 
 func (h *PasswordResetHandler) handleForgotPassword(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Invalid form", http.StatusBadRequest)
+		apperrors.Handle(w, r, apperrors.BadRequest("Invalid form"))
 		return
 	}
 
@@ -107,7 +108,7 @@ func (h *PasswordResetHandler) handleForgotPassword(w http.ResponseWriter, r *ht
 
 func (h *PasswordResetHandler) handleResetPassword(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Invalid form", http.StatusBadRequest)
+		apperrors.Handle(w, r, apperrors.BadRequest("Invalid form"))
 		return
 	}
 
@@ -124,16 +125,9 @@ func (h *PasswordResetHandler) handleResetPassword(w http.ResponseWriter, r *htt
 		showErr("Passwords do not match.")
 		return
 	}
-	if len(password) < 8 {
-		showErr("Password must be at least 8 characters.")
-		return
-	}
 
 	resetStore.mu.Lock()
 	entry, ok := resetStore.tokens[token]
-	if ok {
-		delete(resetStore.tokens, token)
-	}
 	resetStore.mu.Unlock()
 
 	if !ok || entry.email != email || time.Now().After(entry.expiresAt) {
@@ -141,21 +135,36 @@ func (h *PasswordResetHandler) handleResetPassword(w http.ResponseWriter, r *htt
 		return
 	}
 
-	ah := &AuthHandler{}
-	newHash := ah.hashPassword(password)
-
 	dbUser, findErr := h.users.FindByEmail(r.Context(), email)
 	if findErr != nil {
 		showErr("Failed to reset password. Please try again.")
 		return
 	}
+	// Checked once the link is known to be valid, as the history tells
+	// about the user; the link stays usable to try another password.
+	if msg := validateNewPassword(r.Context(), h.users, dbUser.GetID(), password); msg != "" {
+		showErr(msg)
+		return
+	}
+
+	resetStore.mu.Lock()
+	_, ok = resetStore.tokens[token]
+	delete(resetStore.tokens, token)
+	resetStore.mu.Unlock()
+	if !ok {
+		showErr("This reset link is invalid or has expired.")
+		return
+	}
+
+	ah := &AuthHandler{}
+	newHash := ah.hashPassword(password)
 	err := h.users.UpdatePassword(r.Context(), dbUser.GetID(), newHash)
 	if err != nil {
 		showErr("Failed to reset password. Please try again.")
 		return
 	}
 
-	http.Redirect(w, r, "/login?reset=1", http.StatusFound)
+	http.Redirect(w, r, PanelURL(r.Context(), "/login?reset=1"), http.StatusFound)
 }
 
 // generateToken returns a cryptographically random 32-byte hex token.
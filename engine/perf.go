@@ -7,6 +7,9 @@ import (
 	"net/http"
 	_ "net/http/pprof" // register pprof handlers on DefaultServeMux
 	"strings"
+
+	"github.com/bozz33/sublimeadmin/apperrors"
+	"github.com/bozz33/sublimeadmin/middleware"
 )
 
 // ---------------------------------------------------------------------------
@@ -32,7 +35,7 @@ func PprofAuthMiddleware(token string) func(http.Handler) http.Handler {
 			if strings.HasPrefix(r.URL.Path, "/debug/pprof/") {
 				auth := r.Header.Get("Authorization")
 				if auth != "Bearer "+token {
-					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+					apperrors.Handle(w, r, apperrors.Unauthorized(""))
 					return
 				}
 			}
@@ -105,14 +108,9 @@ func ETagMiddleware(next http.Handler) http.Handler {
 // ---------------------------------------------------------------------------
 
 // SecurityHeadersMiddleware adds OWASP-recommended security headers to every response.
+//
+// Deprecated: use middleware.SecurityHeaders, or Panel.WithSecurityHeaders
+// to configure CSP and HSTS for the panel.
 func SecurityHeadersMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		h := w.Header()
-		h.Set("X-Content-Type-Options", "nosniff")
-		h.Set("X-Frame-Options", "SAMEORIGIN")
-		h.Set("X-XSS-Protection", "1; mode=block")
-		h.Set("Referrer-Policy", "strict-origin-when-cross-origin")
-		h.Set("Permissions-Policy", "camera=(), microphone=(), geolocation=()")
-		next.ServeHTTP(w, r)
-	})
+	return middleware.SecurityHeaders()(next)
 }
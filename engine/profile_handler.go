@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/apperrors"
 	authpkg "github.com/bozz33/sublimeadmin/auth"
 	authtemplates "github.com/bozz33/sublimeadmin/views/auth"
 )
@@ -20,6 +21,9 @@ func NewProfileHandler(authManager *authpkg.Manager, users UserRepository) *Prof
 }
 
 func (h *ProfileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p := GetPanelFromContext(r.Context()); p.devicesEnabled() {
+		r = r.WithContext(p.withDevices(r))
+	}
 	switch r.Method {
 	case http.MethodGet:
 		h.showProfile(w, r)
@@ -28,22 +32,28 @@ func (h *ProfileHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		switch action {
 		case "change_password":
 			h.handleChangePassword(w, r)
+		case "revoke_device":
+			h.handleRevokeDevice(w, r)
 		default:
 			h.handleUpdateProfile(w, r)
 		}
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		apperrors.Handle(w, r, apperrors.MethodNotAllowed(""))
 	}
 }
 
 func (h *ProfileHandler) showProfile(w http.ResponseWriter, r *http.Request) {
 	u := h.currentUser(r)
-	templ.Handler(authtemplates.ProfilePage(u, "", "")).ServeHTTP(w, r)
+	errMsg := ""
+	if h.authManager.Session().GetBool(r.Context(), passwordExpiredKey) {
+		errMsg = "Your password has expired. Choose a new one to continue."
+	}
+	templ.Handler(authtemplates.ProfilePage(u, errMsg, "")).ServeHTTP(w, r)
 }
 
 func (h *ProfileHandler) handleUpdateProfile(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Invalid form", http.StatusBadRequest)
+		apperrors.Handle(w, r, apperrors.BadRequest("Invalid form"))
 		return
 	}
 
@@ -85,7 +95,7 @@ func (h *ProfileHandler) handleUpdateProfile(w http.ResponseWriter, r *http.Requ
 
 func (h *ProfileHandler) handleChangePassword(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Invalid form", http.StatusBadRequest)
+		apperrors.Handle(w, r, apperrors.BadRequest("Invalid form"))
 		return
 	}
 
@@ -98,10 +108,6 @@ func (h *ProfileHandler) handleChangePassword(w http.ResponseWriter, r *http.Req
 		templ.Handler(authtemplates.ProfilePage(u, "New passwords do not match.", "")).ServeHTTP(w, r)
 		return
 	}
-	if len(newPwd) < 8 {
-		templ.Handler(authtemplates.ProfilePage(u, "Password must be at least 8 characters.", "")).ServeHTTP(w, r)
-		return
-	}
 
 	// Load current hash from DB
 	dbUser, err := h.users.GetByID(r.Context(), u.ID)
@@ -115,6 +121,10 @@ func (h *ProfileHandler) handleChangePassword(w http.ResponseWriter, r *http.Req
 		templ.Handler(authtemplates.ProfilePage(u, "Current password is incorrect.", "")).ServeHTTP(w, r)
 		return
 	}
+	if msg := validateNewPassword(r.Context(), h.users, u.ID, newPwd); msg != "" {
+		templ.Handler(authtemplates.ProfilePage(u, msg, "")).ServeHTTP(w, r)
+		return
+	}
 
 	newHash := ah.hashPassword(newPwd)
 	err = h.users.UpdatePassword(r.Context(), u.ID, newHash)
@@ -122,10 +132,24 @@ func (h *ProfileHandler) handleChangePassword(w http.ResponseWriter, r *http.Req
 		templ.Handler(authtemplates.ProfilePage(u, "Failed to update password.", "")).ServeHTTP(w, r)
 		return
 	}
+	h.authManager.Session().Remove(r.Context(), passwordExpiredKey)
 
 	templ.Handler(authtemplates.ProfilePage(u, "", "Password changed successfully.")).ServeHTTP(w, r)
 }
 
+// handleRevokeDevice forgets a device of the user: it needs the password
+// to sign in again.
+func (h *ProfileHandler) handleRevokeDevice(w http.ResponseWriter, r *http.Request) {
+	u := h.currentUser(r)
+	p := GetPanelFromContext(r.Context())
+	if !p.devicesEnabled() || !p.revokeDevice(r.Context(), u.ID, r.FormValue("device")) {
+		templ.Handler(authtemplates.ProfilePage(u, "Device not found.", "")).ServeHTTP(w, r)
+		return
+	}
+	r = r.WithContext(p.withDevices(r))
+	templ.Handler(authtemplates.ProfilePage(u, "", "Device revoked.")).ServeHTTP(w, r)
+}
+
 // currentUser returns the authenticated user from context, falling back to a
 // minimal user built from the session ID.
 func (h *ProfileHandler) currentUser(r *http.Request) *authpkg.User {
@@ -0,0 +1,166 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/infolist"
+	"github.com/bozz33/sublimeadmin/ui/components"
+)
+
+// ResourceInfolistable is an optional interface for resources describing
+// their detail page with an infolist, filled with each record (see
+// infolist.Infolist.Fill), instead of a component of their own (see
+// ResourceViewable). BaseResource implements it (see SetInfolist); a nil
+// infolist means no detail page.
+type ResourceInfolistable interface {
+	Infolist(ctx context.Context) *infolist.Infolist
+}
+
+// hasRecordView reports whether the resource has a detail page at
+// GET /{slug}/{id}.
+func hasRecordView(ctx context.Context, res Resource) bool {
+	if _, ok := res.(ResourceViewable); ok {
+		return true
+	}
+	il, ok := res.(ResourceInfolistable)
+	return ok && il.Infolist(ctx) != nil
+}
+
+// recordContent returns the details of item on its detail page.
+func recordContent(ctx context.Context, res Resource, item any) templ.Component {
+	if viewable, ok := res.(ResourceViewable); ok {
+		return viewable.View(ctx, item)
+	}
+	if il, ok := res.(ResourceInfolistable); ok && il.Infolist(ctx) != nil {
+		return infolist.View(il.Infolist(ctx).Fill(item))
+	}
+	return emptyComponent()
+}
+
+// relationPageSize is the number of related records per page of a
+// relation sub-table.
+const relationPageSize = 10
+
+// relationTabs lists the first records of each relation manager of the
+// resource (see RelationManagerAware) for the pages of the record id.
+func relationTabs(ctx context.Context, res Resource, id string) []components.RelationTab {
+	rma, ok := res.(RelationManagerAware)
+	if !ok {
+		return nil
+	}
+	var tabs []components.RelationTab
+	for _, rm := range rma.GetRelationManagers() {
+		tabs = append(tabs, relationTab(ctx, res, rm, id, 1))
+	}
+	return tabs
+}
+
+// relationTab returns the page of the sub-table of rm for the record id,
+// with the actions allowed from ctx.
+func relationTab(ctx context.Context, res Resource, rm RelationManager, id string, page int) components.RelationTab {
+	tab := components.RelationTab{
+		Name:      rm.Name(),
+		Label:     rm.Label(),
+		Icon:      rm.Icon(),
+		URL:       PanelURL(ctx, fmt.Sprintf("/%s/%s/relations/%s", res.Slug(), url.PathEscape(id), rm.Name())),
+		CanCreate: rm.CanCreate(ctx),
+		CanAttach: rm.RelationType() == RelationManyToMany && rm.CanAttach(ctx),
+		CanDelete: rm.CanDelete(ctx),
+	}
+	columns := rm.Columns()
+	for _, col := range columns {
+		tab.Columns = append(tab.Columns, col.Label)
+	}
+	related, err := rm.ListRelated(ctx, id)
+	if err != nil {
+		tab.Error = fmt.Sprintf("The %s could not be loaded.", rm.Label())
+	}
+
+	tab.Total = len(related)
+	tab.Pages = max((len(related)+relationPageSize-1)/relationPageSize, 1)
+	tab.Page = min(max(page, 1), tab.Pages)
+	start := (tab.Page - 1) * relationPageSize
+	for _, item := range related[start:min(start+relationPageSize, len(related))] {
+		cells := make([]string, len(columns))
+		for i, col := range columns {
+			if v := ExtractRelatedID(item, col.Key); v != nil {
+				cells[i] = col.Prefix + fmt.Sprintf("%v", v) + col.Suffix
+			}
+		}
+		tab.Rows = append(tab.Rows, cells)
+		tab.IDs = append(tab.IDs, getItemID(item))
+	}
+	if tab.CanAttach {
+		tab.AttachOptions = attachOptions(ctx, res, rm, related)
+		pivotTab(&tab, res, rm, related[start:min(start+relationPageSize, len(related))])
+	}
+	return tab
+}
+
+// pivotTab adds the pivot fields of the relation of rm to tab: a column
+// each, the inputs of the Attach modal and, when rm can update them (see
+// RelationPivotManager), an Edit action on the rows.
+func pivotTab(tab *components.RelationTab, res Resource, rm RelationManager, rows []any) {
+	rel := managerRelation(res, rm)
+	if rel == nil || len(rel.PivotFields) == 0 {
+		return
+	}
+	_, tab.CanEditPivot = rm.(RelationPivotManager)
+	for _, f := range rel.PivotFields {
+		field := components.RelationPivotField{Name: f.Name, Label: pivotLabel(f), Type: f.Type, Required: f.Required}
+		for _, opt := range f.Options {
+			field.Options = append(field.Options, components.RelationOption{Value: opt.Value, Label: opt.Label})
+		}
+		tab.Pivot = append(tab.Pivot, field)
+		tab.Columns = append(tab.Columns, field.Label)
+	}
+	for i, item := range rows {
+		values := make(map[string]string, len(rel.PivotFields))
+		for _, f := range rel.PivotFields {
+			if v, ok := recordField(item, f.Name); ok {
+				values[f.Name] = fmt.Sprint(v.Interface())
+			}
+			tab.Rows[i] = append(tab.Rows[i], pivotCell(f, values[f.Name]))
+		}
+		tab.PivotValues = append(tab.PivotValues, values)
+	}
+}
+
+// pivotCell returns the cell of the value of a pivot field: the label of
+// the option of a select.
+func pivotCell(f PivotField, value string) string {
+	for _, opt := range f.Options {
+		if opt.Value == value {
+			return opt.Label
+		}
+	}
+	return value
+}
+
+// attachOptions returns the records of the related resource not related
+// yet, when the resource declares the relation of rm (see RelationAware).
+// Nil otherwise: the id is typed.
+func attachOptions(ctx context.Context, res Resource, rm RelationManager, related []any) []components.RelationOption {
+	rel := managerRelation(res, rm)
+	if rel == nil {
+		return nil
+	}
+	opts, err := SearchRelationOptions(ctx, rel, nil, "", 100)
+	if err != nil {
+		return nil
+	}
+	attached := make(map[string]bool, len(related))
+	for _, item := range related {
+		attached[getItemID(item)] = true
+	}
+	var options []components.RelationOption
+	for _, opt := range opts.Options {
+		if !attached[opt.Value] {
+			options = append(options, components.RelationOption{Value: opt.Value, Label: opt.Label})
+		}
+	}
+	return options
+}
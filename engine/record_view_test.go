@@ -0,0 +1,192 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/bozz33/sublimeadmin/infolist"
+)
+
+// commentsManager lists the comments of a post.
+type commentsManager struct {
+	*mockRelationManager
+}
+
+func (m *commentsManager) Columns() []Column {
+	return []Column{{Key: "Name", Label: "Author"}}
+}
+
+// viewResource describes its detail page with an infolist.
+type viewResource struct {
+	*confirmResource
+}
+
+func (r *viewResource) Get(_ context.Context, id string) (any, error) {
+	return &testItem{AuthorID: 7, Name: "post-" + id}, nil
+}
+
+func TestCRUDHandler_View_infolist(t *testing.T) {
+	comments := &commentsManager{newMockRM("comments")}
+	comments.listItems = []any{&testItem{Name: "jane"}, &testItem{Name: "john"}}
+	failing := newMockRM("likes")
+	failing.listErr = errors.New("db down")
+	res := &viewResource{newConfirmResource(comments, failing)}
+	res.SetInfolist(infolist.New().AddSection(infolist.NewSection("Post").Add(
+		infolist.Text("Name").WithLabel("Title"),
+		infolist.Badge("AuthorID").Colors(map[string]string{"7": "green"}),
+	)))
+	h := newHandler(res)
+
+	rw := serveWith(h, http.MethodGet, "/projects/3", nil)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rw.Code)
+	}
+	body := rw.Body.String()
+	for _, want := range []string{"Title", "post-3", "bg-green-100", "comments Label", "jane", "john", "The likes Label could not be loaded.", `href="/projects/3/edit"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in the detail page", want)
+		}
+	}
+
+	// Without a detail page the record opens in the edit form
+	res.SetInfolist(nil)
+	rw = serveWith(h, http.MethodGet, "/projects/3", nil)
+	if rw.Code != http.StatusSeeOther || rw.Header().Get("Location") != "/projects/3/edit" {
+		t.Errorf("expected a redirect to the edit form, got %d %q", rw.Code, rw.Header().Get("Location"))
+	}
+}
+
+func TestCRUDHandler_relationTable(t *testing.T) {
+	comments := &commentsManager{newMockRM("comments")}
+	for i := range 12 {
+		comments.listItems = append(comments.listItems, &author{ID: i + 1, FullName: fmt.Sprintf("c%d", i+1)})
+	}
+	tags := &mockRelationManager{BaseRelationManager: NewBaseRelationManager("tags", "Tags", "tags", RelationManyToMany), canAttach: true}
+	res := &viewResource{newConfirmResource(comments, tags)}
+	h := newHandler(res)
+
+	fragment := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("HX-Request", "true")
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, req)
+		return rw
+	}
+
+	rw := fragment(http.MethodGet, "/projects/3/relations/comments?page=2")
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rw.Code)
+	}
+	body := rw.Body.String()
+	for _, want := range []string{`id="relation-comments"`, `data-url="/projects/3/relations/comments"`, "Page 2 of 2", "?page=1", `data-relation-delete="/projects/3/relations/comments/12"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in the relation table", want)
+		}
+	}
+	if strings.Contains(body, "<html") {
+		t.Error("expected the table without the layout")
+	}
+
+	rw = fragment(http.MethodPost, "/projects/3/relations/comments")
+	if rw.Code != http.StatusOK || !comments.createCalled || !strings.Contains(rw.Body.String(), "Page 1 of 2") {
+		t.Errorf("expected the record created and the table returned, got %d", rw.Code)
+	}
+	rw = fragment(http.MethodDelete, "/projects/3/relations/tags/detach/9")
+	if rw.Code != http.StatusOK || !tags.detachCalled {
+		t.Errorf("expected the record detached and the table returned, got %d", rw.Code)
+	}
+
+	// Without HX-Request the relation is listed as JSON
+	rw = serveWith(h, http.MethodGet, "/projects/3/relations/tags", nil)
+	if ct := rw.Header().Get("Content-Type"); rw.Code != http.StatusOK || ct != "application/json" {
+		t.Errorf("expected JSON, got %d %q", rw.Code, ct)
+	}
+
+	// The edit form is followed by the sub-tables
+	rw = serveWith(h, http.MethodGet, "/projects/3/edit", nil)
+	if body := rw.Body.String(); !strings.Contains(body, `id="relation-tags"`) || !strings.Contains(body, "Attach") {
+		t.Error("expected the relation tabs on the edit page")
+	}
+}
+
+// membersManager attaches users to a project with a role.
+type membersManager struct {
+	*mockRelationManager
+	attached map[string]map[string]string
+	updated  map[string]string
+}
+
+func (m *membersManager) AttachRelatedPivot(_ context.Context, _, relatedID string, pivot map[string]string) error {
+	m.attached[relatedID] = pivot
+	return nil
+}
+
+func (m *membersManager) UpdatePivot(_ context.Context, _, _ string, pivot map[string]string) error {
+	m.updated = pivot
+	return nil
+}
+
+type member struct {
+	ID   int
+	Name string
+	Role string
+}
+
+// membersResource declares the pivot fields of its members.
+type membersResource struct {
+	*viewResource
+}
+
+func (r *membersResource) GetRelations() []*Relation {
+	return []*Relation{ManyToMany("members", "users").WithPivot(
+		PivotField{Name: "role", Type: "select", Required: true, Options: []SelectOption{{Value: "admin", Label: "Administrator"}, {Value: "viewer", Label: "Viewer"}}},
+		PivotField{Name: "hours", Type: "number"},
+	).Build()}
+}
+
+func TestCRUDHandler_relationPivot(t *testing.T) {
+	members := &membersManager{
+		mockRelationManager: &mockRelationManager{BaseRelationManager: NewBaseRelationManager("members", "Members", "members", RelationManyToMany), canAttach: true},
+		attached:            map[string]map[string]string{},
+	}
+	members.listItems = []any{&member{ID: 4, Name: "jane", Role: "admin"}}
+	h := newHandler(&membersResource{&viewResource{newConfirmResource(members)}})
+
+	send := func(method, path string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("HX-Request", "true")
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, req)
+		return rw
+	}
+
+	body := send(http.MethodGet, "/projects/3/relations/members", nil).Body.String()
+	for _, want := range []string{"Role", "Administrator", `action="/projects/3/relations/members/pivot/4"`, `<option value="admin" selected>`, `name="hours"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in the relation table", want)
+		}
+	}
+
+	rw := send(http.MethodPost, "/projects/3/relations/members/attach", url.Values{"related_id": {"5"}, "role": {"viewer"}, "hours": {"12"}})
+	if rw.Code != http.StatusOK || members.attached["5"]["role"] != "viewer" || members.attached["5"]["hours"] != "12" {
+		t.Errorf("expected the member attached with its pivot, got %d %v", rw.Code, members.attached)
+	}
+	if rw := send(http.MethodPost, "/projects/3/relations/members/attach", url.Values{"related_id": {"6"}, "role": {"owner"}}); rw.Code != http.StatusBadRequest {
+		t.Errorf("expected an invalid role refused, got %d", rw.Code)
+	}
+	if rw := send(http.MethodPost, "/projects/3/relations/members/attach", url.Values{"related_id": {"6"}, "hours": {"x"}}); rw.Code != http.StatusBadRequest {
+		t.Errorf("expected a missing role refused, got %d", rw.Code)
+	}
+
+	rw = send(http.MethodPost, "/projects/3/relations/members/pivot/4", url.Values{"role": {"viewer"}})
+	if rw.Code != http.StatusOK || members.updated["role"] != "viewer" {
+		t.Errorf("expected the pivot updated, got %d %v", rw.Code, members.updated)
+	}
+}
@@ -0,0 +1,296 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// BatchRelationLoader loads the relations of a page of records with one
+// query per relation instead of one per record: the ListWhereIn of the
+// related resource (see ResourceBatchLister) or, without it, its List.
+//
+//	loader := engine.NewBatchRelationLoader()
+//	err := loader.Load(ctx, items, author, comments)
+//
+// The related records are set on the field of each record named after the
+// relation ("author" → Author, a *User or User; "comments" → Comments, a
+// slice), or its key for map records. Morph-to relations are loaded with one
+// query per target resource. Many-to-many and morph-many relations, listed
+// by their RelationManager only, are not loaded.
+type BatchRelationLoader struct{}
+
+// NewBatchRelationLoader creates a batch relation loader.
+func NewBatchRelationLoader() *BatchRelationLoader {
+	return &BatchRelationLoader{}
+}
+
+var _ RelationLoader = (*BatchRelationLoader)(nil)
+
+// Load loads the relations for items, finding the related resources by
+// slug in the panel of ctx then in the registered panels.
+func (l *BatchRelationLoader) Load(ctx context.Context, items []any, relations ...*Relation) error {
+	if len(items) == 0 {
+		return nil
+	}
+	for _, rel := range relations {
+		values, err := l.load(ctx, items, rel)
+		if err != nil {
+			return err
+		}
+		for i, item := range items {
+			setRelated(item, rel.Name, values[i])
+		}
+	}
+	return nil
+}
+
+// LoadRelation loads a relation of item.
+func (l *BatchRelationLoader) LoadRelation(ctx context.Context, item any, relation *Relation) (any, error) {
+	values, err := l.load(ctx, []any{item}, relation)
+	if err != nil {
+		return nil, err
+	}
+	setRelated(item, relation.Name, values[0])
+	return values[0], nil
+}
+
+// LoadRelations loads relations of item, keyed by relation name.
+func (l *BatchRelationLoader) LoadRelations(ctx context.Context, item any, relations []*Relation) (map[string]any, error) {
+	loaded := make(map[string]any, len(relations))
+	for _, rel := range relations {
+		value, err := l.LoadRelation(ctx, item, rel)
+		if err != nil {
+			return nil, err
+		}
+		loaded[rel.Name] = value
+	}
+	return loaded, nil
+}
+
+// load returns the related records of each item: the record, or nil, of a
+// belongs-to or has-one relation, the slice of a has-many one.
+func (l *BatchRelationLoader) load(ctx context.Context, items []any, rel *Relation) ([]any, error) {
+	if rel.Type == RelationMorphTo {
+		return l.loadMorphTo(ctx, items, rel)
+	}
+	res := findRelatedResource(ctx, rel.RelatedSlug)
+	if res == nil {
+		return nil, fmt.Errorf("relation %s: resource %q not found", rel.Name, rel.RelatedSlug)
+	}
+	ownerKey := rel.OwnerKey
+	if ownerKey == "" {
+		ownerKey = "id"
+	}
+
+	// Keys of the items, and field of the related records matching them.
+	var localKey, relatedKey string
+	switch rel.Type {
+	case RelationBelongsTo:
+		localKey, relatedKey = rel.ForeignKey, ownerKey
+	case RelationHasOne, RelationHasMany:
+		localKey, relatedKey = ownerKey, rel.ForeignKey
+	default:
+		return nil, fmt.Errorf("relation %s: %s relations are not loaded in batch", rel.Name, rel.Type)
+	}
+	if localKey == "" || relatedKey == "" {
+		return nil, fmt.Errorf("relation %s: no foreign key", rel.Name)
+	}
+
+	keys := make([]string, len(items))
+	var distinct []string
+	seen := make(map[string]bool)
+	for i, item := range items {
+		keys[i] = relationKey(item, localKey)
+		if keys[i] != "" && !seen[keys[i]] {
+			seen[keys[i]] = true
+			distinct = append(distinct, keys[i])
+		}
+	}
+	values := make([]any, len(items))
+	if len(distinct) == 0 {
+		return values, nil
+	}
+
+	related, err := listWhereIn(ctx, res, relatedKey, distinct, seen)
+	if err != nil {
+		return nil, fmt.Errorf("relation %s: %w", rel.Name, err)
+	}
+	byKey := make(map[string][]any)
+	for _, r := range related {
+		key := relationKey(r, relatedKey)
+		byKey[key] = append(byKey[key], r)
+	}
+	for i, key := range keys {
+		found := byKey[key]
+		switch {
+		case rel.Type == RelationHasMany:
+			values[i] = found
+		case len(found) > 0:
+			values[i] = found[0]
+		}
+	}
+	return values, nil
+}
+
+// loadMorphTo returns the record each item points to through a morph-to
+// relation, with one query per target resource.
+func (l *BatchRelationLoader) loadMorphTo(ctx context.Context, items []any, rel *Relation) ([]any, error) {
+	ownerKey := rel.OwnerKey
+	if ownerKey == "" {
+		ownerKey = "id"
+	}
+	ids := make(map[string]map[string]bool) // type → IDs
+	var types []string
+	for _, item := range items {
+		typ, id := relationKey(item, rel.MorphType), relationKey(item, rel.ForeignKey)
+		if typ == "" || id == "" {
+			continue
+		}
+		if ids[typ] == nil {
+			ids[typ] = make(map[string]bool)
+			types = append(types, typ)
+		}
+		ids[typ][id] = true
+	}
+	byKey := make(map[string]any) // MorphValue → record
+	for _, typ := range types {
+		res := morphTarget(ctx, rel, typ)
+		if res == nil {
+			return nil, fmt.Errorf("relation %s: resource %q not found", rel.Name, typ)
+		}
+		values := make([]string, 0, len(ids[typ]))
+		for id := range ids[typ] {
+			values = append(values, id)
+		}
+		related, err := listWhereIn(ctx, res, ownerKey, values, ids[typ])
+		if err != nil {
+			return nil, fmt.Errorf("relation %s: %w", rel.Name, err)
+		}
+		for _, r := range related {
+			byKey[MorphValue(typ, relationKey(r, ownerKey))] = r
+		}
+	}
+	values := make([]any, len(items))
+	for i, item := range items {
+		values[i] = byKey[MorphValue(relationKey(item, rel.MorphType), relationKey(item, rel.ForeignKey))]
+	}
+	return values, nil
+}
+
+// listWhereIn lists the records of res whose field is in values, through
+// ResourceBatchLister or else the whole list filtered.
+func listWhereIn(ctx context.Context, res Resource, field string, values []string, set map[string]bool) ([]any, error) {
+	if bl, ok := res.(ResourceBatchLister); ok {
+		return bl.ListWhereIn(ctx, field, values)
+	}
+	all, err := res.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var found []any
+	for _, item := range all {
+		if set[relationKey(item, field)] {
+			found = append(found, item)
+		}
+	}
+	return found, nil
+}
+
+// relationKey returns the field of record as a key, "" when it is null.
+func relationKey(record any, field string) string {
+	if v, ok := recordField(record, field); ok {
+		return fmt.Sprint(v.Interface())
+	}
+	if strings.EqualFold(field, "id") {
+		return getItemID(record)
+	}
+	return ""
+}
+
+// setRelated sets the related value on the field name of record, when it
+// has one of a compatible type.
+func setRelated(record any, name string, value any) {
+	v := reflect.ValueOf(record)
+	if v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String {
+		if value == nil {
+			return
+		}
+		if rv := reflect.ValueOf(value); rv.Type().AssignableTo(v.Type().Elem()) {
+			v.SetMapIndex(reflect.ValueOf(name).Convert(v.Type().Key()), rv)
+		}
+		return
+	}
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	s := v.Elem()
+	f := s.FieldByNameFunc(func(field string) bool {
+		return strings.EqualFold(field, strings.ReplaceAll(name, "_", ""))
+	})
+	if !f.IsValid() {
+		f = jsonField(s, name)
+	}
+	if !f.IsValid() || !f.CanSet() {
+		return
+	}
+	if list, ok := value.([]any); ok {
+		if f.Kind() != reflect.Slice {
+			return
+		}
+		slice := reflect.MakeSlice(f.Type(), 0, len(list))
+		for _, item := range list {
+			if ev, ok := assignable(reflect.ValueOf(item), f.Type().Elem()); ok {
+				slice = reflect.Append(slice, ev)
+			}
+		}
+		f.Set(slice)
+		return
+	}
+	if value == nil {
+		f.SetZero()
+		return
+	}
+	if rv, ok := assignable(reflect.ValueOf(value), f.Type()); ok {
+		f.Set(rv)
+	}
+}
+
+// assignable adapts v to type t, dereferencing or taking the address of a
+// struct when needed.
+func assignable(v reflect.Value, t reflect.Type) (reflect.Value, bool) {
+	switch {
+	case v.Type().AssignableTo(t):
+		return v, true
+	case v.Kind() == reflect.Pointer && !v.IsNil() && v.Elem().Type().AssignableTo(t):
+		return v.Elem(), true
+	case t.Kind() == reflect.Pointer && v.Type().AssignableTo(t.Elem()):
+		p := reflect.New(t.Elem())
+		p.Elem().Set(v)
+		return p, true
+	}
+	return reflect.Value{}, false
+}
+
+// defaultRelationLoader loads the eager relations of the lists and detail
+// pages (see EagerLoad).
+var defaultRelationLoader = NewBatchRelationLoader()
+
+// EagerLoad loads the eager relations of res (see RelationAware and
+// RelationBuilder.Eager) for items, with one query per relation. The lists
+// and the detail pages of the CRUD handlers load them; a resource rendering
+// its own table calls it on its page of records.
+func EagerLoad(ctx context.Context, res any, items []any) error {
+	ra, ok := res.(RelationAware)
+	if !ok || len(items) == 0 {
+		return nil
+	}
+	var eager []*Relation
+	for _, rel := range ra.GetRelations() {
+		if rel.Eager && rel.Type != RelationManyToMany && rel.Type != RelationMorphMany {
+			eager = append(eager, rel)
+		}
+	}
+	return defaultRelationLoader.Load(ctx, items, eager...)
+}
@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+type loaderComment struct {
+	ID     int
+	PostID int
+	Body   string
+}
+
+type loaderPost struct {
+	ID       int
+	AuthorID int
+	Author   *author
+	Comments []loaderComment
+}
+
+// commentsResource lists comments in batch, counting its queries.
+type commentsResource struct {
+	*mockResource
+	comments []any
+	queries  int
+}
+
+func (r *commentsResource) ListWhereIn(_ context.Context, field string, values []string) ([]any, error) {
+	r.queries++
+	var found []any
+	for _, c := range r.comments {
+		if slices.Contains(values, relationKey(c, field)) {
+			found = append(found, c)
+		}
+	}
+	return found, nil
+}
+
+// postsResource declares the eager relations of the posts.
+type postsResource struct {
+	*mockResource
+}
+
+func (r *postsResource) GetRelations() []*Relation {
+	return []*Relation{
+		BelongsTo("author", "authors").Eager().Build(),
+		HasMany("comments", "comments").ForeignKey("post_id").Eager().Build(),
+	}
+}
+
+func TestEagerLoad(t *testing.T) {
+	authors := &authorsResource{mockResource: newMockResource("authors"), authors: []any{
+		&author{ID: 1, FullName: "Alice"},
+		&author{ID: 2, FullName: "Bob"},
+	}}
+	comments := &commentsResource{mockResource: newMockResource("comments"), comments: []any{
+		&loaderComment{ID: 1, PostID: 10, Body: "first"},
+		&loaderComment{ID: 2, PostID: 10, Body: "second"},
+		&loaderComment{ID: 3, PostID: 11, Body: "third"},
+	}}
+	posts := &postsResource{mockResource: newMockResource("posts")}
+	p := NewPanel("eager-test").AddResources(authors, comments, posts)
+	ctx := context.WithValue(context.Background(), ContextKeyPanel, p)
+
+	items := []any{
+		&loaderPost{ID: 10, AuthorID: 2},
+		&loaderPost{ID: 11, AuthorID: 1},
+		&loaderPost{ID: 12, AuthorID: 3},
+	}
+	if err := EagerLoad(ctx, posts, items); err != nil {
+		t.Fatal(err)
+	}
+	if comments.queries != 1 {
+		t.Errorf("expected one query for the comments, got %d", comments.queries)
+	}
+
+	first := items[0].(*loaderPost)
+	if first.Author == nil || first.Author.FullName != "Bob" {
+		t.Errorf("expected Bob as author, got %+v", first.Author)
+	}
+	if len(first.Comments) != 2 || first.Comments[1].Body != "second" {
+		t.Errorf("expected 2 comments, got %+v", first.Comments)
+	}
+	if last := items[2].(*loaderPost); last.Author != nil || len(last.Comments) != 0 {
+		t.Errorf("expected no relations, got %+v", last)
+	}
+}
+
+func TestBatchRelationLoader_LoadRelations(t *testing.T) {
+	authors := &authorsResource{mockResource: newMockResource("authors"), authors: []any{
+		&author{ID: 1, FullName: "Alice"},
+	}}
+	p := NewPanel("loader-test").AddResources(authors)
+	ctx := context.WithValue(context.Background(), ContextKeyPanel, p)
+
+	post := map[string]any{"id": 10, "author_id": 1}
+	loaded, err := NewBatchRelationLoader().LoadRelations(ctx, post, []*Relation{BelongsTo("author", "authors").Build()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, ok := loaded["author"].(*author); !ok || a.FullName != "Alice" {
+		t.Errorf("expected Alice, got %v", loaded["author"])
+	}
+	if post["author"] != loaded["author"] {
+		t.Error("expected the author set on the map record")
+	}
+
+	if _, err := NewBatchRelationLoader().LoadRelation(ctx, post, ManyToMany("tags", "authors").Build()); err == nil {
+		t.Error("expected an error for a many-to-many relation")
+	}
+}
@@ -0,0 +1,316 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	formPkg "github.com/bozz33/sublimeadmin/form"
+)
+
+// MorphTo creates a polymorphic belongs-to relation: the record points to a
+// record of any resource through a pair of columns, the slug of the
+// resource in name+"_type" and its ID in name+"_id", so that comments or
+// attachments can be shared across resources:
+//
+//	engine.MorphTo("commentable").MorphTypes("posts", "videos")
+//
+// The target resource is found by slug in the panel of the request then in
+// the registered panels (see ResolveMorphTo).
+func MorphTo(name string) *RelationBuilder {
+	return &RelationBuilder{
+		relation: &Relation{
+			Name:         name,
+			Type:         RelationMorphTo,
+			ForeignKey:   name + "_id",
+			MorphType:    name + "_type",
+			OwnerKey:     "id",
+			DisplayField: "name",
+		},
+	}
+}
+
+// MorphMany creates the inverse of a MorphTo relation: the records of the
+// related resource whose morphName columns point to the record.
+//
+//	engine.MorphMany("comments", "comments", "commentable")
+//
+// Its records are listed and created by a MorphManyManager.
+func MorphMany(name, relatedSlug, morphName string) *RelationBuilder {
+	return &RelationBuilder{
+		relation: &Relation{
+			Name:         name,
+			Type:         RelationMorphMany,
+			RelatedSlug:  relatedSlug,
+			ForeignKey:   morphName + "_id",
+			MorphType:    morphName + "_type",
+			OwnerKey:     "id",
+			DisplayField: "name",
+		},
+	}
+}
+
+// MorphType sets the type column of a polymorphic relation.
+func (rb *RelationBuilder) MorphType(column string) *RelationBuilder {
+	rb.relation.MorphType = column
+	return rb
+}
+
+// MorphTypes restricts the resources a morph-to relation may point to.
+func (rb *RelationBuilder) MorphTypes(slugs ...string) *RelationBuilder {
+	rb.relation.MorphTypes = append(rb.relation.MorphTypes, slugs...)
+	return rb
+}
+
+// ResolveMorphTo returns the resource and the record item points to through
+// a morph-to relation, or nils when its columns are empty.
+func ResolveMorphTo(ctx context.Context, relation *Relation, item any) (Resource, any, error) {
+	typ, id := relationKey(item, relation.MorphType), relationKey(item, relation.ForeignKey)
+	if typ == "" || id == "" {
+		return nil, nil, nil
+	}
+	res := morphTarget(ctx, relation, typ)
+	if res == nil {
+		return nil, nil, fmt.Errorf("relation %s: resource %q not found", relation.Name, typ)
+	}
+	target, err := res.Get(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("relation %s: %w", relation.Name, err)
+	}
+	return res, target, nil
+}
+
+// morphTarget returns the resource of slug when the relation may point to
+// it, or nil.
+func morphTarget(ctx context.Context, relation *Relation, slug string) Resource {
+	if len(relation.MorphTypes) > 0 && !slices.Contains(relation.MorphTypes, slug) {
+		return nil
+	}
+	return findRelatedResource(ctx, slug)
+}
+
+// morphTargets returns the resources a morph-to relation may point to: its
+// MorphTypes, or the resources of the panel of ctx, else of all panels.
+func morphTargets(ctx context.Context, relation *Relation) []Resource {
+	var targets []Resource
+	if len(relation.MorphTypes) > 0 {
+		for _, slug := range relation.MorphTypes {
+			if res := findRelatedResource(ctx, slug); res != nil {
+				targets = append(targets, res)
+			}
+		}
+		return targets
+	}
+	panels := All()
+	if p := GetPanelFromContext(ctx); p != nil {
+		panels = []*Panel{p}
+	}
+	seen := make(map[string]bool)
+	for _, p := range panels {
+		for _, res := range p.Resources {
+			if !seen[res.Slug()] {
+				seen[res.Slug()] = true
+				targets = append(targets, res)
+			}
+		}
+	}
+	return targets
+}
+
+// MorphOptionGroup is the group of options of a morph-to select for one
+// resource.
+type MorphOptionGroup struct {
+	Type    string // slug of the resource
+	Label   string
+	Options []SelectOption // values joined by MorphValue
+}
+
+// MorphValue joins the type and the ID of a morph-to relation into the value
+// of a select option, e.g. "posts:12".
+func MorphValue(typ, id string) string {
+	return typ + ":" + id
+}
+
+// ParseMorphValue splits a value of MorphValue, ok false when it is not one.
+func ParseMorphValue(value string) (typ, id string, ok bool) {
+	typ, id, ok = strings.Cut(value, ":")
+	if !ok || typ == "" || id == "" {
+		return "", "", false
+	}
+	return typ, id, true
+}
+
+// GetMorphOptions fetches the options of a select for a morph-to relation,
+// grouped by target resource. The option of selectedType and selectedID is
+// selected.
+func GetMorphOptions(ctx context.Context, relation *Relation, selectedType string, selectedID any) ([]MorphOptionGroup, error) {
+	var groups []MorphOptionGroup
+	for _, res := range morphTargets(ctx, relation) {
+		target := *relation
+		target.RelatedSlug = res.Slug()
+		var selected any
+		if selectedType == res.Slug() {
+			selected = selectedID
+		}
+		opts, err := SearchRelationOptions(ctx, &target, selected, "", 0)
+		if err != nil {
+			return nil, err
+		}
+		group := MorphOptionGroup{Type: res.Slug(), Label: res.PluralLabel(), Options: opts.Options}
+		for i, opt := range group.Options {
+			group.Options[i].Value = MorphValue(res.Slug(), opt.Value)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// MorphSelect returns a select field named after a morph-to relation with
+// the records of its target resources grouped by resource, the one item
+// points to selected. Read the choice back with BindMorphTo.
+func MorphSelect(ctx context.Context, relation *Relation, item any) (*formPkg.SelectInput, error) {
+	var typ, id string
+	if item != nil {
+		typ, id = relationKey(item, relation.MorphType), relationKey(item, relation.ForeignKey)
+	}
+	groups, err := GetMorphOptions(ctx, relation, typ, id)
+	if err != nil {
+		return nil, err
+	}
+	field := formPkg.Select(relation.Name).Label(humanize(relation.Name))
+	optGroups := make([]formPkg.SelectOptGroup, 0, len(groups))
+	for _, g := range groups {
+		options := make([]formPkg.SelectOption, 0, len(g.Options))
+		for _, opt := range g.Options {
+			options = append(options, formPkg.SelectOption{Value: opt.Value, Label: opt.Label})
+		}
+		optGroups = append(optGroups, formPkg.SelectOptGroup{Label: g.Label, Options: options})
+	}
+	field.WithGroups(optGroups)
+	if typ != "" && id != "" {
+		field.Default(MorphValue(typ, id))
+	}
+	return field, nil
+}
+
+// BindMorphTo reads the value of a MorphSelect from the form of r and sets
+// the type and ID columns of the relation in the form, so that the Create
+// and Update of the resource read them like any other field. It fails when
+// the value points to a resource the relation may not point to.
+func BindMorphTo(ctx context.Context, r *http.Request, relation *Relation) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	var typ, id string
+	if value := r.Form.Get(relation.Name); value != "" {
+		var ok bool
+		typ, id, ok = ParseMorphValue(value)
+		if !ok || morphTarget(ctx, relation, typ) == nil {
+			return fmt.Errorf("relation %s: invalid value %q", relation.Name, value)
+		}
+	}
+	setFormValue(r, relation.MorphType, typ)
+	setFormValue(r, relation.ForeignKey, id)
+	return nil
+}
+
+// setFormValue sets a field of the parsed form of r.
+func setFormValue(r *http.Request, key, value string) {
+	r.Form.Set(key, value)
+	if r.PostForm != nil {
+		r.PostForm.Set(key, value)
+	}
+}
+
+// MorphManyManager is the relation manager of a MorphMany relation: it
+// lists the records of the related resource pointing to the parent record,
+// creates them with the type and ID columns set, and deletes them. Embed it
+// to override its Columns or Form.
+//
+//	engine.NewMorphManyManager("posts", engine.MorphMany("comments", "comments", "commentable").Build(), "Comments")
+type MorphManyManager struct {
+	*BaseRelationManager
+	parent   string
+	relation *Relation
+}
+
+// NewMorphManyManager creates the manager of relation for the records of the
+// resource parentSlug, the value of their type column.
+func NewMorphManyManager(parentSlug string, relation *Relation, label string) *MorphManyManager {
+	return &MorphManyManager{
+		BaseRelationManager: NewBaseRelationManager(relation.Name, label, relation.Name, RelationMorphMany),
+		parent:              parentSlug,
+		relation:            relation,
+	}
+}
+
+// related returns the related resource.
+func (m *MorphManyManager) related(ctx context.Context) (Resource, error) {
+	res := findRelatedResource(ctx, m.relation.RelatedSlug)
+	if res == nil {
+		return nil, fmt.Errorf("relation %s: resource %q not found", m.relation.Name, m.relation.RelatedSlug)
+	}
+	return res, nil
+}
+
+// ListRelated lists the records pointing to the parent record, through the
+// ListWhereIn of the related resource when it has one.
+func (m *MorphManyManager) ListRelated(ctx context.Context, parentID string) ([]any, error) {
+	res, err := m.related(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := listWhereIn(ctx, res, m.relation.ForeignKey, []string{parentID}, map[string]bool{parentID: true})
+	if err != nil {
+		return nil, fmt.Errorf("relation %s: %w", m.relation.Name, err)
+	}
+	related := make([]any, 0, len(items))
+	for _, item := range items {
+		if relationKey(item, m.relation.MorphType) == m.parent && relationKey(item, m.relation.ForeignKey) == parentID {
+			related = append(related, item)
+		}
+	}
+	return related, nil
+}
+
+// CreateRelated creates a record of the related resource from the form of
+// r, pointing to the parent record.
+func (m *MorphManyManager) CreateRelated(ctx context.Context, parentID string, r *http.Request) error {
+	res, err := m.related(ctx)
+	if err != nil {
+		return err
+	}
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	setFormValue(r, m.relation.MorphType, m.parent)
+	setFormValue(r, m.relation.ForeignKey, parentID)
+	return res.Create(ctx, r)
+}
+
+// DeleteRelated deletes a record of the related resource, when it points
+// to the parent record.
+func (m *MorphManyManager) DeleteRelated(ctx context.Context, parentID, relatedID string) error {
+	res, err := m.related(ctx)
+	if err != nil {
+		return err
+	}
+	item, err := res.Get(ctx, relatedID)
+	if err != nil {
+		return err
+	}
+	if relationKey(item, m.relation.MorphType) != m.parent || relationKey(item, m.relation.ForeignKey) != parentID {
+		return fmt.Errorf("relation %s: record %s does not belong to %s %s", m.relation.Name, relatedID, m.parent, parentID)
+	}
+	return res.Delete(ctx, relatedID)
+}
+
+// Columns shows the DisplayField of the related records.
+func (m *MorphManyManager) Columns() []Column {
+	return []Column{{Key: m.relation.DisplayField, Label: humanize(m.relation.DisplayField), Type: "text"}}
+}
+
+// CanAttach is false: morph-many records are created, not attached.
+func (m *MorphManyManager) CanAttach(_ context.Context) bool { return false }
@@ -0,0 +1,167 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type note struct {
+	ID          int
+	Body        string
+	NotableType string
+	NotableID   int
+	Notable     any
+}
+
+// notesResource stores notes pointing to records of any resource.
+type notesResource struct {
+	*mockResource
+	notes   []any
+	created url.Values
+	queries int
+}
+
+func (r *notesResource) List(_ context.Context) ([]any, error) {
+	r.queries++
+	return r.notes, nil
+}
+
+func (r *notesResource) Get(_ context.Context, id string) (any, error) {
+	for _, n := range r.notes {
+		if getItemID(n) == id {
+			return n, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *notesResource) Create(_ context.Context, req *http.Request) error {
+	r.created = req.PostForm
+	return nil
+}
+
+// notedResource eager-loads what its notes point to.
+type notedResource struct {
+	*mockResource
+}
+
+func (r *notedResource) GetRelations() []*Relation {
+	return []*Relation{MorphTo("notable").Eager().Build()}
+}
+
+func morphPanelContext(notes *notesResource) context.Context {
+	authors := &authorsResource{mockResource: newMockResource("authors"), authors: []any{
+		&author{ID: 1, FullName: "Alice"},
+		&author{ID: 2, FullName: "Bob"},
+	}}
+	p := NewPanel("morph-test").AddResources(authors, notes)
+	return context.WithValue(context.Background(), ContextKeyPanel, p)
+}
+
+func TestEagerLoad_morphTo(t *testing.T) {
+	notes := &notesResource{mockResource: newMockResource("notes"), notes: []any{
+		&note{ID: 1, NotableType: "authors", NotableID: 2},
+		&note{ID: 2, NotableType: "notes", NotableID: 1},
+		&note{ID: 3},
+	}}
+	notes.notes = append(notes.notes, &note{ID: 4, NotableType: "authors", NotableID: 1})
+	ctx := morphPanelContext(notes)
+
+	items := notes.notes
+	if err := EagerLoad(ctx, &notedResource{newMockResource("notes")}, items); err != nil {
+		t.Fatal(err)
+	}
+	if a, ok := items[0].(*note).Notable.(*author); !ok || a.FullName != "Bob" {
+		t.Errorf("expected Bob, got %v", items[0].(*note).Notable)
+	}
+	if n, ok := items[1].(*note).Notable.(*note); !ok || n.ID != 1 {
+		t.Errorf("expected the first note, got %v", items[1].(*note).Notable)
+	}
+	if items[2].(*note).Notable != nil {
+		t.Error("expected nothing loaded without a type")
+	}
+	if notes.queries != 1 {
+		t.Errorf("expected one query per target resource, got %d", notes.queries)
+	}
+
+	res, target, err := ResolveMorphTo(ctx, MorphTo("notable").MorphTypes("authors").Build(), items[3])
+	if err != nil || res.Slug() != "authors" || target.(*author).FullName != "Alice" {
+		t.Errorf("expected Alice resolved, got %v %v %v", res, target, err)
+	}
+	if _, _, err := ResolveMorphTo(ctx, MorphTo("notable").MorphTypes("authors").Build(), items[1]); err == nil {
+		t.Error("expected an error for a type the relation may not point to")
+	}
+}
+
+func TestMorphSelect(t *testing.T) {
+	ctx := morphPanelContext(&notesResource{mockResource: newMockResource("notes")})
+	rel := MorphTo("notable").MorphTypes("authors").DisplayField("full_name").Build()
+
+	field, err := MorphSelect(ctx, rel, &note{NotableType: "authors", NotableID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := field.Render().Render(ctx, &buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`name="notable"`, `<optgroup label="authorss">`, `<option value="authors:2" selected>`, "Alice"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in the select, got %s", want, buf.String())
+		}
+	}
+
+	form := func(value string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/notes", strings.NewReader(url.Values{"notable": {value}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req
+	}
+	req := form("authors:2")
+	if err := BindMorphTo(ctx, req, rel); err != nil {
+		t.Fatal(err)
+	}
+	if req.PostForm.Get("notable_type") != "authors" || req.PostForm.Get("notable_id") != "2" {
+		t.Errorf("expected the type and ID columns set, got %v", req.PostForm)
+	}
+	for _, value := range []string{"notes:1", "authors"} {
+		if err := BindMorphTo(ctx, form(value), rel); err == nil {
+			t.Errorf("expected %q refused", value)
+		}
+	}
+}
+
+func TestMorphManyManager(t *testing.T) {
+	notes := &notesResource{mockResource: newMockResource("notes"), notes: []any{
+		&note{ID: 1, NotableType: "authors", NotableID: 2},
+		&note{ID: 2, NotableType: "posts", NotableID: 2},
+		&note{ID: 3, NotableType: "authors", NotableID: 2},
+	}}
+	ctx := morphPanelContext(notes)
+	m := NewMorphManyManager("authors", MorphMany("notes", "notes", "notable").Build(), "Notes")
+
+	related, err := m.ListRelated(ctx, "2")
+	if err != nil || len(related) != 2 {
+		t.Fatalf("expected the 2 notes of the author, got %v %v", related, err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("body=hi"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if err := m.CreateRelated(ctx, "2", req); err != nil {
+		t.Fatal(err)
+	}
+	if notes.created.Get("notable_type") != "authors" || notes.created.Get("notable_id") != "2" || notes.created.Get("body") != "hi" {
+		t.Errorf("expected the note created for the author, got %v", notes.created)
+	}
+
+	if err := m.DeleteRelated(ctx, "2", "2"); err == nil {
+		t.Error("expected the note of a post not deleted")
+	}
+	if err := m.DeleteRelated(ctx, "2", "3"); err != nil || notes.deleteCalledWith != "3" {
+		t.Errorf("expected the note deleted, got %v", err)
+	}
+}
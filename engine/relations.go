@@ -7,9 +7,13 @@ import (
 	"io"
 	"net/http"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/apperrors"
+	"github.com/bozz33/sublimeadmin/ui/components"
 )
 
 // RelationType defines the type of relationship.
@@ -20,18 +24,50 @@ const (
 	RelationHasOne     RelationType = "has_one"
 	RelationHasMany    RelationType = "has_many"
 	RelationManyToMany RelationType = "many_to_many"
+	RelationMorphTo    RelationType = "morph_to"   // see MorphTo
+	RelationMorphMany  RelationType = "morph_many" // see MorphMany
 )
 
 // Relation defines a relationship between resources.
 type Relation struct {
-	Name         string       // Name of the relation (e.g., "author", "posts")
-	Type         RelationType // Type of relation
-	RelatedSlug  string       // Slug of the related resource
-	ForeignKey   string       // Foreign key field name
-	OwnerKey     string       // Owner key field name (usually "id")
-	PivotTable   string       // Pivot table for many-to-many
-	DisplayField string       // Field to display in select/list
-	Eager        bool         // Whether to eager load by default
+	Name         string         // Name of the relation (e.g., "author", "posts")
+	Type         RelationType   // Type of relation
+	RelatedSlug  string         // Slug of the related resource
+	ForeignKey   string         // Foreign key field name
+	OwnerKey     string         // Owner key field name (usually "id")
+	PivotTable   string         // Pivot table for many-to-many
+	DisplayField string         // Field to display in select/list
+	Eager        bool           // Whether to eager load by default
+	PivotFields  []PivotField   // Extra columns of the pivot table for many-to-many
+	OnDelete     OnDeleteAction // What happens to the related records when the record is deleted
+	MorphType    string         // Type column of a polymorphic relation, next to ForeignKey
+	MorphTypes   []string       // Slugs of the resources a morph-to relation may point to (default: all)
+}
+
+// OnDeleteAction is the policy applied to the related records of a record
+// being deleted. The CRUDHandler enforces it before deleting the record and
+// the delete modal describes it (see DeleteImpact).
+type OnDeleteAction string
+
+const (
+	// OnDeleteCascade deletes the related records with the record, once the
+	// user chose to delete them too ("delete with children").
+	OnDeleteCascade OnDeleteAction = "CASCADE"
+	// OnDeleteSetNull unlinks the related records, left to the store.
+	OnDeleteSetNull OnDeleteAction = "SET NULL"
+	// OnDeleteRestrict refuses to delete a record that has related records.
+	OnDeleteRestrict OnDeleteAction = "RESTRICT"
+)
+
+// PivotField is an extra column of the pivot table of a many-to-many
+// relation (e.g. role, quantity, starts_at), filled when attaching a record
+// and editable in the sub-table of its relation manager.
+type PivotField struct {
+	Name     string         // column, and key of the values (see RelationPivotManager)
+	Label    string         // default: from the name
+	Type     string         // input: text (default), number, date, datetime-local or select
+	Options  []SelectOption // choices of a select
+	Required bool
 }
 
 // RelationBuilder provides a fluent API for defining relations.
@@ -110,6 +146,26 @@ func (rb *RelationBuilder) PivotTable(table string) *RelationBuilder {
 	return rb
 }
 
+// WithPivot declares the extra columns of the pivot table of a many-to-many
+// relation:
+//
+//	engine.ManyToMany("members", "users").PivotTable("project_user").WithPivot(
+//		engine.PivotField{Name: "role", Type: "select", Options: roles, Required: true},
+//		engine.PivotField{Name: "starts_at", Type: "date"},
+//	)
+func (rb *RelationBuilder) WithPivot(fields ...PivotField) *RelationBuilder {
+	rb.relation.PivotFields = append(rb.relation.PivotFields, fields...)
+	return rb
+}
+
+// OnDelete sets what happens to the related records of a has-one or
+// has-many relation when the record is deleted. The links of a many-to-many
+// relation are always removed, only OnDeleteRestrict applies to it.
+func (rb *RelationBuilder) OnDelete(action OnDeleteAction) *RelationBuilder {
+	rb.relation.OnDelete = action
+	return rb
+}
+
 // DisplayField sets the field to display.
 func (rb *RelationBuilder) DisplayField(field string) *RelationBuilder {
 	rb.relation.DisplayField = field
@@ -158,8 +214,18 @@ type SelectOption struct {
 	Selected bool
 }
 
-// GetRelationOptions fetches options for a relation from the registry.
+// GetRelationOptions fetches the options of a select for a relation from
+// its related resource, found by slug in the panel of ctx then in the
+// registered panels. The option of selectedID is selected.
 func GetRelationOptions(ctx context.Context, relation *Relation, selectedID any) (*RelationOptions, error) {
+	return SearchRelationOptions(ctx, relation, selectedID, "", 0)
+}
+
+// SearchRelationOptions is GetRelationOptions restricted to the related
+// items matching search (through ResourceSearchable when the resource
+// implements it), at most limit when positive. The selected item is kept
+// when the limit leaves it out.
+func SearchRelationOptions(ctx context.Context, relation *Relation, selectedID any, search string, limit int) (*RelationOptions, error) {
 	opts := &RelationOptions{
 		Relation:    relation,
 		Options:     make([]SelectOption, 0),
@@ -169,13 +235,89 @@ func GetRelationOptions(ctx context.Context, relation *Relation, selectedID any)
 		EmptyLabel:  "-- None --",
 	}
 
-	// This would be implemented to fetch from the related resource
-	// For now, return empty options - the actual implementation would
-	// use the registry to find the related resource and fetch its data
+	res := findRelatedResource(ctx, relation.RelatedSlug)
+	if res == nil {
+		return nil, fmt.Errorf("relation %s: resource %q not found", relation.Name, relation.RelatedSlug)
+	}
+	var items []any
+	var err error
+	search = strings.TrimSpace(search)
+	if s, ok := res.(ResourceSearchable); ok && search != "" {
+		items, err = s.Search(ctx, search)
+	} else {
+		items, err = res.List(ctx)
+		if err == nil && search != "" {
+			query := strings.ToLower(search)
+			items = slices.DeleteFunc(items, func(item any) bool {
+				return !strings.Contains(strings.ToLower(relationLabel(item, relation)), query)
+			})
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("relation %s: %w", relation.Name, err)
+	}
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
 
+	selected := ""
+	if selectedID != nil {
+		selected = fmt.Sprint(selectedID)
+	}
+	found := false
+	for _, item := range items {
+		value := relationValue(item, relation)
+		isSelected := selected != "" && value == selected
+		found = found || isSelected
+		opts.Options = append(opts.Options, SelectOption{Value: value, Label: relationLabel(item, relation), Selected: isSelected})
+	}
+	if selected != "" && !found {
+		if item, err := res.Get(ctx, selected); err == nil && item != nil {
+			opts.Options = append([]SelectOption{{Value: relationValue(item, relation), Label: relationLabel(item, relation), Selected: true}}, opts.Options...)
+		}
+	}
 	return opts, nil
 }
 
+// findRelatedResource returns the resource of slug, from the panel of ctx
+// first, or nil.
+func findRelatedResource(ctx context.Context, slug string) Resource {
+	panels := All()
+	if p := GetPanelFromContext(ctx); p != nil {
+		panels = append([]*Panel{p}, panels...)
+	}
+	for _, p := range panels {
+		for _, r := range p.Resources {
+			if r.Slug() == slug {
+				return r
+			}
+		}
+	}
+	return nil
+}
+
+// relationValue returns the value of the option of a related item: its
+// OwnerKey, or its ID.
+func relationValue(item any, relation *Relation) string {
+	if relation.OwnerKey != "" {
+		if v, ok := recordField(item, relation.OwnerKey); ok {
+			return fmt.Sprint(v.Interface())
+		}
+	}
+	return getItemID(item)
+}
+
+// relationLabel returns the label of the option of a related item: its
+// DisplayField, or its value.
+func relationLabel(item any, relation *Relation) string {
+	if relation.DisplayField != "" {
+		if v, ok := recordField(item, relation.DisplayField); ok {
+			return fmt.Sprint(v.Interface())
+		}
+	}
+	return relationValue(item, relation)
+}
+
 // ExtractRelatedID extracts the related ID from an item using reflection.
 func ExtractRelatedID(item any, foreignKey string) any {
 	val := reflect.ValueOf(item)
@@ -235,13 +377,17 @@ type RelationSchema struct {
 
 // GetRelationSchema returns schema information for a relation.
 func GetRelationSchema(relation *Relation) *RelationSchema {
+	onDelete := OnDeleteSetNull
+	if relation.OnDelete != "" {
+		onDelete = relation.OnDelete
+	}
 	return &RelationSchema{
 		Name:       relation.Name,
 		Type:       relation.Type,
 		Related:    relation.RelatedSlug,
 		ForeignKey: relation.ForeignKey,
 		Nullable:   true,
-		OnDelete:   "SET NULL",
+		OnDelete:   string(onDelete),
 		OnUpdate:   "CASCADE",
 	}
 }
@@ -339,6 +485,19 @@ func (b *BaseRelationManager) SetIcon(icon string) *BaseRelationManager {
 	return b
 }
 
+// RelationPivotManager is an optional interface for the relation managers
+// of many-to-many relations with pivot fields (see RelationBuilder.WithPivot),
+// found by the RelationName of the manager among the relations of the
+// resource (see RelationAware). The values of the pivot fields are keyed by
+// name; those of the related records listed are read from their fields
+// (e.g. Role for "role").
+type RelationPivotManager interface {
+	// AttachRelatedPivot attaches a related item with the values of its pivot fields.
+	AttachRelatedPivot(ctx context.Context, parentID, relatedID string, pivot map[string]string) error
+	// UpdatePivot updates the values of the pivot fields of an attached item.
+	UpdatePivot(ctx context.Context, parentID, relatedID string, pivot map[string]string) error
+}
+
 // RelationManagerAware is the interface for resources that expose relation managers.
 type RelationManagerAware interface {
 	GetRelationManagers() []RelationManager
@@ -365,14 +524,19 @@ func GetRelationManagers(ctx context.Context) []RelationManager {
 // RelationManagerHandler — HTTP sub-router for relation manager endpoints
 // ---------------------------------------------------------------------------
 
-// RelationManagerHandler handles HTTP requests for relation manager sub-tables.
+// RelationManagerHandler handles HTTP requests for relation manager sub-tables,
+// routed by the CRUDHandler of the resource under /{slug}.
 // Routes handled:
 //
 //	GET    /{parentID}/relations/{name}              -> list related items (JSON)
 //	POST   /{parentID}/relations/{name}              -> create related item
 //	POST   /{parentID}/relations/{name}/attach       -> attach (ManyToMany)
-//	POST   /{parentID}/relations/{name}/detach/{id}  -> detach (ManyToMany)
+//	POST   /{parentID}/relations/{name}/pivot/{id}   -> update pivot fields (ManyToMany)
+//	DELETE /{parentID}/relations/{name}/detach/{id}  -> detach (ManyToMany)
 //	DELETE /{parentID}/relations/{name}/{id}         -> delete related item
+//
+// Requests of the sub-table (HX-Request: true, see components.RelationTable)
+// are answered with the page of the table, ?page=N for GET.
 type RelationManagerHandler struct {
 	resource Resource
 	managers map[string]RelationManager
@@ -413,23 +577,27 @@ func (h *RelationManagerHandler) ServeHTTP(w http.ResponseWriter, r *http.Reques
 	}
 	rm, exists := h.managers[relationName]
 	if !exists {
-		http.Error(w, "relation manager not found: "+relationName, http.StatusNotFound)
+		apperrors.Handle(w, r, apperrors.NotFoundf("relation manager not found: %s", relationName))
 		return
 	}
 	ctx := r.Context()
 	switch r.Method {
 	case http.MethodGet:
 		if subAction == "form" {
-			h.handleRelationForm(w, rm, parentID, ctx)
+			h.handleRelationForm(w, r, rm, parentID, ctx)
 		} else {
-			h.handleRelationGET(w, rm, parentID, relationName, ctx)
+			h.handleRelationGET(w, r, rm, parentID, relationName, ctx)
 		}
 	case http.MethodPost:
+		if subAction == "pivot" {
+			h.handlePivotPOST(w, r, rm, parentID, relatedID, ctx)
+			return
+		}
 		h.handleRelationPOST(w, r, rm, parentID, subAction, ctx)
 	case http.MethodDelete:
-		h.handleRelationDELETE(w, rm, parentID, relatedID, subAction, ctx)
+		h.handleRelationDELETE(w, r, rm, parentID, relatedID, subAction, ctx)
 	default:
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		apperrors.Handle(w, r, apperrors.MethodNotAllowed(""))
 	}
 }
 
@@ -446,6 +614,8 @@ func (h *RelationManagerHandler) parseRelationPath(r *http.Request) (parentID, r
 		switch {
 		case strings.HasPrefix(tail, "detach/"):
 			subAction, relatedID = "detach", strings.TrimPrefix(tail, "detach/")
+		case strings.HasPrefix(tail, "pivot/"):
+			subAction, relatedID = "pivot", strings.TrimPrefix(tail, "pivot/")
 		case tail == "attach":
 			subAction = "attach"
 		case tail == "form":
@@ -458,23 +628,28 @@ func (h *RelationManagerHandler) parseRelationPath(r *http.Request) (parentID, r
 }
 
 func (h *RelationManagerHandler) handleRelationForm(
-	w http.ResponseWriter, rm RelationManager, parentID string, ctx context.Context,
+	w http.ResponseWriter, r *http.Request, rm RelationManager, parentID string, ctx context.Context,
 ) {
 	if !rm.CanCreate(ctx) {
-		http.Error(w, "forbidden", http.StatusForbidden)
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
 		return
 	}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	comp := rm.Form(ctx, parentID)
 	if err := comp.Render(ctx, w); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, ""))
 	}
 }
 
-func (h *RelationManagerHandler) handleRelationGET(w http.ResponseWriter, rm RelationManager, parentID, relationName string, ctx context.Context) {
+func (h *RelationManagerHandler) handleRelationGET(w http.ResponseWriter, r *http.Request, rm RelationManager, parentID, relationName string, ctx context.Context) {
+	if isRelationFragmentRequest(r) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		h.renderTable(w, r, rm, parentID, page)
+		return
+	}
 	items, err := rm.ListRelated(ctx, parentID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, ""))
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -491,51 +666,175 @@ func (h *RelationManagerHandler) handleRelationGET(w http.ResponseWriter, rm Rel
 func (h *RelationManagerHandler) handleRelationPOST(w http.ResponseWriter, r *http.Request, rm RelationManager, parentID, subAction string, ctx context.Context) {
 	if subAction == "attach" {
 		if !rm.CanAttach(ctx) {
-			http.Error(w, "forbidden", http.StatusForbidden)
+			apperrors.Handle(w, r, apperrors.Forbidden(""))
 			return
 		}
 		relID := r.FormValue("related_id")
 		if relID == "" {
-			http.Error(w, "related_id required", http.StatusBadRequest)
+			apperrors.Handle(w, r, apperrors.BadRequest("related_id required"))
 			return
 		}
-		if err := rm.AttachRelated(ctx, parentID, relID); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		var err error
+		if pm, fields := h.pivot(rm); pm != nil {
+			pivot, perr := pivotValues(r, fields)
+			if perr != nil {
+				apperrors.Handle(w, r, perr)
+				return
+			}
+			err = pm.AttachRelatedPivot(ctx, parentID, relID, pivot)
+		} else {
+			err = rm.AttachRelated(ctx, parentID, relID)
+		}
+		if err != nil {
+			apperrors.Handle(w, r, apperrors.Internal(err, ""))
 			return
 		}
-		http.Redirect(w, r, r.Header.Get("Referer"), http.StatusSeeOther)
+		h.changed(w, r, rm, parentID)
 		return
 	}
 	if !rm.CanCreate(ctx) {
-		http.Error(w, "forbidden", http.StatusForbidden)
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
 		return
 	}
 	if err := rm.CreateRelated(ctx, parentID, r); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apperrors.Handle(w, r, apperrors.Internal(err, ""))
 		return
 	}
-	http.Redirect(w, r, r.Header.Get("Referer"), http.StatusSeeOther)
+	h.changed(w, r, rm, parentID)
 }
 
-func (h *RelationManagerHandler) handleRelationDELETE(w http.ResponseWriter, rm RelationManager, parentID, relatedID, subAction string, ctx context.Context) {
+func (h *RelationManagerHandler) handleRelationDELETE(w http.ResponseWriter, r *http.Request, rm RelationManager, parentID, relatedID, subAction string, ctx context.Context) {
 	if subAction == "detach" {
 		if !rm.CanAttach(ctx) {
-			http.Error(w, "forbidden", http.StatusForbidden)
+			apperrors.Handle(w, r, apperrors.Forbidden(""))
 			return
 		}
 		if err := rm.DetachRelated(ctx, parentID, relatedID); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apperrors.Handle(w, r, apperrors.Internal(err, ""))
 			return
 		}
 	} else {
 		if !rm.CanDelete(ctx) {
-			http.Error(w, "forbidden", http.StatusForbidden)
+			apperrors.Handle(w, r, apperrors.Forbidden(""))
 			return
 		}
 		if err := rm.DeleteRelated(ctx, parentID, relatedID); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apperrors.Handle(w, r, apperrors.Internal(err, ""))
 			return
 		}
 	}
+	if isRelationFragmentRequest(r) {
+		h.renderTable(w, r, rm, parentID, 1)
+		return
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *RelationManagerHandler) handlePivotPOST(w http.ResponseWriter, r *http.Request, rm RelationManager, parentID, relatedID string, ctx context.Context) {
+	if !rm.CanAttach(ctx) {
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
+		return
+	}
+	pm, fields := h.pivot(rm)
+	if pm == nil || relatedID == "" {
+		apperrors.Handle(w, r, apperrors.NotFoundf("relation %s has no pivot fields", rm.Name()))
+		return
+	}
+	pivot, err := pivotValues(r, fields)
+	if err != nil {
+		apperrors.Handle(w, r, err)
+		return
+	}
+	if err := pm.UpdatePivot(ctx, parentID, relatedID, pivot); err != nil {
+		apperrors.Handle(w, r, apperrors.Internal(err, ""))
+		return
+	}
+	h.changed(w, r, rm, parentID)
+}
+
+// pivot returns rm as a RelationPivotManager with the pivot fields of its
+// relation, or nil when it has none.
+func (h *RelationManagerHandler) pivot(rm RelationManager) (RelationPivotManager, []PivotField) {
+	pm, ok := rm.(RelationPivotManager)
+	if !ok {
+		return nil, nil
+	}
+	rel := managerRelation(h.resource, rm)
+	if rel == nil || len(rel.PivotFields) == 0 {
+		return nil, nil
+	}
+	return pm, rel.PivotFields
+}
+
+// pivotValues reads the values of the pivot fields from the form of r.
+func pivotValues(r *http.Request, fields []PivotField) (map[string]string, *apperrors.AppError) {
+	values := make(map[string]string, len(fields))
+	for _, f := range fields {
+		v := strings.TrimSpace(r.FormValue(f.Name))
+		switch {
+		case v == "" && f.Required:
+			return nil, apperrors.BadRequest(pivotLabel(f) + " is required")
+		case v != "" && f.Type == "number":
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return nil, apperrors.BadRequest(pivotLabel(f) + " must be a number")
+			}
+		case v != "" && f.Type == "select" && len(f.Options) > 0:
+			if !slices.ContainsFunc(f.Options, func(o SelectOption) bool { return o.Value == v }) {
+				return nil, apperrors.BadRequest(pivotLabel(f) + " is not a valid choice")
+			}
+		}
+		values[f.Name] = v
+	}
+	return values, nil
+}
+
+// pivotLabel returns the label of a pivot field.
+func pivotLabel(f PivotField) string {
+	if f.Label != "" {
+		return f.Label
+	}
+	return humanize(f.Name)
+}
+
+// managerRelation returns the relation of rm among the relations of res
+// (see RelationAware), or nil.
+func managerRelation(res Resource, rm RelationManager) *Relation {
+	ra, ok := res.(RelationAware)
+	if !ok {
+		return nil
+	}
+	for _, rel := range ra.GetRelations() {
+		if rel.Name == rm.RelationName() {
+			return rel
+		}
+	}
+	return nil
+}
+
+// isRelationFragmentRequest reports whether r comes from the sub-table of
+// a relation (see components.RelationTable), which is answered with the
+// table.
+func isRelationFragmentRequest(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true"
+}
+
+// changed answers a successful create or attach: with the table for the
+// sub-table, else by going back to the page.
+func (h *RelationManagerHandler) changed(w http.ResponseWriter, r *http.Request, rm RelationManager, parentID string) {
+	if isRelationFragmentRequest(r) {
+		h.renderTable(w, r, rm, parentID, 1)
+		return
+	}
+	http.Redirect(w, r, r.Header.Get("Referer"), http.StatusSeeOther)
+}
+
+// renderTable writes the page of the sub-table of rm for the record
+// parentID, without the layout.
+func (h *RelationManagerHandler) renderTable(w http.ResponseWriter, r *http.Request, rm RelationManager, parentID string, page int) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Add("Vary", "HX-Request")
+	tab := relationTab(r.Context(), h.resource, rm, parentID, page)
+	if err := components.RelationTable(tab).Render(r.Context(), w); err != nil {
+		apperrors.Handle(w, r, apperrors.Internal(err, ""))
+	}
+}
@@ -170,6 +170,64 @@ func TestExtractRelatedID(t *testing.T) {
 	}
 }
 
+// authorsResource lists authors for the relation options.
+type authorsResource struct {
+	*mockResource
+	authors []any
+}
+
+func (r *authorsResource) List(_ context.Context) ([]any, error) { return r.authors, nil }
+
+func (r *authorsResource) Get(_ context.Context, id string) (any, error) {
+	for _, a := range r.authors {
+		if getItemID(a) == id {
+			return a, nil
+		}
+	}
+	return nil, nil
+}
+
+type author struct {
+	ID       int
+	FullName string `json:"full_name"`
+}
+
+func TestGetRelationOptions(t *testing.T) {
+	authors := &authorsResource{mockResource: newMockResource("authors")}
+	for i, name := range []string{"Alice", "Bob", "Alina"} {
+		authors.authors = append(authors.authors, &author{ID: i + 1, FullName: name})
+	}
+	p := NewPanel("relations-test").AddResources(authors)
+	ctx := context.WithValue(context.Background(), ContextKeyPanel, p)
+	rel := BelongsTo("author", "authors").DisplayField("full_name").Build()
+
+	opts, err := GetRelationOptions(ctx, rel, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts.Options) != 3 {
+		t.Fatalf("expected 3 options, got %d", len(opts.Options))
+	}
+	if got := opts.Options[1]; got.Value != "2" || got.Label != "Bob" || !got.Selected {
+		t.Errorf("unexpected option %+v", got)
+	}
+	if opts.Options[0].Selected {
+		t.Error("expected only the option of 2 selected")
+	}
+
+	opts, err = SearchRelationOptions(ctx, rel, 3, "ali", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts.Options) != 2 || opts.Options[0].Label != "Alina" || !opts.Options[0].Selected || opts.Options[1].Label != "Alice" {
+		t.Errorf("expected the selected option kept beyond the limit, got %+v", opts.Options)
+	}
+
+	if _, err := GetRelationOptions(ctx, BelongsTo("tag", "tags").Build(), nil); err == nil {
+		t.Error("expected an error for an unknown resource")
+	}
+}
+
 func TestSetRelatedID(t *testing.T) {
 	item := &testItem{AuthorID: 0}
 
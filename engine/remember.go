@@ -0,0 +1,398 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	authpkg "github.com/bozz33/sublimeadmin/auth"
+	"github.com/bozz33/sublimeadmin/clientip"
+	"github.com/bozz33/sublimeadmin/mailer"
+	authtemplates "github.com/bozz33/sublimeadmin/views/auth"
+)
+
+const (
+	// deviceCookie identifies the browser of a user across sessions.
+	deviceCookie = "_device"
+	// rememberCookie holds the remember token of the device, "ID:secret".
+	rememberCookie = "_remember"
+	// deviceSessionKey holds the device of a session, which ends when the
+	// device is revoked.
+	deviceSessionKey = "auth.device"
+)
+
+// Device is a browser a user signed in from. With "Remember me" checked,
+// it holds the remember token signing the user in again once the session
+// has expired; the token rotates on each use.
+type Device struct {
+	ID        string // random, in the device cookie of the browser
+	UserID    int
+	Name      string // e.g. "Chrome on macOS", from the User-Agent
+	IP        string // of the last sign-in
+	FirstSeen time.Time
+	LastSeen  time.Time
+
+	TokenHash    string    // SHA-256 of the secret of the remember token, "" when not remembered
+	TokenExpires time.Time // when the remember token expires
+}
+
+// Remembered reports whether the device signs its user in without a
+// password.
+func (d Device) Remembered() bool {
+	return d.TokenHash != "" && time.Now().Before(d.TokenExpires)
+}
+
+// DeviceStore persists the devices of the users.
+// Implementations must be safe for concurrent use.
+//
+// MemoryDeviceStore (default) keeps the devices per process. Implement
+// DeviceStore on top of a table to keep them across restarts.
+type DeviceStore interface {
+	// Devices returns the devices of the user, the last seen first.
+	Devices(ctx context.Context, userID int) ([]Device, error)
+	// Device returns the device with id, nil when unknown.
+	Device(ctx context.Context, id string) (*Device, error)
+	// SaveDevice creates or replaces the device with the ID of d.
+	SaveDevice(ctx context.Context, d Device) error
+	// DeleteDevice forgets the device, revoking its remember token.
+	DeleteDevice(ctx context.Context, id string) error
+}
+
+// MemoryDeviceStore is an in-process DeviceStore.
+type MemoryDeviceStore struct {
+	mu      sync.RWMutex
+	devices map[string]Device
+}
+
+// NewMemoryDeviceStore creates an empty in-memory store.
+func NewMemoryDeviceStore() *MemoryDeviceStore {
+	return &MemoryDeviceStore{devices: make(map[string]Device)}
+}
+
+// Devices implements DeviceStore.
+func (s *MemoryDeviceStore) Devices(_ context.Context, userID int) ([]Device, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var devices []Device
+	for _, d := range s.devices {
+		if d.UserID == userID {
+			devices = append(devices, d)
+		}
+	}
+	slices.SortFunc(devices, func(a, b Device) int { return b.LastSeen.Compare(a.LastSeen) })
+	return devices, nil
+}
+
+// Device implements DeviceStore.
+func (s *MemoryDeviceStore) Device(_ context.Context, id string) (*Device, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	d, ok := s.devices[id]
+	if !ok {
+		return nil, nil
+	}
+	return &d, nil
+}
+
+// SaveDevice implements DeviceStore.
+func (s *MemoryDeviceStore) SaveDevice(_ context.Context, d Device) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.devices[d.ID] = d
+	return nil
+}
+
+// DeleteDevice implements DeviceStore.
+func (s *MemoryDeviceStore) DeleteDevice(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.devices, id)
+	return nil
+}
+
+// WithRememberMe recognizes the devices the users sign in from and makes
+// "Remember me" sign them in again for lifetime (30 days when 0) once
+// their session has expired. The remember token is hashed in store, bound
+// to the browser of the device and rotated on each use. The devices are
+// listed on the profile page, where the user can revoke them. Defaults to a
+// MemoryDeviceStore when store is nil.
+func (p *Panel) WithRememberMe(store DeviceStore, lifetime time.Duration) *Panel {
+	if store == nil {
+		store = NewMemoryDeviceStore()
+	}
+	if lifetime <= 0 {
+		lifetime = 30 * 24 * time.Hour
+	}
+	p.deviceStore = store
+	p.rememberFor = lifetime
+	return p
+}
+
+// EnableNewDeviceAlerts emails the users signing in from a device they
+// never used before, through the Mailer of the panel. It needs
+// WithRememberMe.
+func (p *Panel) EnableNewDeviceAlerts(enabled bool) *Panel {
+	p.newDeviceAlerts = enabled
+	return p
+}
+
+// devicesEnabled reports whether the panel recognizes devices.
+func (p *Panel) devicesEnabled() bool {
+	return p != nil && p.deviceStore != nil && p.AuthManager != nil
+}
+
+// rememberDevice records the device of r after user signed in, with a new
+// remember token when remember is set, and alerts the user when the device
+// is new.
+func (p *Panel) rememberDevice(w http.ResponseWriter, r *http.Request, user *authpkg.User, remember bool) {
+	ctx := r.Context()
+	now := time.Now()
+	var device Device
+	if c, err := r.Cookie(deviceCookie); err == nil {
+		if d, _ := p.deviceStore.Device(ctx, c.Value); d != nil && d.UserID == user.ID {
+			device = *d
+		}
+	}
+	if device.ID == "" {
+		known, _ := p.deviceStore.Devices(ctx, user.ID)
+		device = Device{ID: generateToken(), UserID: user.ID, FirstSeen: now}
+		// The first device of a user is not news.
+		if p.newDeviceAlerts && len(known) > 0 {
+			p.alertNewDevice(r, user)
+		}
+	}
+	device.Name = deviceName(r.UserAgent())
+	device.IP = clientip.FromRequest(r)
+	device.LastSeen = now
+	device.TokenHash, device.TokenExpires = "", time.Time{}
+	if remember {
+		p.setRememberToken(w, r, &device)
+	} else {
+		p.clearCookie(w, r, rememberCookie)
+	}
+	if err := p.deviceStore.SaveDevice(ctx, device); err != nil {
+		return
+	}
+	p.AuthManager.Session().Put(ctx, deviceSessionKey, device.ID)
+	p.setCookie(w, r, deviceCookie, device.ID, 365*24*time.Hour)
+}
+
+// setRememberToken gives device a new remember token and sets its cookie.
+func (p *Panel) setRememberToken(w http.ResponseWriter, r *http.Request, device *Device) {
+	secret := generateToken()
+	device.TokenHash = hashToken(secret)
+	device.TokenExpires = time.Now().Add(p.rememberFor)
+	p.setCookie(w, r, rememberCookie, device.ID+":"+secret, p.rememberFor)
+}
+
+// rememberLogin signs in the guests whose browser holds a valid remember
+// token, rotating it. A token whose secret does not match revokes its
+// device: it was stolen, or already used by a copy of the cookie. The
+// sessions of a revoked device are signed out.
+func (p *Panel) rememberLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if p.AuthManager.IsAuthenticatedFromRequest(r) {
+			if id := p.AuthManager.Session().GetString(ctx, deviceSessionKey); id != "" {
+				if device, err := p.deviceStore.Device(ctx, id); err == nil && device == nil {
+					_ = p.AuthManager.LogoutWithRequest(r)
+				}
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		c, err := r.Cookie(rememberCookie)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		id, secret, _ := strings.Cut(c.Value, ":")
+		device, _ := p.deviceStore.Device(ctx, id)
+		switch {
+		case device == nil || !device.Remembered():
+			p.clearCookie(w, r, rememberCookie)
+		case subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(device.TokenHash)) != 1,
+			device.Name != deviceName(r.UserAgent()):
+			p.revokeStolenDevice(ctx, device.ID)
+			p.clearCookie(w, r, rememberCookie)
+		default:
+			if user := p.rememberedUser(ctx, device.UserID); user != nil && p.AuthManager.LoginWithRequest(r, user) == nil {
+				device.LastSeen = time.Now()
+				device.IP = clientip.FromRequest(r)
+				p.setRememberToken(w, r, device)
+				_ = p.deviceStore.SaveDevice(ctx, *device)
+				p.AuthManager.Session().Put(ctx, deviceSessionKey, device.ID)
+				if passwordExpired(ctx, p.Users, user.ID) {
+					p.AuthManager.Session().Put(ctx, passwordExpiredKey, true)
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// revokeStolenDevice forgets the device id whose remember token was
+// stolen and ends its sessions, which would end on their next request
+// anyway.
+func (p *Panel) revokeStolenDevice(ctx context.Context, id string) {
+	if err := p.deviceStore.DeleteDevice(ctx, id); err != nil {
+		return
+	}
+	_ = p.endSessions(ctx, func(ctx context.Context) bool {
+		return p.AuthManager.Session().GetString(ctx, deviceSessionKey) == id
+	})
+}
+
+// rememberedUser loads the user signed in by a remember token, nil when
+// they no longer exist or cannot sign in (see canSignIn).
+func (p *Panel) rememberedUser(ctx context.Context, id int) *authpkg.User {
+	if p.Users == nil {
+		return nil
+	}
+	u, err := p.Users.GetByID(ctx, id)
+	if err != nil || u == nil || !p.canSignIn(ctx, u) {
+		return nil
+	}
+	user := &authpkg.User{ID: u.GetID(), Name: u.GetName(), Email: u.GetEmail()}
+	if withRoles, ok := u.(UserWithRoles); ok {
+		user.Roles = withRoles.GetRoles()
+	}
+	return user
+}
+
+// forgetDevice revokes the remember token of the device of r, on logout.
+func (p *Panel) forgetDevice(w http.ResponseWriter, r *http.Request) {
+	p.clearCookie(w, r, rememberCookie)
+	c, err := r.Cookie(deviceCookie)
+	if err != nil {
+		return
+	}
+	if device, _ := p.deviceStore.Device(r.Context(), c.Value); device != nil && device.TokenHash != "" {
+		device.TokenHash, device.TokenExpires = "", time.Time{}
+		_ = p.deviceStore.SaveDevice(r.Context(), *device)
+	}
+}
+
+// alertNewDevice emails user about a sign-in from the device of r.
+func (p *Panel) alertNewDevice(r *http.Request, user *authpkg.User) {
+	if user.Email == "" {
+		return
+	}
+	m := p.Mailer
+	if m == nil {
+		m = &mailer.LogMailer{}
+	}
+	_ = m.Send(mailer.Message{
+		To:      []string{user.Email},
+		Subject: "New sign-in to " + p.BrandName,
+		Body: fmt.Sprintf("Your account signed in from a new device:\n\n  %s\n  IP address: %s\n  %s\n\n"+
+			"If this was not you, change your password and revoke the device from your profile.\n",
+			deviceName(r.UserAgent()), clientip.FromRequest(r), time.Now().Format("January 2, 2006 15:04 MST")),
+	})
+}
+
+// withDevices returns ctx carrying the devices of the user of r for the
+// profile page.
+func (p *Panel) withDevices(r *http.Request) context.Context {
+	ctx := r.Context()
+	devices, err := p.deviceStore.Devices(ctx, p.AuthManager.UserIDFromRequest(r))
+	if err != nil {
+		return ctx
+	}
+	current := ""
+	if c, err := r.Cookie(deviceCookie); err == nil {
+		current = c.Value
+	}
+	rows := make([]authtemplates.DeviceRow, 0, len(devices))
+	for _, d := range devices {
+		rows = append(rows, authtemplates.DeviceRow{
+			ID:         d.ID,
+			Name:       d.Name,
+			IP:         d.IP,
+			LastSeen:   d.LastSeen,
+			Current:    d.ID == current,
+			Remembered: d.Remembered(),
+		})
+	}
+	return authtemplates.WithDevices(ctx, rows)
+}
+
+// revokeDevice forgets the device id of the user, reporting whether it was
+// theirs. The sessions signed in from the device end on their next request
+// (see rememberLogin).
+func (p *Panel) revokeDevice(ctx context.Context, userID int, id string) bool {
+	device, err := p.deviceStore.Device(ctx, id)
+	if err != nil || device == nil || device.UserID != userID {
+		return false
+	}
+	return p.deviceStore.DeleteDevice(ctx, id) == nil
+}
+
+// hashToken returns the SHA-256 of a remember token secret, hex-encoded.
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// deviceName names the browser and the system of a User-Agent, e.g.
+// "Firefox on Windows".
+func deviceName(ua string) string {
+	browser := "Unknown browser"
+	for _, b := range []struct{ token, name string }{
+		{"Edg/", "Edge"}, {"OPR/", "Opera"}, {"Firefox/", "Firefox"}, {"Chrome/", "Chrome"}, {"Safari/", "Safari"},
+	} {
+		if strings.Contains(ua, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	system := "unknown system"
+	for _, s := range []struct{ token, name string }{
+		{"Android", "Android"}, {"iPhone", "iOS"}, {"iPad", "iPadOS"}, {"Windows", "Windows"},
+		{"Mac OS X", "macOS"}, {"CrOS", "ChromeOS"}, {"Linux", "Linux"},
+	} {
+		if strings.Contains(ua, s.token) {
+			system = s.name
+			break
+		}
+	}
+	return browser + " on " + system
+}
+
+// cookiePath scopes the device cookies to the panel, so the panels of a
+// host keep their own.
+func (p *Panel) cookiePath() string {
+	if p.Path == "" {
+		return "/"
+	}
+	return p.Path
+}
+
+// setCookie sets a device cookie, Secure like the session cookie or when
+// the client uses HTTPS, directly or through a trusted proxy (see
+// clientip.SetTrustedProxies).
+func (p *Panel) setCookie(w http.ResponseWriter, r *http.Request, name, value string, maxAge time.Duration) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     p.cookiePath(),
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   p.AuthManager.Session().Cookie.Secure || clientip.IsHTTPS(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (p *Panel) clearCookie(w http.ResponseWriter, r *http.Request, name string) {
+	if _, err := r.Cookie(name); err != nil {
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: name, Path: p.cookiePath(), MaxAge: -1, HttpOnly: true})
+}
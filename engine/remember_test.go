@@ -0,0 +1,292 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/bozz33/sublimeadmin/apperrors"
+	"github.com/bozz33/sublimeadmin/auth"
+	"github.com/bozz33/sublimeadmin/clientip"
+	"github.com/bozz33/sublimeadmin/mailer"
+	"github.com/bozz33/sublimeadmin/scim"
+)
+
+const firefoxUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:128.0) Gecko/20100101 Firefox/128.0"
+
+type spyMailer struct{ sent []mailer.Message }
+
+func (m *spyMailer) Send(msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// rememberPanel returns a panel remembering devices and a handler serving
+// its login, logout and dashboard routes.
+func rememberPanel() (*Panel, *policyUsers, *spyMailer, http.Handler) {
+	sm := scs.New()
+	users := &policyUsers{user: &policyUser{id: 1, name: "Ann", email: "ann@example.com", password: (&AuthHandler{}).hashPassword("secret-pass")}}
+	spy := &spyMailer{}
+	p := NewPanel("remember-test").WithRememberMe(nil, 0).EnableNewDeviceAlerts(true).WithMailer(spy)
+	p.AuthManager = auth.NewManager(sm)
+	p.Users = users
+
+	mux := http.NewServeMux()
+	authHandler := NewAuthHandler(p.AuthManager, users)
+	mux.Handle("/login", authHandler)
+	mux.Handle("/logout", authHandler)
+	mux.Handle("/", p.protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	return p, users, spy, sm.LoadAndSave(p.injectConfig(p.rememberLogin(mux)))
+}
+
+func login(h http.Handler, ua string, remember bool, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	form := url.Values{"email": {"ann@example.com"}, "password": {"secret-pass"}}
+	if remember {
+		form.Set("remember_me", "on")
+	}
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", ua)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func cookie(rec *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestPanel_RememberMe(t *testing.T) {
+	p, _, _, h := rememberPanel()
+	rec := login(h, firefoxUA, true)
+	device, remember := cookie(rec, deviceCookie), cookie(rec, rememberCookie)
+	if device == nil || remember == nil {
+		t.Fatalf("login with remember me: cookies %v", rec.Result().Cookies())
+	}
+	d, _ := p.deviceStore.Device(context.Background(), device.Value)
+	if d == nil || !d.Remembered() || d.Name != "Firefox on Windows" || strings.Contains(remember.Value, d.TokenHash) {
+		t.Fatalf("device = %+v, want a remembered device storing the hash of the token", d)
+	}
+
+	// A new browser session signs in with the token, which rotates.
+	get := func(ua string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("User-Agent", ua)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	rec = get(firefoxUA, remember)
+	rotated := cookie(rec, rememberCookie)
+	if rec.Code != http.StatusOK || rotated == nil || rotated.Value == remember.Value {
+		t.Fatalf("dashboard with a remember token: status %d, rotated cookie %v", rec.Code, rotated)
+	}
+
+	// The token is bound to the browser.
+	if rec := get("Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) Chrome/126.0 Safari/537.36", rotated); rec.Code != http.StatusFound {
+		t.Errorf("token from another browser: status %d, want a redirect to the login", rec.Code)
+	}
+	// A mismatch revoked the token.
+	if rec := get(firefoxUA, rotated); rec.Code != http.StatusFound {
+		t.Errorf("revoked token: status %d, want a redirect to the login", rec.Code)
+	}
+}
+
+func TestPanel_RememberMe_replay(t *testing.T) {
+	p, _, _, h := rememberPanel()
+	rec := login(h, firefoxUA, true)
+	remember, device := cookie(rec, rememberCookie), cookie(rec, deviceCookie)
+
+	serve := func(cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("User-Agent", firefoxUA)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	first := serve(remember)
+	session := cookie(first, "session")
+	if first.Code != http.StatusOK || session == nil {
+		t.Fatalf("first use: status %d", first.Code)
+	}
+	if rec := serve(remember); rec.Code != http.StatusFound {
+		t.Errorf("replayed token: status %d, want a redirect to the login", rec.Code)
+	}
+	// The replay revoked the device and ended the session it signed in.
+	if d, _ := p.deviceStore.Device(context.Background(), device.Value); d != nil {
+		t.Errorf("device %+v, want it revoked", d)
+	}
+	if rec := serve(session); rec.Code != http.StatusFound {
+		t.Errorf("session of the revoked device: status %d, want a redirect to the login", rec.Code)
+	}
+}
+
+func TestPanel_RememberMe_secureBehindProxy(t *testing.T) {
+	defer func() { _ = clientip.SetTrustedProxies() }()
+	if err := clientip.SetTrustedProxies("192.0.2.0/24"); err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, h := rememberPanel()
+	form := url.Values{"email": {"ann@example.com"}, "password": {"secret-pass"}, "remember_me": {"on"}}
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", firefoxUA)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if c := cookie(rec, rememberCookie); c == nil || !c.Secure {
+		t.Errorf("remember cookie %v, want it Secure behind a TLS-terminating proxy", c)
+	}
+}
+
+func TestPanel_NewDeviceAlerts(t *testing.T) {
+	p, _, spy, h := rememberPanel()
+	device := cookie(login(h, firefoxUA, false), deviceCookie)
+	if len(spy.sent) != 0 {
+		t.Errorf("the first device should not be alerted, got %+v", spy.sent)
+	}
+	login(h, firefoxUA, false, device)
+	if len(spy.sent) != 0 {
+		t.Errorf("a known device should not be alerted, got %+v", spy.sent)
+	}
+	login(h, "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) Version/17.5 Mobile/15E148 Safari/604.1", false)
+	if len(spy.sent) != 1 || !strings.Contains(spy.sent[0].Body, "Safari on iOS") || spy.sent[0].To[0] != "ann@example.com" {
+		t.Fatalf("new device alert = %+v", spy.sent)
+	}
+
+	devices, _ := p.deviceStore.Devices(context.Background(), 1)
+	if len(devices) != 2 {
+		t.Fatalf("devices = %+v, want 2", devices)
+	}
+	if p.revokeDevice(context.Background(), 2, devices[0].ID) {
+		t.Error("a user should not revoke the devices of another")
+	}
+	if !p.revokeDevice(context.Background(), 1, devices[0].ID) {
+		t.Error("expected the device to be revoked")
+	}
+}
+
+func TestPanel_RevokeDevice_endsSessions(t *testing.T) {
+	p, _, _, h := rememberPanel()
+	p.WithPath("/admin")
+	rec := login(h, firefoxUA, false)
+	session, device := cookie(rec, "session"), cookie(rec, deviceCookie)
+	if session == nil || device == nil {
+		t.Fatalf("login: cookies %v", rec.Result().Cookies())
+	}
+	if device.Path != "/admin" {
+		t.Errorf("device cookie path = %q, want the panel path", device.Path)
+	}
+
+	get := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(session)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := get(); code != http.StatusOK {
+		t.Fatalf("signed in: status %d", code)
+	}
+	if !p.revokeDevice(context.Background(), 1, device.Value) {
+		t.Fatal("expected the device to be revoked")
+	}
+	if code := get(); code != http.StatusFound {
+		t.Errorf("session of a revoked device: status %d, want a redirect to the login", code)
+	}
+}
+
+// emailUsers finds the user of policyUsers by its email only, else fails
+// with err (not found by default).
+type emailUsers struct {
+	*policyUsers
+	err error
+}
+
+func (r emailUsers) FindByEmail(ctx context.Context, email string) (FrameworkUser, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if email != r.user.email {
+		return nil, apperrors.NotFound("user")
+	}
+	return r.user, nil
+}
+
+func TestPanel_SCIM_deprovision(t *testing.T) {
+	p, users, _, h := rememberPanel()
+	p.Users = emailUsers{policyUsers: users}
+	store := scim.NewMemoryStore()
+	p.WithSCIM(store, "s3cret")
+	provision := func(method, path, body string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rec := httptest.NewRecorder()
+		p.scim.ServeHTTP(rec, req)
+		if rec.Code >= http.StatusBadRequest {
+			t.Fatalf("%s %s = %d %s", method, path, rec.Code, rec.Body)
+		}
+	}
+	// The work email of the identity provider is not the one of the panel.
+	provision(http.MethodPost, "/Users", `{"userName": "ann@example.com", "emails": [{"value": "ann@corp.example", "primary": true}]}`)
+
+	session := cookie(login(h, firefoxUA, false), "session")
+	get := func(session *http.Cookie) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if session != nil {
+			req.AddCookie(session)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := get(session); code != http.StatusOK {
+		t.Fatalf("signed in: status %d", code)
+	}
+
+	provision(http.MethodPut, "/Users/1", `{"userName": "ann@example.com", "active": false}`)
+	if code := get(session); code != http.StatusFound {
+		t.Errorf("session of a deactivated user: status %d, want a redirect to the login", code)
+	}
+	rec := login(h, firefoxUA, false)
+	if !strings.Contains(rec.Body.String(), "This account is disabled") {
+		t.Errorf("login of a deactivated user: status %d, want the disabled account error", rec.Code)
+	}
+	if code := get(cookie(rec, "session")); code != http.StatusFound {
+		t.Errorf("after the login of a deactivated user: status %d, want a redirect to the login", code)
+	}
+}
+
+func TestPanel_SCIM_deprovision_lookupError(t *testing.T) {
+	p, users, _, _ := rememberPanel()
+	p.Users = emailUsers{policyUsers: users, err: errors.New("connection refused")}
+	p.WithSCIM(scim.NewMemoryStore(), "s3cret")
+
+	err := p.deprovisionUser(context.Background(), &scim.User{UserName: "ann@example.com"})
+	if err == nil {
+		t.Fatal("expected the lookup error, so the identity provider retries")
+	}
+	p.Users = emailUsers{policyUsers: users}
+	if err := p.deprovisionUser(context.Background(), &scim.User{UserName: "bob@example.com"}); err != nil {
+		t.Errorf("user unknown to the panel: %v", err)
+	}
+}
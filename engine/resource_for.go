@@ -0,0 +1,645 @@
+package engine
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/form"
+	"github.com/bozz33/sublimeadmin/table"
+	"github.com/bozz33/sublimeadmin/ui/components"
+	"github.com/bozz33/sublimeadmin/validation"
+)
+
+// TypedResource is a resource of records of type T whose table columns,
+// form fields and validation are derived from the struct tags of T, so they
+// cannot drift from the model (see ResourceFor).
+type TypedResource[T any] struct {
+	*BaseResource
+	fields []typedField
+
+	listFunc   func(ctx context.Context) ([]*T, error)
+	getFunc    func(ctx context.Context, id string) (*T, error)
+	createFunc func(ctx context.Context, item *T) error
+	updateFunc func(ctx context.Context, id string, item *T) error
+	deleteFunc func(ctx context.Context, id string) error
+}
+
+// ResourceFor creates a resource for the struct type T. Its slug and labels
+// come from the type name (OrderItem → "order-items", "Order Item", "Order
+// Items"), its columns and fields from the exported fields of T:
+//
+//	type Post struct {
+//		ID     int
+//		Title  string `json:"title" admin:"sortable,searchable" validate:"required,max=120"`
+//		Body   string `json:"body" admin:"type=textarea,notable"`
+//		Status string `json:"status" admin:"badge" validate:"oneof=draft published"`
+//	}
+//
+//	posts := engine.ResourceFor[Post]().
+//		WithList(store.ListPosts).
+//		WithCreate(store.CreatePost).
+//		WithUpdate(store.UpdatePost).
+//		WithDelete(store.DeletePost)
+//
+// The admin tag holds comma-separated options, or "-" to ignore the field:
+//
+//	label=Text        label of the column and the field (default: from the name)
+//	sortable          the column can be sorted
+//	searchable        the search of the list looks in the field
+//	searchinline      a search input under the column header filters the list
+//	type=…            input: text, email, password, number, textarea, checkbox,
+//	                  date, datetime or select (default: from the Go type)
+//	options=a|b       options of a select
+//	badge             show the value as a badge
+//	notable, noform   leave the field out of the table or of the form
+//
+// The validate tag (see the validation package) marks required fields, turns
+// email fields into email inputs and oneof fields into selects, and checks
+// the submitted values: the form names its fields after their json tag. Its
+// min, max and url rules are checked by the browser too (see
+// form.ClientValidation).
+// Fields of other types than strings, numbers, booleans and time.Time are
+// ignored, and the ID field is only shown in the table.
+//
+// Lists are searched and sorted in memory. A resource without a create,
+// update or delete function does not allow the operation.
+func ResourceFor[T any]() *TypedResource[T] {
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("engine: ResourceFor needs a struct type, got %s", t))
+	}
+	label := humanize(t.Name())
+	plural := pluralize(label)
+	return &TypedResource[T]{
+		BaseResource: NewBaseResource(strings.ToLower(strings.ReplaceAll(plural, " ", "-")), label, plural),
+		fields:       parseTypedFields(t),
+	}
+}
+
+// WithIcon sets the icon.
+func (r *TypedResource[T]) WithIcon(icon string) *TypedResource[T] {
+	r.BaseResource.SetIcon(icon)
+	return r
+}
+
+// WithGroup sets the navigation group.
+func (r *TypedResource[T]) WithGroup(group string) *TypedResource[T] {
+	r.BaseResource.SetGroup(group)
+	return r
+}
+
+// WithSort sets the sort order.
+func (r *TypedResource[T]) WithSort(sort int) *TypedResource[T] {
+	r.BaseResource.SetSort(sort)
+	return r
+}
+
+// WithList sets the list function.
+func (r *TypedResource[T]) WithList(fn func(ctx context.Context) ([]*T, error)) *TypedResource[T] {
+	r.listFunc = fn
+	return r
+}
+
+// WithGet sets the get function. Without one, records are looked up by ID
+// in the list.
+func (r *TypedResource[T]) WithGet(fn func(ctx context.Context, id string) (*T, error)) *TypedResource[T] {
+	r.getFunc = fn
+	return r
+}
+
+// WithCreate sets the create function, called with a validated record.
+func (r *TypedResource[T]) WithCreate(fn func(ctx context.Context, item *T) error) *TypedResource[T] {
+	r.createFunc = fn
+	return r
+}
+
+// WithUpdate sets the update function, called with the stored record
+// updated with the submitted values, once validated.
+func (r *TypedResource[T]) WithUpdate(fn func(ctx context.Context, id string, item *T) error) *TypedResource[T] {
+	r.updateFunc = fn
+	return r
+}
+
+// WithDelete sets the delete function.
+func (r *TypedResource[T]) WithDelete(fn func(ctx context.Context, id string) error) *TypedResource[T] {
+	r.deleteFunc = fn
+	return r
+}
+
+func (r *TypedResource[T]) CanCreate(ctx context.Context) bool { return r.createFunc != nil }
+func (r *TypedResource[T]) CanUpdate(ctx context.Context) bool { return r.updateFunc != nil }
+func (r *TypedResource[T]) CanDelete(ctx context.Context) bool { return r.deleteFunc != nil }
+
+// CRUD method overrides
+func (r *TypedResource[T]) List(ctx context.Context) ([]any, error) {
+	items, err := r.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]any, len(items))
+	for i, item := range items {
+		result[i] = item
+	}
+	return result, nil
+}
+
+func (r *TypedResource[T]) Get(ctx context.Context, id string) (any, error) {
+	item, err := r.get(ctx, id)
+	if err != nil || item == nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *TypedResource[T]) Create(ctx context.Context, req *http.Request) error {
+	if r.createFunc == nil {
+		return fmt.Errorf("%s cannot be created", r.PluralLabel())
+	}
+	item := new(T)
+	if err := r.bind(req, item); err != nil {
+		return err
+	}
+	if err := r.createFunc(ctx, item); err != nil {
+		return err
+	}
+	SetCreatedRecord(ctx, item)
+	return nil
+}
+
+func (r *TypedResource[T]) Update(ctx context.Context, id string, req *http.Request) error {
+	if r.updateFunc == nil {
+		return fmt.Errorf("%s cannot be updated", r.PluralLabel())
+	}
+	item, err := r.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		return fmt.Errorf("%s %s not found", r.Label(), id)
+	}
+	updated := *item
+	if err := r.bind(req, &updated); err != nil {
+		return err
+	}
+	return r.updateFunc(ctx, id, &updated)
+}
+
+func (r *TypedResource[T]) Delete(ctx context.Context, id string) error {
+	if r.deleteFunc == nil {
+		return fmt.Errorf("%s cannot be deleted", r.PluralLabel())
+	}
+	return r.deleteFunc(ctx, id)
+}
+
+func (r *TypedResource[T]) BulkDelete(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		if err := r.Delete(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Table lists the records, filtered, searched and sorted with the ListQuery
+// of the context.
+func (r *TypedResource[T]) Table(ctx context.Context) templ.Component {
+	items, err := r.list(ctx)
+	if err != nil {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, werr := io.WriteString(w, "<p class=\"text-red-500\">Error loading table: "+templ.EscapeString(err.Error())+"</p>")
+			return werr
+		})
+	}
+	if lq := GetListQuery(ctx); lq != nil {
+		conditions := lq.conditions()
+		items = slices.DeleteFunc(slices.Clone(items), func(item *T) bool { return !MatchFilters(item, conditions) })
+		items = r.search(items, lq.Search)
+		r.sortItems(items, lq.SortKey, lq.SortDir == "desc")
+	}
+
+	data := make([]any, len(items))
+	for i, item := range items {
+		data[i] = item
+	}
+	t := table.New(data).WithColumns(r.Columns()...)
+	t.BaseURL = PanelURL(ctx, "/"+r.Slug())
+	t.Searchable = slices.ContainsFunc(r.fields, func(f typedField) bool { return f.searchable })
+	t.Pagination = false
+	t.ColumnSearch = columnSearchFromContext(ctx)
+	return components.Table(ctx, t, data)
+}
+
+// Form renders the form of the record, or of a new one when item is nil.
+func (r *TypedResource[T]) Form(ctx context.Context, item any) templ.Component {
+	action := "/" + r.Slug()
+	record, _ := item.(*T)
+	if record != nil {
+		action += "/" + getItemID(record)
+	}
+	return components.Form(r.Fields(record), PanelURL(ctx, action), "POST")
+}
+
+// TableColumns returns the table columns derived from T, for the inline
+// column searches (see ResourceTableColumns).
+func (r *TypedResource[T]) TableColumns(_ context.Context) []table.Column {
+	return r.Columns()
+}
+
+// Columns returns the table columns derived from T.
+func (r *TypedResource[T]) Columns() []table.Column {
+	var cols []table.Column
+	for _, f := range r.fields {
+		if f.inTable {
+			cols = append(cols, f.column())
+		}
+	}
+	return cols
+}
+
+// Fields returns the form fields derived from T, filled with the values of
+// item (nil = a new record).
+func (r *TypedResource[T]) Fields(item *T) []form.Component {
+	var v reflect.Value
+	if item != nil {
+		v = reflect.ValueOf(item).Elem()
+	}
+	var fields []form.Component
+	for _, f := range r.fields {
+		if !f.inForm {
+			continue
+		}
+		var value reflect.Value
+		if v.IsValid() {
+			value = v.FieldByIndex(f.index)
+		}
+		fields = append(fields, f.field(value))
+	}
+	return fields
+}
+
+func (r *TypedResource[T]) list(ctx context.Context) ([]*T, error) {
+	if r.listFunc == nil {
+		return nil, nil
+	}
+	return r.listFunc(ctx)
+}
+
+func (r *TypedResource[T]) get(ctx context.Context, id string) (*T, error) {
+	if r.getFunc != nil {
+		return r.getFunc(ctx, id)
+	}
+	items, err := r.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if getItemID(item) == id {
+			return item, nil
+		}
+	}
+	return nil, nil
+}
+
+// bind decodes the submitted form into item and validates it. Booleans
+// left out of the form are unchecked checkboxes.
+func (r *TypedResource[T]) bind(req *http.Request, item *T) error {
+	if err := req.ParseForm(); err != nil {
+		return err
+	}
+	v := reflect.ValueOf(item).Elem()
+	for _, f := range r.fields {
+		if f.inForm && f.input == "checkbox" && !req.Form.Has(f.name) {
+			v.FieldByIndex(f.index).SetBool(false)
+		}
+	}
+	if errs := validation.FormErrors(req, item); errs != nil {
+		return errs
+	}
+	return nil
+}
+
+// search keeps the items with a searchable field containing query.
+func (r *TypedResource[T]) search(items []*T, query string) []*T {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return items
+	}
+	var found []*T
+	for _, item := range items {
+		v := reflect.ValueOf(item).Elem()
+		for _, f := range r.fields {
+			if f.searchable && strings.Contains(strings.ToLower(fmt.Sprint(v.FieldByIndex(f.index).Interface())), query) {
+				found = append(found, item)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// sortItems sorts the items by the sortable field whose column key is key.
+func (r *TypedResource[T]) sortItems(items []*T, key string, desc bool) {
+	i := slices.IndexFunc(r.fields, func(f typedField) bool { return f.sortable && f.key == key })
+	if i < 0 {
+		return
+	}
+	index := r.fields[i].index
+	slices.SortStableFunc(items, func(a, b *T) int {
+		c := compareValues(reflect.ValueOf(a).Elem().FieldByIndex(index), reflect.ValueOf(b).Elem().FieldByIndex(index))
+		if desc {
+			return -c
+		}
+		return c
+	})
+}
+
+// compareValues orders two values of the same basic type.
+func compareValues(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
+	case reflect.Bool:
+		return cmp.Compare(boolRank(a.Bool()), boolRank(b.Bool()))
+	case reflect.String:
+		return cmp.Compare(strings.ToLower(a.String()), strings.ToLower(b.String()))
+	}
+	if at, ok := a.Interface().(time.Time); ok {
+		return at.Compare(b.Interface().(time.Time))
+	}
+	return 0
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// typedField is a field of a TypedResource, parsed from the struct tags.
+type typedField struct {
+	index      []int
+	key        string // column key: the Go name
+	name       string // form name: the json name
+	label      string
+	input      string // text, email, password, number, textarea, checkbox, date, datetime or select
+	options    []string
+	rules      []string // client-side rules of the form field, from the validate tag
+	badge      bool
+	sortable   bool
+	searchable bool
+	inline     bool // search input under the column header
+	required   bool
+	inTable    bool
+	inForm     bool
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// parseTypedFields parses the exported fields of the struct type t.
+func parseTypedFields(t reflect.Type) []typedField {
+	var fields []typedField
+	for _, sf := range reflect.VisibleFields(t) {
+		if !sf.IsExported() || sf.Anonymous {
+			continue
+		}
+		input := defaultInput(sf.Type)
+		if input == "" || sf.Tag.Get("admin") == "-" {
+			continue
+		}
+		jsonName, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		f := typedField{
+			index:   sf.Index,
+			key:     sf.Name,
+			name:    cmp.Or(jsonName, sf.Name),
+			label:   humanize(sf.Name),
+			input:   input,
+			inTable: true,
+			inForm:  jsonName != "-" && sf.Name != "ID" && sf.Name != "Id",
+		}
+
+		for _, rule := range strings.Split(sf.Tag.Get("validate"), ",") {
+			name, param, _ := strings.Cut(rule, "=")
+			switch name {
+			case "required":
+				f.required = true
+			case "email":
+				if f.input == "text" {
+					f.input = "email"
+				}
+			case "oneof":
+				if f.input == "text" {
+					f.input = "select"
+					f.options = strings.Fields(param)
+				}
+			case "min", "max":
+				f.rules = append(f.rules, name+":"+param)
+			case "url":
+				f.rules = append(f.rules, name)
+			}
+		}
+
+		for _, opt := range strings.Split(sf.Tag.Get("admin"), ",") {
+			name, param, _ := strings.Cut(strings.TrimSpace(opt), "=")
+			switch name {
+			case "label":
+				f.label = param
+			case "type":
+				f.input = param
+			case "options":
+				f.options = strings.Split(param, "|")
+			case "badge":
+				f.badge = true
+			case "sortable":
+				f.sortable = true
+			case "searchable":
+				f.searchable = true
+			case "searchinline":
+				f.inline = true
+			case "notable":
+				f.inTable = false
+			case "noform":
+				f.inForm = false
+			}
+		}
+		if f.input == "password" {
+			f.inTable = false
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// defaultInput returns the input of a field of type t, or "" for types a
+// TypedResource ignores.
+func defaultInput(t reflect.Type) string {
+	if t == timeType {
+		return "date"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "text"
+	case reflect.Bool:
+		return "checkbox"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return ""
+}
+
+// column returns the table column of the field.
+func (f typedField) column() table.Column {
+	switch {
+	case f.badge:
+		col := table.Badge(f.key).WithLabel(f.label)
+		if f.sortable {
+			col.Sortable()
+		}
+		if f.inline {
+			col.SearchableInline()
+		}
+		return col
+	case f.input == "checkbox":
+		col := table.BoolCol(f.key).WithLabel(f.label)
+		if f.sortable {
+			col.Sortable()
+		}
+		return col
+	case f.input == "date" || f.input == "datetime":
+		col := table.DateCol(f.key).WithLabel(f.label)
+		if f.input == "datetime" {
+			col.Format = "2006-01-02 15:04"
+		}
+		if f.sortable {
+			col.Sortable()
+		}
+		return col
+	}
+	col := table.Text(f.key).WithLabel(f.label)
+	if f.sortable {
+		col.Sortable()
+	}
+	if f.searchable {
+		col.Searchable()
+	}
+	if f.inline {
+		col.SearchableInline()
+	}
+	return col
+}
+
+// field returns the form field, filled with value when it is valid.
+func (f typedField) field(value reflect.Value) form.Component {
+	var current any
+	if value.IsValid() {
+		current = value.Interface()
+		if t, ok := current.(time.Time); ok {
+			switch {
+			case t.IsZero():
+				current = nil
+			case f.input == "datetime":
+				current = t.Format("2006-01-02T15:04")
+			default:
+				current = t.Format("2006-01-02")
+			}
+		}
+	}
+
+	switch f.input {
+	case "checkbox":
+		b, _ := current.(bool)
+		return form.Checkbox(f.name).Label(f.label).Default(b)
+	case "select":
+		options := make([]form.SelectOption, len(f.options))
+		for i, o := range f.options {
+			options[i] = form.SelectOption{Value: o, Label: humanize(o)}
+		}
+		field := form.Select(f.name).Label(f.label).OptionsOrdered(options).Default(current)
+		if f.required {
+			field.Required()
+		}
+		return field
+	case "textarea":
+		field := form.Textarea(f.name).Label(f.label).Default(current).WithRules(f.rules...)
+		if f.required {
+			field.Required()
+		}
+		return field
+	case "date", "datetime":
+		field := form.Date(f.name)
+		if f.input == "datetime" {
+			field = form.DateTime(f.name)
+		}
+		field.Label(f.label).Default(current)
+		if f.required {
+			field.Required()
+		}
+		return field
+	}
+
+	var field *form.TextInput
+	switch f.input {
+	case "email":
+		field = form.Email(f.name)
+	case "password":
+		field = form.Password(f.name)
+		current = nil
+	case "number":
+		field = form.Number(f.name)
+	default:
+		field = form.Text(f.name)
+	}
+	field.Label(f.label).Default(current).WithRules(f.rules...)
+	if f.required {
+		field.Required()
+	}
+	return field
+}
+
+// humanize turns a Go or snake_case name into a label: "CreatedAt" and
+// "created_at" → "Created At".
+func humanize(name string) string {
+	var b strings.Builder
+	runes := []rune(strings.ReplaceAll(name, "_", " "))
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && runes[i-1] != ' ' &&
+			(unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			b.WriteRune(' ')
+		}
+		if i == 0 || runes[i-1] == ' ' {
+			r = unicode.ToUpper(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// pluralize returns the English plural of a label.
+func pluralize(label string) string {
+	lower := strings.ToLower(label)
+	switch {
+	case label == "":
+		return ""
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return label[:len(label)-1] + "ies"
+	case strings.HasSuffix(lower, "s") || strings.HasSuffix(lower, "x") || strings.HasSuffix(lower, "z") ||
+		strings.HasSuffix(lower, "ch") || strings.HasSuffix(lower, "sh"):
+		return label + "es"
+	}
+	return label + "s"
+}
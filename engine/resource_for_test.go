@@ -0,0 +1,230 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/form"
+	"github.com/bozz33/sublimeadmin/validation"
+)
+
+type blogPost struct {
+	ID          int
+	Title       string    `json:"title" admin:"sortable,searchable" validate:"required,max=120"`
+	Body        string    `json:"body" admin:"type=textarea,notable"`
+	Status      string    `json:"status" admin:"badge" validate:"oneof=draft published"`
+	AuthorEmail string    `json:"author_email" validate:"omitempty,email"`
+	Featured    bool      `json:"featured"`
+	PublishedAt time.Time `json:"published_at" admin:"label=Published,sortable"`
+	Internal    string    `json:"-" admin:"-"`
+	Tags        []string  `json:"tags"`
+}
+
+func newPostResource(posts *[]*blogPost) *TypedResource[blogPost] {
+	return ResourceFor[blogPost]().
+		WithList(func(ctx context.Context) ([]*blogPost, error) { return *posts, nil }).
+		WithCreate(func(ctx context.Context, p *blogPost) error {
+			p.ID = len(*posts) + 1
+			*posts = append(*posts, p)
+			return nil
+		}).
+		WithUpdate(func(ctx context.Context, id string, p *blogPost) error {
+			for i, old := range *posts {
+				if getItemID(old) == id {
+					(*posts)[i] = p
+				}
+			}
+			return nil
+		})
+}
+
+func TestResourceFor_Meta(t *testing.T) {
+	res := ResourceFor[blogPost]()
+	if res.Slug() != "blog-posts" || res.Label() != "Blog Post" || res.PluralLabel() != "Blog Posts" {
+		t.Errorf("got slug %q, label %q, plural %q", res.Slug(), res.Label(), res.PluralLabel())
+	}
+	var _ Resource = res
+}
+
+func TestResourceFor_Columns(t *testing.T) {
+	cols := ResourceFor[blogPost]().Columns()
+
+	var got []string
+	for _, c := range cols {
+		got = append(got, c.Key()+":"+c.Label()+":"+c.Type())
+	}
+	want := []string{
+		"ID:ID:text",
+		"Title:Title:text",
+		"Status:Status:badge",
+		"AuthorEmail:Author Email:text",
+		"Featured:Featured:boolean",
+		"PublishedAt:Published:date",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("columns = %v, want %v", got, want)
+	}
+	if !cols[1].IsSortable() || !cols[1].IsSearchable() {
+		t.Error("Title should be sortable and searchable")
+	}
+	if cols[3].IsSortable() {
+		t.Error("AuthorEmail should not be sortable")
+	}
+}
+
+func TestResourceFor_Fields(t *testing.T) {
+	post := &blogPost{ID: 7, Title: "Hello", Status: "draft", Featured: true,
+		PublishedAt: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}
+	fields := ResourceFor[blogPost]().Fields(post)
+
+	type named interface {
+		GetName() string
+		GetValueString() string
+	}
+	byName := map[string]form.Component{}
+	var names []string
+	for _, c := range fields {
+		name := c.(named).GetName()
+		byName[name] = c
+		names = append(names, name)
+	}
+	if got := strings.Join(names, ","); got != "title,body,status,author_email,featured,published_at" {
+		t.Fatalf("fields = %s", got)
+	}
+
+	if title, ok := byName["title"].(*form.TextInput); !ok || !title.IsRequired() || title.ValueString() != "Hello" {
+		t.Errorf("title should be a required text input filled with Hello, got %#v", byName["title"])
+	}
+	if attrs := byName["title"].(*form.TextInput).ValidationAttributes("text"); attrs["maxlength"] != "120" || attrs["required"] != true {
+		t.Errorf("title should carry the max rule of its validate tag, got %v", attrs)
+	}
+	if _, ok := byName["body"].(*form.TextareaInput); !ok {
+		t.Error("body should be a textarea")
+	}
+	sel, ok := byName["status"].(*form.SelectInput)
+	if !ok || len(sel.SelectOptions()) != 2 || sel.SelectOptions()[1].Label != "Published" {
+		t.Errorf("status should be a select of draft/published, got %#v", byName["status"])
+	}
+	if email, ok := byName["author_email"].(*form.TextInput); !ok || email.Type != "email" {
+		t.Errorf("author_email should be an email input, got %#v", byName["author_email"])
+	}
+	if featured, ok := byName["featured"].(*form.CheckboxInput); !ok || !featured.IsChecked() {
+		t.Error("featured should be a checked checkbox")
+	}
+	if got := byName["published_at"].(named).GetValueString(); got != "2024-03-01" {
+		t.Errorf("published_at value = %q", got)
+	}
+}
+
+func TestResourceFor_Create(t *testing.T) {
+	var posts []*blogPost
+	res := newPostResource(&posts)
+
+	req := httptest.NewRequest(http.MethodPost, "/blog-posts", strings.NewReader(url.Values{
+		"title":        {"Hello"},
+		"status":       {"published"},
+		"featured":     {"1"},
+		"published_at": {"2024-03-01"},
+	}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if err := res.Create(context.Background(), req); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(posts) != 1 {
+		t.Fatalf("expected 1 post, got %d", len(posts))
+	}
+	p := posts[0]
+	if p.Title != "Hello" || p.Status != "published" || !p.Featured || p.PublishedAt.Day() != 1 {
+		t.Errorf("unexpected post %+v", p)
+	}
+}
+
+func TestResourceFor_Create_validation(t *testing.T) {
+	var posts []*blogPost
+	res := newPostResource(&posts)
+
+	req := httptest.NewRequest(http.MethodPost, "/blog-posts", strings.NewReader("status=archived&author_email=nope"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	err := res.Create(context.Background(), req)
+	var errs validation.Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+	for _, field := range []string{"title", "status", "author_email"} {
+		if !errs.Has(field) {
+			t.Errorf("expected an error for %s, got %v", field, errs.Map())
+		}
+	}
+	if len(posts) != 0 {
+		t.Error("an invalid post should not be created")
+	}
+}
+
+func TestResourceFor_Update_keepsOtherFields(t *testing.T) {
+	posts := []*blogPost{{ID: 1, Title: "Old", Status: "draft", Featured: true, Internal: "keep"}}
+	res := newPostResource(&posts)
+
+	req := httptest.NewRequest(http.MethodPost, "/blog-posts/1", strings.NewReader("title=New&status=draft"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	if err := res.Update(context.Background(), "1", req); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	p := posts[0]
+	if p.Title != "New" || p.Internal != "keep" || p.ID != 1 {
+		t.Errorf("unexpected post %+v", p)
+	}
+	if p.Featured {
+		t.Error("an unchecked checkbox should clear the boolean")
+	}
+}
+
+func TestResourceFor_Permissions(t *testing.T) {
+	res := ResourceFor[blogPost]()
+	ctx := context.Background()
+	if res.CanCreate(ctx) || res.CanUpdate(ctx) || res.CanDelete(ctx) {
+		t.Error("a resource without create/update/delete functions should not allow them")
+	}
+	if err := res.Delete(ctx, "1"); err == nil {
+		t.Error("Delete without a delete function should fail")
+	}
+}
+
+func TestResourceFor_Table_searchAndSort(t *testing.T) {
+	posts := []*blogPost{
+		{ID: 1, Title: "Banana bread"},
+		{ID: 2, Title: "apple pie"},
+		{ID: 3, Title: "Cherry tart"},
+		{ID: 4, Title: "Apple crumble"},
+	}
+	res := newPostResource(&posts)
+
+	items := res.search(posts, "APPLE")
+	res.sortItems(items, "Title", true)
+	var titles []string
+	for _, p := range items {
+		titles = append(titles, p.Title)
+	}
+	if got := strings.Join(titles, ","); got != "apple pie,Apple crumble" {
+		t.Errorf("titles = %s", got)
+	}
+
+	h := NewCRUDHandler(res)
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/blog-posts?search=cherry", nil))
+	if rw.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rw.Code)
+	}
+	body := rw.Body.String()
+	if !strings.Contains(body, "Cherry tart") || strings.Contains(body, "Banana bread") {
+		t.Error("the list should only show the matching posts")
+	}
+}
@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/ui/components"
+	"github.com/bozz33/sublimeadmin/widget"
+)
+
+// ResourceHeaderWidgets is an optional interface for resources showing
+// widgets above their pages, e.g. stats above the table. page is PageList,
+// PageCreate, PageView or PageEdit; item is nil on the list and create
+// pages.
+//
+//	func (r *PostResource) HeaderWidgets(ctx context.Context, page string, item any) []widget.Widget {
+//	    if page != engine.PageList {
+//	        return nil
+//	    }
+//	    return []widget.Widget{widget.NewStats(
+//	        widget.Stat{Label: "Published", Value: strconv.Itoa(r.count(ctx, "published"))},
+//	        widget.Stat{Label: "Draft", Value: strconv.Itoa(r.count(ctx, "draft"))},
+//	    )}
+//	}
+type ResourceHeaderWidgets interface {
+	HeaderWidgets(ctx context.Context, page string, item any) []widget.Widget
+}
+
+// ListTab is a tab above the list of a resource, selected with ?tab=Name.
+// Its Filters are added to those of the list (see ListQuery), so the
+// resource lists the records of the tab without knowing about tabs.
+type ListTab struct {
+	Name    string
+	Label   string
+	Icon    string
+	Filters map[string]string                // filter key → value
+	Badge   func(ctx context.Context) string // e.g. the number of records (nil = none)
+}
+
+// ResourceTabs is an optional interface for resources pre-filtering their
+// list with tabs (All | Published | Draft). The first tab is selected by
+// default.
+//
+//	func (r *PostResource) Tabs(ctx context.Context) []engine.ListTab {
+//	    return []engine.ListTab{
+//	        {Name: "all", Label: "All"},
+//	        {Name: "published", Label: "Published", Filters: map[string]string{"status": "published"}},
+//	        {Name: "draft", Label: "Draft", Filters: map[string]string{"status": "draft"}},
+//	    }
+//	}
+type ResourceTabs interface {
+	Tabs(ctx context.Context) []ListTab
+}
+
+// listTabs returns the tabs of the resource and the one selected by name,
+// or the first one. active is nil when the resource has no tabs.
+func listTabs(ctx context.Context, res Resource, name string) (tabs []ListTab, active *ListTab) {
+	rt, ok := res.(ResourceTabs)
+	if !ok {
+		return nil, nil
+	}
+	tabs = rt.Tabs(ctx)
+	for i := range tabs {
+		if tabs[i].Name == name {
+			return tabs, &tabs[i]
+		}
+	}
+	if len(tabs) > 0 {
+		active = &tabs[0]
+	}
+	return tabs, active
+}
+
+// tabLinks returns the links of the tabs of a list page. They keep the
+// search and sort of the request, but go back to the first page.
+func tabLinks(r *http.Request, res Resource, tabs []ListTab, active *ListTab) []components.ResourceTab {
+	links := make([]components.ResourceTab, 0, len(tabs))
+	for i, tab := range tabs {
+		q := r.URL.Query()
+		q.Del("page")
+		q.Del("tab")
+		if i > 0 {
+			q.Set("tab", tab.Name)
+		}
+		href := PanelURL(r.Context(), "/"+res.Slug())
+		if len(q) > 0 {
+			href += "?" + q.Encode()
+		}
+		link := components.ResourceTab{
+			Label:  tab.Label,
+			Icon:   tab.Icon,
+			URL:    href,
+			Active: active != nil && tab.Name == active.Name,
+		}
+		if tab.Badge != nil {
+			link.Badge = tab.Badge(r.Context())
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// resourcePage returns the content of a page of the resource below its
+// header widgets (see ResourceHeaderWidgets) and tabs. Slide-overs only
+// show the content.
+func resourcePage(r *http.Request, res Resource, page string, item any, tabs []components.ResourceTab, content templ.Component) templ.Component {
+	props := components.ResourcePageProps{Tabs: tabs, Content: content}
+	if hw, ok := res.(ResourceHeaderWidgets); ok && !isDrawer(r) {
+		for _, w := range hw.HeaderWidgets(r.Context(), page, item) {
+			props.Widgets = append(props.Widgets, w.Render())
+		}
+	}
+	if len(props.Widgets) == 0 && len(props.Tabs) == 0 {
+		return content
+	}
+	return components.ResourcePage(props)
+}
@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/widget"
+)
+
+// tabbedResource lists the status filter it receives and shows a stat of
+// the page above it.
+type tabbedResource struct {
+	*mockResource
+}
+
+func (r *tabbedResource) Table(ctx context.Context) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		lq := GetListQuery(ctx)
+		_, err := io.WriteString(w, "table tab="+lq.Tab+" status="+lq.Filters["status"])
+		return err
+	})
+}
+
+func (r *tabbedResource) Tabs(ctx context.Context) []ListTab {
+	return []ListTab{
+		{Name: "all", Label: "All"},
+		{Name: "published", Label: "Published", Filters: map[string]string{"status": "published"},
+			Badge: func(ctx context.Context) string { return "12" }},
+		{Name: "draft", Label: "Draft", Filters: map[string]string{"status": "draft"}},
+	}
+}
+
+func (r *tabbedResource) HeaderWidgets(ctx context.Context, page string, item any) []widget.Widget {
+	return []widget.Widget{widget.NewStats(widget.Stat{Label: "Widget of the " + page + " page", Value: "3"})}
+}
+
+func TestCRUDHandler_ListTabs(t *testing.T) {
+	h := newHandler(&tabbedResource{newMockResource("posts")})
+
+	rw := serveWith(h, http.MethodGet, "/posts?search=go&page=3", nil)
+	body := rw.Body.String()
+	if !strings.Contains(body, "table tab=all status=") || strings.Contains(body, "status=published") {
+		t.Error("expected the first tab selected by default, without filters")
+	}
+	for _, want := range []string{
+		`href="/posts?search=go"`,
+		`href="/posts?search=go&amp;tab=published"`,
+		`href="/posts?search=go&amp;tab=draft"`,
+		">12<",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %s in the tabs", want)
+		}
+	}
+
+	rw = serveWith(h, http.MethodGet, "/posts?tab=draft&filter_status=published", nil)
+	if body := rw.Body.String(); !strings.Contains(body, "table tab=draft status=draft") {
+		t.Error("expected the filters of the draft tab")
+	}
+
+	rw = serveWith(h, http.MethodGet, "/posts?tab=unknown", nil)
+	if body := rw.Body.String(); !strings.Contains(body, "table tab=all") {
+		t.Error("expected the first tab for an unknown tab")
+	}
+}
+
+func TestCRUDHandler_HeaderWidgets(t *testing.T) {
+	h := newHandler(&tabbedResource{newMockResource("posts")})
+
+	for path, want := range map[string]string{
+		"/posts":        "Widget of the list page",
+		"/posts/create": "Widget of the create page",
+		"/posts/1/edit": "Widget of the edit page",
+	} {
+		rw := serveWith(h, http.MethodGet, path, nil)
+		if !strings.Contains(rw.Body.String(), want) {
+			t.Errorf("%s: expected %q", path, want)
+		}
+	}
+
+	rw := serveWith(h, http.MethodGet, "/posts/1/edit?display="+DisplayDrawer, nil)
+	if strings.Contains(rw.Body.String(), "Widget of") {
+		t.Error("slide-overs should not show the header widgets")
+	}
+}
@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+)
+
+type createdRecordKey struct{}
+
+// SetCreatedRecord hands the record created by the Create of a resource to
+// its AfterCreate hook (see ResourceHookable). Resources built with
+// ResourceFor call it themselves.
+func SetCreatedRecord(ctx context.Context, item any) {
+	if slot, ok := ctx.Value(createdRecordKey{}).(*any); ok {
+		*slot = item
+	}
+}
+
+// createWithHooks creates a record of res from r between its BeforeCreate
+// and AfterCreate hooks.
+func createWithHooks(ctx context.Context, res Resource, r *http.Request) error {
+	hooks, ok := res.(ResourceHookable)
+	if !ok {
+		return res.Create(ctx, r)
+	}
+	if err := hooks.BeforeCreate(ctx, r); err != nil {
+		return err
+	}
+	var created any
+	ctx = context.WithValue(ctx, createdRecordKey{}, &created)
+	if err := res.Create(ctx, r); err != nil {
+		return err
+	}
+	return hooks.AfterCreate(ctx, created)
+}
+
+// updateWithHooks updates the record id of res from r between its
+// BeforeUpdate and AfterUpdate hooks, the latter given the updated record.
+func updateWithHooks(ctx context.Context, res Resource, id string, r *http.Request) error {
+	hooks, ok := res.(ResourceHookable)
+	if !ok {
+		return res.Update(ctx, id, r)
+	}
+	if err := hooks.BeforeUpdate(ctx, id, r); err != nil {
+		return err
+	}
+	if err := res.Update(ctx, id, r); err != nil {
+		return err
+	}
+	item, err := res.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	return hooks.AfterUpdate(ctx, id, item)
+}
+
+// deleteWithHooks runs del, deleting the record id of res, between its
+// BeforeDelete and AfterDelete hooks.
+func deleteWithHooks(ctx context.Context, res Resource, id string, del func() error) error {
+	hooks, ok := res.(ResourceHookable)
+	if !ok {
+		return del()
+	}
+	if err := hooks.BeforeDelete(ctx, id); err != nil {
+		return err
+	}
+	if err := del(); err != nil {
+		return err
+	}
+	return hooks.AfterDelete(ctx, id)
+}
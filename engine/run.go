@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/logger"
+)
+
+// DefaultShutdownTimeout bounds the graceful shutdown of Run.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// WithShutdownTimeout sets the time Run gives the in-flight requests, the
+// running jobs and the OnShutdown hooks to finish once the server is asked
+// to stop (DefaultShutdownTimeout by default).
+func (p *Panel) WithShutdownTimeout(d time.Duration) *Panel {
+	p.shutdownTimeout = d
+	return p
+}
+
+// OnShutdown registers a hook run by Run on shutdown, once the requests
+// are drained and the jobs queue is stopped, before the logger is closed.
+// Hooks run in registration order: register the tenant databases before
+// the databases they depend on.
+//
+//	panel.OnShutdown(func(ctx context.Context) error { return tenants.Close() })
+func (p *Panel) OnShutdown(fn func(ctx context.Context) error) *Panel {
+	p.shutdownHooks = append(p.shutdownHooks, fn)
+	return p
+}
+
+// Run serves the panel on addr until ctx is done or the process receives
+// SIGINT or SIGTERM, then shuts it down gracefully, within the timeout of
+// WithShutdownTimeout:
+//
+//  1. the server stops accepting connections, ends the notification
+//     streams and drains the in-flight requests;
+//  2. the jobs queue of WithJobs lets its running jobs finish, the queued
+//     ones left pending (see jobs.Queue.Shutdown);
+//  3. the OnShutdown hooks run, e.g. closing the tenant database pools;
+//  4. the default logger is flushed and closed.
+//
+// Run returns nil after a clean shutdown, else the errors of the steps.
+//
+//	if err := engine.Run(context.Background(), panel, ":8080"); err != nil {
+//	    log.Fatal(err)
+//	}
+func Run(ctx context.Context, p *Panel, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("engine: listen %s: %w", addr, err)
+	}
+	return serve(ctx, p, ln)
+}
+
+// serve serves p on ln for Run.
+func serve(ctx context.Context, p *Panel, ln net.Listener) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{
+		Handler:           p.Router(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	srv.RegisterOnShutdown(p.closeStreams)
+
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ln) }()
+	logger.Info("panel listening", "panel", p.ID, "addr", ln.Addr().String())
+
+	select {
+	case err := <-served:
+		// The server failed before being asked to stop.
+		return errors.Join(fmt.Errorf("engine: serve: %w", err), p.shutdown(context.Background()))
+	case <-ctx.Done():
+	}
+	stop()
+	logger.Info("panel shutting down", "panel", p.ID)
+
+	timeout := p.shutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, fmt.Errorf("engine: drain requests: %w", err))
+		_ = srv.Close()
+	}
+	<-served
+	errs = append(errs, p.shutdown(shutdownCtx))
+	return errors.Join(errs...)
+}
+
+// shutdown stops the jobs queue, runs the OnShutdown hooks then closes the
+// default logger, once the server is stopped.
+func (p *Panel) shutdown(ctx context.Context) error {
+	var errs []error
+	if p.jobs != nil {
+		if err := p.jobs.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("engine: stop jobs: %w", err))
+		}
+	}
+	for i, fn := range p.shutdownHooks {
+		if err := fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("engine: shutdown hook %d: %w", i, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		logger.Error("panel shutdown failed", "panel", p.ID, logger.Err(err))
+	} else {
+		logger.Info("panel stopped", "panel", p.ID)
+	}
+	if err := logger.Default().Close(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// closeStreams ends the notification streams of p, so that the server
+// shutting down does not wait for them.
+func (p *Panel) closeStreams() {
+	if p.notifHandler != nil {
+		p.notifHandler.CloseStreams()
+	}
+}
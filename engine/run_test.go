@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/health"
+	"github.com/bozz33/sublimeadmin/jobs"
+)
+
+func TestRun_gracefulShutdown(t *testing.T) {
+	started := make(chan struct{})
+	checks := health.NewRegistry().Register("slow", func(ctx context.Context) error {
+		close(started)
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	queue := jobs.NewQueue(1)
+	queue.Start()
+	var order []string
+	p := NewPanel("run-test").
+		WithHealthChecks(checks).
+		WithJobs(queue).
+		WithShutdownTimeout(time.Second).
+		OnShutdown(func(ctx context.Context) error { order = append(order, "tenants"); return nil }).
+		OnShutdown(func(ctx context.Context) error { order = append(order, "db"); return errors.New("already closed") })
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan error, 1)
+	go func() { stopped <- serve(ctx, p, ln) }()
+
+	// A request in flight when the shutdown starts is answered.
+	answered := make(chan int, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String() + "/readyz")
+		if err != nil {
+			answered <- 0
+			return
+		}
+		resp.Body.Close()
+		answered <- resp.StatusCode
+	}()
+	<-started
+	cancel()
+
+	if code := <-answered; code != http.StatusOK {
+		t.Errorf("in-flight request answered %d, want 200", code)
+	}
+	err = <-stopped
+	if err == nil || err.Error() != "engine: shutdown hook 1: already closed" {
+		t.Errorf("Run = %v, want the error of the failing hook", err)
+	}
+	if len(order) != 2 || order[0] != "tenants" || order[1] != "db" {
+		t.Errorf("hooks ran %v, want them in registration order", order)
+	}
+	if id := queue.Dispatch("late", func(ctx context.Context, job *jobs.Job) error { return nil }); id == "" {
+		t.Error("expected a dispatch after the shutdown to be accepted")
+	} else if job, _ := queue.Get(id); job.Status != jobs.StatusPending {
+		t.Errorf("job dispatched after the shutdown is %s, want pending", job.Status)
+	}
+	if _, err := net.Dial("tcp", ln.Addr().String()); err == nil {
+		t.Error("expected the listener to be closed")
+	}
+}
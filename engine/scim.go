@@ -0,0 +1,151 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/bozz33/sublimeadmin/scim"
+)
+
+// scimPrefix is the path of the SCIM endpoint under the panel path.
+const scimPrefix = "/scim/v2"
+
+// UserWithStatus is implemented by users that can be deactivated, e.g. by
+// SCIM deprovisioning: inactive users cannot sign in.
+type UserWithStatus interface {
+	IsActive() bool
+}
+
+// UserDeactivator is implemented by the UserRepository of a panel serving
+// SCIM (see WithSCIM) whose users are not kept by the scim.Store, so a
+// user deleted through SCIM can no longer sign in.
+type UserDeactivator interface {
+	DeactivateUser(ctx context.Context, id int) error
+}
+
+// WithSCIM serves a SCIM 2.0 endpoint of store at /scim/v2 under the panel
+// path, so an identity provider (Okta, Azure AD) provisions and
+// deprovisions the users and their groups. The identity provider
+// authenticates with token as a bearer token; the endpoint uses no session
+// and no CSRF cookie. Implement scim.Store on the user table of the
+// application, mapping the groups to the roles of the users (see
+// UserWithRoles):
+//
+//	panel.WithSCIM(store, os.Getenv("SCIM_TOKEN"))
+//
+// The SCIM userName of a user is the email of the panel user.
+// Deactivating or deleting a user ends its sessions, when the session
+// store can list them, and forgets its devices (see WithRememberMe). A
+// user inactive in store, or an inactive UserWithStatus, cannot sign in;
+// implement UserDeactivator when deleting a user from store keeps it in
+// the UserRepository.
+func (p *Panel) WithSCIM(store scim.Store, token string) *Panel {
+	p.scim = scim.NewServer(store, token)
+	p.scim.OnDeprovision = p.deprovisionUser
+	p.scimStore = store
+	return p
+}
+
+// scimRouter serves the requests to the SCIM endpoint with the server of
+// the panel, the others with next.
+func (p *Panel) scimRouter(next http.Handler) http.Handler {
+	if p.BaseURL != "" {
+		p.scim.BaseURL = strings.TrimRight(p.BaseURL, "/") + strings.TrimRight(p.Path, "/") + scimPrefix
+	}
+	scimHandler := http.StripPrefix(scimPrefix, p.scim)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == scimPrefix || strings.HasPrefix(r.URL.Path, scimPrefix+"/") {
+			scimHandler.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// deprovisionUser signs out the panel user of u, deprovisioned through
+// SCIM: it deactivates it (see UserDeactivator), forgets its devices and
+// ends its sessions. The panel user is the one whose email is the userName
+// of u, like for canSignIn. An error makes the identity provider retry.
+func (p *Panel) deprovisionUser(ctx context.Context, u *scim.User) error {
+	if p.Users == nil {
+		return nil
+	}
+	user, err := p.Users.FindByEmail(ctx, u.UserName)
+	if err != nil {
+		if isNotFoundError(err) {
+			return nil // not a user of the panel
+		}
+		return fmt.Errorf("scim: find user %s: %w", u.UserName, err)
+	}
+	if user == nil {
+		return nil
+	}
+	id := user.GetID()
+	if d, ok := p.Users.(UserDeactivator); ok {
+		if err := d.DeactivateUser(ctx, id); err != nil {
+			return fmt.Errorf("scim: deactivate user %d: %w", id, err)
+		}
+	}
+	if p.devicesEnabled() {
+		devices, err := p.deviceStore.Devices(ctx, id)
+		if err != nil {
+			return fmt.Errorf("scim: devices of user %d: %w", id, err)
+		}
+		for _, device := range devices {
+			if err := p.deviceStore.DeleteDevice(ctx, device.ID); err != nil {
+				return fmt.Errorf("scim: forget device %s: %w", device.ID, err)
+			}
+		}
+	}
+	err = p.endSessions(ctx, func(ctx context.Context) bool { return p.AuthManager.UserID(ctx) == id })
+	if err != nil {
+		return fmt.Errorf("scim: end sessions of user %d: %w", id, err)
+	}
+	return nil
+}
+
+// endSessions destroys the sessions for which match is true, when the
+// session store can list them (scs.IterableStore).
+func (p *Panel) endSessions(ctx context.Context, match func(ctx context.Context) bool) error {
+	if p.AuthManager == nil {
+		return nil
+	}
+	sm := p.AuthManager.Session()
+	switch sm.Store.(type) {
+	case scs.IterableCtxStore, scs.IterableStore:
+	default:
+		return nil
+	}
+	return sm.Iterate(ctx, func(ctx context.Context) error {
+		if !match(ctx) {
+			return nil
+		}
+		return sm.Destroy(ctx)
+	})
+}
+
+// canSignIn reports whether user may sign in: false for an inactive
+// UserWithStatus, or a user inactive in the SCIM store of the panel, whose
+// userName is the email of user. It fails closed when the store cannot be
+// read.
+func (p *Panel) canSignIn(ctx context.Context, user FrameworkUser) bool {
+	if u, ok := user.(UserWithStatus); ok && !u.IsActive() {
+		return false
+	}
+	if p == nil || p.scimStore == nil {
+		return true
+	}
+	users, err := p.scimStore.Users(ctx, scim.Filter{Attribute: "userName", Value: user.GetEmail()})
+	if err != nil {
+		return false
+	}
+	for _, u := range users {
+		if !u.Active {
+			return false
+		}
+	}
+	return true
+}
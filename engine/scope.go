@@ -0,0 +1,292 @@
+package engine
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/search"
+)
+
+// ResourceScoped is an optional interface for resources restricting the
+// records they list, e.g. hiding archived records or those of other teams:
+//
+//	func (r *PostResource) DefaultScope(ctx context.Context) []*engine.FilterExpr {
+//	    return []*engine.FilterExpr{
+//	        {Field: "archived_at", Operator: engine.FilterIsNull},
+//	        {Field: "team_id", Operator: engine.FilterEq, Value: teamID(ctx)},
+//	    }
+//	}
+//
+// The scope is ANDed with the filters of the user, on the list, the export
+// and the global search. Queries built by the resource receive it:
+// ListPaginated first in params.Filters, ListQuery in q.Scope (see
+// FilterCompiler). The records returned by List, Search and ListFiltered
+// are matched against it (see MatchFilters). WithoutScope lifts it.
+type ResourceScoped interface {
+	DefaultScope(ctx context.Context) []*FilterExpr
+}
+
+type scopeLiftedKey struct{}
+
+// WithoutScope returns a context in which the resources list all their
+// records, ignoring their DefaultScope. Lift it for privileged users with a
+// panel middleware:
+//
+//	panel.WithMiddleware(func(next http.Handler) http.Handler {
+//	    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//	        if isSuperAdmin(r.Context()) {
+//	            r = r.WithContext(engine.WithoutScope(r.Context()))
+//	        }
+//	        next.ServeHTTP(w, r)
+//	    })
+//	})
+func WithoutScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeLiftedKey{}, true)
+}
+
+// ScopeLifted reports whether ctx comes from WithoutScope.
+func ScopeLifted(ctx context.Context) bool {
+	lifted, _ := ctx.Value(scopeLiftedKey{}).(bool)
+	return lifted
+}
+
+// resourceScope returns the scope of the resource: the filter of its
+// tenant (see TenantScoper), which WithoutScope does not lift, then its
+// default scope unless lifted. The error is ErrNoTenant when the resource
+// is scoped by tenant and the context has none.
+func resourceScope(ctx context.Context, res Resource) ([]*FilterExpr, error) {
+	var scope []*FilterExpr
+	if s := resourceTenantScoper(ctx, res); s != nil {
+		filter, err := s.Filter(ctx)
+		if err != nil {
+			return nil, err
+		}
+		scope = append(scope, filter)
+	}
+	if rs, ok := res.(ResourceScoped); ok && !ScopeLifted(ctx) {
+		scope = append(scope, rs.DefaultScope(ctx)...)
+	}
+	return scope, nil
+}
+
+// scopeItems returns the items matching the scope.
+func scopeItems(items []any, scope []*FilterExpr) []any {
+	if len(scope) == 0 {
+		return items
+	}
+	kept := make([]any, 0, len(items))
+	for _, item := range items {
+		if MatchFilters(item, scope) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// MatchFilters reports whether a record, a struct or a map[string]any,
+// matches all the filters, for lists filtered in memory. A filter field
+// names a struct field, by its Go name, its json name or in snake case
+// (team_id → TeamID). Nil pointers, missing fields and invalid sql.Null*
+// values are null; like matches a substring, ignoring case.
+func MatchFilters(item any, filters []*FilterExpr) bool {
+	for _, f := range filters {
+		if f != nil && !matchFilter(item, f) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchFilter(item any, f *FilterExpr) bool {
+	if len(f.And) > 0 || len(f.Or) > 0 {
+		if !MatchFilters(item, f.And) {
+			return false
+		}
+		if len(f.Or) == 0 {
+			return true
+		}
+		for _, o := range f.Or {
+			if o != nil && matchFilter(item, o) {
+				return true
+			}
+		}
+		return false
+	}
+
+	value, ok := recordField(item, f.Field)
+	switch f.Operator {
+	case FilterEq, "":
+		if f.Value == nil {
+			return !ok
+		}
+		return ok && compareFilterValue(value, f.Value) == 0
+	case FilterNeq:
+		if f.Value == nil {
+			return ok
+		}
+		return !ok || compareFilterValue(value, f.Value) != 0
+	case FilterIsNull:
+		return !ok
+	case FilterIsNotNull:
+		return ok
+	}
+	if !ok {
+		return false
+	}
+	switch f.Operator {
+	case FilterGt:
+		return compareFilterValue(value, f.Value) > 0
+	case FilterGte:
+		return compareFilterValue(value, f.Value) >= 0
+	case FilterLt:
+		return compareFilterValue(value, f.Value) < 0
+	case FilterLte:
+		return compareFilterValue(value, f.Value) <= 0
+	case FilterLike, FilterNotLike:
+		found := strings.Contains(strings.ToLower(fmt.Sprint(value.Interface())), strings.ToLower(fmt.Sprint(f.Value)))
+		return found == (f.Operator == FilterLike)
+	case FilterBetween:
+		bounds, ok := f.Value.([]any)
+		return ok && len(bounds) == 2 &&
+			compareFilterValue(value, bounds[0]) >= 0 && compareFilterValue(value, bounds[1]) <= 0
+	case FilterIn, FilterNotIn:
+		values, ok := f.Value.([]any)
+		if !ok {
+			values = []any{f.Value}
+		}
+		found := false
+		for _, v := range values {
+			if compareFilterValue(value, v) == 0 {
+				found = true
+				break
+			}
+		}
+		return found == (f.Operator == FilterIn)
+	}
+	return false
+}
+
+// recordField returns the value of the field name of the record, with ok
+// false when it is null.
+func recordField(item any, name string) (reflect.Value, bool) {
+	v := reflect.ValueOf(item)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, false
+		}
+		v = v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+	case reflect.Struct:
+		s := v
+		v = s.FieldByNameFunc(func(field string) bool {
+			return field == name || strings.EqualFold(field, strings.ReplaceAll(name, "_", ""))
+		})
+		if !v.IsValid() {
+			v = jsonField(s, name)
+		}
+	default:
+		return reflect.Value{}, false
+	}
+	return nullable(v)
+}
+
+// jsonField returns the field of the struct v whose json name is name.
+func jsonField(v reflect.Value, name string) reflect.Value {
+	for i := range v.NumField() {
+		tag, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		if tag == name {
+			return v.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
+
+// nullable dereferences v, with ok false when it is null.
+func nullable(v reflect.Value) (reflect.Value, bool) {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || !v.CanInterface() {
+		return reflect.Value{}, false
+	}
+	// sql.NullString, sql.NullTime...
+	if v.Kind() == reflect.Struct && v.NumField() == 2 {
+		if valid := v.FieldByName("Valid"); valid.IsValid() && valid.Kind() == reflect.Bool {
+			if !valid.Bool() {
+				return reflect.Value{}, false
+			}
+			return v.Field(0), true
+		}
+	}
+	return v, true
+}
+
+// compareFilterValue orders the value of a field and the value of a filter:
+// numbers as numbers, times as times, and anything else as text.
+func compareFilterValue(value reflect.Value, want any) int {
+	w := reflect.ValueOf(want)
+	if n, ok := filterNumber(value); ok {
+		if m, ok := filterNumber(w); ok {
+			return cmp.Compare(n, m)
+		}
+	}
+	if t, ok := value.Interface().(time.Time); ok {
+		if u, ok := want.(time.Time); ok {
+			return t.Compare(u)
+		}
+	}
+	if b, ok := want.(bool); ok && value.Kind() == reflect.Bool {
+		return cmp.Compare(boolRank(value.Bool()), boolRank(b))
+	}
+	return cmp.Compare(fmt.Sprint(value.Interface()), fmt.Sprint(want))
+}
+
+func filterNumber(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// scopedSearchable drops the global search results of a scoped resource
+// outside of its scope.
+type scopedSearchable struct {
+	search.Searchable
+	res Resource
+}
+
+func (s scopedSearchable) Search(ctx context.Context, query string, limit int) ([]search.Result, error) {
+	scope, err := resourceScope(ctx, s.res)
+	if err != nil {
+		return nil, err
+	}
+	results, err := s.Searchable.Search(ctx, query, limit)
+	if err != nil || len(scope) == 0 {
+		return results, err
+	}
+	kept := results[:0]
+	for _, result := range results {
+		if item, err := s.res.Get(ctx, result.ID); err == nil && item != nil && MatchFilters(item, scope) {
+			kept = append(kept, result)
+		}
+	}
+	return kept, nil
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type scopedRecord struct {
+	ID         int
+	TeamID     int
+	Name       string `json:"full_name"`
+	Archived   *time.Time
+	Nickname   sql.NullString
+	Score      float64
+	internal   string
+	Membership map[string]any
+}
+
+func TestMatchFilters(t *testing.T) {
+	now := time.Now()
+	rec := &scopedRecord{ID: 1, TeamID: 7, Name: "Jane Doe", Score: 4.5, internal: "x"}
+	archived := &scopedRecord{ID: 2, TeamID: 8, Name: "John", Archived: &now, Nickname: sql.NullString{String: "jo", Valid: true}}
+
+	tests := []struct {
+		raw  string
+		item any
+		want bool
+	}{
+		{`["team_id",7]`, rec, true},
+		{`["TeamID",7]`, archived, false},
+		{`["full_name","like","jane"]`, rec, true},
+		{`["full_name","not like","jane"]`, rec, false},
+		{`["archived","is null"]`, rec, true},
+		{`["archived","is null"]`, archived, false},
+		{`["nickname","is null"]`, rec, true},
+		{`["nickname","jo"]`, archived, true},
+		{`["score","between",[4,5]]`, rec, true},
+		{`["score","gt",5]`, rec, false},
+		{`["team_id","in",[1,7]]`, rec, true},
+		{`["team_id","not in",[1,7]]`, rec, false},
+		{`["internal","x"]`, rec, false},
+		{`["missing","is null"]`, rec, true},
+		{`[["team_id",8],["OR"],["full_name","Jane Doe"]]`, rec, true},
+		{`[["team_id",8],["OR"],["full_name","Jim"]]`, rec, false},
+		{`["status","active"]`, map[string]any{"status": "active"}, true},
+		{`["status","is not null"]`, map[string]any{"status": nil}, false},
+	}
+	for _, tt := range tests {
+		filters, err := ParseFiltersJSON(tt.raw)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.raw, err)
+		}
+		if got := MatchFilters(tt.item, filters); got != tt.want {
+			t.Errorf("%s on %v: got %v, want %v", tt.raw, tt.item, got, tt.want)
+		}
+	}
+}
+
+// scopedPosts hides the draft posts.
+type scopedPosts struct {
+	*TypedResource[blogPost]
+}
+
+func (s *scopedPosts) DefaultScope(ctx context.Context) []*FilterExpr {
+	return []*FilterExpr{{Field: "status", Operator: FilterNeq, Value: "draft"}}
+}
+
+func newScopedPosts() *scopedPosts {
+	posts := []*blogPost{
+		{ID: 1, Title: "Published post", Status: "published"},
+		{ID: 2, Title: "Draft post", Status: "draft"},
+	}
+	return &scopedPosts{newPostResource(&posts)}
+}
+
+func TestResourceScoped_List(t *testing.T) {
+	h := NewCRUDHandler(newScopedPosts())
+
+	body := serveWith(h, http.MethodGet, "/blog-posts", nil).Body.String()
+	if !strings.Contains(body, "Published post") || strings.Contains(body, "Draft post") {
+		t.Error("the list should hide the records outside of the scope")
+	}
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/blog-posts", nil)
+	h.ServeHTTP(rw, req.WithContext(WithoutScope(req.Context())))
+	if body := rw.Body.String(); !strings.Contains(body, "Draft post") {
+		t.Error("WithoutScope should lift the scope")
+	}
+}
+
+func TestResourceScoped_Export(t *testing.T) {
+	h := NewExportHandler(newScopedPosts(), "csv")
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/blog-posts/export", nil))
+	if body := rw.Body.String(); !strings.Contains(body, "Published post") || strings.Contains(body, "Draft post") {
+		t.Errorf("the export should hide the records outside of the scope, got %s", body)
+	}
+}
+
+// scopedPaginated records the filters of ListPaginated.
+type scopedPaginated struct {
+	*mockResource
+	filters []*FilterExpr
+}
+
+func (s *scopedPaginated) DefaultScope(ctx context.Context) []*FilterExpr {
+	return []*FilterExpr{{Field: "team_id", Operator: FilterEq, Value: 7}}
+}
+
+func (s *scopedPaginated) ListPaginated(ctx context.Context, params PaginationParams) (*PageResult, error) {
+	s.filters = params.Filters
+	return NewPage(nil, 0, params.Page, params.PerPage), nil
+}
+
+func TestResourceScoped_ListPaginated(t *testing.T) {
+	res := &scopedPaginated{mockResource: newMockResource("members")}
+	h := NewPaginatedCRUDHandler(res)
+
+	serveWith(h, http.MethodGet, `/members?filters=["name","like","jo"]`, nil)
+	if len(res.filters) != 2 || res.filters[0].Field != "team_id" || res.filters[1].Field != "name" {
+		t.Fatalf("expected the scope before the filters of the request, got %+v", res.filters)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/members", nil)
+	h.ServeHTTP(httptest.NewRecorder(), req.WithContext(WithoutScope(req.Context())))
+	if len(res.filters) != 0 {
+		t.Errorf("WithoutScope should lift the scope, got %+v", res.filters)
+	}
+}
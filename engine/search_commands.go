@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/bozz33/sublimeadmin/search"
+)
+
+// maxNavigationResults caps the "Go to" results listed before the records.
+const maxNavigationResults = 5
+
+// AddCommands adds "Go to" targets to the global search, next to the pages
+// and the resources registered automatically:
+//
+//	panel.AddCommands(search.Command{
+//		Label:    "Billing",
+//		URL:      "/settings/billing",
+//		Icon:     "credit_card",
+//		Keywords: []string{"invoices", "subscription"},
+//	})
+//
+// A URL starting with "/" is relative to the panel.
+func (p *Panel) AddCommands(commands ...search.Command) *Panel {
+	p.commands = append(p.commands, commands...)
+	return p
+}
+
+// WithSearchAnalyzer sets the synonyms, stop words and typo tolerance of
+// the global search, applied to the queries before they reach the
+// searchables and to the "Go to" results. The searchables score their
+// results with search.Score to forgive typos.
+func (p *Panel) WithSearchAnalyzer(a *search.Analyzer) *Panel {
+	p.searchAnalyzer = a
+	return p
+}
+
+// navigationCommands returns the "Go to" targets of the panel: its pages,
+// the list and the creation form of its resources, then its commands.
+func (p *Panel) navigationCommands(ctx context.Context) []search.Command {
+	commands := make([]search.Command, 0, len(p.Pages)+2*len(p.Resources)+len(p.commands))
+	for _, pg := range p.Pages {
+		commands = append(commands, search.Command{
+			ID:       "page:" + pg.Slug(),
+			Label:    pg.Label(),
+			Subtitle: pg.Group(),
+			URL:      PanelURL(ctx, "/"+pg.Slug()),
+			Icon:     pg.Icon(),
+			Visible:  pg.CanAccess,
+		})
+	}
+	for _, res := range p.Resources {
+		commands = append(commands,
+			search.Command{
+				ID:       "list:" + res.Slug(),
+				Label:    res.PluralLabel(),
+				Subtitle: res.Group(),
+				URL:      PanelURL(ctx, "/"+res.Slug()),
+				Icon:     res.Icon(),
+				Visible:  res.CanRead,
+			},
+			search.Command{
+				ID:       "create:" + res.Slug(),
+				Label:    "New " + res.Label(),
+				Subtitle: res.PluralLabel(),
+				URL:      PanelURL(ctx, "/"+res.Slug()+"/create"),
+				Icon:     "add",
+				Keywords: []string{"create " + res.Label(), "add " + res.Label()},
+				Visible:  res.CanCreate,
+			},
+		)
+	}
+	for _, c := range p.commands {
+		if len(c.URL) > 0 && c.URL[0] == '/' {
+			c.URL = PanelURL(ctx, c.URL)
+		}
+		commands = append(commands, c)
+	}
+	return commands
+}
+
+// navigationResults returns the "Go to" results of query, the best first.
+func (p *Panel) navigationResults(ctx context.Context, query string) []search.Result {
+	results := search.MatchCommands(ctx, query, p.navigationCommands(ctx))
+	if len(results) > maxNavigationResults {
+		results = results[:maxNavigationResults]
+	}
+	return results
+}
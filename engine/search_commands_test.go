@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bozz33/sublimeadmin/search"
+)
+
+func TestPanel_SearchNavigation(t *testing.T) {
+	posts := []*blogPost{}
+	p := NewPanel("search-nav-test")
+	p.Path = "/admin"
+	p.AddResources(newPostResource(&posts))
+	p.AddPages(
+		NewSimplePage("settings", "Settings", nil).WithIcon("settings"),
+		NewSimplePage("audit", "Audit Log", nil).WithAccess(func(context.Context) bool { return false }),
+	)
+	p.AddCommands(search.Command{Label: "Billing", URL: "/settings/billing", Keywords: []string{"invoices"}})
+
+	search.Clear()
+	defer search.Clear()
+	query := func(q string) []search.Result {
+		rec := httptest.NewRecorder()
+		p.injectConfig(http.HandlerFunc(p.handleSearch)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/search?q="+q, nil))
+		var results []search.Result
+		if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+			t.Fatalf("GET /api/search?q=%s: %v", q, err)
+		}
+		return results
+	}
+
+	results := query("sett")
+	if len(results) == 0 || results[0].URL != "/admin/settings" || results[0].Kind != search.KindNavigation || results[0].ResourceType != search.NavigationType {
+		t.Fatalf("sett = %+v", results)
+	}
+	if results := query("audit"); len(results) != 0 {
+		t.Errorf("a page the user cannot access should not be listed: %+v", results)
+	}
+	results = query("new%20blog")
+	if len(results) == 0 || results[0].URL != "/admin/blog-posts/create" {
+		t.Errorf("new blog = %+v", results)
+	}
+	results = query("blog%20posts")
+	if len(results) == 0 || results[0].URL != "/admin/blog-posts" {
+		t.Errorf("blog posts = %+v", results)
+	}
+	results = query("invoices")
+	if len(results) != 1 || results[0].Title != "Billing" || results[0].URL != "/admin/settings/billing" {
+		t.Errorf("invoices = %+v", results)
+	}
+}
+
+func TestPanel_SearchAnalyzer(t *testing.T) {
+	p := NewPanel("search-analyzer-test").WithSearchAnalyzer(&search.Analyzer{
+		Synonyms:    map[string][]string{"preferences": {"settings"}},
+		MaxDistance: 1,
+	})
+	p.AddPages(NewSimplePage("settings", "Settings", nil))
+
+	search.Clear()
+	defer search.Clear()
+	for _, q := range []string{"preferences", "setings"} {
+		rec := httptest.NewRecorder()
+		p.injectConfig(http.HandlerFunc(p.handleSearch)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/search?q="+q, nil))
+		var results []search.Result
+		if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+			t.Fatalf("GET /api/search?q=%s: %v", q, err)
+		}
+		if len(results) != 1 || results[0].Title != "Settings" {
+			t.Errorf("%s = %+v", q, results)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/a-h/templ"
+)
+
+// ListTableID, ListPaginationID and ListFiltersID are the ids of the
+// table, of the pagination and of the active filters of a list page.
+//
+// Sorting, paging and filtering a list fetch it with the HX-Request: true
+// and HX-Target: ListTableID headers. CRUDHandler.List then renders the
+// table alone (see IsTableFragment), with the pagination and the active
+// filters swapped out of band and the URL of the list in the HX-Push-Url
+// header.
+const (
+	ListTableID      = "list-table"
+	ListPaginationID = "list-pagination"
+	ListFiltersID    = "list-filters"
+)
+
+type tableFragmentKey struct{}
+
+// WithTableFragment returns a context in which the list templates render
+// their table and pagination only.
+func WithTableFragment(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tableFragmentKey{}, true)
+}
+
+// IsTableFragment reports whether the list is rendered for a request of its
+// table alone (see ListTableID). Custom Table components should then render
+// the element of id ListTableID and, out of band, that of id
+// ListPaginationID.
+func IsTableFragment(ctx context.Context) bool {
+	fragment, _ := ctx.Value(tableFragmentKey{}).(bool)
+	return fragment
+}
+
+// isTableFragmentRequest reports whether r asks for the table of the list
+// only.
+func isTableFragmentRequest(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true" &&
+		r.Header.Get("HX-Target") == ListTableID &&
+		!isDrawer(r)
+}
+
+// renderTableFragment writes the table of a list without the layout,
+// pushing the URL of the list into the history of the browser.
+func renderTableFragment(w http.ResponseWriter, r *http.Request, content templ.Component) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("HX-Push-Url", PanelURL(r.Context(), r.URL.RequestURI()))
+	_ = content.Render(WithTableFragment(r.Context()), w)
+}
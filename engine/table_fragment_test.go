@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+// fragmentResource renders whether its table is requested alone.
+type fragmentResource struct {
+	*mockResource
+}
+
+func (f *fragmentResource) Table(ctx context.Context) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		_, err := fmt.Fprintf(w, `<div id="list-table">fragment=%v</div>`, IsTableFragment(ctx))
+		return err
+	})
+}
+
+func serveList(h http.Handler, path string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	return rw
+}
+
+func TestCRUDHandler_List_tableFragment(t *testing.T) {
+	h := newHandler(&fragmentResource{newMockResource("items")})
+
+	rw := serveList(h, "/items?sort=name&page=2", "HX-Request", "true", "HX-Target", ListTableID)
+	body := rw.Body.String()
+	if body != `<div id="list-table">fragment=true</div>` {
+		t.Errorf("expected the table alone, got %q", body)
+	}
+	if got := rw.Header().Get("HX-Push-Url"); got != "/items?sort=name&page=2" {
+		t.Errorf("HX-Push-Url = %q", got)
+	}
+	if vary := rw.Header().Get("Vary"); !strings.Contains(vary, "HX-Target") {
+		t.Errorf("Vary = %q", vary)
+	}
+}
+
+func TestCRUDHandler_List_fullPage(t *testing.T) {
+	h := newHandler(&fragmentResource{newMockResource("items")})
+
+	tests := []struct {
+		name    string
+		headers []string
+	}{
+		{"no headers", nil},
+		{"other target", []string{"HX-Request", "true", "HX-Target", "crud-drawer"}},
+		{"target without HX-Request", []string{"HX-Target", ListTableID}},
+	}
+	for _, tt := range tests {
+		rw := serveList(h, "/items", tt.headers...)
+		body := rw.Body.String()
+		if !strings.Contains(body, "fragment=false") || !strings.Contains(body, "<html") {
+			t.Errorf("%s: expected the whole page, got %q", tt.name, body)
+		}
+		if rw.Header().Get("HX-Push-Url") != "" {
+			t.Errorf("%s: unexpected HX-Push-Url", tt.name)
+		}
+	}
+}
+
+func TestCRUDHandler_List_tableFragment_panelPath(t *testing.T) {
+	p := NewPanel("admin").WithPath("/admin").AddResources(&fragmentResource{newMockResource("items")})
+	rw := serveList(Serve(p), "/admin/items?page=3", "HX-Request", "true", "HX-Target", ListTableID)
+	if got := rw.Header().Get("HX-Push-Url"); got != "/admin/items?page=3" {
+		t.Errorf("HX-Push-Url = %q, body %q", got, rw.Body.String())
+	}
+}
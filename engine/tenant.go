@@ -2,9 +2,16 @@ package engine
 
 import (
 	"context"
+	"log/slog"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
+
+	"github.com/bozz33/sublimeadmin/apperrors"
+	"github.com/bozz33/sublimeadmin/config"
+	"github.com/bozz33/sublimeadmin/logger"
+	"github.com/bozz33/sublimeadmin/middleware"
 )
 
 // ---------------------------------------------------------------------------
@@ -35,6 +42,13 @@ type TenantResolver interface {
 	Resolve(r *http.Request) (*Tenant, bool)
 }
 
+// TenantURLBuilder is implemented by the resolvers that can link to a
+// tenant; the topbar tenant switcher uses it to send the user to the
+// chosen tenant. path is the path of the panel ("/admin").
+type TenantURLBuilder interface {
+	TenantURL(r *http.Request, t *Tenant, path string) string
+}
+
 // SubdomainResolver resolves tenants by subdomain (e.g. {tenant}.example.com).
 type SubdomainResolver struct {
 	mu      sync.RWMutex
@@ -88,6 +102,16 @@ func (r *SubdomainResolver) Resolve(req *http.Request) (*Tenant, bool) {
 	return nil, false
 }
 
+// TenantURL implements TenantURLBuilder: the domain of the tenant, or its
+// subdomain of the base domain, on the scheme and port of the request.
+func (r *SubdomainResolver) TenantURL(req *http.Request, t *Tenant, path string) string {
+	host := t.Domain
+	if host == "" {
+		host = t.Subdomain + "." + r.base
+	}
+	return tenantHostURL(req, host, path)
+}
+
 // PathResolver resolves tenants from the URL path prefix (e.g. /acme/admin/...).
 type PathResolver struct {
 	mu      sync.RWMutex
@@ -121,6 +145,24 @@ func (r *PathResolver) Resolve(req *http.Request) (*Tenant, bool) {
 	return nil, false
 }
 
+// TenantURL implements TenantURLBuilder: the path prefixed with the tenant ID.
+func (r *PathResolver) TenantURL(_ *http.Request, t *Tenant, path string) string {
+	return "/" + t.ID + path
+}
+
+// tenantHostURL returns the URL of path on host, keeping the scheme and the
+// port of the request.
+func tenantHostURL(r *http.Request, host, path string) string {
+	scheme := "http"
+	if r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	if _, port, err := net.SplitHostPort(r.Host); err == nil && port != "" {
+		host = net.JoinHostPort(host, port)
+	}
+	return scheme + "://" + host + path
+}
+
 // ---------------------------------------------------------------------------
 // Context helpers
 // ---------------------------------------------------------------------------
@@ -141,6 +183,38 @@ func TenantFromContext(ctx context.Context) *Tenant {
 	return nil
 }
 
+func init() {
+	// Every context-aware log line carries the current tenant.
+	logger.RegisterEnricher("tenant_id", func(ctx context.Context) []slog.Attr {
+		if t := TenantFromContext(ctx); t != nil {
+			return []slog.Attr{slog.String("tenant_id", t.ID)}
+		}
+		return nil
+	})
+
+	// The default cache keys never mix the pages of two tenants.
+	middleware.SetCacheScope(func(ctx context.Context) string {
+		if t := TenantFromContext(ctx); t != nil {
+			return t.ID
+		}
+		return ""
+	})
+
+	// config.ForTenant resolves the panels.<id> and tenants.<id> sections.
+	config.RegisterPanelFunc(func(ctx context.Context) string {
+		if p := GetPanelFromContext(ctx); p != nil {
+			return p.ID
+		}
+		return ""
+	})
+	config.RegisterTenantFunc(func(ctx context.Context) string {
+		if t := TenantFromContext(ctx); t != nil {
+			return t.ID
+		}
+		return ""
+	})
+}
+
 // ---------------------------------------------------------------------------
 // TenantMiddleware — injects tenant into every request context
 // ---------------------------------------------------------------------------
@@ -165,6 +239,27 @@ func TenantMiddleware(resolver TenantResolver, requireTenant bool) func(http.Han
 	}
 }
 
+// CacheKeyByTenant keys the responses of the Cache middleware by tenant
+// as well, so that one tenant is never served the page of another under
+// the same URL. The default keys (middleware.CacheKeyByURL and
+// middleware.CacheKeyByUser) already include the tenant and the host; wrap
+// a custom key with it:
+//
+//	middleware.Cache(store, time.Minute, engine.CacheKeyByTenant(keyByLocale))
+//
+// Out of the tenant middleware, the requests are keyed by host.
+func CacheKeyByTenant(key middleware.CacheKeyFunc) middleware.CacheKeyFunc {
+	if key == nil {
+		key = middleware.CacheKeyByURL
+	}
+	return func(r *http.Request) string {
+		if t := TenantFromContext(r.Context()); t != nil {
+			return "tenant:" + t.ID + ":" + key(r)
+		}
+		return "host:" + r.Host + ":" + key(r)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // MultiPanelRouter — routes requests to the correct Panel per tenant
 // ---------------------------------------------------------------------------
@@ -189,11 +284,13 @@ func NewMultiPanelRouter(resolver TenantResolver) *MultiPanelRouter {
 	}
 }
 
-// RegisterPanel associates a Panel with a tenant ID.
-// Call Build() on the panel before registering, or let ServeHTTP build lazily.
+// RegisterPanel associates a Panel with a tenant ID. The searchable
+// resources of the panel are only searched by the requests of the tenant.
+// Register the panel before building it, or let ServeHTTP build lazily.
 func (m *MultiPanelRouter) RegisterPanel(tenantID string, p *Panel) *MultiPanelRouter {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	p.searchNamespace = tenantID
 	m.panels[tenantID] = p
 	return m
 }
@@ -223,7 +320,7 @@ func (m *MultiPanelRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Get or build the handler for this tenant
 	h := m.getHandler(tenant.ID)
 	if h == nil {
-		http.Error(w, "Panel not configured for tenant: "+tenant.ID, http.StatusServiceUnavailable)
+		apperrors.Handle(w, r, apperrors.ServiceUnavailable("Panel not configured for tenant: "+tenant.ID))
 		return
 	}
 
@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/middleware"
+)
+
+func TestChainedTenantResolver_cacheByContrib(t *testing.T) {
+	store := NewMemoryTenantStore([]*TenantConfig{
+		{ID: "acme", Subdomain: "acme", Status: TenantStatusActive},
+		{ID: "globex", Subdomain: "globex", Status: TenantStatusActive},
+	})
+	resolver := NewChainedTenantResolver(store, time.Minute, NewHeaderContrib("X-Tenant"), NewQueryContrib("tenant"))
+
+	req := httptest.NewRequest("GET", "/?tenant=acme", nil)
+	if got, ok := resolver.Resolve(req); !ok || got.ID != "acme" {
+		t.Fatalf("expected acme, got %+v", got)
+	}
+	if err := store.Update(context.Background(), &TenantConfig{ID: "acme", Subdomain: "acme", Status: TenantStatusSuspended}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := resolver.Resolve(req); !ok {
+		t.Fatal("expected acme still cached")
+	}
+	resolver.Forget("acme")
+	if got, ok := resolver.Resolve(req); ok {
+		t.Errorf("expected the suspended tenant forgotten, got %+v", got)
+	}
+}
+
+func TestCacheKeyByTenant(t *testing.T) {
+	key := CacheKeyByTenant(middleware.CacheKeyByURL)
+	req := httptest.NewRequest("GET", "/admin/posts", nil)
+	acme := key(req.WithContext(WithTenant(req.Context(), &Tenant{ID: "acme"})))
+	globex := key(req.WithContext(WithTenant(req.Context(), &Tenant{ID: "globex"})))
+	if acme == globex {
+		t.Errorf("expected a key per tenant, got %q twice", acme)
+	}
+	other := httptest.NewRequest("GET", "http://globex.example.com/admin/posts", nil)
+	if key(req) == key(other) {
+		t.Error("expected a key per host without tenant")
+	}
+}
+
+func TestCacheKeyByUser_tenant(t *testing.T) {
+	req := httptest.NewRequest("GET", "/admin/posts", nil)
+	acme := middleware.CacheKeyByUser(req.WithContext(WithTenant(req.Context(), &Tenant{ID: "acme"})))
+	globex := middleware.CacheKeyByUser(req.WithContext(WithTenant(req.Context(), &Tenant{ID: "globex"})))
+	if acme == globex {
+		t.Errorf("expected the default key to include the tenant, got %q twice", acme)
+	}
+}
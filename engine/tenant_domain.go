@@ -0,0 +1,262 @@
+package engine
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Metadata keys of a tenant tracking the verification of its custom
+// domain: the domain requested, its status, the token of its TXT record
+// and the time of its last check.
+const (
+	TenantMetaDomainPending = "domain.pending"
+	TenantMetaDomainStatus  = "domain.status"
+	TenantMetaDomainToken   = "domain.token"
+	TenantMetaDomainChecked = "domain.checked_at"
+)
+
+// Statuses of the custom domain of a tenant.
+const (
+	DomainStatusPending  = "pending"
+	DomainStatusVerified = "verified"
+	DomainStatusFailed   = "failed"
+)
+
+// DomainChallengePrefix is the label of the TXT record proving the
+// ownership of a domain: _sublime-challenge.admin.example.com.
+const DomainChallengePrefix = "_sublime-challenge."
+
+// domainPattern is the form of the custom domains: lowercase host names
+// with at least two labels, without port.
+var domainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+// DomainVerification is the DNS record a tenant creates to prove it owns
+// its custom domain (see TenantManager.RequestDomain).
+type DomainVerification struct {
+	Domain string
+	Record string // TXT record name
+	Value  string // TXT record value
+	Status string
+}
+
+// DomainHook handles the verification or the removal of the custom domain
+// of a tenant, e.g. to have the reverse proxy obtain or drop a
+// certificate. Its errors are logged.
+type DomainHook func(ctx context.Context, tenant *TenantConfig, domain string) error
+
+// TenantForgetter is implemented by the tenant resolvers caching tenants
+// (ChainedTenantResolver, TenantDatabaseResolver).
+type TenantForgetter interface {
+	Forget(tenantID string)
+}
+
+// RefreshResolvers registers resolvers whose cache entries of a tenant are
+// dropped when it is suspended or deleted, or its domain changes, so they
+// resolve the change at once.
+func (tm *TenantManager) RefreshResolvers(resolvers ...TenantForgetter) *TenantManager {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.resolvers = append(tm.resolvers, resolvers...)
+	return tm
+}
+
+// forget drops the tenant id from the caches of the resolvers.
+func (tm *TenantManager) forget(id string) {
+	tm.mu.Lock()
+	resolvers := slices.Clone(tm.resolvers)
+	tm.mu.Unlock()
+	for _, r := range resolvers {
+		r.Forget(id)
+	}
+}
+
+// OnDomainVerified registers a hook run after the custom domain of a
+// tenant is verified.
+func (tm *TenantManager) OnDomainVerified(fn DomainHook) *TenantManager {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.hooks.domainVerified = append(tm.hooks.domainVerified, fn)
+	return tm
+}
+
+// OnDomainRemoved registers a hook run after the custom domain of a tenant
+// is removed or replaced.
+func (tm *TenantManager) OnDomainRemoved(fn DomainHook) *TenantManager {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.hooks.domainRemoved = append(tm.hooks.domainRemoved, fn)
+	return tm
+}
+
+// emitDomain runs the domain hooks selected by pick.
+func (tm *TenantManager) emitDomain(ctx context.Context, event string, pick func(h *tenantHooks) []DomainHook, tenant *TenantConfig, domain string) {
+	tm.mu.Lock()
+	hooks := slices.Clone(pick(&tm.hooks))
+	tm.mu.Unlock()
+	for _, fn := range hooks {
+		if err := fn(ctx, tenant, domain); err != nil {
+			tm.logger.Error("tenant hook failed", "event", event, "id", tenant.ID, "domain", domain, "error", err)
+		}
+	}
+}
+
+// RequestDomain starts the verification of a custom domain for a tenant.
+// The tenant keeps its current domain until the TXT record returned is
+// found by VerifyDomain.
+func (tm *TenantManager) RequestDomain(ctx context.Context, id, domain string) (*DomainVerification, error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if !domainPattern.MatchString(domain) {
+		return nil, fmt.Errorf("invalid domain %q", domain)
+	}
+	cfg, err := tm.store.GetByNameOrId(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if taken, err := tm.domainTaken(ctx, cfg.ID, domain); err != nil {
+		return nil, err
+	} else if taken {
+		return nil, fmt.Errorf("domain %s is used by another tenant", domain)
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+	if cfg.Meta == nil {
+		cfg.Meta = map[string]string{}
+	}
+	cfg.Meta[TenantMetaDomainPending] = domain
+	cfg.Meta[TenantMetaDomainStatus] = DomainStatusPending
+	cfg.Meta[TenantMetaDomainToken] = hex.EncodeToString(token)
+	delete(cfg.Meta, TenantMetaDomainChecked)
+	if err := tm.store.Update(ctx, cfg); err != nil {
+		return nil, fmt.Errorf("request domain: %w", err)
+	}
+	tm.logger.Info("tenant domain requested", "id", cfg.ID, "domain", domain)
+	return cfg.DomainVerification(), nil
+}
+
+// VerifyDomain looks up the TXT record of the domain requested by a tenant.
+// When found, the domain becomes the domain of the tenant, resolved at
+// once by the resolvers of RefreshResolvers, and the OnDomainVerified
+// hooks run; a previous domain is removed. Otherwise the status is failed
+// and the check can be retried.
+func (tm *TenantManager) VerifyDomain(ctx context.Context, id string) error {
+	cfg, err := tm.store.GetByNameOrId(ctx, id)
+	if err != nil {
+		return err
+	}
+	v := cfg.DomainVerification()
+	if v == nil || v.Status == DomainStatusVerified {
+		return fmt.Errorf("tenant %s has no domain to verify", cfg.ID)
+	}
+
+	records, lookupErr := tm.cfg.LookupTXT(ctx, v.Record)
+	cfg.Meta[TenantMetaDomainChecked] = time.Now().UTC().Format(time.RFC3339)
+	if lookupErr != nil || !slices.Contains(records, v.Value) {
+		cfg.Meta[TenantMetaDomainStatus] = DomainStatusFailed
+		if err := tm.store.Update(ctx, cfg); err != nil {
+			return fmt.Errorf("verify domain: %w", err)
+		}
+		if lookupErr != nil {
+			return fmt.Errorf("domain %s not verified: %w", v.Domain, lookupErr)
+		}
+		return fmt.Errorf("domain %s not verified: TXT record %s not found", v.Domain, v.Record)
+	}
+
+	previous := cfg.Domain
+	cfg.Domain = v.Domain
+	cfg.Meta[TenantMetaDomainStatus] = DomainStatusVerified
+	delete(cfg.Meta, TenantMetaDomainPending)
+	delete(cfg.Meta, TenantMetaDomainToken)
+	if err := tm.store.Update(ctx, cfg); err != nil {
+		return fmt.Errorf("verify domain: %w", err)
+	}
+	tm.forget(cfg.ID)
+	tm.logger.Info("tenant domain verified", "id", cfg.ID, "domain", cfg.Domain)
+	if previous != "" && previous != cfg.Domain {
+		tm.emitDomain(ctx, "domain_removed", func(h *tenantHooks) []DomainHook { return h.domainRemoved }, cfg, previous)
+	}
+	tm.emitDomain(ctx, "domain_verified", func(h *tenantHooks) []DomainHook { return h.domainVerified }, cfg, cfg.Domain)
+	return nil
+}
+
+// RemoveDomain removes the custom domain of a tenant, verified or pending,
+// and runs the OnDomainRemoved hooks.
+func (tm *TenantManager) RemoveDomain(ctx context.Context, id string) error {
+	cfg, err := tm.store.GetByNameOrId(ctx, id)
+	if err != nil {
+		return err
+	}
+	removed := cfg.Domain
+	cfg.Domain = ""
+	for _, key := range []string{TenantMetaDomainPending, TenantMetaDomainStatus, TenantMetaDomainToken, TenantMetaDomainChecked} {
+		delete(cfg.Meta, key)
+	}
+	if err := tm.store.Update(ctx, cfg); err != nil {
+		return fmt.Errorf("remove domain: %w", err)
+	}
+	tm.forget(cfg.ID)
+	if removed != "" {
+		tm.logger.Info("tenant domain removed", "id", cfg.ID, "domain", removed)
+		tm.emitDomain(ctx, "domain_removed", func(h *tenantHooks) []DomainHook { return h.domainRemoved }, cfg, removed)
+	}
+	return nil
+}
+
+// domainTaken reports whether another tenant than id uses or requested
+// domain.
+func (tm *TenantManager) domainTaken(ctx context.Context, id, domain string) (bool, error) {
+	tenants, err := tm.AllTenants(ctx)
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(tenants, func(t *TenantConfig) bool {
+		return t.ID != id && (t.Domain == domain || t.Meta[TenantMetaDomainPending] == domain)
+	}), nil
+}
+
+// DomainVerification returns the verification of the custom domain of the
+// tenant: pending or failed with the record to create, or verified. Nil
+// when it has none.
+func (tc *TenantConfig) DomainVerification() *DomainVerification {
+	status := tc.Meta[TenantMetaDomainStatus]
+	if pending := tc.Meta[TenantMetaDomainPending]; pending != "" && status != DomainStatusVerified {
+		return &DomainVerification{
+			Domain: pending,
+			Record: DomainChallengePrefix + pending,
+			Value:  "sublime-verification=" + tc.Meta[TenantMetaDomainToken],
+			Status: status,
+		}
+	}
+	if tc.Domain != "" && status == DomainStatusVerified {
+		return &DomainVerification{Domain: tc.Domain, Status: DomainStatusVerified}
+	}
+	return nil
+}
+
+// DomainAskHandler answers the reverse proxies asking whether to obtain a
+// certificate for ?domain= (e.g. Caddy on-demand TLS): 200 for the domains
+// of the active tenants, verified or set by the operators, 404 otherwise.
+//
+//	mux.Handle("/internal/tls-ask", tm.DomainAskHandler())
+//
+// Mount it where only the proxy can reach it.
+func (tm *TenantManager) DomainAskHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		domain := strings.ToLower(r.URL.Query().Get("domain"))
+		cfg, err := tm.store.GetByNameOrId(r.Context(), domain)
+		if domain == "" || err != nil || !tenantActive(cfg) || cfg.Domain != domain {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
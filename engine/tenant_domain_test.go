@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTenantManager_VerifyDomain(t *testing.T) {
+	dns := map[string][]string{}
+	store := NewMemoryTenantStore([]*TenantConfig{
+		{ID: "acme", Subdomain: "acme", Status: TenantStatusActive},
+		{ID: "globex", Subdomain: "globex", Domain: "admin.globex.com", Status: TenantStatusActive},
+	})
+	tm := NewTenantManagerWithStore(store, TenantManagerConfig{
+		DatabaseStyle: DatabaseStyleSingle,
+		LookupTXT: func(_ context.Context, name string) ([]string, error) {
+			return dns[name], nil
+		},
+	})
+	resolver := NewChainedTenantResolver(store, time.Minute, NewDomainContrib(""))
+	var certs []string
+	tm.RefreshResolvers(resolver).OnDomainVerified(func(_ context.Context, _ *TenantConfig, domain string) error {
+		certs = append(certs, domain)
+		return nil
+	})
+	ctx := context.Background()
+
+	if _, err := tm.RequestDomain(ctx, "acme", "admin.globex.com"); err == nil {
+		t.Error("expected the domain of globex refused")
+	}
+	v, err := tm.RequestDomain(ctx, "acme", "Admin.Acme.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Domain != "admin.acme.com" || v.Record != "_sublime-challenge.admin.acme.com" || v.Status != DomainStatusPending {
+		t.Fatalf("unexpected verification %+v", v)
+	}
+
+	req := httptest.NewRequest("GET", "http://admin.acme.com/", nil)
+	if _, ok := resolver.Resolve(req); ok {
+		t.Error("expected the pending domain not resolved")
+	}
+	if err := tm.VerifyDomain(ctx, "acme"); err == nil {
+		t.Error("expected the verification failed without record")
+	}
+	if cfg, _ := tm.GetTenant(ctx, "acme"); cfg.DomainVerification().Status != DomainStatusFailed {
+		t.Errorf("expected the status failed, got %+v", cfg.DomainVerification())
+	}
+
+	dns[v.Record] = []string{"other", v.Value}
+	if err := tm.VerifyDomain(ctx, "acme"); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := resolver.Resolve(req); !ok || got.ID != "acme" {
+		t.Errorf("expected the verified domain resolved, got %+v", got)
+	}
+	if len(certs) != 1 || certs[0] != "admin.acme.com" {
+		t.Errorf("expected the verified hook run once, got %v", certs)
+	}
+
+	ask := func(domain string) int {
+		rw := httptest.NewRecorder()
+		tm.DomainAskHandler().ServeHTTP(rw, httptest.NewRequest("GET", "/?domain="+domain, nil))
+		return rw.Code
+	}
+	if ask("admin.acme.com") != http.StatusOK || ask("unknown.com") != http.StatusNotFound {
+		t.Error("expected a certificate for the verified domain only")
+	}
+
+	if err := tm.RemoveDomain(ctx, "acme"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := resolver.Resolve(req); ok {
+		t.Error("expected the removed domain no longer resolved")
+	}
+	if ask("admin.acme.com") != http.StatusNotFound {
+		t.Error("expected no certificate for the removed domain")
+	}
+}
@@ -31,6 +31,12 @@ func ExampleTenantSetup() {
 		panic(err)
 	}
 
+	// Optional: react to the lifecycle of the tenants
+	manager.OnCreated(func(ctx context.Context, tenant *TenantConfig) error {
+		slog.Info("welcome", "tenant", tenant.Name)
+		return nil
+	})
+
 	// 2. Initialize tenant registry (creates tables if they don't exist)
 	ctx := context.Background()
 	if err := manager.InitializeTenantRegistry(ctx); err != nil {
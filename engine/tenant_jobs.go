@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/bozz33/sublimeadmin/jobs"
+)
+
+const contextKeyTenantDB contextKey = "tenant_db"
+
+// WithTenantDB injects the database of the current tenant into the context.
+func WithTenantDB(ctx context.Context, db *sql.DB) context.Context {
+	return context.WithValue(ctx, contextKeyTenantDB, db)
+}
+
+// TenantDBFromContext retrieves the database of the current tenant from
+// context, set by TenantManager.JobContext. Returns nil when there is none.
+func TenantDBFromContext(ctx context.Context) *sql.DB {
+	if db, ok := ctx.Value(contextKeyTenantDB).(*sql.DB); ok {
+		return db
+	}
+	return nil
+}
+
+// JobContext prepares the context of the jobs dispatched for a tenant (see
+// jobs.Queue.WithTenantContext): the tenant, and with a database per
+// tenant its shared pool (see TenantDBFromContext).
+//
+//	queue.WithTenantContext(tm.JobContext)
+//	queue.DispatchForTenant(ctx, tenant.ID, "export", func(ctx context.Context, job *jobs.Job) error {
+//		db := engine.TenantDBFromContext(ctx)
+//		...
+//	})
+//
+// It fails for tenants not active, so their pending jobs don't run.
+func (tm *TenantManager) JobContext(ctx context.Context, tenantID string) (context.Context, error) {
+	cfg, err := tm.store.GetByNameOrId(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !tenantActive(cfg) {
+		return nil, fmt.Errorf("tenant %s is %s", cfg.ID, tenantStatus(cfg))
+	}
+	ctx = WithTenant(ctx, cfg.ToTenant())
+	if tm.cfg.DatabaseStyle == DatabaseStyleSingle {
+		return ctx, nil
+	}
+	db, err := tm.pools.Get(ctx, cfg.ID)
+	if err != nil {
+		return nil, err
+	}
+	return WithTenantDB(ctx, db), nil
+}
+
+// dispatchJob dispatches a job on queue, for the tenant of ctx if any.
+func dispatchJob(ctx context.Context, queue *jobs.Queue, name string, handler func(ctx context.Context, job *jobs.Job) error) string {
+	if t := TenantFromContext(ctx); t != nil {
+		return queue.DispatchForTenant(ctx, t.ID, name, handler)
+	}
+	return queue.DispatchContext(ctx, name, handler)
+}
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/jobs"
+)
+
+func TestTenantManager_JobContext(t *testing.T) {
+	store := NewMemoryTenantStore([]*TenantConfig{
+		{ID: "acme", Status: TenantStatusActive},
+		{ID: "globex", Status: TenantStatusActive},
+	})
+	tm := NewTenantManagerWithStore(store, TenantManagerConfig{DatabaseStyle: DatabaseStyleSingle})
+	queue := jobs.NewQueue(1).WithTenantContext(tm.JobContext)
+	queue.Start()
+	defer queue.Stop()
+
+	var tenant *Tenant
+	acmeID := queue.DispatchForTenant(context.Background(), "acme", "report", func(ctx context.Context, job *jobs.Job) error {
+		tenant = TenantFromContext(ctx)
+		return nil
+	})
+	if job, err := queue.Wait(acmeID, time.Second); err != nil || job.Status != jobs.StatusCompleted {
+		t.Fatalf("expected the job completed, got %v %v", job, err)
+	}
+	if tenant == nil || tenant.ID != "acme" {
+		t.Errorf("expected the job run for acme, got %+v", tenant)
+	}
+
+	if err := tm.SuspendTenant(context.Background(), "globex"); err != nil {
+		t.Fatal(err)
+	}
+	globexID := queue.DispatchForTenant(context.Background(), "globex", "report", func(ctx context.Context, job *jobs.Job) error {
+		return nil
+	})
+	if job, _ := queue.Wait(globexID, time.Second); job == nil || job.Status != jobs.StatusFailed {
+		t.Errorf("expected the job of a suspended tenant failed, got %+v", job)
+	}
+
+	res := NewJobsResource(queue)
+	if items, _ := res.ListFiltered(context.Background(), map[string]string{"tenant": "acme"}); len(items) != 1 {
+		t.Errorf("expected 1 job of acme, got %d", len(items))
+	}
+	inGlobex := WithTenant(context.Background(), &Tenant{ID: "globex"})
+	if items, _ := res.List(inGlobex); len(items) != 1 || items[0].(*jobs.Job).ID != globexID {
+		t.Errorf("expected only the job of globex in its panel, got %d", len(items))
+	}
+	if item, _ := res.Get(inGlobex, acmeID); item != nil {
+		t.Error("expected the job of acme hidden from globex")
+	}
+}
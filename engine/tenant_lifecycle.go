@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+)
+
+// Statuses of a TenantConfig.
+const (
+	TenantStatusActive    = "active"
+	TenantStatusSuspended = "suspended"
+	TenantStatusDeleted   = "deleted"
+)
+
+// TenantHook handles a lifecycle event of a tenant. Hooks run in order of
+// registration once the event happened; their errors are logged and do not
+// undo it.
+type TenantHook func(ctx context.Context, tenant *TenantConfig) error
+
+// tenantHooks are the lifecycle hooks of a TenantManager.
+type tenantHooks struct {
+	created   []TenantHook
+	suspended []TenantHook
+	deleted   []TenantHook
+
+	domainVerified []DomainHook
+	domainRemoved  []DomainHook
+}
+
+// OnCreated registers a hook run after a tenant is provisioned and saved.
+func (tm *TenantManager) OnCreated(fn TenantHook) *TenantManager {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.hooks.created = append(tm.hooks.created, fn)
+	return tm
+}
+
+// OnSuspended registers a hook run after a tenant is suspended.
+func (tm *TenantManager) OnSuspended(fn TenantHook) *TenantManager {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.hooks.suspended = append(tm.hooks.suspended, fn)
+	return tm
+}
+
+// OnDeleted registers a hook run after a tenant is deleted.
+func (tm *TenantManager) OnDeleted(fn TenantHook) *TenantManager {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.hooks.deleted = append(tm.hooks.deleted, fn)
+	return tm
+}
+
+// emit runs the hooks of event selected by pick.
+func (tm *TenantManager) emit(ctx context.Context, event string, pick func(h *tenantHooks) []TenantHook, tenant *TenantConfig) {
+	tm.mu.Lock()
+	hooks := append([]TenantHook(nil), pick(&tm.hooks)...)
+	tm.mu.Unlock()
+	for _, fn := range hooks {
+		if err := fn(ctx, tenant); err != nil {
+			tm.logger.Error("tenant hook failed", "event", event, "id", tenant.ID, "error", err)
+		}
+	}
+}
+
+// SuspendTenant marks a tenant suspended: it is no longer resolved (once
+// the resolver caches expire) but keeps its data.
+func (tm *TenantManager) SuspendTenant(ctx context.Context, id string) error {
+	cfg, err := tm.store.GetByNameOrId(ctx, id)
+	if err != nil {
+		return err
+	}
+	cfg.Status = TenantStatusSuspended
+	if err := tm.store.Update(ctx, cfg); err != nil {
+		return fmt.Errorf("suspend tenant: %w", err)
+	}
+	tm.pools.Close(cfg.ID)
+	tm.forget(cfg.ID)
+	tm.logger.Info("tenant suspended", "id", cfg.ID)
+	tm.emit(ctx, "suspended", func(h *tenantHooks) []TenantHook { return h.suspended }, cfg)
+	return nil
+}
+
+// ProvisioningState returns the progress of the provisioning of a tenant,
+// or nil if it was never provisioned.
+func (tm *TenantManager) ProvisioningState(ctx context.Context, id string) (*ProvisionState, error) {
+	if tm.states == nil {
+		return nil, nil
+	}
+	return tm.states.Load(ctx, id)
+}
+
+// tenantActive reports whether a tenant of the store may be resolved.
+func tenantActive(cfg *TenantConfig) bool {
+	return cfg.Status == "" || cfg.Status == TenantStatusActive
+}
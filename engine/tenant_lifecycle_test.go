@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTenantManager_provisioningResumes(t *testing.T) {
+	var ran []string
+	failSeed := true
+	pipeline := NewProvisioningPipeline().
+		Step(StepCreateDatabase, func(ctx context.Context, tenant *TenantConfig) error {
+			ran = append(ran, StepCreateDatabase)
+			return nil
+		}).
+		Step(StepSeedAdmin, func(ctx context.Context, tenant *TenantConfig) error {
+			ran = append(ran, StepSeedAdmin)
+			if failSeed {
+				return errors.New("smtp down")
+			}
+			return nil
+		})
+	store := NewMemoryTenantStore(nil)
+	tm := NewTenantManagerWithStore(store, TenantManagerConfig{Pipeline: pipeline})
+	var created []string
+	tm.OnCreated(func(ctx context.Context, tenant *TenantConfig) error {
+		created = append(created, tenant.ID)
+		return nil
+	})
+	ctx := context.Background()
+
+	err := tm.CreateTenant(ctx, &TenantConfig{ID: "acme"})
+	var perr *ProvisionError
+	if !errors.As(err, &perr) || perr.Step != StepSeedAdmin {
+		t.Fatalf("expected the seed step to fail, got %v", err)
+	}
+	if _, err := store.GetByNameOrId(ctx, "acme"); err == nil || len(created) != 0 {
+		t.Fatal("expected the tenant not saved")
+	}
+	state, _ := tm.ProvisioningState(ctx, "acme")
+	if state == nil || state.Steps[0].Status != ProvisionDone || state.Failed().Error != "smtp down" {
+		t.Fatalf("unexpected state %+v", state)
+	}
+
+	failSeed = false
+	ran = nil
+	if err := tm.CreateTenant(ctx, &TenantConfig{ID: "acme"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ran) != 1 || ran[0] != StepSeedAdmin {
+		t.Errorf("expected to resume at the failed step, ran %v", ran)
+	}
+	state, _ = tm.ProvisioningState(ctx, "acme")
+	if !state.Done() || state.Steps[1].Attempts != 2 {
+		t.Errorf("unexpected state %+v", state)
+	}
+	if len(created) != 1 {
+		t.Errorf("expected OnCreated once, got %v", created)
+	}
+}
+
+func TestTenantManager_lifecycleHooks(t *testing.T) {
+	store := NewMemoryTenantStore(nil)
+	tm := NewTenantManagerWithStore(store, TenantManagerConfig{DatabaseStyle: DatabaseStyleSingle})
+	var events []string
+	tm.OnSuspended(func(ctx context.Context, tenant *TenantConfig) error {
+		events = append(events, "suspended:"+tenant.ID)
+		return errors.New("ignored")
+	})
+	tm.OnDeleted(func(ctx context.Context, tenant *TenantConfig) error {
+		events = append(events, "deleted:"+tenant.Status)
+		return nil
+	})
+	ctx := context.Background()
+	if err := tm.CreateTenant(ctx, &TenantConfig{ID: "acme", Subdomain: "acme"}); err != nil {
+		t.Fatal(err)
+	}
+
+	resolver := NewChainedTenantResolver(store, time.Minute, NewQueryContrib("tenant"))
+	if _, ok := resolver.Resolve(httptest.NewRequest("GET", "/?tenant=acme", nil)); !ok {
+		t.Fatal("expected the active tenant resolved")
+	}
+	resolver.cache.flush()
+	if err := tm.SuspendTenant(ctx, "acme"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := resolver.Resolve(httptest.NewRequest("GET", "/?tenant=acme", nil)); ok {
+		t.Error("expected the suspended tenant not resolved")
+	}
+	if err := tm.DeleteTenant(ctx, "acme"); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0] != "suspended:acme" || events[1] != "deleted:deleted" {
+		t.Errorf("unexpected events %v", events)
+	}
+}
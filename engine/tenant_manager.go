@@ -5,7 +5,10 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -43,7 +46,7 @@ func (d *DefaultConnStrGenerator) Gen(_ context.Context, tenant TenantInfo) (str
 }
 
 // MigrationHook is called after a tenant database is created or upgraded.
-// Use it to run schema migrations or seed data.
+// Use it to run schema migrations or seed data (see MigrateStep).
 type MigrationHook func(ctx context.Context, db *sql.DB, tenant TenantInfo) error
 
 // TenantManagerConfig holds configuration for TenantManager.
@@ -54,6 +57,18 @@ type TenantManagerConfig struct {
 	ConnStrGen    ConnStrGenerator
 	MigrationHook MigrationHook
 	Logger        *slog.Logger
+	// Pipeline provisions new tenants. Defaults, for the per-tenant
+	// database styles, to CreateDatabaseStep and MigrateStep(MigrationHook).
+	Pipeline *ProvisioningPipeline
+	// ProvisionStates tracks the provisioning of the tenants. Defaults to
+	// the master database (NewTenantManager) or memory.
+	ProvisionStates ProvisionStateStore
+	// Pool configures the shared pools of the tenant databases (see
+	// TenantManager.TenantDB).
+	Pool TenantPoolConfig
+	// LookupTXT looks up the TXT records verifying the custom domains (see
+	// TenantManager.VerifyDomain). Defaults to net.DefaultResolver.
+	LookupTXT func(ctx context.Context, name string) ([]string, error)
 	// Connection pool settings (official Go database/sql patterns)
 	MaxOpenConns    int
 	MaxIdleConns    int
@@ -68,6 +83,12 @@ type TenantManager struct {
 	masterDB *sql.DB
 	mu       sync.Mutex
 	logger   *slog.Logger
+
+	states ProvisionStateStore
+	hooks  tenantHooks
+	pools  *TenantPools
+
+	resolvers []TenantForgetter // see RefreshResolvers
 }
 
 // NewTenantManager creates a TenantManager with a SQL master database.
@@ -83,22 +104,31 @@ func NewTenantManager(cfg TenantManagerConfig) (*TenantManager, error) {
 	masterDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 	masterDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
-	return &TenantManager{
+	tm := &TenantManager{
 		cfg:      cfg,
 		masterDB: masterDB,
 		logger:   cfg.Logger,
-	}, nil
+		states:   cfg.ProvisionStates,
+	}
+	tm.pools = newTenantPools(cfg.Pool, cfg.Driver, tm.Store, cfg.Logger)
+	return tm, nil
 }
 
 // NewTenantManagerWithStore creates a TenantManager with a custom TenantStore.
 // Use this with MemoryTenantStore for testing or simple setups.
 func NewTenantManagerWithStore(store TenantStore, cfg TenantManagerConfig) *TenantManager {
 	cfg = applyTenantManagerDefaults(cfg)
-	return &TenantManager{
+	if cfg.ProvisionStates == nil {
+		cfg.ProvisionStates = NewMemoryProvisionStateStore()
+	}
+	tm := &TenantManager{
 		cfg:    cfg,
 		store:  store,
 		logger: cfg.Logger,
+		states: cfg.ProvisionStates,
 	}
+	tm.pools = newTenantPools(cfg.Pool, cfg.Driver, tm.Store, cfg.Logger)
+	return tm
 }
 
 func applyTenantManagerDefaults(cfg TenantManagerConfig) TenantManagerConfig {
@@ -111,6 +141,13 @@ func applyTenantManagerDefaults(cfg TenantManagerConfig) TenantManagerConfig {
 	if cfg.DatabaseStyle == 0 {
 		cfg.DatabaseStyle = DatabaseStylePerTenant
 	}
+	if cfg.Pipeline == nil && cfg.DatabaseStyle != DatabaseStyleSingle {
+		steps := []ProvisionStep{CreateDatabaseStep(cfg.Driver)}
+		if cfg.MigrationHook != nil {
+			steps = append(steps, MigrateStep(cfg.Driver, cfg.MigrationHook))
+		}
+		cfg.Pipeline = NewProvisioningPipeline(steps...)
+	}
 	if cfg.MaxOpenConns == 0 {
 		cfg.MaxOpenConns = 25
 	}
@@ -123,6 +160,15 @@ func applyTenantManagerDefaults(cfg TenantManagerConfig) TenantManagerConfig {
 	if cfg.ConnMaxIdleTime == 0 {
 		cfg.ConnMaxIdleTime = 2 * time.Minute
 	}
+	if cfg.LookupTXT == nil {
+		cfg.LookupTXT = net.DefaultResolver.LookupTXT
+	}
+	if cfg.Pool.Limits.ConnMaxLifetime == 0 {
+		cfg.Pool.Limits.ConnMaxLifetime = cfg.ConnMaxLifetime
+	}
+	if cfg.Pool.Limits.ConnMaxIdleTime == 0 {
+		cfg.Pool.Limits.ConnMaxIdleTime = cfg.ConnMaxIdleTime
+	}
 	return cfg
 }
 
@@ -147,16 +193,35 @@ func (tm *TenantManager) InitializeTenantRegistry(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("create tenants table: %w", err)
 	}
+	_, err = tm.masterDB.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS tenant_provisioning (
+			tenant_id  TEXT PRIMARY KEY,
+			state      TEXT NOT NULL,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("create tenant_provisioning table: %w", err)
+	}
 	tm.store = NewSQLTenantStore(tm.masterDB)
+	if tm.states == nil {
+		tm.states = NewSQLProvisionStateStore(tm.masterDB)
+	}
 	tm.logger.Info("tenant registry initialized")
 	return nil
 }
 
-// CreateTenant creates a new tenant with automatic database provisioning.
+// CreateTenant creates a new tenant with automatic database provisioning,
+// then runs the OnCreated hooks. When a step of the provisioning fails, a
+// *ProvisionError is returned and the tenant is not saved; calling
+// CreateTenant again with the same tenant resumes at the failed step.
 func (tm *TenantManager) CreateTenant(ctx context.Context, cfg *TenantConfig) error {
 	if cfg.Meta == nil {
 		cfg.Meta = make(map[string]string)
 	}
+	if cfg.Status == "" {
+		cfg.Status = TenantStatusActive
+	}
 
 	if cfg.DatabaseDSN == "" {
 		dsn, err := tm.cfg.ConnStrGen.Gen(ctx, cfg)
@@ -166,11 +231,11 @@ func (tm *TenantManager) CreateTenant(ctx context.Context, cfg *TenantConfig) er
 		cfg.DatabaseDSN = dsn
 	}
 
-	// Provision per-tenant database
-	if tm.cfg.DatabaseStyle == DatabaseStylePerTenant || tm.cfg.DatabaseStyle == DatabaseStyleMulti {
-		if err := tm.provisionTenantDatabase(ctx, cfg); err != nil {
-			return fmt.Errorf("provision tenant database: %w", err)
+	if tm.cfg.Pipeline != nil {
+		if _, err := tm.cfg.Pipeline.Run(ctx, tm.states, cfg); err != nil {
+			return err
 		}
+		tm.logger.Info("tenant provisioned", "id", cfg.ID, "dsn", cfg.DatabaseDSN)
 	}
 
 	if err := tm.store.Create(ctx, cfg); err != nil {
@@ -178,39 +243,7 @@ func (tm *TenantManager) CreateTenant(ctx context.Context, cfg *TenantConfig) er
 	}
 
 	tm.logger.Info("tenant created", "id", cfg.ID, "dsn", cfg.DatabaseDSN)
-	return nil
-}
-
-// provisionTenantDatabase creates and initializes a tenant's database.
-func (tm *TenantManager) provisionTenantDatabase(ctx context.Context, cfg *TenantConfig) error {
-	db, err := sql.Open(tm.cfg.Driver, cfg.DatabaseDSN)
-	if err != nil {
-		return fmt.Errorf("open tenant database: %w", err)
-	}
-	defer db.Close()
-
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(3)
-	db.SetConnMaxLifetime(tm.cfg.ConnMaxLifetime)
-	db.SetConnMaxIdleTime(tm.cfg.ConnMaxIdleTime)
-
-	_, err = db.ExecContext(ctx, `
-		CREATE TABLE IF NOT EXISTS schema_migrations (
-			version    TEXT PRIMARY KEY,
-			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("create schema_migrations: %w", err)
-	}
-
-	if tm.cfg.MigrationHook != nil {
-		if err := tm.cfg.MigrationHook(ctx, db, cfg); err != nil {
-			return fmt.Errorf("migration hook: %w", err)
-		}
-	}
-
-	tm.logger.Info("tenant database provisioned", "id", cfg.ID, "dsn", cfg.DatabaseDSN)
+	tm.emit(ctx, "created", func(h *tenantHooks) []TenantHook { return h.created }, cfg)
 	return nil
 }
 
@@ -233,35 +266,69 @@ func (tm *TenantManager) UpdateTenant(ctx context.Context, cfg *TenantConfig) er
 	return nil
 }
 
-// DeleteTenant soft-deletes a tenant (keeps database for backup).
+// DeleteTenant soft-deletes a tenant (keeps database for backup), forgets
+// its provisioning and runs the OnDeleted hooks.
 func (tm *TenantManager) DeleteTenant(ctx context.Context, id string) error {
-	if err := tm.store.Delete(ctx, id); err != nil {
+	cfg, err := tm.store.GetByNameOrId(ctx, id)
+	if err != nil {
+		cfg = &TenantConfig{ID: id}
+	}
+	if err := tm.store.Delete(ctx, cfg.ID); err != nil {
 		return fmt.Errorf("delete tenant: %w", err)
 	}
-	tm.logger.Info("tenant deleted", "id", id)
+	if tm.states != nil {
+		if err := tm.states.Delete(ctx, cfg.ID); err != nil {
+			tm.logger.Warn("forget tenant provisioning", "id", cfg.ID, "error", err)
+		}
+	}
+	tm.pools.Close(cfg.ID)
+	tm.forget(cfg.ID)
+	cfg.Status = TenantStatusDeleted
+	tm.logger.Info("tenant deleted", "id", cfg.ID)
+	tm.emit(ctx, "deleted", func(h *tenantHooks) []TenantHook { return h.deleted }, cfg)
 	return nil
 }
 
-// OpenTenantDB opens a connection to a tenant's database with proper pool settings.
+// OpenTenantDB opens a new pool to a tenant's database, with the pool
+// settings of the tenant (see TenantPoolConfig). The caller closes it.
+//
+// Deprecated: use TenantDB, which shares one pool per tenant.
 func (tm *TenantManager) OpenTenantDB(ctx context.Context, nameOrId string) (*sql.DB, error) {
 	cfg, err := tm.store.GetByNameOrId(ctx, nameOrId)
 	if err != nil {
 		return nil, err
 	}
-	db, err := sql.Open(tm.cfg.Driver, cfg.DatabaseDSN)
-	if err != nil {
-		return nil, fmt.Errorf("open tenant db: %w", err)
-	}
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(3)
-	db.SetConnMaxLifetime(tm.cfg.ConnMaxLifetime)
-	db.SetConnMaxIdleTime(tm.cfg.ConnMaxIdleTime)
-	return db, nil
+	return tm.pools.open(ctx, cfg)
+}
+
+// TenantDB returns the shared pool of a tenant's database (see
+// TenantPools.Get). Do not close it.
+func (tm *TenantManager) TenantDB(ctx context.Context, nameOrId string) (*sql.DB, error) {
+	return tm.pools.Get(ctx, nameOrId)
 }
 
+// Pools returns the pools of the tenant databases, for their statistics
+// and health checks.
+func (tm *TenantManager) Pools() *TenantPools { return tm.pools }
+
 // Store returns the underlying TenantStore.
 func (tm *TenantManager) Store() TenantStore { return tm.store }
 
+// PingContext pings the master database, so that the manager is a
+// health.Pinger:
+//
+//	checks.Register("tenants", health.Ping(manager))
+func (tm *TenantManager) PingContext(ctx context.Context) error {
+	return tm.masterDB.PingContext(ctx)
+}
+
+// Close closes the pools of the tenant databases, then the master
+// database, e.g. on shutdown (see Panel.OnShutdown).
+func (tm *TenantManager) Close() error {
+	tm.pools.CloseAll()
+	return tm.masterDB.Close()
+}
+
 // TenantResolveContrib is a single resolver in a chain.
 type TenantResolveContrib interface {
 	Name() string
@@ -284,25 +351,49 @@ func NewChainedTenantResolver(store TenantStore, ttl time.Duration, contribs ...
 	}
 }
 
-// Resolve tries each contrib in order, returns first match.
+// Resolve tries each contrib in order, returns first match. The tenants
+// are cached by contrib and value, so that a value of one contrib (e.g. a
+// header) never hits the tenant cached for another (e.g. a domain).
 func (r *ChainedTenantResolver) Resolve(req *http.Request) (*Tenant, bool) {
 	for _, contrib := range r.contribs {
 		if slug, ok := contrib.Resolve(req); ok && slug != "" {
-			if t, hit := r.cache.get(slug); hit {
+			key := contrib.Name() + ":" + slug
+			if t, hit := r.cache.get(key); hit {
 				return t, true
 			}
 			cfg, err := r.store.GetByNameOrId(req.Context(), slug)
-			if err != nil {
+			if err != nil || !tenantActive(cfg) {
 				continue
 			}
 			t := cfg.ToTenant()
-			r.cache.set(slug, t)
+			r.cache.set(key, t)
 			return t, true
 		}
 	}
 	return nil, false
 }
 
+// Forget drops the cached entries of a tenant, e.g. when it is suspended:
+//
+//	tm.OnSuspended(func(ctx context.Context, t *engine.TenantConfig) error {
+//		resolver.Forget(t.ID)
+//		return nil
+//	})
+func (r *ChainedTenantResolver) Forget(tenantID string) {
+	r.cache.deleteTenant(tenantID)
+}
+
+// TenantURL implements TenantURLBuilder with the first contrib that can
+// link to a tenant, or returns "".
+func (r *ChainedTenantResolver) TenantURL(req *http.Request, t *Tenant, path string) string {
+	for _, contrib := range r.contribs {
+		if b, ok := contrib.(TenantURLBuilder); ok {
+			return b.TenantURL(req, t, path)
+		}
+	}
+	return ""
+}
+
 // DomainContrib resolves tenant from the full hostname.
 type DomainContrib struct{ BaseDomain string }
 
@@ -321,6 +412,15 @@ func (c *DomainContrib) Resolve(r *http.Request) (string, bool) {
 	return "", false
 }
 
+// TenantURL implements TenantURLBuilder.
+func (c *DomainContrib) TenantURL(r *http.Request, t *Tenant, path string) string {
+	host := t.Domain
+	if host == "" {
+		host = t.ID + "." + c.BaseDomain
+	}
+	return tenantHostURL(r, host, path)
+}
+
 // HeaderContrib resolves tenant from a request header (e.g. X-Tenant-ID).
 type HeaderContrib struct{ Header string }
 
@@ -341,6 +441,11 @@ func (c *QueryContrib) Resolve(r *http.Request) (string, bool) {
 	return v, v != ""
 }
 
+// TenantURL implements TenantURLBuilder.
+func (c *QueryContrib) TenantURL(_ *http.Request, t *Tenant, path string) string {
+	return path + "?" + url.Values{c.Param: {t.ID}}.Encode()
+}
+
 // CookieContrib resolves tenant from a cookie.
 type CookieContrib struct{ CookieName string }
 
@@ -365,6 +470,11 @@ func (c *PathContrib) Resolve(r *http.Request) (string, bool) {
 	return seg, seg != ""
 }
 
+// TenantURL implements TenantURLBuilder.
+func (c *PathContrib) TenantURL(_ *http.Request, t *Tenant, path string) string {
+	return "/" + t.ID + path
+}
+
 // TenantDatabaseResolver resolves tenants from the database registry.
 // Prefer ChainedTenantResolver for new code.
 type TenantDatabaseResolver struct {
@@ -382,6 +492,11 @@ func NewTenantDatabaseResolver(manager *TenantManager, baseDomain string) *Tenan
 	}
 }
 
+// Forget drops the cached entries of a tenant, e.g. when it is suspended.
+func (r *TenantDatabaseResolver) Forget(tenantID string) {
+	r.cache.deleteTenant(tenantID)
+}
+
 func (r *TenantDatabaseResolver) Resolve(req *http.Request) (*Tenant, bool) {
 	host := stripPort(req.Host)
 	var slug string
@@ -397,7 +512,7 @@ func (r *TenantDatabaseResolver) Resolve(req *http.Request) (*Tenant, bool) {
 		return t, true
 	}
 	cfg, err := r.manager.GetTenant(req.Context(), slug)
-	if err != nil {
+	if err != nil || !tenantActive(cfg) {
 		return nil, false
 	}
 	t := cfg.ToTenant()
@@ -467,6 +582,18 @@ func (c *tenantLRUCache) delete(key string) {
 	delete(c.entries, key)
 }
 
+// deleteTenant drops the entries of the tenant id, under any key.
+func (c *tenantLRUCache) deleteTenant(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, e := range c.entries {
+		if e.tenant.ID == id {
+			delete(c.entries, key)
+			c.order = slices.DeleteFunc(c.order, func(k string) bool { return k == key })
+		}
+	}
+}
+
 func (c *tenantLRUCache) flush() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -0,0 +1,343 @@
+package engine
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/mailer"
+)
+
+// Names of the provisioning steps provided by the engine.
+const (
+	StepCreateDatabase = "create_database"
+	StepMigrate        = "migrate"
+	StepSeedAdmin      = "seed_admin"
+	StepWelcomeEmail   = "welcome_email"
+)
+
+// ProvisionStepStatus is the status of a provisioning step of a tenant.
+type ProvisionStepStatus string
+
+const (
+	ProvisionPending ProvisionStepStatus = "pending"
+	ProvisionRunning ProvisionStepStatus = "running"
+	ProvisionDone    ProvisionStepStatus = "done"
+	ProvisionFailed  ProvisionStepStatus = "failed"
+)
+
+// ProvisionStep is a step of a ProvisioningPipeline. Run receives the tenant
+// being created, its DatabaseDSN set. A step that failed is run again when
+// the provisioning is resumed, so it must tolerate what a previous attempt
+// left behind (CREATE TABLE IF NOT EXISTS, upserts...).
+type ProvisionStep struct {
+	Name string
+	Run  func(ctx context.Context, tenant *TenantConfig) error
+}
+
+// ProvisionStepState is the progress of a step for a tenant.
+type ProvisionStepState struct {
+	Name       string              `json:"name"`
+	Status     ProvisionStepStatus `json:"status"`
+	Error      string              `json:"error,omitempty"`
+	Attempts   int                 `json:"attempts"`
+	StartedAt  time.Time           `json:"started_at,omitzero"`
+	FinishedAt time.Time           `json:"finished_at,omitzero"`
+}
+
+// ProvisionState is the progress of the provisioning of a tenant, one entry
+// per step of the pipeline in order.
+type ProvisionState struct {
+	TenantID string               `json:"tenant_id"`
+	Steps    []ProvisionStepState `json:"steps"`
+}
+
+// Done reports whether every step succeeded.
+func (s *ProvisionState) Done() bool {
+	for _, st := range s.Steps {
+		if st.Status != ProvisionDone {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed returns the step that failed, or nil.
+func (s *ProvisionState) Failed() *ProvisionStepState {
+	for i := range s.Steps {
+		if s.Steps[i].Status == ProvisionFailed {
+			return &s.Steps[i]
+		}
+	}
+	return nil
+}
+
+// ProvisionError is returned when a step of the provisioning of a tenant
+// fails. The steps before it are not run again on resume.
+type ProvisionError struct {
+	TenantID string
+	Step     string
+	Err      error
+}
+
+func (e *ProvisionError) Error() string {
+	return fmt.Sprintf("provision tenant %s: step %s: %v", e.TenantID, e.Step, e.Err)
+}
+
+func (e *ProvisionError) Unwrap() error { return e.Err }
+
+// ProvisionStateStore persists the ProvisionState of the tenants, so that
+// a failed provisioning resumes at the failed step, also after a restart.
+//
+// MemoryProvisionStateStore keeps the states per process,
+// SQLProvisionStateStore in the master database.
+type ProvisionStateStore interface {
+	// Load returns the state of the tenant, or nil if none is saved.
+	Load(ctx context.Context, tenantID string) (*ProvisionState, error)
+	Save(ctx context.Context, state *ProvisionState) error
+	Delete(ctx context.Context, tenantID string) error
+}
+
+// MemoryProvisionStateStore is an in-process ProvisionStateStore.
+type MemoryProvisionStateStore struct {
+	mu     sync.RWMutex
+	states map[string]ProvisionState
+}
+
+// NewMemoryProvisionStateStore creates an empty in-memory store.
+func NewMemoryProvisionStateStore() *MemoryProvisionStateStore {
+	return &MemoryProvisionStateStore{states: make(map[string]ProvisionState)}
+}
+
+func (m *MemoryProvisionStateStore) Load(_ context.Context, tenantID string) (*ProvisionState, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.states[tenantID]
+	if !ok {
+		return nil, nil
+	}
+	s.Steps = append([]ProvisionStepState(nil), s.Steps...)
+	return &s, nil
+}
+
+func (m *MemoryProvisionStateStore) Save(_ context.Context, state *ProvisionState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := *state
+	s.Steps = append([]ProvisionStepState(nil), state.Steps...)
+	m.states[state.TenantID] = s
+	return nil
+}
+
+func (m *MemoryProvisionStateStore) Delete(_ context.Context, tenantID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.states, tenantID)
+	return nil
+}
+
+// SQLProvisionStateStore persists the provisioning states in the
+// tenant_provisioning table (see TenantManager.InitializeTenantRegistry).
+type SQLProvisionStateStore struct {
+	db *sql.DB
+}
+
+// NewSQLProvisionStateStore creates a SQL-backed provisioning state store.
+func NewSQLProvisionStateStore(db *sql.DB) *SQLProvisionStateStore {
+	return &SQLProvisionStateStore{db: db}
+}
+
+func (s *SQLProvisionStateStore) Load(ctx context.Context, tenantID string) (*ProvisionState, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, `SELECT state FROM tenant_provisioning WHERE tenant_id = ?`, tenantID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("query provisioning state: %w", err)
+	}
+	var state ProvisionState
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		return nil, fmt.Errorf("decode provisioning state: %w", err)
+	}
+	return &state, nil
+}
+
+func (s *SQLProvisionStateStore) Save(ctx context.Context, state *ProvisionState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO tenant_provisioning (tenant_id, state) VALUES (?, ?)
+		ON CONFLICT (tenant_id) DO UPDATE SET state = excluded.state, updated_at = CURRENT_TIMESTAMP
+	`, state.TenantID, string(data))
+	if err != nil {
+		return fmt.Errorf("save provisioning state: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLProvisionStateStore) Delete(ctx context.Context, tenantID string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM tenant_provisioning WHERE tenant_id = ?`, tenantID); err != nil {
+		return fmt.Errorf("delete provisioning state: %w", err)
+	}
+	return nil
+}
+
+// ProvisioningPipeline runs the steps provisioning a new tenant in order,
+// e.g.:
+//
+//	engine.NewProvisioningPipeline(
+//		engine.CreateDatabaseStep("sqlite3"),
+//		engine.MigrateStep("sqlite3", migrate),
+//		engine.SeedAdminStep("sqlite3", seedAdmin),
+//		engine.WelcomeEmailStep(m, welcome),
+//	)
+//
+// The status of each step is saved as it runs; after a failure, running the
+// pipeline again for the tenant skips the steps already done.
+type ProvisioningPipeline struct {
+	steps []ProvisionStep
+}
+
+// NewProvisioningPipeline creates a pipeline of the steps.
+func NewProvisioningPipeline(steps ...ProvisionStep) *ProvisioningPipeline {
+	return &ProvisioningPipeline{steps: steps}
+}
+
+// Step appends a step to the pipeline.
+func (p *ProvisioningPipeline) Step(name string, run func(ctx context.Context, tenant *TenantConfig) error) *ProvisioningPipeline {
+	p.steps = append(p.steps, ProvisionStep{Name: name, Run: run})
+	return p
+}
+
+// Steps returns the steps of the pipeline.
+func (p *ProvisioningPipeline) Steps() []ProvisionStep { return p.steps }
+
+// Run provisions the tenant, resuming from the state saved in states (nil
+// keeps no state). It returns the state reached and, when a step fails, a
+// *ProvisionError.
+func (p *ProvisioningPipeline) Run(ctx context.Context, states ProvisionStateStore, tenant *TenantConfig) (*ProvisionState, error) {
+	var saved *ProvisionState
+	if states != nil {
+		var err error
+		if saved, err = states.Load(ctx, tenant.ID); err != nil {
+			return nil, err
+		}
+	}
+	state := p.state(tenant.ID, saved)
+	save := func() error {
+		if states == nil {
+			return nil
+		}
+		return states.Save(ctx, state)
+	}
+
+	for i, step := range p.steps {
+		st := &state.Steps[i]
+		if st.Status == ProvisionDone {
+			continue
+		}
+		st.Status, st.Error, st.StartedAt, st.FinishedAt = ProvisionRunning, "", time.Now(), time.Time{}
+		st.Attempts++
+		if err := save(); err != nil {
+			return state, err
+		}
+
+		err := step.Run(ctx, tenant)
+		st.FinishedAt = time.Now()
+		if err != nil {
+			st.Status, st.Error = ProvisionFailed, err.Error()
+			if serr := save(); serr != nil {
+				return state, serr
+			}
+			return state, &ProvisionError{TenantID: tenant.ID, Step: step.Name, Err: err}
+		}
+		st.Status = ProvisionDone
+		if err := save(); err != nil {
+			return state, err
+		}
+	}
+	return state, nil
+}
+
+// state returns the state of the steps of the pipeline for the tenant,
+// carrying over the saved progress of the steps by name.
+func (p *ProvisioningPipeline) state(tenantID string, saved *ProvisionState) *ProvisionState {
+	prev := make(map[string]ProvisionStepState)
+	if saved != nil {
+		for _, st := range saved.Steps {
+			prev[st.Name] = st
+		}
+	}
+	state := &ProvisionState{TenantID: tenantID, Steps: make([]ProvisionStepState, len(p.steps))}
+	for i, step := range p.steps {
+		st, ok := prev[step.Name]
+		if !ok {
+			st = ProvisionStepState{Name: step.Name, Status: ProvisionPending}
+		}
+		state.Steps[i] = st
+	}
+	return state
+}
+
+// withTenantDB opens the database of the tenant for the duration of fn.
+func withTenantDB(ctx context.Context, driver string, tenant *TenantConfig, fn func(db *sql.DB) error) error {
+	db, err := sql.Open(driver, tenant.GetDatabaseDSN())
+	if err != nil {
+		return fmt.Errorf("open tenant database: %w", err)
+	}
+	defer db.Close()
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("ping tenant database: %w", err)
+	}
+	return fn(db)
+}
+
+// CreateDatabaseStep creates the database of the tenant and its
+// schema_migrations table.
+func CreateDatabaseStep(driver string) ProvisionStep {
+	return ProvisionStep{Name: StepCreateDatabase, Run: func(ctx context.Context, tenant *TenantConfig) error {
+		return withTenantDB(ctx, driver, tenant, func(db *sql.DB) error {
+			_, err := db.ExecContext(ctx, `
+				CREATE TABLE IF NOT EXISTS schema_migrations (
+					version    TEXT PRIMARY KEY,
+					applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+				)
+			`)
+			if err != nil {
+				return fmt.Errorf("create schema_migrations: %w", err)
+			}
+			return nil
+		})
+	}}
+}
+
+// MigrateStep runs the migrations of migrate on the database of the tenant.
+func MigrateStep(driver string, migrate MigrationHook) ProvisionStep {
+	return ProvisionStep{Name: StepMigrate, Run: func(ctx context.Context, tenant *TenantConfig) error {
+		return withTenantDB(ctx, driver, tenant, func(db *sql.DB) error {
+			return migrate(ctx, db, tenant)
+		})
+	}}
+}
+
+// SeedAdminStep creates the first admin user of the tenant with seed.
+func SeedAdminStep(driver string, seed MigrationHook) ProvisionStep {
+	return ProvisionStep{Name: StepSeedAdmin, Run: func(ctx context.Context, tenant *TenantConfig) error {
+		return withTenantDB(ctx, driver, tenant, func(db *sql.DB) error {
+			return seed(ctx, db, tenant)
+		})
+	}}
+}
+
+// WelcomeEmailStep sends the message built by welcome for the tenant.
+func WelcomeEmailStep(m mailer.Mailer, welcome func(tenant *TenantConfig) mailer.Message) ProvisionStep {
+	return ProvisionStep{Name: StepWelcomeEmail, Run: func(_ context.Context, tenant *TenantConfig) error {
+		return m.Send(welcome(tenant))
+	}}
+}
@@ -0,0 +1,202 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LimitUsers is the limit of the users of a tenant, checked on
+// registration.
+const LimitUsers = "users"
+
+// Metadata keys of a tenant holding its plan: "plan" names the plan,
+// "limit.<name>" overrides one of its limits and "modules" replaces its
+// modules (comma-separated).
+const (
+	TenantMetaPlan    = "plan"
+	TenantMetaLimit   = "limit."
+	TenantMetaModules = "modules"
+)
+
+// TenantPlan is a subscription plan: the limits of the tenants on it and
+// the modules they can use.
+//
+//	engine.RegisterTenantPlans(
+//		&engine.TenantPlan{Name: "free", Limits: map[string]int{engine.LimitUsers: 3, "posts": 100}},
+//		&engine.TenantPlan{Name: "pro", Limits: map[string]int{engine.LimitUsers: 50}, Modules: []string{"reports"}},
+//	)
+//
+// Limits are keyed by LimitUsers or by the slug of a resource, for its
+// records. A missing or zero limit is unlimited, and a plan without modules
+// enables them all.
+type TenantPlan struct {
+	Name    string
+	Label   string
+	Limits  map[string]int
+	Modules []string
+}
+
+// Limit returns the limit name of the plan, 0 when unlimited.
+func (p *TenantPlan) Limit(name string) int {
+	if p == nil {
+		return 0
+	}
+	return p.Limits[name]
+}
+
+// HasModule reports whether the plan enables module.
+func (p *TenantPlan) HasModule(module string) bool {
+	return p == nil || p.Modules == nil || slices.Contains(p.Modules, module)
+}
+
+var (
+	tenantPlansMu sync.RWMutex
+	tenantPlans   = map[string]*TenantPlan{}
+)
+
+// RegisterTenantPlans registers plans, replacing those of the same name.
+func RegisterTenantPlans(plans ...*TenantPlan) {
+	tenantPlansMu.Lock()
+	defer tenantPlansMu.Unlock()
+	for _, p := range plans {
+		tenantPlans[p.Name] = p
+	}
+}
+
+// TenantPlans returns the registered plans by name.
+func TenantPlans() []*TenantPlan {
+	tenantPlansMu.RLock()
+	defer tenantPlansMu.RUnlock()
+	plans := slices.Collect(maps.Values(tenantPlans))
+	slices.SortFunc(plans, func(a, b *TenantPlan) int { return strings.Compare(a.Name, b.Name) })
+	return plans
+}
+
+// planOf returns the plan of a tenant with the overrides of its metadata,
+// or nil when it has none.
+func planOf(meta map[string]string) *TenantPlan {
+	name := meta[TenantMetaPlan]
+	tenantPlansMu.RLock()
+	base := tenantPlans[name]
+	tenantPlansMu.RUnlock()
+
+	plan := &TenantPlan{Name: name, Limits: map[string]int{}}
+	if base != nil {
+		plan.Label = base.Label
+		maps.Copy(plan.Limits, base.Limits)
+		plan.Modules = base.Modules
+	}
+	overridden := false
+	for key, v := range meta {
+		if limit, ok := strings.CutPrefix(key, TenantMetaLimit); ok {
+			plan.Limits[limit], _ = strconv.Atoi(v)
+			overridden = true
+		}
+	}
+	if v, ok := meta[TenantMetaModules]; ok {
+		plan.Modules = []string{}
+		for _, m := range strings.Split(v, ",") {
+			if m = strings.TrimSpace(m); m != "" {
+				plan.Modules = append(plan.Modules, m)
+			}
+		}
+		overridden = true
+	}
+	if base == nil && !overridden {
+		return nil
+	}
+	return plan
+}
+
+// Plan returns the plan of the tenant with the overrides of its metadata,
+// or nil when it has none.
+func (t *Tenant) Plan() *TenantPlan {
+	meta := make(map[string]string, len(t.Meta))
+	for k, v := range t.Meta {
+		if s, ok := v.(string); ok {
+			meta[k] = s
+		}
+	}
+	return planOf(meta)
+}
+
+// Plan returns the plan of the tenant with the overrides of its metadata,
+// or nil when it has none.
+func (tc *TenantConfig) Plan() *TenantPlan {
+	return planOf(tc.Meta)
+}
+
+// LimitError is returned by CheckLimit when a tenant reached a limit of its
+// plan.
+type LimitError struct {
+	Limit string
+	Max   int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("the limit of %d %s of your plan is reached", e.Max, strings.ReplaceAll(e.Limit, "-", " "))
+}
+
+// CheckLimit returns a *LimitError when the tenant of ctx cannot have one
+// more of limit, having current already. Requests without tenant or plan
+// are not limited.
+//
+//	if err := engine.CheckLimit(ctx, "projects", count); err != nil {
+//		return err
+//	}
+//
+// CRUDHandler checks the limit of the resource before creating a record
+// (see ResourceCountable), and AuthHandler LimitUsers on registration.
+func CheckLimit(ctx context.Context, limit string, current int) error {
+	t := TenantFromContext(ctx)
+	if t == nil {
+		return nil
+	}
+	max := t.Plan().Limit(limit)
+	if max > 0 && current >= max {
+		return &LimitError{Limit: limit, Max: max}
+	}
+	return nil
+}
+
+// ModuleEnabled reports whether the plan of the tenant of ctx enables
+// module. Requests without tenant or plan can use every module.
+func ModuleEnabled(ctx context.Context, module string) bool {
+	t := TenantFromContext(ctx)
+	return t == nil || t.Plan().HasModule(module)
+}
+
+// ResourceCountable is an optional interface for resources counting their
+// records without listing them, for the limits of the tenant plans.
+type ResourceCountable interface {
+	Count(ctx context.Context) (int, error)
+}
+
+// checkResourceLimit checks the limit of the records of res for the
+// tenant of ctx, counted with ResourceCountable or List.
+func checkResourceLimit(ctx context.Context, res Resource) error {
+	t := TenantFromContext(ctx)
+	if t == nil || t.Plan().Limit(res.Slug()) == 0 {
+		return nil
+	}
+	var count int
+	if c, ok := res.(ResourceCountable); ok {
+		n, err := c.Count(ctx)
+		if err != nil {
+			return err
+		}
+		count = n
+	} else {
+		items, err := res.List(ctx)
+		if err != nil {
+			return err
+		}
+		count = len(items)
+	}
+	return CheckLimit(ctx, res.Slug(), count)
+}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type countedResource struct {
+	*mockResource
+	count   int
+	created bool
+}
+
+func (c *countedResource) Count(ctx context.Context) (int, error) { return c.count, nil }
+
+func (c *countedResource) Create(ctx context.Context, r *http.Request) error {
+	c.created = true
+	return nil
+}
+
+func TestTenantPlan_limitsAndModules(t *testing.T) {
+	RegisterTenantPlans(&TenantPlan{
+		Name:    "plan-test-free",
+		Limits:  map[string]int{LimitUsers: 3, "projects": 10},
+		Modules: []string{"reports"},
+	})
+	tenant := (&TenantConfig{ID: "acme", Meta: map[string]string{
+		TenantMetaPlan:               "plan-test-free",
+		TenantMetaLimit + LimitUsers: "5",
+	}}).ToTenant()
+	ctx := WithTenant(context.Background(), tenant)
+
+	if err := CheckLimit(ctx, LimitUsers, 4); err != nil {
+		t.Errorf("expected the override to allow a 5th user, got %v", err)
+	}
+	var lerr *LimitError
+	if err := CheckLimit(ctx, "projects", 10); !errors.As(err, &lerr) || lerr.Max != 10 {
+		t.Errorf("expected the projects limit reached, got %v", err)
+	}
+	if err := CheckLimit(ctx, "invoices", 1000); err != nil {
+		t.Errorf("expected no limit on invoices, got %v", err)
+	}
+	if err := CheckLimit(context.Background(), "projects", 1000); err != nil {
+		t.Errorf("expected no limit without tenant, got %v", err)
+	}
+	if !ModuleEnabled(ctx, "reports") || ModuleEnabled(ctx, "billing") {
+		t.Error("expected only the reports module enabled")
+	}
+	if !ModuleEnabled(WithTenant(context.Background(), &Tenant{ID: "free"}), "billing") {
+		t.Error("expected every module enabled without plan")
+	}
+}
+
+func TestCRUDHandler_Store_limitReached(t *testing.T) {
+	RegisterTenantPlans(&TenantPlan{Name: "plan-test-small", Limits: map[string]int{"projects": 2}})
+	res := &countedResource{mockResource: newMockResource("projects"), count: 2}
+	tenant := &Tenant{ID: "acme", Meta: map[string]any{TenantMetaPlan: "plan-test-small"}}
+
+	req := httptest.NewRequest(http.MethodPost, "/projects", strings.NewReader(url.Values{"name": {"x"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req = req.WithContext(WithTenant(req.Context(), tenant))
+	rw := httptest.NewRecorder()
+	newHandler(res).ServeHTTP(rw, req)
+
+	if rw.Code != http.StatusUnprocessableEntity || res.created {
+		t.Errorf("expected the creation refused, got %d (created %v)", rw.Code, res.created)
+	}
+
+	res.count = 1
+	rw = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/projects", strings.NewReader("name=x"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	newHandler(res).ServeHTTP(rw, req.WithContext(WithTenant(req.Context(), tenant)))
+	if !res.created {
+		t.Errorf("expected the record created under the limit, got %d", rw.Code)
+	}
+}
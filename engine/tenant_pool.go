@@ -0,0 +1,267 @@
+package engine
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/metrics"
+)
+
+// TenantPoolLimits are the connection pool settings of a tenant database.
+type TenantPoolLimits struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// TenantPoolConfig configures the pools of the tenant databases (see
+// TenantPools).
+type TenantPoolConfig struct {
+	// MaxPools is the number of tenant databases kept open; opening one
+	// more closes the least recently used. Defaults to 100.
+	MaxPools int
+	// Limits are the pool settings of every tenant. Defaults to 10 open
+	// and 3 idle connections, the lifetimes of the TenantManagerConfig.
+	Limits TenantPoolLimits
+	// PerTenant returns the pool settings of a tenant, e.g. larger for a
+	// plan; zero fields keep Limits.
+	PerTenant func(tenant *TenantConfig) TenantPoolLimits
+	// HealthCheckInterval is the interval of the health checks started by
+	// TenantPools.Start. Defaults to one minute.
+	HealthCheckInterval time.Duration
+}
+
+// TenantPoolStats describes the pool of a tenant database.
+type TenantPoolStats struct {
+	TenantID string
+	LastUsed time.Time
+	sql.DBStats
+}
+
+// TenantPools shares one *sql.DB per tenant database between the requests,
+// keeping at most MaxPools open so that many tenants don't exhaust the
+// connections of the database server. Get it with TenantManager.Pools.
+type TenantPools struct {
+	cfg    TenantPoolConfig
+	driver string
+	store  func() TenantStore
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	pools map[string]*list.Element // tenant ID → *tenantPool
+	lru   *list.List               // most recently used first
+}
+
+type tenantPool struct {
+	id       string
+	db       *sql.DB
+	lastUsed time.Time
+}
+
+// newTenantPools creates the pools of the tenants of store.
+func newTenantPools(cfg TenantPoolConfig, driver string, store func() TenantStore, logger *slog.Logger) *TenantPools {
+	if cfg.MaxPools <= 0 {
+		cfg.MaxPools = 100
+	}
+	if cfg.Limits.MaxOpenConns == 0 {
+		cfg.Limits.MaxOpenConns = 10
+	}
+	if cfg.Limits.MaxIdleConns == 0 {
+		cfg.Limits.MaxIdleConns = 3
+	}
+	if cfg.HealthCheckInterval <= 0 {
+		cfg.HealthCheckInterval = time.Minute
+	}
+	return &TenantPools{
+		cfg:    cfg,
+		driver: driver,
+		store:  store,
+		logger: logger,
+		pools:  make(map[string]*list.Element),
+		lru:    list.New(),
+	}
+}
+
+// Get returns the database of the tenant, opening its pool on first use.
+// The pool is shared: do not close it, the pools close it on eviction,
+// suspension or deletion of the tenant.
+func (p *TenantPools) Get(ctx context.Context, nameOrId string) (*sql.DB, error) {
+	cfg, err := p.store().GetByNameOrId(ctx, nameOrId)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	if el, ok := p.pools[cfg.ID]; ok {
+		p.lru.MoveToFront(el)
+		pool := el.Value.(*tenantPool)
+		pool.lastUsed = time.Now()
+		p.mu.Unlock()
+		return pool.db, nil
+	}
+	p.mu.Unlock()
+
+	db, err := p.open(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Opened concurrently by another request
+	if el, ok := p.pools[cfg.ID]; ok {
+		p.lru.MoveToFront(el)
+		db.Close()
+		return el.Value.(*tenantPool).db, nil
+	}
+	p.pools[cfg.ID] = p.lru.PushFront(&tenantPool{id: cfg.ID, db: db, lastUsed: time.Now()})
+	metrics.TenantDBPoolEvents.WithLabelValues("opened").Inc()
+	for p.lru.Len() > p.cfg.MaxPools {
+		p.remove(p.lru.Back(), "evicted")
+	}
+	metrics.TenantDBPools.Set(float64(p.lru.Len()))
+	return db, nil
+}
+
+// open opens and pings the database of the tenant with its pool settings.
+func (p *TenantPools) open(ctx context.Context, cfg *TenantConfig) (*sql.DB, error) {
+	limits := p.cfg.Limits
+	if p.cfg.PerTenant != nil {
+		own := p.cfg.PerTenant(cfg)
+		if own.MaxOpenConns != 0 {
+			limits.MaxOpenConns = own.MaxOpenConns
+		}
+		if own.MaxIdleConns != 0 {
+			limits.MaxIdleConns = own.MaxIdleConns
+		}
+		if own.ConnMaxLifetime != 0 {
+			limits.ConnMaxLifetime = own.ConnMaxLifetime
+		}
+		if own.ConnMaxIdleTime != 0 {
+			limits.ConnMaxIdleTime = own.ConnMaxIdleTime
+		}
+	}
+
+	db, err := sql.Open(p.driver, cfg.GetDatabaseDSN())
+	if err != nil {
+		return nil, fmt.Errorf("open tenant db: %w", err)
+	}
+	db.SetMaxOpenConns(limits.MaxOpenConns)
+	db.SetMaxIdleConns(limits.MaxIdleConns)
+	db.SetConnMaxLifetime(limits.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(limits.ConnMaxIdleTime)
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ping tenant db: %w", err)
+	}
+	return db, nil
+}
+
+// remove closes the pool of el. The caller holds p.mu.
+func (p *TenantPools) remove(el *list.Element, event string) {
+	pool := p.lru.Remove(el).(*tenantPool)
+	delete(p.pools, pool.id)
+	if err := pool.db.Close(); err != nil {
+		p.logger.Warn("close tenant db", "id", pool.id, "error", err)
+	}
+	metrics.TenantDBPoolEvents.WithLabelValues(event).Inc()
+}
+
+// Close closes the pool of the tenant, if open.
+func (p *TenantPools) Close(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if el, ok := p.pools[id]; ok {
+		p.remove(el, "closed")
+		metrics.TenantDBPools.Set(float64(p.lru.Len()))
+	}
+}
+
+// CloseAll closes every pool, e.g. on shutdown.
+func (p *TenantPools) CloseAll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.lru.Len() > 0 {
+		p.remove(p.lru.Front(), "closed")
+	}
+	metrics.TenantDBPools.Set(0)
+}
+
+// Stats returns the statistics of the open pools, most recently used
+// first.
+func (p *TenantPools) Stats() []TenantPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := make([]TenantPoolStats, 0, p.lru.Len())
+	for el := p.lru.Front(); el != nil; el = el.Next() {
+		pool := el.Value.(*tenantPool)
+		stats = append(stats, TenantPoolStats{TenantID: pool.id, LastUsed: pool.lastUsed, DBStats: pool.db.Stats()})
+	}
+	return stats
+}
+
+// HealthCheck pings every open pool and closes those failing, so the next
+// Get opens them again. It returns the errors by tenant ID.
+func (p *TenantPools) HealthCheck(ctx context.Context) map[string]error {
+	p.mu.Lock()
+	pools := make([]*tenantPool, 0, p.lru.Len())
+	for el := p.lru.Front(); el != nil; el = el.Next() {
+		pools = append(pools, el.Value.(*tenantPool))
+	}
+	p.mu.Unlock()
+
+	failed := make(map[string]error)
+	failedDBs := make(map[string]*sql.DB)
+	var inUse, idle int
+	for _, pool := range pools {
+		if err := pool.db.PingContext(ctx); err != nil {
+			failed[pool.id] = err
+			failedDBs[pool.id] = pool.db
+			continue
+		}
+		s := pool.db.Stats()
+		inUse += s.InUse
+		idle += s.Idle
+	}
+	metrics.TenantDBConnections.WithLabelValues("in_use").Set(float64(inUse))
+	metrics.TenantDBConnections.WithLabelValues("idle").Set(float64(idle))
+
+	if len(failed) == 0 {
+		return failed
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id, err := range failed {
+		// Unless reopened meanwhile
+		if el, ok := p.pools[id]; ok && el.Value.(*tenantPool).db == failedDBs[id] {
+			p.logger.Warn("tenant db health check failed", "id", id, "error", err)
+			p.remove(el, "unhealthy")
+		}
+	}
+	metrics.TenantDBPools.Set(float64(p.lru.Len()))
+	return failed
+}
+
+// Start runs the health checks every HealthCheckInterval until ctx is
+// done, then closes every pool.
+func (p *TenantPools) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(p.cfg.HealthCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				p.CloseAll()
+				return
+			case <-ticker.C:
+				p.HealthCheck(ctx)
+			}
+		}
+	}()
+}
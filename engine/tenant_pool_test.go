@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestTenantPools_sharedAndEvicted(t *testing.T) {
+	dir := t.TempDir()
+	store := NewMemoryTenantStore([]*TenantConfig{
+		{ID: "acme", Status: TenantStatusActive, DatabaseDSN: filepath.Join(dir, "acme.db")},
+		{ID: "globex", Status: TenantStatusActive, DatabaseDSN: filepath.Join(dir, "globex.db")},
+	})
+	tm := NewTenantManagerWithStore(store, TenantManagerConfig{
+		Driver: "sqlite3",
+		Pool: TenantPoolConfig{
+			MaxPools: 1,
+			PerTenant: func(tc *TenantConfig) TenantPoolLimits {
+				if tc.ID == "globex" {
+					return TenantPoolLimits{MaxOpenConns: 2}
+				}
+				return TenantPoolLimits{}
+			},
+		},
+	})
+	ctx := context.Background()
+
+	acme, err := tm.TenantDB(ctx, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := tm.TenantDB(ctx, "acme"); again != acme {
+		t.Error("expected the pool shared")
+	}
+	if got := acme.Stats().MaxOpenConnections; got != 10 {
+		t.Errorf("expected the default limit of 10, got %d", got)
+	}
+
+	globex, err := tm.TenantDB(ctx, "globex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := globex.Stats().MaxOpenConnections; got != 2 {
+		t.Errorf("expected the tenant limit of 2, got %d", got)
+	}
+	if err := acme.PingContext(ctx); err == nil {
+		t.Error("expected the least recently used pool closed")
+	}
+	stats := tm.Pools().Stats()
+	if len(stats) != 1 || stats[0].TenantID != "globex" {
+		t.Errorf("expected only globex open, got %+v", stats)
+	}
+	if failed := tm.Pools().HealthCheck(ctx); len(failed) != 0 {
+		t.Errorf("expected healthy pools, got %v", failed)
+	}
+
+	if err := tm.SuspendTenant(ctx, "globex"); err != nil {
+		t.Fatal(err)
+	}
+	if len(tm.Pools().Stats()) != 0 {
+		t.Error("expected the pool closed on suspension")
+	}
+}
@@ -0,0 +1,358 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/actions"
+	"github.com/bozz33/sublimeadmin/form"
+	"github.com/bozz33/sublimeadmin/table"
+	"github.com/bozz33/sublimeadmin/ui/components"
+)
+
+// TenantResource is the built-in admin resource of the tenants of a
+// TenantManager (see TenantsResource).
+type TenantResource struct {
+	*BaseResource
+	manager *TenantManager
+
+	suspend *actions.Action
+	usage   []tenantUsageColumn
+	openURL func(t *TenantConfig) string
+}
+
+// tenantUsageColumn is a column showing a key of the tenant metadata.
+type tenantUsageColumn struct {
+	key   string
+	label string
+}
+
+// tenantIDPattern is the form of the IDs of the tenants created from the
+// resource: they end up in subdomains, paths and database file names.
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// TenantsResource creates the resource listing, creating, suspending and
+// deleting the tenants of tm, to be served by a central panel of the
+// operators, without tenant resolver:
+//
+//	central := engine.NewPanel("central").WithPath("/central").
+//		AddResources(engine.TenantsResource(tm).
+//			WithUsageColumn("users", "Users").
+//			WithUsageColumn("storage_mb", "Storage (MB)").
+//			WithTenantPanel("/admin"))
+//
+// Creating a tenant runs the provisioning of the manager; the form is shown
+// again with the error when a step fails, and submitting it again resumes
+// the provisioning. Deleting a tenant asks for its ID. Suspended tenants
+// are listed when the TenantStore implements TenantDirectory.
+func TenantsResource(tm *TenantManager) *TenantResource {
+	r := &TenantResource{
+		BaseResource: NewBaseResource("tenants", "Tenant", "Tenants"),
+		manager:      tm,
+	}
+	r.SetIcon("apartment")
+	r.SetDeleteConfirmation(func(item any) string { return item.(*TenantConfig).ID })
+	r.suspend = actions.New("suspend").
+		SetLabel("Suspend").
+		SetIcon("pause_circle").
+		SetColor(actions.ColorWarning).
+		WithForm(form.Schema()).
+		WithSubmitLabel("Suspend").
+		WithSuccessMessage("Tenant suspended.").
+		Authorize(func(ctx context.Context, item any) bool {
+			t, ok := item.(*TenantConfig)
+			return ok && tenantActive(t)
+		}).
+		Handle(func(ctx context.Context, item any, _ url.Values) error {
+			return tm.SuspendTenant(ctx, item.(*TenantConfig).ID)
+		})
+	r.suspend.ModalTitle = "Suspend this tenant?"
+	r.suspend.ModalDescription = "Its users lose access to its panel. Its data is kept."
+	r.SetRowActions(r.suspend)
+	return r
+}
+
+// WithUsageColumn adds a column showing the metadata key of the tenants,
+// e.g. a user count or a storage size kept up to date by the application.
+func (r *TenantResource) WithUsageColumn(key, label string) *TenantResource {
+	r.usage = append(r.usage, tenantUsageColumn{key: key, label: label})
+	return r
+}
+
+// WithTenantPanel adds the "Open panel" action, linking to the panel at
+// path of each active tenant: on its domain when it has one, else with
+// ?tenant={id}. Use WithOpenURL for other resolvers.
+func (r *TenantResource) WithTenantPanel(path string) *TenantResource {
+	path = strings.TrimRight(path, "/") + "/"
+	return r.WithOpenURL(func(t *TenantConfig) string {
+		if t.Domain != "" {
+			return "//" + t.Domain + path
+		}
+		return path + "?" + url.Values{"tenant": {t.ID}}.Encode()
+	})
+}
+
+// WithOpenURL adds the "Open panel" action, linking to the URL returned by
+// fn for each active tenant.
+func (r *TenantResource) WithOpenURL(fn func(t *TenantConfig) string) *TenantResource {
+	r.openURL = fn
+	return r
+}
+
+func (r *TenantResource) CanUpdate(ctx context.Context) bool { return false }
+
+// List returns the tenants, suspended ones included when the store can
+// list them.
+func (r *TenantResource) List(ctx context.Context) ([]any, error) {
+	tenants, err := r.manager.AllTenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]any, len(tenants))
+	for i, t := range tenants {
+		items[i] = t
+	}
+	return items, nil
+}
+
+func (r *TenantResource) Get(ctx context.Context, id string) (any, error) {
+	tenants, err := r.manager.AllTenants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tenants {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+// Create provisions a tenant from the submitted form.
+func (r *TenantResource) Create(ctx context.Context, req *http.Request) error {
+	if err := req.ParseForm(); err != nil {
+		return err
+	}
+	cfg := &TenantConfig{
+		ID:        strings.ToLower(strings.TrimSpace(req.FormValue("id"))),
+		Name:      strings.TrimSpace(req.FormValue("name")),
+		Domain:    strings.ToLower(strings.TrimSpace(req.FormValue("domain"))),
+		Subdomain: strings.ToLower(strings.TrimSpace(req.FormValue("subdomain"))),
+	}
+	errs := form.FormErrors{}
+	if !tenantIDPattern.MatchString(cfg.ID) {
+		errs["id"] = "Use lowercase letters, digits and dashes."
+	} else if existing, _ := r.Get(ctx, cfg.ID); existing != nil {
+		errs["id"] = "This ID is already taken."
+	}
+	if cfg.Name == "" {
+		errs["name"] = "The name is required."
+	}
+	if plan := req.FormValue(TenantMetaPlan); plan != "" {
+		if !slices.ContainsFunc(TenantPlans(), func(p *TenantPlan) bool { return p.Name == plan }) {
+			errs[TenantMetaPlan] = "Unknown plan."
+		}
+		cfg.Meta = map[string]string{TenantMetaPlan: plan}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	if cfg.Subdomain == "" {
+		cfg.Subdomain = cfg.ID
+	}
+	return r.manager.CreateTenant(ctx, cfg)
+}
+
+func (r *TenantResource) Delete(ctx context.Context, id string) error {
+	return r.manager.DeleteTenant(ctx, id)
+}
+
+func (r *TenantResource) BulkDelete(ctx context.Context, ids []string) error {
+	for _, id := range ids {
+		if err := r.Delete(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Table lists the tenants, searched by ID, name and domain with the
+// ListQuery of the context.
+func (r *TenantResource) Table(ctx context.Context) templ.Component {
+	tenants, err := r.manager.AllTenants(ctx)
+	if err != nil {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, werr := io.WriteString(w, "<p class=\"text-red-500\">Error loading table: "+templ.EscapeString(err.Error())+"</p>")
+			return werr
+		})
+	}
+	if lq := GetListQuery(ctx); lq != nil && lq.Search != "" {
+		query := strings.ToLower(strings.TrimSpace(lq.Search))
+		tenants = slices.DeleteFunc(slices.Clone(tenants), func(t *TenantConfig) bool {
+			return !strings.Contains(strings.ToLower(t.ID+" "+t.Name+" "+t.Domain), query)
+		})
+	}
+
+	data := make([]any, len(tenants))
+	for i, t := range tenants {
+		data[i] = t
+	}
+	base := PanelURL(ctx, "/"+r.Slug())
+	t := table.New(data).WithColumns(r.Columns()...)
+	t.BaseURL = base
+	t.Pagination = false
+	t.Actions = r.tableActions(ctx, base)
+	return components.Table(ctx, t, data)
+}
+
+// tableActions returns the actions of the rows allowed to the user of ctx.
+func (r *TenantResource) tableActions(ctx context.Context, base string) []*actions.Action {
+	var list []*actions.Action
+	if r.openURL != nil {
+		list = append(list, actions.New("open").
+			SetLabel("Open panel").
+			SetIcon("open_in_new").
+			SetUrl(func(item any) string {
+				if t := item.(*TenantConfig); tenantActive(t) {
+					return r.openURL(t)
+				}
+				return "#"
+			}))
+	}
+	suspend := r.suspend
+	list = append(list, actions.New(suspend.Name).
+		SetLabel(suspend.Label).
+		SetIcon(suspend.Icon).
+		SetColor(suspend.Color).
+		SetUrl(func(item any) string {
+			if !suspend.IsAuthorized(ctx, item) {
+				return "#"
+			}
+			return base + "/" + getItemID(item) + "/actions/" + suspend.Name
+		}))
+	if r.CanDelete(ctx) {
+		list = append(list, actions.DeleteAction(base).RequiresPhraseFrom(func(item any) string {
+			return item.(*TenantConfig).ID
+		}))
+	}
+	return list
+}
+
+// Columns returns the columns of the table: ID, name, domain, status, the
+// usage columns and the creation date.
+func (r *TenantResource) Columns() []table.Column {
+	cols := []table.Column{
+		table.Text("ID").WithLabel("ID").Copyable(),
+		table.Text("Name").WithLabel("Name"),
+		table.Text("Domain").WithLabel("Domain").Using(func(item any) string {
+			t := item.(*TenantConfig)
+			if v := t.DomainVerification(); v != nil && v.Status != DomainStatusVerified {
+				return v.Domain + " (" + v.Status + ")"
+			}
+			if t.Domain != "" {
+				return t.Domain
+			}
+			return t.Subdomain
+		}),
+		table.Badge("Status").WithLabel("Status").
+			Using(func(item any) string { return tenantStatus(item.(*TenantConfig)) }).
+			Colors(map[string]string{TenantStatusActive: "success", TenantStatusSuspended: "warning"}),
+	}
+	if len(TenantPlans()) > 0 {
+		cols = append(cols, table.Text("Plan").WithLabel("Plan").Using(func(item any) string {
+			p := item.(*TenantConfig).Plan()
+			switch {
+			case p == nil:
+				return ""
+			case p.Label != "":
+				return p.Label
+			}
+			return p.Name
+		}))
+	}
+	for _, u := range r.usage {
+		key := u.key
+		cols = append(cols, table.Text("meta."+key).WithLabel(u.label).
+			Using(func(item any) string { return tenantUsage(item.(*TenantConfig), key) }).
+			WithColorFunc(func(_ string, record any) string {
+				t := record.(*TenantConfig)
+				current, _ := strconv.Atoi(t.Meta[key])
+				if max := t.Plan().Limit(key); max > 0 && current >= max {
+					return "danger"
+				}
+				return ""
+			}))
+	}
+	return append(cols, table.DateCol("CreatedAt").WithLabel("Created"))
+}
+
+// tenantUsage returns the usage key of the tenant, with the limit of its
+// plan: "42 / 50".
+func tenantUsage(t *TenantConfig, key string) string {
+	current := t.Meta[key]
+	if max := t.Plan().Limit(key); max > 0 {
+		if current == "" {
+			current = "0"
+		}
+		return current + " / " + strconv.Itoa(max)
+	}
+	return current
+}
+
+// Form renders the creation form of a tenant.
+func (r *TenantResource) Form(ctx context.Context, item any) templ.Component {
+	fields := []form.Component{
+		form.Text("id").Label("ID").Required().
+			HelperText("Lowercase letters, digits and dashes. Used in URLs and database names."),
+		form.Text("name").Label("Name").Required(),
+		form.Text("subdomain").Label("Subdomain").HelperText("Defaults to the ID."),
+		form.Text("domain").Label("Custom domain").WithPlaceholder("admin.example.com"),
+	}
+	if plans := TenantPlans(); len(plans) > 0 {
+		options := make([]form.SelectOption, len(plans))
+		for i, p := range plans {
+			label := p.Label
+			if label == "" {
+				label = humanize(p.Name)
+			}
+			options[i] = form.SelectOption{Value: p.Name, Label: label}
+		}
+		fields = append(fields, form.Select(TenantMetaPlan).Label("Plan").OptionsOrdered(options))
+	}
+	return components.Form(fields, PanelURL(ctx, "/"+r.Slug()), "POST")
+}
+
+// TenantDirectory is implemented by the TenantStores that list every
+// tenant not deleted, suspended ones included, for the operators (see
+// TenantsResource).
+type TenantDirectory interface {
+	ListAll(ctx context.Context) ([]*TenantConfig, error)
+}
+
+// AllTenants returns the tenants not deleted when the store implements
+// TenantDirectory, else the tenants of the store.
+func (tm *TenantManager) AllTenants(ctx context.Context) ([]*TenantConfig, error) {
+	if d, ok := tm.store.(TenantDirectory); ok {
+		return d.ListAll(ctx)
+	}
+	return tm.store.List(ctx)
+}
+
+// ListAll implements TenantDirectory.
+func (m *MemoryTenantStore) ListAll(ctx context.Context) ([]*TenantConfig, error) {
+	tenants, err := m.List(ctx)
+	slices.SortFunc(tenants, func(a, b *TenantConfig) int { return strings.Compare(a.ID, b.ID) })
+	return tenants, err
+}
+
+// ListAll implements TenantDirectory.
+func (s *SQLTenantStore) ListAll(ctx context.Context) ([]*TenantConfig, error) {
+	return s.list(ctx, `status <> 'deleted'`)
+}
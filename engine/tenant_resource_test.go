@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/bozz33/sublimeadmin/form"
+)
+
+func TestTenantsResource_CreateSuspendDelete(t *testing.T) {
+	store := NewMemoryTenantStore(nil)
+	tm := NewTenantManagerWithStore(store, TenantManagerConfig{DatabaseStyle: DatabaseStyleSingle})
+	res := TenantsResource(tm).WithUsageColumn("users", "Users").WithTenantPanel("/admin")
+	var _ Resource = res
+	ctx := context.Background()
+
+	post := func(values url.Values) error {
+		req := httptest.NewRequest("POST", "/tenants", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return res.Create(ctx, req)
+	}
+	var errs form.FormErrors
+	if err := post(url.Values{"id": {"Not valid!"}}); !errors.As(err, &errs) || errs["id"] == "" || errs["name"] == "" {
+		t.Fatalf("expected field errors, got %v", err)
+	}
+	if err := post(url.Values{"id": {"acme"}, "name": {"Acme"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := post(url.Values{"id": {"acme"}, "name": {"Acme again"}}); !errors.As(err, &errs) || errs["id"] == "" {
+		t.Fatalf("expected the ID taken, got %v", err)
+	}
+
+	item, _ := res.Get(ctx, "acme")
+	acme, ok := item.(*TenantConfig)
+	if !ok || acme.Subdomain != "acme" || acme.Status != TenantStatusActive {
+		t.Fatalf("unexpected tenant %+v", item)
+	}
+	acme.Meta["users"] = "42"
+
+	var buf bytes.Buffer
+	if err := res.Table(ctx).Render(ctx, &buf); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	for _, want := range []string{"Acme", "42", "/admin/?tenant=acme", "/tenants/acme/actions/suspend"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected %q in the table", want)
+		}
+	}
+
+	suspend := res.RowActions()[0]
+	if err := suspend.Submit(ctx, acme, url.Values{}); err != nil {
+		t.Fatal(err)
+	}
+	if acme.Status != TenantStatusSuspended || suspend.IsAuthorized(ctx, acme) {
+		t.Errorf("expected the tenant suspended once, got %q", acme.Status)
+	}
+	if items, _ := res.List(ctx); len(items) != 1 {
+		t.Errorf("expected the suspended tenant listed, got %d", len(items))
+	}
+
+	if err := res.Delete(ctx, "acme"); err != nil {
+		t.Fatal(err)
+	}
+	if items, _ := res.List(ctx); len(items) != 0 {
+		t.Errorf("expected no tenant after delete, got %d", len(items))
+	}
+}
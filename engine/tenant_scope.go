@@ -0,0 +1,240 @@
+package engine
+
+import (
+	"context"
+	stdsql "database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/bozz33/sublimeadmin/apperrors"
+)
+
+// ErrNoTenant is returned by a TenantScoper without a tenant in the
+// context: tenant scoped resources fail closed instead of reaching the rows
+// of every tenant.
+var ErrNoTenant = errors.New("no tenant in context")
+
+// TenantScoper isolates the rows of the tenants sharing one database
+// (DatabaseStyleSingle) by their tenant column. Set on a panel, it scopes
+// its TenantAware resources:
+//
+//	panel.WithTenantScoper(engine.NewTenantScoper("tenant_id"))
+//
+// Their lists, exports and global search results are restricted to the
+// tenant of the request like a DefaultScope (ListPaginated receives the
+// tenant filter first in params.Filters, ListQuery in q.Scope), the records
+// of other tenants answer 404 and the tenant field of the forms saved is
+// set to the tenant, whatever was posted. Without a tenant in the context,
+// every request of these resources is refused.
+//
+// Queries run by the resources themselves (Get, Update, Delete) are scoped
+// with Selector or Filter.
+type TenantScoper struct {
+	field string
+	value func(t *Tenant) any
+}
+
+// NewTenantScoper creates a scoper on the tenant field and column field
+// (default "tenant_id"), holding the ID of the tenant.
+func NewTenantScoper(field string) *TenantScoper {
+	if field == "" {
+		field = "tenant_id"
+	}
+	return &TenantScoper{field: field, value: func(t *Tenant) any { return t.ID }}
+}
+
+// WithValue sets the value stored in the tenant column, e.g. a numeric ID
+// kept in Tenant.Meta. Defaults to Tenant.ID.
+func (s *TenantScoper) WithValue(fn func(t *Tenant) any) *TenantScoper {
+	s.value = fn
+	return s
+}
+
+// Field returns the tenant field and column.
+func (s *TenantScoper) Field() string { return s.field }
+
+// Value returns the tenant value of the context, or ErrNoTenant.
+func (s *TenantScoper) Value(ctx context.Context) (any, error) {
+	t := TenantFromContext(ctx)
+	if t == nil {
+		return nil, ErrNoTenant
+	}
+	return s.value(t), nil
+}
+
+// Filter returns the filter tenant_id = <tenant of the context>.
+func (s *TenantScoper) Filter(ctx context.Context) (*FilterExpr, error) {
+	v, err := s.Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &FilterExpr{Field: s.field, Operator: FilterEq, Value: v}, nil
+}
+
+// Selector returns the predicate of an Ent query restricting it to the
+// tenant of the context, the column qualified by the table of the query:
+//
+//	p, err := scoper.Selector(ctx)
+//	post, err := client.Post.Query().Where(post.ID(id), predicate.Post(p)).Only(ctx)
+func (s *TenantScoper) Selector(ctx context.Context) (func(*sql.Selector), error) {
+	v, err := s.Value(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(sel *sql.Selector) {
+		sel.Where(sql.EQ(sel.C(s.field), v))
+	}, nil
+}
+
+// Stamp sets the tenant field of a new record, a struct pointer or a
+// map[string]any, to the tenant of the context. A struct field is found
+// like by MatchFilters.
+func (s *TenantScoper) Stamp(ctx context.Context, record any) error {
+	v, err := s.Value(ctx)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(record)
+	if m, ok := record.(map[string]any); ok {
+		m[s.field] = v
+		return nil
+	}
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("tenant: cannot stamp a %T", record)
+	}
+	rv = rv.Elem()
+	f := rv.FieldByNameFunc(func(name string) bool {
+		return name == s.field || strings.EqualFold(name, strings.ReplaceAll(s.field, "_", ""))
+	})
+	if !f.IsValid() {
+		f = jsonField(rv, s.field)
+	}
+	value := reflect.ValueOf(v)
+	if !f.IsValid() || !f.CanSet() || !value.Type().ConvertibleTo(f.Type()) {
+		return fmt.Errorf("tenant: %T has no %s field of type %T", record, s.field, v)
+	}
+	f.Set(value.Convert(f.Type()))
+	return nil
+}
+
+// WithTenantScoper isolates the TenantAware resources of the panel by
+// tenant (see TenantScoper).
+func (p *Panel) WithTenantScoper(s *TenantScoper) *Panel {
+	p.tenantScoper = s
+	return p
+}
+
+// resourceTenantScoper returns the TenantScoper of the panel of the context
+// when res is TenantAware, else nil.
+func resourceTenantScoper(ctx context.Context, res any) *TenantScoper {
+	if _, ok := res.(TenantAware); !ok {
+		return nil
+	}
+	if p := GetPanelFromContext(ctx); p != nil {
+		return p.tenantScoper
+	}
+	return nil
+}
+
+// guardTenant enforces the TenantScoper of res on a CRUD request: the
+// tenant must be known, the records of the path and of ids[] must belong
+// to it, and the tenant field of the forms posted is set to it. It answers
+// the request and returns false otherwise.
+func guardTenant(w http.ResponseWriter, r *http.Request, res Resource, parts []string) bool {
+	s := resourceTenantScoper(r.Context(), res)
+	if s == nil {
+		return true
+	}
+	ctx := r.Context()
+	filter, err := s.Filter(ctx)
+	if err != nil {
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
+		return false
+	}
+
+	var ids []string
+	if id := recordID(parts); id != "" {
+		ids = append(ids, id)
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		// Parse the whole body, multipart included, before stamping: the
+		// handlers parsing it again keep the stamped values.
+		if err := parseRequestForm(r); err != nil {
+			apperrors.Handle(w, r, apperrors.BadRequest("Bad request"))
+			return false
+		}
+		ids = append(ids, r.Form["ids[]"]...)
+		stampTenantForm(r, s.field, fmt.Sprint(filter.Value), len(parts) == 0 || parts[0] == "" || parts[0] == "create")
+	}
+	for _, id := range ids {
+		item, err := res.Get(ctx, id)
+		if err != nil {
+			if isNotFoundError(err) {
+				continue
+			}
+			apperrors.Handle(w, r, apperrors.Internal(err, "Could not check the tenant of the record"))
+			return false
+		}
+		if item != nil && !MatchFilters(item, []*FilterExpr{filter}) {
+			http.NotFound(w, r)
+			return false
+		}
+	}
+	return true
+}
+
+// routeWords are the first path segments of the CRUD routes that are not
+// record IDs (see CRUDHandler.route).
+var routeWords = map[string]bool{
+	"create":         true,
+	"validate-field": true,
+	"actions":        true,
+	"bulk":           true,
+	"bulk-delete":    true,
+	"tree":           true,
+}
+
+// recordID returns the ID of the record of a CRUD path split in parts, or
+// "" when the path names no record, like /create or /bulk/{action}.
+func recordID(parts []string) string {
+	if len(parts) == 0 || parts[0] == "" || routeWords[parts[0]] {
+		return ""
+	}
+	return parts[0]
+}
+
+// parseRequestForm parses the form of r, multipart bodies included.
+func parseRequestForm(r *http.Request) error {
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return r.ParseMultipartForm(32 << 20)
+	}
+	return r.ParseForm()
+}
+
+// isNotFoundError reports whether err of Resource.Get means there is no
+// such record: a 404 AppError, sql.ErrNoRows or an error saying "not
+// found", like those of Ent.
+func isNotFoundError(err error) bool {
+	return apperrors.IsNotFound(err) || errors.Is(err, stdsql.ErrNoRows) ||
+		strings.Contains(strings.ToLower(err.Error()), "not found")
+}
+
+// stampTenantForm sets the tenant field of the form of r to value: always
+// on creation, else when it is posted.
+func stampTenantForm(r *http.Request, field, value string, create bool) {
+	if create || r.Form.Has(field) {
+		r.Form.Set(field, value)
+	}
+	if r.PostForm != nil && (create || r.PostForm.Has(field)) {
+		r.PostForm.Set(field, value)
+	}
+	if r.MultipartForm != nil {
+		if _, ok := r.MultipartForm.Value[field]; create || ok {
+			r.MultipartForm.Value[field] = []string{value}
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"entgo.io/ent/dialect/sql"
+	"github.com/a-h/templ"
+)
+
+type tenantRecord struct {
+	ID       string
+	TenantID string
+	Title    string
+}
+
+// tenantResource is a TenantAware resource of two tenants.
+type tenantResource struct {
+	*mockResource
+	records map[string]*tenantRecord
+	scope   []*FilterExpr
+	created url.Values
+}
+
+func (t *tenantResource) SetTenant(*Tenant) {}
+
+func (t *tenantResource) Get(ctx context.Context, id string) (any, error) {
+	if rec, ok := t.records[id]; ok {
+		return rec, nil
+	}
+	if id == "broken" {
+		return nil, errors.New("connection refused")
+	}
+	return nil, errors.New("not found")
+}
+
+func (t *tenantResource) Create(ctx context.Context, r *http.Request) error {
+	_ = r.ParseMultipartForm(1 << 20) // like a resource with uploads
+	t.created = r.PostForm
+	return nil
+}
+
+func (t *tenantResource) Table(ctx context.Context) templ.Component {
+	t.scope = GetListQuery(ctx).Scope
+	return emptyComponent()
+}
+
+// serveTenant serves the request in the panel with scoper, for tenant
+// (none when empty).
+func serveTenant(h http.Handler, scoper *TenantScoper, tenant, method, path string, body url.Values) *httptest.ResponseRecorder {
+	p := NewPanel("admin").WithTenantScoper(scoper)
+	return serveWith(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), ContextKeyPanel, p)
+		if tenant != "" {
+			ctx = WithTenant(ctx, &Tenant{ID: tenant})
+		}
+		h.ServeHTTP(w, r.WithContext(ctx))
+	}), method, path, body)
+}
+
+func TestTenantScoper_CRUDHandler(t *testing.T) {
+	res := &tenantResource{mockResource: newMockResource("posts"), records: map[string]*tenantRecord{
+		"1": {ID: "1", TenantID: "acme"},
+		"2": {ID: "2", TenantID: "globex"},
+	}}
+	h := NewCRUDHandler(res)
+	scoper := NewTenantScoper("")
+
+	if rec := serveTenant(h, scoper, "", http.MethodGet, "/posts", nil); rec.Code != http.StatusForbidden {
+		t.Errorf("expected 403 without a tenant, got %d", rec.Code)
+	}
+
+	serveTenant(h, scoper, "acme", http.MethodGet, "/posts", nil)
+	if len(res.scope) != 1 || res.scope[0].Field != "tenant_id" || res.scope[0].Value != "acme" {
+		t.Errorf("expected the list scoped by tenant, got %+v", res.scope)
+	}
+
+	if rec := serveTenant(h, scoper, "acme", http.MethodGet, "/posts/2/edit", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for the record of another tenant, got %d", rec.Code)
+	}
+	if rec := serveTenant(h, scoper, "acme", http.MethodGet, "/posts/1/edit", nil); rec.Code != http.StatusOK {
+		t.Errorf("expected the record of the tenant, got %d", rec.Code)
+	}
+	form := url.Values{"ids[]": {"1", "2"}}
+	if rec := serveTenant(h, scoper, "acme", http.MethodPost, "/posts/bulk-delete", form); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 when deleting the record of another tenant, got %d", rec.Code)
+	}
+
+	serveTenant(h, scoper, "acme", http.MethodPost, "/posts", url.Values{"title": {"Hi"}, "tenant_id": {"globex"}})
+	if got := res.created.Get("tenant_id"); got != "acme" {
+		t.Errorf("expected the record created for the tenant, got %q", got)
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	_ = mw.WriteField("title", "Hi")
+	_ = mw.WriteField("tenant_id", "globex")
+	_ = mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/posts", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	ctx := context.WithValue(req.Context(), ContextKeyPanel, NewPanel("admin").WithTenantScoper(scoper))
+	h.ServeHTTP(httptest.NewRecorder(), req.WithContext(WithTenant(ctx, &Tenant{ID: "acme"})))
+	if got := res.created["tenant_id"]; len(got) != 1 || got[0] != "acme" {
+		t.Errorf("expected the multipart record created for the tenant, got %q", got)
+	}
+
+	if rec := serveTenant(h, scoper, "acme", http.MethodPost, "/posts/broken", url.Values{"title": {"Hi"}}); rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 when the record cannot be checked, got %d", rec.Code)
+	}
+}
+
+// intTenantResource has integer IDs, its Get failing on other segments like
+// the generated resources.
+type intTenantResource struct {
+	*tenantResource
+}
+
+func (t *intTenantResource) Get(ctx context.Context, id string) (any, error) {
+	if _, err := strconv.Atoi(id); err != nil {
+		return nil, err
+	}
+	return t.tenantResource.Get(ctx, id)
+}
+
+func TestTenantScoper_CRUDHandler_routeWords(t *testing.T) {
+	res := &intTenantResource{&tenantResource{mockResource: newMockResource("posts"), records: map[string]*tenantRecord{
+		"1": {ID: "1", TenantID: "acme"},
+		"2": {ID: "2", TenantID: "globex"},
+	}}}
+	h := NewCRUDHandler(res)
+	scoper := NewTenantScoper("")
+
+	for _, tc := range []struct{ method, path string }{
+		{http.MethodGet, "/posts/create"},
+		{http.MethodPost, "/posts/create"},
+		{http.MethodPost, "/posts/bulk-delete"},
+		{http.MethodPost, "/posts/bulk/publish"},
+		{http.MethodGet, "/posts/actions/import"},
+		{http.MethodPost, "/posts/actions/import"},
+	} {
+		if rec := serveTenant(h, scoper, "acme", tc.method, tc.path, url.Values{"title": {"Hi"}}); rec.Code == http.StatusInternalServerError {
+			t.Errorf("%s %s: got 500, the route word was checked as a record", tc.method, tc.path)
+		}
+	}
+	if rec := serveTenant(h, scoper, "acme", http.MethodGet, "/posts/2/edit", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for the record of another tenant, got %d", rec.Code)
+	}
+}
+
+func TestTenantScoper_Stamp(t *testing.T) {
+	ctx := WithTenant(context.Background(), &Tenant{ID: "acme", Meta: map[string]any{"id": 7}})
+
+	rec := &tenantRecord{}
+	if err := NewTenantScoper("").Stamp(ctx, rec); err != nil || rec.TenantID != "acme" {
+		t.Errorf("expected the tenant stamped, got %q (%v)", rec.TenantID, err)
+	}
+	m := map[string]any{}
+	scoper := NewTenantScoper("team").WithValue(func(t *Tenant) any { return t.Meta["id"] })
+	if err := scoper.Stamp(ctx, m); err != nil || m["team"] != 7 {
+		t.Errorf("expected the tenant value stamped, got %v (%v)", m, err)
+	}
+	if err := NewTenantScoper("").Stamp(context.Background(), rec); !errors.Is(err, ErrNoTenant) {
+		t.Errorf("expected ErrNoTenant, got %v", err)
+	}
+}
+
+func TestTenantScoper_Selector(t *testing.T) {
+	scoper := NewTenantScoper("")
+	if _, err := scoper.Selector(context.Background()); !errors.Is(err, ErrNoTenant) {
+		t.Fatalf("expected ErrNoTenant, got %v", err)
+	}
+	ctx := WithTenant(context.Background(), &Tenant{ID: "acme"})
+	p, err := scoper.Selector(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sel := sql.Select("*").From(sql.Table("posts"))
+	p(sel)
+	query, args := sel.Query()
+	if !strings.Contains(query, "`posts`.`tenant_id` = ?") || len(args) != 1 || args[0] != "acme" {
+		t.Errorf("unexpected query %s %v", query, args)
+	}
+
+	filter, _ := scoper.Filter(ctx)
+	where, _, err := NewFilterCompiler("title").WithTenant(scoper).SQL([]*FilterExpr{filter})
+	if err != nil || where != "`tenant_id` = ?" {
+		t.Errorf("expected the tenant filter to compile, got %q (%v)", where, err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"sync"
 	"time"
 )
@@ -130,8 +131,13 @@ func (m *MemoryTenantStore) Create(_ context.Context, cfg *TenantConfig) error {
 	return nil
 }
 
-func (m *MemoryTenantStore) Update(ctx context.Context, cfg *TenantConfig) error {
-	return m.Create(ctx, cfg)
+// Update replaces the tenant, dropping its former domain and subdomain.
+func (m *MemoryTenantStore) Update(_ context.Context, cfg *TenantConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	maps.DeleteFunc(m.tenants, func(_ string, t *TenantConfig) bool { return t.ID == cfg.ID })
+	m.index(cfg)
+	return nil
 }
 
 func (m *MemoryTenantStore) Delete(_ context.Context, id string) error {
@@ -183,9 +189,14 @@ func (s *SQLTenantStore) GetByNameOrId(ctx context.Context, nameOrId string) (*T
 }
 
 func (s *SQLTenantStore) List(ctx context.Context) ([]*TenantConfig, error) {
+	return s.list(ctx, `status = 'active'`)
+}
+
+// list returns the tenants matching where, oldest first.
+func (s *SQLTenantStore) list(ctx context.Context, where string) ([]*TenantConfig, error) {
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT id, name, domain, subdomain, database_dsn, status, meta, created_at, updated_at
-		FROM tenants WHERE status = 'active' ORDER BY created_at
+		FROM tenants WHERE `+where+` ORDER BY created_at
 	`)
 	if err != nil {
 		return nil, fmt.Errorf("list tenants: %w", err)
@@ -212,8 +223,8 @@ func (s *SQLTenantStore) Create(ctx context.Context, cfg *TenantConfig) error {
 	metaJSON, _ := json.Marshal(cfg.Meta)
 	_, err := s.db.ExecContext(ctx, `
 		INSERT INTO tenants (id, name, domain, subdomain, database_dsn, status, meta)
-		VALUES (?, ?, ?, ?, ?, 'active', ?)
-	`, cfg.ID, cfg.Name, cfg.Domain, cfg.Subdomain, cfg.DatabaseDSN, string(metaJSON))
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, cfg.ID, cfg.Name, cfg.Domain, cfg.Subdomain, cfg.DatabaseDSN, tenantStatus(cfg), string(metaJSON))
 	if err != nil {
 		return fmt.Errorf("insert tenant: %w", err)
 	}
@@ -223,9 +234,9 @@ func (s *SQLTenantStore) Create(ctx context.Context, cfg *TenantConfig) error {
 func (s *SQLTenantStore) Update(ctx context.Context, cfg *TenantConfig) error {
 	metaJSON, _ := json.Marshal(cfg.Meta)
 	_, err := s.db.ExecContext(ctx, `
-		UPDATE tenants SET name=?, domain=?, subdomain=?, database_dsn=?, meta=?, updated_at=CURRENT_TIMESTAMP
+		UPDATE tenants SET name=?, domain=?, subdomain=?, database_dsn=?, status=COALESCE(NULLIF(?, ''), status), meta=?, updated_at=CURRENT_TIMESTAMP
 		WHERE id=?
-	`, cfg.Name, cfg.Domain, cfg.Subdomain, cfg.DatabaseDSN, string(metaJSON), cfg.ID)
+	`, cfg.Name, cfg.Domain, cfg.Subdomain, cfg.DatabaseDSN, cfg.Status, string(metaJSON), cfg.ID)
 	if err != nil {
 		return fmt.Errorf("update tenant: %w", err)
 	}
@@ -241,3 +252,11 @@ func (s *SQLTenantStore) Delete(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+// tenantStatus returns the status of cfg, active by default.
+func tenantStatus(cfg *TenantConfig) string {
+	if cfg.Status == "" {
+		return TenantStatusActive
+	}
+	return cfg.Status
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/bozz33/sublimeadmin/config"
 )
 
 func TestSubdomainResolver(t *testing.T) {
@@ -166,3 +170,22 @@ func TestTenantMiddlewareOptionalPassthrough(t *testing.T) {
 		t.Errorf("expected 200, got %d", w.Code)
 	}
 }
+
+func TestTenantConfigScope(t *testing.T) {
+	dir := t.TempDir()
+	content := "tenants:\n  acme:\n    engine:\n      brand_name: Acme Admin\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.Load(config.WithConfigPaths([]string{dir})); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithTenant(context.Background(), &Tenant{ID: "acme"})
+	if got := config.ForTenant(ctx).Engine.BrandName; got != "Acme Admin" {
+		t.Errorf("brand name = %q, want %q", got, "Acme Admin")
+	}
+	if got := config.ForTenant(context.Background()).Engine.BrandName; got != "SublimeAdmin" {
+		t.Errorf("brand name without tenant = %q, want %q", got, "SublimeAdmin")
+	}
+}
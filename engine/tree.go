@@ -0,0 +1,168 @@
+package engine
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/bozz33/sublimeadmin/apperrors"
+)
+
+// ResourceTree is an optional interface for resources whose records form a
+// hierarchy: categories, menus, org charts. Their list is shown as a tree,
+// children indented under their parent and collapsible, and a row dragged
+// onto another one is moved there (see MoveNode). The breadcrumbs of a
+// record list its ancestors.
+//
+// The tree holds all the records of List, within the scope of the list
+// (see ResourceScoped); a search, a filter or a sort shows the usual flat,
+// paginated table instead.
+type ResourceTree interface {
+	// ParentID returns the ID of the parent of item, "" for a root.
+	ParentID(item any) string
+	// TreeOrder returns the position of item among its siblings, lowest
+	// first. Equal positions keep the order of List.
+	TreeOrder(item any) int
+	// MoveNode moves the record id under parentID ("" for the root), at
+	// position among its new siblings (0 first).
+	MoveNode(ctx context.Context, id, parentID string, position int) error
+}
+
+// maxTreeDepth bounds the walk up the ancestors of a record.
+const maxTreeDepth = 64
+
+// treeNode is a record of a tree, in depth-first order.
+type treeNode struct {
+	item        any
+	parentID    string
+	depth       int
+	hasChildren bool
+}
+
+// arrangeTree orders items depth first, the children of each record after
+// it in TreeOrder. Records whose parent is not listed are roots.
+func arrangeTree(tree ResourceTree, items []any) []treeNode {
+	listed := make(map[string]bool, len(items))
+	for _, item := range items {
+		listed[getItemID(item)] = true
+	}
+	children := make(map[string][]any)
+	for _, item := range items {
+		parent := tree.ParentID(item)
+		if !listed[parent] || parent == getItemID(item) {
+			parent = ""
+		}
+		children[parent] = append(children[parent], item)
+	}
+	for _, siblings := range children {
+		slices.SortStableFunc(siblings, func(a, b any) int {
+			return cmp.Compare(tree.TreeOrder(a), tree.TreeOrder(b))
+		})
+	}
+
+	nodes := make([]treeNode, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	var walk func(parent string, depth int)
+	walk = func(parent string, depth int) {
+		for _, item := range children[parent] {
+			id := getItemID(item)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			nodes = append(nodes, treeNode{item: item, parentID: parent, depth: depth, hasChildren: len(children[id]) > 0})
+			walk(id, depth+1)
+		}
+	}
+	walk("", 0)
+	// Records in a cycle are reachable from no root: list them as roots.
+	for _, item := range items {
+		if id := getItemID(item); !seen[id] {
+			seen[id] = true
+			nodes = append(nodes, treeNode{item: item})
+			walk(id, 1)
+		}
+	}
+	return nodes
+}
+
+// treeMode returns the tree of the listed resource when its list is shown
+// as one: neither searched, filtered nor sorted.
+func treeMode(res any, lq *ListQuery, activeFilters map[string]string) (ResourceTree, bool) {
+	tree, ok := res.(ResourceTree)
+	if !ok || len(activeFilters) > 0 {
+		return nil, false
+	}
+	if lq != nil && (lq.Search != "" || lq.SortKey != "" || len(lq.Where) > 0) {
+		return nil, false
+	}
+	return tree, true
+}
+
+// treeAncestors returns the ancestors of item, the root first.
+func treeAncestors(ctx context.Context, res Resource, tree ResourceTree, item any) []any {
+	var ancestors []any
+	seen := map[string]bool{getItemID(item): true}
+	for parent := tree.ParentID(item); parent != "" && !seen[parent] && len(ancestors) < maxTreeDepth; {
+		seen[parent] = true
+		p, err := res.Get(ctx, parent)
+		if err != nil || p == nil {
+			break
+		}
+		ancestors = append(ancestors, p)
+		parent = tree.ParentID(p)
+	}
+	slices.Reverse(ancestors)
+	return ancestors
+}
+
+// MoveTreeNode moves a record of a ResourceTree, dragged in the tree table:
+//
+//	POST /{slug}/tree/move  id, parent_id ("" for the root), position
+//
+// It refuses to move a record under itself or one of its descendants, and
+// answers 204 No Content to the table, else redirects to the list.
+func (h *CRUDHandler) MoveTreeNode(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tree, ok := h.Resource.(ResourceTree)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if !h.Resource.CanUpdate(ctx) {
+		apperrors.Handle(w, r, apperrors.Forbidden(""))
+		return
+	}
+
+	id, parentID := r.FormValue("id"), r.FormValue("parent_id")
+	position, err := strconv.Atoi(r.FormValue("position"))
+	if id == "" || err != nil || position < 0 {
+		apperrors.Handle(w, r, apperrors.BadRequest("Invalid move"))
+		return
+	}
+	if parentID != "" {
+		parent, err := h.Resource.Get(ctx, parentID)
+		if err != nil || parent == nil {
+			apperrors.Handle(w, r, apperrors.NotFoundf("Record %s not found", parentID))
+			return
+		}
+		if parentID == id || slices.ContainsFunc(treeAncestors(ctx, h.Resource, tree, parent), func(a any) bool { return getItemID(a) == id }) {
+			apperrors.Handle(w, r, apperrors.BadRequest("A record cannot be moved under itself."))
+			return
+		}
+	}
+
+	if err := tree.MoveNode(ctx, id, parentID, position); err != nil {
+		apperrors.Handle(w, r, apperrors.Internal(err, "Move error"))
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	http.Redirect(w, r, PanelURL(ctx, "/"+h.Resource.Slug()), http.StatusSeeOther)
+}
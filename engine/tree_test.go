@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+type category struct {
+	ID       int
+	Name     string
+	ParentID int
+	Position int
+}
+
+// categoriesResource is a tree of categories.
+type categoriesResource struct {
+	*mockResource
+	categories []any
+	moved      string
+}
+
+func newCategoriesResource() *categoriesResource {
+	return &categoriesResource{mockResource: newMockResource("categories"), categories: []any{
+		&category{ID: 1, Name: "Electronics", Position: 2},
+		&category{ID: 2, Name: "Phones", ParentID: 1, Position: 1},
+		&category{ID: 3, Name: "Books"},
+		&category{ID: 4, Name: "Laptops", ParentID: 1},
+		&category{ID: 5, Name: "Android", ParentID: 2},
+	}}
+}
+
+func (c *categoriesResource) List(_ context.Context) ([]any, error) { return c.categories, nil }
+
+func (c *categoriesResource) Get(_ context.Context, id string) (any, error) {
+	for _, item := range c.categories {
+		if getItemID(item) == id {
+			return item, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *categoriesResource) ParentID(item any) string {
+	if id := item.(*category).ParentID; id != 0 {
+		return fmt.Sprint(id)
+	}
+	return ""
+}
+
+func (c *categoriesResource) TreeOrder(item any) int { return item.(*category).Position }
+
+func (c *categoriesResource) MoveNode(_ context.Context, id, parentID string, position int) error {
+	c.moved = fmt.Sprintf("%s under %q at %d", id, parentID, position)
+	return nil
+}
+
+func (c *categoriesResource) RecordTitle(item any) string { return item.(*category).Name }
+
+func (c *categoriesResource) Table(ctx context.Context) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		state, err := c.BuildTableState(ctx, false, false)
+		if err != nil {
+			return err
+		}
+		rows := make([]string, len(state.Rows))
+		for i, row := range state.Rows {
+			rows[i] = fmt.Sprintf("%s/%d/%s/%v", row.ID, row.Depth, row.ParentID, row.HasChildren)
+		}
+		_, err = fmt.Fprintf(w, "tree=%v move=%s rows=%s", state.Tree, state.TreeMoveURL, strings.Join(rows, " "))
+		return err
+	})
+}
+
+func TestCRUDHandler_List_tree(t *testing.T) {
+	h := NewCRUDHandler(newCategoriesResource())
+
+	body := serveWith(h, http.MethodGet, "/categories", nil).Body.String()
+	want := "tree=true move=/categories/tree/move rows=3/0//false 1/0//true 4/1/1/false 2/1/1/true 5/2/2/false"
+	if !strings.Contains(body, want) {
+		t.Errorf("expected %q, got %s", want, body)
+	}
+
+	body = serveWith(h, http.MethodGet, "/categories?search=pho", nil).Body.String()
+	if !strings.Contains(body, "tree=false") {
+		t.Errorf("expected a flat list while searching, got %s", body)
+	}
+}
+
+func TestCRUDHandler_MoveTreeNode(t *testing.T) {
+	res := newCategoriesResource()
+	h := NewCRUDHandler(res)
+
+	for _, parent := range []string{"1", "5"} {
+		rw := serveWith(h, http.MethodPost, "/categories/tree/move", url.Values{"id": {"1"}, "parent_id": {parent}, "position": {"0"}})
+		if rw.Code != http.StatusBadRequest || res.moved != "" {
+			t.Errorf("expected moving under %s refused, got %d", parent, rw.Code)
+		}
+	}
+
+	rw := serveWith(h, http.MethodPost, "/categories/tree/move", url.Values{"id": {"2"}, "parent_id": {"3"}, "position": {"1"}})
+	if rw.Code != http.StatusSeeOther || res.moved != `2 under "3" at 1` {
+		t.Errorf("expected the category moved, got %d %s", rw.Code, res.moved)
+	}
+	rw = serveWith(h, http.MethodPost, "/categories/tree/move", url.Values{"id": {"2"}, "position": {"x"}})
+	if rw.Code != http.StatusBadRequest {
+		t.Errorf("expected an invalid position refused, got %d", rw.Code)
+	}
+}
+
+func TestResourceBreadcrumbs_tree(t *testing.T) {
+	res := newCategoriesResource()
+	item, _ := res.Get(context.Background(), "5")
+	got := breadcrumbLabels(resourceBreadcrumbs(context.Background(), res, PageEdit, "5", item))
+	want := "categoriess=categories > Electronics=categories/1/edit > Phones=categories/2/edit > Android= > Edit="
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,188 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bozz33/sublimeadmin/apperrors"
+)
+
+// Tx is a transaction begun by a UnitOfWork.
+type Tx interface {
+	Commit() error
+	Rollback() error
+}
+
+// UnitOfWork begins the transaction a mutation runs in. The context it
+// returns carries the transaction, for the resources to join it: with
+// SQLUnitOfWork, through SQLConn; with Ent, through ent.TxFromContext.
+//
+// When a UnitOfWork is set (see Panel.WithUnitOfWork), each POST, PATCH or
+// DELETE request of a resource, its relation managers and actions included,
+// runs in one transaction: committed when the response succeeds, rolled
+// back when a validation, a hook (see ResourceHookable) or the operation
+// fails.
+type UnitOfWork interface {
+	Begin(ctx context.Context) (context.Context, Tx, error)
+}
+
+// UnitOfWorkFunc adapts a function to UnitOfWork, e.g. for Ent:
+//
+//	engine.UnitOfWorkFunc(func(ctx context.Context) (context.Context, engine.Tx, error) {
+//	    tx, err := client.Tx(ctx)
+//	    if err != nil {
+//	        return nil, nil, err
+//	    }
+//	    return ent.NewTxContext(ctx, tx), tx, nil
+//	})
+//
+// Resources then query through ent.TxFromContext(ctx) when it is not nil.
+type UnitOfWorkFunc func(ctx context.Context) (context.Context, Tx, error)
+
+// Begin calls f(ctx).
+func (f UnitOfWorkFunc) Begin(ctx context.Context) (context.Context, Tx, error) {
+	return f(ctx)
+}
+
+type sqlTxKey struct{}
+
+// SQLUnitOfWork returns a UnitOfWork running the mutations in a transaction
+// of db. Resources join it through SQLConn.
+func SQLUnitOfWork(db *sql.DB) UnitOfWork {
+	return UnitOfWorkFunc(func(ctx context.Context) (context.Context, Tx, error) {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		return context.WithValue(ctx, sqlTxKey{}, tx), tx, nil
+	})
+}
+
+// TxFromContext returns the transaction of SQLUnitOfWork in ctx, or nil.
+func TxFromContext(ctx context.Context) *sql.Tx {
+	tx, _ := ctx.Value(sqlTxKey{}).(*sql.Tx)
+	return tx
+}
+
+// SQLExecutor is the part of *sql.DB and *sql.Tx running queries.
+type SQLExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// SQLConn returns the transaction of ctx (see SQLUnitOfWork), else db, so
+// that a resource joins the unit of work of the request when there is one:
+//
+//	_, err := engine.SQLConn(ctx, r.db).ExecContext(ctx, "DELETE FROM posts WHERE id = ?", id)
+func SQLConn(ctx context.Context, db *sql.DB) SQLExecutor {
+	if tx := TxFromContext(ctx); tx != nil {
+		return tx
+	}
+	return db
+}
+
+// RunInUnitOfWork runs fn in a transaction of uow, committed when fn
+// succeeds and rolled back when it fails or panics. Without uow, fn runs
+// as is.
+func RunInUnitOfWork(ctx context.Context, uow UnitOfWork, fn func(ctx context.Context) error) error {
+	if uow == nil {
+		return fn(ctx)
+	}
+	txCtx, tx, err := uow.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("unit of work: begin: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+	if err := fn(txCtx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unit of work: commit: %w", err)
+	}
+	return nil
+}
+
+// UnitOfWorkMiddleware runs the POST, PATCH, PUT and DELETE requests of
+// next in a transaction of uow, for handlers mounted outside a Panel. The
+// response is held until the transaction ends: it is sent when the commit
+// succeeds; a response with an error status rolls the transaction back.
+func UnitOfWorkMiddleware(uow UnitOfWork) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			serveInUnitOfWork(uow, w, r, next.ServeHTTP)
+		})
+	}
+}
+
+// serveInUnitOfWork serves r with serve in a transaction of uow when r is a
+// mutation, else as is.
+func serveInUnitOfWork(uow UnitOfWork, w http.ResponseWriter, r *http.Request, serve http.HandlerFunc) {
+	if uow == nil || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+		serve(w, r)
+		return
+	}
+	tw := &txResponseWriter{header: make(http.Header)}
+	err := RunInUnitOfWork(r.Context(), uow, func(ctx context.Context) error {
+		serve(tw, r.WithContext(ctx))
+		if tw.Status() >= http.StatusBadRequest {
+			return errResponseFailed
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errResponseFailed) {
+		apperrors.Handle(w, r, apperrors.Internal(err, "Transaction error"))
+		return
+	}
+	tw.flush(w)
+}
+
+// errResponseFailed rolls back the unit of work of a failed response.
+var errResponseFailed = errors.New("unit of work: response failed")
+
+// txResponseWriter holds a response until its transaction ends.
+type txResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *txResponseWriter) Header() http.Header { return w.header }
+
+func (w *txResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *txResponseWriter) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
+
+// Status returns the status of the response, 200 when none was written.
+func (w *txResponseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// flush sends the response held to dst.
+func (w *txResponseWriter) flush(dst http.ResponseWriter) {
+	for k, v := range w.header {
+		dst.Header()[k] = v
+	}
+	dst.WriteHeader(w.Status())
+	_, _ = dst.Write(w.body.Bytes())
+}
@@ -0,0 +1,191 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// recordingUoW is a UnitOfWork recording the outcome of its transactions.
+type recordingUoW struct {
+	begun, committed, rolledBack int
+	commitErr                    error
+}
+
+type uowCtxKey struct{}
+
+func (u *recordingUoW) Begin(ctx context.Context) (context.Context, Tx, error) {
+	u.begun++
+	return context.WithValue(ctx, uowCtxKey{}, u), u, nil
+}
+
+func (u *recordingUoW) Commit() error {
+	if u.commitErr != nil {
+		return u.commitErr
+	}
+	u.committed++
+	return nil
+}
+
+func (u *recordingUoW) Rollback() error {
+	u.rolledBack++
+	return nil
+}
+
+// hookedPosts is a blog post resource recording its hooks, failing the
+// ones named in fail.
+type hookedPosts struct {
+	*TypedResource[blogPost]
+	fail    string
+	calls   []string
+	created any
+	inTx    bool
+}
+
+func (h *hookedPosts) hook(ctx context.Context, name string) error {
+	h.calls = append(h.calls, name)
+	h.inTx = ctx.Value(uowCtxKey{}) != nil
+	if h.fail == name {
+		return errors.New(name + " failed")
+	}
+	return nil
+}
+
+func (h *hookedPosts) BeforeCreate(ctx context.Context, _ *http.Request) error {
+	return h.hook(ctx, "BeforeCreate")
+}
+
+func (h *hookedPosts) AfterCreate(ctx context.Context, item any) error {
+	h.created = item
+	return h.hook(ctx, "AfterCreate")
+}
+
+func (h *hookedPosts) BeforeUpdate(ctx context.Context, _ string, _ *http.Request) error {
+	return h.hook(ctx, "BeforeUpdate")
+}
+
+func (h *hookedPosts) AfterUpdate(ctx context.Context, _ string, _ any) error {
+	return h.hook(ctx, "AfterUpdate")
+}
+
+func (h *hookedPosts) BeforeDelete(ctx context.Context, _ string) error {
+	return h.hook(ctx, "BeforeDelete")
+}
+
+func (h *hookedPosts) AfterDelete(ctx context.Context, _ string) error {
+	return h.hook(ctx, "AfterDelete")
+}
+
+func newHookedPosts(posts *[]*blogPost) *hookedPosts {
+	res := newPostResource(posts)
+	res.WithDelete(func(ctx context.Context, id string) error { return nil })
+	return &hookedPosts{TypedResource: res}
+}
+
+func TestCRUDHandler_UnitOfWork(t *testing.T) {
+	form := url.Values{"title": {"Hello"}, "status": {"draft"}}
+
+	t.Run("commits a successful create", func(t *testing.T) {
+		var posts []*blogPost
+		res := newHookedPosts(&posts)
+		uow := &recordingUoW{}
+		h := newHandler(res)
+		h.UnitOfWork = uow
+
+		rw := serveWith(h, http.MethodPost, "/blog-posts", form)
+		if rw.Code != http.StatusSeeOther {
+			t.Fatalf("status = %d, want 303", rw.Code)
+		}
+		if uow.begun != 1 || uow.committed != 1 || uow.rolledBack != 0 {
+			t.Errorf("begun %d, committed %d, rolled back %d", uow.begun, uow.committed, uow.rolledBack)
+		}
+		if len(res.calls) != 2 || !res.inTx {
+			t.Errorf("hooks = %v, in transaction %v", res.calls, res.inTx)
+		}
+		if p, ok := res.created.(*blogPost); !ok || p.Title != "Hello" {
+			t.Errorf("AfterCreate got %#v, want the created post", res.created)
+		}
+	})
+
+	t.Run("rolls back when a hook fails", func(t *testing.T) {
+		var posts []*blogPost
+		res := newHookedPosts(&posts)
+		res.fail = "AfterCreate"
+		uow := &recordingUoW{}
+		h := newHandler(res)
+		h.UnitOfWork = uow
+
+		rw := serveWith(h, http.MethodPost, "/blog-posts", form)
+		if rw.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("status = %d, want 422", rw.Code)
+		}
+		if uow.committed != 0 || uow.rolledBack != 1 {
+			t.Errorf("committed %d, rolled back %d", uow.committed, uow.rolledBack)
+		}
+	})
+
+	t.Run("rolls back a failed delete", func(t *testing.T) {
+		var posts []*blogPost
+		res := newHookedPosts(&posts)
+		res.fail = "BeforeDelete"
+		uow := &recordingUoW{}
+		h := newHandler(res)
+		h.UnitOfWork = uow
+
+		rw := serveWith(h, http.MethodPost, "/blog-posts/1/delete", url.Values{})
+		if rw.Code < http.StatusBadRequest {
+			t.Fatalf("status = %d, want an error", rw.Code)
+		}
+		if uow.rolledBack != 1 || len(res.calls) != 1 {
+			t.Errorf("rolled back %d, hooks %v", uow.rolledBack, res.calls)
+		}
+	})
+
+	t.Run("answers 500 when the commit fails", func(t *testing.T) {
+		var posts []*blogPost
+		uow := &recordingUoW{commitErr: errors.New("serialization failure")}
+		h := newHandler(newHookedPosts(&posts))
+		h.UnitOfWork = uow
+
+		rw := serveWith(h, http.MethodPost, "/blog-posts", form)
+		if rw.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want 500", rw.Code)
+		}
+	})
+
+	t.Run("reads run outside a transaction", func(t *testing.T) {
+		var posts []*blogPost
+		uow := &recordingUoW{}
+		h := newHandler(newHookedPosts(&posts))
+		h.UnitOfWork = uow
+
+		serveWith(h, http.MethodGet, "/blog-posts", nil)
+		if uow.begun != 0 {
+			t.Errorf("begun %d transactions for a GET", uow.begun)
+		}
+	})
+}
+
+func TestRunInUnitOfWork(t *testing.T) {
+	uow := &recordingUoW{}
+	err := RunInUnitOfWork(context.Background(), uow, func(ctx context.Context) error {
+		return errors.New("boom")
+	})
+	if err == nil || uow.rolledBack != 1 || uow.committed != 0 {
+		t.Errorf("err %v, committed %d, rolled back %d", err, uow.committed, uow.rolledBack)
+	}
+
+	func() {
+		defer func() { _ = recover() }()
+		_ = RunInUnitOfWork(context.Background(), uow, func(ctx context.Context) error { panic("boom") })
+	}()
+	if uow.rolledBack != 2 {
+		t.Errorf("rolled back %d, want a rollback on panic", uow.rolledBack)
+	}
+
+	if err := RunInUnitOfWork(context.Background(), nil, func(ctx context.Context) error { return nil }); err != nil {
+		t.Errorf("without a unit of work: %v", err)
+	}
+}
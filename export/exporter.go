@@ -61,8 +61,9 @@ func (e *Exporter) FromStructs(items interface{}) *Exporter {
 		return e
 	}
 
+	// Slices of any ([]any from Resource.List) hold their records in interfaces.
 	first := v.Index(0)
-	if first.Kind() == reflect.Ptr {
+	for first.Kind() == reflect.Interface || first.Kind() == reflect.Ptr {
 		first = first.Elem()
 	}
 
@@ -90,9 +91,12 @@ func (e *Exporter) FromStructs(items interface{}) *Exporter {
 
 	for i := 0; i < v.Len(); i++ {
 		item := v.Index(i)
-		if item.Kind() == reflect.Ptr {
+		for item.Kind() == reflect.Interface || item.Kind() == reflect.Ptr {
 			item = item.Elem()
 		}
+		if item.Kind() != reflect.Struct {
+			continue
+		}
 
 		row := make([]string, 0)
 		for j := 0; j < item.NumField(); j++ {
@@ -87,6 +87,16 @@ func TestFromStructs(t *testing.T) {
 	assert.Equal(t, "No", exp.data[1][3]) // Active = false
 }
 
+func TestFromStructs_AnySlice(t *testing.T) {
+	items := []any{&TestUser{ID: 1, Name: "John Doe"}, &TestUser{ID: 2, Name: "Jane Smith"}}
+
+	exp := New(FormatCSV).FromStructs(items)
+
+	assert.Len(t, exp.headers, 5)
+	assert.Len(t, exp.data, 2)
+	assert.Equal(t, "Jane Smith", exp.data[1][1])
+}
+
 func TestWriteCSV(t *testing.T) {
 	exp := New(FormatCSV)
 	exp.SetHeaders([]string{"ID", "Name", "Email"})
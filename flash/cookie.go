@@ -0,0 +1,159 @@
+package flash
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// maxCookieSize is the largest flash cookie value written; the oldest
+// messages are dropped beyond it (browsers ignore cookies over 4 KB).
+const maxCookieSize = 3800
+
+// CookieConfig configures the cookie of a cookie-based Manager.
+type CookieConfig struct {
+	Name     string
+	Path     string
+	Domain   string // e.g. ".example.com" to share the messages across subdomains
+	Secure   bool   // enable in production (HTTPS only)
+	SameSite http.SameSite
+}
+
+// DefaultCookieConfig returns the default configuration.
+func DefaultCookieConfig() CookieConfig {
+	return CookieConfig{
+		Name:     "_flash",
+		Path:     "/",
+		Secure:   false,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// NewCookieManager creates a flash message manager storing the messages in a
+// cookie signed with secret instead of a session, for panels and endpoints
+// that run stateless. The messages are loaded and written by
+// middleware.Flash.
+func NewCookieManager(secret []byte) *Manager {
+	return NewCookieManagerWithConfig(secret, DefaultCookieConfig())
+}
+
+// NewCookieManagerWithConfig creates a cookie-based manager with a custom
+// cookie configuration.
+func NewCookieManagerWithConfig(secret []byte, config CookieConfig) *Manager {
+	if len(secret) == 0 {
+		panic("flash: cookie secret is required")
+	}
+	if config.Name == "" {
+		config.Name = DefaultCookieConfig().Name
+	}
+	return &Manager{store: &cookieStore{secret: secret, config: config}}
+}
+
+// cookieState holds the messages of the flash cookie for a request.
+type cookieState struct {
+	messages []*Message
+	changed  bool
+}
+
+type cookieStateKey struct{}
+
+// cookieStore keeps the messages in a signed cookie: Load reads it into the
+// request context, where they are changed, and Save writes it back.
+type cookieStore struct {
+	secret []byte
+	config CookieConfig
+}
+
+func (s *cookieStore) get(ctx context.Context) []*Message {
+	if state, ok := ctx.Value(cookieStateKey{}).(*cookieState); ok {
+		return state.messages
+	}
+	return nil
+}
+
+func (s *cookieStore) put(ctx context.Context, messages []*Message) {
+	state, ok := ctx.Value(cookieStateKey{}).(*cookieState)
+	if !ok || len(messages) == 0 && len(state.messages) == 0 {
+		return
+	}
+	state.messages = messages
+	state.changed = true
+}
+
+func (s *cookieStore) remove(ctx context.Context) {
+	s.put(ctx, nil)
+}
+
+func (s *cookieStore) load(r *http.Request) context.Context {
+	state := &cookieState{}
+	if c, err := r.Cookie(s.config.Name); err == nil {
+		state.messages = s.decode(c.Value)
+	}
+	return context.WithValue(r.Context(), cookieStateKey{}, state)
+}
+
+func (s *cookieStore) save(ctx context.Context, w http.ResponseWriter) {
+	state, ok := ctx.Value(cookieStateKey{}).(*cookieState)
+	if !ok || !state.changed {
+		return
+	}
+	cookie := &http.Cookie{
+		Name:     s.config.Name,
+		Path:     s.config.Path,
+		Domain:   s.config.Domain,
+		Secure:   s.config.Secure,
+		SameSite: s.config.SameSite,
+		HttpOnly: true,
+	}
+	messages := state.messages
+	for len(messages) > 0 {
+		if cookie.Value = s.encode(messages); len(cookie.Value) <= maxCookieSize {
+			break
+		}
+		messages = messages[1:]
+	}
+	if len(messages) == 0 {
+		cookie.Value = ""
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(w, cookie)
+	state.changed = false
+}
+
+// encode returns the messages as base64 JSON followed by its signature.
+func (s *cookieStore) encode(messages []*Message) string {
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return ""
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + s.sign(payload)
+}
+
+// decode returns the messages of a cookie value, nil when it was tampered
+// with.
+func (s *cookieStore) decode(value string) []*Message {
+	payload, signature, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(payload))) {
+		return nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil
+	}
+	var messages []*Message
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil
+	}
+	return messages
+}
+
+func (s *cookieStore) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
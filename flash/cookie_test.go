@@ -0,0 +1,85 @@
+package flash
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTrip adds messages in one request and returns the cookies written.
+func roundTrip(t *testing.T, m *Manager, cookies []*http.Cookie, add ...*Message) ([]*Message, []*http.Cookie) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	ctx := m.Load(req)
+	loaded := m.GetAndClear(ctx)
+	for _, msg := range add {
+		m.Add(ctx, msg)
+	}
+	rw := httptest.NewRecorder()
+	m.Save(ctx, rw)
+	return loaded, rw.Result().Cookies()
+}
+
+func TestCookieManager(t *testing.T) {
+	m := NewCookieManager([]byte("secret"))
+
+	_, cookies := roundTrip(t, m, nil, NewMessage(TypeSuccess, "Saved").WithAction("Undo", "/undo"))
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "_flash", cookies[0].Name)
+	assert.True(t, cookies[0].HttpOnly)
+
+	loaded, next := roundTrip(t, m, cookies)
+	require.Len(t, loaded, 1)
+	assert.Equal(t, "Saved", loaded[0].Text)
+	assert.Equal(t, "/undo", loaded[0].Actions[0].URL)
+
+	// Reading the messages deletes the cookie
+	require.Len(t, next, 1)
+	assert.Equal(t, -1, next[0].MaxAge)
+
+	// No cookie is written when there is nothing to keep
+	_, none := roundTrip(t, m, nil)
+	assert.Empty(t, none)
+}
+
+func TestCookieManager_rejects_tampered_cookies(t *testing.T) {
+	m := NewCookieManager([]byte("secret"))
+	_, cookies := roundTrip(t, m, nil, NewMessage(TypeInfo, "Hello"))
+
+	forged := NewCookieManager([]byte("other"))
+	loaded, _ := roundTrip(t, forged, cookies)
+	assert.Empty(t, loaded)
+
+	payload, _, _ := strings.Cut(cookies[0].Value, ".")
+	cookies[0].Value = payload + ".AAAA"
+	loaded, _ = roundTrip(t, m, cookies)
+	assert.Empty(t, loaded)
+}
+
+func TestCookieManager_size_limit(t *testing.T) {
+	m := NewCookieManager([]byte("secret"))
+	var messages []*Message
+	for i := 0; i < 40; i++ {
+		messages = append(messages, NewMessage(TypeInfo, strings.Repeat("x", 200)))
+	}
+	messages = append(messages, NewMessage(TypeSuccess, "last"))
+
+	_, cookies := roundTrip(t, m, nil, messages...)
+	require.Len(t, cookies, 1)
+	assert.LessOrEqual(t, len(cookies[0].Value), maxCookieSize)
+
+	loaded, _ := roundTrip(t, m, cookies)
+	require.NotEmpty(t, loaded)
+	assert.Equal(t, "last", loaded[len(loaded)-1].Text, "the oldest messages are dropped first")
+}
+
+func TestNewCookieManager_requires_secret(t *testing.T) {
+	assert.Panics(t, func() { NewCookieManager(nil) })
+}
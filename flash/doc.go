@@ -6,9 +6,11 @@
 //
 // Features:
 //   - Success, error, warning, info message types
-//   - Session-based storage with SCS
+//   - Session-based storage with SCS, or a signed cookie for stateless panels
 //   - Automatic clearing after display
-//   - Multiple messages support
+//   - Multiple messages support, rendered as stacked toasts by the layout
+//   - Action buttons ("Undo", "View record")
+//   - Kept across redirects, sent in the HX-Trigger header to HTMX requests
 //
 // Basic usage:
 //
@@ -23,4 +25,22 @@
 //	for _, msg := range messages {
 //		// Display message
 //	}
+//
+// Behind middleware.Flash (installed by panels with a session), messages are
+// added from the request and rendered by the layout as toasts, their Type
+// giving the level of the toast:
+//
+//	flash.Add(r, flash.NewMessage(flash.TypeSuccess, "Post deleted").
+//		WithAction("Undo", "/posts/1/restore").
+//		WithLink("View trash", "/posts/trash"))
+//	http.Redirect(w, r, "/posts", http.StatusSeeOther)
+//
+// Panels and endpoints running without a session keep the messages in a
+// signed cookie instead; middleware.Flash reads and writes it:
+//
+//	manager := flash.NewCookieManager([]byte(os.Getenv("FLASH_SECRET")))
+//	handler = middleware.Flash(manager)(handler)
+//
+// HTMX requests receive the messages in the HX-Trigger response header as
+// a "flash" event, shown as toasts by app.js.
 package flash
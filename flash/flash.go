@@ -2,7 +2,10 @@ package flash
 
 import (
 	"context"
+	"encoding/gob"
+	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/alexedwards/scs/v2"
 	"github.com/samber/lo"
@@ -17,11 +20,26 @@ const (
 
 const sessionKey = "_flash_messages"
 
-// Message represents a flash message.
+func init() {
+	// Session stores gob-encode their values: without this the messages
+	// would not survive the redirect they are meant for.
+	gob.Register([]*Message{})
+}
+
+// Message represents a flash message. Its Type is the level of the toast it
+// is displayed as.
 type Message struct {
-	Type  string `json:"type"`
-	Text  string `json:"text"`
-	Title string `json:"title,omitempty"`
+	Type    string   `json:"type"`
+	Text    string   `json:"text"`
+	Title   string   `json:"title,omitempty"`
+	Actions []Action `json:"actions,omitempty"`
+}
+
+// Action is a button of a flash message, e.g. "Undo" or "View record".
+type Action struct {
+	Label  string `json:"label"`
+	URL    string `json:"url"`
+	Method string `json:"method,omitempty"` // "POST" submits URL; empty follows it as a link
 }
 
 // NewMessage creates a new flash message.
@@ -38,15 +56,72 @@ func (m *Message) WithTitle(title string) *Message {
 	return m
 }
 
-// Manager handles flash messages.
+// WithLink adds a button following url, e.g. "View record".
+func (m *Message) WithLink(label, url string) *Message {
+	m.Actions = append(m.Actions, Action{Label: label, URL: url})
+	return m
+}
+
+// WithAction adds a button posting to url, e.g. "Undo".
+func (m *Message) WithAction(label, url string) *Message {
+	m.Actions = append(m.Actions, Action{Label: label, URL: url, Method: http.MethodPost})
+	return m
+}
+
+// Manager handles flash messages. NewManager stores them in the session,
+// NewCookieManager in a signed cookie.
 type Manager struct {
-	session *scs.SessionManager
+	store store
+}
+
+// store persists the messages between requests.
+type store interface {
+	get(ctx context.Context) []*Message
+	put(ctx context.Context, messages []*Message)
+	remove(ctx context.Context)
 }
 
 // NewManager creates a new flash message manager.
 func NewManager(session *scs.SessionManager) *Manager {
-	return &Manager{
-		session: session,
+	return &Manager{store: sessionStore{session}}
+}
+
+// sessionStore keeps the messages in an SCS session.
+type sessionStore struct {
+	session *scs.SessionManager
+}
+
+func (s sessionStore) get(ctx context.Context) []*Message {
+	messages, _ := s.session.Get(ctx, sessionKey).([]*Message)
+	return messages
+}
+
+func (s sessionStore) put(ctx context.Context, messages []*Message) {
+	s.session.Put(ctx, sessionKey, messages)
+}
+
+func (s sessionStore) remove(ctx context.Context) {
+	s.session.Remove(ctx, sessionKey)
+}
+
+// Load prepares a request for the manager and returns its context: a
+// cookie-based manager reads the flash cookie. Session-based managers rely
+// on the session middleware and return the context unchanged.
+// middleware.Flash calls it.
+func (m *Manager) Load(r *http.Request) context.Context {
+	if c, ok := m.store.(*cookieStore); ok {
+		return c.load(r)
+	}
+	return r.Context()
+}
+
+// Save writes the messages changed during the request to the flash cookie
+// of a cookie-based manager, and does nothing for session-based ones. It
+// must be called before the response header is written; middleware.Flash
+// calls it.
+func (m *Manager) Save(ctx context.Context, w http.ResponseWriter) {
+	if c, ok := m.store.(*cookieStore); ok {
+		c.save(ctx, w)
 	}
 }
 
@@ -94,7 +169,7 @@ func (m *Manager) InfoWithTitle(ctx context.Context, title, text string) {
 func (m *Manager) Add(ctx context.Context, message *Message) {
 	messages := m.getMessages(ctx)
 	messages = append(messages, message)
-	m.session.Put(ctx, sessionKey, messages)
+	m.store.put(ctx, messages)
 }
 
 // Get retrieves all messages without clearing them.
@@ -111,7 +186,7 @@ func (m *Manager) GetAndClear(ctx context.Context) []*Message {
 
 // Clear removes all messages.
 func (m *Manager) Clear(ctx context.Context) {
-	m.session.Remove(ctx, sessionKey)
+	m.store.remove(ctx)
 }
 
 // Has checks if there are any messages.
@@ -143,17 +218,11 @@ func (m *Manager) CountByType(ctx context.Context, msgType string) int {
 	return len(m.GetByType(ctx, msgType))
 }
 
-// getMessages retrieves messages from the session.
+// getMessages retrieves messages from the store.
 func (m *Manager) getMessages(ctx context.Context) []*Message {
-	data := m.session.Get(ctx, sessionKey)
-	if data == nil {
-		return []*Message{}
-	}
-
-	if messages, ok := data.([]*Message); ok {
+	if messages := m.store.get(ctx); messages != nil {
 		return messages
 	}
-
 	return []*Message{}
 }
 
@@ -162,9 +231,16 @@ func (m *Manager) Keep(ctx context.Context) {
 	// Messages are already in session, no action needed
 }
 
-// Reflash keeps all messages for the next request.
+// Reflash keeps the messages loaded for the current request (see
+// WithMessages) for the next one, ahead of those added since. The Flash
+// middleware calls it when the request ends with a redirect, so that
+// messages are not lost on a page that is never rendered.
 func (m *Manager) Reflash(ctx context.Context) {
-	// Already implemented by default with SCS
+	loaded := MessagesFromContext(ctx)
+	if len(loaded) == 0 {
+		return
+	}
+	m.store.put(ctx, append(append([]*Message{}, loaded...), m.getMessages(ctx)...))
 }
 
 // SuccessFromRequest adds a success message from the request.
@@ -268,6 +344,13 @@ func Info(r *http.Request, text string) {
 	}
 }
 
+// Add adds a custom message, e.g. one with actions.
+func Add(r *http.Request, message *Message) {
+	if manager := ManagerFromRequest(r); manager != nil {
+		manager.Add(r.Context(), message)
+	}
+}
+
 // Get retrieves messages.
 func Get(r *http.Request) []*Message {
 	return MessagesFromRequest(r)
@@ -277,3 +360,44 @@ func Get(r *http.Request) []*Message {
 func Has(r *http.Request) bool {
 	return len(MessagesFromRequest(r)) > 0
 }
+
+// TriggerHeader is the response header carrying flash messages to HTMX
+// requests, which swap a fragment rather than render the toast region.
+const TriggerHeader = "HX-Trigger"
+
+// TriggerEvent is the client-side event the messages are dispatched as:
+// its detail is {"messages": [...]}.
+const TriggerEvent = "flash"
+
+// IsHTMX reports whether the request was sent by HTMX.
+func IsHTMX(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true"
+}
+
+// WriteTrigger adds messages to the HX-Trigger header of w, keeping the
+// events already triggered there. It must be called before the header is
+// written.
+func WriteTrigger(w http.ResponseWriter, messages []*Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	events := map[string]any{}
+	if existing := w.Header().Get(TriggerHeader); existing != "" {
+		if err := json.Unmarshal([]byte(existing), &events); err != nil {
+			// Plain comma-separated event names
+			events = map[string]any{}
+			for _, name := range strings.Split(existing, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					events[name] = nil
+				}
+			}
+		}
+	}
+	events[TriggerEvent] = map[string]any{"messages": messages}
+	data, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	w.Header().Set(TriggerHeader, string(data))
+	return nil
+}
@@ -2,6 +2,8 @@ package flash
 
 import (
 	"context"
+	"encoding/json"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/alexedwards/scs/v2"
@@ -29,7 +31,7 @@ func TestNewManager(t *testing.T) {
 	manager := NewManager(session)
 
 	require.NotNil(t, manager)
-	assert.Equal(t, session, manager.session)
+	assert.Equal(t, sessionStore{session}, manager.store)
 }
 
 func TestManagerSuccess(t *testing.T) {
@@ -255,6 +257,68 @@ func TestMessagesFromContextEmpty(t *testing.T) {
 	assert.Len(t, messages, 0)
 }
 
+func TestMessageActions(t *testing.T) {
+	msg := NewMessage(TypeSuccess, "Post deleted").
+		WithAction("Undo", "/posts/1/restore").
+		WithLink("View trash", "/posts/trash")
+
+	require.Len(t, msg.Actions, 2)
+	assert.Equal(t, Action{Label: "Undo", URL: "/posts/1/restore", Method: "POST"}, msg.Actions[0])
+	assert.Equal(t, Action{Label: "View trash", URL: "/posts/trash"}, msg.Actions[1])
+}
+
+func TestManagerReflash(t *testing.T) {
+	session := scs.New()
+	manager := NewManager(session)
+	ctx, _ := session.Load(context.Background(), "")
+
+	ctx = WithMessages(ctx, []*Message{NewMessage(TypeInfo, "Loaded")})
+	manager.Success(ctx, "Added")
+	manager.Reflash(ctx)
+
+	messages := manager.Get(ctx)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "Loaded", messages[0].Text)
+	assert.Equal(t, "Added", messages[1].Text)
+}
+
+func TestManagerMessagesSurviveSessionStore(t *testing.T) {
+	session := scs.New()
+	manager := NewManager(session)
+	ctx, _ := session.Load(context.Background(), "")
+	manager.Add(ctx, NewMessage(TypeSuccess, "Saved").WithAction("Undo", "/undo"))
+
+	token, _, err := session.Commit(ctx)
+	require.NoError(t, err)
+
+	next, err := session.Load(context.Background(), token)
+	require.NoError(t, err)
+	messages := manager.GetAndClear(next)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "Saved", messages[0].Text)
+	assert.Equal(t, "Undo", messages[0].Actions[0].Label)
+}
+
+func TestWriteTrigger(t *testing.T) {
+	rw := httptest.NewRecorder()
+	rw.Header().Set(TriggerHeader, "refresh-table")
+
+	require.NoError(t, WriteTrigger(rw, []*Message{NewMessage(TypeSuccess, "Saved")}))
+
+	var events map[string]struct {
+		Messages []*Message `json:"messages"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(rw.Header().Get(TriggerHeader)), &events))
+	assert.Contains(t, events, "refresh-table")
+	require.Len(t, events[TriggerEvent].Messages, 1)
+	assert.Equal(t, "Saved", events[TriggerEvent].Messages[0].Text)
+
+	// Nothing to send
+	rw = httptest.NewRecorder()
+	require.NoError(t, WriteTrigger(rw, nil))
+	assert.Empty(t, rw.Header().Get(TriggerHeader))
+}
+
 func BenchmarkManagerAdd(b *testing.B) {
 	session := scs.New()
 	manager := NewManager(session)
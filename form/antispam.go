@@ -0,0 +1,223 @@
+package form
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+// DefaultHoneypotField is the name of the honeypot field of
+// WithHoneypot("").
+const DefaultHoneypotField = "website"
+
+// renderedAtField is the field carrying the signed time the form was
+// rendered at (see WithMinFillTime).
+const renderedAtField = "_form_rendered_at"
+
+// ErrSpam is returned by CheckSubmission for the submissions looking
+// automated: honeypot filled, form sent too fast or without the time it was
+// rendered at.
+var ErrSpam = errors.New("form: submission rejected as spam")
+
+// ThrottleError is returned by CheckSubmission when the submitter sent the
+// form too many times.
+type ThrottleError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottleError) Error() string {
+	return fmt.Sprintf("form: too many submissions, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+// ThrottleStore counts the submissions of the throttled forms. The engine
+// backs it with the rate limit stores of the middleware package (see
+// engine.NewFormThrottleStore).
+type ThrottleStore interface {
+	// Allow reports whether key may submit again under a limit of limit
+	// submissions per window and, when it may not, how long to wait.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error)
+}
+
+// Throttle limits the submissions of a form per submitter.
+type Throttle struct {
+	Name   string // identifies the form in the store (default: the path of the request)
+	Store  ThrottleStore
+	Limit  int           // submissions allowed per Window
+	Window time.Duration // e.g. time.Hour
+	// Key identifies the submitter, e.g. middleware.KeyByIP behind a
+	// proxy. Default: the remote address of the request.
+	Key func(r *http.Request) string
+}
+
+// antiSpam holds the anti-spam settings of a form.
+type antiSpam struct {
+	honeypot    string
+	minFillTime time.Duration
+	throttle    *Throttle
+}
+
+// antiSpamKey signs the time the forms were rendered at.
+var antiSpamKey = func() []byte {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return key
+}()
+
+// SetAntiSpamKey sets the key signing the time the forms were rendered at,
+// random by default. Set the same key on every replica serving the forms,
+// before serving them.
+func SetAntiSpamKey(key []byte) {
+	antiSpamKey = key
+}
+
+// WithHoneypot adds a field hidden from the users, named name
+// (DefaultHoneypotField if empty): bots filling every field are rejected by
+// CheckSubmission. Render it with AntiSpamFields.
+func (f *Form) WithHoneypot(name string) *Form {
+	if name == "" {
+		name = DefaultHoneypotField
+	}
+	f.spam.honeypot = name
+	return f
+}
+
+// WithMinFillTime rejects the submissions sent less than d after the form
+// was rendered, faster than a human fills it. AntiSpamFields renders the
+// signed time the form was rendered at.
+func (f *Form) WithMinFillTime(d time.Duration) *Form {
+	f.spam.minFillTime = d
+	return f
+}
+
+// WithThrottle limits the submissions of the form per submitter:
+//
+//	form.New().WithThrottle(form.Throttle{
+//		Name:   "register",
+//		Store:  engine.NewFormThrottleStore(middleware.NewMemoryRateLimitStore(0)),
+//		Limit:  5,
+//		Window: time.Hour,
+//		Key:    middleware.KeyByIP,
+//	})
+func (f *Form) WithThrottle(t Throttle) *Form {
+	f.spam.throttle = &t
+	return f
+}
+
+// AntiSpamFields renders the hidden fields of WithHoneypot and
+// WithMinFillTime, to put inside the <form> element.
+func (f *Form) AntiSpamFields() templ.Component {
+	var renderedAt string
+	if f.spam.minFillTime > 0 {
+		renderedAt = signRenderedAt(time.Now())
+	}
+	return antiSpamFields(f.spam.honeypot, renderedAtField, renderedAt)
+}
+
+// CheckSubmission checks the submission r of the form against its
+// throttle, then its honeypot and its minimum fill time. It returns a
+// *ThrottleError, ErrSpam, or the error of the ThrottleStore.
+func (f *Form) CheckSubmission(r *http.Request) error {
+	if t := f.spam.throttle; t != nil && t.Store != nil && t.Limit > 0 {
+		if err := t.allow(r); err != nil {
+			return err
+		}
+	}
+	if f.spam.honeypot != "" && r.PostFormValue(f.spam.honeypot) != "" {
+		return ErrSpam
+	}
+	if f.spam.minFillTime > 0 {
+		renderedAt, ok := parseRenderedAt(r.PostFormValue(renderedAtField))
+		if !ok || time.Since(renderedAt) < f.spam.minFillTime {
+			return ErrSpam
+		}
+	}
+	return nil
+}
+
+// allow counts the submission r.
+func (t *Throttle) allow(r *http.Request) error {
+	name := t.Name
+	if name == "" {
+		name = r.URL.Path
+	}
+	submitter := r.RemoteAddr
+	if t.Key != nil {
+		submitter = t.Key(r)
+	} else if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		submitter = host
+	}
+	ok, retryAfter, err := t.Store.Allow(r.Context(), "form:"+name+":"+submitter, t.Limit, t.Window)
+	if err != nil {
+		return fmt.Errorf("form: throttle: %w", err)
+	}
+	if !ok {
+		return &ThrottleError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// signRenderedAt returns the signed value of the renderedAtField of a form
+// rendered at t.
+func signRenderedAt(t time.Time) string {
+	ts := strconv.FormatInt(t.UnixMilli(), 10)
+	return ts + "." + renderedAtMAC(ts)
+}
+
+// parseRenderedAt returns the time signed in value, false when value is
+// not signed with antiSpamKey.
+func parseRenderedAt(value string) (time.Time, bool) {
+	ts, mac, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(mac), []byte(renderedAtMAC(ts))) {
+		return time.Time{}, false
+	}
+	ms, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(ms), true
+}
+
+func renderedAtMAC(ts string) string {
+	mac := hmac.New(sha256.New, antiSpamKey)
+	mac.Write([]byte(ts))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// antiSpamContextKey is the context key of the form of WithAntiSpam.
+type antiSpamContextKey struct{}
+
+// WithAntiSpam returns a context whose pages render the anti-spam fields of
+// f with ContextAntiSpamFields, e.g. the built-in registration and password
+// reset pages (see engine.Panel.WithAntiSpam).
+func WithAntiSpam(ctx context.Context, f *Form) context.Context {
+	return context.WithValue(ctx, antiSpamContextKey{}, f)
+}
+
+// AntiSpamFromContext returns the form of WithAntiSpam, or nil.
+func AntiSpamFromContext(ctx context.Context) *Form {
+	f, _ := ctx.Value(antiSpamContextKey{}).(*Form)
+	return f
+}
+
+// ContextAntiSpamFields renders the AntiSpamFields of the form of the
+// context, nothing without one.
+func ContextAntiSpamFields() templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		if f := AntiSpamFromContext(ctx); f != nil {
+			return f.AntiSpamFields().Render(ctx, w)
+		}
+		return nil
+	})
+}
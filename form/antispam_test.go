@@ -0,0 +1,99 @@
+package form
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingStore allows limit submissions per key.
+type countingStore struct {
+	counts map[string]int
+}
+
+func (s *countingStore) Allow(_ context.Context, key string, limit int, _ time.Duration) (bool, time.Duration, error) {
+	s.counts[key]++
+	if s.counts[key] > limit {
+		return false, time.Minute, nil
+	}
+	return true, 0, nil
+}
+
+func postForm(values url.Values) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(values.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestAntiSpamFields(t *testing.T) {
+	var b strings.Builder
+	if err := New().WithHoneypot("").WithMinFillTime(time.Second).AntiSpamFields().Render(context.Background(), &b); err != nil {
+		t.Fatal(err)
+	}
+	html := b.String()
+	for _, want := range []string{`name="website"`, `tabindex="-1"`, `name="_form_rendered_at"`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected %s in %s", want, html)
+		}
+	}
+
+	b.Reset()
+	if err := ContextAntiSpamFields().Render(context.Background(), &b); err != nil || b.Len() != 0 {
+		t.Errorf("expected nothing without a form in the context, got %q", b.String())
+	}
+}
+
+func TestCheckSubmission_Honeypot(t *testing.T) {
+	f := New().WithHoneypot("website")
+
+	if err := f.CheckSubmission(postForm(url.Values{"email": {"a@b.c"}, "website": {""}})); err != nil {
+		t.Errorf("expected an empty honeypot to pass, got %v", err)
+	}
+	if err := f.CheckSubmission(postForm(url.Values{"email": {"a@b.c"}, "website": {"http://spam"}})); !errors.Is(err, ErrSpam) {
+		t.Errorf("expected ErrSpam for a filled honeypot, got %v", err)
+	}
+}
+
+func TestCheckSubmission_MinFillTime(t *testing.T) {
+	f := New().WithMinFillTime(3 * time.Second)
+
+	tests := []struct {
+		name       string
+		renderedAt string
+		wantSpam   bool
+	}{
+		{"filled in time", signRenderedAt(time.Now().Add(-5 * time.Second)), false},
+		{"too fast", signRenderedAt(time.Now().Add(-time.Second)), true},
+		{"missing", "", true},
+		{"forged", "1000.forged", true},
+	}
+	for _, tt := range tests {
+		err := f.CheckSubmission(postForm(url.Values{renderedAtField: {tt.renderedAt}}))
+		if errors.Is(err, ErrSpam) != tt.wantSpam {
+			t.Errorf("%s: got %v", tt.name, err)
+		}
+	}
+}
+
+func TestCheckSubmission_Throttle(t *testing.T) {
+	store := &countingStore{counts: map[string]int{}}
+	f := New().WithThrottle(Throttle{Store: store, Limit: 2, Window: time.Hour})
+
+	for i := range 2 {
+		if err := f.CheckSubmission(postForm(nil)); err != nil {
+			t.Fatalf("submission %d: %v", i+1, err)
+		}
+	}
+	var throttled *ThrottleError
+	if err := f.CheckSubmission(postForm(nil)); !errors.As(err, &throttled) || throttled.RetryAfter != time.Minute {
+		t.Errorf("expected a ThrottleError, got %v", err)
+	}
+	if _, ok := store.counts["form:/register:192.0.2.1"]; !ok {
+		t.Errorf("expected the submissions counted per form and remote address, got %v", store.counts)
+	}
+}
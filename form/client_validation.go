@@ -0,0 +1,136 @@
+package form
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/validation"
+)
+
+// ClientInputType returns the type of an input of type inputType checking
+// rules: a text input with an email, url or numeric rule becomes an email,
+// url or number input.
+func ClientInputType(inputType string, rules []string) string {
+	if inputType != "text" {
+		return inputType
+	}
+	for _, rule := range parseRules(rules) {
+		switch rule.(type) {
+		case *validation.EmailRule:
+			return "email"
+		case *validation.URLRule:
+			return "url"
+		case *validation.NumericRule:
+			return "number"
+		}
+	}
+	return inputType
+}
+
+// ClientValidation returns the HTML5 validation attributes of the rules of
+// an input of type inputType, so the browser checks them as the user types:
+// required, minlength and maxlength (min and max for a number input) and
+// pattern. The server rules stay the source of truth (see Form.Validate);
+// the rules the browser cannot check are left to them.
+//
+// The inputs carrying attributes are marked data-client-validate: app.js
+// shows their validation message under them once they lose focus.
+func ClientValidation(inputType string, rules []string) templ.Attributes {
+	attrs := templ.Attributes{}
+	number := inputType == "number"
+	minAttr, maxAttr := "minlength", "maxlength"
+	if number {
+		minAttr, maxAttr = "min", "max"
+	}
+	for _, rule := range parseRules(rules) {
+		switch r := rule.(type) {
+		case *validation.RequiredRule:
+			attrs["required"] = true
+		case *validation.MinRule:
+			attrs[minAttr] = strconv.Itoa(r.Min)
+		case *validation.MaxRule:
+			attrs[maxAttr] = strconv.Itoa(r.Max)
+		case *validation.BetweenRule:
+			attrs[minAttr], attrs[maxAttr] = strconv.Itoa(r.Min), strconv.Itoa(r.Max)
+		case *validation.AlphaRule:
+			attrs["pattern"] = "[a-zA-Z]+"
+		case *validation.AlphaNumericRule:
+			attrs["pattern"] = "[a-zA-Z0-9]+"
+		case *validation.InRule:
+			if !number {
+				quoted := make([]string, len(r.Values))
+				for i, v := range r.Values {
+					quoted[i] = regexp.QuoteMeta(v)
+				}
+				attrs["pattern"] = strings.Join(quoted, "|")
+			}
+		case *validation.RegexRule:
+			if pattern, ok := clientPattern(r.Pattern); ok && !number {
+				attrs["pattern"] = pattern
+			}
+		}
+	}
+	if len(attrs) > 0 {
+		attrs["data-client-validate"] = true
+	}
+	return attrs
+}
+
+// clientPattern returns the pattern attribute matching like the Go regular
+// expression pattern, false when the browser may read pattern differently.
+// The pattern attribute must match the whole value, the rule only a part.
+func clientPattern(pattern string) (string, bool) {
+	if _, err := regexp.Compile(pattern); err != nil || strings.Contains(strings.ReplaceAll(pattern, "(?:", ""), "(?") ||
+		strings.Contains(pattern, `\z`) || strings.Contains(pattern, `\A`) {
+		return "", false
+	}
+	anchoredStart := strings.HasPrefix(pattern, "^")
+	anchoredEnd := strings.HasSuffix(pattern, "$") && !strings.HasSuffix(pattern, `\$`)
+	pattern = strings.TrimPrefix(pattern, "^")
+	if anchoredEnd {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+	pattern = "(?:" + pattern + ")"
+	if !anchoredStart {
+		pattern = ".*" + pattern
+	}
+	if !anchoredEnd {
+		pattern += ".*"
+	}
+	return pattern, true
+}
+
+// parseRules parses the rules of a field, each rule string holding one or
+// more rules separated by "|".
+func parseRules(rules []string) []validation.Rule {
+	var parsed []validation.Rule
+	for _, rule := range rules {
+		parsed = append(parsed, validation.ParseRules("", rule).Rules...)
+	}
+	return parsed
+}
+
+// InputType returns the type of the input, upgraded by its rules (see
+// ClientInputType).
+func (f *TextInput) InputType() string {
+	return ClientInputType(f.Type, f.fieldRules)
+}
+
+// ValidationAttributes returns the HTML5 validation attributes of the rules
+// of the field (see ClientValidation).
+func (b *BaseField) ValidationAttributes(inputType string) templ.Attributes {
+	return fieldValidation(b, inputType)
+}
+
+// fieldValidation returns the HTML5 validation attributes of f, an input
+// of type inputType, required when f is even without a required rule.
+func fieldValidation(f Field, inputType string) templ.Attributes {
+	attrs := ClientValidation(inputType, f.Rules())
+	if f.IsRequired() {
+		attrs["required"] = true
+		attrs["data-client-validate"] = true
+	}
+	return attrs
+}
@@ -0,0 +1,83 @@
+package form
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestClientValidation(t *testing.T) {
+	attrs := ClientValidation("text", []string{"required|min:3", "max:10"})
+	for key, want := range map[string]any{"required": true, "minlength": "3", "maxlength": "10", "data-client-validate": true} {
+		if attrs[key] != want {
+			t.Errorf("%s = %v, want %v", key, attrs[key], want)
+		}
+	}
+
+	attrs = ClientValidation("number", []string{"between:1,5"})
+	if attrs["min"] != "1" || attrs["max"] != "5" || attrs["minlength"] != nil {
+		t.Errorf("a number input should get min and max, got %v", attrs)
+	}
+
+	if attrs := ClientValidation("text", nil); len(attrs) != 0 {
+		t.Errorf("expected no attributes without rules, got %v", attrs)
+	}
+}
+
+func TestClientValidation_Pattern(t *testing.T) {
+	tests := []struct {
+		rule string
+		want any
+	}{
+		{"regex:^[A-Z]{3}$", "(?:[A-Z]{3})"},
+		{"regex:[0-9]", ".*(?:[0-9]).*"},
+		{"regex:(?i)^abc$", nil},
+		{"regex:^draft", "(?:draft).*"},
+		{"in:draft,published", "draft|published"},
+		{"alphanumeric", "[a-zA-Z0-9]+"},
+	}
+	for _, tt := range tests {
+		if got := ClientValidation("text", []string{tt.rule})["pattern"]; got != tt.want {
+			t.Errorf("%s: pattern = %v, want %v", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestClientInputType(t *testing.T) {
+	tests := []struct {
+		inputType string
+		rules     []string
+		want      string
+	}{
+		{"text", []string{"url"}, "url"},
+		{"text", []string{"required|numeric"}, "number"},
+		{"text", []string{"min:2"}, "text"},
+		{"password", []string{"numeric"}, "password"},
+	}
+	for _, tt := range tests {
+		if got := ClientInputType(tt.inputType, tt.rules); got != tt.want {
+			t.Errorf("ClientInputType(%s, %v) = %s, want %s", tt.inputType, tt.rules, got, tt.want)
+		}
+	}
+}
+
+func TestTextInput_WithRules(t *testing.T) {
+	f := Text("website").WithRules("required", "url", "max:200")
+	if !f.IsRequired() {
+		t.Error("a required rule should make the field required")
+	}
+
+	var b strings.Builder
+	if err := f.Render().Render(context.Background(), &b); err != nil {
+		t.Fatal(err)
+	}
+	html := b.String()
+	for _, want := range []string{`type="url"`, `maxlength="200"`, ` required`, `data-client-validate`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected %s in %s", want, html)
+		}
+	}
+	if strings.Count(html, " required") != 1 {
+		t.Errorf("expected a single required attribute in %s", html)
+	}
+}
@@ -3,10 +3,12 @@ package form
 import (
 	"fmt"
 	"html/template"
+	"net/http"
 	"sort"
 	"strings"
 
 	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/validation"
 )
 
 // BaseField contains common logic.
@@ -43,6 +45,16 @@ func (b *BaseField) GetPlaceholder() string { return b.fieldPlaceholder }
 func (b *BaseField) GetHelp() string        { return b.HelpText }
 func (b *BaseField) GetValueString() string { return b.ValueString() }
 
+// addRules adds rules to the field, required when one of them is.
+func (b *BaseField) addRules(rules []string) {
+	b.fieldRules = append(b.fieldRules, rules...)
+	for _, rule := range parseRules(rules) {
+		if _, ok := rule.(*validation.RequiredRule); ok {
+			b.Required = true
+		}
+	}
+}
+
 // RulesString returns the rules as a pipe-separated string for validation.
 func (b *BaseField) RulesString() string {
 	return strings.Join(b.fieldRules, "|")
@@ -134,6 +146,14 @@ func (f *TextInput) Required() *TextInput {
 	return f
 }
 
+// WithRules adds validation rules to the field, e.g. "min:3", "max:120" or
+// "regex:^[A-Z]{3}$" (see validation.ParseRules). Form.Validate checks them
+// and the input carries their HTML5 attributes (see ClientValidation).
+func (f *TextInput) WithRules(rules ...string) *TextInput {
+	f.addRules(rules)
+	return f
+}
+
 // Disabled disables the field.
 func (f *TextInput) Disabled() *TextInput {
 	f.BaseField.Disabled = true
@@ -190,6 +210,19 @@ func (t *TextareaInput) Required() *TextareaInput {
 	return t
 }
 
+// WithRules adds validation rules to the field, e.g. "min:20" or "max:500"
+// (see TextInput.WithRules).
+func (t *TextareaInput) WithRules(rules ...string) *TextareaInput {
+	t.addRules(rules)
+	return t
+}
+
+// Default sets the default value.
+func (t *TextareaInput) Default(val any) *TextareaInput {
+	t.fieldValue = val
+	return t
+}
+
 // SelectOption represents a select option.
 type SelectOption struct {
 	Label string
@@ -300,9 +333,11 @@ func (c *CheckboxInput) Default(val bool) *CheckboxInput {
 // FileUploadInput represents a file upload field.
 type FileUploadInput struct {
 	BaseField
-	AcceptTypes   string
-	MaxFileSize   int64
-	AllowMultiple bool
+	AcceptTypes    string
+	MaxFileSize    int64
+	AllowMultiple  bool
+	ImageOnly      bool
+	DimensionRules string // e.g. "min_width=200|max_height=1000"
 }
 
 func (f *FileUploadInput) Render() templ.Component { return FileUploadRender(f) }
@@ -345,6 +380,47 @@ func (f *FileUploadInput) Required() *FileUploadInput {
 	return f
 }
 
+// Image restricts uploads to images (checked on the sniffed content).
+func (f *FileUploadInput) Image() *FileUploadInput {
+	f.ImageOnly = true
+	if f.AcceptTypes == "" {
+		f.AcceptTypes = "image/*"
+	}
+	return f
+}
+
+// Dimensions sets image dimension constraints, separated by "|".
+// Example: Dimensions("min_width=200|max_width=2000").
+func (f *FileUploadInput) Dimensions(rules string) *FileUploadInput {
+	f.DimensionRules = rules
+	f.ImageOnly = true
+	return f
+}
+
+// FileRules returns the rule string understood by validation.ValidateFile.
+func (f *FileUploadInput) FileRules() string {
+	var rules []string
+	if f.ImageOnly {
+		rules = append(rules, "image")
+	}
+	if types := validation.AcceptToMIMETypes(f.AcceptTypes); len(types) > 0 {
+		rules = append(rules, "mimes="+strings.Join(types, "|"))
+	}
+	if f.MaxFileSize > 0 {
+		rules = append(rules, fmt.Sprintf("max=%d", f.MaxFileSize))
+	}
+	if f.DimensionRules != "" {
+		rules = append(rules, "dimensions="+f.DimensionRules)
+	}
+	return strings.Join(rules, ",")
+}
+
+// ValidateRequest validates the files uploaded for this field in a parsed
+// multipart request. Returns nil if the upload is valid.
+func (f *FileUploadInput) ValidateRequest(r *http.Request) validation.Errors {
+	return validation.ValidateRequestFiles(r, f.Name(), f.FileRules(), f.IsRequired())
+}
+
 // DatePicker represents a date/datetime input field.
 type DatePicker struct {
 	BaseField
@@ -2,6 +2,7 @@ package form
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/bozz33/sublimeadmin/validation"
 )
@@ -12,6 +13,8 @@ type Form struct {
 	Model  any
 	State  map[string]any
 	Errors map[string][]string
+
+	spam antiSpam // see WithHoneypot, WithMinFillTime and WithThrottle
 }
 
 // New creates a new form.
@@ -23,6 +26,12 @@ func New() *Form {
 	}
 }
 
+// Schema creates a form made of components, e.g. the modal form of an
+// action (see actions.Action.WithForm).
+func Schema(components ...Component) *Form {
+	return New().SetSchema(components...)
+}
+
 // SetSchema sets the form structure.
 func (f *Form) SetSchema(components ...Component) *Form {
 	f.Schema = components
@@ -79,6 +88,38 @@ func (f *Form) Validate(data map[string]any) bool {
 	return len(f.Errors) == 0
 }
 
+// ValidateFiles validates the files uploaded in r, a parsed multipart
+// request, for the FileUpload fields of the form, layouts included (see
+// FileUploadInput.ValidateRequest). Without requireUploads, e.g. when an
+// edited record keeps its files, a required field may be left empty. It
+// returns the first error of each invalid field, nil when every upload is
+// valid.
+func (f *Form) ValidateFiles(r *http.Request, requireUploads bool) FormErrors {
+	errs := FormErrors{}
+	validateFiles(r, f.Schema, requireUploads, errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// validateFiles adds to errs the errors of the uploads of components.
+func validateFiles(r *http.Request, components []Component, requireUploads bool, errs FormErrors) {
+	for _, component := range components {
+		switch c := component.(type) {
+		case *FileUploadInput:
+			if !requireUploads && (r.MultipartForm == nil || len(r.MultipartForm.File[c.Name()]) == 0) {
+				continue
+			}
+			if fieldErrs := c.ValidateRequest(r); fieldErrs != nil {
+				errs[c.Name()] = fieldErrs.Get(c.Name())
+			}
+		case interface{ GetSchema() []Component }:
+			validateFiles(r, c.GetSchema(), requireUploads, errs)
+		}
+	}
+}
+
 // GetValidationRules returns all validation rules as a map for use with validation.ValidateMap.
 func (f *Form) GetValidationRules() map[string]string {
 	rules := make(map[string]string)
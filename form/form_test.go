@@ -1,6 +1,10 @@
 package form
 
 import (
+	"context"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -168,6 +172,29 @@ func TestFileUpload(t *testing.T) {
 	}
 }
 
+func TestFileUploadRules(t *testing.T) {
+	field := FileUpload("avatar").
+		Image().
+		MaxSize(5 * 1024 * 1024).
+		Dimensions("min_width=200")
+
+	want := "image,mimes=image/*,max=5242880,dimensions=min_width=200"
+	if got := field.FileRules(); got != want {
+		t.Errorf("Expected rules '%s', got '%s'", want, got)
+	}
+
+	req := httptest.NewRequest("POST", "/", nil)
+	req.MultipartForm = &multipart.Form{}
+	if errs := field.ValidateRequest(req); errs != nil {
+		t.Errorf("Expected no errors for optional empty upload, got %v", errs)
+	}
+
+	field.Required()
+	if errs := field.ValidateRequest(req); !errs.Has("avatar") {
+		t.Error("Expected required error for missing upload")
+	}
+}
+
 func TestFieldVisibility(t *testing.T) {
 	field := Text("hidden_field")
 	field.Hidden = true
@@ -189,3 +216,24 @@ func TestFieldComponentType(t *testing.T) {
 		t.Errorf("Expected component type 'field', got '%s'", field.ComponentType())
 	}
 }
+
+func TestGridStackBelow(t *testing.T) {
+	render := func(c Component) string {
+		var sb strings.Builder
+		if err := c.Render().Render(context.Background(), &sb); err != nil {
+			t.Fatal(err)
+		}
+		return sb.String()
+	}
+
+	grid := NewGrid(3).SetSchema(Text("first_name"), Text("last_name"))
+	if html := render(grid); !strings.Contains(html, "grid-cols-3") || !strings.Contains(html, "grid-stack-md") {
+		t.Errorf("Expected a grid stacking below md by default, got %s", html)
+	}
+	if html := render(grid.StackedBelow(BreakpointNever)); strings.Contains(html, "grid-stack") {
+		t.Errorf("Expected a grid never stacking, got %s", html)
+	}
+	if html := render(NewSplit().WithRatio(2, 1).StackedBelow(BreakpointLG)); !strings.Contains(html, "grid-stack-lg") {
+		t.Errorf("Expected a split stacking below lg, got %s", html)
+	}
+}
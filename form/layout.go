@@ -59,10 +59,35 @@ func (s *Section) Render() templ.Component  { return SectionRender(s) }
 func (s *Section) Schema() []Component    { return s.Components }
 func (s *Section) GetSchema() []Component { return s.Components }
 
-// Grid represents a column grid.
+// Breakpoint is a screen width of the responsive layout, named after the
+// Tailwind screens (same values as table.Breakpoint).
+type Breakpoint string
+
+const (
+	BreakpointSM    Breakpoint = "sm"    // 640px
+	BreakpointMD    Breakpoint = "md"    // 768px (default)
+	BreakpointLG    Breakpoint = "lg"    // 1024px
+	BreakpointNever Breakpoint = "never" // never stack
+)
+
+// stackClass returns the class stacking a grid into one column below bp
+// (see custom.css), or "" for BreakpointNever. An empty bp means md.
+func stackClass(bp Breakpoint) string {
+	switch bp {
+	case BreakpointNever:
+		return ""
+	case BreakpointSM, BreakpointLG:
+		return "grid-stack-" + string(bp)
+	default:
+		return "grid-stack-md"
+	}
+}
+
+// Grid represents a column grid. Its columns stack below StackBelow.
 type Grid struct {
 	Columns    int
 	Components []Component
+	StackBelow Breakpoint // default: md
 }
 
 // NewGrid creates a new grid.
@@ -79,6 +104,13 @@ func (g *Grid) SetSchema(components ...Component) *Grid {
 	return g
 }
 
+// StackedBelow sets the screen width below which the columns stack
+// (BreakpointNever keeps them on every screen).
+func (g *Grid) StackedBelow(bp Breakpoint) *Grid {
+	g.StackBelow = bp
+	return g
+}
+
 // IsVisible returns true if the grid is visible.
 func (g *Grid) IsVisible() bool { return true }
 
@@ -370,6 +402,7 @@ type Split struct {
 	Right      []Component
 	LeftWidth  int // Tailwind col-span value for left column (default 1, right also 1 — equal)
 	RightWidth int
+	StackBelow Breakpoint // the columns stack below this width (default: md)
 }
 
 // NewSplit creates a new Split layout with equal columns.
@@ -401,6 +434,12 @@ func (s *Split) WithRatio(left, right int) *Split {
 	return s
 }
 
+// StackedBelow sets the screen width below which the columns stack.
+func (s *Split) StackedBelow(bp Breakpoint) *Split {
+	s.StackBelow = bp
+	return s
+}
+
 // IsVisible returns true.
 func (s *Split) IsVisible() bool { return true }
 
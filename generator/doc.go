@@ -23,6 +23,46 @@
 //	// Generate a complete resource (resource.go, table.go, form.go, schema.go)
 //	err = generator.GenerateResource(gen, "Product", projectPath)
 //
+// Generate a Resource with Relations:
+//
+//	author, _ := generator.ParseRelations(generator.RelationBelongsTo, "author:users")
+//	comments, _ := generator.ParseRelations(generator.RelationHasMany, "comments")
+//
+//	// Adds the engine relations, a select field per belongs-to, the Ent
+//	// edges and a relation_<name>.go manager per has-many/many-to-many
+//	err = generator.GenerateResourceWithRelations(gen, "Post", projectPath,
+//		append(author, comments...)...)
+//
+// Generate a Resource from an Existing Schema or Table:
+//
+//	// Columns, typed form fields and CRUD methods from internal/ent/schema/product.go
+//	err = generator.GenerateResourceFromSchema(gen, "Product", projectPath)
+//
+//	// The same from a live table, plus its Ent schema
+//	db, _ := sql.Open("sqlite", "file:app.db")
+//	err = generator.GenerateResourceFromTable(ctx, gen, "Product", projectPath, db, "sqlite", "products")
+//
+// Generate Tests:
+//
+// With Options.WithTests (--with-tests) each resource also gets a
+// resource_test.go: table-driven CRUD handler tests through
+// engine.NewCRUDHandler and httptest, and, for resources generated from a
+// schema or table, form binding and validation tests. They run against an
+// in-memory SQLite database (enttest) and skip until Create is implemented.
+//
+// Create a Project:
+//
+//	// The answers of sublimego new: module path, database, auth options
+//	data := generator.NewProjectData("shop")
+//	data.Module = "github.com/acme/shop"
+//	data.Driver = "postgres"
+//	data.DSN = generator.DefaultDSN("postgres", "shop")
+//	err = data.SetAdmin("admin@acme.test", password) // seeded admin user
+//
+//	// main.go, config.yaml, a Makefile running sublimego dev, migrations,
+//	// the users repository and an example resource
+//	files, err := generator.GenerateProject(gen, data, "shop")
+//
 // Generate a Custom Page:
 //
 //	// Generate a page with default options
@@ -37,6 +77,25 @@
 //	├── page.go         # Page struct with Render(), GetForm(), GetTable()
 //	└── content.templ   # Templ template for the page content
 //
+// Custom Templates:
+//
+// Templates in <project>/.sublimego/templates override the embedded ones by
+// name (resource.go.tmpl, form.go.tmpl, table.go.tmpl, schema.go.tmpl,
+// resource_test.go.tmpl, page.go.tmpl, page_templ.go.tmpl, widget.go.tmpl, action.go.tmpl,
+// enum.go.tmpl, relation_manager.go.tmpl). A template pack is a directory
+// of the same files, selected with Options.TemplatePack (--template-pack)
+// and winning over the project templates; named packs live in
+// .sublimego/packs/<name> or ~/.sublimego/packs/<name>. Files starting with
+// an underscore are partials shared by every template:
+//
+//	{{template "_header.go.tmpl" .}}
+//	package {{.PackageName}}
+//
+// PublishTemplates (sublimego templates:publish) copies the embedded
+// templates as a starting point. Resource templates receive a ResourceData
+// (.Name, .TypeName, .EntTypeName, .Slug, .Label, .Fields, .Relations...),
+// page templates a PageData; the functions are listed by TemplateFuncs.
+//
 // Page Features:
 //   - Full access to Form Builder (TextInput, Select, Checkbox, etc.)
 //   - Full access to Table Builder (columns, sorting, pagination)
@@ -4,7 +4,6 @@ import (
 	_ "embed"
 	"fmt"
 	"path/filepath"
-	"text/template"
 )
 
 //go:embed stubs/widget.go.tmpl
@@ -18,40 +17,19 @@ var enumTemplate string
 
 // registerExtraTemplates adds widget/action/enum templates to an existing Generator.
 func registerExtraTemplates(g *Generator) error {
-	funcMap := template.FuncMap{
-		"lower":  toLower,
-		"upper":  toUpper,
-		"pascal": ToPascalCase,
-		"snake":  ToSnakeCase,
-		"camel":  ToCamelCase,
-		"plural": Pluralize,
-	}
 	extras := map[string]string{
 		"widget": widgetTemplate,
 		"action": actionTemplate,
 		"enum":   enumTemplate,
 	}
 	for name, content := range extras {
-		tmpl, err := template.New(name).Funcs(funcMap).Parse(content)
-		if err != nil {
-			return fmt.Errorf("failed to parse template %s: %w", name, err)
+		if err := g.parse(name, content); err != nil {
+			return err
 		}
-		g.templates[name] = tmpl
 	}
 	return nil
 }
 
-func toLower(s string) string { return ToSnakeCase(s) }
-func toUpper(s string) string {
-	r := []byte(s)
-	for i, b := range r {
-		if b >= 'a' && b <= 'z' {
-			r[i] = b - 32
-		}
-	}
-	return string(r)
-}
-
 // GenerateWidget generates a widget file.
 func GenerateWidget(g *Generator, name, outputDir string) error {
 	if err := registerExtraTemplates(g); err != nil {
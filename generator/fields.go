@@ -0,0 +1,328 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Field kinds of FieldData, one per form input family.
+const (
+	FieldString = "string"
+	FieldText   = "text"
+	FieldInt    = "int"
+	FieldFloat  = "float"
+	FieldBool   = "bool"
+	FieldTime   = "time"
+	FieldEnum   = "enum"
+)
+
+// FieldData describes a field of a generated resource, read from an Ent
+// schema (FieldsFromSchema) or a database table (FieldsFromTable).
+type FieldData struct {
+	Name      string   // unit_price (column name)
+	GoName    string   // UnitPrice (Ent struct field)
+	Label     string   // Unit Price
+	Kind      string   // string, text, int, float, bool, time, enum
+	GoType    string   // float64
+	Required  bool     // not null and without default
+	NotEmpty  bool     // strings: rejects ""
+	Nillable  bool     // nullable column, *T in the Ent entity
+	Sensitive bool     // password-like, hidden from the table
+	Managed   bool     // set by the database or Ent hooks, hidden from the form
+	MaxLen    int      // varchar length
+	Values    []string // enum values
+}
+
+// NewFieldData creates a field of the given kind with its names and Go
+// type filled in.
+func NewFieldData(name, kind string) FieldData {
+	f := FieldData{
+		Name:   name,
+		GoName: entPascal(name),
+		Label:  ToLabel(name),
+		Kind:   kind,
+	}
+	switch kind {
+	case FieldInt:
+		f.GoType = "int"
+	case FieldFloat:
+		f.GoType = "float64"
+	case FieldBool:
+		f.GoType = "bool"
+	case FieldTime:
+		f.GoType = "time.Time"
+	default:
+		f.GoType = "string"
+	}
+	if isSensitiveName(name) {
+		f.Sensitive = true
+	}
+	return f
+}
+
+// Input returns the form input type of the field.
+func (f FieldData) Input() string {
+	switch {
+	case f.Sensitive:
+		return "password"
+	case f.Kind == FieldText:
+		return "textarea"
+	case f.Kind == FieldInt, f.Kind == FieldFloat:
+		return "number"
+	case f.Kind == FieldBool:
+		return "checkbox"
+	case f.Kind == FieldTime:
+		return "date"
+	case f.Kind == FieldEnum:
+		return "select"
+	case strings.Contains(f.Name, "email"):
+		return "email"
+	}
+	return "text"
+}
+
+// Column returns the table column type of the field.
+func (f FieldData) Column() string {
+	switch f.Kind {
+	case FieldBool:
+		return "boolean"
+	case FieldTime:
+		return "date"
+	case FieldEnum:
+		return "badge"
+	}
+	return "text"
+}
+
+// Searchable reports whether the table column is searchable.
+func (f FieldData) Searchable() bool {
+	return f.Kind == FieldString || f.Kind == FieldText || f.Kind == FieldEnum
+}
+
+// Access returns the expression reading the field from the entity e,
+// dereferencing nillable fields.
+func (f FieldData) Access() string {
+	if f.Nillable {
+		return "deref(e." + f.GoName + ")"
+	}
+	return "e." + f.GoName
+}
+
+// EntField returns the Ent schema builder of the field, such as
+// field.String("name").MaxLen(255).NotEmpty().
+func (f FieldData) EntField() string {
+	var b strings.Builder
+	switch f.Kind {
+	case FieldText:
+		fmt.Fprintf(&b, "field.Text(%q)", f.Name)
+	case FieldInt:
+		fmt.Fprintf(&b, "field.%s(%q)", ToPascalCase(f.GoType), f.Name)
+	case FieldFloat:
+		if f.GoType == "float32" {
+			fmt.Fprintf(&b, "field.Float32(%q)", f.Name)
+		} else {
+			fmt.Fprintf(&b, "field.Float(%q)", f.Name)
+		}
+	case FieldBool:
+		fmt.Fprintf(&b, "field.Bool(%q)", f.Name)
+	case FieldTime:
+		fmt.Fprintf(&b, "field.Time(%q)", f.Name)
+	case FieldEnum:
+		fmt.Fprintf(&b, "field.Enum(%q)", f.Name)
+		if len(f.Values) > 0 {
+			quoted := make([]string, len(f.Values))
+			for i, v := range f.Values {
+				quoted[i] = fmt.Sprintf("%q", v)
+			}
+			fmt.Fprintf(&b, ".Values(%s)", strings.Join(quoted, ", "))
+		}
+	default:
+		fmt.Fprintf(&b, "field.String(%q)", f.Name)
+		if f.MaxLen > 0 {
+			fmt.Fprintf(&b, ".MaxLen(%d)", f.MaxLen)
+		}
+		if f.NotEmpty {
+			b.WriteString(".NotEmpty()")
+		}
+	}
+	if !f.Required {
+		b.WriteString(".Optional()")
+	}
+	if f.Nillable {
+		b.WriteString(".Nillable()")
+	}
+	if f.Sensitive {
+		b.WriteString(".Sensitive()")
+	}
+	return b.String()
+}
+
+// SampleValue returns a valid form value for the field, used by the
+// generated tests.
+func (f FieldData) SampleValue() string {
+	switch f.Kind {
+	case FieldInt:
+		return "1"
+	case FieldFloat:
+		return "9.99"
+	case FieldBool:
+		return "on"
+	case FieldTime:
+		return "2024-01-31"
+	case FieldEnum:
+		if len(f.Values) > 0 {
+			return f.Values[0]
+		}
+	case FieldText:
+		return "Sample " + strings.ToLower(f.Label)
+	}
+	if f.Input() == "email" {
+		return "sample@example.com"
+	}
+	sample := "Sample " + strings.ToLower(f.Label)
+	if f.MaxLen > 0 && len(sample) > f.MaxLen {
+		sample = strings.Repeat("a", f.MaxLen)
+	}
+	return sample
+}
+
+// InvalidValue returns a form value the field fails to parse, or "" when
+// any value is accepted.
+func (f FieldData) InvalidValue() string {
+	switch f.Kind {
+	case FieldInt, FieldFloat:
+		return "not-a-number"
+	case FieldTime:
+		return "not-a-date"
+	}
+	return ""
+}
+
+// RejectsEmpty reports whether saving the field without a value fails.
+func (f FieldData) RejectsEmpty() bool {
+	switch f.Kind {
+	case FieldBool:
+		return false
+	case FieldString, FieldText:
+		return f.NotEmpty
+	}
+	return f.Required
+}
+
+// FormFields returns the fields shown in the form. Foreign keys of
+// belongs-to relations are left to their select.
+func (d *ResourceData) FormFields() []FieldData {
+	foreignKeys := make(map[string]bool)
+	for _, r := range d.BelongsTo() {
+		foreignKeys[r.ForeignKey] = true
+	}
+
+	var out []FieldData
+	for _, f := range d.Fields {
+		if !f.Managed && !foreignKeys[f.Name] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// TestFields returns the form fields exercised by the generated tests: the
+// form fields, or the name field of the default stubs.
+func (d *ResourceData) TestFields() []FieldData {
+	if len(d.Fields) > 0 {
+		return d.FormFields()
+	}
+	name := NewFieldData("name", FieldString)
+	name.Required, name.NotEmpty = true, true
+	return []FieldData{name}
+}
+
+// TableFields returns the fields shown as table columns.
+func (d *ResourceData) TableFields() []FieldData {
+	var out []FieldData
+	for _, f := range d.Fields {
+		if !f.Sensitive {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// HasKind reports whether a form field has the given kind.
+func (d *ResourceData) HasKind(kind string) bool {
+	for _, f := range d.FormFields() {
+		if f.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// HasNillable reports whether a field is nillable.
+func (d *ResourceData) HasNillable() bool {
+	for _, f := range d.Fields {
+		if f.Nillable {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsesValues reports whether the form values need parsing (numbers,
+// dates or foreign keys), which may fail.
+func (d *ResourceData) ParsesValues() bool {
+	if len(d.Fields) > 0 && len(d.BelongsTo()) > 0 {
+		return true
+	}
+	return d.HasKind(FieldInt) || d.HasKind(FieldFloat) || d.HasKind(FieldTime)
+}
+
+// EntPackage returns the package Ent generates for the entity, holding its
+// enum types.
+func (d *ResourceData) EntPackage() string {
+	return strings.ToLower(d.EntTypeName)
+}
+
+// ToLabel converts a column name to a label: "unit_price" gives "Unit Price".
+func ToLabel(s string) string {
+	words := strings.FieldsFunc(ToSnakeCase(s), func(r rune) bool { return r == '_' })
+	for i, w := range words {
+		if entAcronyms[w] {
+			words[i] = strings.ToUpper(w)
+		} else {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// entAcronyms are the words Ent writes in capitals in struct fields.
+var entAcronyms = map[string]bool{
+	"api": true, "html": true, "http": true, "https": true, "id": true,
+	"ip": true, "json": true, "sql": true, "ui": true, "uid": true,
+	"uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// entPascal converts a column name to the Ent struct field name:
+// "author_id" gives AuthorID.
+func entPascal(s string) string {
+	words := strings.FieldsFunc(ToSnakeCase(s), func(r rune) bool { return r == '_' })
+	var b strings.Builder
+	for _, w := range words {
+		if entAcronyms[w] {
+			b.WriteString(strings.ToUpper(w))
+		} else {
+			b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+		}
+	}
+	return b.String()
+}
+
+func isSensitiveName(name string) bool {
+	for _, s := range []string{"password", "secret", "token"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -12,8 +12,6 @@ import (
 	"time"
 
 	"github.com/samber/lo"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 )
 
 //go:embed stubs/resource.go.tmpl
@@ -28,6 +26,9 @@ var tableTemplate string
 //go:embed stubs/form.go.tmpl
 var formTemplate string
 
+//go:embed stubs/resource_test.go.tmpl
+var resourceTestTemplate string
+
 //go:embed stubs/page.go.tmpl
 var pageTemplate string
 
@@ -38,6 +39,7 @@ var pageTemplTemplate string
 type Generator struct {
 	templates map[string]*template.Template
 	options   *Options
+	overrides *overrides
 }
 
 // Options configures the generator behavior.
@@ -48,6 +50,17 @@ type Options struct {
 	NoBackup  bool
 	Verbose   bool
 	OutputDir string
+
+	// WithTests also generates table-driven tests (resource_test.go)
+	// for each resource.
+	WithTests bool
+
+	// TemplateDir overrides the embedded templates with its <name>.go.tmpl
+	// files. Defaults to <OutputDir>/.sublimego/templates when it exists.
+	TemplateDir string
+	// TemplatePack is a directory, or the name of a pack in
+	// .sublimego/packs, whose templates win over TemplateDir.
+	TemplatePack string
 }
 
 // New creates a new generator with embedded templates.
@@ -61,18 +74,11 @@ func New(opts *Options) (*Generator, error) {
 		options:   opts,
 	}
 
-	funcMap := template.FuncMap{
-		"lower":      strings.ToLower,
-		"upper":      strings.ToUpper,
-		"pascal":     ToPascalCase,
-		"snake":      ToSnakeCase,
-		"camel":      ToCamelCase,
-		"plural":     Pluralize,
-		"singular":   Singularize,
-		"title":      cases.Title(language.English).String,
-		"now":        time.Now,
-		"formatTime": func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+	o, err := loadOverrides(opts)
+	if err != nil {
+		return nil, err
 	}
+	g.overrides = o
 
 	templates := map[string]string{
 		"resource":   resourceTemplate,
@@ -84,11 +90,9 @@ func New(opts *Options) (*Generator, error) {
 	}
 
 	for name, content := range templates {
-		tmpl, err := template.New(name).Funcs(funcMap).Parse(content)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+		if err := g.parse(name, content); err != nil {
+			return nil, err
 		}
-		g.templates[name] = tmpl
 	}
 
 	return g, nil
@@ -145,6 +149,9 @@ func (g *Generator) Generate(templateName, outputPath string, data interface{})
 
 	if g.options.Verbose {
 		fmt.Printf("Generated: %s (%d bytes)\n", outputPath, len(formatted))
+		if source := g.TemplateSource(templateName); source != "embedded" {
+			fmt.Printf("   from template %s\n", source)
+		}
 	}
 
 	return nil
@@ -17,6 +17,16 @@ type ResourceData struct {
 	Label       string // User
 	PluralLabel string // Users
 	Icon        string // users
+
+	// Relations are set by GenerateResourceWithRelations.
+	Relations []RelationData
+	// Fields are set by GenerateResourceFromSchema and
+	// GenerateResourceFromTable; without them the stubs hold a single name
+	// field and commented examples.
+	Fields []FieldData
+
+	// fromSchema keeps the existing Ent schema instead of generating one.
+	fromSchema bool
 }
 
 // PageData contains the data to generate a custom page.
@@ -93,8 +103,12 @@ func NewPageDataWithOptions(name, group, icon string, sort int) *PageData {
 
 // GenerateResource generates all files for a resource.
 func GenerateResource(g *Generator, name, outputDir string) error {
-	data := NewResourceData(name)
+	return generateResource(g, NewResourceData(name), outputDir)
+}
 
+// generateResource generates the resource, schema, table and form files,
+// and the relation managers.
+func generateResource(g *Generator, data *ResourceData, outputDir string) error {
 	resourceDir := filepath.Join(outputDir, "internal", "resources", data.PackageName)
 
 	schemaDir := filepath.Join(outputDir, "internal", "ent", "schema")
@@ -105,6 +119,17 @@ func GenerateResource(g *Generator, name, outputDir string) error {
 		"table":    filepath.Join(resourceDir, "table.go"),
 		"form":     filepath.Join(resourceDir, "form.go"),
 	}
+	if data.fromSchema {
+		delete(files, "schema")
+	}
+	if g.options.WithTests {
+		if !g.HasTemplate("resource_test") {
+			if err := g.parse("resource_test", resourceTestTemplate); err != nil {
+				return err
+			}
+		}
+		files["resource_test"] = filepath.Join(resourceDir, "resource_test.go")
+	}
 
 	stats := struct {
 		Generated int
@@ -143,11 +168,18 @@ func GenerateResource(g *Generator, name, outputDir string) error {
 		}
 	}
 
-	return nil
+	return generateRelationManagers(g, data, resourceDir)
 }
 
-// GenerateMigration generates a migration file.
+// GenerateMigration generates a migration file with an Up and a Down
+// section, run by the migrate package (sublimego migrate).
 func GenerateMigration(name, outputDir string) error {
+	_, err := GenerateMigrationFile(name, outputDir)
+	return err
+}
+
+// GenerateMigrationFile generates a migration file and returns its path.
+func GenerateMigrationFile(name, outputDir string) (string, error) {
 	timestamp := fmt.Sprintf("%d", timeNow().Unix())
 	filename := fmt.Sprintf("%s_%s.sql", timestamp, ToSnakeCase(name))
 	outputPath := filepath.Join(outputDir, "migrations", filename)
@@ -155,6 +187,7 @@ func GenerateMigration(name, outputDir string) error {
 	content := fmt.Sprintf(`-- Migration: %s
 -- Created at: %s
 
+-- +migrate Up
 -- TODO: Add SQL commands here
 
 -- Example:
@@ -163,13 +196,22 @@ func GenerateMigration(name, outputDir string) error {
 --     name TEXT NOT NULL,
 --     created_at DATETIME DEFAULT CURRENT_TIMESTAMP
 -- );
-`, name, timeNow().Format("2006-01-02 15:04:05"), Pluralize(ToSnakeCase(name)))
 
+-- +migrate Down
+-- TODO: Revert the Up section here
+
+-- Example:
+-- DROP TABLE IF EXISTS %s;
+`, name, timeNow().Format("2006-01-02 15:04:05"), Pluralize(ToSnakeCase(name)), Pluralize(ToSnakeCase(name)))
+
+	if fileExists(outputPath) {
+		return "", fmt.Errorf("file already exists: %s", outputPath)
+	}
 	if err := ensureDir(filepath.Dir(outputPath)); err != nil {
-		return err
+		return "", err
 	}
 
-	return writeFile(outputPath, []byte(content))
+	return outputPath, writeFile(outputPath, []byte(content))
 }
 
 // GeneratePage generates all files for a custom page.
@@ -233,7 +275,8 @@ func GeneratePageWithOptions(g *Generator, name, outputDir, group, icon string,
 	return nil
 }
 
-// GenerateSeeder generates a seeder file.
+// GenerateSeeder generates a Go seeder file, registered with
+// migrate.RegisterSeeder and run by migrate.Seed from the application.
 func GenerateSeeder(name, outputDir string) error {
 	packageName := ToSnakeCase(name)
 	typeName := ToPascalCase(name)
@@ -246,8 +289,14 @@ import (
 	"context"
 	"database/sql"
 	"log"
+
+	"github.com/bozz33/sublimeadmin/migrate"
 )
 
+func init() {
+	migrate.RegisterSeeder(%q, Seed%s)
+}
+
 // Seed%s inserts test data for %s.
 // db is a *sql.DB — replace with your own ORM client if needed.
 func Seed%s(ctx context.Context, db *sql.DB) error {
@@ -260,7 +309,7 @@ func Seed%s(ctx context.Context, db *sql.DB) error {
 	log.Println("%s seeded successfully")
 	return nil
 }
-`, typeName, name, typeName, name, typeName)
+`, packageName, typeName, typeName, name, typeName, name, typeName)
 
 	if err := ensureDir(filepath.Dir(outputPath)); err != nil {
 		return err
@@ -269,6 +318,30 @@ func Seed%s(ctx context.Context, db *sql.DB) error {
 	return writeFile(outputPath, []byte(content))
 }
 
+// GenerateSQLSeeder generates a SQL seeder file, run by sublimego db:seed
+// in file name order, and returns its path.
+func GenerateSQLSeeder(name, outputDir string) (string, error) {
+	timestamp := fmt.Sprintf("%d", timeNow().Unix())
+	filename := fmt.Sprintf("%s_%s.sql", timestamp, ToSnakeCase(name))
+	outputPath := filepath.Join(outputDir, "seeders", filename)
+
+	content := fmt.Sprintf(`-- Seeder: %s
+-- Created at: %s
+
+-- TODO: Add INSERT statements here, one per line ending with a semicolon
+
+-- Example:
+-- INSERT INTO %s (name) VALUES ('First');
+-- INSERT INTO %s (name) VALUES ('Second');
+`, name, timeNow().Format("2006-01-02 15:04:05"), Pluralize(ToSnakeCase(name)), Pluralize(ToSnakeCase(name)))
+
+	if err := ensureDir(filepath.Dir(outputPath)); err != nil {
+		return "", err
+	}
+
+	return outputPath, writeFile(outputPath, []byte(content))
+}
+
 // Internal helpers
 
 func ensureDir(dir string) error {
@@ -0,0 +1,361 @@
+package generator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FieldsFromSchema reads the fields of the Ent schema typeName (such as
+// Product) from the Go files of schemaDir, usually internal/ent/schema.
+// JSON, bytes and UUID fields are skipped.
+func FieldsFromSchema(schemaDir, typeName string) ([]FieldData, error) {
+	paths, err := filepath.Glob(filepath.Join(schemaDir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	for _, path := range paths {
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if fields, ok := schemaFields(file, typeName); ok {
+			return fields, nil
+		}
+	}
+	return nil, fmt.Errorf("schema %s not found in %s", typeName, schemaDir)
+}
+
+// schemaFields reads the Fields method of typeName in file.
+func schemaFields(file *ast.File, typeName string) ([]FieldData, bool) {
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != "Fields" || fn.Recv == nil || fn.Body == nil || receiverName(fn) != typeName {
+			continue
+		}
+
+		var fields []FieldData
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			ret, ok := n.(*ast.ReturnStmt)
+			if !ok || len(ret.Results) != 1 {
+				return true
+			}
+			lit, ok := ret.Results[0].(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+			for _, elt := range lit.Elts {
+				if f, ok := schemaField(elt); ok {
+					fields = append(fields, f)
+				}
+			}
+			return false
+		})
+		return fields, true
+	}
+	return nil, false
+}
+
+func receiverName(fn *ast.FuncDecl) string {
+	expr := fn.Recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// schemaField reads a field.Kind("name").Modifier()... chain.
+func schemaField(expr ast.Expr) (FieldData, bool) {
+	type call struct {
+		name string
+		args []ast.Expr
+	}
+
+	// Unwind the chain from the last modifier to field.Kind("name").
+	var chain []call
+	for {
+		c, ok := expr.(*ast.CallExpr)
+		if !ok {
+			return FieldData{}, false
+		}
+		sel, ok := c.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return FieldData{}, false
+		}
+		chain = append(chain, call{sel.Sel.Name, c.Args})
+		if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "field" {
+			break
+		}
+		expr = sel.X
+	}
+
+	root := chain[len(chain)-1]
+	if len(root.args) == 0 {
+		return FieldData{}, false
+	}
+	name, ok := stringLit(root.args[0])
+	if !ok {
+		return FieldData{}, false
+	}
+
+	var f FieldData
+	switch root.name {
+	case "String":
+		f = NewFieldData(name, FieldString)
+	case "Text":
+		f = NewFieldData(name, FieldText)
+	case "Bool":
+		f = NewFieldData(name, FieldBool)
+	case "Time":
+		f = NewFieldData(name, FieldTime)
+	case "Enum":
+		f = NewFieldData(name, FieldEnum)
+	case "Float", "Float32":
+		f = NewFieldData(name, FieldFloat)
+		if root.name == "Float32" {
+			f.GoType = "float32"
+		}
+	case "Int", "Int8", "Int16", "Int32", "Int64", "Uint", "Uint8", "Uint16", "Uint32", "Uint64":
+		f = NewFieldData(name, FieldInt)
+		f.GoType = strings.ToLower(root.name)
+	default:
+		return FieldData{}, false
+	}
+
+	required := true
+	for _, c := range chain[:len(chain)-1] {
+		switch c.name {
+		case "Optional":
+			required = false
+		case "Default", "DefaultFunc":
+			required = false
+			if f.Kind == FieldTime {
+				f.Managed = true
+			}
+		case "UpdateDefault", "Immutable":
+			f.Managed = true
+		case "NotEmpty", "MinLen":
+			f.NotEmpty = true
+		case "Nillable":
+			f.Nillable = true
+		case "Sensitive":
+			f.Sensitive = true
+		case "MaxLen":
+			if len(c.args) == 1 {
+				if lit, ok := c.args[0].(*ast.BasicLit); ok {
+					f.MaxLen, _ = strconv.Atoi(lit.Value)
+				}
+			}
+		case "Values":
+			for _, arg := range c.args {
+				if v, ok := stringLit(arg); ok {
+					f.Values = append(f.Values, v)
+				}
+			}
+		}
+	}
+	// Booleans are never required: an unchecked box is false.
+	f.Required = required && f.Kind != FieldBool
+	return f, true
+}
+
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// tableColumn is a column read from the database catalog.
+type tableColumn struct {
+	name       string
+	typ        string
+	nullable   bool
+	hasDefault bool
+}
+
+// FieldsFromTable reads the fields of a database table. dialect is the
+// config database driver: sqlite, sqlite3, postgres or mysql. The id
+// column is skipped, and created_at/updated_at are kept out of the form.
+//
+//	db, _ := sql.Open("sqlite", "file:app.db")
+//	fields, err := generator.FieldsFromTable(ctx, db, "sqlite", "products")
+func FieldsFromTable(ctx context.Context, db *sql.DB, dialect, table string) ([]FieldData, error) {
+	var (
+		columns []tableColumn
+		err     error
+	)
+	switch dialect {
+	case "sqlite", "sqlite3":
+		columns, err = sqliteColumns(ctx, db, table)
+	case "postgres", "pgx":
+		columns, err = catalogColumns(ctx, db, `
+			SELECT column_name,
+				CASE WHEN character_maximum_length IS NULL THEN data_type
+				ELSE data_type || '(' || character_maximum_length || ')' END,
+				is_nullable = 'YES', column_default IS NOT NULL
+			FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = $1
+			ORDER BY ordinal_position`, table)
+	case "mysql":
+		columns, err = catalogColumns(ctx, db, `
+			SELECT column_name, column_type, is_nullable = 'YES', column_default IS NOT NULL
+			FROM information_schema.columns
+			WHERE table_schema = DATABASE() AND table_name = ?
+			ORDER BY ordinal_position`, table)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", dialect)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table %s: %w", table, err)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s not found or has no columns", table)
+	}
+
+	var fields []FieldData
+	for _, col := range columns {
+		if col.name == "id" {
+			continue
+		}
+		fields = append(fields, fieldFromColumn(col))
+	}
+	return fields, nil
+}
+
+func sqliteColumns(ctx context.Context, db *sql.DB, table string) ([]tableColumn, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", strconv.Quote(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []tableColumn
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, typ        string
+			dflt             sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+			return nil, err
+		}
+		columns = append(columns, tableColumn{
+			name:       name,
+			typ:        typ,
+			nullable:   notNull == 0 && pk == 0,
+			hasDefault: dflt.Valid,
+		})
+	}
+	return columns, rows.Err()
+}
+
+func catalogColumns(ctx context.Context, db *sql.DB, query, table string) ([]tableColumn, error) {
+	rows, err := db.QueryContext(ctx, query, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []tableColumn
+	for rows.Next() {
+		var col tableColumn
+		if err := rows.Scan(&col.name, &col.typ, &col.nullable, &col.hasDefault); err != nil {
+			return nil, err
+		}
+		columns = append(columns, col)
+	}
+	return columns, rows.Err()
+}
+
+var (
+	sizePattern = regexp.MustCompile(`\((\d+)\)`)
+	enumPattern = regexp.MustCompile(`'((?:[^']|'')*)'`)
+)
+
+// fieldFromColumn maps a SQL column type to a field.
+func fieldFromColumn(col tableColumn) FieldData {
+	typ := strings.ToLower(strings.TrimSpace(col.typ))
+
+	var f FieldData
+	switch {
+	case strings.HasPrefix(typ, "enum("):
+		f = NewFieldData(col.name, FieldEnum)
+		for _, m := range enumPattern.FindAllStringSubmatch(typ, -1) {
+			f.Values = append(f.Values, strings.ReplaceAll(m[1], "''", "'"))
+		}
+	case strings.HasPrefix(typ, "bool"), typ == "tinyint(1)", typ == "bit(1)":
+		f = NewFieldData(col.name, FieldBool)
+	case strings.Contains(typ, "int"):
+		f = NewFieldData(col.name, FieldInt)
+		if strings.Contains(typ, "bigint") {
+			f.GoType = "int64"
+		}
+	case strings.Contains(typ, "real"), strings.Contains(typ, "floa"), strings.Contains(typ, "doub"),
+		strings.Contains(typ, "numeric"), strings.Contains(typ, "decimal"):
+		f = NewFieldData(col.name, FieldFloat)
+	case strings.Contains(typ, "date"), strings.Contains(typ, "time"):
+		f = NewFieldData(col.name, FieldTime)
+	case strings.Contains(typ, "text"), strings.Contains(typ, "clob"):
+		f = NewFieldData(col.name, FieldText)
+	default:
+		f = NewFieldData(col.name, FieldString)
+		if m := sizePattern.FindStringSubmatch(typ); m != nil {
+			f.MaxLen, _ = strconv.Atoi(m[1])
+		}
+	}
+
+	f.Nillable = col.nullable
+	f.Required = !col.nullable && !col.hasDefault && f.Kind != FieldBool
+	f.NotEmpty = f.Required && f.Kind == FieldString
+	if f.Kind == FieldTime && (col.hasDefault || col.name == "created_at" || col.name == "updated_at") {
+		f.Managed = true
+	}
+	return f
+}
+
+// GenerateResourceFromSchema generates a resource from its existing Ent
+// schema in outputDir/internal/ent/schema: the table columns, the typed
+// form fields and the CRUD methods. The schema itself is left untouched.
+//
+//	err := generator.GenerateResourceFromSchema(gen, "Product", ".")
+func GenerateResourceFromSchema(g *Generator, name, outputDir string, relations ...RelationData) error {
+	data := NewResourceData(name)
+	fields, err := FieldsFromSchema(filepath.Join(outputDir, "internal", "ent", "schema"), data.EntTypeName)
+	if err != nil {
+		return err
+	}
+	data.Fields = fields
+	data.Relations = relations
+	data.fromSchema = true
+	return generateResource(g, data, outputDir)
+}
+
+// GenerateResourceFromTable generates a resource, and its Ent schema,
+// from a live database table.
+//
+//	err := generator.GenerateResourceFromTable(ctx, gen, "Product", ".", db, "postgres", "products")
+func GenerateResourceFromTable(ctx context.Context, g *Generator, name, outputDir string, db *sql.DB, dialect, table string, relations ...RelationData) error {
+	fields, err := FieldsFromTable(ctx, db, dialect, table)
+	if err != nil {
+		return err
+	}
+	data := NewResourceData(name)
+	data.Fields = fields
+	data.Relations = relations
+	return generateResource(g, data, outputDir)
+}
@@ -0,0 +1,186 @@
+package generator
+
+import (
+	_ "embed"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+//go:embed stubs/relation_manager.go.tmpl
+var relationManagerTemplate string
+
+// Relation kinds accepted by ParseRelation, matching engine.RelationType.
+const (
+	RelationBelongsTo  = "belongs_to"
+	RelationHasOne     = "has_one"
+	RelationHasMany    = "has_many"
+	RelationManyToMany = "many_to_many"
+)
+
+// RelationData describes a relation of a generated resource.
+type RelationData struct {
+	Kind        string // belongs_to, has_one, has_many, many_to_many
+	Name        string // author
+	FieldName   string // Author
+	RelatedSlug string // users
+	RelatedType string // User (Ent type)
+	ForeignKey  string // author_id (belongs_to only)
+	ManagerType string // CommentsRelationManager (has_many and many_to_many)
+	Label       string // Comments
+}
+
+// IsBelongsTo reports whether the relation is a belongs-to.
+func (r RelationData) IsBelongsTo() bool { return r.Kind == RelationBelongsTo }
+
+// HasManager reports whether the relation gets a RelationManager (a tab on
+// the edit page listing the related records).
+func (r RelationData) HasManager() bool {
+	return r.Kind == RelationHasMany || r.Kind == RelationManyToMany
+}
+
+// EngineBuilder returns the engine constructor of the relation kind.
+func (r RelationData) EngineBuilder() string {
+	switch r.Kind {
+	case RelationBelongsTo:
+		return "BelongsTo"
+	case RelationHasOne:
+		return "HasOne"
+	case RelationManyToMany:
+		return "ManyToMany"
+	default:
+		return "HasMany"
+	}
+}
+
+// EngineType returns the engine.RelationType constant of the relation kind.
+func (r RelationData) EngineType() string {
+	return "engine.Relation" + r.EngineBuilder()
+}
+
+// ParseRelation parses a relation flag value: "author:users" is the
+// relation author to the users resource; the slug defaults to the plural
+// of the name ("comments" gives comments, "author" gives authors).
+//
+//	rel, err := generator.ParseRelation(generator.RelationBelongsTo, "author:users")
+func ParseRelation(kind, spec string) (RelationData, error) {
+	switch kind {
+	case RelationBelongsTo, RelationHasOne, RelationHasMany, RelationManyToMany:
+	default:
+		return RelationData{}, fmt.Errorf("unknown relation kind %q", kind)
+	}
+
+	name, slug, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	name = ToSnakeCase(strings.TrimSpace(name))
+	slug = strings.TrimSpace(slug)
+	if name == "" {
+		return RelationData{}, fmt.Errorf("invalid %s relation %q: expected name[:slug]", kind, spec)
+	}
+	if slug == "" {
+		slug = name
+		if kind == RelationBelongsTo || kind == RelationHasOne {
+			slug = Pluralize(name)
+		}
+	}
+
+	rel := RelationData{
+		Kind:        kind,
+		Name:        name,
+		FieldName:   ToPascalCase(name),
+		RelatedSlug: slug,
+		RelatedType: ToPascalCase(Singularize(slug)),
+		Label:       ToPascalCase(name),
+	}
+	if kind == RelationBelongsTo {
+		rel.ForeignKey = name + "_id"
+	}
+	if rel.HasManager() {
+		rel.ManagerType = rel.FieldName + "RelationManager"
+	}
+	return rel, nil
+}
+
+// ParseRelations parses comma-separated relation flag values of one kind.
+func ParseRelations(kind string, values ...string) ([]RelationData, error) {
+	var relations []RelationData
+	for _, value := range values {
+		for _, spec := range strings.Split(value, ",") {
+			if strings.TrimSpace(spec) == "" {
+				continue
+			}
+			rel, err := ParseRelation(kind, spec)
+			if err != nil {
+				return nil, err
+			}
+			relations = append(relations, rel)
+		}
+	}
+	return relations, nil
+}
+
+// BelongsTo returns the belongs-to relations of the resource.
+func (d *ResourceData) BelongsTo() []RelationData {
+	var out []RelationData
+	for _, r := range d.Relations {
+		if r.IsBelongsTo() {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Managers returns the relations that get a RelationManager.
+func (d *ResourceData) Managers() []RelationData {
+	var out []RelationData
+	for _, r := range d.Relations {
+		if r.HasManager() {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// GenerateResourceWithRelations generates a resource with its relations:
+// the Relation definitions and BelongsTo selects of the resource, Ent
+// schema edges, and one RelationManager stub per has-many or many-to-many
+// relation.
+//
+//	author, _ := generator.ParseRelation(generator.RelationBelongsTo, "author:users")
+//	comments, _ := generator.ParseRelation(generator.RelationHasMany, "comments")
+//	err := generator.GenerateResourceWithRelations(gen, "Post", ".", author, comments)
+func GenerateResourceWithRelations(g *Generator, name, outputDir string, relations ...RelationData) error {
+	data := NewResourceData(name)
+	data.Relations = relations
+	return generateResource(g, data, outputDir)
+}
+
+// generateRelationManagers writes relation_<name>.go for each relation
+// with a manager.
+func generateRelationManagers(g *Generator, data *ResourceData, resourceDir string) error {
+	managers := data.Managers()
+	if len(managers) == 0 || g.shouldSkip("relation_manager") {
+		return nil
+	}
+	if err := registerRelationTemplates(g); err != nil {
+		return err
+	}
+
+	for _, rel := range managers {
+		outputPath := filepath.Join(resourceDir, "relation_"+rel.Name+".go")
+		if err := g.Generate("relation_manager", outputPath, map[string]any{
+			"Resource": data,
+			"Relation": rel,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerRelationTemplates adds the relation manager template.
+func registerRelationTemplates(g *Generator) error {
+	if g.HasTemplate("relation_manager") {
+		return nil
+	}
+	return g.parse("relation_manager", relationManagerTemplate)
+}
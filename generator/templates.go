@@ -0,0 +1,247 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// ProjectTemplateDir is the directory, relative to the project root, whose
+// templates override the embedded ones.
+const ProjectTemplateDir = ".sublimego/templates"
+
+// ProjectPackDir is the directory, relative to the project root or to the
+// home directory, holding the named template packs.
+const ProjectPackDir = ".sublimego/packs"
+
+// templateExt is the extension of template files: resource.go.tmpl
+// overrides the resource template.
+const templateExt = ".go.tmpl"
+
+// TemplateFuncs returns the functions available to the templates:
+//
+//	lower       strings.ToLower            {{.Label | lower}}
+//	upper       strings.ToUpper
+//	pascal      ToPascalCase ("blog_post" gives BlogPost)
+//	snake       ToSnakeCase ("BlogPost" gives blog_post)
+//	camel       ToCamelCase ("blog_post" gives blogPost)
+//	plural      Pluralize ("category" gives categories)
+//	singular    Singularize
+//	title       title case ("hello world" gives Hello World)
+//	label       ToLabel ("unit_price" gives Unit Price)
+//	now         time.Now
+//	formatTime  formats a time as 2006-01-02 15:04:05
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"pascal":     ToPascalCase,
+		"snake":      ToSnakeCase,
+		"camel":      ToCamelCase,
+		"plural":     Pluralize,
+		"singular":   Singularize,
+		"title":      cases.Title(language.English).String,
+		"label":      ToLabel,
+		"now":        time.Now,
+		"formatTime": func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+	}
+}
+
+// embeddedTemplates returns the embedded templates by name.
+func embeddedTemplates() map[string]string {
+	return map[string]string{
+		"resource":         resourceTemplate,
+		"resource_test":    resourceTestTemplate,
+		"schema":           schemaTemplate,
+		"table":            tableTemplate,
+		"form":             formTemplate,
+		"page":             pageTemplate,
+		"page_templ":       pageTemplTemplate,
+		"widget":           widgetTemplate,
+		"action":           actionTemplate,
+		"enum":             enumTemplate,
+		"relation_manager": relationManagerTemplate,
+	}
+}
+
+// TemplateNames returns the names of the templates that can be overridden.
+func TemplateNames() []string {
+	names := make([]string, 0, len(embeddedTemplates()))
+	for name := range embeddedTemplates() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// overrides holds the templates read from the project directory and the
+// template pack.
+type overrides struct {
+	templates map[string]string // name -> content
+	sources   map[string]string // name -> file path
+	partials  map[string]string // _header.go.tmpl -> content
+}
+
+// loadOverrides reads the project templates, then the template pack, which
+// wins over them. Files starting with an underscore are partials, available
+// to every template with {{template "_header.go.tmpl" .}}.
+func loadOverrides(opts *Options) (*overrides, error) {
+	o := &overrides{
+		templates: make(map[string]string),
+		sources:   make(map[string]string),
+		partials:  make(map[string]string),
+	}
+
+	root := opts.OutputDir
+	if root == "" {
+		root = "."
+	}
+
+	dir := opts.TemplateDir
+	if dir == "" {
+		dir = filepath.Join(root, ProjectTemplateDir)
+	}
+	if err := o.read(dir, opts.TemplateDir != ""); err != nil {
+		return nil, err
+	}
+
+	if opts.TemplatePack != "" {
+		packDir, err := ResolveTemplatePack(root, opts.TemplatePack)
+		if err != nil {
+			return nil, err
+		}
+		if err := o.read(packDir, true); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// read reads the templates of dir; a missing dir is an error only when
+// required.
+func (o *overrides) read(dir string, required bool) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) && !required {
+			return nil
+		}
+		return fmt.Errorf("failed to read templates: %w", err)
+	}
+
+	known := embeddedTemplates()
+	for _, entry := range entries {
+		file := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(file, ".tmpl") {
+			continue
+		}
+		path := filepath.Join(dir, file)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read template: %w", err)
+		}
+
+		if strings.HasPrefix(file, "_") {
+			o.partials[file] = string(content)
+			continue
+		}
+
+		name := strings.TrimSuffix(strings.TrimSuffix(file, ".tmpl"), ".go")
+		if _, ok := known[name]; !ok {
+			return fmt.Errorf("unknown template %s (expected one of %s)", path, strings.Join(TemplateNames(), ", "))
+		}
+		o.templates[name] = string(content)
+		o.sources[name] = path
+	}
+	return nil
+}
+
+// ResolveTemplatePack returns the directory of a template pack: pack is a
+// directory, or the name of a pack in <root>/.sublimego/packs or
+// ~/.sublimego/packs.
+func ResolveTemplatePack(root, pack string) (string, error) {
+	candidates := []string{pack, filepath.Join(root, ProjectPackDir, pack)}
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, filepath.Join(home, ProjectPackDir, pack))
+	}
+
+	for _, dir := range candidates {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("template pack %q not found (looked in %s)", pack, strings.Join(candidates, ", "))
+}
+
+// parse parses a template, preferring its override, with the partials.
+func (g *Generator) parse(name, content string) error {
+	source := "embedded"
+	if g.overrides != nil {
+		if override, ok := g.overrides.templates[name]; ok {
+			content, source = override, g.overrides.sources[name]
+		}
+	}
+
+	tmpl, err := template.New(name).Funcs(TemplateFuncs()).Parse(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse template %s (%s): %w", name, source, err)
+	}
+	if g.overrides != nil {
+		for partial, body := range g.overrides.partials {
+			if _, err := tmpl.New(partial).Parse(body); err != nil {
+				return fmt.Errorf("failed to parse template %s: %w", partial, err)
+			}
+		}
+	}
+
+	g.templates[name] = tmpl
+	return nil
+}
+
+// TemplateSource returns where a template comes from: the path of its
+// override, or "embedded".
+func (g *Generator) TemplateSource(name string) string {
+	if g.overrides != nil {
+		if path, ok := g.overrides.sources[name]; ok {
+			return path
+		}
+	}
+	return "embedded"
+}
+
+// PublishTemplates copies the embedded templates to dir, usually
+// <project>/.sublimego/templates, to be customized. Without names every
+// template is published; existing files are kept unless force is set.
+func PublishTemplates(dir string, force bool, names ...string) ([]string, error) {
+	if len(names) == 0 {
+		names = TemplateNames()
+	}
+
+	known := embeddedTemplates()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	var written []string
+	for _, name := range names {
+		content, ok := known[name]
+		if !ok {
+			return written, fmt.Errorf("unknown template %q (expected one of %s)", name, strings.Join(TemplateNames(), ", "))
+		}
+		path := filepath.Join(dir, name+templateExt)
+		if fileExists(path) && !force {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return written, fmt.Errorf("failed to write file: %w", err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
@@ -0,0 +1,48 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/bozz33/sublimeadmin/jobs"
+)
+
+// Pinger is a dependency answering pings, like *sql.DB.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Ping checks that p answers a ping, e.g. a database:
+//
+//	checks.Register("db", health.Ping(db))
+func Ping(p Pinger) Check {
+	return func(ctx context.Context) error {
+		return p.PingContext(ctx)
+	}
+}
+
+// Dial checks that addr accepts connections, e.g. an SMTP server or a
+// cache:
+//
+//	checks.Register("redis", health.Dial("tcp", "localhost:6379"))
+func Dial(network, addr string) Check {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, network, addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// QueueDepth checks that no more than max jobs of q wait for a worker.
+func QueueDepth(q *jobs.Queue, max int) Check {
+	return func(ctx context.Context) error {
+		if n := q.CountByStatus(jobs.StatusPending); n > max {
+			return fmt.Errorf("%d pending jobs, more than %d", n, max)
+		}
+		return nil
+	}
+}
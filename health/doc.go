@@ -0,0 +1,24 @@
+// Package health serves liveness and readiness probes backed by a registry
+// of dependency checks.
+//
+// /healthz answers whether the process is alive: only the checks registered
+// with Liveness run. /readyz answers whether it can serve traffic: every
+// check runs. Both answer 200 with a JSON report when the checks pass, 503
+// when one fails, so Kubernetes probes need no custom code.
+//
+// Features:
+//   - Checks run concurrently, each with its own timeout
+//   - Failure thresholds: a check fails after N consecutive failures
+//   - Built-in checks: database ping, TCP dial (SMTP, cache), job queue depth
+//   - JSON report with the status, duration and error of each check
+//
+// Basic usage:
+//
+//	checks := health.NewRegistry()
+//	checks.Register("db", health.Ping(db))
+//	checks.Register("smtp", health.Dial("tcp", "smtp.example.com:587"), health.FailureThreshold(3))
+//	checks.Register("jobs", health.QueueDepth(queue, 1000))
+//
+//	mux.Handle("/healthz", checks.LivenessHandler())
+//	mux.Handle("/readyz", checks.ReadinessHandler())
+package health
@@ -0,0 +1,214 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds a check registered without a Timeout.
+const DefaultTimeout = 5 * time.Second
+
+// Check reports an error when a dependency is unavailable. It must return
+// when ctx is done.
+type Check func(ctx context.Context) error
+
+// Status is the status of a check or of a report.
+type Status string
+
+const (
+	// StatusOK means the check passed.
+	StatusOK Status = "ok"
+	// StatusDegraded means the check failed fewer times in a row than its
+	// failure threshold: the probe still passes.
+	StatusDegraded Status = "degraded"
+	// StatusFail means the check failed its failure threshold times in a
+	// row: the probe fails.
+	StatusFail Status = "fail"
+)
+
+// Option configures a registered check.
+type Option func(*entry)
+
+// Timeout bounds a run of the check (DefaultTimeout by default).
+func Timeout(d time.Duration) Option {
+	return func(e *entry) { e.timeout = d }
+}
+
+// FailureThreshold sets the number of consecutive failures after which the
+// check fails the probe (1 by default). Fewer failures report it degraded.
+func FailureThreshold(n int) Option {
+	return func(e *entry) {
+		if n > 0 {
+			e.threshold = n
+		}
+	}
+}
+
+// Liveness runs the check on /healthz as well as on /readyz. Keep liveness
+// checks for failures a restart fixes: a dependency down fails readiness.
+func Liveness() Option {
+	return func(e *entry) { e.liveness = true }
+}
+
+// entry is a registered check.
+type entry struct {
+	name      string
+	check     Check
+	timeout   time.Duration
+	threshold int
+	liveness  bool
+
+	mu       sync.Mutex
+	failures int
+}
+
+// Registry holds the checks of the probes.
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]*entry
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]*entry)}
+}
+
+// Register adds a check, replacing the check of the same name.
+func (r *Registry) Register(name string, check Check, opts ...Option) *Registry {
+	e := &entry{name: name, check: check, timeout: DefaultTimeout, threshold: 1}
+	for _, opt := range opts {
+		opt(e)
+	}
+	r.mu.Lock()
+	r.entries[name] = e
+	r.mu.Unlock()
+	return r
+}
+
+// Unregister removes a check.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	delete(r.entries, name)
+	r.mu.Unlock()
+}
+
+// CheckResult is the result of a check in a Report.
+type CheckResult struct {
+	Status   Status  `json:"status"`
+	Duration float64 `json:"duration_ms"`
+	Error    string  `json:"error,omitempty"`
+	// Failures counts the consecutive failures of the check.
+	Failures int `json:"failures,omitempty"`
+}
+
+// Report is the JSON answer of a probe.
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Liveness runs the liveness checks.
+func (r *Registry) Liveness(ctx context.Context) Report {
+	return r.run(ctx, true)
+}
+
+// Readiness runs all the checks.
+func (r *Registry) Readiness(ctx context.Context) Report {
+	return r.run(ctx, false)
+}
+
+// run runs the checks concurrently, only the liveness ones when liveness.
+func (r *Registry) run(ctx context.Context, liveness bool) Report {
+	r.mu.RLock()
+	entries := make([]*entry, 0, len(r.entries))
+	for _, e := range r.entries {
+		if !liveness || e.liveness {
+			entries = append(entries, e)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+
+	results := make([]CheckResult, len(entries))
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = e.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(entries))}
+	for i, e := range entries {
+		res := results[i]
+		report.Checks[e.name] = res
+		switch {
+		case res.Status == StatusFail:
+			report.Status = StatusFail
+		case res.Status == StatusDegraded && report.Status == StatusOK:
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// run runs the check once and counts its consecutive failures.
+func (e *entry) run(ctx context.Context) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := e.check(ctx)
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	res := CheckResult{Status: StatusOK, Duration: float64(time.Since(start).Microseconds()) / 1000}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err == nil {
+		e.failures = 0
+		return res
+	}
+	e.failures++
+	res.Error = err.Error()
+	res.Failures = e.failures
+	res.Status = StatusDegraded
+	if e.failures >= e.threshold {
+		res.Status = StatusFail
+	}
+	return res
+}
+
+// LivenessHandler serves the liveness report (/healthz).
+func (r *Registry) LivenessHandler() http.Handler {
+	return reportHandler(r.Liveness)
+}
+
+// ReadinessHandler serves the readiness report (/readyz).
+func (r *Registry) ReadinessHandler() http.Handler {
+	return reportHandler(r.Readiness)
+}
+
+// reportHandler serves the report of run as JSON: 200 unless it failed,
+// 503 else. ?verbose=0 leaves the checks out.
+func reportHandler(run func(ctx context.Context) Report) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := run(r.Context())
+		if r.URL.Query().Get("verbose") == "0" {
+			report.Checks = nil
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if report.Status == StatusFail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,120 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/jobs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ok(context.Context) error { return nil }
+
+func serve(t *testing.T, h http.Handler) (int, Report) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var report Report
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	return rec.Code, report
+}
+
+func TestRegistry_Readiness(t *testing.T) {
+	r := NewRegistry().
+		Register("db", ok).
+		Register("smtp", func(context.Context) error { return errors.New("connection refused") })
+
+	code, report := serve(t, r.ReadinessHandler())
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, StatusOK, report.Checks["db"].Status)
+	assert.Equal(t, "connection refused", report.Checks["smtp"].Error)
+
+	r.Unregister("smtp")
+	code, report = serve(t, r.ReadinessHandler())
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, StatusOK, report.Status)
+}
+
+func TestRegistry_Liveness(t *testing.T) {
+	r := NewRegistry().
+		Register("goroutines", ok, Liveness()).
+		Register("db", func(context.Context) error { return errors.New("down") })
+
+	code, report := serve(t, r.LivenessHandler())
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, report.Checks, 1)
+	assert.Contains(t, report.Checks, "goroutines")
+}
+
+func TestRegistry_FailureThreshold(t *testing.T) {
+	fail := true
+	r := NewRegistry().Register("cache", func(context.Context) error {
+		if fail {
+			return errors.New("timeout")
+		}
+		return nil
+	}, FailureThreshold(2))
+
+	code, report := serve(t, r.ReadinessHandler())
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.Equal(t, 1, report.Checks["cache"].Failures)
+
+	code, report = serve(t, r.ReadinessHandler())
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, StatusFail, report.Checks["cache"].Status)
+
+	fail = false
+	_, report = serve(t, r.ReadinessHandler())
+	assert.Equal(t, StatusOK, report.Status)
+	assert.Zero(t, report.Checks["cache"].Failures)
+}
+
+func TestRegistry_Timeout(t *testing.T) {
+	r := NewRegistry().Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, Timeout(10*time.Millisecond))
+
+	report := r.Readiness(context.Background())
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Contains(t, report.Checks["slow"].Error, "deadline")
+}
+
+func TestDial(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	assert.NoError(t, Dial("tcp", addr)(context.Background()))
+	ln.Close()
+	assert.Error(t, Dial("tcp", addr)(context.Background()))
+}
+
+func TestQueueDepth(t *testing.T) {
+	q := jobs.NewQueue(1) // not started: jobs stay pending
+	check := QueueDepth(q, 1)
+	q.Dispatch("a", func(context.Context, *jobs.Job) error { return nil })
+	assert.NoError(t, check(context.Background()))
+
+	q.Dispatch("b", func(context.Context, *jobs.Job) error { return nil })
+	assert.Error(t, check(context.Background()))
+}
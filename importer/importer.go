@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bozz33/sublimeadmin/apperrors"
 	"github.com/xuri/excelize/v2"
 )
 
@@ -63,6 +64,10 @@ type ImportConfig struct {
 	ValidateRow   func(row map[string]any) error
 	BeforeImport  func(row map[string]any) (map[string]any, error)
 	AfterImport   func(row map[string]any, result any) error
+
+	// Retry retries the row handler on transient errors (deadlocks, lost
+	// connections marked with AppError.MarkTemporary). nil disables it.
+	Retry *apperrors.RetryPolicy
 }
 
 // DefaultConfig returns a default import configuration.
@@ -138,7 +143,7 @@ func (i *Importer) ImportFromReader(ctx context.Context, reader io.Reader, handl
 				continue
 			}
 		}
-		if err := handler(ctx, row); err != nil {
+		if err := i.handle(ctx, handler, row); err != nil {
 			result.ErrorCount++
 			result.Errors = append(result.Errors, ImportError{Row: idx + 1, Message: err.Error()})
 			if i.config.StopOnError || len(result.Errors) >= i.config.MaxErrors {
@@ -271,7 +276,7 @@ func (i *Importer) importExcel(ctx context.Context, file io.Reader, handler func
 				continue
 			}
 		}
-		if err := handler(ctx, row); err != nil {
+		if err := i.handle(ctx, handler, row); err != nil {
 			result.ErrorCount++
 			result.Errors = append(result.Errors, ImportError{Row: idx + 1, Message: err.Error()})
 			if i.config.StopOnError || len(result.Errors) >= i.config.MaxErrors {
@@ -286,6 +291,16 @@ func (i *Importer) importExcel(ctx context.Context, file io.Reader, handler func
 	return result, nil
 }
 
+// handle calls the row handler, retrying it according to the Retry policy.
+func (i *Importer) handle(ctx context.Context, handler func(ctx context.Context, row map[string]any) error, row map[string]any) error {
+	if i.config.Retry == nil {
+		return handler(ctx, row)
+	}
+	return apperrors.Retry(ctx, *i.config.Retry, func(ctx context.Context) error {
+		return handler(ctx, row)
+	})
+}
+
 func (i *Importer) transformValue(column, value string) any {
 	for _, mapping := range i.config.Mappings {
 		if mapping.SourceColumn == column && mapping.Transform != nil {
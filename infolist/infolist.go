@@ -66,6 +66,13 @@ type Entry struct {
 	Alignment string // "left", "center", "right"
 	// HasPlaceholder
 	Placeholder string // shown when value is empty
+	// Record entries (see Text, Badge, Relation and Infolist.Fill)
+	Key          string                   // field of the record holding the value
+	ValueFunc    func(record any) any     // optional: replaces the field lookup
+	ColorMap     map[string]string        // for EntryTypeBadge: value → color
+	DisplayField string                   // for EntryTypeRelation: field shown for each record
+	RelatedURL   func(related any) string // for EntryTypeRelation: link of each record
+	RelatedItems []RelatedItem            // for EntryTypeRelation: filled by Infolist.Fill
 }
 
 // Label returns the display label.
@@ -0,0 +1,210 @@
+package infolist
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// EntryTypeRelation lists the records of a relation (see Relation).
+const EntryTypeRelation EntryType = "relation"
+
+// RelatedItem is one record of a relation entry.
+type RelatedItem struct {
+	Label string
+	URL   string // empty = not a link
+}
+
+// --- Record entries ---
+//
+// Text, Badge, Boolean, Date and Relation create entries reading a field of
+// the record, so an Infolist can be declared once for a resource and filled
+// for each record (see Infolist.Fill):
+//
+//	il := infolist.New().AddSection(infolist.NewSection("Customer").Add(
+//		infolist.Text("Email").WithCopy(),
+//		infolist.Badge("Status").Colors(map[string]string{"active": "green"}),
+//		infolist.Relation("Orders").Display("Number").LinkTo(orderURL),
+//	))
+
+// Text creates a text entry reading the field key of the record.
+func Text(key string) *Entry {
+	return &Entry{Name: key, LabelStr: key, Type: EntryTypeText, Key: key}
+}
+
+// Badge creates a badge entry reading the field key of the record. The
+// color depends on the value (see Colors).
+func Badge(key string) *Entry {
+	return &Entry{Name: key, LabelStr: key, Type: EntryTypeBadge, Key: key}
+}
+
+// Boolean creates a boolean (✓/✗) entry reading the field key of the record.
+func Boolean(key string) *Entry {
+	return &Entry{Name: key, LabelStr: key, Type: EntryTypeBoolean, Key: key}
+}
+
+// Date creates a date entry reading the field key of the record.
+func Date(key string) *Entry {
+	return &Entry{Name: key, LabelStr: key, Type: EntryTypeDate, Format: "2006-01-02", Key: key}
+}
+
+// Relation creates an entry listing the records of the field key of the
+// record: a slice of records, or a single one. Each record shows its
+// Display field (default: Name, Title, then the record itself).
+func Relation(key string) *Entry {
+	return &Entry{Name: key, LabelStr: key, Type: EntryTypeRelation, Key: key}
+}
+
+// WithLabel sets the label of the entry.
+func (e *Entry) WithLabel(label string) *Entry {
+	e.LabelStr = label
+	return e
+}
+
+// Using sets a custom accessor, bypassing the field lookup.
+func (e *Entry) Using(fn func(record any) any) *Entry {
+	e.ValueFunc = fn
+	return e
+}
+
+// Colors maps the values of a badge entry to colors, e.g. "active" → "green".
+func (e *Entry) Colors(colors map[string]string) *Entry {
+	e.ColorMap = colors
+	return e
+}
+
+// WithFormat sets the Go time layout of a date entry.
+func (e *Entry) WithFormat(layout string) *Entry {
+	e.Format = layout
+	return e
+}
+
+// Display sets the field shown for each record of a relation entry.
+func (e *Entry) Display(field string) *Entry {
+	e.DisplayField = field
+	return e
+}
+
+// LinkTo makes each record of a relation entry a link, e.g. to its page.
+func (e *Entry) LinkTo(fn func(related any) string) *Entry {
+	e.RelatedURL = fn
+	return e
+}
+
+// Fill returns a copy of the infolist with the values of record. Entries
+// created with a value (TextEntry, BadgeEntry...) are kept as they are. The
+// infolist itself is not modified, so it can be shared by requests.
+func (il *Infolist) Fill(record any) *Infolist {
+	filled := &Infolist{Sections: make([]*Section, len(il.Sections))}
+	for i, s := range il.Sections {
+		copied := *s
+		copied.Entries = make([]*Entry, len(s.Entries))
+		for j, e := range s.Entries {
+			copied.Entries[j] = e.fill(record)
+		}
+		filled.Sections[i] = &copied
+	}
+	return filled
+}
+
+// fill returns a copy of the entry with the value of record.
+func (e *Entry) fill(record any) *Entry {
+	copied := *e
+	if e.Key == "" && e.ValueFunc == nil {
+		return &copied
+	}
+	value := fieldValue(record, e.Key)
+	if e.ValueFunc != nil {
+		value = e.ValueFunc(record)
+	}
+	copied.Value = value
+	switch e.Type {
+	case EntryTypeDate:
+		switch t := value.(type) {
+		case time.Time:
+			copied.Value = t.Format(time.RFC3339)
+		case *time.Time:
+			if t != nil {
+				copied.Value = t.Format(time.RFC3339)
+			}
+		}
+	case EntryTypeBadge:
+		if color, ok := e.ColorMap[copied.ValueStr()]; ok {
+			copied.BadgeColor = color
+		}
+	case EntryTypeRelation:
+		copied.RelatedItems = e.relatedItems(value)
+	}
+	return &copied
+}
+
+// relatedItems lists the records of a relation value.
+func (e *Entry) relatedItems(value any) []RelatedItem {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil
+	}
+	var records []any
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		for i := 0; i < v.Len(); i++ {
+			records = append(records, v.Index(i).Interface())
+		}
+	} else {
+		records = []any{value}
+	}
+
+	items := make([]RelatedItem, 0, len(records))
+	for _, related := range records {
+		item := RelatedItem{Label: e.relatedLabel(related)}
+		if e.RelatedURL != nil {
+			item.URL = e.RelatedURL(related)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// relatedLabel returns the label of a related record.
+func (e *Entry) relatedLabel(related any) string {
+	fields := []string{"Name", "Title"}
+	if e.DisplayField != "" {
+		fields = []string{e.DisplayField}
+	}
+	for _, field := range fields {
+		if v := fieldValue(related, field); v != nil {
+			return fmt.Sprintf("%v", v)
+		}
+	}
+	return fmt.Sprintf("%v", related)
+}
+
+// fieldValue returns the field key of a struct record, matched by name,
+// case-insensitively, or by json tag. It returns nil when there is none.
+func fieldValue(record any, key string) any {
+	v := reflect.ValueOf(record)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || key == "" {
+		return nil
+	}
+	if f := v.FieldByName(key); f.IsValid() && f.CanInterface() {
+		return f.Interface()
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if strings.EqualFold(sf.Name, key) || tag == key {
+			return v.Field(i).Interface()
+		}
+	}
+	return nil
+}
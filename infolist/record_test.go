@@ -0,0 +1,65 @@
+package infolist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type order struct {
+	Number string
+}
+
+type customer struct {
+	Email     string `json:"email_address"`
+	Status    string
+	Active    bool
+	CreatedAt time.Time
+	Orders    []*order
+	Manager   *customer
+	Name      string
+}
+
+func TestInfolist_Fill(t *testing.T) {
+	il := New().AddSection(NewSection("Customer").Add(
+		Text("email_address"),
+		Text("status").WithLabel("State"),
+		Badge("Status").Colors(map[string]string{"active": "green"}),
+		Boolean("Active"),
+		Date("CreatedAt"),
+		Relation("Orders").Display("Number").LinkTo(func(related any) string {
+			return "/orders/" + related.(*order).Number
+		}),
+		Relation("Manager"),
+		Text("Missing"),
+		Text("computed").Using(func(record any) any { return len(record.(*customer).Orders) }),
+		TextEntry("static", "Static", "kept"),
+	))
+	c := &customer{
+		Email:     "jane@example.com",
+		Status:    "active",
+		Active:    true,
+		CreatedAt: time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC),
+		Orders:    []*order{{Number: "A1"}, {Number: "A2"}},
+		Manager:   &customer{Name: "Sam"},
+	}
+
+	filled := il.Fill(c)
+	entries := filled.Sections[0].Entries
+	assert.Equal(t, "jane@example.com", entries[0].ValueStr())
+	assert.Equal(t, "active", entries[1].ValueStr())
+	assert.Equal(t, "State", entries[1].Label())
+	assert.Equal(t, "green", entries[2].BadgeColor)
+	assert.Equal(t, "true", entries[3].ValueStr())
+	assert.Equal(t, "2026-03-15T10:00:00Z", entries[4].ValueStr())
+	assert.Equal(t, []RelatedItem{{Label: "A1", URL: "/orders/A1"}, {Label: "A2", URL: "/orders/A2"}}, entries[5].RelatedItems)
+	assert.Equal(t, []RelatedItem{{Label: "Sam"}}, entries[6].RelatedItems)
+	assert.Equal(t, "", entries[7].ValueStr())
+	assert.Equal(t, "2", entries[8].ValueStr())
+	assert.Equal(t, "kept", entries[9].ValueStr())
+
+	// The infolist is not modified
+	assert.Nil(t, il.Sections[0].Entries[0].Value)
+	assert.Nil(t, il.Sections[0].Entries[5].RelatedItems)
+}
@@ -12,6 +12,13 @@
 //   - Job cancellation
 //   - Timeout handling (default 30 minutes)
 //   - Job cleanup for old completed jobs
+//   - Trace span links to the dispatching request (DispatchContext)
+//   - Jobs scoped to a tenant (DispatchForTenant, WithTenantContext)
+//   - Named queues and priorities (OnQueue, Priority), worker pools
+//     dedicated to some queues (WithPool) and aging of the waiting jobs
+//     (WithAging)
+//   - Jobs persisted in a SQLite database (WithStore, NewSQLiteStore), listed
+//     with those of previous runs (List) and pruned (WithPruning)
 //
 // Basic usage:
 //
@@ -29,4 +36,10 @@
 //
 //	// Wait for completion
 //	result, err := queue.Wait(jobID, 5*time.Minute)
+//
+// Named queues and priorities:
+//
+//	queue := jobs.NewQueue(4).WithPool(2, "mail") // 2 workers for the mail only
+//	queue.Dispatch("send-email", fn, jobs.OnQueue("mail"), jobs.Priority(jobs.High))
+//	queue.Dispatch("import", fn, jobs.Priority(jobs.Low))
 package jobs
@@ -3,11 +3,17 @@ package jobs
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/bozz33/sublimeadmin/metrics"
+	"github.com/bozz33/sublimeadmin/tracing"
 	"github.com/google/uuid"
 	"github.com/samber/lo"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Status represents the state of a job.
@@ -21,10 +27,49 @@ const (
 	StatusCancelled Status = "cancelled"
 )
 
+// DefaultQueue is the named queue of the jobs dispatched without OnQueue.
+const DefaultQueue = "default"
+
+// DefaultAging is the time after which a pending job gains a priority
+// level (see WithAging).
+const DefaultAging = time.Minute
+
+// PriorityLevel orders the pending jobs of the queues served by a worker:
+// the highest runs first.
+type PriorityLevel int
+
+const (
+	Low    PriorityLevel = -1
+	Normal PriorityLevel = 0
+	High   PriorityLevel = 1
+)
+
+// DispatchOption configures a dispatched job.
+type DispatchOption func(job *Job)
+
+// OnQueue dispatches the job on the named queue, served by the workers of
+// its pool (see WithPool).
+func OnQueue(name string) DispatchOption {
+	return func(job *Job) {
+		if name != "" {
+			job.Queue = name
+		}
+	}
+}
+
+// Priority sets the priority of the job among the pending jobs (Normal by
+// default).
+func Priority(level PriorityLevel) DispatchOption {
+	return func(job *Job) { job.Priority = level }
+}
+
 // Job represents a task to execute.
 type Job struct {
 	ID          string
 	Name        string
+	TenantID    string // tenant the job runs for, set by DispatchForTenant
+	Queue       string // named queue, DefaultQueue unless OnQueue
+	Priority    PriorityLevel
 	Status      Status
 	Progress    int // 0-100
 	Result      interface{}
@@ -35,19 +80,57 @@ type Job struct {
 	Handler     func(ctx context.Context, job *Job) error
 	OnComplete  func(job *Job)
 	OnError     func(job *Job, err error)
+
+	link tracing.Link // span of the dispatcher, set by DispatchContext
 }
 
 // Queue manages asynchronous job execution.
 type Queue struct {
-	jobs    sync.Map // map[string]*Job
+	jobs      sync.Map // map[string]*Job
+	workers   int
+	pools     []*pool         // workers dedicated to named queues, see WithPool
+	dedicated map[string]bool // queues served by a pool of their own
+	pending   map[lane][]*Job // queued jobs, oldest first
+	ready     *sync.Cond      // signals the workers a job was queued or the queue closed
+	aging     time.Duration   // see WithAging
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	mu        sync.RWMutex
+	started   bool
+	closed    bool        // no more jobs are queued, see enqueue
+	drain     atomic.Bool // workers leave the queued jobs pending, see Shutdown
+	store     JobStore    // optional persistence, see WithStore
+
+	// pruneAfter and pruneEvery are set via WithPruning.
+	pruneAfter time.Duration
+	pruneEvery time.Duration
+
+	// tenantContext prepares the context of the jobs of a tenant, set via
+	// WithTenantContext.
+	tenantContext func(ctx context.Context, tenantID string) (context.Context, error)
+}
+
+// lane holds the pending jobs of a queue with a priority.
+type lane struct {
+	queue    string
+	priority PriorityLevel
+}
+
+// pool is a set of workers serving some queues.
+type pool struct {
 	workers int
-	jobChan chan *Job
-	ctx     context.Context
-	cancel  context.CancelFunc
-	wg      sync.WaitGroup
-	mu      sync.RWMutex
-	started bool
-	store   *Store // optional SQLite persistence
+	queues  []string // nil: every queue without a pool of its own
+}
+
+// tenantKey is the context key of the tenant ID of a job.
+type tenantKey struct{}
+
+// TenantID returns the tenant the running job was dispatched for, or ""
+// (see DispatchForTenant).
+func TenantID(ctx context.Context) string {
+	id, _ := ctx.Value(tenantKey{}).(string)
+	return id
 }
 
 // NewQueue creates a new queue with a number of workers.
@@ -58,15 +141,20 @@ func NewQueue(workers int) *Queue {
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &Queue{
-		workers: workers,
-		jobChan: make(chan *Job, workers*10),
-		ctx:     ctx,
-		cancel:  cancel,
+	q := &Queue{
+		workers:   workers,
+		dedicated: map[string]bool{},
+		pending:   map[lane][]*Job{},
+		aging:     DefaultAging,
+		ctx:       ctx,
+		cancel:    cancel,
 	}
+	q.ready = sync.NewCond(&q.mu)
+	return q
 }
 
-// NewPersistentQueue creates a queue backed by a SQLite store.
+// NewPersistentQueue creates a queue backed by a SQLite store (see
+// NewStore and WithStore).
 // Pending jobs from previous runs are automatically re-queued on Start().
 func NewPersistentQueue(workers int, storePath string) (*Queue, error) {
 	q := NewQueue(workers)
@@ -80,8 +168,63 @@ func NewPersistentQueue(workers int, storePath string) (*Queue, error) {
 	return q, nil
 }
 
+// WithTenantContext sets the function preparing the context of the jobs
+// dispatched for a tenant before they run, e.g. to load the tenant and its
+// database:
+//
+//	queue.WithTenantContext(tm.JobContext)
+//
+// A job fails without running when fn returns an error, e.g. for a tenant
+// suspended meanwhile.
+func (q *Queue) WithTenantContext(fn func(ctx context.Context, tenantID string) (context.Context, error)) *Queue {
+	q.tenantContext = fn
+	return q
+}
+
+// WithPool adds a pool of workers serving only the named queues, which the
+// other workers no longer serve, e.g. so password reset emails don't wait
+// behind a bulk import:
+//
+//	queue := jobs.NewQueue(4).WithPool(2, "mail")
+//	queue.Dispatch("send-email", fn, jobs.OnQueue("mail"), jobs.Priority(jobs.High))
+//
+// Call it before Start.
+func (q *Queue) WithPool(workers int, queues ...string) *Queue {
+	if workers <= 0 || len(queues) == 0 {
+		return q
+	}
+	q.pools = append(q.pools, &pool{workers: workers, queues: queues})
+	for _, name := range queues {
+		q.dedicated[name] = true
+	}
+	return q
+}
+
+// WithAging sets the time after which a pending job gains a priority level
+// (DefaultAging by default), so the low priority jobs still run under a
+// steady flow of higher ones. Zero disables it.
+func (q *Queue) WithAging(d time.Duration) *Queue {
+	q.aging = d
+	return q
+}
+
+// WithStore persists the jobs in store: the pending jobs of previous runs
+// are re-queued on Start, and Get and List find the finished ones. Call it
+// before Start.
+func (q *Queue) WithStore(store JobStore) *Queue {
+	q.store = store
+	return q
+}
+
+// WithPruning removes, every interval while the queue runs, the finished
+// jobs completed more than olderThan ago, from the queue and its store.
+func (q *Queue) WithPruning(olderThan, every time.Duration) *Queue {
+	q.pruneAfter, q.pruneEvery = olderThan, every
+	return q
+}
+
 // Start starts the queue workers.
-// If the queue has a Store, pending jobs from previous runs are re-queued.
+// If the queue has a JobStore, pending jobs from previous runs are re-queued.
 func (q *Queue) Start() {
 	q.mu.Lock()
 	defer q.mu.Unlock()
@@ -92,9 +235,14 @@ func (q *Queue) Start() {
 
 	q.started = true
 
-	for i := 0; i < q.workers; i++ {
-		q.wg.Add(1)
-		go q.worker(i)
+	for _, p := range append([]*pool{{workers: q.workers}}, q.pools...) {
+		for i := 0; i < p.workers; i++ {
+			q.wg.Add(1)
+			go q.worker(p)
+		}
+	}
+	if q.pruneEvery > 0 {
+		go q.prune()
 	}
 
 	if q.store != nil {
@@ -103,7 +251,7 @@ func (q *Queue) Start() {
 			for _, job := range pending {
 				q.jobs.Store(job.ID, job)
 				if job.Handler != nil {
-					q.jobChan <- job
+					q.push(job)
 				}
 			}
 		}
@@ -112,27 +260,197 @@ func (q *Queue) Start() {
 
 // Stop stops the queue and waits for all jobs to finish.
 func (q *Queue) Stop() {
-	q.mu.Lock()
-	if !q.started {
-		q.mu.Unlock()
+	if !q.close() {
 		return
 	}
-	q.mu.Unlock()
-
-	close(q.jobChan)
 	q.wg.Wait()
 	q.cancel()
 }
 
-// worker processes jobs from the queue.
-func (q *Queue) worker(id int) {
+// Shutdown stops the queue on shutdown: the running jobs finish while
+// the queued ones are left pending, saved by the store of a persistent
+// queue to run on the next Start. Without a store, the queued jobs are
+// dropped when the process exits: Shutdown logs their IDs. When ctx is done
+// first, the running jobs are cancelled and Shutdown returns ctx.Err()
+// without waiting for them to return; the store is then closed once they
+// have. Jobs dispatched afterwards stay pending. The store, if any, is
+// closed.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.drain.Store(true)
+	if !q.close() {
+		return q.closeStore()
+	}
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		q.cancel()
+		q.logDropped()
+		if q.store != nil {
+			go func() {
+				<-done
+				_ = q.closeStore()
+			}()
+		}
+		return ctx.Err()
+	}
+	q.cancel()
+	q.logDropped()
+	return q.closeStore()
+}
+
+// closeStore closes the store of the queue, if any.
+func (q *Queue) closeStore() error {
+	if q.store == nil {
+		return nil
+	}
+	if err := q.store.Close(); err != nil {
+		return fmt.Errorf("jobs: close store: %w", err)
+	}
+	return nil
+}
+
+// logDropped logs the IDs of the queued jobs of a queue without a store,
+// lost when the process exits.
+func (q *Queue) logDropped() {
+	if q.store != nil {
+		return
+	}
+	q.mu.Lock()
+	var ids []string
+	for _, list := range q.pending {
+		for _, job := range list {
+			if job.Status == StatusPending {
+				ids = append(ids, job.ID)
+			}
+		}
+	}
+	q.mu.Unlock()
+	if len(ids) > 0 {
+		slices.Sort(ids)
+		slog.Warn("jobs: shutdown dropped queued jobs without a store", slog.Any("job_ids", ids))
+	}
+}
+
+// close stops queuing jobs, false when the queue was not running.
+func (q *Queue) close() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.started || q.closed {
+		return false
+	}
+	q.closed = true
+	q.ready.Broadcast()
+	return true
+}
+
+// enqueue queues a job for the workers, unless the queue is stopped: the
+// job then stays pending.
+func (q *Queue) enqueue(job *Job) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.push(job)
+	}
+}
+
+// push queues a job, q.mu held.
+func (q *Queue) push(job *Job) {
+	l := lane{queue: job.Queue, priority: job.Priority}
+	q.pending[l] = append(q.pending[l], job)
+	q.ready.Broadcast()
+}
+
+// worker processes the jobs of the queues of p.
+func (q *Queue) worker(p *pool) {
 	defer q.wg.Done()
 
-	for job := range q.jobChan {
+	for {
+		job, ok := q.next(p)
+		if !ok {
+			return
+		}
 		q.executeJob(job)
 	}
 }
 
+// next waits for the next job of the queues of p, false once the queue is
+// stopped and they are empty, or shut down.
+func (q *Queue) next(p *pool) (*Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		if q.drain.Load() {
+			return nil, false
+		}
+		if job := q.pick(p); job != nil {
+			return job, true
+		}
+		if q.closed {
+			return nil, false
+		}
+		q.ready.Wait()
+	}
+}
+
+// pick removes the next job of the queues of p, q.mu held: the oldest of
+// the highest priority, raised by a level every aging it has waited.
+func (q *Queue) pick(p *pool) *Job {
+	now := time.Now()
+	var (
+		best     lane
+		found    bool
+		bestRank PriorityLevel
+		bestAt   time.Time
+	)
+	for l, list := range q.pending {
+		if !q.serves(p, l.queue) {
+			continue
+		}
+		for len(list) > 0 && list[0].Status == StatusCancelled {
+			list[0] = nil
+			list = list[1:]
+		}
+		if len(list) == 0 {
+			delete(q.pending, l)
+			continue
+		}
+		q.pending[l] = list
+		head := list[0]
+		rank := l.priority
+		if q.aging > 0 {
+			rank += PriorityLevel(now.Sub(head.CreatedAt) / q.aging)
+		}
+		if !found || rank > bestRank || rank == bestRank && head.CreatedAt.Before(bestAt) {
+			best, found, bestRank, bestAt = l, true, rank, head.CreatedAt
+		}
+	}
+	if !found {
+		return nil
+	}
+	list := q.pending[best]
+	job := list[0]
+	list[0] = nil
+	if len(list) == 1 {
+		delete(q.pending, best)
+	} else {
+		q.pending[best] = list[1:]
+	}
+	return job
+}
+
+// serves reports whether the workers of p serve the named queue.
+func (q *Queue) serves(p *pool, queue string) bool {
+	if p.queues == nil {
+		return !q.dedicated[queue]
+	}
+	return slices.Contains(p.queues, queue)
+}
+
 // executeJob executes a job.
 func (q *Queue) executeJob(job *Job) {
 	job.Status = StatusRunning
@@ -144,9 +462,18 @@ func (q *Queue) executeJob(job *Job) {
 	ctx, cancel := context.WithTimeout(q.ctx, 30*time.Minute)
 	defer cancel()
 
-	err := job.Handler(ctx, job)
+	ctx, span := job.link.Start(ctx, "job "+job.Name,
+		attribute.String("job.id", job.ID),
+		attribute.String("job.name", job.Name),
+		attribute.String("job.tenant", job.TenantID),
+	)
+	defer span.End()
+
+	err := q.runHandler(ctx, job)
+	tracing.RecordError(span, err)
 	completed := time.Now()
 	job.CompletedAt = &completed
+	metrics.JobDuration.WithLabelValues(job.Name).Observe(completed.Sub(now).Seconds())
 
 	if err != nil {
 		job.Status = StatusFailed
@@ -164,6 +491,50 @@ func (q *Queue) executeJob(job *Job) {
 
 	q.jobs.Store(job.ID, job)
 	q.persist(job)
+	metrics.JobsProcessed.WithLabelValues(job.Name, string(job.Status)).Inc()
+}
+
+// runHandler runs the handler of the job, in the context of its tenant.
+func (q *Queue) runHandler(ctx context.Context, job *Job) error {
+	if job.TenantID != "" {
+		ctx = context.WithValue(ctx, tenantKey{}, job.TenantID)
+		if q.tenantContext != nil {
+			var err error
+			if ctx, err = q.tenantContext(ctx, job.TenantID); err != nil {
+				return fmt.Errorf("jobs: tenant %s: %w", job.TenantID, err)
+			}
+		}
+	}
+	return job.Handler(ctx, job)
+}
+
+// prune runs Prune every pruneEvery until the queue stops.
+func (q *Queue) prune() {
+	ticker := time.NewTicker(q.pruneEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-ticker.C:
+			_, _ = q.Prune(q.pruneAfter)
+		}
+	}
+}
+
+// Prune removes the finished jobs completed more than olderThan ago from
+// the queue and its store. It returns the number of jobs removed from the
+// store, or from the queue without one.
+func (q *Queue) Prune(olderThan time.Duration) (int, error) {
+	n := q.Clear(olderThan)
+	if q.store == nil {
+		return n, nil
+	}
+	deleted, err := q.store.DeleteOlderThan(olderThan)
+	if err != nil {
+		return 0, fmt.Errorf("jobs: prune store: %w", err)
+	}
+	return int(deleted), nil
 }
 
 // persist saves the job to the store if one is configured.
@@ -173,20 +544,49 @@ func (q *Queue) persist(job *Job) {
 	}
 }
 
-// Dispatch adds a job to the queue.
-func (q *Queue) Dispatch(name string, handler func(ctx context.Context, job *Job) error) string {
+// Dispatch adds a job to the queue, on the DefaultQueue with a Normal
+// priority unless opts say otherwise:
+//
+//	queue.Dispatch("send-email", fn, jobs.OnQueue("mail"), jobs.Priority(jobs.High))
+func (q *Queue) Dispatch(name string, handler func(ctx context.Context, job *Job) error, opts ...DispatchOption) string {
+	return q.DispatchContext(context.Background(), name, handler, opts...)
+}
+
+// DispatchContext adds a job to the queue, linking it to the trace span
+// carried by ctx. The job runs in its own trace, with a span link back to
+// the dispatching request. ctx is not used to cancel the job.
+func (q *Queue) DispatchContext(ctx context.Context, name string, handler func(ctx context.Context, job *Job) error, opts ...DispatchOption) string {
+	return q.DispatchForTenant(ctx, "", name, handler, opts...)
+}
+
+// DispatchForTenant adds a job to the queue for a tenant, like
+// DispatchContext. The job runs with the tenant ID in its context (see
+// TenantID), prepared by the function of WithTenantContext, and can be
+// listed with GetByTenant.
+func (q *Queue) DispatchForTenant(ctx context.Context, tenantID, name string, handler func(ctx context.Context, job *Job) error, opts ...DispatchOption) string {
+	ctx, span := tracing.Start(ctx, "jobs.dispatch "+name)
+	defer span.End()
+
 	job := &Job{
 		ID:        uuid.New().String(),
 		Name:      name,
+		TenantID:  tenantID,
+		Queue:     DefaultQueue,
 		Status:    StatusPending,
 		Progress:  0,
 		CreatedAt: time.Now(),
 		Handler:   handler,
+		link:      tracing.LinkFrom(ctx),
+	}
+	for _, opt := range opts {
+		opt(job)
 	}
+	span.SetAttributes(attribute.String("job.id", job.ID))
 
 	q.jobs.Store(job.ID, job)
 	q.persist(job)
-	q.jobChan <- job
+	metrics.JobsDispatched.WithLabelValues(name).Inc()
+	q.enqueue(job)
 
 	return job.ID
 }
@@ -197,10 +597,12 @@ func (q *Queue) DispatchWithCallbacks(
 	handler func(ctx context.Context, job *Job) error,
 	onComplete func(job *Job),
 	onError func(job *Job, err error),
+	opts ...DispatchOption,
 ) string {
 	job := &Job{
 		ID:         uuid.New().String(),
 		Name:       name,
+		Queue:      DefaultQueue,
 		Status:     StatusPending,
 		Progress:   0,
 		CreatedAt:  time.Now(),
@@ -208,21 +610,57 @@ func (q *Queue) DispatchWithCallbacks(
 		OnComplete: onComplete,
 		OnError:    onError,
 	}
+	for _, opt := range opts {
+		opt(job)
+	}
 
 	q.jobs.Store(job.ID, job)
 	q.persist(job)
-	q.jobChan <- job
+	metrics.JobsDispatched.WithLabelValues(name).Inc()
+	q.enqueue(job)
 
 	return job.ID
 }
 
-// Get retrieves a job by its ID.
+// Get retrieves a job by its ID, from the store when the queue no longer
+// holds it, e.g. after a restart.
 func (q *Queue) Get(id string) (*Job, bool) {
 	value, ok := q.jobs.Load(id)
-	if !ok {
-		return nil, false
+	if ok {
+		return value.(*Job), true
 	}
-	return value.(*Job), true
+	if q.store != nil {
+		if job, err := q.store.Get(id); err == nil && job != nil {
+			return job, true
+		}
+	}
+	return nil, false
+}
+
+// List returns the jobs matching filter, most recent first: those of the
+// queue and, with a store, those of previous runs.
+func (q *Queue) List(filter Filter) ([]*Job, error) {
+	list := lo.Filter(q.GetAll(), func(job *Job, _ int) bool {
+		return (filter.Status == "" || job.Status == filter.Status) &&
+			(filter.TenantID == "" || job.TenantID == filter.TenantID) &&
+			(filter.Queue == "" || job.Queue == filter.Queue)
+	})
+	if q.store != nil {
+		stored, err := q.store.Query(filter)
+		if err != nil {
+			return nil, fmt.Errorf("jobs: query store: %w", err)
+		}
+		for _, job := range stored {
+			if _, ok := q.jobs.Load(job.ID); !ok {
+				list = append(list, job)
+			}
+		}
+	}
+	slices.SortFunc(list, func(a, b *Job) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	if filter.Limit > 0 && len(list) > filter.Limit {
+		list = list[:filter.Limit]
+	}
+	return list, nil
 }
 
 // GetAll returns all jobs.
@@ -242,6 +680,13 @@ func (q *Queue) GetByStatus(status Status) []*Job {
 	})
 }
 
+// GetByTenant returns the jobs dispatched for a tenant.
+func (q *Queue) GetByTenant(tenantID string) []*Job {
+	return lo.Filter(q.GetAll(), func(job *Job, _ int) bool {
+		return job.TenantID == tenantID
+	})
+}
+
 // Count returns the total number of jobs.
 func (q *Queue) Count() int {
 	count := 0
@@ -322,8 +767,17 @@ func (q *Queue) Stats() map[string]interface{} {
 		"completed": q.CountByStatus(StatusCompleted),
 		"failed":    q.CountByStatus(StatusFailed),
 		"cancelled": q.CountByStatus(StatusCancelled),
-		"workers":   q.workers,
+		"workers":   q.workerCount(),
+	}
+}
+
+// workerCount returns the number of workers of every pool.
+func (q *Queue) workerCount() int {
+	n := q.workers
+	for _, p := range q.pools {
+		n += p.workers
 	}
+	return n
 }
 
 // UpdateProgress updates the progress of a job.
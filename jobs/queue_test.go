@@ -3,18 +3,90 @@ package jobs
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestNewQueue(t *testing.T) {
 	q := NewQueue(4)
 	require.NotNil(t, q)
 	assert.Equal(t, 4, q.workers)
-	assert.NotNil(t, q.jobChan)
+	assert.NotNil(t, q.pending)
+	assert.Equal(t, DefaultAging, q.aging)
+}
+
+// recordOrder returns handlers appending their job name to the order.
+func recordOrder() (func(ctx context.Context, job *Job) error, func() []string) {
+	var mu sync.Mutex
+	var order []string
+	return func(ctx context.Context, job *Job) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, job.Name)
+			return nil
+		}, func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return order
+		}
+}
+
+func TestDispatch_priority(t *testing.T) {
+	q := NewQueue(1) // not started: the jobs wait
+	handler, order := recordOrder()
+	q.Dispatch("low", handler, Priority(Low))
+	q.Dispatch("normal", handler)
+	id := q.Dispatch("high", handler, Priority(High))
+	q.Dispatch("high-2", handler, Priority(High))
+
+	job, _ := q.Get(id)
+	assert.Equal(t, DefaultQueue, job.Queue)
+	assert.Equal(t, High, job.Priority)
+
+	q.Start()
+	q.Stop()
+	assert.Equal(t, []string{"high", "high-2", "normal", "low"}, order())
+}
+
+func TestDispatch_aging(t *testing.T) {
+	q := NewQueue(1).WithAging(time.Millisecond)
+	handler, order := recordOrder()
+	q.Dispatch("low", handler, Priority(Low))
+	time.Sleep(10 * time.Millisecond)
+	q.Dispatch("high", handler, Priority(High))
+
+	q.Start()
+	q.Stop()
+	assert.Equal(t, []string{"low", "high"}, order(), "the starving job should run first")
+}
+
+func TestWithPool(t *testing.T) {
+	q := NewQueue(1).WithPool(1, "mail")
+	q.Start()
+	defer q.Stop()
+
+	release := make(chan struct{})
+	defer close(release)
+	q.Dispatch("import", func(ctx context.Context, job *Job) error {
+		<-release
+		return nil
+	})
+	queued := q.Dispatch("import-2", func(ctx context.Context, job *Job) error { return nil })
+	mail := q.Dispatch("send-email", func(ctx context.Context, job *Job) error { return nil }, OnQueue("mail"), Priority(High))
+
+	job, err := q.Wait(mail, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, job.Status, "the mail pool should not wait behind the import")
+	assert.Equal(t, "mail", job.Queue)
+
+	job, _ = q.Get(queued)
+	assert.Equal(t, StatusPending, job.Status, "the mail pool should not serve the default queue")
+	assert.Equal(t, 2, q.Stats()["workers"])
 }
 
 func TestQueueStartStop(t *testing.T) {
@@ -67,6 +139,74 @@ func TestDispatchWithError(t *testing.T) {
 	assert.Equal(t, expectedErr, job.Error)
 }
 
+func TestDispatchContext(t *testing.T) {
+	q := NewQueue(2)
+	q.Start()
+	defer q.Stop()
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx, cancel := context.WithCancel(trace.ContextWithSpanContext(context.Background(), sc))
+
+	done := make(chan struct{})
+	jobID := q.DispatchContext(ctx, "traced-job", func(ctx context.Context, job *Job) error {
+		close(done)
+		return nil
+	})
+	cancel() // cancelling the dispatch context must not cancel the job
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("job did not run")
+	}
+
+	job, ok := q.Get(jobID)
+	require.True(t, ok)
+	assert.True(t, job.link.IsValid())
+}
+
+func TestDispatchForTenant(t *testing.T) {
+	type dbKey struct{}
+	q := NewQueue(2).WithTenantContext(func(ctx context.Context, tenantID string) (context.Context, error) {
+		if tenantID == "suspended" {
+			return nil, errors.New("tenant suspended")
+		}
+		return context.WithValue(ctx, dbKey{}, tenantID+".db"), nil
+	})
+	q.Start()
+	defer q.Stop()
+
+	var gotTenant, gotDB any
+	acmeID := q.DispatchForTenant(context.Background(), "acme", "export", func(ctx context.Context, job *Job) error {
+		gotTenant, gotDB = TenantID(ctx), ctx.Value(dbKey{})
+		return nil
+	})
+	ran := false
+	suspendedID := q.DispatchForTenant(context.Background(), "suspended", "export", func(ctx context.Context, job *Job) error {
+		ran = true
+		return nil
+	})
+	q.Dispatch("global", func(ctx context.Context, job *Job) error { return nil })
+
+	acme, err := q.Wait(acmeID, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, acme.Status)
+	assert.Equal(t, "acme", gotTenant)
+	assert.Equal(t, "acme.db", gotDB)
+
+	suspended, err := q.Wait(suspendedID, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, suspended.Status)
+	assert.False(t, ran)
+
+	assert.Len(t, q.GetByTenant("acme"), 1)
+	assert.Equal(t, acmeID, q.GetByTenant("acme")[0].ID)
+}
+
 func TestDispatchWithCallbacks(t *testing.T) {
 	q := NewQueue(2)
 	q.Start()
@@ -395,3 +535,81 @@ func BenchmarkDispatch(b *testing.B) {
 		})
 	}
 }
+
+func TestQueueShutdown(t *testing.T) {
+	q := NewQueue(1)
+	q.Start()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	running := q.Dispatch("running", func(ctx context.Context, job *Job) error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+	queued := q.Dispatch("queued", func(ctx context.Context, job *Job) error { return nil })
+
+	done := make(chan error, 1)
+	go func() { done <- q.Shutdown(context.Background()) }()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	require.NoError(t, <-done)
+
+	job, _ := q.Get(running)
+	assert.Equal(t, StatusCompleted, job.Status)
+	job, _ = q.Get(queued)
+	assert.Equal(t, StatusPending, job.Status)
+
+	// Dispatching after the shutdown neither panics nor runs the job
+	late := q.Dispatch("late", func(ctx context.Context, job *Job) error { return nil })
+	job, _ = q.Get(late)
+	assert.Equal(t, StatusPending, job.Status)
+}
+
+func TestQueueShutdown_deadline(t *testing.T) {
+	q := NewQueue(1)
+	q.Start()
+
+	started := make(chan struct{})
+	id := q.Dispatch("slow", func(ctx context.Context, job *Job) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, q.Shutdown(ctx), context.DeadlineExceeded)
+
+	job, err := q.Wait(id, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, job.Status)
+}
+
+func TestQueueShutdown_deadline_stuckJob(t *testing.T) {
+	q := NewQueue(1)
+	q.Start()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	q.Dispatch("stuck", func(ctx context.Context, job *Job) error {
+		close(started)
+		<-release // ignores the cancellation
+		return nil
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- q.Shutdown(ctx) }()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown waited for a job ignoring the cancellation")
+	}
+}
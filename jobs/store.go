@@ -4,19 +4,57 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
 )
 
-// Store provides SQLite-backed persistence for jobs.
-// Jobs are persisted across restarts; pending jobs are re-queued on startup.
-type Store struct {
-	db *sql.DB
+// JobStore persists the jobs of a queue (see WithStore): their status,
+// progress, result and error survive restarts, and the pending ones are
+// re-queued on Start.
+type JobStore interface {
+	// Save inserts or updates a job.
+	Save(job *Job) error
+	// Get returns the job with the ID, nil if there is none.
+	Get(id string) (*Job, error)
+	// LoadPending returns the pending jobs, oldest first.
+	LoadPending() ([]*Job, error)
+	// Query returns the jobs matching filter, most recent first.
+	Query(filter Filter) ([]*Job, error)
+	// DeleteOlderThan removes the finished jobs completed more than d ago.
+	DeleteOlderThan(d time.Duration) (int64, error)
+	// Close releases the resources of the store. The queue calls it on
+	// Shutdown.
+	Close() error
 }
 
-// NewStore opens (or creates) the SQLite database at the given path.
-func NewStore(path string) (*Store, error) {
+// Filter selects jobs (see JobStore.Query and Queue.List). Empty fields match
+// every job.
+type Filter struct {
+	Status   Status
+	TenantID string
+	Queue    string
+	Limit    int // at most Limit jobs, 0 for all
+}
+
+// SQLiteStore persists the jobs in the jobs table of a SQLite database,
+// created if missing. Its queries and migrations use the SQLite dialect:
+// implement JobStore to keep the jobs in another database.
+type SQLiteStore struct {
+	db     *sql.DB
+	ownsDB bool // the store opened db and closes it
+}
+
+// Store is the former name of SQLiteStore.
+//
+// Deprecated: use SQLiteStore, or JobStore for any store.
+type Store = SQLiteStore
+
+// NewStore opens (or creates) the SQLite database at the given path. The
+// store owns the database: Close closes it.
+func NewStore(path string) (*SQLiteStore, error) {
 	db, err := sql.Open("sqlite", path)
 	if err != nil {
 		return nil, fmt.Errorf("jobs: open store: %w", err)
@@ -26,25 +64,50 @@ func NewStore(path string) (*Store, error) {
 		return nil, fmt.Errorf("jobs: ping store: %w", err)
 	}
 
-	s := &Store{db: db}
+	s, err := NewSQLiteStore(db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	s.ownsDB = true
+	return s, nil
+}
+
+// NewSQLiteStore creates a store keeping the jobs in db, a SQLite database
+// such as the one of the application, so the jobs monitor and the users
+// notified of a job see it after a restart:
+//
+//	store, err := jobs.NewSQLiteStore(db)
+//	queue := jobs.NewQueue(4).WithStore(store).WithPruning(30*24*time.Hour, time.Hour)
+//
+// The caller keeps owning db: Close, called by Queue.Shutdown, leaves it
+// open.
+func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
+	s := &SQLiteStore{db: db}
 	if err := s.migrate(); err != nil {
 		return nil, fmt.Errorf("jobs: migrate store: %w", err)
 	}
-
 	return s, nil
 }
 
-// Close closes the underlying database connection.
-func (s *Store) Close() error {
+// Close closes the database opened by NewStore. It does nothing for the
+// database passed to NewSQLiteStore.
+func (s *SQLiteStore) Close() error {
+	if !s.ownsDB {
+		return nil
+	}
 	return s.db.Close()
 }
 
 // migrate creates the jobs table if it does not exist.
-func (s *Store) migrate() error {
+func (s *SQLiteStore) migrate() error {
 	_, err := s.db.Exec(`
 		CREATE TABLE IF NOT EXISTS jobs (
 			id           TEXT PRIMARY KEY,
 			name         TEXT NOT NULL,
+			tenant_id    TEXT NOT NULL DEFAULT '',
+			queue        TEXT NOT NULL DEFAULT 'default',
+			priority     INTEGER NOT NULL DEFAULT 0,
 			status       TEXT NOT NULL DEFAULT 'pending',
 			progress     INTEGER NOT NULL DEFAULT 0,
 			result       TEXT,
@@ -54,11 +117,24 @@ func (s *Store) migrate() error {
 			completed_at DATETIME
 		)
 	`)
-	return err
+	if err != nil {
+		return err
+	}
+	// Stores created before the tenants, then the named queues
+	for _, column := range []string{
+		`tenant_id TEXT NOT NULL DEFAULT ''`,
+		`queue TEXT NOT NULL DEFAULT 'default'`,
+		`priority INTEGER NOT NULL DEFAULT 0`,
+	} {
+		if _, err := s.db.Exec(`ALTER TABLE jobs ADD COLUMN ` + column); err != nil && !strings.Contains(err.Error(), "duplicate column") {
+			return err
+		}
+	}
+	return nil
 }
 
 // Save inserts or updates a job record.
-func (s *Store) Save(job *Job) error {
+func (s *SQLiteStore) Save(job *Job) error {
 	var resultJSON []byte
 	if job.Result != nil {
 		var err error
@@ -75,8 +151,8 @@ func (s *Store) Save(job *Job) error {
 	}
 
 	_, err := s.db.Exec(`
-		INSERT INTO jobs (id, name, status, progress, result, error, created_at, started_at, completed_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO jobs (id, name, tenant_id, queue, priority, status, progress, result, error, created_at, started_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			status       = excluded.status,
 			progress     = excluded.progress,
@@ -87,6 +163,9 @@ func (s *Store) Save(job *Job) error {
 	`,
 		job.ID,
 		job.Name,
+		job.TenantID,
+		job.Queue,
+		int(job.Priority),
 		string(job.Status),
 		job.Progress,
 		nullableBytes(resultJSON),
@@ -98,10 +177,28 @@ func (s *Store) Save(job *Job) error {
 	return err
 }
 
+// jobColumns are the columns read by scanJobs.
+const jobColumns = `id, name, tenant_id, queue, priority, status, progress, result, error, created_at, started_at, completed_at`
+
+// Get returns the job with the ID, nil if there is none.
+func (s *SQLiteStore) Get(id string) (*Job, error) {
+	rows, err := s.db.Query(`SELECT `+jobColumns+` FROM jobs WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs, err := s.scanJobs(rows)
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+	return jobs[0], nil
+}
+
 // LoadPending returns all jobs with status "pending" (to re-queue after restart).
-func (s *Store) LoadPending() ([]*Job, error) {
+func (s *SQLiteStore) LoadPending() ([]*Job, error) {
 	rows, err := s.db.Query(`
-		SELECT id, name, status, progress, result, error, created_at, started_at, completed_at
+		SELECT ` + jobColumns + `
 		FROM jobs
 		WHERE status = 'pending'
 		ORDER BY created_at ASC
@@ -115,12 +212,36 @@ func (s *Store) LoadPending() ([]*Job, error) {
 }
 
 // LoadAll returns all jobs ordered by creation date descending.
-func (s *Store) LoadAll() ([]*Job, error) {
-	rows, err := s.db.Query(`
-		SELECT id, name, status, progress, result, error, created_at, started_at, completed_at
-		FROM jobs
-		ORDER BY created_at DESC
-	`)
+func (s *SQLiteStore) LoadAll() ([]*Job, error) {
+	return s.Query(Filter{})
+}
+
+// Query returns the jobs matching filter, most recent first.
+func (s *SQLiteStore) Query(filter Filter) ([]*Job, error) {
+	var (
+		where []string
+		args  []any
+	)
+	for _, cond := range [][2]string{
+		{"status", string(filter.Status)},
+		{"tenant_id", filter.TenantID},
+		{"queue", filter.Queue},
+	} {
+		if cond[1] != "" {
+			where = append(where, cond[0]+" = ?")
+			args = append(args, cond[1])
+		}
+	}
+	query := `SELECT ` + jobColumns + ` FROM jobs`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY created_at DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ` + strconv.Itoa(filter.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -130,7 +251,7 @@ func (s *Store) LoadAll() ([]*Job, error) {
 }
 
 // DeleteOlderThan removes completed/failed/cancelled jobs older than the given duration.
-func (s *Store) DeleteOlderThan(d time.Duration) (int64, error) {
+func (s *SQLiteStore) DeleteOlderThan(d time.Duration) (int64, error) {
 	threshold := time.Now().Add(-d)
 	result, err := s.db.Exec(`
 		DELETE FROM jobs
@@ -144,13 +265,16 @@ func (s *Store) DeleteOlderThan(d time.Duration) (int64, error) {
 }
 
 // scanJobs scans SQL rows into Job slices.
-func (s *Store) scanJobs(rows *sql.Rows) ([]*Job, error) {
+func (s *SQLiteStore) scanJobs(rows *sql.Rows) ([]*Job, error) {
 	var jobs []*Job
 
 	for rows.Next() {
 		var (
 			id          string
 			name        string
+			tenantID    string
+			queue       string
+			priority    int
 			status      string
 			progress    int
 			resultJSON  sql.NullString
@@ -160,13 +284,16 @@ func (s *Store) scanJobs(rows *sql.Rows) ([]*Job, error) {
 			completedAt sql.NullTime
 		)
 
-		if err := rows.Scan(&id, &name, &status, &progress, &resultJSON, &errStr, &createdAt, &startedAt, &completedAt); err != nil {
+		if err := rows.Scan(&id, &name, &tenantID, &queue, &priority, &status, &progress, &resultJSON, &errStr, &createdAt, &startedAt, &completedAt); err != nil {
 			return nil, err
 		}
 
 		job := &Job{
 			ID:        id,
 			Name:      name,
+			TenantID:  tenantID,
+			Queue:     queue,
+			Priority:  PriorityLevel(priority),
 			Status:    Status(status),
 			Progress:  progress,
 			CreatedAt: createdAt,
@@ -183,6 +310,9 @@ func (s *Store) scanJobs(rows *sql.Rows) ([]*Job, error) {
 		if errStr.Valid {
 			job.Error = fmt.Errorf("%s", errStr.String)
 		}
+		if resultJSON.Valid {
+			job.Result = json.RawMessage(resultJSON.String)
+		}
 
 		jobs = append(jobs, job)
 	}
@@ -0,0 +1,87 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteStore_survivesRestart(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "jobs.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	q := NewQueue(1).WithStore(store)
+	q.Start()
+	id := q.DispatchForTenant(context.Background(), "acme", "export", func(ctx context.Context, job *Job) error {
+		job.SetResult(map[string]int{"rows": 3})
+		return nil
+	}, OnQueue("exports"))
+	_, err = q.Wait(id, time.Second)
+	require.NoError(t, err)
+	q.Stop()
+
+	restarted := NewQueue(1).WithStore(store)
+	job, ok := restarted.Get(id)
+	require.True(t, ok, "the finished job should be found in the store")
+	assert.Equal(t, StatusCompleted, job.Status)
+	assert.Equal(t, "exports", job.Queue)
+	assert.JSONEq(t, `{"rows":3}`, string(job.Result.(json.RawMessage)))
+
+	list, err := restarted.List(Filter{TenantID: "acme", Status: StatusCompleted})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, id, list[0].ID)
+
+	list, err = restarted.List(Filter{TenantID: "other"})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestQueue_Prune(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "jobs.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	old := time.Now().Add(-2 * time.Hour)
+	recent := time.Now()
+	require.NoError(t, store.Save(&Job{ID: "old", Name: "a", Status: StatusCompleted, CreatedAt: old, CompletedAt: &old}))
+	require.NoError(t, store.Save(&Job{ID: "recent", Name: "b", Status: StatusFailed, CreatedAt: recent, CompletedAt: &recent}))
+	require.NoError(t, store.Save(&Job{ID: "pending", Name: "c", Status: StatusPending, CreatedAt: old}))
+
+	q := NewQueue(1).WithStore(store)
+	n, err := q.Prune(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	list, err := store.Query(Filter{})
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+	_, ok := q.Get("old")
+	assert.False(t, ok)
+}
+
+func TestSQLiteStore_Close_ownership(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "app.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	store, err := NewSQLiteStore(db)
+	require.NoError(t, err)
+	q := NewQueue(1).WithStore(store)
+	q.Start()
+	require.NoError(t, q.Shutdown(context.Background()))
+	assert.NoError(t, db.Ping(), "the database of the application should stay open")
+
+	var owned *Store // former name, kept as an alias
+	owned, err = NewStore(filepath.Join(t.TempDir(), "jobs.db"))
+	require.NoError(t, err)
+	require.NoError(t, owned.Close())
+	assert.Error(t, owned.db.Ping(), "the database opened by NewStore should be closed")
+}
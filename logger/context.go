@@ -22,12 +22,13 @@ func WithContext(ctx context.Context, l *Logger) context.Context {
 	return context.WithValue(ctx, loggerKey, l)
 }
 
-// FromContext retrieves the logger from the context.
+// FromContext retrieves the logger from the context, enriched with the
+// context attributes (request ID, tenant, user...) of the enricher pipeline.
 func FromContext(ctx context.Context) *Logger {
 	if l, ok := ctx.Value(loggerKey).(*Logger); ok {
-		return l
+		return l.Ctx(ctx)
 	}
-	return Default()
+	return Default().Ctx(ctx)
 }
 
 // WithRequestID adds a unique ID to the request.
@@ -36,6 +37,12 @@ func WithRequestID(ctx context.Context) (context.Context, string) {
 	return context.WithValue(ctx, requestIDKey, requestID), requestID
 }
 
+// ContextWithRequestID stores an existing request ID (e.g. received in an
+// X-Request-ID header) in the context.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
 // RequestIDFromContext retrieves the request ID.
 func RequestIDFromContext(ctx context.Context) string {
 	if id, ok := ctx.Value(requestIDKey).(string); ok {
@@ -45,11 +52,18 @@ func RequestIDFromContext(ctx context.Context) string {
 }
 
 // Middleware HTTP that adds the logger to each request's context.
-func Middleware(l *Logger) func(http.Handler) http.Handler {
+//
+//	router.Use(logger.Middleware(log, logger.SlowRequestThreshold(time.Second)))
+func Middleware(l *Logger, opts ...MiddlewareOption) func(http.Handler) http.Handler {
 	if l == nil {
 		l = Default()
 	}
 
+	options := &middlewareOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
@@ -70,11 +84,13 @@ func Middleware(l *Logger) func(http.Handler) http.Handler {
 			ctx = WithContext(ctx, reqLogger)
 
 			rw := &responseWriter{ResponseWriter: w, statusCode: 200}
+			req := TrackRequest(r.WithContext(ctx))
 
-			next.ServeHTTP(rw, r.WithContext(ctx))
+			next.ServeHTTP(rw, req)
 
 			duration := time.Since(start)
 			reqLogger.Request(r.Method, r.URL.Path, rw.statusCode, duration)
+			reqLogger.SlowRequest(req, duration, options.slowThreshold)
 		})
 	}
 }
@@ -11,6 +11,13 @@
 //   - Context-aware logging
 //   - HTTP request logging middleware
 //   - Source file information
+//   - Context enrichers (request, tenant and user IDs on every line)
+//   - Runtime level changes (SetLevel, LevelHandler)
+//   - Sinks with per-sink levels: stdout/stderr, rotating file (size and
+//     age), syslog and OTLP/HTTP, configured from logging.sinks in config.yaml
+//   - Slow request and slow query (Ent) warnings with route and resource
+//   - Redaction of sensitive keys (password, token, card...) and patterns,
+//     including inside nested maps and slices
 //
 // Basic usage:
 //
@@ -27,6 +34,37 @@
 //	// With context
 //	logger.With("user_id", userID).Info("user logged in")
 //
-//	// HTTP middleware
-//	router.Use(logger.Middleware(logger))
+//	// HTTP middleware, warning about requests slower than 1s
+//	router.Use(logger.Middleware(logger, logger.SlowRequestThreshold(time.Second)))
+//
+//	// Mask more keys and patterns (password, token, card... are built in)
+//	logger.RegisterSensitiveKeys("otp_code")
+//	logger.RegisterSensitivePattern(regexp.MustCompile(`ACCT-\d+`))
+//	logger.Info("form submitted", "form", logger.Redact(r.PostForm))
+//
+//	// Warn about Ent queries slower than 200ms
+//	client := ent.NewClient(ent.Driver(logger.SlowQueryDriver(drv, 200*time.Millisecond)))
+//
+//	// Context-aware logging: request_id, tenant_id and user_id are added
+//	logger.FromContext(ctx).Info("order shipped", "order_id", id)
+//
+//	// Custom enricher
+//	logger.RegisterEnricher("plan", func(ctx context.Context) []slog.Attr {
+//		return []slog.Attr{slog.String("plan", planFromContext(ctx))}
+//	})
+//
+//	// Change the level at runtime (also exposed by logger.LevelHandler)
+//	logger.SetLevel(slog.LevelDebug)
+//
+//	// Fan out to several sinks (or logger.Open(cfg.LoggerConfig()) from
+//	// config.yaml); close them on shutdown
+//	log, err := logger.Open(&logger.Config{
+//		Level: slog.LevelInfo,
+//		Sinks: []logger.SinkConfig{
+//			{Type: logger.SinkStdout},
+//			{Type: logger.SinkFile, Path: "app.log", RotateEvery: 24 * time.Hour},
+//			{Type: logger.SinkOTLP, Endpoint: "http://collector:4318", Level: "error"},
+//		},
+//	})
+//	defer log.Close()
 package logger
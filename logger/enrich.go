@@ -0,0 +1,145 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Enricher extracts attributes from a context (request ID, tenant, user...).
+// It must be cheap and return nil when the context carries nothing.
+type Enricher func(ctx context.Context) []slog.Attr
+
+type namedEnricher struct {
+	name string
+	fn   Enricher
+}
+
+var (
+	enrichersMu sync.RWMutex
+	enrichers   = []namedEnricher{{name: "request_id", fn: requestIDEnricher}}
+)
+
+// RegisterEnricher adds an enricher to the pipeline run for every log line
+// written with a context (InfoContext, FromContext(ctx).Info...).
+// Registering an existing name replaces it in place. The framework
+// registers "request_id", "tenant_id" (engine) and "user_id" (auth).
+func RegisterEnricher(name string, fn Enricher) {
+	enrichersMu.Lock()
+	defer enrichersMu.Unlock()
+
+	for i, e := range enrichers {
+		if e.name == name {
+			enrichers[i].fn = fn
+			return
+		}
+	}
+	enrichers = append(enrichers, namedEnricher{name: name, fn: fn})
+}
+
+// UnregisterEnricher removes an enricher.
+func UnregisterEnricher(name string) {
+	enrichersMu.Lock()
+	defer enrichersMu.Unlock()
+
+	for i, e := range enrichers {
+		if e.name == name {
+			enrichers = append(enrichers[:i], enrichers[i+1:]...)
+			return
+		}
+	}
+}
+
+// Enrich runs the enricher pipeline on ctx.
+func Enrich(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+
+	enrichersMu.RLock()
+	defer enrichersMu.RUnlock()
+
+	var attrs []slog.Attr
+	for _, e := range enrichers {
+		attrs = append(attrs, e.fn(ctx)...)
+	}
+	return attrs
+}
+
+func requestIDEnricher(ctx context.Context) []slog.Attr {
+	if id := RequestIDFromContext(ctx); id != "" {
+		return []slog.Attr{slog.String("request_id", id)}
+	}
+	return nil
+}
+
+// enrichHandler adds enricher attributes to records logged with a context.
+// Keys already attached with With are not repeated.
+type enrichHandler struct {
+	slog.Handler
+	preset map[string]bool
+	group  bool
+}
+
+func newEnrichHandler(h slog.Handler) *enrichHandler {
+	return &enrichHandler{Handler: h, preset: map[string]bool{}}
+}
+
+// Handle implements slog.Handler.
+func (h *enrichHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.group {
+		seen := map[string]bool{}
+		r.Attrs(func(a slog.Attr) bool {
+			seen[a.Key] = true
+			return true
+		})
+		for _, a := range Enrich(ctx) {
+			if !h.preset[a.Key] && !seen[a.Key] {
+				r.AddAttrs(a)
+				seen[a.Key] = true
+			}
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *enrichHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	preset := make(map[string]bool, len(h.preset)+len(attrs))
+	for k := range h.preset {
+		preset[k] = true
+	}
+	for _, a := range attrs {
+		preset[a.Key] = true
+	}
+	return &enrichHandler{Handler: h.Handler.WithAttrs(attrs), preset: preset, group: h.group}
+}
+
+// WithGroup implements slog.Handler. Enrichment is skipped inside groups so
+// context attributes stay at the top level of the line.
+func (h *enrichHandler) WithGroup(name string) slog.Handler {
+	return &enrichHandler{Handler: h.Handler.WithGroup(name), preset: h.preset, group: true}
+}
+
+// Ctx returns a logger carrying the enricher attributes of ctx, so plain
+// Info/Error calls include them too.
+func (l *Logger) Ctx(ctx context.Context) *Logger {
+	preset := map[string]bool{}
+	if h, ok := l.Handler().(*enrichHandler); ok {
+		if h.group {
+			return l
+		}
+		preset = h.preset
+	}
+
+	var attrs []any
+	for _, a := range Enrich(ctx) {
+		if !preset[a.Key] {
+			attrs = append(attrs, a)
+		}
+	}
+	if len(attrs) == 0 {
+		return l
+	}
+	return l.With(attrs...)
+}
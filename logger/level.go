@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Level returns the current minimum level.
+func (l *Logger) Level() slog.Level {
+	if l.level == nil {
+		if l.config != nil {
+			return l.config.Level
+		}
+		return slog.LevelInfo
+	}
+	return l.level.Level()
+}
+
+// SetLevel changes the minimum level at runtime. Loggers derived with
+// With/WithGroup/Ctx share the level of their parent. Returns false for
+// loggers not created with New.
+func (l *Logger) SetLevel(level slog.Level) bool {
+	if l.level == nil {
+		return false
+	}
+	l.level.Set(level)
+	return true
+}
+
+// SetLevel changes the level of the default logger at runtime.
+func SetLevel(level slog.Level) bool {
+	return Default().SetLevel(level)
+}
+
+// LevelName returns the lowercase name of a level ("debug", "info"...).
+func LevelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
+
+// LevelHandler returns an HTTP endpoint reading (GET) and changing
+// (PUT/POST) the level of l at runtime. A nil l uses the default logger.
+// The body is JSON ({"level":"debug"}) or a "level" form value.
+// Mount it behind admin authentication:
+//
+//	mux.Handle("/admin/api/log-level", requireAdmin(logger.LevelHandler(nil)))
+func LevelHandler(l *Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := l
+		if target == nil {
+			target = Default()
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var body struct {
+				Level string `json:"level"`
+			}
+			if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					http.Error(w, "invalid JSON body", http.StatusBadRequest)
+					return
+				}
+			} else {
+				body.Level = r.FormValue("level")
+			}
+
+			name := strings.ToLower(strings.TrimSpace(body.Level))
+			if _, ok := levelNames[name]; !ok {
+				http.Error(w, "level must be one of: debug, info, warn, error", http.StatusBadRequest)
+				return
+			}
+			if !target.SetLevel(ParseLevel(name)) {
+				http.Error(w, "logger level is not adjustable", http.StatusConflict)
+				return
+			}
+			FromContext(r.Context()).Info("log level changed", slog.String("level", name))
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"level": LevelName(target.Level())})
+	})
+}
+
+var levelNames = map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
@@ -2,12 +2,11 @@ package logger
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
-	"os"
 	"time"
-
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // Config contains the logger configuration.
@@ -21,6 +20,10 @@ type Config struct {
 	MaxBackups     int
 	MaxAgeDays     int
 	Compress       bool
+
+	// Sinks replaces the stdout/OutputPath destinations above with a list of
+	// sinks, each with its own minimum level. See SinkConfig.
+	Sinks []SinkConfig
 }
 
 // DefaultConfig returns a default configuration.
@@ -41,72 +44,131 @@ func DefaultConfig() *Config {
 // Logger wraps slog.Logger with helper methods.
 type Logger struct {
 	*slog.Logger
-	config *Config
+	config  *Config
+	level   *slog.LevelVar // shared by derived loggers, nil if not created with New
+	closers []io.Closer
 }
 
-// New creates a new configured logger.
+// New creates a new configured logger. Sinks that cannot be opened are
+// skipped and reported on the remaining ones; use Open to get the error.
 func New(cfg *Config) *Logger {
+	l, err := Open(cfg)
+	if err != nil {
+		l.Warn("some log sinks could not be opened", Err(err))
+	}
+	return l
+}
+
+// Open creates a logger like New and returns the errors of the sinks that
+// could not be opened. The returned logger is always usable: it falls back
+// to stdout when no sink could be opened.
+func Open(cfg *Config) (*Logger, error) {
 	if cfg == nil {
 		cfg = DefaultConfig()
 	}
 
-	var writer io.Writer = os.Stdout
+	level := new(slog.LevelVar)
+	level.Set(cfg.Level)
+
+	var (
+		sinks []Sink
+		errs  []error
+	)
+	for _, sc := range cfg.sinkConfigs() {
+		sink, err := NewSink(sc, level, cfg.Environment, cfg.AddSource)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sinks = append(sinks, sink)
+	}
+	if len(sinks) == 0 {
+		sink, _ := NewSink(SinkConfig{Type: SinkStdout}, level, cfg.Environment, cfg.AddSource)
+		sinks = append(sinks, sink)
+	}
 
-	if cfg.OutputPath != "" {
-		var fileWriter io.Writer
+	return NewWithSinks(cfg, level, sinks...), errors.Join(errs...)
+}
 
-		if cfg.EnableRotation {
-			fileWriter = &lumberjack.Logger{
-				Filename:   cfg.OutputPath,
-				MaxSize:    cfg.MaxSizeMB,
-				MaxBackups: cfg.MaxBackups,
-				MaxAge:     cfg.MaxAgeDays,
-				Compress:   cfg.Compress,
-			}
-		} else {
-			file, err := os.OpenFile(cfg.OutputPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-			if err != nil {
-				fileWriter = os.Stdout
-			} else {
-				fileWriter = file
-			}
-		}
+// NewWithSinks creates a logger writing to the given sinks, for custom
+// slog.Handler destinations. level gates all sinks; nil uses cfg.Level.
+// Sensitive attributes are masked before reaching the sinks (see Redact).
+func NewWithSinks(cfg *Config, level *slog.LevelVar, sinks ...Sink) *Logger {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if level == nil {
+		level = new(slog.LevelVar)
+		level.Set(cfg.Level)
+	}
 
-		writer = io.MultiWriter(os.Stdout, fileWriter)
+	var closers []io.Closer
+	for _, s := range sinks {
+		if s.Closer != nil {
+			closers = append(closers, s.Closer)
+		}
 	}
 
-	var handler slog.Handler
+	return &Logger{
+		Logger:  slog.New(newEnrichHandler(newRedactHandler(newFanoutHandler(level, sinks)))),
+		config:  cfg,
+		level:   level,
+		closers: closers,
+	}
+}
 
-	opts := &slog.HandlerOptions{
-		Level:     cfg.Level,
-		AddSource: cfg.AddSource,
+// sinkConfigs returns cfg.Sinks, or the sinks equivalent to the legacy
+// fields (stdout, plus OutputPath when set).
+func (c *Config) sinkConfigs() []SinkConfig {
+	if len(c.Sinks) > 0 {
+		return c.Sinks
 	}
 
-	if cfg.Environment == "prod" || cfg.Environment == "production" {
-		handler = slog.NewJSONHandler(writer, opts)
-	} else {
-		handler = slog.NewTextHandler(writer, opts)
+	sinks := []SinkConfig{{Type: SinkStdout}}
+	if c.OutputPath != "" {
+		file := SinkConfig{Type: SinkFile, Path: c.OutputPath}
+		if c.EnableRotation {
+			file.MaxSizeMB = c.MaxSizeMB
+			file.MaxBackups = c.MaxBackups
+			file.MaxAgeDays = c.MaxAgeDays
+			file.Compress = c.Compress
+		} else {
+			file.MaxSizeMB = -1
+		}
+		sinks = append(sinks, file)
 	}
+	return sinks
+}
 
-	return &Logger{
-		Logger: slog.New(handler),
-		config: cfg,
+// Close flushes and closes the sinks of the logger (files, syslog and OTLP
+// connections). Call it on shutdown; derived loggers share the same sinks.
+func (l *Logger) Close() error {
+	var errs []error
+	for _, c := range l.closers {
+		if err := c.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("logger: close sink: %w", err))
+		}
 	}
+	return errors.Join(errs...)
 }
 
 // With returns a new logger with default attributes.
 func (l *Logger) With(attrs ...any) *Logger {
 	return &Logger{
-		Logger: l.Logger.With(attrs...),
-		config: l.config,
+		Logger:  l.Logger.With(attrs...),
+		config:  l.config,
+		level:   l.level,
+		closers: l.closers,
 	}
 }
 
 // WithGroup returns a new logger in a group.
 func (l *Logger) WithGroup(name string) *Logger {
 	return &Logger{
-		Logger: l.Logger.WithGroup(name),
-		config: l.config,
+		Logger:  l.Logger.WithGroup(name),
+		config:  l.config,
+		level:   l.level,
+		closers: l.closers,
 	}
 }
 
@@ -3,13 +3,17 @@ package logger
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"entgo.io/ent/dialect"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -286,3 +290,265 @@ func BenchmarkRequest(b *testing.B) {
 		logger.Request("GET", "/api/test", 200, 10*time.Millisecond)
 	}
 }
+
+func newBufferLogger(buf *bytes.Buffer) *Logger {
+	level := new(slog.LevelVar)
+	handler := slog.NewTextHandler(buf, &slog.HandlerOptions{Level: level})
+	return &Logger{Logger: slog.New(newEnrichHandler(handler)), config: DefaultConfig(), level: level}
+}
+
+func TestEnrichers(t *testing.T) {
+	var buf bytes.Buffer
+	l := newBufferLogger(&buf)
+
+	RegisterEnricher("tenant_test", func(ctx context.Context) []slog.Attr {
+		if v, ok := ctx.Value(contextKey("tenant_test")).(string); ok {
+			return []slog.Attr{slog.String("tenant_test", v)}
+		}
+		return nil
+	})
+	defer UnregisterEnricher("tenant_test")
+
+	ctx := ContextWithRequestID(context.Background(), "req-1")
+	ctx = context.WithValue(ctx, contextKey("tenant_test"), "acme")
+
+	l.InfoContext(ctx, "context method")
+	assert.Contains(t, buf.String(), "request_id=req-1")
+	assert.Contains(t, buf.String(), "tenant_test=acme")
+
+	buf.Reset()
+	l.Ctx(ctx).Info("plain method")
+	assert.Contains(t, buf.String(), "request_id=req-1")
+
+	buf.Reset()
+	l.With("request_id", "req-1").InfoContext(ctx, "no duplicates")
+	assert.Equal(t, 1, strings.Count(buf.String(), "request_id="))
+
+	buf.Reset()
+	FromContext(WithContext(ctx, l)).Info("from context")
+	assert.Contains(t, buf.String(), "tenant_test=acme")
+}
+
+func TestSetLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := newBufferLogger(&buf)
+	child := l.With("component", "test")
+
+	require.True(t, l.SetLevel(slog.LevelWarn))
+	child.Info("hidden")
+	assert.Empty(t, buf.String())
+	assert.Equal(t, slog.LevelWarn, child.Level())
+
+	l.SetLevel(slog.LevelDebug)
+	child.Debug("visible")
+	assert.Contains(t, buf.String(), "visible")
+
+	legacy := &Logger{Logger: slog.Default()}
+	assert.False(t, legacy.SetLevel(slog.LevelError))
+}
+
+func TestLevelHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := newBufferLogger(&buf)
+	handler := LevelHandler(l)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/log-level", nil))
+	assert.JSONEq(t, `{"level":"info"}`, rec.Body.String())
+
+	req := httptest.NewRequest(http.MethodPut, "/log-level", strings.NewReader(`{"level":"debug"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"level":"debug"}`, rec.Body.String())
+	assert.Equal(t, slog.LevelDebug, l.Level())
+
+	req = httptest.NewRequest(http.MethodPost, "/log-level", strings.NewReader("level=verbose"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/log-level", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestSinksFanOutByLevel(t *testing.T) {
+	var all, errs bytes.Buffer
+	level := new(slog.LevelVar)
+	level.Set(slog.LevelInfo)
+
+	l := NewWithSinks(DefaultConfig(), level,
+		Sink{Name: "all", Handler: slog.NewTextHandler(&all, &slog.HandlerOptions{Level: level})},
+		Sink{Name: "errors", Handler: slog.NewTextHandler(&errs, &slog.HandlerOptions{Level: slog.LevelError})},
+	)
+
+	l.Debug("hidden")
+	l.Info("started")
+	l.Error("failed")
+
+	assert.NotContains(t, all.String(), "hidden")
+	assert.Contains(t, all.String(), "started")
+	assert.Contains(t, all.String(), "failed")
+	assert.NotContains(t, errs.String(), "started")
+	assert.Contains(t, errs.String(), "failed")
+
+	// The logger level gates every sink.
+	l.SetLevel(slog.LevelDebug)
+	l.Debug("visible")
+	assert.Contains(t, all.String(), "visible")
+	assert.NotContains(t, errs.String(), "visible")
+}
+
+func TestOpenFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	l, err := Open(&Config{
+		Level: slog.LevelInfo,
+		Sinks: []SinkConfig{{Type: SinkFile, Path: path, Format: "json"}},
+	})
+	require.NoError(t, err)
+
+	l.Info("written to file", slog.String("key", "value"))
+	require.NoError(t, l.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"msg":"written to file"`)
+	assert.Contains(t, string(data), `"key":"value"`)
+}
+
+func TestOpenReportsBadSinks(t *testing.T) {
+	l, err := Open(&Config{Sinks: []SinkConfig{{Type: "kafka"}}})
+	assert.Error(t, err)
+	require.NotNil(t, l, "falls back to stdout")
+}
+
+func TestRotatingWriterRotatesOnAge(t *testing.T) {
+	dir := t.TempDir()
+	w := NewRotatingWriter(SinkConfig{Path: filepath.Join(dir, "app.log"), RotateEvery: time.Hour})
+	defer w.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+
+	_, err := w.Write([]byte("first\n"))
+	require.NoError(t, err)
+	now = now.Add(30 * time.Minute)
+	_, err = w.Write([]byte("second\n"))
+	require.NoError(t, err)
+
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 1)
+
+	now = now.Add(time.Hour)
+	_, err = w.Write([]byte("third\n"))
+	require.NoError(t, err)
+
+	entries, _ = os.ReadDir(dir)
+	assert.Len(t, entries, 2)
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "third\n", string(data))
+}
+
+func TestOTLPSink(t *testing.T) {
+	received := make(chan otlpLogsRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/logs", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+
+		var req otlpLogsRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		received <- req
+	}))
+	defer srv.Close()
+
+	sink, err := NewSink(SinkConfig{
+		Type:        SinkOTLP,
+		Endpoint:    srv.URL,
+		Headers:     map[string]string{"X-Api-Key": "secret"},
+		ServiceName: "admin",
+		Level:       "warn",
+	}, nil, "", false)
+	require.NoError(t, err)
+
+	l := NewWithSinks(DefaultConfig(), nil, sink)
+	l.Info("dropped")
+	l.WithGroup("db").Warn("slow query", slog.Int("rows", 42))
+	require.NoError(t, l.Close())
+
+	req := <-received
+	require.Len(t, req.ResourceLogs, 1)
+	assert.Equal(t, "admin", *req.ResourceLogs[0].Resource.Attributes[0].Value.StringValue)
+
+	records := req.ResourceLogs[0].ScopeLogs[0].LogRecords
+	require.Len(t, records, 1)
+	assert.Equal(t, "slow query", *records[0].Body.StringValue)
+	assert.Equal(t, 13, records[0].SeverityNumber)
+	require.Len(t, records[0].Attributes, 1)
+	assert.Equal(t, "db.rows", records[0].Attributes[0].Key)
+	assert.Equal(t, "42", *records[0].Attributes[0].Value.IntValue)
+}
+
+func TestSlowRequestThreshold(t *testing.T) {
+	var buf bytes.Buffer
+	l := newBufferLogger(&buf)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		SetResource(r.Context(), "users")
+		time.Sleep(5 * time.Millisecond)
+	})
+	mux.HandleFunc("GET /fast", func(w http.ResponseWriter, r *http.Request) {})
+
+	handler := Middleware(l, SlowRequestThreshold(time.Millisecond))(mux)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fast", nil))
+	assert.NotContains(t, buf.String(), "slow request")
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	out := buf.String()
+	assert.Contains(t, out, `msg="slow request"`)
+	assert.Contains(t, out, `route="GET /users/{id}"`)
+	assert.Contains(t, out, "resource=users")
+	assert.Contains(t, out, "threshold=1ms")
+}
+
+type fakeDriver struct {
+	dialect.Driver
+	delay time.Duration
+}
+
+func (d fakeDriver) Query(ctx context.Context, query string, args, v any) error {
+	time.Sleep(d.delay)
+	return nil
+}
+
+func TestSlowQueryDriver(t *testing.T) {
+	var buf bytes.Buffer
+	prev := Default()
+	SetDefault(newBufferLogger(&buf))
+	defer SetDefault(prev)
+
+	req := TrackRequest(httptest.NewRequest(http.MethodGet, "/posts", nil))
+	SetResource(req.Context(), "posts")
+	SetRoute(req.Context(), "GET /posts")
+
+	fast := SlowQueryDriver(fakeDriver{}, 50*time.Millisecond)
+	require.NoError(t, fast.Query(req.Context(), "SELECT 1", nil, nil))
+	assert.Empty(t, buf.String())
+
+	slow := SlowQueryDriver(fakeDriver{delay: 5 * time.Millisecond}, time.Millisecond)
+	require.NoError(t, slow.Query(req.Context(), "SELECT * FROM posts", []any{"secret"}, nil))
+
+	out := buf.String()
+	assert.Contains(t, out, `msg="slow query"`)
+	assert.Contains(t, out, `query="SELECT * FROM posts"`)
+	assert.Contains(t, out, "resource=posts")
+	assert.Contains(t, out, `route="GET /posts"`)
+	assert.NotContains(t, out, "secret", "arguments are not logged")
+}
@@ -0,0 +1,276 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RedactedValue replaces sensitive values in log output.
+const RedactedValue = "[REDACTED]"
+
+var (
+	redactMu sync.RWMutex
+	// sensitiveKeys are normalized (lowercase, no separators) and matched as
+	// substrings of normalized attribute keys: "password" also masks
+	// "new_password" and "PasswordConfirmation".
+	sensitiveKeys = []string{
+		"password", "passwd", "secret", "token", "apikey", "authorization",
+		"cookie", "card", "cvv", "cvc", "ssn", "iban",
+	}
+	sensitivePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\bbearer\s+[\w.~+/=-]+`),
+	}
+	keyValuePattern = buildKeyValuePattern(sensitiveKeys)
+	// 15 to 19 digits, so millisecond timestamps (13 digits) are left alone.
+	cardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){14,18}\b`)
+)
+
+// RegisterSensitiveKeys adds attribute keys whose values are masked in every
+// log line. Matching ignores case and "_", "-", "." separators, and also
+// applies to keys containing the registered one.
+func RegisterSensitiveKeys(keys ...string) {
+	redactMu.Lock()
+	defer redactMu.Unlock()
+
+	for _, k := range keys {
+		if n := normalizeKey(k); n != "" && !containsString(sensitiveKeys, n) {
+			sensitiveKeys = append(sensitiveKeys, n)
+		}
+	}
+	keyValuePattern = buildKeyValuePattern(sensitiveKeys)
+}
+
+// RegisterSensitivePattern masks every match of pattern inside string
+// values, whatever their key (e.g. internal account numbers).
+func RegisterSensitivePattern(pattern *regexp.Regexp) {
+	redactMu.Lock()
+	defer redactMu.Unlock()
+	sensitivePatterns = append(sensitivePatterns, pattern)
+}
+
+// IsSensitiveKey reports whether values logged under key are masked.
+func IsSensitiveKey(key string) bool {
+	n := normalizeKey(key)
+	if n == "" {
+		return false
+	}
+
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+
+	for _, k := range sensitiveKeys {
+		if strings.Contains(n, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns a copy of v with sensitive values masked. Maps with string
+// keys and slices are walked recursively (form payloads, url.Values, decoded
+// JSON); strings are scanned for "key=value" pairs of sensitive keys, card
+// numbers and registered patterns. Other values are returned unchanged.
+func Redact(v any) any {
+	return redactValue(reflect.ValueOf(v), 0)
+}
+
+// RedactString masks sensitive fragments of s: values of sensitive keys in
+// query strings ("token=abc"), card numbers and registered patterns.
+func RedactString(s string) string {
+	redactMu.RLock()
+	kv, patterns := keyValuePattern, sensitivePatterns
+	redactMu.RUnlock()
+
+	for _, p := range patterns {
+		s = p.ReplaceAllString(s, RedactedValue)
+	}
+	s = cardPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if luhnValid(m) {
+			return RedactedValue
+		}
+		return m
+	})
+	return kv.ReplaceAllString(s, "${1}"+RedactedValue)
+}
+
+// RedactAttr masks an attribute: the whole value when its key is sensitive,
+// sensitive fragments otherwise. Groups are redacted recursively.
+func RedactAttr(a slog.Attr) slog.Attr {
+	if IsSensitiveKey(a.Key) {
+		return slog.String(a.Key, RedactedValue)
+	}
+
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, RedactString(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			attrs[i] = RedactAttr(ga)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, RedactString(err.Error()))
+		}
+		return slog.Any(a.Key, Redact(v.Any()))
+	default:
+		return slog.Attr{Key: a.Key, Value: v}
+	}
+}
+
+// maxRedactDepth stops the walk on deeply nested or cyclic values.
+const maxRedactDepth = 32
+
+func redactValue(rv reflect.Value, depth int) any {
+	if !rv.IsValid() {
+		return nil
+	}
+	if depth > maxRedactDepth {
+		return RedactedValue
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		return RedactString(rv.String())
+	case reflect.Interface, reflect.Pointer:
+		if rv.IsNil() {
+			return rv.Interface()
+		}
+		elem := rv.Elem()
+		if k := elem.Kind(); k == reflect.Map || k == reflect.Slice || k == reflect.Array || k == reflect.String || k == reflect.Interface {
+			return redactValue(elem, depth+1)
+		}
+		return rv.Interface()
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String || rv.IsNil() {
+			return rv.Interface()
+		}
+		out := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if IsSensitiveKey(key) {
+				out[key] = RedactedValue
+				continue
+			}
+			out[key] = redactValue(iter.Value(), depth+1)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return rv.Interface() // []byte
+		}
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return rv.Interface()
+		}
+		out := make([]any, rv.Len())
+		for i := range out {
+			out[i] = redactValue(rv.Index(i), depth+1)
+		}
+		return out
+	default:
+		if rv.CanInterface() {
+			return rv.Interface()
+		}
+		return nil
+	}
+}
+
+// redactHandler masks sensitive attributes before they reach the sinks.
+type redactHandler struct {
+	slog.Handler
+}
+
+func newRedactHandler(h slog.Handler) *redactHandler {
+	return &redactHandler{Handler: h}
+}
+
+// Handle implements slog.Handler.
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, RedactString(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(RedactAttr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, out)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = RedactAttr(a)
+	}
+	return &redactHandler{Handler: h.Handler.WithAttrs(redacted)}
+}
+
+// WithGroup implements slog.Handler.
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '-', '.', ' ':
+			return -1
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return r
+	}, key)
+}
+
+// buildKeyValuePattern matches "key=value" and "key: value" pairs whose key
+// contains a sensitive key, capturing the key and separator. Separators are
+// allowed inside keys so "apikey" also matches "api_key=".
+func buildKeyValuePattern(keys []string) *regexp.Regexp {
+	alternatives := make([]string, len(keys))
+	for i, k := range keys {
+		chars := make([]string, 0, len(k))
+		for _, r := range k {
+			chars = append(chars, regexp.QuoteMeta(string(r)))
+		}
+		alternatives[i] = strings.Join(chars, `[_.-]?`)
+	}
+	return regexp.MustCompile(`(?i)(\b[\w.-]*(?:` + strings.Join(alternatives, "|") + `)[\w.-]*\s*[=:]\s*)[^&\s,;"]+`)
+}
+
+// luhnValid reports whether the digits of s pass the Luhn checksum used by
+// payment card numbers, to avoid masking ordinary long numbers.
+func luhnValid(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSensitiveKey(t *testing.T) {
+	for _, key := range []string{"password", "new_password", "PasswordConfirmation", "api-key", "csrf_token", "card_number", "Authorization"} {
+		assert.True(t, IsSensitiveKey(key), key)
+	}
+	for _, key := range []string{"email", "name", "status", ""} {
+		assert.False(t, IsSensitiveKey(key), key)
+	}
+}
+
+func TestRedactNestedMapsAndSlices(t *testing.T) {
+	payload := map[string]any{
+		"email":    "jane@example.com",
+		"password": "hunter2",
+		"profile": map[string]any{
+			"name":      "Jane",
+			"api_token": "abc123",
+			"cards": []any{
+				map[string]string{"card_number": "4111111111111111", "label": "main"},
+			},
+		},
+		"addresses": []map[string]any{
+			{"city": "Paris", "secret": "x"},
+		},
+		"notes": []string{"paid with 4111 1111 1111 1111", "ok"},
+	}
+
+	got := Redact(payload).(map[string]any)
+
+	assert.Equal(t, "jane@example.com", got["email"])
+	assert.Equal(t, RedactedValue, got["password"])
+
+	profile := got["profile"].(map[string]any)
+	assert.Equal(t, "Jane", profile["name"])
+	assert.Equal(t, RedactedValue, profile["api_token"])
+	assert.Equal(t, RedactedValue, profile["cards"], "the key contains \"card\"")
+
+	addresses := got["addresses"].([]any)
+	require.Len(t, addresses, 1)
+	assert.Equal(t, map[string]any{"city": "Paris", "secret": RedactedValue}, addresses[0])
+
+	assert.Equal(t, []any{"paid with " + RedactedValue, "ok"}, got["notes"])
+
+	// The input is not modified.
+	assert.Equal(t, "hunter2", payload["password"])
+}
+
+func TestRedactFormValues(t *testing.T) {
+	form := url.Values{"username": {"jane"}, "password": {"hunter2"}, "password_confirmation": {"hunter2"}}
+
+	got := Redact(form).(map[string]any)
+
+	assert.Equal(t, []any{"jane"}, got["username"])
+	assert.Equal(t, RedactedValue, got["password"])
+	assert.Equal(t, RedactedValue, got["password_confirmation"])
+}
+
+func TestRedactString(t *testing.T) {
+	assert.Equal(t, "page=2&token="+RedactedValue+"&sort=name", RedactString("page=2&token=abc&sort=name"))
+	assert.Equal(t, "Authorization: "+RedactedValue, RedactString("Authorization: Bearer eyJhbGciOi.x.y"))
+	assert.Equal(t, "card "+RedactedValue, RedactString("card 4111-1111-1111-1111"))
+	assert.Equal(t, "order 1700000000000", RedactString("order 1700000000000"), "not a card number")
+	assert.Equal(t, "id 1234567890123456", RedactString("id 1234567890123456"), "fails the Luhn check")
+}
+
+func TestRegisterSensitivePattern(t *testing.T) {
+	keys, patterns := sensitiveKeys, sensitivePatterns
+	t.Cleanup(func() {
+		sensitiveKeys, sensitivePatterns = keys, patterns
+		keyValuePattern = buildKeyValuePattern(keys)
+	})
+
+	RegisterSensitivePattern(regexp.MustCompile(`ACCT-\d+`))
+	RegisterSensitiveKeys("otp_code")
+
+	assert.Equal(t, "moved to "+RedactedValue, RedactString("moved to ACCT-12345"))
+	assert.True(t, IsSensitiveKey("OTPCode"))
+	assert.Equal(t, "otp_code="+RedactedValue, RedactString("otp_code=123456"))
+}
+
+func TestLoggerRedactsAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWithSinks(DefaultConfig(), nil, Sink{Handler: slog.NewJSONHandler(&buf, nil)})
+
+	l.With("token", "t0k3n").Info("login",
+		slog.String("email", "jane@example.com"),
+		slog.String("password", "hunter2"),
+		slog.Any("form", map[string]any{"items": []any{map[string]any{"cvv": "123"}}}),
+		slog.Group("request", slog.String("query", "a=1&secret=s3cr3t")),
+	)
+
+	out := buf.String()
+	assert.Contains(t, out, "jane@example.com")
+	for _, secret := range []string{"t0k3n", "hunter2", `"123"`, "s3cr3t"} {
+		assert.NotContains(t, out, secret)
+	}
+	assert.Contains(t, out, `"password":"[REDACTED]"`)
+	assert.Contains(t, out, `"cvv":"[REDACTED]"`)
+}
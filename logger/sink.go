@@ -0,0 +1,279 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Sink types accepted by SinkConfig.Type.
+const (
+	SinkStdout = "stdout"
+	SinkStderr = "stderr"
+	SinkFile   = "file"
+	SinkSyslog = "syslog"
+	SinkOTLP   = "otlp"
+)
+
+// Sink is a log destination. Records are sent to Handler when its Enabled
+// method accepts their level, so each sink can have its own minimum level.
+// Closer, if set, is called by Logger.Close (flush buffers, close files).
+type Sink struct {
+	Name    string
+	Handler slog.Handler
+	Closer  io.Closer
+}
+
+// SinkConfig describes a built-in sink. It maps to an entry of
+// logging.sinks in config.yaml:
+//
+//	logging:
+//	  level: debug
+//	  sinks:
+//	    - type: stdout
+//	      level: info
+//	    - type: file
+//	      path: /var/log/app/app.log
+//	      max_size: 100
+//	      rotate_every: 24h
+//	    - type: syslog
+//	      address: localhost:514
+//	      level: warn
+//	    - type: otlp
+//	      endpoint: http://otel-collector:4318
+//	      level: error
+type SinkConfig struct {
+	// Type is one of stdout, stderr, file, syslog, otlp.
+	Type string `mapstructure:"type" validate:"required,oneof=stdout stderr file syslog otlp"`
+	// Level is the minimum level of the sink. Empty follows the logger
+	// level; a sink never receives records below the logger level.
+	Level string `mapstructure:"level" validate:"omitempty,oneof=debug info warn error"`
+	// Format is text or json (stdout, stderr, file). Empty picks json in
+	// production and text otherwise.
+	Format string `mapstructure:"format" validate:"omitempty,oneof=json text"`
+
+	// File sink.
+	Path        string        `mapstructure:"path" validate:"required_if=Type file"`
+	MaxSizeMB   int           `mapstructure:"max_size"`     // rotate above this size, 0 = 100 MB, < 0 = never rotate
+	MaxBackups  int           `mapstructure:"max_backups"`  // rotated files to keep, 0 = all
+	MaxAgeDays  int           `mapstructure:"max_age"`      // delete rotated files older than this, 0 = never
+	RotateEvery time.Duration `mapstructure:"rotate_every"` // also rotate on age (e.g. 24h), 0 = size only
+	Compress    bool          `mapstructure:"compress"`
+
+	// Syslog sink. An empty Network and Address use the local syslog daemon.
+	Network string `mapstructure:"network"` // "udp", "tcp" or "unix"
+	Address string `mapstructure:"address"`
+	Tag     string `mapstructure:"tag"` // defaults to the program name
+
+	// OTLP sink (OTLP/HTTP with JSON encoding).
+	Endpoint      string            `mapstructure:"endpoint" validate:"required_if=Type otlp"`
+	Headers       map[string]string `mapstructure:"headers"`
+	ServiceName   string            `mapstructure:"service_name"`
+	BatchSize     int               `mapstructure:"batch_size"`
+	FlushInterval time.Duration     `mapstructure:"flush_interval"`
+}
+
+// NewSink builds a built-in sink. level is the minimum level used when
+// cfg.Level is empty; pass the logger's LevelVar to follow SetLevel.
+func NewSink(cfg SinkConfig, level slog.Leveler, environment string, addSource bool) (Sink, error) {
+	if cfg.Level != "" {
+		level = ParseLevel(strings.ToLower(cfg.Level))
+	}
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	opts := &slog.HandlerOptions{Level: level, AddSource: addSource}
+
+	switch strings.ToLower(cfg.Type) {
+	case SinkStdout, "":
+		return Sink{Name: SinkStdout, Handler: formatHandler(cfg.Format, environment, os.Stdout, opts)}, nil
+	case SinkStderr:
+		return Sink{Name: SinkStderr, Handler: formatHandler(cfg.Format, environment, os.Stderr, opts)}, nil
+	case SinkFile:
+		if cfg.Path == "" {
+			return Sink{}, errors.New("logger: file sink requires a path")
+		}
+		var w io.WriteCloser
+		if cfg.MaxSizeMB < 0 {
+			file, err := os.OpenFile(cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				return Sink{}, fmt.Errorf("logger: open log file: %w", err)
+			}
+			w = file
+		} else {
+			w = NewRotatingWriter(cfg)
+		}
+		return Sink{Name: SinkFile + ":" + cfg.Path, Handler: formatHandler(cfg.Format, environment, w, opts), Closer: w}, nil
+	case SinkSyslog:
+		h, err := newSyslogHandler(cfg, opts)
+		if err != nil {
+			return Sink{}, err
+		}
+		return Sink{Name: SinkSyslog, Handler: h, Closer: h}, nil
+	case SinkOTLP:
+		h, err := newOTLPHandler(cfg, level)
+		if err != nil {
+			return Sink{}, err
+		}
+		return Sink{Name: SinkOTLP, Handler: h, Closer: h.exporter}, nil
+	default:
+		return Sink{}, fmt.Errorf("logger: unknown sink type %q", cfg.Type)
+	}
+}
+
+func formatHandler(format, environment string, w io.Writer, opts *slog.HandlerOptions) slog.Handler {
+	if format == "" && (environment == "prod" || environment == "production") {
+		format = "json"
+	}
+	if strings.EqualFold(format, "json") {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// ---------------------------------------------------------------------------
+// Rotating file writer
+// ---------------------------------------------------------------------------
+
+// RotatingWriter is a file writer rotated on size (lumberjack) and,
+// optionally, on age: the file is rotated once RotateEvery has elapsed
+// since it was opened.
+type RotatingWriter struct {
+	mu    sync.Mutex
+	file  *lumberjack.Logger
+	every time.Duration
+	next  time.Time
+	now   func() time.Time
+}
+
+// NewRotatingWriter creates a rotating writer from a file sink config.
+func NewRotatingWriter(cfg SinkConfig) *RotatingWriter {
+	maxSize := cfg.MaxSizeMB
+	if maxSize <= 0 {
+		maxSize = 100
+	}
+	return &RotatingWriter{
+		file: &lumberjack.Logger{
+			Filename:   cfg.Path,
+			MaxSize:    maxSize,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAgeDays,
+			Compress:   cfg.Compress,
+		},
+		every: cfg.RotateEvery,
+		now:   time.Now,
+	}
+}
+
+// Write implements io.Writer.
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.every > 0 {
+		now := w.now()
+		if w.next.IsZero() {
+			w.next = now.Add(w.every)
+		} else if !now.Before(w.next) {
+			if err := w.file.Rotate(); err != nil {
+				return 0, err
+			}
+			w.next = now.Add(w.every)
+		}
+	}
+	return w.file.Write(p)
+}
+
+// Rotate closes the current file and starts a new one.
+func (w *RotatingWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.every > 0 {
+		w.next = w.now().Add(w.every)
+	}
+	return w.file.Rotate()
+}
+
+// Close implements io.Closer.
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// ---------------------------------------------------------------------------
+// Fan-out
+// ---------------------------------------------------------------------------
+
+// fanoutHandler sends each record to every sink that accepts its level.
+// The shared level gates all sinks, so SetLevel keeps working when sinks
+// have their own (higher) minimum level.
+type fanoutHandler struct {
+	level    slog.Leveler
+	handlers []slog.Handler
+}
+
+func newFanoutHandler(level slog.Leveler, sinks []Sink) slog.Handler {
+	handlers := make([]slog.Handler, 0, len(sinks))
+	for _, s := range sinks {
+		if s.Handler != nil {
+			handlers = append(handlers, s.Handler)
+		}
+	}
+	return &fanoutHandler{level: level, handlers: handlers}
+}
+
+// Enabled implements slog.Handler.
+func (h *fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.level != nil && level < h.level.Level() {
+		return false
+	}
+	for _, sh := range h.handlers {
+		if sh.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle implements slog.Handler. Errors from individual sinks are joined;
+// a failing sink does not prevent delivery to the others.
+func (h *fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, sh := range h.handlers {
+		if !sh.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := sh.Handle(ctx, r.Clone()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, sh := range h.handlers {
+		handlers[i] = sh.WithAttrs(attrs)
+	}
+	return &fanoutHandler{level: h.level, handlers: handlers}
+}
+
+// WithGroup implements slog.Handler.
+func (h *fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, sh := range h.handlers {
+		handlers[i] = sh.WithGroup(name)
+	}
+	return &fanoutHandler{level: h.level, handlers: handlers}
+}
@@ -0,0 +1,327 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OTLP JSON payload types (opentelemetry-proto, logs/v1). Only the fields
+// the sink writes are declared.
+type (
+	otlpLogsRequest struct {
+		ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+	}
+	otlpResourceLogs struct {
+		Resource  otlpResource    `json:"resource"`
+		ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeLogs struct {
+		Scope      otlpScope       `json:"scope"`
+		LogRecords []otlpLogRecord `json:"logRecords"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpLogRecord struct {
+		TimeUnixNano   string         `json:"timeUnixNano"`
+		SeverityNumber int            `json:"severityNumber"`
+		SeverityText   string         `json:"severityText"`
+		Body           otlpAnyValue   `json:"body"`
+		Attributes     []otlpKeyValue `json:"attributes,omitempty"`
+		TraceID        string         `json:"traceId,omitempty"`
+		SpanID         string         `json:"spanId,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+	otlpAnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// otlpExporter batches log records and posts them to an OTLP/HTTP
+// collector. Records are dropped when the collector is unreachable; the
+// logger never blocks on the network.
+type otlpExporter struct {
+	endpoint  string
+	headers   map[string]string
+	resource  otlpResource
+	batchSize int
+	client    *http.Client
+
+	mu      sync.Mutex
+	pending []otlpLogRecord
+	flushCh chan struct{}
+	done    chan struct{}
+	stopped sync.WaitGroup
+	once    sync.Once
+}
+
+func newOTLPExporter(cfg SinkConfig) (*otlpExporter, error) {
+	endpoint := strings.TrimRight(cfg.Endpoint, "/")
+	if endpoint == "" {
+		return nil, fmt.Errorf("logger: otlp sink requires an endpoint")
+	}
+	if !strings.HasSuffix(endpoint, "/v1/logs") {
+		endpoint += "/v1/logs"
+	}
+
+	service := cfg.ServiceName
+	if service == "" {
+		service = filepath.Base(os.Args[0])
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = 512
+	}
+	interval := cfg.FlushInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+
+	e := &otlpExporter{
+		endpoint:  endpoint,
+		headers:   cfg.Headers,
+		resource:  otlpResource{Attributes: []otlpKeyValue{otlpString("service.name", service)}},
+		batchSize: batchSize,
+		client:    &http.Client{Timeout: 10 * time.Second},
+		flushCh:   make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+
+	e.stopped.Add(1)
+	go e.loop(interval)
+	return e, nil
+}
+
+func (e *otlpExporter) loop(interval time.Duration) {
+	defer e.stopped.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = e.Flush()
+		case <-e.flushCh:
+			_ = e.Flush()
+		case <-e.done:
+			return
+		}
+	}
+}
+
+func (e *otlpExporter) enqueue(rec otlpLogRecord) {
+	e.mu.Lock()
+	e.pending = append(e.pending, rec)
+	full := len(e.pending) >= e.batchSize
+	e.mu.Unlock()
+
+	if full {
+		select {
+		case e.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush sends the pending records.
+func (e *otlpExporter) Flush() error {
+	e.mu.Lock()
+	batch := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(otlpLogsRequest{ResourceLogs: []otlpResourceLogs{{
+		Resource: e.resource,
+		ScopeLogs: []otlpScopeLogs{{
+			Scope:      otlpScope{Name: "github.com/bozz33/sublimeadmin/logger"},
+			LogRecords: batch,
+		}},
+	}}})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("logger: export logs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("logger: export logs: collector returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Close stops the background flush and sends the remaining records.
+func (e *otlpExporter) Close() error {
+	e.once.Do(func() {
+		close(e.done)
+		e.stopped.Wait()
+	})
+	return e.Flush()
+}
+
+// otlpHandler converts records to OTLP log records. Groups are flattened
+// into dotted keys ("group.key").
+type otlpHandler struct {
+	exporter *otlpExporter
+	level    slog.Leveler
+	attrs    []otlpKeyValue
+	prefix   string
+}
+
+func newOTLPHandler(cfg SinkConfig, level slog.Leveler) (*otlpHandler, error) {
+	e, err := newOTLPExporter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &otlpHandler{exporter: e, level: level}, nil
+}
+
+// Enabled implements slog.Handler.
+func (h *otlpHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle implements slog.Handler.
+func (h *otlpHandler) Handle(ctx context.Context, r slog.Record) error {
+	rec := otlpLogRecord{
+		TimeUnixNano:   strconv.FormatInt(r.Time.UnixNano(), 10),
+		SeverityNumber: otlpSeverity(r.Level),
+		SeverityText:   r.Level.String(),
+		Body:           otlpAnyValue{StringValue: &r.Message},
+		Attributes:     append([]otlpKeyValue(nil), h.attrs...),
+	}
+
+	r.Attrs(func(a slog.Attr) bool {
+		rec.Attributes = appendOTLPAttr(rec.Attributes, h.prefix, a)
+		return true
+	})
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		traceID, spanID := sc.TraceID(), sc.SpanID()
+		rec.TraceID = hex.EncodeToString(traceID[:])
+		rec.SpanID = hex.EncodeToString(spanID[:])
+	}
+
+	h.exporter.enqueue(rec)
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *otlpHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]otlpKeyValue(nil), h.attrs...)
+	for _, a := range attrs {
+		clone.attrs = appendOTLPAttr(clone.attrs, h.prefix, a)
+	}
+	return &clone
+}
+
+// WithGroup implements slog.Handler.
+func (h *otlpHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	return &clone
+}
+
+// otlpSeverity maps slog levels to OTLP severity numbers
+// (DEBUG=5, INFO=9, WARN=13, ERROR=17).
+func otlpSeverity(level slog.Level) int {
+	n := 9 + int(level-slog.LevelInfo)
+	if n < 1 {
+		return 1
+	}
+	if n > 24 {
+		return 24
+	}
+	return n
+}
+
+func appendOTLPAttr(kvs []otlpKeyValue, prefix string, a slog.Attr) []otlpKeyValue {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return kvs
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		if a.Key == "" {
+			for _, ga := range group {
+				kvs = appendOTLPAttr(kvs, prefix, ga)
+			}
+			return kvs
+		}
+		for _, ga := range group {
+			kvs = appendOTLPAttr(kvs, prefix+a.Key+".", ga)
+		}
+		return kvs
+	}
+	return append(kvs, otlpKeyValue{Key: prefix + a.Key, Value: otlpValue(a.Value)})
+}
+
+func otlpValue(v slog.Value) otlpAnyValue {
+	switch v.Kind() {
+	case slog.KindBool:
+		b := v.Bool()
+		return otlpAnyValue{BoolValue: &b}
+	case slog.KindInt64:
+		s := strconv.FormatInt(v.Int64(), 10)
+		return otlpAnyValue{IntValue: &s}
+	case slog.KindUint64:
+		s := strconv.FormatUint(v.Uint64(), 10)
+		return otlpAnyValue{IntValue: &s}
+	case slog.KindFloat64:
+		f := v.Float64()
+		return otlpAnyValue{DoubleValue: &f}
+	case slog.KindTime:
+		s := v.Time().Format(time.RFC3339Nano)
+		return otlpAnyValue{StringValue: &s}
+	default:
+		s := v.String()
+		return otlpAnyValue{StringValue: &s}
+	}
+}
+
+func otlpString(key, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: &value}}
+}
@@ -0,0 +1,102 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"log/syslog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// syslogHandler formats records as text and writes them to syslog with the
+// severity matching their level. The time is left to the syslog daemon.
+type syslogHandler struct {
+	inner slog.Handler
+	out   *syslogOutput
+}
+
+// syslogOutput is shared by the handlers derived with WithAttrs/WithGroup.
+type syslogOutput struct {
+	mu     sync.Mutex
+	writer *syslog.Writer
+	buf    bytes.Buffer
+}
+
+func (o *syslogOutput) Write(p []byte) (int, error) {
+	return o.buf.Write(p)
+}
+
+func newSyslogHandler(cfg SinkConfig, opts *slog.HandlerOptions) (*syslogHandler, error) {
+	tag := cfg.Tag
+	if tag == "" {
+		tag = filepath.Base(os.Args[0])
+	}
+
+	w, err := syslog.Dial(cfg.Network, cfg.Address, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, fmt.Errorf("logger: connect to syslog: %w", err)
+	}
+
+	out := &syslogOutput{writer: w}
+	inner := slog.NewTextHandler(out, &slog.HandlerOptions{
+		Level:     opts.Level,
+		AddSource: opts.AddSource,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	return &syslogHandler{inner: inner, out: out}, nil
+}
+
+// Enabled implements slog.Handler.
+func (h *syslogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *syslogHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.out.mu.Lock()
+	defer h.out.mu.Unlock()
+
+	h.out.buf.Reset()
+	if err := h.inner.Handle(ctx, r); err != nil {
+		return err
+	}
+	msg := strings.TrimSuffix(h.out.buf.String(), "\n")
+
+	w := h.out.writer
+	switch {
+	case r.Level >= slog.LevelError:
+		return w.Err(msg)
+	case r.Level >= slog.LevelWarn:
+		return w.Warning(msg)
+	case r.Level >= slog.LevelInfo:
+		return w.Info(msg)
+	default:
+		return w.Debug(msg)
+	}
+}
+
+// WithAttrs implements slog.Handler.
+func (h *syslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &syslogHandler{inner: h.inner.WithAttrs(attrs), out: h.out}
+}
+
+// WithGroup implements slog.Handler.
+func (h *syslogHandler) WithGroup(name string) slog.Handler {
+	return &syslogHandler{inner: h.inner.WithGroup(name), out: h.out}
+}
+
+// Close closes the syslog connection.
+func (h *syslogHandler) Close() error {
+	return h.out.writer.Close()
+}
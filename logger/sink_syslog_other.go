@@ -0,0 +1,19 @@
+//go:build windows || plan9
+
+package logger
+
+import (
+	"errors"
+	"log/slog"
+)
+
+type syslogHandler struct {
+	slog.Handler
+}
+
+func newSyslogHandler(SinkConfig, *slog.HandlerOptions) (*syslogHandler, error) {
+	return nil, errors.New("logger: syslog sink is not supported on this platform")
+}
+
+// Close implements io.Closer.
+func (h *syslogHandler) Close() error { return nil }
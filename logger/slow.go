@@ -0,0 +1,242 @@
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"entgo.io/ent/dialect"
+)
+
+// ---------------------------------------------------------------------------
+// Request info (route and resource slug)
+// ---------------------------------------------------------------------------
+
+// requestInfo is shared by a request and the handlers it goes through, so
+// values set deep in the stack (resource slug) are visible to the logging
+// middleware once the request is served.
+type requestInfo struct {
+	mu       sync.RWMutex
+	request  *http.Request
+	route    string
+	resource string
+}
+
+type requestInfoKey struct{}
+
+// TrackRequest returns r with a request info holder in its context, used by
+// Route, Resource and SetResource. Logging middlewares call it; calling it
+// twice keeps the first holder.
+func TrackRequest(r *http.Request) *http.Request {
+	if _, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+		return r
+	}
+	info := &requestInfo{}
+	r = r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info))
+	info.request = r
+	return r
+}
+
+// SetResource records the resource slug handling the request (the engine
+// CRUD handler calls it). It is a no-op outside TrackRequest.
+func SetResource(ctx context.Context, slug string) {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		info.mu.Lock()
+		info.resource = slug
+		info.mu.Unlock()
+	}
+}
+
+// Resource returns the resource slug recorded with SetResource.
+func Resource(ctx context.Context) string {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		info.mu.RLock()
+		defer info.mu.RUnlock()
+		return info.resource
+	}
+	return ""
+}
+
+// SetRoute records the route pattern of the request. Handlers behind
+// middlewares that copy the request should call it with r.Pattern, since
+// ServeMux only sets the pattern on the copy it receives.
+func SetRoute(ctx context.Context, pattern string) {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok && pattern != "" {
+		info.mu.Lock()
+		info.route = pattern
+		info.mu.Unlock()
+	}
+}
+
+// Route returns the route recorded with SetRoute, or the ServeMux pattern
+// matching the tracked request ("GET /users/{id}") once routing has happened.
+func Route(ctx context.Context) string {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		info.mu.RLock()
+		defer info.mu.RUnlock()
+		if info.route != "" {
+			return info.route
+		}
+		if info.request != nil {
+			return info.request.Pattern
+		}
+	}
+	return ""
+}
+
+func routeAttrs(ctx context.Context) []any {
+	var attrs []any
+	if route := Route(ctx); route != "" {
+		attrs = append(attrs, slog.String("route", route))
+	}
+	if resource := Resource(ctx); resource != "" {
+		attrs = append(attrs, slog.String("resource", resource))
+	}
+	return attrs
+}
+
+// ---------------------------------------------------------------------------
+// Slow requests
+// ---------------------------------------------------------------------------
+
+// MiddlewareOption configures Middleware.
+type MiddlewareOption func(*middlewareOptions)
+
+type middlewareOptions struct {
+	slowThreshold time.Duration
+}
+
+// SlowRequestThreshold makes Middleware log a "slow request" warning for
+// requests taking longer than d.
+func SlowRequestThreshold(d time.Duration) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.slowThreshold = d
+	}
+}
+
+// SlowRequest logs a "slow request" warning when duration exceeds threshold,
+// with the route and resource of r. r must come from TrackRequest for the
+// route and resource to be known.
+func (l *Logger) SlowRequest(r *http.Request, duration, threshold time.Duration) {
+	if threshold <= 0 || duration <= threshold {
+		return
+	}
+
+	attrs := []any{
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.Duration("duration", duration),
+		slog.Duration("threshold", threshold),
+	}
+	l.WarnContext(r.Context(), "slow request", append(attrs, routeAttrs(r.Context())...)...)
+}
+
+// ---------------------------------------------------------------------------
+// Slow queries (Ent)
+// ---------------------------------------------------------------------------
+
+// maxQueryLength bounds the SQL text written to slow query logs.
+const maxQueryLength = 2000
+
+// SlowQueryDriver wraps an Ent driver and logs a "slow query" warning for
+// statements taking longer than threshold. The warning carries the route
+// and resource of the request that issued the query, when the query context
+// comes from one. Query arguments are not logged.
+//
+//	drv, _ := entsql.Open(dialect.SQLite, dsn)
+//	client := ent.NewClient(ent.Driver(logger.SlowQueryDriver(drv, 200*time.Millisecond)))
+func SlowQueryDriver(drv dialect.Driver, threshold time.Duration) dialect.Driver {
+	return &slowQueryDriver{Driver: drv, threshold: threshold}
+}
+
+type slowQueryDriver struct {
+	dialect.Driver
+	threshold time.Duration
+}
+
+// Exec implements dialect.Driver.
+func (d *slowQueryDriver) Exec(ctx context.Context, query string, args, v any) error {
+	start := time.Now()
+	err := d.Driver.Exec(ctx, query, args, v)
+	logSlowQuery(ctx, "exec", query, time.Since(start), d.threshold, err)
+	return err
+}
+
+// Query implements dialect.Driver.
+func (d *slowQueryDriver) Query(ctx context.Context, query string, args, v any) error {
+	start := time.Now()
+	err := d.Driver.Query(ctx, query, args, v)
+	logSlowQuery(ctx, "query", query, time.Since(start), d.threshold, err)
+	return err
+}
+
+// Tx implements dialect.Driver.
+func (d *slowQueryDriver) Tx(ctx context.Context) (dialect.Tx, error) {
+	tx, err := d.Driver.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &slowQueryTx{Tx: tx, threshold: d.threshold}, nil
+}
+
+// BeginTx starts a transaction with options when the underlying driver
+// supports it (Ent's SQL driver does).
+func (d *slowQueryDriver) BeginTx(ctx context.Context, opts *sql.TxOptions) (dialect.Tx, error) {
+	drv, ok := d.Driver.(interface {
+		BeginTx(context.Context, *sql.TxOptions) (dialect.Tx, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("logger: driver %T does not support BeginTx", d.Driver)
+	}
+	tx, err := drv.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &slowQueryTx{Tx: tx, threshold: d.threshold}, nil
+}
+
+type slowQueryTx struct {
+	dialect.Tx
+	threshold time.Duration
+}
+
+// Exec implements dialect.Tx.
+func (t *slowQueryTx) Exec(ctx context.Context, query string, args, v any) error {
+	start := time.Now()
+	err := t.Tx.Exec(ctx, query, args, v)
+	logSlowQuery(ctx, "exec", query, time.Since(start), t.threshold, err)
+	return err
+}
+
+// Query implements dialect.Tx.
+func (t *slowQueryTx) Query(ctx context.Context, query string, args, v any) error {
+	start := time.Now()
+	err := t.Tx.Query(ctx, query, args, v)
+	logSlowQuery(ctx, "query", query, time.Since(start), t.threshold, err)
+	return err
+}
+
+func logSlowQuery(ctx context.Context, op, query string, duration, threshold time.Duration, err error) {
+	if threshold <= 0 || duration <= threshold {
+		return
+	}
+	if len(query) > maxQueryLength {
+		query = query[:maxQueryLength] + "..."
+	}
+
+	attrs := []any{
+		slog.String("op", op),
+		slog.String("query", query),
+		slog.Duration("duration", duration),
+		slog.Duration("threshold", threshold),
+	}
+	attrs = append(attrs, routeAttrs(ctx)...)
+	if err != nil {
+		attrs = append(attrs, Err(err))
+	}
+	FromContext(ctx).WarnContext(ctx, "slow query", attrs...)
+}
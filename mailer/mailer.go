@@ -1,9 +1,15 @@
 package mailer
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/smtp"
+	"net/textproto"
 	"strings"
+
+	"github.com/bozz33/sublimeadmin/apperrors"
 )
 
 // Mailer is the interface for sending emails.
@@ -73,5 +79,35 @@ func (s *SMTPMailer) Send(msg Message) error {
 	body := headers + msg.Body
 	addr := fmt.Sprintf("%s:%d", s.cfg.Host, s.cfg.Port)
 
-	return smtp.SendMail(addr, auth, s.cfg.From, msg.To, []byte(body))
+	return smtpError(smtp.SendMail(addr, auth, s.cfg.From, msg.To, []byte(body)))
+}
+
+// smtpError marks 4xx SMTP replies (mailbox busy, greylisting, rate limits)
+// as temporary, so RetryMailer retries them. 5xx replies are permanent.
+func smtpError(err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 400 && reply.Code < 500 {
+		return apperrors.Wrap(err, apperrors.CodeServiceUnavailable, "SMTP temporary failure", http.StatusServiceUnavailable).MarkTemporary()
+	}
+	return err
+}
+
+// RetryMailer retries failed sends of another mailer with exponential
+// backoff. Only transient errors are retried (see apperrors.IsRetryable).
+type RetryMailer struct {
+	Mailer Mailer
+	Policy apperrors.RetryPolicy
+}
+
+// WithRetry wraps m so that transient failures are retried with policy.
+//
+//	m := mailer.WithRetry(mailer.NewSMTPMailer(cfg), apperrors.DefaultRetryPolicy())
+func WithRetry(m Mailer, policy apperrors.RetryPolicy) *RetryMailer {
+	return &RetryMailer{Mailer: m, Policy: policy}
+}
+
+func (r *RetryMailer) Send(msg Message) error {
+	return apperrors.Retry(context.Background(), r.Policy, func(context.Context) error {
+		return r.Mailer.Send(msg)
+	})
 }
@@ -1,8 +1,12 @@
 package mailer
 
 import (
+	"net/textproto"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/apperrors"
 )
 
 func TestNoopMailer(t *testing.T) {
@@ -72,3 +76,38 @@ func TestMailer_Interface(t *testing.T) {
 	var _ Mailer = &LogMailer{}
 	var _ Mailer = &SMTPMailer{}
 }
+
+// flakyMailer fails the first `failures` sends with err.
+type flakyMailer struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (f *flakyMailer) Send(msg Message) error {
+	f.calls++
+	if f.calls <= f.failures {
+		return f.err
+	}
+	return nil
+}
+
+func TestRetryMailer(t *testing.T) {
+	policy := apperrors.RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond}
+
+	flaky := &flakyMailer{failures: 2, err: smtpError(&textproto.Error{Code: 421, Msg: "try again later"})}
+	if err := WithRetry(flaky, policy).Send(Message{To: []string{"a@example.com"}}); err != nil {
+		t.Errorf("expected success after retries, got %v", err)
+	}
+	if flaky.calls != 3 {
+		t.Errorf("expected 3 calls, got %d", flaky.calls)
+	}
+
+	rejected := &flakyMailer{failures: 5, err: smtpError(&textproto.Error{Code: 550, Msg: "no such user"})}
+	if err := WithRetry(rejected, policy).Send(Message{To: []string{"a@example.com"}}); err == nil {
+		t.Error("expected permanent SMTP error")
+	}
+	if rejected.calls != 1 {
+		t.Errorf("expected permanent errors not to be retried, got %d calls", rejected.calls)
+	}
+}
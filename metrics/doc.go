@@ -0,0 +1,23 @@
+// Package metrics provides Prometheus-compatible instrumentation without
+// external dependencies.
+//
+// It implements counters, gauges and histograms with labels, and serves them
+// in the Prometheus text exposition format. Built-in metrics cover HTTP
+// requests (via middleware.Metrics), background jobs, global search and
+// caches.
+//
+// Features:
+//   - Counter, Gauge and Histogram families with labels
+//   - Text exposition format 0.0.4 (scrapable by Prometheus)
+//   - Built-in HTTP, jobs, search and cache metrics
+//   - Custom registries for isolated tests
+//
+// Basic usage:
+//
+//	mux.Handle("/metrics", metrics.Handler())
+//	handler := middleware.Metrics()(mux)
+//
+//	// Custom metric
+//	exports := metrics.Default.NewCounterVec("app_exports_total", "Exports by format.", "format")
+//	exports.WithLabelValues("csv").Inc()
+package metrics
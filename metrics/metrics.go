@@ -0,0 +1,371 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefBuckets are the default histogram buckets (seconds), matching the
+// Prometheus client defaults.
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// SizeBuckets are histogram buckets suited for response sizes (bytes).
+var SizeBuckets = []float64{100, 1000, 10_000, 100_000, 1_000_000, 10_000_000}
+
+// collector is a metric family that can write itself in text format.
+type collector interface {
+	name() string
+	kind() string
+	write(w *bufio.Writer)
+}
+
+// Registry holds metric families and exposes them in the Prometheus
+// text exposition format (version 0.0.4).
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]collector
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+// Default is the global registry used by the package-level helpers,
+// middleware.Metrics and the built-in subsystem metrics.
+var Default = NewRegistry()
+
+// register adds c, or returns the already registered family with the same
+// name. It panics when that family is of another kind, as the callers
+// would get a family they cannot use.
+func (r *Registry) register(c collector) collector {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.collectors[c.name()]; ok {
+		if existing.kind() != c.kind() {
+			panic(fmt.Sprintf("metrics: %s is registered as a %s, not a %s", c.name(), existing.kind(), c.kind()))
+		}
+		return existing
+	}
+	r.collectors[c.name()] = c
+	return c
+}
+
+// NewCounterVec registers (or returns the existing) counter family.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := r.register(&CounterVec{family: newFamily(name, help, "counter", labels)})
+	return c.(*CounterVec)
+}
+
+// NewGaugeVec registers (or returns the existing) gauge family.
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	c := r.register(&GaugeVec{family: newFamily(name, help, "gauge", labels)})
+	return c.(*GaugeVec)
+}
+
+// NewHistogramVec registers (or returns the existing) histogram family.
+// Buckets default to DefBuckets when nil.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+
+	c := r.register(&HistogramVec{family: newFamily(name, help, "histogram", labels), buckets: sorted})
+	return c.(*HistogramVec)
+}
+
+// Handler returns an http.Handler serving the registry in text format.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	})
+}
+
+// Write writes every metric family to w, sorted by name.
+func (r *Registry) Write(w io.Writer) {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	collectors := make([]collector, len(names))
+	for i, name := range names {
+		collectors[i] = r.collectors[name]
+	}
+	r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+	_ = bw.Flush()
+}
+
+// Handler serves the Default registry.
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+// --- family ---
+
+// family holds the series of one metric, keyed by label values.
+type family struct {
+	metricName string
+	help       string
+	metricKind string
+	labels     []string
+
+	mu     sync.RWMutex
+	series map[string]any
+	values map[string][]string
+}
+
+func newFamily(name, help, kind string, labels []string) family {
+	return family{
+		metricName: name,
+		help:       help,
+		metricKind: kind,
+		labels:     labels,
+		series:     make(map[string]any),
+		values:     make(map[string][]string),
+	}
+}
+
+func (f *family) name() string { return f.metricName }
+func (f *family) kind() string { return f.metricKind }
+
+// get returns the series for values, creating it with create when missing.
+func (f *family) get(values []string, create func() any) any {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.metricName, len(f.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+
+	f.mu.RLock()
+	s, ok := f.series[key]
+	f.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok := f.series[key]; ok {
+		return s
+	}
+	s = create()
+	f.series[key] = s
+	f.values[key] = append([]string(nil), values...)
+	return s
+}
+
+// sortedKeys returns series keys in a stable order.
+func (f *family) sortedKeys() []string {
+	keys := make([]string, 0, len(f.series))
+	for k := range f.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (f *family) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.metricName, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.metricName, f.metricKind)
+}
+
+// labelString renders {a="x",b="y"} with optional extra label pairs appended.
+func (f *family) labelString(values []string, extra ...string) string {
+	if len(values) == 0 && len(extra) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(values)+len(extra)/2)
+	for i, v := range values {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, f.labels[i], escapeLabel(v)))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[i], escapeLabel(extra[i+1])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// --- Counter ---
+
+// Counter is a monotonically increasing value.
+type Counter struct{ v atomicFloat }
+
+// Inc increments the counter by 1.
+func (c *Counter) Inc() { c.v.add(1) }
+
+// Add increments the counter by delta. Negative deltas are ignored.
+func (c *Counter) Add(delta float64) {
+	if delta > 0 {
+		c.v.add(delta)
+	}
+}
+
+// Value returns the current value.
+func (c *Counter) Value() float64 { return c.v.load() }
+
+// CounterVec is a counter family partitioned by labels.
+type CounterVec struct{ family }
+
+// WithLabelValues returns the counter for the given label values.
+func (v *CounterVec) WithLabelValues(values ...string) *Counter {
+	return v.get(values, func() any { return &Counter{} }).(*Counter)
+}
+
+func (v *CounterVec) write(w *bufio.Writer) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	v.writeHeader(w)
+	for _, k := range v.sortedKeys() {
+		fmt.Fprintf(w, "%s%s %s\n", v.metricName, v.labelString(v.values[k]), formatFloat(v.series[k].(*Counter).Value()))
+	}
+}
+
+// --- Gauge ---
+
+// Gauge is a value that can go up and down.
+type Gauge struct{ v atomicFloat }
+
+// Set sets the gauge to value.
+func (g *Gauge) Set(value float64) { g.v.store(value) }
+
+// Inc increments the gauge by 1.
+func (g *Gauge) Inc() { g.v.add(1) }
+
+// Dec decrements the gauge by 1.
+func (g *Gauge) Dec() { g.v.add(-1) }
+
+// Add adds delta to the gauge.
+func (g *Gauge) Add(delta float64) { g.v.add(delta) }
+
+// Value returns the current value.
+func (g *Gauge) Value() float64 { return g.v.load() }
+
+// GaugeVec is a gauge family partitioned by labels.
+type GaugeVec struct{ family }
+
+// WithLabelValues returns the gauge for the given label values.
+func (v *GaugeVec) WithLabelValues(values ...string) *Gauge {
+	return v.get(values, func() any { return &Gauge{} }).(*Gauge)
+}
+
+func (v *GaugeVec) write(w *bufio.Writer) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	v.writeHeader(w)
+	for _, k := range v.sortedKeys() {
+		fmt.Fprintf(w, "%s%s %s\n", v.metricName, v.labelString(v.values[k]), formatFloat(v.series[k].(*Gauge).Value()))
+	}
+}
+
+// --- Histogram ---
+
+// Histogram counts observations in cumulative buckets.
+type Histogram struct {
+	buckets []float64
+	counts  []atomic.Uint64
+	count   atomic.Uint64
+	sum     atomicFloat
+}
+
+// Observe records a value.
+func (h *Histogram) Observe(value float64) {
+	for i, upper := range h.buckets {
+		if value <= upper {
+			h.counts[i].Add(1)
+		}
+	}
+	h.count.Add(1)
+	h.sum.add(value)
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() uint64 { return h.count.Load() }
+
+// Sum returns the sum of observations.
+func (h *Histogram) Sum() float64 { return h.sum.load() }
+
+// HistogramVec is a histogram family partitioned by labels.
+type HistogramVec struct {
+	family
+	buckets []float64
+}
+
+// WithLabelValues returns the histogram for the given label values.
+func (v *HistogramVec) WithLabelValues(values ...string) *Histogram {
+	return v.get(values, func() any {
+		return &Histogram{buckets: v.buckets, counts: make([]atomic.Uint64, len(v.buckets))}
+	}).(*Histogram)
+}
+
+func (v *HistogramVec) write(w *bufio.Writer) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	v.writeHeader(w)
+	for _, k := range v.sortedKeys() {
+		h := v.series[k].(*Histogram)
+		values := v.values[k]
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", v.metricName, v.labelString(values, "le", formatFloat(upper)), h.counts[i].Load())
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", v.metricName, v.labelString(values, "le", "+Inf"), h.Count())
+		fmt.Fprintf(w, "%s_sum%s %s\n", v.metricName, v.labelString(values), formatFloat(h.Sum()))
+		fmt.Fprintf(w, "%s_count%s %d\n", v.metricName, v.labelString(values), h.Count())
+	}
+}
+
+// --- helpers ---
+
+// atomicFloat is a float64 updated with compare-and-swap.
+type atomicFloat struct{ bits atomic.Uint64 }
+
+func (a *atomicFloat) load() float64 { return math.Float64frombits(a.bits.Load()) }
+
+func (a *atomicFloat) store(v float64) { a.bits.Store(math.Float64bits(v)) }
+
+func (a *atomicFloat) add(delta float64) {
+	for {
+		old := a.bits.Load()
+		next := math.Float64bits(math.Float64frombits(old) + delta)
+		if a.bits.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterVec(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("test_total", "Test counter.", "kind")
+
+	c.WithLabelValues("a").Inc()
+	c.WithLabelValues("a").Add(2)
+	c.WithLabelValues("b").Add(-1) // ignored
+
+	assert.Equal(t, float64(3), c.WithLabelValues("a").Value())
+	assert.Equal(t, float64(0), c.WithLabelValues("b").Value())
+	assert.Same(t, c, r.NewCounterVec("test_total", "Test counter.", "kind"))
+}
+
+func TestCounterVec_WrongLabelCount(t *testing.T) {
+	c := NewRegistry().NewCounterVec("test_total", "Test counter.", "kind")
+	assert.Panics(t, func() { c.WithLabelValues("a", "b") })
+}
+
+func TestRegistry_KindConflict(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("test_total", "Test counter.", "kind")
+	assert.PanicsWithValue(t, "metrics: test_total is registered as a counter, not a gauge", func() {
+		r.NewGaugeVec("test_total", "Test gauge.", "kind")
+	})
+}
+
+func TestGauge(t *testing.T) {
+	g := NewRegistry().NewGaugeVec("test_gauge", "Test gauge.").WithLabelValues()
+
+	g.Inc()
+	g.Inc()
+	g.Dec()
+	assert.Equal(t, float64(1), g.Value())
+
+	g.Set(42.5)
+	assert.Equal(t, 42.5, g.Value())
+}
+
+func TestHistogram(t *testing.T) {
+	h := NewRegistry().NewHistogramVec("test_seconds", "Test histogram.", []float64{1, 0.1}).WithLabelValues()
+
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(5)
+
+	assert.Equal(t, uint64(3), h.Count())
+	assert.InDelta(t, 5.55, h.Sum(), 1e-9)
+	assert.Equal(t, uint64(1), h.counts[0].Load()) // le=0.1
+	assert.Equal(t, uint64(2), h.counts[1].Load()) // le=1
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("b_total", "Second.", "path").WithLabelValues(`/a"b`).Inc()
+	r.NewHistogramVec("a_seconds", "First.", []float64{0.5}, "route").WithLabelValues("/x").Observe(0.2)
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body := rec.Body.String()
+	assert.Contains(t, rec.Header().Get("Content-Type"), "version=0.0.4")
+	assert.Less(t, strings.Index(body, "a_seconds"), strings.Index(body, "b_total"))
+	assert.Contains(t, body, "# TYPE a_seconds histogram\n")
+	assert.Contains(t, body, `a_seconds_bucket{route="/x",le="0.5"} 1`)
+	assert.Contains(t, body, `a_seconds_bucket{route="/x",le="+Inf"} 1`)
+	assert.Contains(t, body, `a_seconds_sum{route="/x"} 0.2`)
+	assert.Contains(t, body, `a_seconds_count{route="/x"} 1`)
+	assert.Contains(t, body, `b_total{path="/a\"b"} 1`)
+}
+
+func TestCounter_Concurrent(t *testing.T) {
+	c := NewRegistry().NewCounterVec("test_total", "Test counter.").WithLabelValues()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Inc()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, float64(5000), c.Value())
+}
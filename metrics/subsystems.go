@@ -0,0 +1,68 @@
+package metrics
+
+// Built-in metrics registered on the Default registry. They are updated by
+// middleware.Metrics, the jobs queue, global search, the pagination cache
+// and the tenant database pools.
+var (
+	// HTTPRequests counts requests by method, route pattern and status code.
+	HTTPRequests = Default.NewCounterVec("sublime_http_requests_total",
+		"Total HTTP requests by method, route and status.", "method", "route", "status")
+
+	// HTTPDuration observes request latency in seconds.
+	HTTPDuration = Default.NewHistogramVec("sublime_http_request_duration_seconds",
+		"HTTP request latency in seconds.", DefBuckets, "method", "route")
+
+	// HTTPResponseSize observes response body sizes in bytes.
+	HTTPResponseSize = Default.NewHistogramVec("sublime_http_response_size_bytes",
+		"HTTP response size in bytes.", SizeBuckets, "method", "route")
+
+	// HTTPInFlight tracks requests currently being served.
+	HTTPInFlight = Default.NewGaugeVec("sublime_http_requests_in_flight",
+		"HTTP requests currently being served.").WithLabelValues()
+
+	// JobsDispatched counts jobs pushed onto a queue.
+	JobsDispatched = Default.NewCounterVec("sublime_jobs_dispatched_total",
+		"Total background jobs dispatched by job name.", "job")
+
+	// JobsProcessed counts finished jobs by name and final status.
+	JobsProcessed = Default.NewCounterVec("sublime_jobs_processed_total",
+		"Total background jobs processed by job name and status.", "job", "status")
+
+	// JobDuration observes job execution time in seconds.
+	JobDuration = Default.NewHistogramVec("sublime_job_duration_seconds",
+		"Background job execution time in seconds.", DefBuckets, "job")
+
+	// SearchQueries counts global search queries.
+	SearchQueries = Default.NewCounterVec("sublime_search_queries_total",
+		"Total global search queries.").WithLabelValues()
+
+	// SearchErrors counts failed searches per searchable.
+	SearchErrors = Default.NewCounterVec("sublime_search_errors_total",
+		"Total global search errors by searchable.", "searchable")
+
+	// SearchDuration observes global search latency in seconds.
+	SearchDuration = Default.NewHistogramVec("sublime_search_duration_seconds",
+		"Global search latency in seconds.", DefBuckets).WithLabelValues()
+
+	// CacheHits counts cache hits by cache name.
+	CacheHits = Default.NewCounterVec("sublime_cache_hits_total",
+		"Total cache hits by cache name.", "cache")
+
+	// CacheMisses counts cache misses by cache name.
+	CacheMisses = Default.NewCounterVec("sublime_cache_misses_total",
+		"Total cache misses by cache name.", "cache")
+
+	// TenantDBPools tracks the tenant database pools currently open.
+	TenantDBPools = Default.NewGaugeVec("sublime_tenant_db_pools_open",
+		"Tenant database pools currently open.").WithLabelValues()
+
+	// TenantDBPoolEvents counts tenant database pools opened, evicted as
+	// least recently used, closed and failing their health check.
+	TenantDBPoolEvents = Default.NewCounterVec("sublime_tenant_db_pool_events_total",
+		"Total tenant database pool events by event.", "event")
+
+	// TenantDBConnections tracks the connections of the tenant database
+	// pools by state (in_use, idle), as of the last health check.
+	TenantDBConnections = Default.NewGaugeVec("sublime_tenant_db_connections",
+		"Connections of the tenant database pools by state.", "state")
+)
@@ -0,0 +1,232 @@
+package metrics
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// latencyBuckets bound the latencies counted by a Window (seconds), fine
+// enough for the p95 of an admin panel.
+var latencyBuckets = [...]float64{.001, .0025, .005, .01, .025, .05, .075, .1, .15, .25, .35, .5, .75, 1, 1.5, 2.5, 5, 10}
+
+// HTTPWindow keeps the HTTP traffic of the last hour, minute by minute. It
+// is fed by middleware.Metrics and read by the server metrics widget.
+var HTTPWindow = NewWindow(time.Minute, 60)
+
+// Window keeps recent HTTP traffic in a ring of intervals: requests, server
+// errors and latency, overall and per route. Unlike the counters of a
+// Registry, which only grow, it answers "how is the server doing now"
+// without a Prometheus server.
+type Window struct {
+	interval time.Duration
+	now      func() time.Time
+
+	mu    sync.Mutex
+	slots []windowSlot
+}
+
+// windowSlot is the traffic of one interval.
+type windowSlot struct {
+	start  time.Time
+	total  windowStats
+	routes map[string]*windowStats
+}
+
+// windowStats counts the requests of an interval.
+type windowStats struct {
+	requests int
+	errors   int
+	max      time.Duration
+	buckets  [len(latencyBuckets) + 1]int
+}
+
+func (s *windowStats) observe(status int, d time.Duration) {
+	s.requests++
+	if status >= 500 {
+		s.errors++
+	}
+	s.max = max(s.max, d)
+	i, _ := slices.BinarySearch(latencyBuckets[:], d.Seconds())
+	s.buckets[i]++
+}
+
+func (s *windowStats) merge(o *windowStats) {
+	s.requests += o.requests
+	s.errors += o.errors
+	s.max = max(s.max, o.max)
+	for i, n := range o.buckets {
+		s.buckets[i] += n
+	}
+}
+
+// p95 returns the upper bound of the bucket of the 95th percentile, capped
+// by the slowest request.
+func (s *windowStats) p95() time.Duration {
+	rank := (s.requests*95 + 99) / 100
+	seen := 0
+	for i, n := range s.buckets {
+		seen += n
+		if seen >= rank && seen > 0 {
+			if i == len(latencyBuckets) {
+				return s.max
+			}
+			return min(time.Duration(latencyBuckets[i]*float64(time.Second)), s.max)
+		}
+	}
+	return 0
+}
+
+// NewWindow creates a window of size intervals.
+func NewWindow(interval time.Duration, size int) *Window {
+	return &Window{interval: interval, now: time.Now, slots: make([]windowSlot, size)}
+}
+
+// Interval returns the length of an interval of the window.
+func (w *Window) Interval() time.Duration { return w.interval }
+
+// Size returns the number of intervals of the window.
+func (w *Window) Size() int { return len(w.slots) }
+
+// slot returns the slot of t, reset when it held an older interval.
+func (w *Window) slot(t time.Time) *windowSlot {
+	start := t.Truncate(w.interval)
+	s := &w.slots[int(start.UnixNano()/int64(w.interval))%len(w.slots)]
+	if !s.start.Equal(start) {
+		*s = windowSlot{start: start, routes: make(map[string]*windowStats)}
+	}
+	return s
+}
+
+// Observe records a request to route answered with status in d.
+func (w *Window) Observe(route string, status int, d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	s := w.slot(w.now())
+	s.total.observe(status, d)
+	rs := s.routes[route]
+	if rs == nil {
+		rs = &windowStats{}
+		s.routes[route] = rs
+	}
+	rs.observe(status, d)
+}
+
+// Point is the traffic of an interval, or of several merged.
+type Point struct {
+	Start    time.Time
+	Requests int
+	Errors   int           // responses with a 5xx status
+	P95      time.Duration // 95th percentile of the latency
+}
+
+// ErrorRate returns the share of the requests answered with an error,
+// between 0 and 1.
+func (p Point) ErrorRate() float64 {
+	if p.Requests == 0 {
+		return 0
+	}
+	return float64(p.Errors) / float64(p.Requests)
+}
+
+func (s *windowStats) point(start time.Time) Point {
+	return Point{Start: start, Requests: s.requests, Errors: s.errors, P95: s.p95()}
+}
+
+// stats calls fn with the slots of the last n intervals, oldest first,
+// empty for the intervals without traffic.
+func (w *Window) stats(n int, fn func(start time.Time, s *windowSlot)) {
+	n = min(n, len(w.slots))
+	current := w.now().Truncate(w.interval)
+	for i := n - 1; i >= 0; i-- {
+		start := current.Add(-time.Duration(i) * w.interval)
+		s := &w.slots[int(start.UnixNano()/int64(w.interval))%len(w.slots)]
+		if !s.start.Equal(start) {
+			fn(start, nil)
+			continue
+		}
+		fn(start, s)
+	}
+}
+
+// Series returns the traffic of each interval of the window, oldest first,
+// the current one last.
+func (w *Window) Series() []Point {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	points := make([]Point, 0, len(w.slots))
+	w.stats(len(w.slots), func(start time.Time, s *windowSlot) {
+		if s == nil {
+			points = append(points, Point{Start: start})
+			return
+		}
+		points = append(points, s.total.point(start))
+	})
+	return points
+}
+
+// Summary returns the traffic of the last n intervals merged, the current
+// one included.
+func (w *Window) Summary(n int) Point {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var total windowStats
+	var first time.Time
+	w.stats(n, func(start time.Time, s *windowSlot) {
+		if first.IsZero() {
+			first = start
+		}
+		if s != nil {
+			total.merge(&s.total)
+		}
+	})
+	return total.point(first)
+}
+
+// RouteStats is the traffic of a route over a window.
+type RouteStats struct {
+	Route string
+	Point
+}
+
+// Routes returns the traffic of each route over the last n intervals, the
+// busiest first.
+func (w *Window) Routes(n int) []RouteStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	totals := make(map[string]*windowStats)
+	var first time.Time
+	w.stats(n, func(start time.Time, s *windowSlot) {
+		if first.IsZero() {
+			first = start
+		}
+		if s == nil {
+			return
+		}
+		for route, rs := range s.routes {
+			if totals[route] == nil {
+				totals[route] = &windowStats{}
+			}
+			totals[route].merge(rs)
+		}
+	})
+	routes := make([]RouteStats, 0, len(totals))
+	for route, rs := range totals {
+		routes = append(routes, RouteStats{Route: route, Point: rs.point(first)})
+	}
+	slices.SortFunc(routes, func(a, b RouteStats) int {
+		if c := cmp.Compare(b.Requests, a.Requests); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Route, b.Route)
+	})
+	return routes
+}
+
+// Reset forgets the traffic recorded.
+func (w *Window) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	clear(w.slots)
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindow(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 30, 0, time.UTC)
+	w := NewWindow(time.Minute, 3)
+	w.now = func() time.Time { return now }
+
+	w.Observe("GET /users", 200, 20*time.Millisecond)
+	w.Observe("GET /users", 500, 40*time.Millisecond)
+	now = now.Add(time.Minute)
+	for range 19 {
+		w.Observe("GET /posts", 200, 3*time.Millisecond)
+	}
+	w.Observe("GET /posts", 200, 2*time.Second)
+
+	series := w.Series()
+	require.Len(t, series, 3)
+	assert.Zero(t, series[0].Requests)
+	assert.Equal(t, 2, series[1].Requests)
+	assert.Equal(t, 0.5, series[1].ErrorRate())
+	assert.Equal(t, 40*time.Millisecond, series[1].P95)
+	assert.Equal(t, 20, series[2].Requests)
+	assert.Equal(t, 5*time.Millisecond, series[2].P95)
+
+	summary := w.Summary(2)
+	assert.Equal(t, 22, summary.Requests)
+	assert.Equal(t, 1, summary.Errors)
+
+	routes := w.Routes(3)
+	require.Len(t, routes, 2)
+	assert.Equal(t, "GET /posts", routes[0].Route)
+	assert.Equal(t, 1, routes[1].Errors)
+
+	// Intervals older than the window are forgotten.
+	now = now.Add(3 * time.Minute)
+	w.Observe("GET /users", 200, time.Millisecond)
+	assert.Equal(t, 1, w.Summary(3).Requests)
+	assert.Len(t, w.Routes(3), 1)
+}
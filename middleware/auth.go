@@ -52,7 +52,7 @@ func RequireAuthWithConfig(config *AuthConfig) Middleware {
 				if config.ErrorHandler != nil {
 					config.ErrorHandler.Handle(w, r, apperrors.Unauthorized("Authentication required"))
 				} else {
-					http.Error(w, "Unauthorized", http.StatusUnauthorized)
+					apperrors.Handle(w, r, apperrors.Unauthorized("Authentication required"))
 				}
 				return
 			}
@@ -98,12 +98,12 @@ func RequirePermissions(manager *auth.Manager, permissions ...string) Middleware
 
 			user, err := manager.UserFromRequest(r)
 			if err != nil || user == nil {
-				http.Error(w, "Forbidden", http.StatusForbidden)
+				apperrors.Handle(w, r, apperrors.Forbidden(""))
 				return
 			}
 
 			if !user.HasAllPermissions(permissions...) {
-				http.Error(w, "Forbidden - Permission denied", http.StatusForbidden)
+				apperrors.Handle(w, r, apperrors.Forbidden("Permission denied"))
 				return
 			}
 
@@ -124,12 +124,12 @@ func RequireAnyPermission(manager *auth.Manager, permissions ...string) Middlewa
 
 			user, err := manager.UserFromRequest(r)
 			if err != nil || user == nil {
-				http.Error(w, "Forbidden", http.StatusForbidden)
+				apperrors.Handle(w, r, apperrors.Forbidden(""))
 				return
 			}
 
 			if !user.HasAnyPermission(permissions...) {
-				http.Error(w, "Forbidden - Permission denied", http.StatusForbidden)
+				apperrors.Handle(w, r, apperrors.Forbidden("Permission denied"))
 				return
 			}
 
@@ -155,12 +155,12 @@ func RequireRoles(manager *auth.Manager, roles ...string) Middleware {
 
 			user, err := manager.UserFromRequest(r)
 			if err != nil || user == nil {
-				http.Error(w, "Forbidden", http.StatusForbidden)
+				apperrors.Handle(w, r, apperrors.Forbidden(""))
 				return
 			}
 
 			if !user.HasAnyRole(roles...) {
-				http.Error(w, "Forbidden - Role required", http.StatusForbidden)
+				apperrors.Handle(w, r, apperrors.Forbidden("Role required"))
 				return
 			}
 
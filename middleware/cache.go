@@ -0,0 +1,430 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/auth"
+)
+
+// CachedResponse is a response stored by the Cache middleware.
+type CachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	ETag   string
+	Tags   []string
+	// Vary lists the request headers the response varies on: the entry
+	// stored under the key of the request then only points to the
+	// variants, stored under keys including the values of these headers.
+	Vary []string
+}
+
+// CacheStore stores cached responses. Implementations must be safe for
+// concurrent use. MemoryCacheStore is provided; implement this interface
+// in your project to share the cache across replicas.
+type CacheStore interface {
+	Get(ctx context.Context, key string) (*CachedResponse, bool)
+	Set(ctx context.Context, key string, resp *CachedResponse, ttl time.Duration)
+	// InvalidateTags removes every entry carrying one of tags.
+	InvalidateTags(ctx context.Context, tags ...string)
+	Flush(ctx context.Context)
+}
+
+// --- Memory store ---
+
+// MemoryCacheStore is an in-process CacheStore. Expired entries are
+// removed lazily on access.
+type MemoryCacheStore struct {
+	mu      sync.RWMutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	resp *CachedResponse
+	exp  time.Time
+}
+
+// NewMemoryCacheStore creates an empty in-memory store.
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{entries: make(map[string]*cacheEntry)}
+}
+
+// Get implements CacheStore.
+func (s *MemoryCacheStore) Get(_ context.Context, key string) (*CachedResponse, bool) {
+	s.mu.RLock()
+	e, ok := s.entries[key]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.exp) {
+		s.mu.Lock()
+		delete(s.entries, key)
+		s.mu.Unlock()
+		return nil, false
+	}
+	return e.resp, true
+}
+
+// Set implements CacheStore.
+func (s *MemoryCacheStore) Set(_ context.Context, key string, resp *CachedResponse, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = &cacheEntry{resp: resp, exp: time.Now().Add(ttl)}
+}
+
+// InvalidateTags implements CacheStore.
+func (s *MemoryCacheStore) InvalidateTags(_ context.Context, tags ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, e := range s.entries {
+		if hasAnyTag(e.resp.Tags, tags) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// Flush implements CacheStore.
+func (s *MemoryCacheStore) Flush(_ context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = make(map[string]*cacheEntry)
+}
+
+// Len returns the number of stored entries, including expired ones.
+func (s *MemoryCacheStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.entries)
+}
+
+func hasAnyTag(entryTags, tags []string) bool {
+	for _, t := range tags {
+		for _, et := range entryTags {
+			if t == et {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// --- Invalidation hooks ---
+
+var (
+	cacheHooksMu sync.RWMutex
+	cacheHooks   []func(ctx context.Context, tags []string)
+)
+
+// OnCacheInvalidate registers a hook called by InvalidateCache. Every Cache
+// middleware registers its store automatically; use this to forward
+// invalidations elsewhere (CDN purge, pub/sub to other replicas...).
+func OnCacheInvalidate(fn func(ctx context.Context, tags []string)) {
+	cacheHooksMu.Lock()
+	defer cacheHooksMu.Unlock()
+	cacheHooks = append(cacheHooks, fn)
+}
+
+// InvalidateCache drops cached responses carrying one of tags from every
+// Cache middleware. CRUD handlers call it with the resource slug after
+// each successful write.
+func InvalidateCache(ctx context.Context, tags ...string) {
+	if len(tags) == 0 {
+		return
+	}
+	cacheHooksMu.RLock()
+	hooks := append([]func(context.Context, []string){}, cacheHooks...)
+	cacheHooksMu.RUnlock()
+
+	for _, fn := range hooks {
+		fn(ctx, tags)
+	}
+}
+
+// --- Middleware ---
+
+// CacheKeyFunc builds the cache key of a request.
+type CacheKeyFunc func(r *http.Request) string
+
+var (
+	cacheScopeMu sync.RWMutex
+	cacheScope   func(ctx context.Context) string
+)
+
+// SetCacheScope sets the function returning the scope of a request, e.g.
+// its tenant, added to the default cache keys so that the scopes sharing a
+// host never share entries. The engine sets it to the tenant of the
+// request.
+func SetCacheScope(fn func(ctx context.Context) string) {
+	cacheScopeMu.Lock()
+	defer cacheScopeMu.Unlock()
+	cacheScope = fn
+}
+
+// cacheScopeOf returns the scope of ctx, see SetCacheScope.
+func cacheScopeOf(ctx context.Context) string {
+	cacheScopeMu.RLock()
+	fn := cacheScope
+	cacheScopeMu.RUnlock()
+	if fn == nil {
+		return ""
+	}
+	return fn(ctx)
+}
+
+// CacheKeyByURL keys responses by scope (see SetCacheScope), method, host,
+// path and query string. Only use it for pages that render the same
+// content for every user.
+func CacheKeyByURL(r *http.Request) string {
+	key := r.Method + " " + r.Host + r.URL.RequestURI()
+	if scope := cacheScopeOf(r.Context()); scope != "" {
+		key = "scope:" + scope + ":" + key
+	}
+	return key
+}
+
+// CacheKeyByUser keys responses by authenticated user and URL, for panel
+// pages whose content depends on the current user.
+func CacheKeyByUser(r *http.Request) string {
+	return fmt.Sprintf("user:%d:%s", auth.CurrentUser(r).ID, CacheKeyByURL(r))
+}
+
+// CacheTagsByPath tags responses with each path segment, so that
+// InvalidateCache(ctx, "users") drops "/admin/users" and "/admin/users/42".
+func CacheTagsByPath(r *http.Request) []string {
+	var tags []string
+	for _, segment := range strings.Split(r.URL.Path, "/") {
+		if segment != "" {
+			tags = append(tags, segment)
+		}
+	}
+	return tags
+}
+
+// CacheConfig configures the response cache middleware.
+type CacheConfig struct {
+	Store   CacheStore
+	TTL     time.Duration
+	KeyFunc CacheKeyFunc
+	// TagsFunc returns the invalidation tags of a response. Defaults to CacheTagsByPath.
+	TagsFunc func(r *http.Request) []string
+	// Tags are added to every entry, e.g. the slugs a dashboard aggregates.
+	Tags      []string
+	SkipPaths []string
+}
+
+// DefaultCacheConfig returns a default configuration.
+func DefaultCacheConfig() *CacheConfig {
+	return &CacheConfig{
+		TTL:      time.Minute,
+		KeyFunc:  CacheKeyByUser,
+		TagsFunc: CacheTagsByPath,
+	}
+}
+
+// Cache returns a middleware caching successful GET/HEAD responses for ttl.
+// Responses carry a strong ETag; a matching If-None-Match gets 304 Not
+// Modified. Entries are dropped by InvalidateCache, which CRUD writes fire
+// with the resource slug. A response with a Vary header is cached per value
+// of the request headers it names, e.g. the table fragment answering an HX
+// request apart from the full page; "Vary: *" is not cached.
+//
+// When SecurityHeaders generates CSP nonces, install Cache outside it so a
+// cached page is replayed with the policy matching its nonce.
+//
+//	store := middleware.NewMemoryCacheStore()
+//	mux.Handle("GET /admin/dashboard", middleware.Cache(store, 30*time.Second, middleware.CacheKeyByUser)(dashboard))
+func Cache(store CacheStore, ttl time.Duration, keyFunc CacheKeyFunc) Middleware {
+	config := DefaultCacheConfig()
+	config.Store = store
+	config.TTL = ttl
+	if keyFunc != nil {
+		config.KeyFunc = keyFunc
+	}
+	return CacheWithConfig(config)
+}
+
+// CacheWithConfig returns a cache middleware with custom config.
+func CacheWithConfig(config *CacheConfig) Middleware {
+	if config == nil {
+		config = DefaultCacheConfig()
+	}
+
+	if config.Store == nil {
+		config.Store = NewMemoryCacheStore()
+	}
+	if config.TTL <= 0 {
+		config.TTL = time.Minute
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = CacheKeyByUser
+	}
+	if config.TagsFunc == nil {
+		config.TagsFunc = CacheTagsByPath
+	}
+
+	store := config.Store
+	OnCacheInvalidate(func(ctx context.Context, tags []string) {
+		store.InvalidateTags(ctx, tags...)
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isCacheable(r, config.SkipPaths) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := config.KeyFunc(r)
+
+			if cached, ok := store.Get(r.Context(), key); ok && cached.Vary != nil {
+				cached, ok = store.Get(r.Context(), variantKey(key, r, cached.Vary))
+				if ok {
+					writeCached(w, r, cached, "HIT")
+					return
+				}
+			} else if ok {
+				writeCached(w, r, cached, "HIT")
+				return
+			}
+
+			// Headers set by outer middleware (request ID...) are per request:
+			// only store what the wrapped handler added.
+			before := w.Header().Clone()
+			crw := &cacheResponseWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(crw, r)
+
+			resp := &CachedResponse{
+				Status: crw.status,
+				Header: headerDiff(before, w.Header()),
+				Body:   crw.buf.Bytes(),
+			}
+
+			vary, varyAll := varyHeaders(w.Header())
+			if crw.status == http.StatusOK && w.Header().Get("Set-Cookie") == "" && !varyAll {
+				resp.ETag = fmt.Sprintf(`"%x"`, sha256.Sum256(resp.Body))
+				resp.Tags = append(config.TagsFunc(r), config.Tags...)
+				if vary != nil {
+					// The variants share the key, pointing to them.
+					store.Set(r.Context(), key, &CachedResponse{Tags: resp.Tags, Vary: vary}, config.TTL)
+					store.Set(r.Context(), variantKey(key, r, vary), resp, config.TTL)
+				} else {
+					store.Set(r.Context(), key, resp, config.TTL)
+				}
+			}
+
+			writeCached(w, r, resp, "MISS")
+		})
+	}
+}
+
+// isCacheable reports whether r may be served from or stored in the cache.
+func isCacheable(r *http.Request, skipPaths []string) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	// Datastar SSE streams must not be buffered.
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		return false
+	}
+	for _, p := range skipPaths {
+		if r.URL.Path == p {
+			return false
+		}
+	}
+	return true
+}
+
+// varyHeaders returns the canonical request headers named by the Vary
+// header of a response, and whether it varies on everything ("*").
+func varyHeaders(h http.Header) (names []string, all bool) {
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			switch {
+			case name == "*":
+				return nil, true
+			case name != "":
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	sort.Strings(names)
+	return slices.Compact(names), false
+}
+
+// variantKey returns the key of the variant of the response to r stored
+// under key, which varies on the request headers vary.
+func variantKey(key string, r *http.Request, vary []string) string {
+	var b strings.Builder
+	b.WriteString(key)
+	for _, name := range vary {
+		fmt.Fprintf(&b, "\x00%s=%q", name, r.Header.Values(name))
+	}
+	return b.String()
+}
+
+// writeCached writes resp, answering 304 when the client already has it.
+func writeCached(w http.ResponseWriter, r *http.Request, resp *CachedResponse, state string) {
+	h := w.Header()
+	for k, v := range resp.Header {
+		h[k] = v
+	}
+
+	if resp.ETag == "" {
+		w.WriteHeader(resp.Status)
+		_, _ = w.Write(resp.Body)
+		return
+	}
+
+	h.Set("ETag", resp.ETag)
+	h.Set("X-Cache", state)
+	if h.Get("Cache-Control") == "" {
+		h.Set("Cache-Control", "private, no-cache")
+	}
+
+	if r.Header.Get("If-None-Match") == resp.ETag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(resp.Status)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(resp.Body)
+	}
+}
+
+// headerDiff returns the headers of after that are missing or different in before.
+func headerDiff(before, after http.Header) http.Header {
+	diff := make(http.Header)
+	for k, v := range after {
+		if old, ok := before[k]; !ok || strings.Join(old, "\x00") != strings.Join(v, "\x00") {
+			diff[k] = append([]string(nil), v...)
+		}
+	}
+	return diff
+}
+
+// cacheResponseWriter buffers the response so it can be stored.
+type cacheResponseWriter struct {
+	http.ResponseWriter
+	buf    bytes.Buffer
+	status int
+}
+
+func (c *cacheResponseWriter) WriteHeader(code int) {
+	c.status = code
+}
+
+func (c *cacheResponseWriter) Write(b []byte) (int, error) {
+	return c.buf.Write(b)
+}
@@ -6,8 +6,15 @@
 //
 // Features:
 //   - CORS with configurable origins and methods
-//   - Rate limiting with token bucket algorithm
+//   - Rate limiting with token bucket algorithm (memory or Redis GCRA store)
 //   - Authentication middleware
+//   - Prometheus metrics (request count, latency, in-flight, response size)
+//   - OpenTelemetry tracing with W3C trace context propagation
+//   - IP allowlist/denylist with CIDR ranges
+//   - Maintenance mode with IP and role bypass
+//   - Security headers with CSP builder, per-request nonce and HSTS
+//   - Response caching with ETag/304 and tag-based invalidation
+//   - Request and correlation ID propagation (logs, error pages, outgoing calls)
 //   - Middleware stack composition
 //   - Conditional middleware execution
 //   - Path-based middleware filtering
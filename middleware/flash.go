@@ -7,6 +7,13 @@ import (
 )
 
 // Flash returns a middleware that loads flash messages into the context.
+// It works with session-based managers (behind the session middleware) and
+// cookie-based ones (flash.NewCookieManager) alike.
+//
+// Messages loaded for a request that ends with a redirect are kept for the
+// page redirected to. HTMX requests (HX-Request: true) receive the messages
+// in the HX-Trigger response header instead (see flash.WriteTrigger), unless
+// they are redirected too.
 func Flash(flashManager *flash.Manager) Middleware {
 	if flashManager == nil {
 		panic("flash.Manager is required")
@@ -14,12 +21,70 @@ func Flash(flashManager *flash.Manager) Middleware {
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			messages := flashManager.GetAndClearFromRequest(r)
-
-			ctx := flash.WithMessages(r.Context(), messages)
+			htmx := flash.IsHTMX(r)
+			ctx := flashManager.Load(r)
+			r = r.WithContext(ctx)
+			if !htmx {
+				ctx = flash.WithMessages(ctx, flashManager.GetAndClearFromRequest(r))
+			}
 			ctx = flash.WithManager(ctx, flashManager)
+			r = r.WithContext(ctx)
 
-			next.ServeHTTP(w, r.WithContext(ctx))
+			fw := &flashWriter{ResponseWriter: w, r: r, manager: flashManager, htmx: htmx}
+			next.ServeHTTP(fw, r)
+			// Nothing written: the server answers 200 OK
+			fw.handOver(http.StatusOK)
 		})
 	}
 }
+
+// flashWriter hands the messages over when the response header is written.
+type flashWriter struct {
+	http.ResponseWriter
+	r       *http.Request
+	manager *flash.Manager
+	htmx    bool
+	wrote   bool
+}
+
+func (fw *flashWriter) WriteHeader(status int) {
+	fw.handOver(status)
+	fw.ResponseWriter.WriteHeader(status)
+}
+
+// handOver keeps the messages loaded for a redirected request, or sends
+// those of an HTMX request in the HX-Trigger header. Only the first call
+// counts.
+func (fw *flashWriter) handOver(status int) {
+	if fw.wrote {
+		return
+	}
+	fw.wrote = true
+	redirect := status >= 300 && status < 400
+	switch {
+	case redirect && !fw.htmx:
+		fw.manager.Reflash(fw.r.Context())
+	case !redirect && fw.htmx:
+		_ = flash.WriteTrigger(fw.ResponseWriter, fw.manager.GetAndClear(fw.r.Context()))
+	}
+	fw.manager.Save(fw.r.Context(), fw.ResponseWriter)
+}
+
+func (fw *flashWriter) Write(b []byte) (int, error) {
+	if !fw.wrote {
+		fw.WriteHeader(http.StatusOK)
+	}
+	return fw.ResponseWriter.Write(b)
+}
+
+// Unwrap allows access to the original ResponseWriter.
+func (fw *flashWriter) Unwrap() http.ResponseWriter {
+	return fw.ResponseWriter
+}
+
+// Flush implements http.Flusher.
+func (fw *flashWriter) Flush() {
+	if f, ok := fw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
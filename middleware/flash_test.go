@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/bozz33/sublimeadmin/flash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flashServer adds a flash message on /save and redirects to /list, which
+// renders the texts of the messages loaded for the request.
+func flashServer() http.Handler {
+	session := scs.New()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/save", func(w http.ResponseWriter, r *http.Request) {
+		flash.Success(r, "Saved")
+		http.Redirect(w, r, "/list", http.StatusSeeOther)
+	})
+	mux.HandleFunc("/bounce", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/list", http.StatusSeeOther)
+	})
+	mux.HandleFunc("/list", func(w http.ResponseWriter, r *http.Request) {
+		for _, msg := range flash.Get(r) {
+			_, _ = w.Write([]byte(msg.Text + ";"))
+		}
+	})
+	return session.LoadAndSave(Flash(flash.NewManager(session))(mux))
+}
+
+// get sends a request carrying the session cookies and returns the response.
+func get(t *testing.T, h http.Handler, path string, cookies []*http.Cookie, header ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	return rw
+}
+
+func TestFlash_survives_redirects(t *testing.T) {
+	h := flashServer()
+
+	rw := get(t, h, "/save", nil)
+	require.Equal(t, http.StatusSeeOther, rw.Code)
+	cookies := rw.Result().Cookies()
+	require.NotEmpty(t, cookies)
+
+	// A redirect in between keeps the message for the page it leads to
+	rw = get(t, h, "/bounce", cookies)
+	require.Equal(t, http.StatusSeeOther, rw.Code)
+
+	rw = get(t, h, "/list", cookies)
+	assert.Equal(t, "Saved;", rw.Body.String())
+
+	rw = get(t, h, "/list", cookies)
+	assert.Empty(t, rw.Body.String(), "messages are displayed once")
+}
+
+func TestFlash_htmx_trigger(t *testing.T) {
+	h := flashServer()
+
+	cookies := get(t, h, "/save", nil).Result().Cookies()
+
+	rw := get(t, h, "/list", cookies, "HX-Request", "true")
+	assert.Empty(t, rw.Body.String(), "HTMX requests get the messages in a header")
+	trigger := rw.Header().Get(flash.TriggerHeader)
+	assert.True(t, strings.Contains(trigger, `"flash"`) && strings.Contains(trigger, `"Saved"`), trigger)
+
+	rw = get(t, h, "/list", cookies)
+	assert.Empty(t, rw.Body.String(), "messages sent in the header are cleared")
+}
+
+func TestFlash_requires_manager(t *testing.T) {
+	assert.Panics(t, func() { Flash(nil) })
+}
+
+func TestFlash_cookie_manager(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/save", func(w http.ResponseWriter, r *http.Request) {
+		flash.Success(r, "Saved")
+		http.Redirect(w, r, "/list", http.StatusSeeOther)
+	})
+	mux.HandleFunc("/list", func(w http.ResponseWriter, r *http.Request) {
+		for _, msg := range flash.Get(r) {
+			_, _ = w.Write([]byte(msg.Text + ";"))
+		}
+	})
+	h := Flash(flash.NewCookieManager([]byte("secret")))(mux)
+
+	cookies := get(t, h, "/save", nil).Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	rw := get(t, h, "/list", cookies)
+	assert.Equal(t, "Saved;", rw.Body.String())
+	cleared := rw.Result().Cookies()
+	require.Len(t, cleared, 1)
+	assert.Equal(t, -1, cleared[0].MaxAge)
+}
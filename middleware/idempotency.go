@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// IdempotencyStore records idempotency keys, so that a request submitted
+// twice (a double click, a retried POST) is only processed once.
+// Implementations must be safe for concurrent use.
+//
+// MemoryIdempotencyStore keeps the keys per process. Use
+// RedisIdempotencyStore to share them across replicas.
+type IdempotencyStore interface {
+	// Claim records key for ttl. It reports false when the key is already
+	// recorded, i.e. the request was already processed.
+	Claim(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Release forgets key, e.g. when the request it claimed failed and may
+	// be sent again.
+	Release(ctx context.Context, key string) error
+}
+
+// --- Memory store ---
+
+// MemoryIdempotencyStore is an in-memory IdempotencyStore. Expired keys are
+// removed as new ones are claimed.
+type MemoryIdempotencyStore struct {
+	mu     sync.Mutex
+	keys   map[string]time.Time // key → expiry
+	claims int
+	now    func() time.Time
+}
+
+// NewMemoryIdempotencyStore creates an in-memory store.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		keys: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+// Claim implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Claim(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if expiry, ok := s.keys[key]; ok && now.Before(expiry) {
+		return false, nil
+	}
+	s.keys[key] = now.Add(ttl)
+
+	// Sweep the expired keys every 1000 claims
+	s.claims++
+	if s.claims%1000 == 0 {
+		for k, expiry := range s.keys {
+			if !now.Before(expiry) {
+				delete(s.keys, k)
+			}
+		}
+	}
+	return true, nil
+}
+
+// Release implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, key)
+	return nil
+}
+
+// --- Redis store ---
+
+// idempotencyScript sets the key unless it exists (SET NX PX).
+const idempotencyScript = `
+if redis.call("SET", KEYS[1], 1, "NX", "PX", ARGV[1]) then
+  return 1
+end
+return 0
+`
+
+// releaseScript deletes the key.
+const releaseScript = `return redis.call("DEL", KEYS[1])`
+
+// RedisIdempotencyStore is an IdempotencyStore shared by every replica
+// connected to the same Redis.
+type RedisIdempotencyStore struct {
+	client RedisEvaler
+	prefix string
+}
+
+// NewRedisIdempotencyStore creates a Redis-backed store. Keys are
+// namespaced with prefix (default "idempotency:").
+func NewRedisIdempotencyStore(client RedisEvaler, prefix string) *RedisIdempotencyStore {
+	if prefix == "" {
+		prefix = "idempotency:"
+	}
+	return &RedisIdempotencyStore{client: client, prefix: prefix}
+}
+
+// Claim implements IdempotencyStore.
+func (s *RedisIdempotencyStore) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if ttl < time.Millisecond {
+		return false, fmt.Errorf("idempotency: ttl must be at least 1ms")
+	}
+	raw, err := s.client.Eval(ctx, idempotencyScript, []string{s.prefix + key}, ttl.Milliseconds())
+	if err != nil {
+		return false, fmt.Errorf("idempotency: redis eval: %w", err)
+	}
+	claimed, err := toInt64(raw)
+	if err != nil {
+		return false, fmt.Errorf("idempotency: unexpected redis reply %v", raw)
+	}
+	return claimed == 1, nil
+}
+
+// Release implements IdempotencyStore.
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key string) error {
+	if _, err := s.client.Eval(ctx, releaseScript, []string{s.prefix + key}); err != nil {
+		return fmt.Errorf("idempotency: redis eval: %w", err)
+	}
+	return nil
+}
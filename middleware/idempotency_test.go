@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryIdempotencyStore_Claim(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := NewMemoryIdempotencyStore()
+	store.now = func() time.Time { return now }
+
+	claimed, err := store.Claim(ctx, "abc", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = store.Claim(ctx, "abc", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, claimed, "a key is claimed once")
+
+	claimed, _ = store.Claim(ctx, "def", time.Minute)
+	assert.True(t, claimed)
+
+	now = now.Add(time.Minute)
+	claimed, _ = store.Claim(ctx, "abc", time.Minute)
+	assert.True(t, claimed, "an expired key can be claimed again")
+}
+
+func TestRedisIdempotencyStore_Claim(t *testing.T) {
+	ctx := context.Background()
+	redis := &fakeRedis{reply: int64(1)}
+	store := NewRedisIdempotencyStore(redis, "")
+
+	claimed, err := store.Claim(ctx, "abc", 10*time.Minute)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, []string{"idempotency:abc"}, redis.keys)
+	assert.Equal(t, []any{int64(600000)}, redis.args)
+
+	redis.reply = int64(0)
+	claimed, err = store.Claim(ctx, "abc", 10*time.Minute)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	redis.err = errors.New("connection refused")
+	_, err = store.Claim(ctx, "abc", 10*time.Minute)
+	assert.Error(t, err)
+
+	_, err = store.Claim(ctx, "abc", 0)
+	assert.Error(t, err)
+}
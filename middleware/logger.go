@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/bozz33/sublimeadmin/clientip"
 	"github.com/bozz33/sublimeadmin/logger"
 	"github.com/google/uuid"
 	"github.com/samber/lo"
@@ -17,13 +18,17 @@ type LoggerConfig struct {
 	SkipStatus  []int
 	LogBody     bool
 	MaxBodySize int
+
+	// SlowRequestThreshold logs a "slow request" warning, with the route
+	// and resource slug, for requests slower than this. 0 disables it.
+	SlowRequestThreshold time.Duration
 }
 
 // DefaultLoggerConfig returns a default configuration.
 func DefaultLoggerConfig(log *logger.Logger) *LoggerConfig {
 	return &LoggerConfig{
 		Logger:      log,
-		SkipPaths:   []string{"/health", "/metrics", "/favicon.ico"},
+		SkipPaths:   []string{"/health", "/healthz", "/readyz", "/metrics", "/favicon.ico"},
 		SkipStatus:  []int{},
 		LogBody:     false,
 		MaxBodySize: 1024, // 1KB
@@ -52,13 +57,17 @@ func LoggerWithConfig(config *LoggerConfig) Middleware {
 				return
 			}
 
-			requestID := uuid.New().String()
+			// Reuse the ID set by the RequestID middleware, if any.
+			requestID := RequestIDFromContext(r.Context())
+			if requestID == "" {
+				requestID = uuid.New().String()
+			}
 
 			reqLogger := config.Logger.With(
 				"request_id", requestID,
 				"method", r.Method,
 				"path", r.URL.Path,
-				"remote_addr", getClientIP(r),
+				"remote_addr", clientip.FromRequest(r),
 			)
 
 			if ua := r.Header.Get("User-Agent"); ua != "" {
@@ -69,12 +78,14 @@ func LoggerWithConfig(config *LoggerConfig) Middleware {
 			ctx = withRequestID(ctx, requestID)
 
 			rw := NewResponseWriter(w)
+			req := logger.TrackRequest(r.WithContext(ctx))
 
 			start := time.Now()
 
-			next.ServeHTTP(rw, r.WithContext(ctx))
+			next.ServeHTTP(rw, req)
 
 			duration := time.Since(start)
+			reqLogger.SlowRequest(req, duration, config.SlowRequestThreshold)
 
 			if lo.Contains(config.SkipStatus, rw.Status()) {
 				return
@@ -113,81 +124,20 @@ func logRequest(log *logger.Logger, r *http.Request, rw *responseWriter, duratio
 	}
 }
 
-// getClientIP retrieves the client IP address.
-func getClientIP(r *http.Request) string {
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		ips := lo.Map(
-			lo.Filter(
-				lo.Map(
-					splitAndTrim(xff, ","),
-					func(ip string, _ int) string {
-						return ip
-					},
-				),
-				func(ip string, _ int) bool {
-					return ip != ""
-				},
-			),
-			func(ip string, _ int) string {
-				return ip
-			},
-		)
-
-		if len(ips) > 0 {
-			return ips[0]
-		}
-	}
-
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return xri
-	}
-
-	return r.RemoteAddr
-}
-
-// splitAndTrim splits a string and trims spaces.
-func splitAndTrim(s, sep string) []string {
-	var result []string
-	var current string
-
-	for _, char := range s {
-		if string(char) == sep {
-			if current != "" {
-				result = append(result, current)
-				current = ""
-			}
-		} else if char != ' ' && char != '\t' {
-			current += string(char)
-		}
-	}
-
-	if current != "" {
-		result = append(result, current)
-	}
-
-	return result
-}
-
-type contextKey string
-
-const requestIDKey contextKey = "request_id"
-
 // withRequestID adds the request ID to the context.
 func withRequestID(ctx context.Context, requestID string) context.Context {
-	return context.WithValue(ctx, requestIDKey, requestID)
+	return logger.ContextWithRequestID(ctx, requestID)
 }
 
-// RequestID retrieves the request ID from the context.
-func RequestID(ctx context.Context) string {
-	if id, ok := ctx.Value(requestIDKey).(string); ok {
-		return id
-	}
-	return ""
+// RequestIDFromContext retrieves the request ID from the context.
+// It is shared with logger.RequestIDFromContext.
+func RequestIDFromContext(ctx context.Context) string {
+	return logger.RequestIDFromContext(ctx)
 }
 
-// GetRequestID is an alias for RequestID.
+// GetRequestID retrieves the request ID of r.
 func GetRequestID(r *http.Request) string {
-	return RequestID(r.Context())
+	return RequestIDFromContext(r.Context())
 }
 
 // LoggerFromRequest retrieves the logger from the request.
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/auth"
+	"github.com/bozz33/sublimeadmin/clientip"
+	"github.com/bozz33/sublimeadmin/logger"
+	errorViews "github.com/bozz33/sublimeadmin/views/errors"
+)
+
+// MaintenanceConfig configures the maintenance mode middleware.
+type MaintenanceConfig struct {
+	// Enabled is checked on every request, so maintenance can be toggled at
+	// runtime (flag file, config reload, database setting...).
+	Enabled func() bool
+	// BypassIPs lists IPs or CIDR ranges that keep full access.
+	BypassIPs []string
+	// BypassRoles lets authenticated users with one of these roles through.
+	// Requires the auth middleware to run before Maintenance.
+	BypassRoles []string
+	// SkipPrefixes are always served (assets, health checks, login page).
+	SkipPrefixes []string
+	Message      string
+	RetryAfter   time.Duration
+	// IPFunc extracts the client IP matched against BypassIPs. Defaults to
+	// clientip.FromRequest, trusting the forwarding headers of the proxies
+	// of clientip.SetTrustedProxies only.
+	IPFunc func(r *http.Request) string
+	// Handler renders the maintenance response. Defaults to the branded
+	// 503 page (or a JSON body for API clients).
+	Handler http.Handler
+}
+
+// DefaultMaintenanceConfig returns a default configuration.
+func DefaultMaintenanceConfig() *MaintenanceConfig {
+	return &MaintenanceConfig{
+		BypassRoles:  []string{"admin"},
+		SkipPrefixes: []string{"/assets/", "/health", "/readyz", "/favicon.ico"},
+		RetryAfter:   10 * time.Minute,
+		IPFunc:       clientip.FromRequest,
+	}
+}
+
+// Maintenance returns a middleware answering 503 with a maintenance page
+// while enabled() is true. Requests from bypassIPs and admins pass through:
+//
+//	var down atomic.Bool
+//	handler := middleware.Maintenance(down.Load, []string{"10.0.0.0/8"})(mux)
+func Maintenance(enabled func() bool, bypassIPs []string) Middleware {
+	config := DefaultMaintenanceConfig()
+	config.Enabled = enabled
+	config.BypassIPs = bypassIPs
+	return MaintenanceWithConfig(config)
+}
+
+// MaintenanceWithConfig returns a maintenance middleware with custom config.
+func MaintenanceWithConfig(config *MaintenanceConfig) Middleware {
+	if config == nil {
+		config = DefaultMaintenanceConfig()
+	}
+
+	if config.Enabled == nil {
+		config.Enabled = func() bool { return false }
+	}
+
+	if config.IPFunc == nil {
+		config.IPFunc = clientip.FromRequest
+	}
+
+	if config.Handler == nil {
+		config.Handler = maintenancePage(config)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !config.Enabled() || config.bypass(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if config.RetryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(config.RetryAfter.Seconds())))
+			}
+			config.Handler.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bypass reports whether r may skip the maintenance page.
+func (c *MaintenanceConfig) bypass(r *http.Request) bool {
+	for _, prefix := range c.SkipPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+
+	if matchIP(c.IPFunc(r), c.BypassIPs) {
+		return true
+	}
+
+	if len(c.BypassRoles) > 0 {
+		user := auth.CurrentUser(r)
+		return user.IsAuthenticated() && user.HasAnyRole(c.BypassRoles...)
+	}
+
+	return false
+}
+
+// maintenancePage renders the default 503 response.
+func maintenancePage(config *MaintenanceConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+
+		if strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":"Service under maintenance"}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		page := errorViews.Maintenance(config.Message, int(config.RetryAfter.Seconds()))
+		if err := page.Render(r.Context(), w); err != nil {
+			logger.FromContext(r.Context()).Error("maintenance page render failed", logger.Err(err))
+		}
+	})
+}
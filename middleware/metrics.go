@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bozz33/sublimeadmin/metrics"
+	"github.com/samber/lo"
+)
+
+// MetricsConfig configures the metrics middleware.
+type MetricsConfig struct {
+	// RouteFunc returns the route label for a served request. It is called
+	// after the handler ran, so http.Request.Pattern set by ServeMux is
+	// available. Defaults to RoutePattern.
+	RouteFunc func(r *http.Request) string
+	SkipPaths []string
+}
+
+// DefaultMetricsConfig returns a default configuration.
+func DefaultMetricsConfig() *MetricsConfig {
+	return &MetricsConfig{
+		RouteFunc: RoutePattern,
+		SkipPaths: []string{"/metrics", "/favicon.ico"},
+	}
+}
+
+// Metrics returns a middleware recording request count, latency, in-flight
+// requests and response size in metrics.Default, labeled by route pattern
+// and status, and the recent traffic in metrics.HTTPWindow. Serve them with
+// metrics.Handler():
+//
+//	mux.Handle("GET /metrics", metrics.Handler())
+//	handler := middleware.Metrics()(mux)
+func Metrics() Middleware {
+	return MetricsWithConfig(DefaultMetricsConfig())
+}
+
+// MetricsWithConfig returns a metrics middleware with custom config.
+func MetricsWithConfig(config *MetricsConfig) Middleware {
+	if config == nil {
+		config = DefaultMetricsConfig()
+	}
+
+	if config.RouteFunc == nil {
+		config.RouteFunc = RoutePattern
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if lo.Contains(config.SkipPaths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			metrics.HTTPInFlight.Inc()
+			defer metrics.HTTPInFlight.Dec()
+
+			rw := NewResponseWriter(w)
+			start := time.Now()
+
+			next.ServeHTTP(rw, r)
+
+			elapsed := time.Since(start)
+			route := config.RouteFunc(r)
+			status := strconv.Itoa(rw.Status())
+
+			method := methodLabel(r.Method)
+
+			metrics.HTTPRequests.WithLabelValues(method, route, status).Inc()
+			metrics.HTTPDuration.WithLabelValues(method, route).Observe(elapsed.Seconds())
+			metrics.HTTPResponseSize.WithLabelValues(method, route).Observe(float64(rw.Size()))
+			metrics.HTTPWindow.Observe(route, rw.Status(), elapsed)
+		})
+	}
+}
+
+// RoutePattern returns the ServeMux pattern that matched r, or "unmatched".
+// Raw paths are never used as labels to keep metric cardinality bounded.
+// The pattern is only visible when no middleware between Metrics and the
+// ServeMux replaced the request (e.g. with r.WithContext); place Metrics
+// closest to the mux or provide a custom RouteFunc otherwise.
+func RoutePattern(r *http.Request) string {
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+	return "unmatched"
+}
+
+// knownMethods are the HTTP methods labeled as such by Metrics.
+var knownMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace,
+}
+
+// methodLabel returns the method label of a request: "OTHER" for a method
+// outside knownMethods, so clients cannot grow the metric cardinality.
+func methodLabel(method string) string {
+	if lo.Contains(knownMethods, method) {
+		return method
+	}
+	return "OTHER"
+}
@@ -1,13 +1,24 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/bozz33/sublimeadmin/auth"
+	"github.com/bozz33/sublimeadmin/clientip"
+	"github.com/bozz33/sublimeadmin/logger"
+	"github.com/bozz33/sublimeadmin/metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 func TestNewStack(t *testing.T) {
@@ -375,6 +386,532 @@ func BenchmarkChain(b *testing.B) {
 	}
 }
 
+func TestMetrics(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	})
+	handler := Metrics()(mux)
+
+	counter := metrics.HTTPRequests.WithLabelValues("GET", "GET /users/{id}", "201")
+	before := counter.Value()
+	metrics.HTTPWindow.Reset()
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, before+1, counter.Value())
+	assert.Equal(t, float64(0), metrics.HTTPInFlight.Value())
+
+	out := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(out, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, out.Body.String(), `sublime_http_request_duration_seconds_count{method="GET",route="GET /users/{id}"}`)
+
+	routes := metrics.HTTPWindow.Routes(1)
+	if assert.Len(t, routes, 1) {
+		assert.Equal(t, "GET /users/{id}", routes[0].Route)
+	}
+}
+
+func TestMetrics_UnknownMethod(t *testing.T) {
+	handler := Metrics()(http.NotFoundHandler())
+	counter := metrics.HTTPRequests.WithLabelValues("OTHER", "unmatched", "404")
+	before := counter.Value()
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("FOO42", "/users", nil))
+
+	assert.Equal(t, before+1, counter.Value())
+	out := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(out, httptest.NewRequest("GET", "/metrics", nil))
+	assert.NotContains(t, out.Body.String(), `method="FOO42"`)
+}
+
+func TestRoutePattern_Unmatched(t *testing.T) {
+	req := httptest.NewRequest("GET", "/anything/123", nil)
+	assert.Equal(t, "unmatched", RoutePattern(req))
+}
+
+// spanRecorder is a minimal TracerProvider recording the last started span.
+type spanRecorder struct {
+	noop.TracerProvider
+	span *recordedSpan
+}
+
+type recordedSpan struct {
+	noop.Span
+	name   string
+	parent trace.SpanContext
+	sc     trace.SpanContext
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	ended  bool
+}
+
+func (p *spanRecorder) Tracer(string, ...trace.TracerOption) trace.Tracer { return spanTracer{p: p} }
+
+type spanTracer struct {
+	noop.Tracer
+	p *spanRecorder
+}
+
+func (t spanTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+	parent := trace.SpanContextFromContext(ctx)
+	span := &recordedSpan{name: name, parent: parent, attrs: map[attribute.Key]attribute.Value{}}
+	span.sc = trace.NewSpanContext(trace.SpanContextConfig{TraceID: parent.TraceID(), SpanID: trace.SpanID{9}})
+	span.SetAttributes(cfg.Attributes()...)
+	t.p.span = span
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func (s *recordedSpan) SpanContext() trace.SpanContext      { return s.sc }
+func (s *recordedSpan) SetName(name string)                 { s.name = name }
+func (s *recordedSpan) SetStatus(code codes.Code, _ string) { s.status = code }
+func (s *recordedSpan) End(...trace.SpanEndOption)          { s.ended = true }
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, a := range kv {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func TestTracing(t *testing.T) {
+	tp := &spanRecorder{}
+	var inner trace.SpanContext
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		inner = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	handler := TracingWithConfig(&TracingConfig{
+		TracerProvider: tp,
+		Propagator:     propagation.TraceContext{},
+	})(mux)
+
+	req := httptest.NewRequest("GET", "/users/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	span := tp.span
+	require.NotNil(t, span)
+	assert.True(t, span.ended)
+	assert.Equal(t, "GET /users/{id}", span.name)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.parent.TraceID().String())
+	assert.True(t, span.parent.IsRemote())
+	assert.Equal(t, span.sc, inner)
+	assert.Equal(t, "GET /users/{id}", span.attrs["http.route"].AsString())
+	assert.Equal(t, int64(500), span.attrs["http.response.status_code"].AsInt64())
+	assert.Equal(t, codes.Error, span.status)
+}
+
+func TestTracing_SkipPaths(t *testing.T) {
+	tp := &spanRecorder{}
+	handler := Tracing(tp)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/metrics", nil))
+	assert.Nil(t, tp.span)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/login", nil))
+	require.NotNil(t, tp.span)
+	assert.Equal(t, "HTTP POST", tp.span.name)
+	assert.Equal(t, codes.Unset, tp.span.status)
+}
+
+func TestIPFilter(t *testing.T) {
+	handler := IPFilter(
+		[]string{"192.168.0.0/16", "10.0.0.5"},
+		[]string{"192.168.1.66"},
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		ip   string
+		want int
+	}{
+		{"192.168.4.2", http.StatusOK},
+		{"10.0.0.5", http.StatusOK},
+		{"192.168.1.66", http.StatusForbidden},
+		{"10.0.0.6", http.StatusForbidden},
+		{"8.8.8.8", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/admin", nil)
+			req.RemoteAddr = tt.ip + ":1234"
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+func TestIPFilter_DenyOnly(t *testing.T) {
+	require.NoError(t, clientip.SetTrustedProxies("10.0.0.0/8"))
+	defer func() { _ = clientip.SetTrustedProxies() }()
+	handler := IPFilter(nil, []string{"203.0.113.0/24"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.2:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.9, 10.0.0.1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "198.51.100.1:1234"
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestIPFilter_SpoofedHeader(t *testing.T) {
+	handler := IPFilter([]string{"10.0.0.0/8"}, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "8.8.8.8:1234"
+	req.Header.Set("X-Forwarded-For", "10.0.0.5")
+	req.Header.Set("X-Real-IP", "10.0.0.5")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code, "the headers of an untrusted peer should be ignored")
+}
+
+func TestMaintenance(t *testing.T) {
+	enabled := true
+	handler := Maintenance(func() bool { return enabled }, []string{"10.0.0.0/8"})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("renders page", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/users", nil)
+		req.RemoteAddr = "198.51.100.1:1234"
+		rec := serve(req)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, "600", rec.Header().Get("Retry-After"))
+		assert.Contains(t, rec.Body.String(), "Maintenance")
+	})
+
+	t.Run("json clients", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/users", nil)
+		req.Header.Set("Accept", "application/json")
+		rec := serve(req)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.JSONEq(t, `{"error":"Service under maintenance"}`, rec.Body.String())
+	})
+
+	t.Run("bypass ip", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/users", nil)
+		req.RemoteAddr = "10.1.2.3:1234"
+		assert.Equal(t, http.StatusOK, serve(req).Code)
+	})
+
+	t.Run("bypass admin", func(t *testing.T) {
+		user := auth.NewUser(1, "admin@example.com", "Admin")
+		user.AddRole("admin")
+		req := httptest.NewRequest("GET", "/users", nil)
+		req = req.WithContext(auth.WithUser(req.Context(), user))
+		assert.Equal(t, http.StatusOK, serve(req).Code)
+	})
+
+	t.Run("assets", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(httptest.NewRequest("GET", "/assets/styles.css", nil)).Code)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		enabled = false
+		defer func() { enabled = true }()
+		assert.Equal(t, http.StatusOK, serve(httptest.NewRequest("GET", "/users", nil)).Code)
+	})
+}
+
+func TestCSP_Builder(t *testing.T) {
+	csp := NewCSP().
+		DefaultSrc("'self'").
+		ScriptSrc("'self'", NonceSource).
+		ImgSrc("'self'").
+		ImgSrc("data:").
+		ReportURI("/csp-report")
+
+	assert.True(t, csp.UsesNonce())
+	assert.Equal(t, []string{"'self'", "data:"}, csp.Sources("img-src"))
+	assert.Equal(t,
+		"default-src 'self'; script-src 'self' 'nonce-abc'; img-src 'self' data:; report-uri /csp-report",
+		csp.String("abc"))
+
+	csp.Remove("img-src").Set("script-src", "'none'")
+	assert.False(t, csp.UsesNonce())
+	assert.Equal(t, "default-src 'self'; script-src 'none'; report-uri /csp-report", csp.String(""))
+
+	assert.Equal(t, "Content-Security-Policy", csp.HeaderName())
+	assert.Equal(t, "Content-Security-Policy-Report-Only", csp.ReportOnly(true).HeaderName())
+}
+
+func TestSecurityHeaders_Default(t *testing.T) {
+	handler := SecurityHeaders()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	assert.Equal(t, "nosniff", rec.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "SAMEORIGIN", rec.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "strict-origin-when-cross-origin", rec.Header().Get("Referrer-Policy"))
+	assert.Empty(t, rec.Header().Get("Content-Security-Policy"))
+	assert.Empty(t, rec.Header().Get("Strict-Transport-Security"))
+}
+
+func TestSecurityHeaders_CSPNonce(t *testing.T) {
+	config := DefaultSecurityHeadersConfig()
+	config.CSP = DefaultCSP()
+
+	var nonces []string
+	handler := SecurityHeadersWithConfig(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonces = append(nonces, CSPNonce(r.Context()))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	require.Len(t, nonces, 2)
+	assert.NotEmpty(t, nonces[0])
+	assert.NotEqual(t, nonces[0], nonces[1])
+	assert.Contains(t, rec.Header().Get("Content-Security-Policy"), "script-src 'self' 'nonce-"+nonces[0]+"'")
+}
+
+func TestSecurityHeaders_HSTS(t *testing.T) {
+	config := DefaultSecurityHeadersConfig()
+	config.HSTS = &HSTSConfig{MaxAge: 365 * 24 * time.Hour, IncludeSubDomains: true, Preload: true}
+	handler := SecurityHeadersWithConfig(config)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Empty(t, rec.Header().Get("Strict-Transport-Security"))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, "max-age=31536000; includeSubDomains; preload", rec.Header().Get("Strict-Transport-Security"))
+}
+
+func TestCache(t *testing.T) {
+	store := NewMemoryCacheStore()
+	calls := 0
+	handler := Cache(store, time.Minute, CacheKeyByURL)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = fmt.Fprint(w, "<h1>Dashboard</h1>")
+	}))
+
+	outer := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", r.URL.Query().Get("rid"))
+		handler.ServeHTTP(w, r)
+	})
+
+	rec := httptest.NewRecorder()
+	outer.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/dashboard", nil))
+	assert.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	rec = httptest.NewRecorder()
+	outer.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/dashboard", nil))
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	assert.Equal(t, "<h1>Dashboard</h1>", rec.Body.String())
+	assert.Equal(t, "text/html", rec.Header().Get("Content-Type"))
+	assert.Equal(t, 1, calls)
+
+	req := httptest.NewRequest("GET", "/admin/dashboard", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	outer.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/dashboard", nil))
+	assert.Empty(t, rec.Header().Get("X-Cache"))
+	assert.Equal(t, 2, calls)
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	store := NewMemoryCacheStore()
+	handler := CacheWithConfig(&CacheConfig{
+		Store:   store,
+		TTL:     time.Minute,
+		KeyFunc: CacheKeyByURL,
+		Tags:    []string{"dashboard"},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, r.URL.Path)
+	}))
+
+	for _, path := range []string{"/admin/users", "/admin/users/42", "/admin/orders"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	require.Equal(t, 3, store.Len())
+
+	InvalidateCache(context.Background(), "users")
+	assert.Equal(t, 1, store.Len())
+
+	InvalidateCache(context.Background(), "dashboard")
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestCache_Vary(t *testing.T) {
+	store := NewMemoryCacheStore()
+	calls := 0
+	handler := Cache(store, time.Minute, CacheKeyByURL)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Add("Vary", "HX-Request, HX-Target")
+		if r.Header.Get("HX-Request") == "true" {
+			_, _ = fmt.Fprint(w, "<table>")
+			return
+		}
+		_, _ = fmt.Fprint(w, "<html><table>")
+	}))
+	get := func(hx bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/admin/posts", nil)
+		if hx {
+			req.Header.Set("HX-Request", "true")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, "<table>", get(true).Body.String())
+	assert.Equal(t, "<html><table>", get(false).Body.String())
+	assert.Equal(t, 2, calls)
+
+	rec := get(true)
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	assert.Equal(t, "<table>", rec.Body.String())
+	rec = get(false)
+	assert.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	assert.Equal(t, "<html><table>", rec.Body.String())
+	assert.Equal(t, 2, calls)
+
+	InvalidateCache(context.Background(), "posts")
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestCache_VaryAll(t *testing.T) {
+	store := NewMemoryCacheStore()
+	handler := Cache(store, time.Minute, CacheKeyByURL)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Vary", "*")
+		_, _ = fmt.Fprint(w, "ok")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/admin/posts", nil))
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestCacheKeyByURL_host(t *testing.T) {
+	acme := httptest.NewRequest("GET", "http://acme.example.com/admin/posts", nil)
+	globex := httptest.NewRequest("GET", "http://globex.example.com/admin/posts", nil)
+	assert.NotEqual(t, CacheKeyByURL(acme), CacheKeyByURL(globex))
+	assert.NotEqual(t, CacheKeyByUser(acme), CacheKeyByUser(globex))
+}
+
+func TestCache_SkipsErrors(t *testing.T) {
+	store := NewMemoryCacheStore()
+	handler := Cache(store, time.Minute, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/reports", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "boom")
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestRequestID(t *testing.T) {
+	var gotID, gotCorrelation string
+	handler := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = RequestIDFromContext(r.Context())
+		gotCorrelation = CorrelationIDFromContext(r.Context())
+	}))
+
+	t.Run("generates", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+		require.NotEmpty(t, gotID)
+		assert.Equal(t, gotID, rec.Header().Get("X-Request-ID"))
+		assert.Equal(t, gotID, gotCorrelation)
+	})
+
+	t.Run("accepts incoming", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Request-ID", "edge-42")
+		req.Header.Set("X-Correlation-ID", "order-7")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, "edge-42", gotID)
+		assert.Equal(t, "order-7", gotCorrelation)
+		assert.Equal(t, "order-7", rec.Header().Get("X-Correlation-ID"))
+	})
+
+	t.Run("rejects unsafe incoming", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Request-ID", "bad id\ninjected")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.NotEqual(t, "bad id\ninjected", gotID)
+		assert.NotEmpty(t, gotID)
+	})
+}
+
+func TestRequestID_SharedWithLogger(t *testing.T) {
+	var loggerID string
+	handler := RequestID()(LoggerWithConfig(&LoggerConfig{Logger: logger.Default()})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			loggerID = logger.RequestIDFromContext(r.Context())
+		})))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/users", nil))
+
+	assert.Equal(t, rec.Header().Get("X-Request-ID"), loggerID)
+}
+
+func TestRequestIDTransport(t *testing.T) {
+	var received http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: RequestIDTransport(nil)}
+	handler := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := http.NewRequestWithContext(r.Context(), "GET", upstream.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "abc-123")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, "abc-123", received.Get("X-Request-ID"))
+	assert.Equal(t, "abc-123", received.Get("X-Correlation-ID"))
+}
+
 func ExampleStack() {
 	// Create a middleware stack
 	stack := NewStack(
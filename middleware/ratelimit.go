@@ -2,14 +2,17 @@ package middleware
 
 import (
 	"fmt"
+	"log/slog"
 	"net"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/bozz33/sublimeadmin/apperrors"
 	"github.com/bozz33/sublimeadmin/auth"
-	"golang.org/x/time/rate"
+	"github.com/bozz33/sublimeadmin/clientip"
+	"github.com/bozz33/sublimeadmin/logger"
 )
 
 // KeyFunc extracts a unique key from a request to identify the client.
@@ -23,21 +26,25 @@ type RateLimitConfig struct {
 	WhitelistIPs      []string
 	CleanupInterval   time.Duration
 	OnLimitExceeded   func(r *http.Request, key string)
-}
 
-// RateLimiter manages rate limiting using the Token Bucket algorithm.
-type RateLimiter struct {
-	config    *RateLimitConfig
-	limiters  sync.Map // map[string]*limiterEntry
-	whitelist map[string]bool
-	mu        sync.RWMutex
-	stopClean chan struct{}
+	// Store decides whether a request is allowed. Defaults to an in-memory
+	// token bucket (per process); use NewRedisRateLimitStore to share limits
+	// across replicas.
+	Store RateLimitStore
+
+	// OnStoreError is called when the store fails. The request is allowed
+	// through (fail open). Defaults to logging a warning.
+	OnStoreError func(r *http.Request, key string, err error)
 }
 
-// limiterEntry contains a rate limiter and its last access time.
-type limiterEntry struct {
-	limiter  *rate.Limiter
-	lastSeen time.Time
+// RateLimiter manages rate limiting using a RateLimitStore
+// (token bucket in memory by default).
+type RateLimiter struct {
+	config      *RateLimitConfig
+	store       RateLimitStore
+	memoryStore *MemoryRateLimitStore // set when the limiter owns the default store
+	whitelist   map[string]bool
+	mu          sync.RWMutex
 }
 
 // NewRateLimiter creates a new rate limiter.
@@ -56,16 +63,19 @@ func NewRateLimiter(config *RateLimitConfig) *RateLimiter {
 
 	rl := &RateLimiter{
 		config:    config,
+		store:     config.Store,
 		whitelist: make(map[string]bool),
-		stopClean: make(chan struct{}),
+	}
+
+	if rl.store == nil {
+		rl.memoryStore = NewMemoryRateLimitStore(config.CleanupInterval)
+		rl.store = rl.memoryStore
 	}
 
 	for _, ip := range config.WhitelistIPs {
 		rl.whitelist[ip] = true
 	}
 
-	go rl.cleanupLoop()
-
 	return rl
 }
 
@@ -90,9 +100,14 @@ func (rl *RateLimiter) Middleware() Middleware {
 				return
 			}
 
-			limiter := rl.getLimiter(key)
+			result, err := rl.store.Allow(r.Context(), key, rl.config.RequestsPerMinute, rl.config.Burst)
+			if err != nil {
+				rl.handleStoreError(r, key, err)
+				next.ServeHTTP(w, r)
+				return
+			}
 
-			if !limiter.Allow() {
+			if !result.Allowed {
 				if rl.config.OnLimitExceeded != nil {
 					rl.config.OnLimitExceeded(r, key)
 				}
@@ -101,31 +116,20 @@ func (rl *RateLimiter) Middleware() Middleware {
 				return
 			}
 
-			rl.setRateLimitHeaders(w, limiter)
+			rl.setRateLimitHeaders(w, result.Remaining)
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
-// getLimiter retrieves or creates a limiter for a given key.
-func (rl *RateLimiter) getLimiter(key string) *rate.Limiter {
-	if entry, ok := rl.limiters.Load(key); ok {
-		if e, ok2 := entry.(*limiterEntry); ok2 {
-			e.lastSeen = time.Now()
-			return e.limiter
-		}
-	}
-
-	limit := rate.Limit(float64(rl.config.RequestsPerMinute) / 60.0)
-	limiter := rate.NewLimiter(limit, rl.config.Burst)
-
-	entry := &limiterEntry{
-		limiter:  limiter,
-		lastSeen: time.Now(),
+// handleStoreError reports a store failure; the request is let through.
+func (rl *RateLimiter) handleStoreError(r *http.Request, key string, err error) {
+	if rl.config.OnStoreError != nil {
+		rl.config.OnStoreError(r, key, err)
+		return
 	}
 
-	rl.limiters.Store(key, entry)
-	return limiter
+	logger.FromContext(r.Context()).Warn("rate limit store failed, allowing request", slog.String("key", key), logger.Err(err))
 }
 
 // isWhitelisted checks if a key or IP is in the whitelist.
@@ -139,7 +143,7 @@ func (rl *RateLimiter) isWhitelisted(key string, r *http.Request) bool {
 	}
 
 	// Extract the client's IP address
-	clientIP := getClientIPFromRequest(r)
+	clientIP := clientip.FromRequest(r)
 
 	// Check if the IP address is whitelisted
 	if rl.whitelist[clientIP] {
@@ -159,14 +163,13 @@ func (rl *RateLimiter) isWhitelisted(key string, r *http.Request) bool {
 }
 
 // setRateLimitHeaders adds informative rate limiting headers.
-func (rl *RateLimiter) setRateLimitHeaders(w http.ResponseWriter, limiter *rate.Limiter) {
+func (rl *RateLimiter) setRateLimitHeaders(w http.ResponseWriter, remaining int) {
 	w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", rl.config.RequestsPerMinute))
 
-	tokens := int(limiter.Tokens())
-	if tokens < 0 {
-		tokens = 0
+	if remaining < 0 {
+		remaining = 0
 	}
-	w.Header().Set("X-RateLimit-Remaining", fmt.Sprintf("%d", tokens))
+	w.Header().Set("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
 
 	resetTime := time.Now().Add(time.Minute).Unix()
 	w.Header().Set("X-RateLimit-Reset", fmt.Sprintf("%d", resetTime))
@@ -179,51 +182,22 @@ func (rl *RateLimiter) handleRateLimitExceeded(w http.ResponseWriter, r *http.Re
 	w.Header().Set("X-RateLimit-Remaining", "0")
 	w.Header().Set("X-RateLimit-Reset", fmt.Sprintf("%d", time.Now().Add(time.Minute).Unix()))
 	w.Header().Set("Retry-After", fmt.Sprintf("%d", retryAfter))
-	w.Header().Set("Content-Type", "application/json")
 
-	w.WriteHeader(http.StatusTooManyRequests)
-	_, _ = fmt.Fprintf(w, `{"error":"Too many requests. Retry in %d seconds."}`, retryAfter)
-}
-
-// cleanupLoop periodically cleans up inactive limiters.
-func (rl *RateLimiter) cleanupLoop() {
-	ticker := time.NewTicker(rl.config.CleanupInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			rl.cleanup()
-		case <-rl.stopClean:
-			return
-		}
-	}
+	err := apperrors.TooManyRequests(fmt.Sprintf("Too many requests. Retry in %d seconds.", retryAfter))
+	apperrors.Handle(w, r, err.WithField("retry_after", retryAfter))
 }
 
-// cleanup removes limiters inactive for more than 2x CleanupInterval.
-func (rl *RateLimiter) cleanup() {
-	threshold := time.Now().Add(-2 * rl.config.CleanupInterval)
-
-	rl.limiters.Range(func(key, value interface{}) bool {
-		entry, ok := value.(*limiterEntry)
-		if !ok {
-			return true
-		}
-		if entry.lastSeen.Before(threshold) {
-			rl.limiters.Delete(key)
-		}
-		return true
-	})
-}
-
-// Stop stops the cleanup loop.
+// Stop stops the cleanup loop of the default in-memory store.
+// Custom stores are left untouched.
 func (rl *RateLimiter) Stop() {
-	close(rl.stopClean)
+	if rl.memoryStore != nil {
+		rl.memoryStore.Stop()
+	}
 }
 
 // KeyByIP extracts the client IP.
 func KeyByIP(r *http.Request) string {
-	return getClientIPFromRequest(r)
+	return clientip.FromRequest(r)
 }
 
 // KeyByUser extracts the authenticated user ID.
@@ -246,26 +220,6 @@ func KeyByHeader(header string) KeyFunc {
 	}
 }
 
-// getClientIPFromRequest extracts the client IP considering proxies.
-func getClientIPFromRequest(r *http.Request) string {
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		ips := strings.Split(xff, ",")
-		if len(ips) > 0 {
-			return strings.TrimSpace(ips[0])
-		}
-	}
-
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return xri
-	}
-
-	ip, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		return r.RemoteAddr
-	}
-	return ip
-}
-
 // isIPInCIDR checks if an IP is in a CIDR range.
 func isIPInCIDR(ipStr, cidr string) bool {
 	ip := net.ParseIP(ipStr)
@@ -0,0 +1,287 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimitResult is the outcome of a RateLimitStore decision.
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration // only meaningful when Allowed is false
+}
+
+// RateLimitStore decides whether a request identified by key may proceed.
+// Implementations must be safe for concurrent use.
+//
+// MemoryRateLimitStore (default) keeps limits per process. Use
+// RedisRateLimitStore to share limits across replicas.
+type RateLimitStore interface {
+	Allow(ctx context.Context, key string, requestsPerMinute, burst int) (RateLimitResult, error)
+}
+
+// AllowPerWindow reports whether a request identified by key may proceed
+// under a limit of limit requests per window, e.g. 5 per hour, which a
+// per-minute rate cannot express. The memory and Redis stores enforce it
+// exactly; other stores are asked for the nearest per-minute rate.
+func AllowPerWindow(ctx context.Context, store RateLimitStore, key string, limit int, window time.Duration) (RateLimitResult, error) {
+	if limit <= 0 || window <= 0 {
+		return RateLimitResult{}, fmt.Errorf("ratelimit: limit and window must be positive")
+	}
+	if s, ok := store.(intervalStore); ok {
+		return s.allowEvery(ctx, key, window/time.Duration(limit), limit)
+	}
+	perMinute := int((int64(limit)*int64(time.Minute) + int64(window) - 1) / int64(window))
+	return store.Allow(ctx, key, max(perMinute, 1), limit)
+}
+
+// intervalStore is implemented by the stores that take the interval
+// between two requests rather than a per-minute rate.
+type intervalStore interface {
+	allowEvery(ctx context.Context, key string, interval time.Duration, burst int) (RateLimitResult, error)
+}
+
+// --- Memory store ---
+
+// MemoryRateLimitStore is an in-process token bucket store.
+type MemoryRateLimitStore struct {
+	limiters        sync.Map // map[string]*limiterEntry
+	cleanupInterval time.Duration
+	stopClean       chan struct{}
+	stopOnce        sync.Once
+}
+
+// limiterEntry contains a rate limiter and its last access time.
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewMemoryRateLimitStore creates an in-memory store. Inactive keys are
+// removed after 2x cleanupInterval (default 5 minutes).
+func NewMemoryRateLimitStore(cleanupInterval time.Duration) *MemoryRateLimitStore {
+	if cleanupInterval == 0 {
+		cleanupInterval = 5 * time.Minute
+	}
+
+	s := &MemoryRateLimitStore{
+		cleanupInterval: cleanupInterval,
+		stopClean:       make(chan struct{}),
+	}
+
+	go s.cleanupLoop()
+
+	return s
+}
+
+// Allow implements RateLimitStore.
+func (s *MemoryRateLimitStore) Allow(_ context.Context, key string, requestsPerMinute, burst int) (RateLimitResult, error) {
+	limiter := s.getLimiter(key, requestsPerMinute, burst)
+
+	if !limiter.Allow() {
+		return RateLimitResult{Allowed: false, Remaining: 0, RetryAfter: time.Minute}, nil
+	}
+
+	remaining := int(limiter.Tokens())
+	if remaining < 0 {
+		remaining = 0
+	}
+	return RateLimitResult{Allowed: true, Remaining: remaining}, nil
+}
+
+// allowEvery implements intervalStore.
+func (s *MemoryRateLimitStore) allowEvery(_ context.Context, key string, interval time.Duration, burst int) (RateLimitResult, error) {
+	limiter := s.getLimiterWithLimit(key, rate.Every(interval), burst)
+
+	reservation := limiter.Reserve()
+	if delay := reservation.Delay(); delay > 0 {
+		reservation.Cancel()
+		return RateLimitResult{Allowed: false, Remaining: 0, RetryAfter: delay}, nil
+	}
+	return RateLimitResult{Allowed: true, Remaining: max(int(limiter.Tokens()), 0)}, nil
+}
+
+// getLimiter retrieves or creates a limiter for a given key.
+func (s *MemoryRateLimitStore) getLimiter(key string, requestsPerMinute, burst int) *rate.Limiter {
+	return s.getLimiterWithLimit(key, rate.Limit(float64(requestsPerMinute)/60.0), burst)
+}
+
+// getLimiterWithLimit retrieves or creates a limiter for a given key.
+func (s *MemoryRateLimitStore) getLimiterWithLimit(key string, limit rate.Limit, burst int) *rate.Limiter {
+	if entry, ok := s.limiters.Load(key); ok {
+		if e, ok2 := entry.(*limiterEntry); ok2 {
+			e.lastSeen = time.Now()
+			return e.limiter
+		}
+	}
+
+	limiter := rate.NewLimiter(limit, burst)
+
+	entry := &limiterEntry{
+		limiter:  limiter,
+		lastSeen: time.Now(),
+	}
+
+	actual, _ := s.limiters.LoadOrStore(key, entry)
+	return actual.(*limiterEntry).limiter
+}
+
+// cleanupLoop periodically cleans up inactive limiters.
+func (s *MemoryRateLimitStore) cleanupLoop() {
+	ticker := time.NewTicker(s.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.cleanup()
+		case <-s.stopClean:
+			return
+		}
+	}
+}
+
+// cleanup removes limiters inactive for more than 2x cleanupInterval.
+func (s *MemoryRateLimitStore) cleanup() {
+	threshold := time.Now().Add(-2 * s.cleanupInterval)
+
+	s.limiters.Range(func(key, value interface{}) bool {
+		entry, ok := value.(*limiterEntry)
+		if !ok {
+			return true
+		}
+		if entry.lastSeen.Before(threshold) {
+			s.limiters.Delete(key)
+		}
+		return true
+	})
+}
+
+// Stop stops the cleanup loop. It is safe to call more than once.
+func (s *MemoryRateLimitStore) Stop() {
+	s.stopOnce.Do(func() { close(s.stopClean) })
+}
+
+// --- Redis store ---
+
+// RedisEvaler is the minimal Redis client surface used by RedisRateLimitStore.
+// Adapt your client of choice; with go-redis:
+//
+//	type goRedisEvaler struct{ c *redis.Client }
+//
+//	func (e goRedisEvaler) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+//		return e.c.Eval(ctx, script, keys, args...).Result()
+//	}
+type RedisEvaler interface {
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// gcraScript implements the Generic Cell Rate Algorithm atomically, in
+// microseconds so that intervals under a millisecond (rates above 60000
+// requests per minute) do not round to 0.
+// KEYS[1] = key, ARGV = emission interval (µs), burst, now (µs).
+// Returns {allowed, remaining, retry_after_µs}.
+const gcraScript = `
+local key = KEYS[1]
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local tolerance = interval * burst
+
+local tat = tonumber(redis.call("GET", key))
+if not tat or tat < now then
+  tat = now
+end
+
+local new_tat = tat + interval
+local allow_at = new_tat - tolerance
+
+if allow_at > now then
+  return {0, 0, allow_at - now}
+end
+
+redis.call("SET", key, string.format("%d", new_tat), "PX", math.max(math.ceil((new_tat - now) / 1000), 1))
+local remaining = math.floor((now - allow_at) / interval)
+return {1, remaining, 0}
+`
+
+// RedisRateLimitStore is a distributed store using the GCRA algorithm,
+// so limits are shared by every replica connected to the same Redis.
+type RedisRateLimitStore struct {
+	client RedisEvaler
+	prefix string
+	now    func() time.Time
+}
+
+// NewRedisRateLimitStore creates a Redis-backed store. Keys are namespaced
+// with prefix (default "ratelimit:").
+func NewRedisRateLimitStore(client RedisEvaler, prefix string) *RedisRateLimitStore {
+	if prefix == "" {
+		prefix = "ratelimit:"
+	}
+	return &RedisRateLimitStore{
+		client: client,
+		prefix: prefix,
+		now:    time.Now,
+	}
+}
+
+// Allow implements RateLimitStore.
+func (s *RedisRateLimitStore) Allow(ctx context.Context, key string, requestsPerMinute, burst int) (RateLimitResult, error) {
+	if requestsPerMinute <= 0 {
+		return RateLimitResult{}, fmt.Errorf("ratelimit: requestsPerMinute must be positive")
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+
+	return s.allowEvery(ctx, key, time.Minute/time.Duration(requestsPerMinute), burst)
+}
+
+// allowEvery implements intervalStore.
+func (s *RedisRateLimitStore) allowEvery(ctx context.Context, key string, interval time.Duration, burst int) (RateLimitResult, error) {
+	now := s.now().UnixMicro()
+
+	raw, err := s.client.Eval(ctx, gcraScript, []string{s.prefix + key}, max(interval.Microseconds(), 1), burst, now)
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("ratelimit: redis eval: %w", err)
+	}
+
+	values, ok := raw.([]any)
+	if !ok || len(values) != 3 {
+		return RateLimitResult{}, fmt.Errorf("ratelimit: unexpected redis reply %T", raw)
+	}
+
+	allowed, err1 := toInt64(values[0])
+	remaining, err2 := toInt64(values[1])
+	retryUs, err3 := toInt64(values[2])
+	if err1 != nil || err2 != nil || err3 != nil {
+		return RateLimitResult{}, fmt.Errorf("ratelimit: unexpected redis reply %v", values)
+	}
+
+	return RateLimitResult{
+		Allowed:    allowed == 1,
+		Remaining:  int(remaining),
+		RetryAfter: time.Duration(retryUs) * time.Microsecond,
+	}, nil
+}
+
+// toInt64 converts a Redis integer reply to int64.
+func toInt64(v any) (int64, error) {
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case int:
+		return int64(n), nil
+	case string:
+		return strconv.ParseInt(n, 10, 64)
+	default:
+		return 0, fmt.Errorf("not an integer: %T", v)
+	}
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -9,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/bozz33/sublimeadmin/clientip"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -351,7 +354,7 @@ func TestRateLimiter_Cleanup(t *testing.T) {
 	wrapped.ServeHTTP(rec, req)
 
 	count := 0
-	rl.limiters.Range(func(key, value interface{}) bool {
+	rl.memoryStore.limiters.Range(func(key, value interface{}) bool {
 		count++
 		return true
 	})
@@ -360,36 +363,176 @@ func TestRateLimiter_Cleanup(t *testing.T) {
 	time.Sleep(300 * time.Millisecond)
 
 	count = 0
-	rl.limiters.Range(func(key, value interface{}) bool {
+	rl.memoryStore.limiters.Range(func(key, value interface{}) bool {
 		count++
 		return true
 	})
 	assert.Equal(t, 0, count, "Cleanup should have removed inactive limiters")
 }
 
+// fakeRedis returns canned GCRA replies.
+type fakeRedis struct {
+	mu    sync.Mutex
+	reply any
+	err   error
+	keys  []string
+	args  []any
+}
+
+func (f *fakeRedis) Eval(_ context.Context, _ string, keys []string, args ...any) (any, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys = append(f.keys, keys...)
+	f.args = args
+	return f.reply, f.err
+}
+
+func TestRedisRateLimitStore_Allow(t *testing.T) {
+	redis := &fakeRedis{reply: []any{int64(1), int64(4), int64(0)}}
+	store := NewRedisRateLimitStore(redis, "")
+
+	result, err := store.Allow(context.Background(), "1.2.3.4", 60, 5)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 4, result.Remaining)
+	assert.Equal(t, []string{"ratelimit:1.2.3.4"}, redis.keys)
+
+	redis.reply = []any{int64(0), int64(0), int64(1_500_000)}
+	result, err = store.Allow(context.Background(), "1.2.3.4", 60, 5)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 1500*time.Millisecond, result.RetryAfter)
+
+	// Intervals under a millisecond keep their precision.
+	_, err = store.Allow(context.Background(), "1.2.3.4", 120_000, 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), redis.args[0])
+	_, err = store.Allow(context.Background(), "1.2.3.4", 120_000_000, 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), redis.args[0])
+
+	redis.reply = []any{"bad"}
+	_, err = store.Allow(context.Background(), "1.2.3.4", 60, 5)
+	assert.Error(t, err)
+}
+
+// perMinuteStore records the rate it is asked for.
+type perMinuteStore struct {
+	requestsPerMinute, burst int
+}
+
+func (s *perMinuteStore) Allow(_ context.Context, _ string, requestsPerMinute, burst int) (RateLimitResult, error) {
+	s.requestsPerMinute, s.burst = requestsPerMinute, burst
+	return RateLimitResult{Allowed: true}, nil
+}
+
+func TestAllowPerWindow(t *testing.T) {
+	ctx := context.Background()
+
+	memory := NewMemoryRateLimitStore(time.Minute)
+	defer memory.Stop()
+	for i := 0; i < 5; i++ {
+		result, err := AllowPerWindow(ctx, memory, "send-invoice:1", 5, time.Hour)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed, "call %d", i+1)
+	}
+	result, err := AllowPerWindow(ctx, memory, "send-invoice:1", 5, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.InDelta(t, 12*time.Minute, result.RetryAfter, float64(time.Second))
+
+	redis := &fakeRedis{reply: []any{int64(1), int64(4), int64(0)}}
+	_, err = AllowPerWindow(ctx, NewRedisRateLimitStore(redis, ""), "send-invoice:1", 5, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(12 * 60 * 1_000_000), 5, redis.args[2]}, redis.args)
+
+	// Other stores get the nearest per-minute rate
+	other := &perMinuteStore{}
+	_, err = AllowPerWindow(ctx, other, "k", 5, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, other.requestsPerMinute)
+	assert.Equal(t, 5, other.burst)
+	_, err = AllowPerWindow(ctx, other, "k", 10, 30*time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 20, other.requestsPerMinute)
+
+	_, err = AllowPerWindow(ctx, other, "k", 0, time.Hour)
+	assert.Error(t, err)
+}
+
+func TestRateLimiter_CustomStore(t *testing.T) {
+	redis := &fakeRedis{reply: []any{int64(0), int64(0), int64(1000)}}
+	rl := NewRateLimiter(&RateLimitConfig{
+		RequestsPerMinute: 60,
+		Burst:             5,
+		Store:             NewRedisRateLimitStore(redis, "test:"),
+	})
+	defer rl.Stop()
+
+	wrapped := rl.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	rec := httptest.NewRecorder()
+	wrapped.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "0", rec.Header().Get("X-RateLimit-Remaining"))
+	assert.Nil(t, rl.memoryStore)
+}
+
+func TestRateLimiter_StoreErrorFailsOpen(t *testing.T) {
+	var storeErr error
+	rl := NewRateLimiter(&RateLimitConfig{
+		RequestsPerMinute: 60,
+		Burst:             5,
+		Store:             NewRedisRateLimitStore(&fakeRedis{err: errors.New("connection refused")}, ""),
+		OnStoreError: func(r *http.Request, key string, err error) {
+			storeErr = err
+		},
+	})
+	defer rl.Stop()
+
+	wrapped := rl.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rec := httptest.NewRecorder()
+	wrapped.ServeHTTP(rec, httptest.NewRequest("GET", "/test", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.ErrorContains(t, storeErr, "connection refused")
+}
+
 func TestGetClientIP_XForwardedFor(t *testing.T) {
 	req := httptest.NewRequest("GET", "/test", nil)
 	req.Header.Set("X-Forwarded-For", "203.0.113.1, 198.51.100.1")
 	req.RemoteAddr = "192.168.1.1:1234"
+	assert.Equal(t, "192.168.1.1", KeyByIP(req), "untrusted peer")
 
-	ip := getClientIPFromRequest(req)
-	assert.Equal(t, "203.0.113.1", ip)
+	require.NoError(t, clientip.SetTrustedProxies("192.168.1.1"))
+	defer func() { _ = clientip.SetTrustedProxies() }()
+	assert.Equal(t, "198.51.100.1", KeyByIP(req), "last hop before the trusted proxy")
 }
 
 func TestGetClientIP_XRealIP(t *testing.T) {
 	req := httptest.NewRequest("GET", "/test", nil)
 	req.Header.Set("X-Real-IP", "203.0.113.1")
 	req.RemoteAddr = "192.168.1.1:1234"
+	assert.Equal(t, "192.168.1.1", KeyByIP(req), "untrusted peer")
 
-	ip := getClientIPFromRequest(req)
-	assert.Equal(t, "203.0.113.1", ip)
+	require.NoError(t, clientip.SetTrustedProxies("192.168.1.1"))
+	defer func() { _ = clientip.SetTrustedProxies() }()
+	assert.Equal(t, "203.0.113.1", KeyByIP(req))
 }
 
 func TestGetClientIP_RemoteAddr(t *testing.T) {
 	req := httptest.NewRequest("GET", "/test", nil)
 	req.RemoteAddr = "192.168.1.1:1234"
 
-	ip := getClientIPFromRequest(req)
+	ip := KeyByIP(req)
 	assert.Equal(t, "192.168.1.1", ip)
 }
 
@@ -27,7 +27,9 @@ func DefaultRecoveryConfig(errorHandler *apperrors.Handler) *RecoveryConfig {
 	}
 }
 
-// Recovery returns a middleware that captures panics.
+// Recovery returns a middleware that captures panics. Panics are reported,
+// with their stack, request and user, to the reporter configured with
+// apperrors.SetReporter. Place it after the auth middleware so the user is known.
 func Recovery(errorHandler *apperrors.Handler) Middleware {
 	return RecoveryWithConfig(DefaultRecoveryConfig(errorHandler))
 }
@@ -63,9 +65,8 @@ func RecoveryWithConfig(config *RecoveryConfig) Middleware {
 						config.OnPanic(r, rec)
 					}
 
-					// Create AppError
-					err := apperrors.Internal(nil, "An error occurred")
-					_ = err.WithField("panic", fmt.Sprint(rec))
+					// Create AppError and send it to the configured reporter
+					err := apperrors.Recovered(rec, r)
 
 					if config.PrintStack {
 						err.Stack = string(stack)
@@ -75,8 +76,7 @@ func RecoveryWithConfig(config *RecoveryConfig) Middleware {
 					if config.ErrorHandler != nil {
 						config.ErrorHandler.Handle(w, r, err)
 					} else {
-						// Simple fallback
-						http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+						apperrors.Handle(w, r, err)
 					}
 				}
 			}()
@@ -95,7 +95,7 @@ func logPanic(log *logger.Logger, r *http.Request, rec any, stack []byte, printS
 		"remote_addr", r.RemoteAddr,
 	}
 
-	if requestID := RequestID(r.Context()); requestID != "" {
+	if requestID := RequestIDFromContext(r.Context()); requestID != "" {
 		attrs = append(attrs, "request_id", requestID)
 	}
 
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bozz33/sublimeadmin/logger"
+	"github.com/google/uuid"
+)
+
+// correlationIDKey is the context key of the correlation ID.
+type correlationIDKey struct{}
+
+// RequestIDConfig configures the request ID middleware.
+type RequestIDConfig struct {
+	// Header carries the request ID in and out. Default: X-Request-ID.
+	Header string
+	// CorrelationHeader carries the ID shared by every request of a
+	// distributed operation. Default: X-Correlation-ID.
+	CorrelationHeader string
+	// TrustIncoming accepts IDs sent by the client or an upstream proxy.
+	// Disable on servers directly exposed to untrusted clients.
+	TrustIncoming bool
+	// Generator creates new IDs. Default: UUID v4.
+	Generator func() string
+}
+
+// DefaultRequestIDConfig returns a default configuration.
+func DefaultRequestIDConfig() *RequestIDConfig {
+	return &RequestIDConfig{
+		Header:            "X-Request-ID",
+		CorrelationHeader: "X-Correlation-ID",
+		TrustIncoming:     true,
+		Generator:         func() string { return uuid.New().String() },
+	}
+}
+
+// RequestID returns a middleware assigning an ID to every request. The ID
+// is accepted from the X-Request-ID header or generated, stored in the
+// context (RequestIDFromContext, logger.RequestIDFromContext), added to the
+// context logger as "request_id", shown on error pages and echoed in the
+// response header. A correlation ID is propagated the same way and
+// defaults to the request ID.
+//
+//	stack := middleware.NewStack(
+//		middleware.RequestID(),
+//		middleware.Logger(log),
+//		middleware.Recovery(errorHandler),
+//	)
+func RequestID() Middleware {
+	return RequestIDWithConfig(DefaultRequestIDConfig())
+}
+
+// RequestIDWithConfig returns a request ID middleware with custom config.
+func RequestIDWithConfig(config *RequestIDConfig) Middleware {
+	if config == nil {
+		config = DefaultRequestIDConfig()
+	}
+
+	if config.Header == "" {
+		config.Header = "X-Request-ID"
+	}
+	if config.Generator == nil {
+		config.Generator = func() string { return uuid.New().String() }
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var requestID, correlationID string
+			if config.TrustIncoming {
+				requestID = sanitizeID(r.Header.Get(config.Header))
+				if config.CorrelationHeader != "" {
+					correlationID = sanitizeID(r.Header.Get(config.CorrelationHeader))
+				}
+			}
+			if requestID == "" {
+				requestID = config.Generator()
+			}
+			if correlationID == "" {
+				correlationID = requestID
+			}
+
+			w.Header().Set(config.Header, requestID)
+			if config.CorrelationHeader != "" {
+				w.Header().Set(config.CorrelationHeader, correlationID)
+			}
+
+			ctx := withRequestID(r.Context(), requestID)
+			ctx = context.WithValue(ctx, correlationIDKey{}, correlationID)
+
+			attrs := []any{"request_id", requestID}
+			if correlationID != requestID {
+				attrs = append(attrs, "correlation_id", correlationID)
+			}
+			ctx = logger.WithContext(ctx, logger.FromContext(ctx).With(attrs...))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// CorrelationIDFromContext retrieves the correlation ID from the context.
+func CorrelationIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(correlationIDKey{}).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// RequestIDTransport returns an http.RoundTripper forwarding the request
+// and correlation IDs of the request context to outgoing calls, so logs
+// of downstream services can be joined. base defaults to http.DefaultTransport.
+//
+//	client := &http.Client{Transport: middleware.RequestIDTransport(nil)}
+//	req, _ := http.NewRequestWithContext(r.Context(), "GET", url, nil)
+func RequestIDTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		requestID := RequestIDFromContext(req.Context())
+		correlationID := CorrelationIDFromContext(req.Context())
+		if requestID == "" && correlationID == "" {
+			return base.RoundTrip(req)
+		}
+
+		req = req.Clone(req.Context())
+		if requestID != "" && req.Header.Get("X-Request-ID") == "" {
+			req.Header.Set("X-Request-ID", requestID)
+		}
+		if correlationID != "" && req.Header.Get("X-Correlation-ID") == "" {
+			req.Header.Set("X-Correlation-ID", correlationID)
+		}
+		return base.RoundTrip(req)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// sanitizeID rejects incoming IDs that are too long or contain characters
+// unsafe for logs and headers.
+func sanitizeID(id string) string {
+	if len(id) == 0 || len(id) > 128 {
+		return ""
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == ':') {
+			return ""
+		}
+	}
+	return id
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/bozz33/sublimeadmin/clientip"
+	"github.com/bozz33/sublimeadmin/tracing"
+	"github.com/samber/lo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingConfig configures the tracing middleware.
+type TracingConfig struct {
+	// TracerProvider creates the request spans. Defaults to otel.GetTracerProvider().
+	TracerProvider trace.TracerProvider
+	// Propagator extracts the incoming trace context (traceparent, baggage).
+	// Defaults to otel.GetTextMapPropagator().
+	Propagator propagation.TextMapPropagator
+	// RouteFunc returns the route attribute for a served request.
+	// Defaults to RoutePattern.
+	RouteFunc func(r *http.Request) string
+	SkipPaths []string
+}
+
+// DefaultTracingConfig returns a default configuration.
+func DefaultTracingConfig() *TracingConfig {
+	return &TracingConfig{
+		RouteFunc: RoutePattern,
+		SkipPaths: []string{"/metrics", "/favicon.ico"},
+	}
+}
+
+// Tracing returns a middleware starting an OpenTelemetry server span per
+// request. The span continues the trace received from upstream services and
+// is named after the matched route ("GET /users/{id}"). Its context is passed
+// down so CRUD handlers, DB calls, search and dispatched jobs become children:
+//
+//	handler := middleware.Tracing(tracerProvider)(mux)
+func Tracing(tp trace.TracerProvider) Middleware {
+	config := DefaultTracingConfig()
+	config.TracerProvider = tp
+	return TracingWithConfig(config)
+}
+
+// TracingWithConfig returns a tracing middleware with custom config.
+func TracingWithConfig(config *TracingConfig) Middleware {
+	if config == nil {
+		config = DefaultTracingConfig()
+	}
+
+	if config.TracerProvider == nil {
+		config.TracerProvider = otel.GetTracerProvider()
+	}
+	if config.Propagator == nil {
+		config.Propagator = otel.GetTextMapPropagator()
+	}
+	if config.RouteFunc == nil {
+		config.RouteFunc = RoutePattern
+	}
+
+	tracer := config.TracerProvider.Tracer(tracing.TracerName)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if lo.Contains(config.SkipPaths, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := config.Propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, "HTTP "+r.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("url.path", r.URL.Path),
+					attribute.String("client.address", clientip.FromRequest(r)),
+				),
+			)
+			defer span.End()
+
+			// Keep the request copy: ServeMux records the matched pattern on it.
+			traced := r.WithContext(ctx)
+			rw := NewResponseWriter(w)
+
+			next.ServeHTTP(rw, traced)
+
+			route := config.RouteFunc(traced)
+			status := rw.Status()
+
+			if route != "unmatched" {
+				span.SetName(spanName(r.Method, route))
+			}
+			span.SetAttributes(
+				attribute.String("http.route", route),
+				attribute.Int("http.response.status_code", status),
+			)
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+		})
+	}
+}
+
+// spanName builds "METHOD route", without repeating a method already
+// present in the ServeMux pattern ("GET /users/{id}").
+func spanName(method, route string) string {
+	if strings.HasPrefix(route, method+" ") {
+		return route
+	}
+	return method + " " + route
+}
@@ -0,0 +1,31 @@
+// Package migrate runs versioned SQL migrations and database seeders.
+//
+// Migrations are <version>_<name>.sql files, generated by
+// sublimego make:migration, with an Up and a Down section. Applied
+// versions are recorded in the schema_migrations table, so each migration
+// runs once; Down rolls back the newest ones.
+//
+// Features:
+//   - Ordered, transactional migrations with rollback
+//   - schema_migrations bookkeeping and status report
+//   - Dry-run mode printing the statements instead of running them
+//   - SQL seeders (seeders/*.sql) and Go seeders (RegisterSeeder)
+//   - SQLite, PostgreSQL and MySQL placeholders
+//
+// Basic usage:
+//
+//	m := migrate.NewWithConfig(db, &migrate.Config{
+//		Dir:     "migrations",
+//		Dialect: "postgres",
+//	})
+//	applied, err := m.Up(ctx, 0)    // all pending migrations
+//	reverted, err := m.Down(ctx, 1) // roll back the last one
+//	ran, err := m.Seed(ctx)         // every seeder
+//
+// From the CLI:
+//
+//	sublimego migrate --dry-run
+//	sublimego migrate:rollback --steps 2
+//	sublimego migrate:status
+//	sublimego db:seed
+package migrate
@@ -0,0 +1,223 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func newTestMigrator(t *testing.T) (*Migrator, *sql.DB, string) {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "app.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "migrations"), "1700000000_create_products.sql", `-- Migration: create_products
+
+-- +migrate Up
+CREATE TABLE products (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL
+);
+
+-- +migrate Down
+DROP TABLE products;
+`)
+	writeFile(t, filepath.Join(dir, "migrations"), "1700000100_add_price.sql", `-- +migrate Up
+ALTER TABLE products ADD COLUMN price REAL NOT NULL DEFAULT 0;
+-- +migrate StatementBegin
+CREATE TRIGGER products_price AFTER INSERT ON products
+BEGIN
+	UPDATE products SET price = 1 WHERE id = NEW.id AND price = 0;
+END;
+-- +migrate StatementEnd
+
+-- +migrate Down
+DROP TRIGGER products_price;
+ALTER TABLE products DROP COLUMN price;
+`)
+
+	m := NewWithConfig(db, &Config{
+		Dir:     filepath.Join(dir, "migrations"),
+		SeedDir: filepath.Join(dir, "seeders"),
+	})
+	return m, db, dir
+}
+
+func TestLoad(t *testing.T) {
+	_, _, dir := newTestMigrator(t)
+
+	migrations, err := Load(filepath.Join(dir, "migrations"))
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+
+	assert.Equal(t, "1700000000", migrations[0].Version)
+	assert.Equal(t, "create_products", migrations[0].Name)
+	assert.Len(t, migrations[0].Up, 1)
+	assert.Len(t, migrations[0].Down, 1)
+
+	// The trigger block stays one statement.
+	assert.Len(t, migrations[1].Up, 2)
+	assert.Contains(t, migrations[1].Up[1], "END;")
+	assert.Len(t, migrations[1].Down, 2)
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "nounderscore.sql", "SELECT 1;")
+	_, err := Load(dir)
+	assert.Error(t, err)
+
+	dir = t.TempDir()
+	writeFile(t, dir, "1_a.sql", "SELECT 1;")
+	writeFile(t, dir, "1_b.sql", "SELECT 1;")
+	_, err = Load(dir)
+	assert.ErrorContains(t, err, "duplicate version")
+
+	dir = t.TempDir()
+	writeFile(t, dir, "1_a.sql", "-- +migrate StatementBegin\nSELECT 1;\n")
+	_, err = Load(dir)
+	assert.ErrorContains(t, err, "without StatementEnd")
+}
+
+func TestVersionOrder(t *testing.T) {
+	assert.True(t, versionLess("9", "10"))
+	assert.True(t, versionLess("1700000000", "1700000001"))
+	assert.True(t, versionLess("a", "b"))
+}
+
+func TestUpDownStatus(t *testing.T) {
+	m, db, _ := newTestMigrator(t)
+	ctx := context.Background()
+
+	applied, err := m.Up(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, "create_products", applied[0].Name)
+
+	applied, err = m.Up(ctx, 0)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+
+	_, err = db.Exec("INSERT INTO products (name) VALUES ('Mug')")
+	require.NoError(t, err)
+	var price float64
+	require.NoError(t, db.QueryRow("SELECT price FROM products").Scan(&price))
+	assert.Equal(t, 1.0, price)
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	for _, s := range statuses {
+		assert.True(t, s.Applied)
+		assert.False(t, s.AppliedAt.IsZero())
+	}
+
+	applied, err = m.Up(ctx, 0)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	reverted, err := m.Down(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, reverted, 2)
+	assert.Equal(t, "add_price", reverted[0].Name)
+
+	_, err = db.Exec("SELECT 1 FROM products")
+	assert.Error(t, err, "products should be dropped")
+
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count))
+	assert.Zero(t, count)
+}
+
+func TestDownWithoutDownSection(t *testing.T) {
+	m, _, dir := newTestMigrator(t)
+	writeFile(t, filepath.Join(dir, "migrations"), "1700000200_index.sql", "CREATE INDEX products_name ON products (name);\n")
+	ctx := context.Background()
+
+	_, err := m.Up(ctx, 0)
+	require.NoError(t, err)
+
+	_, err = m.Down(ctx, 1)
+	assert.ErrorContains(t, err, "no Down section")
+}
+
+func TestMissingMigrationFile(t *testing.T) {
+	m, _, dir := newTestMigrator(t)
+	ctx := context.Background()
+	_, err := m.Up(ctx, 0)
+	require.NoError(t, err)
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "migrations", "1700000100_add_price.sql")))
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[1].Missing)
+
+	_, err = m.Down(ctx, 1)
+	assert.ErrorContains(t, err, "migration file not found")
+}
+
+func TestDryRun(t *testing.T) {
+	m, db, _ := newTestMigrator(t)
+	var out bytes.Buffer
+	m.cfg.DryRun = true
+	m.cfg.Out = &out
+
+	applied, err := m.Up(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Len(t, applied, 2)
+	assert.Contains(t, out.String(), "CREATE TABLE products")
+
+	var name string
+	err = db.QueryRow("SELECT name FROM sqlite_master WHERE name = 'schema_migrations'").Scan(&name)
+	assert.ErrorIs(t, err, sql.ErrNoRows, "dry run should not touch the database")
+}
+
+func TestSeed(t *testing.T) {
+	m, db, dir := newTestMigrator(t)
+	ctx := context.Background()
+	_, err := m.Up(ctx, 0)
+	require.NoError(t, err)
+
+	writeFile(t, filepath.Join(dir, "seeders"), "01_products.sql", "INSERT INTO products (name, price) VALUES ('Mug', 5);\nINSERT INTO products (name, price) VALUES ('Cap', 9);\n")
+	writeFile(t, filepath.Join(dir, "seeders"), "02_broken.sql", "INSERT INTO nope VALUES (1);\n")
+
+	RegisterSeeder("test_go_seeder", func(ctx context.Context, db *sql.DB) error {
+		_, err := db.ExecContext(ctx, "INSERT INTO products (name, price) VALUES ('Pen', 2)")
+		return err
+	})
+	defer func() {
+		seedersMu.Lock()
+		delete(seeders, "test_go_seeder")
+		seedersMu.Unlock()
+	}()
+
+	ran, err := m.Seed(ctx, "01_products", "test_go_seeder")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"01_products", "test_go_seeder"}, ran)
+
+	var count int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM products").Scan(&count))
+	assert.Equal(t, 3, count)
+
+	_, err = m.Seed(ctx, "02_broken")
+	assert.Error(t, err)
+
+	_, err = m.Seed(ctx, "unknown")
+	assert.ErrorContains(t, err, "not found")
+}
@@ -0,0 +1,172 @@
+package migrate
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Section markers of a migration file.
+const (
+	markerUp             = "-- +migrate Up"
+	markerDown           = "-- +migrate Down"
+	markerStatementBegin = "-- +migrate StatementBegin"
+	markerStatementEnd   = "-- +migrate StatementEnd"
+)
+
+// Migration is a versioned SQL migration read from a
+// <version>_<name>.sql file.
+type Migration struct {
+	Version string
+	Name    string
+	Path    string
+	Up      []string // statements applied by Up
+	Down    []string // statements reverted by Down
+}
+
+// HasDown reports whether the migration can be rolled back.
+func (m Migration) HasDown() bool {
+	return len(m.Down) > 0
+}
+
+// Load reads the migrations of dir, sorted by version. A file holds an
+// Up and a Down section:
+//
+//	-- +migrate Up
+//	CREATE TABLE products (id INTEGER PRIMARY KEY, name TEXT NOT NULL);
+//
+//	-- +migrate Down
+//	DROP TABLE products;
+//
+// A file without markers is all Up. Statements end with a semicolon at
+// the end of a line; wrap statements holding semicolons (triggers,
+// functions) in StatementBegin/StatementEnd markers.
+func Load(dir string) ([]Migration, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, fmt.Errorf("migrate: list migrations: %w", err)
+	}
+
+	seen := make(map[string]string)
+	migrations := make([]Migration, 0, len(paths))
+	for _, path := range paths {
+		version, name, ok := strings.Cut(strings.TrimSuffix(filepath.Base(path), ".sql"), "_")
+		if !ok || version == "" {
+			return nil, fmt.Errorf("migrate: invalid migration file name %s (expected <version>_<name>.sql)", path)
+		}
+		if other, dup := seen[version]; dup {
+			return nil, fmt.Errorf("migrate: duplicate version %s in %s and %s", version, other, path)
+		}
+		seen[version] = path
+
+		up, down, err := parseFile(path, true)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    name,
+			Path:    path,
+			Up:      up,
+			Down:    down,
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return versionLess(migrations[i].Version, migrations[j].Version)
+	})
+	return migrations, nil
+}
+
+// versionLess orders numeric versions by value and others as strings.
+func versionLess(a, b string) bool {
+	if isDigits(a) && isDigits(b) && len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// parseFile splits a SQL file into statements. With sections, the Up and
+// Down markers select where statements go; otherwise everything is up.
+func parseFile(path string, sections bool) (up, down []string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("migrate: open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var (
+		target   = &up
+		current  strings.Builder
+		inBlock  bool
+		lineNo   int
+		hasLines bool
+	)
+	flush := func() {
+		if stmt := strings.TrimSpace(current.String()); stmt != "" {
+			*target = append(*target, stmt)
+		}
+		current.Reset()
+		hasLines = false
+	}
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for sc.Scan() {
+		lineNo++
+		line := sc.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case sections && strings.HasPrefix(trimmed, markerUp):
+			flush()
+			target = &up
+			continue
+		case sections && strings.HasPrefix(trimmed, markerDown):
+			flush()
+			target = &down
+			continue
+		case strings.HasPrefix(trimmed, markerStatementBegin):
+			flush()
+			inBlock = true
+			continue
+		case strings.HasPrefix(trimmed, markerStatementEnd):
+			if !inBlock {
+				return nil, nil, fmt.Errorf("migrate: %s:%d: StatementEnd without StatementBegin", path, lineNo)
+			}
+			inBlock = false
+			flush()
+			continue
+		case !inBlock && !hasLines && (trimmed == "" || strings.HasPrefix(trimmed, "--")):
+			// Comments between statements.
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteByte('\n')
+		hasLines = true
+		if !inBlock && strings.HasSuffix(trimmed, ";") {
+			flush()
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, fmt.Errorf("migrate: read %s: %w", path, err)
+	}
+	if inBlock {
+		return nil, nil, fmt.Errorf("migrate: %s: StatementBegin without StatementEnd", path)
+	}
+	flush()
+	return up, down, nil
+}
@@ -0,0 +1,331 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// Config configures a Migrator.
+type Config struct {
+	// Dir holds the <version>_<name>.sql migrations.
+	Dir string
+	// SeedDir holds the .sql seeders run by Seed.
+	SeedDir string
+	// Table records the applied migrations.
+	Table string
+	// Dialect is the config database driver: sqlite, sqlite3, postgres or
+	// mysql. It selects the placeholder style.
+	Dialect string
+	// DryRun prints the statements to Out instead of executing them.
+	DryRun bool
+	// Out receives the progress and dry-run output.
+	Out io.Writer
+}
+
+// DefaultConfig returns the default migrator configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		Dir:     "migrations",
+		SeedDir: "seeders",
+		Table:   "schema_migrations",
+		Dialect: "sqlite",
+		Out:     os.Stdout,
+	}
+}
+
+// Migrator applies and rolls back migrations, recording them in the
+// schema_migrations table.
+type Migrator struct {
+	db  *sql.DB
+	cfg *Config
+}
+
+// Status is the state of a migration.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+	// Missing is set for an applied migration whose file is gone.
+	Missing bool
+}
+
+// New creates a Migrator with the default configuration.
+func New(db *sql.DB) *Migrator {
+	return NewWithConfig(db, DefaultConfig())
+}
+
+// NewWithConfig creates a Migrator with a custom configuration.
+func NewWithConfig(db *sql.DB, cfg *Config) *Migrator {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	defaults := DefaultConfig()
+	if cfg.Dir == "" {
+		cfg.Dir = defaults.Dir
+	}
+	if cfg.SeedDir == "" {
+		cfg.SeedDir = defaults.SeedDir
+	}
+	if cfg.Table == "" {
+		cfg.Table = defaults.Table
+	}
+	if cfg.Dialect == "" {
+		cfg.Dialect = defaults.Dialect
+	}
+	if cfg.Out == nil {
+		cfg.Out = io.Discard
+	}
+	return &Migrator{db: db, cfg: cfg}
+}
+
+// Status returns every migration, applied or pending, in version order,
+// followed by the applied migrations whose file is missing.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	migrations, err := Load(m.cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	known := make(map[string]bool, len(migrations))
+	for _, mig := range migrations {
+		known[mig.Version] = true
+		s := Status{Migration: mig}
+		if rec, ok := applied[mig.Version]; ok {
+			s.Applied, s.AppliedAt = true, rec.at
+		}
+		statuses = append(statuses, s)
+	}
+	for _, rec := range sortedRecords(applied) {
+		if !known[rec.version] {
+			statuses = append(statuses, Status{
+				Migration: Migration{Version: rec.version, Name: rec.name},
+				Applied:   true,
+				AppliedAt: rec.at,
+				Missing:   true,
+			})
+		}
+	}
+	return statuses, nil
+}
+
+// Up applies the pending migrations in version order, each in its own
+// transaction; steps limits how many (0 applies them all). It returns the
+// applied migrations.
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, s := range statuses {
+		if s.Applied {
+			continue
+		}
+		if steps > 0 && len(done) == steps {
+			break
+		}
+		if err := m.run(ctx, s.Migration, s.Up, true); err != nil {
+			return done, err
+		}
+		done = append(done, s.Migration)
+	}
+	if len(done) == 0 {
+		fmt.Fprintln(m.cfg.Out, "Nothing to migrate")
+	}
+	return done, nil
+}
+
+// Down rolls back the last applied migrations, newest first; steps
+// defaults to 1. It returns the rolled back migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Status
+	for _, s := range statuses {
+		if s.Applied {
+			applied = append(applied, s)
+		}
+	}
+
+	var done []Migration
+	for i := len(applied) - 1; i >= 0 && len(done) < steps; i-- {
+		s := applied[i]
+		if s.Missing {
+			return done, fmt.Errorf("migrate: cannot roll back %s_%s: migration file not found", s.Version, s.Name)
+		}
+		if !s.HasDown() {
+			return done, fmt.Errorf("migrate: cannot roll back %s: no Down section", s.Path)
+		}
+		if err := m.run(ctx, s.Migration, s.Down, false); err != nil {
+			return done, err
+		}
+		done = append(done, s.Migration)
+	}
+	if len(done) == 0 {
+		fmt.Fprintln(m.cfg.Out, "Nothing to roll back")
+	}
+	return done, nil
+}
+
+// run executes the statements of a migration and records it.
+func (m *Migrator) run(ctx context.Context, mig Migration, statements []string, up bool) error {
+	direction := "Migrating"
+	if !up {
+		direction = "Rolling back"
+	}
+	fmt.Fprintf(m.cfg.Out, "%s: %s_%s\n", direction, mig.Version, mig.Name)
+
+	if m.cfg.DryRun {
+		for _, stmt := range statements {
+			fmt.Fprintf(m.cfg.Out, "%s\n", stmt)
+		}
+		return nil
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migrate: begin %s: %w", mig.Version, err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("migrate: %s_%s: %w", mig.Version, mig.Name, err)
+		}
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx,
+			fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES (%s, %s, %s)",
+				m.cfg.Table, m.placeholder(1), m.placeholder(2), m.placeholder(3)),
+			mig.Version, mig.Name, time.Now().UTC())
+	} else {
+		_, err = tx.ExecContext(ctx,
+			fmt.Sprintf("DELETE FROM %s WHERE version = %s", m.cfg.Table, m.placeholder(1)),
+			mig.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("migrate: record %s: %w", mig.Version, err)
+	}
+	return tx.Commit()
+}
+
+// record is a row of the migrations table.
+type record struct {
+	version string
+	name    string
+	at      time.Time
+}
+
+// applied returns the applied migrations by version, creating the
+// migrations table if needed.
+func (m *Migrator) applied(ctx context.Context) (map[string]record, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, fmt.Sprintf("SELECT version, name, applied_at FROM %s", m.cfg.Table))
+	if err != nil {
+		if m.cfg.DryRun {
+			// The table is not created in dry-run mode.
+			return map[string]record{}, nil
+		}
+		return nil, fmt.Errorf("migrate: read %s: %w", m.cfg.Table, err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]record)
+	for rows.Next() {
+		var (
+			rec record
+			at  any
+		)
+		if err := rows.Scan(&rec.version, &rec.name, &at); err != nil {
+			return nil, fmt.Errorf("migrate: read %s: %w", m.cfg.Table, err)
+		}
+		rec.at = parseTime(at)
+		applied[rec.version] = rec
+	}
+	return applied, rows.Err()
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	if m.cfg.DryRun {
+		return nil
+	}
+	_, err := m.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		version    VARCHAR(255) NOT NULL PRIMARY KEY,
+		name       VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`, m.cfg.Table))
+	if err != nil {
+		return fmt.Errorf("migrate: create %s: %w", m.cfg.Table, err)
+	}
+	return nil
+}
+
+// placeholder returns the n-th bind parameter of the dialect.
+func (m *Migrator) placeholder(n int) string {
+	switch m.cfg.Dialect {
+	case "postgres", "pgx":
+		return fmt.Sprintf("$%d", n)
+	default:
+		return "?"
+	}
+}
+
+func sortedRecords(applied map[string]record) []record {
+	out := make([]record, 0, len(applied))
+	for _, rec := range applied {
+		out = append(out, rec)
+	}
+	sort.Slice(out, func(i, j int) bool { return versionLess(out[i].version, out[j].version) })
+	return out
+}
+
+// timeLayouts are the formats drivers return timestamps in when they do
+// not parse them (MySQL without parseTime, SQLite text columns).
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05",
+}
+
+// parseTime converts a scanned timestamp to a time.Time.
+func parseTime(v any) time.Time {
+	var s string
+	switch t := v.(type) {
+	case time.Time:
+		return t
+	case []byte:
+		s = string(t)
+	case string:
+		s = t
+	default:
+		return time.Time{}
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
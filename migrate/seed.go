@@ -0,0 +1,131 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SeederFunc seeds the database from Go code.
+type SeederFunc func(ctx context.Context, db *sql.DB) error
+
+var (
+	seedersMu sync.RWMutex
+	seeders   = make(map[string]SeederFunc)
+)
+
+// RegisterSeeder registers a Go seeder, usually from the init function of
+// a file generated by sublimego make:seeder --go. Go seeders run after the
+// SQL seeders when the application calls Seed.
+func RegisterSeeder(name string, fn SeederFunc) {
+	seedersMu.Lock()
+	defer seedersMu.Unlock()
+	seeders[name] = fn
+}
+
+// Seeders returns the names of the registered Go seeders, sorted.
+func Seeders() []string {
+	seedersMu.RLock()
+	defer seedersMu.RUnlock()
+	names := make([]string, 0, len(seeders))
+	for name := range seeders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Seed runs the .sql seeders of SeedDir in file name order, then the
+// registered Go seeders. With names, only the seeders whose name (file
+// name without .sql) is listed run. Each SQL seeder runs in a transaction.
+// It returns the names of the seeders that ran.
+func (m *Migrator) Seed(ctx context.Context, names ...string) ([]string, error) {
+	only := make(map[string]bool, len(names))
+	for _, name := range names {
+		only[name] = true
+	}
+	selected := func(name string) bool { return len(only) == 0 || only[name] }
+
+	paths, err := filepath.Glob(filepath.Join(m.cfg.SeedDir, "*.sql"))
+	if err != nil {
+		return nil, fmt.Errorf("migrate: list seeders: %w", err)
+	}
+	sort.Strings(paths)
+
+	var ran []string
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".sql")
+		if !selected(name) {
+			continue
+		}
+		statements, _, err := parseFile(path, false)
+		if err != nil {
+			return ran, err
+		}
+		if err := m.seedSQL(ctx, name, statements); err != nil {
+			return ran, err
+		}
+		ran = append(ran, name)
+	}
+
+	for _, name := range Seeders() {
+		if !selected(name) {
+			continue
+		}
+		fmt.Fprintf(m.cfg.Out, "Seeding: %s\n", name)
+		if m.cfg.DryRun {
+			ran = append(ran, name)
+			continue
+		}
+		seedersMu.RLock()
+		fn := seeders[name]
+		seedersMu.RUnlock()
+		if err := fn(ctx, m.db); err != nil {
+			return ran, fmt.Errorf("migrate: seeder %s: %w", name, err)
+		}
+		ran = append(ran, name)
+	}
+
+	for name := range only {
+		if !contains(ran, name) {
+			return ran, fmt.Errorf("migrate: seeder %s not found", name)
+		}
+	}
+	return ran, nil
+}
+
+func (m *Migrator) seedSQL(ctx context.Context, name string, statements []string) error {
+	fmt.Fprintf(m.cfg.Out, "Seeding: %s\n", name)
+	if m.cfg.DryRun {
+		for _, stmt := range statements {
+			fmt.Fprintf(m.cfg.Out, "%s\n", stmt)
+		}
+		return nil
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migrate: begin seeder %s: %w", name, err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("migrate: seeder %s: %w", name, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
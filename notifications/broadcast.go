@@ -23,6 +23,7 @@ type Broadcaster struct {
 	mu      sync.RWMutex
 	clients map[string]map[*client]struct{} // userID -> set of clients
 	logger  *slog.Logger
+	streams streams
 }
 
 // client represents a single connected SSE client.
@@ -122,6 +123,12 @@ func (b *Broadcaster) ConnectedClients() int {
 	return total
 }
 
+// CloseStreams ends the SSE streams served by b open at the time of the
+// call (see Handler.CloseStreams).
+func (b *Broadcaster) CloseStreams() {
+	b.streams.close()
+}
+
 // ServeSSE is an http.HandlerFunc that streams notifications via SSE.
 // It expects userIDFunc to extract the authenticated user ID from the request.
 func (b *Broadcaster) ServeSSE(userIDFunc func(r *http.Request) string) http.HandlerFunc {
@@ -144,6 +151,7 @@ func (b *Broadcaster) ServeSSE(userIDFunc func(r *http.Request) string) http.Han
 		w.Header().Set("X-Accel-Buffering", "no")
 
 		ch := b.Subscribe(r.Context(), userID)
+		closed := b.streams.done()
 
 		// Send initial heartbeat so the client knows the connection is alive.
 		writeSSEEvent(w, "connected", map[string]any{
@@ -159,6 +167,8 @@ func (b *Broadcaster) ServeSSE(userIDFunc func(r *http.Request) string) http.Han
 			select {
 			case <-r.Context().Done():
 				return
+			case <-closed:
+				return
 			case n, ok := <-ch:
 				if !ok {
 					return
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	ds "github.com/bozz33/sublimeadmin/datastar"
@@ -36,6 +37,7 @@ type Handler struct {
 	store      NotificationStore
 	userIDFunc func(r *http.Request) string
 	prefix     string // registered route prefix, e.g. "/api/notifications"
+	streams    streams
 }
 
 // NewHandler creates a notification HTTP handler.
@@ -97,6 +99,41 @@ func (h *Handler) handleUnread(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// streams ends the SSE streams served by a Handler or a Broadcaster, see
+// CloseStreams.
+type streams struct {
+	mu     sync.Mutex
+	closed chan struct{}
+}
+
+// close ends the streams open now.
+func (s *streams) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed != nil {
+		close(s.closed)
+		s.closed = nil
+	}
+}
+
+// done returns the channel ending the streams open now.
+func (s *streams) done() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed == nil {
+		s.closed = make(chan struct{})
+	}
+	return s.closed
+}
+
+// CloseStreams ends the SSE streams of h open at the time of the call, so
+// that an HTTP server shutting down does not wait for them: the browsers
+// reconnect to another instance. Streams opened afterwards are not ended.
+// Register it with http.Server.RegisterOnShutdown.
+func (h *Handler) CloseStreams() {
+	h.streams.close()
+}
+
 // handleStream streams live notifications via Server-Sent Events.
 func (h *Handler) handleStream(w http.ResponseWriter, r *http.Request) {
 	userID := h.userIDFunc(r)
@@ -122,11 +159,14 @@ func (h *Handler) handleStream(w http.ResponseWriter, r *http.Request) {
 	flusher.Flush()
 
 	ch := h.store.Subscribe(r.Context(), userID)
+	closed := h.streams.done()
 
 	for {
 		select {
 		case <-r.Context().Done():
 			return
+		case <-closed:
+			return
 		case n, ok := <-ch:
 			if !ok {
 				return
@@ -216,6 +256,7 @@ func (h *Handler) handleBadgeStream(w http.ResponseWriter, r *http.Request) {
 	})
 
 	ch := h.store.Subscribe(r.Context(), userID)
+	closed := h.streams.done()
 
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
@@ -224,6 +265,8 @@ func (h *Handler) handleBadgeStream(w http.ResponseWriter, r *http.Request) {
 		select {
 		case <-r.Context().Done():
 			return
+		case <-closed:
+			return
 		case n, ok := <-ch:
 			if !ok {
 				return
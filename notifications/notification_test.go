@@ -1,7 +1,11 @@
 package notifications_test
 
 import (
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/bozz33/sublimeadmin/notifications"
 )
@@ -110,3 +114,49 @@ func TestStoreGetUnread(t *testing.T) {
 		}
 	}
 }
+
+func TestCloseStreams(t *testing.T) {
+	b := notifications.NewBroadcaster(nil)
+	srv := httptest.NewServer(b.ServeSSE(func(r *http.Request) string { return "1" }))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		close(done)
+	}()
+	// The streams of another broadcaster stay open.
+	other := notifications.NewBroadcaster(nil)
+	otherSrv := httptest.NewServer(other.ServeSSE(func(r *http.Request) string { return "1" }))
+	defer otherSrv.Close()
+	otherResp, err := http.Get(otherSrv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer otherResp.Body.Close()
+	otherDone := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, otherResp.Body)
+		close(otherDone)
+	}()
+
+	b.CloseStreams()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected CloseStreams to end the open stream")
+	}
+	select {
+	case <-otherDone:
+		t.Fatal("CloseStreams ended the stream of another broadcaster")
+	case <-time.After(50 * time.Millisecond):
+	}
+	other.CloseStreams()
+	<-otherDone
+}
@@ -0,0 +1,60 @@
+// Package plugin is the extension system of SublimeAdmin: plugins add
+// navigation items, middleware, resources, pages, widgets and search
+// providers to the panels, boot in dependency order, and can be disabled
+// from the configuration.
+//
+// Features:
+//   - Plugin interface (Name, Boot) and Extension interface (Register)
+//   - Manifest with version, description and required plugins
+//   - Dependency-ordered registration and boot, with cycle detection
+//   - Per-plugin enable/disable and settings (plugins section of config.yaml)
+//   - Embedded assets served under /assets/plugins/{name}/
+//   - Route group under /p/{name}/, behind the panel authentication
+//
+// Basic usage:
+//
+//	type Blog struct{}
+//
+//	func (b *Blog) Name() string { return "blog" }
+//	func (b *Blog) Boot() error  { return nil }
+//
+//	func (b *Blog) Manifest() plugin.Manifest {
+//		return plugin.Manifest{Version: "1.0.0", Requires: []string{"media"}}
+//	}
+//
+//	func (b *Blog) Register(p *plugin.Panel) error {
+//		p.AddResources(NewPostResource()).
+//			AddNavItems(engine.NavigationItem{Label: "Blog", URL: "/admin/posts", Icon: "article"}).
+//			AddWidgets(NewBlogStats())
+//		return nil
+//	}
+//
+//	func init() {
+//		plugin.Register(&Blog{})
+//	}
+//
+// Assets and routes:
+//
+//	//go:embed assets
+//	var assets embed.FS
+//
+//	func (b *Blog) Register(p *plugin.Panel) error {
+//		sub, _ := fs.Sub(assets, "assets")
+//		p.Assets(sub).Stylesheet("blog.css") // /assets/plugins/blog/blog.css
+//		p.HandleFunc("POST /posts/{id}/publish", b.publish) // /p/blog/posts/42/publish
+//		p.Page("GET /stats", "Blog stats", func(r *http.Request) templ.Component {
+//			return views.Stats() // rendered in the panel layout
+//		})
+//		return nil
+//	}
+//
+//	// In the Templ components of the plugin:
+//	<img src={ plugin.AssetURL("blog", "logo.svg") }/>
+//	<a href={ templ.SafeURL(plugin.URL("blog", "/stats")) }>Stats</a>
+//
+// Panels register and boot the enabled plugins in Router(). Plugins are
+// enabled by default; configure them with:
+//
+//	plugin.Configure(cfg) // plugins: {blog: {enabled: false}}
+//	plugin.Disable("blog")
+package plugin
@@ -0,0 +1,175 @@
+package plugin
+
+import (
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/engine"
+	"github.com/bozz33/sublimeadmin/search"
+	"github.com/bozz33/sublimeadmin/ui/layouts"
+	"github.com/bozz33/sublimeadmin/widget"
+)
+
+// Panel exposes the hook points of a booting panel to an Extension.
+type Panel struct {
+	panel  *engine.Panel
+	plugin string
+	mux    *http.ServeMux // route group, mounted on first use
+}
+
+func newPanel(panel *engine.Panel, plugin string) *Panel {
+	return &Panel{panel: panel, plugin: plugin}
+}
+
+// ID returns the ID of the panel.
+func (p *Panel) ID() string {
+	return p.panel.ID
+}
+
+// Engine returns the underlying panel, for settings without a hook point.
+func (p *Panel) Engine() *engine.Panel {
+	return p.panel
+}
+
+// Settings returns the settings of the plugin in the plugins section of
+// the configuration (see Configure), or nil.
+func (p *Panel) Settings() map[string]any {
+	return settingsOf(p.plugin)
+}
+
+// AddNavItems adds sidebar links.
+func (p *Panel) AddNavItems(items ...engine.NavigationItem) *Panel {
+	p.panel.WithNavItems(items...)
+	return p
+}
+
+// AddNavGroups adds sidebar groups.
+func (p *Panel) AddNavGroups(groups ...engine.NavigationGroup) *Panel {
+	p.panel.WithNavGroups(groups...)
+	return p
+}
+
+// Use adds middleware to the protected routes of the panel.
+func (p *Panel) Use(mw ...func(http.Handler) http.Handler) *Panel {
+	p.panel.WithMiddleware(mw...)
+	return p
+}
+
+// AddResources adds resources, with their CRUD routes and navigation.
+func (p *Panel) AddResources(rs ...engine.Resource) *Panel {
+	p.panel.AddResources(rs...)
+	return p
+}
+
+// AddPages adds custom pages.
+func (p *Panel) AddPages(pages ...engine.Page) *Panel {
+	p.panel.AddPages(pages...)
+	return p
+}
+
+// AddWidgets registers dashboard widget providers. Widget providers are
+// global: they are shown on the dashboard of every panel, and a provider
+// whose ID is already registered is skipped.
+func (p *Panel) AddWidgets(providers ...widget.Provider) *Panel {
+	registered := map[string]bool{}
+	for _, provider := range widget.GetProviders() {
+		registered[provider.GetID()] = true
+	}
+	for _, provider := range providers {
+		if !registered[provider.GetID()] {
+			widget.Register(provider)
+			registered[provider.GetID()] = true
+		}
+	}
+	return p
+}
+
+// AddSearchProviders registers global search providers, replacing the
+// providers with the same label. Resources implementing search.Searchable
+// are registered by the panel already.
+func (p *Panel) AddSearchProviders(providers ...search.Searchable) *Panel {
+	for _, provider := range providers {
+		search.Unregister(provider.GetSearchLabel())
+		search.Register(provider)
+	}
+	return p
+}
+
+// Assets serves fsys under /assets/plugins/{name}/:
+//
+//	//go:embed assets
+//	var assets embed.FS
+//
+//	sub, _ := fs.Sub(assets, "assets")
+//	p.Assets(sub).Stylesheet("blog.css")
+func (p *Panel) Assets(fsys fs.FS) *Panel {
+	p.panel.WithPluginAssets(p.plugin, fsys)
+	return p
+}
+
+// Stylesheet adds an asset of the plugin (see Assets) to every page.
+func (p *Panel) Stylesheet(path string) *Panel {
+	p.panel.WithStylesheets(AssetPath(p.plugin, path))
+	return p
+}
+
+// Script adds a deferred script asset of the plugin to every page.
+func (p *Panel) Script(path string) *Panel {
+	p.panel.WithScripts(AssetPath(p.plugin, path))
+	return p
+}
+
+// RenderHook adds a component to a render hook of the panel layout, e.g.
+// layouts.HookSidebarFooter.
+func (p *Panel) RenderHook(name string, component templ.Component) *Panel {
+	p.panel.WithRenderHook(name, component)
+	return p
+}
+
+// Handle registers a handler in the route group of the plugin, served
+// under /p/{name}/ behind the panel authentication and middleware.
+// Patterns are relative to the group: "GET /posts/{id}" serves
+// /p/blog/posts/42.
+func (p *Panel) Handle(pattern string, h http.Handler) *Panel {
+	if p.mux == nil {
+		p.mux = http.NewServeMux()
+		p.panel.WithPluginRoutes(p.plugin, p.mux)
+	}
+	p.mux.Handle(pattern, h)
+	return p
+}
+
+// HandleFunc registers a handler function in the route group of the plugin.
+func (p *Panel) HandleFunc(pattern string, fn func(http.ResponseWriter, *http.Request)) *Panel {
+	return p.Handle(pattern, http.HandlerFunc(fn))
+}
+
+// Page registers a page of the route group rendered in the panel layout.
+func (p *Panel) Page(pattern, title string, render func(r *http.Request) templ.Component) *Panel {
+	return p.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = layouts.Page(title, render(r)).Render(r.Context(), w)
+	})
+}
+
+// AssetPath returns the path of an asset of a plugin, relative to the
+// panel: AssetPath("blog", "blog.css") => "/assets/plugins/blog/blog.css".
+func AssetPath(name, path string) string {
+	return engine.PluginAssetsPrefix + name + "/" + strings.TrimLeft(path, "/")
+}
+
+// AssetURL returns the URL of an asset of a plugin, prefixed with the
+// panel path, for use in the Templ components of the plugin:
+//
+//	<img src={ plugin.AssetURL("blog", "logo.svg") }/>
+func AssetURL(name, path string) string {
+	return layouts.AssetURL(AssetPath(name, path))
+}
+
+// URL returns the URL of a route of a plugin, prefixed with the panel
+// path: URL("blog", "/posts") => "/admin/p/blog/posts".
+func URL(name, path string) string {
+	return layouts.AssetURL(engine.PluginRoutesPrefix + name + "/" + strings.TrimLeft(path, "/"))
+}
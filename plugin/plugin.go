@@ -2,7 +2,11 @@ package plugin
 
 import (
 	"fmt"
+	"strings"
 	"sync"
+
+	"github.com/bozz33/sublimeadmin/config"
+	"github.com/bozz33/sublimeadmin/engine"
 )
 
 // Plugin is the interface that all SublimeAdmin plugins must implement.
@@ -13,12 +17,40 @@ type Plugin interface {
 	Boot() error
 }
 
+// Extension is a plugin that extends the panels: Register is called by
+// every panel at boot, before Boot, with the hook points of the panel.
+type Extension interface {
+	Plugin
+	Register(p *Panel) error
+}
+
+// Manifest describes a plugin.
+type Manifest struct {
+	Name        string
+	Version     string
+	Description string
+	// Requires are the names of the plugins this plugin depends on; they
+	// are registered and booted first.
+	Requires []string
+}
+
+// HasManifest is implemented by plugins that declare a manifest.
+type HasManifest interface {
+	Manifest() Manifest
+}
+
 // registry holds all registered plugins.
 var (
-	mu      sync.RWMutex
-	plugins []Plugin
+	mu       sync.RWMutex
+	plugins  []Plugin
+	disabled = map[string]bool{}
+	settings = map[string]map[string]any{}
 )
 
+func init() {
+	engine.RegisterExtension(BootPanel)
+}
+
 // Register adds a plugin to the global registry.
 // Typically called from a plugin's init() function.
 func Register(p Plugin) {
@@ -36,14 +68,181 @@ func All() []Plugin {
 	return out
 }
 
-// Boot calls Boot() on every registered plugin in registration order.
-// Returns the first error encountered.
-func Boot() error {
+// Get returns the plugin with the given name, or nil if not found.
+func Get(name string) Plugin {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, p := range plugins {
+		if p.Name() == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// ManifestOf returns the manifest of a plugin; plugins without one get a
+// manifest with their name only.
+func ManifestOf(p Plugin) Manifest {
+	if m, ok := p.(HasManifest); ok {
+		manifest := m.Manifest()
+		if manifest.Name == "" {
+			manifest.Name = p.Name()
+		}
+		return manifest
+	}
+	return Manifest{Name: p.Name()}
+}
+
+// Disable disables plugins: they are neither registered on the panels nor
+// booted.
+func Disable(names ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, name := range names {
+		disabled[strings.ToLower(name)] = true
+	}
+}
+
+// Enable re-enables disabled plugins.
+func Enable(names ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, name := range names {
+		delete(disabled, strings.ToLower(name))
+	}
+}
+
+// Enabled reports whether the plugin name is enabled.
+func Enabled(name string) bool {
 	mu.RLock()
-	list := make([]Plugin, len(plugins))
-	copy(list, plugins)
+	defer mu.RUnlock()
+	return !disabled[strings.ToLower(name)]
+}
+
+// Configure applies the plugins section of the configuration: plugins
+// with enabled: false are disabled, and the settings are passed to the
+// plugins through Panel.Settings.
+//
+//	plugins:
+//	  blog:
+//	    enabled: false
+//	  seo:
+//	    settings:
+//	      sitemap: true
+func Configure(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for name, pc := range cfg.Plugins {
+		name = strings.ToLower(name)
+		if !cfg.PluginEnabled(name) {
+			disabled[name] = true
+		} else {
+			delete(disabled, name)
+		}
+		if pc.Settings != nil {
+			settings[name] = pc.Settings
+		}
+	}
+}
+
+// Ordered returns the enabled plugins in boot order: each plugin after the
+// plugins it requires, in registration order otherwise. It fails when a
+// required plugin is missing or disabled, or when dependencies form a
+// cycle.
+func Ordered() ([]Plugin, error) {
+	mu.RLock()
+	list := make([]Plugin, 0, len(plugins))
+	byName := make(map[string]int, len(plugins))
+	for _, p := range plugins {
+		if disabled[strings.ToLower(p.Name())] {
+			continue
+		}
+		if _, ok := byName[p.Name()]; !ok {
+			byName[p.Name()] = len(list)
+		}
+		list = append(list, p)
+	}
+	isDisabled := make(map[string]bool, len(disabled))
+	for name := range disabled {
+		isDisabled[name] = true
+	}
 	mu.RUnlock()
 
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make([]int, len(list))
+	ordered := make([]Plugin, 0, len(list))
+
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		p := list[i]
+		switch state[i] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("plugin %q: dependency cycle: %s", p.Name(), strings.Join(append(path, p.Name()), " -> "))
+		}
+		state[i] = visiting
+		for _, req := range ManifestOf(p).Requires {
+			dep, ok := byName[req]
+			if !ok {
+				if isDisabled[strings.ToLower(req)] {
+					return fmt.Errorf("plugin %q requires %q, which is disabled", p.Name(), req)
+				}
+				return fmt.Errorf("plugin %q requires %q, which is not registered", p.Name(), req)
+			}
+			if err := visit(dep, append(path, p.Name())); err != nil {
+				return err
+			}
+		}
+		state[i] = done
+		ordered = append(ordered, p)
+		return nil
+	}
+
+	for i := range list {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// Boot calls Boot() on every enabled plugin in dependency order.
+// Returns the first error encountered.
+func Boot() error {
+	list, err := Ordered()
+	if err != nil {
+		return err
+	}
+	return boot(list)
+}
+
+// BootPanel registers the extensions on a panel, then boots every enabled
+// plugin, in dependency order. Panels call it from Router().
+func BootPanel(panel *engine.Panel) error {
+	list, err := Ordered()
+	if err != nil {
+		return err
+	}
+	for _, p := range list {
+		ext, ok := p.(Extension)
+		if !ok {
+			continue
+		}
+		if err := ext.Register(newPanel(panel, p.Name())); err != nil {
+			return fmt.Errorf("plugin %q: register failed: %w", p.Name(), err)
+		}
+	}
+	return boot(list)
+}
+
+func boot(list []Plugin) error {
 	for _, p := range list {
 		if err := p.Boot(); err != nil {
 			return fmt.Errorf("plugin %q: boot failed: %w", p.Name(), err)
@@ -52,14 +251,9 @@ func Boot() error {
 	return nil
 }
 
-// Get returns the plugin with the given name, or nil if not found.
-func Get(name string) Plugin {
+// settingsOf returns the configured settings of a plugin.
+func settingsOf(name string) map[string]any {
 	mu.RLock()
 	defer mu.RUnlock()
-	for _, p := range plugins {
-		if p.Name() == name {
-			return p
-		}
-	}
-	return nil
+	return settings[strings.ToLower(name)]
 }
@@ -1,16 +1,27 @@
 package plugin
 
 import (
+	"context"
 	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"testing/fstest"
 
+	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/config"
+	"github.com/bozz33/sublimeadmin/engine"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // reset clears the global registry between tests.
 func reset() {
 	mu.Lock()
 	plugins = nil
+	disabled = map[string]bool{}
+	settings = map[string]map[string]any{}
 	mu.Unlock()
 }
 
@@ -94,3 +105,235 @@ func TestAllReturnsCopy(t *testing.T) {
 	a[0] = &mockPlugin{name: "mutated"}
 	assert.Equal(t, "x", All()[0].Name())
 }
+
+// extPlugin is an Extension with a manifest.
+type extPlugin struct {
+	mockPlugin
+	requires []string
+	register func(p *Panel) error
+	order    *[]string
+}
+
+func (e *extPlugin) Manifest() Manifest {
+	return Manifest{Version: "1.0.0", Requires: e.requires}
+}
+
+func (e *extPlugin) Register(p *Panel) error {
+	if e.order != nil {
+		*e.order = append(*e.order, "register:"+e.name)
+	}
+	if e.register != nil {
+		return e.register(p)
+	}
+	return nil
+}
+
+func (e *extPlugin) Boot() error {
+	if e.order != nil {
+		*e.order = append(*e.order, "boot:"+e.name)
+	}
+	return e.mockPlugin.Boot()
+}
+
+func names(list []Plugin) []string {
+	out := make([]string, len(list))
+	for i, p := range list {
+		out[i] = p.Name()
+	}
+	return out
+}
+
+func TestOrderedDependencies(t *testing.T) {
+	reset()
+	Register(&extPlugin{mockPlugin: mockPlugin{name: "blog"}, requires: []string{"media", "seo"}})
+	Register(&extPlugin{mockPlugin: mockPlugin{name: "seo"}, requires: []string{"media"}})
+	Register(&mockPlugin{name: "analytics"})
+	Register(&extPlugin{mockPlugin: mockPlugin{name: "media"}})
+
+	list, err := Ordered()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"media", "seo", "blog", "analytics"}, names(list))
+	assert.Equal(t, "1.0.0", ManifestOf(list[0]).Version)
+	assert.Equal(t, Manifest{Name: "analytics"}, ManifestOf(list[3]))
+}
+
+func TestOrderedErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func()
+		wantErr string
+	}{
+		{
+			name: "missing dependency",
+			setup: func() {
+				Register(&extPlugin{mockPlugin: mockPlugin{name: "blog"}, requires: []string{"media"}})
+			},
+			wantErr: `plugin "blog" requires "media", which is not registered`,
+		},
+		{
+			name: "disabled dependency",
+			setup: func() {
+				Register(&extPlugin{mockPlugin: mockPlugin{name: "blog"}, requires: []string{"media"}})
+				Register(&mockPlugin{name: "media"})
+				Disable("media")
+			},
+			wantErr: `plugin "blog" requires "media", which is disabled`,
+		},
+		{
+			name: "cycle",
+			setup: func() {
+				Register(&extPlugin{mockPlugin: mockPlugin{name: "a"}, requires: []string{"b"}})
+				Register(&extPlugin{mockPlugin: mockPlugin{name: "b"}, requires: []string{"a"}})
+			},
+			wantErr: "dependency cycle: a -> b -> a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reset()
+			tt.setup()
+			_, err := Ordered()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.Error(t, Boot())
+		})
+	}
+}
+
+func TestDisableAndEnable(t *testing.T) {
+	reset()
+	p := &mockPlugin{name: "blog"}
+	Register(p)
+
+	Disable("Blog")
+	assert.False(t, Enabled("blog"))
+	require.NoError(t, Boot())
+	assert.False(t, p.booted)
+
+	Enable("blog")
+	require.NoError(t, Boot())
+	assert.True(t, p.booted)
+}
+
+func TestConfigure(t *testing.T) {
+	reset()
+	off := false
+	cfg := &config.Config{Plugins: map[string]config.PluginConfig{
+		"blog": {Enabled: &off},
+		"seo":  {Settings: map[string]any{"sitemap": true}},
+	}}
+	Configure(cfg)
+
+	assert.False(t, Enabled("blog"))
+	assert.True(t, Enabled("seo"))
+	assert.True(t, Enabled("other"))
+	assert.Equal(t, map[string]any{"sitemap": true}, newPanel(engine.NewPanel("admin"), "seo").Settings())
+}
+
+func TestBootPanel(t *testing.T) {
+	reset()
+	var order []string
+	var settingsSeen map[string]any
+	middleware := func(next http.Handler) http.Handler { return next }
+
+	Register(&extPlugin{
+		mockPlugin: mockPlugin{name: "blog"},
+		requires:   []string{"media"},
+		order:      &order,
+		register: func(p *Panel) error {
+			settingsSeen = p.Settings()
+			p.AddNavItems(engine.NavigationItem{Label: "Blog", URL: "/admin/blog"}).
+				AddNavGroups(engine.NavigationGroup{Label: "Content"}).
+				Use(middleware)
+			return nil
+		},
+	})
+	Register(&extPlugin{mockPlugin: mockPlugin{name: "media"}, order: &order})
+	Configure(&config.Config{Plugins: map[string]config.PluginConfig{
+		"blog": {Settings: map[string]any{"per_page": 10}},
+	}})
+
+	panel := engine.NewPanel("admin")
+	require.NoError(t, BootPanel(panel))
+
+	assert.Equal(t, []string{"register:media", "register:blog", "boot:media", "boot:blog"}, order)
+	assert.Equal(t, map[string]any{"per_page": 10}, settingsSeen)
+	require.Len(t, panel.NavItems, 1)
+	assert.Equal(t, "Blog", panel.NavItems[0].Label)
+	assert.Len(t, panel.NavGroups, 1)
+	assert.Len(t, panel.Middlewares, 1)
+}
+
+func TestBootPanelRegisterError(t *testing.T) {
+	reset()
+	Register(&extPlugin{
+		mockPlugin: mockPlugin{name: "broken"},
+		register:   func(p *Panel) error { return errors.New("no database") },
+	})
+
+	err := BootPanel(engine.NewPanel("admin"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `plugin "broken": register failed: no database`)
+}
+
+func TestRouterBootsPlugins(t *testing.T) {
+	reset()
+	p := &extPlugin{
+		mockPlugin: mockPlugin{name: "nav"},
+		register: func(p *Panel) error {
+			p.AddNavItems(engine.NavigationItem{Label: "Docs", URL: "/docs"})
+			return nil
+		},
+	}
+	Register(p)
+
+	panel := engine.NewPanel("admin")
+	panel.Router()
+	assert.True(t, p.booted)
+	assert.Len(t, panel.NavItems, 1)
+}
+
+func TestPluginAssetsAndRoutes(t *testing.T) {
+	reset()
+	Register(&extPlugin{
+		mockPlugin: mockPlugin{name: "blog"},
+		register: func(p *Panel) error {
+			p.Assets(fstest.MapFS{"blog.css": {Data: []byte(".post{}")}}).
+				Stylesheet("blog.css").
+				HandleFunc("GET /hello/{name}", func(w http.ResponseWriter, r *http.Request) {
+					io.WriteString(w, "hello "+r.PathValue("name"))
+				}).
+				Page("GET /posts", "Posts", func(r *http.Request) templ.Component {
+					return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+						_, err := io.WriteString(w, "<p>post list</p>")
+						return err
+					})
+				})
+			return nil
+		},
+	})
+
+	h := engine.NewPanel("admin").WithPath("/admin").Router()
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/assets/plugins/blog/blog.css")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, ".post{}", rec.Body.String())
+
+	rec = get("/p/blog/hello/world")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "hello world", rec.Body.String())
+
+	rec = get("/p/blog/posts")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<p>post list</p>")
+	assert.Contains(t, rec.Body.String(), `href="/admin/assets/plugins/blog/blog.css"`)
+
+	assert.Equal(t, http.StatusNotFound, get("/p/blog/missing").Code)
+	assert.Equal(t, "/admin/assets/plugins/blog/logo.svg", AssetURL("blog", "/logo.svg"))
+	assert.Equal(t, "/admin/p/blog/posts", URL("blog", "posts"))
+}
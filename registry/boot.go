@@ -0,0 +1,204 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bozz33/sublimeadmin/engine"
+)
+
+// Dependent is implemented by resources that need other resources or
+// services to be booted first. Dependencies are resource slugs or service
+// names.
+//
+//	func (r *OrderResource) DependsOn() []string { return []string{"users", "payments"} }
+type Dependent interface {
+	DependsOn() []string
+}
+
+// Booter is implemented by resources that need initialization at boot
+// (seeders, relation managers, caches). Boot calls it in dependency order.
+type Booter interface {
+	Boot(ctx context.Context) error
+}
+
+// service is a named boot step that resources can depend on.
+type service struct {
+	boot      func(ctx context.Context) error
+	dependsOn []string
+}
+
+// CycleError is returned when dependencies form a cycle.
+type CycleError struct {
+	// Cycle lists the names of the cycle, the first one repeated at the end.
+	Cycle []string
+}
+
+func (e *CycleError) Error() string {
+	return "registry: dependency cycle: " + strings.Join(e.Cycle, " -> ")
+}
+
+// MissingDependencyError is returned when a dependency is not registered.
+type MissingDependencyError struct {
+	Name       string
+	Dependency string
+}
+
+func (e *MissingDependencyError) Error() string {
+	return fmt.Sprintf("registry: %s depends on %s, which is not registered", e.Name, e.Dependency)
+}
+
+// RegisterService registers a named boot step (database, cache, search
+// index...) that resources can list in DependsOn. boot may be nil for
+// services initialized elsewhere that only serve as an ordering anchor.
+func (r *Registry) RegisterService(name string, boot func(ctx context.Context) error, dependsOn ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.exists(name) {
+		return fmt.Errorf("'%s' already registered", name)
+	}
+	r.services[name] = &service{boot: boot, dependsOn: dependsOn}
+	return nil
+}
+
+// BootOrder returns the names of resources and services sorted so that
+// every name comes after its dependencies, alphabetically among
+// independent ones. Lazy resources not built yet are listed without
+// dependencies. It returns a *CycleError or a *MissingDependencyError when
+// the dependencies cannot be satisfied.
+func (r *Registry) BootOrder() ([]string, error) {
+	graph := r.dependencies()
+
+	names := make([]string, 0, len(graph))
+	for name := range graph {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		for _, dep := range graph[name] {
+			if _, ok := graph[dep]; !ok {
+				return nil, &MissingDependencyError{Name: name, Dependency: dep}
+			}
+		}
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(graph))
+	order := make([]string, 0, len(graph))
+	var path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case done:
+			return nil
+		case visiting:
+			start := slices.Index(path, name)
+			cycle := append(slices.Clone(path[start:]), name)
+			return &CycleError{Cycle: cycle}
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		deps := slices.Clone(graph[name])
+		slices.Sort(deps)
+		for _, dep := range deps {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		order = append(order, name)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// Boot initializes services and resources implementing Booter in
+// BootOrder. It stops at the first error, which names the failing step;
+// dependency cycles are reported before anything is booted.
+func (r *Registry) Boot(ctx context.Context) error {
+	order, err := r.BootOrder()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range order {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		r.mu.RLock()
+		svc := r.services[name]
+		resource := r.resources[name]
+		r.mu.RUnlock()
+
+		switch {
+		case svc != nil && svc.boot != nil:
+			if err := svc.boot(ctx); err != nil {
+				return fmt.Errorf("registry: boot service %s: %w", name, err)
+			}
+		case resource != nil:
+			if booter, ok := resource.(Booter); ok {
+				if err := booter.Boot(ctx); err != nil {
+					return fmt.Errorf("registry: boot resource %s: %w", name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// dependencies returns the dependency graph of every registered name.
+func (r *Registry) dependencies() map[string][]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	graph := make(map[string][]string, len(r.resources)+len(r.providers)+len(r.services))
+	for slug, resource := range r.resources {
+		graph[slug] = dependsOn(resource)
+	}
+	for slug := range r.providers {
+		graph[slug] = nil
+	}
+	for name, svc := range r.services {
+		graph[name] = svc.dependsOn
+	}
+	return graph
+}
+
+func dependsOn(resource engine.Resource) []string {
+	if d, ok := resource.(Dependent); ok {
+		return d.DependsOn()
+	}
+	return nil
+}
+
+// RegisterService registers a boot step in the global registry.
+func RegisterService(name string, boot func(ctx context.Context) error, dependsOn ...string) error {
+	return global.RegisterService(name, boot, dependsOn...)
+}
+
+// BootOrder returns the boot order of the global registry.
+func BootOrder() ([]string, error) {
+	return global.BootOrder()
+}
+
+// Boot boots the global registry.
+func Boot(ctx context.Context) error {
+	return global.Boot(ctx)
+}
@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bozz33/sublimeadmin/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bootResource records its boot in a shared log.
+type bootResource struct {
+	*engine.BaseResource
+	deps []string
+	log  *[]string
+	err  error
+}
+
+func (r *bootResource) DependsOn() []string { return r.deps }
+
+func (r *bootResource) Boot(context.Context) error {
+	*r.log = append(*r.log, r.Slug())
+	return r.err
+}
+
+func newBootResource(log *[]string, slug string, deps ...string) *bootResource {
+	return &bootResource{BaseResource: engine.NewBaseResource(slug, slug, slug), deps: deps, log: log}
+}
+
+func TestBoot(t *testing.T) {
+	var log []string
+	reg := New()
+	require.NoError(t, reg.Register(newBootResource(&log, "orders", "users", "payments")))
+	require.NoError(t, reg.Register(newBootResource(&log, "users", "db")))
+	require.NoError(t, reg.Register(newBootResource(&log, "payments", "users")))
+	require.NoError(t, reg.RegisterService("db", func(context.Context) error {
+		log = append(log, "db")
+		return nil
+	}))
+	assert.False(t, reg.Has("db"), "services are not resources")
+
+	order, err := reg.BootOrder()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db", "users", "payments", "orders"}, order)
+
+	require.NoError(t, reg.Boot(context.Background()))
+	assert.Equal(t, order, log)
+}
+
+func TestBootOrderErrors(t *testing.T) {
+	var log []string
+	reg := New()
+	require.NoError(t, reg.Register(newBootResource(&log, "a", "b")))
+	require.NoError(t, reg.Register(newBootResource(&log, "b", "c")))
+	require.NoError(t, reg.Register(newBootResource(&log, "c", "a")))
+
+	err := reg.Boot(context.Background())
+	var cycle *CycleError
+	require.ErrorAs(t, err, &cycle)
+	assert.Equal(t, []string{"a", "b", "c", "a"}, cycle.Cycle)
+	assert.Empty(t, log, "nothing boots when the graph has a cycle")
+
+	reg.Clear()
+	require.NoError(t, reg.Register(newBootResource(&log, "a", "missing")))
+	_, err = reg.BootOrder()
+	var missing *MissingDependencyError
+	require.ErrorAs(t, err, &missing)
+	assert.Equal(t, "missing", missing.Dependency)
+}
+
+func TestBootStopsOnError(t *testing.T) {
+	var log []string
+	reg := New()
+	failing := newBootResource(&log, "users")
+	failing.err = errors.New("seed failed")
+	require.NoError(t, reg.Register(failing))
+	require.NoError(t, reg.Register(newBootResource(&log, "orders", "users")))
+
+	err := reg.Boot(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boot resource users: seed failed")
+	assert.Equal(t, []string{"users"}, log)
+}
@@ -5,9 +5,11 @@
 // The registry is used by the engine to build navigation and route handlers.
 //
 // Features:
-//   - Resource registration
+//   - Resource registration, lazy providers and unregistration
+//   - OnRegister lifecycle hooks
+//   - Dependency declaration (DependsOn) and ordered Boot, with cycle detection
 //   - Lookup by slug or type
-//   - Filtering by group or capability
+//   - Filtering by capability (Implementing[T]) and metadata (tags, owner, flag)
 //   - Navigation item generation
 //   - Resource metadata access
 //
@@ -25,6 +27,23 @@
 //	// Get all resources
 //	all := reg.All()
 //
-//	// Filter by group
-//	adminResources := reg.ByGroup("Administration")
+//	// Build an expensive resource on first use
+//	reg.RegisterProvider("reports", func() engine.Resource { return NewReportResource() })
+//
+//	// React to registrations (plugins, audit...)
+//	reg.OnRegister(func(res engine.Resource) { log.Println("registered", res.Slug()) })
+//
+//	// Boot services and resources in dependency order
+//	reg.RegisterService("db", connectDB)
+//	if err := reg.Boot(ctx); err != nil {
+//		log.Fatal(err) // e.g. registry: dependency cycle: orders -> users -> orders
+//	}
+//
+//	// Discover resources by capability or tag
+//	importable := registry.Implementing[engine.ResourceImportable]()
+//	reg.Attach("invoices", registry.Metadata{Tags: []string{"billing"}, Owner: "payments"})
+//	billing := reg.WithTag("billing")
+//
+//	// Group by category
+//	byCategory := reg.GroupByCategory()
 package registry
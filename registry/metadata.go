@@ -0,0 +1,174 @@
+package registry
+
+import (
+	"context"
+	"slices"
+
+	"github.com/bozz33/sublimeadmin/config"
+	"github.com/bozz33/sublimeadmin/engine"
+)
+
+// Metadata describes a resource for discovery by panels and plugins.
+type Metadata struct {
+	// Tags group resources across categories ("billing", "gdpr").
+	Tags []string
+	// Owner is the team responsible for the resource.
+	Owner string
+	// Flag is the feature flag gating the resource (see config.FlagEnabled).
+	Flag string
+	// Extra holds any other key/value metadata.
+	Extra map[string]string
+}
+
+// HasTag reports whether the metadata has tag.
+func (m Metadata) HasTag(tag string) bool {
+	return slices.Contains(m.Tags, tag)
+}
+
+// Described is implemented by resources declaring their own metadata.
+// Metadata attached with Attach is merged over it.
+type Described interface {
+	RegistryMetadata() Metadata
+}
+
+// Attach adds metadata to a resource, including lazy resources not built
+// yet. Tags are appended; Owner, Flag and Extra keys replace the previous
+// values when set.
+//
+//	reg.Attach("invoices", registry.Metadata{Tags: []string{"billing"}, Owner: "payments"})
+func (r *Registry) Attach(slug string, meta Metadata) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.metadata[slug] = mergeMetadata(r.metadata[slug], meta)
+}
+
+// Metadata returns the metadata of a resource: declared with Described,
+// then attached with Attach.
+func (r *Registry) Metadata(slug string) Metadata {
+	r.mu.RLock()
+	attached := r.metadata[slug]
+	resource := r.resources[slug]
+	r.mu.RUnlock()
+
+	var meta Metadata
+	if d, ok := resource.(Described); ok {
+		meta = d.RegistryMetadata()
+	}
+	return mergeMetadata(meta, attached)
+}
+
+// WithTag returns the resources tagged tag, sorted by slug.
+func (r *Registry) WithTag(tag string) []engine.Resource {
+	return r.Query(func(_ engine.Resource, meta Metadata) bool {
+		return meta.HasTag(tag)
+	})
+}
+
+// OwnedBy returns the resources owned by team, sorted by slug.
+func (r *Registry) OwnedBy(team string) []engine.Resource {
+	return r.Query(func(_ engine.Resource, meta Metadata) bool {
+		return meta.Owner == team
+	})
+}
+
+// Enabled returns the resources whose feature flag is enabled for ctx, and
+// the resources without flag, sorted by slug.
+func (r *Registry) Enabled(ctx context.Context) []engine.Resource {
+	return r.Query(func(_ engine.Resource, meta Metadata) bool {
+		return meta.Flag == "" || config.FlagEnabled(ctx, meta.Flag)
+	})
+}
+
+// Query returns the resources matching predicate, sorted by slug. Like
+// All, it builds pending lazy resources.
+func (r *Registry) Query(predicate func(engine.Resource, Metadata) bool) []engine.Resource {
+	var result []engine.Resource
+	for _, slug := range r.sortedNames() {
+		resource, ok := r.Get(slug)
+		if !ok {
+			continue
+		}
+		if predicate(resource, r.Metadata(slug)) {
+			result = append(result, resource)
+		}
+	}
+	return result
+}
+
+// ImplementingIn returns the resources of reg implementing T, sorted by
+// slug.
+//
+//	importable := registry.ImplementingIn[engine.ResourceImportable](reg)
+func ImplementingIn[T any](reg *Registry) []T {
+	var result []T
+	for _, slug := range reg.sortedNames() {
+		resource, ok := reg.Get(slug)
+		if !ok {
+			continue
+		}
+		if v, ok := resource.(T); ok {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// Implementing returns the resources of the global registry implementing
+// T, sorted by slug.
+//
+//	for _, res := range registry.Implementing[engine.ResourceImportable]() { ... }
+func Implementing[T any]() []T {
+	return ImplementingIn[T](global)
+}
+
+// sortedNames returns the resource slugs in alphabetical order.
+func (r *Registry) sortedNames() []string {
+	names := r.Names()
+	slices.Sort(names)
+	return names
+}
+
+func mergeMetadata(base, override Metadata) Metadata {
+	out := Metadata{
+		Tags:  slices.Clone(base.Tags),
+		Owner: base.Owner,
+		Flag:  base.Flag,
+	}
+	for _, tag := range override.Tags {
+		if !slices.Contains(out.Tags, tag) {
+			out.Tags = append(out.Tags, tag)
+		}
+	}
+	if override.Owner != "" {
+		out.Owner = override.Owner
+	}
+	if override.Flag != "" {
+		out.Flag = override.Flag
+	}
+	if len(base.Extra)+len(override.Extra) > 0 {
+		out.Extra = make(map[string]string, len(base.Extra)+len(override.Extra))
+		for k, v := range base.Extra {
+			out.Extra[k] = v
+		}
+		for k, v := range override.Extra {
+			out.Extra[k] = v
+		}
+	}
+	return out
+}
+
+// Attach adds metadata to a resource of the global registry.
+func Attach(slug string, meta Metadata) {
+	global.Attach(slug, meta)
+}
+
+// WithTag returns the resources of the global registry tagged tag.
+func WithTag(tag string) []engine.Resource {
+	return global.WithTag(tag)
+}
+
+// Query returns the resources of the global registry matching predicate.
+func Query(predicate func(engine.Resource, Metadata) bool) []engine.Resource {
+	return global.Query(predicate)
+}
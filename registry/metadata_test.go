@@ -0,0 +1,75 @@
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bozz33/sublimeadmin/config"
+	"github.com/bozz33/sublimeadmin/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// taggedResource declares its own metadata.
+type taggedResource struct {
+	*engine.BaseResource
+}
+
+func (r *taggedResource) RegistryMetadata() Metadata {
+	return Metadata{Tags: []string{"billing"}, Owner: "payments", Flag: "new_invoices"}
+}
+
+// exportableResource implements an optional capability.
+type exportableResource struct {
+	*engine.BaseResource
+}
+
+func (r *exportableResource) Export(context.Context) error { return nil }
+
+type exporter interface {
+	Export(context.Context) error
+}
+
+func slugs(resources []engine.Resource) []string {
+	out := make([]string, 0, len(resources))
+	for _, res := range resources {
+		out = append(out, res.Slug())
+	}
+	return out
+}
+
+func TestMetadata(t *testing.T) {
+	reg := New()
+	require.NoError(t, reg.Register(&taggedResource{engine.NewBaseResource("invoices", "Invoice", "Invoices")}))
+	require.NoError(t, reg.Register(resource("users")))
+	require.NoError(t, reg.RegisterProvider("refunds", func() engine.Resource { return resource("refunds") }))
+
+	reg.Attach("invoices", Metadata{Tags: []string{"gdpr"}, Extra: map[string]string{"sla": "gold"}})
+	reg.Attach("refunds", Metadata{Tags: []string{"billing"}, Owner: "payments"})
+
+	meta := reg.Metadata("invoices")
+	assert.Equal(t, []string{"billing", "gdpr"}, meta.Tags)
+	assert.Equal(t, "payments", meta.Owner)
+	assert.Equal(t, "gold", meta.Extra["sla"])
+
+	assert.Equal(t, []string{"invoices", "refunds"}, slugs(reg.WithTag("billing")))
+	assert.Equal(t, []string{"invoices"}, slugs(reg.WithTag("gdpr")))
+	assert.Equal(t, []string{"invoices", "refunds"}, slugs(reg.OwnedBy("payments")))
+
+	assert.Equal(t, []string{"refunds", "users"}, slugs(reg.Enabled(context.Background())))
+	ctx := config.ContextWithFlag(context.Background(), "new_invoices", true)
+	assert.Equal(t, []string{"invoices", "refunds", "users"}, slugs(reg.Enabled(ctx)))
+
+	assert.True(t, reg.Unregister("invoices"))
+	assert.Empty(t, reg.Metadata("invoices").Tags)
+}
+
+func TestImplementing(t *testing.T) {
+	reg := New()
+	require.NoError(t, reg.Register(&exportableResource{engine.NewBaseResource("orders", "Order", "Orders")}))
+	require.NoError(t, reg.Register(resource("users")))
+
+	exporters := ImplementingIn[exporter](reg)
+	require.Len(t, exporters, 1)
+	assert.Equal(t, "orders", exporters[0].(engine.Resource).Slug())
+}
@@ -11,27 +11,65 @@ import (
 // Registry manages resource registration and discovery.
 type Registry struct {
 	resources map[string]engine.Resource
+	providers map[string]*lazyResource
+	services  map[string]*service
+	metadata  map[string]Metadata
+	hooks     []func(engine.Resource)
 	mu        sync.RWMutex
 }
 
+// lazyResource is a resource built on first use by its provider.
+type lazyResource struct {
+	once     sync.Once
+	provider func() engine.Resource
+	resource engine.Resource
+}
+
+func (l *lazyResource) get() engine.Resource {
+	l.once.Do(func() { l.resource = l.provider() })
+	return l.resource
+}
+
 // New creates a new Registry instance.
 func New() *Registry {
 	return &Registry{
 		resources: make(map[string]engine.Resource),
+		providers: make(map[string]*lazyResource),
+		services:  make(map[string]*service),
+		metadata:  make(map[string]Metadata),
 	}
 }
 
 // Register registers a resource in the registry.
 func (r *Registry) Register(resource engine.Resource) error {
 	r.mu.Lock()
-	defer r.mu.Unlock()
-
 	slug := resource.Slug()
-	if _, exists := r.resources[slug]; exists {
+	if r.exists(slug) {
+		r.mu.Unlock()
 		return fmt.Errorf("resource '%s' already registered", slug)
 	}
-
 	r.resources[slug] = resource
+	hooks := r.hooks
+	r.mu.Unlock()
+
+	runHooks(hooks, resource)
+	return nil
+}
+
+// RegisterProvider registers a resource built on first use, for resources
+// that are expensive to construct. OnRegister hooks run when it is built.
+//
+//	registry.RegisterProvider("reports", func() engine.Resource {
+//		return NewReportResource(warehouse.Connect())
+//	})
+func (r *Registry) RegisterProvider(slug string, provider func() engine.Resource) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.exists(slug) {
+		return fmt.Errorf("resource '%s' already registered", slug)
+	}
+	r.providers[slug] = &lazyResource{provider: provider}
 	return nil
 }
 
@@ -45,13 +83,71 @@ func (r *Registry) RegisterMany(resources ...engine.Resource) error {
 	return nil
 }
 
-// Get retrieves a resource by its name.
+// Unregister removes a resource, a pending provider or a service. It reports whether
+// the slug was registered.
+func (r *Registry) Unregister(slug string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.exists(slug) {
+		return false
+	}
+	delete(r.resources, slug)
+	delete(r.providers, slug)
+	delete(r.services, slug)
+	delete(r.metadata, slug)
+	return true
+}
+
+// OnRegister adds a hook called with every resource registered afterwards,
+// and with lazy resources when they are built. Hooks run outside the
+// registry lock, so they may query the registry.
+func (r *Registry) OnRegister(hook func(engine.Resource)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hooks = append(r.hooks, hook)
+}
+
+// Get retrieves a resource by its name, building it if it was registered
+// with a provider.
 func (r *Registry) Get(name string) (engine.Resource, bool) {
 	r.mu.RLock()
-	defer r.mu.RUnlock()
-
 	resource, exists := r.resources[name]
-	return resource, exists
+	lazy := r.providers[name]
+	r.mu.RUnlock()
+
+	if exists {
+		return resource, true
+	}
+	if lazy == nil {
+		return nil, false
+	}
+	return r.build(name, lazy)
+}
+
+// build constructs a lazy resource and moves it to the resources, running
+// the hooks once.
+func (r *Registry) build(name string, lazy *lazyResource) (engine.Resource, bool) {
+	resource := lazy.get()
+	if resource == nil {
+		return nil, false
+	}
+
+	r.mu.Lock()
+	if r.providers[name] != lazy {
+		// Unregistered, or already moved by a concurrent call.
+		current, exists := r.resources[name]
+		r.mu.Unlock()
+		return current, exists
+	}
+	delete(r.providers, name)
+	r.resources[name] = resource
+	hooks := r.hooks
+	r.mu.Unlock()
+
+	runHooks(hooks, resource)
+	return resource, true
 }
 
 // Has checks if a resource exists.
@@ -59,24 +155,37 @@ func (r *Registry) Has(name string) bool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	_, exists := r.resources[name]
-	return exists
+	_, registered := r.resources[name]
+	_, lazy := r.providers[name]
+	return registered || lazy
 }
 
-// All returns all registered resources.
+// All returns all registered resources, building pending lazy resources.
 func (r *Registry) All() []engine.Resource {
+	r.mu.RLock()
+	pending := make(map[string]*lazyResource, len(r.providers))
+	for name, lazy := range r.providers {
+		pending[name] = lazy
+	}
+	r.mu.RUnlock()
+
+	for name, lazy := range pending {
+		r.build(name, lazy)
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	return lo.Values(r.resources)
 }
 
-// Names returns the names of all resources.
+// Names returns the names of all resources, including lazy ones not built
+// yet.
 func (r *Registry) Names() []string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	return lo.Keys(r.resources)
+	return append(lo.Keys(r.resources), lo.Keys(r.providers)...)
 }
 
 // Count returns the number of registered resources.
@@ -84,14 +193,11 @@ func (r *Registry) Count() int {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	return len(r.resources)
+	return len(r.resources) + len(r.providers)
 }
 
 // Filter returns resources that match the predicate.
 func (r *Registry) Filter(predicate func(engine.Resource) bool) []engine.Resource {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
 	return lo.Filter(r.All(), func(res engine.Resource, _ int) bool {
 		return predicate(res)
 	})
@@ -99,9 +205,6 @@ func (r *Registry) Filter(predicate func(engine.Resource) bool) []engine.Resourc
 
 // GroupByCategory groups resources by category.
 func (r *Registry) GroupByCategory() map[string][]engine.Resource {
-	r.mu.RLock()
-	defer r.mu.RUnlock()
-
 	return lo.GroupBy(r.All(), func(res engine.Resource) string {
 		if meta, ok := res.(interface{ Category() string }); ok {
 			return meta.Category()
@@ -110,12 +213,30 @@ func (r *Registry) GroupByCategory() map[string][]engine.Resource {
 	})
 }
 
-// Clear empties the registry (useful for tests).
+// Clear empties the registry (useful for tests). Hooks are kept.
 func (r *Registry) Clear() {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	r.resources = make(map[string]engine.Resource)
+	r.providers = make(map[string]*lazyResource)
+	r.services = make(map[string]*service)
+	r.metadata = make(map[string]Metadata)
+}
+
+// exists reports whether slug is registered, as a resource or a service.
+// The caller holds the lock.
+func (r *Registry) exists(slug string) bool {
+	_, registered := r.resources[slug]
+	_, lazy := r.providers[slug]
+	_, svc := r.services[slug]
+	return registered || lazy || svc
+}
+
+func runHooks(hooks []func(engine.Resource), resource engine.Resource) {
+	for _, hook := range hooks {
+		hook(resource)
+	}
 }
 
 // Global registry instance
@@ -131,11 +252,26 @@ func Register(resource engine.Resource) error {
 	return global.Register(resource)
 }
 
+// RegisterProvider registers a lazy resource in the global registry.
+func RegisterProvider(slug string, provider func() engine.Resource) error {
+	return global.RegisterProvider(slug, provider)
+}
+
 // RegisterMany registers multiple resources in the global registry.
 func RegisterMany(resources ...engine.Resource) error {
 	return global.RegisterMany(resources...)
 }
 
+// Unregister removes a resource from the global registry.
+func Unregister(slug string) bool {
+	return global.Unregister(slug)
+}
+
+// OnRegister adds a registration hook to the global registry.
+func OnRegister(hook func(engine.Resource)) {
+	global.OnRegister(hook)
+}
+
 // Get retrieves a resource from the global registry.
 func Get(name string) (engine.Resource, bool) {
 	return global.Get(name)
@@ -0,0 +1,89 @@
+package registry
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/bozz33/sublimeadmin/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resource(slug string) engine.Resource {
+	return engine.NewBaseResource(slug, slug, slug)
+}
+
+func TestOnRegister(t *testing.T) {
+	reg := New()
+	var registered []string
+	reg.OnRegister(func(res engine.Resource) {
+		registered = append(registered, res.Slug())
+		assert.True(t, reg.Has(res.Slug()), "hooks run after registration, outside the lock")
+	})
+
+	require.NoError(t, reg.Register(resource("users")))
+	assert.Error(t, reg.Register(resource("users")))
+	assert.Equal(t, []string{"users"}, registered)
+}
+
+func TestRegisterProvider(t *testing.T) {
+	reg := New()
+	var built atomic.Int32
+	var hooked []string
+	reg.OnRegister(func(res engine.Resource) { hooked = append(hooked, res.Slug()) })
+
+	require.NoError(t, reg.RegisterProvider("reports", func() engine.Resource {
+		built.Add(1)
+		return resource("reports")
+	}))
+	assert.Error(t, reg.RegisterProvider("reports", func() engine.Resource { return nil }))
+
+	assert.True(t, reg.Has("reports"))
+	assert.Equal(t, 1, reg.Count())
+	assert.Equal(t, []string{"reports"}, reg.Names())
+	assert.Zero(t, built.Load(), "providers are not called before first use")
+	assert.Empty(t, hooked)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, ok := reg.Get("reports")
+			assert.True(t, ok)
+			assert.Equal(t, "reports", res.Slug())
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), built.Load())
+	assert.Equal(t, []string{"reports"}, hooked)
+	assert.Len(t, reg.All(), 1)
+}
+
+func TestAllBuildsProviders(t *testing.T) {
+	reg := New()
+	require.NoError(t, reg.Register(resource("users")))
+	require.NoError(t, reg.RegisterProvider("orders", func() engine.Resource { return resource("orders") }))
+
+	assert.Len(t, reg.All(), 2)
+	res, ok := reg.Get("orders")
+	require.True(t, ok)
+	assert.Equal(t, "orders", res.Slug())
+}
+
+func TestUnregister(t *testing.T) {
+	reg := New()
+	require.NoError(t, reg.Register(resource("users")))
+	require.NoError(t, reg.RegisterProvider("orders", func() engine.Resource { return resource("orders") }))
+
+	assert.True(t, reg.Unregister("users"))
+	assert.True(t, reg.Unregister("orders"))
+	assert.False(t, reg.Unregister("users"))
+	assert.Zero(t, reg.Count())
+
+	_, ok := reg.Get("orders")
+	assert.False(t, ok)
+	require.NoError(t, reg.Register(resource("users")), "a removed slug can be registered again")
+}
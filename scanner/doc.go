@@ -0,0 +1,28 @@
+// Package scanner discovers resources in a Go module and generates the
+// provider file that registers them, so resources need no manual
+// registration.
+//
+// A resource is an exported struct type embedding engine.BaseResource (or
+// declaring a Slug method) with a New<Type>() constructor without
+// parameters. The generated file exposes:
+//
+//	func Resources() []engine.Resource
+//
+// Basic usage:
+//
+//	s, err := scanner.New(&scanner.Options{Root: "."})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	diff, err := s.Scan() // writes internal/registry/provider_gen.go
+//
+//	// Incremental mode: rescan only the packages that change
+//	err = s.Watch(ctx, func(diff *scanner.Diff, err error) {
+//		fmt.Print(diff)
+//	})
+//
+// From the CLI:
+//
+//	sublimego scan
+//	sublimego scan --watch
+package scanner
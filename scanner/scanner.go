@@ -0,0 +1,468 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Options configures the scanner.
+type Options struct {
+	// Root is the module directory to scan (default ".").
+	Root string
+	// Output is the generated file, relative to Root
+	// (default internal/registry/provider_gen.go).
+	Output string
+	// Package is the package name of the generated file (default the
+	// directory name of Output).
+	Package string
+	Verbose bool
+}
+
+// Registration is a resource found by the scanner.
+type Registration struct {
+	ImportPath  string // e.g. example.com/app/internal/resources/products
+	PackageName string // e.g. products
+	Type        string // e.g. ProductResource
+	Constructor string // e.g. NewProductResource
+}
+
+// String returns the constructor call, e.g. products.NewProductResource.
+func (r Registration) String() string {
+	return r.PackageName + "." + r.Constructor
+}
+
+// key identifies a registration across scans.
+func (r Registration) key() string {
+	return r.ImportPath + "." + r.Constructor
+}
+
+// Diff lists the registrations added and removed by a scan.
+type Diff struct {
+	Added   []Registration
+	Removed []Registration
+	// Written reports whether the generated file was (re)written.
+	Written bool
+}
+
+// Empty reports whether the scan changed no registration.
+func (d *Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// String returns one line per change: "+ products.NewProductResource".
+func (d *Diff) String() string {
+	var b strings.Builder
+	for _, r := range d.Added {
+		fmt.Fprintf(&b, "+ %s\n", r)
+	}
+	for _, r := range d.Removed {
+		fmt.Fprintf(&b, "- %s\n", r)
+	}
+	return b.String()
+}
+
+// Scanner discovers resources in a Go module and generates the provider
+// file registering them. Results are cached per package, so Rescan only
+// parses the packages that changed.
+type Scanner struct {
+	options *Options
+	root    string
+	module  string
+	output  string
+
+	mu       sync.Mutex
+	packages map[string][]Registration // directory -> registrations
+}
+
+// New creates a scanner for the module at opts.Root.
+func New(opts *Options) (*Scanner, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.Root == "" {
+		opts.Root = "."
+	}
+	if opts.Output == "" {
+		opts.Output = filepath.Join("internal", "registry", "provider_gen.go")
+	}
+
+	root, err := filepath.Abs(opts.Root)
+	if err != nil {
+		return nil, err
+	}
+	module, err := modulePath(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+	output := opts.Output
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(root, output)
+	}
+	if opts.Package == "" {
+		opts.Package = filepath.Base(filepath.Dir(output))
+	}
+
+	return &Scanner{
+		options:  opts,
+		root:     root,
+		module:   module,
+		output:   output,
+		packages: make(map[string][]Registration),
+	}, nil
+}
+
+// Output returns the path of the generated file.
+func (s *Scanner) Output() string {
+	return s.output
+}
+
+// Scan scans every package of the module and regenerates the provider file.
+func (s *Scanner) Scan() (*Diff, error) {
+	dirs, err := s.packageDirs(s.root)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.registrations()
+	s.packages = make(map[string][]Registration)
+	for _, dir := range dirs {
+		if err := s.scanDir(dir); err != nil {
+			return nil, err
+		}
+	}
+	return s.generate(previous)
+}
+
+// Rescan scans only the given package directories (changed, added or
+// removed) and regenerates the provider file when registrations changed.
+func (s *Scanner) Rescan(dirs ...string) (*Diff, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.registrations()
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		delete(s.packages, abs)
+		if err := s.scanDir(abs); err != nil {
+			return nil, err
+		}
+	}
+	return s.generate(previous)
+}
+
+// Registrations returns the registrations found, sorted by import path and
+// constructor.
+func (s *Scanner) Registrations() []Registration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.registrations()
+}
+
+func (s *Scanner) registrations() []Registration {
+	var all []Registration
+	for _, regs := range s.packages {
+		all = append(all, regs...)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].key() < all[j].key() })
+	return all
+}
+
+// packageDirs returns the directories under dir that may hold packages of
+// the module, skipping hidden, vendor, testdata and nested module dirs.
+func (s *Scanner) packageDirs(dir string) ([]string, error) {
+	var dirs []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != s.root {
+			if skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+		dirs = append(dirs, path)
+		return nil
+	})
+	return dirs, err
+}
+
+// skipDir reports whether a directory is never scanned.
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") ||
+		name == "vendor" || name == "testdata" || name == "node_modules"
+}
+
+// scanDir parses the package in dir and records its resources. The caller
+// holds s.mu.
+func (s *Scanner) scanDir(dir string) error {
+	if dir == filepath.Dir(s.output) {
+		// The generated package cannot import itself.
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return fmt.Errorf("scanner: %w", err)
+		}
+		if f.Name.Name == "main" {
+			return nil
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	rel, err := filepath.Rel(s.root, dir)
+	if err != nil {
+		return err
+	}
+	importPath := s.module
+	if rel != "." {
+		importPath += "/" + filepath.ToSlash(rel)
+	}
+
+	regs := findResources(files, importPath)
+	if s.options.Verbose {
+		for _, r := range regs {
+			fmt.Printf("   found %s\n", r)
+		}
+	}
+	if len(regs) > 0 {
+		s.packages[dir] = regs
+	}
+	return nil
+}
+
+// findResources returns the resources of a package: struct types embedding
+// BaseResource or declaring a Slug method, built by a New<Type>()
+// constructor without parameters.
+func findResources(files []*ast.File, importPath string) []Registration {
+	candidates := map[string]bool{}
+	constructors := map[string]string{} // type -> constructor
+
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range d.Specs {
+					ts, ok := spec.(*ast.TypeSpec)
+					if !ok || !ts.Name.IsExported() {
+						continue
+					}
+					if st, ok := ts.Type.(*ast.StructType); ok && embedsBaseResource(st) {
+						candidates[ts.Name.Name] = true
+					}
+				}
+			case *ast.FuncDecl:
+				if d.Recv != nil {
+					if d.Name.Name == "Slug" && len(d.Recv.List) == 1 {
+						candidates[typeName(d.Recv.List[0].Type)] = true
+					}
+					continue
+				}
+				if !strings.HasPrefix(d.Name.Name, "New") || d.Type.Params.NumFields() != 0 ||
+					d.Type.Results.NumFields() != 1 || d.Type.TypeParams != nil {
+					continue
+				}
+				result := typeName(d.Type.Results.List[0].Type)
+				if d.Name.Name == "New"+result {
+					constructors[result] = d.Name.Name
+				}
+			}
+		}
+	}
+
+	var regs []Registration
+	for typ := range candidates {
+		ctor, ok := constructors[typ]
+		if !ok || !ast.IsExported(typ) {
+			continue
+		}
+		regs = append(regs, Registration{
+			ImportPath:  importPath,
+			PackageName: files[0].Name.Name,
+			Type:        typ,
+			Constructor: ctor,
+		})
+	}
+	sort.Slice(regs, func(i, j int) bool { return regs[i].Constructor < regs[j].Constructor })
+	return regs
+}
+
+func embedsBaseResource(st *ast.StructType) bool {
+	for _, field := range st.Fields.List {
+		if len(field.Names) == 0 && typeName(field.Type) == "BaseResource" {
+			return true
+		}
+	}
+	return false
+}
+
+// typeName returns the name of a type expression without pointer and
+// package qualifier: *engine.BaseResource gives BaseResource.
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+var providerTemplate = template.Must(template.New("provider").Parse(`// Code generated by sublimego scan. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"github.com/bozz33/sublimeadmin/engine"
+{{ range .Imports }}
+	{{ .Alias }} "{{ .Path }}"
+{{- end }}
+)
+
+// Resources returns the resources discovered by sublimego scan.
+func Resources() []engine.Resource {
+	return []engine.Resource{
+{{- range .Calls }}
+		{{ . }}(),
+{{- end }}
+	}
+}
+`))
+
+// generate writes the provider file when its content changed and returns
+// the difference with previous. The caller holds s.mu.
+func (s *Scanner) generate(previous []Registration) (*Diff, error) {
+	current := s.registrations()
+	diff := diffRegistrations(previous, current)
+
+	type importSpec struct{ Alias, Path string }
+	var (
+		imports []importSpec
+		calls   []string
+	)
+	aliases := map[string]string{} // import path -> alias
+	used := map[string]bool{"engine": true}
+	for _, r := range current {
+		alias, ok := aliases[r.ImportPath]
+		if !ok {
+			alias = r.PackageName
+			for i := 2; used[alias]; i++ {
+				alias = fmt.Sprintf("%s%d", r.PackageName, i)
+			}
+			used[alias] = true
+			aliases[r.ImportPath] = alias
+			imports = append(imports, importSpec{Alias: alias, Path: r.ImportPath})
+		}
+		calls = append(calls, alias+"."+r.Constructor)
+	}
+
+	var buf bytes.Buffer
+	if err := providerTemplate.Execute(&buf, map[string]any{
+		"Package": s.options.Package,
+		"Imports": imports,
+		"Calls":   calls,
+	}); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("scanner: format provider file: %w", err)
+	}
+
+	if existing, err := os.ReadFile(s.output); err == nil && bytes.Equal(existing, src) {
+		return diff, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.output), 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(s.output, src, 0o644); err != nil {
+		return nil, err
+	}
+	diff.Written = true
+	return diff, nil
+}
+
+func diffRegistrations(previous, current []Registration) *Diff {
+	before := map[string]bool{}
+	for _, r := range previous {
+		before[r.key()] = true
+	}
+	after := map[string]bool{}
+	for _, r := range current {
+		after[r.key()] = true
+	}
+
+	diff := &Diff{}
+	for _, r := range current {
+		if !before[r.key()] {
+			diff.Added = append(diff.Added, r)
+		}
+	}
+	for _, r := range previous {
+		if !after[r.key()] {
+			diff.Removed = append(diff.Removed, r)
+		}
+	}
+	return diff
+}
+
+// modulePath reads the module path of a go.mod file.
+func modulePath(gomod string) (string, error) {
+	f, err := os.Open(gomod)
+	if err != nil {
+		return "", fmt.Errorf("scanner: %w (run the scanner from the module root)", err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if path, ok := strings.CutPrefix(line, "module "); ok {
+			return strings.Trim(strings.TrimSpace(path), `"`), nil
+		}
+	}
+	return "", fmt.Errorf("scanner: no module directive in %s", gomod)
+}
@@ -0,0 +1,151 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+const productResource = `package products
+
+import "github.com/bozz33/sublimeadmin/engine"
+
+type ProductResource struct {
+	*engine.BaseResource
+}
+
+func NewProductResource() *ProductResource {
+	return &ProductResource{BaseResource: engine.NewBaseResource("products", "Product", "Products")}
+}
+`
+
+func newModule(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/shop\n\ngo 1.24\n")
+	writeFile(t, filepath.Join(root, "internal", "resources", "products", "resource.go"), productResource)
+	writeFile(t, filepath.Join(root, "internal", "resources", "orders", "resource.go"), `package orders
+
+type OrderResource struct{}
+
+func (r *OrderResource) Slug() string { return "orders" }
+
+func NewOrderResource() *OrderResource { return &OrderResource{} }
+
+// helper has no constructor and is not registered.
+type Helper struct{}
+
+func (Helper) Slug() string { return "helper" }
+`)
+	writeFile(t, filepath.Join(root, "vendor", "x", "resource.go"), productResource)
+	return root
+}
+
+func TestScan(t *testing.T) {
+	root := newModule(t)
+	s, err := New(&Options{Root: root})
+	require.NoError(t, err)
+
+	diff, err := s.Scan()
+	require.NoError(t, err)
+	assert.True(t, diff.Written)
+	assert.Equal(t, "+ orders.NewOrderResource\n+ products.NewProductResource\n", diff.String())
+
+	src, err := os.ReadFile(filepath.Join(root, "internal", "registry", "provider_gen.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "package registry")
+	assert.Contains(t, string(src), `products "example.com/shop/internal/resources/products"`)
+	assert.Contains(t, string(src), "orders.NewOrderResource(),")
+
+	diff, err = s.Scan()
+	require.NoError(t, err)
+	assert.True(t, diff.Empty())
+	assert.False(t, diff.Written, "unchanged output is not rewritten")
+}
+
+func TestRescan(t *testing.T) {
+	root := newModule(t)
+	s, err := New(&Options{Root: root})
+	require.NoError(t, err)
+	_, err = s.Scan()
+	require.NoError(t, err)
+
+	ordersDir := filepath.Join(root, "internal", "resources", "orders")
+	require.NoError(t, os.RemoveAll(ordersDir))
+	billing := filepath.Join(root, "internal", "resources", "billing")
+	writeFile(t, filepath.Join(billing, "resource.go"), `package products
+
+import "github.com/bozz33/sublimeadmin/engine"
+
+type InvoiceResource struct{ engine.BaseResource }
+
+func NewInvoiceResource() engine.Resource { return nil }
+`)
+
+	diff, err := s.Rescan(ordersDir, billing)
+	require.NoError(t, err)
+	assert.Equal(t, "- orders.NewOrderResource\n", diff.String(), "constructors must return the resource type")
+
+	writeFile(t, filepath.Join(billing, "resource.go"), `package products
+
+import "github.com/bozz33/sublimeadmin/engine"
+
+type InvoiceResource struct{ engine.BaseResource }
+
+func NewInvoiceResource() *InvoiceResource { return &InvoiceResource{} }
+`)
+	diff, err = s.Rescan(billing)
+	require.NoError(t, err)
+	assert.Equal(t, "+ products.NewInvoiceResource\n", diff.String())
+
+	src, err := os.ReadFile(s.Output())
+	require.NoError(t, err)
+	assert.Contains(t, string(src), `products2 "example.com/shop/internal/resources/products"`, "package name clashes are aliased")
+}
+
+func TestWatch(t *testing.T) {
+	root := newModule(t)
+	s, err := New(&Options{Root: root})
+	require.NoError(t, err)
+	_, err = s.Scan()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	diffs := make(chan *Diff, 4)
+	go func() {
+		_ = s.Watch(ctx, func(diff *Diff, err error) {
+			if err == nil {
+				diffs <- diff
+			}
+		})
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	writeFile(t, filepath.Join(root, "internal", "resources", "users", "resource.go"), `package users
+
+type UserResource struct{}
+
+func (UserResource) Slug() string { return "users" }
+
+func NewUserResource() UserResource { return UserResource{} }
+`)
+
+	select {
+	case diff := <-diffs:
+		assert.Equal(t, "+ users.NewUserResource\n", diff.String())
+	case <-time.After(5 * time.Second):
+		t.Fatal("no rescan after adding a package")
+	}
+}
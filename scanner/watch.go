@@ -0,0 +1,122 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// debounce groups the events of an editor save or a git checkout.
+const debounce = 150 * time.Millisecond
+
+// Watch watches the module and rescans the packages whose Go files change,
+// calling onChange with the diff of every rescan that changed the
+// registrations or failed. It blocks until ctx is done. Call Scan first to
+// start from a complete scan.
+func (s *Scanner) Watch(ctx context.Context, onChange func(*Diff, error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	if err := s.watchTree(watcher, s.root); err != nil {
+		return err
+	}
+
+	pending := map[string]bool{}
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if dirs := s.changedDirs(watcher, event); len(dirs) > 0 {
+				for _, dir := range dirs {
+					pending[dir] = true
+				}
+				timer.Reset(debounce)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			onChange(nil, err)
+
+		case <-timer.C:
+			dirs := make([]string, 0, len(pending))
+			for dir := range pending {
+				dirs = append(dirs, dir)
+			}
+			pending = map[string]bool{}
+
+			diff, err := s.Rescan(dirs...)
+			if err != nil || !diff.Empty() {
+				onChange(diff, err)
+			}
+		}
+	}
+}
+
+// watchTree adds dir and its package directories to the watcher.
+func (s *Scanner) watchTree(watcher *fsnotify.Watcher, dir string) error {
+	dirs, err := s.packageDirs(dir)
+	if err != nil {
+		return err
+	}
+	for _, d := range dirs {
+		if err := watcher.Add(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changedDirs returns the package directories to rescan for an event.
+// New directories are watched and scanned; the generated file is ignored.
+func (s *Scanner) changedDirs(watcher *fsnotify.Watcher, event fsnotify.Event) []string {
+	if event.Name == s.output || event.Op == fsnotify.Chmod {
+		return nil
+	}
+
+	if event.Op&fsnotify.Create != 0 {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if skipDir(filepath.Base(event.Name)) {
+				return nil
+			}
+			_ = s.watchTree(watcher, event.Name)
+			dirs, _ := s.packageDirs(event.Name)
+			return dirs
+		}
+	}
+
+	name := filepath.Base(event.Name)
+	if strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+		return []string{filepath.Dir(event.Name)}
+	}
+
+	// A removed or renamed directory: rescan it, and its known packages.
+	if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 && filepath.Ext(name) == "" {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		dirs := []string{event.Name}
+		for dir := range s.packages {
+			if strings.HasPrefix(dir, event.Name+string(filepath.Separator)) {
+				dirs = append(dirs, dir)
+			}
+		}
+		return dirs
+	}
+	return nil
+}
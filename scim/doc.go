@@ -0,0 +1,28 @@
+// Package scim serves a SCIM 2.0 endpoint (RFC 7643, RFC 7644) so identity
+// providers like Okta or Azure AD provision the admin users and their
+// groups: they create, update, deactivate and delete them as people join,
+// move and leave, and assign roles through group memberships.
+//
+// The endpoint is secured by a bearer token shared with the identity
+// provider and stores its users and groups in a Store, implemented on top
+// of the user table of the application (MemoryStore keeps them in memory,
+// for development and tests).
+//
+// Features:
+//   - /Users and /Groups: list, filter (attribute eq "value"), paginate,
+//     get, create, replace, patch and delete
+//   - PATCH operations on attributes, sub-attributes and filtered values,
+//     as sent by Okta and Azure AD (members[value eq "id"])
+//   - /ServiceProviderConfig and /ResourceTypes discovery
+//   - SCIM errors: 400 invalidValue / invalidFilter, 401, 404, 409 uniqueness
+//
+// Basic usage:
+//
+//	store := scim.NewMemoryStore()
+//	mux.Handle("/scim/v2/", http.StripPrefix("/scim/v2", scim.NewServer(store, os.Getenv("SCIM_TOKEN"))))
+//
+// In a panel, WithSCIM mounts it at /scim/v2, outside the session and CSRF
+// protections:
+//
+//	panel.WithSCIM(store, os.Getenv("SCIM_TOKEN"))
+package scim
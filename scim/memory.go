@@ -0,0 +1,249 @@
+package scim
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore is an in-process Store. Roles returns the roles of its users
+// to copy them to the users of the application.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	nextID int
+	users  []*User
+	groups []*Group
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) newID() string {
+	s.nextID++
+	return strconv.Itoa(s.nextID)
+}
+
+// Roles returns the names of the groups of the user, none when unknown.
+func (s *MemoryStore) Roles(userID string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var roles []string
+	for _, g := range s.groups {
+		if slices.ContainsFunc(g.Members, func(m Ref) bool { return m.Value == userID }) {
+			roles = append(roles, g.DisplayName)
+		}
+	}
+	return roles
+}
+
+// Users implements Store.
+func (s *MemoryStore) Users(_ context.Context, filter Filter) ([]*User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var users []*User
+	for _, u := range s.users {
+		if userMatches(u, filter) {
+			users = append(users, s.withGroups(u))
+		}
+	}
+	return users, nil
+}
+
+func userMatches(u *User, f Filter) bool {
+	switch strings.ToLower(f.Attribute) {
+	case "":
+		return true
+	case "id":
+		return u.ID == f.Value
+	case "username":
+		return strings.EqualFold(u.UserName, f.Value)
+	case "externalid":
+		return u.ExternalID == f.Value
+	case "displayname":
+		return u.DisplayName == f.Value
+	case "emails.value", "emails":
+		return slices.ContainsFunc(u.Emails, func(e Email) bool { return strings.EqualFold(e.Value, f.Value) })
+	}
+	return false
+}
+
+// withGroups returns a copy of u with its groups.
+func (s *MemoryStore) withGroups(u *User) *User {
+	c := *u
+	c.Groups = nil
+	for _, g := range s.groups {
+		if slices.ContainsFunc(g.Members, func(m Ref) bool { return m.Value == u.ID }) {
+			c.Groups = append(c.Groups, Ref{Value: g.ID, Display: g.DisplayName})
+		}
+	}
+	return &c
+}
+
+// User implements Store.
+func (s *MemoryStore) User(_ context.Context, id string) (*User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := slices.IndexFunc(s.users, func(u *User) bool { return u.ID == id })
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	return s.withGroups(s.users[i]), nil
+}
+
+// CreateUser implements Store.
+func (s *MemoryStore) CreateUser(_ context.Context, u *User) (*User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if slices.ContainsFunc(s.users, func(o *User) bool { return strings.EqualFold(o.UserName, u.UserName) }) {
+		return nil, ErrConflict
+	}
+	c := *u
+	c.ID = s.newID()
+	c.Groups = nil
+	now := time.Now()
+	c.Meta = &Meta{ResourceType: "User", Created: now, LastModified: now}
+	s.users = append(s.users, &c)
+	return s.withGroups(&c), nil
+}
+
+// UpdateUser implements Store.
+func (s *MemoryStore) UpdateUser(_ context.Context, u *User) (*User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.users, func(o *User) bool { return o.ID == u.ID })
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	if slices.ContainsFunc(s.users, func(o *User) bool { return o.ID != u.ID && strings.EqualFold(o.UserName, u.UserName) }) {
+		return nil, ErrConflict
+	}
+	c := *u
+	c.Groups = nil
+	c.Meta = &Meta{ResourceType: "User", Created: s.users[i].Meta.Created, LastModified: time.Now()}
+	s.users[i] = &c
+	return s.withGroups(&c), nil
+}
+
+// DeleteUser implements Store.
+func (s *MemoryStore) DeleteUser(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.users)
+	s.users = slices.DeleteFunc(s.users, func(u *User) bool { return u.ID == id })
+	if len(s.users) == n {
+		return ErrNotFound
+	}
+	for _, g := range s.groups {
+		g.Members = slices.DeleteFunc(g.Members, func(m Ref) bool { return m.Value == id })
+	}
+	return nil
+}
+
+// Groups implements Store.
+func (s *MemoryStore) Groups(_ context.Context, filter Filter) ([]*Group, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var groups []*Group
+	for _, g := range s.groups {
+		if groupMatches(g, filter) {
+			groups = append(groups, cloneGroup(g))
+		}
+	}
+	return groups, nil
+}
+
+func groupMatches(g *Group, f Filter) bool {
+	switch strings.ToLower(f.Attribute) {
+	case "":
+		return true
+	case "id":
+		return g.ID == f.Value
+	case "displayname":
+		return strings.EqualFold(g.DisplayName, f.Value)
+	case "externalid":
+		return g.ExternalID == f.Value
+	}
+	return false
+}
+
+func cloneGroup(g *Group) *Group {
+	c := *g
+	c.Members = slices.Clone(g.Members)
+	return &c
+}
+
+// Group implements Store.
+func (s *MemoryStore) Group(_ context.Context, id string) (*Group, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	i := slices.IndexFunc(s.groups, func(g *Group) bool { return g.ID == id })
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	return cloneGroup(s.groups[i]), nil
+}
+
+// CreateGroup implements Store.
+func (s *MemoryStore) CreateGroup(_ context.Context, g *Group) (*Group, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if slices.ContainsFunc(s.groups, func(o *Group) bool { return strings.EqualFold(o.DisplayName, g.DisplayName) }) {
+		return nil, ErrConflict
+	}
+	c := cloneGroup(g)
+	c.ID = s.newID()
+	c.Members = s.members(c.Members)
+	now := time.Now()
+	c.Meta = &Meta{ResourceType: "Group", Created: now, LastModified: now}
+	s.groups = append(s.groups, c)
+	return cloneGroup(c), nil
+}
+
+// UpdateGroup implements Store.
+func (s *MemoryStore) UpdateGroup(_ context.Context, g *Group) (*Group, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.IndexFunc(s.groups, func(o *Group) bool { return o.ID == g.ID })
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+	if slices.ContainsFunc(s.groups, func(o *Group) bool { return o.ID != g.ID && strings.EqualFold(o.DisplayName, g.DisplayName) }) {
+		return nil, ErrConflict
+	}
+	c := cloneGroup(g)
+	c.Members = s.members(c.Members)
+	c.Meta = &Meta{ResourceType: "Group", Created: s.groups[i].Meta.Created, LastModified: time.Now()}
+	s.groups[i] = c
+	return cloneGroup(c), nil
+}
+
+// members returns the members of refs which are users of the store, once
+// each, with their display name.
+func (s *MemoryStore) members(refs []Ref) []Ref {
+	members := make([]Ref, 0, len(refs))
+	for _, m := range refs {
+		i := slices.IndexFunc(s.users, func(u *User) bool { return u.ID == m.Value })
+		if i < 0 || slices.ContainsFunc(members, func(o Ref) bool { return o.Value == m.Value }) {
+			continue
+		}
+		members = append(members, Ref{Value: m.Value, Display: s.users[i].FullName()})
+	}
+	return members
+}
+
+// DeleteGroup implements Store.
+func (s *MemoryStore) DeleteGroup(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.groups)
+	s.groups = slices.DeleteFunc(s.groups, func(g *Group) bool { return g.ID == id })
+	if len(s.groups) == n {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PatchOp is the body of a PATCH request.
+type PatchOp struct {
+	Schemas    []string    `json:"schemas"`
+	Operations []Operation `json:"Operations"`
+}
+
+// Operation is an operation of a PatchOp.
+type Operation struct {
+	Op    string `json:"op"`             // add, replace or remove, case-insensitive
+	Path  string `json:"path,omitempty"` // e.g. "active", "name.givenName", `members[value eq "2"]`
+	Value any    `json:"value,omitempty"`
+}
+
+// pathPattern matches attr, attr.sub, attr[sub eq "v"] and
+// attr[sub eq "v"].sub2.
+var pathPattern = regexp.MustCompile(`^([\w$]+)(?:\[\s*([\w$.]+)\s+eq\s+("[^"]*"|\S+)\s*\])?(?:\.([\w$]+))?$`)
+
+// patch applies the operations to res, a resource as decoded from JSON.
+func patch(res map[string]any, ops []Operation) error {
+	for _, op := range ops {
+		kind := strings.ToLower(op.Op)
+		if kind != "add" && kind != "replace" && kind != "remove" {
+			return invalidValue("unsupported op %q", op.Op)
+		}
+		if op.Path == "" {
+			if kind == "remove" {
+				return &Error{Status: 400, ScimType: "noTarget", Detail: "remove needs a path"}
+			}
+			values, ok := op.Value.(map[string]any)
+			if !ok {
+				return invalidValue("%s without a path needs an object value", op.Op)
+			}
+			for attr, v := range values {
+				if err := patchPath(res, kind, attr, v); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := patchPath(res, kind, op.Path, op.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func patchPath(res map[string]any, kind, path string, value any) error {
+	// Attributes of the core schema may be prefixed by its URN.
+	for _, urn := range []string{SchemaUser + ":", SchemaGroup + ":"} {
+		if len(path) > len(urn) && strings.EqualFold(path[:len(urn)], urn) {
+			path = path[len(urn):]
+		}
+	}
+	m := pathPattern.FindStringSubmatch(path)
+	if m == nil {
+		return &Error{Status: 400, ScimType: "invalidPath", Detail: fmt.Sprintf("unsupported path %q", path)}
+	}
+	attr, filterAttr, filterValue, sub := key(res, m[1]), m[2], strings.Trim(m[3], `"`), m[4]
+
+	if filterAttr == "" {
+		if sub == "" {
+			return patchValue(res, kind, attr, value)
+		}
+		nested, _ := res[attr].(map[string]any)
+		if nested == nil {
+			if kind == "remove" {
+				return nil
+			}
+			nested = map[string]any{}
+			res[attr] = nested
+		}
+		return patchValue(nested, kind, key(nested, sub), value)
+	}
+
+	// attr[filterAttr eq "filterValue"](.sub): the matching values of a
+	// multi-valued attribute.
+	values, _ := res[attr].([]any)
+	matches := func(v any) bool {
+		item, ok := v.(map[string]any)
+		return ok && fmt.Sprint(item[key(item, filterAttr)]) == filterValue
+	}
+	if kind == "remove" && sub == "" {
+		kept := values[:0]
+		for _, v := range values {
+			if !matches(v) {
+				kept = append(kept, v)
+			}
+		}
+		res[attr] = kept
+		return nil
+	}
+	found := false
+	for _, v := range values {
+		if !matches(v) {
+			continue
+		}
+		found = true
+		item := v.(map[string]any)
+		if sub == "" {
+			if obj, ok := value.(map[string]any); ok {
+				for k, vv := range obj {
+					item[key(item, k)] = vv
+				}
+			}
+			continue
+		}
+		if err := patchValue(item, kind, key(item, sub), value); err != nil {
+			return err
+		}
+	}
+	if !found && kind != "remove" && sub != "" {
+		// Azure AD sets emails[type eq "work"].value on users without one.
+		res[attr] = append(values, map[string]any{filterAttr: filterValue, sub: value})
+	}
+	return nil
+}
+
+// patchValue applies an operation on the attribute attr of res: add
+// appends to a multi-valued attribute and sets a single-valued one.
+func patchValue(res map[string]any, kind, attr string, value any) error {
+	switch kind {
+	case "remove":
+		if list, ok := res[attr].([]any); ok && value != nil {
+			// remove with a value: Azure AD removes members by value.
+			removed := toList(value)
+			kept := list[:0]
+			for _, v := range list {
+				if !containsRef(removed, v) {
+					kept = append(kept, v)
+				}
+			}
+			res[attr] = kept
+			return nil
+		}
+		delete(res, attr)
+	case "add":
+		if list, ok := res[attr].([]any); ok {
+			for _, v := range toList(value) {
+				if !containsRef(list, v) {
+					list = append(list, v)
+				}
+			}
+			res[attr] = list
+			return nil
+		}
+		res[attr] = value
+	default:
+		res[attr] = value
+	}
+	return nil
+}
+
+func toList(value any) []any {
+	if list, ok := value.([]any); ok {
+		return list
+	}
+	return []any{value}
+}
+
+// containsRef reports whether list holds v, or a value with the same
+// "value" sub-attribute.
+func containsRef(list []any, v any) bool {
+	ref, isRef := v.(map[string]any)
+	for _, o := range list {
+		if other, ok := o.(map[string]any); ok && isRef {
+			if ref["value"] != nil && fmt.Sprint(other["value"]) == fmt.Sprint(ref["value"]) {
+				return true
+			}
+			continue
+		}
+		if fmt.Sprint(o) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// key returns the key of m matching attr case-insensitively, as SCIM
+// attribute names are, else attr.
+func key(m map[string]any, attr string) string {
+	if _, ok := m[attr]; ok {
+		return attr
+	}
+	for k := range m {
+		if strings.EqualFold(k, attr) {
+			return k
+		}
+	}
+	if len(attr) > 0 {
+		// The JSON names of User and Group are camelCase.
+		return strings.ToLower(attr[:1]) + attr[1:]
+	}
+	return attr
+}
+
+// patchResource applies the operations to the resource v (a User or a
+// Group) through its JSON form.
+func patchResource[T any](v *T, ops []Operation) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var res map[string]any
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	if err := patch(res, ops); err != nil {
+		return err
+	}
+	// Azure AD sends booleans as strings ("False").
+	if s, ok := res["active"].(string); ok {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return invalidValue("active must be a boolean")
+		}
+		res["active"] = b
+	}
+	if data, err = json.Marshal(res); err != nil {
+		return err
+	}
+	var zero T
+	*v = zero
+	if err := json.Unmarshal(data, v); err != nil {
+		return invalidValue("%v", err)
+	}
+	return nil
+}
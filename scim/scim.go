@@ -0,0 +1,145 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Schema URNs of the resources and messages.
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+)
+
+var (
+	// ErrNotFound is returned by a Store when the user or group does not
+	// exist; the server answers 404.
+	ErrNotFound = errors.New("scim: resource not found")
+	// ErrConflict is returned by a Store when a user name or a group name
+	// is already taken; the server answers 409.
+	ErrConflict = errors.New("scim: resource already exists")
+)
+
+// User is a provisioned user.
+type User struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id"`
+	ExternalID  string   `json:"externalId,omitempty"` // ID of the user in the identity provider
+	UserName    string   `json:"userName"`             // usually the email
+	Name        *Name    `json:"name,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Emails      []Email  `json:"emails,omitempty"`
+	Active      bool     `json:"active"`           // false once deprovisioned: the user cannot sign in
+	Groups      []Ref    `json:"groups,omitempty"` // read-only, set by the Store from the memberships
+	Meta        *Meta    `json:"meta,omitempty"`
+}
+
+// Email returns the primary email of the user, else the first one, else
+// the user name.
+func (u *User) Email() string {
+	for _, e := range u.Emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+	return u.UserName
+}
+
+// FullName returns the display name of the user, else the formatted or
+// given and family names, else the user name.
+func (u *User) FullName() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	if u.Name != nil {
+		if u.Name.Formatted != "" {
+			return u.Name.Formatted
+		}
+		if full := strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName); full != "" {
+			return full
+		}
+	}
+	return u.UserName
+}
+
+// Name is the name of a user.
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// Email is an email of a user.
+type Email struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"` // work, home...
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// Group is a provisioned group. Groups carry the roles: a Store assigns
+// the members of a group the role of the same name.
+type Group struct {
+	Schemas     []string `json:"schemas"`
+	ID          string   `json:"id"`
+	ExternalID  string   `json:"externalId,omitempty"`
+	DisplayName string   `json:"displayName"`
+	Members     []Ref    `json:"members,omitempty"`
+	Meta        *Meta    `json:"meta,omitempty"`
+}
+
+// Ref references a user (in the members of a group) or a group (in the
+// groups of a user).
+type Ref struct {
+	Value   string `json:"value"` // ID of the resource
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// Meta is the metadata of a resource. The server sets ResourceType and
+// Location; a Store sets the dates.
+type Meta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location,omitempty"`
+}
+
+// Filter selects the resources whose attribute equals value. The zero
+// Filter selects them all. The server passes userName, externalId,
+// displayName, emails.value and id filters to the Store.
+type Filter struct {
+	Attribute string // e.g. "userName", compared case-insensitively
+	Value     string
+}
+
+// Store persists the provisioned users and groups. Implementations must be
+// safe for concurrent use, return ErrNotFound and ErrConflict, and keep the
+// groups of each user in sync with the members of the groups.
+type Store interface {
+	// Users returns the users matching filter, in a stable order.
+	Users(ctx context.Context, filter Filter) ([]*User, error)
+	User(ctx context.Context, id string) (*User, error)
+	// CreateUser stores u, setting its ID, and returns it.
+	CreateUser(ctx context.Context, u *User) (*User, error)
+	// UpdateUser replaces the user with the ID of u and returns it.
+	UpdateUser(ctx context.Context, u *User) (*User, error)
+	// DeleteUser deletes the user and removes it from its groups.
+	DeleteUser(ctx context.Context, id string) error
+
+	// Groups returns the groups matching filter, in a stable order.
+	Groups(ctx context.Context, filter Filter) ([]*Group, error)
+	Group(ctx context.Context, id string) (*Group, error)
+	CreateGroup(ctx context.Context, g *Group) (*Group, error)
+	UpdateGroup(ctx context.Context, g *Group) (*Group, error)
+	DeleteGroup(ctx context.Context, id string) error
+}
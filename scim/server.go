@@ -0,0 +1,516 @@
+package scim
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MaxResults bounds the resources of a page of a list.
+const MaxResults = 200
+
+// Error is a SCIM error response.
+type Error struct {
+	Status   int
+	ScimType string // e.g. "invalidFilter", "uniqueness"
+	Detail   string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("scim: %d %s", e.Status, e.Detail)
+}
+
+// MarshalJSON encodes the error as a SCIM error message, the status as a
+// string.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Schemas  []string `json:"schemas"`
+		Status   string   `json:"status"`
+		ScimType string   `json:"scimType,omitempty"`
+		Detail   string   `json:"detail,omitempty"`
+	}{[]string{SchemaError}, strconv.Itoa(e.Status), e.ScimType, e.Detail})
+}
+
+func invalidValue(format string, args ...any) *Error {
+	return &Error{Status: http.StatusBadRequest, ScimType: "invalidValue", Detail: fmt.Sprintf(format, args...)}
+}
+
+// ListResponse is a page of a list of resources.
+type ListResponse[T any] struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []T      `json:"Resources"`
+}
+
+// Server serves the SCIM endpoint of a Store. Mount it with the prefix of
+// the endpoint stripped:
+//
+//	mux.Handle("/scim/v2/", http.StripPrefix("/scim/v2", scim.NewServer(store, token)))
+type Server struct {
+	store Store
+	token string
+
+	// BaseURL is the public URL of the endpoint (e.g.
+	// "https://admin.example.com/scim/v2"), for the meta.location of the
+	// resources. Defaults to the URL of the request.
+	BaseURL string
+
+	// OnDeprovision, if set, is called before a user is deleted and after
+	// it is saved inactive, e.g. to end its sessions. When it fails, the
+	// server answers 500 so the identity provider retries; a user to
+	// delete is then kept.
+	OnDeprovision func(ctx context.Context, u *User) error
+}
+
+// NewServer creates a server of store accepting the requests bearing
+// token. An empty token rejects every request.
+func NewServer(store Store, token string) *Server {
+	return &Server{store: store, token: token}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="scim"`)
+		s.error(w, &Error{Status: http.StatusUnauthorized, Detail: "invalid or missing bearer token"})
+		return
+	}
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	resource, id := segments[0], ""
+	if len(segments) == 2 {
+		id = segments[1]
+	} else if len(segments) > 2 {
+		s.error(w, &Error{Status: http.StatusNotFound, Detail: "unknown endpoint"})
+		return
+	}
+
+	switch {
+	case resource == "Users" && id == "" && r.Method == http.MethodGet:
+		s.listUsers(w, r)
+	case resource == "Users" && id == "" && r.Method == http.MethodPost:
+		s.createUser(w, r)
+	case resource == "Users" && id != "":
+		s.user(w, r, id)
+	case resource == "Groups" && id == "" && r.Method == http.MethodGet:
+		s.listGroups(w, r)
+	case resource == "Groups" && id == "" && r.Method == http.MethodPost:
+		s.createGroup(w, r)
+	case resource == "Groups" && id != "":
+		s.group(w, r, id)
+	case resource == "ServiceProviderConfig" && r.Method == http.MethodGet:
+		s.write(w, http.StatusOK, serviceProviderConfig)
+	case resource == "ResourceTypes" && r.Method == http.MethodGet:
+		s.write(w, http.StatusOK, ListResponse[any]{
+			Schemas: []string{SchemaListResponse}, TotalResults: 2, StartIndex: 1, ItemsPerPage: 2,
+			Resources: []any{resourceType("User", SchemaUser), resourceType("Group", SchemaGroup)},
+		})
+	case resource == "Users" || resource == "Groups" || resource == "ServiceProviderConfig" || resource == "ResourceTypes":
+		s.error(w, &Error{Status: http.StatusMethodNotAllowed, Detail: r.Method + " not supported"})
+	default:
+		s.error(w, &Error{Status: http.StatusNotFound, Detail: "unknown endpoint"})
+	}
+}
+
+// authorized reports whether r bears the token of the server.
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && s.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// Users
+
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		s.error(w, err)
+		return
+	}
+	users, err := s.store.Users(r.Context(), filter)
+	if err != nil {
+		s.error(w, err)
+		return
+	}
+	for _, u := range users {
+		s.userMeta(r, u)
+	}
+	page, err := paginate(r, users)
+	if err != nil {
+		s.error(w, err)
+		return
+	}
+	s.write(w, http.StatusOK, page)
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	u := &User{Active: true}
+	if err := decode(r, u); err != nil {
+		s.error(w, err)
+		return
+	}
+	if err := validateUser(u); err != nil {
+		s.error(w, err)
+		return
+	}
+	u, err := s.store.CreateUser(r.Context(), u)
+	if err != nil {
+		s.error(w, err)
+		return
+	}
+	if err := s.deprovisioned(r.Context(), u); err != nil {
+		s.error(w, err)
+		return
+	}
+	s.userMeta(r, u)
+	w.Header().Set("Location", u.Meta.Location)
+	s.write(w, http.StatusCreated, u)
+}
+
+func (s *Server) user(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+	if r.Method == http.MethodDelete {
+		u, err := s.store.User(ctx, id)
+		if err == nil && s.OnDeprovision != nil {
+			err = s.OnDeprovision(ctx, u)
+		}
+		if err == nil {
+			err = s.store.DeleteUser(ctx, id)
+		}
+		if err != nil {
+			s.error(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	u, err := s.store.User(ctx, id)
+	if err != nil {
+		s.error(w, err)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		replaced := &User{Active: true}
+		if err := decode(r, replaced); err != nil {
+			s.error(w, err)
+			return
+		}
+		u = replaced
+	case http.MethodPatch:
+		var op PatchOp
+		if err := decode(r, &op); err != nil {
+			s.error(w, err)
+			return
+		}
+		if err := patchResource(u, op.Operations); err != nil {
+			s.error(w, err)
+			return
+		}
+	default:
+		s.error(w, &Error{Status: http.StatusMethodNotAllowed, Detail: r.Method + " not supported"})
+		return
+	}
+	if r.Method != http.MethodGet {
+		u.ID = id
+		if err := validateUser(u); err != nil {
+			s.error(w, err)
+			return
+		}
+		if u, err = s.store.UpdateUser(ctx, u); err != nil {
+			s.error(w, err)
+			return
+		}
+		if err := s.deprovisioned(ctx, u); err != nil {
+			s.error(w, err)
+			return
+		}
+	}
+	s.userMeta(r, u)
+	s.write(w, http.StatusOK, u)
+}
+
+// deprovisioned calls OnDeprovision for u when it is inactive.
+func (s *Server) deprovisioned(ctx context.Context, u *User) error {
+	if u.Active || s.OnDeprovision == nil {
+		return nil
+	}
+	return s.OnDeprovision(ctx, u)
+}
+
+func validateUser(u *User) error {
+	if strings.TrimSpace(u.UserName) == "" {
+		return invalidValue("userName is required")
+	}
+	return nil
+}
+
+func (s *Server) userMeta(r *http.Request, u *User) {
+	u.Schemas = []string{SchemaUser}
+	if u.Meta == nil {
+		u.Meta = &Meta{}
+	}
+	u.Meta.ResourceType = "User"
+	u.Meta.Location = s.baseURL(r) + "/Users/" + u.ID
+	for i := range u.Groups {
+		u.Groups[i].Ref = s.baseURL(r) + "/Groups/" + u.Groups[i].Value
+	}
+}
+
+// Groups
+
+func (s *Server) listGroups(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r.URL.Query().Get("filter"))
+	if err != nil {
+		s.error(w, err)
+		return
+	}
+	groups, err := s.store.Groups(r.Context(), filter)
+	if err != nil {
+		s.error(w, err)
+		return
+	}
+	excludeMembers := strings.Contains(r.URL.Query().Get("excludedAttributes"), "members")
+	for _, g := range groups {
+		s.groupMeta(r, g)
+		if excludeMembers {
+			g.Members = nil
+		}
+	}
+	page, err := paginate(r, groups)
+	if err != nil {
+		s.error(w, err)
+		return
+	}
+	s.write(w, http.StatusOK, page)
+}
+
+func (s *Server) createGroup(w http.ResponseWriter, r *http.Request) {
+	g := &Group{}
+	if err := decode(r, g); err != nil {
+		s.error(w, err)
+		return
+	}
+	if err := validateGroup(g); err != nil {
+		s.error(w, err)
+		return
+	}
+	g, err := s.store.CreateGroup(r.Context(), g)
+	if err != nil {
+		s.error(w, err)
+		return
+	}
+	s.groupMeta(r, g)
+	w.Header().Set("Location", g.Meta.Location)
+	s.write(w, http.StatusCreated, g)
+}
+
+func (s *Server) group(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+	if r.Method == http.MethodDelete {
+		if err := s.store.DeleteGroup(ctx, id); err != nil {
+			s.error(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	g, err := s.store.Group(ctx, id)
+	if err != nil {
+		s.error(w, err)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		replaced := &Group{}
+		if err := decode(r, replaced); err != nil {
+			s.error(w, err)
+			return
+		}
+		g = replaced
+	case http.MethodPatch:
+		var op PatchOp
+		if err := decode(r, &op); err != nil {
+			s.error(w, err)
+			return
+		}
+		if err := patchResource(g, op.Operations); err != nil {
+			s.error(w, err)
+			return
+		}
+	default:
+		s.error(w, &Error{Status: http.StatusMethodNotAllowed, Detail: r.Method + " not supported"})
+		return
+	}
+	if r.Method != http.MethodGet {
+		g.ID = id
+		if err := validateGroup(g); err != nil {
+			s.error(w, err)
+			return
+		}
+		if g, err = s.store.UpdateGroup(ctx, g); err != nil {
+			s.error(w, err)
+			return
+		}
+		if r.Method == http.MethodPatch {
+			// Okta and Azure AD expect no body after a membership change.
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	s.groupMeta(r, g)
+	if strings.Contains(r.URL.Query().Get("excludedAttributes"), "members") {
+		g.Members = nil
+	}
+	s.write(w, http.StatusOK, g)
+}
+
+func validateGroup(g *Group) error {
+	if strings.TrimSpace(g.DisplayName) == "" {
+		return invalidValue("displayName is required")
+	}
+	return nil
+}
+
+func (s *Server) groupMeta(r *http.Request, g *Group) {
+	g.Schemas = []string{SchemaGroup}
+	if g.Meta == nil {
+		g.Meta = &Meta{}
+	}
+	g.Meta.ResourceType = "Group"
+	g.Meta.Location = s.baseURL(r) + "/Groups/" + g.ID
+	for i := range g.Members {
+		g.Members[i].Ref = s.baseURL(r) + "/Users/" + g.Members[i].Value
+	}
+}
+
+// Helpers
+
+// filterPattern matches the only filters supported: attribute eq "value".
+var filterPattern = regexp.MustCompile(`^\s*([\w.]+)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+func parseFilter(filter string) (Filter, error) {
+	if filter == "" {
+		return Filter{}, nil
+	}
+	m := filterPattern.FindStringSubmatch(filter)
+	if m == nil {
+		return Filter{}, &Error{Status: http.StatusBadRequest, ScimType: "invalidFilter", Detail: `only 'attribute eq "value"' filters are supported`}
+	}
+	value, err := strconv.Unquote(`"` + m[2] + `"`)
+	if err != nil {
+		value = m[2]
+	}
+	return Filter{Attribute: m[1], Value: value}, nil
+}
+
+// paginate returns the page of resources of the startIndex (1-based) and
+// count parameters of r.
+func paginate[T any](r *http.Request, resources []T) (ListResponse[T], error) {
+	start, count := 1, MaxResults
+	var err error
+	if v := r.URL.Query().Get("startIndex"); v != "" {
+		if start, err = strconv.Atoi(v); err != nil {
+			return ListResponse[T]{}, invalidValue("invalid startIndex %q", v)
+		}
+		start = max(start, 1)
+	}
+	if v := r.URL.Query().Get("count"); v != "" {
+		if count, err = strconv.Atoi(v); err != nil {
+			return ListResponse[T]{}, invalidValue("invalid count %q", v)
+		}
+		count = min(max(count, 0), MaxResults)
+	}
+	from := min(start-1, len(resources))
+	page := resources[from:min(from+count, len(resources))]
+	if page == nil {
+		page = []T{}
+	}
+	return ListResponse[T]{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   start,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	}, nil
+}
+
+func decode(r *http.Request, v any) error {
+	if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, 1<<20)).Decode(v); err != nil {
+		return &Error{Status: http.StatusBadRequest, ScimType: "invalidSyntax", Detail: err.Error()}
+	}
+	return nil
+}
+
+// baseURL returns the URL of the endpoint: BaseURL, else the URL of the
+// request up to the resource type.
+func (s *Server) baseURL(r *http.Request) string {
+	if s.BaseURL != "" {
+		return strings.TrimRight(s.BaseURL, "/")
+	}
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	prefix := strings.TrimSuffix(r.RequestURI, r.URL.RequestURI())
+	return scheme + "://" + r.Host + prefix
+}
+
+func (s *Server) write(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// error writes err as a SCIM error: the Store errors as 404 and 409, the
+// other errors as 500.
+func (s *Server) error(w http.ResponseWriter, err error) {
+	var scimErr *Error
+	switch {
+	case errors.As(err, &scimErr):
+	case errors.Is(err, ErrNotFound):
+		scimErr = &Error{Status: http.StatusNotFound, Detail: "resource not found"}
+	case errors.Is(err, ErrConflict):
+		scimErr = &Error{Status: http.StatusConflict, ScimType: "uniqueness", Detail: "resource already exists"}
+	default:
+		scimErr = &Error{Status: http.StatusInternalServerError, Detail: "internal error"}
+	}
+	s.write(w, scimErr.Status, scimErr)
+}
+
+// serviceProviderConfig describes the features of the server.
+var serviceProviderConfig = map[string]any{
+	"schemas":        []string{SchemaServiceProviderConfig},
+	"patch":          map[string]bool{"supported": true},
+	"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+	"filter":         map[string]any{"supported": true, "maxResults": MaxResults},
+	"changePassword": map[string]bool{"supported": false},
+	"sort":           map[string]bool{"supported": false},
+	"etag":           map[string]bool{"supported": false},
+	"authenticationSchemes": []map[string]any{{
+		"type":        "oauthbearertoken",
+		"name":        "OAuth Bearer Token",
+		"description": "Authentication with the bearer token of the panel",
+		"primary":     true,
+	}},
+}
+
+func resourceType(name, schema string) map[string]any {
+	return map[string]any{
+		"schemas":  []string{SchemaResourceType},
+		"id":       name,
+		"name":     name,
+		"endpoint": "/" + name + "s",
+		"schema":   schema,
+	}
+}
@@ -0,0 +1,194 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const token = "s3cret"
+
+// call sends a request to a server of store and decodes the response into
+// out.
+func call(t *testing.T, store Store, method, path, body string, out any) int {
+	t.Helper()
+	req := httptest.NewRequest(method, "/scim/v2"+path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/scim+json")
+	rec := httptest.NewRecorder()
+	http.StripPrefix("/scim/v2", NewServer(store, token)).ServeHTTP(rec, req)
+	if out != nil && rec.Body.Len() > 0 {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), out), rec.Body.String())
+	}
+	return rec.Code
+}
+
+func TestServer_Auth(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/Users", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	rec := httptest.NewRecorder()
+	NewServer(NewMemoryStore(), token).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), SchemaError)
+
+	rec = httptest.NewRecorder()
+	NewServer(NewMemoryStore(), "").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/Users", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestServer_Users(t *testing.T) {
+	store := NewMemoryStore()
+
+	var ann User
+	code := call(t, store, http.MethodPost, "/Users", `{
+		"schemas": ["`+SchemaUser+`"],
+		"userName": "ann@example.com",
+		"externalId": "okta-1",
+		"name": {"givenName": "Ann", "familyName": "Lee"},
+		"emails": [{"value": "ann@example.com", "type": "work", "primary": true}]
+	}`, &ann)
+	require.Equal(t, http.StatusCreated, code)
+	assert.NotEmpty(t, ann.ID)
+	assert.True(t, ann.Active, "active defaults to true")
+	assert.Equal(t, "http://example.com/scim/v2/Users/"+ann.ID, ann.Meta.Location)
+
+	var e map[string]any
+	assert.Equal(t, http.StatusConflict, call(t, store, http.MethodPost, "/Users", `{"userName": "ANN@example.com"}`, &e))
+	assert.Equal(t, "uniqueness", e["scimType"])
+	assert.Equal(t, http.StatusBadRequest, call(t, store, http.MethodPost, "/Users", `{"name": {}}`, nil))
+
+	var list ListResponse[User]
+	require.Equal(t, http.StatusOK, call(t, store, http.MethodGet, `/Users?filter=userName%20eq%20%22ann@example.com%22`, "", &list))
+	assert.Equal(t, 1, list.TotalResults)
+	require.Equal(t, http.StatusOK, call(t, store, http.MethodGet, `/Users?filter=userName%20eq%20%22bob@example.com%22`, "", &list))
+	assert.Equal(t, 0, list.TotalResults)
+	assert.Empty(t, list.Resources)
+	assert.Equal(t, http.StatusBadRequest, call(t, store, http.MethodGet, `/Users?filter=userName%20sw%20%22a%22`, "", nil))
+
+	// Azure AD deactivates with a string and sets emails by filter.
+	var patched User
+	require.Equal(t, http.StatusOK, call(t, store, http.MethodPatch, "/Users/"+ann.ID, `{
+		"schemas": ["`+SchemaPatchOp+`"],
+		"Operations": [
+			{"op": "Replace", "path": "active", "value": "False"},
+			{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "ann.lee@example.com"},
+			{"op": "replace", "value": {"name.familyName": "Smith", "displayName": "Ann Smith"}}
+		]
+	}`, &patched))
+	assert.False(t, patched.Active)
+	assert.Equal(t, "ann.lee@example.com", patched.Email())
+	assert.Equal(t, "Smith", patched.Name.FamilyName)
+	assert.Equal(t, "Ann Smith", patched.FullName())
+
+	var replaced User
+	require.Equal(t, http.StatusOK, call(t, store, http.MethodPut, "/Users/"+ann.ID, `{"userName": "ann@example.com"}`, &replaced))
+	assert.True(t, replaced.Active)
+	assert.Nil(t, replaced.Name)
+
+	assert.Equal(t, http.StatusNoContent, call(t, store, http.MethodDelete, "/Users/"+ann.ID, "", nil))
+	assert.Equal(t, http.StatusNotFound, call(t, store, http.MethodGet, "/Users/"+ann.ID, "", nil))
+}
+
+func TestServer_OnDeprovision(t *testing.T) {
+	store := NewMemoryStore()
+	ann, err := store.CreateUser(context.Background(), &User{UserName: "ann@example.com", Active: true})
+	require.NoError(t, err)
+
+	var deprovisioned []string
+	var fail error
+	srv := NewServer(store, token)
+	srv.OnDeprovision = func(_ context.Context, u *User) error {
+		deprovisioned = append(deprovisioned, u.UserName)
+		return fail
+	}
+	send := func(method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusOK, send(http.MethodPatch, "/Users/"+ann.ID, `{"Operations": [{"op": "replace", "value": {"displayName": "Ann"}}]}`))
+	assert.Empty(t, deprovisioned, "an active user is not deprovisioned")
+
+	require.Equal(t, http.StatusOK, send(http.MethodPatch, "/Users/"+ann.ID, `{"Operations": [{"op": "replace", "path": "active", "value": false}]}`))
+	assert.Equal(t, []string{"ann@example.com"}, deprovisioned)
+
+	fail = errors.New("session store down")
+	assert.Equal(t, http.StatusInternalServerError, send(http.MethodDelete, "/Users/"+ann.ID, ""))
+	_, err = store.User(context.Background(), ann.ID)
+	assert.NoError(t, err, "the user is kept when OnDeprovision fails")
+
+	fail = nil
+	assert.Equal(t, http.StatusNoContent, send(http.MethodDelete, "/Users/"+ann.ID, ""))
+	assert.Len(t, deprovisioned, 3)
+}
+
+func TestServer_Groups(t *testing.T) {
+	store := NewMemoryStore()
+	var ann, bob User
+	call(t, store, http.MethodPost, "/Users", `{"userName": "ann@example.com"}`, &ann)
+	call(t, store, http.MethodPost, "/Users", `{"userName": "bob@example.com"}`, &bob)
+
+	var admins Group
+	require.Equal(t, http.StatusCreated, call(t, store, http.MethodPost, "/Groups",
+		`{"displayName": "admin", "members": [{"value": "`+ann.ID+`"}, {"value": "nobody"}]}`, &admins))
+	require.Len(t, admins.Members, 1, "unknown members are dropped")
+	assert.Equal(t, []string{"admin"}, store.Roles(ann.ID))
+
+	// Okta adds and removes members.
+	require.Equal(t, http.StatusNoContent, call(t, store, http.MethodPatch, "/Groups/"+admins.ID, `{
+		"schemas": ["`+SchemaPatchOp+`"],
+		"Operations": [
+			{"op": "add", "path": "members", "value": [{"value": "`+bob.ID+`"}]},
+			{"op": "remove", "path": "members[value eq \"`+ann.ID+`\"]"}
+		]
+	}`, nil))
+	assert.Empty(t, store.Roles(ann.ID))
+	assert.Equal(t, []string{"admin"}, store.Roles(bob.ID))
+
+	var u User
+	call(t, store, http.MethodGet, "/Users/"+bob.ID, "", &u)
+	require.Len(t, u.Groups, 1)
+	assert.Equal(t, "admin", u.Groups[0].Display)
+
+	var list ListResponse[Group]
+	require.Equal(t, http.StatusOK, call(t, store, http.MethodGet, `/Groups?filter=displayName%20eq%20%22admin%22&excludedAttributes=members`, "", &list))
+	require.Equal(t, 1, list.TotalResults)
+	assert.Empty(t, list.Resources[0].Members)
+
+	// Azure AD removes members by value.
+	call(t, store, http.MethodPatch, "/Groups/"+admins.ID, `{"Operations": [{"op": "Remove", "path": "members", "value": [{"value": "`+bob.ID+`"}]}]}`, nil)
+	assert.Empty(t, store.Roles(bob.ID))
+
+	assert.Equal(t, http.StatusNoContent, call(t, store, http.MethodDelete, "/Groups/"+admins.ID, "", nil))
+	assert.Equal(t, http.StatusNotFound, call(t, store, http.MethodDelete, "/Groups/"+admins.ID, "", nil))
+}
+
+func TestServer_Pagination(t *testing.T) {
+	store := NewMemoryStore()
+	for _, name := range []string{"a", "b", "c"} {
+		call(t, store, http.MethodPost, "/Users", `{"userName": "`+name+`"}`, nil)
+	}
+	var list ListResponse[User]
+	require.Equal(t, http.StatusOK, call(t, store, http.MethodGet, "/Users?startIndex=2&count=1", "", &list))
+	assert.Equal(t, 3, list.TotalResults)
+	assert.Equal(t, 2, list.StartIndex)
+	require.Len(t, list.Resources, 1)
+	assert.Equal(t, "b", list.Resources[0].UserName)
+}
+
+func TestServer_Discovery(t *testing.T) {
+	var config map[string]any
+	require.Equal(t, http.StatusOK, call(t, NewMemoryStore(), http.MethodGet, "/ServiceProviderConfig", "", &config))
+	assert.Equal(t, true, config["patch"].(map[string]any)["supported"])
+	assert.Equal(t, http.StatusNotFound, call(t, NewMemoryStore(), http.MethodGet, "/Nope", "", nil))
+}
@@ -0,0 +1,185 @@
+package search
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// maxQueries caps the queries an Analyzer dispatches for one search: the
+// query itself and its synonym rewrites.
+const maxQueries = 4
+
+// synonymWeight discounts the results found through a synonym, so the ones
+// matching the terms typed come first.
+const synonymWeight = 0.9
+
+// EnglishStopWords are common English words carrying no meaning of their
+// own in a search, for Analyzer.StopWords.
+var EnglishStopWords = []string{
+	"a", "an", "and", "are", "as", "at", "be", "by", "for", "from", "in",
+	"is", "it", "of", "on", "or", "the", "to", "with",
+}
+
+// Analyzer rewrites the queries of the global search before they are
+// dispatched to the searchables: the stop words are dropped, the synonyms
+// of the terms searched too, and small typos forgiven by Score.
+//
+//	search.Analyzer{
+//		Synonyms:    map[string][]string{"invoice": {"bill"}, "bill": {"invoice"}},
+//		StopWords:   search.EnglishStopWords,
+//		MaxDistance: 1,
+//	}
+type Analyzer struct {
+	// Synonyms maps a term (lowercase) to the terms also searched in its
+	// place, e.g. "invoice": {"bill"}. Add the reverse entry for the
+	// synonyms to work both ways.
+	Synonyms map[string][]string
+	// StopWords are dropped from the queries ("the", "of"...), unless the
+	// query has no other term.
+	StopWords []string
+	// MaxDistance is the number of typos (a letter added, missing, wrong or
+	// swapped) Score forgives in each term, 0 for none. Short terms get
+	// fewer: one per 3 letters.
+	MaxDistance int
+}
+
+// Terms returns the lowercased terms of query, its stop words dropped.
+func (a *Analyzer) Terms(query string) []string {
+	terms := strings.Fields(strings.ToLower(query))
+	if a == nil || len(a.StopWords) == 0 {
+		return terms
+	}
+	kept := slices.DeleteFunc(slices.Clone(terms), func(t string) bool {
+		return slices.ContainsFunc(a.StopWords, func(s string) bool { return strings.EqualFold(s, t) })
+	})
+	if len(kept) == 0 {
+		return terms
+	}
+	return kept
+}
+
+// Queries returns the queries to dispatch for query: the analyzed query
+// first, then its rewrites with a term replaced by one of its synonyms. A
+// nil Analyzer returns query unchanged.
+func (a *Analyzer) Queries(query string) []string {
+	if a == nil {
+		return []string{query}
+	}
+	terms := a.Terms(query)
+	if len(terms) == 0 {
+		return []string{query}
+	}
+	queries := []string{strings.Join(terms, " ")}
+	for i, t := range terms {
+		for _, syn := range a.Synonyms[t] {
+			rewrite := slices.Clone(terms)
+			rewrite[i] = strings.ToLower(syn)
+			q := strings.Join(rewrite, " ")
+			if !slices.Contains(queries, q) {
+				queries = append(queries, q)
+			}
+			if len(queries) == maxQueries {
+				return queries
+			}
+		}
+	}
+	return queries
+}
+
+// Score is CalculateScore forgiving the stop words and, up to MaxDistance,
+// the typos of query. A nil Analyzer is CalculateScore.
+func (a *Analyzer) Score(query, text string) float64 {
+	if a == nil {
+		return CalculateScore(query, text)
+	}
+	terms := a.Terms(query)
+	score := CalculateScore(strings.Join(terms, " "), text)
+	if a.MaxDistance > 0 {
+		score = max(score, a.typoScore(terms, text))
+	}
+	return score
+}
+
+// typoScore scores text when each of terms is a word of text but for a few
+// typos, lower with each typo; 0 when a term is too far from every word.
+func (a *Analyzer) typoScore(terms []string, text string) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	typos := 0
+	for _, t := range terms {
+		limit := min(a.MaxDistance, len([]rune(t))/3)
+		best := limit + 1
+		for _, w := range words {
+			best = min(best, editDistance(t, w, limit))
+		}
+		if best > limit {
+			return 0
+		}
+		typos += best
+	}
+	return max(0.7-0.05*float64(typos), 0.4)
+}
+
+// editDistance returns the number of letters to add, remove, change or
+// swap to turn a into b, limit+1 when it is more than limit.
+func editDistance(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if d := len(ra) - len(rb); d > limit || -d > limit {
+		return limit + 1
+	}
+	// Optimal string alignment distance, three rows.
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return min(prev[len(rb)], limit+1)
+}
+
+// analyzerKey is the context key of the search analyzer.
+type analyzerKey struct{}
+
+// WithAnalyzer returns a context whose searches are analyzed by a (see
+// GlobalSearch and Score).
+func WithAnalyzer(ctx context.Context, a *Analyzer) context.Context {
+	return context.WithValue(ctx, analyzerKey{}, a)
+}
+
+// AnalyzerFromContext returns the search analyzer of ctx, or nil.
+func AnalyzerFromContext(ctx context.Context) *Analyzer {
+	a, _ := ctx.Value(analyzerKey{}).(*Analyzer)
+	return a
+}
+
+// Score scores text for query with the analyzer of ctx: the searchables
+// should score their results with it rather than with CalculateScore, for
+// the typo tolerance of the panel to apply.
+func Score(ctx context.Context, query, text string) float64 {
+	return AnalyzerFromContext(ctx).Score(query, text)
+}
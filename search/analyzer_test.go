@@ -0,0 +1,75 @@
+package search_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/bozz33/sublimeadmin/search"
+)
+
+func TestAnalyzerQueries(t *testing.T) {
+	a := &search.Analyzer{
+		Synonyms:  map[string][]string{"invoice": {"bill", "receipt"}},
+		StopWords: search.EnglishStopWords,
+	}
+
+	if got := a.Queries("The Invoice of ACME"); !slices.Equal(got, []string{"invoice acme", "bill acme", "receipt acme"}) {
+		t.Errorf("queries = %q", got)
+	}
+	if got := a.Queries("the"); !slices.Equal(got, []string{"the"}) {
+		t.Errorf("a query of stop words only should be kept, got %q", got)
+	}
+	var none *search.Analyzer
+	if got := none.Queries("The Invoice"); !slices.Equal(got, []string{"The Invoice"}) {
+		t.Errorf("a nil analyzer should not rewrite the query, got %q", got)
+	}
+}
+
+func TestAnalyzerScore(t *testing.T) {
+	a := &search.Analyzer{StopWords: search.EnglishStopWords, MaxDistance: 2}
+
+	if s := a.Score("the settings", "Settings"); s != 1 {
+		t.Errorf("stop words should be ignored, score %v", s)
+	}
+	if s := a.Score("setings", "Account Settings"); s < 0.6 {
+		t.Errorf("one typo should be forgiven, score %v", s)
+	}
+	if s := a.Score("settnigs", "Settings"); s < 0.6 {
+		t.Errorf("a swap should count as one typo, score %v", s)
+	}
+	if s := a.Score("invoce", "Invoices"); s < 0.6 {
+		t.Errorf("a missing letter should be forgiven, score %v", s)
+	}
+	if s := (&search.Analyzer{MaxDistance: 2}).Score("cxt", "cow"); s != 0 {
+		t.Errorf("a 3-letter term should forgive one typo at most, score %v", s)
+	}
+	if s := (&search.Analyzer{}).Score("settnigs", "Settings"); s != 0 {
+		t.Errorf("no typo should be forgiven without MaxDistance, score %v", s)
+	}
+}
+
+func TestGlobalSearchAnalyzer(t *testing.T) {
+	search.Clear()
+	defer search.Clear()
+	var queries []string
+	search.Register(search.NewSearchable("Invoices").WithSearcher(func(ctx context.Context, query string, _ int) ([]search.Result, error) {
+		queries = append(queries, query)
+		if query != "bill" {
+			return nil, nil
+		}
+		return []search.Result{{ID: "1", Title: "Bill #1", ResourceType: "Invoices", Score: search.Score(ctx, query, "Bill #1")}}, nil
+	}))
+
+	a := &search.Analyzer{Synonyms: map[string][]string{"invoice": {"bill"}}}
+	results, err := search.GlobalSearch(search.WithAnalyzer(context.Background(), a), search.DefaultSearchOptions("Invoice"))
+	if err != nil {
+		t.Fatalf("GlobalSearch: %v", err)
+	}
+	if !slices.Equal(queries, []string{"invoice", "bill"}) {
+		t.Errorf("dispatched queries = %q", queries)
+	}
+	if len(results) != 1 || results[0].Score >= 0.95 {
+		t.Errorf("a synonym match should be found, scored below a direct match: %+v", results)
+	}
+}
@@ -0,0 +1,79 @@
+package search
+
+import (
+	"context"
+	"sort"
+)
+
+// KindNavigation is the Kind of the results taking the user to a page of
+// the panel ("Go to" results) rather than to a record.
+const KindNavigation = "navigation"
+
+// NavigationType is the ResourceType of the navigation results.
+const NavigationType = "Go to"
+
+// minCommandScore is the score below which a command does not match.
+const minCommandScore = 0.5
+
+// Command is a navigation target of the global search: a page, the list or
+// the creation form of a resource, or a custom command of the panel.
+type Command struct {
+	ID       string
+	Label    string // e.g. "Settings", "New Post"
+	Subtitle string // e.g. the navigation group
+	URL      string
+	Icon     string
+	Keywords []string // other words finding the command, e.g. "preferences"
+	// Visible reports whether the user of ctx may run the command; nil
+	// always shows it.
+	Visible func(ctx context.Context) bool
+}
+
+// MatchCommands returns the visible commands matching query as navigation
+// results, the best first. Matching a keyword scores a little below
+// matching the label. The analyzer of ctx, if any, applies.
+func MatchCommands(ctx context.Context, query string, commands []Command) []Result {
+	results := make([]Result, 0)
+	if query == "" {
+		return results
+	}
+	analyzer := AnalyzerFromContext(ctx)
+	queries := analyzer.Queries(query)
+	for _, c := range commands {
+		if c.Visible != nil && !c.Visible(ctx) {
+			continue
+		}
+		score := 0.0
+		for i, q := range queries {
+			weight := 1.0
+			if i > 0 {
+				weight = synonymWeight
+			}
+			score = max(score, analyzer.Score(q, c.Label)*weight)
+			for _, k := range c.Keywords {
+				score = max(score, analyzer.Score(q, k)*0.9*weight)
+			}
+		}
+		if score < minCommandScore {
+			continue
+		}
+		id := c.ID
+		if id == "" {
+			id = c.URL
+		}
+		results = append(results, Result{
+			ID:           "go:" + id,
+			Title:        c.Label,
+			Subtitle:     c.Subtitle,
+			URL:          c.URL,
+			Icon:         c.Icon,
+			ResourceType: NavigationType,
+			Kind:         KindNavigation,
+			Score:        score,
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
@@ -0,0 +1,36 @@
+package search_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bozz33/sublimeadmin/search"
+)
+
+func TestMatchCommands(t *testing.T) {
+	commands := []search.Command{
+		{Label: "Reports", URL: "/reports"},
+		{Label: "Settings", URL: "/settings", Keywords: []string{"preferences"}},
+		{Label: "Secrets", URL: "/secrets", Visible: func(context.Context) bool { return false }},
+	}
+
+	results := search.MatchCommands(context.Background(), "settings", commands)
+	if len(results) != 1 || results[0].URL != "/settings" || results[0].Score != 1 {
+		t.Fatalf("settings = %+v", results)
+	}
+	if results[0].Kind != search.KindNavigation || results[0].ResourceType != search.NavigationType {
+		t.Errorf("a command should be a navigation result: %+v", results[0])
+	}
+
+	results = search.MatchCommands(context.Background(), "pref", commands)
+	if len(results) != 1 || results[0].Title != "Settings" || results[0].Score >= 0.95 {
+		t.Errorf("a keyword match should score below a label match: %+v", results)
+	}
+
+	if results := search.MatchCommands(context.Background(), "secrets", commands); len(results) != 0 {
+		t.Errorf("a hidden command should not match: %+v", results)
+	}
+	if results := search.MatchCommands(context.Background(), "", commands); len(results) != 0 {
+		t.Errorf("an empty query should match nothing: %+v", results)
+	}
+}
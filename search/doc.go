@@ -42,4 +42,12 @@
 //
 //	// Perform a global search
 //	results, err := search.QuickSearch(ctx, "john")
+//
+// Searchables bound to the data of one tenant are registered in its
+// namespace with RegisterIn, and only searched from a context of that
+// namespace (WithNamespace).
+//
+// An Analyzer (WithAnalyzer or SearchOptions.Analyzer) drops the stop words
+// of the queries and dispatches the synonyms of their terms too; the
+// searchables scoring with Score also forgive its MaxDistance typos.
 package search
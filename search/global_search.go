@@ -5,8 +5,12 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/bozz33/sublimeadmin/metrics"
+	"github.com/bozz33/sublimeadmin/tracing"
 	"github.com/sahilm/fuzzy"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Result represents a single search result.
@@ -17,6 +21,7 @@ type Result struct {
 	URL          string  `json:"url"`
 	Icon         string  `json:"icon,omitempty"`
 	ResourceType string  `json:"resource_type"`
+	Kind         string  `json:"kind,omitempty"` // KindNavigation for "Go to" results, "" for records
 	Score        float64 `json:"score"`
 }
 
@@ -104,10 +109,27 @@ func (s *BaseSearchable) WithSearcher(fn func(ctx context.Context, query string,
 type Registry struct {
 	mu          sync.RWMutex
 	searchables []Searchable
+	namespaces  map[string][]Searchable // searchables of one tenant, see RegisterIn
 }
 
 var globalRegistry = &Registry{
 	searchables: make([]Searchable, 0),
+	namespaces:  make(map[string][]Searchable),
+}
+
+// namespaceKey is the context key of the search namespace.
+type namespaceKey struct{}
+
+// WithNamespace returns a context searching the namespace ns (see
+// RegisterIn), typically the ID of the current tenant.
+func WithNamespace(ctx context.Context, ns string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, ns)
+}
+
+// Namespace returns the search namespace of ctx, or "".
+func Namespace(ctx context.Context) string {
+	ns, _ := ctx.Value(namespaceKey{}).(string)
+	return ns
 }
 
 // Register registers a searchable resource.
@@ -117,27 +139,65 @@ func Register(s Searchable) {
 	globalRegistry.searchables = append(globalRegistry.searchables, s)
 }
 
-// Unregister removes a searchable by label.
+// RegisterIn registers a searchable resource in the namespace ns, e.g.
+// the resource of the panel of one tenant bound to its own database. It is
+// only searched by the searches of ctx in ns (see WithNamespace), so its
+// records never surface for another tenant. An empty ns is Register.
+func RegisterIn(ns string, s Searchable) {
+	if ns == "" {
+		Register(s)
+		return
+	}
+	globalRegistry.mu.Lock()
+	defer globalRegistry.mu.Unlock()
+	globalRegistry.namespaces[ns] = append(globalRegistry.namespaces[ns], s)
+}
+
+// UnregisterNamespace removes the searchables of the namespace ns, e.g.
+// of a deleted tenant.
+func UnregisterNamespace(ns string) {
+	globalRegistry.mu.Lock()
+	defer globalRegistry.mu.Unlock()
+	delete(globalRegistry.namespaces, ns)
+}
+
+// Unregister removes a searchable by label, from every namespace.
 func Unregister(label string) {
 	globalRegistry.mu.Lock()
 	defer globalRegistry.mu.Unlock()
 
-	filtered := make([]Searchable, 0)
-	for _, s := range globalRegistry.searchables {
-		if s.GetSearchLabel() != label {
-			filtered = append(filtered, s)
+	keep := func(list []Searchable) []Searchable {
+		filtered := make([]Searchable, 0)
+		for _, s := range list {
+			if s.GetSearchLabel() != label {
+				filtered = append(filtered, s)
+			}
 		}
+		return filtered
+	}
+	globalRegistry.searchables = keep(globalRegistry.searchables)
+	for ns, list := range globalRegistry.namespaces {
+		globalRegistry.namespaces[ns] = keep(list)
 	}
-	globalRegistry.searchables = filtered
 }
 
-// GetSearchables returns all registered searchables sorted by priority.
+// GetSearchables returns the searchables registered without namespace
+// sorted by priority.
 func GetSearchables() []Searchable {
+	return GetSearchablesIn("")
+}
+
+// GetSearchablesIn returns the searchables registered without namespace
+// and in the namespace ns, sorted by priority.
+func GetSearchablesIn(ns string) []Searchable {
 	globalRegistry.mu.RLock()
 	defer globalRegistry.mu.RUnlock()
 
 	sorted := make([]Searchable, len(globalRegistry.searchables))
 	copy(sorted, globalRegistry.searchables)
+	if ns != "" {
+		sorted = append(sorted, globalRegistry.namespaces[ns]...)
+	}
 
 	sort.Slice(sorted, func(i, j int) bool {
 		return sorted[i].GetSearchPriority() < sorted[j].GetSearchPriority()
@@ -152,6 +212,9 @@ type SearchOptions struct {
 	Limit    int
 	Types    []string // Filter by resource types (empty = all)
 	MinScore float64  // Minimum score threshold
+	// Analyzer rewrites the query before it is dispatched; nil uses the
+	// analyzer of the context, if any (see WithAnalyzer).
+	Analyzer *Analyzer
 }
 
 // DefaultSearchOptions returns default search options.
@@ -164,9 +227,26 @@ func DefaultSearchOptions(query string) *SearchOptions {
 	}
 }
 
-// GlobalSearch performs a search across all registered searchables.
+// GlobalSearch performs a search across the searchables registered without
+// namespace and in the namespace of ctx (see WithNamespace).
+// When ctx carries a trace span, the search and each searchable get their own span.
 func GlobalSearch(ctx context.Context, opts *SearchOptions) ([]Result, error) {
-	searchables := GetSearchables()
+	metrics.SearchQueries.Inc()
+	start := time.Now()
+	defer func() { metrics.SearchDuration.Observe(time.Since(start).Seconds()) }()
+
+	ctx, span := tracing.Start(ctx, "search.global", attribute.Int("search.limit", opts.Limit))
+	defer span.End()
+
+	analyzer := opts.Analyzer
+	if analyzer == nil {
+		analyzer = AnalyzerFromContext(ctx)
+	} else {
+		ctx = WithAnalyzer(ctx, analyzer)
+	}
+	queries := analyzer.Queries(opts.Query)
+
+	searchables := GetSearchablesIn(Namespace(ctx))
 
 	if len(searchables) == 0 {
 		return []Result{}, nil
@@ -207,10 +287,26 @@ func GlobalSearch(ctx context.Context, opts *SearchOptions) ([]Result, error) {
 		go func(searchable Searchable) {
 			defer wg.Done()
 
-			results, err := searchable.Search(ctx, opts.Query, perResourceLimit)
-			if err != nil {
-				return
+			sctx, sspan := tracing.Start(ctx, "search "+searchable.GetSearchLabel())
+			defer sspan.End()
+
+			var results []Result
+			for i, q := range queries {
+				found, err := searchable.Search(sctx, q, perResourceLimit)
+				if err != nil {
+					metrics.SearchErrors.WithLabelValues(searchable.GetSearchLabel()).Inc()
+					tracing.RecordError(sspan, err)
+					return
+				}
+				if i > 0 {
+					// Found through a synonym
+					for j := range found {
+						found[j].Score *= synonymWeight
+					}
+				}
+				results = append(results, found...)
 			}
+			sspan.SetAttributes(attribute.Int("search.results", len(results)))
 
 			mu.Lock()
 			allResults = append(allResults, results...)
@@ -220,6 +316,10 @@ func GlobalSearch(ctx context.Context, opts *SearchOptions) ([]Result, error) {
 
 	wg.Wait()
 
+	if len(queries) > 1 {
+		allResults = dedupResults(allResults)
+	}
+
 	// Filter by minimum score
 	if opts.MinScore > 0 {
 		filtered := make([]Result, 0)
@@ -244,6 +344,26 @@ func GlobalSearch(ctx context.Context, opts *SearchOptions) ([]Result, error) {
 	return allResults, nil
 }
 
+// dedupResults keeps the best scored of the results of the same record,
+// found by several queries.
+func dedupResults(results []Result) []Result {
+	best := make(map[string]int, len(results))
+	deduped := make([]Result, 0, len(results))
+	for _, r := range results {
+		key := r.ResourceType + "\x00" + r.ID
+		if r.ID == "" {
+			key = r.URL
+		}
+		if i, ok := best[key]; ok {
+			deduped[i].Score = max(deduped[i].Score, r.Score)
+			continue
+		}
+		best[key] = len(deduped)
+		deduped = append(deduped, r)
+	}
+	return deduped
+}
+
 // QuickSearch performs a quick search with default options.
 func QuickSearch(ctx context.Context, query string) ([]Result, error) {
 	return GlobalSearch(ctx, DefaultSearchOptions(query))
@@ -323,11 +443,16 @@ func Clear() {
 	globalRegistry.mu.Lock()
 	defer globalRegistry.mu.Unlock()
 	globalRegistry.searchables = make([]Searchable, 0)
+	globalRegistry.namespaces = make(map[string][]Searchable)
 }
 
-// Count returns the number of registered searchables.
+// Count returns the number of registered searchables, in every namespace.
 func Count() int {
 	globalRegistry.mu.RLock()
 	defer globalRegistry.mu.RUnlock()
-	return len(globalRegistry.searchables)
+	count := len(globalRegistry.searchables)
+	for _, list := range globalRegistry.namespaces {
+		count += len(list)
+	}
+	return count
 }
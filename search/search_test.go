@@ -101,3 +101,43 @@ func TestCalculateScore(t *testing.T) {
 		t.Errorf("expected 'golang' to score higher than 'python' for query 'go', got %f vs %f", score, noScore)
 	}
 }
+
+func TestGlobalSearchNamespaces(t *testing.T) {
+	search.Clear()
+	defer search.Clear()
+
+	searcher := func(title string) func(context.Context, string, int) ([]search.Result, error) {
+		return func(_ context.Context, query string, _ int) ([]search.Result, error) {
+			return []search.Result{{ID: "1", Title: title, Score: 1}}, nil
+		}
+	}
+	search.Register(search.NewSearchable("Docs").WithSearcher(searcher("shared")))
+	search.RegisterIn("acme", search.NewSearchable("Posts").WithSearcher(searcher("acme post")))
+	search.RegisterIn("globex", search.NewSearchable("Posts").WithSearcher(searcher("globex post")))
+
+	titles := func(ctx context.Context) map[string]bool {
+		results, err := search.QuickSearch(ctx, "post")
+		if err != nil {
+			t.Fatal(err)
+		}
+		seen := map[string]bool{}
+		for _, r := range results {
+			seen[r.Title] = true
+		}
+		return seen
+	}
+	if got := titles(search.WithNamespace(context.Background(), "acme")); !got["shared"] || !got["acme post"] || got["globex post"] {
+		t.Errorf("expected the shared and acme results only, got %v", got)
+	}
+	if got := titles(context.Background()); len(got) != 1 || !got["shared"] {
+		t.Errorf("expected the shared results only without namespace, got %v", got)
+	}
+
+	search.UnregisterNamespace("acme")
+	if got := titles(search.WithNamespace(context.Background(), "acme")); got["acme post"] {
+		t.Error("expected the acme namespace dropped")
+	}
+	if search.Count() != 2 {
+		t.Errorf("expected 2 searchables left, got %d", search.Count())
+	}
+}
@@ -0,0 +1,98 @@
+package table
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// fieldKey identifies a field of a struct type.
+type fieldKey struct {
+	typ  reflect.Type
+	name string
+}
+
+// fieldIndexes caches the index of the fields read by the columns, found
+// once per struct type and field name (nil when there is none).
+var fieldIndexes sync.Map // fieldKey → []int
+
+// fieldOf returns the field name of the record, a struct or a pointer to
+// one, or an invalid value.
+func fieldOf(record any, name string) reflect.Value {
+	v := reflect.ValueOf(record)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+
+	key := fieldKey{v.Type(), name}
+	index, ok := fieldIndexes.Load(key)
+	if !ok {
+		var idx []int
+		if f, found := v.Type().FieldByName(name); found {
+			idx = f.Index
+		}
+		index, _ = fieldIndexes.LoadOrStore(key, idx)
+	}
+	idx := index.([]int)
+	if idx == nil {
+		return reflect.Value{}
+	}
+	if len(idx) == 1 {
+		return v.Field(idx[0])
+	}
+	f, err := v.FieldByIndexErr(idx)
+	if err != nil {
+		// Nil embedded pointer.
+		return reflect.Value{}
+	}
+	return f
+}
+
+// fieldString returns the field name of the record as text, or "".
+func fieldString(record any, name string) string {
+	f := fieldOf(record, name)
+	if !f.IsValid() || !f.CanInterface() {
+		return ""
+	}
+	// Common kinds without fmt, unless they format themselves.
+	if f.Type().PkgPath() == "" {
+		switch f.Kind() {
+		case reflect.String:
+			return f.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return strconv.FormatInt(f.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return strconv.FormatUint(f.Uint(), 10)
+		case reflect.Bool:
+			return strconv.FormatBool(f.Bool())
+		}
+	}
+	return fmt.Sprintf("%v", f.Interface())
+}
+
+// Typed adapts a typed accessor to the ValueFunc of a column, reading the
+// records without reflection:
+//
+//	table.Text("Email").Using(table.Typed(func(u *User) string { return u.Email }))
+//
+// Records of another type give "".
+func Typed[T any](fn func(*T) string) func(item any) string {
+	return func(item any) string {
+		switch r := item.(type) {
+		case *T:
+			if r != nil {
+				return fn(r)
+			}
+		case T:
+			return fn(&r)
+		}
+		return ""
+	}
+}
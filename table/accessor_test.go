@@ -0,0 +1,99 @@
+package table
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type embeddedBase struct {
+	CreatedBy string
+}
+
+type accessorRecord struct {
+	*embeddedBase
+	Name   string
+	Count  int
+	Status status
+	secret string
+}
+
+type status string
+
+func (s status) String() string { return "status:" + string(s) }
+
+func TestFieldString(t *testing.T) {
+	rec := &accessorRecord{embeddedBase: &embeddedBase{CreatedBy: "jane"}, Name: "Widget", Count: 3, Status: "on", secret: "x"}
+
+	tests := []struct {
+		record any
+		field  string
+		want   string
+	}{
+		{rec, "Name", "Widget"},
+		{*rec, "Count", "3"},
+		{rec, "Status", "status:on"},
+		{rec, "CreatedBy", "jane"},
+		{&accessorRecord{}, "CreatedBy", ""},
+		{rec, "secret", ""},
+		{rec, "Missing", ""},
+		{(*accessorRecord)(nil), "Name", ""},
+		{"not a struct", "Name", ""},
+		{&testRecord{Name: "other type"}, "Name", "other type"},
+	}
+	for _, tt := range tests {
+		if got := fieldString(tt.record, tt.field); got != tt.want {
+			t.Errorf("fieldString(%T, %s) = %q, want %q", tt.record, tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestTyped(t *testing.T) {
+	name := Typed(func(r *testRecord) string { return r.Name })
+
+	if got := name(&testRecord{Name: "ptr"}); got != "ptr" {
+		t.Errorf("got %q", got)
+	}
+	if got := name(testRecord{Name: "value"}); got != "value" {
+		t.Errorf("got %q", got)
+	}
+	if got := name((*testRecord)(nil)); got != "" {
+		t.Errorf("nil record: got %q", got)
+	}
+	if got := name(&accessorRecord{Name: "other"}); got != "" {
+		t.Errorf("other type: got %q", got)
+	}
+
+	col := Text("Name").Using(name)
+	if got := col.Value(&testRecord{Name: "column"}); got != "column" {
+		t.Errorf("column value = %q", got)
+	}
+}
+
+// BenchmarkColumnValue compares reading a cell by field name on every call,
+// with the cached field index, and with a typed accessor.
+func BenchmarkColumnValue(b *testing.B) {
+	rec := &testRecord{ID: 42, Name: "Jane Doe", Email: "jane@example.com", Status: "active"}
+
+	b.Run("FieldByName", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			v := reflect.ValueOf(rec).Elem()
+			_ = fmt.Sprintf("%v", v.FieldByName("Email").Interface())
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		col := Text("Email")
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = col.Value(rec)
+		}
+	})
+	b.Run("Typed", func(b *testing.B) {
+		col := Text("Email").Using(Typed(func(r *testRecord) string { return r.Email }))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = col.Value(rec)
+		}
+	})
+}
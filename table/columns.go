@@ -18,6 +18,7 @@ type TextColumn struct {
 	LabelStr     string
 	SortableFlag bool
 	SearchFlag   bool
+	InlineFlag   bool // search input under the column header
 	CopyFlag     bool
 	ValueFunc    func(item any) string // optional: replaces reflect-based lookup
 	// Filament-inspired enrichments
@@ -98,6 +99,13 @@ func (c *TextColumn) Searchable() *TextColumn {
 	return c
 }
 
+// SearchableInline adds a search input under the column header, filtering
+// the rows whose value contains the text typed (see InlineSearchable).
+func (c *TextColumn) SearchableInline() *TextColumn {
+	c.InlineFlag = true
+	return c
+}
+
 // Copyable makes the column copyable.
 func (c *TextColumn) Copyable() *TextColumn {
 	c.CopyFlag = true
@@ -189,6 +197,9 @@ func (c *TextColumn) Type() string       { return "text" }
 func (c *TextColumn) IsSortable() bool   { return c.SortableFlag }
 func (c *TextColumn) IsSearchable() bool { return c.SearchFlag }
 func (c *TextColumn) IsCopyable() bool   { return c.CopyFlag }
+
+// IsSearchableInline implements InlineSearchable.
+func (c *TextColumn) IsSearchableInline() bool { return c.InlineFlag }
 func (c *TextColumn) Render(value string, record any) templ.Component {
 	v := applyTextTransforms(value, c)
 	color := c.ColorEval.Resolve(v, record)
@@ -308,29 +319,13 @@ func formatIntWithSep(n int64, sep string) string {
 
 // extractField extracts a string field from a struct by field name using reflection.
 func extractField(record any, field string) string {
-	v := reflect.ValueOf(record)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	f := v.FieldByName(field)
-	if f.IsValid() {
-		return fmt.Sprintf("%v", f.Interface())
-	}
-	return ""
+	return fieldString(record, field)
 }
 func (c *TextColumn) Value(item any) string {
 	if c.ValueFunc != nil {
 		return c.ValueFunc(item)
 	}
-	v := reflect.ValueOf(item)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	field := v.FieldByName(c.colKey)
-	if field.IsValid() {
-		return fmt.Sprintf("%v", field.Interface())
-	}
-	return ""
+	return fieldString(item, c.colKey)
 }
 
 // BadgeColumn represents a badge column.
@@ -338,6 +333,7 @@ type BadgeColumn struct {
 	colKey       string
 	LabelStr     string
 	SortableFlag bool
+	InlineFlag   bool // search input under the column header
 	ColorMap     map[string]string
 	ValueFunc    func(item any) string // optional: replaces reflect-based lookup
 	IconFunc     func(string) string   // returns Material icon name based on value
@@ -364,6 +360,13 @@ func (c *BadgeColumn) Sortable() *BadgeColumn {
 	return c
 }
 
+// SearchableInline adds a search input under the column header (see
+// InlineSearchable).
+func (c *BadgeColumn) SearchableInline() *BadgeColumn {
+	c.InlineFlag = true
+	return c
+}
+
 // Using sets a custom accessor function, bypassing reflection.
 func (c *BadgeColumn) Using(fn func(item any) string) *BadgeColumn {
 	c.ValueFunc = fn
@@ -397,6 +400,9 @@ func (c *BadgeColumn) Type() string       { return "badge" }
 func (c *BadgeColumn) IsSortable() bool   { return c.SortableFlag }
 func (c *BadgeColumn) IsSearchable() bool { return false }
 func (c *BadgeColumn) IsCopyable() bool   { return false }
+
+// IsSearchableInline implements InlineSearchable.
+func (c *BadgeColumn) IsSearchableInline() bool { return c.InlineFlag }
 func (c *BadgeColumn) Render(value string, _ any) templ.Component {
 	return BadgeCellView(value, c.GetColor(value))
 }
@@ -404,15 +410,7 @@ func (c *BadgeColumn) Value(item any) string {
 	if c.ValueFunc != nil {
 		return c.ValueFunc(item)
 	}
-	v := reflect.ValueOf(item)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	field := v.FieldByName(c.colKey)
-	if field.IsValid() {
-		return fmt.Sprintf("%v", field.Interface())
-	}
-	return ""
+	return fieldString(item, c.colKey)
 }
 
 // ImageColumn represents an image column.
@@ -489,15 +487,7 @@ func (c *ImageColumn) Value(item any) string {
 	if c.ValueFunc != nil {
 		return c.ValueFunc(item)
 	}
-	v := reflect.ValueOf(item)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	field := v.FieldByName(c.colKey)
-	if field.IsValid() {
-		return fmt.Sprintf("%v", field.Interface())
-	}
-	return ""
+	return fieldString(item, c.colKey)
 }
 
 // BooleanColumn displays a boolean value as a ✓ or ✗ icon.
@@ -599,11 +589,7 @@ func (c *BooleanColumn) Value(item any) string {
 	if c.ValueFunc != nil {
 		return c.ValueFunc(item)
 	}
-	v := reflect.ValueOf(item)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	field := v.FieldByName(c.colKey)
+	field := fieldOf(item, c.colKey)
 	if !field.IsValid() {
 		return c.FalseLabel
 	}
@@ -685,11 +671,7 @@ func (c *DateColumn) Value(item any) string {
 	if c.ValueFunc != nil {
 		return c.ValueFunc(item)
 	}
-	v := reflect.ValueOf(item)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	field := v.FieldByName(c.colKey)
+	field := fieldOf(item, c.colKey)
 	if !field.IsValid() {
 		return ""
 	}
@@ -758,15 +740,7 @@ func (c *AvatarColumn) Value(item any) string {
 	if c.ValueFunc != nil {
 		return c.ValueFunc(item)
 	}
-	v := reflect.ValueOf(item)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	field := v.FieldByName(c.colKey)
-	if field.IsValid() {
-		return fmt.Sprintf("%v", field.Interface())
-	}
-	return ""
+	return fieldString(item, c.colKey)
 }
 func (c *AvatarColumn) Render(value string, _ any) templ.Component {
 	initials := avatarCellInitials(value)
@@ -831,15 +805,7 @@ func (c *IconColumn) Value(item any) string {
 	if c.ValueFunc != nil {
 		return c.ValueFunc(item)
 	}
-	v := reflect.ValueOf(item)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	field := v.FieldByName(c.colKey)
-	if field.IsValid() {
-		return fmt.Sprintf("%v", field.Interface())
-	}
-	return ""
+	return fieldString(item, c.colKey)
 }
 func (c *IconColumn) Render(value string, record any) templ.Component {
 	color := c.ColorEval.Resolve(value, record)
@@ -891,20 +857,103 @@ func (c *ColorColumn) Value(item any) string {
 	if c.ValueFunc != nil {
 		return c.ValueFunc(item)
 	}
-	v := reflect.ValueOf(item)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	field := v.FieldByName(c.colKey)
-	if field.IsValid() {
-		return fmt.Sprintf("%v", field.Interface())
-	}
-	return ""
+	return fieldString(item, c.colKey)
 }
 func (c *ColorColumn) Render(value string, _ any) templ.Component {
 	return ColorCellView(value)
 }
 
+// ---------------------------------------------------------------------------
+// RelationColumn — related records (loaded by the relation loader)
+// ---------------------------------------------------------------------------
+
+// RelationColumn displays the related records of the field key of the
+// record, a record or a slice of records, e.g. as set by the eager
+// loading of the relations (see engine.EagerLoad).
+type RelationColumn struct {
+	colKey       string
+	LabelStr     string
+	DisplayField string // field shown for each record (default: Name, Title)
+	LimitItems   int    // records shown before "+N" (0 = all)
+}
+
+// Relation creates a new relation column.
+func Relation(key string) *RelationColumn {
+	return &RelationColumn{colKey: key, LabelStr: key}
+}
+
+// WithLabel sets the column label.
+func (c *RelationColumn) WithLabel(label string) *RelationColumn {
+	c.LabelStr = label
+	return c
+}
+
+// Display sets the field shown for each related record.
+func (c *RelationColumn) Display(field string) *RelationColumn {
+	c.DisplayField = field
+	return c
+}
+
+// Limit shows at most n related records, then how many more there are.
+func (c *RelationColumn) Limit(n int) *RelationColumn {
+	c.LimitItems = n
+	return c
+}
+
+func (c *RelationColumn) Key() string        { return c.colKey }
+func (c *RelationColumn) Label() string      { return c.LabelStr }
+func (c *RelationColumn) Type() string       { return "relation" }
+func (c *RelationColumn) IsSortable() bool   { return false }
+func (c *RelationColumn) IsSearchable() bool { return false }
+func (c *RelationColumn) IsCopyable() bool   { return false }
+
+// Value returns the labels of the related records, separated by commas.
+func (c *RelationColumn) Value(item any) string {
+	f := fieldOf(item, c.colKey)
+	if f.IsValid() && f.Kind() == reflect.Interface && !f.IsNil() {
+		f = f.Elem()
+	}
+	if !f.IsValid() || !f.CanInterface() || (f.Kind() == reflect.Ptr || f.Kind() == reflect.Interface) && f.IsNil() {
+		return ""
+	}
+	var related []any
+	if f.Kind() == reflect.Slice || f.Kind() == reflect.Array {
+		for i := range f.Len() {
+			related = append(related, f.Index(i).Interface())
+		}
+	} else {
+		related = []any{f.Interface()}
+	}
+
+	labels := make([]string, 0, len(related))
+	for i, r := range related {
+		if c.LimitItems > 0 && i == c.LimitItems {
+			labels = append(labels, fmt.Sprintf("+%d", len(related)-i))
+			break
+		}
+		labels = append(labels, c.relatedLabel(r))
+	}
+	return strings.Join(labels, ", ")
+}
+
+// relatedLabel returns the label of a related record.
+func (c *RelationColumn) relatedLabel(related any) string {
+	fields := []string{"Name", "Title"}
+	if c.DisplayField != "" {
+		fields = []string{c.DisplayField}
+	}
+	for _, field := range fields {
+		if f := fieldOf(related, field); f.IsValid() {
+			return fieldString(related, field)
+		}
+	}
+	return fmt.Sprintf("%v", related)
+}
+
+func (c *RelationColumn) Render(value string, _ any) templ.Component {
+	return TextCellView(value, "", "")
+}
+
 // ---------------------------------------------------------------------------
 // Inline editing columns — render as interactive inputs in table cells.
 // When changed, they fire a Datastar @patch() to save the value server-side.
@@ -946,15 +995,7 @@ func (c *TextInputColumn) Value(item any) string {
 	if c.ValueFunc != nil {
 		return c.ValueFunc(item)
 	}
-	v := reflect.ValueOf(item)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	field := v.FieldByName(c.colKey)
-	if field.IsValid() {
-		return fmt.Sprintf("%v", field.Interface())
-	}
-	return ""
+	return fieldString(item, c.colKey)
 }
 func (c *TextInputColumn) Render(value string, record any) templ.Component {
 	patchURL := ""
@@ -1010,15 +1051,7 @@ func (c *SelectColumn) Value(item any) string {
 	if c.ValueFunc != nil {
 		return c.ValueFunc(item)
 	}
-	v := reflect.ValueOf(item)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	field := v.FieldByName(c.colKey)
-	if field.IsValid() {
-		return fmt.Sprintf("%v", field.Interface())
-	}
-	return ""
+	return fieldString(item, c.colKey)
 }
 func (c *SelectColumn) Render(value string, record any) templ.Component {
 	patchURL := ""
@@ -1061,15 +1094,7 @@ func (c *ToggleColumn) Value(item any) string {
 	if c.ValueFunc != nil {
 		return c.ValueFunc(item)
 	}
-	v := reflect.ValueOf(item)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	field := v.FieldByName(c.colKey)
-	if field.IsValid() {
-		return fmt.Sprintf("%v", field.Interface())
-	}
-	return ""
+	return fieldString(item, c.colKey)
 }
 func (c *ToggleColumn) Render(value string, record any) templ.Component {
 	patchURL := ""
@@ -1113,15 +1138,7 @@ func (c *CheckboxColumn) Value(item any) string {
 	if c.ValueFunc != nil {
 		return c.ValueFunc(item)
 	}
-	v := reflect.ValueOf(item)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	field := v.FieldByName(c.colKey)
-	if field.IsValid() {
-		return fmt.Sprintf("%v", field.Interface())
-	}
-	return ""
+	return fieldString(item, c.colKey)
 }
 func (c *CheckboxColumn) Render(value string, record any) templ.Component {
 	patchURL := ""
@@ -1190,15 +1207,7 @@ func (c *TagsColumn) Value(item any) string {
 	if c.ValueFunc != nil {
 		return c.ValueFunc(item)
 	}
-	v := reflect.ValueOf(item)
-	if v.Kind() == reflect.Ptr {
-		v = v.Elem()
-	}
-	field := v.FieldByName(c.colKey)
-	if field.IsValid() {
-		return fmt.Sprintf("%v", field.Interface())
-	}
-	return ""
+	return fieldString(item, c.colKey)
 }
 
 func (c *TagsColumn) Render(value string, _ any) templ.Component {
@@ -78,6 +78,18 @@ func TestText_IsSearchable_after_Searchable(t *testing.T) {
 	}
 }
 
+func TestSearchesInline(t *testing.T) {
+	if SearchesInline(Text("name")) || HasInlineSearch([]Column{Text("name"), Image("avatar")}) {
+		t.Error("expected no inline search by default")
+	}
+	if !SearchesInline(Text("name").SearchableInline()) || !SearchesInline(Badge("status").SearchableInline()) {
+		t.Error("expected SearchesInline()=true after SearchableInline()")
+	}
+	if !HasInlineSearch([]Column{Image("avatar"), Badge("status").SearchableInline()}) {
+		t.Error("expected HasInlineSearch()=true with a column searchable inline")
+	}
+}
+
 func TestText_IsCopyable_default(t *testing.T) {
 	col := Text("name")
 	if col.IsCopyable() {
@@ -546,3 +558,31 @@ func TestText_Wrap_flag(t *testing.T) {
 		t.Error("expected Wrap=true after WithWrap()")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// RelationColumn tests
+// ---------------------------------------------------------------------------
+
+type relationRecord struct {
+	Owner *testRecord
+	Tags  []testRecord
+}
+
+func TestRelation_Value(t *testing.T) {
+	rec := relationRecord{
+		Owner: &testRecord{Name: "Alice", Email: "alice@example.com"},
+		Tags:  []testRecord{{Name: "go"}, {Name: "web"}, {Name: "admin"}},
+	}
+	if got := Relation("Owner").Value(rec); got != "Alice" {
+		t.Errorf("expected 'Alice', got '%s'", got)
+	}
+	if got := Relation("Owner").Display("Email").Value(&rec); got != "alice@example.com" {
+		t.Errorf("expected the email, got '%s'", got)
+	}
+	if got := Relation("Tags").Limit(2).Value(rec); got != "go, web, +1" {
+		t.Errorf("expected 'go, web, +1', got '%s'", got)
+	}
+	if got := Relation("Owner").Value(relationRecord{}); got != "" {
+		t.Errorf("expected '' without owner, got '%s'", got)
+	}
+}
@@ -6,7 +6,7 @@
 //
 // Features:
 //   - Fluent column builder API
-//   - Multiple column types (text, badge, boolean, date, etc.)
+//   - Multiple column types (text, badge, boolean, date, relation, etc.)
 //   - Sortable columns
 //   - Filters and search
 //   - Pagination
@@ -1,5 +1,7 @@
 package table
 
+import "context"
+
 // SelectFilter represents a select type filter
 type SelectFilter struct {
 	filterKey string
@@ -65,6 +67,39 @@ func (f *BooleanFilter) FilterOptions() []FilterOption {
 	}
 }
 
+// MultiSelectFilter represents a filter matching any of the options checked.
+type MultiSelectFilter struct {
+	filterKey string
+	LabelStr  string
+	Options   []FilterOption
+}
+
+// MultiSelect creates a new multi-select filter.
+func MultiSelect(key string) *MultiSelectFilter {
+	return &MultiSelectFilter{
+		filterKey: key,
+		LabelStr:  key,
+		Options:   make([]FilterOption, 0),
+	}
+}
+
+// WithLabel sets the filter label.
+func (f *MultiSelectFilter) WithLabel(label string) *MultiSelectFilter {
+	f.LabelStr = label
+	return f
+}
+
+// WithOptions sets the filter options.
+func (f *MultiSelectFilter) WithOptions(options []FilterOption) *MultiSelectFilter {
+	f.Options = options
+	return f
+}
+
+func (f *MultiSelectFilter) Key() string                   { return f.filterKey }
+func (f *MultiSelectFilter) Label() string                 { return f.LabelStr }
+func (f *MultiSelectFilter) Type() string                  { return "multiselect" }
+func (f *MultiSelectFilter) FilterOptions() []FilterOption { return f.Options }
+
 // DateFilter represents a date range filter with from/until inputs.
 type DateFilter struct {
 	filterKey string
@@ -90,6 +125,96 @@ func (f *DateFilter) Label() string                 { return f.LabelStr }
 func (f *DateFilter) Type() string                  { return "date" }
 func (f *DateFilter) FilterOptions() []FilterOption { return nil }
 
+// NumberFilter represents a numeric range filter with min/max inputs.
+type NumberFilter struct {
+	filterKey string
+	LabelStr  string
+	Step      string // step of the inputs, e.g. "0.01" (default "any")
+}
+
+// NumberRange creates a new numeric range filter.
+func NumberRange(key string) *NumberFilter {
+	return &NumberFilter{
+		filterKey: key,
+		LabelStr:  key,
+		Step:      "any",
+	}
+}
+
+// WithLabel sets the filter label.
+func (f *NumberFilter) WithLabel(label string) *NumberFilter {
+	f.LabelStr = label
+	return f
+}
+
+// WithStep sets the step of the inputs.
+func (f *NumberFilter) WithStep(step string) *NumberFilter {
+	f.Step = step
+	return f
+}
+
+func (f *NumberFilter) Key() string                   { return f.filterKey }
+func (f *NumberFilter) Label() string                 { return f.LabelStr }
+func (f *NumberFilter) Type() string                  { return "number" }
+func (f *NumberFilter) FilterOptions() []FilterOption { return nil }
+
+// RelationFilter represents a filter on a foreign key, e.g. "author_id",
+// choosing among the related records.
+type RelationFilter struct {
+	filterKey   string
+	LabelStr    string
+	Options     []FilterOption
+	OptionsFunc func(ctx context.Context) []FilterOption // loads the related records on each request
+	Multi       bool                                     // match any of several records
+}
+
+// RelationSelect creates a new relation filter.
+func RelationSelect(key string) *RelationFilter {
+	return &RelationFilter{
+		filterKey: key,
+		LabelStr:  key,
+		Options:   make([]FilterOption, 0),
+	}
+}
+
+// WithLabel sets the filter label.
+func (f *RelationFilter) WithLabel(label string) *RelationFilter {
+	f.LabelStr = label
+	return f
+}
+
+// WithOptions sets the related records, their ID as Value.
+func (f *RelationFilter) WithOptions(options []FilterOption) *RelationFilter {
+	f.Options = options
+	return f
+}
+
+// WithOptionsFunc sets the function loading the related records, their ID
+// as Value, on each request.
+func (f *RelationFilter) WithOptionsFunc(fn func(ctx context.Context) []FilterOption) *RelationFilter {
+	f.OptionsFunc = fn
+	return f
+}
+
+// Multiple lets the user pick several records.
+func (f *RelationFilter) Multiple() *RelationFilter {
+	f.Multi = true
+	return f
+}
+
+// OptionsFor returns the related records for the request of ctx.
+func (f *RelationFilter) OptionsFor(ctx context.Context) []FilterOption {
+	if f.OptionsFunc != nil {
+		return f.OptionsFunc(ctx)
+	}
+	return f.Options
+}
+
+func (f *RelationFilter) Key() string                   { return f.filterKey }
+func (f *RelationFilter) Label() string                 { return f.LabelStr }
+func (f *RelationFilter) Type() string                  { return "relation" }
+func (f *RelationFilter) FilterOptions() []FilterOption { return f.Options }
+
 // TextFilter represents a free-text search filter on a specific field.
 type TextFilter struct {
 	filterKey string
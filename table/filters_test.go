@@ -1,6 +1,7 @@
 package table
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -27,3 +28,20 @@ func TestTableWithFiltersIntegration(t *testing.T) {
 	assert.Equal(t, "select", tbl.Filters[0].Type())
 	assert.Equal(t, "boolean", tbl.Filters[1].Type())
 }
+
+func TestRangeAndMultiFilters(t *testing.T) {
+	assert.Equal(t, "multiselect", MultiSelect("tags").Type())
+	assert.Equal(t, "number", NumberRange("total").Type())
+	assert.Equal(t, "any", NumberRange("total").Step)
+	assert.Equal(t, "0.01", NumberRange("total").WithStep("0.01").Step)
+}
+
+func TestRelationFilterOptions(t *testing.T) {
+	f := RelationSelect("author_id").WithLabel("Author").WithOptions([]FilterOption{{Value: "1", Label: "Ann"}})
+	assert.Equal(t, "relation", f.Type())
+	assert.Equal(t, "Ann", f.OptionsFor(context.Background())[0].Label)
+
+	f.WithOptionsFunc(func(context.Context) []FilterOption { return []FilterOption{{Value: "2", Label: "Bob"}} }).Multiple()
+	assert.True(t, f.Multi)
+	assert.Equal(t, "Bob", f.OptionsFor(context.Background())[0].Label)
+}
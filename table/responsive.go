@@ -0,0 +1,36 @@
+package table
+
+// Breakpoint is a screen width of the responsive layout, named after the
+// Tailwind screens.
+type Breakpoint string
+
+const (
+	BreakpointSM    Breakpoint = "sm"    // 640px
+	BreakpointMD    Breakpoint = "md"    // 768px (default)
+	BreakpointLG    Breakpoint = "lg"    // 1024px
+	BreakpointNever Breakpoint = "never" // always render a table
+)
+
+// StackClass returns the class collapsing a table into stacked cards below
+// bp (see custom.css), or "" for BreakpointNever. An empty bp means md.
+func StackClass(bp Breakpoint) string {
+	switch bp {
+	case BreakpointNever:
+		return ""
+	case BreakpointSM, BreakpointLG:
+		return "table-stack-" + string(bp)
+	default:
+		return "table-stack-md"
+	}
+}
+
+// PrimaryColumnIndex returns the index of the column with the given key,
+// which titles the stacked cards, or 0 (the first column).
+func PrimaryColumnIndex(cols []Column, key string) int {
+	for i, col := range cols {
+		if col.Key() == key {
+			return i
+		}
+	}
+	return 0
+}
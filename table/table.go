@@ -20,25 +20,28 @@ type TableTab struct {
 
 // Table represents the complete configuration of a table.
 type Table struct {
-	Columns      []Column
-	Rows         []any
-	Actions      []*actions.Action
-	ActionGroups []*actions.ActionGroup // dropdown groups of actions (rendered after individual actions)
-	BulkActions  []*BulkAction
-	Filters      []Filter
-	Summaries    []Summary
-	Groups       []Grouping
-	Tabs         []TableTab // filter preset tabs
-	Searchable   bool
-	Pagination   bool
-	PerPage      int
-	BaseURL      string
-	Striped      bool // alternate row background colors
-	Deferred     bool // lazy-load rows after initial page render
-	EmptyHeading string          // custom empty state heading (default: "Aucun résultat trouvé")
-	EmptyDesc    string          // custom empty state description
-	EmptyIcon    string          // custom Material icon for empty state (default: "inbox")
-	RecordUrlFn  func(any) string // optional: custom URL per row (overrides default /{slug}/{id})
+	Columns       []Column
+	Rows          []any
+	Actions       []*actions.Action
+	ActionGroups  []*actions.ActionGroup // dropdown groups of actions (rendered after individual actions)
+	BulkActions   []*BulkAction
+	Filters       []Filter
+	Summaries     []Summary
+	Groups        []Grouping
+	Tabs          []TableTab // filter preset tabs
+	Searchable    bool
+	Pagination    bool
+	PerPage       int
+	BaseURL       string
+	Striped       bool              // alternate row background colors
+	Deferred      bool              // lazy-load rows after initial page render
+	EmptyHeading  string            // custom empty state heading (default: "Aucun résultat trouvé")
+	EmptyDesc     string            // custom empty state description
+	EmptyIcon     string            // custom Material icon for empty state (default: "inbox")
+	RecordUrlFn   func(any) string  // optional: custom URL per row (overrides default /{slug}/{id})
+	StackBelow    Breakpoint        // rows collapse into stacked cards below this screen width (default: md)
+	PrimaryColumn string            // column key titling the stacked cards (default: first column)
+	ColumnSearch  map[string]string // values of the inline column searches, by column key
 }
 
 // New creates a new Table instance.
@@ -159,6 +162,19 @@ func (t *Table) WithRecordUrl(fn func(item any) string) *Table {
 	return t
 }
 
+// StackedBelow sets the screen width below which the rows collapse into
+// stacked cards (BreakpointNever keeps the table on every screen).
+func (t *Table) StackedBelow(bp Breakpoint) *Table {
+	t.StackBelow = bp
+	return t
+}
+
+// WithPrimaryColumn sets the column titling the stacked cards on small screens.
+func (t *Table) WithPrimaryColumn(key string) *Table {
+	t.PrimaryColumn = key
+	return t
+}
+
 // Column is the common interface for all columns.
 type Column interface {
 	Key() string
@@ -171,6 +187,31 @@ type Column interface {
 	Render(value string, record any) templ.Component
 }
 
+// InlineSearchable is implemented by the columns that may render a search
+// input under their header, Excel-style. The lists send its value as the
+// col_<key> param; the engine filters the rows whose value contains it.
+type InlineSearchable interface {
+	IsSearchableInline() bool
+}
+
+// SearchesInline reports whether col renders a search input under its
+// header.
+func SearchesInline(col Column) bool {
+	s, ok := col.(InlineSearchable)
+	return ok && s.IsSearchableInline()
+}
+
+// HasInlineSearch reports whether one of cols renders a search input under
+// its header.
+func HasInlineSearch(cols []Column) bool {
+	for _, col := range cols {
+		if SearchesInline(col) {
+			return true
+		}
+	}
+	return false
+}
+
 // Action represents an action on a row.
 type Action interface {
 	Label() string
@@ -510,3 +510,27 @@ func TestWithGroups_appends(t *testing.T) {
 		t.Errorf("expected 2 groups, got %d", len(tbl.Groups))
 	}
 }
+
+func TestTableResponsive(t *testing.T) {
+	tbl := New(nil).
+		WithColumns(Text("id"), Text("name"), Text("email"))
+
+	if got := StackClass(tbl.StackBelow); got != "table-stack-md" {
+		t.Errorf("Expected rows stacked below md by default, got %q", got)
+	}
+	if got := PrimaryColumnIndex(tbl.Columns, tbl.PrimaryColumn); got != 0 {
+		t.Errorf("Expected the first column as primary by default, got %d", got)
+	}
+
+	tbl.StackedBelow(BreakpointLG).WithPrimaryColumn("name")
+	if got := StackClass(tbl.StackBelow); got != "table-stack-lg" {
+		t.Errorf("Expected table-stack-lg, got %q", got)
+	}
+	if got := PrimaryColumnIndex(tbl.Columns, tbl.PrimaryColumn); got != 1 {
+		t.Errorf("Expected the name column as primary, got %d", got)
+	}
+
+	if got := StackClass(BreakpointNever); got != "" {
+		t.Errorf("Expected no stacking, got %q", got)
+	}
+}
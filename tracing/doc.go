@@ -0,0 +1,23 @@
+// Package tracing provides OpenTelemetry helpers shared by the framework.
+//
+// Spans are created from the TracerProvider of the span already carried by
+// the context, so nothing is recorded unless middleware.Tracing (or your own
+// instrumentation) started a span upstream. There is no global state.
+//
+// Features:
+//   - Child spans for CRUD handlers, search fan-out and custom code
+//   - Span links across background job dispatch
+//   - Error recording helper
+//
+// Basic usage:
+//
+//	handler := middleware.Tracing(tracerProvider)(mux)
+//
+//	// In a resource or custom handler
+//	ctx, span := tracing.Start(ctx, "orders.recalculate",
+//		attribute.Int("order.count", len(orders)))
+//	defer span.End()
+//	if err := recalculate(ctx, orders); err != nil {
+//		tracing.RecordError(span, err)
+//	}
+package tracing
@@ -0,0 +1,64 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// TracerName is the instrumentation scope used for every framework span.
+const TracerName = "github.com/bozz33/sublimeadmin"
+
+// Start starts a child span of the span carried by ctx, using the same
+// TracerProvider. When ctx carries no span (tracing disabled), the
+// returned span is a no-op and costs nothing.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	tracer := trace.SpanFromContext(ctx).TracerProvider().Tracer(TracerName)
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// RecordError records err on span and marks it as failed. A nil err is ignored.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// Link captures the span of a context so that work continued elsewhere
+// (e.g. a background job) can start its own trace linked back to it.
+type Link struct {
+	provider trace.TracerProvider
+	link     trace.Link
+}
+
+// LinkFrom captures the span carried by ctx.
+func LinkFrom(ctx context.Context) Link {
+	span := trace.SpanFromContext(ctx)
+	return Link{
+		provider: span.TracerProvider(),
+		link:     trace.Link{SpanContext: span.SpanContext()},
+	}
+}
+
+// IsValid reports whether the link points to a recorded span.
+func (l Link) IsValid() bool {
+	return l.link.SpanContext.IsValid()
+}
+
+// Start starts a new root span linked to the captured span. When the link
+// is invalid, the returned span is a no-op.
+func (l Link) Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if !l.IsValid() {
+		return noop.NewTracerProvider().Tracer(TracerName).Start(ctx, name)
+	}
+	return l.provider.Tracer(TracerName).Start(ctx, name,
+		trace.WithNewRoot(),
+		trace.WithLinks(l.link),
+		trace.WithAttributes(attrs...),
+	)
+}
@@ -0,0 +1,133 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recorder is a minimal TracerProvider recording started spans.
+type recorder struct {
+	noop.TracerProvider
+	mu    sync.Mutex
+	spans []*recordedSpan
+	next  byte
+}
+
+type recordedTracer struct {
+	noop.Tracer
+	r *recorder
+}
+
+type recordedSpan struct {
+	noop.Span
+	r      *recorder
+	name   string
+	sc     trace.SpanContext
+	parent trace.SpanContext
+	links  []trace.Link
+	status codes.Code
+	err    error
+}
+
+func (r *recorder) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return recordedTracer{r: r}
+}
+
+func (t recordedTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	cfg := trace.NewSpanStartConfig(opts...)
+
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	t.r.next++
+
+	span := &recordedSpan{r: t.r, name: name, links: cfg.Links()}
+	traceID := trace.TraceID{t.r.next}
+	if !cfg.NewRoot() {
+		span.parent = trace.SpanContextFromContext(ctx)
+		if span.parent.IsValid() {
+			traceID = span.parent.TraceID()
+		}
+	}
+	span.sc = trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     trace.SpanID{t.r.next},
+		TraceFlags: trace.FlagsSampled,
+	})
+	t.r.spans = append(t.r.spans, span)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func (s *recordedSpan) SpanContext() trace.SpanContext       { return s.sc }
+func (s *recordedSpan) IsRecording() bool                    { return true }
+func (s *recordedSpan) TracerProvider() trace.TracerProvider { return s.r }
+func (s *recordedSpan) RecordError(err error, _ ...trace.EventOption) {
+	s.err = err
+}
+func (s *recordedSpan) SetStatus(code codes.Code, _ string) { s.status = code }
+
+func TestStart_WithoutSpanIsNoop(t *testing.T) {
+	ctx, span := Start(context.Background(), "work")
+	defer span.End()
+
+	assert.False(t, span.SpanContext().IsValid())
+	assert.False(t, span.IsRecording())
+	assert.NotNil(t, ctx)
+}
+
+func TestStart_ChildOfContextSpan(t *testing.T) {
+	rec := &recorder{}
+	ctx, root := rec.Tracer("test").Start(context.Background(), "root")
+
+	_, child := Start(ctx, "child")
+
+	require.Len(t, rec.spans, 2)
+	assert.Equal(t, "child", rec.spans[1].name)
+	assert.Equal(t, root.SpanContext(), rec.spans[1].parent)
+	assert.Equal(t, root.SpanContext().TraceID(), child.SpanContext().TraceID())
+}
+
+func TestRecordError(t *testing.T) {
+	rec := &recorder{}
+	_, span := rec.Tracer("test").Start(context.Background(), "op")
+
+	RecordError(span, nil)
+	assert.Equal(t, codes.Unset, rec.spans[0].status)
+
+	RecordError(span, errors.New("boom"))
+	assert.Equal(t, codes.Error, rec.spans[0].status)
+	assert.EqualError(t, rec.spans[0].err, "boom")
+}
+
+func TestLink(t *testing.T) {
+	t.Run("invalid link is noop", func(t *testing.T) {
+		link := LinkFrom(context.Background())
+		assert.False(t, link.IsValid())
+
+		_, span := link.Start(context.Background(), "job")
+		assert.False(t, span.SpanContext().IsValid())
+	})
+
+	t.Run("new root linked to origin", func(t *testing.T) {
+		rec := &recorder{}
+		ctx, origin := rec.Tracer("test").Start(context.Background(), "request")
+
+		link := LinkFrom(ctx)
+		require.True(t, link.IsValid())
+
+		_, span := link.Start(context.Background(), "job")
+
+		require.Len(t, rec.spans, 2)
+		job := rec.spans[1]
+		assert.NotEqual(t, origin.SpanContext().TraceID(), span.SpanContext().TraceID())
+		require.Len(t, job.links, 1)
+		assert.Equal(t, origin.SpanContext(), job.links[0].SpanContext)
+	})
+}
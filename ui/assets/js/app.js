@@ -110,6 +110,12 @@ const Utils = {
         const div = document.createElement('div');
         div.textContent = text;
         return div.innerHTML;
+    },
+
+    // CSRF token of the page, sent as X-CSRF-Token with fetch requests
+    csrfToken() {
+        const csrf = document.cookie.match(/(?:^|; )_csrf=([^;]*)/);
+        return csrf ? decodeURIComponent(csrf[1]) : '';
     }
 };
 
@@ -410,6 +416,655 @@ const Modal = {
     }
 };
 
+// ============================================
+// CONFIRM - Confirmation Dialogs
+// ============================================
+// Drives the confirmation dialogs ([data-confirm-dialog]: components.DeleteModal,
+// generics.ActionConfirmModal): the phrase typed to confirm, the summary
+// loaded from the server and the async submission with its loading state.
+const Confirm = {
+    init() {
+        document.addEventListener('input', (e) => {
+            if (e.target.matches('[data-confirm-phrase-input]')) {
+                this.update(e.target.closest('[data-confirm-dialog]'));
+            }
+        });
+
+        document.addEventListener('change', (e) => {
+            if (e.target.matches('[data-confirm-require]')) {
+                this.update(e.target.closest('[data-confirm-dialog]'));
+            }
+        });
+
+        document.addEventListener('submit', (e) => {
+            const form = e.target.closest('[data-confirm-form]');
+            if (!form) return;
+            const dialog = form.closest('[data-confirm-dialog]');
+            if (!this.confirmed(dialog) || form.getAttribute('aria-busy') === 'true') {
+                e.preventDefault();
+                return;
+            }
+            if (form.dataset.async === 'true') {
+                e.preventDefault();
+                this.submit(form, dialog);
+            }
+        });
+    },
+
+    // Configure a dialog before it opens. source is the trigger element
+    // (data-confirm-phrase, data-confirm-summary, data-confirm-async) or an
+    // object { phrase, summaryUrl, async }.
+    prepare(dialog, source = {}) {
+        if (!dialog) return;
+        const options = source.dataset ? {
+            phrase: source.dataset.confirmPhrase,
+            summaryUrl: source.dataset.confirmSummary,
+            async: source.dataset.confirmAsync === 'true'
+        } : source;
+        const phrase = options.phrase || '';
+
+        dialog.dataset.confirmPhrase = phrase;
+        const block = dialog.querySelector('[data-confirm-phrase-block]');
+        const text = dialog.querySelector('[data-confirm-phrase-text]');
+        const input = dialog.querySelector('[data-confirm-phrase-input]');
+        if (block) block.hidden = phrase === '';
+        if (text) text.textContent = phrase;
+        if (input) input.value = '';
+
+        const form = dialog.querySelector('[data-confirm-form]');
+        if (form) {
+            form.dataset.async = options.async ? 'true' : 'false';
+            form.removeAttribute('aria-busy');
+        }
+
+        this.error(dialog, '');
+        this.update(dialog);
+        this.load(dialog.querySelector('[data-confirm-summary]'), options.summaryUrl || '');
+        if (phrase && input) setTimeout(() => input.focus(), 100);
+    },
+
+    // Whether the action can be confirmed: the summary does not block it,
+    // its required checkboxes are checked and the typed phrase matches
+    confirmed(dialog) {
+        if (!dialog) return true;
+        if (dialog.querySelector('[data-confirm-summary] [data-confirm-blocked]')) return false;
+        if (dialog.querySelector('[data-confirm-summary] [data-confirm-require]:not(:checked)')) return false;
+        const phrase = dialog.dataset.confirmPhrase || '';
+        if (!phrase) return true;
+        const input = dialog.querySelector('[data-confirm-phrase-input]');
+        return !!input && input.value.trim() === phrase;
+    },
+
+    // Enable the confirm button once the action can be confirmed
+    update(dialog) {
+        if (!dialog) return;
+        const button = dialog.querySelector('[data-confirm-submit]');
+        const busy = dialog.querySelector('[data-confirm-form]')?.getAttribute('aria-busy') === 'true';
+        if (button) button.disabled = busy || !this.confirmed(dialog);
+    },
+
+    // Load the summary fragment at url into el. Anything but a 200 (e.g. the
+    // 204 of a deletion affecting nothing else) leaves it hidden. Its inputs
+    // (e.g. "delete with children") are sent with the form of the dialog.
+    load(el, url) {
+        if (!el) return;
+        const dialog = el.closest('[data-confirm-dialog]');
+        el.innerHTML = '';
+        el.hidden = true;
+        el.dataset.confirmSummaryUrl = url;
+        this.update(dialog);
+        if (!url) return;
+        fetch(url, { headers: { 'Accept': 'text/html' } })
+            .then((res) => (res.status === 200 ? res.text() : ''))
+            .then((html) => {
+                // Ignore the answer when the dialog was reopened meanwhile
+                if (!html || el.dataset.confirmSummaryUrl !== url) return;
+                el.innerHTML = html;
+                el.hidden = false;
+                const form = dialog?.querySelector('[data-confirm-form]');
+                if (form?.id) {
+                    el.querySelectorAll('input').forEach((input) => input.setAttribute('form', form.id));
+                }
+                this.update(dialog);
+            })
+            .catch(() => {});
+    },
+
+    // Submit the form in the background: the dialog stays open with a
+    // loading state, shows the error if the action fails and follows the
+    // redirect once it succeeds.
+    async submit(form, dialog) {
+        form.setAttribute('aria-busy', 'true');
+        this.error(dialog, '');
+        this.update(dialog);
+        try {
+            const res = await fetch(form.action, {
+                method: 'POST',
+                body: new FormData(form),
+                headers: {
+                    'Accept': 'application/json',
+                    'X-CSRF-Token': Utils.csrfToken()
+                }
+            });
+            if (res.ok) {
+                window.location.assign(res.redirected ? res.url : window.location.href);
+                return;
+            }
+            const problem = await res.json().catch(() => ({}));
+            this.error(dialog, problem.detail || problem.title || 'The action failed.');
+        } catch (e) {
+            this.error(dialog, 'The server could not be reached. Please try again.');
+        }
+        form.removeAttribute('aria-busy');
+        this.update(dialog);
+    },
+
+    error(dialog, message) {
+        const el = dialog?.querySelector('[data-confirm-error]');
+        if (!el) return;
+        el.textContent = message;
+        el.hidden = message === '';
+    }
+};
+
+// ============================================
+// SLIDE-OVER - Drawer Forms
+// ============================================
+// Drives the drawers ([data-slide-over]: components.SlideOver). A link with
+// data-slide-over-url loads that URL into the drawer named by its
+// data-slide-over attribute ("crud-drawer" by default); forms inside are
+// submitted in the background and the list refreshed once they are saved.
+const SlideOver = {
+    lastFocus: null,
+
+    init() {
+        document.addEventListener('click', (e) => {
+            const trigger = e.target.closest('[data-slide-over-url]');
+            if (trigger) {
+                // Let ctrl/cmd-click open the full page in a new tab
+                if (e.button !== 0 || e.metaKey || e.ctrlKey || e.shiftKey || e.altKey) return;
+                e.preventDefault();
+                this.lastFocus = trigger;
+                this.load(trigger.dataset.slideOver || 'crud-drawer', trigger.dataset.slideOverUrl);
+                return;
+            }
+            const close = e.target.closest('[data-slide-over-close]');
+            const drawer = close?.closest('[data-slide-over]');
+            if (drawer) {
+                e.preventDefault();
+                this.close(drawer.id);
+            }
+        });
+
+        document.addEventListener('keydown', (e) => {
+            if (e.key !== 'Escape') return;
+            const drawer = document.querySelector('[data-slide-over][data-open]');
+            if (drawer) this.close(drawer.id);
+        });
+
+        document.addEventListener('submit', (e) => {
+            const drawer = e.target.closest('[data-slide-over]');
+            if (!drawer || e.target.closest('[data-confirm-form]')) return;
+            e.preventDefault();
+            this.submit(drawer, e.target);
+        });
+    },
+
+    // Load url into the drawer and open it
+    async load(id, url) {
+        const drawer = document.getElementById(id);
+        if (!drawer) {
+            window.location.assign(url);
+            return;
+        }
+        drawer.dataset.url = url;
+        drawer.setAttribute('aria-busy', 'true');
+        this.open(id);
+        try {
+            const res = await fetch(url, { headers: { 'Accept': 'text/html' } });
+            if (!res.ok) throw new Error(res.statusText);
+            this.fill(drawer, await res.text());
+        } catch (e) {
+            this.close(id);
+            Toast.error('The form could not be loaded.');
+        }
+        drawer.removeAttribute('aria-busy');
+    },
+
+    // Swap html into the body of the drawer and take its title
+    fill(drawer, html) {
+        const body = drawer.querySelector('[data-slide-over-body]');
+        body.innerHTML = html;
+        const content = body.querySelector('[data-slide-over-content]');
+        const title = drawer.querySelector('[data-slide-over-title]');
+        if (content && title) title.textContent = content.dataset.title || '';
+        body.querySelector('input:not([type=hidden]), select, textarea')?.focus();
+    },
+
+    open(id) {
+        const drawer = document.getElementById(id);
+        if (!drawer) return;
+        drawer.hidden = false;
+        document.body.style.overflow = 'hidden';
+        requestAnimationFrame(() => drawer.setAttribute('data-open', ''));
+    },
+
+    close(id) {
+        const drawer = document.getElementById(id);
+        if (!drawer || drawer.hidden) return;
+        drawer.removeAttribute('data-open');
+        document.body.style.overflow = '';
+        setTimeout(() => {
+            drawer.hidden = true;
+            drawer.querySelector('[data-slide-over-body]').innerHTML = '';
+        }, 200);
+        this.lastFocus?.focus();
+        this.lastFocus = null;
+    },
+
+    // Submit a form of the drawer: a 422 swaps the returned form in, a
+    // success closes the drawer and refreshes the list.
+    async submit(drawer, form) {
+        if (drawer.getAttribute('aria-busy') === 'true') return;
+        drawer.setAttribute('aria-busy', 'true');
+        const data = new FormData(form);
+        try {
+            const res = await fetch(drawer.dataset.url || form.action, {
+                method: 'POST',
+                body: data,
+                headers: {
+                    'Accept': 'application/json',
+                    'HX-Request': 'true',
+                    'X-CSRF-Token': Utils.csrfToken()
+                }
+            });
+            if (res.status === 422) {
+                this.fill(drawer, await res.text());
+                this.restore(drawer, data);
+            } else if (res.ok) {
+                this.close(drawer.id);
+                await this.refresh();
+                if (!Toast.fromResponse(res)) Toast.success('Saved.');
+            } else {
+                const problem = await res.json().catch(() => ({}));
+                Toast.error(problem.detail || problem.title || 'The record could not be saved.');
+            }
+        } catch (e) {
+            Toast.error('The server could not be reached. Please try again.');
+        }
+        drawer.removeAttribute('aria-busy');
+    },
+
+    // Put the values typed by the user back into the form returned with
+    // errors, for forms whose fields are not filled from the request
+    restore(drawer, data) {
+        const form = drawer.querySelector('[data-slide-over-body] form');
+        if (!form) return;
+        for (const el of form.elements) {
+            if (!el.name || el.type === 'hidden' || el.type === 'file' || !data.has(el.name)) continue;
+            const values = data.getAll(el.name);
+            if (el.type === 'checkbox' || el.type === 'radio') {
+                el.checked = values.includes(el.value);
+            } else if (el.multiple) {
+                for (const option of el.options) option.selected = values.includes(option.value);
+            } else {
+                el.value = values[0];
+            }
+        }
+    },
+
+    // Replace the table of the list with a fresh copy
+    async refresh() {
+        const table = document.querySelector('[data-list-table]');
+        if (!table) {
+            window.location.reload();
+            return;
+        }
+        const res = await fetch(window.location.href, { headers: { 'Accept': 'text/html' } });
+        const doc = new DOMParser().parseFromString(await res.text(), 'text/html');
+        const fresh = doc.querySelector('[data-list-table]');
+        if (fresh) table.replaceWith(fresh);
+    }
+};
+
+// ============================================
+// LIST TABLE - Partial Table Updates
+// ============================================
+// Sorts, pages and filters the lists without reloading the page. Links and
+// GET forms marked data-list-nav fetch the list with HX-Request: true and
+// HX-Target: list-table; the server returns the table (#list-table) and the
+// pagination (#list-pagination, hx-swap-oob) only, see engine.ListTableID.
+const ListTable = {
+    controller: null,
+
+    init() {
+        if (!document.getElementById('list-table')) return;
+
+        document.addEventListener('click', (e) => {
+            const link = e.target.closest('a[data-list-nav]');
+            if (!link) return;
+            // Let ctrl/cmd-click open the page in a new tab
+            if (e.button !== 0 || e.metaKey || e.ctrlKey || e.shiftKey || e.altKey) return;
+            e.preventDefault();
+            this.load(link.href, true);
+        });
+
+        document.addEventListener('submit', (e) => {
+            const form = e.target.closest('form[data-list-nav]');
+            if (!form) return;
+            e.preventDefault();
+            const url = new URL(form.action || window.location.href);
+            url.search = new URLSearchParams(new FormData(form)).toString();
+            this.load(url.toString(), true);
+        });
+
+        window.addEventListener('popstate', () => this.load(window.location.href, false));
+    },
+
+    // Fetch the table of url and swap it in, pushing the URL returned by the
+    // server into the history unless it comes from it.
+    async load(url, push) {
+        const target = document.getElementById('list-table');
+        if (!target) {
+            window.location.assign(url);
+            return;
+        }
+        this.controller?.abort();
+        this.controller = new AbortController();
+        target.setAttribute('aria-busy', 'true');
+        try {
+            const res = await fetch(url, {
+                headers: { 'Accept': 'text/html', 'HX-Request': 'true', 'HX-Target': 'list-table' },
+                signal: this.controller.signal
+            });
+            const doc = new DOMParser().parseFromString(await res.text(), 'text/html');
+            const fresh = doc.getElementById('list-table');
+            if (!res.ok || !fresh) {
+                // Not a list fragment (custom table, error page): load the page
+                window.location.assign(url);
+                return;
+            }
+            // Keep typing in the inline column search of the table
+            const focused = target.contains(document.activeElement) ? document.activeElement.name : '';
+            target.replaceWith(fresh);
+            if (focused) {
+                const input = fresh.querySelector(`input[name="${CSS.escape(focused)}"]`);
+                input?.focus();
+                input?.setSelectionRange?.(input.value.length, input.value.length);
+            }
+            doc.querySelectorAll('[hx-swap-oob]').forEach((el) => {
+                el.removeAttribute('hx-swap-oob');
+                document.getElementById(el.id)?.replaceWith(el);
+            });
+            TreeTable.apply(fresh.querySelector('[data-tree-table]'));
+            if (push) {
+                history.pushState({}, '', res.headers.get('HX-Push-Url') || url);
+            }
+        } catch (e) {
+            if (e.name !== 'AbortError') window.location.assign(url);
+        } finally {
+            target.removeAttribute('aria-busy');
+        }
+    }
+};
+
+// ============================================
+// TREE TABLE - Hierarchical Lists
+// ============================================
+// Drives the lists of tree resources ([data-tree-table]: engine.ResourceTree).
+// A toggle collapses the descendants of a row, remembered per resource; a
+// row dropped on the middle of another becomes its last child, on its top
+// or bottom edge its sibling before or after. Moves are sent to the
+// data-tree-move-url of the table and the list reloaded.
+const TreeTable = {
+    dragged: null,
+
+    init() {
+        document.addEventListener('click', (e) => {
+            const toggle = e.target.closest('[data-tree-toggle]');
+            if (!toggle) return;
+            e.preventDefault();
+            const row = toggle.closest('[data-tree-row]');
+            const table = row.closest('[data-tree-table]');
+            const collapsed = this.collapsed(table);
+            const id = row.dataset.treeId;
+            if (collapsed.has(id)) collapsed.delete(id); else collapsed.add(id);
+            localStorage.setItem(this.key(table), JSON.stringify([...collapsed]));
+            this.apply(table);
+        });
+
+        document.addEventListener('dragstart', (e) => {
+            const row = e.target.closest?.('[data-tree-row][draggable="true"]');
+            if (!row) return;
+            this.dragged = row;
+            e.dataTransfer.effectAllowed = 'move';
+            e.dataTransfer.setData('text/plain', row.dataset.treeId);
+            row.classList.add('opacity-50');
+        });
+
+        document.addEventListener('dragend', () => {
+            this.dragged?.classList.remove('opacity-50');
+            this.dragged = null;
+            this.mark(null);
+        });
+
+        document.addEventListener('dragover', (e) => {
+            const row = this.target(e);
+            if (!row) return;
+            e.preventDefault();
+            this.mark(row, this.zone(row, e));
+        });
+
+        document.addEventListener('drop', (e) => {
+            const row = this.target(e);
+            if (!row) return;
+            e.preventDefault();
+            const zone = this.zone(row, e);
+            this.mark(null);
+            this.move(this.dragged, row, zone);
+        });
+
+        this.apply(document.querySelector('[data-tree-table]'));
+    },
+
+    key(table) {
+        return 'sublimego-tree-' + table.dataset.treeTable;
+    },
+
+    collapsed(table) {
+        try {
+            return new Set(JSON.parse(localStorage.getItem(this.key(table)) || '[]'));
+        } catch (e) {
+            return new Set();
+        }
+    },
+
+    rows(table) {
+        return [...table.querySelectorAll('[data-tree-row]')];
+    },
+
+    // Hide the descendants of the collapsed rows
+    apply(table) {
+        if (!table) return;
+        const collapsed = this.collapsed(table);
+        const hidden = new Set();
+        this.rows(table).forEach((row) => {
+            const parent = row.dataset.treeParent;
+            const hide = parent !== '' && (collapsed.has(parent) || hidden.has(parent));
+            if (hide) hidden.add(row.dataset.treeId);
+            row.hidden = hide;
+            const toggle = row.querySelector('[data-tree-toggle]');
+            if (toggle) {
+                const open = !collapsed.has(row.dataset.treeId);
+                toggle.setAttribute('aria-expanded', open ? 'true' : 'false');
+                toggle.setAttribute('aria-label', open ? 'Collapse' : 'Expand');
+                toggle.classList.toggle('-rotate-90', !open);
+            }
+        });
+    },
+
+    // The row under the pointer a dragged row may be dropped on: not the
+    // dragged row itself nor one of its descendants
+    target(e) {
+        const row = e.target.closest?.('[data-tree-row]');
+        if (!row || !this.dragged || row === this.dragged) return null;
+        const table = row.closest('[data-tree-table]');
+        if (table !== this.dragged.closest('[data-tree-table]')) return null;
+        const parents = new Map(this.rows(table).map((r) => [r.dataset.treeId, r.dataset.treeParent]));
+        for (let id = row.dataset.treeParent; id; id = parents.get(id)) {
+            if (id === this.dragged.dataset.treeId) return null;
+        }
+        return row;
+    },
+
+    zone(row, e) {
+        const rect = row.getBoundingClientRect();
+        const y = (e.clientY - rect.top) / rect.height;
+        if (y < 0.25) return 'before';
+        if (y > 0.75) return 'after';
+        return 'inside';
+    },
+
+    mark(row, zone) {
+        document.querySelectorAll('[data-tree-drop]').forEach((r) => {
+            r.removeAttribute('data-tree-drop');
+            r.classList.remove('border-t-2', 'border-b-2', 'border-primary-500', 'bg-primary-50', 'dark:bg-primary-900/20');
+        });
+        if (!row) return;
+        row.setAttribute('data-tree-drop', zone);
+        if (zone === 'before') row.classList.add('border-t-2', 'border-primary-500');
+        else if (zone === 'after') row.classList.add('border-b-2', 'border-primary-500');
+        else row.classList.add('bg-primary-50', 'dark:bg-primary-900/20');
+    },
+
+    // Send the new parent and position of the dragged row, then reload
+    async move(dragged, target, zone) {
+        const table = target.closest('[data-tree-table]');
+        const id = dragged.dataset.treeId;
+        const parent = zone === 'inside' ? target.dataset.treeId : target.dataset.treeParent;
+        const siblings = this.rows(table).filter((r) => r.dataset.treeParent === parent && r !== dragged);
+        let position = siblings.length;
+        if (zone !== 'inside') {
+            position = siblings.indexOf(target) + (zone === 'after' ? 1 : 0);
+        }
+
+        const body = new FormData();
+        body.set('id', id);
+        body.set('parent_id', parent);
+        body.set('position', String(position));
+        try {
+            const res = await fetch(table.dataset.treeMoveUrl, {
+                method: 'POST',
+                body,
+                headers: {
+                    'Accept': 'application/json',
+                    'HX-Request': 'true',
+                    'X-CSRF-Token': Utils.csrfToken()
+                }
+            });
+            if (!res.ok) {
+                const problem = await res.json().catch(() => ({}));
+                Toast.error(problem.detail || problem.title || 'The record could not be moved.');
+                return;
+            }
+            ListTable.load(window.location.href, false);
+        } catch (e) {
+            Toast.error('The server could not be reached. Please try again.');
+        }
+    }
+};
+
+// ============================================
+// RELATION TABLE - Relation Manager Sub-tables
+// ============================================
+// Drives the sub-tables of the relation managers ([data-relation-table]:
+// components.RelationTable). Their pages, Create and Attach forms and row
+// actions are sent with HX-Request: true to the relation endpoints, which
+// answer with the fresh table, swapped in place.
+const RelationTable = {
+    init() {
+        document.addEventListener('click', (e) => {
+            const table = e.target.closest('[data-relation-table]');
+            if (!table) return;
+
+            const link = e.target.closest('a[data-relation-nav]');
+            if (link) {
+                e.preventDefault();
+                this.send(table, link.href, 'GET');
+                return;
+            }
+
+            const remove = e.target.closest('[data-relation-delete]');
+            if (remove) {
+                if (!window.confirm(remove.dataset.relationConfirm || 'Are you sure?')) return;
+                this.send(table, remove.dataset.relationDelete, 'DELETE');
+                return;
+            }
+
+            // The Create modal is opened by Modal, its form loaded here
+            const create = e.target.closest('[data-relation-create]');
+            if (create) this.loadForm(table, create.dataset.relationCreate, create.dataset.modalOpen);
+        });
+
+        document.addEventListener('submit', (e) => {
+            const table = e.target.closest('[data-relation-table]');
+            if (!table || e.target.method?.toLowerCase() === 'get') return;
+            e.preventDefault();
+            const form = e.target;
+            this.send(table, form.getAttribute('action') || table.dataset.url, 'POST', new FormData(form));
+        });
+    },
+
+    // Load the create form of the relation into the body of its modal
+    async loadForm(table, url, modalId) {
+        const body = document.getElementById(modalId)?.querySelector('[data-relation-modal-body]');
+        if (!body) return;
+        body.innerHTML = '';
+        try {
+            const res = await fetch(url, { headers: { 'Accept': 'text/html' } });
+            if (!res.ok) throw new Error(res.statusText);
+            body.innerHTML = await res.text();
+            body.querySelector('input:not([type=hidden]), select, textarea')?.focus();
+        } catch (e) {
+            Modal.close(modalId);
+            Toast.error('The form could not be loaded.');
+        }
+    },
+
+    // Send a request of the table and swap in the table returned
+    async send(table, url, method, body) {
+        if (table.getAttribute('aria-busy') === 'true') return;
+        table.setAttribute('aria-busy', 'true');
+        try {
+            const res = await fetch(url, {
+                method,
+                body,
+                headers: {
+                    'Accept': 'text/html',
+                    'HX-Request': 'true',
+                    'HX-Target': table.id,
+                    'X-CSRF-Token': Utils.csrfToken()
+                }
+            });
+            if (!res.ok) {
+                const problem = await res.json().catch(() => ({}));
+                Toast.error(problem.detail || problem.title || 'The action failed.');
+                return;
+            }
+            const doc = new DOMParser().parseFromString(await res.text(), 'text/html');
+            const fresh = doc.getElementById(table.id);
+            if (!fresh) return;
+            table.querySelectorAll('[data-modal]').forEach((modal) => Modal.close(modal.id));
+            table.replaceWith(fresh);
+            if (method !== 'GET') Toast.fromResponse(res);
+        } catch (e) {
+            Toast.error('The server could not be reached. Please try again.');
+        } finally {
+            table.removeAttribute('aria-busy');
+        }
+    }
+};
+
 // ============================================
 // TOAST - Notification System
 // ============================================
@@ -417,24 +1072,64 @@ const Toast = {
     container: null,
     queue: [],
     maxVisible: 5,
+    defaultDuration: 5000,
 
     init() {
         if (this.container) return;
-        
-        this.container = document.createElement('div');
-        this.container.id = 'toast-container';
-        this.container.className = 'fixed bottom-4 right-4 z-[9999] space-y-2 pointer-events-none';
-        this.container.setAttribute('aria-live', 'polite');
-        document.body.appendChild(this.container);
+
+        // The container is rendered by atoms.ToastRegion, or created here
+        this.container = document.getElementById('toast-container');
+        if (!this.container) {
+            this.container = document.createElement('div');
+            this.container.id = 'toast-container';
+            this.container.className = 'fixed bottom-4 right-4 z-[9999] flex flex-col gap-2 pointer-events-none';
+            this.container.setAttribute('aria-live', 'polite');
+            document.body.appendChild(this.container);
+        }
+
+        // Close buttons (no inline handlers: the CSP forbids them)
+        document.addEventListener('click', (e) => {
+            const button = e.target.closest('[data-toast-dismiss]');
+            const toast = button && button.closest('[data-toast]');
+            if (toast) this.dismiss(toast.id);
+        });
+
+        // POST actions of toasts ("Undo"): submitted in the background, then
+        // the redirect is followed
+        document.addEventListener('click', async (e) => {
+            const button = e.target.closest('[data-toast-action]');
+            if (!button || button.disabled) return;
+            button.disabled = true;
+            try {
+                const res = await fetch(button.dataset.toastAction, {
+                    method: button.dataset.toastMethod || 'POST',
+                    headers: { 'Accept': 'application/json', 'X-CSRF-Token': Utils.csrfToken() }
+                });
+                if (!res.ok) throw new Error(res.statusText);
+                window.location.assign(res.redirected ? res.url : window.location.href);
+            } catch (err) {
+                button.disabled = false;
+                this.error('The action failed.');
+            }
+        });
+
+        // Flash messages of HTMX responses (HX-Trigger: {"flash": {...}})
+        document.addEventListener('flash', (e) => this.flash(e.detail?.messages));
+
+        // Toasts rendered by the server (atoms.Toast, flash messages)
+        document.querySelectorAll('[data-toast]').forEach((toast) => {
+            this.schedule(toast, parseInt(toast.dataset.toastDuration, 10) || this.defaultDuration);
+        });
     },
 
     show(message, type = 'info', options = {}) {
         if (!this.container) this.init();
 
         const config = {
-            duration: 5000,
+            duration: this.defaultDuration,
             dismissible: true,
             icon: true,
+            title: '',
             ...options
         };
 
@@ -451,44 +1146,113 @@ const Toast = {
             warning: `<svg class="w-5 h-5" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M12 9v2m0 4h.01m-6.938 4h13.856c1.54 0 2.502-1.667 1.732-3L13.732 4c-.77-1.333-2.694-1.333-3.464 0L3.34 16c-.77 1.333.192 3 1.732 3z"/></svg>`,
             info: `<svg class="w-5 h-5" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M13 16h-1v-4h-1m1-4h.01M21 12a9 9 0 11-18 0 9 9 0 0118 0z"/></svg>`
         };
+        if (!colors[type]) type = 'info';
 
         const id = Utils.uniqueId('toast');
         const toast = document.createElement('div');
         toast.id = id;
         toast.className = `flex items-center gap-3 px-4 py-3 rounded-xl text-white ${colors[type]} shadow-lg transform translate-x-full transition-all duration-300 pointer-events-auto`;
-        toast.setAttribute('role', 'alert');
-        
+        // Errors and warnings interrupt screen readers, the others wait
+        toast.setAttribute('role', type === 'error' || type === 'warning' ? 'alert' : 'status');
+        toast.setAttribute('aria-atomic', 'true');
+        toast.dataset.toast = '';
+
         toast.innerHTML = `
-            ${config.icon ? `<span class="flex-shrink-0">${icons[type]}</span>` : ''}
-            <span class="text-sm font-medium flex-1">${Utils.escapeHtml(message)}</span>
+            ${config.icon ? `<span class="flex-shrink-0" aria-hidden="true">${icons[type]}</span>` : ''}
+            <span class="text-sm font-medium flex-1">
+                ${config.title ? `<strong class="block">${Utils.escapeHtml(config.title)}</strong>` : ''}
+                ${Utils.escapeHtml(message)}
+                ${config.actions?.length ? `<span class="mt-1 flex gap-3">${config.actions.map((a) => (!a.method || a.method === 'GET')
+                    ? `<a href="${Utils.escapeHtml(a.url)}" class="font-semibold underline">${Utils.escapeHtml(a.label)}</a>`
+                    : `<button type="button" class="font-semibold underline" data-toast-action="${Utils.escapeHtml(a.url)}" data-toast-method="${Utils.escapeHtml(a.method)}">${Utils.escapeHtml(a.label)}</button>`
+                ).join('')}</span>` : ''}
+            </span>
             ${config.dismissible ? `
-                <button class="flex-shrink-0 p-1 hover:bg-white/20 rounded-lg transition-colors" onclick="Toast.dismiss('${id}')">
-                    <svg class="w-4 h-4" fill="none" stroke="currentColor" viewBox="0 0 24 24"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M6 18L18 6M6 6l12 12"/></svg>
+                <button type="button" class="flex-shrink-0 p-1 hover:bg-white/20 rounded-lg transition-colors" data-toast-dismiss aria-label="Close">
+                    <svg class="w-4 h-4" fill="none" stroke="currentColor" viewBox="0 0 24 24" aria-hidden="true"><path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M6 18L18 6M6 6l12 12"/></svg>
                 </button>
             ` : ''}
         `;
 
+        // Wait for a slot when maxVisible toasts are on screen
+        if (this.visible() >= this.maxVisible) {
+            this.queue.push({ toast, duration: config.duration });
+        } else {
+            this.mount(toast, config.duration);
+        }
+        return id;
+    },
+
+    visible() {
+        return this.container.querySelectorAll('[data-toast]:not([data-toast-leaving])').length;
+    },
+
+    mount(toast, duration) {
         this.container.appendChild(toast);
 
         // Animate in
         requestAnimationFrame(() => {
             toast.classList.remove('translate-x-full');
         });
+        this.schedule(toast, duration);
+    },
 
-        // Auto dismiss
-        if (config.duration > 0) {
-            setTimeout(() => this.dismiss(id), config.duration);
-        }
-
-        return id;
+    // Auto-dismiss after duration ms (none when <= 0); hovering or focusing
+    // the toast pauses the timer so it can be read and acted upon.
+    schedule(toast, duration) {
+        if (!(duration > 0)) return;
+        let remaining = duration;
+        let started = Date.now();
+        let timer = setTimeout(() => this.dismiss(toast.id), remaining);
+        const pause = () => {
+            clearTimeout(timer);
+            remaining -= Date.now() - started;
+        };
+        const resume = () => {
+            if (toast.matches(':hover') || toast.contains(document.activeElement)) return;
+            started = Date.now();
+            clearTimeout(timer);
+            timer = setTimeout(() => this.dismiss(toast.id), Math.max(remaining, 1000));
+        };
+        toast.addEventListener('mouseenter', pause);
+        toast.addEventListener('focusin', pause);
+        toast.addEventListener('mouseleave', resume);
+        toast.addEventListener('focusout', () => setTimeout(resume));
     },
 
     dismiss(id) {
         const toast = document.getElementById(id);
-        if (!toast) return;
+        if (!toast || toast.dataset.toastLeaving !== undefined) return;
 
+        toast.dataset.toastLeaving = '';
         toast.classList.add('translate-x-full', 'opacity-0');
-        setTimeout(() => toast.remove(), 300);
+        setTimeout(() => {
+            toast.remove();
+            const next = this.queue.shift();
+            if (next) this.mount(next.toast, next.duration);
+        }, 300);
+    },
+
+    // Show flash messages ({ type, text, title, actions }, see flash.Message).
+    // Errors stay until closed; messages with actions are left longer.
+    flash(messages) {
+        (messages || []).forEach((msg) => {
+            const actions = msg.actions || [];
+            const duration = msg.type === 'error' ? -1 : (actions.length ? 10000 : this.defaultDuration);
+            this.show(msg.text, msg.type, { title: msg.title || '', actions, duration });
+        });
+    },
+
+    // Show the flash messages carried by the HX-Trigger header of a fetch
+    // response sent with HX-Request: true. Returns how many were shown.
+    fromResponse(res) {
+        try {
+            const messages = JSON.parse(res.headers.get('HX-Trigger') || '{}').flash?.messages || [];
+            this.flash(messages);
+            return messages.length;
+        } catch (e) {
+            return 0;
+        }
     },
 
     success(message, options = {}) {
@@ -508,6 +1272,7 @@ const Toast = {
     },
 
     clear() {
+        this.queue = [];
         if (this.container) {
             this.container.innerHTML = '';
         }
@@ -601,6 +1366,28 @@ const FormValidator = {
 
     clearAll(form) {
         form.querySelectorAll('[data-validate]').forEach(input => this.clearError(input));
+    },
+
+    // Inputs carrying the HTML5 attributes of their server rules
+    // (data-client-validate, see form.ClientValidation) show the message of
+    // the browser once they lose focus, updated as the user fixes them.
+    init() {
+        document.addEventListener('focusout', (e) => {
+            if (e.target.matches?.('[data-client-validate]')) this.report(e.target);
+        });
+        document.addEventListener('input', (e) => {
+            if (e.target.matches?.('[data-client-validate]') && e.target.dataset.clientInvalid) this.report(e.target);
+        });
+    },
+
+    report(input) {
+        if (input.validity.valid) {
+            delete input.dataset.clientInvalid;
+            this.clearError(input);
+        } else {
+            input.dataset.clientInvalid = 'true';
+            this.showError(input, input.validationMessage);
+        }
     }
 };
 
@@ -672,42 +1459,351 @@ const Dropdown = {
     }
 };
 
+// ============================================
+// DROPDOWN MENU - Accessible menu button (atoms.DropdownMenu)
+// ============================================
+const DropdownMenu = {
+    init() {
+        document.addEventListener('click', (e) => {
+            const button = e.target.closest('[data-menu-button]');
+            if (button) {
+                const menu = button.closest('[data-menu]');
+                this.isOpen(menu) ? this.close(menu, true) : this.open(menu, 0);
+                return;
+            }
+            const item = e.target.closest('[data-menu] [role="menuitem"]');
+            if (item) {
+                if (item.getAttribute('aria-disabled') === 'true') {
+                    e.preventDefault();
+                    e.stopPropagation();
+                    return;
+                }
+                this.close(item.closest('[data-menu]'), true);
+                return;
+            }
+            // Close the open menus on a click outside
+            document.querySelectorAll('[data-menu]').forEach((menu) => {
+                if (!menu.contains(e.target)) this.close(menu, false);
+            });
+        });
+
+        document.addEventListener('keydown', (e) => {
+            const menu = e.target.closest && e.target.closest('[data-menu]');
+            if (!menu) return;
+            if (e.target.matches('[data-menu-button]')) {
+                if (e.key === 'ArrowDown' || e.key === 'Enter' || e.key === ' ') {
+                    e.preventDefault();
+                    this.open(menu, 0);
+                } else if (e.key === 'ArrowUp') {
+                    e.preventDefault();
+                    this.open(menu, -1);
+                }
+                return;
+            }
+            const items = this.items(menu);
+            const index = items.indexOf(document.activeElement);
+            switch (e.key) {
+                case 'ArrowDown':
+                    e.preventDefault();
+                    this.focus(items, index + 1);
+                    break;
+                case 'ArrowUp':
+                    e.preventDefault();
+                    this.focus(items, index - 1);
+                    break;
+                case 'Home':
+                    e.preventDefault();
+                    this.focus(items, 0);
+                    break;
+                case 'End':
+                    e.preventDefault();
+                    this.focus(items, -1);
+                    break;
+                case 'Escape':
+                    e.preventDefault();
+                    this.close(menu, true);
+                    break;
+                case 'Tab':
+                    this.close(menu, false);
+                    break;
+                default:
+                    // Type-ahead: move to the next item starting with the letter
+                    if (e.key.length === 1 && !e.ctrlKey && !e.metaKey && !e.altKey) {
+                        const key = e.key.toLowerCase();
+                        const order = items.slice(index + 1).concat(items.slice(0, index + 1));
+                        const match = order.find((el) => el.textContent.trim().toLowerCase().startsWith(key));
+                        if (match) match.focus();
+                    }
+            }
+        });
+    },
+
+    // Enabled items of the menu, in order
+    items(menu) {
+        return Array.from(menu.querySelectorAll('[role="menuitem"]'))
+            .filter((el) => el.getAttribute('aria-disabled') !== 'true');
+    },
+
+    isOpen(menu) {
+        return !menu.querySelector('[data-menu-list]').hidden;
+    },
+
+    // Open the menu and focus the item at index (-1 for the last)
+    open(menu, index) {
+        document.querySelectorAll('[data-menu]').forEach((other) => {
+            if (other !== menu) this.close(other, false);
+        });
+        menu.querySelector('[data-menu-list]').hidden = false;
+        menu.querySelector('[data-menu-button]').setAttribute('aria-expanded', 'true');
+        this.focus(this.items(menu), index);
+    },
+
+    close(menu, refocus) {
+        if (!menu || !this.isOpen(menu)) return;
+        menu.querySelector('[data-menu-list]').hidden = true;
+        const button = menu.querySelector('[data-menu-button]');
+        button.setAttribute('aria-expanded', 'false');
+        if (refocus) button.focus();
+    },
+
+    // Focus items[index], wrapping around both ends
+    focus(items, index) {
+        if (items.length === 0) return;
+        items[(index + items.length) % items.length].focus();
+    }
+};
+
+// ============================================
+// COMBOBOX - Accessible autocomplete (atoms.Combobox)
+// ============================================
+const Combobox = {
+    init() {
+        document.addEventListener('input', (e) => {
+            if (e.target.matches('[data-combobox-input]')) {
+                const box = e.target.closest('[data-combobox]');
+                // Typing clears the choice until an option is picked again
+                box.querySelector('[data-combobox-value]').value = '';
+                this.update(box);
+            }
+        });
+
+        document.addEventListener('focusin', (e) => {
+            if (e.target.matches('[data-combobox-input]')) {
+                const box = e.target.closest('[data-combobox]');
+                if (!box.dataset.comboboxSource) this.update(box);
+            }
+        });
+
+        document.addEventListener('focusout', (e) => {
+            if (e.target.matches('[data-combobox-input]')) {
+                // Let a click on an option land before closing
+                setTimeout(() => this.close(e.target.closest('[data-combobox]')), 150);
+            }
+        });
+
+        document.addEventListener('mousedown', (e) => {
+            const option = e.target.closest('[data-combobox] [role="option"]');
+            if (option) {
+                e.preventDefault();
+                this.select(option.closest('[data-combobox]'), option);
+            }
+        });
+
+        document.addEventListener('keydown', (e) => {
+            if (!e.target.matches('[data-combobox-input]')) return;
+            const box = e.target.closest('[data-combobox]');
+            const options = this.options(box);
+            const index = options.findIndex((el) => el.hasAttribute('data-active'));
+            switch (e.key) {
+                case 'ArrowDown':
+                    e.preventDefault();
+                    if (!this.isOpen(box)) {
+                        this.update(box);
+                    } else {
+                        this.activate(box, options, Math.min(index + 1, options.length - 1));
+                    }
+                    break;
+                case 'ArrowUp':
+                    e.preventDefault();
+                    this.activate(box, options, Math.max(index - 1, 0));
+                    break;
+                case 'Home':
+                case 'End':
+                    if (this.isOpen(box)) {
+                        e.preventDefault();
+                        this.activate(box, options, e.key === 'Home' ? 0 : options.length - 1);
+                    }
+                    break;
+                case 'Enter':
+                    if (this.isOpen(box) && index >= 0) {
+                        e.preventDefault();
+                        this.select(box, options[index]);
+                    }
+                    break;
+                case 'Escape':
+                    if (this.isOpen(box)) {
+                        e.preventDefault();
+                        this.close(box);
+                    } else {
+                        e.target.value = '';
+                        box.querySelector('[data-combobox-value]').value = '';
+                    }
+                    break;
+            }
+        });
+    },
+
+    input(box) {
+        return box.querySelector('[data-combobox-input]');
+    },
+
+    listbox(box) {
+        return box.querySelector('[data-combobox-listbox]');
+    },
+
+    // Visible options, in order
+    options(box) {
+        return Array.from(this.listbox(box).querySelectorAll('[role="option"]')).filter((el) => !el.hidden);
+    },
+
+    isOpen(box) {
+        return !this.listbox(box).hidden;
+    },
+
+    // Filter the options for the query, or fetch them from the source URL
+    update(box) {
+        const query = this.input(box).value.trim();
+        const source = box.dataset.comboboxSource;
+        if (!source) {
+            const q = query.toLowerCase();
+            this.listbox(box).querySelectorAll('[role="option"]').forEach((el) => {
+                el.hidden = q !== '' && !el.dataset.label.toLowerCase().includes(q);
+            });
+            this.open(box);
+            return;
+        }
+        if (query.length < (parseInt(box.dataset.comboboxMinChars, 10) || 1)) {
+            this.close(box);
+            return;
+        }
+        clearTimeout(box._comboboxTimer);
+        box._comboboxTimer = setTimeout(() => this.fetch(box, source, query), 250);
+    },
+
+    fetch(box, source, query) {
+        const url = new URL(source, window.location.href);
+        url.searchParams.set('q', query);
+        fetch(url, { headers: { 'Accept': 'application/json' } })
+            .then((res) => (res.ok ? res.json() : []))
+            .then((items) => {
+                if (this.input(box).value.trim() !== query) return; // stale
+                const listbox = this.listbox(box);
+                const empty = listbox.querySelector('[data-combobox-empty]');
+                listbox.querySelectorAll('[role="option"]').forEach((el) => el.remove());
+                (items || []).forEach((item, i) => {
+                    const li = document.createElement('li');
+                    li.id = `${this.input(box).id}-option-${i}`;
+                    li.setAttribute('role', 'option');
+                    li.setAttribute('aria-selected', 'false');
+                    li.dataset.value = item.value;
+                    li.dataset.label = item.label;
+                    li.className = 'combobox-option cursor-pointer px-3 py-2 text-sm text-gray-700 dark:text-gray-200';
+                    li.innerHTML = `<span class="block truncate">${Utils.escapeHtml(item.label)}</span>` +
+                        (item.description ? `<span class="block truncate text-xs text-gray-500 dark:text-gray-400">${Utils.escapeHtml(item.description)}</span>` : '');
+                    listbox.insertBefore(li, empty);
+                });
+                this.open(box);
+            })
+            .catch(() => this.close(box));
+    },
+
+    open(box) {
+        const options = this.options(box);
+        const listbox = this.listbox(box);
+        listbox.querySelector('[data-combobox-empty]').hidden = options.length > 0;
+        listbox.hidden = false;
+        this.input(box).setAttribute('aria-expanded', 'true');
+        this.activate(box, options, -1);
+        const status = box.querySelector('[data-combobox-status]');
+        if (status) status.textContent = `${options.length} result${options.length === 1 ? '' : 's'}`;
+    },
+
+    close(box) {
+        if (!box) return;
+        this.listbox(box).hidden = true;
+        const input = this.input(box);
+        input.setAttribute('aria-expanded', 'false');
+        input.removeAttribute('aria-activedescendant');
+    },
+
+    // Highlight options[index] (none when -1) as the active descendant
+    activate(box, options, index) {
+        const input = this.input(box);
+        options.forEach((el, i) => el.toggleAttribute('data-active', i === index));
+        if (index >= 0 && options[index]) {
+            input.setAttribute('aria-activedescendant', options[index].id);
+            options[index].scrollIntoView({ block: 'nearest' });
+        } else {
+            input.removeAttribute('aria-activedescendant');
+        }
+    },
+
+    select(box, option) {
+        const input = this.input(box);
+        const hidden = box.querySelector('[data-combobox-value]');
+        this.listbox(box).querySelectorAll('[role="option"]').forEach((el) => {
+            el.setAttribute('aria-selected', el === option ? 'true' : 'false');
+        });
+        input.value = option.dataset.label;
+        hidden.value = option.dataset.value;
+        hidden.dispatchEvent(new Event('change', { bubbles: true }));
+        this.close(box);
+        input.focus();
+    }
+};
+
 // ============================================
 // THEME - Dark Mode Management
 // ============================================
 const Theme = {
     init() {
-        // Check for saved preference or system preference
-        const savedTheme = localStorage.getItem('theme');
-        const systemDark = window.matchMedia('(prefers-color-scheme: dark)').matches;
-        
-        if (savedTheme === 'dark' || (!savedTheme && systemDark)) {
-            document.documentElement.classList.add('dark');
+        // The server renders the dark class of the color mode of the user
+        // (html[data-color-mode]); the system mode follows the OS
+        const media = window.matchMedia('(prefers-color-scheme: dark)');
+        if (this.mode() === 'system') {
+            document.documentElement.classList.toggle('dark', media.matches);
         }
 
         // Listen for system theme changes
-        window.matchMedia('(prefers-color-scheme: dark)').addEventListener('change', (e) => {
-            if (!localStorage.getItem('theme')) {
+        media.addEventListener('change', (e) => {
+            if (this.mode() === 'system') {
                 document.documentElement.classList.toggle('dark', e.matches);
             }
         });
     },
 
+    // Color mode of the page: light, dark or system
+    mode() {
+        return document.documentElement.dataset.colorMode || localStorage.getItem('theme') || 'system';
+    },
+
     toggle() {
         const isDark = document.documentElement.classList.toggle('dark');
-        localStorage.setItem('theme', isDark ? 'dark' : 'light');
+        this.set(isDark ? 'dark' : 'light');
         return isDark;
     },
 
     set(theme) {
+        const root = document.documentElement;
+        root.dataset.colorMode = theme;
         if (theme === 'dark') {
-            document.documentElement.classList.add('dark');
+            root.classList.add('dark');
         } else if (theme === 'light') {
-            document.documentElement.classList.remove('dark');
+            root.classList.remove('dark');
         } else {
             localStorage.removeItem('theme');
             const systemDark = window.matchMedia('(prefers-color-scheme: dark)').matches;
-            document.documentElement.classList.toggle('dark', systemDark);
+            root.classList.toggle('dark', systemDark);
         }
         if (theme !== 'system') {
             localStorage.setItem('theme', theme);
@@ -716,6 +1812,44 @@ const Theme = {
 
     get() {
         return document.documentElement.classList.contains('dark') ? 'dark' : 'light';
+    },
+
+    // Switch to a color mode (see layouts.ColorMode) and save the preference
+    // of the user with a POST to url (the /api/color-mode route of the panel).
+    // Returns whether the page is dark, for the $darkMode signal.
+    setMode(mode, url) {
+        this.set(mode);
+        document.querySelectorAll('[data-color-mode-option]').forEach((el) => {
+            const selected = el.dataset.colorModeOption === mode;
+            el.setAttribute('aria-checked', selected ? 'true' : 'false');
+            const check = el.querySelector('[data-color-mode-check]');
+            if (check) check.classList.toggle('invisible', !selected);
+        });
+        this.save(url, { mode });
+        return this.get() === 'dark';
+    },
+
+    // Switch to a panel theme (see layouts.Theme) and save the preference
+    // of the user with a POST to url (the /api/theme route of the panel).
+    use(name, url) {
+        document.documentElement.dataset.theme = name;
+        document.querySelectorAll('[data-theme-option]').forEach((el) => {
+            const check = el.querySelector('.material-icons-outlined');
+            if (check) check.classList.toggle('invisible', el.dataset.themeOption !== name);
+        });
+        return this.save(url, { theme: name });
+    },
+
+    // POST a preference of the user with the CSRF token of the page
+    save(url, values) {
+        return fetch(url, {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/x-www-form-urlencoded',
+                'X-CSRF-Token': Utils.csrfToken()
+            },
+            body: new URLSearchParams(values)
+        });
     }
 };
 
@@ -1026,6 +2160,104 @@ const BulkActions = {
     }
 };
 
+// ============================================
+// FAVORITES - Pinned records and resources
+// ============================================
+const Favorites = {
+    init() {
+        document.addEventListener('click', (e) => {
+            const button = e.target.closest('[data-favorite-toggle]');
+            if (!button) return;
+            e.preventDefault();
+            e.stopPropagation();
+            this.toggle(button);
+        });
+    },
+
+    // Pin or unpin the record of the star with a POST to its
+    // data-favorite-toggle URL (the /api/favorites route of the panel)
+    async toggle(button) {
+        button.disabled = true;
+        try {
+            const res = await fetch(button.dataset.favoriteToggle, {
+                method: 'POST',
+                headers: {
+                    'Accept': 'application/json',
+                    'Content-Type': 'application/x-www-form-urlencoded',
+                    'X-CSRF-Token': Utils.csrfToken()
+                },
+                body: new URLSearchParams({
+                    resource: button.dataset.favoriteResource,
+                    id: button.dataset.favoriteId || ''
+                })
+            });
+            if (!res.ok) throw new Error(res.statusText);
+            const { pinned } = await res.json();
+            this.mark(button, pinned);
+        } catch (e) {
+            Toast.show('The favorite could not be saved.', 'error');
+        }
+        button.disabled = false;
+    },
+
+    mark(button, pinned) {
+        button.setAttribute('aria-pressed', pinned ? 'true' : 'false');
+        button.classList.toggle('text-amber-500', pinned);
+        button.classList.toggle('text-gray-400', !pinned);
+        const icon = button.querySelector('.material-icons-outlined');
+        if (icon) icon.textContent = pinned ? 'star' : 'star_border';
+    }
+};
+
+// ============================================
+// PASSWORD STRENGTH - Password policy rules of the new password fields
+// ============================================
+const PasswordStrength = {
+    checks: {
+        length: (value, min) => [...value].length >= min,
+        upper: (value) => /\p{Lu}/u.test(value),
+        lower: (value) => /\p{Ll}/u.test(value),
+        digit: (value) => /\p{Nd}/u.test(value),
+        symbol: (value) => /[^\p{L}\p{Nd}\s]/u.test(value)
+    },
+
+    // Meter colors from weak to strong
+    colors: ['bg-red-500', 'bg-orange-500', 'bg-yellow-500', 'bg-green-500'],
+
+    init() {
+        document.querySelectorAll('[data-password-strength]').forEach(meter => {
+            const input = document.getElementById(meter.dataset.passwordStrength);
+            if (!input) return;
+            input.addEventListener('input', () => this.update(meter, input.value));
+            this.update(meter, input.value);
+        });
+    },
+
+    // Tick the rules of the policy met by value and fill the meter with the
+    // share met, longer passwords scoring higher
+    update(meter, value) {
+        const min = parseInt(meter.dataset.passwordMin, 10) || 8;
+        const rules = meter.querySelectorAll('[data-password-rule]');
+        let met = 0;
+        rules.forEach(rule => {
+            const check = this.checks[rule.dataset.passwordRule];
+            const ok = value !== '' && (!check || check(value, min));
+            if (ok) met++;
+            rule.classList.toggle('text-green-600', ok);
+            const icon = rule.querySelector('[data-password-rule-icon]');
+            if (icon) icon.textContent = ok ? 'check_circle' : 'radio_button_unchecked';
+        });
+
+        let score = rules.length ? met / rules.length : 0;
+        if (score === 1 && [...value].length < min + 4) score = 0.75;
+        const bar = meter.querySelector('[data-password-meter]');
+        if (!bar) return;
+        bar.style.width = value === '' ? '0' : `${Math.max(score, 0.1) * 100}%`;
+        bar.classList.remove(...this.colors);
+        bar.classList.add(this.colors[Math.min(Math.floor(score * (this.colors.length - 1)), this.colors.length - 1)]);
+    }
+};
+
 // ============================================
 // INITIALIZATION
 // ============================================
@@ -1033,8 +2265,18 @@ document.addEventListener('DOMContentLoaded', () => {
     // Core UI modules
     Theme.init();
     Modal.init();
+    Confirm.init();
+    SlideOver.init();
+    ListTable.init();
+    TreeTable.init();
+    RelationTable.init();
     Toast.init();
     Dropdown.init();
+    DropdownMenu.init();
+    Combobox.init();
+    Favorites.init();
+    PasswordStrength.init();
+    FormValidator.init();
 
     // Datastar integration (replaces HTMX)
     DatastarIntegration.init();
@@ -1066,24 +2308,37 @@ window.SublimeGo = {
     Utils,
     DataTable,
     Modal,
+    Confirm,
+    SlideOver,
+    ListTable,
+    TreeTable,
+    RelationTable,
     Toast,
     SSEToast,
     FormValidator,
     Dropdown,
+    DropdownMenu,
+    Combobox,
     Theme,
     Sidebar,
     SidebarSync,
     DatastarIntegration,
-    BulkActions
+    BulkActions,
+    Favorites,
+    PasswordStrength
 };
 
 // Shortcuts
 window.Utils = Utils;
 window.DataTable = DataTable;
 window.Modal = Modal;
+window.Confirm = Confirm;
+window.SlideOver = SlideOver;
 window.Toast = Toast;
 window.FormValidator = FormValidator;
 window.Dropdown = Dropdown;
+window.DropdownMenu = DropdownMenu;
+window.Combobox = Combobox;
 window.Theme = Theme;
 window.Sidebar = Sidebar;
 window.SidebarSync = SidebarSync;
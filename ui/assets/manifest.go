@@ -0,0 +1,129 @@
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Prefix is the URL path the assets are served under.
+const Prefix = "/assets/"
+
+// hashLen is the number of hex digits of the content hash in a filename.
+const hashLen = 10
+
+// Manifest maps the files of a file system to hashed names, e.g.
+// "js/app.js" to "js/app.3f9c2a71be.js", so that a URL changes with the
+// content of its file: hashed URLs are cached forever, and an upgrade is
+// never served a stale file. The hashes are computed once, from files
+// embedded at build time.
+type Manifest struct {
+	fsys      fs.FS
+	once      sync.Once
+	hashed    map[string]string // name -> hashed name
+	original  map[string]string // hashed name -> name
+	integrity map[string]string // name -> subresource integrity
+}
+
+// NewManifest creates the manifest of the files of fsys.
+func NewManifest(fsys fs.FS) *Manifest {
+	return &Manifest{fsys: fsys}
+}
+
+// build hashes the files of the manifest.
+func (m *Manifest) build() {
+	m.hashed = make(map[string]string)
+	m.original = make(map[string]string)
+	m.integrity = make(map[string]string)
+	_ = fs.WalkDir(m.fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		data, err := fs.ReadFile(m.fsys, name)
+		if err != nil {
+			return nil
+		}
+		sum := sha256.Sum256(data)
+		ext := path.Ext(name)
+		hashed := strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:])[:hashLen] + ext
+		m.hashed[name] = hashed
+		m.original[hashed] = name
+		m.integrity[name] = "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+		return nil
+	})
+}
+
+// clean returns the name of an asset in the file system: "js/app.js",
+// "/js/app.js" and "/assets/js/app.js" all name "js/app.js".
+func clean(name string) string {
+	name = strings.TrimPrefix(name, Prefix)
+	return strings.TrimPrefix(name, "/")
+}
+
+// Path returns the hashed name of an asset, or its name when it is not in
+// the file system.
+func (m *Manifest) Path(name string) string {
+	m.once.Do(m.build)
+	name = clean(name)
+	if hashed, ok := m.hashed[name]; ok {
+		return hashed
+	}
+	return name
+}
+
+// URL returns the hashed URL of an asset under Prefix:
+// URL("js/app.js") => "/assets/js/app.3f9c2a71be.js".
+func (m *Manifest) URL(name string) string {
+	return Prefix + m.Path(name)
+}
+
+// Integrity returns the subresource integrity of an asset ("sha256-..."),
+// for the integrity attribute of its script or link tag, or "".
+func (m *Manifest) Integrity(name string) string {
+	m.once.Do(m.build)
+	return m.integrity[clean(name)]
+}
+
+// Handler serves the files of the manifest under their hashed names with
+// an immutable Cache-Control, and under their names with a no-cache one
+// and an ETag, so that a browser revalidates them. Mount it without Prefix:
+//
+//	mux.Handle("/assets/", http.StripPrefix("/assets", manifest.Handler()))
+func (m *Manifest) Handler() http.Handler {
+	files := http.FileServer(http.FS(m.fsys))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.once.Do(m.build)
+		name := clean(r.URL.Path)
+		if original, ok := m.original[name]; ok {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+			r2 := r.Clone(r.Context())
+			r2.URL.Path = "/" + original
+			files.ServeHTTP(w, r2)
+			return
+		}
+		if hashed, ok := m.hashed[name]; ok {
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("ETag", `"`+hashed+`"`)
+		}
+		files.ServeHTTP(w, r)
+	})
+}
+
+// Default is the manifest of the embedded assets (see FS).
+var Default = NewManifest(FS)
+
+// URL returns the hashed URL of an embedded asset, e.g.
+// URL("js/app.js") => "/assets/js/app.3f9c2a71be.js" (see Manifest.URL).
+func URL(name string) string { return Default.URL(name) }
+
+// Integrity returns the subresource integrity of an embedded asset (see
+// Manifest.Integrity).
+func Integrity(name string) string { return Default.Integrity(name) }
+
+// Handler serves the embedded assets (see Manifest.Handler).
+func Handler() http.Handler { return Default.Handler() }
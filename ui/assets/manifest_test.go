@@ -0,0 +1,56 @@
+package assets
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"testing/fstest"
+)
+
+func TestManifest(t *testing.T) {
+	m := NewManifest(fstest.MapFS{
+		"js/app.js":  {Data: []byte("console.log(1)")},
+		"styles.css": {Data: []byte("body{}")},
+	})
+
+	url := m.URL("js/app.js")
+	if !regexp.MustCompile(`^/assets/js/app\.[0-9a-f]{10}\.js$`).MatchString(url) {
+		t.Fatalf("URL = %q, want a hashed name", url)
+	}
+	if m.URL("/assets/js/app.js") != url || m.URL("/js/app.js") != url {
+		t.Error("expected the names of an asset to give the same URL")
+	}
+	if got := m.URL("favicon.ico"); got != "/assets/favicon.ico" {
+		t.Errorf("URL of a missing asset = %q", got)
+	}
+	if sri := m.Integrity("styles.css"); len(sri) < 10 || sri[:7] != "sha256-" {
+		t.Errorf("Integrity = %q", sri)
+	}
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		http.StripPrefix("/assets", m.Handler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+	rec := serve(url)
+	if rec.Code != http.StatusOK || rec.Body.String() != "console.log(1)" {
+		t.Fatalf("hashed URL served %d %q", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("Cache-Control") != "public, max-age=31536000, immutable" {
+		t.Errorf("hashed URL Cache-Control = %q", rec.Header().Get("Cache-Control"))
+	}
+	rec = serve("/assets/js/app.js")
+	if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != "no-cache" || rec.Header().Get("ETag") == "" {
+		t.Errorf("plain URL served %d with Cache-Control %q, ETag %q", rec.Code, rec.Header().Get("Cache-Control"), rec.Header().Get("ETag"))
+	}
+	if rec := serve("/assets/js/app.0000000000.js"); rec.Code != http.StatusNotFound {
+		t.Errorf("stale hashed URL served %d, want 404", rec.Code)
+	}
+}
+
+func TestURL_embedded(t *testing.T) {
+	if URL("js/app.js") == "/assets/js/app.js" {
+		t.Error("expected the embedded app.js to have a hashed URL")
+	}
+}
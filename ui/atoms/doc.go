@@ -12,6 +12,8 @@
 //   - Modals and popovers
 //   - Tables and pagination
 //   - Spinners and loading states
+//   - Accessible combobox, dropdown menu and toasts (ARIA roles, keyboard
+//     navigation; behavior in app.js)
 //
 // Basic usage:
 //
@@ -33,4 +35,25 @@
 //	@atoms.Card() {
 //		<p>Card content</p>
 //	}
+//
+//	// Combobox: options filtered in the browser, or fetched from SourceURL
+//	@atoms.Combobox(atoms.ComboboxProps{
+//		Name:      "author_id",
+//		Label:     "Author",
+//		SourceURL: "/admin/api/authors",
+//	})
+//
+//	// Dropdown menu
+//	@atoms.DropdownMenu(atoms.DropdownMenuProps{
+//		ID:    "row-actions",
+//		Label: "Actions",
+//		Items: []atoms.MenuItem{
+//			{Label: "Edit", Href: "/admin/posts/1/edit", Icon: "edit"},
+//			{Divider: true},
+//			{Label: "Delete", Icon: "delete", Danger: true, Attrs: templ.Attributes{"data-modal-open": "delete-1"}},
+//		},
+//	})
+//
+//	// Toasts: queued, auto-dismissed; Toast.show(...) adds more from JS
+//	@atoms.ToastRegion([]atoms.ToastProps{{Type: "success", Message: "Saved"}})
 package atoms
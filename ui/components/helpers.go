@@ -1,6 +1,31 @@
 package components
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/actions"
+)
+
+// openDeleteModal copies the data-delete-* attributes of the clicked button
+// into the DeleteModal signals and prepares the dialog from its
+// data-confirm-* attributes.
+const openDeleteModal = "const b=evt.target.closest('[data-delete-url]'); $deleteModalUrl=b.dataset.deleteUrl; $deleteModalTitle=b.dataset.deleteTitle; $deleteModalDesc=b.dataset.deleteDesc; $deleteModalOpen=true; SublimeGo.Confirm.prepare(document.getElementById('delete-modal'), b)"
+
+// deleteModalAttrs returns the attributes of a button opening the
+// DeleteModal for an action requiring confirmation.
+func deleteModalAttrs(a *actions.Action, item any) templ.Attributes {
+	return templ.Attributes{
+		"data-delete-url":      a.URL(item),
+		"data-delete-title":    a.ModalTitle,
+		"data-delete-desc":     a.ModalDescription,
+		"data-confirm-phrase":  a.ConfirmationPhrase(item),
+		"data-confirm-summary": a.SummaryURL(item),
+		"data-confirm-async":   strconv.FormatBool(a.Async),
+		"data-on-click":        openDeleteModal,
+	}
+}
 
 // extractRowID extracts a string ID from an item using the Identifiable interface,
 // or falls back to fmt.Sprintf for map[string]any and raw values.
@@ -12,6 +12,9 @@ import templruntime "github.com/a-h/templ/runtime"
 // Uses Alpine.js x-data for open/state management (scoped component, not Datastar signals).
 // Calls GET {searchURL}?q=query and expects JSON: {"results": [...], "total": N}
 // The searchURL is injected via a data-search-url attribute to avoid Go→HTML injection issues.
+// When the panel has favorites (meta favorites-url), the pinned records are
+// listed before typing and the ones matching the query come first.
+// Navigation results (kind "navigation") are badged "Go to".
 func GlobalSearchModal(searchURL string) templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
@@ -40,13 +43,13 @@ func GlobalSearchModal(searchURL string) templ.Component {
 		var templ_7745c5c3_Var2 string
 		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(searchURL)
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `ui/components/search_modal.templ`, Line: 10, Col: 29}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `search_modal.templ`, Line: 13, Col: 29}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "\" x-data=\"{\n\t\t\topen: false,\n\t\t\tquery: '',\n\t\t\tresults: [],\n\t\t\tloading: false,\n\t\t\topenModal() { this.open = true; this.$nextTick(() => this.$refs.searchInput?.focus()); },\n\t\t\tclose() { this.open = false; this.query = ''; this.results = []; },\n\t\t\tsearch() {\n\t\t\t\tif (!this.query || this.query.length < 2) { this.results = []; return; }\n\t\t\t\tthis.loading = true;\n\t\t\t\tconst el = document.getElementById('global-search-modal');\n\t\t\t\tconst url = el ? el.dataset.searchUrl : '/api/search';\n\t\t\t\tfetch(url + '?q=' + encodeURIComponent(this.query))\n\t\t\t\t\t.then(r => r.json())\n\t\t\t\t\t.then(data => {\n\t\t\t\t\t\tthis.results = Array.isArray(data) ? data : (data.results || []);\n\t\t\t\t\t\tthis.loading = false;\n\t\t\t\t\t})\n\t\t\t\t\t.catch(() => { this.loading = false; });\n\t\t\t}\n\t\t}\" x-init=\"\n\t\t\twindow.addEventListener('keydown', (e) => {\n\t\t\t\tif ((e.metaKey || e.ctrlKey) && e.key === 'k') { e.preventDefault(); openModal(); }\n\t\t\t});\n\t\t\tdocument.addEventListener('sublimego:search-open', () => openModal());\n\t\t\" @keydown.window.escape=\"close()\"><!-- Modal overlay — shown when open == true --><div x-show=\"open\" x-cloak class=\"fixed inset-0 z-50 overflow-y-auto p-4 sm:p-6 md:p-20\"><!-- Backdrop --><div @click=\"close()\" class=\"fixed inset-0 bg-gray-500/75 dark:bg-gray-900/80 transition-opacity\"></div><!-- Panel --><div class=\"relative mx-auto max-w-2xl bg-white dark:bg-gray-800 rounded-2xl shadow-2xl ring-1 ring-black/5 overflow-hidden\"><!-- Search input row --><div class=\"flex items-center gap-3 px-4 border-b border-gray-200 dark:border-gray-700\"><span class=\"material-icons-outlined text-gray-400\">search</span> <input x-ref=\"searchInput\" type=\"text\" x-model=\"query\" @input.debounce.300ms=\"search()\" @keydown.escape.prevent=\"close()\" class=\"w-full py-4 text-gray-900 dark:text-white bg-transparent border-0 outline-none placeholder-gray-400 text-base\" placeholder=\"Rechercher...\" autocomplete=\"off\"> <kbd class=\"hidden sm:flex items-center px-2 py-1 text-xs font-medium text-gray-400 border border-gray-300 dark:border-gray-600 rounded\">Esc</kbd></div><!-- Results area --><div class=\"max-h-96 overflow-y-auto py-2\"><!-- Loading spinner --><div x-show=\"loading\" class=\"flex items-center justify-center py-8\"><span class=\"material-icons-outlined animate-spin text-gray-400\">refresh</span></div><!-- No results --><div x-show=\"!loading && query && results.length === 0\" class=\"py-8 text-center text-sm text-gray-500 dark:text-gray-400\">Aucun résultat pour «&#160;<span x-text=\"query\" class=\"font-medium\"></span>&#160;»</div><!-- Results list --><template x-if=\"!loading && results.length > 0\"><ul class=\"divide-y divide-gray-100 dark:divide-gray-700\"><template x-for=\"result in results\" :key=\"result.id\"><li><a :href=\"result.url\" @click=\"close()\" class=\"flex items-center gap-3 px-4 py-3 hover:bg-gray-50 dark:hover:bg-gray-700 transition-colors\"><span class=\"material-icons-outlined text-gray-400 flex-shrink-0 text-xl\" x-text=\"result.icon || 'article'\"></span><div class=\"min-w-0 flex-1\"><p class=\"text-sm font-medium text-gray-900 dark:text-white truncate\" x-text=\"result.title\"></p><p x-show=\"result.subtitle\" class=\"text-xs text-gray-500 dark:text-gray-400 truncate\" x-text=\"result.subtitle\"></p></div><span class=\"ml-auto text-xs text-gray-400 dark:text-gray-500 flex-shrink-0\" x-text=\"result.resource_type\"></span></a></li></template></ul></template><!-- Default state — no query typed yet --><div x-show=\"!loading && !query\" class=\"py-8 text-center text-sm text-gray-500 dark:text-gray-400\"><span class=\"material-icons-outlined text-2xl text-gray-300 dark:text-gray-600 block mb-2\">search</span> Tapez pour rechercher...</div></div><!-- Footer hints --><div class=\"flex items-center justify-between px-4 py-2 border-t border-gray-200 dark:border-gray-700 text-xs text-gray-400\"><div class=\"flex items-center gap-3\"><span><kbd class=\"px-1.5 py-0.5 border border-gray-300 dark:border-gray-600 rounded\">↑↓</kbd> naviguer</span> <span><kbd class=\"px-1.5 py-0.5 border border-gray-300 dark:border-gray-600 rounded\">↵</kbd> ouvrir</span></div><span>SublimeAdmin Search</span></div></div></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "\" x-data=\"{\n\t\t\topen: false,\n\t\t\tquery: '',\n\t\t\tresults: [],\n\t\t\tloading: false,\n\t\t\tpinned: [],\n\t\t\topenModal() { this.open = true; this.loadPinned(); this.$nextTick(() => this.$refs.searchInput?.focus()); },\n\t\t\tclose() { this.open = false; this.query = ''; this.results = []; },\n\t\t\tloadPinned() {\n\t\t\t\tconst url = document.querySelector('meta[name=favorites-url]')?.content;\n\t\t\t\tif (!url) return;\n\t\t\t\tfetch(url, { headers: { 'Accept': 'application/json' } })\n\t\t\t\t\t.then(r => r.json())\n\t\t\t\t\t.then(data => { this.pinned = Array.isArray(data) ? data : []; })\n\t\t\t\t\t.catch(() => {});\n\t\t\t},\n\t\t\tpinnedMatches() {\n\t\t\t\tconst q = this.query.toLowerCase();\n\t\t\t\treturn this.pinned.filter(p => p.title.toLowerCase().includes(q) || (p.resource_type || '').toLowerCase().includes(q));\n\t\t\t},\n\t\t\tsearch() {\n\t\t\t\tif (!this.query || this.query.length < 2) { this.results = []; return; }\n\t\t\t\tthis.loading = true;\n\t\t\t\tconst el = document.getElementById('global-search-modal');\n\t\t\t\tconst url = el ? el.dataset.searchUrl : '/api/search';\n\t\t\t\tfetch(url + '?q=' + encodeURIComponent(this.query))\n\t\t\t\t\t.then(r => r.json())\n\t\t\t\t\t.then(data => {\n\t\t\t\t\t\tconst found = Array.isArray(data) ? data : (data.results || []);\n\t\t\t\t\t\tconst pinned = this.pinnedMatches();\n\t\t\t\t\t\tthis.results = pinned.concat(found.filter(r => !pinned.some(p => p.url === r.url)));\n\t\t\t\t\t\tthis.loading = false;\n\t\t\t\t\t})\n\t\t\t\t\t.catch(() => { this.loading = false; });\n\t\t\t}\n\t\t}\" x-init=\"\n\t\t\twindow.addEventListener('keydown', (e) => {\n\t\t\t\tif ((e.metaKey || e.ctrlKey) && e.key === 'k') { e.preventDefault(); openModal(); }\n\t\t\t});\n\t\t\tdocument.addEventListener('sublimego:search-open', () => openModal());\n\t\t\" @keydown.window.escape=\"close()\"><!-- Modal overlay — shown when open == true --><div x-show=\"open\" x-cloak class=\"fixed inset-0 z-50 overflow-y-auto p-4 sm:p-6 md:p-20\"><!-- Backdrop --><div @click=\"close()\" class=\"fixed inset-0 bg-gray-500/75 dark:bg-gray-900/80 transition-opacity\"></div><!-- Panel --><div class=\"relative mx-auto max-w-2xl bg-white dark:bg-gray-800 rounded-2xl shadow-2xl ring-1 ring-black/5 overflow-hidden\"><!-- Search input row --><div class=\"flex items-center gap-3 px-4 border-b border-gray-200 dark:border-gray-700\"><span class=\"material-icons-outlined text-gray-400\">search</span> <input x-ref=\"searchInput\" type=\"text\" x-model=\"query\" @input.debounce.300ms=\"search()\" @keydown.escape.prevent=\"close()\" class=\"w-full py-4 text-gray-900 dark:text-white bg-transparent border-0 outline-none placeholder-gray-400 text-base\" placeholder=\"Rechercher...\" autocomplete=\"off\"> <kbd class=\"hidden sm:flex items-center px-2 py-1 text-xs font-medium text-gray-400 border border-gray-300 dark:border-gray-600 rounded\">Esc</kbd></div><!-- Results area --><div class=\"max-h-96 overflow-y-auto py-2\"><!-- Loading spinner --><div x-show=\"loading\" class=\"flex items-center justify-center py-8\"><span class=\"material-icons-outlined animate-spin text-gray-400\">refresh</span></div><!-- No results --><div x-show=\"!loading && query && results.length === 0\" class=\"py-8 text-center text-sm text-gray-500 dark:text-gray-400\">Aucun résultat pour «&#160;<span x-text=\"query\" class=\"font-medium\"></span>&#160;»</div><!-- Results list --><template x-if=\"!loading && results.length > 0\"><ul class=\"divide-y divide-gray-100 dark:divide-gray-700\"><template x-for=\"result in results\" :key=\"result.id\"><li><a :href=\"result.url\" @click=\"close()\" class=\"flex items-center gap-3 px-4 py-3 hover:bg-gray-50 dark:hover:bg-gray-700 transition-colors\"><span class=\"material-icons-outlined text-gray-400 flex-shrink-0 text-xl\" x-text=\"result.icon || 'article'\"></span><div class=\"min-w-0 flex-1\"><p class=\"text-sm font-medium text-gray-900 dark:text-white truncate\" x-text=\"result.title\"></p><p x-show=\"result.subtitle\" class=\"text-xs text-gray-500 dark:text-gray-400 truncate\" x-text=\"result.subtitle\"></p></div><span class=\"ml-auto text-xs flex-shrink-0\" :class=\"result.kind === 'navigation' ? 'inline-flex items-center gap-1 px-1.5 py-0.5 rounded bg-primary-50 dark:bg-primary-900/30 text-primary-700 dark:text-primary-300 font-medium' : 'text-gray-400 dark:text-gray-500'\"><span x-text=\"result.resource_type\"></span> <span x-show=\"result.kind === 'navigation'\" class=\"material-icons-outlined text-sm\">arrow_forward</span></span></a></li></template></ul></template><!-- Pinned records — no query typed yet --><template x-if=\"!loading && !query && pinned.length > 0\"><div><p class=\"px-4 pt-2 pb-1 text-xs font-semibold uppercase tracking-wide text-gray-400\">Pinned</p><ul class=\"divide-y divide-gray-100 dark:divide-gray-700\"><template x-for=\"result in pinned\" :key=\"result.id\"><li><a :href=\"result.url\" @click=\"close()\" class=\"flex items-center gap-3 px-4 py-3 hover:bg-gray-50 dark:hover:bg-gray-700 transition-colors\"><span class=\"material-icons-outlined text-amber-500 flex-shrink-0 text-xl\">star</span><p class=\"min-w-0 flex-1 text-sm font-medium text-gray-900 dark:text-white truncate\" x-text=\"result.title\"></p><span class=\"ml-auto text-xs text-gray-400 dark:text-gray-500 flex-shrink-0\" x-text=\"result.resource_type\"></span></a></li></template></ul></div></template><!-- Default state — no query typed yet --><div x-show=\"!loading && !query && pinned.length === 0\" class=\"py-8 text-center text-sm text-gray-500 dark:text-gray-400\"><span class=\"material-icons-outlined text-2xl text-gray-300 dark:text-gray-600 block mb-2\">search</span> Tapez pour rechercher...</div></div><!-- Footer hints --><div class=\"flex items-center justify-between px-4 py-2 border-t border-gray-200 dark:border-gray-700 text-xs text-gray-400\"><div class=\"flex items-center gap-3\"><span><kbd class=\"px-1.5 py-0.5 border border-gray-300 dark:border-gray-600 rounded\">↑↓</kbd> naviguer</span> <span><kbd class=\"px-1.5 py-0.5 border border-gray-300 dark:border-gray-600 rounded\">↵</kbd> ouvrir</span></div><span>SublimeAdmin Search</span></div></div></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
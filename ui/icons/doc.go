@@ -29,4 +29,21 @@
 //	// In templates
 //	@icons.IconComponent("users")
 //	@icons.IconWithSize("settings", "6")
+//
+// Icon sets:
+//
+// Every icon name of the framework (navigation, actions, columns, widgets)
+// goes through Use, which looks the name up in the registry and falls back
+// to the Material Icons Outlined font; registered SVGs are referenced from
+// the sprite sheet of the panel (see Sprite). Projects add Heroicons, Lucide,
+// Tabler or their own SVGs:
+//
+//	icons.RegisterSet("lucide", lucideFS) // "lucide:rocket" is rocket.svg
+//	icons.Register("custom-logo", logoSVG)
+//	icons.SetFallback("lucide")           // names without a prefix try lucide first
+//
+//	@icons.Render("lucide:rocket", "text-xl text-primary-600") // inline SVG
+//	@icons.Use("lucide:rocket", "text-xl")                     // <use> of the sprite sheet
+//
+// Panel.EnableIconBrowser lists the available icons at /debug/icons.
 package icons
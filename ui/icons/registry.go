@@ -0,0 +1,233 @@
+package icons
+
+import (
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Names of the sets every panel has. Material Icons Outlined is the icon
+// font of the framework: it is the last step of the fallback chain and is
+// not listed, since any of its names works.
+const (
+	SetCustom   = "custom"   // SVGs added with Register
+	SetBuiltIn  = "sublime"  // the SVG icons of IconMap
+	SetMaterial = "material" // the Material Icons Outlined font
+)
+
+// Projects use their own icons anywhere the framework takes an icon name
+// (navigation, actions, columns, widgets...):
+//
+//	//go:embed heroicons/24/outline/*.svg
+//	var heroicons embed.FS
+//	sub, _ := fs.Sub(heroicons, "heroicons/24/outline")
+//	icons.RegisterSet("heroicons", sub)
+//	icons.Register("custom-logo", logoSVG)
+//
+//	engine.NavigationItem{Label: "Users", URL: "users", Icon: "heroicons:users"}
+//
+// A name is resolved in this order:
+//  1. "set:name" looks in that set only ("material:home" forces the font);
+//  2. the SVGs added with Register;
+//  3. the sets of the fallback chain (SetFallback), by default the sets
+//     added with RegisterSet in registration order;
+//  4. the Material Icons font.
+//
+// Sets are looked up with the name as is, then with underscores replaced by
+// dashes, so "arrow_upward" finds arrow-upward.svg.
+var (
+	registryMu sync.RWMutex
+	custom     = make(map[string]string)
+	sets       = make(map[string]*iconSet)
+	setOrder   []string
+	fallback   []string
+	version    int // bumped on each change of the icons, to rebuild the sprite
+)
+
+// iconSet is a directory of {name}.svg files, read on first use.
+type iconSet struct {
+	fsys  fs.FS
+	mu    sync.Mutex
+	cache map[string]string // name -> SVG, "" when missing
+}
+
+// Register adds an SVG icon under name, replacing any icon of that name.
+// The markup is trusted: only register SVGs you ship.
+func Register(name, svg string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	custom[name] = cleanSVG(svg)
+	version++
+}
+
+// RegisterSet adds a set of icons: the .svg files of fsys, named after their
+// path without the extension ("home.svg" is "home", "solid/home.svg" is
+// "solid/home"). Registering a name twice replaces the set.
+func RegisterSet(name string, fsys fs.FS) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := sets[name]; !ok {
+		setOrder = append(setOrder, name)
+	}
+	sets[name] = &iconSet{fsys: fsys, cache: make(map[string]string)}
+	version++
+}
+
+// SetFallback sets the sets searched, in order, for names without a set
+// prefix. Include SetBuiltIn to prefer the built-in SVGs over the font.
+func SetFallback(chain ...string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	fallback = append([]string(nil), chain...)
+}
+
+// Fallback returns the sets searched for names without a set prefix.
+func Fallback() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if fallback != nil {
+		return append([]string(nil), fallback...)
+	}
+	return append([]string(nil), setOrder...)
+}
+
+// Lookup returns the SVG of the named icon, or false when the name falls
+// back to the Material Icons font.
+func Lookup(name string) (string, bool) {
+	_, svg, ok := resolve(name)
+	return svg, ok
+}
+
+// resolve returns the SVG of the named icon and its qualified name
+// ("heroicons:arrow-up"), the id of its symbol in the sprite.
+func resolve(name string) (qualified, svg string, ok bool) {
+	if set, icon, ok := strings.Cut(name, ":"); ok {
+		return lookupIn(set, icon)
+	}
+	registryMu.RLock()
+	svg, ok = custom[name]
+	registryMu.RUnlock()
+	if ok {
+		return SetCustom + ":" + name, svg, true
+	}
+	for _, set := range Fallback() {
+		if qualified, svg, ok := lookupIn(set, name); ok {
+			return qualified, svg, true
+		}
+	}
+	return "", "", false
+}
+
+// FontName returns the Material Icons name of an icon: the name without its
+// set prefix.
+func FontName(name string) string {
+	if _, icon, ok := strings.Cut(name, ":"); ok {
+		return icon
+	}
+	return name
+}
+
+func lookupIn(set, name string) (string, string, bool) {
+	names := []string{name}
+	if dashed := strings.ReplaceAll(name, "_", "-"); dashed != name {
+		names = append(names, dashed)
+	}
+	for _, n := range names {
+		if svg := lookupExact(set, n); svg != "" {
+			return set + ":" + n, svg, true
+		}
+	}
+	return "", "", false
+}
+
+// lookupExact returns the SVG of an icon of a set, or "".
+func lookupExact(set, name string) string {
+	switch set {
+	case SetMaterial:
+		return ""
+	case SetCustom:
+		registryMu.RLock()
+		defer registryMu.RUnlock()
+		return custom[name]
+	case SetBuiltIn:
+		return string(IconMap[name])
+	}
+	registryMu.RLock()
+	s := sets[set]
+	registryMu.RUnlock()
+	if s == nil {
+		return ""
+	}
+	return s.get(name)
+}
+
+func (s *iconSet) get(name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if svg, ok := s.cache[name]; ok {
+		return svg
+	}
+	svg := ""
+	if file := name + ".svg"; fs.ValidPath(file) {
+		if data, err := fs.ReadFile(s.fsys, file); err == nil {
+			svg = cleanSVG(string(data))
+		}
+	}
+	s.cache[name] = svg
+	return svg
+}
+
+// Sets returns the names of the sets with SVG icons: SetCustom, the sets
+// added with RegisterSet, then SetBuiltIn.
+func Sets() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := append([]string{SetCustom}, setOrder...)
+	return append(names, SetBuiltIn)
+}
+
+// Names returns the sorted names of the icons of a set.
+func Names(set string) []string {
+	var names []string
+	switch set {
+	case SetCustom:
+		registryMu.RLock()
+		for name := range custom {
+			names = append(names, name)
+		}
+		registryMu.RUnlock()
+	case SetBuiltIn:
+		for name := range IconMap {
+			names = append(names, name)
+		}
+	default:
+		registryMu.RLock()
+		s := sets[set]
+		registryMu.RUnlock()
+		if s == nil {
+			return nil
+		}
+		_ = fs.WalkDir(s.fsys, ".", func(p string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && path.Ext(p) == ".svg" {
+				names = append(names, strings.TrimSuffix(p, ".svg"))
+			}
+			return nil
+		})
+	}
+	sort.Strings(names)
+	return names
+}
+
+// cleanSVG keeps the <svg> element of a file, without the XML prolog and
+// the comments around it.
+func cleanSVG(svg string) string {
+	if i := strings.Index(svg, "<svg"); i >= 0 {
+		svg = svg[i:]
+	}
+	if i := strings.LastIndex(svg, "</svg>"); i >= 0 {
+		svg = svg[:i+len("</svg>")]
+	}
+	return strings.TrimSpace(svg)
+}
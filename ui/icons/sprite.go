@@ -0,0 +1,144 @@
+package icons
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// SpritePath is the path of the sprite sheet under the panel path.
+const SpritePath = "/icons/sprite.svg"
+
+// The sprite sheet holds every SVG icon of the registry as a <symbol>, so
+// pages reference icons with a <use> (see Use) instead of inlining their
+// markup. It is built on first use, cached until an icon is registered,
+// and served with immutable caching under a versioned URL.
+var (
+	spriteMu      sync.Mutex
+	spriteSheet   []byte
+	spriteHash    string
+	spriteVersion = -1
+)
+
+// Sprite returns the sprite sheet and its content hash.
+func Sprite() ([]byte, string) {
+	registryMu.RLock()
+	v := version
+	registryMu.RUnlock()
+
+	spriteMu.Lock()
+	defer spriteMu.Unlock()
+	if spriteVersion != v {
+		var buf bytes.Buffer
+		writeSprite(&buf)
+		sum := sha256.Sum256(buf.Bytes())
+		spriteSheet, spriteHash, spriteVersion = buf.Bytes(), hex.EncodeToString(sum[:8]), v
+	}
+	return spriteSheet, spriteHash
+}
+
+// WriteSprite writes the sprite sheet to w, e.g. from a go:generate step
+// that ships it as a static file.
+func WriteSprite(w io.Writer) error {
+	sheet, _ := Sprite()
+	_, err := w.Write(sheet)
+	return err
+}
+
+func writeSprite(buf *bytes.Buffer) {
+	buf.WriteString(`<svg xmlns="http://www.w3.org/2000/svg">`)
+	for _, set := range Sets() {
+		for _, name := range Names(set) {
+			if svg := lookupExact(set, name); svg != "" {
+				buf.WriteString(symbol(set+":"+name, svg))
+			}
+		}
+	}
+	buf.WriteString(`</svg>`)
+}
+
+// symbolAttrs are the attributes of an <svg> kept on its <symbol>; the
+// others (size, class, xmlns) come from the page.
+var symbolAttrs = regexp.MustCompile(`\s(viewBox|fill|stroke|stroke-width|stroke-linecap|stroke-linejoin)="[^"]*"`)
+
+// symbol converts an SVG into a <symbol> of the sprite.
+func symbol(qualified, svg string) string {
+	open := strings.Index(svg, ">")
+	end := strings.LastIndex(svg, "</svg>")
+	if !strings.HasPrefix(svg, "<svg") || open < 0 || end < open {
+		return ""
+	}
+	attrs := strings.Join(symbolAttrs.FindAllString(svg[:open], -1), "")
+	return `<symbol id="` + SymbolID(qualified) + `"` + attrs + `>` + svg[open+1:end] + `</symbol>`
+}
+
+// SymbolID returns the id of the symbol of an icon in the sprite sheet:
+// "heroicons:arrow-up" is "i-heroicons-arrow-up".
+func SymbolID(qualified string) string {
+	var b strings.Builder
+	b.WriteString("i-")
+	for _, r := range qualified {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// SpriteURL returns the versioned URL of the sprite sheet of a panel.
+func SpriteURL(basePath string) string {
+	_, hash := Sprite()
+	return strings.TrimRight(basePath, "/") + SpritePath + "?v=" + hash
+}
+
+// SpriteHandler serves the sprite sheet. Requests for the current version
+// (?v={hash}) are cached for a year; the others must revalidate.
+func SpriteHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sheet, hash := Sprite()
+		etag := `"` + hash + `"`
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Header().Set("ETag", etag)
+		if r.URL.Query().Get("v") == hash {
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		} else {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write(sheet)
+	})
+}
+
+type spriteKey struct{}
+
+// WithSprite returns a context in which Use references the sprite sheet at
+// url (see SpriteURL). Panels set it on every request.
+func WithSprite(ctx context.Context, url string) context.Context {
+	return context.WithValue(ctx, spriteKey{}, url)
+}
+
+// spriteRef returns the <use> reference of the named icon, or false when
+// there is no sprite in ctx or the icon is not an SVG of the registry.
+func spriteRef(ctx context.Context, name string) (string, bool) {
+	url, _ := ctx.Value(spriteKey{}).(string)
+	if url == "" {
+		return "", false
+	}
+	qualified, _, ok := resolve(name)
+	if !ok {
+		return "", false
+	}
+	return url + "#" + SymbolID(qualified), true
+}
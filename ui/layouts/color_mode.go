@@ -0,0 +1,124 @@
+package layouts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ColorMode is the light/dark preference of a user.
+type ColorMode string
+
+const (
+	ColorModeLight  ColorMode = "light"
+	ColorModeDark   ColorMode = "dark"
+	ColorModeSystem ColorMode = "system" // follows the operating system
+)
+
+// ColorModes returns the color modes in the order of the topbar toggle.
+func ColorModes() []ColorMode {
+	return []ColorMode{ColorModeLight, ColorModeDark, ColorModeSystem}
+}
+
+// ParseColorMode returns the color mode named s, or false.
+func ParseColorMode(s string) (ColorMode, bool) {
+	switch m := ColorMode(s); m {
+	case ColorModeLight, ColorModeDark, ColorModeSystem:
+		return m, true
+	}
+	return "", false
+}
+
+// Label returns the name of the mode shown in the toggle.
+func (m ColorMode) Label() string {
+	switch m {
+	case ColorModeLight:
+		return "Clair"
+	case ColorModeDark:
+		return "Sombre"
+	default:
+		return "Système"
+	}
+}
+
+// Icon returns the Material icon of the mode.
+func (m ColorMode) Icon() string {
+	switch m {
+	case ColorModeLight:
+		return "light_mode"
+	case ColorModeDark:
+		return "dark_mode"
+	default:
+		return "contrast"
+	}
+}
+
+type colorModeKey struct{}
+type systemDarkKey struct{}
+
+// WithColorMode returns a new context carrying the color mode of the user.
+func WithColorMode(ctx context.Context, mode ColorMode) context.Context {
+	return context.WithValue(ctx, colorModeKey{}, mode)
+}
+
+// WithSystemDark returns a new context recording that the browser asked for
+// a dark color scheme (the Sec-CH-Prefers-Color-Scheme client hint), so the
+// system mode renders dark on the server.
+func WithSystemDark(ctx context.Context, dark bool) context.Context {
+	return context.WithValue(ctx, systemDarkKey{}, dark)
+}
+
+// CurrentColorMode returns the color mode of the request: the preference of
+// the user, else dark for panels with DarkMode, else system.
+func CurrentColorMode(ctx context.Context) ColorMode {
+	if m, ok := ctx.Value(colorModeKey{}).(ColorMode); ok && m != "" {
+		return m
+	}
+	if GetPanelConfigFromContext(ctx).DarkMode {
+		return ColorModeDark
+	}
+	return ColorModeSystem
+}
+
+// renderDark reports whether the page is rendered with the dark class, so
+// the browser paints the right colors before any script runs.
+func renderDark(ctx context.Context) bool {
+	switch CurrentColorMode(ctx) {
+	case ColorModeDark:
+		return true
+	case ColorModeSystem:
+		dark, _ := ctx.Value(systemDarkKey{}).(bool)
+		return dark
+	default:
+		return false
+	}
+}
+
+// htmlClass returns the classes of the html element.
+func htmlClass(ctx context.Context) string {
+	if renderDark(ctx) {
+		return "h-full dark"
+	}
+	return "h-full"
+}
+
+// darkModeSignal returns the initial expression of the $darkMode signal.
+func darkModeSignal(mode ColorMode) string {
+	switch mode {
+	case ColorModeDark:
+		return "true"
+	case ColorModeLight:
+		return "false"
+	default:
+		return "window.matchMedia('(prefers-color-scheme: dark)').matches"
+	}
+}
+
+// colorModeSwitchExpr returns the Datastar expression of a color mode
+// option: Theme.setMode (app.js) applies the mode, saves the preference and
+// returns whether the page is dark.
+func colorModeSwitchExpr(mode ColorMode, url string) string {
+	m, _ := json.Marshal(string(mode))
+	u, _ := json.Marshal(url)
+	return fmt.Sprintf("$darkMode = Theme.setMode(%s, %s); $colorModeMenuOpen = false", m, u)
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/ui/assets"
 )
 
 // FooterLink represents a link in the footer
@@ -21,11 +24,15 @@ type PanelConfig struct {
 	PrimaryColor string // Accent color: green, blue, red, purple, orange, pink, indigo
 	DarkMode     bool   // Enable dark mode by default
 
+	Theme  string  // Default theme (ex: "ocean"; default: "default")
+	Themes []Theme // Themes the users can switch between (see theme.go)
+
 	Registration      bool // Enable /register route
 	EmailVerification bool // Enable email verification flow
 	PasswordReset     bool // Enable /forgot-password route
 	Profile           bool // Enable /profile page
 	Notifications     bool // Enable notification bell + SSE
+	Favorites         bool // Enable pinned records (star toggles, sidebar and palette)
 
 	SidebarCollapsible bool // Enable sidebar collapse on desktop (w-64 <-> w-20)
 
@@ -34,6 +41,11 @@ type PanelConfig struct {
 	FooterLinks     []FooterLink // Footer links
 
 	Navigation []NavItem // Navigation items
+
+	Switcher func(ctx context.Context) []SwitcherGroup // Panels and tenants of the topbar switcher
+
+	Stylesheets []string // Extra stylesheets (plugins, themes)
+	Scripts     []string // Extra deferred scripts (plugins)
 }
 
 // DefaultPanelConfig returns the default configuration
@@ -92,6 +104,25 @@ func GetPanelConfig() *PanelConfig {
 	return panelConfig
 }
 
+// AssetURL returns the URL of a local asset of the current panel, e.g.
+// AssetURL("/assets/plugins/blog/blog.css") => "/admin/assets/plugins/blog/blog.css".
+// Absolute URLs are returned unchanged.
+//
+// It uses the global configuration; templates of a request should prefer the
+// base path of GetPanelConfigFromContext, which differs between panels.
+func AssetURL(asset string) string {
+	return localAssetURL(GetPanelConfig().Path, asset)
+}
+
+// localAssetURL returns the URL of a local asset under basePath; absolute
+// URLs are returned unchanged.
+func localAssetURL(basePath, asset string) string {
+	if !strings.HasPrefix(asset, "/") || strings.HasPrefix(asset, "//") {
+		return asset
+	}
+	return assetPath(basePath, asset)
+}
+
 // assetPath returns the full path for a local asset, prefixed with the panel's base path.
 // e.g. assetPath("/admin", "/assets/css/output.css") => "/admin/assets/css/output.css"
 // If the panel is at "/", it returns "/assets/css/output.css" (no double slash).
@@ -102,6 +133,22 @@ func assetPath(basePath, asset string) string {
 	return strings.TrimRight(basePath, "/") + asset
 }
 
+// coreAsset returns the hashed URL of an embedded asset under the panel's
+// base path (see assets.URL).
+// e.g. coreAsset("/admin", "js/app.js") => "/admin/assets/js/app.3f9c2a71be.js"
+func coreAsset(basePath, name string) string {
+	return assetPath(basePath, assets.URL(name))
+}
+
+// integrity returns the integrity attribute of an embedded asset, so that
+// a strict CSP can require it.
+func integrity(name string) templ.Attributes {
+	if sri := assets.Integrity(name); sri != "" {
+		return templ.Attributes{"integrity": sri}
+	}
+	return nil
+}
+
 // navLink builds a navigation URL prefixed with the panel's base path.
 // e.g. navLink("/platform", "users") => "/platform/users"
 // e.g. navLink("/", "users") => "/users"
@@ -114,12 +161,10 @@ func navLink(basePath, slug string) string {
 }
 
 // initSignals returns the Datastar data-signals expression string for the root html element.
-// It initialises all global layout signals: darkMode, sidebar, dropdowns, modals.
-func initSignals(defaultDark bool, collapsible bool) string {
-	darkExpr := "localStorage.getItem('theme')==='dark'||(!localStorage.getItem('theme')&&window.matchMedia('(prefers-color-scheme: dark)').matches)"
-	if defaultDark {
-		darkExpr = "localStorage.getItem('theme')!=='light'"
-	}
+// It initialises all global layout signals: darkMode (from the color mode of
+// the user), sidebar, dropdowns, modals.
+func initSignals(mode ColorMode, collapsible bool) string {
+	darkExpr := darkModeSignal(mode)
 	sidebarExpr := "localStorage.getItem('sidebarCollapsed')!=='true'"
 	if !collapsible {
 		sidebarExpr = "true"
@@ -127,7 +172,7 @@ func initSignals(defaultDark bool, collapsible bool) string {
 	return "{darkMode:" + darkExpr + ",sidebarOpen:" + sidebarExpr +
 		",sidebarMobileOpen:false" +
 		",notifOpen:false,notifUnread:0" +
-		",userMenuOpen:false" +
+		",userMenuOpen:false,themeMenuOpen:false,colorModeMenuOpen:false,switcherOpen:false" +
 		",deleteModalOpen:false,deleteModalUrl:'',deleteModalTitle:'',deleteModalDesc:''" +
 		",bulkModalOpen:false,bulkModalTitle:'',bulkModalDesc:'',bulkModalAction:''}"
 }
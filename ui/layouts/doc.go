@@ -9,8 +9,9 @@
 //   - Responsive sidebar with collapsible groups
 //   - Topbar with user menu and notifications
 //   - Flash message display
-//   - Dark mode support
+//   - Light, dark and system color modes, rendered on the server
 //   - Panel configuration
+//   - Render hooks injecting components into the layout (RegisterHook)
 //
 // Basic usage:
 //
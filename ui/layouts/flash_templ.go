@@ -8,7 +8,12 @@ package layouts
 import "github.com/a-h/templ"
 import templruntime "github.com/a-h/templ/runtime"
 
-import "github.com/bozz33/sublimeadmin/ui/atoms"
+import (
+	"strconv"
+
+	"github.com/bozz33/sublimeadmin/flash"
+	"github.com/bozz33/sublimeadmin/ui/atoms"
+)
 
 // FlashType: "success", "error", "warning", "info"
 type FlashMessage struct {
@@ -61,18 +66,12 @@ func FlashContainer(messages []FlashMessage) templ.Component {
 }
 
 func getFlashID(index int) string {
-	return "flash-" + intToStr(index)
-}
-
-func intToStr(i int) string {
-	if i < 10 {
-		return string(rune('0' + i))
-	}
-	return "0"
+	return "flash-" + strconv.Itoa(index)
 }
 
-// Flash - Empty component by default (messages are injected via HTMX/JS).
-// This component is called in base.templ for the initial container
+// Flash renders the flash messages of the request (see middleware.Flash) as
+// the stacked toast region of the page. Toast (app.js) adds the toasts shown
+// later on, including the flash messages of HTMX responses.
 func Flash() templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
 		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
@@ -94,7 +93,7 @@ func Flash() templ.Component {
 			templ_7745c5c3_Var2 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<!-- Flash messages will be injected here via HTMX or JS Toast -->")
+		templ_7745c5c3_Err = atoms.ToastRegion(flashToasts(flash.MessagesFromContext(ctx))).Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -102,4 +101,39 @@ func Flash() templ.Component {
 	})
 }
 
+// flashToasts maps flash messages to toasts. Errors stay until closed, and
+// messages with actions ("Undo") are left longer on screen.
+func flashToasts(messages []*flash.Message) []atoms.ToastProps {
+	toasts := make([]atoms.ToastProps, 0, len(messages))
+	for i, msg := range messages {
+		t := atoms.ToastProps{
+			ID:      getFlashID(i),
+			Title:   msg.Title,
+			Message: msg.Text,
+			Type:    flashLevel(msg.Type),
+		}
+		for _, a := range msg.Actions {
+			t.Actions = append(t.Actions, atoms.ToastAction{Label: a.Label, URL: a.URL, Method: a.Method})
+		}
+		switch {
+		case t.Type == flash.TypeError:
+			t.Duration = -1
+		case len(t.Actions) > 0:
+			t.Duration = 10000
+		}
+		toasts = append(toasts, t)
+	}
+	return toasts
+}
+
+// flashLevel returns the toast type of a message type, "info" when unknown.
+func flashLevel(msgType string) string {
+	switch msgType {
+	case flash.TypeSuccess, flash.TypeError, flash.TypeWarning:
+		return msgType
+	default:
+		return flash.TypeInfo
+	}
+}
+
 var _ = templruntime.GeneratedTemplate
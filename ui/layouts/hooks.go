@@ -0,0 +1,83 @@
+package layouts
+
+import (
+	"context"
+	"sync"
+
+	"github.com/a-h/templ"
+)
+
+// Render hooks are named slots of the layout where packages and user code
+// inject components without forking the templates:
+//
+//	layouts.RegisterHook(layouts.HookTopbarEnd, myStatusBadge)
+//	layouts.RegisterHook("sidebar.footer", versionInfo)
+//
+// The components receive the context of the page (panel config, user,
+// current path...). Hooks registered with RegisterHook are global; a panel
+// scopes its own with Panel.WithRenderHook.
+const (
+	HookHeadEnd           = "head.end"            // end of <head>: meta tags, styles
+	HookBodyStart         = "body.start"          // start of <body>
+	HookBodyEnd           = "body.end"            // end of <body>: modals, scripts
+	HookSidebarNavStart   = "sidebar.nav.start"   // above the navigation
+	HookSidebarNavEnd     = "sidebar.nav.end"     // below the navigation
+	HookSidebarFooter     = "sidebar.footer"      // bottom of the sidebar
+	HookTopbarStart       = "topbar.start"        // after the breadcrumbs
+	HookTopbarEnd         = "topbar.end"          // after the user menu
+	HookContentStart      = "content.start"       // above the page content
+	HookContentEnd        = "content.end"         // below the page content
+	HookPageHeaderActions = "page.header.actions" // next to the action of PageHeader
+	HookFooter            = "footer"              // in the footer
+)
+
+var (
+	hooksMu sync.RWMutex
+	hooks   = make(map[string][]templ.Component)
+)
+
+// RegisterHook appends a component to the named render hook of every panel.
+func RegisterHook(name string, component templ.Component) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	hooks[name] = append(hooks[name], component)
+}
+
+// ResetHooks removes the components of the named hooks, or of every hook
+// when no name is given.
+func ResetHooks(names ...string) {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	if len(names) == 0 {
+		hooks = make(map[string][]templ.Component)
+		return
+	}
+	for _, name := range names {
+		delete(hooks, name)
+	}
+}
+
+type hooksKey struct{}
+
+// WithHooks returns a context carrying render hooks scoped to a request,
+// rendered after the global ones. Panels use it for their own hooks.
+func WithHooks(ctx context.Context, scoped map[string][]templ.Component) context.Context {
+	return context.WithValue(ctx, hooksKey{}, scoped)
+}
+
+// Hooks returns the components of the named hook: the global ones, then
+// the ones of the context.
+func Hooks(ctx context.Context, name string) []templ.Component {
+	hooksMu.RLock()
+	components := append([]templ.Component(nil), hooks[name]...)
+	hooksMu.RUnlock()
+	if scoped, ok := ctx.Value(hooksKey{}).(map[string][]templ.Component); ok {
+		components = append(components, scoped[name]...)
+	}
+	return components
+}
+
+// HasHooks reports whether the named hook has components.
+func HasHooks(ctx context.Context, name string) bool {
+	return len(Hooks(ctx, name)) > 0
+}
@@ -1,6 +1,12 @@
 package layouts
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
 
 type navGroupsKey struct{}
 
@@ -17,3 +23,183 @@ func GetNavGroups(ctx context.Context) []NavGroup {
 	}
 	return navGroups
 }
+
+type currentPathKey struct{}
+
+// WithCurrentPath returns a context carrying the path of the request, used
+// for the active state of the navigation items.
+func WithCurrentPath(ctx context.Context, path string) context.Context {
+	return context.WithValue(ctx, currentPathKey{}, path)
+}
+
+// CurrentPath returns the path of the request, or "".
+func CurrentPath(ctx context.Context) string {
+	path, _ := ctx.Value(currentPathKey{}).(string)
+	return path
+}
+
+// ResolveNavGroups prepares the navigation of a request: it drops the
+// groups and items hidden by their VisibleWhen predicate, adds the items
+// of ItemsFunc, computes the badges of BadgeFunc and marks the active
+// items. Groups left empty are
+// dropped. The sidebar calls it on each render.
+func ResolveNavGroups(ctx context.Context, groups []NavGroup) []NavGroup {
+	basePath := GetPanelConfigFromContext(ctx).Path
+	path := CurrentPath(ctx)
+	out := make([]NavGroup, 0, len(groups))
+	for _, g := range groups {
+		if g.VisibleWhen != nil && !g.VisibleWhen(ctx) {
+			continue
+		}
+		if g.ItemsFunc != nil {
+			g.Items = append(append([]NavItem(nil), g.Items...), g.ItemsFunc(ctx)...)
+		}
+		g.Items = resolveNavItems(ctx, basePath, path, g.Items)
+		if len(g.Items) == 0 {
+			continue
+		}
+		out = append(out, g)
+	}
+	return out
+}
+
+func resolveNavItems(ctx context.Context, basePath, path string, items []NavItem) []NavItem {
+	out := make([]NavItem, 0, len(items))
+	for _, item := range items {
+		if item.VisibleWhen != nil && !item.VisibleWhen(ctx) {
+			continue
+		}
+		hadChildren := len(item.Children) > 0
+		item.Children = resolveNavItems(ctx, basePath, path, item.Children)
+		if hadChildren && len(item.Children) == 0 && item.Slug == "" {
+			continue
+		}
+		if item.BadgeFunc != nil {
+			item.Badge, item.BadgeColor = item.BadgeFunc(ctx)
+		}
+		if !item.Active && path != "" {
+			item.Active = navItemActive(basePath, path, item)
+		}
+		for _, child := range item.Children {
+			item.Active = item.Active || child.Active
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+// navItemActive reports whether the item links to the request path (or a
+// sub-path: /users/42/edit activates /users).
+func navItemActive(basePath, path string, item NavItem) bool {
+	if item.ActivePattern != "" {
+		re, err := compileNavPattern(item.ActivePattern)
+		return err == nil && re.MatchString(path)
+	}
+	if item.Slug == "" || isExternalURL(item.Slug) {
+		return false
+	}
+	for _, href := range []string{navHref(basePath, item.Slug), navHref("", item.Slug)} {
+		href = strings.TrimRight(href, "/")
+		if href == "" {
+			// The dashboard is only active on itself.
+			if path == "/" || path == strings.TrimRight(basePath, "/") || path == strings.TrimRight(basePath, "/")+"/" {
+				return true
+			}
+			continue
+		}
+		if path == href || strings.HasPrefix(path, href+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+var navPatterns sync.Map // map[string]*regexp.Regexp
+
+func compileNavPattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := navPatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	navPatterns.Store(pattern, re)
+	return re, nil
+}
+
+// isExternalURL reports whether slug is an absolute URL.
+func isExternalURL(slug string) bool {
+	return strings.HasPrefix(slug, "https://") || strings.HasPrefix(slug, "http://") || strings.HasPrefix(slug, "//")
+}
+
+// navHref returns the link of a navigation item: absolute URLs and paths
+// are kept, slugs are prefixed with the panel path.
+func navHref(basePath, slug string) string {
+	if isExternalURL(slug) || strings.HasPrefix(slug, "/") {
+		return slug
+	}
+	return navLink(basePath, slug)
+}
+
+// navItemExternal reports whether the item opens in a new tab.
+func navItemExternal(item NavItem) bool {
+	return item.External || isExternalURL(item.Slug)
+}
+
+// navSignal returns a Datastar signal name for a sidebar element; the
+// signals are global, so each group and submenu gets its own.
+func navSignal(prefix, label string) string {
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	for _, r := range strings.ToLower(label) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			sb.WriteRune(r)
+		default:
+			sb.WriteString(fmt.Sprintf("_%x", r))
+		}
+	}
+	return sb.String()
+}
+
+// navGroupSignals returns the data-signals of a collapsible group: open
+// when the browser saved it open, else when it is not collapsed by
+// default or holds the active item.
+func navGroupSignals(signal string, g NavGroup) string {
+	open := !g.Collapsed
+	for _, item := range g.Items {
+		open = open || item.Active
+	}
+	return fmt.Sprintf("{%s: localStorage.getItem('sidebar.%s') ? localStorage.getItem('sidebar.%s') === 'open' : %t}", signal, signal, signal, open)
+}
+
+// navGroupToggle returns the click handler folding a group.
+func navGroupToggle(signal string) string {
+	return fmt.Sprintf("$%s = !$%s; localStorage.setItem('sidebar.%s', $%s ? 'open' : 'closed')", signal, signal, signal, signal)
+}
+
+// navSubmenuSignals returns the data-signals of a submenu, open when it
+// holds the active item.
+func navSubmenuSignals(signal string, item NavItem) string {
+	return fmt.Sprintf("{%s: %t}", signal, item.Active)
+}
+
+// navBadgeClasses returns the classes of a navigation badge.
+func navBadgeClasses(color string) string {
+	classes := "ml-auto px-2 py-0.5 text-xs font-medium rounded-full "
+	switch color {
+	case "red", "danger":
+		return classes + "bg-red-100 text-red-700 dark:bg-red-900/30 dark:text-red-400"
+	case "green", "success":
+		return classes + "bg-green-100 text-green-700 dark:bg-green-900/30 dark:text-green-400"
+	case "yellow", "amber", "warning":
+		return classes + "bg-yellow-100 text-yellow-700 dark:bg-yellow-900/30 dark:text-yellow-400"
+	case "blue", "info":
+		return classes + "bg-blue-100 text-blue-700 dark:bg-blue-900/30 dark:text-blue-400"
+	case "gray", "secondary":
+		return classes + "bg-gray-100 text-gray-700 dark:bg-gray-700 dark:text-gray-300"
+	default:
+		return classes + "bg-primary-100 text-primary-700 dark:bg-primary-900/30 dark:text-primary-400"
+	}
+}
@@ -0,0 +1,229 @@
+package layouts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bozz33/sublimeadmin/color"
+)
+
+// Theme is a visual theme of the panel. Themes are rendered as CSS
+// variables overriding the Tailwind theme of the compiled stylesheet, so
+// every component follows them without being recompiled.
+//
+// CSS variable contract — a custom stylesheet (see Panel.WithStylesheets)
+// can set these variables on :root[data-theme="name"] and
+// :root.dark[data-theme="name"] to ship a fully custom theme:
+//
+//	--font-sans                          body font stack
+//	--spacing                            spacing unit (density), 0.25rem by default
+//	--radius-md, -lg, -xl, -2xl          corner radii
+//	--color-primary-{50..950}            accent color (also --primary-{n})
+//	--color-gray-{50..950}               neutral color: text, borders, surfaces
+//	--color-danger-{50..950}             also applied to the red utilities
+//	--color-success-{50..950}            also applied to the green utilities
+//	--color-warning-{50..950}            also applied to the amber and yellow utilities
+//	--color-info-{50..950}               also applied to the blue utilities
+type Theme struct {
+	Name    string  // Identifier, stored as the preference of the users
+	Label   string  // Name shown in the theme switcher (default: Name)
+	Font    string  // Font stack (ex: "'Nunito', sans-serif")
+	FontURL string  // Stylesheet loading the font (optional)
+	Radius  string  // Base corner radius of cards and inputs (ex: "0.5rem")
+	Density Density // Spacing scale
+	Light   ThemeColors
+	Dark    ThemeColors // Colors in dark mode; empty colors fall back to Light
+}
+
+// ThemeColors are the semantic colors of a theme. Each color is the name of
+// a palette of the color package ("blue", "slate") or a hex or RGB color
+// from which a palette is generated ("#3b82f6"). Empty colors keep the
+// defaults of the panel.
+type ThemeColors struct {
+	Primary string
+	Gray    string
+	Danger  string
+	Success string
+	Warning string
+	Info    string
+}
+
+// Density is the spacing scale of a theme.
+type Density string
+
+const (
+	DensityCompact     Density = "compact"
+	DensityComfortable Density = "comfortable" // default
+	DensitySpacious    Density = "spacious"
+)
+
+// densitySpacing maps the densities to the Tailwind spacing unit.
+var densitySpacing = map[Density]string{
+	DensityCompact:     "0.225rem",
+	DensityComfortable: "0.25rem",
+	DensitySpacious:    "0.275rem",
+}
+
+// DefaultThemeName is the name of the theme of panels that set none.
+const DefaultThemeName = "default"
+
+// Built-in themes.
+var (
+	// DefaultTheme keeps the colors of the panel (PrimaryColor) and the
+	// compiled stylesheet.
+	DefaultTheme = Theme{Name: DefaultThemeName, Label: "Default"}
+
+	// OceanTheme is blue on cool slate grays.
+	OceanTheme = Theme{
+		Name: "ocean", Label: "Ocean",
+		Radius: "0.75rem",
+		Light:  ThemeColors{Primary: "blue", Gray: "slate", Info: "cyan"},
+	}
+
+	// ForestTheme is emerald on warm stone grays.
+	ForestTheme = Theme{
+		Name: "forest", Label: "Forest",
+		Light: ThemeColors{Primary: "emerald", Gray: "stone", Success: "green", Warning: "amber"},
+	}
+
+	// GrapeTheme is purple on neutral zinc grays, with rounder corners.
+	GrapeTheme = Theme{
+		Name: "grape", Label: "Grape",
+		Radius: "1rem",
+		Light:  ThemeColors{Primary: "purple", Gray: "zinc", Danger: "rose"},
+		Dark:   ThemeColors{Primary: "#c084fc"},
+	}
+
+	// CompactTheme is a dense indigo theme with square corners, for data
+	// heavy back offices.
+	CompactTheme = Theme{
+		Name: "compact", Label: "Compact",
+		Font:    "ui-sans-serif, system-ui, -apple-system, 'Segoe UI', sans-serif",
+		Radius:  "0.25rem",
+		Density: DensityCompact,
+		Light:   ThemeColors{Primary: "indigo", Gray: "slate"},
+	}
+)
+
+// BuiltInThemes returns the built-in themes, the default one first.
+func BuiltInThemes() []Theme {
+	return []Theme{DefaultTheme, OceanTheme, ForestTheme, GrapeTheme, CompactTheme}
+}
+
+// DisplayLabel returns the label of the theme, or its name.
+func (t Theme) DisplayLabel() string {
+	if t.Label != "" {
+		return t.Label
+	}
+	return t.Name
+}
+
+// CSS returns the CSS variables of the theme, scoped to
+// :root[data-theme="name"], or "" when the theme overrides nothing.
+func (t Theme) CSS() string {
+	selector := fmt.Sprintf(":root[data-theme=%q]", t.Name)
+	var sb strings.Builder
+
+	var decls []string
+	if t.Font != "" {
+		decls = append(decls, "--font-sans:"+t.Font)
+	}
+	if spacing, ok := densitySpacing[t.Density]; ok && t.Density != DensityComfortable {
+		decls = append(decls, "--spacing:"+spacing)
+	}
+	if t.Radius != "" {
+		decls = append(decls,
+			fmt.Sprintf("--radius-md:calc(%s * 0.75)", t.Radius),
+			"--radius-lg:"+t.Radius,
+			fmt.Sprintf("--radius-xl:calc(%s * 1.5)", t.Radius),
+			fmt.Sprintf("--radius-2xl:calc(%s * 2)", t.Radius),
+		)
+	}
+	decls = append(decls, t.Light.cssVars()...)
+	if len(decls) > 0 {
+		sb.WriteString(selector + "{" + strings.Join(decls, ";") + "}")
+	}
+	if dark := t.Dark.cssVars(); len(dark) > 0 {
+		sb.WriteString(fmt.Sprintf(":root.dark[data-theme=%q]{%s}", t.Name, strings.Join(dark, ";")))
+	}
+	return sb.String()
+}
+
+// cssVars returns the declarations of the semantic colors.
+func (c ThemeColors) cssVars() []string {
+	var decls []string
+	add := func(value string, names ...string) {
+		palette := color.Parse(value)
+		if palette == nil {
+			return
+		}
+		for _, s := range palette.Shades {
+			for _, name := range names {
+				decls = append(decls, fmt.Sprintf("--%s-%d:%s", name, s.Number, s.Hex))
+			}
+		}
+	}
+	add(c.Primary, "primary", "color-primary")
+	add(c.Gray, "color-gray")
+	add(c.Danger, "color-danger", "color-red")
+	add(c.Success, "color-success", "color-green")
+	add(c.Warning, "color-warning", "color-amber", "color-yellow")
+	add(c.Info, "color-info", "color-blue")
+	return decls
+}
+
+// themesCSS returns the CSS of the themes of the panel, sorted by name so
+// the output is stable.
+func themesCSS(themes []Theme) string {
+	sorted := append([]Theme(nil), themes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	var sb strings.Builder
+	for _, t := range sorted {
+		sb.WriteString(t.CSS())
+	}
+	return sb.String()
+}
+
+// FindTheme returns the theme of the panel with the given name.
+func (c *PanelConfig) FindTheme(name string) (Theme, bool) {
+	for _, t := range c.Themes {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+type themeKey struct{}
+
+// WithTheme returns a new context carrying the name of the theme of the
+// request (the preference of the user).
+func WithTheme(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, themeKey{}, name)
+}
+
+// CurrentTheme returns the name of the theme of the request: the theme set
+// with WithTheme when the panel has it, or the default theme of the panel.
+func CurrentTheme(ctx context.Context) string {
+	cfg := GetPanelConfigFromContext(ctx)
+	if name, ok := ctx.Value(themeKey{}).(string); ok && name != "" {
+		if _, ok := cfg.FindTheme(name); ok {
+			return name
+		}
+	}
+	if cfg.Theme != "" {
+		return cfg.Theme
+	}
+	return DefaultThemeName
+}
+
+// themeSwitchExpr returns the Datastar expression of a theme switcher
+// option: Theme.use (app.js) applies the theme and saves the preference.
+func themeSwitchExpr(name, url string) string {
+	n, _ := json.Marshal(name)
+	u, _ := json.Marshal(url)
+	return fmt.Sprintf("Theme.use(%s, %s); $themeMenuOpen = false", n, u)
+}
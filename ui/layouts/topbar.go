@@ -0,0 +1,66 @@
+package layouts
+
+import "context"
+
+// Breadcrumb is an entry of the topbar breadcrumbs.
+type Breadcrumb struct {
+	Label string
+	URL   string // Slug relative to the panel path ("users"), path or URL; empty for the current page
+}
+
+type breadcrumbsKey struct{}
+
+// WithBreadcrumbs returns a context carrying the breadcrumbs of the page.
+// The CRUD and page handlers set them; custom handlers can too.
+func WithBreadcrumbs(ctx context.Context, crumbs []Breadcrumb) context.Context {
+	return context.WithValue(ctx, breadcrumbsKey{}, crumbs)
+}
+
+// Breadcrumbs returns the breadcrumbs of the page, or nil.
+func Breadcrumbs(ctx context.Context) []Breadcrumb {
+	crumbs, _ := ctx.Value(breadcrumbsKey{}).([]Breadcrumb)
+	return crumbs
+}
+
+// SwitcherItem is an entry of the topbar switcher: a panel or a tenant.
+type SwitcherItem struct {
+	Label  string
+	URL    string
+	Icon   string // Icon name, see ui/icons
+	Active bool   // The current panel or tenant
+}
+
+// SwitcherGroup is a section of the topbar switcher ("Panels", "Tenants").
+type SwitcherGroup struct {
+	Label string
+	Items []SwitcherItem
+}
+
+// switcherGroups returns the sections of the switcher for the request,
+// without the empty ones.
+func switcherGroups(ctx context.Context, cfg *PanelConfig) []SwitcherGroup {
+	if cfg.Switcher == nil {
+		return nil
+	}
+	var groups []SwitcherGroup
+	for _, g := range cfg.Switcher(ctx) {
+		if len(g.Items) > 0 {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// switcherLabel returns the label of the switcher button: the active entry
+// of the last section (the tenant when there are tenants), or the panel name.
+func switcherLabel(groups []SwitcherGroup, fallback string) string {
+	label := fallback
+	for _, g := range groups {
+		for _, item := range g.Items {
+			if item.Active {
+				label = item.Label
+			}
+		}
+	}
+	return label
+}
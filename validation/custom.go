@@ -27,6 +27,8 @@ func (v *Validator) registerCustomValidators() {
 	_ = v.validate.RegisterValidation("siret", validateSIRET)
 	_ = v.validate.RegisterValidation("siren", validateSIREN)
 	_ = v.validate.RegisterValidation("strong_password", validateStrongPassword)
+
+	v.registerInternationalValidators()
 }
 
 // validatePhoneFR validates a French phone number.
@@ -1,16 +1,21 @@
 // Package validation provides data validation using go-playground/validator.
 //
 // It wraps the validator library with English error messages by default and adds
-// custom validators for specific data types (phone, postal code, SIRET/SIREN).
+// custom validators for specific data types (phone, postal code, SIRET/SIREN,
+// IBAN/BIC, EU VAT).
 // The package supports struct validation, form validation, and JSON validation.
 //
 // Features:
 //   - Struct validation with tags
 //   - English error messages (French messages available)
 //   - Custom validators (phone_fr, postal_code_fr, siret, siren, slug)
+//   - International validators (iban, bic, vat_eu, phone_e164, postal_code=<country>)
 //   - Strong password validation
 //   - Form and JSON validation helpers
+//   - File upload validation (sniffed MIME type, size, image dimensions)
+//   - Sanitization pipeline via `sanitize` tags (trim, lower, title, strip_html, normalize_phone)
 //   - Error message helpers
+//   - Structured Errors type (multiple messages per field, JSON-ready)
 //
 // Basic usage:
 //
@@ -30,4 +35,11 @@
 //
 //	// Validate JSON request
 //	errors := validation.ValidateJSON(request, &user)
+//
+//	// Structured errors keep rule names, params and every message per field
+//	errs := validation.JSONErrors(request, &user)
+//	if errs != nil {
+//		errs.WriteJSON(w) // 422 {"message": "...", "errors": {...}}
+//		return
+//	}
 package validation
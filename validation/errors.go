@@ -0,0 +1,203 @@
+package validation
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// FieldError describes a single failed rule on a field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Errors maps field names to every rule that failed on that field, in the
+// order they were reported.
+//
+// Errors marshals to a stable JSON shape (keys sorted by encoding/json):
+//
+//	{"email": [{"field": "email", "rule": "email", "message": "..."}]}
+//
+// It implements the error interface and form.ValidationErrors, so it can be
+// returned directly from a resource's Create/Update. Use Err to avoid the
+// typed-nil pitfall when returning it as an error.
+type Errors map[string][]FieldError
+
+// Add appends a failed rule for field and returns e for chaining.
+// Add on a nil Errors is not allowed; use make(Errors) or NewErrors.
+func (e Errors) Add(field, rule, param, message string) Errors {
+	e[field] = append(e[field], FieldError{
+		Field:   field,
+		Rule:    rule,
+		Param:   param,
+		Message: message,
+	})
+	return e
+}
+
+// NewErrors creates an empty Errors collection.
+func NewErrors() Errors {
+	return make(Errors)
+}
+
+// Has reports whether field has at least one error.
+func (e Errors) Has(field string) bool {
+	return len(e[field]) > 0
+}
+
+// Get returns the first message for field, or "" if none.
+func (e Errors) Get(field string) string {
+	if errs := e[field]; len(errs) > 0 {
+		return errs[0].Message
+	}
+	return ""
+}
+
+// Messages returns all messages for field.
+func (e Errors) Messages(field string) []string {
+	errs := e[field]
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(errs))
+	for i, fe := range errs {
+		msgs[i] = fe.Message
+	}
+	return msgs
+}
+
+// Rules returns the rule names that failed for field.
+func (e Errors) Rules(field string) []string {
+	errs := e[field]
+	if len(errs) == 0 {
+		return nil
+	}
+	rules := make([]string, len(errs))
+	for i, fe := range errs {
+		rules[i] = fe.Rule
+	}
+	return rules
+}
+
+// Fields returns the names of fields with errors, sorted alphabetically.
+func (e Errors) Fields() []string {
+	fields := make([]string, 0, len(e))
+	for field, errs := range e {
+		if len(errs) > 0 {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Len returns the number of fields with errors.
+func (e Errors) Len() int {
+	return len(e.Fields())
+}
+
+// IsEmpty reports whether there are no errors.
+func (e Errors) IsEmpty() bool {
+	return e.Len() == 0
+}
+
+// All returns every FieldError, ordered by field name then report order.
+func (e Errors) All() []FieldError {
+	var all []FieldError
+	for _, field := range e.Fields() {
+		all = append(all, e[field]...)
+	}
+	return all
+}
+
+// Merge appends every error from others into e and returns e.
+func (e Errors) Merge(others ...Errors) Errors {
+	for _, other := range others {
+		for field, errs := range other {
+			e[field] = append(e[field], errs...)
+		}
+	}
+	return e
+}
+
+// Map returns the first message per field, matching the legacy
+// map[string]string shape. Returns nil when there are no errors.
+func (e Errors) Map() map[string]string {
+	if e.IsEmpty() {
+		return nil
+	}
+	result := make(map[string]string, len(e))
+	for field, errs := range e {
+		if len(errs) > 0 {
+			result[field] = errs[0].Message
+		}
+	}
+	return result
+}
+
+// MultiMap returns all messages per field, matching the shape used by
+// ValidateMap. Returns nil when there are no errors.
+func (e Errors) MultiMap() map[string][]string {
+	if e.IsEmpty() {
+		return nil
+	}
+	result := make(map[string][]string, len(e))
+	for _, field := range e.Fields() {
+		result[field] = e.Messages(field)
+	}
+	return result
+}
+
+// FieldErrors implements form.ValidationErrors.
+func (e Errors) FieldErrors() map[string]string {
+	return e.Map()
+}
+
+// Error implements the error interface.
+func (e Errors) Error() string {
+	if e.IsEmpty() {
+		return "validation failed"
+	}
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e.All() {
+		msgs = append(msgs, fe.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Err returns e as an error, or nil when there are no errors.
+func (e Errors) Err() error {
+	if e.IsEmpty() {
+		return nil
+	}
+	return e
+}
+
+// WriteJSON writes the errors as a 422 Unprocessable Entity JSON response:
+//
+//	{"message": "validation failed", "errors": {...}}
+func (e Errors) WriteJSON(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	_ = json.NewEncoder(w).Encode(struct {
+		Message string `json:"message"`
+		Errors  Errors `json:"errors"`
+	}{
+		Message: "validation failed",
+		Errors:  e,
+	})
+}
+
+// FromMap converts a legacy map[string]string into Errors.
+// The rule name of each entry is left empty.
+func FromMap(m map[string]string) Errors {
+	e := make(Errors, len(m))
+	for field, msg := range m {
+		e.Add(field, "", "", msg)
+	}
+	return e
+}
@@ -0,0 +1,400 @@
+package validation
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoder for dimension checks
+	_ "image/jpeg" // register JPEG decoder for dimension checks
+	_ "image/png"  // register PNG decoder for dimension checks
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sniffLen is the number of bytes inspected by http.DetectContentType.
+const sniffLen = 512
+
+// FileRules holds parsed file upload constraints.
+type FileRules struct {
+	MaxSize   int64    // bytes, 0 = unlimited
+	MinSize   int64    // bytes, 0 = no minimum
+	MIMETypes []string // e.g. "image/png", "image/*"; empty = any
+	Image     bool     // require a decodable image
+
+	MinWidth, MaxWidth   int
+	MinHeight, MaxHeight int
+}
+
+// dimensionKeys are accepted both inside dimensions=... and as standalone rules.
+var dimensionKeys = map[string]bool{
+	"min_width": true, "max_width": true, "min_height": true, "max_height": true,
+	"width": true, "height": true,
+}
+
+// ParseFileRules parses a comma-separated file rule string.
+//
+// Supported rules:
+//   - image                          sniffed content must be an image
+//   - mimes=image/png|application/pdf allowed MIME types ("image/*" allowed),
+//     matched on the sniffed content; types the content cannot tell apart
+//     (text/csv, application/json, .docx, .xlsx...) also need their extension
+//   - max=5MB, min=1KB               size limits (B, KB, MB, GB; 1024-based)
+//   - dimensions=min_width=200       image dimension constraints; several
+//     constraints are separated by "|" or repeated as standalone rules
+//     (min_width, max_width, min_height, max_height, width, height)
+func ParseFileRules(rules string) (FileRules, error) {
+	var fr FileRules
+
+	for _, part := range strings.Split(rules, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, param, _ := strings.Cut(part, "=")
+		switch {
+		case name == "image":
+			fr.Image = true
+		case name == "mimes":
+			for _, m := range strings.Split(param, "|") {
+				if m = strings.TrimSpace(m); m != "" {
+					fr.MIMETypes = append(fr.MIMETypes, m)
+				}
+			}
+		case name == "max":
+			size, err := ParseSize(param)
+			if err != nil {
+				return fr, err
+			}
+			fr.MaxSize = size
+		case name == "min":
+			size, err := ParseSize(param)
+			if err != nil {
+				return fr, err
+			}
+			fr.MinSize = size
+		case name == "dimensions":
+			for _, d := range strings.Split(param, "|") {
+				if err := fr.setDimension(d); err != nil {
+					return fr, err
+				}
+			}
+		case dimensionKeys[name]:
+			if err := fr.setDimension(part); err != nil {
+				return fr, err
+			}
+		default:
+			return fr, fmt.Errorf("validation: unknown file rule %q", name)
+		}
+	}
+
+	return fr, nil
+}
+
+func (fr *FileRules) setDimension(constraint string) error {
+	key, val, ok := strings.Cut(strings.TrimSpace(constraint), "=")
+	if !ok || !dimensionKeys[key] {
+		return fmt.Errorf("validation: invalid dimension constraint %q", constraint)
+	}
+	n, err := strconv.Atoi(val)
+	if err != nil || n < 0 {
+		return fmt.Errorf("validation: invalid dimension value %q", constraint)
+	}
+
+	switch key {
+	case "min_width":
+		fr.MinWidth = n
+	case "max_width":
+		fr.MaxWidth = n
+	case "min_height":
+		fr.MinHeight = n
+	case "max_height":
+		fr.MaxHeight = n
+	case "width":
+		fr.MinWidth, fr.MaxWidth = n, n
+	case "height":
+		fr.MinHeight, fr.MaxHeight = n, n
+	}
+	fr.Image = true
+	return nil
+}
+
+// hasDimensions reports whether any dimension constraint is set.
+func (fr FileRules) hasDimensions() bool {
+	return fr.MinWidth > 0 || fr.MaxWidth > 0 || fr.MinHeight > 0 || fr.MaxHeight > 0
+}
+
+// ParseSize parses a human-readable size ("5MB", "500KB", "1024") into bytes.
+func ParseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+
+	for _, unit := range []struct {
+		suffix string
+		mult   int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			multiplier = unit.mult
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("validation: invalid size %q", s)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// FormatSize formats a byte count for error messages ("5MB", "200KB").
+func FormatSize(size int64) string {
+	switch {
+	case size >= 1<<30 && size%(1<<30) == 0:
+		return fmt.Sprintf("%dGB", size>>30)
+	case size >= 1<<20 && size%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", size>>20)
+	case size >= 1<<10 && size%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", size>>10)
+	default:
+		return fmt.Sprintf("%dB", size)
+	}
+}
+
+// ValidateFile validates an uploaded file against a rule string.
+// The content type is sniffed from the file bytes, never from the extension
+// or the client-provided Content-Type header.
+//
+// Example:
+//
+//	errs := validation.ValidateFile(header, "image,max=5MB,dimensions=min_width=200")
+//	if errs != nil {
+//		// errs.Messages(header.Filename)
+//	}
+//
+// Errors are keyed by the uploaded file name. Returns nil if the file is valid.
+func ValidateFile(header *multipart.FileHeader, rules string) Errors {
+	fr, err := ParseFileRules(rules)
+	if err != nil {
+		return NewErrors().Add("file", "rules", rules, err.Error())
+	}
+	return ValidateFileRules(header, fr)
+}
+
+// ValidateFileRules validates an uploaded file against parsed FileRules.
+// Returns nil if the file is valid.
+func ValidateFileRules(header *multipart.FileHeader, fr FileRules) Errors {
+	if header == nil {
+		return NewErrors().Add("file", "required", "", fileMessage("required", "file", ""))
+	}
+
+	field := header.Filename
+	errs := NewErrors()
+
+	if fr.MaxSize > 0 && header.Size > fr.MaxSize {
+		param := FormatSize(fr.MaxSize)
+		errs.Add(field, "file_max", param, fileMessage("file_max", field, param))
+	}
+	if fr.MinSize > 0 && header.Size < fr.MinSize {
+		param := FormatSize(fr.MinSize)
+		errs.Add(field, "file_min", param, fileMessage("file_min", field, param))
+	}
+
+	f, err := header.Open()
+	if err != nil {
+		return errs.Add(field, "file", "", fileMessage("file", field, ""))
+	}
+	defer func() { _ = f.Close() }()
+
+	buf := make([]byte, sniffLen)
+	n, _ := io.ReadFull(f, buf)
+	contentType := http.DetectContentType(buf[:n])
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+
+	if len(fr.MIMETypes) > 0 && !matchMIME(contentType, header.Filename, fr.MIMETypes) {
+		param := strings.Join(fr.MIMETypes, ", ")
+		errs.Add(field, "file_mime", param, fileMessage("file_mime", field, param))
+	}
+
+	if fr.Image {
+		if !strings.HasPrefix(contentType, "image/") {
+			errs.Add(field, "file_image", "", fileMessage("file_image", field, ""))
+		} else if fr.hasDimensions() {
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				return errs.Add(field, "file", "", fileMessage("file", field, ""))
+			}
+			cfg, _, err := image.DecodeConfig(f)
+			if err != nil {
+				errs.Add(field, "file_image", "", fileMessage("file_image", field, ""))
+			} else if !fr.dimensionsOK(cfg.Width, cfg.Height) {
+				param := fr.dimensionsParam()
+				errs.Add(field, "file_dimensions", param, fileMessage("file_dimensions", field, param))
+			}
+		}
+	}
+
+	if errs.IsEmpty() {
+		return nil
+	}
+	return errs
+}
+
+// ValidateRequestFiles validates every file uploaded under field in a
+// multipart request. Errors are keyed by field. When required is false,
+// a missing upload is not an error.
+func ValidateRequestFiles(r *http.Request, field, rules string, required bool) Errors {
+	var headers []*multipart.FileHeader
+	if r.MultipartForm != nil {
+		headers = r.MultipartForm.File[field]
+	}
+
+	if len(headers) == 0 {
+		if required {
+			return NewErrors().Add(field, "required", "", fileMessage("required", field, ""))
+		}
+		return nil
+	}
+
+	errs := NewErrors()
+	for _, h := range headers {
+		for _, fe := range ValidateFile(h, rules).All() {
+			errs.Add(field, fe.Rule, fe.Param, fe.Message)
+		}
+	}
+	if errs.IsEmpty() {
+		return nil
+	}
+	return errs
+}
+
+// AcceptToMIMETypes converts an HTML accept attribute ("image/*,.pdf") to
+// a list of MIME types usable with the mimes= rule.
+func AcceptToMIMETypes(accept string) []string {
+	var types []string
+	for _, a := range strings.Split(accept, ",") {
+		a = strings.TrimSpace(a)
+		switch {
+		case a == "":
+			continue
+		case strings.HasPrefix(a, "."):
+			if t := typeByExtension(a); t != "" {
+				types = append(types, t)
+			}
+		default:
+			types = append(types, a)
+		}
+	}
+	return types
+}
+
+// sniffedTypes lists, for the types http.DetectContentType cannot tell
+// apart, the types it returns for their content: CSV or JSON files are
+// sniffed as text/plain, Office documents as application/zip.
+var sniffedTypes = map[string][]string{
+	"text/csv":                 {"text/plain"},
+	"text/markdown":            {"text/plain"},
+	"application/json":         {"text/plain"},
+	"application/xml":          {"text/xml", "text/plain"},
+	"text/xml":                 {"text/plain"},
+	"application/msword":       {"application/octet-stream"},
+	"application/vnd.ms-excel": {"application/octet-stream"},
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   {"application/zip"},
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         {"application/zip"},
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": {"application/zip"},
+	"application/vnd.oasis.opendocument.text":                                   {"application/zip"},
+	"application/vnd.oasis.opendocument.spreadsheet":                            {"application/zip"},
+}
+
+// extensionTypes completes mime.TypeByExtension, whose table depends on
+// the system, for the extensions of sniffedTypes.
+var extensionTypes = map[string]string{
+	".csv":  "text/csv",
+	".md":   "text/markdown",
+	".json": "application/json",
+	".xml":  "application/xml",
+	".doc":  "application/msword",
+	".xls":  "application/vnd.ms-excel",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+	".odt":  "application/vnd.oasis.opendocument.text",
+	".ods":  "application/vnd.oasis.opendocument.spreadsheet",
+}
+
+// typeByExtension returns the MIME type of a file extension (".pdf"),
+// without parameters, or "" when it is unknown.
+func typeByExtension(ext string) string {
+	ext = strings.ToLower(ext)
+	if t, ok := extensionTypes[ext]; ok {
+		return t
+	}
+	if mediaType, _, err := mime.ParseMediaType(mime.TypeByExtension(ext)); err == nil {
+		return mediaType
+	}
+	return ""
+}
+
+// matchMIME reports whether a file named filename, whose content was
+// sniffed as contentType, is one of the allowed types. A type in
+// sniffedTypes matches its sniffed types only with its own extension, so a
+// text file is not taken for a CSV file nor any ZIP archive for a .docx.
+func matchMIME(contentType, filename string, allowed []string) bool {
+	for _, a := range allowed {
+		if a == "*/*" || a == contentType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+		for _, sniffed := range sniffedTypes[a] {
+			if sniffed == contentType && typeByExtension(filepath.Ext(filename)) == a {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (fr FileRules) dimensionsOK(w, h int) bool {
+	return (fr.MinWidth == 0 || w >= fr.MinWidth) &&
+		(fr.MaxWidth == 0 || w <= fr.MaxWidth) &&
+		(fr.MinHeight == 0 || h >= fr.MinHeight) &&
+		(fr.MaxHeight == 0 || h <= fr.MaxHeight)
+}
+
+func (fr FileRules) dimensionsParam() string {
+	var parts []string
+	if fr.MinWidth > 0 {
+		parts = append(parts, fmt.Sprintf("min_width=%d", fr.MinWidth))
+	}
+	if fr.MaxWidth > 0 {
+		parts = append(parts, fmt.Sprintf("max_width=%d", fr.MaxWidth))
+	}
+	if fr.MinHeight > 0 {
+		parts = append(parts, fmt.Sprintf("min_height=%d", fr.MinHeight))
+	}
+	if fr.MaxHeight > 0 {
+		parts = append(parts, fmt.Sprintf("max_height=%d", fr.MaxHeight))
+	}
+	return strings.Join(parts, "|")
+}
+
+func fileMessage(tag, field, param string) string {
+	message, ok := defaultMessages()[tag]
+	if !ok {
+		message = "The {field} field is invalid"
+	}
+	message = strings.ReplaceAll(message, "{field}", field)
+	return strings.ReplaceAll(message, "{param}", param)
+}
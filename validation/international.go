@@ -0,0 +1,219 @@
+package validation
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	reIBAN = regexp.MustCompile(`^[A-Z]{2}\d{2}[A-Z0-9]{11,30}$`)
+	reBIC  = regexp.MustCompile(`^[A-Z]{4}[A-Z]{2}[A-Z0-9]{2}([A-Z0-9]{3})?$`)
+	reE164 = regexp.MustCompile(`^\+[1-9]\d{1,14}$`)
+)
+
+// ibanLengths holds the expected IBAN length per country (ISO 13616 registry).
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16,
+	"BG": 22, "BH": 22, "BR": 29, "CH": 21, "CR": 22, "CY": 28, "CZ": 24,
+	"DE": 22, "DK": 18, "DO": 28, "EE": 20, "EG": 29, "ES": 24, "FI": 18,
+	"FO": 18, "FR": 27, "GB": 22, "GE": 22, "GI": 23, "GL": 18, "GR": 27,
+	"GT": 28, "HR": 21, "HU": 28, "IE": 22, "IL": 23, "IS": 26, "IT": 27,
+	"JO": 30, "KW": 30, "KZ": 20, "LB": 28, "LI": 21, "LT": 20, "LU": 20,
+	"LV": 21, "MC": 27, "MD": 24, "ME": 22, "MK": 19, "MR": 27, "MT": 31,
+	"MU": 30, "NL": 18, "NO": 15, "PK": 24, "PL": 28, "PS": 29, "PT": 25,
+	"QA": 29, "RO": 24, "RS": 22, "SA": 24, "SE": 24, "SI": 19, "SK": 24,
+	"SM": 27, "TN": 24, "TR": 26, "UA": 29, "VG": 24, "XK": 20,
+}
+
+// vatPatterns holds the VAT number format (without country prefix) for each
+// EU member state. Greece uses the "EL" prefix.
+var vatPatterns = map[string]*regexp.Regexp{
+	"AT": regexp.MustCompile(`^U\d{8}$`),
+	"BE": regexp.MustCompile(`^[01]\d{9}$`),
+	"BG": regexp.MustCompile(`^\d{9,10}$`),
+	"CY": regexp.MustCompile(`^\d{8}[A-Z]$`),
+	"CZ": regexp.MustCompile(`^\d{8,10}$`),
+	"DE": regexp.MustCompile(`^\d{9}$`),
+	"DK": regexp.MustCompile(`^\d{8}$`),
+	"EE": regexp.MustCompile(`^\d{9}$`),
+	"EL": regexp.MustCompile(`^\d{9}$`),
+	"ES": regexp.MustCompile(`^[A-Z0-9]\d{7}[A-Z0-9]$`),
+	"FI": regexp.MustCompile(`^\d{8}$`),
+	"FR": regexp.MustCompile(`^[A-HJ-NP-Z0-9]{2}\d{9}$`),
+	"HR": regexp.MustCompile(`^\d{11}$`),
+	"HU": regexp.MustCompile(`^\d{8}$`),
+	"IE": regexp.MustCompile(`^\d[A-Z0-9+*]\d{5}[A-Z]{1,2}$`),
+	"IT": regexp.MustCompile(`^\d{11}$`),
+	"LT": regexp.MustCompile(`^(\d{9}|\d{12})$`),
+	"LU": regexp.MustCompile(`^\d{8}$`),
+	"LV": regexp.MustCompile(`^\d{11}$`),
+	"MT": regexp.MustCompile(`^\d{8}$`),
+	"NL": regexp.MustCompile(`^\d{9}B\d{2}$`),
+	"PL": regexp.MustCompile(`^\d{10}$`),
+	"PT": regexp.MustCompile(`^\d{9}$`),
+	"RO": regexp.MustCompile(`^\d{2,10}$`),
+	"SE": regexp.MustCompile(`^\d{12}$`),
+	"SI": regexp.MustCompile(`^\d{8}$`),
+	"SK": regexp.MustCompile(`^\d{10}$`),
+}
+
+// postalCodePatterns holds the postal code format per ISO 3166-1 alpha-2 country.
+var postalCodePatterns = map[string]*regexp.Regexp{
+	"AT": regexp.MustCompile(`^\d{4}$`),
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"BE": regexp.MustCompile(`^\d{4}$`),
+	"BR": regexp.MustCompile(`^\d{5}-?\d{3}$`),
+	"CA": regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY]\d[ABCEGHJ-NPRSTV-Z] ?\d[ABCEGHJ-NPRSTV-Z]\d$`),
+	"CH": regexp.MustCompile(`^\d{4}$`),
+	"CZ": regexp.MustCompile(`^\d{3} ?\d{2}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"DK": regexp.MustCompile(`^\d{4}$`),
+	"ES": regexp.MustCompile(`^(0[1-9]|[1-4]\d|5[0-2])\d{3}$`),
+	"FI": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^(\d{5}|2[AB]\d{3})$`),
+	"GB": regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+	"GR": regexp.MustCompile(`^\d{3} ?\d{2}$`),
+	"IE": regexp.MustCompile(`^([AC-FHKNPRTV-Y]\d{2}|D6W) ?[0-9AC-FHKNPRTV-Y]{4}$`),
+	"IN": regexp.MustCompile(`^[1-9]\d{5}$`),
+	"IT": regexp.MustCompile(`^\d{5}$`),
+	"JP": regexp.MustCompile(`^\d{3}-?\d{4}$`),
+	"LU": regexp.MustCompile(`^\d{4}$`),
+	"MA": regexp.MustCompile(`^\d{5}$`),
+	"MX": regexp.MustCompile(`^\d{5}$`),
+	"NL": regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`),
+	"NO": regexp.MustCompile(`^\d{4}$`),
+	"PL": regexp.MustCompile(`^\d{2}-\d{3}$`),
+	"PT": regexp.MustCompile(`^\d{4}-\d{3}$`),
+	"SE": regexp.MustCompile(`^\d{3} ?\d{2}$`),
+	"SN": regexp.MustCompile(`^\d{5}$`),
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+}
+
+// registerInternationalValidators registers country-aware validators.
+func (v *Validator) registerInternationalValidators() {
+	_ = v.validate.RegisterValidation("iban", validateIBAN)
+	_ = v.validate.RegisterValidation("bic", validateBIC)
+	_ = v.validate.RegisterValidation("vat_eu", validateVATEU)
+	_ = v.validate.RegisterValidation("phone_e164", validatePhoneE164)
+	_ = v.validate.RegisterValidation("postal_code", validatePostalCode)
+}
+
+// compact uppercases a value and strips spaces, dots and dashes.
+func compact(s string) string {
+	s = strings.ToUpper(s)
+	return strings.NewReplacer(" ", "", ".", "", "-", "").Replace(s)
+}
+
+// validateIBAN validates an IBAN: country length and ISO 7064 mod-97 checksum.
+// Spaces are allowed (e.g., "FR76 3000 6000 0112 3456 7890 189").
+func validateIBAN(fl validator.FieldLevel) bool {
+	return isIBAN(fl.Field().String())
+}
+
+func isIBAN(value string) bool {
+	iban := compact(value)
+
+	if !reIBAN.MatchString(iban) {
+		return false
+	}
+
+	if expected, ok := ibanLengths[iban[:2]]; !ok || len(iban) != expected {
+		return false
+	}
+
+	// Move the first four characters to the end, convert letters to
+	// numbers (A=10 ... Z=35) and compute the remainder digit by digit.
+	rearranged := iban[4:] + iban[:4]
+	remainder := 0
+	for _, r := range rearranged {
+		if r >= 'A' && r <= 'Z' {
+			remainder = (remainder*100 + int(r-'A'+10)) % 97
+		} else {
+			remainder = (remainder*10 + int(r-'0')) % 97
+		}
+	}
+	return remainder == 1
+}
+
+// validateBIC validates a BIC/SWIFT code (8 or 11 characters).
+func validateBIC(fl validator.FieldLevel) bool {
+	return reBIC.MatchString(compact(fl.Field().String()))
+}
+
+// validateVATEU validates an EU VAT number including its country prefix.
+// Accepts: FR40303265045, DE 123456789, EL123456789
+func validateVATEU(fl validator.FieldLevel) bool {
+	vat := compact(fl.Field().String())
+
+	if len(vat) < 4 {
+		return false
+	}
+
+	pattern, ok := vatPatterns[vat[:2]]
+	if !ok {
+		return false
+	}
+	return pattern.MatchString(vat[2:])
+}
+
+// validatePhoneE164 validates a phone number in strict E.164 format.
+// Accepts: +33612345678, +14155552671
+// Rejects: 0612345678, +33 6 12 34 56 78
+func validatePhoneE164(fl validator.FieldLevel) bool {
+	return reE164.MatchString(fl.Field().String())
+}
+
+// validatePostalCode validates a postal code for the country given as param.
+// Usage: `validate:"postal_code=DE"`. Unknown countries are rejected.
+func validatePostalCode(fl validator.FieldLevel) bool {
+	return isPostalCode(fl.Field().String(), fl.Param())
+}
+
+func isPostalCode(code, country string) bool {
+	pattern, ok := postalCodePatterns[strings.ToUpper(country)]
+	if !ok {
+		return false
+	}
+	return pattern.MatchString(strings.ToUpper(strings.TrimSpace(code)))
+}
+
+// PostalCodeCountries returns the sorted country codes supported by postal_code.
+func PostalCodeCountries() []string {
+	countries := make([]string, 0, len(postalCodePatterns))
+	for country := range postalCodePatterns {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+	return countries
+}
+
+// IsValidIBAN checks if an IBAN is valid.
+func IsValidIBAN(iban string) bool {
+	return isIBAN(iban)
+}
+
+// IsValidBIC checks if a BIC/SWIFT code is valid.
+func IsValidBIC(bic string) bool {
+	v := New()
+	return v.validate.Var(bic, "bic") == nil
+}
+
+// IsValidVATEU checks if an EU VAT number is valid.
+func IsValidVATEU(vat string) bool {
+	v := New()
+	return v.validate.Var(vat, "vat_eu") == nil
+}
+
+// IsValidPhoneE164 checks if a phone number is in E.164 format.
+func IsValidPhoneE164(phone string) bool {
+	v := New()
+	return v.validate.Var(phone, "phone_e164") == nil
+}
+
+// IsValidPostalCode checks if a postal code is valid for the given country.
+func IsValidPostalCode(code, country string) bool {
+	return isPostalCode(code, country)
+}
@@ -91,6 +91,21 @@ func defaultMessages() map[string]string {
 		"siret":           "The {field} field must be a valid SIRET number (14 digits)",
 		"siren":           "The {field} field must be a valid SIREN number (9 digits)",
 		"strong_password": "The {field} field must contain at least 8 characters with uppercase, lowercase and number",
+
+		// International Validators
+		"iban":        "The {field} field must be a valid IBAN",
+		"bic":         "The {field} field must be a valid BIC/SWIFT code",
+		"vat_eu":      "The {field} field must be a valid EU VAT number (e.g., FR40303265045)",
+		"phone_e164":  "The {field} field must be a phone number in international format (e.g., +33612345678)",
+		"postal_code": "The {field} field must be a valid postal code for {param}",
+
+		// File Uploads
+		"file":            "The file {field} could not be read",
+		"file_max":        "The file {field} must not be larger than {param}",
+		"file_min":        "The file {field} must be at least {param}",
+		"file_mime":       "The file {field} must be of type: {param}",
+		"file_image":      "The file {field} must be an image",
+		"file_dimensions": "The image {field} has invalid dimensions ({param})",
 	}
 }
 
@@ -187,5 +202,20 @@ func frenchMessages() map[string]string {
 		"siret":           "Le champ {field} doit être un numéro SIRET valide (14 chiffres)",
 		"siren":           "Le champ {field} doit être un numéro SIREN valide (9 chiffres)",
 		"strong_password": "Le champ {field} doit contenir au moins 8 caractères avec majuscule, minuscule et chiffre",
+
+		// International Validators
+		"iban":        "Le champ {field} doit être un IBAN valide",
+		"bic":         "Le champ {field} doit être un code BIC/SWIFT valide",
+		"vat_eu":      "Le champ {field} doit être un numéro de TVA intracommunautaire valide (ex: FR40303265045)",
+		"phone_e164":  "Le champ {field} doit être un numéro au format international (ex: +33612345678)",
+		"postal_code": "Le champ {field} doit être un code postal valide pour {param}",
+
+		// File Uploads
+		"file":            "Le fichier {field} n'a pas pu être lu",
+		"file_max":        "Le fichier {field} ne doit pas dépasser {param}",
+		"file_min":        "Le fichier {field} doit faire au moins {param}",
+		"file_mime":       "Le fichier {field} doit être de type : {param}",
+		"file_image":      "Le fichier {field} doit être une image",
+		"file_dimensions": "L'image {field} a des dimensions invalides ({param})",
 	}
 }
@@ -0,0 +1,161 @@
+package validation
+
+import (
+	"html"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// SanitizerFunc transforms a string value before validation.
+type SanitizerFunc func(string) string
+
+// maxStripHTMLPasses bounds the decoding of nested entities by strip_html.
+const maxStripHTMLPasses = 8
+
+// maxSanitizeDepth stops Sanitize on deeply nested values.
+const maxSanitizeDepth = 32
+
+var (
+	reHTMLTag = regexp.MustCompile(`<[^>]*>`)
+	rePhoneNo = regexp.MustCompile(`[^\d+]`)
+
+	sanitizersMu sync.RWMutex
+	sanitizers   = map[string]SanitizerFunc{
+		"trim":            strings.TrimSpace,
+		"lower":           strings.ToLower,
+		"upper":           strings.ToUpper,
+		"title":           sanitizeTitle,
+		"strip_html":      sanitizeStripHTML,
+		"normalize_phone": sanitizeNormalizePhone,
+	}
+)
+
+// RegisterSanitizer registers a custom sanitizer usable in `sanitize` tags.
+// Registering an existing name replaces it.
+func RegisterSanitizer(name string, fn SanitizerFunc) {
+	sanitizersMu.Lock()
+	defer sanitizersMu.Unlock()
+	sanitizers[name] = fn
+}
+
+// SanitizeString applies a comma-separated sanitizer pipeline to a value.
+// Unknown sanitizer names are ignored.
+//
+// Example:
+//
+//	validation.SanitizeString("  <b>Hello</b> ", "strip_html,trim,lower") // "hello"
+func SanitizeString(value, pipeline string) string {
+	sanitizersMu.RLock()
+	defer sanitizersMu.RUnlock()
+
+	for _, name := range strings.Split(pipeline, ",") {
+		if fn, ok := sanitizers[strings.TrimSpace(name)]; ok {
+			value = fn(value)
+		}
+	}
+	return value
+}
+
+// Sanitize applies the `sanitize` tag pipeline to every string field of the
+// struct pointed to by dest. Nested structs, string pointers and string
+// slices are supported. Non-pointer values are left untouched.
+//
+// Example:
+//
+//	type Contact struct {
+//		Name  string `sanitize:"trim,title"`
+//		Email string `sanitize:"trim,lower" validate:"required,email"`
+//		Phone string `sanitize:"normalize_phone" validate:"phone_e164"`
+//	}
+//
+// ValidateForm, ValidateJSON, FormErrors and JSONErrors call Sanitize
+// automatically before validating.
+func Sanitize(dest interface{}) {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return
+	}
+	sanitizeStruct(rv.Elem(), map[uintptr]bool{rv.Pointer(): true}, 0)
+}
+
+// sanitizeStruct sanitizes the fields of rv, skipping the structs already
+// visited through a pointer, so cyclic values terminate.
+func sanitizeStruct(rv reflect.Value, visited map[uintptr]bool, depth int) {
+	if rv.Kind() != reflect.Struct || depth > maxSanitizeDepth {
+		return
+	}
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		field := rv.Field(i)
+		pipeline := sf.Tag.Get("sanitize")
+
+		switch field.Kind() {
+		case reflect.String:
+			if pipeline != "" {
+				field.SetString(SanitizeString(field.String(), pipeline))
+			}
+		case reflect.Ptr:
+			if field.IsNil() {
+				continue
+			}
+			if field.Elem().Kind() == reflect.String {
+				if pipeline != "" {
+					field.Elem().SetString(SanitizeString(field.Elem().String(), pipeline))
+				}
+			} else if !visited[field.Pointer()] {
+				visited[field.Pointer()] = true
+				sanitizeStruct(field.Elem(), visited, depth+1)
+			}
+		case reflect.Slice:
+			if pipeline != "" && field.Type().Elem().Kind() == reflect.String {
+				for j := 0; j < field.Len(); j++ {
+					field.Index(j).SetString(SanitizeString(field.Index(j).String(), pipeline))
+				}
+			}
+		case reflect.Struct:
+			sanitizeStruct(field, visited, depth+1)
+		}
+	}
+}
+
+// sanitizeTitle converts a value to title case ("jean dupont" -> "Jean Dupont").
+func sanitizeTitle(s string) string {
+	return cases.Title(language.Und).String(s)
+}
+
+// sanitizeStripHTML decodes entities and removes HTML tags, until no
+// decoded entity forms a tag anymore ("&lt;script&gt;" is removed too).
+func sanitizeStripHTML(s string) string {
+	for range maxStripHTMLPasses {
+		next := reHTMLTag.ReplaceAllString(html.UnescapeString(s), "")
+		if next == s {
+			return s
+		}
+		s = next
+	}
+	// Still changing: drop what could open or close a tag.
+	return strings.NewReplacer("<", "", ">", "").Replace(s)
+}
+
+// sanitizeNormalizePhone removes every character except digits and a leading "+".
+// "+33 6 12-34.56 (78)" -> "+33612345678"
+func sanitizeNormalizePhone(s string) string {
+	s = strings.TrimSpace(s)
+	plus := strings.HasPrefix(s, "+")
+	s = strings.ReplaceAll(rePhoneNo.ReplaceAllString(s, ""), "+", "")
+	if plus {
+		return "+" + s
+	}
+	return s
+}
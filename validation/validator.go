@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gorilla/schema"
@@ -46,13 +47,66 @@ func (v *Validator) Validate(s interface{}) error {
 	return v.validate.Struct(s)
 }
 
+// WithFieldNameTag names fields after the given struct tag instead of
+// "json", e.g. "mapstructure" for configuration structs.
+func (v *Validator) WithFieldNameTag(tag string) *Validator {
+	v.validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		name := strings.SplitN(fld.Tag.Get(tag), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return fld.Name
+		}
+		return name
+	})
+	return v
+}
+
+// PathErrors validates s and returns errors keyed by the dotted path of
+// each field below s ("database.url"), for nested structs such as
+// configuration. Returns nil if s is valid.
+func (v *Validator) PathErrors(s interface{}) Errors {
+	err := v.Validate(s)
+	if err == nil {
+		return nil
+	}
+	return formatErrorsBy(err, v.messages, fieldPath)
+}
+
+// fieldPath returns the namespace of e without the root struct name.
+func fieldPath(e validator.FieldError) string {
+	path := e.Namespace()
+	if i := strings.Index(path, "."); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
 // ValidateVar validates a single variable.
 func (v *Validator) ValidateVar(field interface{}, tag string) error {
 	return v.validate.Var(field, tag)
 }
 
 // ValidateStruct validates a struct and returns formatted errors.
+// It returns the first message per field; use StructErrors for the full set.
 func ValidateStruct(s interface{}) map[string]string {
+	return StructErrors(s).Map()
+}
+
+// ValidateForm validates an HTTP form and binds to a struct.
+func ValidateForm(r *http.Request, dest interface{}) map[string]string {
+	return FormErrors(r, dest).Map()
+}
+
+// ValidateJSON validates JSON and binds to a struct.
+func ValidateJSON(r *http.Request, dest interface{}) map[string]string {
+	return JSONErrors(r, dest).Map()
+}
+
+// StructErrors validates a struct and returns structured errors.
+// Returns nil if the struct is valid.
+func StructErrors(s interface{}) Errors {
 	v := New()
 	err := v.Validate(s)
 	if err == nil {
@@ -62,25 +116,31 @@ func ValidateStruct(s interface{}) map[string]string {
 	return formatErrors(err, v.messages)
 }
 
-// ValidateForm validates an HTTP form and binds to a struct.
-func ValidateForm(r *http.Request, dest interface{}) map[string]string {
+// FormErrors parses an HTTP form, binds it to dest, applies `sanitize` tags
+// and validates it.
+// Returns nil if the data is valid.
+func FormErrors(r *http.Request, dest interface{}) Errors {
 	if err := r.ParseForm(); err != nil {
-		return map[string]string{"form": "Failed to parse form"}
+		return NewErrors().Add("form", "parse", "", "Failed to parse form")
 	}
 
 	if err := decoder.Decode(dest, r.Form); err != nil {
-		return map[string]string{"form": "Failed to bind data"}
+		return NewErrors().Add("form", "bind", "", "Failed to bind data")
 	}
 
-	return ValidateStruct(dest)
+	Sanitize(dest)
+	return StructErrors(dest)
 }
 
-// ValidateJSON validates JSON and binds to a struct.
-func ValidateJSON(r *http.Request, dest interface{}) map[string]string {
+// JSONErrors decodes a JSON body into dest, applies `sanitize` tags and
+// validates it.
+// Returns nil if the data is valid.
+func JSONErrors(r *http.Request, dest interface{}) Errors {
 	if err := json.NewDecoder(r.Body).Decode(dest); err != nil {
-		return map[string]string{"json": "Invalid JSON format"}
+		return NewErrors().Add("json", "parse", "", "Invalid JSON format")
 	}
-	return ValidateStruct(dest)
+	Sanitize(dest)
+	return StructErrors(dest)
 }
 
 // Check quickly checks if a struct is valid.
@@ -96,12 +156,17 @@ func Must(s interface{}) {
 }
 
 // formatErrors formats validation errors.
-func formatErrors(err error, messages map[string]string) map[string]string {
-	result := make(map[string]string)
+func formatErrors(err error, messages map[string]string) Errors {
+	return formatErrorsBy(err, messages, validator.FieldError.Field)
+}
+
+// formatErrorsBy formats validation errors, naming fields with key.
+func formatErrorsBy(err error, messages map[string]string, key func(validator.FieldError) string) Errors {
+	result := NewErrors()
 
 	if validationErrors, ok := err.(validator.ValidationErrors); ok {
 		for _, e := range validationErrors {
-			field := e.Field()
+			field := key(e)
 			tag := e.Tag()
 			param := e.Param()
 
@@ -114,7 +179,7 @@ func formatErrors(err error, messages map[string]string) map[string]string {
 			message = strings.ReplaceAll(message, "{param}", param)
 			message = strings.ReplaceAll(message, "{value}", fmt.Sprintf("%v", e.Value()))
 
-			result[field] = message
+			result.Add(field, tag, param, message)
 		}
 	}
 
@@ -196,4 +261,23 @@ var (
 func init() {
 	decoder.SetAliasTag("json")
 	decoder.IgnoreUnknownKeys(true)
+	decoder.RegisterConverter(time.Time{}, convertTime)
+}
+
+// timeLayouts are the layouts of the time values posted by forms: date,
+// datetime-local and RFC 3339 inputs.
+var timeLayouts = []string{"2006-01-02", "2006-01-02T15:04", "2006-01-02T15:04:05", time.RFC3339}
+
+// convertTime decodes a posted time value. An empty value is the zero time;
+// an invalid one fails the binding.
+func convertTime(value string) reflect.Value {
+	if value == "" {
+		return reflect.ValueOf(time.Time{})
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return reflect.ValueOf(t)
+		}
+	}
+	return reflect.Value{}
 }
@@ -1,6 +1,11 @@
 package validation
 
 import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
 	"net/http/httptest"
 	"net/url"
 	"strings"
@@ -530,6 +535,108 @@ func TestCompany_Invalid(t *testing.T) {
 	assert.Contains(t, errors["siren"], "valid SIREN number")
 }
 
+// Tests international validators
+
+type InternationalContact struct {
+	IBAN       string `json:"iban" validate:"required,iban"`
+	BIC        string `json:"bic" validate:"required,bic"`
+	VAT        string `json:"vat" validate:"required,vat_eu"`
+	Phone      string `json:"phone" validate:"required,phone_e164"`
+	PostalCode string `json:"postal_code" validate:"required,postal_code=DE"`
+}
+
+func TestValidateIBAN(t *testing.T) {
+	valid := []string{
+		"FR7630006000011234567890189",
+		"FR76 3000 6000 0112 3456 7890 189",
+		"DE89370400440532013000",
+		"GB29NWBK60161331926819",
+		"be68539007547034",
+	}
+	for _, iban := range valid {
+		assert.True(t, IsValidIBAN(iban), "IBAN should be valid: %s", iban)
+	}
+
+	invalid := []string{
+		"",
+		"FR7630006000011234567890188", // bad checksum
+		"DE8937040044053201300",       // wrong length
+		"XX89370400440532013000",      // unknown country
+		"not an iban",
+	}
+	for _, iban := range invalid {
+		assert.False(t, IsValidIBAN(iban), "IBAN should be invalid: %s", iban)
+	}
+}
+
+func TestValidateBIC(t *testing.T) {
+	assert.True(t, IsValidBIC("BNPAFRPP"))
+	assert.True(t, IsValidBIC("DEUTDEFF500"))
+	assert.False(t, IsValidBIC("BNPAFRP"))
+	assert.False(t, IsValidBIC("1NPAFRPP"))
+}
+
+func TestValidateVATEU(t *testing.T) {
+	valid := []string{"FR40303265045", "DE123456789", "EL123456789", "NL123456789B01", "ATU12345678", "fr 40 303265045"}
+	for _, vat := range valid {
+		assert.True(t, IsValidVATEU(vat), "VAT should be valid: %s", vat)
+	}
+
+	invalid := []string{"", "FR123", "US123456789", "DE12345678", "GR123456789"}
+	for _, vat := range invalid {
+		assert.False(t, IsValidVATEU(vat), "VAT should be invalid: %s", vat)
+	}
+}
+
+func TestValidatePhoneE164(t *testing.T) {
+	assert.True(t, IsValidPhoneE164("+33612345678"))
+	assert.True(t, IsValidPhoneE164("+14155552671"))
+	assert.False(t, IsValidPhoneE164("0612345678"))
+	assert.False(t, IsValidPhoneE164("+33 6 12 34 56 78"))
+	assert.False(t, IsValidPhoneE164("+0612345678"))
+	assert.False(t, IsValidPhoneE164("+1234567890123456"))
+}
+
+func TestValidatePostalCode(t *testing.T) {
+	tests := []struct {
+		code, country string
+		valid         bool
+	}{
+		{"10115", "DE", true},
+		{"75001", "fr", true},
+		{"2A000", "FR", true},
+		{"SW1A 1AA", "GB", true},
+		{"90210-1234", "US", true},
+		{"K1A 0B1", "CA", true},
+		{"1234 AB", "NL", true},
+		{"00-950", "PL", true},
+		{"1015", "DE", false},
+		{"ABCDE", "US", false},
+		{"12345", "ZZ", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.valid, IsValidPostalCode(tt.code, tt.country), "%s/%s", tt.country, tt.code)
+	}
+	assert.Contains(t, PostalCodeCountries(), "DE")
+}
+
+func TestInternationalContact_Validation(t *testing.T) {
+	contact := InternationalContact{
+		IBAN:       "DE89370400440532013000",
+		BIC:        "DEUTDEFF",
+		VAT:        "DE123456789",
+		Phone:      "+4915112345678",
+		PostalCode: "10115",
+	}
+	assert.Nil(t, ValidateStruct(contact))
+
+	contact.PostalCode = "75001A"
+	errs := StructErrors(contact)
+	require.NotNil(t, errs)
+	assert.Equal(t, "DE", errs["postal_code"][0].Param)
+	assert.Contains(t, errs.Get("postal_code"), "valid postal code for DE")
+}
+
 // Tests global helpers
 
 func TestCheck(t *testing.T) {
@@ -572,6 +679,291 @@ func TestMust(t *testing.T) {
 	})
 }
 
+// Tests structured errors
+
+func TestStructErrors(t *testing.T) {
+	errs := StructErrors(User{Email: "invalid-email", Password: "short", Age: 16})
+	require.NotNil(t, errs)
+
+	assert.Equal(t, []string{"age", "email", "password"}, errs.Fields())
+	assert.Equal(t, []string{"email"}, errs.Rules("email"))
+	assert.Equal(t, "8", errs["password"][0].Param)
+	assert.Contains(t, errs.Get("email"), "valid email address")
+
+	assert.Nil(t, StructErrors(User{Email: "test@example.com", Password: "password123", Age: 25}))
+}
+
+func TestErrors_MultipleMessages(t *testing.T) {
+	errs := NewErrors().
+		Add("password", "min", "8", "Too short").
+		Add("password", "strong_password", "", "Too weak")
+
+	assert.True(t, errs.Has("password"))
+	assert.False(t, errs.Has("email"))
+	assert.Equal(t, []string{"Too short", "Too weak"}, errs.Messages("password"))
+	assert.Equal(t, "Too short", errs.Map()["password"])
+	assert.Equal(t, 1, errs.Len())
+	assert.Equal(t, "Too short; Too weak", errs.Error())
+}
+
+func TestErrors_Empty(t *testing.T) {
+	var errs Errors
+
+	assert.True(t, errs.IsEmpty())
+	assert.Nil(t, errs.Map())
+	assert.Nil(t, errs.MultiMap())
+	assert.NoError(t, errs.Err())
+	assert.Error(t, NewErrors().Add("name", "required", "", "Required").Err())
+}
+
+func TestErrors_JSON(t *testing.T) {
+	errs := NewErrors().
+		Add("name", "required", "", "Name required").
+		Add("email", "email", "", "Invalid email")
+
+	data, err := json.Marshal(errs)
+	require.NoError(t, err)
+	assert.Equal(t,
+		`{"email":[{"field":"email","rule":"email","message":"Invalid email"}],"name":[{"field":"name","rule":"required","message":"Name required"}]}`,
+		string(data))
+
+	rec := httptest.NewRecorder()
+	errs.WriteJSON(rec)
+	assert.Equal(t, 422, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `"message":"validation failed"`)
+}
+
+func TestErrors_MergeAndFromMap(t *testing.T) {
+	errs := FromMap(map[string]string{"email": "Invalid email"}).
+		Merge(NewErrors().Add("email", "unique", "", "Taken"))
+
+	assert.Equal(t, []string{"Invalid email", "Taken"}, errs.Messages("email"))
+	assert.Equal(t, map[string]string{"email": "Invalid email"}, errs.FieldErrors())
+}
+
+// Tests sanitization
+
+type SanitizedContact struct {
+	Name  string   `json:"name" sanitize:"trim,title" validate:"required"`
+	Email string   `json:"email" sanitize:"trim,lower" validate:"required,email"`
+	Phone string   `json:"phone" sanitize:"normalize_phone" validate:"omitempty,phone_e164"`
+	Bio   *string  `json:"bio" sanitize:"strip_html,trim"`
+	Tags  []string `json:"tags" sanitize:"trim,lower"`
+}
+
+func TestSanitizeString(t *testing.T) {
+	assert.Equal(t, "hello", SanitizeString("  <b>Hello</b> ", "strip_html,trim,lower"))
+	assert.Equal(t, "Jean Dupont", SanitizeString("jean dupont", "title"))
+	assert.Equal(t, "+33612345678", SanitizeString("+33 6 12-34.56 (78)", "normalize_phone"))
+	assert.Equal(t, "0612345678", SanitizeString("06 12 34 56 78", "normalize_phone"))
+	assert.Equal(t, "Tom & Jerry", SanitizeString("<p>Tom &amp; Jerry</p>", "strip_html"))
+	assert.Equal(t, " x ", SanitizeString(" x ", "unknown"))
+}
+
+func TestSanitizeString_StripHTMLEncoded(t *testing.T) {
+	assert.Equal(t, "alert(1)", SanitizeString("&lt;script&gt;alert(1)&lt;/script&gt;", "strip_html"))
+	assert.Equal(t, "x", SanitizeString("&amp;lt;img src=x onerror=alert(1)&amp;gt;x", "strip_html"))
+	assert.Equal(t, "a < b", SanitizeString("a &lt; b", "strip_html"))
+	assert.NotContains(t, SanitizeString(strings.Repeat("&amp;", 20)+"lt;script&gt;", "strip_html"), "<")
+}
+
+type sanitizedNode struct {
+	Name string `sanitize:"trim"`
+	Next *sanitizedNode
+}
+
+func TestSanitize_Cycle(t *testing.T) {
+	a := &sanitizedNode{Name: " a "}
+	b := &sanitizedNode{Name: " b ", Next: a}
+	a.Next = b
+
+	Sanitize(a)
+
+	assert.Equal(t, "a", a.Name)
+	assert.Equal(t, "b", b.Name)
+}
+
+func TestSanitize_Struct(t *testing.T) {
+	bio := "  <i>Gopher</i>  "
+	c := SanitizedContact{
+		Name:  "  jean dupont ",
+		Email: " JEAN@Example.com ",
+		Phone: "+33 6 12 34 56 78",
+		Bio:   &bio,
+		Tags:  []string{" Go ", "ADMIN"},
+	}
+
+	Sanitize(&c)
+
+	assert.Equal(t, "Jean Dupont", c.Name)
+	assert.Equal(t, "jean@example.com", c.Email)
+	assert.Equal(t, "+33612345678", c.Phone)
+	assert.Equal(t, "Gopher", *c.Bio)
+	assert.Equal(t, []string{"go", "admin"}, c.Tags)
+}
+
+func TestRegisterSanitizer(t *testing.T) {
+	RegisterSanitizer("digits_only", func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, s)
+	})
+
+	assert.Equal(t, "123", SanitizeString("a1b2c3", "digits_only"))
+}
+
+func TestValidateJSON_Sanitizes(t *testing.T) {
+	body := `{"name":"  ada lovelace ","email":" ADA@Example.com ","phone":"+44 20 7946 0958"}`
+	req := httptest.NewRequest("POST", "/test", strings.NewReader(body))
+
+	var c SanitizedContact
+	errors := ValidateJSON(req, &c)
+
+	assert.Nil(t, errors)
+	assert.Equal(t, "Ada Lovelace", c.Name)
+	assert.Equal(t, "ada@example.com", c.Email)
+	assert.Equal(t, "+442079460958", c.Phone)
+}
+
+func TestValidateForm_Sanitizes(t *testing.T) {
+	form := url.Values{
+		"name":  {" bob "},
+		"email": {" BOB@EXAMPLE.COM"},
+	}
+	req := httptest.NewRequest("POST", "/test", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var c SanitizedContact
+	errors := ValidateForm(req, &c)
+
+	assert.Nil(t, errors)
+	assert.Equal(t, "Bob", c.Name)
+	assert.Equal(t, "bob@example.com", c.Email)
+}
+
+// Tests file uploads
+
+// newFileHeader builds a multipart.FileHeader holding content.
+func newFileHeader(t *testing.T, filename string, content []byte) *multipart.FileHeader {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("upload", filename)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest("POST", "/", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	require.NoError(t, req.ParseMultipartForm(1<<20))
+
+	return req.MultipartForm.File["upload"][0]
+}
+
+func pngBytes(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"1024":  1024,
+		"10B":   10,
+		"500KB": 500 << 10,
+		"5MB":   5 << 20,
+		"1.5mb": 3 << 19,
+		"2GB":   2 << 30,
+	}
+	for in, want := range tests {
+		got, err := ParseSize(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	_, err := ParseSize("five")
+	assert.Error(t, err)
+	assert.Equal(t, "5MB", FormatSize(5<<20))
+}
+
+func TestParseFileRules(t *testing.T) {
+	fr, err := ParseFileRules("image,max=5MB,dimensions=min_width=200|max_height=1000,min_height=50")
+	require.NoError(t, err)
+
+	assert.True(t, fr.Image)
+	assert.Equal(t, int64(5<<20), fr.MaxSize)
+	assert.Equal(t, 200, fr.MinWidth)
+	assert.Equal(t, 1000, fr.MaxHeight)
+	assert.Equal(t, 50, fr.MinHeight)
+
+	_, err = ParseFileRules("unknown=1")
+	assert.Error(t, err)
+}
+
+func TestValidateFile_Image(t *testing.T) {
+	header := newFileHeader(t, "avatar.png", pngBytes(t, 300, 300))
+
+	assert.Nil(t, ValidateFile(header, "image,max=5MB,dimensions=min_width=200"))
+	assert.Nil(t, ValidateFile(header, "mimes=image/png|image/jpeg"))
+
+	errs := ValidateFile(header, "image,dimensions=min_width=400")
+	require.NotNil(t, errs)
+	assert.Equal(t, []string{"file_dimensions"}, errs.Rules("avatar.png"))
+
+	errs = ValidateFile(header, "max=100B")
+	require.NotNil(t, errs)
+	assert.Equal(t, []string{"file_max"}, errs.Rules("avatar.png"))
+	assert.Contains(t, errs.Get("avatar.png"), "100B")
+}
+
+func TestValidateFile_SniffsContent(t *testing.T) {
+	// A text file renamed to .png must not pass as an image.
+	header := newFileHeader(t, "fake.png", []byte("just some plain text"))
+
+	errs := ValidateFile(header, "image")
+	require.NotNil(t, errs)
+	assert.Equal(t, []string{"file_image"}, errs.Rules("fake.png"))
+
+	errs = ValidateFile(header, "mimes=image/*")
+	require.NotNil(t, errs)
+	assert.Equal(t, []string{"file_mime"}, errs.Rules("fake.png"))
+
+	assert.Nil(t, ValidateFile(header, "mimes=text/plain"))
+}
+
+func TestValidateFile_UnsniffableTypes(t *testing.T) {
+	csv := newFileHeader(t, "users.csv", []byte("name,email\njane,jane@example.com\n"))
+	assert.Nil(t, ValidateFile(csv, "mimes=text/csv"))
+	assert.Nil(t, ValidateFile(csv, "mimes="+strings.Join(AcceptToMIMETypes(".csv,.json"), "|")))
+
+	renamed := newFileHeader(t, "notes.txt", []byte("name,email\n"))
+	errs := ValidateFile(renamed, "mimes=text/csv")
+	require.NotNil(t, errs)
+	assert.Equal(t, []string{"file_mime"}, errs.Rules("notes.txt"))
+
+	zip := []byte("PK\x03\x04\x14\x00\x06\x00")
+	assert.Nil(t, ValidateFile(newFileHeader(t, "report.xlsx", zip), "mimes="+strings.Join(AcceptToMIMETypes(".xlsx"), "|")))
+
+	// A PNG named .csv is sniffed as an image, not as text
+	errs = ValidateFile(newFileHeader(t, "fake.csv", pngBytes(t, 1, 1)), "mimes=text/csv")
+	require.NotNil(t, errs)
+	assert.Equal(t, []string{"file_mime"}, errs.Rules("fake.csv"))
+}
+
+func TestAcceptToMIMETypes(t *testing.T) {
+	assert.Equal(t, []string{"image/*", "application/pdf"}, AcceptToMIMETypes("image/*, .pdf"))
+	assert.Equal(t, []string{"text/csv", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"}, AcceptToMIMETypes(".csv,.XLSX"))
+	assert.Nil(t, AcceptToMIMETypes(""))
+}
+
 // Benchmarks
 
 func BenchmarkValidateStruct_Valid(b *testing.B) {
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"context"
+	"time"
+)
+
+// DeviceRow is a device listed on the profile page.
+type DeviceRow struct {
+	ID         string
+	Name       string // e.g. "Chrome on macOS"
+	IP         string
+	LastSeen   time.Time
+	Current    bool // the device of the request
+	Remembered bool // signs in without a password
+}
+
+type devicesKey struct{}
+
+// WithDevices returns ctx listing devices on the profile page.
+func WithDevices(ctx context.Context, devices []DeviceRow) context.Context {
+	return context.WithValue(ctx, devicesKey{}, devices)
+}
+
+// devicesFromContext returns the devices of the profile page and whether
+// the panel recognizes devices.
+func devicesFromContext(ctx context.Context) ([]DeviceRow, bool) {
+	devices, ok := ctx.Value(devicesKey{}).([]DeviceRow)
+	return devices, ok
+}
@@ -32,7 +32,20 @@ func ToastContainer() templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div id=\"toast-container\" class=\"fixed top-4 right-4 z-50 flex flex-col gap-2\" x-data=\"toastManager()\" @toast.window=\"addToast($event.detail)\"><template x-for=\"toast in toasts\" :key=\"toast.id\"><div x-show=\"toast.visible\" x-transition:enter=\"transform ease-out duration-300 transition\" x-transition:enter-start=\"translate-x-full opacity-0\" x-transition:enter-end=\"translate-x-0 opacity-100\" x-transition:leave=\"transform ease-in duration-200 transition\" x-transition:leave-start=\"translate-x-0 opacity-100\" x-transition:leave-end=\"translate-x-full opacity-0\" :class=\"{\r\n\t\t\t\t\t'bg-green-50 border-green-200 dark:bg-green-900/20 dark:border-green-800': toast.type === 'success',\r\n\t\t\t\t\t'bg-red-50 border-red-200 dark:bg-red-900/20 dark:border-red-800': toast.type === 'error',\r\n\t\t\t\t\t'bg-yellow-50 border-yellow-200 dark:bg-yellow-900/20 dark:border-yellow-800': toast.type === 'warning',\r\n\t\t\t\t\t'bg-blue-50 border-blue-200 dark:bg-blue-900/20 dark:border-blue-800': toast.type === 'info'\r\n\t\t\t\t}\" class=\"flex items-start p-4 rounded-lg border shadow-lg max-w-sm\"><!-- Icon --><div class=\"flex-shrink-0\"><template x-if=\"toast.type === 'success'\"><span class=\"material-icons-outlined text-xl text-green-600 dark:text-green-500\">check_circle</span></template><template x-if=\"toast.type === 'error'\"><span class=\"material-icons-outlined text-xl text-red-600 dark:text-red-500\">cancel</span></template><template x-if=\"toast.type === 'warning'\"><span class=\"material-icons-outlined text-xl text-yellow-600 dark:text-yellow-500\">warning</span></template><template x-if=\"toast.type === 'info'\"><span class=\"material-icons-outlined text-xl text-blue-600 dark:text-blue-500\">info</span></template></div><!-- Content --><div class=\"ml-3 flex-1\"><template x-if=\"toast.title\"><p x-text=\"toast.title\" :class=\"{\r\n\t\t\t\t\t\t\t\t'text-green-800 dark:text-green-400': toast.type === 'success',\r\n\t\t\t\t\t\t\t\t'text-red-800 dark:text-red-400': toast.type === 'error',\r\n\t\t\t\t\t\t\t\t'text-yellow-800 dark:text-yellow-400': toast.type === 'warning',\r\n\t\t\t\t\t\t\t\t'text-blue-800 dark:text-blue-400': toast.type === 'info'\r\n\t\t\t\t\t\t\t}\" class=\"text-sm font-medium\"></p></template><p x-text=\"toast.message\" :class=\"{\r\n\t\t\t\t\t\t\t'text-green-700 dark:text-green-300': toast.type === 'success',\r\n\t\t\t\t\t\t\t'text-red-700 dark:text-red-300': toast.type === 'error',\r\n\t\t\t\t\t\t\t'text-yellow-700 dark:text-yellow-300': toast.type === 'warning',\r\n\t\t\t\t\t\t\t'text-blue-700 dark:text-blue-300': toast.type === 'info'\r\n\t\t\t\t\t\t}\" class=\"text-sm\" :class=\"{ 'mt-1': toast.title }\"></p></div><!-- Close button --><button @click=\"removeToast(toast.id)\" type=\"button\" class=\"ml-3 flex-shrink-0 inline-flex rounded-lg p-1.5 hover:bg-black/5 dark:hover:bg-white/5\"><span class=\"sr-only\">Close</span> <span class=\"material-icons-outlined text-base\">close</span></button></div></template></div><script>\r\n\t\tfunction toastManager() {\r\n\t\t\treturn {\r\n\t\t\t\ttoasts: [],\r\n\t\t\t\tnextId: 1,\r\n\r\n\t\t\t\taddToast(data) {\r\n\t\t\t\t\tconst id = this.nextId++;\r\n\t\t\t\t\tconst toast = {\r\n\t\t\t\t\t\tid,\r\n\t\t\t\t\t\ttype: data.type || 'info',\r\n\t\t\t\t\t\ttitle: data.title || '',\r\n\t\t\t\t\t\tmessage: data.message || '',\r\n\t\t\t\t\t\tvisible: false,\r\n\t\t\t\t\t\tduration: data.duration || 5000\r\n\t\t\t\t\t};\r\n\r\n\t\t\t\t\tthis.toasts.push(toast);\r\n\r\n\t\t\t\t\t// Entry animation\r\n\t\t\t\t\tthis.$nextTick(() => {\r\n\t\t\t\t\t\ttoast.visible = true;\r\n\t\t\t\t\t});\r\n\r\n\t\t\t\t\t// Auto-remove\r\n\t\t\t\t\tif (toast.duration > 0) {\r\n\t\t\t\t\t\tsetTimeout(() => {\r\n\t\t\t\t\t\t\tthis.removeToast(id);\r\n\t\t\t\t\t\t}, toast.duration);\r\n\t\t\t\t\t}\r\n\t\t\t\t},\r\n\r\n\t\t\t\tremoveToast(id) {\r\n\t\t\t\t\tconst toast = this.toasts.find(t => t.id === id);\r\n\t\t\t\t\tif (toast) {\r\n\t\t\t\t\t\ttoast.visible = false;\r\n\t\t\t\t\t\tsetTimeout(() => {\r\n\t\t\t\t\t\t\tthis.toasts = this.toasts.filter(t => t.id !== id);\r\n\t\t\t\t\t\t}, 200);\r\n\t\t\t\t\t}\r\n\t\t\t\t}\r\n\t\t\t};\r\n\t\t}\r\n\r\n\t\t// Global helpers to trigger toasts\r\n\t\twindow.toast = {\r\n\t\t\tsuccess(message, title = '') {\r\n\t\t\t\twindow.dispatchEvent(new CustomEvent('toast', {\r\n\t\t\t\t\tdetail: { type: 'success', message, title }\r\n\t\t\t\t}));\r\n\t\t\t},\r\n\t\t\terror(message, title = '') {\r\n\t\t\t\twindow.dispatchEvent(new CustomEvent('toast', {\r\n\t\t\t\t\tdetail: { type: 'error', message, title }\r\n\t\t\t\t}));\r\n\t\t\t},\r\n\t\t\twarning(message, title = '') {\r\n\t\t\t\twindow.dispatchEvent(new CustomEvent('toast', {\r\n\t\t\t\t\tdetail: { type: 'warning', message, title }\r\n\t\t\t\t}));\r\n\t\t\t},\r\n\t\t\tinfo(message, title = '') {\r\n\t\t\t\twindow.dispatchEvent(new CustomEvent('toast', {\r\n\t\t\t\t\tdetail: { type: 'info', message, title }\r\n\t\t\t\t}));\r\n\t\t\t}\r\n\t\t};\r\n\t</script>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div id=\"toast-container\" class=\"fixed top-4 right-4 z-50 flex flex-col gap-2\" x-data=\"toastManager()\" @toast.window=\"addToast($event.detail)\"><template x-for=\"toast in toasts\" :key=\"toast.id\"><div x-show=\"toast.visible\" x-transition:enter=\"transform ease-out duration-300 transition\" x-transition:enter-start=\"translate-x-full opacity-0\" x-transition:enter-end=\"translate-x-0 opacity-100\" x-transition:leave=\"transform ease-in duration-200 transition\" x-transition:leave-start=\"translate-x-0 opacity-100\" x-transition:leave-end=\"translate-x-full opacity-0\" :class=\"{\n\t\t\t\t\t'bg-green-50 border-green-200 dark:bg-green-900/20 dark:border-green-800': toast.type === 'success',\n\t\t\t\t\t'bg-red-50 border-red-200 dark:bg-red-900/20 dark:border-red-800': toast.type === 'error',\n\t\t\t\t\t'bg-yellow-50 border-yellow-200 dark:bg-yellow-900/20 dark:border-yellow-800': toast.type === 'warning',\n\t\t\t\t\t'bg-blue-50 border-blue-200 dark:bg-blue-900/20 dark:border-blue-800': toast.type === 'info'\n\t\t\t\t}\" class=\"flex items-start p-4 rounded-lg border shadow-lg max-w-sm\"><!-- Icon --><div class=\"flex-shrink-0\"><template x-if=\"toast.type === 'success'\"><span class=\"material-icons-outlined text-xl text-green-600 dark:text-green-500\">check_circle</span></template><template x-if=\"toast.type === 'error'\"><span class=\"material-icons-outlined text-xl text-red-600 dark:text-red-500\">cancel</span></template><template x-if=\"toast.type === 'warning'\"><span class=\"material-icons-outlined text-xl text-yellow-600 dark:text-yellow-500\">warning</span></template><template x-if=\"toast.type === 'info'\"><span class=\"material-icons-outlined text-xl text-blue-600 dark:text-blue-500\">info</span></template></div><!-- Content --><div class=\"ml-3 flex-1\"><template x-if=\"toast.title\"><p x-text=\"toast.title\" :class=\"{\n\t\t\t\t\t\t\t\t'text-green-800 dark:text-green-400': toast.type === 'success',\n\t\t\t\t\t\t\t\t'text-red-800 dark:text-red-400': toast.type === 'error',\n\t\t\t\t\t\t\t\t'text-yellow-800 dark:text-yellow-400': toast.type === 'warning',\n\t\t\t\t\t\t\t\t'text-blue-800 dark:text-blue-400': toast.type === 'info'\n\t\t\t\t\t\t\t}\" class=\"text-sm font-medium\"></p></template><p x-text=\"toast.message\" :class=\"{\n\t\t\t\t\t\t\t'text-green-700 dark:text-green-300': toast.type === 'success',\n\t\t\t\t\t\t\t'text-red-700 dark:text-red-300': toast.type === 'error',\n\t\t\t\t\t\t\t'text-yellow-700 dark:text-yellow-300': toast.type === 'warning',\n\t\t\t\t\t\t\t'text-blue-700 dark:text-blue-300': toast.type === 'info'\n\t\t\t\t\t\t}\" class=\"text-sm\" :class=\"{ 'mt-1': toast.title }\"></p></div><!-- Close button --><button @click=\"removeToast(toast.id)\" type=\"button\" class=\"ml-3 flex-shrink-0 inline-flex rounded-lg p-1.5 hover:bg-black/5 dark:hover:bg-white/5\"><span class=\"sr-only\">Close</span> <span class=\"material-icons-outlined text-base\">close</span></button></div></template></div><script nonce=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var2 string
+		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinStringErrs(templ.GetNonce(ctx))
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/components/toast.templ`, Line: 86, Col: 36}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "\">\n\t\tfunction toastManager() {\n\t\t\treturn {\n\t\t\t\ttoasts: [],\n\t\t\t\tnextId: 1,\n\n\t\t\t\taddToast(data) {\n\t\t\t\t\tconst id = this.nextId++;\n\t\t\t\t\tconst toast = {\n\t\t\t\t\t\tid,\n\t\t\t\t\t\ttype: data.type || 'info',\n\t\t\t\t\t\ttitle: data.title || '',\n\t\t\t\t\t\tmessage: data.message || '',\n\t\t\t\t\t\tvisible: false,\n\t\t\t\t\t\tduration: data.duration || 5000\n\t\t\t\t\t};\n\n\t\t\t\t\tthis.toasts.push(toast);\n\n\t\t\t\t\t// Entry animation\n\t\t\t\t\tthis.$nextTick(() => {\n\t\t\t\t\t\ttoast.visible = true;\n\t\t\t\t\t});\n\n\t\t\t\t\t// Auto-remove\n\t\t\t\t\tif (toast.duration > 0) {\n\t\t\t\t\t\tsetTimeout(() => {\n\t\t\t\t\t\t\tthis.removeToast(id);\n\t\t\t\t\t\t}, toast.duration);\n\t\t\t\t\t}\n\t\t\t\t},\n\n\t\t\t\tremoveToast(id) {\n\t\t\t\t\tconst toast = this.toasts.find(t => t.id === id);\n\t\t\t\t\tif (toast) {\n\t\t\t\t\t\ttoast.visible = false;\n\t\t\t\t\t\tsetTimeout(() => {\n\t\t\t\t\t\t\tthis.toasts = this.toasts.filter(t => t.id !== id);\n\t\t\t\t\t\t}, 200);\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t};\n\t\t}\n\n\t\t// Global helpers to trigger toasts\n\t\twindow.toast = {\n\t\t\tsuccess(message, title = '') {\n\t\t\t\twindow.dispatchEvent(new CustomEvent('toast', {\n\t\t\t\t\tdetail: { type: 'success', message, title }\n\t\t\t\t}));\n\t\t\t},\n\t\t\terror(message, title = '') {\n\t\t\t\twindow.dispatchEvent(new CustomEvent('toast', {\n\t\t\t\t\tdetail: { type: 'error', message, title }\n\t\t\t\t}));\n\t\t\t},\n\t\t\twarning(message, title = '') {\n\t\t\t\twindow.dispatchEvent(new CustomEvent('toast', {\n\t\t\t\t\tdetail: { type: 'warning', message, title }\n\t\t\t\t}));\n\t\t\t},\n\t\t\tinfo(message, title = '') {\n\t\t\t\twindow.dispatchEvent(new CustomEvent('toast', {\n\t\t\t\t\tdetail: { type: 'info', message, title }\n\t\t\t\t}));\n\t\t\t}\n\t\t};\n\t</script>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -57,13 +70,26 @@ func ToastFromFlash(messages []flash.Message) templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var2 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var2 == nil {
-			templ_7745c5c3_Var2 = templ.NopComponent
+		templ_7745c5c3_Var3 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var3 == nil {
+			templ_7745c5c3_Var3 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
 		if len(messages) > 0 {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<script>\r\n\t\t\tdocument.addEventListener('DOMContentLoaded', function() {\r\n\t\t\t\tfor msg of messages {\r\n\t\t\t\t\tconst toastData = {\r\n\t\t\t\t\t\ttype: msg.Type,\r\n\t\t\t\t\t\tmessage: msg.Text,\r\n\t\t\t\t\t\ttitle: msg.Title || ''\r\n\t\t\t\t\t};\r\n\t\t\t\t\twindow.dispatchEvent(new CustomEvent('toast', { detail: toastData }));\r\n\t\t\t\t}\r\n\t\t\t});\r\n\t\t</script>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "<script nonce=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var4 string
+			templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(templ.GetNonce(ctx))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/components/toast.templ`, Line: 159, Col: 37}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "\">\n\t\t\tdocument.addEventListener('DOMContentLoaded', function() {\n\t\t\t\tfor msg of messages {\n\t\t\t\t\tconst toastData = {\n\t\t\t\t\t\ttype: msg.Type,\n\t\t\t\t\t\tmessage: msg.Text,\n\t\t\t\t\t\ttitle: msg.Title || ''\n\t\t\t\t\t};\n\t\t\t\t\twindow.dispatchEvent(new CustomEvent('toast', { detail: toastData }));\n\t\t\t\t}\n\t\t\t});\n\t\t</script>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
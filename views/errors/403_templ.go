@@ -61,7 +61,7 @@ func Page403(data Error403Data) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		if data.Path != "" || data.RequestID != "" {
+		if data.Path != "" || referenceID(ctx, data.RequestID) != "" {
 			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<div class=\"mt-6 bg-white rounded-lg shadow px-4 py-3\"><dl class=\"space-y-2 text-sm\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
@@ -85,15 +85,15 @@ func Page403(data Error403Data) templ.Component {
 					return templ_7745c5c3_Err
 				}
 			}
-			if data.RequestID != "" {
+			if referenceID(ctx, data.RequestID) != "" {
 				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "<div class=\"flex justify-between\"><dt class=\"font-medium text-gray-500\">Request ID:</dt><dd class=\"text-gray-900 font-mono text-xs\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 				var templ_7745c5c3_Var4 string
-				templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(data.RequestID)
+				templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(referenceID(ctx, data.RequestID))
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/errors/403.templ`, Line: 59, Col: 70}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/errors/403.templ`, Line: 59, Col: 88}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
 				if templ_7745c5c3_Err != nil {
@@ -58,15 +58,15 @@ func Page404(data Error404Data) templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			if data.RequestID != "" {
+			if referenceID(ctx, data.RequestID) != "" {
 				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<div class=\"flex justify-between\"><dt class=\"font-medium text-gray-500\">Request ID:</dt><dd class=\"text-gray-900 font-mono text-xs\">")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 				var templ_7745c5c3_Var3 string
-				templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(data.RequestID)
+				templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs(referenceID(ctx, data.RequestID))
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/errors/404.templ`, Line: 52, Col: 70}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `views/errors/404.templ`, Line: 52, Col: 88}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
 				if templ_7745c5c3_Err != nil {
@@ -86,7 +86,7 @@ func Page404(data Error404Data) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		if data.RequestID != "" {
+		if referenceID(ctx, data.RequestID) != "" {
 			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "<p>If you think this is an error, <a href=\"/contact\" class=\"font-medium text-indigo-600 hover:text-indigo-500\">contact support</a> with the request ID above.</p>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
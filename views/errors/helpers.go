@@ -0,0 +1,16 @@
+package errors
+
+import (
+	"context"
+
+	"github.com/bozz33/sublimeadmin/logger"
+)
+
+// referenceID returns the request ID shown on error pages: the explicit
+// value when set, otherwise the one stored by middleware.RequestID.
+func referenceID(ctx context.Context, explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	return logger.RequestIDFromContext(ctx)
+}
@@ -0,0 +1,95 @@
+package errors
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/apperrors"
+)
+
+// Panel403 is the themed "access denied" page.
+func Panel403() templ.Component {
+	return PanelError(PanelErrorData{
+		StatusCode:  http.StatusForbidden,
+		Title:       "Access Denied",
+		Description: "You do not have permission to access this resource.",
+		Icon:        "lock",
+		Color:       "amber",
+	})
+}
+
+// Panel404 is the themed "page not found" page.
+func Panel404() templ.Component {
+	return PanelError(PanelErrorData{
+		StatusCode:  http.StatusNotFound,
+		Title:       "Page Not Found",
+		Description: "The page you are looking for has been moved, deleted or does not exist.",
+		Icon:        "search_off",
+		Color:       "blue",
+	})
+}
+
+// Panel419 is the themed "page expired" page, shown on CSRF token
+// mismatches and expired sessions.
+func Panel419() templ.Component {
+	return PanelError(PanelErrorData{
+		StatusCode:  apperrors.StatusPageExpired,
+		Title:       "Page Expired",
+		Description: "The page has expired, please refresh and try again.",
+		Icon:        "hourglass_empty",
+		Color:       "gray",
+		Reload:      true,
+	})
+}
+
+// Panel429 is the themed "too many requests" page. The retry delay comes
+// from the "retry_after" field of the error, in seconds.
+func Panel429() templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		return TooManyRequests("", retryAfter(ctx)).Render(ctx, w)
+	})
+}
+
+// Panel500 is the themed "server error" page. It never shows the error
+// message, which may contain internal details.
+func Panel500() templ.Component {
+	return PanelError(PanelErrorData{
+		StatusCode:  http.StatusInternalServerError,
+		Title:       "Server Error",
+		Description: "An internal error occurred. Our teams have been notified.",
+		Icon:        "error",
+		Color:       "red",
+		Reload:      true,
+	})
+}
+
+// PanelPages returns the themed error pages, keyed by status code, for
+// apperrors.WithErrorPage.
+func PanelPages() map[int]templ.Component {
+	return map[int]templ.Component{
+		http.StatusForbidden:           Panel403(),
+		http.StatusNotFound:            Panel404(),
+		apperrors.StatusPageExpired:    Panel419(),
+		http.StatusTooManyRequests:     Panel429(),
+		http.StatusInternalServerError: Panel500(),
+	}
+}
+
+// retryAfter returns the "retry_after" field of the rendered error.
+func retryAfter(ctx context.Context) int {
+	err := apperrors.ErrorFromContext(ctx)
+	if err == nil {
+		return 0
+	}
+	switch v := err.Fields["retry_after"].(type) {
+	case int:
+		return v
+	case string:
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	return 0
+}
@@ -1,17 +1,36 @@
 package generics
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/bozz33/sublimeadmin/actions"
+	"github.com/bozz33/sublimeadmin/engine"
 )
 
+// actionModalDetail is the detail of the open-action-modal event.
+type actionModalDetail struct {
+	URL          string `json:"url"`
+	Method       string `json:"method"`
+	Title        string `json:"title"`
+	Desc         string `json:"desc"`
+	ConfirmLabel string `json:"confirmLabel"`
+	CancelLabel  string `json:"cancelLabel"`
+	Color        string `json:"color"`
+	Phrase       string `json:"phrase,omitempty"`
+	SummaryURL   string `json:"summaryUrl,omitempty"`
+	Async        bool   `json:"async,omitempty"`
+}
+
+// dispatch returns the Alpine.js $dispatch call opening ActionConfirmModal.
+// The detail is JSON so quotes in titles or phrases cannot break the expression.
+func (d actionModalDetail) dispatch() string {
+	detail, _ := json.Marshal(d)
+	return fmt.Sprintf("$dispatch('open-action-modal', %s)", detail)
+}
+
 // actionModalDispatch builds the Alpine.js $dispatch call string for a confirmation modal.
 func actionModalDispatch(a *actions.Action, item any) string {
-	url := ""
-	if a.UrlResolver != nil {
-		url = a.UrlResolver(item)
-	}
 	method := a.Method
 	if method == "" {
 		method = "POST"
@@ -24,16 +43,178 @@ func actionModalDispatch(a *actions.Action, item any) string {
 	if cancelLabel == "" {
 		cancelLabel = "Cancel"
 	}
-	title := a.ModalTitle
-	desc := a.ModalDescription
 	color := a.Color
 	if color == "" {
 		color = "red"
 	}
-	return fmt.Sprintf(
-		`$dispatch('open-action-modal', { url: '%s', method: '%s', title: '%s', desc: '%s', confirmLabel: '%s', cancelLabel: '%s', color: '%s' })`,
-		url, method, title, desc, confirmLabel, cancelLabel, color,
-	)
+	return actionModalDetail{
+		URL:          a.URL(item),
+		Method:       method,
+		Title:        a.ModalTitle,
+		Desc:         a.ModalDescription,
+		ConfirmLabel: confirmLabel,
+		CancelLabel:  cancelLabel,
+		Color:        color,
+		Phrase:       a.ConfirmationPhrase(item),
+		SummaryURL:   a.SummaryURL(item),
+		Async:        a.Async,
+	}.dispatch()
+}
+
+// deleteRowDispatch builds the $dispatch call of the delete button of a
+// list row: the modal shows what the deletion cascades to, asks for the
+// row's DeletePhrase when set and deletes in the background.
+func deleteRowDispatch(state engine.TableState, row engine.Row) string {
+	url := fmt.Sprintf("%s/%s", state.BaseURL, row.ID)
+	return actionModalDetail{
+		URL:          url,
+		Method:       "DELETE",
+		Title:        "Delete this record?",
+		Desc:         "This action cannot be undone.",
+		ConfirmLabel: "Delete",
+		CancelLabel:  "Cancel",
+		Color:        "red",
+		Phrase:       row.DeletePhrase,
+		SummaryURL:   url + "/delete-summary",
+		Async:        true,
+	}.dispatch()
+}
+
+// bulkActionCall builds the Alpine.js call of a bulk action button: the
+// selected ids are posted to the action, after ActionConfirmModal when the
+// action asks for a confirmation.
+func bulkActionCall(ba engine.BulkActionDef) string {
+	if ba.Confirm == nil {
+		return fmt.Sprintf("bulkAction('%s')", ba.URL)
+	}
+	confirmLabel := ba.Confirm.ConfirmLabel
+	if confirmLabel == "" {
+		confirmLabel = "Confirm"
+	}
+	detail, _ := json.Marshal(actionModalDetail{
+		URL:          ba.URL,
+		Method:       "POST",
+		Title:        ba.Confirm.Title,
+		Desc:         ba.Confirm.Description,
+		ConfirmLabel: confirmLabel,
+		CancelLabel:  "Cancel",
+		Color:        ba.Color,
+		Async:        true,
+	})
+	return fmt.Sprintf("bulkAction('%s', %s)", ba.URL, detail)
+}
+
+// bulkConfirms reports whether a bulk action opens ActionConfirmModal.
+func bulkConfirms(bulkActions []engine.BulkActionDef) bool {
+	for _, ba := range bulkActions {
+		if ba.Confirm != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// rowActionDispatch builds the $dispatch call of a row action asking for
+// a confirmation; the action is submitted in the background.
+func rowActionDispatch(a engine.ActionDef) string {
+	confirmLabel := a.Confirm.ConfirmLabel
+	if confirmLabel == "" {
+		confirmLabel = "Confirm"
+	}
+	return actionModalDetail{
+		URL:          a.URL,
+		Method:       a.Method,
+		Title:        a.Confirm.Title,
+		Desc:         a.Confirm.Description,
+		ConfirmLabel: confirmLabel,
+		CancelLabel:  "Cancel",
+		Color:        a.Color,
+		Async:        true,
+	}.dispatch()
+}
+
+// rowActions returns the actions of a row, including those of its
+// dropdowns.
+func rowActions(row engine.Row) []engine.ActionDef {
+	all := row.Actions
+	for _, g := range row.ActionGroups {
+		all = append(all[:len(all):len(all)], g.Actions...)
+	}
+	return all
+}
+
+// actionsConfirm reports whether one of the actions opens
+// ActionConfirmModal.
+func actionsConfirm(defs []engine.ActionDef) bool {
+	for _, a := range defs {
+		if a.Confirm != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// rowsConfirm reports whether an action of a row opens ActionConfirmModal.
+func rowsConfirm(rows []engine.Row) bool {
+	for _, row := range rows {
+		if actionsConfirm(rowActions(row)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFormActions reports whether an action of the list opens its form in
+// the action modal.
+func hasFormActions(state engine.TableState) bool {
+	for _, a := range state.ListActions {
+		if a.Form {
+			return true
+		}
+	}
+	for _, row := range state.Rows {
+		for _, a := range rowActions(row) {
+			if a.Form {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rowActionIcon returns the icon of a row action, "more_horiz" by default.
+func rowActionIcon(a engine.ActionDef) string {
+	if a.Icon != "" {
+		return a.Icon
+	}
+	return "more_horiz"
+}
+
+// rowActionGroupIcon returns the icon of a dropdown of row actions,
+// "more_vert" by default.
+func rowActionGroupIcon(g engine.ActionGroupDef) string {
+	if g.Icon != "" {
+		return g.Icon
+	}
+	return "more_vert"
+}
+
+// rowActionMenuItemClass returns Tailwind classes for an action inside a
+// dropdown of row actions.
+func rowActionMenuItemClass(color string) string {
+	base := "w-full flex items-center gap-2 px-3 py-2 text-sm text-left transition-colors hover:bg-gray-50 dark:hover:bg-gray-700 "
+	switch color {
+	case "danger", "red":
+		return base + "text-red-600 dark:text-red-400"
+	case "primary":
+		return base + "text-primary-600 dark:text-primary-400"
+	case "success", "green":
+		return base + "text-green-600 dark:text-green-400"
+	case "warning", "yellow":
+		return base + "text-yellow-600 dark:text-yellow-400"
+	default:
+		return base + "text-gray-700 dark:text-gray-300"
+	}
 }
 
 // actionButtonClass returns Tailwind classes for a full action button by color.
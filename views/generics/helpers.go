@@ -2,11 +2,103 @@ package generics
 
 import (
 	"fmt"
+	"maps"
+	"net/url"
+	"slices"
 	"strings"
 
+	"github.com/a-h/templ"
 	"github.com/bozz33/sublimeadmin/engine"
+	"github.com/bozz33/sublimeadmin/table"
 )
 
+// drawerURL returns the URL of a create/edit form rendered for the
+// slide-over of the list (see engine.DisplayDrawer).
+func drawerURL(u string) string {
+	sep := "?"
+	if strings.Contains(u, "?") {
+		sep = "&"
+	}
+	return u + sep + "display=" + engine.DisplayDrawer
+}
+
+// listSearchForm is the id of the search form of the list toolbar, which
+// the inline column searches belong to.
+const listSearchForm = "list-search"
+
+// columnSearchQuery returns the inline column searches of the list as query
+// params to append to its links, e.g. "&col_title=go".
+func columnSearchQuery(state engine.TableState) string {
+	if len(state.ColumnSearch) == 0 {
+		return ""
+	}
+	q := url.Values{}
+	for key, v := range state.ColumnSearch {
+		q.Set(engine.ColumnSearchPrefix+key, v)
+	}
+	return "&" + q.Encode()
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]string) []string {
+	return slices.Sorted(maps.Keys(m))
+}
+
+// cursorURL returns the link to the page of cursor in a list paginated by
+// cursor, or "" without one.
+func cursorURL(state engine.TableState, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+	q := url.Values{}
+	q.Set("sort", state.SortKey)
+	q.Set("dir", state.SortDir)
+	q.Set("search", state.Search)
+	q.Set("per_page", fmt.Sprint(state.Pagination.PerPage))
+	q.Set("cursor", cursor)
+	for key, v := range state.ColumnSearch {
+		q.Set(engine.ColumnSearchPrefix+key, v)
+	}
+	return "?" + q.Encode()
+}
+
+// hasPages reports whether the list has other pages than the current one.
+// treeIndent indents the first cell of a tree row by its depth.
+func treeIndent(depth int) templ.SafeCSS {
+	return templ.SafeCSS(fmt.Sprintf("padding-left: %.1frem;", float64(depth)*1.5))
+}
+
+func hasPages(p *engine.Pagination) bool {
+	return p.LastPage > 1 || p.HasMore || p.CurrentPage > 1
+}
+
+// showPageSizes reports whether the page size of the list can be chosen:
+// there are choices and more records than the smallest.
+func showPageSizes(state engine.TableState) bool {
+	return len(state.PageSizes) > 1 && (hasPages(state.Pagination) || state.Pagination.Total > state.PageSizes[0])
+}
+
+// paginationShowing returns the range of the records of the page and
+// their total as counted (see engine.CountStrategy), e.g. "Showing 21–40
+// of 10000+".
+func paginationShowing(p *engine.Pagination) string {
+	from := (p.CurrentPage-1)*p.PerPage + 1
+	to := p.CurrentPage * p.PerPage
+	if !p.HasMore {
+		to = min(to, p.Total)
+	}
+	switch p.Count {
+	case engine.CountNone:
+		return fmt.Sprintf("Showing %d–%d", from, to)
+	case engine.CountEstimated:
+		return fmt.Sprintf("Showing %d–%d of about %d", from, to, p.Total)
+	case engine.CountCapped:
+		return fmt.Sprintf("Showing %d–%d of %d+", from, to, p.Total)
+	default:
+		return fmt.Sprintf("Showing %d–%d of %d", from, to, p.Total)
+	}
+}
+
 // getValueStr returns the value as a string
 func getValueStr(v any) string {
 	if v == nil {
@@ -90,3 +182,25 @@ func min(a, b int) int { //nolint:unused
 	}
 	return b
 }
+
+// filterMultiple reports whether several options of the filter f can be
+// picked in the filter panel.
+func filterMultiple(f table.Filter) bool {
+	if rf, ok := f.(*table.RelationFilter); ok {
+		return rf.Multi
+	}
+	return f.Type() == "multiselect"
+}
+
+// filterChecked reports whether the option v of the filter f is picked.
+func filterChecked(state engine.TableState, f table.Filter, v string) bool {
+	return slices.Contains(state.FilterValues[f.Key()], v)
+}
+
+// filterStep returns the step of the inputs of a number filter.
+func filterStep(f table.Filter) string {
+	if nf, ok := f.(*table.NumberFilter); ok && nf.Step != "" {
+		return nf.Step
+	}
+	return "any"
+}
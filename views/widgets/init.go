@@ -27,4 +27,7 @@ func init() {
 	widget.SetListRenderer(func(w *widget.ListWidget) templ.Component {
 		return List(w)
 	})
+	widget.SetServerMetricsRenderer(func(w *widget.ServerMetricsWidget) templ.Component {
+		return ServerMetrics(w)
+	})
 }
@@ -0,0 +1,64 @@
+package widget
+
+import (
+	"github.com/a-h/templ"
+	"github.com/bozz33/sublimeadmin/metrics"
+)
+
+// ServerMetricsWidget shows the traffic of the panel itself, recorded by
+// middleware.Metrics: throughput, p95 latency and error rate over the last
+// minutes, with a sparkline of each over the whole window.
+type ServerMetricsWidget struct {
+	Title      string
+	Window     *metrics.Window
+	Recent     int    // intervals of the figures (default 5, the last 5 minutes of metrics.HTTPWindow)
+	DetailsURL string // link to the drill-down page (empty = none)
+}
+
+// NewServerMetrics creates a server metrics widget reading window, or
+// metrics.HTTPWindow when nil.
+func NewServerMetrics(window *metrics.Window) *ServerMetricsWidget {
+	if window == nil {
+		window = metrics.HTTPWindow
+	}
+	return &ServerMetricsWidget{Title: "Server", Window: window, Recent: 5}
+}
+
+// WithTitle sets the widget title.
+func (w *ServerMetricsWidget) WithTitle(title string) *ServerMetricsWidget {
+	w.Title = title
+	return w
+}
+
+// WithDetailsURL links the widget to a drill-down page.
+func (w *ServerMetricsWidget) WithDetailsURL(url string) *ServerMetricsWidget {
+	w.DetailsURL = url
+	return w
+}
+
+// Summary returns the traffic of the last Recent intervals.
+func (w *ServerMetricsWidget) Summary() metrics.Point {
+	return w.Window.Summary(max(w.Recent, 1))
+}
+
+// Throughput returns the requests per minute of the last Recent intervals.
+func (w *ServerMetricsWidget) Throughput() float64 {
+	minutes := float64(max(w.Recent, 1)) * w.Window.Interval().Minutes()
+	return float64(w.Summary().Requests) / minutes
+}
+
+func (w *ServerMetricsWidget) GetType() string { return "server_metrics" }
+
+var serverMetricsRenderFunc func(*ServerMetricsWidget) templ.Component
+
+// SetServerMetricsRenderer registers the render function (called from views/widgets init).
+func SetServerMetricsRenderer(fn func(*ServerMetricsWidget) templ.Component) {
+	serverMetricsRenderFunc = fn
+}
+
+func (w *ServerMetricsWidget) Render() templ.Component {
+	if serverMetricsRenderFunc != nil {
+		return serverMetricsRenderFunc(w)
+	}
+	return templ.NopComponent
+}